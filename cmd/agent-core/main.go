@@ -13,6 +13,7 @@ import (
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
@@ -28,12 +29,19 @@ func main() {
 	cfg := config.LoadConfig()
 	log.Info().Str("env", cfg.Env).Msg("🚀 Starting agent-core")
 
+	// Init tracing (OTLP exporter configured through the standard OTEL_* variables)
+	shutdownTracing, err := tracing.Init(context.Background(), "agent-core")
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to init tracing")
+	}
+
 	// Init database
-	db := database.NewDB(cfg.DatabaseURL)
+	db := database.NewDB(cfg.DatabaseURL, cfg.DatabasePool())
 	defer db.Close()
 
 	// Init core services (use GORM instance)
 	waService := whatsapp.NewService(cfg.WhatsAppStoreURL)
+	waService.SetDedupStore(whatsapp.NewPostgresDedupStore(db.DB, whatsapp.DefaultDedupTTL))
 	llmClient := llm.NewClient(cfg.OpenAIKey)
 	kbRetriever := kb.NewRetriever(db.GORM)
 	tenantResolver := tenant.NewResolver(db.DB) // Keep sql.DB for now (uses raw SQL)
@@ -50,6 +58,11 @@ func main() {
 		convRepo,
 	)
 
+	// Tenants are resolved from the session the provider receives messages on
+	if providerCfg, err := whatsapp.LoadProviderFromEnv(); err == nil {
+		agentEngine.SetSessionID(providerCfg.SessionID())
+	}
+
 	// Log provider yang digunakan
 	log.Info().Str("provider", waService.GetProviderName()).Msg("📱 WhatsApp Provider")
 
@@ -61,7 +74,7 @@ func main() {
 
 	// Start listening to messages
 	log.Info().Msg("👂 Listening for WhatsApp messages...")
-	err := waService.StartListening(agentEngine.HandleMessage)
+	err = waService.StartListening(agentEngine.HandleMessage)
 
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to start listening")
@@ -84,6 +97,8 @@ func main() {
 	defer cancel()
 
 	waService.Disconnect()
+	if err := shutdownTracing(ctx); err != nil {
+		log.Error().Err(err).Msg("Failed to flush traces")
+	}
 	log.Info().Msg("👋 Goodbye!")
-	_ = ctx // suppress unused warning
 }
@@ -0,0 +1,362 @@
+// Command loadtest replays synthetic WhatsApp traffic against a sandbox tenant and reports
+// latency percentiles and error rates for the webhook pipeline.
+//
+// Run saas-api with LLM_PROVIDER=mock (LLM_MOCK_LATENCY sets the simulated response time)
+// and point the test at a client in sandbox mode, so replies are captured instead of sent:
+//
+//	go run ./cmd/loadtest -client <id> -concurrency 50 -duration 1m
+//	go run ./cmd/loadtest -client <id> -target sandbox -rate 20
+//	go run ./cmd/loadtest -client <id> -profile smoke -json   # CI: exit code 1 when a threshold fails
+//
+// Target "webhook" posts WAHA message events and measures ingestion (the reply is generated
+// after the response); target "sandbox" waits for the bot's reply, so it includes the LLM.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// profiles are preset flag values; flags given explicitly win
+var profiles = map[string]map[string]string{
+	// Short, gentle run for CI: catches a broken pipeline or a latency regression
+	"smoke": {
+		"concurrency":    "4",
+		"duration":       "15s",
+		"rate":           "10",
+		"max-error-rate": "0.01",
+		"max-p95":        "2s",
+	},
+	// Sustained load to find an instance's throughput ceiling
+	"soak": {
+		"concurrency": "100",
+		"duration":    "10m",
+	},
+}
+
+// defaultMessages are typical customer messages, replayed round-robin
+var defaultMessages = []string{
+	"Halo, toko buka jam berapa?",
+	"Kak, ready stok ukuran L?",
+	"Berapa ongkir ke Bandung?",
+	"Saya mau pesan 2 pcs ya",
+	"Bisa bayar COD?",
+	"Pesanan saya sudah dikirim belum?",
+	"Ada promo hari ini?",
+	"Terima kasih kak",
+}
+
+type options struct {
+	baseURL      string
+	clientID     string
+	target       string
+	token        string
+	secret       string
+	concurrency  int
+	duration     time.Duration
+	requests     int
+	rate         float64
+	customers    int
+	timeout      time.Duration
+	messagesFile string
+	maxErrorRate float64
+	maxP95       time.Duration
+	jsonOutput   bool
+}
+
+func main() {
+	opts := parseFlags()
+
+	messages := defaultMessages
+	if opts.messagesFile != "" {
+		loaded, err := loadMessages(opts.messagesFile)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		messages = loaded
+	}
+
+	httpClient := &http.Client{
+		Timeout: opts.timeout,
+		Transport: &http.Transport{
+			MaxIdleConns:        opts.concurrency,
+			MaxIdleConnsPerHost: opts.concurrency,
+		},
+	}
+
+	send, err := newSender(httpClient, opts)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	log.Printf("🚦 Load test: target=%s concurrency=%d duration=%s requests=%d rate=%.1f/s customers=%d",
+		opts.target, opts.concurrency, opts.duration, opts.requests, opts.rate, opts.customers)
+
+	report := run(opts, messages, send)
+
+	if opts.jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("❌ Failed to write report: %v", err)
+		}
+	} else {
+		report.print(os.Stdout)
+	}
+
+	if failures := report.check(opts.maxErrorRate, opts.maxP95); len(failures) > 0 {
+		for _, f := range failures {
+			log.Printf("❌ Threshold failed: %s", f)
+		}
+		os.Exit(1)
+	}
+	log.Printf("✅ Load test passed")
+}
+
+func parseFlags() *options {
+	opts := &options{}
+	profile := flag.String("profile", "", "Preset: smoke (CI) or soak; explicit flags override it")
+	flag.StringVar(&opts.baseURL, "url", "http://localhost:8080", "saas-api base URL")
+	flag.StringVar(&opts.clientID, "client", "", "Client ID of the sandbox tenant (required)")
+	flag.StringVar(&opts.target, "target", "webhook", "webhook (ingestion) or sandbox (full reply, includes the LLM)")
+	flag.StringVar(&opts.token, "token", "", "Per-tenant webhook token: posts to /webhook/{token} instead of /webhook")
+	flag.StringVar(&opts.secret, "secret", "", "Webhook secret to sign bodies (X-Webhook-Hmac): the token's secret, or WAHA_WEBHOOK_HMAC_KEY for /webhook")
+	flag.IntVar(&opts.concurrency, "concurrency", 10, "Concurrent senders")
+	flag.DurationVar(&opts.duration, "duration", 30*time.Second, "Test duration")
+	flag.IntVar(&opts.requests, "requests", 0, "Stop after this many requests (0 = run for -duration)")
+	flag.Float64Var(&opts.rate, "rate", 0, "Total requests per second (0 = as fast as the senders go)")
+	flag.IntVar(&opts.customers, "customers", 100, "Distinct synthetic customer numbers")
+	flag.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Per-request timeout")
+	flag.StringVar(&opts.messagesFile, "messages", "", "File with one customer message per line (default: built-in set)")
+	flag.Float64Var(&opts.maxErrorRate, "max-error-rate", 1, "Fail when the error rate (0-1) is above this")
+	flag.DurationVar(&opts.maxP95, "max-p95", 0, "Fail when p95 latency is above this (0 = no limit)")
+	flag.BoolVar(&opts.jsonOutput, "json", false, "Print the report as JSON")
+	flag.Parse()
+
+	if *profile != "" {
+		preset, ok := profiles[*profile]
+		if !ok {
+			log.Fatalf("❌ Unknown profile: %s", *profile)
+		}
+		explicit := map[string]bool{}
+		flag.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+		for name, value := range preset {
+			if !explicit[name] {
+				if err := flag.Set(name, value); err != nil {
+					log.Fatalf("❌ Invalid profile value for -%s: %v", name, err)
+				}
+			}
+		}
+	}
+
+	if opts.clientID == "" {
+		log.Fatalf("❌ -client is required")
+	}
+	if opts.target != "webhook" && opts.target != "sandbox" {
+		log.Fatalf("❌ -target must be webhook or sandbox")
+	}
+	if opts.concurrency < 1 {
+		opts.concurrency = 1
+	}
+	if opts.customers < 1 {
+		opts.customers = 1
+	}
+	opts.baseURL = strings.TrimRight(opts.baseURL, "/")
+	return opts
+}
+
+// loadMessages reads non-empty lines from a file
+func loadMessages(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages: %w", err)
+	}
+
+	var messages []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			messages = append(messages, line)
+		}
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages in %s", path)
+	}
+	return messages, nil
+}
+
+// sendFunc sends one message and returns the HTTP status
+type sendFunc func(ctx context.Context, seq int, phone, message string) (int, error)
+
+// newSender checks the tenant is safe to load and builds the request for the chosen target
+func newSender(httpClient *http.Client, opts *options) (sendFunc, error) {
+	client, err := fetchClient(httpClient, opts)
+	if err != nil {
+		return nil, err
+	}
+	// Outside sandbox mode every synthetic message would be answered over real WhatsApp
+	if !client.SandboxMode {
+		return nil, fmt.Errorf("client %s is not in sandbox mode, enable it first (PUT /v1/sandbox/mode)", opts.clientID)
+	}
+	log.Printf("🧪 Tenant: %s (sandbox mode)", client.BusinessName)
+
+	if opts.target == "sandbox" {
+		endpoint := fmt.Sprintf("%s/v1/sandbox/messages?client_id=%s", opts.baseURL, url.QueryEscape(opts.clientID))
+		return func(ctx context.Context, seq int, phone, message string) (int, error) {
+			body, _ := json.Marshal(map[string]string{"phone": phone, "message": message})
+			return post(ctx, httpClient, endpoint, body, nil)
+		}, nil
+	}
+
+	endpoint := opts.baseURL + "/webhook"
+	if opts.token != "" {
+		endpoint += "/" + url.PathEscape(opts.token)
+	} else if client.WhatsAppSessionID == "" {
+		return nil, fmt.Errorf("client %s has no WhatsApp session, pass -token or use -target sandbox", opts.clientID)
+	}
+
+	return func(ctx context.Context, seq int, phone, message string) (int, error) {
+		body, _ := json.Marshal(wahaMessage(client.WhatsAppSessionID, seq, phone, message))
+		headers := map[string]string{}
+		if opts.secret != "" {
+			mac := hmac.New(sha512.New, []byte(opts.secret))
+			mac.Write(body)
+			headers["X-Webhook-Hmac"] = hex.EncodeToString(mac.Sum(nil))
+		}
+		return post(ctx, httpClient, endpoint, body, headers)
+	}, nil
+}
+
+type tenant struct {
+	BusinessName      string `json:"business_name"`
+	WhatsAppSessionID string `json:"whatsapp_session_id"`
+	SandboxMode       bool   `json:"sandbox_mode"`
+}
+
+func fetchClient(httpClient *http.Client, opts *options) (*tenant, error) {
+	resp, err := httpClient.Get(fmt.Sprintf("%s/v1/clients/%s", opts.baseURL, url.PathEscape(opts.clientID)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach %s: %w", opts.baseURL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to load client %s: HTTP %d", opts.clientID, resp.StatusCode)
+	}
+	var client tenant
+	if err := json.NewDecoder(resp.Body).Decode(&client); err != nil {
+		return nil, fmt.Errorf("failed to decode client: %w", err)
+	}
+	return &client, nil
+}
+
+// wahaMessage builds a WAHA "message" event as sent by the WhatsApp provider
+func wahaMessage(session string, seq int, phone, message string) map[string]interface{} {
+	now := time.Now()
+	return map[string]interface{}{
+		"event":   "message",
+		"session": session,
+		"payload": map[string]interface{}{
+			"id":        fmt.Sprintf("false_%s@c.us_LOADTEST%d%d", phone, now.UnixNano(), seq),
+			"timestamp": now.Unix(),
+			"from":      phone + "@c.us",
+			"fromMe":    false,
+			"body":      message,
+			"hasMedia":  false,
+		},
+	}
+}
+
+func post(ctx context.Context, httpClient *http.Client, endpoint string, body []byte, headers map[string]string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain so the connection is reused
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// syntheticPhone returns the i-th test customer number (6280000xxxxxx is not a real Indonesian range)
+func syntheticPhone(i int) string {
+	return fmt.Sprintf("6280000%06d", i)
+}
+
+// run sends requests until the duration or request count is reached
+func run(opts *options, messages []string, send sendFunc) *Report {
+	ctx, cancel := context.WithTimeout(context.Background(), opts.duration)
+	defer cancel()
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+
+		var tick <-chan time.Time
+		if opts.rate > 0 {
+			ticker := time.NewTicker(time.Duration(float64(time.Second) / opts.rate))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for seq := 0; opts.requests == 0 || seq < opts.requests; seq++ {
+			if tick != nil {
+				select {
+				case <-tick:
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case jobs <- seq:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	collector := newCollector()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for seq := range jobs {
+				phone := syntheticPhone(seq % opts.customers)
+				message := messages[seq%len(messages)]
+
+				// Requests in flight at the deadline are allowed to finish
+				sent := time.Now()
+				status, err := send(context.Background(), seq, phone, message)
+				collector.record(time.Since(sent), status, err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return collector.report(time.Since(start))
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Report summarizes a load test run; latencies are in milliseconds
+type Report struct {
+	Requests    int            `json:"requests"`
+	Succeeded   int            `json:"succeeded"`
+	Failed      int            `json:"failed"` // Transport errors and non-2xx responses
+	ErrorRate   float64        `json:"error_rate"`
+	DurationSec float64        `json:"duration_sec"`
+	Throughput  float64        `json:"throughput_rps"`
+	Latency     LatencyStats   `json:"latency_ms"`
+	StatusCodes map[string]int `json:"status_codes"`     // "200", "413", ... and "error" for transport errors
+	Errors      map[string]int `json:"errors,omitempty"` // Transport error messages
+}
+
+// LatencyStats are latency percentiles of successful requests
+type LatencyStats struct {
+	Min  float64 `json:"min"`
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P95  float64 `json:"p95"`
+	P99  float64 `json:"p99"`
+	Max  float64 `json:"max"`
+}
+
+// maxDistinctErrors caps the error messages kept, the rest are counted under "other"
+const maxDistinctErrors = 10
+
+type collector struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	failed    int
+	statuses  map[string]int
+	errors    map[string]int
+}
+
+func newCollector() *collector {
+	return &collector{
+		statuses: make(map[string]int),
+		errors:   make(map[string]int),
+	}
+}
+
+func (c *collector) record(latency time.Duration, status int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err != nil {
+		c.failed++
+		c.statuses["error"]++
+		msg := err.Error()
+		if _, seen := c.errors[msg]; !seen && len(c.errors) >= maxDistinctErrors {
+			msg = "other"
+		}
+		c.errors[msg]++
+		return
+	}
+
+	c.statuses[fmt.Sprint(status)]++
+	if status < 200 || status > 299 {
+		c.failed++
+		return
+	}
+	c.latencies = append(c.latencies, latency)
+}
+
+func (c *collector) report(elapsed time.Duration) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r := &Report{
+		Succeeded:   len(c.latencies),
+		Failed:      c.failed,
+		DurationSec: elapsed.Seconds(),
+		StatusCodes: c.statuses,
+	}
+	r.Requests = r.Succeeded + r.Failed
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Failed) / float64(r.Requests)
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Requests) / elapsed.Seconds()
+	}
+	if len(c.errors) > 0 {
+		r.Errors = c.errors
+	}
+
+	if len(c.latencies) == 0 {
+		return r
+	}
+	sorted := append([]time.Duration(nil), c.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	r.Latency = LatencyStats{
+		Min:  ms(sorted[0]),
+		Mean: ms(total / time.Duration(len(sorted))),
+		P50:  ms(percentile(sorted, 50)),
+		P90:  ms(percentile(sorted, 90)),
+		P95:  ms(percentile(sorted, 95)),
+		P99:  ms(percentile(sorted, 99)),
+		Max:  ms(sorted[len(sorted)-1]),
+	}
+	return r
+}
+
+// percentile uses the nearest-rank method on sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(p/100*float64(len(sorted)) + 0.5)
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+	return sorted[rank-1]
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// check returns the thresholds the run failed
+func (r *Report) check(maxErrorRate float64, maxP95 time.Duration) []string {
+	var failures []string
+	if r.Requests == 0 {
+		failures = append(failures, "no requests completed")
+	}
+	if r.ErrorRate > maxErrorRate {
+		failures = append(failures, fmt.Sprintf("error rate %.2f%% > %.2f%%", r.ErrorRate*100, maxErrorRate*100))
+	}
+	if maxP95 > 0 && r.Latency.P95 > ms(maxP95) {
+		failures = append(failures, fmt.Sprintf("p95 %.1fms > %s", r.Latency.P95, maxP95))
+	}
+	return failures
+}
+
+func (r *Report) print(w io.Writer) {
+	fmt.Fprintf(w, "\nRequests:    %d (%d ok, %d failed, %.2f%% errors)\n", r.Requests, r.Succeeded, r.Failed, r.ErrorRate*100)
+	fmt.Fprintf(w, "Duration:    %.1fs\n", r.DurationSec)
+	fmt.Fprintf(w, "Throughput:  %.1f req/s\n", r.Throughput)
+	fmt.Fprintf(w, "Latency ms:  min %.1f | mean %.1f | p50 %.1f | p90 %.1f | p95 %.1f | p99 %.1f | max %.1f\n",
+		r.Latency.Min, r.Latency.Mean, r.Latency.P50, r.Latency.P90, r.Latency.P95, r.Latency.P99, r.Latency.Max)
+
+	codes := make([]string, 0, len(r.StatusCodes))
+	for code := range r.StatusCodes {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	fmt.Fprint(w, "Status:     ")
+	for _, code := range codes {
+		fmt.Fprintf(w, " %s=%d", code, r.StatusCodes[code])
+	}
+	fmt.Fprintln(w)
+
+	for msg, n := range r.Errors {
+		fmt.Fprintf(w, "Error (%d): %s\n", n, msg)
+	}
+	fmt.Fprintln(w)
+}
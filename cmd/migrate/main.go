@@ -1,11 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/migrations"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
@@ -15,22 +18,78 @@ func main() {
 	var module string
 	var command string
 
-	flag.StringVar(&module, "module", "saas", "Module to migrate (saas, umkm, farmasi)")
-	flag.StringVar(&command, "cmd", "up", "Migration command (up, down, version, force)")
+	flag.StringVar(&module, "module", "saas", "Module to migrate (saas, core) or all")
+	flag.StringVar(&command, "cmd", "up", "Migration command (up, down, version, force, status)")
 	flag.Parse()
 
 	// Load config
 	cfg := config.LoadConfig()
+	log.Printf("💾 Database: %s", maskDatabaseURL(cfg.DatabaseURL))
+
+	switch command {
+	case "up":
+		// Dependencies are applied first; "all" applies every module
+		var names []string
+		if module != "all" {
+			names = []string{module}
+		}
+		modules, err := migrations.Resolve(names...)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		for _, m := range modules {
+			log.Printf("📦 Module: %s (depends on: %v)", m.Name, m.DependsOn)
+		}
+
+		db := database.NewDB(cfg.DatabaseURL, cfg.DatabasePool())
+		defer db.Close()
+
+		log.Println("⬆️  Running UP migrations...")
+		if err := migrations.Up(context.Background(), db, cfg.DatabaseURL, names...); err != nil {
+			log.Fatalf("❌ Migration UP failed: %v", err)
+		}
+		log.Println("✅ Migrations UP completed!")
+		return
+
+	case "status":
+		db := database.NewDB(cfg.DatabaseURL, cfg.DatabasePool())
+		defer db.Close()
+
+		statuses, err := migrations.Status(context.Background(), db)
+		if err != nil {
+			log.Fatalf("❌ Failed to get status: %v", err)
+		}
+		for _, s := range statuses {
+			version := "none"
+			if s.Version != nil {
+				version = fmt.Sprint(*s.Version)
+			}
+			log.Printf("📌 %-6s version %s / %d (dirty: %t, pending: %d, depends on: %v)", s.Name, version, s.Latest, s.Dirty, s.Pending, s.DependsOn)
+		}
+		return
+	}
+
+	if module == "all" {
+		log.Fatalf("❌ -module=all is only supported for up and status")
+	}
+	mod, ok := migrations.Find(module)
+	if !ok {
+		log.Fatalf("❌ Unknown module: %s", module)
+	}
 
 	// Migration path
 	migrationPath := fmt.Sprintf("file://migrations/%s", module)
 
 	log.Printf("🔄 Running migrations for module: %s", module)
-	log.Printf("📂 Migration path: %s", migrationPath)
-	log.Printf("💾 Database: %s", maskDatabaseURL(cfg.DatabaseURL))
+	log.Printf("📂 Migration path: %s (version table: %s)", migrationPath, mod.Table)
+
+	databaseURL, err := database.MigrationURL(cfg.DatabaseURL, mod.Table)
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
 
 	// Create migrate instance
-	m, err := migrate.New(migrationPath, cfg.DatabaseURL)
+	m, err := migrate.New(migrationPath, databaseURL)
 	if err != nil {
 		log.Fatalf("❌ Failed to create migrate instance: %v", err)
 	}
@@ -38,13 +97,6 @@ func main() {
 
 	// Execute command
 	switch command {
-	case "up":
-		log.Println("⬆️  Running UP migrations...")
-		if err := m.Up(); err != nil && err != migrate.ErrNoChange {
-			log.Fatalf("❌ Migration UP failed: %v", err)
-		}
-		log.Println("✅ Migrations UP completed!")
-
 	case "down":
 		log.Println("⬇️  Running DOWN migrations...")
 		if err := m.Down(); err != nil && err != migrate.ErrNoChange {
@@ -71,7 +123,7 @@ func main() {
 		log.Printf("✅ Forced version to: %d", forceVersion)
 
 	default:
-		log.Fatalf("❌ Unknown command: %s (use: up, down, version, force)", command)
+		log.Fatalf("❌ Unknown command: %s (use: up, down, version, force, status)", command)
 	}
 }
 
@@ -1868,7 +1868,7 @@ var SwaggerInfo = &swag.Spec{
 	BasePath:         "/",
 	Schemes:          []string{},
 	Title:            "WhatsApp Bot SaaS API",
-	Description:      "API documentation for WhatsApp Bot SaaS (Modular Architecture)",
+	Description:      "API documentation for WhatsApp Bot SaaS (Modular Architecture)\n\n## Versioning\nThe REST API is versioned by path prefix: call `/v1/...` (e.g. `/v1/clients/{id}` for `/clients/{id}` below). Every /v1 response carries `API-Version: v1`. A new major version gets a new prefix; /v1 keeps working unchanged alongside it.\n\nThe unprefixed paths are legacy aliases of /v1 for a deprecation window. Their responses carry `Deprecation` (RFC 9745), `Sunset` (RFC 8594, the date the alias is removed) and `Link: </v1/...>; rel=\"successor-version\"`.\n\nNot versioned: provider webhooks (`/webhook`, `/webhook/{token}`, `/webhooks/midtrans`), customer links (`/q/{token}`, `/t/{token}`), `/uploads` and health checks.",
 	InfoInstanceName: "swagger",
 	SwaggerTemplate:  docTemplate,
 	LeftDelim:        "{{",
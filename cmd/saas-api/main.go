@@ -1,27 +1,42 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/swagger"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/email"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/export"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/jobs"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/notification"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/ocr"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/region"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/stt"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/vector"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/handlers"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/cache"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/migrations"
 
 	_ "github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/cmd/saas-api/docs"
 )
@@ -29,6 +44,13 @@ import (
 // @title WhatsApp Bot SaaS API
 // @version 2.0
 // @description API documentation for WhatsApp Bot SaaS (Modular Architecture)
+// @description
+// @description ## Versioning
+// @description The REST API is versioned by path prefix: call `/v1/...` (e.g. `/v1/clients/{id}` for `/clients/{id}` below). Every /v1 response carries `API-Version: v1`. A new major version gets a new prefix; /v1 keeps working unchanged alongside it.
+// @description
+// @description The unprefixed paths are legacy aliases of /v1 for a deprecation window. Their responses carry `Deprecation` (RFC 9745), `Sunset` (RFC 8594, the date the alias is removed) and `Link: </v1/...>; rel="successor-version"`.
+// @description
+// @description Not versioned: provider webhooks (`/webhook`, `/webhook/{token}`, `/webhooks/whatsapp-cloud`, `/webhooks/midtrans`), customer links (`/q/{token}`, `/t/{token}`), `/uploads` and health checks.
 // @termsOfService http://swagger.io/terms/
 // @contact.name API Support
 // @contact.email support@whatsapp-saas.com
@@ -40,23 +62,105 @@ func main() {
 	cfg := config.LoadConfig()
 	log.Printf("🚀 Starting saas-api on port %s", cfg.Port)
 
+	// Init tracing (OTLP exporter configured through the standard OTEL_* variables)
+	shutdownTracing, err := tracing.Init(context.Background(), "saas-api")
+	if err != nil {
+		log.Fatalf("❌ Failed to init tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	// Periodic background jobs run until shutdown begins
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
 	// Init database
-	db := database.NewDB(cfg.DatabaseURL)
+	db := database.NewDB(cfg.DatabaseURL, cfg.DatabasePool())
 	defer db.Close()
 
+	// Apply pending migrations of every module in dependency order (optional, replicas serialize on an advisory lock)
+	if cfg.AutoMigrate {
+		if err := migrations.Up(context.Background(), db, cfg.DatabaseURL); err != nil {
+			log.Fatalf("❌ Auto-migration failed: %v", err)
+		}
+	}
+
+	// Init Redis cache (optional, caches clients, knowledge bases and tenant resolution for the message path)
+	appCache := newCache(cfg)
+	defer appCache.Close()
+
 	// Init repositories (use GORM instance)
-	clientRepo := repositories.NewClientRepo(db.GORM)
+	clientRepo, err := repositories.NewCachedClientRepo(repositories.NewClientRepo(db.GORM), db.GORM, appCache)
+	if err != nil {
+		log.Fatalf("❌ Failed to init client cache: %v", err)
+	}
 	conversationRepo := repositories.NewConversationRepo(db.GORM)
 	kbRepo := repositories.NewKBRepo(db.GORM)
+	kbDuplicateRepo := repositories.NewKBDuplicateRepo(db.GORM)
+	kbDocumentRepo := repositories.NewKBDocumentRepo(db.GORM)
+	kbSyncRepo := repositories.NewKBSyncRepo(db.GORM)
 	transactionRepo := repositories.NewTransactionRepo(db.GORM)
 	workflowRepo := repositories.NewWorkflowRepo(db.GORM)
 	orderRepo := repositories.NewOrderRepo(db.GORM)
+	orderRiskRepo := repositories.NewOrderRiskRepo(db.GORM)
 	cartRepo := repositories.NewCartRepo(db.GORM)
 	productRepo := repositories.NewProductRepo(db.GORM)
+	provisioningRepo := repositories.NewWhatsAppProvisioningRepo(db.GORM)
+	sandboxRepo := repositories.NewSandboxRepo(db.GORM)
+	storeRepo := repositories.NewStoreRepo(db.GORM)
+	branchStockRepo := repositories.NewBranchStockRepo(db.GORM)
+	driverRepo := repositories.NewDriverRepo(db.GORM)
+	shipmentRepo := repositories.NewShipmentRepo(db.GORM)
+	waitlistRepo := repositories.NewWaitlistRepo(db.GORM)
+	quoteRepo := repositories.NewQuoteRepo(db.GORM)
+	productMentionRepo := repositories.NewProductMentionRepo(db.GORM)
+	reportRepo := repositories.NewReportRepo(db.GORM)
+	kbSuggestionRepo := repositories.NewKBSuggestionRepo(db.GORM)
+	reconciliationRepo := repositories.NewReconciliationRepo(db.GORM)
+	paymentRoutingRepo := repositories.NewPaymentRoutingRepo(db.GORM)
+	codSettingsRepo := repositories.NewCODSettingsRepo(db.GORM)
+	onboardingFlowRepo := repositories.NewOnboardingFlowRepo(db.GORM)
+	reactionSettingsRepo := repositories.NewReactionSettingsRepo(db.GORM)
+	languageSettingsRepo := repositories.NewLanguageSettingsRepo(db.GORM)
+	latencySettingsRepo := repositories.NewLatencySettingsRepo(db.GORM)
+	subscriptionRepo := repositories.NewSubscriptionRepo(db.GORM)
+	billingStatementRepo := repositories.NewBillingStatementRepo(db.GORM)
+	offboardingRepo := repositories.NewClientOffboardingRepo(db.GORM)
+	slaRepo := repositories.NewSLARepo(db.GORM)
+	paymentReminderRepo := repositories.NewPaymentReminderRepo(db.GORM)
+	splitPaymentRepo := repositories.NewSplitPaymentRepo(db.GORM)
+	walletRepo := repositories.NewWalletRepo(db.GORM)
+	conversationTagRepo := repositories.NewConversationTagRepo(db.GORM)
+	campaignRepo := repositories.NewCampaignRepo(db.GORM)
+	recommendationRepo := repositories.NewRecommendationRepo(db.GORM)
+	messageFeatureRepo := repositories.NewMessageFeatureRepo(db.GORM)
+	usageRepo := repositories.NewUsageRepo(db.GORM)
+	customerPreferenceRepo := repositories.NewCustomerPreferenceRepo(db.GORM)
+	customerRepo := repositories.NewCustomerRepo(db.GORM)
+	customerSegmentRepo := repositories.NewCustomerSegmentRepo(db.GORM)
+	sessionBackupRepo := repositories.NewWhatsAppSessionBackupRepo(db.GORM)
+	customFieldRepo := repositories.NewCustomFieldRepo(db.GORM)
+	transcriptExportRepo := repositories.NewTranscriptExportRepo(db.GORM)
+	companyUserRepo := repositories.NewCompanyUserRepo(db.GORM)
+	apiKeyRepo := repositories.NewAPIKeyRepo(db.GORM)
+	adminCommandRepo := repositories.NewAdminCommandRepo(db.GORM)
+	llmBenchmarkRepo := repositories.NewLLMBenchmarkRepo(db.GORM)
+	paymentEventRepo := repositories.NewPaymentEventRepo(db.GORM)
+	regionMigrationRepo := repositories.NewRegionMigrationRepo(db.GORM)
+	conversationReplayRepo := repositories.NewConversationReplayRepo(db.GORM)
 	kbRetriever := kb.NewRetriever(db.GORM)
+	if appCache.Enabled() {
+		if err := kbRetriever.SetCache(appCache); err != nil {
+			log.Fatalf("❌ Failed to init knowledge base cache: %v", err)
+		}
+	}
 
 	// Init tenant resolver (for multi-tenant/multi-module routing)
 	tenantResolver := tenant.NewResolver(db.DB)
+	if appCache.Enabled() {
+		if err := tenantResolver.SetCache(appCache, db.GORM); err != nil {
+			log.Fatalf("❌ Failed to init tenant cache: %v", err)
+		}
+	}
 
 	// Init LLM service (multi-provider support)
 	llmService := llm.NewService()
@@ -64,6 +168,14 @@ func main() {
 	// Init WhatsApp service
 	waService := whatsapp.NewService(cfg.WhatsAppStoreURL)
 
+	// Share seen message IDs between replicas so retried or re-polled messages are handled once
+	messageDedup := whatsapp.NewPostgresDedupStore(db.DB, whatsapp.DefaultDedupTTL)
+	waService.SetDedupStore(messageDedup)
+	go messageDedup.RunCleanupJob(jobsCtx, time.Hour)
+
+	// Init session manager (one WAHA session per client, messages sent through the client's own session)
+	sessionManager := whatsapp.NewSessionManager(waService, services.ClientSessionLookup(clientRepo))
+
 	// Init OCR service (multi-provider support)
 	var ocrProvider ocr.Provider
 	switch cfg.OCRProvider {
@@ -77,6 +189,18 @@ func main() {
 	}
 	ocrService := ocr.NewService(ocrProvider)
 
+	// Init speech-to-text service for customer voice notes (multi-provider support)
+	var sttService *stt.Service
+	switch cfg.STTProvider {
+	case "none":
+		log.Println("ℹ️ Voice note transcription disabled (STT_PROVIDER=none)")
+	case "google":
+		sttService = stt.NewService(stt.NewGoogleProvider(cfg.GoogleSTTAPIKey), cfg.STTLanguage)
+	default:
+		// Default to OpenAI Whisper
+		sttService = stt.NewService(stt.NewWhisperProvider(cfg.OpenAIKey), cfg.STTLanguage)
+	}
+
 	// Init email service (multi-provider support)
 	var emailProvider email.Provider
 	switch cfg.EmailProvider {
@@ -116,35 +240,188 @@ func main() {
 		log.Printf("🔔 Notification service enabled (Admin: %s, %s)", cfg.AdminPhone, cfg.AdminEmail)
 	}
 
-	// Init payment gateway based on config
-	paymentGateway, err := payment.NewGateway(cfg, db.GORM)
+	// Init payment gateways based on config (tenants can route orders between them)
+	paymentGateways, err := payment.NewGatewayRegistry(cfg, db.GORM)
 	if err != nil {
 		log.Fatalf("Failed to initialize payment gateway: %v", err)
 	}
 	log.Printf("💳 Payment mode: %s", cfg.PaymentMode)
+	sandboxGateway := payment.NewSandboxPaymentGateway(db.GORM)
 
 	// Init services
-	workflowService := services.NewWorkflowService(workflowRepo, db.GORM, waService, llmService)
+	auditService := audit.NewService(db.GORM)
+
+	// Init usage service (LLM tokens metered per client against the plan quota and AI credits)
+	usageService := services.NewUsageService(usageRepo, clientRepo, auditService)
+	llmService.SetUsageMeter(usageService)
+	llmService.SetModelLookup(services.ClientModelLookup(clientRepo))
+
+	// Init AI settings service (per-client model, prompt template, temperature and max tokens)
+	aiSettingsService := services.NewAISettingsService(clientRepo)
+
+	workflowService := services.NewWorkflowService(workflowRepo, db.GORM, waService, llmService, auditService)
 	if err := workflowService.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize workflow service: %v", err)
 	}
-	defer workflowService.Shutdown()
 
-	// Init order service with payment gateway and notification
-	orderService := services.NewOrderService(orderRepo, clientRepo, paymentGateway, waService, notificationService)
+	// Init sandbox service (test mode: captured WhatsApp messages and simulated payments)
+	sandboxService := services.NewSandboxService(clientRepo, sandboxRepo, waService)
+	sandboxService.SetSessionManager(sessionManager)
 
 	// Init cart service
 	cartService := services.NewCartService(cartRepo, orderRepo)
 
-	// Init product service
-	productService := services.NewProductService(productRepo)
+	// Init branch service (branch stock, checkout branch selection and branch analytics)
+	branchService := services.NewBranchService(storeRepo, branchStockRepo, productRepo, cartService, analytics.NewAggregator(db.GORM))
+
+	// Init waitlist service (restock alerts and pre-orders)
+	waitlistService := services.NewWaitlistService(waitlistRepo, productRepo, waService, sandboxService)
+
+	// Init order service with payment gateway and notification
+	orderService := services.NewOrderService(orderRepo, clientRepo, orderRiskRepo, paymentRoutingRepo, codSettingsRepo, paymentGateways, sandboxGateway, waService, notificationService, sandboxService, branchService, waitlistService, cfg.PublicBaseURL)
+
+	// Init store service (store locator)
+	storeService := services.NewStoreService(storeRepo)
+
+	// Init delivery service (drivers update shipments via WhatsApp keywords)
+	deliveryService := services.NewDeliveryService(driverRepo, shipmentRepo, orderRepo, orderService, branchService, waService, sandboxService)
+
+	// Init tracking service (public order tracking links)
+	trackingService := services.NewTrackingService(orderRepo, shipmentRepo, clientRepo)
+
+	// Init OCR retention service (raw receipt text anonymization and purge job)
+	ocrRetentionService := services.NewOCRRetentionService(clientRepo, transactionRepo)
+	go ocrRetentionService.RunPurgeJob(jobsCtx, 6*time.Hour)
+
+	// Init mobile dashboard service (compact admin dashboard and quick actions)
+	mobileDashboardService := services.NewMobileDashboardService(orderRepo, clientRepo, orderService, waService)
+
+	// Init order board service (kanban board and stage moves for fulfillment teams)
+	orderBoardService := services.NewOrderBoardService(orderRepo)
+
+	// Init quote service (quotations converted into orders on acceptance)
+	quoteService := services.NewQuoteService(quoteRepo, cartService, orderService, export.NewService(), waService, sandboxService, cfg.PublicBaseURL)
+
+	// Init product mention service (products asked about in conversations, for demand analytics)
+	productMentionService := services.NewProductMentionService(productMentionRepo, productRepo, orderRepo, llmService)
 
-	// Init webhook service with cart and order services
-	webhookService := services.NewWebhookService(clientRepo, conversationRepo, transactionRepo, kbRetriever, llmService, waService, ocrService, tenantResolver, cartService, orderService, cfg)
+	// Init report service (sales, top products and conversation reports aggregated in SQL for tenant dashboards)
+	reportService := services.NewReportService(reportRepo)
+
+	// Init vector KB retriever (optional, re-indexes knowledge base entries for semantic search)
+	var vectorRetriever *kb.VectorRetriever
+	if cfg.VectorProvider != "" {
+		vectorRetriever = newVectorRetriever(cfg)
+	}
+
+	// Init KB suggestion service (weekly FAQ drafts from unanswered and low-rated questions)
+	kbSuggestionService := services.NewKBSuggestionService(kbSuggestionRepo, kbRepo, conversationRepo, kbRetriever, vectorRetriever, llmService)
+	go kbSuggestionService.RunWeeklyJob(jobsCtx)
+
+	// Init KB bulk service (bulk delete and re-import, kept in sync with the vector index)
+	kbBulkService := services.NewKBBulkService(kbRepo, kbDuplicateRepo, vectorRetriever, cfg.JWTSecret)
+
+	// Init KB dedup service (normalization and duplicate detection when entries are added)
+	kbDedupService := services.NewKBDedupService(kbRepo, kbDuplicateRepo, kbBulkService, vectorRetriever)
+
+	// Init KB document service (PDF/DOCX/TXT uploads chunked into the vector index, quoted in answers)
+	kbDocumentService := services.NewKBDocumentService(kbDocumentRepo, vectorRetriever)
+
+	// Init KB sync service (applies the queue of knowledge base changes to the vector DB)
+	kbSyncService := services.NewKBSyncService(kbSyncRepo, vectorRetriever)
+	if vectorRetriever != nil {
+		go kbSyncService.RunSyncWorker(jobsCtx, time.Minute)
+	}
+
+	// Init LLM benchmark service (prompt suite against every configured provider, results kept for comparison)
+	llmProviderConfig, err := llm.LoadProviderFromEnv()
+	if err != nil {
+		log.Fatalf("❌ Failed to load LLM config: %v", err)
+	}
+	llmBenchmarkService := services.NewLLMBenchmarkService(llmBenchmarkRepo, llmProviderConfig)
+
+	// Init reconciliation service (paid orders vs gateway settlements, reconciles the previous day)
+	// Routed orders can use Midtrans even when it isn't the default gateway
+	reconciliationGateway, ok := paymentGateways.Get(payment.GatewayMidtrans)
+	if !ok {
+		reconciliationGateway = paymentGateways.Default()
+	}
+	reconciliationService := services.NewReconciliationService(reconciliationRepo, orderRepo, clientRepo, reconciliationGateway)
+	go reconciliationService.RunDailyJob(jobsCtx, 6*time.Hour)
+
+	// Init customer onboarding service (greeting, language and consent for first-time customers)
+	customerOnboardingService := services.NewCustomerOnboardingService(onboardingFlowRepo, conversationRepo)
+
+	// Init bot pause service (vacation mode from the admin's WhatsApp, resumes on schedule)
+	botPauseService := services.NewBotPauseService(clientRepo, waService, sandboxService)
+	languageService := services.NewLanguageService(languageSettingsRepo, conversationRepo, llmService)
+	reactionService := services.NewReactionService(reactionSettingsRepo, conversationRepo, orderService, quoteService, workflowService)
+	go botPauseService.RunAutoResume(jobsCtx, time.Minute)
+
+	// Init offboarding service (client deactivation cascade, retried until every step is done)
+	offboardingService := services.NewOffboardingService(clientRepo, offboardingRepo, waService, workflowService)
+
+	// Init SLA service (first-response and resolution times, breach events)
+	slaService := services.NewSLAService(slaRepo, workflowService)
+	go slaService.RunSLAJob(jobsCtx, time.Minute)
+
+	// Init payment reminder service (reminder ladder for pending orders, reminder -> payment conversion)
+	paymentReminderService := services.NewPaymentReminderService(paymentReminderRepo, orderRepo, waService, sandboxService)
+	go paymentReminderService.RunReminderJob(jobsCtx, time.Minute)
+
+	// Init split payment service (group orders paid in portions, confirmed once every portion is paid)
+	splitPaymentService := services.NewSplitPaymentService(splitPaymentRepo, orderRepo, orderService)
+	orderService.SetSplitPaymentRepo(splitPaymentRepo)
+	go splitPaymentService.RunSplitPaymentJob(jobsCtx, time.Minute)
+
+	// Init wallet service (prepaid customer credit, spent at checkout when it covers the order)
+	walletService := services.NewWalletService(walletRepo, orderService, auditService)
+	orderService.SetWalletService(walletService)
+
+	// Init conversation tag service (manual, chat command and keyword tags on customer chats)
+	conversationTagService := services.NewConversationTagService(conversationTagRepo, workflowService)
+
+	// Init campaign service (scheduled broadcasts to filtered audiences, throttled, with delivery tracking)
+	campaignService := services.NewCampaignService(campaignRepo, conversationTagService, waService, sandboxService)
+	campaignService.SetSessionManager(sessionManager)
+
+	// Init customer segment service (saved customer filters for campaign audiences and workflow conditions)
+	customerSegmentService := services.NewCustomerSegmentService(customerSegmentRepo, customerRepo, conversationTagService)
+	campaignService.SetSegmentService(customerSegmentService)
+	workflowService.SetCustomerSegmentService(customerSegmentService)
+	go campaignService.RunCampaignJob(jobsCtx, time.Minute)
+
+	// Init recommendation service (complementary products from co-purchases, ranked by the LLM, with conversion tracking)
+	recommendationService := services.NewRecommendationService(recommendationRepo, llmService, waitlistService)
+
+	// Init latency service (interim message and degraded fallback when the LLM is slow)
+	latencyService := services.NewLatencyService(latencySettingsRepo)
+
+	// Init subscription service (plan catalog, usage upgrade prompts, prorated plan changes paid through Midtrans)
+	billingGateway, _ := paymentGateways.Get(payment.GatewayMidtrans)
+	var usageNotifier services.UsageNotifier
+	if notificationService != nil {
+		usageNotifier = notificationService
+	}
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, clientRepo, usageRepo, billingGateway, usageNotifier)
+	if err := subscriptionService.LoadPlans(); err != nil {
+		log.Printf("⚠️ Using the built-in plan catalog: %v", err)
+	}
+	go subscriptionService.RunSubscriptionJob(jobsCtx, time.Hour)
+
+	// Init payment event service (every gateway webhook stored with its result, replayable by admins)
+	// Midtrans webhooks are only accepted with a valid signature_key and confirmed through the status API
+	midtransGateway, _ := billingGateway.(*payment.MidtransPaymentGateway)
+	paymentEventService := services.NewPaymentEventService(paymentEventRepo, orderService, subscriptionService, splitPaymentService, walletService, midtransGateway)
+
+	// Init custom field service (per-client extra fields on customers, orders and products)
+	customFieldService := services.NewCustomFieldService(customFieldRepo)
+
+	// Init config bundle service (export a tenant's bot setup and import it into another tenant)
+	configBundleService := services.NewConfigBundleService(clientRepo, kbRepo, kbBulkService, workflowService, conversationTagService, languageService, reactionService, customerOnboardingService, orderService, slaService, ocrRetentionService, latencyService, customFieldService)
 
 	// Init auth service
 	authService := auth.NewService(db.GORM, cfg.JWTSecret)
-	authHandler := auth.NewHandler(authService, cfg.GoogleClientID)
 	log.Printf("🔐 Authentication service initialized")
 
 	// Init upload service (multi-provider support)
@@ -195,116 +472,205 @@ func main() {
 	}
 	uploadService := upload.NewService(uploadProvider)
 
+	// Init region registry (this deployment is the default region; tenants can be pinned to the REGIONS)
+	regionRegistry := region.NewRegistry(&region.Region{Code: cfg.DefaultRegion, DB: db, Vector: vectorRetriever, Storage: uploadService})
+	for _, rc := range cfg.Regions {
+		regionRegistry.Register(newRegion(cfg, rc, uploadService))
+	}
+	regionMigrationService := services.NewRegionMigrationService(regionMigrationRepo, clientRepo, regionRegistry)
+
+	// Init jobs service (background job queue; transcript exports run on the exports queue)
+	jobService := jobs.NewService(db.GORM)
+
+	// Init transcript service (per-conversation PDF/HTML transcripts, optionally emailed)
+	var transcriptMailer services.TranscriptMailer
+	if emailService != nil {
+		transcriptMailer = emailService
+	}
+	transcriptService := services.NewTranscriptService(transcriptExportRepo, conversationRepo, clientRepo, companyUserRepo, jobService, export.NewService(), uploadService, transcriptMailer)
+
+	// Init billing statements (monthly plan fee plus usage overages, PDF emailed to tenant admins, paid through the billing gateway)
+	var statementMailer services.StatementMailer
+	if emailService != nil {
+		statementMailer = emailService
+	}
+	billingStatementService := services.NewBillingStatementService(billingStatementRepo, subscriptionRepo, clientRepo, companyUserRepo, usageRepo, export.NewService(), uploadService, billingGateway, statementMailer, cfg.EmailFromName)
+	paymentEventService.SetStatementService(billingStatementService)
+	go billingStatementService.RunStatementJob(jobsCtx, time.Hour)
+
+	// Init dunning service (overdue statements make subscriptions past due, downgraded to free after a grace period)
+	var dunningNotifier services.DunningNotifier
+	if notificationService != nil {
+		dunningNotifier = notificationService
+	}
+	dunningService := services.NewDunningService(subscriptionRepo, billingStatementRepo, clientRepo, subscriptionService, auditService, dunningNotifier)
+	go dunningService.RunDunningJob(jobsCtx, time.Hour)
+	jobService.RegisterWorker(jobs.WorkerConfig{
+		Queue:        services.TranscriptQueue,
+		Concurrency:  2,
+		PollInterval: 2 * time.Second,
+		Timeout:      5 * time.Minute,
+	}, transcriptService)
+
+	// Init outbound message queue (WhatsApp sends retried with backoff, dead letters kept for the platform admin).
+	// One worker keeps each customer's messages in order.
+	outboundService := services.NewOutboundMessageService(jobService, sessionManager)
+	jobService.RegisterWorker(jobs.WorkerConfig{
+		Queue:        services.OutboundQueue,
+		Concurrency:  1,
+		PollInterval: 500 * time.Millisecond,
+		Timeout:      30 * time.Second,
+	}, outboundService)
+	sandboxService.SetOutboundQueue(outboundService)
+	if err := jobService.StartWorkers(context.Background()); err != nil {
+		log.Fatalf("Failed to start job workers: %v", err)
+	}
+
+	// Init product service (uses upload service for catalog images)
+	productService := services.NewProductService(productRepo, uploadService, waitlistService)
+
+	// Init conversation memory (earlier exchanges sent to the LLM as chat history)
+	conversationMemory := services.NewConversationMemory(conversationRepo, cfg.LLMHistoryTurns, cfg.LLMHistoryTokens, cfg.LLMHistoryMaxAge)
+
+	// Init webhook service with cart and order services (after the product service, used by admin stock commands)
+	webhookService := services.NewWebhookService(clientRepo, conversationRepo, transactionRepo, kbRetriever, llmService, waService, ocrService, tenantResolver, cartService, orderService, sandboxService, storeService, deliveryService, waitlistService, quoteService, productMentionService, kbSuggestionService, customerOnboardingService, botPauseService, reactionService, languageService, slaService, conversationTagService, latencyService, conversationMemory, productService, adminCommandRepo, auditService, cfg)
+
+	webhookService.SetCampaignService(campaignService)
+	webhookService.SetRecommendationService(recommendationService)
+	webhookService.SetSTTService(sttService)
+	webhookService.SetOutboundQueue(outboundService)
+	webhookService.SetSessionManager(sessionManager)
+	webhookService.SetWalletService(walletService)
+	messageFeatureService := services.NewMessageFeatureService(messageFeatureRepo)
+	webhookService.SetMessageFeatureService(messageFeatureService)
+	webhookService.SetKBDocumentService(kbDocumentService)
+	customerPreferenceService := services.NewCustomerPreferenceService(customerPreferenceRepo)
+	webhookService.SetCustomerPreferenceService(customerPreferenceService)
+	// Init customer service (CRM records created from inbound messages, with order totals and chat tags)
+	customerService := services.NewCustomerService(customerRepo, conversationTagRepo)
+	webhookService.SetCustomerService(customerService)
+	webhookService.SetWorkflowService(workflowService)
+	workflowService.SetCommerceServices(cartService, orderService, productService, reportService)
+	go workflowService.RunCommerceEventJob(jobsCtx, time.Minute)
+	if notificationService != nil {
+		workflowService.SetNotifier(notificationService)
+	}
+
+	// Init conversation replay service (QA replays of historical messages against the current configuration, nothing sent)
+	conversationReplayService := services.NewConversationReplayService(conversationReplayRepo, conversationRepo, clientRepo, kbRetriever, webhookService, llmService, llmProviderConfig)
+
 	// Init handlers
-	clientHandler := handlers.NewClientHandler(clientRepo)
-	kbHandler := handlers.NewKBHandler(kbRetriever, kbRepo)
-	healthHandler := handlers.NewHealthHandler(waService)
-	whatsappHandler := handlers.NewWhatsAppHandler(waService, clientRepo)
-	webhookHandler := handlers.NewWebhookHandler(webhookService)
-	ocrHandler := handlers.NewOCRHandler(ocrService, llmService, transactionRepo, workflowService)
-	workflowHandler := handlers.NewWorkflowHandler(workflowService)
-	paymentHandler := handlers.NewPaymentHandler(orderService)
-	cartHandler := handlers.NewCartHandler(cartService)
-	productHandler := handlers.NewProductHandler(productService)
-	uploadHandler := upload.NewHandler(uploadService)
+	h := &routeHandlers{}
+	h.auth = auth.NewHandler(authService, cfg.GoogleClientID)
+	h.client = handlers.NewClientHandler(clientRepo)
+	h.kb = handlers.NewKBHandler(kbRetriever, kbRepo, kbBulkService, kbDedupService)
+	h.kbDocument = handlers.NewKBDocumentHandler(kbDocumentService)
+	h.kbSync = handlers.NewKBSyncHandler(kbSyncService)
+	h.health = handlers.NewHealthHandler(waService, db, vectorRetriever, cfg.AutoMigrate)
+	h.health.SetCache(appCache)
+	h.migration = handlers.NewMigrationHandler(db)
+	h.offboarding = handlers.NewOffboardingHandler(offboardingService)
+	h.vectorIndex = handlers.NewVectorIndexHandler(vectorRetriever)
+	h.llmBenchmark = handlers.NewLLMBenchmarkHandler(llmBenchmarkService)
+	h.whatsapp = handlers.NewWhatsAppHandler(waService, clientRepo, cfg.WAHAWebhookHMACKey)
+	h.whatsappSession = handlers.NewWhatsAppSessionHandler(services.NewWhatsAppSessionService(clientRepo, sessionManager))
+
+	// Init WhatsApp session backups (hourly WAHA session snapshots, restored by the platform admin after the WAHA container is replaced)
+	sessionBackupService := services.NewWhatsAppSessionBackupService(sessionBackupRepo, sessionManager, uploadService)
+	if sessionManager.MultiSession() {
+		go sessionBackupService.RunBackupJob(jobsCtx, time.Hour)
+	}
+	h.sessionBackup = handlers.NewWhatsAppSessionBackupHandler(sessionBackupService)
+	onboardingService := services.NewOnboardingService(clientRepo, provisioningRepo, waService, cfg.PublicBaseURL)
+
+	// Init admin provisioning service (idempotent /v1/admin API keyed by external reference IDs)
+	adminProvisioningService := services.NewAdminProvisioningService(clientRepo, companyUserRepo, apiKeyRepo, onboardingService)
+	offboardingService.RegisterStep("revoke_api_keys", adminProvisioningService.RevokeAPIKeysStep)
+	offboardingService.RegisterStep("cancel_campaigns", campaignService.CancelClientCampaigns)
+	go offboardingService.RunOffboardingJob(jobsCtx, 5*time.Minute) // After every step is registered
+	h.onboarding = handlers.NewOnboardingHandler(onboardingService)
+	h.onboardingFlow = handlers.NewOnboardingFlowHandler(customerOnboardingService)
+	h.reaction = handlers.NewReactionHandler(reactionService)
+	h.language = handlers.NewLanguageHandler(languageService)
+	h.latency = handlers.NewLatencyHandler(latencyService)
+	h.subscription = handlers.NewSubscriptionHandler(subscriptionService, dunningService)
+	h.usage = handlers.NewUsageHandler(usageService)
+	h.aiSettings = handlers.NewAISettingsHandler(aiSettingsService)
+	h.billingStatement = handlers.NewBillingStatementHandler(billingStatementService)
+	h.sla = handlers.NewSLAHandler(slaService, clientRepo)
+	h.paymentReminder = handlers.NewPaymentReminderHandler(paymentReminderService)
+	h.splitPayment = handlers.NewSplitPaymentHandler(splitPaymentService)
+	h.wallet = handlers.NewWalletHandler(walletService)
+	h.conversationTag = handlers.NewConversationTagHandler(conversationTagService)
+	h.campaign = handlers.NewCampaignHandler(campaignService)
+	h.recommendation = handlers.NewRecommendationHandler(recommendationService)
+	h.messageFeature = handlers.NewMessageFeatureHandler(messageFeatureService)
+	h.configBundle = handlers.NewConfigBundleHandler(configBundleService)
+	h.customField = handlers.NewCustomFieldHandler(customFieldService)
+	h.customerPreference = handlers.NewCustomerPreferenceHandler(customerPreferenceService)
+	h.customer = handlers.NewCustomerHandler(customerService, customerSegmentService)
+	h.customerSegment = handlers.NewCustomerSegmentHandler(customerSegmentService)
+	h.transcript = handlers.NewTranscriptHandler(transcriptService)
+	h.adminProvisioning = handlers.NewAdminProvisioningHandler(adminProvisioningService)
+	webhookBodyReader := handlers.NewWebhookBodyReader(cfg.WebhookMaxBodyBytes, uploadService)
+	webhookSignatures := handlers.NewWebhookSignatures(handlers.WebhookSecrets{
+		WAHAHMACKey:       cfg.WAHAWebhookHMACKey,
+		CloudAPIAppSecret: cfg.CloudAPIAppSecret,
+		GreenAPIToken:     cfg.GreenAPIWebhookToken,
+		AllowUnsigned:     cfg.WebhookAllowUnsigned,
+	})
+	switch {
+	case cfg.WebhookAllowUnsigned:
+		log.Printf("⚠️ WEBHOOK_ALLOW_UNSIGNED is set, /webhook accepts unsigned requests")
+	case len(webhookSignatures.Providers()) == 0:
+		log.Printf("⚠️ No webhook secret configured, /webhook rejects every request (set WAHA_WEBHOOK_HMAC_KEY, CLOUDAPI_APP_SECRET or GREEN_API_WEBHOOK_TOKEN)")
+	}
+	h.webhook = handlers.NewWebhookHandler(webhookService, onboardingService, webhookBodyReader, webhookSignatures)
+	h.cloudAPIWebhook = handlers.NewCloudAPIWebhookHandler(webhookService, waService, webhookBodyReader, cfg.CloudAPIAppSecret, cfg.CloudAPIVerifyToken, cfg.WebhookAllowUnsigned)
+	h.sandbox = handlers.NewSandboxHandler(sandboxService, webhookService, orderService)
+	h.ocr = handlers.NewOCRHandler(ocrService, llmService, transactionRepo, workflowService, ocrRetentionService)
+	h.workflow = handlers.NewWorkflowHandler(workflowService)
+	h.payment = handlers.NewPaymentHandler(orderService, branchService, paymentEventService)
+	h.paymentEvent = handlers.NewPaymentEventHandler(paymentEventService)
+	h.outboundMessage = handlers.NewOutboundMessageHandler(outboundService)
+	h.region = handlers.NewRegionHandler(regionMigrationService)
+	h.conversationReplay = handlers.NewConversationReplayHandler(conversationReplayService)
+	h.cart = handlers.NewCartHandler(cartService, branchService)
+	h.product = handlers.NewProductHandler(productService, waitlistService)
+	h.store = handlers.NewStoreHandler(storeService, branchService)
+	h.delivery = handlers.NewDeliveryHandler(deliveryService)
+	h.quote = handlers.NewQuoteHandler(quoteService)
+	h.tracking = handlers.NewTrackingHandler(trackingService)
+	h.mobile = handlers.NewMobileHandler(mobileDashboardService)
+	h.orderBoard = handlers.NewOrderBoardHandler(orderBoardService)
+	h.analytics = handlers.NewAnalyticsHandler(productMentionService)
+	h.report = handlers.NewReportHandler(reportService)
+	h.kbSuggestion = handlers.NewKBSuggestionHandler(kbSuggestionService)
+	h.reconciliation = handlers.NewReconciliationHandler(reconciliationService)
+	h.upload = upload.NewHandler(uploadService)
 
 	// Init Fiber app
+	// Bodies over BodyLimit are streamed; only webhook routes accept them (up to WEBHOOK_MAX_BODY_BYTES)
 	app := fiber.New(fiber.Config{
-		AppName: "WhatsApp Bot SaaS API",
+		AppName:           "WhatsApp Bot SaaS API",
+		BodyLimit:         cfg.APIMaxBodyBytes,
+		StreamRequestBody: true,
 	})
 
 	// Middleware
 	app.Use(cors.New())
+	app.Use(handlers.LimitBufferedBody(cfg.APIMaxBodyBytes, func(c *fiber.Ctx) bool {
+		return c.Path() == "/webhook" || strings.HasPrefix(c.Path(), "/webhook/")
+	}))
+
+	// Every route is mounted through the route registry with the access policy it requires
+	routes := handlers.NewRouteRegistry(app)
+	mountRoutes(app, routes, cfg, authService, repositories.NewOwnershipRepo(db.GORM), h)
 
-	// Swagger
-	app.Get("/swagger/*", swagger.HandlerDefault)
-
-	// Health check
-	app.Get("/health", healthHandler.GetHealth)
-
-	// Authentication routes (public - no auth required)
-	authGroup := app.Group("/auth")
-	authGroup.Post("/register", authHandler.Register)
-	authGroup.Post("/login", authHandler.Login)
-	authGroup.Post("/google", authHandler.LoginWithGoogle)
-	authGroup.Post("/refresh", authHandler.RefreshToken)
-
-	// Protected auth routes (require authentication)
-	authGroup.Post("/logout", auth.AuthMiddleware(authService), authHandler.Logout)
-	authGroup.Get("/me", auth.AuthMiddleware(authService), authHandler.Me)
-
-	// Product routes (protected - require authentication)
-	productsGroup := app.Group("/products", auth.AuthMiddleware(authService))
-	productsGroup.Post("/", productHandler.CreateProduct)
-	productsGroup.Get("/", productHandler.ListProducts)
-	productsGroup.Get("/:id", productHandler.GetProduct)
-	productsGroup.Put("/:id", productHandler.UpdateProduct)
-	productsGroup.Delete("/:id", productHandler.DeleteProduct)
-	productsGroup.Patch("/:id/stock", productHandler.UpdateStock)
-	productsGroup.Patch("/:id/toggle", productHandler.ToggleProductStatus)
-
-	// Upload routes (protected - require authentication)
-	uploadGroup := app.Group("/upload", auth.AuthMiddleware(authService))
-	uploadGroup.Post("/", uploadHandler.UploadFile)
-	uploadGroup.Post("/product", uploadHandler.UploadProductImage)
-	uploadGroup.Delete("/", uploadHandler.DeleteFile)
-	uploadGroup.Get("/info", uploadHandler.GetProviderInfo)
-
-	// Static file serving for local uploads
-	app.Static("/uploads", cfg.UploadBasePath)
-
-	// Client routes
-	app.Get("/clients", clientHandler.GetActiveClients)
-	app.Get("/clients/:id", clientHandler.GetClientByID)
-
-	// Knowledge Base routes
-	app.Get("/knowledge-base", kbHandler.GetKnowledgeBase)
-	app.Post("/knowledge-base", kbHandler.AddKnowledgeItem)
-
-	// WhatsApp routes
-	app.Get("/whatsapp/qr", whatsappHandler.GetQRCode)
-	app.Post("/whatsapp/session/start", whatsappHandler.StartSession)
-	app.Post("/whatsapp/session/stop", whatsappHandler.StopSession)
-	app.Post("/whatsapp/session/restart", whatsappHandler.RestartSession)
-	app.Get("/whatsapp/session/status", whatsappHandler.GetSessionStatus)
-	app.Post("/whatsapp/webhook/configure", whatsappHandler.ConfigureWebhook)
-
-	// Webhook route
-	app.Post("/webhook", webhookHandler.ReceiveWebhook)
-
-	// OCR routes
-	app.Post("/ocr/process-receipt", ocrHandler.ProcessReceipt)
-	app.Get("/transactions", ocrHandler.GetTransactions)
-
-	// Workflow routes
-	app.Post("/workflows", workflowHandler.CreateWorkflow)
-	app.Get("/workflows", workflowHandler.ListWorkflows)
-	app.Get("/workflows/:id", workflowHandler.GetWorkflow)
-	app.Put("/workflows/:id", workflowHandler.UpdateWorkflow)
-	app.Delete("/workflows/:id", workflowHandler.DeleteWorkflow)
-	app.Post("/workflows/:id/execute", workflowHandler.ExecuteWorkflow)
-	app.Get("/workflows/:id/executions", workflowHandler.GetWorkflowExecutions)
-
-	// Shopping Cart routes
-	app.Post("/cart/add", cartHandler.AddToCart)
-	app.Put("/cart/update", cartHandler.UpdateCartItem)
-	app.Delete("/cart/remove", cartHandler.RemoveFromCart)
-	app.Get("/cart", cartHandler.ViewCart)
-	app.Delete("/cart/clear", cartHandler.ClearCart)
-	app.Post("/cart/checkout", cartHandler.CheckoutCart)
-
-	// Order/Payment routes
-	app.Post("/orders", paymentHandler.CreateOrder)
-	app.Get("/orders", paymentHandler.ListOrders)
-	app.Get("/orders/customer", paymentHandler.ListCustomerOrders)
-	app.Get("/orders/status/:orderNumber", paymentHandler.GetOrderStatus)
-	app.Get("/orders/:id", paymentHandler.GetOrderByID)
-	app.Put("/orders/:id", paymentHandler.UpdateOrder)
-	app.Post("/orders/:id/confirm-payment", paymentHandler.ManualPaymentConfirm)
-	app.Post("/orders/:id/cancel", paymentHandler.CancelOrder)
-
-	// Payment webhook routes
-	app.Post("/webhooks/midtrans", paymentHandler.MidtransWebhook)
+	if err := routes.Verify(); err != nil {
+		log.Fatalf("❌ Routes mounted without an access policy: %v", err)
+	}
+	log.Printf("🔐 Mounted %d routes with their access policies", len(routes.Routes()))
 
 	// Start server
 	port := cfg.Port
@@ -314,6 +680,154 @@ func main() {
 
 	log.Printf("✅ saas-api running at :%s", port)
 	log.Printf("📄 Swagger UI: http://localhost:%s/swagger/", port)
-	log.Printf("🔗 QR Endpoint: http://localhost:%s/whatsapp/qr", port)
-	log.Fatal(app.Listen(":" + port))
+	log.Printf("🔗 QR Endpoint: http://localhost:%s/v1/whatsapp/qr", port)
+	go func() {
+		if err := app.Listen(":" + port); err != nil {
+			log.Fatalf("❌ Server failed: %v", err)
+		}
+	}()
+
+	// On SIGTERM (deploys) or SIGINT, stop accepting requests and let in-flight work finish
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	sig := <-quit
+	log.Printf("🛑 Received %s, shutting down (timeout %s)...", sig, cfg.ShutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := app.ShutdownWithContext(shutdownCtx); err != nil {
+		log.Printf("⚠️ HTTP server did not stop cleanly: %v", err)
+	}
+	if err := webhookService.Drain(shutdownCtx); err != nil {
+		log.Printf("⚠️ Messages still being processed at shutdown: %v", err)
+	}
+	stopJobs()
+	if err := workflowService.Shutdown(shutdownCtx); err != nil {
+		log.Printf("⚠️ Workflow service did not stop cleanly: %v", err)
+	}
+	stopWorkers(shutdownCtx, jobService)
+
+	log.Println("✅ saas-api stopped")
+}
+
+// stopWorkers drains the job queue workers, letting the jobs in progress (queued WhatsApp sends and
+// notifications, exports) finish; jobs not started yet stay queued for the next instance
+func stopWorkers(ctx context.Context, jobService *jobs.Service) {
+	done := make(chan struct{})
+	go func() {
+		jobService.StopWorkers()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Printf("⚠️ Job workers still running at shutdown: %v", ctx.Err())
+	}
+}
+
+// newCache connects the Redis cache, or returns nil (caching off) when REDIS_URL is empty or Redis is
+// unreachable at startup
+func newCache(cfg *config.Config) *cache.Cache {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+	c, err := cache.New(cfg.RedisURL, cfg.CacheTTL)
+	if err != nil {
+		log.Printf("⚠️ Redis cache disabled: %v", err)
+		return nil
+	}
+	log.Printf("✅ Redis cache enabled (TTL %s)", cfg.CacheTTL)
+	return c
+}
+
+// newVectorRetriever connects the configured vector DB; returns nil (semantic indexing disabled) on failure
+func newVectorRetriever(cfg *config.Config) *kb.VectorRetriever {
+	var provider vector.Provider
+	var err error
+	switch cfg.VectorProvider {
+	case "qdrant_self_hosted":
+		provider, err = vector.NewQdrantSelfHostedProvider(cfg.QdrantSelfHostedHost, cfg.QdrantSelfHostedPort)
+	default:
+		provider, err = vector.NewQdrantCloudProvider(cfg.QdrantCloudURL, cfg.QdrantCloudAPIKey)
+	}
+	if err != nil {
+		log.Printf("⚠️ Vector DB disabled: %v", err)
+		return nil
+	}
+	return initVectorRetriever(cfg, provider)
+}
+
+// initVectorRetriever wraps a vector DB connection in a KB retriever with the configured embedding and cache
+func initVectorRetriever(cfg *config.Config, provider vector.Provider) *kb.VectorRetriever {
+	embedding, err := vector.NewOpenAIEmbeddingProvider(cfg.OpenAIKey, cfg.EmbeddingModel)
+	if err != nil {
+		log.Printf("⚠️ Vector DB disabled: %v", err)
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	vectorService := vector.NewService(provider, embedding)
+	if err := vectorService.Initialize(ctx); err != nil {
+		log.Printf("⚠️ Vector DB disabled: %v", err)
+		return nil
+	}
+
+	retriever := kb.NewVectorRetriever(vectorService, "knowledge_base")
+	if err := retriever.Initialize(ctx); err != nil {
+		log.Printf("⚠️ Vector DB disabled: %v", err)
+		return nil
+	}
+	if cfg.VectorCacheEnabled {
+		retriever.SetSearchCache(kb.NewSearchCache(cfg.VectorCacheTTL, cfg.VectorCacheMaxEntries))
+		log.Printf("✅ Vector search cache enabled (TTL %s, max %d entries)", cfg.VectorCacheTTL, cfg.VectorCacheMaxEntries)
+	}
+	return retriever
+}
+
+// newRegion connects the database, vector DB and storage of a region (vector DB and storage are optional).
+// The database is migrated like the main one.
+func newRegion(cfg *config.Config, rc config.RegionConfig, defaultStorage *upload.Service) *region.Region {
+	regionDB := database.NewDB(rc.DatabaseURL, cfg.DatabasePool())
+	if cfg.AutoMigrate {
+		if err := migrations.Up(context.Background(), regionDB, rc.DatabaseURL); err != nil {
+			log.Fatalf("❌ Auto-migration of region %q failed: %v", rc.Code, err)
+		}
+	}
+
+	r := &region.Region{Code: rc.Code, DB: regionDB, Storage: defaultStorage}
+
+	var provider vector.Provider
+	var err error
+	switch {
+	case rc.QdrantCloudURL != "":
+		provider, err = vector.NewQdrantCloudProvider(rc.QdrantCloudURL, rc.QdrantAPIKey)
+	case rc.QdrantHost != "":
+		provider, err = vector.NewQdrantSelfHostedProvider(rc.QdrantHost, rc.QdrantPort)
+	}
+	if err != nil {
+		log.Printf("⚠️ Vector DB of region %q disabled: %v", rc.Code, err)
+	} else if provider != nil {
+		r.Vector = initVectorRetriever(cfg, provider)
+	}
+
+	if rc.S3BucketName != "" {
+		s3Region := rc.S3Region
+		if s3Region == "" {
+			s3Region = cfg.S3Region
+		}
+		storage, err := upload.NewS3Provider(cfg.S3AccessKeyID, cfg.S3SecretAccessKey, s3Region, rc.S3BucketName)
+		if err != nil {
+			log.Fatalf("❌ Failed to initialize S3 storage of region %q: %v", rc.Code, err)
+		}
+		r.Storage = upload.NewService(storage)
+	} else {
+		log.Printf("⚠️ Region %q has no storage bucket, its uploads use the default region's storage", rc.Code)
+	}
+
+	log.Printf("🌏 Region %q connected (vector DB: %t)", rc.Code, r.Vector != nil)
+	return r
 }
@@ -0,0 +1,505 @@
+package main
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/gofiber/swagger"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/handlers"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
+)
+
+// routeHandlers are the handlers the API routes are mounted with
+type routeHandlers struct {
+	adminProvisioning  *handlers.AdminProvisioningHandler
+	aiSettings         *handlers.AISettingsHandler
+	analytics          *handlers.AnalyticsHandler
+	auth               *auth.Handler
+	billingStatement   *handlers.BillingStatementHandler
+	campaign           *handlers.CampaignHandler
+	cart               *handlers.CartHandler
+	client             *handlers.ClientHandler
+	cloudAPIWebhook    *handlers.CloudAPIWebhookHandler
+	configBundle       *handlers.ConfigBundleHandler
+	conversationReplay *handlers.ConversationReplayHandler
+	conversationTag    *handlers.ConversationTagHandler
+	customField        *handlers.CustomFieldHandler
+	customer           *handlers.CustomerHandler
+	customerPreference *handlers.CustomerPreferenceHandler
+	customerSegment    *handlers.CustomerSegmentHandler
+	delivery           *handlers.DeliveryHandler
+	health             *handlers.HealthHandler
+	kbDocument         *handlers.KBDocumentHandler
+	kb                 *handlers.KBHandler
+	kbSuggestion       *handlers.KBSuggestionHandler
+	kbSync             *handlers.KBSyncHandler
+	language           *handlers.LanguageHandler
+	latency            *handlers.LatencyHandler
+	llmBenchmark       *handlers.LLMBenchmarkHandler
+	messageFeature     *handlers.MessageFeatureHandler
+	migration          *handlers.MigrationHandler
+	mobile             *handlers.MobileHandler
+	ocr                *handlers.OCRHandler
+	offboarding        *handlers.OffboardingHandler
+	onboardingFlow     *handlers.OnboardingFlowHandler
+	onboarding         *handlers.OnboardingHandler
+	orderBoard         *handlers.OrderBoardHandler
+	outboundMessage    *handlers.OutboundMessageHandler
+	paymentEvent       *handlers.PaymentEventHandler
+	payment            *handlers.PaymentHandler
+	paymentReminder    *handlers.PaymentReminderHandler
+	product            *handlers.ProductHandler
+	quote              *handlers.QuoteHandler
+	reaction           *handlers.ReactionHandler
+	recommendation     *handlers.RecommendationHandler
+	reconciliation     *handlers.ReconciliationHandler
+	region             *handlers.RegionHandler
+	report             *handlers.ReportHandler
+	sandbox            *handlers.SandboxHandler
+	sessionBackup      *handlers.WhatsAppSessionBackupHandler
+	sla                *handlers.SLAHandler
+	splitPayment       *handlers.SplitPaymentHandler
+	store              *handlers.StoreHandler
+	subscription       *handlers.SubscriptionHandler
+	tracking           *handlers.TrackingHandler
+	transcript         *handlers.TranscriptHandler
+	upload             *upload.Handler
+	usage              *handlers.UsageHandler
+	vectorIndex        *handlers.VectorIndexHandler
+	wallet             *handlers.WalletHandler
+	webhook            *handlers.WebhookHandler
+	whatsapp           *handlers.WhatsAppHandler
+	whatsappSession    *handlers.WhatsAppSessionHandler
+	workflow           *handlers.WorkflowHandler
+}
+
+// mountRoutes mounts every route of the API through the route registry, each with the access policy it
+// requires. routes_test.go checks that every policy is enforced.
+func mountRoutes(app *fiber.App, routes *handlers.RouteRegistry, cfg *config.Config, authService *auth.Service, ownerRepo repositories.OwnershipRepo, h *routeHandlers) {
+	authMiddleware := auth.AuthMiddleware(authService)
+	authenticated := handlers.NewRoutePolicy("authenticated", authMiddleware)
+	adminKey := handlers.NewRoutePolicy("admin_key", auth.RequireAdminKey(cfg.AdminAPIKey))
+	metricsToken := handlers.NewRoutePolicy("metrics_token", auth.RequireMetricsToken(cfg.MetricsToken))
+
+	// Webhooks, public links, uploads and health checks are external contracts and are not versioned
+	public := handlers.NewUnversionedRouter(app, routes).Require(handlers.PublicRoute)
+
+	// Swagger
+	public.Get("/swagger/*", swagger.HandlerDefault)
+
+	// Health check
+	public.Get("/health", h.health.GetHealth)
+	public.Get("/healthz", h.health.GetHealthz)
+	public.Get("/health/live", h.health.GetLiveness)
+	public.Get("/health/ready", h.health.GetReadiness)
+
+	// Prometheus metrics (Authorization: Bearer METRICS_TOKEN)
+	handlers.NewUnversionedRouter(app, routes).Require(metricsToken).Get("/metrics", metrics.Handler())
+
+	// REST API, served under /v1; the unprefixed legacy paths stay as deprecated aliases until the sunset date
+	versioned := handlers.NewVersionedRouter(app, routes, cfg.LegacyRoutesEnabled, cfg.LegacyRoutesSunset)
+	api := versioned.Require(handlers.PublicRoute)
+
+	// Tenant API (JWT): admins manage their client, staff run its day-to-day operations, super admins may act
+	// for any client. Tenant users only reach their own client's data: the client_id they send must be their
+	// own (and is filled in from the token when missing), and orders and workflows addressed by ID must be
+	// their client's.
+	requireSuperAdmin := auth.RequireRole(auth.RoleSuperAdmin)
+	requireAdmin := auth.RequireRole(auth.RoleSuperAdmin, auth.RoleAdminTenant)
+	requireStaff := auth.RequireRole(auth.RoleSuperAdmin, auth.RoleAdminTenant, auth.RoleStaffTenant)
+	superAdmin := versioned.Require(handlers.NewRoutePolicy(auth.RoleSuperAdmin, authMiddleware, requireSuperAdmin))
+	admin := versioned.Require(handlers.NewRoutePolicy(auth.RoleAdminTenant, authMiddleware, requireAdmin, auth.ScopeTenant()))
+	clientAdmin := versioned.Require(handlers.NewRoutePolicy(auth.RoleAdminTenant, authMiddleware, requireAdmin, auth.ScopeTenantParam("id")))
+	staff := versioned.Require(handlers.NewRoutePolicy(auth.RoleStaffTenant, authMiddleware, requireStaff, auth.ScopeTenant()))
+	ownOrder := handlers.OwnedBy(ownerRepo, models.Order{}.TableName(), "id", "id")
+	ownOrderNumber := handlers.OwnedBy(ownerRepo, models.Order{}.TableName(), "order_number", "orderNumber")
+	ownWorkflow := handlers.OwnedBy(ownerRepo, models.Workflow{}.TableName(), "id", "id")
+
+	// Platform admin routes (X-Admin-Key)
+	adminGroup := versioned.Group("/admin").Require(adminKey)
+	adminGroup.Get("/migrations", h.migration.GetMigrations)
+	adminGroup.Post("/clients/:id/deactivate", h.offboarding.DeactivateClient)
+	adminGroup.Get("/clients/:id/offboarding", h.offboarding.GetOffboardingStatus)
+	adminGroup.Get("/regions", h.region.GetRegions)
+	adminGroup.Post("/clients/:id/region", h.region.MigrateClientRegion)
+	adminGroup.Get("/clients/:id/region/migrations", h.region.GetClientRegionMigrations)
+	adminGroup.Post("/clients/:id/conversation-replays", h.conversationReplay.StartConversationReplay)
+	adminGroup.Get("/clients/:id/conversation-replays", h.conversationReplay.GetConversationReplays)
+	adminGroup.Get("/conversation-replays/:id", h.conversationReplay.GetConversationReplay)
+	adminGroup.Get("/vector/indexes", h.vectorIndex.GetIndexes)
+	adminGroup.Post("/vector/indexes", h.vectorIndex.CreateIndexes)
+	adminGroup.Get("/vector/cache", h.vectorIndex.GetCacheStats)
+	adminGroup.Post("/llm/benchmark", h.llmBenchmark.RunBenchmark)
+	adminGroup.Get("/llm/benchmarks", h.llmBenchmark.GetBenchmarks)
+	adminGroup.Get("/payment-events", h.paymentEvent.ListPaymentEvents)
+	adminGroup.Post("/payment-events/:id/replay", h.paymentEvent.ReplayPaymentEvent)
+	adminGroup.Get("/outbound-messages", h.outboundMessage.ListOutboundMessages)
+	adminGroup.Post("/outbound-messages/:id/retry", h.outboundMessage.RetryOutboundMessage)
+	adminGroup.Get("/billing/statements", h.billingStatement.ListAllStatements)
+	adminGroup.Post("/billing/statements/generate", h.billingStatement.GenerateStatements)
+	adminGroup.Post("/billing/statements/:id/mark-paid", h.billingStatement.MarkStatementPaid)
+	adminGroup.Post("/billing/statements/:id/void", h.billingStatement.VoidStatement)
+	adminGroup.Post("/clients/:id/ai-credits", h.usage.GrantAICredits)
+	adminGroup.Get("/plans", h.subscription.ListPlanDefinitions)
+	adminGroup.Put("/plans/:code", h.subscription.SavePlan)
+	adminGroup.Get("/subscriptions", h.subscription.ListSubscriptions)
+	adminGroup.Get("/whatsapp/session-backups", h.sessionBackup.ListSessionBackups)
+	adminGroup.Post("/whatsapp/session-backups", h.sessionBackup.BackupSessions)
+	adminGroup.Post("/whatsapp/session-backups/restore", h.sessionBackup.RestoreSessions)
+	adminGroup.Get("/routes", handlers.NewRouteHandler(routes).GetRoutes)
+
+	// Stable provisioning API for IaC tools (X-Admin-Key); resources are addressed by the caller's reference IDs
+	v1Admin := adminGroup.V1()
+	v1Admin.Get("/clients/:ref", h.adminProvisioning.GetClient)
+	v1Admin.Put("/clients/:ref", h.adminProvisioning.PutClient)
+	v1Admin.Patch("/clients/:ref", h.adminProvisioning.PatchClient)
+	v1Admin.Get("/clients/:ref/users/:userRef", h.adminProvisioning.GetUser)
+	v1Admin.Put("/clients/:ref/users/:userRef", h.adminProvisioning.PutUser)
+	v1Admin.Patch("/clients/:ref/users/:userRef", h.adminProvisioning.PatchUser)
+	v1Admin.Get("/clients/:ref/api-keys/:keyRef", h.adminProvisioning.GetAPIKey)
+	v1Admin.Put("/clients/:ref/api-keys/:keyRef", h.adminProvisioning.PutAPIKey)
+	v1Admin.Patch("/clients/:ref/api-keys/:keyRef", h.adminProvisioning.PatchAPIKey)
+	v1Admin.Delete("/clients/:ref/api-keys/:keyRef", h.adminProvisioning.RevokeAPIKey)
+	v1Admin.Get("/clients/:ref/whatsapp", h.adminProvisioning.GetWhatsApp)
+	v1Admin.Put("/clients/:ref/whatsapp", h.adminProvisioning.PutWhatsApp)
+
+	// Authentication routes (public - no auth required)
+	authGroup := api.Group("/auth")
+	authGroup.Post("/register", h.auth.Register)
+	authGroup.Post("/login", h.auth.Login)
+	authGroup.Post("/google", h.auth.LoginWithGoogle)
+	authGroup.Post("/refresh", h.auth.RefreshToken)
+
+	// Protected auth routes (require authentication)
+	authGroup.Require(authenticated).Post("/logout", h.auth.Logout)
+	authGroup.Require(authenticated).Get("/me", h.auth.Me)
+
+	// Product routes (protected - require authentication)
+	productsGroup := versioned.Group("/products").Require(authenticated)
+	productsGroup.Post("/", h.product.CreateProduct)
+	productsGroup.Get("/", h.product.ListProducts)
+	productsGroup.Post("/import", h.product.ImportProducts)
+	productsGroup.Get("/:id", h.product.GetProduct)
+	productsGroup.Put("/:id", h.product.UpdateProduct)
+	productsGroup.Delete("/:id", h.product.DeleteProduct)
+	productsGroup.Patch("/:id/stock", h.product.UpdateStock)
+	productsGroup.Patch("/:id/toggle", h.product.ToggleProductStatus)
+	productsGroup.Post("/:id/image", h.product.UploadProductImage)
+	productsGroup.Delete("/:id/image", h.product.RemoveProductImage)
+	productsGroup.Get("/:id/branch-stock", h.store.GetProductBranchStock)
+	productsGroup.Get("/:id/waitlist", h.product.GetWaitlist)
+	productsGroup.Get("/:id/custom-fields", h.customField.GetProductCustomFields)
+	productsGroup.Put("/:id/custom-fields", h.customField.SetProductCustomFields)
+
+	// Store routes (protected - require authentication)
+	storesGroup := versioned.Group("/stores").Require(authenticated)
+	storesGroup.Post("/", h.store.CreateStore)
+	storesGroup.Get("/", h.store.ListStores)
+	storesGroup.Get("/nearest", h.store.FindNearest)
+	storesGroup.Get("/:id", h.store.GetStore)
+	storesGroup.Put("/:id", h.store.UpdateStore)
+	storesGroup.Delete("/:id", h.store.DeleteStore)
+	storesGroup.Get("/:id/stock", h.store.ListBranchStock)
+	storesGroup.Put("/:id/stock", h.store.SetBranchStock)
+
+	// Mobile dashboard routes (protected, ETag-cached compact payloads)
+	mobileGroup := versioned.Group("/m", etag.New()).Require(authenticated)
+	mobileGroup.Get("/dashboard", h.mobile.GetDashboard)
+	mobileGroup.Post("/orders/:id/confirm-payment", h.mobile.ConfirmPayment)
+	mobileGroup.Post("/orders/:id/cancel", h.mobile.CancelOrder)
+
+	// Upload routes (protected - require authentication)
+	uploadGroup := versioned.Group("/upload").Require(authenticated)
+	uploadGroup.Post("/", h.upload.UploadFile)
+	uploadGroup.Post("/product", h.upload.UploadProductImage)
+	uploadGroup.Delete("/", h.upload.DeleteFile)
+	uploadGroup.Get("/info", h.upload.GetProviderInfo)
+
+	// Static file serving for local uploads
+	routes.Static("/uploads", cfg.UploadBasePath)
+
+	// Client routes
+	superAdmin.Get("/clients", h.client.GetActiveClients)
+	clientAdmin.Get("/clients/:id", h.client.GetClientByID)
+	clientAdmin.Get("/clients/:id/config/export", h.configBundle.ExportConfig)
+	clientAdmin.Post("/clients/:id/config/import", h.configBundle.ImportConfig)
+	clientAdmin.Get("/clients/:id/ai-settings", h.aiSettings.GetAISettings)
+	clientAdmin.Put("/clients/:id/ai-settings", h.aiSettings.UpdateAISettings)
+
+	// Per-client WhatsApp session routes (session named after the client ID)
+	clientAdmin.Post("/clients/:id/whatsapp/session", h.whatsappSession.StartClientSession)
+	clientAdmin.Get("/clients/:id/whatsapp/session", h.whatsappSession.GetClientSession)
+	clientAdmin.Delete("/clients/:id/whatsapp/session", h.whatsappSession.DeleteClientSession)
+	clientAdmin.Get("/clients/:id/whatsapp/session/qr", h.whatsappSession.GetClientSessionQR)
+	clientAdmin.Post("/clients/:id/whatsapp/session/restart", h.whatsappSession.RestartClientSession)
+	clientAdmin.Post("/clients/:id/whatsapp/session/stop", h.whatsappSession.StopClientSession)
+
+	// Knowledge Base routes
+	staff.Get("/knowledge-base", h.kb.GetKnowledgeBase)
+	admin.Post("/knowledge-base", h.kb.AddKnowledgeItem)
+	admin.Delete("/knowledge-base", h.kb.DeleteKnowledgeBase)
+	admin.Post("/knowledge-base/import", h.kb.ImportKnowledgeBase)
+	staff.Get("/knowledge-base/duplicates", h.kb.ListKBDuplicates)
+	admin.Post("/knowledge-base/duplicates/:id/merge", h.kb.MergeKBDuplicate)
+	admin.Post("/knowledge-base/duplicates/:id/dismiss", h.kb.DismissKBDuplicate)
+	staff.Get("/knowledge-base/documents", h.kbDocument.ListDocuments)
+	admin.Post("/knowledge-base/documents", h.kbDocument.UploadDocument)
+	admin.Delete("/knowledge-base/documents/:id", h.kbDocument.DeleteDocument)
+	admin.Post("/knowledge-base/sync", h.kbSync.SyncKnowledgeBase)
+
+	// KB suggestion routes (FAQ drafts queued for admin approval)
+	staff.Get("/kb/suggestions", h.kbSuggestion.ListSuggestions)
+	admin.Post("/kb/suggestions/generate", h.kbSuggestion.GenerateSuggestions)
+	admin.Post("/kb/suggestions/:id/accept", h.kbSuggestion.AcceptSuggestion)
+	admin.Post("/kb/suggestions/:id/reject", h.kbSuggestion.RejectSuggestion)
+	staff.Post("/conversations/:id/rating", h.kbSuggestion.RateConversation)
+
+	// Conversation list and tags
+	staff.Get("/conversations", h.conversationTag.ListConversations)
+	staff.Get("/conversations/:phone/tags", h.conversationTag.GetConversationTags)
+	staff.Post("/conversations/:phone/tags", h.conversationTag.TagConversation)
+	staff.Delete("/conversations/:phone/tags/:tag", h.conversationTag.UntagConversation)
+	staff.Post("/conversations/:phone/export", h.transcript.ExportTranscript)
+	staff.Get("/conversations/:phone/exports", h.transcript.ListTranscriptExports)
+	staff.Get("/transcript-exports/:id", h.transcript.GetTranscriptExport)
+	staff.Get("/conversation-tags", h.conversationTag.ListTags)
+	admin.Post("/conversation-tags", h.conversationTag.CreateTag)
+	admin.Put("/conversation-tags/:id", h.conversationTag.UpdateTag)
+	admin.Delete("/conversation-tags/:id", h.conversationTag.DeleteTag)
+
+	// Broadcast campaigns
+	staff.Get("/campaigns", h.campaign.ListCampaigns)
+	admin.Post("/campaigns", h.campaign.CreateCampaign)
+	admin.Post("/campaigns/audience", h.campaign.PreviewCampaignAudience)
+	staff.Get("/campaigns/:id", h.campaign.GetCampaign)
+	admin.Put("/campaigns/:id", h.campaign.UpdateCampaign)
+	admin.Post("/campaigns/:id/schedule", h.campaign.ScheduleCampaign)
+	admin.Post("/campaigns/:id/cancel", h.campaign.CancelCampaign)
+	staff.Get("/campaigns/:id/recipients", h.campaign.ListCampaignRecipients)
+
+	// Customers (CRM)
+	staff.Get("/customers", h.customer.ListCustomers)
+	staff.Get("/customers/:phone", h.customer.GetCustomer)
+	staff.Put("/customers/:phone", h.customer.UpdateCustomer)
+	staff.Post("/customers/:phone/tags", h.conversationTag.TagConversation)
+	staff.Delete("/customers/:phone/tags/:tag", h.conversationTag.UntagConversation)
+
+	// Customer segments
+	staff.Get("/customer-segments", h.customerSegment.ListSegments)
+	admin.Post("/customer-segments", h.customerSegment.CreateSegment)
+	admin.Post("/customer-segments/preview", h.customerSegment.PreviewSegment)
+	staff.Get("/customer-segments/:id", h.customerSegment.GetSegment)
+	admin.Put("/customer-segments/:id", h.customerSegment.UpdateSegment)
+	admin.Delete("/customer-segments/:id", h.customerSegment.DeleteSegment)
+
+	// Custom fields
+	staff.Get("/custom-fields", h.customField.ListCustomFields)
+	admin.Post("/custom-fields", h.customField.CreateCustomField)
+	staff.Get("/custom-fields/export", h.customField.ExportCustomFields)
+	admin.Put("/custom-fields/:id", h.customField.UpdateCustomField)
+	admin.Delete("/custom-fields/:id", h.customField.DeleteCustomField)
+	staff.Get("/customers/:phone/custom-fields", h.customField.GetCustomerCustomFields)
+	staff.Put("/customers/:phone/custom-fields", h.customField.SetCustomerCustomFields)
+	staff.Get("/customers/:phone/preferences", h.customerPreference.GetCustomerPreferences)
+	staff.Put("/customers/:phone/preferences", h.customerPreference.UpdateCustomerPreferences)
+	staff.Delete("/customers/:phone/preferences", h.customerPreference.DeleteCustomerPreferences)
+
+	// WhatsApp routes
+	superAdmin.Get("/whatsapp/qr", h.whatsapp.GetQRCode)
+	superAdmin.Post("/whatsapp/session/start", h.whatsapp.StartSession)
+	superAdmin.Post("/whatsapp/session/stop", h.whatsapp.StopSession)
+	superAdmin.Post("/whatsapp/session/restart", h.whatsapp.RestartSession)
+	superAdmin.Get("/whatsapp/session/status", h.whatsapp.GetSessionStatus)
+	superAdmin.Post("/whatsapp/webhook/configure", h.whatsapp.ConfigureWebhook)
+
+	// Onboarding routes
+	clientAdmin.Post("/onboarding/:id/whatsapp", h.onboarding.ProvisionWhatsApp)
+	clientAdmin.Post("/onboarding/:id/whatsapp/self-test", h.onboarding.RunSelfTest)
+	clientAdmin.Get("/onboarding/:id/status", h.onboarding.GetStatus)
+
+	// First-contact flow for new customers
+	staff.Get("/onboarding-flow", h.onboardingFlow.GetOnboardingFlow)
+	admin.Put("/onboarding-flow", h.onboardingFlow.UpdateOnboardingFlow)
+	admin.Delete("/onboarding-flow/customers/:phone", h.onboardingFlow.ResetCustomerOnboarding)
+
+	// Customer reactions (emoji -> intent)
+	staff.Get("/reaction-settings", h.reaction.GetReactionSettings)
+	admin.Put("/reaction-settings", h.reaction.UpdateReactionSettings)
+
+	// Product recommendations in chat
+	staff.Get("/recommendation-settings", h.recommendation.GetRecommendationSettings)
+	admin.Put("/recommendation-settings", h.recommendation.UpdateRecommendationSettings)
+
+	// Message type toggles (text, image OCR, voice, location, groups)
+	staff.Get("/message-features", h.messageFeature.GetMessageFeatureSettings)
+	admin.Put("/message-features", h.messageFeature.UpdateMessageFeatureSettings)
+
+	// Prepaid wallet routes (customer credit, ledger and manual adjustments)
+	staff.Get("/wallet-settings", h.wallet.GetWalletSettings)
+	admin.Put("/wallet-settings", h.wallet.UpdateWalletSettings)
+	staff.Get("/wallets", h.wallet.ListWallets)
+	staff.Post("/wallets/top-up", h.wallet.TopUpWallet)
+	admin.Post("/wallets/top-ups/:reference/confirm", h.wallet.ConfirmWalletTopUp)
+	staff.Get("/wallets/:phone", h.wallet.GetWallet)
+	admin.Post("/wallets/:phone/adjustments", h.wallet.AdjustWallet)
+	staff.Get("/recommendations/stats", h.recommendation.GetRecommendationStats)
+
+	// Reply language matching
+	staff.Get("/language-settings", h.language.GetLanguageSettings)
+	admin.Put("/language-settings", h.language.UpdateLanguageSettings)
+
+	// Reply latency budget (interim message, hard timeout, FAQ/handover fallback)
+	staff.Get("/latency-settings", h.latency.GetLatencySettings)
+	admin.Put("/latency-settings", h.latency.UpdateLatencySettings)
+
+	// Subscription routes (plan catalog and self-service plan changes)
+	staff.Get("/plans", h.subscription.ListPlans)
+	admin.Post("/subscription/change", h.subscription.ChangePlan)
+	admin.Get("/usage", h.usage.GetUsage)
+	admin.Get("/billing/statements", h.billingStatement.ListStatements)
+	admin.Get("/billing/statements/:id", h.billingStatement.GetStatement)
+
+	// SLA routes (targets, agent responses, thread resolution)
+	staff.Get("/sla/settings", h.sla.GetSLASettings)
+	admin.Put("/sla/settings", h.sla.UpdateSLASettings)
+	staff.Post("/sla/responses", h.sla.RecordAgentResponse)
+	staff.Post("/sla/resolve", h.sla.ResolveThread)
+
+	// Sandbox (test mode) routes
+	admin.Put("/sandbox/mode", h.sandbox.SetMode)
+	admin.Post("/sandbox/messages", h.sandbox.SendMessage)
+	admin.Get("/sandbox/messages", h.sandbox.ListMessages)
+	admin.Delete("/sandbox/messages", h.sandbox.ClearMessages)
+	admin.Post("/sandbox/orders/:id/settle", ownOrder, h.sandbox.SettlePayment)
+
+	// Webhook routes
+	public.Post("/webhook", h.webhook.ReceiveWebhook)
+	handlers.NewUnversionedRouter(app, routes).Require(metricsToken).Get("/webhook/metrics", h.webhook.GetPayloadMetrics)
+	public.Post("/webhook/:token", h.webhook.ReceiveTenantWebhook)
+
+	// WhatsApp Cloud API (Meta) webhook: GET answers the subscription check, POST receives notifications
+	public.Get("/webhooks/whatsapp-cloud", h.cloudAPIWebhook.VerifyWebhook)
+	public.Post("/webhooks/whatsapp-cloud", h.cloudAPIWebhook.ReceiveWebhook)
+
+	// OCR routes
+	staff.Post("/ocr/process-receipt", h.ocr.ProcessReceipt)
+	staff.Get("/transactions", h.ocr.GetTransactions)
+	staff.Get("/transactions/ocr-retention", h.ocr.GetOCRRetention)
+	admin.Put("/transactions/ocr-retention", h.ocr.UpdateOCRRetention)
+	admin.Delete("/transactions/raw-text", h.ocr.PurgeRawText)
+	admin.Delete("/transactions/:id/raw-text", h.ocr.PurgeTransactionRawText)
+
+	// Workflow routes
+	admin.Post("/workflows", h.workflow.CreateWorkflow)
+	staff.Get("/workflows", h.workflow.ListWorkflows)
+	admin.Post("/workflows/bulk", h.workflow.BulkUpdateWorkflows)
+	admin.Post("/workflows/from-template/:templateID", h.workflow.CreateWorkflowFromTemplate)
+	staff.Get("/workflow-templates", h.workflow.ListWorkflowTemplates)
+	staff.Get("/workflows/kill-switch", h.workflow.GetKillSwitch)
+	admin.Post("/workflows/kill-switch", h.workflow.SetKillSwitch)
+	staff.Get("/workflows/:id", ownWorkflow, h.workflow.GetWorkflow)
+	admin.Put("/workflows/:id", ownWorkflow, h.workflow.UpdateWorkflow)
+	admin.Delete("/workflows/:id", ownWorkflow, h.workflow.DeleteWorkflow)
+	admin.Post("/workflows/:id/execute", ownWorkflow, h.workflow.ExecuteWorkflow)
+	staff.Get("/workflows/:id/executions", ownWorkflow, h.workflow.GetWorkflowExecutions)
+	staff.Get("/workflows/:id/executions/export", ownWorkflow, h.workflow.ExportWorkflowExecutions)
+	admin.Post("/workflows/:id/executions/:execID/retry", ownWorkflow, h.workflow.RetryWorkflowExecution)
+	api.Post("/workflows/:id/webhook", h.workflow.ReceiveWorkflowWebhook)
+	admin.Post("/workflows/:id/webhook/rotate", ownWorkflow, h.workflow.RotateWorkflowWebhookToken)
+	staff.Get("/workflows/:id/stats", ownWorkflow, h.workflow.GetWorkflowStats)
+
+	// Shopping Cart routes
+	staff.Post("/cart/add", h.cart.AddToCart)
+	staff.Put("/cart/update", h.cart.UpdateCartItem)
+	staff.Delete("/cart/remove", h.cart.RemoveFromCart)
+	staff.Get("/cart", h.cart.ViewCart)
+	staff.Put("/cart/branch", h.cart.SelectBranch)
+	staff.Delete("/cart/clear", h.cart.ClearCart)
+	staff.Post("/cart/checkout", h.cart.CheckoutCart)
+
+	// Order/Payment routes
+	staff.Post("/orders", h.payment.CreateOrder)
+	staff.Get("/orders", h.payment.ListOrders)
+	staff.Get("/orders/customer", h.payment.ListCustomerOrders)
+	staff.Get("/orders/analytics", h.payment.GetSalesAnalytics)
+	staff.Get("/orders/board", h.orderBoard.GetBoard)
+	staff.Get("/orders/board/stream", h.orderBoard.StreamBoard)
+	staff.Get("/analytics/product-demand", h.analytics.GetProductDemand)
+	staff.Get("/analytics/languages", h.language.GetLanguageReport)
+	staff.Get("/analytics/sla", h.sla.GetSLAReport)
+	staff.Get("/analytics/payment-reminders", h.paymentReminder.GetPaymentReminderStats)
+
+	// Report routes (date-range aggregates for tenant dashboards)
+	staff.Get("/reports/sales", h.report.GetSalesReport)
+	staff.Get("/reports/top-products", h.report.GetTopProducts)
+	staff.Get("/reports/conversations", h.report.GetConversationReport)
+
+	// Payment reconciliation routes
+	admin.Post("/reconciliation/settlements", h.reconciliation.ImportSettlements)
+	staff.Get("/reconciliation/:date", h.reconciliation.GetReconciliation)
+	staff.Get("/orders/risk-rules", h.payment.GetRiskRules)
+	admin.Put("/orders/risk-rules", h.payment.UpdateRiskRules)
+	staff.Get("/orders/payment-routing", h.payment.GetPaymentRouting)
+	admin.Put("/orders/payment-routing", h.payment.UpdatePaymentRouting)
+	staff.Get("/orders/cod-settings", h.payment.GetCODSettings)
+	admin.Put("/orders/cod-settings", h.payment.UpdateCODSettings)
+	staff.Get("/orders/payment-reminders", h.paymentReminder.GetPaymentReminderSettings)
+	admin.Put("/orders/payment-reminders", h.paymentReminder.UpdatePaymentReminderSettings)
+	staff.Get("/orders/status/:orderNumber", ownOrderNumber, h.payment.GetOrderStatus)
+	staff.Get("/orders/:id", ownOrder, h.payment.GetOrderByID)
+	staff.Put("/orders/:id", ownOrder, h.payment.UpdateOrder)
+	staff.Get("/orders/:id/custom-fields", ownOrder, h.customField.GetOrderCustomFields)
+	staff.Put("/orders/:id/custom-fields", ownOrder, h.customField.SetOrderCustomFields)
+	staff.Post("/orders/:id/confirm-payment", ownOrder, h.payment.ManualPaymentConfirm)
+	admin.Post("/orders/:id/refund", ownOrder, h.payment.RefundOrder)
+	staff.Get("/orders/:id/refunds", ownOrder, h.payment.ListOrderRefunds)
+	staff.Post("/orders/:id/split", ownOrder, h.splitPayment.SplitOrder)
+	staff.Get("/orders/:id/split", ownOrder, h.splitPayment.GetSplitPayments)
+	staff.Post("/orders/:id/split/:payment_id/confirm-payment", ownOrder, h.splitPayment.ConfirmSplitPayment)
+	staff.Post("/orders/:id/cancel", ownOrder, h.payment.CancelOrder)
+	staff.Get("/orders/:id/payment-reminders", ownOrder, h.paymentReminder.GetOrderPaymentReminders)
+	staff.Post("/orders/:id/stage", ownOrder, h.orderBoard.MoveOrder)
+	staff.Put("/orders/:id/fulfillment", ownOrder, h.payment.UpdateFulfillment)
+	admin.Post("/orders/:id/review", ownOrder, h.payment.ReviewOrder)
+	staff.Post("/orders/:id/cod/confirm-cash", ownOrder, h.payment.ConfirmCODCash)
+	staff.Post("/orders/:id/assign-driver", ownOrder, h.delivery.AssignDriver)
+
+	// Delivery routes (drivers and shipments)
+	admin.Post("/drivers", h.delivery.RegisterDriver)
+	staff.Get("/drivers", h.delivery.ListDrivers)
+	admin.Put("/drivers/:id", h.delivery.UpdateDriver)
+	staff.Get("/shipments", h.delivery.ListShipments)
+	staff.Put("/shipments/:id/status", h.delivery.UpdateShipmentStatus)
+
+	// Quote routes
+	staff.Post("/quotes", h.quote.CreateQuote)
+	staff.Get("/quotes", h.quote.ListQuotes)
+	staff.Get("/quotes/stats", h.quote.GetQuoteStats)
+	staff.Get("/quotes/:id", h.quote.GetQuote)
+	staff.Post("/quotes/:id/send", h.quote.SendQuote)
+	staff.Get("/quotes/:id/pdf", h.quote.DownloadQuotePDF)
+	staff.Post("/quotes/:id/accept", h.quote.AcceptQuote)
+	staff.Post("/quotes/:id/reject", h.quote.RejectQuote)
+
+	// Customer quote links (public, authorized by the quote token)
+	public.Get("/q/:token", h.quote.ViewPublicQuote)
+	public.Get("/q/:token/pdf", h.quote.DownloadPublicQuotePDF)
+	public.Post("/q/:token/accept", h.quote.AcceptPublicQuote)
+
+	// Public order tracking (short links in order messages, rate-limited per IP)
+	public.Get("/t/:token", limiter.New(limiter.Config{
+		Max:        30,
+		Expiration: time.Minute,
+	}), h.tracking.TrackOrder)
+
+	// Payment webhook routes
+	public.Post("/webhooks/midtrans", h.payment.MidtransWebhook)
+}
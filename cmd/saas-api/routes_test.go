@@ -0,0 +1,230 @@
+package main
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/handlers"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
+)
+
+const (
+	testJWTSecret    = "route-test-secret"
+	testAdminKey     = "route-test-admin-key"
+	testMetricsToken = "route-test-metrics-token"
+)
+
+// testClientID owns every row the routes address, so tenant users of another client must be turned away
+var testClientID = uuid.MustParse("8f1c2a4e-3b5d-4c6e-9f7a-0b1c2d3e4f5a")
+
+// ownedPrefixes are the routes addressing an order or workflow by its own ID, checked against its owner
+var ownedPrefixes = []string{"/orders/:id", "/orders/status/:orderNumber", "/sandbox/orders/:id", "/workflows/:id"}
+
+type stubOwnershipRepo struct{}
+
+func (stubOwnershipRepo) ClientOf(table, column, value string) (uuid.UUID, error) {
+	return testClientID, nil
+}
+
+// routeCaller is who a request is sent as
+type routeCaller struct {
+	name    string
+	headers map[string]string
+	query   string
+}
+
+// TestRoutePolicies calls every route as each kind of caller and checks that only the callers its policy
+// admits reach the handler. Handlers are stubbed to answer 418, so nothing behind them runs.
+func TestRoutePolicies(t *testing.T) {
+	app := fiber.New()
+	routes := handlers.NewRouteRegistry(app)
+	routes.StubHandlers(func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusTeapot)
+	})
+	cfg := &config.Config{
+		AdminAPIKey:         testAdminKey,
+		MetricsToken:        testMetricsToken,
+		LegacyRoutesEnabled: true,
+		LegacyRoutesSunset:  time.Now().AddDate(0, 6, 0),
+		UploadBasePath:      t.TempDir(),
+	}
+	mountRoutes(app, routes, cfg, auth.NewService(nil, testJWTSecret), stubOwnershipRepo{}, &routeHandlers{})
+
+	if err := routes.Verify(); err != nil {
+		t.Fatal(err)
+	}
+
+	client := testClientID.String()
+	other := uuid.NewString()
+	bearer := func(role, clientID string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + testToken(t, role, clientID)}
+	}
+
+	anonymous := routeCaller{name: "anonymous"}
+	wrongAdminKey := routeCaller{name: "wrong admin key", headers: map[string]string{"X-Admin-Key": "wrong"}}
+	adminKey := routeCaller{name: "admin key", headers: map[string]string{"X-Admin-Key": testAdminKey}}
+	wrongMetricsToken := routeCaller{name: "wrong metrics token", headers: map[string]string{"Authorization": "Bearer wrong"}}
+	metricsToken := routeCaller{name: "metrics token", headers: map[string]string{"Authorization": "Bearer " + testMetricsToken}}
+	superAdmin := routeCaller{name: "super admin", headers: bearer(auth.RoleSuperAdmin, "")}
+	admin := routeCaller{name: "admin", headers: bearer(auth.RoleAdminTenant, client), query: "client_id=" + client}
+	staff := routeCaller{name: "staff", headers: bearer(auth.RoleStaffTenant, client), query: "client_id=" + client}
+	unbound := routeCaller{name: "staff without client", headers: bearer(auth.RoleStaffTenant, "")}
+	otherAdmin := routeCaller{name: "other client's admin", headers: bearer(auth.RoleAdminTenant, other), query: "client_id=" + client}
+	otherStaff := routeCaller{name: "other client's staff", headers: bearer(auth.RoleStaffTenant, other), query: "client_id=" + client}
+	otherOwner := routeCaller{name: "other client's admin on its own client", headers: bearer(auth.RoleAdminTenant, other)}
+
+	rejected := []int{fiber.StatusUnauthorized, fiber.StatusForbidden}
+	forbidden := []int{fiber.StatusForbidden}
+	admitted := []int{fiber.StatusTeapot}
+
+	// What each policy answers each caller
+	expectations := map[string][]struct {
+		caller routeCaller
+		status []int
+	}{
+		handlers.PublicRoute.Name: {
+			{anonymous, admitted},
+		},
+		"authenticated": {
+			{anonymous, rejected},
+			{adminKey, rejected},
+			{staff, admitted},
+		},
+		"admin_key": {
+			{anonymous, rejected},
+			{wrongAdminKey, rejected},
+			{superAdmin, rejected},
+			{adminKey, admitted},
+		},
+		"metrics_token": {
+			{anonymous, rejected},
+			{wrongMetricsToken, rejected},
+			{superAdmin, rejected},
+			{metricsToken, admitted},
+		},
+		auth.RoleSuperAdmin: {
+			{anonymous, rejected},
+			{adminKey, rejected},
+			{staff, forbidden},
+			{admin, forbidden},
+			{superAdmin, admitted},
+		},
+		auth.RoleAdminTenant: {
+			{anonymous, rejected},
+			{adminKey, rejected},
+			{staff, forbidden},
+			{otherAdmin, forbidden},
+			{admin, admitted},
+			{superAdmin, admitted},
+		},
+		auth.RoleStaffTenant: {
+			{anonymous, rejected},
+			{adminKey, rejected},
+			{unbound, forbidden},
+			{otherStaff, forbidden},
+			{otherAdmin, forbidden},
+			{staff, admitted},
+			{admin, admitted},
+			{superAdmin, admitted},
+		},
+	}
+
+	for _, route := range routes.Routes() {
+		if route.Path == "/uploads" {
+			continue // Static files
+		}
+		cases, ok := expectations[route.Policy]
+		if !ok {
+			t.Errorf("%s %s declares policy %q, which this test does not cover", route.Method, route.Path, route.Policy)
+			continue
+		}
+		if isTenantPolicy(route.Policy) && isOwnedRoute(route.Path) {
+			cases = append(cases, struct {
+				caller routeCaller
+				status []int
+			}{otherOwner, []int{fiber.StatusNotFound}})
+		}
+
+		path := fillRoutePath(route.Path, client)
+		for _, tc := range cases {
+			if status := callRoute(t, app, route.Method, path, tc.caller); !containsStatus(tc.status, status) {
+				t.Errorf("%s %s (%s) as %s: got %d, want one of %v", route.Method, route.Path, route.Policy, tc.caller.name, status, tc.status)
+			}
+		}
+	}
+}
+
+func testToken(t *testing.T, role, clientID string) string {
+	t.Helper()
+	token, _, err := auth.NewJWTService(testJWTSecret).GenerateAccessToken(&auth.TokenClaims{
+		UserID:   uuid.NewString(),
+		Email:    role + "@example.com",
+		Role:     role,
+		ClientID: clientID,
+	})
+	if err != nil {
+		t.Fatalf("failed to generate %s token: %v", role, err)
+	}
+	return token
+}
+
+func callRoute(t *testing.T, app *fiber.App, method, path string, caller routeCaller) int {
+	t.Helper()
+	if caller.query != "" {
+		path += "?" + caller.query
+	}
+	req := httptest.NewRequest(method, path, nil)
+	for name, value := range caller.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode
+}
+
+// fillRoutePath fills the route's parameters with value and its wildcards with a placeholder
+func fillRoutePath(path, value string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":"):
+			segments[i] = value
+		case strings.ContainsAny(segment, "*+"):
+			segments[i] = "x"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+func isTenantPolicy(policy string) bool {
+	return policy == auth.RoleAdminTenant || policy == auth.RoleStaffTenant
+}
+
+func isOwnedRoute(path string) bool {
+	path = strings.TrimPrefix(path, "/"+handlers.APIVersion)
+	for _, prefix := range ownedPrefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStatus(statuses []int, status int) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
@@ -3,15 +3,18 @@ package agent
 
 import (
 	"context"
+	"errors"
 	"log"
 	"sync"
 	"time"
 
 	"go.mau.fi/whatsmeow/types/events"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
 )
 
@@ -21,6 +24,7 @@ type Engine struct {
 	kbRetriever     *kb.Retriever
 	tenantResolver  *tenant.Resolver
 	conversationLog ConversationLogger
+	sessionID       string // Session the messages are received on, for tenant resolution
 	lastMessageTime map[string]time.Time
 	messageMutex    sync.Mutex
 }
@@ -63,6 +67,11 @@ func (e *Engine) cleanupOldRateLimitEntries(olderThan time.Duration) {
 	}
 }
 
+// SetSessionID sets the WhatsApp session messages are received on; tenants are resolved from it
+func (e *Engine) SetSessionID(sessionID string) {
+	e.sessionID = sessionID
+}
+
 // HandleMessage adalah entry point untuk semua pesan masuk
 // Menerima interface{} untuk support multi-provider (whatsmeow, greenapi, waha)
 func (e *Engine) HandleMessage(evt interface{}) {
@@ -124,11 +133,18 @@ func (e *Engine) HandleMessage(evt interface{}) {
 
 	e.messageMutex.Unlock()
 
+	traceCtx, span := tracing.Start(context.Background(), "message.receive", attribute.String("whatsapp.provider", e.waService.GetProviderName()))
+	defer span.End()
+
 	// Resolve tenant context
-	ctx, err := e.tenantResolver.ResolveFromPhone(from)
+	_, resolveSpan := tracing.Start(traceCtx, "tenant.resolve")
+	ctx, err := e.tenantResolver.ResolveFromSession(e.sessionID, from)
+	tracing.End(resolveSpan, err)
 	if err != nil {
 		log.Printf("❌ Failed to resolve tenant for %s: %v", from, err)
-		e.waService.SendMessage(from, "Maaf, sistem sedang bermasalah.")
+		if !errors.Is(err, tenant.ErrSessionNotMapped) { // Dropped: no tenant may answer on that session
+			e.waService.SendMessage(from, "Maaf, sistem sedang bermasalah.")
+		}
 		return
 	}
 
@@ -137,18 +153,20 @@ func (e *Engine) HandleMessage(evt interface{}) {
 	// Route ke handler berdasarkan module
 	switch ctx.Module {
 	case "saas":
-		e.handleSaaSMessage(ctx, from, text)
+		e.handleSaaSMessage(traceCtx, ctx, from, text)
 	case "farmasi":
-		e.handleFarmasiMessage(ctx, from, text)
+		e.handleFarmasiMessage(traceCtx, ctx, from, text)
 	case "umkm":
-		e.handleUMKMMessage(ctx, from, text)
+		e.handleUMKMMessage(traceCtx, ctx, from, text)
 	default:
-		e.handleSaaSMessage(ctx, from, text)
+		e.handleSaaSMessage(traceCtx, ctx, from, text)
 	}
 }
 
-func (e *Engine) handleSaaSMessage(ctx *tenant.TenantContext, from, text string) {
+func (e *Engine) handleSaaSMessage(traceCtx context.Context, ctx *tenant.TenantContext, from, text string) {
+	_, kbSpan := tracing.Start(traceCtx, "kb.retrieve", attribute.String("client.id", ctx.ClientID))
 	kb, err := e.kbRetriever.GetKnowledgeBase(ctx.ClientID)
+	tracing.End(kbSpan, err)
 	if err != nil {
 		log.Printf("❌ Failed to get KB for client %s: %v", ctx.ClientID, err)
 		e.waService.SendMessage(from, "Maaf, sistem sedang bermasalah.")
@@ -157,7 +175,7 @@ func (e *Engine) handleSaaSMessage(ctx *tenant.TenantContext, from, text string)
 
 	systemPrompt := llm.BuildSystemPrompt(kb)
 
-	llmCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	llmCtx, cancel := context.WithTimeout(traceCtx, 10*time.Second)
 	defer cancel()
 
 	reply, err := e.llmClient.GenerateResponse(llmCtx, systemPrompt, text)
@@ -166,7 +184,10 @@ func (e *Engine) handleSaaSMessage(ctx *tenant.TenantContext, from, text string)
 		reply = "Maaf, saya sedang tidak bisa menjawab saat ini."
 	}
 
-	if err := e.waService.SendMessage(from, reply); err != nil {
+	_, sendSpan := tracing.Start(traceCtx, "whatsapp.send")
+	err = e.waService.SendMessage(from, reply)
+	tracing.End(sendSpan, err)
+	if err != nil {
 		log.Printf("❌ Failed to send message: %v", err)
 		return
 	}
@@ -178,12 +199,12 @@ func (e *Engine) handleSaaSMessage(ctx *tenant.TenantContext, from, text string)
 	}()
 }
 
-func (e *Engine) handleFarmasiMessage(ctx *tenant.TenantContext, from, text string) {
+func (e *Engine) handleFarmasiMessage(traceCtx context.Context, ctx *tenant.TenantContext, from, text string) {
 	log.Printf("ℹ️ Farmasi module not yet implemented, using SaaS handler")
-	e.handleSaaSMessage(ctx, from, text)
+	e.handleSaaSMessage(traceCtx, ctx, from, text)
 }
 
-func (e *Engine) handleUMKMMessage(ctx *tenant.TenantContext, from, text string) {
+func (e *Engine) handleUMKMMessage(traceCtx context.Context, ctx *tenant.TenantContext, from, text string) {
 	log.Printf("ℹ️ UMKM module not yet implemented, using SaaS handler")
-	e.handleSaaSMessage(ctx, from, text)
+	e.handleSaaSMessage(traceCtx, ctx, from, text)
 }
@@ -0,0 +1,69 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+)
+
+// ToolHandler runs a tool with the arguments the model gave and returns the result fed back to it
+type ToolHandler func(ctx context.Context, args json.RawMessage) (string, error)
+
+// Toolbox holds the tools offered to the model and runs the calls it requests
+type Toolbox struct {
+	tools    []llm.Tool
+	handlers map[string]ToolHandler
+}
+
+func NewToolbox() *Toolbox {
+	return &Toolbox{handlers: make(map[string]ToolHandler)}
+}
+
+// Register adds a tool; a tool registered again replaces the earlier one
+func (t *Toolbox) Register(tool llm.Tool, handler ToolHandler) {
+	if _, ok := t.handlers[tool.Name]; ok {
+		for i := range t.tools {
+			if t.tools[i].Name == tool.Name {
+				t.tools[i] = tool
+			}
+		}
+	} else {
+		t.tools = append(t.tools, tool)
+	}
+	t.handlers[tool.Name] = handler
+}
+
+// Tools returns the tools to offer the model
+func (t *Toolbox) Tools() []llm.Tool {
+	return t.tools
+}
+
+// Execute runs a tool call (an llm.ToolExecutor)
+func (t *Toolbox) Execute(ctx context.Context, call llm.ToolCall) (string, error) {
+	handler, ok := t.handlers[call.Name]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %q", call.Name)
+	}
+	log.Printf("🧰 Tool call %s %s", call.Name, call.Arguments)
+	return handler(ctx, call.Arguments)
+}
+
+// DecodeArgs unmarshals the arguments of a tool call; the error tells the model what was wrong
+func DecodeArgs(args json.RawMessage, v interface{}) error {
+	if err := json.Unmarshal(args, v); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// Result encodes a tool result as JSON for the model
+func Result(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode result: %w", err)
+	}
+	return string(data), nil
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"crypto/subtle"
+	"encoding/json"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -78,6 +80,77 @@ func RequireRole(roles ...string) fiber.Handler {
 	}
 }
 
+// ScopeTenant confines tenant users to their own client for routes addressed by client_id. A client_id
+// given in the query, a JSON body or a form must be the user's own, and is filled into the query from
+// the token when missing, so handlers keep reading it from there. Super admins may address any client.
+// Runs after AuthMiddleware.
+func ScopeTenant() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		clientID, _ := c.Locals("clientID").(string)
+		if c.Locals("role") != RoleSuperAdmin {
+			if clientID == "" {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Token is not bound to a client",
+				})
+			}
+			for _, requested := range requestedClientIDs(c) {
+				if !strings.EqualFold(requested, clientID) {
+					return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+						"error": "Access denied for this client",
+					})
+				}
+			}
+		}
+
+		if c.Query("client_id") == "" && clientID != "" {
+			c.Request().URI().QueryArgs().Set("client_id", clientID)
+		}
+		return c.Next()
+	}
+}
+
+// ScopeTenantParam confines tenant users to their own client for routes addressed by a client ID path
+// parameter, such as /clients/:id. Super admins may address any client. Runs after AuthMiddleware.
+func ScopeTenantParam(param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("role") == RoleSuperAdmin {
+			return c.Next()
+		}
+		clientID, _ := c.Locals("clientID").(string)
+		if clientID == "" || !strings.EqualFold(c.Params(param), clientID) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Access denied for this client",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// requestedClientIDs returns the client IDs a request names in its query, JSON body or form
+func requestedClientIDs(c *fiber.Ctx) []string {
+	var requested []string
+	if clientID := c.Query("client_id"); clientID != "" {
+		requested = append(requested, clientID)
+	}
+
+	contentType := string(c.Request().Header.ContentType())
+	switch {
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+		var body struct {
+			ClientID string `json:"client_id"`
+		}
+		// Bodies that don't decode are left to the handler, which rejects them
+		if json.Unmarshal(c.Body(), &body) == nil && body.ClientID != "" {
+			requested = append(requested, body.ClientID)
+		}
+	case strings.HasPrefix(contentType, fiber.MIMEMultipartForm), strings.HasPrefix(contentType, fiber.MIMEApplicationForm):
+		if clientID := c.FormValue("client_id"); clientID != "" {
+			requested = append(requested, clientID)
+		}
+	}
+	return requested
+}
+
 // RequireModule creates a middleware that checks if user belongs to required module
 func RequireModule(modules ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -101,3 +174,43 @@ func RequireModule(modules ...string) fiber.Handler {
 		})
 	}
 }
+
+// RequireAdminKey creates a middleware for platform operator endpoints, checked against the X-Admin-Key header.
+// The endpoints are disabled when no key is configured.
+func RequireAdminKey(adminKey string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if adminKey == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Admin API is disabled (ADMIN_API_KEY not set)",
+			})
+		}
+
+		if subtle.ConstantTimeCompare([]byte(c.Get("X-Admin-Key")), []byte(adminKey)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid admin key",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireMetricsToken creates a middleware for the Prometheus endpoint, checked against the
+// Authorization: Bearer header. The endpoint is disabled when no token is configured.
+func RequireMetricsToken(token string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if token == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Metrics are disabled (METRICS_TOKEN not set)",
+			})
+		}
+
+		if subtle.ConstantTimeCompare([]byte(c.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Invalid metrics token",
+			})
+		}
+
+		return c.Next()
+	}
+}
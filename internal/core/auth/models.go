@@ -6,6 +6,13 @@ import (
 	"github.com/google/uuid"
 )
 
+// Roles of company users
+const (
+	RoleSuperAdmin  = "super_admin"  // Platform operator, may act for any client
+	RoleAdminTenant = "admin_tenant" // Manages their own client: settings, knowledge base, workflows, billing
+	RoleStaffTenant = "staff_tenant" // Runs day-to-day operations of their own client: orders, chats, quotes
+)
+
 // CompanyUser represents a user that can login to the CMS
 // Can be tenant admin, staff, or super admin
 type CompanyUser struct {
@@ -18,6 +25,9 @@ type CompanyUser struct {
 	Name        string `gorm:"type:text" json:"name"`
 	Role        string `gorm:"type:text;not null" json:"role"` // super_admin, admin_tenant, staff_tenant
 
+	// Reference ID from the provisioning tool, unique per client
+	ExternalRef *string `gorm:"type:text;column:external_ref" json:"external_ref,omitempty"`
+
 	// Authentication
 	PasswordHash string `gorm:"type:text" json:"-"` // Hidden from JSON
 
@@ -0,0 +1,125 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Statement is a billing statement rendered as an invoice document
+type Statement struct {
+	Number      string
+	Issuer      string // Shown in the header, e.g. the SaaS name
+	BillTo      string
+	Plan        string
+	PeriodStart time.Time
+	PeriodEnd   time.Time // Exclusive
+	IssuedAt    time.Time
+	Currency    string
+	Lines       []StatementLine
+	Total       float64
+	Status      string
+	PaymentLink string
+}
+
+// StatementLine is one charge of a statement
+type StatementLine struct {
+	Description string
+	Detail      string // e.g. the usage behind an overage
+	Amount      float64
+}
+
+// ExportStatement renders a billing statement as PDF
+func (s *Service) ExportStatement(statement *Statement) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeStatementPDF(statement, &buf); err != nil {
+		return nil, fmt.Errorf("statement PDF export failed: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeStatementPDF lays out the header, the billed party and period, then one row per charge and the total
+func writeStatementPDF(statement *Statement, w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 18)
+	pdf.Cell(110, 10, tr(statement.Issuer))
+	pdf.SetFont("Arial", "B", 14)
+	pdf.CellFormat(0, 10, "STATEMENT", "", 1, "R", false, 0, "")
+
+	pdf.SetFont("Arial", "", 10)
+	lastDay := statement.PeriodEnd.AddDate(0, 0, -1)
+	details := [][2]string{
+		{"Number", statement.Number},
+		{"Issued", statement.IssuedAt.Format("2006-01-02")},
+		{"Period", fmt.Sprintf("%s - %s", statement.PeriodStart.Format("2006-01-02"), lastDay.Format("2006-01-02"))},
+		{"Bill to", statement.BillTo},
+		{"Plan", statement.Plan},
+		{"Status", strings.ToUpper(statement.Status)},
+	}
+	pdf.Ln(4)
+	for _, detail := range details {
+		pdf.SetFont("Arial", "B", 10)
+		pdf.Cell(30, 6, detail[0])
+		pdf.SetFont("Arial", "", 10)
+		pdf.Cell(0, 6, tr(detail[1]))
+		pdf.Ln(6)
+	}
+	pdf.Ln(6)
+
+	pdf.SetFillColor(52, 73, 94)
+	pdf.SetTextColor(255, 255, 255)
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(70, 8, "Description", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(80, 8, "Details", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(40, 8, "Amount", "1", 1, "R", true, 0, "")
+
+	pdf.SetTextColor(0, 0, 0)
+	pdf.SetFont("Arial", "", 9)
+	for _, line := range statement.Lines {
+		pdf.CellFormat(70, 7, tr(line.Description), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(80, 7, tr(line.Detail), "1", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 7, formatStatementAmount(statement.Currency, line.Amount), "1", 1, "R", false, 0, "")
+	}
+
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(150, 8, "Total", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(40, 8, formatStatementAmount(statement.Currency, statement.Total), "1", 1, "R", false, 0, "")
+
+	if statement.PaymentLink != "" {
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "", 10)
+		pdf.Cell(0, 5, "Pay online:")
+		pdf.Ln(5)
+		pdf.SetTextColor(0, 0, 200)
+		pdf.SetFont("Arial", "U", 9)
+		pdf.WriteLinkString(5, tr(statement.PaymentLink), statement.PaymentLink)
+		pdf.Ln(5)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// formatStatementAmount formats a whole amount with thousands separators, e.g. "IDR 1.250.000"
+func formatStatementAmount(currency string, amount float64) string {
+	digits := strconv.FormatInt(int64(amount+0.5), 10)
+	var grouped strings.Builder
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(d)
+	}
+	return strings.TrimSpace(currency + " " + grouped.String())
+}
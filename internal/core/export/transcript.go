@@ -0,0 +1,168 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io"
+	"regexp"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// FormatHTML exports a standalone HTML page (transcripts only)
+const FormatHTML ExportFormat = "html"
+
+// Transcript roles
+const (
+	TranscriptRoleCustomer = "customer"
+	TranscriptRoleBot      = "bot"
+	TranscriptRoleAgent    = "agent"
+)
+
+// Transcript is a chat history rendered as a document, e.g. as evidence in a dispute
+type Transcript struct {
+	Title       string
+	Subtitle    string
+	GeneratedAt time.Time
+	Timezone    string // Shown next to the timestamps
+	Entries     []TranscriptEntry
+}
+
+// TranscriptEntry is one message of a transcript
+type TranscriptEntry struct {
+	Time     time.Time
+	Role     string // customer, bot, agent
+	Author   string // Display name
+	Text     string
+	MediaURL string
+}
+
+var transcriptLinkPattern = regexp.MustCompile(`https?://[^\s<>"]+`)
+
+// ExportTranscript renders a transcript as PDF or HTML and returns it with its content type
+func (s *Service) ExportTranscript(transcript *Transcript, format ExportFormat) ([]byte, string, error) {
+	var buf bytes.Buffer
+	switch format {
+	case FormatPDF:
+		if err := writeTranscriptPDF(transcript, &buf); err != nil {
+			return nil, "", fmt.Errorf("transcript PDF export failed: %w", err)
+		}
+		return buf.Bytes(), "application/pdf", nil
+	case FormatHTML:
+		if err := transcriptHTML.Execute(&buf, transcript); err != nil {
+			return nil, "", fmt.Errorf("transcript HTML export failed: %w", err)
+		}
+		return buf.Bytes(), "text/html; charset=utf-8", nil
+	default:
+		return nil, "", fmt.Errorf("unsupported transcript format: %s", format)
+	}
+}
+
+// writeTranscriptPDF lays out one block per message: a header line with time and author, the wrapped text
+// and the media link. The core PDF fonts only cover Latin-1, so other characters (emoji) are dropped
+func writeTranscriptPDF(transcript *Transcript, w io.Writer) error {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.SetTextColor(128, 128, 128)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.MultiCell(0, 8, tr(transcript.Title), "", "L", false)
+	if transcript.Subtitle != "" {
+		pdf.SetFont("Arial", "", 10)
+		pdf.MultiCell(0, 5, tr(transcript.Subtitle), "", "L", false)
+	}
+	pdf.SetFont("Arial", "I", 8)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.Cell(0, 5, fmt.Sprintf("Generated: %s (%s) | %d messages",
+		transcript.GeneratedAt.Format("2006-01-02 15:04:05"), transcript.Timezone, len(transcript.Entries)))
+	pdf.Ln(10)
+
+	for _, entry := range transcript.Entries {
+		r, g, b := transcriptRoleColor(entry.Role)
+		pdf.SetTextColor(r, g, b)
+		pdf.SetFont("Arial", "B", 9)
+		pdf.Cell(0, 5, tr(fmt.Sprintf("%s  %s (%s)", entry.Time.Format("2006-01-02 15:04:05"), entry.Author, entry.Role)))
+		pdf.Ln(5)
+
+		pdf.SetTextColor(0, 0, 0)
+		pdf.SetFont("Arial", "", 10)
+		if entry.Text != "" {
+			pdf.MultiCell(0, 5, tr(entry.Text), "", "L", false)
+		}
+		if entry.MediaURL != "" {
+			pdf.SetTextColor(0, 0, 200)
+			pdf.SetFont("Arial", "U", 9)
+			pdf.WriteLinkString(5, tr("Media: "+entry.MediaURL), entry.MediaURL)
+			pdf.Ln(5)
+		}
+		pdf.Ln(3)
+	}
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("failed to write PDF: %w", err)
+	}
+	return nil
+}
+
+// transcriptRoleColor picks the header color of a message by its sender
+func transcriptRoleColor(role string) (int, int, int) {
+	switch role {
+	case TranscriptRoleCustomer:
+		return 30, 90, 160
+	case TranscriptRoleAgent:
+		return 170, 90, 0
+	default:
+		return 40, 120, 60
+	}
+}
+
+var transcriptHTML = template.Must(template.New("transcript").Funcs(template.FuncMap{
+	"linkify": func(text string) template.HTML {
+		// Escape the message, then turn the (escaped) URLs in it into links
+		escaped := template.HTMLEscapeString(text)
+		return template.HTML(transcriptLinkPattern.ReplaceAllString(escaped, `<a href="$0" rel="noopener noreferrer">$0</a>`))
+	},
+	"stamp": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Arial, sans-serif; max-width: 760px; margin: 24px auto; color: #222; }
+h1 { font-size: 20px; margin-bottom: 4px; }
+.meta { color: #666; font-size: 12px; margin-bottom: 24px; }
+.msg { margin: 10px 0; padding: 8px 12px; border-radius: 8px; max-width: 80%; }
+.msg .head { font-size: 11px; font-weight: bold; margin-bottom: 4px; }
+.msg .text { white-space: pre-wrap; word-wrap: break-word; }
+.customer { background: #eef4fb; }
+.customer .head { color: #1e5aa0; }
+.bot { background: #eef8f0; margin-left: auto; }
+.bot .head { color: #28783c; }
+.agent { background: #fdf3e6; margin-left: auto; }
+.agent .head { color: #aa5a00; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<div class="meta">{{if .Subtitle}}{{.Subtitle}}<br>{{end}}Generated: {{stamp .GeneratedAt}} ({{.Timezone}}) | {{len .Entries}} messages</div>
+{{range .Entries}}<div class="msg {{.Role}}">
+<div class="head">{{stamp .Time}} &middot; {{.Author}} ({{.Role}})</div>
+{{if .Text}}<div class="text">{{linkify .Text}}</div>{{end}}
+{{if .MediaURL}}<div class="media"><a href="{{.MediaURL}}" rel="noopener noreferrer">Media</a></div>{{end}}
+</div>
+{{end}}</body>
+</html>
+`))
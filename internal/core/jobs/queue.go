@@ -3,12 +3,14 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Queue manages job queue operations
@@ -77,13 +79,17 @@ func (q *Queue) Dequeue(ctx context.Context, queueName string) (*Job, error) {
 		// - Must be pending status
 		// - If scheduled, must be past scheduled time
 		// - Order by priority DESC, created_at ASC
-		query := tx.Where("queue = ? AND status = ?", queueName, StatusPending)
+		// Retrying jobs are picked up again once their backoff has passed
+		query := tx.Where("queue = ? AND status IN ?", queueName, []JobStatus{StatusPending, StatusRetrying})
 
 		// Check if job is ready to run (not scheduled or scheduled time has passed)
 		query = query.Where("scheduled_at IS NULL OR scheduled_at <= ?", time.Now())
 
 		query = query.Order("priority DESC, created_at ASC").Limit(1)
 
+		// Skip jobs another worker (or replica) is dequeuing right now
+		query = query.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"})
+
 		if err := query.First(&job).Error; err != nil {
 			return err
 		}
@@ -138,8 +144,11 @@ func (q *Queue) MarkFailed(ctx context.Context, jobID uuid.UUID, err error) erro
 	job.Error = err.Error()
 	job.FailedAt = &now
 
-	// Check if we should retry
-	if job.Attempts < job.MaxRetries {
+	// Check if we should retry: permanent errors fail right away, exhausted retries go to the dead letters
+	var permanent *PermanentError
+	if errors.As(err, &permanent) {
+		job.Status = StatusFailed
+	} else if job.Attempts < job.MaxRetries {
 		// Calculate exponential backoff
 		backoffSeconds := calculateBackoff(job.Attempts)
 		scheduleAt := time.Now().Add(time.Duration(backoffSeconds) * time.Second)
@@ -147,12 +156,33 @@ func (q *Queue) MarkFailed(ctx context.Context, jobID uuid.UUID, err error) erro
 		job.Status = StatusRetrying
 		job.ScheduledAt = &scheduleAt
 	} else {
-		job.Status = StatusFailed
+		job.Status = StatusDeadLetter
 	}
 
 	return q.db.WithContext(ctx).Save(&job).Error
 }
 
+// Requeue runs a failed or dead-letter job again from its first attempt
+func (q *Queue) Requeue(ctx context.Context, jobID uuid.UUID) error {
+	result := q.db.WithContext(ctx).Model(&Job{}).
+		Where("id = ? AND status IN ?", jobID, []JobStatus{StatusFailed, StatusDeadLetter}).
+		Updates(map[string]interface{}{
+			"status":       StatusPending,
+			"attempts":     0,
+			"scheduled_at": nil,
+		})
+
+	if result.Error != nil {
+		return fmt.Errorf("failed to requeue job: %w", result.Error)
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("job not found or not in failed state")
+	}
+
+	return nil
+}
+
 // Cancel cancels a pending job
 func (q *Queue) Cancel(ctx context.Context, jobID uuid.UUID) error {
 	result := q.db.WithContext(ctx).Model(&Job{}).
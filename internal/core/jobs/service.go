@@ -63,6 +63,11 @@ func (s *Service) Cancel(ctx context.Context, jobID uuid.UUID) error {
 	return s.queue.Cancel(ctx, jobID)
 }
 
+// Requeue runs a failed or dead-letter job again
+func (s *Service) Requeue(ctx context.Context, jobID uuid.UUID) error {
+	return s.queue.Requeue(ctx, jobID)
+}
+
 // GetJob retrieves a job by ID
 func (s *Service) GetJob(ctx context.Context, jobID uuid.UUID) (*Job, error) {
 	return s.queue.GetJob(ctx, jobID)
@@ -150,7 +155,7 @@ func (s *Service) EnqueueDataExportJob(ctx context.Context, clientID uuid.UUID,
 func (s *Service) GetQueueStats(ctx context.Context, queueName string) (map[JobStatus]int64, error) {
 	stats := make(map[JobStatus]int64)
 
-	statuses := []JobStatus{StatusPending, StatusProcessing, StatusCompleted, StatusFailed, StatusRetrying}
+	statuses := []JobStatus{StatusPending, StatusProcessing, StatusCompleted, StatusFailed, StatusRetrying, StatusDeadLetter}
 
 	for _, status := range statuses {
 		var count int64
@@ -18,6 +18,7 @@ const (
 	StatusFailed     JobStatus = "failed"
 	StatusRetrying   JobStatus = "retrying"
 	StatusCancelled  JobStatus = "cancelled"
+	StatusDeadLetter JobStatus = "dead_letter" // Retries exhausted; kept for inspection until requeued
 )
 
 // JobPriority represents the priority of a job
@@ -68,6 +69,24 @@ type JobHandler interface {
 	GetType() string
 }
 
+// PermanentError marks a job error that retrying cannot fix (e.g. an invalid payload)
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *PermanentError) Unwrap() error {
+	return e.Err
+}
+
+// Permanent wraps a handler error so the job fails right away instead of being retried
+func Permanent(err error) error {
+	return &PermanentError{Err: err}
+}
+
 // JobPayload is a convenience interface for job payloads
 type JobPayload interface {
 	Validate() error
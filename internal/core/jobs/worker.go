@@ -100,17 +100,27 @@ func (w *Worker) runWorker(ctx context.Context, workerID int) {
 			}
 			w.mu.RUnlock()
 
-			// Try to dequeue a job
-			if err := w.processNextJob(ctx, workerID); err != nil {
-				// Log error but continue processing
-				if err != ErrNoJobsAvailable {
-					log.Printf("⚠️  Worker #%d error: %v", workerID, err)
+			// Process jobs until the queue is drained, then wait for the next tick
+			for ctx.Err() == nil && !w.isStopped() {
+				if err := w.processNextJob(ctx, workerID); err != nil {
+					// Log error but continue processing
+					if err != ErrNoJobsAvailable {
+						log.Printf("⚠️  Worker #%d error: %v", workerID, err)
+					}
+					break
 				}
 			}
 		}
 	}
 }
 
+// isStopped reports whether Stop was called
+func (w *Worker) isStopped() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.stopped
+}
+
 // ErrNoJobsAvailable is returned when no jobs are available
 var ErrNoJobsAvailable = fmt.Errorf("no jobs available")
 
@@ -0,0 +1,160 @@
+package kb
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/vector"
+)
+
+// DocTypeDocument is the vector doc_type of uploaded document chunks
+const DocTypeDocument = "document"
+
+const (
+	DefaultChunkSize    = 1200 // Characters per chunk
+	DefaultChunkOverlap = 200  // Characters repeated from the end of the previous chunk
+	embeddingBatchSize  = 64   // Chunks embedded per request
+)
+
+// DocumentChunk is one overlapping piece of a document's text
+type DocumentChunk struct {
+	Index int
+	Text  string
+}
+
+// ChunkText splits text into chunks of at most size characters, each starting overlap characters
+// before the end of the previous one. Chunks end at a paragraph, sentence or word boundary when possible.
+func ChunkText(text string, size, overlap int) []DocumentChunk {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	runes := []rune(strings.TrimSpace(text))
+	var chunks []DocumentChunk
+	for start := 0; start < len(runes); {
+		end := start + size
+		if end >= len(runes) {
+			end = len(runes)
+		} else {
+			end = chunkBoundary(runes, start, end)
+		}
+
+		if chunk := strings.TrimSpace(string(runes[start:end])); chunk != "" {
+			chunks = append(chunks, DocumentChunk{Index: len(chunks), Text: chunk})
+		}
+		if end == len(runes) {
+			break
+		}
+
+		// Step back by the overlap, then forward to the next word so chunks don't start mid-word
+		next := end - overlap
+		if next <= start {
+			next = end
+		}
+		for next < end && next > 0 && !unicode.IsSpace(runes[next-1]) {
+			next++
+		}
+		start = next
+	}
+	return chunks
+}
+
+// chunkBoundary moves end back to the last paragraph, sentence or word break in the second half of the window
+func chunkBoundary(runes []rune, start, end int) int {
+	min := start + (end-start)/2
+	for i := end; i > min; i-- {
+		if runes[i-1] == '\n' && i >= 2 && runes[i-2] == '\n' {
+			return i
+		}
+	}
+	for i := end; i > min; i-- {
+		if unicode.IsSpace(runes[i-1]) && i >= 2 && strings.ContainsRune(".!?", runes[i-2]) {
+			return i
+		}
+	}
+	for i := end; i > min; i-- {
+		if unicode.IsSpace(runes[i-1]) {
+			return i
+		}
+	}
+	return end
+}
+
+// DocumentChunkID is the vector doc_id of a document chunk
+func DocumentChunkID(documentID string, index int) string {
+	return fmt.Sprintf("%s_%d", documentID, index)
+}
+
+// AddDocumentChunks embeds and stores a document's chunks, tagged with the client, document and chunk position
+func (r *VectorRetriever) AddDocumentChunks(ctx context.Context, clientID, documentID, name string, chunks []DocumentChunk) error {
+	defer r.invalidate(clientID)
+
+	for from := 0; from < len(chunks); from += embeddingBatchSize {
+		to := from + embeddingBatchSize
+		if to > len(chunks) {
+			to = len(chunks)
+		}
+
+		docs := make([]vector.Document, 0, to-from)
+		for _, chunk := range chunks[from:to] {
+			docID := DocumentChunkID(documentID, chunk.Index)
+			docs = append(docs, vector.Document{
+				ID:   fmt.Sprintf("%s_%s_%s", clientID, DocTypeDocument, docID),
+				Text: chunk.Text,
+				Metadata: map[string]interface{}{
+					"client_id":     clientID,
+					"doc_type":      DocTypeDocument,
+					"doc_id":        docID,
+					"document_id":   documentID,
+					"document_name": name,
+					"chunk_index":   chunk.Index,
+					"chunk_count":   len(chunks),
+				},
+			})
+		}
+
+		if err := r.vectorService.AddDocuments(ctx, r.collection, docs); err != nil {
+			return fmt.Errorf("failed to store chunks %d-%d: %w", from, to-1, err)
+		}
+	}
+	return nil
+}
+
+// DeleteDocumentChunks removes the first count chunks of a document
+func (r *VectorRetriever) DeleteDocumentChunks(ctx context.Context, clientID, documentID string, count int) error {
+	if count <= 0 {
+		return nil
+	}
+
+	ids := make([]string, count)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%s_%s_%s", clientID, DocTypeDocument, DocumentChunkID(documentID, i))
+	}
+	err := r.vectorService.DeleteDocuments(ctx, r.collection, ids)
+	r.invalidate(clientID)
+	return err
+}
+
+// GetDocumentContext returns the document passages most relevant to a question, formatted for the system prompt.
+// Passages scoring below minScore are left out; an empty string means nothing relevant was found.
+func (r *VectorRetriever) GetDocumentContext(ctx context.Context, clientID, query string, limit int, minScore float32) (string, error) {
+	results, err := r.SearchByType(ctx, clientID, query, DocTypeDocument, limit)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, result := range results {
+		if result.Score < minScore {
+			continue
+		}
+		name := getStringFromPayload(result.Metadata, "document_name")
+		sb.WriteString(fmt.Sprintf("[%s]\n%s\n\n", name, result.Text))
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,446 @@
+package kb
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Supported document formats
+const (
+	DocumentFormatPDF  = "pdf"
+	DocumentFormatDOCX = "docx"
+	DocumentFormatTXT  = "txt"
+)
+
+var (
+	ErrUnsupportedDocument = errors.New("unsupported document format, upload a PDF, DOCX or TXT file")
+	ErrNoDocumentText      = errors.New("no text found in document (scanned PDFs are not supported)")
+)
+
+// DetectDocumentFormat picks the format from the file content, falling back to the file extension
+func DetectDocumentFormat(filename string, data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte("%PDF-")):
+		return DocumentFormatPDF, nil
+	case bytes.HasPrefix(data, []byte("PK\x03\x04")) && strings.EqualFold(filepath.Ext(filename), ".docx"):
+		return DocumentFormatDOCX, nil
+	}
+
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".txt", ".md", ".text":
+		return DocumentFormatTXT, nil
+	}
+	return "", ErrUnsupportedDocument
+}
+
+// ExtractText returns the plain text of a PDF, DOCX or TXT document
+func ExtractText(format string, data []byte) (string, error) {
+	var text string
+	var err error
+	switch format {
+	case DocumentFormatPDF:
+		text, err = extractPDFText(data)
+	case DocumentFormatDOCX:
+		text, err = extractDOCXText(data)
+	case DocumentFormatTXT:
+		text = strings.TrimPrefix(string(data), "\uFEFF")
+	default:
+		return "", ErrUnsupportedDocument
+	}
+	if err != nil {
+		return "", err
+	}
+
+	text = normalizeDocumentText(strings.ToValidUTF8(text, ""))
+	if text == "" {
+		return "", ErrNoDocumentText
+	}
+	return text, nil
+}
+
+// normalizeDocumentText collapses spacing within lines and keeps at most one blank line between paragraphs
+func normalizeDocumentText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	var sb strings.Builder
+	blank := false
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			blank = sb.Len() > 0
+			continue
+		}
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+			if blank {
+				sb.WriteString("\n")
+			}
+		}
+		sb.WriteString(line)
+		blank = false
+	}
+	return sb.String()
+}
+
+// extractDOCXText reads the paragraphs of word/document.xml
+func extractDOCXText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid DOCX file: %w", err)
+	}
+
+	for _, f := range archive.File {
+		if f.Name != "word/document.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return "", fmt.Errorf("failed to open DOCX body: %w", err)
+		}
+		defer rc.Close()
+		return readDOCXBody(rc)
+	}
+	return "", fmt.Errorf("invalid DOCX file: word/document.xml not found")
+}
+
+func readDOCXBody(r io.Reader) (string, error) {
+	var sb strings.Builder
+	decoder := xml.NewDecoder(r)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid DOCX body: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br", "cr":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+	return sb.String(), nil
+}
+
+// extractPDFText reads the text shown by the page content streams. Only text drawn with simple
+// (single byte) font encodings is recovered; composite fonts without a readable encoding and
+// scanned pages yield no text.
+func extractPDFText(data []byte) (string, error) {
+	var sb strings.Builder
+	rest := data
+	for {
+		start := bytes.Index(rest, []byte("stream"))
+		if start < 0 {
+			break
+		}
+		// "endstream" also contains "stream"
+		if start >= 3 && string(rest[start-3:start]) == "end" {
+			rest = rest[start+len("stream"):]
+			continue
+		}
+
+		dict := streamDictionary(rest[:start])
+		body := rest[start+len("stream"):]
+		body = bytes.TrimPrefix(body, []byte("\r"))
+		body = bytes.TrimPrefix(body, []byte("\n"))
+		end := bytes.Index(body, []byte("endstream"))
+		if end < 0 {
+			break
+		}
+		content := body[:end]
+		rest = body[end+len("endstream"):]
+
+		if !isContentStream(dict) {
+			continue
+		}
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			inflated, err := inflate(content)
+			if err != nil {
+				continue
+			}
+			content = inflated
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue // Other filters (images, LZW, ...) carry no readable text
+		}
+
+		if text := pdfContentText(content); strings.TrimSpace(text) != "" {
+			sb.WriteString(text)
+			sb.WriteString("\n\n")
+		}
+	}
+
+	if sb.Len() == 0 && !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", fmt.Errorf("invalid PDF file")
+	}
+	return sb.String(), nil
+}
+
+// streamDictionary returns the dictionary written right before a stream keyword
+func streamDictionary(before []byte) []byte {
+	obj := bytes.LastIndex(before, []byte(" obj"))
+	if obj < 0 {
+		obj = 0
+	}
+	return before[obj:]
+}
+
+// isContentStream skips streams that are known not to be page content (fonts, images, metadata, xref)
+func isContentStream(dict []byte) bool {
+	for _, marker := range []string{"/Subtype/Image", "/Subtype /Image", "/Length1", "/Length2", "/FontFile", "/Type/XRef", "/Type /XRef", "/Type/Metadata", "/Type /Metadata", "/Type/ObjStm", "/Type /ObjStm", "/Type/EmbeddedFile", "/Type /EmbeddedFile"} {
+		if bytes.Contains(dict, []byte(marker)) {
+			return false
+		}
+	}
+	return true
+}
+
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// A truncated stream still yields the text decoded so far
+	out, err := io.ReadAll(r)
+	if len(out) > 0 {
+		return out, nil
+	}
+	return nil, err
+}
+
+// pdfContentText interprets the text operators (Tj, TJ, ', ", Td, TD, T*) of a content stream
+func pdfContentText(content []byte) string {
+	var sb strings.Builder
+	var strs []string  // String operands since the last operator
+	var nums []float64 // Number operands since the last operator
+	inArray := false
+
+	newline := func() {
+		if sb.Len() > 0 && !strings.HasSuffix(sb.String(), "\n") {
+			sb.WriteString("\n")
+		}
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			s, n := readPDFLiteral(content[i:])
+			strs = append(strs, s)
+			i += n
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2 // Inline dictionaries (marked content properties) carry no text
+		case c == '>' && i+1 < len(content) && content[i+1] == '>':
+			i += 2
+		case c == '<':
+			end := bytes.IndexByte(content[i:], '>')
+			if end < 0 {
+				return sb.String()
+			}
+			strs = append(strs, decodePDFHex(content[i+1:i+end]))
+			i += end + 1
+		case c == '[':
+			inArray = true
+			i++
+		case c == ']':
+			inArray = false
+			i++
+		case isPDFDelimiter(c) || isPDFSpace(c):
+			i++
+		default:
+			start := i
+			for i < len(content) && !isPDFDelimiter(content[i]) && !isPDFSpace(content[i]) {
+				i++
+			}
+			token := string(content[start:i])
+			if n, err := strconv.ParseFloat(token, 64); err == nil {
+				// Large negative kerning inside a TJ array is a word gap
+				if inArray && n < -200 {
+					strs = append(strs, " ")
+				}
+				nums = append(nums, n)
+				continue
+			}
+
+			switch token {
+			case "Tj", "TJ":
+				sb.WriteString(strings.Join(strs, ""))
+			case "'", "\"":
+				newline()
+				sb.WriteString(strings.Join(strs, ""))
+			case "T*":
+				newline()
+			case "Td", "TD":
+				if len(nums) >= 2 && nums[len(nums)-1] != 0 {
+					newline()
+				} else if sb.Len() > 0 {
+					sb.WriteString(" ")
+				}
+			case "Tm":
+				newline()
+			case "ET":
+				sb.WriteString(" ")
+			case "BI":
+				// Skip inline image data
+				end := bytes.Index(content[i:], []byte("EI"))
+				if end < 0 {
+					return sb.String()
+				}
+				i += end + 2
+			}
+			if !inArray {
+				strs = strs[:0]
+				nums = nums[:0]
+			}
+		}
+	}
+	return sb.String()
+}
+
+// readPDFLiteral decodes a (literal string) and returns it with the number of bytes consumed
+func readPDFLiteral(data []byte) (string, int) {
+	var sb strings.Builder
+	depth := 0
+	i := 0
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '(':
+			if depth > 0 {
+				sb.WriteByte(c)
+			}
+			depth++
+			i++
+		case c == ')':
+			depth--
+			i++
+			if depth == 0 {
+				return latin1(sb.String()), i
+			}
+			sb.WriteByte(c)
+		case c == '\\' && i+1 < len(data):
+			i++
+			e := data[i]
+			switch e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r':
+				sb.WriteByte('\r')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b', 'f':
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					end := i
+					for end < len(data) && end < i+3 && data[end] >= '0' && data[end] <= '7' {
+						end++
+					}
+					v, _ := strconv.ParseUint(string(data[i:end]), 8, 8)
+					sb.WriteByte(byte(v))
+					i = end
+					continue
+				}
+				sb.WriteByte(e)
+			}
+			i++
+		default:
+			sb.WriteByte(c)
+			i++
+		}
+	}
+	return latin1(sb.String()), i
+}
+
+// decodePDFHex decodes a <hex string>; two-byte strings starting with a zero byte are read as UTF-16
+func decodePDFHex(hex []byte) string {
+	var digits []byte
+	for _, c := range hex {
+		if !isPDFSpace(c) {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	raw := make([]byte, 0, len(digits)/2)
+	for i := 0; i+1 < len(digits); i += 2 {
+		v, err := strconv.ParseUint(string(digits[i:i+2]), 16, 8)
+		if err != nil {
+			return ""
+		}
+		raw = append(raw, byte(v))
+	}
+
+	if len(raw) >= 2 && len(raw)%2 == 0 && raw[0] == 0 {
+		var sb strings.Builder
+		for i := 0; i+1 < len(raw); i += 2 {
+			r := rune(raw[i])<<8 | rune(raw[i+1])
+			if r >= 0x20 || r == '\n' || r == '\t' {
+				sb.WriteRune(r)
+			}
+		}
+		return sb.String()
+	}
+	return latin1(string(raw))
+}
+
+// latin1 maps single byte (WinAnsi/PDFDoc) characters to UTF-8, dropping control bytes
+func latin1(s string) string {
+	if utf8.ValidString(s) && !strings.ContainsFunc(s, func(r rune) bool { return r < 0x20 && r != '\n' && r != '\t' }) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		b := s[i]
+		if b < 0x20 && b != '\n' && b != '\t' {
+			continue
+		}
+		sb.WriteRune(rune(b))
+	}
+	return sb.String()
+}
+
+func isPDFSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\r' || c == '\t' || c == '\f' || c == 0
+}
+
+func isPDFDelimiter(c byte) bool {
+	return c == '(' || c == ')' || c == '<' || c == '>' || c == '[' || c == ']' || c == '{' || c == '}' || c == '/' || c == '%'
+}
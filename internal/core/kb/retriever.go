@@ -1,22 +1,48 @@
 package kb
 
 import (
+	"context"
 	"encoding/json"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/cache"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type Retriever struct {
-	db *gorm.DB
+	db    *gorm.DB
+	cache *cache.Cache // nil when Redis caching is off
 }
 
 func NewRetriever(db *gorm.DB) *Retriever {
 	return &Retriever{db: db}
 }
 
+// SetCache caches knowledge bases in Redis; writes to a client or its entries drop the client's copy
+func (r *Retriever) SetCache(c *cache.Cache) error {
+	r.cache = c
+	invalidate := func(ctx context.Context, clientIDs []string) {
+		if clientIDs == nil {
+			c.DeletePrefix(ctx, knowledgeBaseCachePrefix)
+			return
+		}
+		keys := make([]string, len(clientIDs))
+		for i, clientID := range clientIDs {
+			keys[i] = knowledgeBaseCachePrefix + clientID
+		}
+		c.Delete(ctx, keys...)
+	}
+	if err := c.OnWrite(r.db, "kb:entries", models.KnowledgeBaseEntry{}.TableName(), "client_id", invalidate); err != nil {
+		return err
+	}
+	return c.OnWrite(r.db, "kb:client", models.Client{}.TableName(), "id", invalidate)
+}
+
+// knowledgeBaseCachePrefix is followed by the client ID
+const knowledgeBaseCachePrefix = "kb:"
+
 // GetKnowledgeBase mengambil knowledge base untuk client tertentu
 func (r *Retriever) GetKnowledgeBase(clientID string) (*llm.KnowledgeBase, error) {
 	kb := &llm.KnowledgeBase{}
@@ -27,6 +53,12 @@ func (r *Retriever) GetKnowledgeBase(clientID string) (*llm.KnowledgeBase, error
 		return nil, err
 	}
 
+	ctx := context.Background()
+	cacheKey := knowledgeBaseCachePrefix + uid.String()
+	if r.cache.Get(ctx, cacheKey, kb) {
+		return kb, nil
+	}
+
 	// Get client info
 	var client models.Client
 	if err := r.db.First(&client, "id = ?", uid).Error; err != nil {
@@ -35,6 +67,7 @@ func (r *Retriever) GetKnowledgeBase(clientID string) (*llm.KnowledgeBase, error
 
 	kb.BusinessName = client.BusinessName
 	kb.Tone = client.Tone
+	kb.PromptTemplate = client.AIPromptTemplate
 
 	// Get all knowledge base entries
 	var entries []models.KnowledgeBaseEntry
@@ -94,5 +127,13 @@ func (r *Retriever) GetKnowledgeBase(clientID string) (*llm.KnowledgeBase, error
 		}
 	}
 
+	r.cache.Set(ctx, cacheKey, kb)
 	return kb, nil
 }
+
+// ListEntries returns all of a client's knowledge base entries, active or not
+func (r *Retriever) ListEntries(clientID string) ([]models.KnowledgeBaseEntry, error) {
+	var entries []models.KnowledgeBaseEntry
+	err := r.db.Where("client_id = ?", clientID).Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
@@ -0,0 +1,164 @@
+package kb
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Search cache defaults
+const (
+	DefaultSearchCacheTTL        = time.Minute
+	DefaultSearchCacheMaxEntries = 5000
+)
+
+// SearchCache keeps vector search results for a short TTL so repeated queries skip the embedder
+// and the vector DB. Entries are keyed per client on the normalized query text and carry the
+// client's KB version; every write to the client's vectors bumps the version, so results cached
+// before the write are never served after it. The cache is per process: writes made by another
+// instance are only picked up once the TTL expires.
+type SearchCache struct {
+	ttl        time.Duration
+	maxEntries int
+
+	mu       sync.Mutex
+	entries  map[string]searchCacheEntry
+	versions map[string]uint64 // Client ID -> KB version
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+}
+
+type searchCacheEntry struct {
+	results   []SearchResult
+	version   uint64
+	expiresAt time.Time
+}
+
+// SearchCacheStats reports the cache size and hit rate since startup
+type SearchCacheStats struct {
+	Enabled       bool    `json:"enabled"`
+	TTLSeconds    float64 `json:"ttl_seconds"`
+	Entries       int     `json:"entries"`
+	MaxEntries    int     `json:"max_entries"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`      // Hits / (hits + misses), 0-1
+	Invalidations int64   `json:"invalidations"` // KB version bumps
+}
+
+// NewSearchCache creates a search cache, using the defaults for a zero TTL or size
+func NewSearchCache(ttl time.Duration, maxEntries int) *SearchCache {
+	if ttl <= 0 {
+		ttl = DefaultSearchCacheTTL
+	}
+	if maxEntries <= 0 {
+		maxEntries = DefaultSearchCacheMaxEntries
+	}
+	return &SearchCache{
+		ttl:        ttl,
+		maxEntries: maxEntries,
+		entries:    make(map[string]searchCacheEntry),
+		versions:   make(map[string]uint64),
+	}
+}
+
+// Get returns the cached results of a search if they are fresh and the client's KB hasn't changed since
+func (c *SearchCache) Get(clientID, docType, query string, limit int) ([]SearchResult, bool) {
+	key := searchCacheKey(clientID, docType, query, limit)
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	if ok && (time.Now().After(entry.expiresAt) || entry.version != c.versions[clientID]) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return nil, false
+	}
+	c.hits.Add(1)
+	return append([]SearchResult(nil), entry.results...), true
+}
+
+// Set caches the results of a search made at the given KB version (read with Version before
+// searching), so results of a search that raced with a write are never served
+func (c *SearchCache) Set(clientID, docType, query string, limit int, version uint64, results []SearchResult) {
+	key := searchCacheKey(clientID, docType, query, limit)
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if version != c.versions[clientID] {
+		return
+	}
+	if len(c.entries) >= c.maxEntries {
+		c.prune(now)
+	}
+	c.entries[key] = searchCacheEntry{
+		results:   results,
+		version:   version,
+		expiresAt: now.Add(c.ttl),
+	}
+}
+
+// Invalidate bumps a client's KB version, dropping its cached results
+func (c *SearchCache) Invalidate(clientID string) {
+	c.mu.Lock()
+	c.versions[clientID]++
+	c.mu.Unlock()
+	c.invalidations.Add(1)
+}
+
+// Version returns a client's KB version as seen by the cache
+func (c *SearchCache) Version(clientID string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.versions[clientID]
+}
+
+// Stats returns the cache counters
+func (c *SearchCache) Stats() SearchCacheStats {
+	c.mu.Lock()
+	entries := len(c.entries)
+	c.mu.Unlock()
+
+	stats := SearchCacheStats{
+		Enabled:       true,
+		TTLSeconds:    c.ttl.Seconds(),
+		Entries:       entries,
+		MaxEntries:    c.maxEntries,
+		Hits:          c.hits.Load(),
+		Misses:        c.misses.Load(),
+		Invalidations: c.invalidations.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// prune drops expired and outdated entries, and everything if the cache is still full. Callers hold mu.
+func (c *SearchCache) prune(now time.Time) {
+	for key, entry := range c.entries {
+		clientID, _, _ := strings.Cut(key, "|")
+		if now.After(entry.expiresAt) || entry.version != c.versions[clientID] {
+			delete(c.entries, key)
+		}
+	}
+	if len(c.entries) >= c.maxEntries {
+		c.entries = make(map[string]searchCacheEntry)
+	}
+}
+
+// searchCacheKey matches queries that only differ in case or spacing
+func searchCacheKey(clientID, docType, query string, limit int) string {
+	query = strings.ToLower(strings.Join(strings.Fields(query), " "))
+	return fmt.Sprintf("%s|%s|%d|%s", clientID, docType, limit, query)
+}
@@ -7,13 +7,29 @@ import (
 	"log"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/vector"
-	"github.com/google/uuid"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 )
 
 // VectorRetriever provides semantic search for knowledge base using vector database
 type VectorRetriever struct {
 	vectorService *vector.Service
 	collection    string
+	cache         *SearchCache // Optional, nil disables result caching
+}
+
+// payloadIndexes are the payload fields every knowledge base search filters on
+var payloadIndexes = []vector.PayloadIndex{
+	{Field: "client_id", Type: vector.PayloadIndexKeyword, Tenant: true},
+	{Field: "doc_type", Type: vector.PayloadIndexKeyword},
+}
+
+// IndexStatus reports the payload indexes of the knowledge base collection
+type IndexStatus struct {
+	Collection string            `json:"collection"`
+	Points     int64             `json:"points"`
+	Indexes    map[string]string `json:"indexes"` // Indexed field -> index type
+	Missing    []string          `json:"missing"` // Fields searches filter on that have no index
+	Created    []string          `json:"created,omitempty"`
 }
 
 // NewVectorRetriever creates a new vector-powered retriever
@@ -24,6 +40,19 @@ func NewVectorRetriever(vectorService *vector.Service, collection string) *Vecto
 	}
 }
 
+// SetSearchCache enables caching of search results, invalidated on every write to a client's vectors
+func (r *VectorRetriever) SetSearchCache(cache *SearchCache) {
+	r.cache = cache
+}
+
+// SearchCacheStats reports the search cache hit rate, or a disabled status without a cache
+func (r *VectorRetriever) SearchCacheStats() SearchCacheStats {
+	if r.cache == nil {
+		return SearchCacheStats{}
+	}
+	return r.cache.Stats()
+}
+
 // Initialize initializes the vector collection for knowledge base
 func (r *VectorRetriever) Initialize(ctx context.Context) error {
 	log.Printf("🔍 Initializing Vector KB collection: %s", r.collection)
@@ -40,9 +69,64 @@ func (r *VectorRetriever) Initialize(ctx context.Context) error {
 		log.Printf("✅ Collection '%s' created", r.collection)
 	}
 
+	// Without payload indexes every client_id filter scans the whole collection; search still works, so only warn
+	status, err := r.EnsureIndexes(ctx)
+	if err != nil {
+		log.Printf("⚠️ Failed to create payload indexes on '%s': %v", r.collection, err)
+	} else if len(status.Created) > 0 {
+		log.Printf("✅ Payload indexes created on '%s': %v", r.collection, status.Created)
+	}
+
 	return nil
 }
 
+// Ping checks that the vector DB answers for the knowledge base collection
+func (r *VectorRetriever) Ping(ctx context.Context) error {
+	if _, err := r.vectorService.GetCollectionInfo(ctx, r.collection); err != nil {
+		return fmt.Errorf("failed to get collection info: %w", err)
+	}
+	return nil
+}
+
+// GetIndexStatus lists the payload indexes of the collection and the ones missing
+func (r *VectorRetriever) GetIndexStatus(ctx context.Context) (*IndexStatus, error) {
+	info, err := r.vectorService.GetCollectionInfo(ctx, r.collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get collection info: %w", err)
+	}
+
+	status := &IndexStatus{
+		Collection: r.collection,
+		Points:     info.PointsCount,
+		Indexes:    info.PayloadIndexes,
+		Missing:    []string{},
+	}
+	if status.Indexes == nil {
+		status.Indexes = map[string]string{}
+	}
+	for _, index := range payloadIndexes {
+		if _, ok := status.Indexes[index.Field]; !ok {
+			status.Missing = append(status.Missing, index.Field)
+		}
+	}
+	return status, nil
+}
+
+// EnsureIndexes creates the missing payload indexes, e.g. on collections created before indexing was added
+func (r *VectorRetriever) EnsureIndexes(ctx context.Context) (*IndexStatus, error) {
+	created, err := r.vectorService.EnsurePayloadIndexes(ctx, r.collection, payloadIndexes)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := r.GetIndexStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+	status.Created = created
+	return status, nil
+}
+
 // AddDocument adds a knowledge base document to the vector database
 func (r *VectorRetriever) AddDocument(ctx context.Context, clientID, docType, docID, text string, metadata map[string]interface{}) error {
 	// Prepare document metadata
@@ -61,7 +145,9 @@ func (r *VectorRetriever) AddDocument(ctx context.Context, clientID, docType, do
 	vectorID := fmt.Sprintf("%s_%s_%s", clientID, docType, docID)
 
 	// Add to vector database
-	return r.vectorService.AddDocument(ctx, r.collection, vectorID, text, docMetadata)
+	err := r.vectorService.AddDocument(ctx, r.collection, vectorID, text, docMetadata)
+	r.invalidate(clientID)
+	return err
 }
 
 // AddFAQ adds an FAQ to the knowledge base
@@ -98,6 +184,14 @@ func (r *VectorRetriever) AddProduct(ctx context.Context, clientID, productID, n
 
 // Search performs semantic search in the knowledge base
 func (r *VectorRetriever) Search(ctx context.Context, clientID, query string, limit int) ([]SearchResult, error) {
+	var version uint64
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(clientID, "", query, limit); ok {
+			return cached, nil
+		}
+		version = r.cache.Version(clientID)
+	}
+
 	// Create filter for client-specific search
 	filter := &vector.Filter{
 		Must: []vector.Condition{
@@ -126,11 +220,22 @@ func (r *VectorRetriever) Search(ctx context.Context, clientID, query string, li
 		}
 	}
 
+	if r.cache != nil {
+		r.cache.Set(clientID, "", query, limit, version, kbResults)
+	}
 	return kbResults, nil
 }
 
 // SearchByType performs semantic search filtered by document type
 func (r *VectorRetriever) SearchByType(ctx context.Context, clientID, query, docType string, limit int) ([]SearchResult, error) {
+	var version uint64
+	if r.cache != nil {
+		if cached, ok := r.cache.Get(clientID, docType, query, limit); ok {
+			return cached, nil
+		}
+		version = r.cache.Version(clientID)
+	}
+
 	filter := &vector.Filter{
 		Must: []vector.Condition{
 			{
@@ -160,13 +265,25 @@ func (r *VectorRetriever) SearchByType(ctx context.Context, clientID, query, doc
 		}
 	}
 
+	if r.cache != nil {
+		r.cache.Set(clientID, docType, query, limit, version, kbResults)
+	}
 	return kbResults, nil
 }
 
 // DeleteDocument removes a document from the vector database
 func (r *VectorRetriever) DeleteDocument(ctx context.Context, clientID, docType, docID string) error {
 	vectorID := fmt.Sprintf("%s_%s_%s", clientID, docType, docID)
-	return r.vectorService.DeleteDocument(ctx, r.collection, vectorID)
+	err := r.vectorService.DeleteDocument(ctx, r.collection, vectorID)
+	r.invalidate(clientID)
+	return err
+}
+
+// invalidate bumps the client's KB version after a write, even a failed one that may have partly applied
+func (r *VectorRetriever) invalidate(clientID string) {
+	if r.cache != nil {
+		r.cache.Invalidate(clientID)
+	}
 }
 
 // GetRelevantContext retrieves relevant context for LLM from vector search
@@ -208,36 +325,60 @@ func (r *VectorRetriever) GetRelevantContext(ctx context.Context, clientID, user
 	return context, nil
 }
 
-// SyncFromDatabase syncs knowledge base from PostgreSQL to vector database
-// This is useful for initial migration or periodic sync
+// SyncFromDatabase indexes a client's knowledge base entries from PostgreSQL under their stable IDs,
+// so running it again updates the vectors instead of duplicating them. Inactive entries are removed.
 func (r *VectorRetriever) SyncFromDatabase(ctx context.Context, dbRetriever *Retriever, clientID string) error {
 	log.Printf("🔄 Syncing KB from database to vector DB for client: %s", clientID)
 
-	// Get knowledge base from database
-	kb, err := dbRetriever.GetKnowledgeBase(clientID)
+	entries, err := dbRetriever.ListEntries(clientID)
 	if err != nil {
 		return fmt.Errorf("failed to get KB from database: %w", err)
 	}
 
-	// Sync FAQs
-	for i, faq := range kb.FAQs {
-		faqID := uuid.New().String()
-		if err := r.AddFAQ(ctx, clientID, faqID, faq.Question, faq.Answer); err != nil {
-			log.Printf("⚠️  Failed to sync FAQ %d: %v", i, err)
+	synced := 0
+	for i := range entries {
+		if err := r.IndexEntry(ctx, &entries[i]); err != nil {
+			log.Printf("⚠️  Failed to sync KB entry %s: %v", entries[i].ID, err)
+			continue
 		}
+		synced++
 	}
-	log.Printf("✅ Synced %d FAQs", len(kb.FAQs))
+	log.Printf("✅ Synced %d/%d KB entries", synced, len(entries))
 
-	// Sync Products
-	for i, product := range kb.Products {
-		productID := uuid.New().String()
-		if err := r.AddProduct(ctx, clientID, productID, product.Name, "", product.Price, nil); err != nil {
-			log.Printf("⚠️  Failed to sync product %d: %v", i, err)
-		}
+	return nil
+}
+
+// IndexEntry writes a knowledge base entry to the vector index under its own ID, using the FAQ and product
+// layouts where they apply. An inactive entry is removed instead.
+func (r *VectorRetriever) IndexEntry(ctx context.Context, entry *models.KnowledgeBaseEntry) error {
+	clientID, id := entry.ClientID.String(), entry.ID.String()
+	if !entry.IsActive {
+		return r.DeleteDocument(ctx, clientID, entry.Type, id)
 	}
-	log.Printf("✅ Synced %d products", len(kb.Products))
 
-	return nil
+	var content map[string]interface{}
+	_ = json.Unmarshal(entry.Content, &content)
+
+	switch entry.Type {
+	case "faq":
+		question, _ := content["question"].(string)
+		answer, _ := content["answer"].(string)
+		if question == "" {
+			question = entry.Title
+		}
+		return r.AddFAQ(ctx, clientID, id, question, answer)
+	case "product":
+		name, _ := content["name"].(string)
+		description, _ := content["description"].(string)
+		price, _ := content["price"].(float64)
+		if name == "" {
+			name = entry.Title
+		}
+		return r.AddProduct(ctx, clientID, id, name, description, price, nil)
+	default:
+		text := entry.Title + "\n" + string(entry.Content)
+		return r.AddDocument(ctx, clientID, entry.Type, id, text, map[string]interface{}{"title": entry.Title})
+	}
 }
 
 // SearchResult represents a knowledge base search result
@@ -0,0 +1,408 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BenchmarkCase is one prompt of the benchmark suite, scored on the response
+type BenchmarkCase struct {
+	Name         string
+	SystemPrompt string
+	UserMessage  string
+	Score        func(response string) float64 // Quality, 0-1
+}
+
+// BenchmarkTarget is a provider and model to benchmark
+type BenchmarkTarget struct {
+	Provider ProviderType `json:"provider" example:"openai"`
+	Model    string       `json:"model" example:"gpt-4o-mini"`
+}
+
+// BenchmarkCaseResult is the outcome of one case against one target
+type BenchmarkCaseResult struct {
+	Name      string  `json:"name"`
+	LatencyMs int64   `json:"latency_ms"`
+	Quality   float64 `json:"quality"` // 0-1, 0 when the call failed
+	Error     string  `json:"error,omitempty"`
+}
+
+// BenchmarkResult aggregates the suite of one target
+type BenchmarkResult struct {
+	Target           BenchmarkTarget       `json:"target"`
+	Cases            []BenchmarkCaseResult `json:"cases"`
+	SuccessRate      float64               `json:"success_rate"` // Calls answered without error, 0-1
+	AvgLatencyMs     int64                 `json:"avg_latency_ms"`
+	P95LatencyMs     int64                 `json:"p95_latency_ms"`
+	Quality          float64               `json:"quality"`            // Mean case quality, 0-1
+	EstimatedCostUSD float64               `json:"estimated_cost_usd"` // Of the whole suite, from estimated tokens
+	CostPer1KUSD     float64               `json:"cost_per_1k_usd"`    // Estimated cost of 1000 average replies
+	PriceKnown       bool                  `json:"price_known"`        // False when the model isn't in the price table
+	HealthScore      float64               `json:"health_score"`       // 0-100, relative to the other targets of the run
+}
+
+// ModelPrice is the list price of a model in USD per million tokens
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+// defaultModelPrices are list prices at the time of writing; LLM_BENCHMARK_PRICES overrides them
+// ("model=input/output,..." in USD per million tokens) when providers change pricing
+var defaultModelPrices = map[string]ModelPrice{
+	"gpt-4o-mini":                {Input: 0.15, Output: 0.60},
+	"gpt-4o":                     {Input: 2.50, Output: 10.00},
+	"gpt-4.1-mini":               {Input: 0.40, Output: 1.60},
+	"gpt-4.1":                    {Input: 2.00, Output: 8.00},
+	"gemini-2.0-flash":           {Input: 0.10, Output: 0.40},
+	"gemini-2.5-flash":           {Input: 0.30, Output: 2.50},
+	"gemini-2.5-pro":             {Input: 1.25, Output: 10.00},
+	"llama-3.1-8b-instant":       {Input: 0.05, Output: 0.08},
+	"llama-3.3-70b-versatile":    {Input: 0.59, Output: 0.79},
+	"deepseek-chat":              {Input: 0.27, Output: 1.10},
+	"claude-3-5-haiku-20241022":  {Input: 0.80, Output: 4.00},
+	"claude-3-5-sonnet-20241022": {Input: 3.00, Output: 15.00},
+}
+
+// Health score weights, summing to 100
+const (
+	benchmarkWeightQuality = 50
+	benchmarkWeightSuccess = 20
+	benchmarkWeightLatency = 15
+	benchmarkWeightCost    = 15
+
+	// BenchmarkMinSuccessRate is the success rate a target needs to be recommended
+	BenchmarkMinSuccessRate = 0.8
+)
+
+var benchmarkYearsPattern = regexp.MustCompile(`\d+\s*(tahun|thn|year)`)
+
+// benchmarkStore is the shop of the suite's customer service prompts
+const benchmarkStore = `Kamu adalah customer service Toko Kopi Senja. Jawab singkat dan ramah dalam Bahasa Indonesia, hanya berdasarkan informasi berikut. Jika informasinya tidak ada, katakan belum tahu dan tawarkan menghubungkan ke admin.
+
+Jam buka: Senin-Sabtu 08.00-21.00, Minggu tutup.
+Pengiriman: seluruh Indonesia via JNE dan SiCepat.
+Pembayaran: transfer BCA, QRIS, dan COD khusus area Jakarta.`
+
+// BenchmarkSuite is the standard prompt suite: typical tasks of the WhatsApp bot (grounded answers in
+// Indonesian, not inventing facts, JSON extraction, short replies), scored without another LLM
+func BenchmarkSuite() []BenchmarkCase {
+	return []BenchmarkCase{
+		{
+			Name:         "faq_grounded",
+			SystemPrompt: benchmarkStore,
+			UserMessage:  "Kak, hari minggu buka ga?",
+			Score: func(response string) float64 {
+				text := strings.ToLower(response)
+				return benchmarkScore(
+					benchmarkCheck{0.7, containsAny(text, "tutup", "tidak buka", "libur")},
+					benchmarkCheck{0.3, repliesInIndonesian(response)},
+				)
+			},
+		},
+		{
+			Name:         "faq_reasoning",
+			SystemPrompt: benchmarkStore,
+			UserMessage:  "Bisa bayar COD ga kalau kirim ke Bandung?",
+			Score: func(response string) float64 {
+				text := strings.ToLower(response)
+				return benchmarkScore(
+					benchmarkCheck{0.5, strings.Contains(text, "jakarta")},
+					benchmarkCheck{0.5, containsAny(text, "tidak", "belum", "hanya", "khusus", "maaf")},
+				)
+			},
+		},
+		{
+			Name:         "no_hallucination",
+			SystemPrompt: benchmarkStore,
+			UserMessage:  "Garansi mesin kopinya berapa tahun kak?",
+			Score: func(response string) float64 {
+				text := strings.ToLower(response)
+				if benchmarkYearsPattern.MatchString(text) {
+					return 0 // Invented a warranty period
+				}
+				return benchmarkScore(
+					benchmarkCheck{0.5, true},
+					benchmarkCheck{0.5, containsAny(text, "belum", "tidak", "maaf", "admin", "konfirmasi", "tanyakan")},
+				)
+			},
+		},
+		{
+			Name:         "order_extraction",
+			SystemPrompt: `Extract the order from the customer's message. Reply ONLY with JSON: {"items":[{"product":"...","quantity":1}]}`,
+			UserMessage:  "mau pesan 2 kopi susu gula aren sama 1 croissant coklat ya kak",
+			Score: func(response string) float64 {
+				var order struct {
+					Items []struct {
+						Product  string `json:"product"`
+						Quantity int    `json:"quantity"`
+					} `json:"items"`
+				}
+				if json.Unmarshal([]byte(ExtractJSON(response)), &order) != nil {
+					return 0
+				}
+				quantities := map[string]int{}
+				for _, item := range order.Items {
+					product := strings.ToLower(item.Product)
+					switch {
+					case strings.Contains(product, "kopi"):
+						quantities["kopi"] = item.Quantity
+					case strings.Contains(product, "croissant"):
+						quantities["croissant"] = item.Quantity
+					}
+				}
+				return benchmarkScore(
+					benchmarkCheck{0.3, len(order.Items) == 2},
+					benchmarkCheck{0.35, quantities["kopi"] == 2},
+					benchmarkCheck{0.35, quantities["croissant"] == 1},
+				)
+			},
+		},
+		{
+			Name:         "receipt_extraction",
+			SystemPrompt: `Extract the store name and the total amount from receipt OCR text. Reply ONLY with JSON: {"store_name":"...","total_amount":0}. total_amount is a number without separators.`,
+			UserMessage:  "Karis Jaya Shop\nJl. Merdeka 10\n1. Indomie Goreng\n1 lusin x 36,000\n2. Teh Botol\n2 x 17.000\nTotal: Rp 70.000\nTerima kasih",
+			Score: func(response string) float64 {
+				var receipt struct {
+					StoreName   string  `json:"store_name"`
+					TotalAmount float64 `json:"total_amount"`
+				}
+				if json.Unmarshal([]byte(ExtractJSON(response)), &receipt) != nil {
+					return 0
+				}
+				return benchmarkScore(
+					benchmarkCheck{0.3, true},
+					benchmarkCheck{0.5, receipt.TotalAmount == 70000},
+					benchmarkCheck{0.2, strings.Contains(strings.ToLower(receipt.StoreName), "karis")},
+				)
+			},
+		},
+		{
+			Name:         "short_reply",
+			SystemPrompt: benchmarkStore + "\n\nBalas maksimal 2 kalimat, tanpa format markdown.",
+			UserMessage:  "makasih ya kak pesanannya udah sampai, kopinya enak banget",
+			Score: func(response string) float64 {
+				return benchmarkScore(
+					benchmarkCheck{0.5, len([]rune(strings.TrimSpace(response))) <= 300},
+					benchmarkCheck{0.3, repliesInIndonesian(response)},
+					benchmarkCheck{0.2, !containsAny(response, "**", "##", "- ")},
+				)
+			},
+		},
+	}
+}
+
+// RunBenchmark runs the suite against one provider, one case after the other so latencies don't interfere
+func RunBenchmark(ctx context.Context, provider LLMProvider, target BenchmarkTarget, suite []BenchmarkCase, prices map[string]ModelPrice) BenchmarkResult {
+	result := BenchmarkResult{Target: target, Cases: make([]BenchmarkCaseResult, 0, len(suite))}
+	price, priceKnown := prices[target.Model]
+	result.PriceKnown = priceKnown
+
+	var latencies []int64
+	var inputTokens, outputTokens int
+	succeeded := 0
+	qualityTotal := 0.0
+	for _, tc := range suite {
+		start := time.Now()
+		response, err := provider.GenerateResponse(ctx, tc.SystemPrompt, tc.UserMessage)
+		caseResult := BenchmarkCaseResult{Name: tc.Name, LatencyMs: time.Since(start).Milliseconds()}
+		if err != nil {
+			caseResult.Error = err.Error()
+		} else {
+			succeeded++
+			caseResult.Quality = tc.Score(response)
+			latencies = append(latencies, caseResult.LatencyMs)
+			inputTokens += estimateTokens(tc.SystemPrompt) + estimateTokens(tc.UserMessage)
+			outputTokens += estimateTokens(response)
+		}
+		qualityTotal += caseResult.Quality
+		result.Cases = append(result.Cases, caseResult)
+	}
+
+	if len(suite) > 0 {
+		result.SuccessRate = float64(succeeded) / float64(len(suite))
+		result.Quality = qualityTotal / float64(len(suite))
+	}
+	if len(latencies) > 0 {
+		var total int64
+		for _, latency := range latencies {
+			total += latency
+		}
+		result.AvgLatencyMs = total / int64(len(latencies))
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		result.P95LatencyMs = latencies[(len(latencies)*95+99)/100-1]
+	}
+	result.EstimatedCostUSD = (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1_000_000
+	if succeeded > 0 {
+		result.CostPer1KUSD = result.EstimatedCostUSD / float64(succeeded) * 1000
+	}
+	return result
+}
+
+// ScoreBenchmarks sets the health score of each result from its quality and success rate and from its
+// latency and cost relative to the best of the run. Returns the index of the recommended result:
+// the healthiest with at least BenchmarkMinSuccessRate, or -1 if none qualifies.
+func ScoreBenchmarks(results []BenchmarkResult) int {
+	var fastest int64
+	cheapest := -1.0
+	for _, result := range results {
+		if result.AvgLatencyMs > 0 && (fastest == 0 || result.AvgLatencyMs < fastest) {
+			fastest = result.AvgLatencyMs
+		}
+		if result.PriceKnown && result.CostPer1KUSD > 0 && (cheapest < 0 || result.CostPer1KUSD < cheapest) {
+			cheapest = result.CostPer1KUSD
+		}
+	}
+
+	recommended := -1
+	for i := range results {
+		result := &results[i]
+
+		latencyScore := 0.0
+		if result.AvgLatencyMs > 0 {
+			latencyScore = float64(fastest) / float64(result.AvgLatencyMs)
+		}
+		costScore := 0.5 // Unknown price: neither rewarded nor penalized
+		if result.PriceKnown && result.CostPer1KUSD > 0 {
+			costScore = cheapest / result.CostPer1KUSD
+		} else if result.PriceKnown {
+			costScore = 1
+		}
+		if result.SuccessRate == 0 {
+			latencyScore, costScore = 0, 0
+		}
+
+		score := benchmarkWeightQuality*result.Quality +
+			benchmarkWeightSuccess*result.SuccessRate +
+			benchmarkWeightLatency*latencyScore +
+			benchmarkWeightCost*costScore
+		result.HealthScore = float64(int(score*10+0.5)) / 10
+
+		if result.SuccessRate >= BenchmarkMinSuccessRate && (recommended < 0 || result.HealthScore > results[recommended].HealthScore) {
+			recommended = i
+		}
+	}
+	return recommended
+}
+
+// BenchmarkPrices returns the model price table with the LLM_BENCHMARK_PRICES overrides applied
+func BenchmarkPrices() map[string]ModelPrice {
+	prices := make(map[string]ModelPrice, len(defaultModelPrices))
+	for model, price := range defaultModelPrices {
+		prices[model] = price
+	}
+
+	// Format: "gpt-4o-mini=0.15/0.60,gemini-2.5-flash=0.30/2.50"
+	for _, item := range strings.Split(os.Getenv("LLM_BENCHMARK_PRICES"), ",") {
+		model, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		inStr, outStr, ok := strings.Cut(value, "/")
+		input, inErr := strconv.ParseFloat(strings.TrimSpace(inStr), 64)
+		output, outErr := strconv.ParseFloat(strings.TrimSpace(outStr), 64)
+		if !ok || inErr != nil || outErr != nil {
+			continue
+		}
+		prices[strings.TrimSpace(model)] = ModelPrice{Input: input, Output: output}
+	}
+	return prices
+}
+
+// EstimateCostUSD estimates the cost of one call from the length of its prompt and response
+func EstimateCostUSD(price ModelPrice, prompt, response string) float64 {
+	return (float64(estimateTokens(prompt))*price.Input + float64(estimateTokens(response))*price.Output) / 1_000_000
+}
+
+// BenchmarkTargets lists what to benchmark: the model in use, the default model of every provider
+// with an API key, and the models in LLM_BENCHMARK_MODELS ("openai:gpt-4o,gemini:gemini-2.5-pro")
+func BenchmarkTargets(cfg *ProviderConfig) []BenchmarkTarget {
+	var targets []BenchmarkTarget
+	seen := map[BenchmarkTarget]bool{}
+	add := func(target BenchmarkTarget) {
+		if !seen[target] && ProviderHasKey(cfg, target.Provider) {
+			seen[target] = true
+			targets = append(targets, target)
+		}
+	}
+
+	add(BenchmarkTarget{Provider: cfg.Type, Model: cfg.Model})
+	for _, providerType := range []ProviderType{ProviderOpenAI, ProviderGemini, ProviderGroq, ProviderDeepSeek, ProviderClaude} {
+		add(BenchmarkTarget{Provider: providerType, Model: DefaultModel(providerType)})
+	}
+	for _, item := range strings.Split(os.Getenv("LLM_BENCHMARK_MODELS"), ",") {
+		providerType, model, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if ok && model != "" {
+			add(BenchmarkTarget{Provider: ProviderType(providerType), Model: model})
+		}
+	}
+	return targets
+}
+
+// ProviderHasKey reports whether the API key of a provider is configured (the mock needs none)
+func ProviderHasKey(cfg *ProviderConfig, providerType ProviderType) bool {
+	switch providerType {
+	case ProviderOpenAI:
+		return cfg.OpenAIKey != ""
+	case ProviderGemini:
+		return cfg.GeminiKey != ""
+	case ProviderGroq:
+		return cfg.GroqKey != ""
+	case ProviderDeepSeek:
+		return cfg.DeepSeekKey != ""
+	case ProviderClaude:
+		return cfg.ClaudeKey != ""
+	case ProviderMock:
+		return true
+	}
+	return false
+}
+
+// estimateTokens approximates the token count of a text (~4 characters per token)
+func estimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
+
+// benchmarkCheck is a weighted pass/fail check of a response
+type benchmarkCheck struct {
+	weight float64
+	passed bool
+}
+
+// benchmarkScore adds up the weights of the checks that passed
+func benchmarkScore(checks ...benchmarkCheck) float64 {
+	total := 0.0
+	for _, check := range checks {
+		if check.passed {
+			total += check.weight
+		}
+	}
+	return total
+}
+
+// repliesInIndonesian reports whether a reply isn't in another language; short replies often have too few markers to detect Indonesian
+func repliesInIndonesian(response string) bool {
+	lang := DetectLanguage(response)
+	return lang == "id" || lang == ""
+}
+
+func containsAny(text string, substrings ...string) bool {
+	for _, s := range substrings {
+		if strings.Contains(text, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// String identifies a target in logs
+func (t BenchmarkTarget) String() string {
+	return fmt.Sprintf("%s/%s", t.Provider, t.Model)
+}
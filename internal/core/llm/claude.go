@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -51,11 +52,31 @@ type claudeRequest struct {
 	Temperature float32          `json:"temperature"`
 	Messages    []claudeMessage  `json:"messages"`
 	System      string           `json:"system,omitempty"`
+	Stream      bool             `json:"stream,omitempty"`
+	Tools       []claudeTool     `json:"tools,omitempty"`
+	ToolChoice  interface{}      `json:"tool_choice,omitempty"`
 }
 
 type claudeMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string      `json:"role"`
+	Content interface{} `json:"content"` // Text, or []claudeContentBlock with tool calls and results
+}
+
+type claudeTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// claudeContentBlock is a text, tool_use or tool_result block of a message
+type claudeContentBlock struct {
+	Type      string          `json:"type"`
+	Text      string          `json:"text,omitempty"`
+	ID        string          `json:"id,omitempty"`
+	Name      string          `json:"name,omitempty"`
+	Input     json.RawMessage `json:"input,omitempty"`
+	ToolUseID string          `json:"tool_use_id,omitempty"`
+	Content   string          `json:"content,omitempty"`
 }
 
 type claudeResponse struct {
@@ -65,62 +86,226 @@ type claudeResponse struct {
 }
 
 func (p *ClaudeProvider) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
-	url := "https://api.anthropic.com/v1/messages"
+	return p.GenerateResponseWithHistory(ctx, systemPrompt, nil, userMessage)
+}
 
-	reqBody := claudeRequest{
-		Model:       p.model,
-		MaxTokens:   p.maxTokens,
-		Temperature: p.temperature,
-		Messages: []claudeMessage{
-			{
-				Role:    "user",
-				Content: userMessage,
-			},
-		},
+func (p *ClaudeProvider) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
+	resp, err := p.post(ctx, "https://api.anthropic.com/v1/messages", p.request(systemPrompt, history, userMessage))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("claude error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var claudeResp claudeResponse
+	if err := json.Unmarshal(body, &claudeResp); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	// Add system prompt if provided
-	if systemPrompt != "" {
-		reqBody.System = systemPrompt
+	if len(claudeResp.Content) == 0 {
+		return "", fmt.Errorf("no response from Claude")
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	return claudeResp.Content[0].Text, nil
+}
+
+// claudeStreamEvent is one server-sent event of a streamed message
+type claudeStreamEvent struct {
+	Type  string `json:"type"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Error struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// StreamResponse streams the message with server-sent events, passing each text delta to onDelta
+func (p *ClaudeProvider) StreamResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, onDelta func(string)) (string, error) {
+	reqBody := p.request(systemPrompt, history, userMessage)
+	reqBody.Stream = true
+
+	resp, err := p.post(ctx, "https://api.anthropic.com/v1/messages", reqBody)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
+		return "", err
 	}
+	defer resp.Body.Close()
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("claude error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var response strings.Builder
+	err = readSSE(resp.Body, func(data []byte) error {
+		var event claudeStreamEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("failed to parse stream event: %w", err)
+		}
+		switch event.Type {
+		case "content_block_delta":
+			if event.Delta.Type == "text_delta" && event.Delta.Text != "" {
+				response.WriteString(event.Delta.Text)
+				onDelta(event.Delta.Text)
+			}
+		case "error":
+			return fmt.Errorf("claude stream error (model: %s): %s", p.model, event.Error.Message)
+		}
+		return nil
+	})
 	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+		return response.String(), err
+	}
+	if response.Len() == 0 {
+		return "", fmt.Errorf("no response from Claude")
 	}
+	return response.String(), nil
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", p.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+// GenerateWithTools offers the tools with tool use; earlier rounds are replayed as tool_use and tool_result blocks
+func (p *ClaudeProvider) GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) (string, []ToolCall, error) {
+	reqBody := p.request(systemPrompt, history, userMessage)
+	for _, step := range steps {
+		var used, results []claudeContentBlock
+		if step.Text != "" {
+			used = append(used, claudeContentBlock{Type: "text", Text: step.Text})
+		}
+		for i, call := range step.Calls {
+			used = append(used, claudeContentBlock{Type: "tool_use", ID: call.ID, Name: call.Name, Input: call.Arguments})
+			results = append(results, claudeContentBlock{Type: "tool_result", ToolUseID: call.ID, Content: step.Results[i]})
+		}
+		reqBody.Messages = append(reqBody.Messages,
+			claudeMessage{Role: "assistant", Content: used},
+			claudeMessage{Role: "user", Content: results},
+		)
+	}
+	for _, tool := range tools {
+		schema := tool.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		reqBody.Tools = append(reqBody.Tools, claudeTool{Name: tool.Name, Description: tool.Description, InputSchema: schema})
+	}
+	if answerOnly && len(reqBody.Tools) > 0 {
+		reqBody.ToolChoice = map[string]string{"type": "none"}
+	}
 
-	resp, err := p.client.Do(req)
+	resp, err := p.post(ctx, "https://api.anthropic.com/v1/messages", reqBody)
 	if err != nil {
-		return "", fmt.Errorf("claude request failed: %w", err)
+		return "", nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response: %w", err)
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
 	}
-
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("claude error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+		return "", nil, fmt.Errorf("claude error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
 	}
 
-	var claudeResp claudeResponse
+	var claudeResp struct {
+		Content []claudeContentBlock `json:"content"`
+	}
 	if err := json.Unmarshal(body, &claudeResp); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+		return "", nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
-	if len(claudeResp.Content) == 0 {
-		return "", fmt.Errorf("no response from Claude")
+	var text strings.Builder
+	var calls []ToolCall
+	for _, block := range claudeResp.Content {
+		switch block.Type {
+		case "text":
+			text.WriteString(block.Text)
+		case "tool_use":
+			calls = append(calls, ToolCall{ID: block.ID, Name: block.Name, Arguments: toolArguments(string(block.Input))})
+		}
+	}
+	if text.Len() == 0 && len(calls) == 0 {
+		return "", nil, fmt.Errorf("no response from Claude")
 	}
+	return text.String(), calls, nil
+}
 
-	return claudeResp.Content[0].Text, nil
+// CountTokens counts the input tokens of a message with the token counting API
+func (p *ClaudeProvider) CountTokens(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (int, error) {
+	reqBody := p.request(systemPrompt, history, userMessage)
+	countBody := map[string]interface{}{
+		"model":    reqBody.Model,
+		"messages": reqBody.Messages,
+	}
+	if reqBody.System != "" {
+		countBody["system"] = reqBody.System
+	}
+
+	resp, err := p.post(ctx, "https://api.anthropic.com/v1/messages/count_tokens", countBody)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("claude error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var counted struct {
+		InputTokens int `json:"input_tokens"`
+	}
+	if err := json.Unmarshal(body, &counted); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return counted.InputTokens, nil
+}
+
+// request builds a Messages API request: earlier turns first, then the user message
+func (p *ClaudeProvider) request(systemPrompt string, history []ChatMessage, userMessage string) claudeRequest {
+	messages := make([]claudeMessage, 0, len(history)+1)
+	for _, msg := range history {
+		messages = append(messages, claudeMessage{Role: msg.Role, Content: msg.Content})
+	}
+	messages = append(messages, claudeMessage{Role: "user", Content: userMessage})
+
+	return claudeRequest{
+		Model:       p.model,
+		MaxTokens:   p.maxTokens,
+		Temperature: p.temperature,
+		Messages:    messages,
+		System:      systemPrompt,
+	}
+}
+
+// post sends a JSON request to the Anthropic API
+func (p *ClaudeProvider) post(ctx context.Context, url string, body interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", "2023-06-01")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("claude request failed: %w", err)
+	}
+	return resp, nil
 }
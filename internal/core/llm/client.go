@@ -30,7 +30,9 @@ func NewClient(apiKey string) *Client {
 
 // GenerateResponse generates AI response (uses provider pattern now)
 func (c *Client) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
-	return c.provider.GenerateResponse(ctx, systemPrompt, userMessage)
+	return observeCall(ctx, c.provider.GetProviderName(), "generate", promptChars(systemPrompt, nil, userMessage), func(ctx context.Context) (string, error) {
+		return c.provider.GenerateResponse(ctx, systemPrompt, userMessage)
+	})
 }
 
 // GenerateResponseWithFunctions untuk AI Actions (nanti)
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -48,6 +49,8 @@ func (p *GeminiProvider) GetProviderName() string {
 type geminiRequest struct {
 	Contents         []geminiContent        `json:"contents"`
 	GenerationConfig geminiGenerationConfig `json:"generationConfig"`
+	Tools            []geminiTool           `json:"tools,omitempty"`
+	ToolConfig       *geminiToolConfig      `json:"toolConfig,omitempty"`
 }
 
 type geminiContent struct {
@@ -56,66 +59,92 @@ type geminiContent struct {
 }
 
 type geminiPart struct {
-	Text string `json:"text"`
+	Text             string                  `json:"text,omitempty"`
+	FunctionCall     *geminiFunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *geminiFunctionResponse `json:"functionResponse,omitempty"`
+}
+
+type geminiFunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+type geminiFunctionResponse struct {
+	Name     string                 `json:"name"`
+	Response map[string]interface{} `json:"response"`
+}
+
+type geminiTool struct {
+	FunctionDeclarations []geminiFunctionDeclaration `json:"functionDeclarations"`
+}
+
+type geminiFunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+type geminiToolConfig struct {
+	FunctionCallingConfig struct {
+		Mode string `json:"mode"` // AUTO or NONE
+	} `json:"functionCallingConfig"`
 }
 
 type geminiGenerationConfig struct {
-	Temperature     float32 `json:"temperature"`
-	MaxOutputTokens int     `json:"maxOutputTokens"`
+	Temperature      float32                `json:"temperature"`
+	MaxOutputTokens  int                    `json:"maxOutputTokens"`
+	ResponseMimeType string                 `json:"responseMimeType,omitempty"`
+	ResponseSchema   map[string]interface{} `json:"responseSchema,omitempty"`
 }
 
 type geminiResponse struct {
 	Candidates []struct {
 		Content struct {
 			Parts []struct {
-				Text string `json:"text"`
+				Text         string              `json:"text"`
+				FunctionCall *geminiFunctionCall `json:"functionCall"`
 			} `json:"parts"`
 		} `json:"content"`
 	} `json:"candidates"`
 }
 
 func (p *GeminiProvider) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
-	// Use REST API v1 endpoint (not v1beta)
-	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1/models/%s:generateContent?key=%s",
-		p.model, p.apiKey)
+	return p.GenerateResponseWithHistory(ctx, systemPrompt, nil, userMessage)
+}
 
-	// Build contents - combine system prompt with user message
-	var contents []geminiContent
+func (p *GeminiProvider) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
+	return p.generateContent(ctx, systemPrompt, history, userMessage, nil)
+}
 
-	// For Gemini v1 API, system instruction should be part of the first user message
-	userText := userMessage
-	if systemPrompt != "" {
-		userText = systemPrompt + "\n\n" + userMessage
-	}
+// GenerateStructuredResponse constrains the response to the schema with Gemini's response schema
+func (p *GeminiProvider) GenerateStructuredResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error) {
+	return p.generateContent(ctx, systemPrompt, history, userMessage, schema)
+}
 
-	contents = append(contents, geminiContent{
-		Parts: []geminiPart{{Text: userText}},
-		Role:  "user",
-	})
+func (p *GeminiProvider) generateContent(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error) {
+	// Use REST API v1 endpoint (not v1beta), response schemas are only on v1beta
+	version := "v1"
+	if schema != nil {
+		version = "v1beta"
+	}
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/%s/models/%s:generateContent?key=%s",
+		version, p.model, p.apiKey)
 
 	reqBody := geminiRequest{
-		Contents: contents,
+		Contents: geminiContents(systemPrompt, history, userMessage),
 		GenerationConfig: geminiGenerationConfig{
 			Temperature:     p.temperature,
 			MaxOutputTokens: p.maxTokens,
 		},
 	}
-
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal request: %w", err)
-	}
-
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return "", fmt.Errorf("failed to create request: %w", err)
+	if schema != nil {
+		reqBody.GenerationConfig.ResponseMimeType = "application/json"
+		reqBody.GenerationConfig.ResponseSchema = geminiSchema(schema.Schema)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := p.client.Do(req)
+	resp, err := p.post(ctx, url, reqBody)
 	if err != nil {
-		return "", fmt.Errorf("gemini request failed: %w", err)
+		return "", err
 	}
 	defer resp.Body.Close()
 
@@ -148,3 +177,246 @@ func (p *GeminiProvider) GenerateResponse(ctx context.Context, systemPrompt, use
 
 	return geminiResp.Candidates[0].Content.Parts[0].Text, nil
 }
+
+// GenerateWithTools offers the tools as function declarations (v1beta); earlier rounds are replayed as
+// functionCall and functionResponse parts. Gemini has no call IDs, so the calls are numbered.
+func (p *GeminiProvider) GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) (string, []ToolCall, error) {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1beta/models/%s:generateContent?key=%s", p.model, p.apiKey)
+
+	reqBody := geminiRequest{
+		Contents: geminiContents(systemPrompt, history, userMessage),
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     p.temperature,
+			MaxOutputTokens: p.maxTokens,
+		},
+	}
+	for _, step := range steps {
+		var calls, responses []geminiPart
+		if step.Text != "" {
+			calls = append(calls, geminiPart{Text: step.Text})
+		}
+		for i, call := range step.Calls {
+			calls = append(calls, geminiPart{FunctionCall: &geminiFunctionCall{Name: call.Name, Args: call.Arguments}})
+			responses = append(responses, geminiPart{FunctionResponse: &geminiFunctionResponse{
+				Name:     call.Name,
+				Response: map[string]interface{}{"result": step.Results[i]},
+			}})
+		}
+		reqBody.Contents = append(reqBody.Contents,
+			geminiContent{Parts: calls, Role: "model"},
+			geminiContent{Parts: responses, Role: "user"},
+		)
+	}
+	if len(tools) > 0 {
+		declarations := make([]geminiFunctionDeclaration, len(tools))
+		for i, tool := range tools {
+			declarations[i] = geminiFunctionDeclaration{Name: tool.Name, Description: tool.Description}
+			if tool.Parameters != nil {
+				declarations[i].Parameters = geminiSchema(tool.Parameters)
+			}
+		}
+		reqBody.Tools = []geminiTool{{FunctionDeclarations: declarations}}
+		reqBody.ToolConfig = &geminiToolConfig{}
+		reqBody.ToolConfig.FunctionCallingConfig.Mode = "AUTO"
+		if answerOnly {
+			reqBody.ToolConfig.FunctionCallingConfig.Mode = "NONE"
+		}
+	}
+
+	resp, err := p.post(ctx, url, reqBody)
+	if err != nil {
+		return "", nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", nil, fmt.Errorf("gemini error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var geminiResp geminiResponse
+	if err := json.Unmarshal(body, &geminiResp); err != nil {
+		return "", nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(geminiResp.Candidates) == 0 {
+		return "", nil, fmt.Errorf("no response from Gemini")
+	}
+
+	var text strings.Builder
+	var calls []ToolCall
+	for _, part := range geminiResp.Candidates[0].Content.Parts {
+		if part.FunctionCall != nil {
+			calls = append(calls, ToolCall{
+				ID:        fmt.Sprintf("call_%d_%d", len(steps), len(calls)),
+				Name:      part.FunctionCall.Name,
+				Arguments: toolArguments(string(part.FunctionCall.Args)),
+			})
+			continue
+		}
+		text.WriteString(part.Text)
+	}
+	if text.Len() == 0 && len(calls) == 0 {
+		return "", nil, fmt.Errorf("no response from Gemini")
+	}
+	return text.String(), calls, nil
+}
+
+// StreamResponse streams the response with server-sent events, passing each chunk of text to onDelta
+func (p *GeminiProvider) StreamResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, onDelta func(string)) (string, error) {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1/models/%s:streamGenerateContent?alt=sse&key=%s",
+		p.model, p.apiKey)
+
+	reqBody := geminiRequest{
+		Contents: geminiContents(systemPrompt, history, userMessage),
+		GenerationConfig: geminiGenerationConfig{
+			Temperature:     p.temperature,
+			MaxOutputTokens: p.maxTokens,
+		},
+	}
+
+	resp, err := p.post(ctx, url, reqBody)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("gemini error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var response strings.Builder
+	err = readSSE(resp.Body, func(data []byte) error {
+		var chunk geminiResponse
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		for _, candidate := range chunk.Candidates {
+			for _, part := range candidate.Content.Parts {
+				if part.Text != "" {
+					response.WriteString(part.Text)
+					onDelta(part.Text)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return response.String(), err
+	}
+	if response.Len() == 0 {
+		return "", fmt.Errorf("no response from Gemini")
+	}
+	return response.String(), nil
+}
+
+// CountTokens counts the prompt tokens with the countTokens API
+func (p *GeminiProvider) CountTokens(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (int, error) {
+	url := fmt.Sprintf("https://generativelanguage.googleapis.com/v1/models/%s:countTokens?key=%s", p.model, p.apiKey)
+
+	resp, err := p.post(ctx, url, map[string]interface{}{
+		"contents": geminiContents(systemPrompt, history, userMessage),
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("gemini error (model: %s, status: %d): %s", p.model, resp.StatusCode, string(body))
+	}
+
+	var counted struct {
+		TotalTokens int `json:"totalTokens"`
+	}
+	if err := json.Unmarshal(body, &counted); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return counted.TotalTokens, nil
+}
+
+// post sends a JSON request to the Gemini API
+func (p *GeminiProvider) post(ctx context.Context, url string, body interface{}) (*http.Response, error) {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("gemini request failed: %w", err)
+	}
+	return resp, nil
+}
+
+// geminiContents builds the conversation: earlier turns first, then the user message
+func geminiContents(systemPrompt string, history []ChatMessage, userMessage string) []geminiContent {
+	var contents []geminiContent
+	for _, msg := range history {
+		// Gemini calls the assistant "model"
+		role := "user"
+		if msg.Role == ChatRoleAssistant {
+			role = "model"
+		}
+		contents = append(contents, geminiContent{
+			Parts: []geminiPart{{Text: msg.Content}},
+			Role:  role,
+		})
+	}
+
+	// For Gemini v1 API, system instruction should be part of the first user message
+	if systemPrompt != "" {
+		if len(contents) > 0 {
+			contents[0].Parts[0].Text = systemPrompt + "\n\n" + contents[0].Parts[0].Text
+		} else {
+			userMessage = systemPrompt + "\n\n" + userMessage
+		}
+	}
+
+	contents = append(contents, geminiContent{
+		Parts: []geminiPart{{Text: userMessage}},
+		Role:  "user",
+	})
+	return contents
+}
+
+// geminiSchema converts a JSON schema to Gemini's OpenAPI subset, which has no additionalProperties
+func geminiSchema(schema map[string]interface{}) map[string]interface{} {
+	converted := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		switch key {
+		case "additionalProperties":
+			continue
+		case "properties":
+			properties, _ := value.(map[string]interface{})
+			convertedProperties := make(map[string]interface{}, len(properties))
+			for name, prop := range properties {
+				if propSchema, ok := prop.(map[string]interface{}); ok {
+					convertedProperties[name] = geminiSchema(propSchema)
+				}
+			}
+			converted[key] = convertedProperties
+		case "items":
+			if items, ok := value.(map[string]interface{}); ok {
+				converted[key] = geminiSchema(items)
+			}
+		default:
+			converted[key] = value
+		}
+	}
+	return converted
+}
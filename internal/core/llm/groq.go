@@ -47,12 +47,13 @@ func (p *GroqProvider) GetProviderName() string {
 }
 
 func (p *GroqProvider) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	return p.GenerateResponseWithHistory(ctx, systemPrompt, nil, userMessage)
+}
+
+func (p *GroqProvider) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
 	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: p.model,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
-			{Role: openai.ChatMessageRoleUser, Content: userMessage},
-		},
+		Model:       p.model,
+		Messages:    openAIMessages(systemPrompt, history, userMessage),
 		Temperature: p.temperature,
 		MaxTokens:   p.maxTokens,
 	})
@@ -67,3 +68,33 @@ func (p *GroqProvider) GenerateResponse(ctx context.Context, systemPrompt, userM
 
 	return resp.Choices[0].Message.Content, nil
 }
+
+// GenerateStructuredResponse uses JSON mode: the response is a JSON object, the schema is only described by the prompt
+func (p *GroqProvider) GenerateStructuredResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:          p.model,
+		Messages:       openAIMessages(systemPrompt, history, userMessage),
+		Temperature:    p.temperature,
+		MaxTokens:      p.maxTokens,
+		ResponseFormat: openAIJSONMode,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("groq error: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from Groq")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// GenerateWithTools offers the tools with function calling of the OpenAI-compatible API
+func (p *GroqProvider) GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) (string, []ToolCall, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, openAIToolRequest(p.model, p.temperature, p.maxTokens, systemPrompt, history, userMessage, tools, steps, answerOnly))
+	if err != nil {
+		return "", nil, fmt.Errorf("groq error: %w", err)
+	}
+	return openAIToolResponse(resp, "Groq")
+}
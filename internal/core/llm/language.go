@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Languages the bot can detect and reply in (code -> name used in prompts)
+var Languages = map[string]string{
+	"id": "Indonesia",
+	"en": "Inggris (English)",
+	"jv": "Jawa",
+	"su": "Sunda",
+}
+
+// languageMarkers are common words that are distinctive for each language
+var languageMarkers = map[string][]string{
+	"id": {
+		"yang", "dan", "ini", "itu", "ada", "tidak", "gak", "nggak", "enggak", "apa", "apakah", "saya", "aku", "kamu", "anda",
+		"mau", "bisa", "berapa", "harga", "harganya", "dengan", "untuk", "dari", "ke", "di", "sudah", "udah", "belum",
+		"kak", "min", "terima", "kasih", "tolong", "boleh", "gimana", "bagaimana", "kapan", "dimana", "mana", "juga",
+		"atau", "pesan", "beli", "dong", "sih", "kok", "banget", "masih", "kalau", "kalo", "bang", "gan", "selamat",
+		"pagi", "siang", "sore", "malam", "ingin", "pengiriman", "ongkir", "stok", "tersedia", "bayar",
+	},
+	"en": {
+		"the", "and", "is", "are", "this", "that", "what", "how", "much", "many", "price", "i", "you", "your", "my", "me",
+		"can", "could", "would", "want", "need", "please", "thanks", "thank", "hello", "hi", "do", "does", "have", "has",
+		"with", "for", "from", "to", "of", "in", "buy", "available", "when", "where", "there", "it", "not", "will",
+		"shipping", "delivery", "pay", "good", "morning", "afternoon", "evening", "stock", "still", "open",
+	},
+	"jv": {
+		"opo", "piye", "pira", "piro", "regane", "rego", "sampeyan", "panjenengan", "kulo", "kula", "nggih", "mboten",
+		"ora", "wis", "wes", "durung", "karo", "iki", "kuwi", "iku", "arep", "pengen", "matur", "nuwun", "sugeng",
+		"monggo", "mbak", "tuku", "tumbas", "saged", "iso", "isa", "sing", "ono", "onten", "ngendi", "endi", "teko",
+		"seko", "dino", "ndak", "nopo", "pripun", "pinten", "badhe", "dereng", "sampun", "kathah", "ngapunten",
+	},
+	"su": {
+		"naon", "kumaha", "sabaraha", "abdi", "anjeun", "teu", "aya", "hoyong", "meser", "punten", "hatur", "nuhun",
+		"mangga", "wios", "tiasa", "atuh", "euy", "pisan", "nu", "sareng", "iraha", "dimana", "timana", "acan",
+		"parantos", "tos", "bade", "teh", "akang", "teteh", "damang", "wilujeng", "enjing", "nyuhunkeun",
+	},
+}
+
+// languageLookup maps each marker word to its language
+var languageLookup = func() map[string]string {
+	lookup := make(map[string]string)
+	for lang, words := range languageMarkers {
+		for _, word := range words {
+			if _, dup := lookup[word]; dup {
+				// A word shared by two languages says nothing
+				lookup[word] = ""
+				continue
+			}
+			lookup[word] = lang
+		}
+	}
+	return lookup
+}()
+
+// DetectLanguage guesses the language of a message from its common words.
+// Returns an empty code when the message is too short or too mixed to tell.
+func DetectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+
+	scores := make(map[string]int)
+	for _, word := range words {
+		if lang := languageLookup[word]; lang != "" {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, secondScore := "", 0, 0
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, secondScore, bestScore = lang, bestScore, score
+		case score > secondScore:
+			secondScore = score
+		}
+	}
+
+	// Short messages ("thanks", "matur nuwun") need one marker, longer ones at least two
+	minScore := 2
+	if len(words) <= 3 {
+		minScore = 1
+	}
+	if bestScore < minScore || bestScore == secondScore {
+		return ""
+	}
+
+	return best
+}
+
+// LanguageInstruction tells the model which language to answer in (empty for Indonesian, the prompt's language)
+func LanguageInstruction(lang string) string {
+	name, ok := Languages[lang]
+	if !ok || lang == "id" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nPENTING: Selalu balas customer dalam bahasa %s, sesuai bahasa yang digunakan customer. Nama produk, harga dan perintah dalam kurung siku tetap ditulis apa adanya.", name)
+}
+
+// BuildTranslationPrompt builds the system prompt for rewriting a reply in another language
+func BuildTranslationPrompt(lang string) string {
+	return fmt.Sprintf("Terjemahkan pesan WhatsApp berikut ke bahasa %s. "+
+		"Pertahankan emoji, format *tebal*, angka, harga, nama produk, nomor pesanan dan link apa adanya. "+
+		"Balas hanya dengan hasil terjemahan.", Languages[lang])
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MockProvider answers without calling an API, for load tests and local runs without keys
+type MockProvider struct {
+	latency time.Duration
+
+	mu       sync.Mutex
+	scripted bool
+	replies  []string // Scripted replies still to give, see Script
+	messages []string // User messages received once scripted
+}
+
+func NewMockProvider(latency time.Duration) *MockProvider {
+	return &MockProvider{latency: latency}
+}
+
+func (p *MockProvider) GetProviderName() string {
+	return "Mock"
+}
+
+func (p *MockProvider) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	return p.GenerateResponseWithHistory(ctx, systemPrompt, nil, userMessage)
+}
+
+// Script makes the mock give these replies, in order, before falling back to its canned one. From then
+// on it also records the user messages it receives (see Messages).
+func (p *MockProvider) Script(replies ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scripted = true
+	p.replies = append(p.replies, replies...)
+}
+
+// Messages returns the user messages the mock received, oldest first
+func (p *MockProvider) Messages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.messages...)
+}
+
+func (p *MockProvider) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
+	// Simulated API latency, +/-25% so concurrent requests don't finish in lockstep
+	if p.latency > 0 {
+		delay := p.latency*3/4 + time.Duration(rand.Int63n(int64(p.latency/2)+1))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return "", fmt.Errorf("mock error: %w", ctx.Err())
+		}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.scripted {
+		p.messages = append(p.messages, userMessage)
+	}
+	if len(p.replies) > 0 {
+		reply := p.replies[0]
+		p.replies = p.replies[1:]
+		return reply, nil
+	}
+
+	return fmt.Sprintf("Terima kasih, pesan Anda sudah kami terima: %q", truncate(userMessage, 80)), nil
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "..."
+}
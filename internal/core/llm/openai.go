@@ -2,6 +2,7 @@ package llm
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
@@ -46,14 +47,48 @@ func (p *OpenAIProvider) GetProviderName() string {
 }
 
 func (p *OpenAIProvider) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
+	return p.GenerateResponseWithHistory(ctx, systemPrompt, nil, userMessage)
+}
+
+func (p *OpenAIProvider) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
 	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: p.model,
-		Messages: []openai.ChatCompletionMessage{
-			{Role: openai.ChatMessageRoleSystem, Content: systemPrompt},
-			{Role: openai.ChatMessageRoleUser, Content: userMessage},
-		},
+		Model:       p.model,
+		Messages:    openAIMessages(systemPrompt, history, userMessage),
+		Temperature: p.temperature,
+		MaxTokens:   p.maxTokens,
+	})
+
+	if err != nil {
+		return "", fmt.Errorf("openai error: %w", err)
+	}
+
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no response from OpenAI")
+	}
+
+	return resp.Choices[0].Message.Content, nil
+}
+
+// GenerateStructuredResponse uses structured outputs, the response is guaranteed to match the schema
+func (p *OpenAIProvider) GenerateStructuredResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error) {
+	schemaJSON, err := json.Marshal(schema.Schema)
+	if err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    openAIMessages(systemPrompt, history, userMessage),
 		Temperature: p.temperature,
 		MaxTokens:   p.maxTokens,
+		ResponseFormat: &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   schema.Name,
+				Schema: json.RawMessage(schemaJSON),
+				Strict: true,
+			},
+		},
 	})
 
 	if err != nil {
@@ -66,3 +101,83 @@ func (p *OpenAIProvider) GenerateResponse(ctx context.Context, systemPrompt, use
 
 	return resp.Choices[0].Message.Content, nil
 }
+
+// GenerateWithTools offers the tools with function calling
+func (p *OpenAIProvider) GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) (string, []ToolCall, error) {
+	resp, err := p.client.CreateChatCompletion(ctx, openAIToolRequest(p.model, p.temperature, p.maxTokens, systemPrompt, history, userMessage, tools, steps, answerOnly))
+	if err != nil {
+		return "", nil, fmt.Errorf("openai error: %w", err)
+	}
+	return openAIToolResponse(resp, "OpenAI")
+}
+
+// openAIJSONMode is the response format of OpenAI-compatible APIs without schema support: any valid JSON object
+var openAIJSONMode = &openai.ChatCompletionResponseFormat{Type: openai.ChatCompletionResponseFormatTypeJSONObject}
+
+// openAIMessages builds the chat messages of OpenAI-compatible APIs (OpenAI, Groq, DeepSeek)
+func openAIMessages(systemPrompt string, history []ChatMessage, userMessage string) []openai.ChatCompletionMessage {
+	messages := make([]openai.ChatCompletionMessage, 0, len(history)+2)
+	messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleSystem, Content: systemPrompt})
+	for _, msg := range history {
+		role := openai.ChatMessageRoleUser
+		if msg.Role == ChatRoleAssistant {
+			role = openai.ChatMessageRoleAssistant
+		}
+		messages = append(messages, openai.ChatCompletionMessage{Role: role, Content: msg.Content})
+	}
+	return append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: userMessage})
+}
+
+// openAIToolRequest builds a chat request of OpenAI-compatible APIs offering tools, with the earlier rounds of
+// tool calls replayed as assistant tool_calls and tool messages
+func openAIToolRequest(model string, temperature float32, maxTokens int, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) openai.ChatCompletionRequest {
+	messages := openAIMessages(systemPrompt, history, userMessage)
+	for _, step := range steps {
+		calls := make([]openai.ToolCall, len(step.Calls))
+		for i, call := range step.Calls {
+			calls[i] = openai.ToolCall{
+				ID:       call.ID,
+				Type:     openai.ToolTypeFunction,
+				Function: openai.FunctionCall{Name: call.Name, Arguments: string(call.Arguments)},
+			}
+		}
+		messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: step.Text, ToolCalls: calls})
+		for i, call := range step.Calls {
+			messages = append(messages, openai.ChatCompletionMessage{Role: openai.ChatMessageRoleTool, Content: step.Results[i], ToolCallID: call.ID})
+		}
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model:       model,
+		Messages:    messages,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
+	}
+	for _, tool := range tools {
+		function := &openai.FunctionDefinition{Name: tool.Name, Description: tool.Description}
+		if tool.Parameters != nil {
+			function.Parameters = tool.Parameters
+		} else {
+			function.Parameters = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+		}
+		req.Tools = append(req.Tools, openai.Tool{Type: openai.ToolTypeFunction, Function: function})
+	}
+	if answerOnly && len(req.Tools) > 0 {
+		req.ToolChoice = "none"
+	}
+	return req
+}
+
+// openAIToolResponse returns the text and tool calls of a chat completion
+func openAIToolResponse(resp openai.ChatCompletionResponse, providerName string) (string, []ToolCall, error) {
+	if len(resp.Choices) == 0 {
+		return "", nil, fmt.Errorf("no response from %s", providerName)
+	}
+
+	message := resp.Choices[0].Message
+	calls := make([]ToolCall, 0, len(message.ToolCalls))
+	for _, call := range message.ToolCalls {
+		calls = append(calls, ToolCall{ID: call.ID, Name: call.Function.Name, Arguments: toolArguments(call.Function.Arguments)})
+	}
+	return message.Content, calls, nil
+}
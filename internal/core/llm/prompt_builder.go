@@ -7,11 +7,13 @@ import (
 )
 
 type KnowledgeBase struct {
-	BusinessName string
-	Tone         string
-	FAQs         []FAQ
-	Products     []Product
-	RawEntries   []RawKBEntry // New: for all other types
+	BusinessName   string
+	Tone           string
+	PromptTemplate string // Client's own persona and instructions; empty uses the default ones
+	ToolCalling    bool   // Orders go through tools (lookup_product, add_to_cart, create_order, check_order_status) instead of commands
+	FAQs           []FAQ
+	Products       []Product
+	RawEntries     []RawKBEntry // New: for all other types
 }
 
 type FAQ struct {
@@ -31,12 +33,30 @@ type RawKBEntry struct {
 	Content map[string]interface{} `json:"content"`
 }
 
-// BuildSystemPrompt membuat system prompt dari knowledge base
+// MaxPromptTemplateLength is the longest prompt template a client can set
+const MaxPromptTemplateLength = 4000
+
+// RenderPromptTemplate fills the {{business_name}} and {{tone}} placeholders of a client's prompt template
+func RenderPromptTemplate(template, businessName, tone string) string {
+	return strings.NewReplacer(
+		"{{business_name}}", businessName,
+		"{{tone}}", tone,
+	).Replace(strings.TrimSpace(template))
+}
+
+// BuildSystemPrompt membuat system prompt dari knowledge base. A client's prompt template replaces the
+// default persona, instructions and examples; the knowledge base and ordering commands are always included.
 func BuildSystemPrompt(kb *KnowledgeBase) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf("Anda adalah asisten virtual untuk %s.\n", kb.BusinessName))
-	sb.WriteString(fmt.Sprintf("Tone komunikasi: %s.\n\n", kb.Tone))
+	custom := strings.TrimSpace(kb.PromptTemplate) != ""
+	if custom {
+		sb.WriteString(RenderPromptTemplate(kb.PromptTemplate, kb.BusinessName, kb.Tone))
+		sb.WriteString("\n\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("Anda adalah asisten virtual untuk %s.\n", kb.BusinessName))
+		sb.WriteString(fmt.Sprintf("Tone komunikasi: %s.\n\n", kb.Tone))
+	}
 
 	// FAQ Section
 	if len(kb.FAQs) > 0 {
@@ -71,47 +91,70 @@ func BuildSystemPrompt(kb *KnowledgeBase) string {
 		sb.WriteString("\n")
 	}
 
-	sb.WriteString("Instruksi:\n")
-	sb.WriteString("- Kamu adalah asisten yang ramah, helpful, dan NATURAL seperti admin toko\n")
-	sb.WriteString("- BOLEH jawab pertanyaan umum/casual (cuaca, tanggal, tips, motivasi, dll) dengan santai dan natural\n")
-	sb.WriteString("- Untuk pertanyaan umum: jawab dulu dengan natural, lalu SOFT REDIRECT ke produk/layanan toko\n")
-	sb.WriteString("- Untuk pertanyaan produk/layanan: gunakan info dari knowledge base di atas\n")
-	sb.WriteString("- Jika ada pertanyaan spesifik yang tidak ada di knowledge base, sarankan kontak langsung\n")
-	sb.WriteString("- Maksimal 2-3 kalimat per response, jangan bertele-tele\n")
-	sb.WriteString("- Jangan gunakan markdown formatting yang berlebihan\n")
-	sb.WriteString("- Berikan improvisasi dan kreativitas dalam jawaban, jangan kaku!\n\n")
+	if !custom {
+		sb.WriteString("Instruksi:\n")
+		sb.WriteString("- Kamu adalah asisten yang ramah, helpful, dan NATURAL seperti admin toko\n")
+		sb.WriteString("- BOLEH jawab pertanyaan umum/casual (cuaca, tanggal, tips, motivasi, dll) dengan santai dan natural\n")
+		sb.WriteString("- Untuk pertanyaan umum: jawab dulu dengan natural, lalu SOFT REDIRECT ke produk/layanan toko\n")
+		sb.WriteString("- Untuk pertanyaan produk/layanan: gunakan info dari knowledge base di atas\n")
+		sb.WriteString("- Jika ada pertanyaan spesifik yang tidak ada di knowledge base, sarankan kontak langsung\n")
+		sb.WriteString("- Maksimal 2-3 kalimat per response, jangan bertele-tele\n")
+		sb.WriteString("- Jangan gunakan markdown formatting yang berlebihan\n")
+		sb.WriteString("- Berikan improvisasi dan kreativitas dalam jawaban, jangan kaku!\n\n")
+	}
 
 	// Cart & Order Instructions
 	sb.WriteString("=== FITUR PEMESANAN (PENTING!) ===\n")
-	sb.WriteString("Jika customer ingin ORDER/PESAN produk:\n")
-	sb.WriteString("1. Berikan response ramah seperti biasa\n")
-	sb.WriteString("2. Di AKHIR response (baris terpisah), tambahkan command:\n")
-	sb.WriteString("   [ADD_TO_CART:product_name|quantity]\n")
-	sb.WriteString("   Contoh: [ADD_TO_CART:Nasi Goreng|2]\n\n")
-	sb.WriteString("Jika customer bilang 'CHECKOUT' atau 'BAYAR':\n")
-	sb.WriteString("1. Berikan response konfirmasi\n")
-	sb.WriteString("2. Di AKHIR response, tambahkan: [CHECKOUT]\n\n")
+	if kb.ToolCalling {
+		sb.WriteString("Gunakan tools yang tersedia untuk aksi pesanan, jangan menebak:\n")
+		sb.WriteString("- lookup_product: cek harga, stok dan detail produk sebelum menjawab\n")
+		sb.WriteString("- add_to_cart: jika customer ingin ORDER/PESAN produk\n")
+		sb.WriteString("- create_order: jika customer bilang 'CHECKOUT' atau 'BAYAR' (payment_method 'cod' untuk 'COD' atau 'BAYAR DI TEMPAT')\n")
+		sb.WriteString("- check_order_status: jika customer menanyakan status pesanannya\n")
+		sb.WriteString("Setelah tool dijalankan, jawab customer berdasarkan hasilnya. JANGAN tulis command [ADD_TO_CART], [CHECKOUT] atau [CHECKOUT_COD].\n\n")
+	} else {
+		sb.WriteString("Jika customer ingin ORDER/PESAN produk:\n")
+		sb.WriteString("1. Berikan response ramah seperti biasa\n")
+		sb.WriteString("2. Di AKHIR response (baris terpisah), tambahkan command:\n")
+		sb.WriteString("   [ADD_TO_CART:product_name|quantity]\n")
+		sb.WriteString("   Contoh: [ADD_TO_CART:Nasi Goreng|2]\n\n")
+		sb.WriteString("Jika customer bilang 'CHECKOUT' atau 'BAYAR':\n")
+		sb.WriteString("1. Berikan response konfirmasi\n")
+		sb.WriteString("2. Di AKHIR response, tambahkan: [CHECKOUT]\n\n")
+		sb.WriteString("Jika customer mau checkout dengan 'COD' atau 'BAYAR DI TEMPAT':\n")
+		sb.WriteString("1. Berikan response konfirmasi\n")
+		sb.WriteString("2. Di AKHIR response, tambahkan: [CHECKOUT_COD]\n\n")
+	}
 	sb.WriteString("Jika customer mau 'LIHAT KERANJANG' atau 'CEK CART':\n")
 	sb.WriteString("1. Berikan response\n")
 	sb.WriteString("2. Di AKHIR response, tambahkan: [VIEW_CART]\n\n")
+	sb.WriteString("Jika customer minta 'PENAWARAN', 'QUOTATION' atau 'ESTIMASI HARGA' untuk isi keranjang:\n")
+	sb.WriteString("1. Berikan response konfirmasi\n")
+	sb.WriteString("2. Di AKHIR response, tambahkan: [REQUEST_QUOTE]\n\n")
 	sb.WriteString("PENTING: Command harus di BARIS TERPISAH di akhir response!\n\n")
 
 	sb.WriteString("Contoh Response yang Baik:\n\n")
-	sb.WriteString("User: \"Gimana caranya jadi kaya?\"\n")
-	sb.WriteString("Bot: \"Wah pertanyaan bagus! Salah satu caranya ya dengan berbisnis dan jual produk berkualitas. Ngomong-ngomong, mau coba produk kita? Recommended banget lho!\"\n\n")
-	sb.WriteString("User: \"Cuaca panas banget hari ini\"\n")
-	sb.WriteString("Bot: \"Iya bener nih panas banget ya! Enak tuh kalau sambil nyeruput minuman dingin. Mau coba produk kita? Pas banget buat cuaca gini!\"\n\n")
-	sb.WriteString("User: \"Lagi bad mood nih\"\n")
-	sb.WriteString("Bot: \"Waduh, semangat ya! Biasanya kalau lagi bad mood enaknya treat yourself dengan sesuatu yang enak. Mau coba produk kita? Bisa jadi mood booster!\"\n\n")
-	sb.WriteString("User: \"Hari ini tanggal berapa?\"\n")
-	sb.WriteString("Bot: \"Waduh maaf aku ga punya kalender nih hehe. Coba cek di HP kamu aja ya. Btw, ada yang bisa aku bantu terkait produk atau layanan kita?\"\n\n")
-
-	sb.WriteString("User: \"Saya mau pesan Nasi Goreng 2 porsi\"\n")
-	sb.WriteString("Bot: \"Siap! Nasi Goreng 2 porsi sudah ditambahkan ke keranjang. Total: Rp 50.000. Mau pesan lagi atau langsung checkout?\n[ADD_TO_CART:Nasi Goreng|2]\"\n\n")
+	if !custom {
+		sb.WriteString("User: \"Gimana caranya jadi kaya?\"\n")
+		sb.WriteString("Bot: \"Wah pertanyaan bagus! Salah satu caranya ya dengan berbisnis dan jual produk berkualitas. Ngomong-ngomong, mau coba produk kita? Recommended banget lho!\"\n\n")
+		sb.WriteString("User: \"Cuaca panas banget hari ini\"\n")
+		sb.WriteString("Bot: \"Iya bener nih panas banget ya! Enak tuh kalau sambil nyeruput minuman dingin. Mau coba produk kita? Pas banget buat cuaca gini!\"\n\n")
+		sb.WriteString("User: \"Lagi bad mood nih\"\n")
+		sb.WriteString("Bot: \"Waduh, semangat ya! Biasanya kalau lagi bad mood enaknya treat yourself dengan sesuatu yang enak. Mau coba produk kita? Bisa jadi mood booster!\"\n\n")
+		sb.WriteString("User: \"Hari ini tanggal berapa?\"\n")
+		sb.WriteString("Bot: \"Waduh maaf aku ga punya kalender nih hehe. Coba cek di HP kamu aja ya. Btw, ada yang bisa aku bantu terkait produk atau layanan kita?\"\n\n")
+	}
+
+	if !kb.ToolCalling {
+		sb.WriteString("User: \"Saya mau pesan Nasi Goreng 2 porsi\"\n")
+		sb.WriteString("Bot: \"Siap! Nasi Goreng 2 porsi sudah ditambahkan ke keranjang. Total: Rp 50.000. Mau pesan lagi atau langsung checkout?\n[ADD_TO_CART:Nasi Goreng|2]\"\n\n")
+	}
 	sb.WriteString("User: \"Lihat keranjang\"\n")
 	sb.WriteString("Bot: \"Baik, saya cek keranjang Anda dulu ya!\n[VIEW_CART]\"\n\n")
-	sb.WriteString("User: \"Checkout\"\n")
-	sb.WriteString("Bot: \"Oke, saya proses pesanan Anda ya!\n[CHECKOUT]\"\n")
+	if !kb.ToolCalling {
+		sb.WriteString("User: \"Checkout\"\n")
+		sb.WriteString("Bot: \"Oke, saya proses pesanan Anda ya!\n[CHECKOUT]\"\n")
+	}
 
 	return sb.String()
 }
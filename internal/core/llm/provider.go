@@ -4,14 +4,29 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 )
 
 // LLMProvider interface untuk multiple AI providers
 type LLMProvider interface {
 	GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error)
+	// GenerateResponseWithHistory answers userMessage following earlier turns of the conversation (oldest first)
+	GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error)
 	GetProviderName() string
 }
 
+// Chat roles of history messages
+const (
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// ChatMessage is one earlier message of a conversation
+type ChatMessage struct {
+	Role    string // ChatRoleUser or ChatRoleAssistant
+	Content string
+}
+
 // ProviderType untuk factory
 type ProviderType string
 
@@ -21,8 +36,18 @@ const (
 	ProviderGroq     ProviderType = "groq"
 	ProviderDeepSeek ProviderType = "deepseek"
 	ProviderClaude   ProviderType = "claude"
+	ProviderMock     ProviderType = "mock" // Canned replies, no API calls (load tests)
 )
 
+// ParseProviderType checks a provider name set for a client
+func ParseProviderType(name string) (ProviderType, error) {
+	switch providerType := ProviderType(name); providerType {
+	case ProviderOpenAI, ProviderGemini, ProviderGroq, ProviderDeepSeek, ProviderClaude:
+		return providerType, nil
+	}
+	return "", fmt.Errorf("unknown LLM provider %q (openai, gemini, groq, deepseek or claude)", name)
+}
+
 // ProviderConfig untuk create provider
 type ProviderConfig struct {
 	Type ProviderType
@@ -38,6 +63,9 @@ type ProviderConfig struct {
 	Model       string
 	Temperature float32
 	MaxTokens   int
+
+	// Mock provider: simulated response time
+	MockLatency time.Duration
 }
 
 // NewProvider factory untuk create LLM provider
@@ -73,6 +101,9 @@ func NewProvider(cfg *ProviderConfig) (LLMProvider, error) {
 		}
 		return NewClaudeProvider(cfg.ClaudeKey, cfg.Model, cfg.Temperature, cfg.MaxTokens), nil
 
+	case ProviderMock:
+		return NewMockProvider(cfg.MockLatency), nil
+
 	default:
 		return nil, fmt.Errorf("unknown LLM provider type: %s", cfg.Type)
 	}
@@ -98,19 +129,17 @@ func LoadProviderFromEnv() (*ProviderConfig, error) {
 	if model := os.Getenv("LLM_MODEL"); model != "" {
 		cfg.Model = model
 	} else {
-		// Provider-specific defaults
-		switch cfg.Type {
-		case ProviderOpenAI:
-			cfg.Model = "gpt-4o-mini"
-		case ProviderGemini:
-			cfg.Model = "gemini-2.5-flash"
-		case ProviderGroq:
-			cfg.Model = "llama-3.1-8b-instant"
-		case ProviderDeepSeek:
-			cfg.Model = "deepseek-chat"
-		case ProviderClaude:
-			cfg.Model = "claude-3-5-sonnet-20241022"
+		cfg.Model = DefaultModel(cfg.Type)
+	}
+
+	// Mock latency (e.g. "800ms"), close to a real provider by default
+	cfg.MockLatency = 800 * time.Millisecond
+	if latency := os.Getenv("LLM_MOCK_LATENCY"); latency != "" {
+		d, err := time.ParseDuration(latency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LLM_MOCK_LATENCY: %w", err)
 		}
+		cfg.MockLatency = d
 	}
 
 	// Temperature
@@ -119,3 +148,22 @@ func LoadProviderFromEnv() (*ProviderConfig, error) {
 
 	return cfg, nil
 }
+
+// DefaultModel returns the model used for a provider when LLM_MODEL is not set
+func DefaultModel(providerType ProviderType) string {
+	switch providerType {
+	case ProviderOpenAI:
+		return "gpt-4o-mini"
+	case ProviderGemini:
+		return "gemini-2.5-flash"
+	case ProviderGroq:
+		return "llama-3.1-8b-instant"
+	case ProviderDeepSeek:
+		return "deepseek-chat"
+	case ProviderClaude:
+		return "claude-3-5-sonnet-20241022"
+	case ProviderMock:
+		return "mock"
+	}
+	return ""
+}
@@ -2,12 +2,37 @@ package llm
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
+	"go.opentelemetry.io/otel/attribute"
 )
 
+// ModelSettings are the LLM settings of a client; zero values mean the default
+type ModelSettings struct {
+	Provider    ProviderType
+	Model       string
+	Temperature float32
+	MaxTokens   int
+}
+
+// ModelLookup returns the LLM settings a client's calls use
+type ModelLookup func(clientID string) (ModelSettings, error)
+
 // Service wraps LLM provider untuk dependency injection
 type Service struct {
 	provider LLMProvider
+	meter    UsageMeter // nil when usage is not metered
+
+	// Per-client providers, built from cfg (the API keys) on first use
+	cfg       *ProviderConfig // nil when the provider was injected
+	lookup    ModelLookup     // nil when every client uses the default provider
+	mu        sync.Mutex
+	providers map[string]LLMProvider
 }
 
 // NewService creates LLM service with provider from environment
@@ -24,7 +49,7 @@ func NewService() *Service {
 
 	log.Printf("🤖 Using LLM provider: %s (model: %s)", provider.GetProviderName(), cfg.Model)
 
-	return &Service{provider: provider}
+	return &Service{provider: provider, cfg: cfg, providers: make(map[string]LLMProvider)}
 }
 
 // NewServiceWithProvider creates service with custom provider (for testing)
@@ -32,12 +57,218 @@ func NewServiceWithProvider(provider LLMProvider) *Service {
 	return &Service{provider: provider}
 }
 
+// SetUsageMeter meters the calls made for a client (see WithUsage) and refuses them once its quota is exhausted
+func (s *Service) SetUsageMeter(meter UsageMeter) {
+	s.meter = meter
+}
+
+// SetModelLookup lets each client use its own provider, model, temperature and max tokens (see WithUsage);
+// clients without them, or whose provider has no API key configured, use the default provider
+func (s *Service) SetModelLookup(lookup ModelLookup) {
+	s.lookup = lookup
+}
+
 // GenerateResponse generates AI response
 func (s *Service) GenerateResponse(ctx context.Context, systemPrompt, userMessage string) (string, error) {
-	return s.provider.GenerateResponse(ctx, systemPrompt, userMessage)
+	return s.call(ctx, "generate", promptChars(systemPrompt, nil, userMessage), func(ctx context.Context, provider LLMProvider) (string, error) {
+		return provider.GenerateResponse(ctx, systemPrompt, userMessage)
+	})
+}
+
+// GenerateResponseWithHistory generates AI response following earlier turns of the conversation (oldest first)
+func (s *Service) GenerateResponseWithHistory(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (string, error) {
+	return s.call(ctx, "generate_with_history", promptChars(systemPrompt, history, userMessage), func(ctx context.Context, provider LLMProvider) (string, error) {
+		return provider.GenerateResponseWithHistory(ctx, systemPrompt, history, userMessage)
+	})
+}
+
+// GenerateStructuredResponse asks for a JSON response matching schema. Providers that can constrain
+// their output (see StructuredProvider) do so; the others only follow the instructions of the prompt.
+func (s *Service) GenerateStructuredResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error) {
+	return s.call(ctx, "generate_structured", promptChars(systemPrompt, history, userMessage), func(ctx context.Context, provider LLMProvider) (string, error) {
+		if structured, ok := provider.(StructuredProvider); ok {
+			return structured.GenerateStructuredResponse(ctx, systemPrompt, history, userMessage, schema)
+		}
+		return provider.GenerateResponseWithHistory(ctx, systemPrompt, history, userMessage)
+	})
+}
+
+// StreamResponse generates AI response, passing the text to onDelta as it is generated. Providers that
+// can't stream (see StreamingProvider) pass the whole response at once.
+func (s *Service) StreamResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, onDelta func(string)) (string, error) {
+	return s.call(ctx, "stream", promptChars(systemPrompt, history, userMessage), func(ctx context.Context, provider LLMProvider) (string, error) {
+		if streaming, ok := provider.(StreamingProvider); ok {
+			return streaming.StreamResponse(ctx, systemPrompt, history, userMessage, onDelta)
+		}
+		response, err := provider.GenerateResponseWithHistory(ctx, systemPrompt, history, userMessage)
+		if err == nil {
+			onDelta(response)
+		}
+		return response, err
+	})
+}
+
+// GenerateWithTools generates AI response, letting the model call tools: every call is run with execute and
+// its result fed back until the model answers in text, for at most MaxToolRounds rounds. A failed call is
+// reported to the model as its result. Providers without function calling (see ToolProvider) answer without
+// tools. Returns the answer and the rounds of tool calls made.
+func (s *Service) GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, execute ToolExecutor) (string, []ToolStep, error) {
+	var steps []ToolStep
+	for round := 0; ; round++ {
+		answerOnly := round == MaxToolRounds
+		var calls []ToolCall
+		response, err := s.call(ctx, "generate_with_tools", promptChars(systemPrompt, history, userMessage)+toolStepChars(steps), func(ctx context.Context, provider LLMProvider) (string, error) {
+			toolProvider, ok := provider.(ToolProvider)
+			if !ok {
+				return provider.GenerateResponseWithHistory(ctx, systemPrompt, history, userMessage)
+			}
+			text, requested, err := toolProvider.GenerateWithTools(ctx, systemPrompt, history, userMessage, tools, steps, answerOnly)
+			calls = requested
+			return text, err
+		})
+		if err != nil || len(calls) == 0 {
+			return response, steps, err
+		}
+
+		step := ToolStep{Text: response, Calls: calls, Results: make([]string, len(calls))}
+		for i, call := range calls {
+			step.Results[i] = runTool(ctx, call, execute)
+		}
+		steps = append(steps, step)
+	}
+}
+
+// SupportsTools reports whether the provider of the client ctx is attributed to can call tools
+func (s *Service) SupportsTools(ctx context.Context) bool {
+	_, ok := s.providerFor(ctx).(ToolProvider)
+	return ok
+}
+
+// CountTokens returns the prompt tokens of a request. Providers with a tokenizer API (see TokenCounter)
+// count them exactly; for the others, or when counting fails, they are estimated from the length.
+func (s *Service) CountTokens(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) int {
+	if counter, ok := s.providerFor(ctx).(TokenCounter); ok {
+		tokens, err := counter.CountTokens(ctx, systemPrompt, history, userMessage)
+		if err == nil {
+			return tokens
+		}
+		log.Printf("⚠️ Token counting failed, estimating: %v", err)
+	}
+	return estimateChars(promptChars(systemPrompt, history, userMessage))
 }
 
 // GetProviderName returns current provider name
 func (s *Service) GetProviderName() string {
 	return s.provider.GetProviderName()
 }
+
+// call checks the quota of the client ctx is attributed to, makes the provider call and records its usage
+func (s *Service) call(ctx context.Context, operation string, prompt int, generate func(context.Context, LLMProvider) (string, error)) (string, error) {
+	scope, metered := usageFrom(ctx)
+	metered = metered && s.meter != nil
+	if metered {
+		if err := s.meter.Allow(ctx, scope.clientID); err != nil {
+			return "", err
+		}
+	}
+
+	selected := s.providerFor(ctx)
+	provider := selected.GetProviderName()
+	response, err := observeCall(ctx, provider, operation, prompt, func(ctx context.Context) (string, error) {
+		return generate(ctx, selected)
+	})
+	if metered && err == nil {
+		s.meter.Record(ctx, Usage{
+			ClientID:         scope.clientID,
+			Feature:          scope.feature,
+			Provider:         provider,
+			Operation:        operation,
+			PromptTokens:     estimateChars(prompt),
+			CompletionTokens: estimateChars(len(response)),
+		})
+	}
+	return response, err
+}
+
+// providerFor returns the provider of the client ctx is attributed to
+func (s *Service) providerFor(ctx context.Context) LLMProvider {
+	scope, ok := usageFrom(ctx)
+	if !ok || s.lookup == nil || s.cfg == nil {
+		return s.provider
+	}
+
+	settings, err := s.lookup(scope.clientID)
+	if err != nil {
+		return s.provider
+	}
+	cfg := *s.cfg
+	if settings.Provider != "" && settings.Provider != cfg.Type {
+		cfg.Type = settings.Provider
+		cfg.Model = DefaultModel(settings.Provider)
+	}
+	if settings.Model != "" {
+		cfg.Model = settings.Model
+	}
+	if settings.Temperature > 0 {
+		cfg.Temperature = settings.Temperature
+	}
+	if settings.MaxTokens > 0 {
+		cfg.MaxTokens = settings.MaxTokens
+	}
+	if cfg == *s.cfg {
+		return s.provider
+	}
+
+	key := fmt.Sprintf("%s/%s/%g/%d", cfg.Type, cfg.Model, cfg.Temperature, cfg.MaxTokens)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if provider, ok := s.providers[key]; ok {
+		return provider
+	}
+
+	provider, err := NewProvider(&cfg)
+	if err != nil {
+		// Remembered so the error is logged once; the client keeps using the default provider
+		log.Printf("⚠️ LLM provider %s unavailable for client %s, using %s: %v", key, scope.clientID, s.provider.GetProviderName(), err)
+		provider = s.provider
+	} else {
+		log.Printf("🤖 LLM provider %s (model: %s, temperature: %g, max tokens: %d) ready for per-client use", provider.GetProviderName(), cfg.Model, cfg.Temperature, cfg.MaxTokens)
+	}
+	s.providers[key] = provider
+	return provider
+}
+
+// observeCall runs a provider call in an "llm.<operation>" span and records its duration and estimated token usage
+func observeCall(ctx context.Context, provider, operation string, prompt int, generate func(context.Context) (string, error)) (string, error) {
+	ctx, span := tracing.Start(ctx, "llm."+operation,
+		attribute.String("llm.provider", provider),
+		attribute.Int("llm.prompt_chars", prompt),
+	)
+	started := time.Now()
+	response, err := generate(ctx)
+	metrics.ObserveLLMCall(provider, operation, started, err, prompt, len(response))
+	span.SetAttributes(attribute.Int("llm.response_chars", len(response)))
+	tracing.End(span, err)
+	return response, err
+}
+
+// runTool runs a tool call in a "tool.<name>" span; errors become the result, so the model can recover
+func runTool(ctx context.Context, call ToolCall, execute ToolExecutor) string {
+	ctx, span := tracing.Start(ctx, "tool."+call.Name)
+	result, err := execute(ctx, call)
+	tracing.End(span, err)
+	if err != nil {
+		log.Printf("⚠️ Tool %s failed: %v", call.Name, err)
+		return "error: " + err.Error()
+	}
+	return result
+}
+
+// promptChars returns the length of everything sent to the provider
+func promptChars(systemPrompt string, history []ChatMessage, userMessage string) int {
+	n := len(systemPrompt) + len(userMessage)
+	for _, msg := range history {
+		n += len(msg.Content)
+	}
+	return n
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+)
+
+// StreamingProvider is implemented by providers that can stream their response as it is generated
+// (Claude, Gemini). onDelta receives each piece of text; the full response is returned at the end.
+type StreamingProvider interface {
+	StreamResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, onDelta func(string)) (string, error)
+}
+
+// TokenCounter is implemented by providers that count prompt tokens with their own tokenizer (Claude, Gemini)
+type TokenCounter interface {
+	CountTokens(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string) (int, error)
+}
+
+// maxSSELine is the longest server-sent event line read from a streaming response
+const maxSSELine = 1024 * 1024
+
+// readSSE calls onData with the payload of every "data:" line of a server-sent event stream
+func readSSE(body io.Reader, onData func(data []byte) error) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxSSELine)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.HasPrefix(line, []byte("data:")) {
+			continue // event:, id:, comments and the blank lines between events
+		}
+		data := bytes.TrimSpace(line[len("data:"):])
+		if len(data) == 0 || bytes.Equal(data, []byte("[DONE]")) {
+			continue
+		}
+		if err := onData(data); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
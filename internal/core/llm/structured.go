@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// JSONSchema describes the JSON object a structured response must contain
+type JSONSchema struct {
+	Name   string                 // Identifier sent to the provider, e.g. "receipt"
+	Schema map[string]interface{} // JSON Schema (object, array, string, number, integer, boolean; properties, required, additionalProperties)
+}
+
+// StructuredProvider is implemented by providers that can constrain their output to JSON
+// (OpenAI structured outputs, JSON mode of OpenAI-compatible APIs, Gemini response schemas).
+// The response still has to be checked with ValidateJSON: only OpenAI enforces the schema strictly.
+type StructuredProvider interface {
+	GenerateStructuredResponse(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, schema *JSONSchema) (string, error)
+}
+
+// ExtractJSON strips markdown code fences and any text around the outermost JSON object
+func ExtractJSON(response string) string {
+	cleaned := strings.TrimSpace(response)
+	cleaned = strings.TrimPrefix(cleaned, "```json")
+	cleaned = strings.TrimPrefix(cleaned, "```")
+	cleaned = strings.TrimSuffix(cleaned, "```")
+	cleaned = strings.TrimSpace(cleaned)
+
+	start, end := strings.Index(cleaned, "{"), strings.LastIndex(cleaned, "}")
+	if start >= 0 && end > start {
+		cleaned = cleaned[start : end+1]
+	}
+	return cleaned
+}
+
+// ValidateJSON checks a JSON document against a schema. Errors name the offending field
+// (e.g. "items[0].quantity: expected integer, got string") so they can be fed back to the model.
+func ValidateJSON(data string, schema *JSONSchema) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(data), &value); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return validateJSONValue("", value, schema.Schema)
+}
+
+func validateJSONValue(path string, value interface{}, schema map[string]interface{}) error {
+	field := path
+	if field == "" {
+		field = "response"
+	}
+
+	switch schema["type"] {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %s", field, jsonTypeName(value))
+		}
+		properties, _ := schema["properties"].(map[string]interface{})
+		for _, name := range schemaRequired(schema) {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: missing required field", joinJSONPath(path, name))
+			}
+		}
+
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, ok := properties[name].(map[string]interface{})
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: unknown field", joinJSONPath(path, name))
+				}
+				continue
+			}
+			if err := validateJSONValue(joinJSONPath(path, name), object[name], propSchema); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %s", field, jsonTypeName(value))
+		}
+		items, _ := schema["items"].(map[string]interface{})
+		if items == nil {
+			return nil
+		}
+		for i, item := range array {
+			if err := validateJSONValue(fmt.Sprintf("%s[%d]", path, i), item, items); err != nil {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %s", field, jsonTypeName(value))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s: expected number, got %s", field, jsonTypeName(value))
+		}
+	case "integer":
+		number, ok := value.(float64)
+		if !ok || number != math.Trunc(number) {
+			return fmt.Errorf("%s: expected integer, got %s", field, jsonTypeName(value))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %s", field, jsonTypeName(value))
+		}
+	}
+	return nil
+}
+
+func schemaRequired(schema map[string]interface{}) []string {
+	switch required := schema["required"].(type) {
+	case []string:
+		return required
+	case []interface{}:
+		names := make([]string, 0, len(required))
+		for _, name := range required {
+			if s, ok := name.(string); ok {
+				names = append(names, s)
+			}
+		}
+		return names
+	}
+	return nil
+}
+
+func joinJSONPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,103 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+var testSchema = &JSONSchema{
+	Name: "receipt",
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"total": map[string]interface{}{"type": "number"},
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":     map[string]interface{}{"type": "string"},
+						"quantity": map[string]interface{}{"type": "integer"},
+					},
+					"required": []string{"name", "quantity"},
+				},
+			},
+		},
+		"required":             []string{"total", "items"},
+		"additionalProperties": false,
+	},
+}
+
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"bare object", `{"total": 1}`, `{"total": 1}`},
+		{"json code fence", "```json\n{\"total\": 1}\n```", `{"total": 1}`},
+		{"plain code fence", "```\n{\"total\": 1}\n```", `{"total": 1}`},
+		{"surrounded by prose", "Here is the receipt:\n{\"total\": 1}\nLet me know if you need more.", `{"total": 1}`},
+		{"nested objects", `Result: {"a": {"b": 1}} done`, `{"a": {"b": 1}}`},
+		{"no object", "I could not read the receipt", "I could not read the receipt"},
+		{"truncated object", `{"total": 1, "items": [`, `{"total": 1, "items": [`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExtractJSON(tt.response); got != tt.want {
+				t.Errorf("ExtractJSON(%q) = %q, want %q", tt.response, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string // Substring of the error, "" when valid
+	}{
+		{"valid", `{"total": 12.5, "items": [{"name": "Kopi", "quantity": 2}]}`, ""},
+		{"valid without items", `{"total": 0, "items": []}`, ""},
+		{"truncated", `{"total": 12.5, "items": [`, "invalid JSON"},
+		{"trailing comma", `{"total": 12.5,}`, "invalid JSON"},
+		{"not JSON", `Total: 12.5`, "invalid JSON"},
+		{"missing total", `{"items": []}`, "total: missing required field"},
+		{"total as string", `{"total": "12.5", "items": []}`, "total: expected number, got string"},
+		{"items not an array", `{"total": 1, "items": {}}`, "items: expected array, got object"},
+		{"fractional quantity", `{"total": 1, "items": [{"name": "Kopi", "quantity": 1.5}]}`, "items[0].quantity: expected integer, got number"},
+		{"missing item name", `{"total": 1, "items": [{"quantity": 1}]}`, "items[0].name: missing required field"},
+		{"unknown field", `{"total": 1, "items": [], "tax": 0}`, "tax: unknown field"},
+		{"array instead of object", `[{"total": 1}]`, "response: expected object, got array"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateJSON(tt.data, testSchema)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("unexpected error: %v", err)
+			case tt.wantErr != "" && err == nil:
+				t.Errorf("got no error, want %q", tt.wantErr)
+			case tt.wantErr != "" && !strings.Contains(err.Error(), tt.wantErr):
+				t.Errorf("got error %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateStructuredResponseWithoutStructuredProvider(t *testing.T) {
+	mock := NewMockProvider(0)
+	mock.Script("```json\n{\"total\": 3, \"items\": []}\n```")
+	service := NewServiceWithProvider(mock)
+
+	response, err := service.GenerateStructuredResponse(context.Background(), "system", nil, "parse", testSchema)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := ValidateJSON(ExtractJSON(response), testSchema); err != nil {
+		t.Errorf("fenced response does not validate once extracted: %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// MaxToolRounds is how many rounds of tool calls one reply may take before the model has to answer in text
+const MaxToolRounds = 4
+
+// Tool is a function the model can call to take a structured action instead of answering in free text
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  map[string]interface{} // JSON Schema of the arguments object; nil for a tool without arguments
+}
+
+// ToolCall is a call the model requested
+type ToolCall struct {
+	ID        string // Matches the result to the call; generated for providers without call IDs (Gemini)
+	Name      string
+	Arguments json.RawMessage // JSON object
+}
+
+// ToolStep is one round of the tool loop: the calls the model made and what they returned
+type ToolStep struct {
+	Text    string // Text the model wrote alongside its calls
+	Calls   []ToolCall
+	Results []string // Result of each call, in order
+}
+
+// ToolProvider is implemented by providers with native function calling (OpenAI, Groq, DeepSeek, Claude, Gemini).
+// steps are the earlier rounds of the current reply; answerOnly forbids further calls. The response is the
+// model's text, or the calls it wants made.
+type ToolProvider interface {
+	GenerateWithTools(ctx context.Context, systemPrompt string, history []ChatMessage, userMessage string, tools []Tool, steps []ToolStep, answerOnly bool) (string, []ToolCall, error)
+}
+
+// ToolExecutor runs a tool call and returns the result fed back to the model
+type ToolExecutor func(ctx context.Context, call ToolCall) (string, error)
+
+// toolArguments returns the arguments of a call as a JSON object, {} when the model sent none
+func toolArguments(arguments string) json.RawMessage {
+	if arguments == "" {
+		return json.RawMessage("{}")
+	}
+	return json.RawMessage(arguments)
+}
+
+// toolStepChars is the prompt length the rounds of tool calls add to a request
+func toolStepChars(steps []ToolStep) int {
+	chars := 0
+	for _, step := range steps {
+		chars += len(step.Text)
+		for i, call := range step.Calls {
+			chars += len(call.Name) + len(call.Arguments)
+			if i < len(step.Results) {
+				chars += len(step.Results[i])
+			}
+		}
+	}
+	return chars
+}
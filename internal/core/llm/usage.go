@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrUsageExhausted is returned instead of calling the provider when the client a call is made for has
+// used up its token quota and credits
+var ErrUsageExhausted = errors.New("AI token quota and credits are exhausted")
+
+// Usage is the estimated token usage of one call made for a client (about 4 characters per token)
+type Usage struct {
+	ClientID         string
+	Feature          string // What the call was for: chat, translation, receipt, ...
+	Provider         string
+	Operation        string
+	PromptTokens     int
+	CompletionTokens int
+}
+
+// UsageMeter decides whether a client may make a call and records what each call used
+type UsageMeter interface {
+	Allow(ctx context.Context, clientID string) error
+	Record(ctx context.Context, usage Usage)
+}
+
+type usageScopeKey struct{}
+
+type usageScope struct {
+	clientID string
+	feature  string
+}
+
+// WithUsage attributes the calls made with ctx to a client and feature, so they are metered and
+// refused once the client's quota is exhausted. Calls without a client are not metered.
+func WithUsage(ctx context.Context, clientID, feature string) context.Context {
+	return context.WithValue(ctx, usageScopeKey{}, usageScope{clientID: clientID, feature: feature})
+}
+
+// usageFrom returns the client and feature calls made with ctx are attributed to
+func usageFrom(ctx context.Context) (usageScope, bool) {
+	scope, ok := ctx.Value(usageScopeKey{}).(usageScope)
+	return scope, ok && scope.clientID != ""
+}
+
+// estimateChars converts a length in characters to estimated tokens
+func estimateChars(chars int) int {
+	return (chars + 3) / 4
+}
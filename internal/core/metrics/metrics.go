@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name
+const namespace = "saas"
+
+// charsPerToken approximates token counts for providers that don't report usage
+const charsPerToken = 4
+
+// Outcome labels
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// registry holds the collectors served on /metrics
+var registry = prometheus.NewRegistry()
+
+var (
+	webhookRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_request_duration_seconds",
+		Help:      "Time to verify, parse and acknowledge a WhatsApp webhook request, by route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "status"})
+
+	webhookProcessingDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "webhook_processing_duration_seconds",
+		Help:      "Time to process an incoming WhatsApp message after the webhook was acknowledged, by message type.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60},
+	}, []string{"type"})
+
+	llmCallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_call_duration_seconds",
+		Help:      "Duration of LLM provider calls, by provider, operation and outcome.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 4, 8, 15, 30, 60},
+	}, []string{"provider", "operation", "outcome"})
+
+	llmTokens = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Estimated LLM tokens (about 4 characters per token), by provider and direction (prompt or completion).",
+	}, []string{"provider", "direction"})
+
+	whatsappSendFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "whatsapp_send_failures_total",
+		Help:      "WhatsApp messages that failed to send, by provider and message kind.",
+	}, []string{"provider", "kind"})
+
+	ocrDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "ocr_processing_duration_seconds",
+		Help:      "Duration of OCR text extraction, by provider and outcome.",
+		Buckets:   []float64{0.25, 0.5, 1, 2, 4, 8, 15, 30},
+	}, []string{"provider", "outcome"})
+
+	paymentWebhooks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "payment_webhooks_total",
+		Help:      "Payment gateway webhooks received, by gateway and result (processed, ignored, rejected or failed).",
+	}, []string{"gateway", "result"})
+
+	workflowExecutions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "workflow_executions_total",
+		Help:      "Workflow executions, by status (completed, skipped or failed).",
+	}, []string{"status"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		webhookRequestDuration,
+		webhookProcessingDuration,
+		llmCallDuration,
+		llmTokens,
+		whatsappSendFailures,
+		ocrDuration,
+		paymentWebhooks,
+		workflowExecutions,
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() fiber.Handler {
+	return adaptor.HTTPHandler(promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+}
+
+// ObserveWebhookRequest records how long a webhook request took to answer with status
+func ObserveWebhookRequest(route string, status int, started time.Time) {
+	webhookRequestDuration.WithLabelValues(route, strconv.Itoa(status)).Observe(time.Since(started).Seconds())
+}
+
+// ObserveWebhookProcessing records how long an incoming message of messageType (text, image, voice) took to process
+func ObserveWebhookProcessing(messageType string, started time.Time) {
+	webhookProcessingDuration.WithLabelValues(messageType).Observe(time.Since(started).Seconds())
+}
+
+// ObserveLLMCall records an LLM call and estimates the tokens it used from the prompt and response lengths
+func ObserveLLMCall(provider, operation string, started time.Time, err error, promptChars, responseChars int) {
+	llmCallDuration.WithLabelValues(provider, operation, outcome(err)).Observe(time.Since(started).Seconds())
+	llmTokens.WithLabelValues(provider, "prompt").Add(float64(estimateTokens(promptChars)))
+	if err == nil {
+		llmTokens.WithLabelValues(provider, "completion").Add(float64(estimateTokens(responseChars)))
+	}
+}
+
+// WhatsAppSendFailed counts a message of kind (text, reply, location) that could not be sent
+func WhatsAppSendFailed(provider, kind string) {
+	whatsappSendFailures.WithLabelValues(provider, kind).Inc()
+}
+
+// ObserveOCR records an OCR extraction
+func ObserveOCR(provider string, started time.Time, err error) {
+	ocrDuration.WithLabelValues(provider, outcome(err)).Observe(time.Since(started).Seconds())
+}
+
+// PaymentWebhook counts a payment webhook by the result it was recorded with
+func PaymentWebhook(gateway, result string) {
+	paymentWebhooks.WithLabelValues(gateway, result).Inc()
+}
+
+// WorkflowExecution counts a finished workflow execution
+func WorkflowExecution(status string) {
+	workflowExecutions.WithLabelValues(status).Inc()
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeError
+	}
+	return OutcomeSuccess
+}
+
+func estimateTokens(chars int) int {
+	return (chars + charsPerToken - 1) / charsPerToken
+}
@@ -3,6 +3,7 @@ package notification
 import (
 	"fmt"
 	"log"
+	"time"
 )
 
 // Channel represents a notification channel
@@ -258,3 +259,176 @@ func (s *Service) NotifyOrderCancelled(tenantAdmin *AdminContact, orderNumber, c
 
 	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
 }
+
+// NotifyOrderRefunded sends notification when a paid order is refunded
+func (s *Service) NotifyOrderRefunded(tenantAdmin *AdminContact, orderNumber, customerPhone string, amount, totalRefunded float64, reason string) error {
+	subject := fmt.Sprintf("↩️ Order Refunded: %s", orderNumber)
+	message := fmt.Sprintf(
+		"*Order Refunded*\n\n"+
+			"📦 Order Number: *%s*\n"+
+			"👤 Customer: %s\n"+
+			"💸 Refund: Rp %.0f (total refunded Rp %.0f)\n"+
+			"📝 Reason: %s",
+		orderNumber,
+		customerPhone,
+		amount,
+		totalRefunded,
+		reason,
+	)
+
+	data := map[string]interface{}{
+		"order_number":   orderNumber,
+		"customer_phone": customerPhone,
+		"amount":         amount,
+		"total_refunded": totalRefunded,
+		"reason":         reason,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
+
+// NotifyOrderNeedsReview sends notification when an order is held for fraud review
+func (s *Service) NotifyOrderNeedsReview(tenantAdmin *AdminContact, orderNumber, customerPhone string, totalAmount float64, riskScore int, reasons string) error {
+	subject := fmt.Sprintf("⚠️ Order Needs Review: %s", orderNumber)
+	message := fmt.Sprintf(
+		"*Order Needs Review*\n\n"+
+			"📦 Order Number: *%s*\n"+
+			"👤 Customer: %s\n"+
+			"💰 Total Amount: Rp %.0f\n"+
+			"🚨 Risk Score: %d\n"+
+			"📝 Reasons:\n%s\n\n"+
+			"Payment is on hold until the order is approved or rejected.",
+		orderNumber,
+		customerPhone,
+		totalAmount,
+		riskScore,
+		reasons,
+	)
+
+	data := map[string]interface{}{
+		"order_number":   orderNumber,
+		"customer_phone": customerPhone,
+		"total_amount":   totalAmount,
+		"risk_score":     riskScore,
+		"reasons":        reasons,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
+
+// NotifyUsageThreshold prompts the tenant admin to upgrade when usage crosses a share of the plan limit
+func (s *Service) NotifyUsageThreshold(tenantAdmin *AdminContact, metric string, used, limit, percent int, currentPlan, upgradePlan string, upgradePrice float64) error {
+	subject := fmt.Sprintf("📈 Plan Usage at %d%%: %s", percent, metric)
+	status := fmt.Sprintf("You have used %d%% of your plan's %s limit.", percent, metric)
+	if percent >= 100 {
+		status = fmt.Sprintf("You have reached your plan's %s limit.", metric)
+	}
+	message := fmt.Sprintf(
+		"*Plan Usage Alert*\n\n"+
+			"%s\n\n"+
+			"📦 Current Plan: *%s*\n"+
+			"📊 Usage: %d / %d\n\n",
+		status,
+		currentPlan,
+		used,
+		limit,
+	)
+	if upgradePlan != "" {
+		message += fmt.Sprintf(
+			"⬆️ Upgrade to *%s* (Rp %.0f/month) for a higher limit.\n"+
+				"You only pay the prorated difference for the rest of this month.",
+			upgradePlan,
+			upgradePrice,
+		)
+	} else {
+		message += "Contact us for a custom plan with higher limits."
+	}
+
+	data := map[string]interface{}{
+		"metric":       metric,
+		"used":         used,
+		"limit":        limit,
+		"percent":      percent,
+		"current_plan": currentPlan,
+		"upgrade_plan": upgradePlan,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
+
+// NotifyPaymentOverdue sends notification when a subscription statement is overdue
+func (s *Service) NotifyPaymentOverdue(tenantAdmin *AdminContact, statementNumber string, total float64, paymentLink, currentPlan, downgradePlan string, graceEndsAt time.Time) error {
+	subject := fmt.Sprintf("⚠️ Payment Overdue: %s", statementNumber)
+	message := fmt.Sprintf(
+		"*Subscription Payment Overdue*\n\n"+
+			"🧾 Statement: *%s*\n"+
+			"💰 Amount Due: Rp %.0f\n"+
+			"📦 Current Plan: *%s*\n\n"+
+			"Please pay before %s, or your subscription will be downgraded to *%s*.",
+		statementNumber,
+		total,
+		currentPlan,
+		graceEndsAt.Format("02 Jan 2006"),
+		downgradePlan,
+	)
+	if paymentLink != "" {
+		message += fmt.Sprintf("\n\n💳 Pay here: %s", paymentLink)
+	}
+
+	data := map[string]interface{}{
+		"statement_number": statementNumber,
+		"total":            total,
+		"payment_link":     paymentLink,
+		"current_plan":     currentPlan,
+		"downgrade_plan":   downgradePlan,
+		"grace_ends_at":    graceEndsAt,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
+
+// NotifyPlanDowngraded sends notification when a subscription is downgraded for non-payment
+func (s *Service) NotifyPlanDowngraded(tenantAdmin *AdminContact, statementNumber, fromPlan, toPlan string) error {
+	subject := fmt.Sprintf("⬇️ Plan Downgraded: %s", toPlan)
+	message := fmt.Sprintf(
+		"*Subscription Downgraded*\n\n"+
+			"Statement *%s* was not paid, so your plan was changed from *%s* to *%s*.\n\n"+
+			"Pay the statement and upgrade again to restore your limits.",
+		statementNumber,
+		fromPlan,
+		toPlan,
+	)
+
+	data := map[string]interface{}{
+		"statement_number": statementNumber,
+		"from_plan":        fromPlan,
+		"to_plan":          toPlan,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
+
+// NotifyWorkflowFailed sends notification when actions of a workflow run failed
+func (s *Service) NotifyWorkflowFailed(tenantAdmin *AdminContact, workflowName, executionID string, actionsFailed int, errorMessage string) error {
+	subject := fmt.Sprintf("⚠️ Workflow Failed: %s", workflowName)
+	message := fmt.Sprintf(
+		"*Workflow Failed*\n\n"+
+			"⚙️ Workflow: *%s*\n"+
+			"🔢 Failed Actions: %d\n"+
+			"📝 Error: %s\n\n"+
+			"Check the execution log and retry it from the dashboard (execution %s).",
+		workflowName,
+		actionsFailed,
+		errorMessage,
+		executionID,
+	)
+
+	data := map[string]interface{}{
+		"workflow_name":  workflowName,
+		"execution_id":   executionID,
+		"actions_failed": actionsFailed,
+		"error":          errorMessage,
+	}
+
+	return s.SendToTenantAdmin(tenantAdmin, subject, message, data)
+}
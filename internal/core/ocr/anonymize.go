@@ -0,0 +1,52 @@
+package ocr
+
+import (
+	"regexp"
+	"strings"
+)
+
+// MaxRawTextLength caps how much raw OCR text is stored per receipt
+const MaxRawTextLength = 4000
+
+var (
+	// 13-19 digits, optionally grouped by spaces or dashes (card numbers)
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// Indonesian mobile numbers (08xx, 628xx, +628xx)
+	phonePattern = regexp.MustCompile(`(?:\+62|\b62|\b0)8\d{1,3}[ -]?\d{3,4}[ -]?\d{3,5}\b`)
+	// Labelled names such as "Nama: Budi" or "Card Holder: BUDI S"
+	nameLinePattern = regexp.MustCompile(`(?im)^(\s*(?:nama|name|card ?holder|pemegang kartu|customer|pelanggan|kasir|cashier)\s*[:.]\s*)\S.*$`)
+	digitPattern    = regexp.MustCompile(`\d`)
+)
+
+// Anonymize masks sensitive data in raw OCR text: card numbers keep only their last 4 digits,
+// emails, phone numbers and labelled names are redacted. Totals and item lines are kept.
+func Anonymize(text string) string {
+	text = cardNumberPattern.ReplaceAllStringFunc(text, maskCardNumber)
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = phonePattern.ReplaceAllString(text, "[phone]")
+	text = nameLinePattern.ReplaceAllString(text, "${1}[name]")
+	return text
+}
+
+// TruncateRawText cuts raw OCR text to at most max bytes without splitting a UTF-8 character
+func TruncateRawText(text string, max int) string {
+	if max <= 0 || len(text) <= max {
+		return text
+	}
+	text = strings.ToValidUTF8(text[:max], "")
+	return text + "…"
+}
+
+// maskCardNumber replaces all but the last 4 digits with '*', keeping separators
+func maskCardNumber(match string) string {
+	digits := len(digitPattern.FindAllString(match, -1))
+	seen := 0
+	return digitPattern.ReplaceAllStringFunc(match, func(d string) string {
+		seen++
+		if seen > digits-4 {
+			return d
+		}
+		return "*"
+	})
+}
@@ -23,7 +23,45 @@ func NewLLMParser(llmService *llm.Service) *LLMParser {
 	}
 }
 
-// ParseReceiptWithLLM parses receipt text using LLM (much more accurate than regex)
+// receiptParseAttempts is how often the LLM is asked before falling back to the regex parser:
+// the first answer, and one retry told what was wrong with it
+const receiptParseAttempts = 2
+
+// receiptSchema is the JSON the LLM must return. Every field is required and no others are
+// allowed, as OpenAI structured outputs demand
+var receiptSchema = &llm.JSONSchema{
+	Name: "receipt",
+	Schema: map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"store_name":       map[string]interface{}{"type": "string"},
+			"total_amount":     map[string]interface{}{"type": "number"},
+			"transaction_date": map[string]interface{}{"type": "string", "description": "ISO 8601, e.g. 2024-01-15T10:30:00Z"},
+			"items": map[string]interface{}{
+				"type": "array",
+				"items": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"name":     map[string]interface{}{"type": "string"},
+						"quantity": map[string]interface{}{"type": "integer"},
+						"price":    map[string]interface{}{"type": "number"},
+					},
+					"required":             []string{"name", "quantity", "price"},
+					"additionalProperties": false,
+				},
+			},
+		},
+		"required":             []string{"store_name", "total_amount", "transaction_date", "items"},
+		"additionalProperties": false,
+	},
+}
+
+// receiptDateLayouts are the transaction_date formats accepted from the LLM
+var receiptDateLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// ParseReceiptWithLLM parses receipt text using LLM (much more accurate than regex).
+// The response is constrained to receiptSchema where the provider supports it and always validated;
+// an invalid response is retried once with the validation error, then the regex parser takes over.
 func (p *LLMParser) ParseReceiptWithLLM(ctx context.Context, ocrText string) (*ReceiptData, error) {
 	log.Printf("🤖 Parsing receipt with LLM: %s", p.llmService.GetProviderName())
 
@@ -31,48 +69,99 @@ func (p *LLMParser) ParseReceiptWithLLM(ctx context.Context, ocrText string) (*R
 	systemPrompt := buildReceiptParserPrompt()
 	userPrompt := fmt.Sprintf("Parse this Indonesian receipt OCR text:\n\n%s", ocrText)
 
-	// Call LLM
-	response, err := p.llmService.GenerateResponse(ctx, systemPrompt, userPrompt)
-	if err != nil {
-		log.Printf("❌ LLM parsing failed: %v", err)
-		// Fallback to regex parser
-		return ParseReceipt(ocrText)
+	var history []llm.ChatMessage
+	for attempt := 1; attempt <= receiptParseAttempts; attempt++ {
+		response, err := p.llmService.GenerateStructuredResponse(ctx, systemPrompt, history, userPrompt, receiptSchema)
+		if err != nil {
+			log.Printf("❌ LLM parsing failed: %v", err)
+			// Fallback to regex parser
+			return ParseReceipt(ocrText)
+		}
+
+		log.Printf("🤖 Raw LLM response: %s", response)
+
+		receiptData, err := decodeReceiptResponse(response)
+		if err == nil {
+			// Store raw text
+			receiptData.RawText = ocrText
+
+			log.Printf("✅ LLM parsed: Total=%.2f, Date=%s, Items=%d, Store=%s",
+				receiptData.TotalAmount, receiptData.TransactionDate.Format("2006-01-02"),
+				len(receiptData.Items), receiptData.StoreName)
+
+			return receiptData, nil
+		}
+
+		log.Printf("⚠️ Invalid LLM receipt JSON (attempt %d/%d): %v", attempt, receiptParseAttempts, err)
+
+		// Show the model its answer and what was wrong with it
+		history = append(history,
+			llm.ChatMessage{Role: llm.ChatRoleUser, Content: userPrompt},
+			llm.ChatMessage{Role: llm.ChatRoleAssistant, Content: response},
+		)
+		userPrompt = fmt.Sprintf("Your response was invalid: %v\n\nReturn ONLY the corrected JSON object for the same receipt, following the required structure exactly.", err)
+	}
+
+	// Fallback to regex parser
+	log.Printf("⬇️ Falling back to regex parser")
+	return ParseReceipt(ocrText)
+}
+
+// decodeReceiptResponse validates an LLM response against receiptSchema and converts it.
+// Errors describe the problem in a way the model can act on.
+func decodeReceiptResponse(response string) (*ReceiptData, error) {
+	// Clean response - remove markdown code blocks or text around the JSON if present
+	cleanedResponse := llm.ExtractJSON(response)
+
+	if err := llm.ValidateJSON(cleanedResponse, receiptSchema); err != nil {
+		return nil, err
 	}
 
-	log.Printf("🤖 Raw LLM response: %s", response)
-
-	// Clean response - remove markdown code blocks if present
-	cleanedResponse := strings.TrimSpace(response)
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```json")
-	cleanedResponse = strings.TrimPrefix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSuffix(cleanedResponse, "```")
-	cleanedResponse = strings.TrimSpace(cleanedResponse)
-
-	log.Printf("🧹 Cleaned LLM response: %s", cleanedResponse)
-
-	// Parse LLM JSON response
-	var receiptData ReceiptData
-	if err := json.Unmarshal([]byte(cleanedResponse), &receiptData); err != nil {
-		log.Printf("⚠️ Failed to parse LLM JSON response: %v", err)
-		log.Printf("⚠️ Response was: %s", cleanedResponse)
-		// Fallback to regex parser
-		log.Printf("⬇️ Falling back to regex parser")
-		return ParseReceipt(ocrText)
+	var parsed struct {
+		StoreName       string        `json:"store_name"`
+		TotalAmount     float64       `json:"total_amount"`
+		TransactionDate string        `json:"transaction_date"`
+		Items           []ReceiptItem `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(cleanedResponse), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	if parsed.TotalAmount < 0 {
+		return nil, fmt.Errorf("total_amount: must not be negative, got %v", parsed.TotalAmount)
+	}
+	for i, item := range parsed.Items {
+		if item.Quantity < 0 || item.Price < 0 {
+			return nil, fmt.Errorf("items[%d]: quantity and price must not be negative", i)
+		}
 	}
 
-	// Store raw text
-	receiptData.RawText = ocrText
+	receiptData := &ReceiptData{
+		StoreName:   strings.TrimSpace(parsed.StoreName),
+		TotalAmount: parsed.TotalAmount,
+		Items:       parsed.Items,
+	}
+	if receiptData.Items == nil {
+		receiptData.Items = []ReceiptItem{}
+	}
 
-	// Validate parsed data
+	// Validate parsed date, an empty one means the receipt has none
+	if date := strings.TrimSpace(parsed.TransactionDate); date != "" {
+		for _, layout := range receiptDateLayouts {
+			if t, err := time.Parse(layout, date); err == nil {
+				receiptData.TransactionDate = t
+				break
+			}
+		}
+		if receiptData.TransactionDate.IsZero() {
+			return nil, fmt.Errorf("transaction_date: %q is not in ISO 8601 format (YYYY-MM-DDTHH:MM:SSZ)", date)
+		}
+	}
 	if receiptData.TransactionDate.IsZero() {
 		receiptData.TransactionDate = time.Now()
 	}
 
-	log.Printf("✅ LLM parsed: Total=%.2f, Date=%s, Items=%d, Store=%s",
-		receiptData.TotalAmount, receiptData.TransactionDate.Format("2006-01-02"),
-		len(receiptData.Items), receiptData.StoreName)
-
-	return &receiptData, nil
+	return receiptData, nil
 }
 
 // buildReceiptParserPrompt creates system prompt for receipt parsing
@@ -0,0 +1,110 @@
+package ocr
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+)
+
+const testReceiptText = "Karis Jaya Shop\n1. Indomie Goreng\n1 lusin x 36,000\nTotal: Rp 70.000"
+
+const validReceiptJSON = `{"store_name": "Karis Jaya Shop", "total_amount": 70000, "transaction_date": "2024-01-15T10:00:00Z", "items": [{"name": "Indomie Goreng", "quantity": 1, "price": 36000}]}`
+
+func TestDecodeReceiptResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		wantErr  string // Substring of the error, "" when valid
+	}{
+		{"bare JSON", validReceiptJSON, ""},
+		{"code fence", "```json\n" + validReceiptJSON + "\n```", ""},
+		{"surrounded by prose", "Sure! Here is the parsed receipt:\n" + validReceiptJSON + "\nHope this helps.", ""},
+		{"date without time", strings.Replace(validReceiptJSON, "2024-01-15T10:00:00Z", "2024-01-15", 1), ""},
+		{"truncated", validReceiptJSON[:60], "invalid JSON"},
+		{"not JSON", "Total: Rp 70.000", "invalid JSON"},
+		{"missing total", `{"store_name": "", "transaction_date": "", "items": []}`, "total_amount: missing required field"},
+		{"total as string", strings.Replace(validReceiptJSON, "70000", `"70.000"`, 1), "total_amount: expected number, got string"},
+		{"fractional quantity", strings.Replace(validReceiptJSON, `"quantity": 1`, `"quantity": 1.5`, 1), "items[0].quantity: expected integer"},
+		{"unknown field", strings.Replace(validReceiptJSON, `"items"`, `"tax": 0, "items"`, 1), "tax: unknown field"},
+		{"negative total", strings.Replace(validReceiptJSON, "70000", "-70000", 1), "total_amount: must not be negative"},
+		{"unparsable date", strings.Replace(validReceiptJSON, "2024-01-15T10:00:00Z", "15/01/2024", 1), "transaction_date"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receipt, err := decodeReceiptResponse(tt.response)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("got %+v, %v; want error containing %q", receipt, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if receipt.StoreName != "Karis Jaya Shop" || receipt.TotalAmount != 70000 || len(receipt.Items) != 1 {
+				t.Errorf("decoded %+v", receipt)
+			}
+			if receipt.TransactionDate.Format("2006-01-02") != "2024-01-15" {
+				t.Errorf("transaction date %s, want 2024-01-15", receipt.TransactionDate)
+			}
+		})
+	}
+}
+
+func TestParseReceiptWithLLMRecovery(t *testing.T) {
+	regex, err := ParseReceipt(testReceiptText)
+	if err != nil {
+		t.Fatalf("regex parser failed: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		replies   []string
+		wantCalls int
+		wantLLM   bool // The LLM's answer is used, not the regex parser's
+	}{
+		{"valid first answer", []string{validReceiptJSON}, 1, true},
+		{"corrected after a truncated answer", []string{validReceiptJSON[:60], validReceiptJSON}, 2, true},
+		{"corrected after a schema violation", []string{`{"store_name": "Karis Jaya Shop", "items": []}`, "```json\n" + validReceiptJSON + "\n```"}, 2, true},
+		{"regex fallback after two invalid answers", []string{"I cannot read this receipt", `{"total_amount": "70.000"}`}, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := llm.NewMockProvider(0)
+			mock.Script(tt.replies...)
+			parser := NewLLMParser(llm.NewServiceWithProvider(mock))
+
+			receipt, err := parser.ParseReceiptWithLLM(context.Background(), testReceiptText)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			messages := mock.Messages()
+			if len(messages) != tt.wantCalls {
+				t.Fatalf("LLM called %d times, want %d", len(messages), tt.wantCalls)
+			}
+			if tt.wantCalls > 1 && !strings.HasPrefix(messages[1], "Your response was invalid: ") {
+				t.Errorf("retry does not explain the error: %q", messages[1])
+			}
+
+			if receipt.RawText != testReceiptText {
+				t.Errorf("raw text %q, want the OCR text", receipt.RawText)
+			}
+			if tt.wantLLM {
+				want := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+				if receipt.TotalAmount != 70000 || receipt.StoreName != "Karis Jaya Shop" || !receipt.TransactionDate.Equal(want) {
+					t.Errorf("got %+v, want the LLM's receipt", receipt)
+				}
+				return
+			}
+			if receipt.TotalAmount != regex.TotalAmount || receipt.StoreName != regex.StoreName || len(receipt.Items) != len(regex.Items) {
+				t.Errorf("got %+v, want the regex parser's %+v", receipt, regex)
+			}
+		})
+	}
+}
@@ -1,6 +1,11 @@
 package ocr
 
-import "context"
+import (
+	"context"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+)
 
 // Provider interface for OCR services
 type Provider interface {
@@ -29,7 +34,10 @@ func NewService(provider Provider) *Service {
 
 // ExtractText extracts text from image using the configured provider
 func (s *Service) ExtractText(ctx context.Context, imageData []byte) (*OCRResult, error) {
-	return s.provider.ExtractText(ctx, imageData)
+	started := time.Now()
+	result, err := s.provider.ExtractText(ctx, imageData)
+	metrics.ObserveOCR(s.provider.GetProviderName(), started, err)
+	return result, err
 }
 
 // GetProviderName returns the name of the current provider
@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// CODConfirmKeyword is the reply a customer sends to confirm paying cash on delivery
+const CODConfirmKeyword = "YA COD"
+
+// StatusPendingCOD is the payment status of a confirmed COD order awaiting cash at delivery
+const StatusPendingCOD = "pending_cod"
+
+// CODPaymentGateway handles cash on delivery: no payment link, the driver or admin confirms the cash
+type CODPaymentGateway struct {
+	*ManualPaymentGateway
+}
+
+// NewCODPaymentGateway creates a new cash on delivery gateway
+func NewCODPaymentGateway(db *gorm.DB) *CODPaymentGateway {
+	return &CODPaymentGateway{
+		ManualPaymentGateway: NewManualPaymentGateway(db),
+	}
+}
+
+// Process asks the customer to confirm the COD order
+func (g *CODPaymentGateway) Process(order *Order) (*ProcessResult, error) {
+	log.Printf("💵 COD payment for order %s - waiting for customer confirmation", order.OrderNumber)
+
+	instructions := fmt.Sprintf(
+		"💵 *Bayar di Tempat (COD)*\n\n"+
+			"Siapkan uang tunai *Rp %s* saat pesanan diantar.\n\n"+
+			"Balas *%s* untuk mengonfirmasi pesanan ini.",
+		formatPrice(order.TotalAmount),
+		CODConfirmKeyword,
+	)
+
+	return &ProcessResult{
+		Success:      true,
+		Message:      "Pesanan COD menunggu konfirmasi pelanggan.",
+		Instructions: instructions,
+	}, nil
+}
+
+// Cancel cancels a COD order that hasn't been paid
+func (g *CODPaymentGateway) Cancel(orderID string) error {
+	result := g.db.Table("saas_orders").
+		Where("id = ? OR order_number = ?", orderID, orderID).
+		Where("payment_status IN ?", []string{StatusPending, StatusPendingCOD}).
+		Update("payment_status", StatusCancelled)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no pending COD payment found for order %s", orderID)
+	}
+
+	log.Printf("✅ COD payment cancelled for order %s", orderID)
+	return nil
+}
+
+// Name returns the gateway name
+func (g *CODPaymentGateway) Name() string {
+	return "Cash on Delivery"
+}
@@ -10,21 +10,41 @@ import (
 
 // NewGateway creates a payment gateway based on configuration
 func NewGateway(cfg *config.Config, db *gorm.DB) (Gateway, error) {
+	registry, err := NewGatewayRegistry(cfg, db)
+	if err != nil {
+		return nil, err
+	}
+	return registry.Default(), nil
+}
+
+// NewGatewayRegistry registers every configured gateway, with the payment mode's gateway as default
+func NewGatewayRegistry(cfg *config.Config, db *gorm.DB) (*Registry, error) {
+	var registry *Registry
+
 	switch cfg.PaymentMode {
 	case "manual":
 		log.Println("💳 Using Manual Payment Gateway")
-		return NewManualPaymentGateway(db), nil
+		registry = NewRegistry(GatewayManual)
 
 	case "automated":
 		if cfg.MidtransServerKey == "" {
 			return nil, fmt.Errorf("MIDTRANS_SERVER_KEY is required for automated payment mode")
 		}
 		log.Println("💳 Using Midtrans Payment Gateway")
-		return NewMidtransPaymentGateway(cfg.MidtransServerKey, cfg.MidtransIsProduction, db), nil
+		registry = NewRegistry(GatewayMidtrans)
 
 	default:
 		// Default to manual
 		log.Printf("⚠️  Unknown payment mode '%s', defaulting to manual", cfg.PaymentMode)
-		return NewManualPaymentGateway(db), nil
+		registry = NewRegistry(GatewayManual)
 	}
+
+	// Manual payment and COD are always available for routing rules
+	registry.Register(GatewayManual, NewManualPaymentGateway(db))
+	registry.Register(GatewayCOD, NewCODPaymentGateway(db))
+	if cfg.MidtransServerKey != "" {
+		registry.Register(GatewayMidtrans, NewMidtransPaymentGateway(cfg.MidtransServerKey, cfg.MidtransIsProduction, db))
+	}
+
+	return registry, nil
 }
@@ -20,6 +20,11 @@ type Gateway interface {
 	// Cancel cancels a pending payment
 	Cancel(orderID string) error
 
+	// Refund returns amount of a paid order to the customer (the full payment when amount is 0)
+	// For manual: nothing moves, admin transfers the money back
+	// For automated: requests the refund from the gateway
+	Refund(orderID string, amount float64, reason string) (*RefundResult, error)
+
 	// Name returns the gateway provider name
 	Name() string
 }
@@ -59,6 +64,14 @@ type ProcessResult struct {
 	Instructions string     `json:"instructions,omitempty"` // Payment instructions
 }
 
+// RefundResult contains the result of a refund request
+type RefundResult struct {
+	Reference string  `json:"reference"` // Refund key or ID at the gateway
+	Amount    float64 `json:"amount"`
+	Status    string  `json:"status"` // refunded or pending (money returned manually)
+	Message   string  `json:"message,omitempty"`
+}
+
 // PaymentStatus represents the current status of a payment
 type PaymentStatus struct {
 	OrderID     string     `json:"order_id"`
@@ -78,6 +91,12 @@ const (
 	StatusExpired   = "expired"
 )
 
+// Refund status constants
+const (
+	RefundStatusRefunded = "refunded" // Gateway returned the money
+	RefundStatusPending  = "pending"  // Admin returns the money outside the system
+)
+
 // Payment method constants
 const (
 	MethodManual       = "manual"
@@ -85,4 +104,5 @@ const (
 	MethodQRIS         = "qris"
 	MethodEWallet      = "ewallet"
 	MethodCreditCard   = "credit_card"
+	MethodCOD          = "cod" // Cash on delivery
 )
@@ -94,6 +94,18 @@ func (g *ManualPaymentGateway) Cancel(orderID string) error {
 	return nil
 }
 
+// Refund records nothing at a gateway: the admin returns the money to the customer outside the system
+func (g *ManualPaymentGateway) Refund(orderID string, amount float64, reason string) (*RefundResult, error) {
+	log.Printf("✅ Manual refund of Rp %s for order %s - admin returns the money", formatPrice(amount), orderID)
+
+	return &RefundResult{
+		Reference: fmt.Sprintf("manual-%s-%d", orderID, time.Now().Unix()),
+		Amount:    amount,
+		Status:    RefundStatusPending,
+		Message:   "Dana dikembalikan manual oleh admin.",
+	}, nil
+}
+
 // Name returns the gateway name
 func (g *ManualPaymentGateway) Name() string {
 	return "Manual Payment Gateway"
@@ -2,15 +2,29 @@ package payment
 
 import (
 	"bytes"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrPaymentNotSettled is returned when Midtrans does not report a notified payment as received
+	ErrPaymentNotSettled = errors.New("payment is not settled at Midtrans")
+	// ErrPaymentAmountMismatch is returned when Midtrans received a different amount than expected
+	ErrPaymentAmountMismatch = errors.New("paid amount does not match")
+)
+
 // MidtransPaymentGateway handles automated payment through Midtrans
 // Supports QRIS, Bank Transfer, E-Wallet, Credit Card
 type MidtransPaymentGateway struct {
@@ -209,11 +223,178 @@ func (g *MidtransPaymentGateway) Cancel(orderID string) error {
 	return nil
 }
 
+// Refund refunds a settled Midtrans transaction, partially when amount is below the paid amount
+func (g *MidtransPaymentGateway) Refund(orderID string, amount float64, reason string) (*RefundResult, error) {
+	refundKey := fmt.Sprintf("%s-refund-%d", orderID, time.Now().UnixNano())
+	payload := map[string]interface{}{
+		"refund_key": refundKey,
+		"reason":     reason,
+	}
+	if amount > 0 {
+		payload["amount"] = int64(amount) // Midtrans takes whole rupiah
+	}
+
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%s/refund", g.baseURL, orderID)
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(g.serverKey, "")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund Midtrans transaction: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		StatusCode    string `json:"status_code"`
+		StatusMessage string `json:"status_message"`
+		RefundKey     string `json:"refund_key"`
+		RefundAmount  string `json:"refund_amount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("midtrans refund failed with status %d", resp.StatusCode)
+	}
+
+	// Midtrans reports errors in status_code, often with HTTP 200
+	if resp.StatusCode != 200 || result.StatusCode != "200" {
+		return nil, fmt.Errorf("midtrans refund failed (%s): %s", result.StatusCode, result.StatusMessage)
+	}
+
+	refunded := amount
+	if parsed, err := strconv.ParseFloat(result.RefundAmount, 64); err == nil {
+		refunded = parsed
+	}
+	if result.RefundKey != "" {
+		refundKey = result.RefundKey
+	}
+
+	log.Printf("✅ Midtrans refund of Rp %s for order %s (key %s)", formatPrice(refunded), orderID, refundKey)
+	return &RefundResult{
+		Reference: refundKey,
+		Amount:    refunded,
+		Status:    RefundStatusRefunded,
+		Message:   result.StatusMessage,
+	}, nil
+}
+
 // Name returns the gateway name
 func (g *MidtransPaymentGateway) Name() string {
 	return "Midtrans Payment Gateway"
 }
 
+// VerifySignature checks a notification's signature_key, SHA512(order_id + status_code + gross_amount + server key).
+// The signature covers the amount, so a valid notification can't claim a different amount than Midtrans sent.
+func (g *MidtransPaymentGateway) VerifySignature(orderID, statusCode, grossAmount, signature string) bool {
+	if signature == "" {
+		return false
+	}
+	sum := sha512.Sum512([]byte(orderID + statusCode + grossAmount + g.serverKey))
+	expected := hex.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(strings.ToLower(signature)), []byte(expected)) == 1
+}
+
+// VerifyTransaction double-checks a payment notification against the Midtrans status API: the transaction must be
+// settled for amount. It returns the transaction as Midtrans recorded it.
+func (g *MidtransPaymentGateway) VerifyTransaction(orderID string, amount float64) (*Settlement, error) {
+	settlements, err := g.FetchSettlements([]string{orderID})
+	if err != nil {
+		return nil, err
+	}
+	if len(settlements) == 0 {
+		return nil, fmt.Errorf("%w: Midtrans has no transaction for %s", ErrPaymentNotSettled, orderID)
+	}
+
+	settlement := settlements[0]
+	if !settlement.IsSettled() {
+		return nil, fmt.Errorf("%w: Midtrans reports %s as %s", ErrPaymentNotSettled, orderID, settlement.Status)
+	}
+	// Midtrans amounts have two decimals
+	if math.Abs(settlement.GrossAmount-amount) >= 0.01 {
+		return nil, fmt.Errorf("%w: Midtrans received %.2f for %s, expected %.2f", ErrPaymentAmountMismatch, settlement.GrossAmount, orderID, amount)
+	}
+	return &settlement, nil
+}
+
+// FetchSettlements queries the Midtrans transaction record of each order.
+// The Core API has no list endpoint, so orders Midtrans doesn't know are skipped.
+func (g *MidtransPaymentGateway) FetchSettlements(orderIDs []string) ([]Settlement, error) {
+	var settlements []Settlement
+	for _, orderID := range orderIDs {
+		url := fmt.Sprintf("%s/%s/status", g.baseURL, orderID)
+
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		req.SetBasicAuth(g.serverKey, "")
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := g.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query Midtrans: %w", err)
+		}
+
+		var result struct {
+			StatusCode        string `json:"status_code"`
+			OrderID           string `json:"order_id"`
+			TransactionID     string `json:"transaction_id"`
+			TransactionStatus string `json:"transaction_status"`
+			PaymentType       string `json:"payment_type"`
+			GrossAmount       string `json:"gross_amount"`
+			TransactionTime   string `json:"transaction_time"`
+			SettlementTime    string `json:"settlement_time"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+
+		if resp.StatusCode == 404 {
+			continue
+		}
+		if resp.StatusCode != 200 {
+			return nil, fmt.Errorf("midtrans returned status %d for order %s", resp.StatusCode, orderID)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode Midtrans status for order %s: %w", orderID, err)
+		}
+		// Midtrans answers HTTP 200 with status_code 404 for unknown orders
+		if result.StatusCode == "404" || result.TransactionID == "" {
+			continue
+		}
+
+		amount, _ := strconv.ParseFloat(result.GrossAmount, 64)
+		settlement := Settlement{
+			OrderID:       orderID,
+			TransactionID: result.TransactionID,
+			Status:        result.TransactionStatus,
+			PaymentType:   result.PaymentType,
+			GrossAmount:   amount,
+		}
+
+		settledAt := result.SettlementTime
+		if settledAt == "" && result.TransactionStatus == "capture" {
+			settledAt = result.TransactionTime
+		}
+		if t, ok := parseGatewayTime(settledAt); ok {
+			settlement.SettledAt = &t
+		}
+
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, nil
+}
+
 // createSnapTransaction creates a Snap transaction
 func (g *MidtransPaymentGateway) createSnapTransaction(payload map[string]interface{}) (*SnapResponse, error) {
 	jsonPayload, err := json.Marshal(payload)
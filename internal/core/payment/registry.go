@@ -0,0 +1,65 @@
+package payment
+
+import "sort"
+
+// Gateway keys used to pick a gateway in per-tenant routing rules
+const (
+	GatewayManual   = "manual"
+	GatewayMidtrans = "midtrans"
+	GatewayCOD      = "cod"
+)
+
+// Registry holds the gateways available to tenants
+type Registry struct {
+	gateways   map[string]Gateway
+	defaultKey string
+}
+
+// NewRegistry creates an empty registry; defaultKey is used when no routing rule applies
+func NewRegistry(defaultKey string) *Registry {
+	return &Registry{
+		gateways:   make(map[string]Gateway),
+		defaultKey: defaultKey,
+	}
+}
+
+// Register adds a gateway under a key
+func (r *Registry) Register(key string, gateway Gateway) {
+	r.gateways[key] = gateway
+}
+
+// Get returns the gateway registered under key
+func (r *Registry) Get(key string) (Gateway, bool) {
+	gateway, ok := r.gateways[key]
+	return gateway, ok
+}
+
+// Default returns the system default gateway
+func (r *Registry) Default() Gateway {
+	return r.gateways[r.defaultKey]
+}
+
+// DefaultKey returns the key of the system default gateway
+func (r *Registry) DefaultKey() string {
+	return r.defaultKey
+}
+
+// ByName finds a gateway by its Name(), as recorded on orders
+func (r *Registry) ByName(name string) (Gateway, bool) {
+	for _, gateway := range r.gateways {
+		if gateway.Name() == name {
+			return gateway, true
+		}
+	}
+	return nil, false
+}
+
+// Keys returns the registered gateway keys
+func (r *Registry) Keys() []string {
+	keys := make([]string, 0, len(r.gateways))
+	for key := range r.gateways {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,122 @@
+package payment
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SandboxGatewayName identifies orders processed by the sandbox gateway
+const SandboxGatewayName = "Sandbox Payment Gateway"
+
+// TestModeMarker is prepended to everything produced for sandbox tenants
+const TestModeMarker = "🧪 *[TEST MODE]*"
+
+// SandboxPaymentGateway simulates payments for tenants in sandbox mode
+// No money moves; payments are settled through the sandbox admin endpoint
+type SandboxPaymentGateway struct {
+	db *gorm.DB
+}
+
+// NewSandboxPaymentGateway creates a new sandbox payment gateway
+func NewSandboxPaymentGateway(db *gorm.DB) *SandboxPaymentGateway {
+	return &SandboxPaymentGateway{
+		db: db,
+	}
+}
+
+// Process creates a fake payment link for the order
+func (g *SandboxPaymentGateway) Process(order *Order) (*ProcessResult, error) {
+	log.Printf("🧪 [TEST MODE] Simulated payment for order %s", order.OrderNumber)
+
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	return &ProcessResult{
+		Success:     true,
+		PaymentLink: fmt.Sprintf("https://sandbox.payment.test/pay/%s", order.OrderNumber),
+		Message:     "Simulated payment created (TEST MODE)",
+		ExpiresAt:   &expiresAt,
+		Instructions: fmt.Sprintf(
+			"%s\n"+
+				"Ini adalah pembayaran simulasi, tidak ada uang yang ditagih.\n\n"+
+				"Nomor Pesanan: *#%s*\n"+
+				"Total Pembayaran: *Rp %s*\n\n"+
+				"Pembayaran akan dikonfirmasi melalui dashboard sandbox.",
+			TestModeMarker,
+			order.OrderNumber,
+			formatPrice(order.TotalAmount),
+		),
+	}, nil
+}
+
+// GetStatus retrieves the simulated payment status from the order table
+func (g *SandboxPaymentGateway) GetStatus(orderID string) (*PaymentStatus, error) {
+	var order struct {
+		ID               uuid.UUID
+		OrderNumber      string
+		PaymentStatus    string
+		PaymentMethod    string
+		PaymentReference string
+		PaidAt           *time.Time
+	}
+
+	err := g.db.Table("saas_orders").
+		Where("id::text = ? OR order_number = ?", orderID, orderID).
+		First(&order).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return &PaymentStatus{
+				OrderID: orderID,
+				Status:  StatusPending,
+			}, nil
+		}
+		return nil, err
+	}
+
+	return &PaymentStatus{
+		OrderID:   order.OrderNumber,
+		Status:    order.PaymentStatus,
+		PaidAt:    order.PaidAt,
+		Reference: order.PaymentReference,
+		Method:    order.PaymentMethod,
+	}, nil
+}
+
+// Cancel cancels a pending simulated payment
+func (g *SandboxPaymentGateway) Cancel(orderID string) error {
+	result := g.db.Table("saas_orders").
+		Where("id::text = ? OR order_number = ?", orderID, orderID).
+		Where("payment_status = ?", StatusPending).
+		Update("payment_status", StatusCancelled)
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("no pending payment found for order %s", orderID)
+	}
+
+	log.Printf("🧪 [TEST MODE] Simulated payment cancelled for order %s", orderID)
+	return nil
+}
+
+// Refund simulates a refund, no money moves
+func (g *SandboxPaymentGateway) Refund(orderID string, amount float64, reason string) (*RefundResult, error) {
+	log.Printf("🧪 [TEST MODE] Simulated refund of Rp %s for order %s", formatPrice(amount), orderID)
+
+	return &RefundResult{
+		Reference: fmt.Sprintf("sandbox-refund-%s", uuid.New().String()[:8]),
+		Amount:    amount,
+		Status:    RefundStatusRefunded,
+		Message:   "Simulated refund (TEST MODE)",
+	}, nil
+}
+
+// Name returns the gateway name
+func (g *SandboxPaymentGateway) Name() string {
+	return SandboxGatewayName
+}
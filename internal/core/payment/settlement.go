@@ -0,0 +1,150 @@
+package payment
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Settlement is a transaction as recorded by the payment gateway
+type Settlement struct {
+	OrderID       string     `json:"order_id"` // Our order number
+	TransactionID string     `json:"transaction_id"`
+	Status        string     `json:"status"` // Gateway status (settlement, capture, pending, refund, ...)
+	PaymentType   string     `json:"payment_type"`
+	GrossAmount   float64    `json:"gross_amount"`
+	SettledAt     *time.Time `json:"settled_at,omitempty"`
+}
+
+// IsSettled reports whether the gateway considers the money received
+func (s Settlement) IsSettled() bool {
+	return s.Status == "settlement" || s.Status == "capture"
+}
+
+// SettlementSource is implemented by gateways that can report their own transaction records
+type SettlementSource interface {
+	// FetchSettlements returns the gateway's transaction for each order number it knows about
+	FetchSettlements(orderIDs []string) ([]Settlement, error)
+}
+
+// settlementReportColumns maps our fields to the header names used in gateway report exports
+var settlementReportColumns = map[string][]string{
+	"order_id":       {"order id", "order_id"},
+	"transaction_id": {"transaction id", "transaction_id"},
+	"status":         {"transaction status", "transaction_status", "status"},
+	"payment_type":   {"payment type", "payment_type", "payment method"},
+	"gross_amount":   {"gross amount", "gross_amount", "amount"},
+	"settled_at":     {"settlement time", "settlement_time", "transaction time", "transaction_time"},
+}
+
+// ParseSettlementReport reads a transaction/settlement CSV exported from the Midtrans dashboard
+func ParseSettlementReport(r io.Reader) ([]Settlement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report header: %w", err)
+	}
+
+	index := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		for field, aliases := range settlementReportColumns {
+			if _, found := index[field]; found {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					index[field] = i
+					break
+				}
+			}
+		}
+	}
+
+	for _, required := range []string{"order_id", "gross_amount"} {
+		if _, ok := index[required]; !ok {
+			return nil, fmt.Errorf("report is missing the %s column", required)
+		}
+	}
+
+	value := func(record []string, field string) string {
+		i, ok := index[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var settlements []Settlement
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		orderID := value(record, "order_id")
+		if orderID == "" {
+			continue
+		}
+
+		amount, err := parseReportAmount(value(record, "gross_amount"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount: %w", line, err)
+		}
+
+		settlement := Settlement{
+			OrderID:       orderID,
+			TransactionID: value(record, "transaction_id"),
+			Status:        strings.ToLower(value(record, "status")),
+			PaymentType:   value(record, "payment_type"),
+			GrossAmount:   amount,
+		}
+		if settlement.Status == "" {
+			// Settlement reports only list settled transactions
+			settlement.Status = "settlement"
+		}
+		if settledAt, ok := parseGatewayTime(value(record, "settled_at")); ok {
+			settlement.SettledAt = &settledAt
+		}
+
+		settlements = append(settlements, settlement)
+	}
+
+	return settlements, nil
+}
+
+// parseReportAmount parses amounts like "150000", "150000.00" or "150,000"
+func parseReportAmount(raw string) (float64, error) {
+	raw = strings.TrimSpace(strings.TrimPrefix(raw, "Rp"))
+	raw = strings.ReplaceAll(raw, ",", "")
+	return strconv.ParseFloat(strings.TrimSpace(raw), 64)
+}
+
+// parseGatewayTime parses Midtrans timestamps, which are in Jakarta time
+func parseGatewayTime(raw string) (time.Time, bool) {
+	if raw == "" {
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		loc = time.Local
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02T15:04:05", "02/01/2006 15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,152 @@
+package region
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+)
+
+// ErrUnknownRegion is returned for a region code that has no connection set configured
+var ErrUnknownRegion = errors.New("unknown region")
+
+// Region is the connection set holding the data of the tenants pinned to a region
+type Region struct {
+	Code    string
+	DB      *database.DB
+	Vector  *kb.VectorRetriever // Nil when semantic search is disabled in the region
+	Storage *upload.Service
+}
+
+// Info describes a configured region
+type Info struct {
+	Code          string `json:"code"`
+	Default       bool   `json:"default"`
+	VectorEnabled bool   `json:"vector_enabled"`
+	Storage       string `json:"storage"` // Upload provider name
+}
+
+// Registry resolves a tenant to the connection set of its region.
+// The default region's database is also the directory: clients.region (empty = default region) lives there.
+type Registry struct {
+	defaultCode string
+	mu          sync.RWMutex
+	regions     map[string]*Region
+	clients     map[string]string // client ID -> region code, cached from the directory
+}
+
+// NewRegistry creates a registry whose default region is the main deployment
+func NewRegistry(defaultRegion *Region) *Registry {
+	return &Registry{
+		defaultCode: defaultRegion.Code,
+		regions:     map[string]*Region{defaultRegion.Code: defaultRegion},
+		clients:     make(map[string]string),
+	}
+}
+
+// Register adds the connection set of another region
+func (r *Registry) Register(region *Region) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.regions[region.Code] = region
+}
+
+// Default returns the region of tenants that are not pinned elsewhere
+func (r *Registry) Default() *Region {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.regions[r.defaultCode]
+}
+
+// Get returns a region by code; an empty code is the default region
+func (r *Registry) Get(code string) (*Region, error) {
+	if code == "" {
+		return r.Default(), nil
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	region, ok := r.regions[code]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRegion, code)
+	}
+	return region, nil
+}
+
+// List describes the configured regions, the default one first
+func (r *Registry) List() []Info {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	infos := make([]Info, 0, len(r.regions))
+	for code, region := range r.regions {
+		info := Info{Code: code, Default: code == r.defaultCode, VectorEnabled: region.Vector != nil}
+		if region.Storage != nil {
+			info.Storage = region.Storage.GetProviderName()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Default != infos[j].Default {
+			return infos[i].Default
+		}
+		return infos[i].Code < infos[j].Code
+	})
+	return infos
+}
+
+// ForTenant returns the region of a resolved tenant
+func (r *Registry) ForTenant(tc *tenant.TenantContext) (*Region, error) {
+	if tc.Region == "" && tc.ClientID != "" {
+		return r.ForClient(context.Background(), tc.ClientID)
+	}
+	return r.Get(tc.Region)
+}
+
+// ForClient returns the region a client is pinned to, looked up in the directory and cached
+func (r *Registry) ForClient(ctx context.Context, clientID string) (*Region, error) {
+	r.mu.RLock()
+	code, ok := r.clients[clientID]
+	r.mu.RUnlock()
+	if ok {
+		return r.Get(code)
+	}
+
+	var stored sql.NullString
+	err := r.Default().DB.QueryRowContext(ctx, "SELECT region FROM clients WHERE id = $1", clientID).Scan(&stored)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("client %s not found", clientID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up region of client %s: %w", clientID, err)
+	}
+
+	r.SetClientRegion(clientID, stored.String)
+	return r.Get(stored.String)
+}
+
+// SetClientRegion updates the cached region of a client after it moved
+func (r *Registry) SetClientRegion(clientID, code string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[clientID] = code
+}
+
+type contextKey struct{}
+
+// WithRegion stores a region code in the context, for code paths that only carry a context
+func WithRegion(ctx context.Context, code string) context.Context {
+	return context.WithValue(ctx, contextKey{}, code)
+}
+
+// FromContext returns the region stored by WithRegion, the default region if none
+func (r *Registry) FromContext(ctx context.Context) (*Region, error) {
+	code, _ := ctx.Value(contextKey{}).(string)
+	return r.Get(code)
+}
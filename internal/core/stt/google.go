@@ -0,0 +1,174 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// GoogleProvider implements speech-to-text using the Google Cloud Speech-to-Text API
+type GoogleProvider struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewGoogleProvider creates a new Google Speech-to-Text provider
+func NewGoogleProvider(apiKey string) *GoogleProvider {
+	return &GoogleProvider{
+		apiKey: apiKey,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// GetProviderName returns the provider name
+func (p *GoogleProvider) GetProviderName() string {
+	return "Google Speech-to-Text"
+}
+
+// Google Speech-to-Text API request/response structures
+type speechRequest struct {
+	Config speechConfig `json:"config"`
+	Audio  speechAudio  `json:"audio"`
+}
+
+type speechConfig struct {
+	Encoding                   string `json:"encoding"`
+	SampleRateHertz            int    `json:"sampleRateHertz,omitempty"`
+	LanguageCode               string `json:"languageCode"`
+	EnableAutomaticPunctuation bool   `json:"enableAutomaticPunctuation"`
+}
+
+type speechAudio struct {
+	Content string `json:"content"` // base64 encoded audio
+}
+
+type speechResponse struct {
+	Results []struct {
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float64 `json:"confidence"`
+		} `json:"alternatives"`
+		LanguageCode string `json:"languageCode"`
+	} `json:"results"`
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Transcribe converts speech to text using synchronous recognition (audio up to one minute)
+func (p *GoogleProvider) Transcribe(ctx context.Context, audio []byte, mimeType, language string) (*Transcript, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("google speech: GOOGLE_STT_API_KEY is not set")
+	}
+
+	config := speechConfig{
+		LanguageCode:               language,
+		EnableAutomaticPunctuation: true,
+	}
+	if config.LanguageCode == "" {
+		config.LanguageCode = "id-ID"
+	}
+	switch baseMimeType(mimeType) {
+	case "audio/ogg", "audio/opus", "":
+		// WhatsApp voice notes are Opus in an Ogg container
+		config.Encoding = "OGG_OPUS"
+		config.SampleRateHertz = opusSampleRate(audio)
+	case "audio/webm":
+		config.Encoding = "WEBM_OPUS"
+		config.SampleRateHertz = 48000
+	case "audio/flac":
+		config.Encoding = "FLAC"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		config.Encoding = "LINEAR16"
+	case "audio/amr":
+		config.Encoding = "AMR"
+		config.SampleRateHertz = 8000
+	default:
+		return nil, fmt.Errorf("google speech: unsupported audio format %s", mimeType)
+	}
+
+	jsonData, err := json.Marshal(speechRequest{
+		Config: config,
+		Audio:  speechAudio{Content: base64.StdEncoding.EncodeToString(audio)},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("https://speech.googleapis.com/v1/speech:recognize?key=%s", p.apiKey)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("google speech error (status: %d): %s", resp.StatusCode, string(body))
+	}
+
+	var speechResp speechResponse
+	if err := json.Unmarshal(body, &speechResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if speechResp.Error != nil {
+		return nil, fmt.Errorf("google speech error: %s", speechResp.Error.Message)
+	}
+
+	// Long audio comes back as consecutive results; the best alternative of each is joined
+	transcript := &Transcript{Language: config.LanguageCode}
+	var text bytes.Buffer
+	confidenceTotal := 0.0
+	for _, result := range speechResp.Results {
+		if len(result.Alternatives) == 0 {
+			continue
+		}
+		if text.Len() > 0 {
+			text.WriteByte(' ')
+		}
+		text.WriteString(result.Alternatives[0].Transcript)
+		confidenceTotal += result.Alternatives[0].Confidence
+		if result.LanguageCode != "" {
+			transcript.Language = result.LanguageCode
+		}
+	}
+	transcript.Text = text.String()
+	if len(speechResp.Results) > 0 {
+		transcript.Confidence = confidenceTotal / float64(len(speechResp.Results))
+	}
+
+	return transcript, nil
+}
+
+// opusSampleRate reads the input sample rate from the OpusHead header of an Ogg Opus stream,
+// falling back to 48 kHz (the Opus decoding rate) when it is missing or not one Google accepts
+func opusSampleRate(audio []byte) int {
+	header := bytes.Index(audio[:min(len(audio), 512)], []byte("OpusHead"))
+	if header < 0 || len(audio) < header+16 {
+		return 48000
+	}
+	switch rate := int(binary.LittleEndian.Uint32(audio[header+12 : header+16])); rate {
+	case 8000, 12000, 16000, 24000, 48000:
+		return rate
+	}
+	return 48000
+}
@@ -0,0 +1,55 @@
+package stt
+
+import (
+	"context"
+	"strings"
+)
+
+// Provider interface for speech-to-text services
+type Provider interface {
+	// Transcribe converts recorded speech to text. mimeType is the audio format (e.g. audio/ogg; codecs=opus)
+	// and language a BCP-47 code (e.g. id-ID); an empty language lets the provider detect it where supported.
+	Transcribe(ctx context.Context, audio []byte, mimeType, language string) (*Transcript, error)
+
+	// GetProviderName returns the provider name
+	GetProviderName() string
+}
+
+// Transcript contains the recognized text and metadata
+type Transcript struct {
+	Text       string  `json:"text"`               // Recognized text
+	Language   string  `json:"language,omitempty"` // Language the provider heard, when it reports one
+	Confidence float64 `json:"confidence"`         // Recognition confidence (0-1), 0 when not reported
+}
+
+// Service wraps the speech-to-text provider
+type Service struct {
+	provider Provider
+	language string
+}
+
+// NewService creates a new speech-to-text service; language is the default BCP-47 code of the audio
+func NewService(provider Provider, language string) *Service {
+	return &Service{provider: provider, language: language}
+}
+
+// Transcribe converts recorded speech to text using the configured provider
+func (s *Service) Transcribe(ctx context.Context, audio []byte, mimeType string) (*Transcript, error) {
+	transcript, err := s.provider.Transcribe(ctx, audio, mimeType, s.language)
+	if err != nil {
+		return nil, err
+	}
+	transcript.Text = strings.TrimSpace(transcript.Text)
+	return transcript, nil
+}
+
+// GetProviderName returns the name of the current provider
+func (s *Service) GetProviderName() string {
+	return s.provider.GetProviderName()
+}
+
+// baseMimeType strips parameters such as codecs from a MIME type
+func baseMimeType(mimeType string) string {
+	base, _, _ := strings.Cut(mimeType, ";")
+	return strings.ToLower(strings.TrimSpace(base))
+}
@@ -0,0 +1,127 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// WhisperProvider implements speech-to-text using the OpenAI Whisper API
+type WhisperProvider struct {
+	apiKey string
+	model  string
+	client *http.Client
+}
+
+// NewWhisperProvider creates a new Whisper provider
+func NewWhisperProvider(apiKey string) *WhisperProvider {
+	return &WhisperProvider{
+		apiKey: apiKey,
+		model:  "whisper-1",
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}
+}
+
+// GetProviderName returns the provider name
+func (p *WhisperProvider) GetProviderName() string {
+	return "OpenAI Whisper"
+}
+
+type whisperResponse struct {
+	Text     string `json:"text"`
+	Language string `json:"language"`
+	Error    *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// Transcribe converts speech to text using the Whisper transcription endpoint
+func (p *WhisperProvider) Transcribe(ctx context.Context, audio []byte, mimeType, language string) (*Transcript, error) {
+	if p.apiKey == "" {
+		return nil, fmt.Errorf("whisper: OPENAI_API_KEY is not set")
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	// Whisper detects the format from the file name
+	part, err := writer.CreateFormFile("file", "voice."+whisperExtension(mimeType))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(audio); err != nil {
+		return nil, fmt.Errorf("failed to write audio: %w", err)
+	}
+
+	writer.WriteField("model", p.model)
+	writer.WriteField("response_format", "verbose_json")
+	if language != "" {
+		// Whisper takes ISO-639-1 codes (id, en), not regional variants
+		code, _, _ := strings.Cut(language, "-")
+		writer.WriteField("language", strings.ToLower(code))
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close writer: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://api.openai.com/v1/audio/transcriptions", &buf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("whisper request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var whisperResp whisperResponse
+	if err := json.Unmarshal(body, &whisperResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response (status: %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if whisperResp.Error != nil {
+			return nil, fmt.Errorf("whisper error (status: %d): %s", resp.StatusCode, whisperResp.Error.Message)
+		}
+		return nil, fmt.Errorf("whisper error (status: %d): %s", resp.StatusCode, string(body))
+	}
+
+	return &Transcript{
+		Text:     whisperResp.Text,
+		Language: whisperResp.Language,
+	}, nil
+}
+
+// whisperExtension maps an audio MIME type to a file extension Whisper accepts
+func whisperExtension(mimeType string) string {
+	switch baseMimeType(mimeType) {
+	case "audio/mpeg", "audio/mp3":
+		return "mp3"
+	case "audio/mp4", "audio/m4a", "audio/x-m4a", "audio/aac":
+		return "m4a"
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "wav"
+	case "audio/webm":
+		return "webm"
+	case "audio/flac":
+		return "flac"
+	default:
+		// WhatsApp voice notes are Opus in an Ogg container
+		return "ogg"
+	}
+}
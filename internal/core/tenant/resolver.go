@@ -1,8 +1,14 @@
 package tenant
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/cache"
+	"gorm.io/gorm"
 )
 
 type TenantContext struct {
@@ -10,35 +16,150 @@ type TenantContext struct {
 	Module    string // "saas", "farmasi", "umkm"
 	Role      string // "customer", "admin", "staff"
 	ClientID  string // ID dari table clients (untuk backward compatibility)
+	Region    string // Region tempat data tenant disimpan (kosong = region default)
 }
 
 type Resolver struct {
-	db *sql.DB
+	db    *sql.DB
+	cache *cache.Cache // nil when Redis caching is off
 }
 
 func NewResolver(db *sql.DB) *Resolver {
 	return &Resolver{db: db}
 }
 
+// tenantCachePrefix starts the keys of cached resolutions
+const tenantCachePrefix = "tenant:"
+
+// SetCache caches resolutions in Redis. A resolution depends on company_users and clients rows, so
+// any write to them seen through db drops every cached resolution.
+func (r *Resolver) SetCache(c *cache.Cache, db *gorm.DB) error {
+	r.cache = c
+	invalidate := func(ctx context.Context, _ []string) {
+		c.DeletePrefix(ctx, tenantCachePrefix)
+	}
+	if err := c.OnWrite(db, "tenant:company_users", "company_users", "id", invalidate); err != nil {
+		return err
+	}
+	return c.OnWrite(db, "tenant:clients", "clients", "id", invalidate)
+}
+
+// ErrSessionNotMapped is returned for messages received on a session no active client is mapped to. They
+// must be dropped: answering them under another tenant would leak that tenant's bot and data.
+var ErrSessionNotMapped = errors.New("session is not mapped to an active client")
+
+// ResolveFromSession menentukan tenant dari session WhatsApp yang menerima pesan (WAHA session, Cloud API
+// phone number ID, Green API instance). Nomor pengirim hanya menentukan role di tenant tersebut, jadi
+// satu customer bisa chat ke beberapa tenant. Hanya deployment satu nomor (session kosong atau "default")
+// yang masih di-resolve dari nomor pengirim; session lain yang tidak dipetakan ke client aktif (termasuk
+// client yang dinonaktifkan) mengembalikan ErrSessionNotMapped.
+func (r *Resolver) ResolveFromSession(sessionID, senderPhone string) (*TenantContext, error) {
+	cleanPhone := strings.TrimPrefix(senderPhone, "+")
+
+	cacheKey := tenantCachePrefix + "session:" + sessionID + ":" + cleanPhone
+	var cached TenantContext
+	if r.cache.Get(context.Background(), cacheKey, &cached) {
+		return &cached, nil
+	}
+
+	ctx, err := r.resolveFromSession(sessionID, cleanPhone)
+	if errors.Is(err, sql.ErrNoRows) {
+		if !SingleNumberSession(sessionID) {
+			return nil, fmt.Errorf("%w: %s", ErrSessionNotMapped, sessionID)
+		}
+		ctx, err = r.resolveFromPhone(cleanPhone)
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(context.Background(), cacheKey, ctx)
+	return ctx, nil
+}
+
+// SingleNumberSession reports whether a session is the only one of a single-number deployment, whose
+// messages are resolved from the sender's phone when no client is mapped to it
+func SingleNumberSession(sessionID string) bool {
+	return sessionID == "" || sessionID == "default"
+}
+
+// resolveFromSession looks the client mapped to a session up, and the sender's role in it. sql.ErrNoRows
+// is returned when no active client is mapped to the session.
+func (r *Resolver) resolveFromSession(sessionID, cleanPhone string) (*TenantContext, error) {
+	if sessionID == "" {
+		return nil, sql.ErrNoRows
+	}
+
+	ctx := &TenantContext{}
+	var clientNumber string
+	query := `
+		SELECT id, module, id as client_id, COALESCE(region, ''), COALESCE(whatsapp_number, '')
+		FROM clients
+		WHERE whatsapp_session_id = $1 AND subscription_status = 'active'
+		LIMIT 1
+	`
+	err := r.db.QueryRow(query, sessionID).Scan(&ctx.CompanyID, &ctx.Module, &ctx.ClientID, &ctx.Region, &clientNumber)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to resolve session %s: %w", sessionID, err)
+	}
+
+	// Role pengirim: admin/staff dari company_users, pemilik bisnis dari whatsapp_number, selain itu customer
+	roleQuery := `
+		SELECT role
+		FROM company_users
+		WHERE client_id = $1 AND phone_number = $2
+		LIMIT 1
+	`
+	err = r.db.QueryRow(roleQuery, ctx.ClientID, cleanPhone).Scan(&ctx.Role)
+	switch {
+	case err == nil:
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, fmt.Errorf("failed to resolve role of %s: %w", cleanPhone, err)
+	case clientNumber != "" && strings.TrimPrefix(clientNumber, "+") == cleanPhone:
+		ctx.Role = "admin"
+	default:
+		ctx.Role = "customer"
+	}
+	return ctx, nil
+}
+
 // ResolveFromPhone menentukan company_id, module, dan role dari nomor WA
+//
+// Deprecated: a customer chatting with several tenants resolves to only one of them. Use
+// ResolveFromSession with the session the message was received on.
 func (r *Resolver) ResolveFromPhone(phoneNumber string) (*TenantContext, error) {
 	// Format: hapus prefix +, ambil nomor saja
-	cleanPhone := phoneNumber
-	if len(cleanPhone) > 0 && cleanPhone[0] == '+' {
-		cleanPhone = cleanPhone[1:]
+	cleanPhone := strings.TrimPrefix(phoneNumber, "+")
+
+	cacheKey := tenantCachePrefix + "phone:" + cleanPhone
+	var cached TenantContext
+	if r.cache.Get(context.Background(), cacheKey, &cached) {
+		return &cached, nil
 	}
 
+	ctx, err := r.resolveFromPhone(cleanPhone)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(context.Background(), cacheKey, ctx)
+	return ctx, nil
+}
+
+// resolveFromPhone looks the tenant of a phone number without + up in the database
+func (r *Resolver) resolveFromPhone(cleanPhone string) (*TenantContext, error) {
 	ctx := &TenantContext{}
 
 	// Query 1: Cek apakah ini admin/staff dari company
 	query := `
-		SELECT cu.client_id as company_id, c.module, cu.role, cu.client_id
+		SELECT cu.client_id as company_id, c.module, cu.role, cu.client_id, COALESCE(c.region, '')
 		FROM company_users cu
 		JOIN clients c ON c.id = cu.client_id
 		WHERE cu.phone_number = $1 AND c.subscription_status = 'active'
 		LIMIT 1
 	`
-	err := r.db.QueryRow(query, cleanPhone).Scan(&ctx.CompanyID, &ctx.Module, &ctx.Role, &ctx.ClientID)
+	err := r.db.QueryRow(query, cleanPhone).Scan(&ctx.CompanyID, &ctx.Module, &ctx.Role, &ctx.ClientID, &ctx.Region)
 	if err == nil {
 		return ctx, nil
 	}
@@ -46,12 +167,12 @@ func (r *Resolver) ResolveFromPhone(phoneNumber string) (*TenantContext, error)
 	// Query 2: Jika tidak ketemu, cek di table clients (check if business owner/admin)
 	// Business owner yang whatsapp_number nya terdaftar di clients
 	queryLegacy := `
-		SELECT id, module, 'admin' as role, id as client_id
+		SELECT id, module, 'admin' as role, id as client_id, COALESCE(region, '')
 		FROM clients
 		WHERE whatsapp_number = $1 AND subscription_status = 'active'
 		LIMIT 1
 	`
-	err = r.db.QueryRow(queryLegacy, cleanPhone).Scan(&ctx.CompanyID, &ctx.Module, &ctx.Role, &ctx.ClientID)
+	err = r.db.QueryRow(queryLegacy, cleanPhone).Scan(&ctx.CompanyID, &ctx.Module, &ctx.Role, &ctx.ClientID, &ctx.Region)
 	if err == nil {
 		return ctx, nil
 	}
@@ -60,12 +181,12 @@ func (r *Resolver) ResolveFromPhone(phoneNumber string) (*TenantContext, error)
 	// Ambil client pertama yang aktif (untuk demo/testing)
 	// CATATAN: Di production, ini harus lebih smart (misal dari context routing)
 	queryDefault := `
-		SELECT id, module, id as client_id
+		SELECT id, module, id as client_id, COALESCE(region, '')
 		FROM clients
 		WHERE subscription_status = 'active'
 		LIMIT 1
 	`
-	err = r.db.QueryRow(queryDefault).Scan(&ctx.CompanyID, &ctx.Module, &ctx.ClientID)
+	err = r.db.QueryRow(queryDefault).Scan(&ctx.CompanyID, &ctx.Module, &ctx.ClientID, &ctx.Region)
 	if err != nil {
 		return nil, fmt.Errorf("no active client found")
 	}
@@ -81,11 +202,11 @@ func (r *Resolver) ResolveFromClientID(clientID string) (*TenantContext, error)
 	}
 
 	query := `
-		SELECT id, module
+		SELECT id, module, COALESCE(region, '')
 		FROM clients
 		WHERE id = $1
 	`
-	err := r.db.QueryRow(query, clientID).Scan(&ctx.CompanyID, &ctx.Module)
+	err := r.db.QueryRow(query, clientID).Scan(&ctx.CompanyID, &ctx.Module, &ctx.Region)
 	if err != nil {
 		return nil, fmt.Errorf("client not found")
 	}
@@ -0,0 +1,80 @@
+package tenant
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	_ "modernc.org/sqlite"
+)
+
+// newTestResolver creates a resolver over an in-memory database with two clients: shop-a is active on
+// session wa-shop-a, shop-b was deactivated but is still mapped to session wa-shop-b
+func newTestResolver(t *testing.T) *Resolver {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	db.SetMaxOpenConns(1) // Every connection would get its own in-memory database
+	t.Cleanup(func() { db.Close() })
+
+	statements := []string{
+		`CREATE TABLE clients (
+			id TEXT PRIMARY KEY,
+			module TEXT,
+			region TEXT,
+			whatsapp_number TEXT,
+			whatsapp_session_id TEXT,
+			subscription_status TEXT
+		)`,
+		`CREATE TABLE company_users (client_id TEXT, phone_number TEXT, role TEXT)`,
+		`INSERT INTO clients VALUES ('shop-a', 'saas', '', '6281100000001', 'wa-shop-a', 'active')`,
+		`INSERT INTO clients VALUES ('shop-b', 'saas', '', '6281100000002', 'wa-shop-b', 'inactive')`,
+		`INSERT INTO company_users VALUES ('shop-a', '6281200000001', 'staff')`,
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatalf("failed to set up database: %v", err)
+		}
+	}
+	return NewResolver(db)
+}
+
+func TestResolveFromSession(t *testing.T) {
+	tests := []struct {
+		name       string
+		sessionID  string
+		phone      string
+		wantClient string
+		wantRole   string
+		wantErr    error
+	}{
+		{name: "mapped session, customer", sessionID: "wa-shop-a", phone: "+6281300000001", wantClient: "shop-a", wantRole: "customer"},
+		{name: "mapped session, staff", sessionID: "wa-shop-a", phone: "6281200000001", wantClient: "shop-a", wantRole: "staff"},
+		{name: "mapped session, owner", sessionID: "wa-shop-a", phone: "6281100000001", wantClient: "shop-a", wantRole: "admin"},
+		{name: "session of a deactivated client", sessionID: "wa-shop-b", phone: "6281300000001", wantErr: ErrSessionNotMapped},
+		{name: "unmapped session", sessionID: "wa-unknown", phone: "6281300000001", wantErr: ErrSessionNotMapped},
+		{name: "default session falls back to the phone", sessionID: "default", phone: "6281200000001", wantClient: "shop-a", wantRole: "staff"},
+		{name: "no session falls back to the phone", sessionID: "", phone: "6281300000001", wantClient: "shop-a", wantRole: "customer"},
+	}
+
+	r := newTestResolver(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := r.ResolveFromSession(tt.sessionID, tt.phone)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %+v, %v; want error %v", ctx, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ctx.ClientID != tt.wantClient || ctx.Role != tt.wantRole {
+				t.Errorf("got client %s as %s, want client %s as %s", ctx.ClientID, ctx.Role, tt.wantClient, tt.wantRole)
+			}
+		})
+	}
+}
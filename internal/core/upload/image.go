@@ -0,0 +1,223 @@
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+)
+
+// WhatsAppMaxImageSize is the size limit for images sent through WhatsApp
+const WhatsAppMaxImageSize = 1024 * 1024 // 1MB
+
+// ImageVariant describes a resized rendition of an uploaded image
+type ImageVariant struct {
+	Name      string // Suffix used in the public ID (e.g. "thumb")
+	MaxWidth  int
+	MaxHeight int
+	Quality   int   // JPEG quality
+	MaxBytes  int64 // Re-encode with lower quality until under this size (0 = no limit)
+}
+
+// ImageVariantResult holds the upload result of every generated variant
+type ImageVariantResult struct {
+	Original  *UploadResult `json:"original"`
+	Thumbnail *UploadResult `json:"thumbnail"`
+	WhatsApp  *UploadResult `json:"whatsapp"`
+}
+
+// PublicIDs returns the public IDs of all uploaded variants
+func (r *ImageVariantResult) PublicIDs() []string {
+	var ids []string
+	for _, res := range []*UploadResult{r.Original, r.Thumbnail, r.WhatsApp} {
+		if res != nil && res.PublicID != "" {
+			ids = append(ids, res.PublicID)
+		}
+	}
+	return ids
+}
+
+// Default image variants for catalog images
+var (
+	VariantOriginal  = ImageVariant{Name: "original", MaxWidth: 1600, MaxHeight: 1600, Quality: 90}
+	VariantThumbnail = ImageVariant{Name: "thumb", MaxWidth: 300, MaxHeight: 300, Quality: 80}
+	VariantWhatsApp  = ImageVariant{Name: "wa", MaxWidth: 1024, MaxHeight: 1024, Quality: 85, MaxBytes: WhatsAppMaxImageSize}
+)
+
+// UploadImageVariants decodes an image, generates resized variants and uploads them via the provider
+func (s *Service) UploadImageVariants(file io.Reader, folder, baseID string) (*ImageVariantResult, error) {
+	if s.provider == nil {
+		return nil, fmt.Errorf("upload provider not configured")
+	}
+
+	src, format, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	log.Printf("🖼️  Processing %s image %dx%d", format, src.Bounds().Dx(), src.Bounds().Dy())
+
+	result := &ImageVariantResult{}
+	targets := []struct {
+		variant ImageVariant
+		dest    **UploadResult
+	}{
+		{VariantOriginal, &result.Original},
+		{VariantThumbnail, &result.Thumbnail},
+		{VariantWhatsApp, &result.WhatsApp},
+	}
+
+	for _, target := range targets {
+		data, err := EncodeVariant(src, target.variant)
+		if err != nil {
+			s.deleteUploaded(result.PublicIDs())
+			return nil, fmt.Errorf("failed to encode %s variant: %w", target.variant.Name, err)
+		}
+
+		uploaded, err := s.provider.Upload(bytes.NewReader(data), baseID+"_"+target.variant.Name+".jpg", &UploadOptions{
+			Folder:       folder,
+			PublicID:     baseID + "_" + target.variant.Name,
+			Overwrite:    true,
+			ResourceType: "image",
+		})
+		if err != nil {
+			s.deleteUploaded(result.PublicIDs())
+			return nil, fmt.Errorf("failed to upload %s variant: %w", target.variant.Name, err)
+		}
+
+		*target.dest = uploaded
+	}
+
+	return result, nil
+}
+
+// DeleteMany deletes several files, logging failures instead of stopping
+func (s *Service) DeleteMany(publicIDs []string) int {
+	return s.deleteUploaded(publicIDs)
+}
+
+// deleteUploaded removes uploaded files and returns how many were deleted
+func (s *Service) deleteUploaded(publicIDs []string) int {
+	deleted := 0
+	for _, id := range publicIDs {
+		if err := s.Delete(id); err != nil {
+			log.Printf("⚠️  Failed to delete media %s: %v", id, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted
+}
+
+// EncodeVariant resizes an image to fit the variant bounds and encodes it as JPEG
+func EncodeVariant(src image.Image, variant ImageVariant) ([]byte, error) {
+	resized := resizeToFit(src, variant.MaxWidth, variant.MaxHeight)
+
+	quality := variant.Quality
+	if quality <= 0 {
+		quality = 85
+	}
+
+	for {
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, err
+		}
+
+		if variant.MaxBytes <= 0 || int64(buf.Len()) <= variant.MaxBytes {
+			return buf.Bytes(), nil
+		}
+
+		// Lower quality first, then shrink dimensions
+		if quality > 40 {
+			quality -= 10
+			continue
+		}
+
+		bounds := resized.Bounds()
+		if bounds.Dx() < 200 || bounds.Dy() < 200 {
+			return buf.Bytes(), nil
+		}
+		resized = resizeToFit(resized, bounds.Dx()*3/4, bounds.Dy()*3/4)
+	}
+}
+
+// resizeToFit scales an image down (never up) to fit within maxWidth x maxHeight, keeping aspect ratio
+func resizeToFit(src image.Image, maxWidth, maxHeight int) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	if (maxWidth <= 0 || srcW <= maxWidth) && (maxHeight <= 0 || srcH <= maxHeight) {
+		return flatten(src)
+	}
+
+	scale := 1.0
+	if maxWidth > 0 && srcW > maxWidth {
+		scale = float64(maxWidth) / float64(srcW)
+	}
+	if maxHeight > 0 && float64(srcH)*scale > float64(maxHeight) {
+		scale = float64(maxHeight) / float64(srcH)
+	}
+
+	dstW := int(float64(srcW) * scale)
+	dstH := int(float64(srcH) * scale)
+	if dstW < 1 {
+		dstW = 1
+	}
+	if dstH < 1 {
+		dstH = 1
+	}
+
+	// Area-average downsampling
+	dst := image.NewRGBA(image.Rect(0, 0, dstW, dstH))
+	for y := 0; y < dstH; y++ {
+		sy0 := bounds.Min.Y + y*srcH/dstH
+		sy1 := bounds.Min.Y + (y+1)*srcH/dstH
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := 0; x < dstW; x++ {
+			sx0 := bounds.Min.X + x*srcW/dstW
+			sx1 := bounds.Min.X + (x+1)*srcW/dstW
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r += uint64(cr)
+					g += uint64(cg)
+					b += uint64(cb)
+					a += uint64(ca)
+					n++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / n),
+				G: uint16(g / n),
+				B: uint16(b / n),
+				A: uint16(a / n),
+			})
+		}
+	}
+
+	return flatten(dst)
+}
+
+// flatten draws an image onto a white background so transparency survives JPEG encoding
+func flatten(src image.Image) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(dst, dst.Bounds(), &image.Uniform{C: color.White}, image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, bounds.Min, draw.Over)
+	return dst
+}
@@ -28,6 +28,9 @@ type Provider interface {
 	// GetCollectionInfo gets information about a collection
 	GetCollectionInfo(ctx context.Context, collection string) (*CollectionInfo, error)
 
+	// CreatePayloadIndex indexes a payload field so filters on it don't scan the whole collection
+	CreatePayloadIndex(ctx context.Context, collection string, index PayloadIndex) error
+
 	// Close closes the connection
 	Close() error
 
@@ -77,4 +80,16 @@ type CollectionInfo struct {
 	VectorSize  int    `json:"vector_size"`
 	PointsCount int64  `json:"points_count"`
 	Status      string `json:"status"`
+
+	PayloadIndexes map[string]string `json:"payload_indexes,omitempty"` // Indexed payload field -> index type
+}
+
+// PayloadIndexKeyword indexes exact-match string fields
+const PayloadIndexKeyword = "keyword"
+
+// PayloadIndex describes an index on a payload field
+type PayloadIndex struct {
+	Field  string `json:"field"`
+	Type   string `json:"type"`   // keyword
+	Tenant bool   `json:"tenant"` // Field partitions the collection per tenant (Qdrant co-locates each tenant's points)
 }
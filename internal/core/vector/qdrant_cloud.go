@@ -153,8 +153,11 @@ func (p *QdrantCloudProvider) GetCollectionInfo(ctx context.Context, collection
 					} `json:"vectors"`
 				} `json:"params"`
 			} `json:"config"`
-			PointsCount int64  `json:"points_count"`
-			Status      string `json:"status"`
+			PointsCount   int64  `json:"points_count"`
+			Status        string `json:"status"`
+			PayloadSchema map[string]struct {
+				DataType string `json:"data_type"`
+			} `json:"payload_schema"`
 		} `json:"result"`
 	}
 
@@ -163,14 +166,38 @@ func (p *QdrantCloudProvider) GetCollectionInfo(ctx context.Context, collection
 		return nil, err
 	}
 
+	indexes := make(map[string]string, len(response.Result.PayloadSchema))
+	for field, schema := range response.Result.PayloadSchema {
+		indexes[field] = schema.DataType
+	}
+
 	return &CollectionInfo{
-		Name:        collection,
-		VectorSize:  response.Result.Config.Params.Vectors.Size,
-		PointsCount: response.Result.PointsCount,
-		Status:      response.Result.Status,
+		Name:           collection,
+		VectorSize:     response.Result.Config.Params.Vectors.Size,
+		PointsCount:    response.Result.PointsCount,
+		Status:         response.Result.Status,
+		PayloadIndexes: indexes,
 	}, nil
 }
 
+// CreatePayloadIndex creates a payload index (succeeds when it already exists)
+func (p *QdrantCloudProvider) CreatePayloadIndex(ctx context.Context, collection string, index PayloadIndex) error {
+	var schema interface{} = index.Type
+	if index.Tenant {
+		schema = map[string]interface{}{
+			"type":      index.Type,
+			"is_tenant": true,
+		}
+	}
+
+	payload := map[string]interface{}{
+		"field_name":   index.Field,
+		"field_schema": schema,
+	}
+
+	return p.doRequest(ctx, "PUT", fmt.Sprintf("/collections/%s/index?wait=true", collection), payload, nil)
+}
+
 // Close closes the connection
 func (p *QdrantCloudProvider) Close() error {
 	// HTTP client doesn't need explicit closing
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
 
 	qdrant "github.com/qdrant/go-client/qdrant"
 	"google.golang.org/grpc"
@@ -236,14 +237,49 @@ func (p *QdrantSelfHostedProvider) GetCollectionInfo(ctx context.Context, collec
 		pointsCount = int64(*result.PointsCount)
 	}
 
+	indexes := make(map[string]string, len(result.PayloadSchema))
+	for field, schema := range result.PayloadSchema {
+		indexes[field] = strings.ToLower(schema.DataType.String())
+	}
+
 	return &CollectionInfo{
-		Name:        collection,
-		VectorSize:  vectorSize,
-		PointsCount: pointsCount,
-		Status:      result.Status.String(),
+		Name:           collection,
+		VectorSize:     vectorSize,
+		PointsCount:    pointsCount,
+		Status:         result.Status.String(),
+		PayloadIndexes: indexes,
 	}, nil
 }
 
+// CreatePayloadIndex creates a payload index (succeeds when it already exists)
+func (p *QdrantSelfHostedProvider) CreatePayloadIndex(ctx context.Context, collection string, index PayloadIndex) error {
+	if index.Type != PayloadIndexKeyword {
+		return fmt.Errorf("unsupported payload index type: %s", index.Type)
+	}
+
+	wait := true
+	request := &qdrant.CreateFieldIndexCollection{
+		CollectionName: collection,
+		Wait:           &wait,
+		FieldName:      index.Field,
+		FieldType:      qdrant.FieldType_FieldTypeKeyword.Enum(),
+	}
+	if index.Tenant {
+		request.FieldIndexParams = &qdrant.PayloadIndexParams{
+			IndexParams: &qdrant.PayloadIndexParams_KeywordIndexParams{
+				KeywordIndexParams: &qdrant.KeywordIndexParams{IsTenant: &index.Tenant},
+			},
+		}
+	}
+
+	if _, err := p.client.CreateFieldIndex(ctx, request); err != nil {
+		return fmt.Errorf("failed to create payload index on %s: %w", index.Field, err)
+	}
+
+	log.Printf("✅ Payload index '%s' (%s) created on '%s'", index.Field, index.Type, collection)
+	return nil
+}
+
 // Close closes the gRPC connection
 func (p *QdrantSelfHostedProvider) Close() error {
 	if p.grpcConn != nil {
@@ -138,6 +138,26 @@ func (s *Service) GetCollectionInfo(ctx context.Context, collection string) (*Co
 	return s.provider.GetCollectionInfo(ctx, collection)
 }
 
+// EnsurePayloadIndexes creates the payload indexes a collection is missing and returns the fields it indexed
+func (s *Service) EnsurePayloadIndexes(ctx context.Context, collection string, indexes []PayloadIndex) ([]string, error) {
+	info, err := s.provider.GetCollectionInfo(ctx, collection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read collection '%s': %w", collection, err)
+	}
+
+	created := []string{}
+	for _, index := range indexes {
+		if _, ok := info.PayloadIndexes[index.Field]; ok {
+			continue
+		}
+		if err := s.provider.CreatePayloadIndex(ctx, collection, index); err != nil {
+			return created, fmt.Errorf("failed to index '%s' on '%s': %w", index.Field, collection, err)
+		}
+		created = append(created, index.Field)
+	}
+	return created, nil
+}
+
 // Close closes all connections
 func (s *Service) Close() error {
 	return s.provider.Close()
@@ -94,7 +94,17 @@ func (p *CloudAPIProvider) Disconnect() {
 }
 
 // SendMessage sends a text message via Cloud API
-func (p *CloudAPIProvider) SendMessage(to, message string) error {
+func (p *CloudAPIProvider) SendMessage(to, message string) (string, error) {
+	return p.sendText(to, message, "")
+}
+
+// SendReply sends a text message quoting an earlier message (Cloud API context.message_id)
+func (p *CloudAPIProvider) SendReply(to, message, quotedMessageID string) (string, error) {
+	return p.sendText(to, message, quotedMessageID)
+}
+
+// sendText sends a text message (optionally quoting a message) and returns its wamid
+func (p *CloudAPIProvider) sendText(to, message, quotedMessageID string) (string, error) {
 	// Remove @ suffix if present (Cloud API uses plain phone numbers)
 	to = cleanPhoneNumber(to)
 
@@ -108,8 +118,28 @@ func (p *CloudAPIProvider) SendMessage(to, message string) error {
 			"body":        message,
 		},
 	}
+	if quotedMessageID != "" {
+		payload["context"] = map[string]string{"message_id": quotedMessageID}
+	}
 
-	return p.sendRequest("POST", "/messages", payload)
+	respBody, err := p.doRequest("POST", "/messages", payload)
+	if err != nil {
+		return "", err
+	}
+	return parseCloudMessageID(respBody), nil
+}
+
+// parseCloudMessageID extracts the wamid of a sent message (empty if the response has none)
+func parseCloudMessageID(body []byte) string {
+	var result struct {
+		Messages []struct {
+			ID string `json:"id"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil || len(result.Messages) == 0 {
+		return ""
+	}
+	return result.Messages[0].ID
 }
 
 // SendMedia sends media (image, document, etc.) via Cloud API
@@ -130,6 +160,139 @@ func (p *CloudAPIProvider) SendMedia(to, mediaType, mediaID, caption string) err
 	return p.sendRequest("POST", "/messages", payload)
 }
 
+// SendImage sends an image by link via Cloud API
+func (p *CloudAPIProvider) SendImage(to string, image Media) (string, error) {
+	return p.sendMediaLink(to, "image", map[string]string{
+		"link":    image.URL,
+		"caption": image.Caption,
+	})
+}
+
+// SendDocument sends a document by link via Cloud API
+func (p *CloudAPIProvider) SendDocument(to string, document Media) (string, error) {
+	return p.sendMediaLink(to, "document", map[string]string{
+		"link":     document.URL,
+		"caption":  document.Caption,
+		"filename": document.filename(),
+	})
+}
+
+// sendMediaLink sends media Meta fetches from a public URL and returns its wamid
+func (p *CloudAPIProvider) sendMediaLink(to, mediaType string, media map[string]string) (string, error) {
+	to = cleanPhoneNumber(to)
+
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              mediaType,
+		mediaType:           media,
+	}
+
+	respBody, err := p.doRequest("POST", "/messages", payload)
+	if err != nil {
+		return "", err
+	}
+	return parseCloudMessageID(respBody), nil
+}
+
+// SendLocation sends a location pin via Cloud API
+func (p *CloudAPIProvider) SendLocation(to string, location Location) error {
+	to = cleanPhoneNumber(to)
+
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "location",
+		"location": map[string]interface{}{
+			"latitude":  location.Latitude,
+			"longitude": location.Longitude,
+			"name":      location.Name,
+			"address":   location.Address,
+		},
+	}
+
+	return p.sendRequest("POST", "/messages", payload)
+}
+
+// SendButtons sends an interactive message with reply buttons via Cloud API
+func (p *CloudAPIProvider) SendButtons(to string, message ButtonMessage) (string, error) {
+	buttons := make([]map[string]interface{}, 0, len(message.Buttons))
+	for _, button := range message.Buttons {
+		buttons = append(buttons, map[string]interface{}{
+			"type": "reply",
+			"reply": map[string]string{
+				"id":    button.ID,
+				"title": button.Title,
+			},
+		})
+	}
+
+	interactive := map[string]interface{}{
+		"type":   "button",
+		"body":   map[string]string{"text": message.Body},
+		"action": map[string]interface{}{"buttons": buttons},
+	}
+	if message.Footer != "" {
+		interactive["footer"] = map[string]string{"text": message.Footer}
+	}
+
+	return p.sendInteractive(to, interactive)
+}
+
+// SendList sends an interactive list message via Cloud API
+func (p *CloudAPIProvider) SendList(to string, message ListMessage) (string, error) {
+	sections := make([]map[string]interface{}, 0, len(message.Sections))
+	for _, section := range message.Sections {
+		rows := make([]map[string]string, 0, len(section.Rows))
+		for _, row := range section.Rows {
+			rows = append(rows, map[string]string{
+				"id":          row.ID,
+				"title":       row.Title,
+				"description": row.Description,
+			})
+		}
+		sections = append(sections, map[string]interface{}{
+			"title": section.Title,
+			"rows":  rows,
+		})
+	}
+
+	interactive := map[string]interface{}{
+		"type": "list",
+		"body": map[string]string{"text": message.Body},
+		"action": map[string]interface{}{
+			"button":   message.ButtonText,
+			"sections": sections,
+		},
+	}
+	if message.Footer != "" {
+		interactive["footer"] = map[string]string{"text": message.Footer}
+	}
+
+	return p.sendInteractive(to, interactive)
+}
+
+// sendInteractive sends an interactive message and returns its wamid
+func (p *CloudAPIProvider) sendInteractive(to string, interactive map[string]interface{}) (string, error) {
+	to = cleanPhoneNumber(to)
+
+	payload := map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"recipient_type":    "individual",
+		"to":                to,
+		"type":              "interactive",
+		"interactive":       interactive,
+	}
+
+	respBody, err := p.doRequest("POST", "/messages", payload)
+	if err != nil {
+		return "", err
+	}
+	return parseCloudMessageID(respBody), nil
+}
+
 // StartTyping sends typing indicator (Cloud API uses "composing" presence)
 func (p *CloudAPIProvider) StartTyping(phoneNumber string) error {
 	// Cloud API doesn't support typing indicators in the same way
@@ -254,20 +417,26 @@ func (p *CloudAPIProvider) DownloadMedia(mediaID string) ([]byte, error) {
 
 // sendRequest is a helper to make API requests
 func (p *CloudAPIProvider) sendRequest(method, endpoint string, payload interface{}) error {
+	_, err := p.doRequest(method, endpoint, payload)
+	return err
+}
+
+// doRequest sends an API request and returns the response body
+func (p *CloudAPIProvider) doRequest(method, endpoint string, payload interface{}) ([]byte, error) {
 	url := p.baseURL + endpoint
 
 	var body io.Reader
 	if payload != nil {
 		jsonData, err := json.Marshal(payload)
 		if err != nil {
-			return fmt.Errorf("failed to marshal payload: %w", err)
+			return nil, fmt.Errorf("failed to marshal payload: %w", err)
 		}
 		body = bytes.NewBuffer(jsonData)
 	}
 
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+p.accessToken)
@@ -275,18 +444,18 @@ func (p *CloudAPIProvider) sendRequest(method, endpoint string, payload interfac
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, _ := io.ReadAll(resp.Body)
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
 	}
 
 	log.Printf("✅ Cloud API request successful: %s %s", method, endpoint)
-	return nil
+	return respBody, nil
 }
 
 // cleanPhoneNumber removes WhatsApp JID suffix (@c.us)
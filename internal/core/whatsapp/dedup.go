@@ -0,0 +1,140 @@
+// internal/core/whatsapp/dedup.go
+package whatsapp
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+)
+
+// DefaultDedupTTL is how long a message ID is remembered. Providers redeliver within minutes,
+// so a day is plenty while keeping the store small
+const DefaultDedupTTL = 24 * time.Hour
+
+// DedupStore remembers incoming message IDs so a message delivered twice (webhook retries,
+// polling overlap, several replicas) is handled once
+type DedupStore interface {
+	// IsDuplicate records the message ID and reports whether it had already been seen.
+	// An empty ID is never a duplicate
+	IsDuplicate(messageID string) bool
+}
+
+// MemoryDedupStore keeps seen IDs in process memory. It is lost on restart and not shared
+// between replicas, so it is only the fallback when no database is available
+type MemoryDedupStore struct {
+	ttl  time.Duration
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewMemoryDedupStore(ttl time.Duration) *MemoryDedupStore {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &MemoryDedupStore{
+		ttl:  ttl,
+		seen: make(map[string]time.Time),
+	}
+}
+
+func (m *MemoryDedupStore) IsDuplicate(messageID string) bool {
+	if messageID == "" {
+		return false
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if seenAt, ok := m.seen[messageID]; ok && now.Sub(seenAt) < m.ttl {
+		return true
+	}
+	m.seen[messageID] = now
+
+	// Drop expired IDs once the map grows, instead of on every call
+	if len(m.seen) > 10000 {
+		for id, seenAt := range m.seen {
+			if now.Sub(seenAt) >= m.ttl {
+				delete(m.seen, id)
+			}
+		}
+	}
+	return false
+}
+
+// PostgresDedupStore keeps seen IDs in the whatsapp_message_dedup table, shared by every replica
+// and kept across restarts. If the database can't be reached it falls back to memory, so a
+// message is handled rather than dropped
+type PostgresDedupStore struct {
+	db       *sql.DB
+	ttl      time.Duration
+	fallback *MemoryDedupStore
+}
+
+func NewPostgresDedupStore(db *sql.DB, ttl time.Duration) *PostgresDedupStore {
+	if ttl <= 0 {
+		ttl = DefaultDedupTTL
+	}
+	return &PostgresDedupStore{
+		db:       db,
+		ttl:      ttl,
+		fallback: NewMemoryDedupStore(ttl),
+	}
+}
+
+func (p *PostgresDedupStore) IsDuplicate(messageID string) bool {
+	if messageID == "" {
+		return false
+	}
+
+	result, err := p.db.Exec(
+		`INSERT INTO whatsapp_message_dedup (message_id) VALUES ($1) ON CONFLICT (message_id) DO NOTHING`,
+		messageID,
+	)
+	if err == nil {
+		var affected int64
+		affected, err = result.RowsAffected()
+		if err == nil {
+			return affected == 0
+		}
+	}
+
+	log.Printf("⚠️ Dedup store unavailable, using memory for message %s: %v", messageID, err)
+	return p.fallback.IsDuplicate(messageID)
+}
+
+// Cleanup deletes IDs older than the TTL
+func (p *PostgresDedupStore) Cleanup() (int64, error) {
+	result, err := p.db.Exec(
+		`DELETE FROM whatsapp_message_dedup WHERE seen_at < NOW() - make_interval(secs => $1)`,
+		p.ttl.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RunCleanupJob periodically deletes expired IDs until ctx is cancelled
+func (p *PostgresDedupStore) RunCleanupJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := p.Cleanup()
+			if err != nil {
+				log.Printf("⚠️ Failed to clean up message dedup store: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("🧹 Removed %d expired message IDs from dedup store", deleted)
+			}
+		}
+	}
+}
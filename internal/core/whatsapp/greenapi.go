@@ -19,6 +19,7 @@ type GreenAPIProvider struct {
 	client      *http.Client
 	connected   bool
 	stopPolling chan bool
+	dedup       DedupStore
 }
 
 func NewGreenAPIProvider(instanceID, token, baseURL string) *GreenAPIProvider {
@@ -30,9 +31,15 @@ func NewGreenAPIProvider(instanceID, token, baseURL string) *GreenAPIProvider {
 			Timeout: 30 * time.Second,
 		},
 		stopPolling: make(chan bool),
+		dedup:       NewMemoryDedupStore(DefaultDedupTTL),
 	}
 }
 
+// SetDedupStore replaces the in-memory dedup of received notifications, e.g. with a store shared by all replicas
+func (g *GreenAPIProvider) SetDedupStore(store DedupStore) {
+	g.dedup = store
+}
+
 func (g *GreenAPIProvider) GetProviderName() string {
 	return "GreenAPI"
 }
@@ -77,7 +84,17 @@ func (g *GreenAPIProvider) Disconnect() {
 	log.Println("🔌 Green API provider disconnected")
 }
 
-func (g *GreenAPIProvider) SendMessage(phoneNumber, message string) error {
+func (g *GreenAPIProvider) SendMessage(phoneNumber, message string) (string, error) {
+	return g.sendText(phoneNumber, message, "")
+}
+
+// SendReply sends a text message quoting an earlier message (Green API quotedMessageId)
+func (g *GreenAPIProvider) SendReply(phoneNumber, message, quotedMessageID string) (string, error) {
+	return g.sendText(phoneNumber, message, quotedMessageID)
+}
+
+// sendText sends a text message (optionally quoting a message) and returns its idMessage
+func (g *GreenAPIProvider) sendText(phoneNumber, message, quotedMessageID string) (string, error) {
 	// Format nomor: 628123456789@c.us
 	chatID := phoneNumber
 	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
@@ -92,6 +109,51 @@ func (g *GreenAPIProvider) SendMessage(phoneNumber, message string) error {
 		"chatId":  chatID,
 		"message": message,
 	}
+	if quotedMessageID != "" {
+		payload["quotedMessageId"] = quotedMessageID
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := g.client.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Green API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		IDMessage string `json:"idMessage"`
+	}
+	_ = json.Unmarshal(body, &result)
+	return result.IDMessage, nil
+}
+
+// SendLocation sends a location pin (Green API sendLocation)
+func (g *GreenAPIProvider) SendLocation(phoneNumber string, location Location) error {
+	chatID := phoneNumber
+	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
+		chatID = phoneNumber[1:] + "@c.us"
+	} else {
+		chatID = phoneNumber + "@c.us"
+	}
+
+	endpoint := fmt.Sprintf("%s/waInstance%s/sendLocation/%s", g.baseURL, g.instanceID, g.token)
+
+	payload := map[string]interface{}{
+		"chatId":       chatID,
+		"nameLocation": location.Name,
+		"address":      location.Address,
+		"latitude":     location.Latitude,
+		"longitude":    location.Longitude,
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -100,7 +162,7 @@ func (g *GreenAPIProvider) SendMessage(phoneNumber, message string) error {
 
 	resp, err := g.client.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send location: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -112,6 +174,67 @@ func (g *GreenAPIProvider) SendMessage(phoneNumber, message string) error {
 	return nil
 }
 
+// SendImage sends an image by URL (Green API sendFileByUrl)
+func (g *GreenAPIProvider) SendImage(phoneNumber string, image Media) (string, error) {
+	return g.sendFileByURL(phoneNumber, image.URL, image.filename(), image.Caption)
+}
+
+// SendDocument sends a document by URL (Green API sendFileByUrl)
+func (g *GreenAPIProvider) SendDocument(phoneNumber string, document Media) (string, error) {
+	return g.sendFileByURL(phoneNumber, document.URL, document.filename(), document.Caption)
+}
+
+// sendFileByURL sends a file Green API downloads from a URL; the file name's extension decides how it is shown
+func (g *GreenAPIProvider) sendFileByURL(phoneNumber, fileURL, filename, caption string) (string, error) {
+	chatID := phoneNumber
+	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
+		chatID = phoneNumber[1:] + "@c.us"
+	} else {
+		chatID = phoneNumber + "@c.us"
+	}
+
+	endpoint := fmt.Sprintf("%s/waInstance%s/sendFileByUrl/%s", g.baseURL, g.instanceID, g.token)
+
+	payload := map[string]interface{}{
+		"chatId":   chatID,
+		"urlFile":  fileURL,
+		"fileName": filename,
+		"caption":  caption,
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	resp, err := g.client.Post(endpoint, "application/json", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to send file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Green API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		IDMessage string `json:"idMessage"`
+	}
+	_ = json.Unmarshal(body, &result)
+	return result.IDMessage, nil
+}
+
+// SendButtons sends the buttons as plain text; Green API no longer delivers button messages
+func (g *GreenAPIProvider) SendButtons(phoneNumber string, message ButtonMessage) (string, error) {
+	return g.sendText(phoneNumber, message.Text(), "")
+}
+
+// SendList sends the list menu as plain text
+func (g *GreenAPIProvider) SendList(phoneNumber string, message ListMessage) (string, error) {
+	return g.sendText(phoneNumber, message.Text(), "")
+}
+
 func (g *GreenAPIProvider) StartListening(handler func(evt interface{})) error {
 	// Green API menggunakan webhook atau polling
 	// Untuk simplicity, kita gunakan polling dengan receiveNotification
@@ -180,6 +303,12 @@ func (g *GreenAPIProvider) pollMessages(handler func(evt interface{})) {
 	if notification.Body.TypeWebhook == "incomingMessageReceived" &&
 		notification.Body.MessageData.TypeMessage == "textMessage" {
 
+		// A notification not deleted in time is received again; handle the message once
+		if g.dedup.IsDuplicate(notification.Body.IDMessage) {
+			g.deleteNotification(notification.ReceiptID)
+			return
+		}
+
 		// Extract phone number dari sender (format: 628xxx@c.us)
 		sender := notification.Body.SenderData.Sender
 
@@ -192,12 +321,16 @@ func (g *GreenAPIProvider) pollMessages(handler func(evt interface{})) {
 		handler(evt)
 
 		// Delete notification after processing
-		deleteEndpoint := fmt.Sprintf("%s/waInstance%s/deleteNotification/%s/%d",
-			g.baseURL, g.instanceID, g.token, notification.ReceiptID)
-		_, _ = g.client.Get(deleteEndpoint)
+		g.deleteNotification(notification.ReceiptID)
 	}
 }
 
+func (g *GreenAPIProvider) deleteNotification(receiptID int) {
+	deleteEndpoint := fmt.Sprintf("%s/waInstance%s/deleteNotification/%s/%d",
+		g.baseURL, g.instanceID, g.token, receiptID)
+	_, _ = g.client.Get(deleteEndpoint)
+}
+
 func (g *GreenAPIProvider) GenerateQR(sessionID string) ([]byte, error) {
 	// Green API doesn't support multiple sessions per instance
 	// sessionID is ignored - each instance is tied to one WhatsApp account
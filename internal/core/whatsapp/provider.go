@@ -4,7 +4,11 @@ package whatsapp
 import (
 	"context"
 	"fmt"
+	"mime"
+	"net/url"
 	"os"
+	"path"
+	"strings"
 )
 
 // WhatsAppProvider adalah interface untuk semua WhatsApp integration providers
@@ -15,8 +19,11 @@ type WhatsAppProvider interface {
 	// Disconnect memutuskan koneksi
 	Disconnect()
 
-	// SendMessage mengirim text message ke nomor tujuan
-	SendMessage(phoneNumber, message string) error
+	// SendMessage mengirim text message ke nomor tujuan, return provider message ID
+	SendMessage(phoneNumber, message string) (string, error)
+
+	// SendReply mengirim text message sebagai balasan (quote) dari message ID tertentu, return provider message ID
+	SendReply(phoneNumber, message, quotedMessageID string) (string, error)
 
 	// StartListening mulai listen incoming messages
 	StartListening(handler func(evt interface{})) error
@@ -45,6 +52,155 @@ type WhatsAppProvider interface {
 
 	// StopTyping stops/clears typing indicator
 	StopTyping(phoneNumber string) error
+
+	// SendLocation mengirim location pin ke nomor tujuan
+	SendLocation(phoneNumber string, location Location) error
+
+	// SendButtons mengirim text message dengan tombol balasan cepat, return provider message ID.
+	// Provider tanpa pesan interaktif mengirim ButtonMessage.Text()
+	SendButtons(phoneNumber string, message ButtonMessage) (string, error)
+
+	// SendList mengirim list menu, return provider message ID.
+	// Provider tanpa pesan interaktif mengirim ListMessage.Text()
+	SendList(phoneNumber string, message ListMessage) (string, error)
+
+	// SendImage mengirim gambar dari URL dengan caption opsional, return provider message ID
+	SendImage(phoneNumber string, image Media) (string, error)
+
+	// SendDocument mengirim dokumen (mis. katalog PDF) dari URL, return provider message ID
+	SendDocument(phoneNumber string, document Media) (string, error)
+}
+
+// Location adalah location pin (lat/long) dengan nama dan alamat opsional
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	Name      string  `json:"name,omitempty"`
+	Address   string  `json:"address,omitempty"`
+}
+
+// Media adalah gambar atau dokumen yang dikirim dari URL publik
+type Media struct {
+	URL      string `json:"url"`
+	Caption  string `json:"caption,omitempty"`
+	Filename string `json:"filename,omitempty"`  // Shown for documents; taken from the URL when empty
+	MimeType string `json:"mime_type,omitempty"` // Guessed from the URL's extension when empty
+}
+
+// mimeType returns the media's MIME type, guessed from the URL's extension, or fallback
+func (m Media) mimeType(fallback string) string {
+	if m.MimeType != "" {
+		return m.MimeType
+	}
+	if u, err := url.Parse(m.URL); err == nil {
+		if guessed := mime.TypeByExtension(path.Ext(u.Path)); guessed != "" {
+			return strings.TrimSpace(strings.Split(guessed, ";")[0])
+		}
+	}
+	return fallback
+}
+
+// filename returns the document's file name, taken from the URL when not set
+func (m Media) filename() string {
+	if m.Filename != "" {
+		return m.Filename
+	}
+	if u, err := url.Parse(m.URL); err == nil && path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+		return path.Base(u.Path)
+	}
+	return "document"
+}
+
+// MaxButtons is the most reply buttons WhatsApp shows on one message
+const MaxButtons = 3
+
+// Button adalah tombol balasan cepat. Cloud API mengirim balik ID saat tombol ditekan, provider lain Title
+type Button struct {
+	ID    string `json:"id"`
+	Title string `json:"title"` // Max 20 characters
+}
+
+// ButtonMessage adalah text message dengan tombol balasan cepat
+type ButtonMessage struct {
+	Body    string   `json:"body"`
+	Footer  string   `json:"footer,omitempty"`
+	Buttons []Button `json:"buttons"`
+}
+
+// ListRow adalah satu pilihan di list menu
+type ListRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`                 // Max 24 characters
+	Description string `json:"description,omitempty"` // Max 72 characters
+}
+
+// ListSection mengelompokkan pilihan di list menu
+type ListSection struct {
+	Title string    `json:"title,omitempty"`
+	Rows  []ListRow `json:"rows"`
+}
+
+// ListMessage adalah text message dengan list menu yang dibuka lewat satu tombol
+type ListMessage struct {
+	Body       string        `json:"body"`
+	Footer     string        `json:"footer,omitempty"`
+	ButtonText string        `json:"button_text"` // Label of the button opening the list, max 20 characters
+	Sections   []ListSection `json:"sections"`
+}
+
+// Validate checks the message against WhatsApp's limits
+func (m ButtonMessage) Validate() error {
+	if len(m.Buttons) == 0 || len(m.Buttons) > MaxButtons {
+		return fmt.Errorf("a button message needs 1 to %d buttons, got %d", MaxButtons, len(m.Buttons))
+	}
+	return nil
+}
+
+// Text returns the message as plain text for providers without interactive messages; customers reply
+// with the title of an option
+func (m ButtonMessage) Text() string {
+	var b strings.Builder
+	b.WriteString(m.Body)
+	b.WriteString("\n")
+	for _, button := range m.Buttons {
+		b.WriteString("\n• *" + button.Title + "*")
+	}
+	if m.Footer != "" {
+		b.WriteString("\n\n_" + m.Footer + "_")
+	}
+	return b.String()
+}
+
+// Validate checks the message has at least one option
+func (m ListMessage) Validate() error {
+	for _, section := range m.Sections {
+		if len(section.Rows) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("a list message needs at least one row")
+}
+
+// Text returns the list as plain text for providers without interactive messages
+func (m ListMessage) Text() string {
+	var b strings.Builder
+	b.WriteString(m.Body)
+	for _, section := range m.Sections {
+		b.WriteString("\n")
+		if section.Title != "" {
+			b.WriteString("\n*" + section.Title + "*")
+		}
+		for _, row := range section.Rows {
+			b.WriteString("\n• " + row.Title)
+			if row.Description != "" {
+				b.WriteString(" - " + row.Description)
+			}
+		}
+	}
+	if m.Footer != "" {
+		b.WriteString("\n\n_" + m.Footer + "_")
+	}
+	return b.String()
 }
 
 // ProviderType untuk factory
@@ -115,6 +271,21 @@ func NewProvider(cfg *ProviderConfig) (WhatsAppProvider, error) {
 	}
 }
 
+// SessionID returns the session messages of the configured provider are received on, as used for
+// tenant resolution: the WAHA session, Cloud API phone number ID or Green API instance
+func (cfg *ProviderConfig) SessionID() string {
+	switch cfg.Type {
+	case ProviderWAHA:
+		return cfg.WAHASessionID
+	case ProviderCloudAPI:
+		return cfg.CloudAPIPhoneID
+	case ProviderGreenAPI:
+		return cfg.GreenAPIInstanceID
+	default:
+		return "default"
+	}
+}
+
 // LoadProviderFromEnv load config dari environment variables
 func LoadProviderFromEnv() (*ProviderConfig, error) {
 	providerType := os.Getenv("WHATSAPP_PROVIDER")
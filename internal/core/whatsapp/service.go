@@ -5,12 +5,16 @@ import (
 	"context"
 	"fmt"
 	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"go.mau.fi/whatsmeow/types/events"
 )
 
 // Service adalah wrapper untuk WhatsApp provider
 // Ini adalah layer yang digunakan oleh aplikasi
 type Service struct {
 	provider WhatsAppProvider
+	dedup    DedupStore
 }
 
 // NewService membuat service dengan provider dari environment
@@ -34,6 +38,7 @@ func NewService(storeURL string) *Service {
 
 	return &Service{
 		provider: provider,
+		dedup:    NewMemoryDedupStore(DefaultDedupTTL),
 	}
 }
 
@@ -41,9 +46,25 @@ func NewService(storeURL string) *Service {
 func NewServiceWithProvider(provider WhatsAppProvider) *Service {
 	return &Service{
 		provider: provider,
+		dedup:    NewMemoryDedupStore(DefaultDedupTTL),
+	}
+}
+
+// SetDedupStore sets the store used to drop redelivered messages, for the service and for
+// providers that poll (WAHA, GreenAPI). Use a shared store when running several replicas
+func (s *Service) SetDedupStore(store DedupStore) {
+	s.dedup = store
+	if p, ok := s.provider.(interface{ SetDedupStore(DedupStore) }); ok {
+		p.SetDedupStore(store)
 	}
 }
 
+// IsDuplicate records an incoming message ID and reports whether it was already handled,
+// e.g. by a webhook retry or another replica
+func (s *Service) IsDuplicate(messageID string) bool {
+	return s.dedup.IsDuplicate(messageID)
+}
+
 // Connect memulai koneksi WhatsApp
 func (s *Service) Connect() error {
 	return s.provider.Connect()
@@ -56,7 +77,66 @@ func (s *Service) Disconnect() {
 
 // SendMessage mengirim text message
 func (s *Service) SendMessage(phoneNumber, message string) error {
-	return s.provider.SendMessage(phoneNumber, message)
+	_, err := s.provider.SendMessage(phoneNumber, message)
+	return s.countFailure("text", err)
+}
+
+// SendMessageWithID mengirim text message dan return provider message ID (empty if the provider returns none)
+func (s *Service) SendMessageWithID(phoneNumber, message string) (string, error) {
+	id, err := s.provider.SendMessage(phoneNumber, message)
+	return id, s.countFailure("text", err)
+}
+
+// SendReply mengirim text message yang mengutip (quote) message ID tertentu; without an ID it is a plain message
+func (s *Service) SendReply(phoneNumber, message, quotedMessageID string) (string, error) {
+	if quotedMessageID == "" {
+		return s.SendMessageWithID(phoneNumber, message)
+	}
+	id, err := s.provider.SendReply(phoneNumber, message, quotedMessageID)
+	return id, s.countFailure("reply", err)
+}
+
+// SendLocation mengirim location pin
+func (s *Service) SendLocation(phoneNumber string, location Location) error {
+	return s.countFailure("location", s.provider.SendLocation(phoneNumber, location))
+}
+
+// SendImage mengirim gambar dari URL, return provider message ID
+func (s *Service) SendImage(phoneNumber string, image Media) (string, error) {
+	id, err := s.provider.SendImage(phoneNumber, image)
+	return id, s.countFailure("image", err)
+}
+
+// SendDocument mengirim dokumen dari URL, return provider message ID
+func (s *Service) SendDocument(phoneNumber string, document Media) (string, error) {
+	id, err := s.provider.SendDocument(phoneNumber, document)
+	return id, s.countFailure("document", err)
+}
+
+// SendButtons mengirim pesan dengan tombol balasan cepat, return provider message ID
+func (s *Service) SendButtons(phoneNumber string, message ButtonMessage) (string, error) {
+	if err := message.Validate(); err != nil {
+		return "", err
+	}
+	id, err := s.provider.SendButtons(phoneNumber, message)
+	return id, s.countFailure("buttons", err)
+}
+
+// SendList mengirim list menu, return provider message ID
+func (s *Service) SendList(phoneNumber string, message ListMessage) (string, error) {
+	if err := message.Validate(); err != nil {
+		return "", err
+	}
+	id, err := s.provider.SendList(phoneNumber, message)
+	return id, s.countFailure("list", err)
+}
+
+// countFailure counts a message of kind that failed to send and returns err unchanged
+func (s *Service) countFailure(kind string, err error) error {
+	if err != nil {
+		metrics.WhatsAppSendFailed(s.provider.GetProviderName(), kind)
+	}
+	return err
 }
 
 // StartListening mulai listen incoming messages
@@ -74,6 +154,13 @@ func (s *Service) StartListening(handler func(evt interface{})) error {
 			// Convert WAHA message ke whatsmeow-like format
 			handler(msg)
 
+		case *events.Message:
+			// Whatsmeow can redeliver a message after reconnecting
+			if s.dedup.IsDuplicate(msg.Info.ID) {
+				return
+			}
+			handler(msg)
+
 		default:
 			// Whatsmeow native events atau unknown
 			handler(evt)
@@ -133,6 +220,47 @@ func (s *Service) ConfigureWebhook(sessionID, webhookURL string) error {
 	return fmt.Errorf("webhook configuration only supported for WAHA provider")
 }
 
+// ConfigureWebhookWithOptions configures webhook with custom events and HMAC signing (WAHA specific)
+func (s *Service) ConfigureWebhookWithOptions(sessionID, webhookURL string, opts WebhookOptions) error {
+	if waha, ok := s.provider.(*WAHAProvider); ok {
+		return waha.ConfigureWebhookWithOptions(sessionID, webhookURL, opts)
+	}
+	return fmt.Errorf("webhook configuration only supported for WAHA provider")
+}
+
+// DownloadMedia downloads the media of an incoming message by its media ID (Cloud API specific)
+func (s *Service) DownloadMedia(mediaID string) ([]byte, error) {
+	if cloud, ok := s.provider.(*CloudAPIProvider); ok {
+		return cloud.DownloadMedia(mediaID)
+	}
+	return nil, fmt.Errorf("media download by ID only supported for Cloud API provider")
+}
+
+// GetSessionState returns the raw session status (WAHA specific)
+func (s *Service) GetSessionState(sessionID string) (string, error) {
+	if waha, ok := s.provider.(*WAHAProvider); ok {
+		return waha.GetSessionState(sessionID)
+	}
+	return "", fmt.Errorf("session state only supported for WAHA provider")
+}
+
+// GetSessionPhone returns the phone number a session is logged in with (WAHA specific)
+func (s *Service) GetSessionPhone(sessionID string) (string, error) {
+	if waha, ok := s.provider.(*WAHAProvider); ok {
+		return waha.GetSessionPhone(sessionID)
+	}
+	return "", fmt.Errorf("session info only supported for WAHA provider")
+}
+
+// SendMessageFromSession sends a text message through a specific session (WAHA specific)
+func (s *Service) SendMessageFromSession(sessionID, phoneNumber, message string) error {
+	if waha, ok := s.provider.(*WAHAProvider); ok {
+		_, err := waha.SendMessageFromSession(sessionID, phoneNumber, message)
+		return s.countFailure("text", err)
+	}
+	return fmt.Errorf("per-session messaging only supported for WAHA provider")
+}
+
 // StopSession stops a session (WAHA specific)
 func (s *Service) StopSession(sessionID string) error {
 	if waha, ok := s.provider.(*WAHAProvider); ok {
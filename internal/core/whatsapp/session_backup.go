@@ -0,0 +1,259 @@
+// internal/core/whatsapp/session_backup.go
+package whatsapp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// restoreSettleTime is how long restored sessions get to reconnect before their state is reported
+const restoreSettleTime = 5 * time.Second
+
+// Outcome of restoring one session
+const (
+	RestoreOutcomeRestored       = "restored"        // Created or updated and started
+	RestoreOutcomeAlreadyWorking = "already_working" // Still connected, left alone
+	RestoreOutcomeSkipped        = "skipped"         // Was stopped when backed up
+	RestoreOutcomeFailed         = "failed"
+)
+
+// SessionSnapshot is what a replaced WAHA instance needs to bring a session back: its name and configuration
+// (webhooks, metadata, proxy). The WhatsApp pairing itself lives in WAHA's session storage; when that storage
+// survived (a mounted volume or WAHA's database session store) a restored session reconnects without a QR scan.
+type SessionSnapshot struct {
+	Name   string          `json:"name"`
+	Status string          `json:"status"`          // Status when backed up
+	Phone  string          `json:"phone,omitempty"` // Number the session was logged in with
+	Engine string          `json:"engine,omitempty"`
+	Config json.RawMessage `json:"config,omitempty"`
+}
+
+// SessionBackup is a snapshot of every session of a provider
+type SessionBackup struct {
+	Provider  string            `json:"provider"`
+	CreatedAt time.Time         `json:"created_at"`
+	Sessions  []SessionSnapshot `json:"sessions"`
+}
+
+// SessionRestoreResult is how one session of a backup was restored
+type SessionRestoreResult struct {
+	SessionID string `json:"session_id"`
+	Phone     string `json:"phone,omitempty"`
+	Outcome   string `json:"outcome"`          // restored, already_working, skipped, failed
+	Status    string `json:"status,omitempty"` // Status after the restore
+	NeedsQR   bool   `json:"needs_qr"`         // The pairing was lost, the tenant has to scan a new QR code
+	Error     string `json:"error,omitempty"`
+}
+
+// Export snapshots every session of the WAHA instance
+func (m *SessionManager) Export() (*SessionBackup, error) {
+	if m.waha == nil {
+		return nil, ErrSessionsUnsupported
+	}
+	sessions, err := m.waha.ListSessions()
+	if err != nil {
+		return nil, err
+	}
+	return &SessionBackup{
+		Provider:  m.waha.GetProviderName(),
+		CreatedAt: time.Now(),
+		Sessions:  sessions,
+	}, nil
+}
+
+// Restore recreates the sessions of a backup on the WAHA instance, e.g. after the container was replaced.
+// Sessions that are still working are left alone and sessions that were stopped are not started.
+func (m *SessionManager) Restore(backup *SessionBackup) ([]SessionRestoreResult, error) {
+	if m.waha == nil {
+		return nil, ErrSessionsUnsupported
+	}
+
+	current, err := m.waha.ListSessions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list current sessions: %w", err)
+	}
+	working := make(map[string]bool, len(current))
+	for _, session := range current {
+		working[session.Name] = session.Status == "WORKING"
+	}
+
+	results := make([]SessionRestoreResult, 0, len(backup.Sessions))
+	restored := 0
+	for _, snapshot := range backup.Sessions {
+		result := SessionRestoreResult{SessionID: snapshot.Name, Phone: snapshot.Phone}
+		switch {
+		case working[snapshot.Name]:
+			result.Outcome = RestoreOutcomeAlreadyWorking
+			result.Status = "WORKING"
+		case snapshot.Status == "STOPPED":
+			result.Outcome = RestoreOutcomeSkipped
+		default:
+			if err := m.waha.RestoreSession(snapshot); err != nil {
+				log.Printf("❌ Failed to restore WAHA session %s: %v", snapshot.Name, err)
+				result.Outcome = RestoreOutcomeFailed
+				result.Error = err.Error()
+			} else {
+				result.Outcome = RestoreOutcomeRestored
+				restored++
+			}
+		}
+		results = append(results, result)
+	}
+
+	if restored == 0 {
+		return results, nil
+	}
+
+	// Give the restored sessions time to load their pairing before telling which ones need a new QR scan
+	time.Sleep(restoreSettleTime)
+	for i := range results {
+		result := &results[i]
+		if result.Outcome != RestoreOutcomeRestored {
+			continue
+		}
+		status, err := m.waha.GetSessionState(result.SessionID)
+		if err != nil {
+			log.Printf("⚠️ Failed to get state of restored session %s: %v", result.SessionID, err)
+			continue
+		}
+		result.Status = status
+		result.NeedsQR = status == "SCAN_QR_CODE"
+	}
+	return results, nil
+}
+
+// ListSessions returns every session of the WAHA instance, including stopped ones
+func (w *WAHAProvider) ListSessions() ([]SessionSnapshot, error) {
+	endpoint := fmt.Sprintf("%s/api/sessions?all=true", w.baseURL)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result []struct {
+		Name   string          `json:"name"`
+		Status string          `json:"status"`
+		Config json.RawMessage `json:"config"`
+		Me     *struct {
+			ID string `json:"id"` // Format: 628xxx@c.us
+		} `json:"me"`
+		Engine *struct {
+			Engine string `json:"engine"`
+		} `json:"engine"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode sessions: %w", err)
+	}
+
+	sessions := make([]SessionSnapshot, 0, len(result))
+	for _, s := range result {
+		snapshot := SessionSnapshot{Name: s.Name, Status: s.Status}
+		if len(s.Config) > 0 && string(s.Config) != "null" {
+			snapshot.Config = s.Config
+		}
+		if s.Me != nil {
+			snapshot.Phone = s.Me.ID
+			for i, ch := range snapshot.Phone {
+				if ch == '@' || ch == ':' {
+					snapshot.Phone = snapshot.Phone[:i]
+					break
+				}
+			}
+		}
+		if s.Engine != nil {
+			snapshot.Engine = s.Engine.Engine
+		}
+		sessions = append(sessions, snapshot)
+	}
+	return sessions, nil
+}
+
+// RestoreSession creates and starts a session with the configuration of a snapshot.
+// A session that already exists gets the snapshot's configuration and is started.
+func (w *WAHAProvider) RestoreSession(snapshot SessionSnapshot) error {
+	if snapshot.Name == "" {
+		return fmt.Errorf("session name is required")
+	}
+
+	log.Printf("♻️ Restoring WAHA session: %s", snapshot.Name)
+
+	payload := map[string]interface{}{"name": snapshot.Name}
+	if len(snapshot.Config) > 0 {
+		payload["config"] = snapshot.Config
+	}
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	status, body, err := w.sessionRequest("POST", fmt.Sprintf("%s/api/sessions/start", w.baseURL), jsonData)
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	if status == http.StatusOK || status == http.StatusCreated {
+		log.Printf("✅ Session restored: %s", snapshot.Name)
+		return nil
+	}
+	if status != http.StatusConflict && status != http.StatusUnprocessableEntity {
+		return fmt.Errorf("WAHA returned status %d: %s", status, string(body))
+	}
+
+	// The session survived the replacement: bring its configuration back, then make sure it runs
+	if len(snapshot.Config) > 0 {
+		jsonData, err = json.Marshal(map[string]interface{}{"config": snapshot.Config})
+		if err != nil {
+			return fmt.Errorf("failed to marshal payload: %w", err)
+		}
+		status, body, err = w.sessionRequest("PUT", fmt.Sprintf("%s/api/sessions/%s", w.baseURL, snapshot.Name), jsonData)
+		if err != nil {
+			return fmt.Errorf("failed to update session: %w", err)
+		}
+		if status != http.StatusOK && status != http.StatusCreated {
+			return fmt.Errorf("WAHA returned status %d: %s", status, string(body))
+		}
+	}
+	return w.StartSession(snapshot.Name)
+}
+
+// sessionRequest sends a JSON request to the WAHA sessions API and returns the status code and body
+func (w *WAHAProvider) sessionRequest(method, endpoint string, jsonData []byte) (int, []byte, error) {
+	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, body, nil
+}
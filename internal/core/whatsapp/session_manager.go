@@ -0,0 +1,266 @@
+// internal/core/whatsapp/session_manager.go
+package whatsapp
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// sessionCacheTTL is how long a client's session name is reused before it is looked up again
+const sessionCacheTTL = 5 * time.Minute
+
+// ErrSessionsUnsupported is returned for per-client session lifecycle calls on single-session providers
+var ErrSessionsUnsupported = errors.New("per-client sessions are only supported for the WAHA provider")
+
+// SessionLookup returns the session a client sends from ("" = the default session)
+type SessionLookup func(clientID string) (string, error)
+
+// SessionState is the state of a client's session
+type SessionState struct {
+	ClientID  string `json:"client_id"`
+	SessionID string `json:"session_id"`
+	Status    string `json:"status"` // STARTING, SCAN_QR_CODE, WORKING, FAILED, STOPPED
+	Connected bool   `json:"connected"`
+	Phone     string `json:"phone,omitempty"` // Number the session is logged in with
+	Provider  string `json:"provider"`
+}
+
+type cachedSession struct {
+	sessionID string
+	expiresAt time.Time
+}
+
+// SessionManager keeps one WAHA session per client (session name = client ID) and sends each
+// client's messages through its own session. Clients without a session, and every client on a
+// single-session provider (whatsmeow, GreenAPI, Cloud API), send through the default session.
+type SessionManager struct {
+	service *Service
+	waha    *WAHAProvider // nil for single-session providers
+	lookup  SessionLookup
+
+	mu       sync.RWMutex
+	sessions map[string]cachedSession // by client ID
+}
+
+// NewSessionManager creates a session manager on top of the service's provider
+func NewSessionManager(service *Service, lookup SessionLookup) *SessionManager {
+	waha, _ := service.provider.(*WAHAProvider)
+	return &SessionManager{
+		service:  service,
+		waha:     waha,
+		lookup:   lookup,
+		sessions: make(map[string]cachedSession),
+	}
+}
+
+// SessionName is the WAHA session name of a client
+func SessionName(clientID string) string {
+	return clientID
+}
+
+// MultiSession reports whether clients can have their own session
+func (m *SessionManager) MultiSession() bool {
+	return m.waha != nil
+}
+
+// SessionFor returns the session a client sends from, "" for the default session
+func (m *SessionManager) SessionFor(clientID string) string {
+	if m.waha == nil || clientID == "" {
+		return ""
+	}
+
+	m.mu.RLock()
+	cached, ok := m.sessions[clientID]
+	m.mu.RUnlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.sessionID
+	}
+
+	sessionID := ""
+	if m.lookup != nil {
+		var err error
+		sessionID, err = m.lookup(clientID)
+		if err != nil {
+			log.Printf("⚠️ Failed to look up WhatsApp session of client %s: %v", clientID, err)
+			return cached.sessionID // Last known session, "" if never looked up
+		}
+	}
+	m.remember(clientID, sessionID)
+	return sessionID
+}
+
+// SendMessage sends a text message through the client's session and returns the provider message ID
+func (m *SessionManager) SendMessage(clientID, phoneNumber, message string) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		id, err := m.waha.SendMessageFromSession(sessionID, phoneNumber, message)
+		return id, m.service.countFailure("text", err)
+	}
+	return m.service.SendMessageWithID(phoneNumber, message)
+}
+
+// SendReply sends a text message quoting quotedMessageID (when set) through the client's session
+func (m *SessionManager) SendReply(clientID, phoneNumber, message, quotedMessageID string) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		id, err := m.waha.SendReplyFromSession(sessionID, phoneNumber, message, quotedMessageID)
+		return id, m.service.countFailure("reply", err)
+	}
+	return m.service.SendReply(phoneNumber, message, quotedMessageID)
+}
+
+// SendLocation sends a location pin through the client's session
+func (m *SessionManager) SendLocation(clientID, phoneNumber string, location Location) error {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		return m.service.countFailure("location", m.waha.SendLocationFromSession(sessionID, phoneNumber, location))
+	}
+	return m.service.SendLocation(phoneNumber, location)
+}
+
+// SendImage sends an image through the client's session
+func (m *SessionManager) SendImage(clientID, phoneNumber string, image Media) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		id, err := m.waha.SendImageFromSession(sessionID, phoneNumber, image)
+		return id, m.service.countFailure("image", err)
+	}
+	return m.service.SendImage(phoneNumber, image)
+}
+
+// SendDocument sends a document through the client's session
+func (m *SessionManager) SendDocument(clientID, phoneNumber string, document Media) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		id, err := m.waha.SendDocumentFromSession(sessionID, phoneNumber, document)
+		return id, m.service.countFailure("document", err)
+	}
+	return m.service.SendDocument(phoneNumber, document)
+}
+
+// SendButtons sends a message with reply buttons through the client's session
+func (m *SessionManager) SendButtons(clientID, phoneNumber string, message ButtonMessage) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		if err := message.Validate(); err != nil {
+			return "", err
+		}
+		id, err := m.waha.SendButtonsFromSession(sessionID, phoneNumber, message)
+		return id, m.service.countFailure("buttons", err)
+	}
+	return m.service.SendButtons(phoneNumber, message)
+}
+
+// SendList sends a list menu through the client's session
+func (m *SessionManager) SendList(clientID, phoneNumber string, message ListMessage) (string, error) {
+	if sessionID := m.SessionFor(clientID); sessionID != "" {
+		if err := message.Validate(); err != nil {
+			return "", err
+		}
+		id, err := m.waha.SendListFromSession(sessionID, phoneNumber, message)
+		return id, m.service.countFailure("list", err)
+	}
+	return m.service.SendList(phoneNumber, message)
+}
+
+// Start creates or starts the client's session and returns its name
+func (m *SessionManager) Start(clientID string) (string, error) {
+	if m.waha == nil {
+		return "", ErrSessionsUnsupported
+	}
+	sessionID := SessionName(clientID)
+	if err := m.waha.StartSession(sessionID); err != nil {
+		return "", err
+	}
+	m.remember(clientID, sessionID)
+	return sessionID, nil
+}
+
+// Stop stops the client's session; its messages fail until the session is started again
+func (m *SessionManager) Stop(clientID string) error {
+	sessionID, err := m.ownSession(clientID)
+	if err != nil {
+		return err
+	}
+	return m.waha.StopSession(sessionID)
+}
+
+// Restart stops and starts the client's session
+func (m *SessionManager) Restart(clientID string) error {
+	sessionID, err := m.ownSession(clientID)
+	if err != nil {
+		return err
+	}
+	return m.waha.RestartSession(sessionID)
+}
+
+// Delete logs the client's session out and removes it
+func (m *SessionManager) Delete(clientID string) error {
+	sessionID, err := m.ownSession(clientID)
+	if err != nil {
+		return err
+	}
+	if err := m.waha.DeleteSession(sessionID); err != nil {
+		return err
+	}
+	m.Forget(clientID)
+	return nil
+}
+
+// QR returns the pairing QR code (PNG) of the client's session, starting it when needed
+func (m *SessionManager) QR(clientID string) ([]byte, error) {
+	sessionID, err := m.ownSession(clientID)
+	if err != nil {
+		return nil, err
+	}
+	return m.waha.GenerateQR(sessionID)
+}
+
+// State returns the state of the client's session
+func (m *SessionManager) State(clientID string) (*SessionState, error) {
+	sessionID, err := m.ownSession(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := m.waha.GetSessionState(sessionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session state: %w", err)
+	}
+
+	state := &SessionState{
+		ClientID:  clientID,
+		SessionID: sessionID,
+		Status:    status,
+		Connected: status == "WORKING",
+		Provider:  m.waha.GetProviderName(),
+	}
+	if state.Connected {
+		if phone, err := m.waha.GetSessionPhone(sessionID); err == nil {
+			state.Phone = phone
+		}
+	}
+	return state, nil
+}
+
+// Forget drops the cached session of a client, e.g. after its session mapping changed
+func (m *SessionManager) Forget(clientID string) {
+	m.mu.Lock()
+	delete(m.sessions, clientID)
+	m.mu.Unlock()
+}
+
+// ownSession is the client's session for lifecycle calls: the mapped one, or the name it gets when started.
+// The shared default session is never managed through a client.
+func (m *SessionManager) ownSession(clientID string) (string, error) {
+	if m.waha == nil {
+		return "", ErrSessionsUnsupported
+	}
+	if sessionID := m.SessionFor(clientID); sessionID != "" && sessionID != m.waha.sessionID {
+		return sessionID, nil
+	}
+	return SessionName(clientID), nil
+}
+
+func (m *SessionManager) remember(clientID, sessionID string) {
+	m.mu.Lock()
+	m.sessions[clientID] = cachedSession{sessionID: sessionID, expiresAt: time.Now().Add(sessionCacheTTL)}
+	m.mu.Unlock()
+}
@@ -13,13 +13,13 @@ import (
 )
 
 type WAHAProvider struct {
-	baseURL      string
-	apiKey       string
-	sessionID    string
-	client       *http.Client
-	connected    bool
-	stopPolling  chan bool
-	processedIDs map[string]bool
+	baseURL     string
+	apiKey      string
+	sessionID   string
+	client      *http.Client
+	connected   bool
+	stopPolling chan bool
+	dedup       DedupStore
 }
 
 func NewWAHAProvider(baseURL, apiKey, sessionID string) *WAHAProvider {
@@ -30,11 +30,16 @@ func NewWAHAProvider(baseURL, apiKey, sessionID string) *WAHAProvider {
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		stopPolling:  make(chan bool),
-		processedIDs: make(map[string]bool),
+		stopPolling: make(chan bool),
+		dedup:       NewMemoryDedupStore(DefaultDedupTTL),
 	}
 }
 
+// SetDedupStore replaces the in-memory dedup of polled messages, e.g. with a store shared by all replicas
+func (w *WAHAProvider) SetDedupStore(store DedupStore) {
+	w.dedup = store
+}
+
 func (w *WAHAProvider) GetProviderName() string {
 	return "WAHA"
 }
@@ -134,22 +139,132 @@ func (w *WAHAProvider) Disconnect() {
 	log.Println("🔌 WAHA provider disconnected")
 }
 
-func (w *WAHAProvider) SendMessage(phoneNumber, message string) error {
+func (w *WAHAProvider) SendMessage(phoneNumber, message string) (string, error) {
+	return w.sendText(w.sessionID, phoneNumber, message, "")
+}
+
+// SendReply sends a text message quoting an earlier message (WAHA reply_to)
+func (w *WAHAProvider) SendReply(phoneNumber, message, quotedMessageID string) (string, error) {
+	return w.sendText(w.sessionID, phoneNumber, message, quotedMessageID)
+}
+
+// sendText sends a text message through a session (optionally quoting a message) and returns its ID
+func (w *WAHAProvider) sendText(sessionID, phoneNumber, message, replyTo string) (string, error) {
 	// Format: 628123456789@c.us
 	chatID := phoneNumber
 	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
-		chatID = phoneNumber[1:] + "@c.us"
-	} else {
-		chatID = phoneNumber + "@c.us"
+		chatID = phoneNumber[1:]
 	}
+	chatID += "@c.us"
 
 	endpoint := fmt.Sprintf("%s/api/sendText", w.baseURL)
 
 	payload := map[string]interface{}{
-		"session": w.sessionID,
+		"session": sessionID,
 		"chatId":  chatID,
 		"text":    message,
 	}
+	if replyTo != "" {
+		payload["reply_to"] = replyTo
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return parseWAHAMessageID(body), nil
+}
+
+// parseWAHAMessageID extracts the sent message ID; depending on the engine WAHA returns it
+// as a string, as an object with _serialized, or under key.id
+func parseWAHAMessageID(body []byte) string {
+	var result struct {
+		ID  json.RawMessage `json:"id"`
+		Key struct {
+			ID string `json:"id"`
+		} `json:"key"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return ""
+	}
+
+	var id string
+	if err := json.Unmarshal(result.ID, &id); err == nil && id != "" {
+		return id
+	}
+
+	var serialized struct {
+		Serialized string `json:"_serialized"`
+		ID         string `json:"id"`
+	}
+	if err := json.Unmarshal(result.ID, &serialized); err == nil {
+		if serialized.Serialized != "" {
+			return serialized.Serialized
+		}
+		if serialized.ID != "" {
+			return serialized.ID
+		}
+	}
+
+	return result.Key.ID
+}
+
+// SendLocation sends a location pin (WAHA /api/sendLocation)
+func (w *WAHAProvider) SendLocation(phoneNumber string, location Location) error {
+	return w.SendLocationFromSession(w.sessionID, phoneNumber, location)
+}
+
+// SendLocationFromSession sends a location pin through a specific session
+func (w *WAHAProvider) SendLocationFromSession(sessionID, phoneNumber string, location Location) error {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+
+	chatID := phoneNumber
+	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
+		chatID = phoneNumber[1:]
+	}
+	chatID += "@c.us"
+
+	endpoint := fmt.Sprintf("%s/api/sendLocation", w.baseURL)
+
+	title := location.Name
+	if location.Address != "" {
+		if title != "" {
+			title += "\n"
+		}
+		title += location.Address
+	}
+
+	payload := map[string]interface{}{
+		"session":   sessionID,
+		"chatId":    chatID,
+		"latitude":  location.Latitude,
+		"longitude": location.Longitude,
+		"title":     title,
+	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
@@ -168,7 +283,7 @@ func (w *WAHAProvider) SendMessage(phoneNumber, message string) error {
 
 	resp, err := w.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to send message: %w", err)
+		return fmt.Errorf("failed to send location: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -180,6 +295,139 @@ func (w *WAHAProvider) SendMessage(phoneNumber, message string) error {
 	return nil
 }
 
+// SendImage sends an image by URL (WAHA /api/sendImage)
+func (w *WAHAProvider) SendImage(phoneNumber string, image Media) (string, error) {
+	return w.SendImageFromSession(w.sessionID, phoneNumber, image)
+}
+
+// SendImageFromSession sends an image by URL through a specific session
+func (w *WAHAProvider) SendImageFromSession(sessionID, phoneNumber string, image Media) (string, error) {
+	return w.sendJSON(sessionID, phoneNumber, "/api/sendImage", map[string]interface{}{
+		"file": map[string]interface{}{
+			"url":      image.URL,
+			"mimetype": image.mimeType("image/jpeg"),
+		},
+		"caption": image.Caption,
+	})
+}
+
+// SendDocument sends a document by URL (WAHA /api/sendFile)
+func (w *WAHAProvider) SendDocument(phoneNumber string, document Media) (string, error) {
+	return w.SendDocumentFromSession(w.sessionID, phoneNumber, document)
+}
+
+// SendDocumentFromSession sends a document by URL through a specific session
+func (w *WAHAProvider) SendDocumentFromSession(sessionID, phoneNumber string, document Media) (string, error) {
+	return w.sendJSON(sessionID, phoneNumber, "/api/sendFile", map[string]interface{}{
+		"file": map[string]interface{}{
+			"url":      document.URL,
+			"mimetype": document.mimeType("application/pdf"),
+			"filename": document.filename(),
+		},
+		"caption": document.Caption,
+	})
+}
+
+// SendButtons sends a message with reply buttons (WAHA /api/sendButtons)
+func (w *WAHAProvider) SendButtons(phoneNumber string, message ButtonMessage) (string, error) {
+	return w.SendButtonsFromSession(w.sessionID, phoneNumber, message)
+}
+
+// SendButtonsFromSession sends a message with reply buttons through a specific session
+func (w *WAHAProvider) SendButtonsFromSession(sessionID, phoneNumber string, message ButtonMessage) (string, error) {
+	buttons := make([]map[string]interface{}, 0, len(message.Buttons))
+	for _, button := range message.Buttons {
+		buttons = append(buttons, map[string]interface{}{
+			"type": "reply",
+			"id":   button.ID,
+			"text": button.Title,
+		})
+	}
+
+	return w.sendJSON(sessionID, phoneNumber, "/api/sendButtons", map[string]interface{}{
+		"body":    message.Body,
+		"footer":  message.Footer,
+		"buttons": buttons,
+	})
+}
+
+// SendList sends a list menu (WAHA /api/sendList)
+func (w *WAHAProvider) SendList(phoneNumber string, message ListMessage) (string, error) {
+	return w.SendListFromSession(w.sessionID, phoneNumber, message)
+}
+
+// SendListFromSession sends a list menu through a specific session
+func (w *WAHAProvider) SendListFromSession(sessionID, phoneNumber string, message ListMessage) (string, error) {
+	sections := make([]map[string]interface{}, 0, len(message.Sections))
+	for _, section := range message.Sections {
+		rows := make([]map[string]interface{}, 0, len(section.Rows))
+		for _, row := range section.Rows {
+			rows = append(rows, map[string]interface{}{
+				"rowId":       row.ID,
+				"title":       row.Title,
+				"description": row.Description,
+			})
+		}
+		sections = append(sections, map[string]interface{}{
+			"title": section.Title,
+			"rows":  rows,
+		})
+	}
+
+	return w.sendJSON(sessionID, phoneNumber, "/api/sendList", map[string]interface{}{
+		"message": map[string]interface{}{
+			"description": message.Body,
+			"footer":      message.Footer,
+			"button":      message.ButtonText,
+			"sections":    sections,
+		},
+	})
+}
+
+// sendJSON posts a message to a WAHA send endpoint and returns its ID
+func (w *WAHAProvider) sendJSON(sessionID, phoneNumber, path string, payload map[string]interface{}) (string, error) {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+
+	chatID := phoneNumber
+	if len(phoneNumber) > 0 && phoneNumber[0] == '+' {
+		chatID = phoneNumber[1:]
+	}
+	chatID += "@c.us"
+
+	payload["session"] = sessionID
+	payload["chatId"] = chatID
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", w.baseURL+path, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	return parseWAHAMessageID(body), nil
+}
+
 func (w *WAHAProvider) StartListening(handler func(evt interface{})) error {
 	log.Println("👂 Starting WAHA message polling...")
 	log.Println("💡 For production, configure WAHA webhook to your /webhook endpoint")
@@ -246,41 +494,26 @@ func (w *WAHAProvider) pollMessages(handler func(evt interface{})) {
 	}
 
 	for _, msg := range messages {
-		// Skip jika sudah diproses
-		if w.processedIDs[msg.ID] {
-			continue
-		}
-
 		// Skip jika dari diri sendiri
 		if msg.FromMe {
 			continue
 		}
 
 		// Process hanya chat messages
-		if msg.Type == "chat" && msg.Body != "" {
-			evt := &WAHAMessage{
-				From:    msg.From,
-				Message: msg.Body,
-			}
-			handler(evt)
-
-			// Mark as processed
-			w.processedIDs[msg.ID] = true
-
-			// Cleanup old IDs (keep last 100)
-			if len(w.processedIDs) > 100 {
-				// Simple cleanup: create new map
-				newMap := make(map[string]bool)
-				count := 0
-				for id := range w.processedIDs {
-					if count >= 50 {
-						newMap[id] = true
-					}
-					count++
-				}
-				w.processedIDs = newMap
-			}
+		if msg.Type != "chat" || msg.Body == "" {
+			continue
+		}
+
+		// Skip jika sudah diproses (oleh poll sebelumnya, webhook, atau replica lain)
+		if w.dedup.IsDuplicate(msg.ID) {
+			continue
 		}
+
+		evt := &WAHAMessage{
+			From:    msg.From,
+			Message: msg.Body,
+		}
+		handler(evt)
 	}
 }
 
@@ -573,7 +806,7 @@ func (w *WAHAProvider) ConfigureWebhook(sessionID, webhookURL string) error {
 			"webhooks": []map[string]interface{}{
 				{
 					"url":           webhookURL,
-					"events":        []string{"message"},
+					"events":        []string{"message", "message.reaction", "message.ack"},
 					"hmac":          nil,
 					"retries":       nil,
 					"customHeaders": nil,
@@ -638,6 +871,187 @@ func (w *WAHAProvider) RestartSession(sessionID string) error {
 	return nil
 }
 
+// WebhookOptions holds optional webhook settings for a WAHA session
+type WebhookOptions struct {
+	Events  []string // Defaults to ["message"]
+	HMACKey string   // When set, WAHA signs each request (X-Webhook-Hmac, sha512)
+}
+
+// ConfigureWebhookWithOptions configures a session webhook with custom events and HMAC signing
+func (w *WAHAProvider) ConfigureWebhookWithOptions(sessionID, webhookURL string, opts WebhookOptions) error {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+
+	events := opts.Events
+	if len(events) == 0 {
+		events = []string{"message"}
+	}
+
+	var hmac interface{}
+	if opts.HMACKey != "" {
+		hmac = map[string]string{"key": opts.HMACKey}
+	}
+
+	log.Printf("🔧 Configuring webhook for session: %s -> %s (events: %v, hmac: %v)", sessionID, webhookURL, events, opts.HMACKey != "")
+
+	endpoint := fmt.Sprintf("%s/api/sessions/%s", w.baseURL, sessionID)
+
+	payload := map[string]interface{}{
+		"config": map[string]interface{}{
+			"webhooks": []map[string]interface{}{
+				{
+					"url":           webhookURL,
+					"events":        events,
+					"hmac":          hmac,
+					"retries":       nil,
+					"customHeaders": nil,
+				},
+			},
+		},
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	req, err := http.NewRequest("PUT", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to configure webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	log.Printf("✅ Webhook configured successfully for session: %s", sessionID)
+	return nil
+}
+
+// GetSessionState returns the raw WAHA session status (STARTING, SCAN_QR_CODE, WORKING, FAILED, STOPPED)
+func (w *WAHAProvider) GetSessionState(sessionID string) (string, error) {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+	return w.getSessionStatusByID(sessionID)
+}
+
+// GetSessionPhone returns the phone number the session is logged in with
+func (w *WAHAProvider) GetSessionPhone(sessionID string) (string, error) {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+
+	endpoint := fmt.Sprintf("%s/api/sessions/%s/me", w.baseURL, sessionID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to get session info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		ID string `json:"id"` // Format: 628xxx@c.us
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode session info: %w", err)
+	}
+
+	if result.ID == "" {
+		return "", fmt.Errorf("session is not logged in")
+	}
+
+	phone := result.ID
+	for i, ch := range phone {
+		if ch == '@' || ch == ':' {
+			phone = phone[:i]
+			break
+		}
+	}
+
+	return phone, nil
+}
+
+// SendMessageFromSession sends a text message through a specific session and returns its ID
+func (w *WAHAProvider) SendMessageFromSession(sessionID, phoneNumber, message string) (string, error) {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+	return w.sendText(sessionID, phoneNumber, message, "")
+}
+
+// SendReplyFromSession sends a text message quoting an earlier message through a specific session and returns its ID
+func (w *WAHAProvider) SendReplyFromSession(sessionID, phoneNumber, message, quotedMessageID string) (string, error) {
+	if sessionID == "" {
+		sessionID = w.sessionID
+	}
+	return w.sendText(sessionID, phoneNumber, message, quotedMessageID)
+}
+
+// DeleteSession logs a session out and removes it from WAHA
+func (w *WAHAProvider) DeleteSession(sessionID string) error {
+	if sessionID == "" {
+		return fmt.Errorf("session ID is required")
+	}
+
+	log.Printf("🗑️ Deleting WAHA session: %s", sessionID)
+
+	endpoint := fmt.Sprintf("%s/api/sessions/%s", w.baseURL, sessionID)
+
+	req, err := http.NewRequest("DELETE", endpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if w.apiKey != "" {
+		req.Header.Set("X-Api-Key", w.apiKey)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+
+	// 404 = already gone
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("WAHA returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	log.Printf("✅ Session deleted: %s", sessionID)
+	return nil
+}
+
 // WAHAMessage adapter untuk compatibility
 type WAHAMessage struct {
 	From    string
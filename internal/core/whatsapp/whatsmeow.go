@@ -7,7 +7,9 @@ import (
 	"database/sql"
 	"fmt"
 	"image/png"
+	"io"
 	"log"
+	"net/http"
 	"time"
 
 	qrcode "github.com/skip2/go-qrcode"
@@ -123,9 +125,9 @@ func (w *WhatsmeowProvider) Disconnect() {
 	}
 }
 
-func (w *WhatsmeowProvider) SendMessage(phoneNumber, message string) error {
+func (w *WhatsmeowProvider) SendMessage(phoneNumber, message string) (string, error) {
 	if w.client == nil {
-		return fmt.Errorf("client not initialized")
+		return "", fmt.Errorf("client not initialized")
 	}
 
 	jid := types.NewJID(phoneNumber, "s.whatsapp.net")
@@ -133,10 +135,170 @@ func (w *WhatsmeowProvider) SendMessage(phoneNumber, message string) error {
 		Conversation: proto.String(message),
 	}
 
+	resp, err := w.client.SendMessage(context.Background(), jid, msg)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// SendReply sends a text message quoting an earlier message in the chat
+func (w *WhatsmeowProvider) SendReply(phoneNumber, message, quotedMessageID string) (string, error) {
+	if w.client == nil {
+		return "", fmt.Errorf("client not initialized")
+	}
+
+	jid := types.NewJID(phoneNumber, "s.whatsapp.net")
+	msg := &waProto.Message{
+		ExtendedTextMessage: &waProto.ExtendedTextMessage{
+			Text: proto.String(message),
+			ContextInfo: &waProto.ContextInfo{
+				StanzaID:      proto.String(quotedMessageID),
+				Participant:   proto.String(jid.String()),
+				QuotedMessage: &waProto.Message{Conversation: proto.String("")},
+			},
+		},
+	}
+
+	resp, err := w.client.SendMessage(context.Background(), jid, msg)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+func (w *WhatsmeowProvider) SendLocation(phoneNumber string, location Location) error {
+	if w.client == nil {
+		return fmt.Errorf("client not initialized")
+	}
+
+	jid := types.NewJID(phoneNumber, "s.whatsapp.net")
+	msg := &waProto.Message{
+		LocationMessage: &waProto.LocationMessage{
+			DegreesLatitude:  proto.Float64(location.Latitude),
+			DegreesLongitude: proto.Float64(location.Longitude),
+			Name:             proto.String(location.Name),
+			Address:          proto.String(location.Address),
+		},
+	}
+
 	_, err := w.client.SendMessage(context.Background(), jid, msg)
 	return err
 }
 
+// SendImage downloads an image and uploads it to WhatsApp's media servers before sending
+func (w *WhatsmeowProvider) SendImage(phoneNumber string, image Media) (string, error) {
+	if w.client == nil {
+		return "", fmt.Errorf("client not initialized")
+	}
+
+	data, mimeType, err := downloadMedia(image)
+	if err != nil {
+		return "", err
+	}
+	uploaded, err := w.client.Upload(context.Background(), data, whatsmeow.MediaImage)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload image: %w", err)
+	}
+
+	jid := types.NewJID(phoneNumber, "s.whatsapp.net")
+	msg := &waProto.Message{
+		ImageMessage: &waProto.ImageMessage{
+			Caption:       proto.String(image.Caption),
+			Mimetype:      proto.String(mimeType),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+
+	resp, err := w.client.SendMessage(context.Background(), jid, msg)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// SendDocument downloads a document and uploads it to WhatsApp's media servers before sending
+func (w *WhatsmeowProvider) SendDocument(phoneNumber string, document Media) (string, error) {
+	if w.client == nil {
+		return "", fmt.Errorf("client not initialized")
+	}
+
+	data, mimeType, err := downloadMedia(document)
+	if err != nil {
+		return "", err
+	}
+	uploaded, err := w.client.Upload(context.Background(), data, whatsmeow.MediaDocument)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload document: %w", err)
+	}
+
+	jid := types.NewJID(phoneNumber, "s.whatsapp.net")
+	msg := &waProto.Message{
+		DocumentMessage: &waProto.DocumentMessage{
+			Caption:       proto.String(document.Caption),
+			FileName:      proto.String(document.filename()),
+			Mimetype:      proto.String(mimeType),
+			URL:           proto.String(uploaded.URL),
+			DirectPath:    proto.String(uploaded.DirectPath),
+			MediaKey:      uploaded.MediaKey,
+			FileEncSHA256: uploaded.FileEncSHA256,
+			FileSHA256:    uploaded.FileSHA256,
+			FileLength:    proto.Uint64(uploaded.FileLength),
+		},
+	}
+
+	resp, err := w.client.SendMessage(context.Background(), jid, msg)
+	if err != nil {
+		return "", err
+	}
+	return resp.ID, nil
+}
+
+// maxMediaSize is the largest file downloaded for sending through whatsmeow
+const maxMediaSize = 16 * 1024 * 1024
+
+// downloadMedia fetches a media URL and returns its bytes and MIME type
+func downloadMedia(media Media) ([]byte, string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(media.URL)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to download media: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download media: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMediaSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read media: %w", err)
+	}
+	if len(data) > maxMediaSize {
+		return nil, "", fmt.Errorf("media is larger than %d bytes", maxMediaSize)
+	}
+
+	mimeType := media.mimeType("")
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	return data, mimeType, nil
+}
+
+// SendButtons sends the buttons as plain text; WhatsApp drops button messages from unofficial clients
+func (w *WhatsmeowProvider) SendButtons(phoneNumber string, message ButtonMessage) (string, error) {
+	return w.SendMessage(phoneNumber, message.Text())
+}
+
+// SendList sends the list menu as plain text
+func (w *WhatsmeowProvider) SendList(phoneNumber string, message ListMessage) (string, error) {
+	return w.SendMessage(phoneNumber, message.Text())
+}
+
 func (w *WhatsmeowProvider) StartListening(handler func(evt interface{})) error {
 	if w.client == nil {
 		return fmt.Errorf("client not initialized")
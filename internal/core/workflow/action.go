@@ -8,20 +8,22 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"regexp"
-	"strings"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
 	"gorm.io/gorm"
 )
 
+// maxAPIOutputBody caps the response body kept as a call_api action's output
+const maxAPIOutputBody = 4096
+
 // ActionExecutor executes workflow actions
 type ActionExecutor struct {
 	db         *gorm.DB
 	waService  *whatsapp.Service
 	llmService *llm.Service
 	httpClient *http.Client
+	commerce   Commerce
 }
 
 // NewActionExecutor creates a new action executor
@@ -34,8 +36,9 @@ func NewActionExecutor(db *gorm.DB, waService *whatsapp.Service, llmService *llm
 	}
 }
 
-// Execute executes a single action with the given context data
-func (e *ActionExecutor) Execute(ctx context.Context, action Action, contextData map[string]interface{}) error {
+// Execute executes a single action with the given context data and returns its output, which later
+// actions can reference as {steps.<id>.<field>} when the action has an ID
+func (e *ActionExecutor) Execute(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
 	log.Printf("🔧 Executing action: %s", action.Type)
 
 	switch action.Type {
@@ -54,13 +57,25 @@ func (e *ActionExecutor) Execute(ctx context.Context, action Action, contextData
 	case "log_message":
 		return e.executeLogMessage(action, contextData)
 
+	case "add_to_cart":
+		return e.executeAddToCart(ctx, action, contextData)
+
+	case "create_order":
+		return e.executeCreateOrder(ctx, action, contextData)
+
+	case "update_order_status":
+		return e.executeUpdateOrderStatus(ctx, action, contextData)
+
+	case "sales_summary":
+		return e.executeSalesSummary(ctx, action, contextData)
+
 	default:
-		return fmt.Errorf("unknown action type: %s", action.Type)
+		return nil, fmt.Errorf("unknown action type: %s", action.Type)
 	}
 }
 
 // executeSendWhatsApp sends a WhatsApp message
-func (e *ActionExecutor) executeSendWhatsApp(ctx context.Context, action Action, contextData map[string]interface{}) error {
+func (e *ActionExecutor) executeSendWhatsApp(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
 	// Get session ID from config or context
 	sessionID, ok := action.Config["session_id"].(string)
 	if !ok || sessionID == "" {
@@ -71,12 +86,15 @@ func (e *ActionExecutor) executeSendWhatsApp(ctx context.Context, action Action,
 	}
 
 	if sessionID == "" {
-		return fmt.Errorf("session_id is required for send_whatsapp action")
+		return nil, fmt.Errorf("session_id is required for send_whatsapp action")
 	}
 
-	// Get recipient
-	recipient, ok := action.Config["recipient"].(string)
-	if !ok || recipient == "" {
+	// Get recipient, which may be a template such as "{admin_phone}"
+	recipient, err := renderConfigString(action.Config, "recipient", NewTemplateVars(contextData))
+	if err != nil {
+		return nil, err
+	}
+	if recipient == "" {
 		// Try to get from context (e.g., sender phone number)
 		if rec, exists := contextData["from"]; exists {
 			recipient, _ = rec.(string)
@@ -84,7 +102,7 @@ func (e *ActionExecutor) executeSendWhatsApp(ctx context.Context, action Action,
 	}
 
 	if recipient == "" {
-		return fmt.Errorf("recipient is required for send_whatsapp action")
+		return nil, fmt.Errorf("recipient is required for send_whatsapp action")
 	}
 
 	// Get message template
@@ -92,41 +110,44 @@ func (e *ActionExecutor) executeSendWhatsApp(ctx context.Context, action Action,
 	if !ok {
 		messageTemplate, ok = action.Config["template"].(string)
 		if !ok {
-			return fmt.Errorf("message or template is required for send_whatsapp action")
+			return nil, fmt.Errorf("message or template is required for send_whatsapp action")
 		}
 	}
 
 	// Replace variables in template with context data
-	message := e.replaceVariables(messageTemplate, contextData)
+	message, err := RenderTemplate(messageTemplate, NewTemplateVars(contextData), TemplateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
 
 	// Send WhatsApp message
 	log.Printf("📤 Sending WhatsApp to %s: %s", recipient, message)
 
-	err := e.waService.SendMessage(recipient, message)
+	err = e.waService.SendMessage(recipient, message)
 	if err != nil {
-		return fmt.Errorf("failed to send WhatsApp message: %w", err)
+		return nil, fmt.Errorf("failed to send WhatsApp message: %w", err)
 	}
 
 	log.Printf("✅ WhatsApp message sent successfully")
-	return nil
+	return map[string]interface{}{"recipient": recipient, "message": message}, nil
 }
 
 // executeUpdateDatabase updates a database record
-func (e *ActionExecutor) executeUpdateDatabase(ctx context.Context, action Action, contextData map[string]interface{}) error {
+func (e *ActionExecutor) executeUpdateDatabase(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
 	table, ok := action.Config["table"].(string)
 	if !ok || table == "" {
-		return fmt.Errorf("table is required for update_database action")
+		return nil, fmt.Errorf("table is required for update_database action")
 	}
 
 	updates, ok := action.Config["updates"].(map[string]interface{})
 	if !ok || len(updates) == 0 {
-		return fmt.Errorf("updates is required for update_database action")
+		return nil, fmt.Errorf("updates is required for update_database action")
 	}
 
 	// Get WHERE conditions
 	where, ok := action.Config["where"].(map[string]interface{})
 	if !ok || len(where) == 0 {
-		return fmt.Errorf("where is required for update_database action")
+		return nil, fmt.Errorf("where is required for update_database action")
 	}
 
 	// Build query
@@ -140,18 +161,25 @@ func (e *ActionExecutor) executeUpdateDatabase(ctx context.Context, action Actio
 	// Execute update
 	result := query.Updates(updates)
 	if result.Error != nil {
-		return fmt.Errorf("database update failed: %w", result.Error)
+		return nil, fmt.Errorf("database update failed: %w", result.Error)
 	}
 
 	log.Printf("✅ Updated %d rows in table %s", result.RowsAffected, table)
-	return nil
+	return map[string]interface{}{"rows_affected": result.RowsAffected}, nil
 }
 
 // executeCallAPI calls an external API
-func (e *ActionExecutor) executeCallAPI(ctx context.Context, action Action, contextData map[string]interface{}) error {
-	url, ok := action.Config["url"].(string)
-	if !ok || url == "" {
-		return fmt.Errorf("url is required for call_api action")
+func (e *ActionExecutor) executeCallAPI(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
+	urlTemplate, ok := action.Config["url"].(string)
+	if !ok || urlTemplate == "" {
+		return nil, fmt.Errorf("url is required for call_api action")
+	}
+
+	// Values are URL-escaped, so they can't add path segments or query parameters
+	vars := NewTemplateVars(contextData)
+	url, err := RenderTemplate(urlTemplate, vars, TemplateURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url template: %w", err)
 	}
 
 	method, ok := action.Config["method"].(string)
@@ -159,22 +187,34 @@ func (e *ActionExecutor) executeCallAPI(ctx context.Context, action Action, cont
 		method = "POST" // Default to POST
 	}
 
-	// Get body
-	body := action.Config["body"]
+	// Get body: a JSON object whose strings are templates, or a raw JSON template
 	var bodyBytes []byte
-	var err error
-
-	if body != nil {
-		bodyBytes, err = json.Marshal(body)
+	switch body := action.Config["body"].(type) {
+	case nil:
+	case string:
+		rendered, err := RenderTemplate(body, vars, TemplateJSON)
+		if err != nil {
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		if !json.Valid([]byte(rendered)) {
+			return nil, fmt.Errorf("body template does not render to valid JSON")
+		}
+		bodyBytes = []byte(rendered)
+	default:
+		rendered, err := RenderTemplateValues(body, vars)
 		if err != nil {
-			return fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, fmt.Errorf("invalid body template: %w", err)
+		}
+		bodyBytes, err = json.Marshal(rendered)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 	}
 
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewBuffer(bodyBytes))
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	// Add headers
@@ -195,84 +235,84 @@ func (e *ActionExecutor) executeCallAPI(ctx context.Context, action Action, cont
 	log.Printf("🌐 Calling API: %s %s", method, url)
 	resp, err := e.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Read response
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return fmt.Errorf("failed to read response body: %w", err)
+		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	// Check status code
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("API returned error status %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("API returned error status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	log.Printf("✅ API call successful: %d", resp.StatusCode)
-	return nil
+
+	// A JSON object response is available field by field ({steps.<id>.response.<field>})
+	body := string(respBody)
+	if len(body) > maxAPIOutputBody {
+		body = body[:maxAPIOutputBody]
+	}
+	output := map[string]interface{}{"status_code": resp.StatusCode, "body": body}
+	var response map[string]interface{}
+	if json.Unmarshal(respBody, &response) == nil {
+		output["response"] = response
+	}
+	return output, nil
 }
 
 // executeCallLLM calls the LLM service
-func (e *ActionExecutor) executeCallLLM(ctx context.Context, action Action, contextData map[string]interface{}) error {
+func (e *ActionExecutor) executeCallLLM(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
 	systemPrompt, _ := action.Config["system_prompt"].(string)
 	userPrompt, ok := action.Config["user_prompt"].(string)
 	if !ok || userPrompt == "" {
-		return fmt.Errorf("user_prompt is required for call_llm action")
+		return nil, fmt.Errorf("user_prompt is required for call_llm action")
 	}
 
 	// Replace variables in prompts
-	systemPrompt = e.replaceVariables(systemPrompt, contextData)
-	userPrompt = e.replaceVariables(userPrompt, contextData)
+	vars := NewTemplateVars(contextData)
+	systemPrompt, err := RenderTemplate(systemPrompt, vars, TemplateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid system_prompt template: %w", err)
+	}
+	userPrompt, err = RenderTemplate(userPrompt, vars, TemplateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user_prompt template: %w", err)
+	}
 
 	// Call LLM
 	log.Printf("🤖 Calling LLM with prompt: %s", userPrompt[:min(100, len(userPrompt))])
 	response, err := e.llmService.GenerateResponse(ctx, systemPrompt, userPrompt)
 	if err != nil {
-		return fmt.Errorf("LLM call failed: %w", err)
+		return nil, fmt.Errorf("LLM call failed: %w", err)
 	}
 
 	// Store response in context for next actions (if needed)
 	contextData["llm_response"] = response
 
 	log.Printf("✅ LLM call successful")
-	return nil
+	return map[string]interface{}{"response": response}, nil
 }
 
 // executeLogMessage logs a message
-func (e *ActionExecutor) executeLogMessage(action Action, contextData map[string]interface{}) error {
+func (e *ActionExecutor) executeLogMessage(action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
 	message, ok := action.Config["message"].(string)
 	if !ok || message == "" {
-		return fmt.Errorf("message is required for log_message action")
+		return nil, fmt.Errorf("message is required for log_message action")
 	}
 
 	// Replace variables
-	message = e.replaceVariables(message, contextData)
+	message, err := RenderTemplate(message, NewTemplateVars(contextData), TemplateText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid message template: %w", err)
+	}
 
 	log.Printf("📝 Workflow Log: %s", message)
-	return nil
-}
-
-// replaceVariables replaces {variable} placeholders with actual values from context
-func (e *ActionExecutor) replaceVariables(template string, contextData map[string]interface{}) string {
-	// Find all {variable} patterns
-	re := regexp.MustCompile(`\{([^}]+)\}`)
-
-	result := re.ReplaceAllStringFunc(template, func(match string) string {
-		// Extract variable name (remove { and })
-		varName := strings.Trim(match, "{}")
-
-		// Look up value in context data
-		if value, exists := contextData[varName]; exists {
-			return fmt.Sprintf("%v", value)
-		}
-
-		// Return original if not found
-		return match
-	})
-
-	return result
+	return map[string]interface{}{"message": message}, nil
 }
 
 // min returns the minimum of two integers
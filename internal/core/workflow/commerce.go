@@ -0,0 +1,345 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// Commerce performs the order and cart actions of workflows. It is implemented by the module that owns
+// orders, so workflows use the same stock, payment and notification rules as the API.
+type Commerce interface {
+	AddToCart(ctx context.Context, req CartItemInput) (map[string]interface{}, error)
+	CreateOrder(ctx context.Context, req OrderInput) (map[string]interface{}, error)
+	UpdateOrderStatus(ctx context.Context, req OrderStatusInput) (map[string]interface{}, error)
+	SalesSummary(ctx context.Context, clientID, period string) (map[string]interface{}, error)
+}
+
+// Periods a sales_summary action reports on, in the client's timezone
+const (
+	SalesPeriodToday     = "today" // Default
+	SalesPeriodYesterday = "yesterday"
+	SalesPeriodLast7Days = "last_7_days"
+)
+
+// CartItemInput is a product added to a customer's cart by a workflow
+type CartItemInput struct {
+	ClientID      string
+	CustomerPhone string
+	ProductID     string // Catalog product ID; ProductName is looked up when empty
+	ProductName   string
+	Quantity      int
+	Price         *float64 // Overrides the catalog price
+	Notes         string
+}
+
+// OrderInput is an order created by a workflow, from the listed items or the customer's cart
+type OrderInput struct {
+	ClientID       string
+	CustomerPhone  string
+	CustomerName   string
+	Items          []CartItemInput // Empty checks out the customer's cart
+	PaymentMethod  string          // "cod" for cash on delivery, online otherwise
+	DeliveryNotes  string
+	IdempotencyKey string // Runs with the same key return the order the first run created
+}
+
+// OrderStatusInput moves an order through fulfillment, or cancels it
+type OrderStatusInput struct {
+	ClientID       string
+	OrderRef       string // Order ID or order number
+	Status         string // packed, shipped, delivered or cancelled
+	Courier        string // Required to ship
+	TrackingNumber string // Required to ship
+	Reason         string // Cancellation reason
+}
+
+type clientIDKey struct{}
+
+// WithClientID scopes the actions run with ctx to the workflow's client
+func WithClientID(ctx context.Context, clientID string) context.Context {
+	return context.WithValue(ctx, clientIDKey{}, clientID)
+}
+
+// ClientIDFrom returns the client the actions run with ctx belong to
+func ClientIDFrom(ctx context.Context) string {
+	clientID, _ := ctx.Value(clientIDKey{}).(string)
+	return clientID
+}
+
+// SetCommerce enables the add_to_cart, create_order, update_order_status and sales_summary actions
+func (e *ActionExecutor) SetCommerce(commerce Commerce) {
+	e.commerce = commerce
+}
+
+// executeAddToCart adds a product to a customer's cart
+func (e *ActionExecutor) executeAddToCart(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
+	clientID, vars, err := e.commerceScope(ctx, action, contextData)
+	if err != nil {
+		return nil, err
+	}
+
+	customerPhone, err := customerPhoneFor(action, vars)
+	if err != nil {
+		return nil, err
+	}
+	item, err := cartItemFrom(action.Config, vars)
+	if err != nil {
+		return nil, err
+	}
+	item.ClientID = clientID
+	item.CustomerPhone = customerPhone
+
+	result, err := e.commerce.AddToCart(ctx, item)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to cart: %w", err)
+	}
+
+	// Available to later actions as {cart.total}, {cart.items}
+	contextData["cart"] = result
+
+	log.Printf("✅ Added %dx %v to cart of %s", item.Quantity, result["product_name"], customerPhone)
+	return result, nil
+}
+
+// executeCreateOrder creates an order from listed items or the customer's cart
+func (e *ActionExecutor) executeCreateOrder(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
+	clientID, vars, err := e.commerceScope(ctx, action, contextData)
+	if err != nil {
+		return nil, err
+	}
+
+	customerPhone, err := customerPhoneFor(action, vars)
+	if err != nil {
+		return nil, err
+	}
+
+	req := OrderInput{ClientID: clientID, CustomerPhone: customerPhone}
+	fields := map[string]*string{
+		"customer_name":   &req.CustomerName,
+		"payment_method":  &req.PaymentMethod,
+		"delivery_notes":  &req.DeliveryNotes,
+		"idempotency_key": &req.IdempotencyKey,
+	}
+	for key, field := range fields {
+		if *field, err = renderConfigString(action.Config, key, vars); err != nil {
+			return nil, err
+		}
+	}
+
+	if rawItems, ok := action.Config["items"]; ok {
+		list, ok := rawItems.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("items must be a list")
+		}
+		for i, raw := range list {
+			config, ok := raw.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("item %d must be an object", i+1)
+			}
+			item, err := cartItemFrom(config, vars)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %w", i+1, err)
+			}
+			req.Items = append(req.Items, item)
+		}
+	}
+
+	result, err := e.commerce.CreateOrder(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create order: %w", err)
+	}
+
+	// Available to later actions as {order.id}, {order.order_number}, {order.total}
+	contextData["order"] = result
+
+	log.Printf("✅ Order %v created for %s", result["order_number"], customerPhone)
+	return result, nil
+}
+
+// executeUpdateOrderStatus marks an order packed, shipped or delivered, or cancels it
+func (e *ActionExecutor) executeUpdateOrderStatus(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
+	clientID, vars, err := e.commerceScope(ctx, action, contextData)
+	if err != nil {
+		return nil, err
+	}
+
+	req := OrderStatusInput{ClientID: clientID}
+	fields := map[string]*string{
+		"status":          &req.Status,
+		"courier":         &req.Courier,
+		"tracking_number": &req.TrackingNumber,
+		"reason":          &req.Reason,
+	}
+	for key, field := range fields {
+		if *field, err = renderConfigString(action.Config, key, vars); err != nil {
+			return nil, err
+		}
+	}
+	if req.Status == "" {
+		return nil, fmt.Errorf("status is required for update_order_status action")
+	}
+
+	// The order from the config, else the order created earlier in the run or the one that triggered it
+	if req.OrderRef, err = renderConfigString(action.Config, "order", vars); err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"order.id", "order.order_number", "order_id", "order_number"} {
+		if req.OrderRef == "" {
+			req.OrderRef = vars[name]
+		}
+	}
+	if req.OrderRef == "" {
+		return nil, fmt.Errorf("order is required for update_order_status action")
+	}
+
+	result, err := e.commerce.UpdateOrderStatus(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update order status: %w", err)
+	}
+	contextData["order"] = result
+
+	log.Printf("✅ Order %s marked %s", req.OrderRef, req.Status)
+	return result, nil
+}
+
+// executeSalesSummary reports the client's orders and revenue over a period
+func (e *ActionExecutor) executeSalesSummary(ctx context.Context, action Action, contextData map[string]interface{}) (map[string]interface{}, error) {
+	clientID, vars, err := e.commerceScope(ctx, action, contextData)
+	if err != nil {
+		return nil, err
+	}
+
+	period, err := renderConfigString(action.Config, "period", vars)
+	if err != nil {
+		return nil, err
+	}
+	switch period {
+	case "":
+		period = SalesPeriodToday
+	case SalesPeriodToday, SalesPeriodYesterday, SalesPeriodLast7Days:
+	default:
+		return nil, fmt.Errorf("period must be %s, %s or %s", SalesPeriodToday, SalesPeriodYesterday, SalesPeriodLast7Days)
+	}
+
+	result, err := e.commerce.SalesSummary(ctx, clientID, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to summarize sales: %w", err)
+	}
+
+	log.Printf("📊 Sales summary (%s): %v orders, revenue %v", period, result["orders"], result["revenue"])
+	return result, nil
+}
+
+// commerceScope checks the commerce actions are available and returns the workflow's client and the
+// template variables of the run
+func (e *ActionExecutor) commerceScope(ctx context.Context, action Action, contextData map[string]interface{}) (string, TemplateVars, error) {
+	if e.commerce == nil {
+		return "", nil, fmt.Errorf("%s action is not available", action.Type)
+	}
+	clientID := ClientIDFrom(ctx)
+	if clientID == "" {
+		return "", nil, fmt.Errorf("%s action requires a client", action.Type)
+	}
+	return clientID, NewTemplateVars(contextData), nil
+}
+
+// customerPhoneFor returns the customer an action is for: customer_phone from the config, else the
+// customer or sender of the trigger
+func customerPhoneFor(action Action, vars TemplateVars) (string, error) {
+	phone, err := renderConfigString(action.Config, "customer_phone", vars)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range []string{"customer_phone", "from"} {
+		if phone == "" {
+			phone = vars[name]
+		}
+	}
+	if phone == "" {
+		return "", fmt.Errorf("customer_phone is required for %s action", action.Type)
+	}
+	return phone, nil
+}
+
+// cartItemFrom reads a product, quantity and optional price from action config
+func cartItemFrom(config map[string]interface{}, vars TemplateVars) (CartItemInput, error) {
+	var item CartItemInput
+	var err error
+	if item.ProductID, err = renderConfigString(config, "product_id", vars); err != nil {
+		return item, err
+	}
+	if item.ProductName, err = renderConfigString(config, "product_name", vars); err != nil {
+		return item, err
+	}
+	if item.Notes, err = renderConfigString(config, "notes", vars); err != nil {
+		return item, err
+	}
+	if item.ProductID == "" && item.ProductName == "" {
+		return item, fmt.Errorf("product_id or product_name is required")
+	}
+
+	item.Quantity = 1
+	if _, ok := config["quantity"]; ok {
+		quantity, err := configNumber(config, "quantity", vars)
+		if err != nil {
+			return item, err
+		}
+		if quantity < 1 || quantity != float64(int(quantity)) {
+			return item, fmt.Errorf("quantity must be a whole number of at least 1")
+		}
+		item.Quantity = int(quantity)
+	}
+	if _, ok := config["price"]; ok {
+		price, err := configNumber(config, "price", vars)
+		if err != nil {
+			return item, err
+		}
+		if price < 0 {
+			return item, fmt.Errorf("price cannot be negative")
+		}
+		item.Price = &price
+	}
+	return item, nil
+}
+
+// renderConfigString renders an optional string template from action config
+func renderConfigString(config map[string]interface{}, key string, vars TemplateVars) (string, error) {
+	raw, ok := config[key]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	template, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("%s must be a string", key)
+	}
+	value, err := RenderTemplate(template, vars, TemplateText)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s template: %w", key, err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// configNumber reads a number from action config, given as a number or a template such as "{quantity}"
+func configNumber(config map[string]interface{}, key string, vars TemplateVars) (float64, error) {
+	switch value := config[key].(type) {
+	case float64:
+		return value, nil
+	case int:
+		return float64(value), nil
+	case string:
+		rendered, err := renderConfigString(config, key, vars)
+		if err != nil {
+			return 0, err
+		}
+		number, err := strconv.ParseFloat(rendered, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%s must be a number, got %q", key, rendered)
+		}
+		return number, nil
+	default:
+		return 0, fmt.Errorf("%s must be a number", key)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 )
 
 // ConditionEvaluator evaluates workflow conditions
@@ -59,8 +60,13 @@ func (e *ConditionEvaluator) Evaluate(conditions []Condition, data map[string]in
 
 // evaluateSingle evaluates a single condition
 func (e *ConditionEvaluator) evaluateSingle(condition Condition, data map[string]interface{}) (bool, error) {
+	// Time-based operators can compare against the current time
+	if condition.Field == NowField && isTemporalOperator(condition.Operator) {
+		return e.evaluateTemporal(condition, time.Now())
+	}
+
 	// Extract field value from data
-	fieldValue, exists := data[condition.Field]
+	fieldValue, exists := lookupField(data, condition.Field)
 	if !exists {
 		// Field doesn't exist in data
 		// For "not_equals", this should return true
@@ -118,11 +124,38 @@ func (e *ConditionEvaluator) evaluateSingle(condition Condition, data map[string
 		}
 		return !result, nil
 
+	case "time_before", "time_after", "day_of_week", "older_than", "within_last", "between_dates":
+		return e.evaluateTemporal(condition, fieldValue)
+
 	default:
 		return false, fmt.Errorf("unknown operator: %s", condition.Operator)
 	}
 }
 
+// lookupField returns a field of the data, following dotted names into nested maps ("steps.order.total")
+// when there is no field with the full name
+func lookupField(data map[string]interface{}, field string) (interface{}, bool) {
+	if value, ok := data[field]; ok {
+		return value, true
+	}
+
+	current := data
+	parts := strings.Split(field, ".")
+	for i, part := range parts {
+		value, ok := current[part]
+		if !ok {
+			return nil, false
+		}
+		if i == len(parts)-1 {
+			return value, true
+		}
+		if current, ok = value.(map[string]interface{}); !ok {
+			return nil, false
+		}
+	}
+	return nil, false
+}
+
 // compareEquals checks if two values are equal
 func (e *ConditionEvaluator) compareEquals(fieldValue, conditionValue interface{}) bool {
 	return reflect.DeepEqual(fieldValue, conditionValue)
@@ -188,16 +221,34 @@ func (e *ConditionEvaluator) compareLessOrEqual(fieldValue, conditionValue inter
 	return fieldNum <= condNum, nil
 }
 
-// compareContains checks if field value contains condition value (string)
+// compareContains checks if field value contains condition value (string).
+// For a list field (e.g. customer tags) it checks for an element equal to the value, ignoring case.
 func (e *ConditionEvaluator) compareContains(fieldValue, conditionValue interface{}) (bool, error) {
-	fieldStr, ok := fieldValue.(string)
+	condStr, ok := conditionValue.(string)
 	if !ok {
-		return false, fmt.Errorf("field value is not a string")
+		return false, fmt.Errorf("condition value is not a string")
 	}
 
-	condStr, ok := conditionValue.(string)
+	switch list := fieldValue.(type) {
+	case []string:
+		for _, item := range list {
+			if strings.EqualFold(item, condStr) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case []interface{}:
+		for _, item := range list {
+			if itemStr, ok := item.(string); ok && strings.EqualFold(itemStr, condStr) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	fieldStr, ok := fieldValue.(string)
 	if !ok {
-		return false, fmt.Errorf("condition value is not a string")
+		return false, fmt.Errorf("field value is not a string")
 	}
 
 	return strings.Contains(strings.ToLower(fieldStr), strings.ToLower(condStr)), nil
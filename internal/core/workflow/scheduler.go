@@ -1,14 +1,23 @@
 package workflow
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
-// Scheduler handles scheduled (cron-based) workflow triggers
+// cronParser matches the parser used by cron.WithSeconds()
+var cronParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+// minInterval is the shortest interval allowed for interval triggers
+const minInterval = time.Minute
+
+// Scheduler handles scheduled (cron, interval and one-shot) workflow triggers
 type Scheduler struct {
 	cron    *cron.Cron
 	jobs    map[string]cron.EntryID // workflow_id -> entry_id
@@ -31,15 +40,42 @@ func (s *Scheduler) Start() {
 }
 
 // Stop stops the scheduler
-func (s *Scheduler) Stop() {
+// Stop stops scheduling runs; the returned context is done once the runs in progress have finished
+func (s *Scheduler) Stop() context.Context {
 	log.Println("⏰ Stopping workflow scheduler...")
-	s.cron.Stop()
+	ctx := s.cron.Stop()
 	log.Println("✅ Workflow scheduler stopped")
+	return ctx
 }
 
 // AddWorkflow adds a workflow to the scheduler
-// schedule should be a cron expression (e.g., "0 18 * * *" for daily at 6 PM)
+// schedule should be a cron expression (e.g., "0 0 18 * * *" for daily at 6 PM)
 func (s *Scheduler) AddWorkflow(workflowID string, schedule string, job func()) error {
+	return s.AddWorkflowTrigger(workflowID, TriggerConfig{Schedule: schedule}, job)
+}
+
+// AddWorkflowTrigger adds a workflow using a cron, interval or one-shot trigger config
+func (s *Scheduler) AddWorkflowTrigger(workflowID string, config TriggerConfig, job func()) error {
+	schedule, err := BuildSchedule(config)
+	if err != nil {
+		return err
+	}
+
+	var wrapped cron.Job = cron.FuncJob(job)
+
+	// One-shot triggers remove themselves after firing
+	if config.RunAt != nil {
+		inner := wrapped
+		wrapped = cron.FuncJob(func() {
+			inner.Run()
+			s.RemoveWorkflow(workflowID)
+		})
+	}
+
+	if config.SkipIfRunning {
+		wrapped = cron.NewChain(cron.SkipIfStillRunning(cron.DefaultLogger)).Then(wrapped)
+	}
+
 	s.jobsMux.Lock()
 	defer s.jobsMux.Unlock()
 
@@ -49,14 +85,9 @@ func (s *Scheduler) AddWorkflow(workflowID string, schedule string, job func())
 		delete(s.jobs, workflowID)
 	}
 
-	// Add new job
-	entryID, err := s.cron.AddFunc(schedule, job)
-	if err != nil {
-		return fmt.Errorf("failed to add cron job: %w", err)
-	}
-
+	entryID := s.cron.Schedule(schedule, wrapped)
 	s.jobs[workflowID] = entryID
-	log.Printf("   ✅ Scheduled workflow %s: %s", workflowID, schedule)
+	log.Printf("   ✅ Scheduled workflow %s: %s", workflowID, config.Describe())
 
 	return nil
 }
@@ -73,6 +104,33 @@ func (s *Scheduler) RemoveWorkflow(workflowID string) {
 	}
 }
 
+// NextRun returns the next time a scheduled workflow will run, or nil if it isn't scheduled
+func (s *Scheduler) NextRun(workflowID string) *time.Time {
+	s.jobsMux.RLock()
+	entryID, exists := s.jobs[workflowID]
+	s.jobsMux.RUnlock()
+
+	if !exists {
+		return nil
+	}
+
+	entry := s.cron.Entry(entryID)
+	if !entry.Valid() {
+		return nil
+	}
+
+	next := entry.Next
+	if next.IsZero() {
+		// Scheduler not started yet, compute from the schedule directly
+		next = entry.Schedule.Next(time.Now())
+		if next.IsZero() {
+			return nil
+		}
+	}
+
+	return &next
+}
+
 // GetScheduledWorkflows returns all currently scheduled workflow IDs
 func (s *Scheduler) GetScheduledWorkflows() []string {
 	s.jobsMux.RLock()
@@ -85,3 +143,55 @@ func (s *Scheduler) GetScheduledWorkflows() []string {
 
 	return workflowIDs
 }
+
+// BuildSchedule converts a trigger config into a cron schedule
+func BuildSchedule(config TriggerConfig) (cron.Schedule, error) {
+	if err := config.ValidateSchedule(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case config.RunAt != nil:
+		return oneShotSchedule{at: *config.RunAt}, nil
+
+	case config.Interval != "":
+		interval, _ := ParseInterval(config.Interval)
+		return cron.Every(interval), nil
+
+	default:
+		schedule, err := cronParser.Parse(config.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression: %w", err)
+		}
+		return schedule, nil
+	}
+}
+
+// ParseInterval parses interval triggers such as "15m", "every 15m" or "every 2h"
+func ParseInterval(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	value = strings.TrimSpace(strings.TrimPrefix(value, "every"))
+
+	interval, err := parseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid interval: %w", err)
+	}
+	if interval < minInterval {
+		return 0, fmt.Errorf("interval must be at least %s", minInterval)
+	}
+
+	return interval, nil
+}
+
+// oneShotSchedule fires once at a fixed time
+type oneShotSchedule struct {
+	at time.Time
+}
+
+// Next returns the run-at time if it's still ahead, otherwise zero (never)
+func (o oneShotSchedule) Next(t time.Time) time.Time {
+	if t.Before(o.at) {
+		return o.at
+	}
+	return time.Time{}
+}
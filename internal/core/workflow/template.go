@@ -0,0 +1,178 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TemplateTarget is where a rendered template ends up, which decides how values are escaped
+type TemplateTarget int
+
+const (
+	// TemplateText is plain text: WhatsApp messages, LLM prompts and logs. Control characters other than
+	// line breaks and tabs are removed from values.
+	TemplateText TemplateTarget = iota
+	// TemplateJSON is a template of a raw JSON document; values are placed inside JSON string literals
+	// ("{name}") and escaped so they can't close the string or break the document.
+	TemplateJSON
+	// TemplateURL is a URL; values are query-escaped so they can't add path segments or parameters.
+	TemplateURL
+)
+
+// Template limits
+const (
+	MaxTemplateValueLength = 1000 // Runes of one substituted value, longer values are truncated
+	maxTemplateDepth       = 4    // Nesting of maps reachable with dotted names ({steps.lookup.response.name})
+)
+
+// maxTemplateOutput is the longest rendered template per target: WhatsApp's message limit for text
+var maxTemplateOutput = map[TemplateTarget]int{
+	TemplateText: 4096,
+	TemplateJSON: 64 * 1024,
+	TemplateURL:  2048,
+}
+
+// templateVariablePattern matches {name} and {name.field}; other braces (JSON, code in prompts) are literal text
+var templateVariablePattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_]*(?:\.[A-Za-z_][A-Za-z0-9_]*)*)\}`)
+
+// TemplateVars are the variables a template may use. Only scalar values (strings, numbers, booleans) are
+// exposed, nested maps through dotted names; lists, structs and other values are not available.
+type TemplateVars map[string]string
+
+// NewTemplateVars whitelists the scalar values of workflow context data as template variables
+func NewTemplateVars(data map[string]interface{}) TemplateVars {
+	vars := TemplateVars{}
+	vars.add("", data, 1)
+	return vars
+}
+
+func (v TemplateVars) add(prefix string, data map[string]interface{}, depth int) {
+	for key, value := range data {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+		switch val := value.(type) {
+		case string:
+			v[name] = val
+		case bool:
+			v[name] = strconv.FormatBool(val)
+		case int:
+			v[name] = strconv.Itoa(val)
+		case int64:
+			v[name] = strconv.FormatInt(val, 10)
+		case float64:
+			v[name] = strconv.FormatFloat(val, 'f', -1, 64)
+		case float32:
+			v[name] = strconv.FormatFloat(float64(val), 'f', -1, 32)
+		case json.Number:
+			v[name] = val.String()
+		case fmt.Stringer:
+			v[name] = val.String() // e.g. uuid.UUID
+		case map[string]interface{}:
+			if depth < maxTemplateDepth {
+				v.add(name, val, depth+1)
+			}
+		}
+	}
+}
+
+// Names lists the available variables, sorted
+func (v TemplateVars) Names() []string {
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RenderTemplate substitutes {variable} placeholders in one pass, so braces inside values are never
+// expanded. Values are capped at MaxTemplateValueLength and escaped for the target. An unknown variable
+// or an output over the target's length limit is an error.
+func RenderTemplate(template string, vars TemplateVars, target TemplateTarget) (string, error) {
+	var unknown []string
+	result := templateVariablePattern.ReplaceAllStringFunc(template, func(match string) string {
+		name := match[1 : len(match)-1]
+		value, ok := vars[name]
+		if !ok {
+			unknown = append(unknown, match)
+			return match
+		}
+		return escapeTemplateValue(truncateTemplateValue(value), target)
+	})
+
+	if len(unknown) > 0 {
+		available := strings.Join(vars.Names(), ", ")
+		if available == "" {
+			available = "none"
+		}
+		return "", fmt.Errorf("unknown template variable %s (available: %s)", strings.Join(unknown, ", "), available)
+	}
+	if limit := maxTemplateOutput[target]; utf8.RuneCountInString(result) > limit {
+		return "", fmt.Errorf("rendered template is %d characters, the limit is %d", utf8.RuneCountInString(result), limit)
+	}
+	return result, nil
+}
+
+// RenderTemplateValues renders every string in a JSON-like value (maps, lists), for structured API bodies.
+// Strings are rendered as text: the body is JSON-encoded afterwards, which escapes them.
+func RenderTemplateValues(value interface{}, vars TemplateVars) (interface{}, error) {
+	switch val := value.(type) {
+	case string:
+		return RenderTemplate(val, vars, TemplateText)
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(val))
+		for key, item := range val {
+			r, err := RenderTemplateValues(item, vars)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			rendered[key] = r
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(val))
+		for i, item := range val {
+			r, err := RenderTemplateValues(item, vars)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			rendered[i] = r
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+func truncateTemplateValue(value string) string {
+	if utf8.RuneCountInString(value) <= MaxTemplateValueLength {
+		return value
+	}
+	return string([]rune(value)[:MaxTemplateValueLength-1]) + "…"
+}
+
+func escapeTemplateValue(value string, target TemplateTarget) string {
+	switch target {
+	case TemplateJSON:
+		encoded, _ := json.Marshal(value)
+		return string(encoded[1 : len(encoded)-1]) // Without the surrounding quotes
+	case TemplateURL:
+		return url.QueryEscape(value)
+	default:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsControl(r) && r != '\n' && r != '\t' {
+				return -1
+			}
+			return r
+		}, value)
+	}
+}
@@ -0,0 +1,151 @@
+package workflow
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed templates/*.json
+var templateFS embed.FS
+
+// ErrTemplateNotFound is returned for an unknown workflow template ID
+var ErrTemplateNotFound = errors.New("workflow template not found")
+
+// placeholderPattern matches a template variable placeholder such as "{{message}}". Single braces are
+// left alone, as they are filled in by the workflow when it runs.
+var placeholderPattern = regexp.MustCompile(`\{\{\s*([a-z_][a-z0-9_]*)\s*\}\}`)
+
+// WorkflowTemplate is a predefined workflow clients can create with their own values
+type WorkflowTemplate struct {
+	ID          string             `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Category    string             `json:"category"`
+	Variables   []TemplateVariable `json:"variables"`
+	Workflow    json.RawMessage    `json:"workflow"` // CreateWorkflowRequest with {{variable}} placeholders
+}
+
+// TemplateVariable is a value filled in when a workflow is created from a template
+type TemplateVariable struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     string `json:"default,omitempty"`
+	Required    bool   `json:"required,omitempty"` // No default; must be given
+}
+
+// CreateFromTemplateRequest represents the request body for creating a workflow from a template
+type CreateFromTemplateRequest struct {
+	Name      string            `json:"name"`      // Overrides the template's workflow name
+	Variables map[string]string `json:"variables"` // Template variables; defaults are used for the rest
+	IsActive  *bool             `json:"is_active"`
+}
+
+var workflowTemplates = loadTemplates()
+
+// loadTemplates reads the embedded templates, sorted by ID
+func loadTemplates() []WorkflowTemplate {
+	files, err := templateFS.ReadDir("templates")
+	if err != nil {
+		panic(fmt.Sprintf("workflow templates: %v", err))
+	}
+
+	templates := make([]WorkflowTemplate, 0, len(files))
+	for _, file := range files {
+		data, err := templateFS.ReadFile(path.Join("templates", file.Name()))
+		if err != nil {
+			panic(fmt.Sprintf("workflow template %s: %v", file.Name(), err))
+		}
+		var tmpl WorkflowTemplate
+		if err := json.Unmarshal(data, &tmpl); err != nil {
+			panic(fmt.Sprintf("workflow template %s: %v", file.Name(), err))
+		}
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates
+}
+
+// ListTemplates returns the predefined workflow templates
+func ListTemplates() []WorkflowTemplate {
+	return workflowTemplates
+}
+
+// GetTemplate returns the workflow template with the given ID
+func GetTemplate(id string) (*WorkflowTemplate, error) {
+	for i := range workflowTemplates {
+		if workflowTemplates[i].ID == id {
+			return &workflowTemplates[i], nil
+		}
+	}
+	return nil, ErrTemplateNotFound
+}
+
+// Instantiate fills the template's variables in, using their defaults where no value is given, and
+// returns the workflow to create
+func (t *WorkflowTemplate) Instantiate(values map[string]string) (*CreateWorkflowRequest, error) {
+	resolved := make(map[string]string, len(t.Variables))
+	for _, variable := range t.Variables {
+		value, ok := values[variable.Name]
+		if !ok || strings.TrimSpace(value) == "" {
+			if variable.Required {
+				return nil, fmt.Errorf("variable %s is required", variable.Name)
+			}
+			value = variable.Default
+		}
+		resolved[variable.Name] = value
+	}
+	for name := range values {
+		if _, ok := resolved[name]; !ok {
+			return nil, fmt.Errorf("unknown variable %s for template %s", name, t.ID)
+		}
+	}
+
+	var workflow interface{}
+	if err := json.Unmarshal(t.Workflow, &workflow); err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", t.ID, err)
+	}
+	workflow = substituteVariables(workflow, resolved)
+
+	// Round trip through JSON so the result is decoded exactly as an API request would be
+	data, err := json.Marshal(workflow)
+	if err != nil {
+		return nil, err
+	}
+	var req CreateWorkflowRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid template %s: %w", t.ID, err)
+	}
+	return &req, nil
+}
+
+// substituteVariables replaces the {{variable}} placeholders in every string of a decoded JSON value
+func substituteVariables(value interface{}, values map[string]string) interface{} {
+	switch v := value.(type) {
+	case string:
+		return placeholderPattern.ReplaceAllStringFunc(v, func(match string) string {
+			name := placeholderPattern.FindStringSubmatch(match)[1]
+			if replacement, ok := values[name]; ok {
+				return replacement
+			}
+			return match
+		})
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = substituteVariables(item, values)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = substituteVariables(item, values)
+		}
+		return v
+	default:
+		return value
+	}
+}
@@ -0,0 +1,214 @@
+package workflow
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NowField can be used as a condition field to compare against the current time
+const NowField = "$now"
+
+// defaultTimezone is used when a condition doesn't specify one
+const defaultTimezone = "Asia/Jakarta"
+
+// temporalOperators are operators that work on timestamps instead of plain values
+var temporalOperators = map[string]bool{
+	"time_before":   true,
+	"time_after":    true,
+	"day_of_week":   true,
+	"older_than":    true,
+	"within_last":   true,
+	"between_dates": true,
+}
+
+// isTemporalOperator reports whether an operator compares timestamps
+func isTemporalOperator(operator string) bool {
+	return temporalOperators[operator]
+}
+
+// evaluateTemporal evaluates time-based operators
+func (e *ConditionEvaluator) evaluateTemporal(condition Condition, fieldValue interface{}) (bool, error) {
+	loc, err := loadLocation(condition.Timezone)
+	if err != nil {
+		return false, err
+	}
+
+	ts, err := toTime(fieldValue, loc)
+	if err != nil {
+		return false, fmt.Errorf("field '%s' is not a timestamp: %v", condition.Field, err)
+	}
+	ts = ts.In(loc)
+
+	switch condition.Operator {
+	case "time_before", "time_after":
+		// Value: "HH:MM" compared against the time of day of the field
+		condStr, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("condition value must be a time of day (HH:MM)")
+		}
+		minutes, err := parseTimeOfDay(condStr)
+		if err != nil {
+			return false, err
+		}
+		fieldMinutes := ts.Hour()*60 + ts.Minute()
+		if condition.Operator == "time_before" {
+			return fieldMinutes < minutes, nil
+		}
+		return fieldMinutes >= minutes, nil
+
+	case "day_of_week":
+		// Value: a day name/number or a list of them
+		days, err := parseDaysOfWeek(condition.Value)
+		if err != nil {
+			return false, err
+		}
+		return days[ts.Weekday()], nil
+
+	case "older_than", "within_last":
+		// Value: duration such as "30m", "2h", "7d"
+		condStr, ok := condition.Value.(string)
+		if !ok {
+			return false, fmt.Errorf("condition value must be a duration (e.g. 2h, 7d)")
+		}
+		duration, err := parseDuration(condStr)
+		if err != nil {
+			return false, err
+		}
+		age := time.Since(ts)
+		if condition.Operator == "older_than" {
+			return age > duration, nil
+		}
+		return age >= 0 && age <= duration, nil
+
+	case "between_dates":
+		// Value: [start, end] dates, end date is inclusive when given without a time
+		bounds, ok := condition.Value.([]interface{})
+		if !ok || len(bounds) != 2 {
+			return false, fmt.Errorf("condition value must be a list of [start, end] dates")
+		}
+		start, err := toTime(bounds[0], loc)
+		if err != nil {
+			return false, fmt.Errorf("invalid start date: %v", err)
+		}
+		end, err := toTime(bounds[1], loc)
+		if err != nil {
+			return false, fmt.Errorf("invalid end date: %v", err)
+		}
+		if endStr, ok := bounds[1].(string); ok && len(endStr) == len("2006-01-02") {
+			end = end.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		return !ts.Before(start) && !ts.After(end), nil
+
+	default:
+		return false, fmt.Errorf("unknown operator: %s", condition.Operator)
+	}
+}
+
+// loadLocation loads a timezone, falling back to the default
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		name = defaultTimezone
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone '%s': %w", name, err)
+	}
+	return loc, nil
+}
+
+// toTime converts timestamps from event data into time.Time
+func toTime(value interface{}, loc *time.Location) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case *time.Time:
+		if v == nil {
+			return time.Time{}, fmt.Errorf("timestamp is nil")
+		}
+		return *v, nil
+	case string:
+		layouts := []string{time.RFC3339Nano, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"}
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, v, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("unsupported time format: %s", v)
+	default:
+		// Numbers are treated as unix seconds
+		if num, err := toFloat64(value); err == nil {
+			return time.Unix(int64(num), 0), nil
+		}
+		return time.Time{}, fmt.Errorf("cannot convert %T to time", value)
+	}
+}
+
+// parseTimeOfDay parses "HH:MM" into minutes since midnight
+func parseTimeOfDay(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day '%s' (expected HH:MM)", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// parseDuration parses durations, adding support for days ("7d") on top of time.ParseDuration
+func parseDuration(value string) (time.Duration, error) {
+	value = strings.TrimSpace(strings.ToLower(value))
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(value, "d"), 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration '%s'", value)
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration '%s'", value)
+	}
+	return duration, nil
+}
+
+// dayNames maps English and Indonesian day names to weekdays
+var dayNames = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday, "minggu": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday, "senin": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "selasa": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday, "rabu": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "kamis": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday, "jumat": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday, "sabtu": time.Saturday,
+}
+
+// parseDaysOfWeek parses a day or list of days (names or 0-6, Sunday = 0)
+func parseDaysOfWeek(value interface{}) (map[time.Weekday]bool, error) {
+	var items []interface{}
+	if list, ok := value.([]interface{}); ok {
+		items = list
+	} else {
+		items = []interface{}{value}
+	}
+
+	days := make(map[time.Weekday]bool)
+	for _, item := range items {
+		if name, ok := item.(string); ok {
+			day, found := dayNames[strings.ToLower(strings.TrimSpace(name))]
+			if !found {
+				return nil, fmt.Errorf("unknown day of week: %s", name)
+			}
+			days[day] = true
+			continue
+		}
+
+		num, err := toFloat64(item)
+		if err != nil || num < 0 || num > 6 {
+			return nil, fmt.Errorf("invalid day of week: %v", item)
+		}
+		days[time.Weekday(int(num))] = true
+	}
+
+	return days, nil
+}
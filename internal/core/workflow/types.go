@@ -1,33 +1,335 @@
 package workflow
 
-import "time"
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Stages of a customer message at which message_received workflows run
+const (
+	MessageStageBeforeLLM = "before_llm" // Before the AI reply is generated (default)
+	MessageStageAfterLLM  = "after_llm"  // After the AI reply is sent
+)
 
 // TriggerConfig represents the configuration for a workflow trigger
 type TriggerConfig struct {
-	EventName string `json:"event_name,omitempty"` // For event triggers: "transaction_created", "message_received", etc.
-	Schedule  string `json:"schedule,omitempty"`   // For scheduled triggers: cron expression "0 18 * * *"
+	EventName     string     `json:"event_name,omitempty"`      // For event triggers: "transaction_created", "message_received", etc.
+	Schedule      string     `json:"schedule,omitempty"`        // For scheduled triggers: cron expression with seconds "0 0 18 * * *"
+	Interval      string     `json:"interval,omitempty"`        // For scheduled triggers: "every 15m", "2h", "1d"
+	RunAt         *time.Time `json:"run_at,omitempty"`          // For scheduled triggers: run once at this time
+	SkipIfRunning bool       `json:"skip_if_running,omitempty"` // Skip a run while the previous one is still executing
+	Keywords      []string   `json:"keywords,omitempty"`        // For message_received triggers: words or phrases, any of which matches
+	Pattern       string     `json:"pattern,omitempty"`         // For message_received triggers: regular expression, matched case-insensitively
+	Stage         string     `json:"stage,omitempty"`           // For message_received triggers: "before_llm" (default) or "after_llm"
+	SkipAIReply   bool       `json:"skip_ai_reply,omitempty"`   // For before_llm triggers: the workflow answers instead of the AI
+}
+
+// MessageStage returns the stage a message_received trigger runs at
+func (c TriggerConfig) MessageStage() string {
+	if c.Stage == "" {
+		return MessageStageBeforeLLM
+	}
+	return c.Stage
+}
+
+// MatchesMessage reports whether a customer message matches the trigger's keywords or pattern.
+// Keywords match whole words, ignoring case and punctuation ("harga?" matches "harga").
+func (c TriggerConfig) MatchesMessage(message string) bool {
+	if len(c.Keywords) > 0 {
+		text := " " + normalizeMessage(message) + " "
+		for _, keyword := range c.Keywords {
+			if keyword = normalizeMessage(keyword); keyword != "" && strings.Contains(text, " "+keyword+" ") {
+				return true
+			}
+		}
+	}
+	if c.Pattern != "" {
+		if re, err := regexp.Compile("(?i)" + c.Pattern); err == nil && re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeMessage lowercases text and reduces it to words separated by single spaces
+func normalizeMessage(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// ValidateMessageTrigger checks that a message_received trigger has something to match and a known stage
+func (c TriggerConfig) ValidateMessageTrigger() error {
+	hasKeyword := false
+	for _, keyword := range c.Keywords {
+		if normalizeMessage(keyword) != "" {
+			hasKeyword = true
+			break
+		}
+	}
+	if !hasKeyword && c.Pattern == "" {
+		return fmt.Errorf("message_received trigger requires keywords or pattern")
+	}
+	if c.Pattern != "" {
+		if _, err := regexp.Compile("(?i)" + c.Pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+
+	switch c.MessageStage() {
+	case MessageStageBeforeLLM:
+	case MessageStageAfterLLM:
+		if c.SkipAIReply {
+			return fmt.Errorf("skip_ai_reply only applies to the before_llm stage")
+		}
+	default:
+		return fmt.Errorf("stage must be before_llm or after_llm")
+	}
+	return nil
+}
+
+// ValidateSchedule checks that exactly one of schedule, interval or run_at is set and valid
+func (c TriggerConfig) ValidateSchedule() error {
+	set := 0
+	if c.Schedule != "" {
+		set++
+	}
+	if c.Interval != "" {
+		set++
+	}
+	if c.RunAt != nil {
+		set++
+	}
+
+	if set == 0 {
+		return fmt.Errorf("scheduled trigger requires one of schedule, interval or run_at")
+	}
+	if set > 1 {
+		return fmt.Errorf("only one of schedule, interval or run_at can be set")
+	}
+
+	if c.Schedule != "" {
+		if _, err := cronParser.Parse(c.Schedule); err != nil {
+			return fmt.Errorf("invalid cron expression: %w", err)
+		}
+	}
+	if c.Interval != "" {
+		if _, err := ParseInterval(c.Interval); err != nil {
+			return err
+		}
+	}
+	if c.RunAt != nil && !c.RunAt.After(time.Now()) {
+		return fmt.Errorf("run_at must be in the future")
+	}
+
+	return nil
+}
+
+// Describe returns a human-readable summary of the schedule
+func (c TriggerConfig) Describe() string {
+	switch {
+	case c.RunAt != nil:
+		return "once at " + c.RunAt.Format(time.RFC3339)
+	case c.Interval != "":
+		if interval, err := ParseInterval(c.Interval); err == nil {
+			return "every " + interval.String()
+		}
+		return c.Interval
+	default:
+		return c.Schedule
+	}
+}
+
+// ValidateTriggerConfig validates a trigger config for the given trigger type
+func ValidateTriggerConfig(triggerType string, config TriggerConfig) error {
+	switch triggerType {
+	case "scheduled":
+		return config.ValidateSchedule()
+	case "event":
+		if config.EventName == "" {
+			return fmt.Errorf("event trigger requires event_name")
+		}
+	case "message_received":
+		return config.ValidateMessageTrigger()
+	}
+	return nil
 }
 
 // Condition represents a single condition to evaluate
 type Condition struct {
-	Field    string      `json:"field"`           // Field to check (e.g., "total_amount", "customer_type")
-	Operator string      `json:"operator"`        // Operator: "equals", "greater_than", "less_than", "contains", etc.
-	Value    interface{} `json:"value"`           // Value to compare against
-	Logic    string      `json:"logic,omitempty"` // "AND" or "OR" (default: "AND")
+	Field    string      `json:"field"`              // Field to check (e.g., "total_amount", "customer_type", or "$now")
+	Operator string      `json:"operator"`           // Operator: "equals", "greater_than", "less_than", "contains", "older_than", etc.
+	Value    interface{} `json:"value"`              // Value to compare against
+	Logic    string      `json:"logic,omitempty"`    // "AND" or "OR" (default: "AND")
+	Timezone string      `json:"timezone,omitempty"` // For time-based operators (default: "Asia/Jakarta")
 }
 
-// Action represents a single action to execute
+// Action represents a single action to execute, or an "if" branch choosing which actions run next
 type Action struct {
-	Type   string                 `json:"type"`   // Action type: "send_whatsapp", "update_database", "call_api", etc.
-	Config map[string]interface{} `json:"config"` // Action-specific configuration
+	ID     string                 `json:"id,omitempty"`    // Names the action; later actions reference its output as {steps.<id>.<field>}
+	Type   string                 `json:"type"`            // Action type: "send_whatsapp", "update_database", "call_api", "if", etc.
+	Config map[string]interface{} `json:"config"`          // Action-specific configuration
+	If     []Condition            `json:"if,omitempty"`    // For "if" actions: conditions on the trigger data and step outputs ("steps.<id>.<field>")
+	Then   []Action               `json:"then,omitempty"`  // For "if" actions: run when the conditions pass
+	Else   []Action               `json:"else,omitempty"`  // For "if" actions: run otherwise
+	Retry  *RetryPolicy           `json:"retry,omitempty"` // Retries the action when it fails
+}
+
+// RetryPolicy retries a failed action, waiting longer before each attempt
+type RetryPolicy struct {
+	MaxAttempts int    `json:"max_attempts"`      // Attempts in total, including the first
+	Backoff     string `json:"backoff,omitempty"` // Wait before the second attempt, doubled before each next one (default "2s")
+}
+
+const (
+	maxRetryAttempts = 5
+	defaultBackoff   = 2 * time.Second
+	maxBackoff       = time.Minute // Longest wait between two attempts
+)
+
+// Attempts returns how many times the action is tried
+func (p *RetryPolicy) Attempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// Delay returns the wait before the given attempt (2 for the first retry)
+func (p *RetryPolicy) Delay(attempt int) time.Duration {
+	delay := defaultBackoff
+	if p != nil && p.Backoff != "" {
+		if backoff, err := time.ParseDuration(p.Backoff); err == nil {
+			delay = backoff
+		}
+	}
+	for i := 2; i < attempt && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
+
+// Validate checks the attempts and backoff are within limits
+func (p *RetryPolicy) Validate() error {
+	if p.MaxAttempts < 1 || p.MaxAttempts > maxRetryAttempts {
+		return fmt.Errorf("retry max_attempts must be between 1 and %d", maxRetryAttempts)
+	}
+	if p.Backoff != "" {
+		backoff, err := time.ParseDuration(p.Backoff)
+		if err != nil || backoff <= 0 || backoff > maxBackoff {
+			return fmt.Errorf("retry backoff must be a duration between 0 and %s, such as \"5s\"", maxBackoff)
+		}
+	}
+	return nil
+}
+
+// ActionTypeIf branches a workflow on conditions
+const ActionTypeIf = "if"
+
+// StepsKey is the context data key holding the outputs of named actions
+const StepsKey = "steps"
+
+const (
+	maxActionDepth = 5  // Nesting of "if" branches
+	maxActionCount = 50 // Actions in a workflow, branches included
+)
+
+// actionIDPattern matches action IDs usable in template names
+var actionIDPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateActions checks action IDs are unique and usable in templates, and that branches have
+// conditions and something to run
+func ValidateActions(actions []Action) error {
+	if len(actions) == 0 {
+		return fmt.Errorf("at least one action is required")
+	}
+	ids := map[string]bool{}
+	count := 0
+	return validateActions(actions, ids, &count, 1)
+}
+
+func validateActions(actions []Action, ids map[string]bool, count *int, depth int) error {
+	if depth > maxActionDepth {
+		return fmt.Errorf("branches can be nested at most %d deep", maxActionDepth)
+	}
+	for _, action := range actions {
+		if *count++; *count > maxActionCount {
+			return fmt.Errorf("a workflow can have at most %d actions", maxActionCount)
+		}
+		if action.Type == "" {
+			return fmt.Errorf("action type is required")
+		}
+		if action.ID != "" {
+			if !actionIDPattern.MatchString(action.ID) {
+				return fmt.Errorf("action id %q must start with a letter and contain only letters, digits and underscores", action.ID)
+			}
+			if ids[action.ID] {
+				return fmt.Errorf("duplicate action id %q", action.ID)
+			}
+			ids[action.ID] = true
+		}
+
+		if action.Type != ActionTypeIf {
+			if len(action.If) > 0 || len(action.Then) > 0 || len(action.Else) > 0 {
+				return fmt.Errorf("%s action cannot have if, then or else (use an if action)", action.Type)
+			}
+			if action.Retry != nil {
+				if err := action.Retry.Validate(); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if action.Retry != nil {
+			return fmt.Errorf("if action cannot have a retry policy")
+		}
+		if len(action.If) == 0 {
+			return fmt.Errorf("if action requires conditions")
+		}
+		if len(action.Then) == 0 && len(action.Else) == 0 {
+			return fmt.Errorf("if action requires then or else actions")
+		}
+		if err := validateActions(action.Then, ids, count, depth+1); err != nil {
+			return err
+		}
+		if err := validateActions(action.Else, ids, count, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecordOutput stores a named action's output in the context data for later actions
+func RecordOutput(contextData map[string]interface{}, id string, output map[string]interface{}) {
+	if id == "" {
+		return
+	}
+	steps, ok := contextData[StepsKey].(map[string]interface{})
+	if !ok {
+		steps = map[string]interface{}{}
+		contextData[StepsKey] = steps
+	}
+	if output == nil {
+		output = map[string]interface{}{}
+	}
+	steps[id] = output
 }
 
 // ExecutionLogEntry represents a single log entry during workflow execution
 type ExecutionLogEntry struct {
 	Timestamp  time.Time   `json:"timestamp"`
-	Step       string      `json:"step"` // "condition_check", "action_execute", etc.
+	Step       string      `json:"step"` // "condition_check", "action_execute", "branch", etc.
 	ActionType string      `json:"action_type,omitempty"`
-	Status     string      `json:"status"` // "success", "failed", "skipped"
+	ActionID   string      `json:"action_id,omitempty"`
+	Path       string      `json:"path,omitempty"`     // Position of the action, e.g. "2.then.1"
+	Attempts   int         `json:"attempts,omitempty"` // Tries of an action with a retry policy
+	Status     string      `json:"status"`             // "success", "failed", "skipped"
 	Message    string      `json:"message"`
 	Error      string      `json:"error,omitempty"`
 	Data       interface{} `json:"data,omitempty"`
@@ -37,7 +339,7 @@ type ExecutionLogEntry struct {
 type CreateWorkflowRequest struct {
 	Name          string        `json:"name" validate:"required"`
 	Description   string        `json:"description"`
-	TriggerType   string        `json:"trigger_type" validate:"required,oneof=event scheduled manual"`
+	TriggerType   string        `json:"trigger_type" validate:"required,oneof=event scheduled manual message_received webhook"`
 	TriggerConfig TriggerConfig `json:"trigger_config" validate:"required"`
 	Conditions    []Condition   `json:"conditions"`
 	Actions       []Action      `json:"actions" validate:"required,min=1"`
@@ -48,13 +350,28 @@ type CreateWorkflowRequest struct {
 type UpdateWorkflowRequest struct {
 	Name          *string        `json:"name"`
 	Description   *string        `json:"description"`
-	TriggerType   *string        `json:"trigger_type" validate:"omitempty,oneof=event scheduled manual"`
+	TriggerType   *string        `json:"trigger_type" validate:"omitempty,oneof=event scheduled manual message_received webhook"`
 	TriggerConfig *TriggerConfig `json:"trigger_config"`
 	Conditions    []Condition    `json:"conditions"`
 	Actions       []Action       `json:"actions" validate:"omitempty,min=1"`
 	IsActive      *bool          `json:"is_active"`
 }
 
+// BulkWorkflowRequest represents the request to enable or disable workflows in bulk
+type BulkWorkflowRequest struct {
+	Action      string   `json:"action" validate:"required,oneof=enable disable"`
+	WorkflowIDs []string `json:"workflow_ids"`    // Workflows to update (ignored when all is true)
+	All         bool     `json:"all"`             // Apply to every workflow of the client
+	Actor       string   `json:"actor,omitempty"` // Who performed the change (defaults to the authenticated user)
+}
+
+// KillSwitchRequest represents the request to pause or resume all automation for a client
+type KillSwitchRequest struct {
+	Paused bool   `json:"paused"`
+	Reason string `json:"reason,omitempty"`
+	Actor  string `json:"actor,omitempty"` // Who flipped the switch (defaults to the authenticated user)
+}
+
 // WorkflowExecutionRequest represents the request to manually execute a workflow
 type WorkflowExecutionRequest struct {
 	TriggerData map[string]interface{} `json:"trigger_data"`
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminProvisioningHandler serves the stable /v1/admin API for infrastructure-as-code tools.
+// Resources are addressed by the caller's reference IDs: PUT creates or updates, PATCH only updates, and both may be repeated safely.
+type AdminProvisioningHandler struct {
+	provisioningService *services.AdminProvisioningService
+}
+
+func NewAdminProvisioningHandler(provisioningService *services.AdminProvisioningService) *AdminProvisioningHandler {
+	return &AdminProvisioningHandler{provisioningService: provisioningService}
+}
+
+// provisionError maps provisioning errors to status codes
+func provisionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrProvisionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrProvisionConflict):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+}
+
+// appliedStatus is 201 for a newly created resource and 200 otherwise
+func appliedStatus(created bool) int {
+	if created {
+		return fiber.StatusCreated
+	}
+	return fiber.StatusOK
+}
+
+// GetClient godoc
+// @Summary Get a provisioned client
+// @Description Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Success 200 {object} models.Client
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref} [get]
+func (h *AdminProvisioningHandler) GetClient(c *fiber.Ctx) error {
+	client, err := h.provisioningService.GetClient(c.Params("ref"))
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(client)
+}
+
+// PutClient godoc
+// @Summary Create or update a client
+// @Description Creates the client with this reference ID (201) or updates it (200); omitted fields keep their value. Set client_id to adopt an existing client. subscription_plan assigns the plan. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param client body services.ProvisionClientRequest true "Desired client state"
+// @Success 200 {object} models.Client
+// @Success 201 {object} models.Client
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref} [put]
+func (h *AdminProvisioningHandler) PutClient(c *fiber.Ctx) error {
+	return h.applyClient(c, true)
+}
+
+// PatchClient godoc
+// @Summary Update a client
+// @Description Updates the fields present in the body. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param client body services.ProvisionClientRequest true "Fields to change"
+// @Success 200 {object} models.Client
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref} [patch]
+func (h *AdminProvisioningHandler) PatchClient(c *fiber.Ctx) error {
+	return h.applyClient(c, false)
+}
+
+func (h *AdminProvisioningHandler) applyClient(c *fiber.Ctx, create bool) error {
+	var req services.ProvisionClientRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	client, created, err := h.provisioningService.ApplyClient(c.Params("ref"), &req, create)
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.Status(appliedStatus(created)).JSON(client)
+}
+
+// GetUser godoc
+// @Summary Get a provisioned CMS user
+// @Description Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param userRef path string true "User reference ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/users/{userRef} [get]
+func (h *AdminProvisioningHandler) GetUser(c *fiber.Ctx) error {
+	user, err := h.provisioningService.GetUser(c.Params("ref"), c.Params("userRef"))
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(user)
+}
+
+// PutUser godoc
+// @Summary Create or update a CMS user
+// @Description Creates the user with this reference ID (201, email and role required) or updates it (200). The password is write-only. Set is_active false to suspend the user. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param userRef path string true "User reference ID"
+// @Param user body services.ProvisionUserRequest true "Desired user state"
+// @Success 200 {object} map[string]interface{}
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/users/{userRef} [put]
+func (h *AdminProvisioningHandler) PutUser(c *fiber.Ctx) error {
+	return h.applyUser(c, true)
+}
+
+// PatchUser godoc
+// @Summary Update a CMS user
+// @Description Updates the fields present in the body. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param userRef path string true "User reference ID"
+// @Param user body services.ProvisionUserRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/users/{userRef} [patch]
+func (h *AdminProvisioningHandler) PatchUser(c *fiber.Ctx) error {
+	return h.applyUser(c, false)
+}
+
+func (h *AdminProvisioningHandler) applyUser(c *fiber.Ctx, create bool) error {
+	var req services.ProvisionUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	user, created, err := h.provisioningService.ApplyUser(c.Params("ref"), c.Params("userRef"), &req, create)
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.Status(appliedStatus(created)).JSON(user)
+}
+
+// GetAPIKey godoc
+// @Summary Get a provisioned API key
+// @Description Key metadata; the key value is only returned when issued. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param keyRef path string true "API key reference ID"
+// @Success 200 {object} models.APIKey
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/api-keys/{keyRef} [get]
+func (h *AdminProvisioningHandler) GetAPIKey(c *fiber.Ctx) error {
+	key, err := h.provisioningService.GetAPIKey(c.Params("ref"), c.Params("keyRef"))
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(fiber.Map{"api_key": key})
+}
+
+// PutAPIKey godoc
+// @Summary Issue or update an API key
+// @Description Issues the key with this reference ID (201) and returns its value once under "key"; store it, it can't be retrieved again. Repeating the call renames it (200) without a new value; a revoked key is reissued (201). Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param keyRef path string true "API key reference ID"
+// @Param key body services.ProvisionAPIKeyRequest false "Desired key state"
+// @Success 200 {object} map[string]interface{}
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/api-keys/{keyRef} [put]
+func (h *AdminProvisioningHandler) PutAPIKey(c *fiber.Ctx) error {
+	return h.applyAPIKey(c, true)
+}
+
+// PatchAPIKey godoc
+// @Summary Update an API key
+// @Description Renames the key; its value doesn't change. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param keyRef path string true "API key reference ID"
+// @Param key body services.ProvisionAPIKeyRequest true "Fields to change"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/api-keys/{keyRef} [patch]
+func (h *AdminProvisioningHandler) PatchAPIKey(c *fiber.Ctx) error {
+	return h.applyAPIKey(c, false)
+}
+
+func (h *AdminProvisioningHandler) applyAPIKey(c *fiber.Ctx, create bool) error {
+	var req services.ProvisionAPIKeyRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	key, secret, err := h.provisioningService.ApplyAPIKey(c.Params("ref"), c.Params("keyRef"), &req, create)
+	if err != nil {
+		return provisionError(c, err)
+	}
+
+	if secret != "" {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"api_key": key, "key": secret})
+	}
+	return c.JSON(fiber.Map{"api_key": key})
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revokes the key; repeating the call is a no-op. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param keyRef path string true "API key reference ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/api-keys/{keyRef} [delete]
+func (h *AdminProvisioningHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	key, err := h.provisioningService.RevokeAPIKey(c.Params("ref"), c.Params("keyRef"))
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(fiber.Map{"api_key": key})
+}
+
+// GetWhatsApp godoc
+// @Summary WhatsApp provisioning status
+// @Description Session, webhook and self-test state of the client. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Success 200 {object} services.OnboardingStatus
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/whatsapp [get]
+func (h *AdminProvisioningHandler) GetWhatsApp(c *fiber.Ctx) error {
+	status, err := h.provisioningService.WhatsAppStatus(c.Params("ref"))
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(status)
+}
+
+// PutWhatsApp godoc
+// @Summary Provision the WhatsApp session and webhook
+// @Description Starts the client's WAHA session and configures its signed webhook unless that is already done for the same session (then nothing changes and "provisioned" is false). Set force to redo it. Requires the X-Admin-Key header.
+// @Tags Admin Provisioning
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param ref path string true "Client reference ID"
+// @Param request body services.ProvisionWhatsAppRequest false "Session"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /v1/admin/clients/{ref}/whatsapp [put]
+func (h *AdminProvisioningHandler) PutWhatsApp(c *fiber.Ctx) error {
+	var req services.ProvisionWhatsAppRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	status, provisioned, err := h.provisioningService.ProvisionWhatsApp(c.Params("ref"), &req)
+	if err != nil {
+		return provisionError(c, err)
+	}
+	return c.JSON(fiber.Map{
+		"provisioned": provisioned,
+		"status":      status,
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AISettingsHandler struct {
+	aiSettingsService *services.AISettingsService
+}
+
+func NewAISettingsHandler(aiSettingsService *services.AISettingsService) *AISettingsHandler {
+	return &AISettingsHandler{aiSettingsService: aiSettingsService}
+}
+
+// GetAISettings godoc
+// @Summary Get a client's AI settings
+// @Description The LLM provider and model, prompt template, tone, temperature and max tokens of the client's bot; empty and null values use the platform default
+// @Tags Clients
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} models.AISettings
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/ai-settings [get]
+func (h *AISettingsHandler) GetAISettings(c *fiber.Ctx) error {
+	settings, err := h.aiSettingsService.GetSettings(c.Params("id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(settings)
+}
+
+// UpdateAISettings godoc
+// @Summary Update a client's AI settings
+// @Description Controls the bot's tone and verbosity. prompt_template replaces the default persona and instructions of the system prompt ({{business_name}} and {{tone}} are filled in); the knowledge base and ordering commands are always included. temperature (0-1) and max_tokens (up to 8192) apply to every AI reply of the client. Omitted fields keep their value; an empty string or 0 restores the platform default.
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param settings body models.UpdateAISettingsRequest true "AI settings"
+// @Success 200 {object} models.AISettings
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /clients/{id}/ai-settings [put]
+func (h *AISettingsHandler) UpdateAISettings(c *fiber.Ctx) error {
+	var req models.UpdateAISettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	settings, err := h.aiSettingsService.UpdateSettings(c.Params("id"), &req)
+	switch {
+	case errors.Is(err, services.ErrInvalidAISettings):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrClientNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to update AI settings: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
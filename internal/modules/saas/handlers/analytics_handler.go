@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AnalyticsHandler struct {
+	mentionService *services.ProductMentionService
+}
+
+func NewAnalyticsHandler(mentionService *services.ProductMentionService) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		mentionService: mentionService,
+	}
+}
+
+// GetProductDemand godoc
+// @Summary Most asked but least sold products
+// @Description Products customers ask about in WhatsApp conversations compared with paid sales, lowest conversion first (test orders excluded)
+// @Tags Analytics
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param period query string false "today, yesterday, this_week, last_week, this_month, last_month, this_year, last_30_days, last_90_days" default(last_30_days)
+// @Param min_mentions query int false "Only products mentioned at least this many times" default(3)
+// @Param limit query int false "Limit results" default(20)
+// @Success 200 {object} models.ProductDemandReport
+// @Router /analytics/product-demand [get]
+func (h *AnalyticsHandler) GetProductDemand(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	report, err := h.mentionService.GetDemandReport(clientID, c.Query("period"), c.QueryInt("min_mentions", 3), c.QueryInt("limit", 20))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(report)
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIVersion is the current (and only) major version of the REST API
+const APIVersion = "v1"
+
+// LegacyAPIDeprecatedAt is when the unprefixed paths were superseded by /v1
+var LegacyAPIDeprecatedAt = time.Date(2026, time.October, 16, 0, 0, 0, 0, time.UTC)
+
+// VersionedRouter registers every route under /v1 and, during the deprecation window,
+// again at its legacy unprefixed path with Deprecation/Sunset headers. Routes are recorded in
+// the route registry with the access policy declared through Require.
+type VersionedRouter struct {
+	v1       fiber.Router
+	legacy   fiber.Router  // nil once legacy aliases are switched off
+	mark     fiber.Handler // Adds the deprecation headers on legacy routes
+	marked   bool          // The legacy group already runs mark as middleware
+	prefix   string        // Full path of the group
+	base     string        // Path of the v1 side, "/v1" ("" for unversioned routes)
+	registry *RouteRegistry
+	policy   *RoutePolicy // Access policy of the routes, nil until declared
+}
+
+// NewVersionedRouter creates the /v1 router; legacy aliases are only served when legacyEnabled
+func NewVersionedRouter(app *fiber.App, registry *RouteRegistry, legacyEnabled bool, sunset time.Time) *VersionedRouter {
+	r := &VersionedRouter{
+		v1: app.Group("/"+APIVersion, func(c *fiber.Ctx) error {
+			c.Set("API-Version", APIVersion)
+			return c.Next()
+		}),
+		base:     "/" + APIVersion,
+		registry: registry,
+	}
+	if legacyEnabled {
+		r.legacy = app
+		r.mark = DeprecatedRoute(sunset)
+	}
+	return r
+}
+
+// NewUnversionedRouter creates a router for the paths outside the versioned API (webhooks, public links,
+// health checks), so they are recorded in the route registry too
+func NewUnversionedRouter(app *fiber.App, registry *RouteRegistry) *VersionedRouter {
+	return &VersionedRouter{v1: app, registry: registry}
+}
+
+// DeprecatedRoute marks a legacy path as deprecated (RFC 9745), announces its removal
+// date (RFC 8594) and links to the /v1 path that replaces it
+func DeprecatedRoute(sunset time.Time) fiber.Handler {
+	deprecation := fmt.Sprintf("@%d", LegacyAPIDeprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", deprecation)
+		c.Set("Sunset", sunsetDate)
+		c.Set("API-Version", APIVersion)
+		c.Set("Link", fmt.Sprintf(`</%s%s>; rel="successor-version"`, APIVersion, c.Path()))
+		return c.Next()
+	}
+}
+
+// V1 returns the /v1 side only, for routes that never had a legacy path
+func (r *VersionedRouter) V1() *VersionedRouter {
+	v1 := *r
+	v1.legacy = nil
+	return &v1
+}
+
+// Require returns the router with the access policy of the routes registered through it
+func (r *VersionedRouter) Require(policy *RoutePolicy) *VersionedRouter {
+	required := *r
+	required.policy = policy
+	return &required
+}
+
+// Group creates a versioned sub-group; the handlers run for every route in it
+func (r *VersionedRouter) Group(prefix string, handlers ...fiber.Handler) *VersionedRouter {
+	group := &VersionedRouter{
+		v1:       r.v1.Group(prefix, handlers...),
+		mark:     r.mark,
+		marked:   true,
+		prefix:   r.prefix + prefix,
+		base:     r.base,
+		registry: r.registry,
+		policy:   r.policy,
+	}
+	if r.legacy == nil {
+		return group
+	}
+	if r.marked {
+		group.legacy = r.legacy.Group(prefix, handlers...)
+	} else {
+		// Mark first, so responses rejected by the group's own middleware (e.g. 401) carry the headers too
+		group.legacy = r.legacy.Group(prefix, append([]fiber.Handler{group.scopedMark()}, handlers...)...)
+	}
+	return group
+}
+
+// Get registers a GET route
+func (r *VersionedRouter) Get(path string, handlers ...fiber.Handler) *VersionedRouter {
+	return r.add(fiber.MethodGet, path, handlers)
+}
+
+// Post registers a POST route
+func (r *VersionedRouter) Post(path string, handlers ...fiber.Handler) *VersionedRouter {
+	return r.add(fiber.MethodPost, path, handlers)
+}
+
+// Put registers a PUT route
+func (r *VersionedRouter) Put(path string, handlers ...fiber.Handler) *VersionedRouter {
+	return r.add(fiber.MethodPut, path, handlers)
+}
+
+// Patch registers a PATCH route
+func (r *VersionedRouter) Patch(path string, handlers ...fiber.Handler) *VersionedRouter {
+	return r.add(fiber.MethodPatch, path, handlers)
+}
+
+// Delete registers a DELETE route
+func (r *VersionedRouter) Delete(path string, handlers ...fiber.Handler) *VersionedRouter {
+	return r.add(fiber.MethodDelete, path, handlers)
+}
+
+// scopedMark runs mark only within the group: group middleware is matched by plain
+// string prefix, so "/upload" would otherwise also catch "/uploads"
+func (r *VersionedRouter) scopedMark() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		path := c.Path()
+		if path == r.prefix || strings.HasPrefix(path, r.prefix+"/") {
+			return r.mark(c)
+		}
+		return c.Next()
+	}
+}
+
+func (r *VersionedRouter) add(method, path string, handlers []fiber.Handler) *VersionedRouter {
+	if r.policy == nil {
+		panic(fmt.Sprintf("route %s %s declares no access policy (use Require)", method, r.prefix+path))
+	}
+
+	if r.registry.stub != nil && len(handlers) > 0 {
+		handlers = append(handlers[:len(handlers)-1:len(handlers)-1], r.registry.stub)
+	}
+
+	// The policy runs before the handlers
+	chain := make([]fiber.Handler, 0, len(r.policy.Middleware)+len(handlers))
+	chain = append(chain, r.policy.Middleware...)
+	handlers = append(chain, handlers...)
+
+	r.v1.Add(method, path, handlers...)
+	r.registry.record(method, joinRoutePath(r.base+r.prefix, path), r.policy)
+	if r.legacy != nil {
+		r.registry.record(method, joinRoutePath(r.prefix, path), r.policy)
+	}
+
+	switch {
+	case r.legacy == nil:
+	case r.marked:
+		r.legacy.Add(method, path, handlers...)
+	default:
+		// Top-level routes are marked one by one so the headers never leak onto
+		// unversioned paths (webhooks, public links) through a root middleware
+		r.legacy.Add(method, path, append([]fiber.Handler{r.mark}, handlers...)...)
+	}
+	return r
+}
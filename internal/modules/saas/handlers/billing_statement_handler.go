@@ -0,0 +1,251 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type BillingStatementHandler struct {
+	statementService *services.BillingStatementService
+}
+
+func NewBillingStatementHandler(statementService *services.BillingStatementService) *BillingStatementHandler {
+	return &BillingStatementHandler{statementService: statementService}
+}
+
+// ListStatements godoc
+// @Summary List billing statements
+// @Description The client's monthly statements newest first: the plan fee, the messages, estimated AI tokens and storage used above the plan limits, the total (IDR), the PDF and payment links and the payment status (unpaid, paid or void).
+// @Tags Subscription
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "unpaid, paid or void"
+// @Param limit query int false "Max statements (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /billing/statements [get]
+func (h *BillingStatementHandler) ListStatements(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	statements, err := h.statementService.List(models.StatementFilter{
+		ClientID: &clientID,
+		Status:   c.Query("status"),
+		Limit:    c.QueryInt("limit", 100),
+	})
+	if err != nil {
+		log.Printf("❌ Failed to list statements for client %s: %v", clientID, err)
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list statements"})
+	}
+
+	return c.JSON(fiber.Map{
+		"statements": statements,
+		"count":      len(statements),
+	})
+}
+
+// GetStatement godoc
+// @Summary Get a billing statement
+// @Description One of the client's monthly statements with its usage lines.
+// @Tags Subscription
+// @Produce json
+// @Param id path string true "Statement ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.BillingStatement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /billing/statements/{id} [get]
+func (h *BillingStatementHandler) GetStatement(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid statement id"})
+	}
+
+	statement, err := h.statementService.Get(id)
+	if errors.Is(err, services.ErrStatementNotFound) || (err == nil && statement.ClientID != clientID) {
+		return c.Status(404).JSON(fiber.Map{"error": services.ErrStatementNotFound.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(statement)
+}
+
+// ListAllStatements godoc
+// @Summary List billing statements of all tenants
+// @Description Monthly statements of every tenant newest first, e.g. to follow up unpaid ones. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param client_id query string false "Client ID"
+// @Param status query string false "unpaid, paid or void"
+// @Param limit query int false "Max statements (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/billing/statements [get]
+func (h *BillingStatementHandler) ListAllStatements(c *fiber.Ctx) error {
+	filter := models.StatementFilter{
+		Status: c.Query("status"),
+		Limit:  c.QueryInt("limit", 100),
+	}
+	if c.Query("client_id") != "" {
+		clientID, err := uuid.Parse(c.Query("client_id"))
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client_id"})
+		}
+		filter.ClientID = &clientID
+	}
+
+	statements, err := h.statementService.List(filter)
+	if err != nil {
+		log.Printf("❌ Failed to list statements: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list statements"})
+	}
+
+	return c.JSON(fiber.Map{
+		"statements": statements,
+		"count":      len(statements),
+	})
+}
+
+// GenerateStatements godoc
+// @Summary Generate billing statements
+// @Description Builds, emails and records the statements of an ended month for one or every active tenant. Statements are issued automatically after each month ends; this rebuilds unpaid or void ones with fresh usage, e.g. after correcting a plan. Paid statements are kept. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param request body models.GenerateStatementsRequest false "Month and client"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/billing/statements/generate [post]
+func (h *BillingStatementHandler) GenerateStatements(c *fiber.Ctx) error {
+	var req models.GenerateStatementsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	var clientID *uuid.UUID
+	if req.ClientID != "" {
+		id, err := uuid.Parse(req.ClientID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client_id"})
+		}
+		clientID = &id
+	}
+
+	statements, err := h.statementService.Generate(clientID, req.Period)
+	switch {
+	case errors.Is(err, services.ErrInvalidStatementPeriod):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrClientNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to generate statements: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"statements": statements,
+		"count":      len(statements),
+	})
+}
+
+// MarkStatementPaid godoc
+// @Summary Mark a billing statement paid
+// @Description Records a payment received outside the payment gateway, e.g. a bank transfer, and cancels the statement's payment link. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Statement ID"
+// @Param request body models.MarkStatementPaidRequest false "Payment method and reference"
+// @Success 200 {object} models.BillingStatement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/billing/statements/{id}/mark-paid [post]
+func (h *BillingStatementHandler) MarkStatementPaid(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid statement id"})
+	}
+	var req models.MarkStatementPaidRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	statement, err := h.statementService.MarkPaid(id, &req)
+	if err != nil {
+		return statementError(c, err)
+	}
+	return c.JSON(statement)
+}
+
+// VoidStatement godoc
+// @Summary Void a billing statement
+// @Description Cancels an unpaid statement, e.g. one issued in error, and its payment link. Generate the month again to issue a corrected one. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Statement ID"
+// @Param request body models.VoidStatementRequest true "Reason"
+// @Success 200 {object} models.BillingStatement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/billing/statements/{id}/void [post]
+func (h *BillingStatementHandler) VoidStatement(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid statement id"})
+	}
+	var req models.VoidStatementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+	}
+	if req.Reason == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "reason is required"})
+	}
+
+	statement, err := h.statementService.Void(id, req.Reason)
+	if err != nil {
+		return statementError(c, err)
+	}
+	return c.JSON(statement)
+}
+
+// statementError maps statement errors to HTTP statuses
+func statementError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrStatementNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrStatementNotUnpaid):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Printf("❌ Billing statement error: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+}
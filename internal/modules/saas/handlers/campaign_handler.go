@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CampaignHandler struct {
+	campaignService *services.CampaignService
+}
+
+func NewCampaignHandler(campaignService *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{
+		campaignService: campaignService,
+	}
+}
+
+// ListCampaigns godoc
+// @Summary List campaigns
+// @Description Broadcast campaigns of a client, newest first
+// @Tags Campaigns
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "Status (draft, scheduled, sending, completed, cancelled, failed)"
+// @Param limit query int false "Max campaigns (default 50, max 200)"
+// @Success 200 {array} models.Campaign
+// @Failure 400 {object} map[string]interface{}
+// @Router /campaigns [get]
+func (h *CampaignHandler) ListCampaigns(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	campaigns, err := h.campaignService.List(clientID, c.Query("status"), c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(campaigns)
+}
+
+// CreateCampaign godoc
+// @Summary Create a campaign
+// @Description Create a broadcast message for all customers, customers who ordered in the last N days, customers with given chat tags, or the members of a customer segment. With scheduled_at the campaign is scheduled, otherwise it stays a draft until scheduled. Recipients are resolved when sending starts and messaged one at a time, throttle_seconds apart.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param campaign body models.CampaignRequest true "Campaign"
+// @Success 201 {object} models.Campaign
+// @Failure 400 {object} map[string]interface{}
+// @Router /campaigns [post]
+func (h *CampaignHandler) CreateCampaign(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	campaign, err := h.campaignService.Create(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(campaign)
+}
+
+// PreviewCampaignAudience godoc
+// @Summary Preview a campaign audience
+// @Description Count the customers a campaign's audience filters would reach right now. Name and message are not needed.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param campaign body models.CampaignRequest true "Audience filters"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /campaigns/audience [post]
+func (h *CampaignHandler) PreviewCampaignAudience(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	count, err := h.campaignService.PreviewAudience(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"recipients": count,
+	})
+}
+
+// GetCampaign godoc
+// @Summary Get a campaign
+// @Description A campaign with its recipients counted per delivery status (pending, sent, delivered, read, failed, skipped)
+// @Tags Campaigns
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} models.Campaign
+// @Failure 404 {object} map[string]interface{}
+// @Router /campaigns/{id} [get]
+func (h *CampaignHandler) GetCampaign(c *fiber.Ctx) error {
+	clientID, id, err := campaignParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	campaign, err := h.campaignService.Get(clientID, id)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(campaign)
+}
+
+// UpdateCampaign godoc
+// @Summary Update a campaign
+// @Description Change a draft or scheduled campaign. Without scheduled_at a scheduled campaign goes back to draft.
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Campaign ID"
+// @Param campaign body models.CampaignRequest true "Campaign"
+// @Success 200 {object} models.Campaign
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /campaigns/{id} [put]
+func (h *CampaignHandler) UpdateCampaign(c *fiber.Ctx) error {
+	clientID, id, err := campaignParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req models.CampaignRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	campaign, err := h.campaignService.Update(clientID, id, &req)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(campaign)
+}
+
+// ScheduleCampaign godoc
+// @Summary Schedule a campaign
+// @Description Queue a draft campaign for sending at scheduled_at, or right away without it
+// @Tags Campaigns
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Campaign ID"
+// @Param schedule body models.ScheduleCampaignRequest false "Send time"
+// @Success 200 {object} models.Campaign
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /campaigns/{id}/schedule [post]
+func (h *CampaignHandler) ScheduleCampaign(c *fiber.Ctx) error {
+	clientID, id, err := campaignParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req models.ScheduleCampaignRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	campaign, err := h.campaignService.Schedule(clientID, id, req.ScheduledAt)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(campaign)
+}
+
+// CancelCampaign godoc
+// @Summary Cancel a campaign
+// @Description Cancel a draft, scheduled or sending campaign. Sending stops after the current recipient; customers not reached yet are marked skipped.
+// @Tags Campaigns
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Campaign ID"
+// @Success 200 {object} models.Campaign
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /campaigns/{id}/cancel [post]
+func (h *CampaignHandler) CancelCampaign(c *fiber.Ctx) error {
+	clientID, id, err := campaignParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	campaign, err := h.campaignService.Cancel(clientID, id)
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(campaign)
+}
+
+// ListCampaignRecipients godoc
+// @Summary List campaign recipients
+// @Description Customers of a campaign with their delivery status, in sending order
+// @Tags Campaigns
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Campaign ID"
+// @Param status query string false "Delivery status (pending, sent, delivered, read, failed, skipped)"
+// @Param limit query int false "Max recipients (default 200, max 1000)"
+// @Success 200 {array} models.CampaignRecipient
+// @Failure 404 {object} map[string]interface{}
+// @Router /campaigns/{id}/recipients [get]
+func (h *CampaignHandler) ListCampaignRecipients(c *fiber.Ctx) error {
+	clientID, id, err := campaignParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	recipients, err := h.campaignService.ListRecipients(clientID, id, c.Query("status"), c.QueryInt("limit", 200))
+	if err != nil {
+		return campaignError(c, err)
+	}
+
+	return c.JSON(recipients)
+}
+
+func campaignParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("client_id is required")
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid campaign id")
+	}
+	return clientID, id, nil
+}
+
+func campaignError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrCampaignNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrCampaignNotEditable):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
@@ -8,12 +8,14 @@ import (
 )
 
 type CartHandler struct {
-	cartService *services.CartService
+	cartService   *services.CartService
+	branchService *services.BranchService
 }
 
-func NewCartHandler(cartService *services.CartService) *CartHandler {
+func NewCartHandler(cartService *services.CartService, branchService *services.BranchService) *CartHandler {
 	return &CartHandler{
-		cartService: cartService,
+		cartService:   cartService,
+		branchService: branchService,
 	}
 }
 
@@ -171,6 +173,43 @@ func (h *CartHandler) ViewCart(c *fiber.Ctx) error {
 	})
 }
 
+// SelectBranch godoc
+// @Summary Select checkout branch
+// @Description Choose the branch the cart will be fulfilled from (multi-branch tenants)
+// @Tags Cart
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param customer_phone query string true "Customer Phone"
+// @Param data body object{branch_id=string} true "Branch (store) ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /cart/branch [put]
+func (h *CartHandler) SelectBranch(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	customerPhone := c.Query("customer_phone")
+
+	if clientID == "" || customerPhone == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id and customer_phone are required"})
+	}
+
+	var req struct {
+		BranchID string `json:"branch_id"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.BranchID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "branch_id is required"})
+	}
+
+	cart, err := h.branchService.SelectCartBranch(clientID, customerPhone, req.BranchID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Branch selected",
+		"cart":    cart,
+	})
+}
+
 // ClearCart godoc
 // @Summary Clear shopping cart
 // @Description Remove all items from the cart
@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"hash"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// cloudAPIAcks maps Cloud API message statuses to WAHA ack levels, which campaign tracking uses
+var cloudAPIAcks = map[string]int{
+	"failed":    -1,
+	"sent":      1,
+	"delivered": 2,
+	"read":      3,
+}
+
+// CloudAPIWebhookHandler receives the webhook of the WhatsApp Cloud API (Meta)
+type CloudAPIWebhookHandler struct {
+	webhookService *services.WebhookService
+	waService      *whatsapp.Service
+	bodyReader     *WebhookBodyReader
+	verifier       WebhookVerifier // nil when no app secret is configured
+	verifyToken    string
+	allowUnsigned  bool
+}
+
+// NewCloudAPIWebhookHandler creates the Cloud API webhook handler. Notifications are only accepted when
+// signed with appSecret, unless allowUnsigned is set.
+func NewCloudAPIWebhookHandler(webhookService *services.WebhookService, waService *whatsapp.Service, bodyReader *WebhookBodyReader, appSecret, verifyToken string, allowUnsigned bool) *CloudAPIWebhookHandler {
+	h := &CloudAPIWebhookHandler{
+		webhookService: webhookService,
+		waService:      waService,
+		bodyReader:     bodyReader,
+		verifyToken:    verifyToken,
+		allowUnsigned:  allowUnsigned,
+	}
+	if appSecret != "" {
+		h.verifier = &cloudAPIVerifier{appSecret: []byte(appSecret)}
+	}
+	return h
+}
+
+// CloudAPIWebhookPayload is a Cloud API change notification
+type CloudAPIWebhookPayload struct {
+	Object string `json:"object"` // "whatsapp_business_account"
+	Entry  []struct {
+		ID      string `json:"id"` // WhatsApp Business Account ID
+		Changes []struct {
+			Field string        `json:"field"` // "messages"
+			Value CloudAPIValue `json:"value"`
+		} `json:"changes"`
+	} `json:"entry"`
+}
+
+// CloudAPIValue carries the messages received and the statuses of messages sent on one phone number
+type CloudAPIValue struct {
+	Metadata struct {
+		DisplayPhoneNumber string `json:"display_phone_number"`
+		PhoneNumberID      string `json:"phone_number_id"`
+	} `json:"metadata"`
+	Messages []CloudAPIMessage `json:"messages"`
+	Statuses []struct {
+		ID          string `json:"id"`
+		Status      string `json:"status"` // sent, delivered, read, failed
+		RecipientID string `json:"recipient_id"`
+	} `json:"statuses"`
+}
+
+// CloudAPIMessage is an incoming Cloud API message; the field matching Type is set
+type CloudAPIMessage struct {
+	ID        string `json:"id"`
+	From      string `json:"from"` // Phone number without +
+	Timestamp string `json:"timestamp"`
+	Type      string `json:"type"` // text, image, audio, location, interactive, button, reaction, ...
+	Context   *struct {
+		ID   string `json:"id"`   // Quoted message
+		From string `json:"from"` // Sender of the quoted message
+	} `json:"context"`
+	Text *struct {
+		Body string `json:"body"`
+	} `json:"text"`
+	Image    *CloudAPIMedia `json:"image"`
+	Audio    *CloudAPIMedia `json:"audio"`
+	Location *struct {
+		Latitude  float64 `json:"latitude"`
+		Longitude float64 `json:"longitude"`
+		Name      string  `json:"name"`
+		Address   string  `json:"address"`
+	} `json:"location"`
+	Interactive *struct {
+		Type        string `json:"type"` // button_reply or list_reply
+		ButtonReply *struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"button_reply"`
+		ListReply *struct {
+			ID          string `json:"id"`
+			Title       string `json:"title"`
+			Description string `json:"description"`
+		} `json:"list_reply"`
+	} `json:"interactive"`
+	Button *struct {
+		Text    string `json:"text"`
+		Payload string `json:"payload"`
+	} `json:"button"` // Quick reply of a template message
+	Reaction *struct {
+		MessageID string `json:"message_id"`
+		Emoji     string `json:"emoji"`
+	} `json:"reaction"`
+}
+
+// CloudAPIMedia is media of an incoming message, downloaded by its ID
+type CloudAPIMedia struct {
+	ID       string `json:"id"`
+	MimeType string `json:"mime_type"`
+	Caption  string `json:"caption"`
+}
+
+// VerifyWebhook godoc
+// @Summary Cloud API webhook verification
+// @Description Answers Meta's subscription check: echoes hub.challenge when hub.verify_token matches CLOUDAPI_VERIFY_TOKEN
+// @Tags Webhook
+// @Produce plain
+// @Param hub.mode query string true "subscribe"
+// @Param hub.verify_token query string true "Verify token set in the Meta app"
+// @Param hub.challenge query string true "Challenge to echo"
+// @Success 200 {string} string "The challenge"
+// @Failure 403 {object} map[string]interface{}
+// @Router /webhooks/whatsapp-cloud [get]
+func (h *CloudAPIWebhookHandler) VerifyWebhook(c *fiber.Ctx) error {
+	token := c.Query("hub.verify_token")
+	if c.Query("hub.mode") != "subscribe" || h.verifyToken == "" ||
+		subtle.ConstantTimeCompare([]byte(token), []byte(h.verifyToken)) != 1 {
+		log.Printf("⚠️ Cloud API webhook verification rejected from %s", c.IP())
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "verification failed",
+		})
+	}
+
+	log.Printf("✅ Cloud API webhook verified")
+	return c.SendString(c.Query("hub.challenge"))
+}
+
+// ReceiveWebhook godoc
+// @Summary Cloud API webhook receiver
+// @Description Receive WhatsApp Cloud API change notifications. Messages (text, button and list replies, images, voice notes, locations, reactions) are processed like WAHA messages; the phone number ID is used as the session. Delivery statuses update campaign tracking. Requests must carry a valid X-Hub-Signature-256 of the body, signed with CLOUDAPI_APP_SECRET.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param X-Hub-Signature-256 header string true "HMAC-SHA256 of the body (sha256=...)"
+// @Param payload body CloudAPIWebhookPayload true "Change notification"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Router /webhooks/whatsapp-cloud [post]
+func (h *CloudAPIWebhookHandler) ReceiveWebhook(c *fiber.Ctx) error {
+	defer observeWebhookRequest(c, "cloudapi_webhook")()
+
+	signed := h.verifier != nil && h.verifier.Signed(c)
+	if !signed && !h.allowUnsigned {
+		log.Printf("⚠️ Unsigned Cloud API webhook rejected from %s", c.IP())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "missing signature",
+		})
+	}
+
+	var mac hash.Hash
+	if signed {
+		mac = h.verifier.MAC()
+	}
+	body, err := h.bodyReader.Read(c, "cloudapi", mac)
+	if err != nil {
+		if errors.Is(err, errWebhookBodyTooLarge) {
+			log.Printf("⚠️ Cloud API webhook payload rejected: %v", err)
+			return h.bodyReader.TooLarge(c)
+		}
+		log.Printf("❌ Failed to read Cloud API webhook body: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payload",
+		})
+	}
+
+	if signed && !h.verifier.Verify(c, mac) {
+		log.Printf("⚠️ Invalid cloudapi webhook signature from %s", c.IP())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid signature",
+		})
+	}
+
+	var payload CloudAPIWebhookPayload
+	if err := json.Unmarshal(body.JSON, &payload); err != nil {
+		log.Printf("❌ Failed to parse Cloud API webhook: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payload",
+		})
+	}
+
+	received := 0
+	for _, entry := range payload.Entry {
+		for _, change := range entry.Changes {
+			if change.Field != "messages" {
+				continue
+			}
+			for _, status := range change.Value.Statuses {
+				if ack, ok := cloudAPIAcks[status.Status]; ok {
+					h.webhookService.Go(func() {
+						h.webhookService.ProcessMessageAck(change.Value.Metadata.PhoneNumberID, status.ID, ack)
+					})
+				}
+			}
+			for i := range change.Value.Messages {
+				if h.handleMessage(c, change.Value.Metadata.PhoneNumberID, &change.Value.Messages[i]) {
+					received++
+				}
+			}
+		}
+	}
+
+	return c.JSON(fiber.Map{"status": "received", "messages": received})
+}
+
+// handleMessage routes one Cloud API message by type, reporting whether it was taken for processing
+func (h *CloudAPIWebhookHandler) handleMessage(c *fiber.Ctx, session string, msg *CloudAPIMessage) bool {
+	phoneNumber := msg.From
+	if phoneNumber == "" {
+		return false
+	}
+
+	// Meta retries notifications it considers undelivered; handle each message once
+	if h.webhookService.IsDuplicateMessage(session, msg.ID) {
+		log.Printf("⏭️ Skipping duplicate message %s", msg.ID)
+		return false
+	}
+
+	ref := services.MessageRef{ID: msg.ID}
+	if msg.Context != nil && msg.Context.ID != "" {
+		ref.ReplyToID = msg.Context.ID
+		ref.ReplyToFromMe = msg.Context.From != "" && msg.Context.From != msg.From
+	}
+
+	ctx := c.UserContext()
+	switch msg.Type {
+	case "text":
+		if msg.Text == nil || msg.Text.Body == "" {
+			return false
+		}
+		log.Printf("✅ Cloud API text message from %s: %s", phoneNumber, msg.Text.Body)
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessTextMessage(ctx, session, phoneNumber, msg.Text.Body, ref)
+		})
+
+	case "interactive":
+		// Button taps are answered by their ID, which the cart buttons are matched on
+		reply := ""
+		if in := msg.Interactive; in != nil && in.ButtonReply != nil {
+			reply = in.ButtonReply.ID
+			if reply == "" {
+				reply = in.ButtonReply.Title
+			}
+		} else if in != nil && in.ListReply != nil {
+			reply = in.ListReply.Title
+		}
+		if reply == "" {
+			return false
+		}
+		log.Printf("🔘 Cloud API interactive reply from %s: %s", phoneNumber, reply)
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessTextMessage(ctx, session, phoneNumber, reply, ref)
+		})
+
+	case "button":
+		if msg.Button == nil || msg.Button.Text == "" {
+			return false
+		}
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessTextMessage(ctx, session, phoneNumber, msg.Button.Text, ref)
+		})
+
+	case "image":
+		if msg.Image == nil || msg.Image.ID == "" {
+			return false
+		}
+		log.Printf("📸 Cloud API image message from %s", phoneNumber)
+		h.webhookService.Go(func() {
+			if mediaURL := h.storeMedia(msg.Image.ID); mediaURL != "" {
+				h.webhookService.ProcessImageMessage(ctx, session, phoneNumber, mediaURL)
+			}
+		})
+
+	case "audio":
+		if msg.Audio == nil || msg.Audio.ID == "" {
+			return false
+		}
+		log.Printf("🎤 Cloud API voice note from %s", phoneNumber)
+		h.webhookService.Go(func() {
+			if mediaURL := h.storeMedia(msg.Audio.ID); mediaURL != "" {
+				h.webhookService.ProcessVoiceMessage(ctx, session, phoneNumber, mediaURL, msg.Audio.MimeType, ref)
+			}
+		})
+
+	case "location":
+		if msg.Location == nil {
+			return false
+		}
+		log.Printf("📍 Cloud API location from %s: %.6f,%.6f", phoneNumber, msg.Location.Latitude, msg.Location.Longitude)
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessLocationMessage(session, phoneNumber, msg.Location.Latitude, msg.Location.Longitude)
+		})
+
+	case "reaction":
+		if msg.Reaction == nil || msg.Reaction.Emoji == "" {
+			return false
+		}
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessReaction(session, phoneNumber, msg.Reaction.Emoji, msg.Reaction.MessageID)
+		})
+
+	default:
+		log.Printf("⏭️ Skipping Cloud API %s message from %s", msg.Type, phoneNumber)
+		return false
+	}
+	return true
+}
+
+// storeMedia downloads Cloud API media, whose URLs need the access token, and stores it for processing
+func (h *CloudAPIWebhookHandler) storeMedia(mediaID string) string {
+	data, err := h.waService.DownloadMedia(mediaID)
+	if err != nil {
+		log.Printf("⚠️ Failed to download Cloud API media %s: %v", mediaID, err)
+		return ""
+	}
+	return h.bodyReader.storeMedia(bytes.NewReader(data))
+}
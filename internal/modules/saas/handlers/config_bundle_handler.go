@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ConfigBundleHandler struct {
+	bundleService *services.ConfigBundleService
+}
+
+func NewConfigBundleHandler(bundleService *services.ConfigBundleService) *ConfigBundleHandler {
+	return &ConfigBundleHandler{
+		bundleService: bundleService,
+	}
+}
+
+// ExportConfig godoc
+// @Summary Export a client's bot configuration
+// @Description Portable bundle of the knowledge base, workflows, conversation tags, tone and feature settings. Secrets are left out: WhatsApp session IDs are dropped and credential headers of call_api actions are blanked (listed under "redacted").
+// @Tags Clients
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param download query bool false "Send as a file attachment"
+// @Success 200 {object} services.ConfigBundle
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/config/export [get]
+func (h *ConfigBundleHandler) ExportConfig(c *fiber.Ctx) error {
+	clientID := c.Params("id")
+
+	bundle, err := h.bundleService.Export(clientID)
+	if err != nil {
+		if errors.Is(err, services.ErrClientNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if c.QueryBool("download") {
+		c.Attachment(fmt.Sprintf("bot-config-%s.json", clientID))
+	}
+	return c.JSON(bundle)
+}
+
+// ImportConfig godoc
+// @Summary Import a bot configuration into a client
+// @Description Apply a bundle from GET /clients/{id}/config/export, e.g. to copy one tenant's bot to another. Knowledge base entries are matched by title, workflows and tags by name. Mode "skip" (default) only adds what the client lacks and keeps its tone and settings, "overwrite" lets the bundle win on conflicts, "replace" also removes what the bundle doesn't have. Use dry_run to preview the changes.
+// @Tags Clients
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param request body services.ConfigImportRequest true "Bundle and conflict options"
+// @Success 200 {object} services.ConfigImportResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/config/import [post]
+func (h *ConfigBundleHandler) ImportConfig(c *fiber.Ctx) error {
+	var req services.ConfigImportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	result, err := h.bundleService.Import(c.Context(), c.Params("id"), &req)
+	if err != nil {
+		if errors.Is(err, services.ErrClientNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
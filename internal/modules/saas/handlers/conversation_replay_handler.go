@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ConversationReplayHandler struct {
+	replayService *services.ConversationReplayService
+}
+
+func NewConversationReplayHandler(replayService *services.ConversationReplayService) *ConversationReplayHandler {
+	return &ConversationReplayHandler{replayService: replayService}
+}
+
+// StartConversationReplay godoc
+// @Summary Replay historical conversations
+// @Description Answers the client's historical customer messages again with the current knowledge base, prompt and LLM (or the provider/model in the body), in a sandbox: nothing is sent to customers and cart commands are not run. Each answer gets the chat history it had originally. Runs in the background; the report compares new and original answers with latency and estimated cost. Makes real LLM calls; one replay per client at a time. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param from query string false "Start date (YYYY-MM-DD)"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive)"
+// @Param request body services.ConversationReplayRequest false "Customer, message limit and LLM"
+// @Success 202 {object} models.ConversationReplay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/clients/{id}/conversation-replays [post]
+func (h *ConversationReplayHandler) StartConversationReplay(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req services.ConversationReplayRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	replay, err := h.replayService.Start(clientID, from, to, &req)
+	if errors.Is(err, services.ErrConversationReplayRunning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to start conversation replay of client %s: %v", clientID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(replay)
+}
+
+// GetConversationReplays godoc
+// @Summary Conversation replays of a client
+// @Description Replay reports of the client newest first, with their totals but without the answers. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param limit query int false "Max replays (default 20, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/clients/{id}/conversation-replays [get]
+func (h *ConversationReplayHandler) GetConversationReplays(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	replays, err := h.replayService.History(clientID, c.QueryInt("limit", 20))
+	if err != nil {
+		log.Printf("❌ Failed to list conversation replays of client %s: %v", clientID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list conversation replays"})
+	}
+
+	return c.JSON(fiber.Map{
+		"replays": replays,
+	})
+}
+
+// GetConversationReplay godoc
+// @Summary Conversation replay report
+// @Description A replay with its totals (changed answers, average similarity, latency, estimated cost) and the original and new answer of every message. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Replay ID"
+// @Param changed query bool false "Only messages whose answer changed"
+// @Success 200 {object} models.ConversationReplay
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/conversation-replays/{id} [get]
+func (h *ConversationReplayHandler) GetConversationReplay(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid replay id"})
+	}
+
+	replay, err := h.replayService.Get(id)
+	if errors.Is(err, services.ErrConversationReplayNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to load conversation replay %s: %v", id, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to load conversation replay"})
+	}
+
+	if c.QueryBool("changed") {
+		services.KeepChangedReplayTurns(replay)
+	}
+	return c.JSON(replay)
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ConversationTagHandler struct {
+	tagService *services.ConversationTagService
+}
+
+func NewConversationTagHandler(tagService *services.ConversationTagService) *ConversationTagHandler {
+	return &ConversationTagHandler{
+		tagService: tagService,
+	}
+}
+
+// ListTags godoc
+// @Summary List conversation tags
+// @Description Tag definitions of a client, with the keywords that apply them automatically
+// @Tags Conversation Tags
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {array} models.ConversationTag
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversation-tags [get]
+func (h *ConversationTagHandler) ListTags(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	tags, err := h.tagService.ListTags(clientID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(tags)
+}
+
+// CreateTag godoc
+// @Summary Create a conversation tag
+// @Description Define a tag (lowercase, no spaces, e.g. "komplain"). Customer messages containing one of the keywords get the tag automatically.
+// @Tags Conversation Tags
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param tag body models.ConversationTagRequest true "Tag definition"
+// @Success 201 {object} models.ConversationTag
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversation-tags [post]
+func (h *ConversationTagHandler) CreateTag(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.ConversationTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	tag, err := h.tagService.CreateTag(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(tag)
+}
+
+// UpdateTag godoc
+// @Summary Update a conversation tag
+// @Description Rename a tag or change its color, description or keywords. Tagged chats keep the tag.
+// @Tags Conversation Tags
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Tag ID"
+// @Param tag body models.ConversationTagRequest true "Tag definition"
+// @Success 200 {object} models.ConversationTag
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /conversation-tags/{id} [put]
+func (h *ConversationTagHandler) UpdateTag(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid tag id"})
+	}
+
+	var req models.ConversationTagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	tag, err := h.tagService.UpdateTag(clientID, id, &req)
+	if err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(tag)
+}
+
+// DeleteTag godoc
+// @Summary Delete a conversation tag
+// @Description Delete a tag definition; it is removed from every chat
+// @Tags Conversation Tags
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Tag ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /conversation-tags/{id} [delete]
+func (h *ConversationTagHandler) DeleteTag(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid tag id"})
+	}
+
+	if err := h.tagService.DeleteTag(clientID, id); err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Tag deleted successfully"})
+}
+
+// ListConversations godoc
+// @Summary List customer conversations
+// @Description One row per customer chat (latest message, number of turns, tags), most recently active first. Filter by tags (comma-separated) matching any or all of them.
+// @Tags Conversation Tags
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param tags query string false "Comma-separated tag names, e.g. komplain,vip"
+// @Param match query string false "any or all" default(any)
+// @Param phone query string false "Customer phone prefix"
+// @Param limit query int false "Limit results" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.ConversationSummary
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversations [get]
+func (h *ConversationTagHandler) ListConversations(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	match := c.Query("match", "any")
+	if match != "any" && match != "all" {
+		return c.Status(400).JSON(fiber.Map{"error": "match must be 'any' or 'all'"})
+	}
+
+	filter := models.ConversationFilter{
+		MatchAll: match == "all",
+		Phone:    strings.TrimSpace(c.Query("phone")),
+		Limit:    c.QueryInt("limit", 50),
+		Offset:   c.QueryInt("offset", 0),
+	}
+	for _, name := range strings.Split(c.Query("tags"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Tags = append(filter.Tags, name)
+		}
+	}
+
+	conversations, err := h.tagService.ListConversations(clientID, filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(conversations)
+}
+
+// GetConversationTags godoc
+// @Summary Get tags of a customer chat
+// @Tags Conversation Tags
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param phone path string true "Customer phone"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversations/{phone}/tags [get]
+func (h *ConversationTagHandler) GetConversationTags(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	tags, err := h.tagService.CustomerTags(clientID, c.Params("phone"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"customer_phone": c.Params("phone"),
+		"tags":           tags,
+	})
+}
+
+// TagConversation godoc
+// @Summary Tag a customer chat
+// @Description Add one or more defined tags to a customer's chat. Fires the conversation_tagged workflow event for each new tag.
+// @Tags Conversation Tags
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param phone path string true "Customer phone"
+// @Param request body models.TagConversationRequest true "Tags"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversations/{phone}/tags [post]
+func (h *ConversationTagHandler) TagConversation(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.TagConversationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	phone := c.Params("phone")
+	added, err := h.tagService.TagConversation(clientID, phone, req.Tags, models.TagSourceManual, req.TaggedBy)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tags, err := h.tagService.CustomerTags(clientID, phone)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"customer_phone": phone,
+		"added":          added,
+		"tags":           tags,
+	})
+}
+
+// UntagConversation godoc
+// @Summary Remove a tag from a customer chat
+// @Tags Conversation Tags
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param phone path string true "Customer phone"
+// @Param tag path string true "Tag name"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /conversations/{phone}/tags/{tag} [delete]
+func (h *ConversationTagHandler) UntagConversation(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	phone := c.Params("phone")
+	if err := h.tagService.UntagConversation(clientID, phone, c.Params("tag")); err != nil {
+		if errors.Is(err, services.ErrTagNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	tags, err := h.tagService.CustomerTags(clientID, phone)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"customer_phone": phone,
+		"tags":           tags,
+	})
+}
@@ -0,0 +1,329 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CustomFieldHandler struct {
+	customFieldService *services.CustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService *services.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		customFieldService: customFieldService,
+	}
+}
+
+// ListCustomFields godoc
+// @Summary List custom fields
+// @Description Get the client's custom field definitions, optionally for one entity
+// @Tags Custom Fields
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param entity query string false "customer, order or product"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /custom-fields [get]
+func (h *CustomFieldHandler) ListCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	fields, err := h.customFieldService.ListDefinitions(clientID, c.Query("entity"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"fields": fields,
+		"total":  len(fields),
+	})
+}
+
+// CreateCustomField godoc
+// @Summary Create a custom field
+// @Description Add a field to the client's customers, orders or products. key (lowercase, digits, underscores) is where values are stored and how workflows refer to them, e.g. the condition field or template variable {order.table_number}. Types: text (pattern, min/max length), number (min/max), boolean, date (YYYY-MM-DD) and select (options).
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param field body models.CustomFieldDefinitionRequest true "Field definition"
+// @Success 201 {object} models.CustomFieldDefinition
+// @Failure 400 {object} map[string]interface{}
+// @Router /custom-fields [post]
+func (h *CustomFieldHandler) CreateCustomField(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.CustomFieldDefinitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	field, err := h.customFieldService.CreateDefinition(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(field)
+}
+
+// UpdateCustomField godoc
+// @Summary Update a custom field
+// @Description Change a custom field's label, type rules or position. Entity and key cannot change; values already stored are not re-validated.
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param client_id query string true "Client ID"
+// @Param field body models.CustomFieldDefinitionRequest true "Field definition"
+// @Success 200 {object} models.CustomFieldDefinition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /custom-fields/{id} [put]
+func (h *CustomFieldHandler) UpdateCustomField(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid field id"})
+	}
+
+	var req models.CustomFieldDefinitionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	field, err := h.customFieldService.UpdateDefinition(clientID, id, &req)
+	if errors.Is(err, services.ErrCustomFieldNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(field)
+}
+
+// DeleteCustomField godoc
+// @Summary Delete a custom field
+// @Description Remove a custom field and the values stored under its key
+// @Tags Custom Fields
+// @Produce json
+// @Param id path string true "Field ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /custom-fields/{id} [delete]
+func (h *CustomFieldHandler) DeleteCustomField(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid field id"})
+	}
+
+	err = h.customFieldService.DeleteDefinition(clientID, id)
+	if errors.Is(err, services.ErrCustomFieldNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Custom field deleted"})
+}
+
+// ExportCustomFields godoc
+// @Summary Export records with custom fields as CSV
+// @Description Download the client's customers, orders or products as CSV, with a column per custom field after the standard columns. Orders are filtered by creation date.
+// @Tags Custom Fields
+// @Produce text/csv
+// @Param client_id query string true "Client ID"
+// @Param entity query string true "customer, order or product"
+// @Param from query string false "Orders from (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "Orders until (YYYY-MM-DD, inclusive), defaults to today"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]interface{}
+// @Router /custom-fields/export [get]
+func (h *CustomFieldHandler) ExportCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	entity := c.Query("entity")
+	data, err := h.customFieldService.ExportCSV(clientID, entity, from, to)
+	if err != nil {
+		log.Printf("❌ Failed to export %s custom fields: %v", entity, err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(fmt.Sprintf("%ss_%s.csv", entity, to.AddDate(0, 0, -1).Format("20060102")))
+	return c.Send(data)
+}
+
+// GetCustomerCustomFields godoc
+// @Summary Get a customer's custom field values
+// @Tags Custom Fields
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone}/custom-fields [get]
+func (h *CustomFieldHandler) GetCustomerCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	return h.getValues(c, clientID, models.CustomFieldEntityCustomer, c.Params("phone"))
+}
+
+// SetCustomerCustomFields godoc
+// @Summary Set a customer's custom field values
+// @Description Merge values into the customer's custom fields; null clears a field, fields left out keep their value
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Param values body map[string]interface{} true "Values by field key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone}/custom-fields [put]
+func (h *CustomFieldHandler) SetCustomerCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	return h.setValues(c, clientID, models.CustomFieldEntityCustomer, c.Params("phone"))
+}
+
+// GetOrderCustomFields godoc
+// @Summary Get an order's custom field values
+// @Tags Custom Fields
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /orders/{id}/custom-fields [get]
+func (h *CustomFieldHandler) GetOrderCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	return h.getValues(c, clientID, models.CustomFieldEntityOrder, c.Params("id"))
+}
+
+// SetOrderCustomFields godoc
+// @Summary Set an order's custom field values
+// @Description Merge values into the order's custom fields; null clears a field, fields left out keep their value
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param values body map[string]interface{} true "Values by field key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /orders/{id}/custom-fields [put]
+func (h *CustomFieldHandler) SetOrderCustomFields(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	return h.setValues(c, clientID, models.CustomFieldEntityOrder, c.Params("id"))
+}
+
+// GetProductCustomFields godoc
+// @Summary Get a product's custom field values
+// @Tags Custom Fields
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/custom-fields [get]
+func (h *CustomFieldHandler) GetProductCustomFields(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+	return h.getValues(c, clientID, models.CustomFieldEntityProduct, c.Params("id"))
+}
+
+// SetProductCustomFields godoc
+// @Summary Set a product's custom field values
+// @Description Merge values into the product's custom fields; null clears a field, fields left out keep their value
+// @Tags Custom Fields
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param values body map[string]interface{} true "Values by field key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/custom-fields [put]
+func (h *CustomFieldHandler) SetProductCustomFields(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": err.Error()})
+	}
+	return h.setValues(c, clientID, models.CustomFieldEntityProduct, c.Params("id"))
+}
+
+func (h *CustomFieldHandler) getValues(c *fiber.Ctx, clientID uuid.UUID, entity, ref string) error {
+	values, err := h.customFieldService.GetValues(clientID, entity, ref)
+	if errors.Is(err, services.ErrCustomFieldTarget) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"custom_fields": values})
+}
+
+func (h *CustomFieldHandler) setValues(c *fiber.Ctx, clientID uuid.UUID, entity, ref string) error {
+	var values map[string]interface{}
+	if err := c.BodyParser(&values); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	saved, err := h.customFieldService.SetValues(clientID, entity, ref, values)
+	if errors.Is(err, services.ErrCustomFieldTarget) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"custom_fields": saved})
+}
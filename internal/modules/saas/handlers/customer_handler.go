@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerHandler struct {
+	customerService *services.CustomerService
+	segmentService  *services.CustomerSegmentService
+}
+
+func NewCustomerHandler(customerService *services.CustomerService, segmentService *services.CustomerSegmentService) *CustomerHandler {
+	return &CustomerHandler{customerService: customerService, segmentService: segmentService}
+}
+
+// ListCustomers godoc
+// @Summary List customers
+// @Description The client's customers, created from their first WhatsApp message: name and notes kept by staff, messages received, last interaction, paid orders, lifetime value (paid totals less refunds) and the tags on their chat. Customers without a name are named after their latest order.
+// @Tags Customers
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param q query string false "Part of the customer's name or phone"
+// @Param tags query string false "Comma-separated tag names; customers whose chat carries any of them"
+// @Param segment_id query string false "Customer segment ID; its members only"
+// @Param sort query string false "recent, lifetime_value or name" default(recent)
+// @Param limit query int false "Limit results" default(50)
+// @Param offset query int false "Offset" default(0)
+// @Success 200 {array} models.CustomerProfile
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /customers [get]
+func (h *CustomerHandler) ListCustomers(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	sort := c.Query("sort", models.CustomerSortRecent)
+	if sort != models.CustomerSortRecent && sort != models.CustomerSortLifetimeValue && sort != models.CustomerSortName {
+		return c.Status(400).JSON(fiber.Map{"error": "sort must be 'recent', 'lifetime_value' or 'name'"})
+	}
+
+	filter := models.CustomerFilter{
+		Search: c.Query("q"),
+		Sort:   sort,
+		Limit:  c.QueryInt("limit", 50),
+		Offset: c.QueryInt("offset", 0),
+	}
+	for _, name := range strings.Split(c.Query("tags"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Tags = append(filter.Tags, name)
+		}
+	}
+	if c.Query("segment_id") != "" {
+		segmentID, err := uuid.Parse(c.Query("segment_id"))
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid segment_id"})
+		}
+		segment, err := h.segmentService.Get(clientID, segmentID)
+		if err != nil {
+			return segmentError(c, err)
+		}
+		rules, err := h.segmentService.Rules(segment)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		filter.Segment = &rules
+	}
+
+	customers, err := h.customerService.List(clientID, filter)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(customers)
+}
+
+// GetCustomer godoc
+// @Summary Get a customer
+// @Tags Customers
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.CustomerProfile
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /customers/{phone} [get]
+func (h *CustomerHandler) GetCustomer(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	customer, err := h.customerService.Get(clientID, c.Params("phone"))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": "customer not found"})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to load customer %s: %v", c.Params("phone"), err)
+		return c.Status(500).JSON(fiber.Map{"error": "failed to load customer"})
+	}
+	return c.JSON(customer)
+}
+
+// UpdateCustomer godoc
+// @Summary Edit a customer
+// @Description Set the customer's name and notes; fields left out keep their value. A customer who never wrote in is created.
+// @Tags Customers
+// @Accept json
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Param customer body models.UpdateCustomerRequest true "Name and notes"
+// @Success 200 {object} models.CustomerProfile
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone} [put]
+func (h *CustomerHandler) UpdateCustomer(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateCustomerRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	customer, err := h.customerService.Update(clientID, c.Params("phone"), &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(customer)
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CustomerPreferenceHandler struct {
+	preferenceService *services.CustomerPreferenceService
+}
+
+func NewCustomerPreferenceHandler(preferenceService *services.CustomerPreferenceService) *CustomerPreferenceHandler {
+	return &CustomerPreferenceHandler{preferenceService: preferenceService}
+}
+
+// GetCustomerPreferences godoc
+// @Summary Get a customer's preferences
+// @Description Preferences the customer stated in chat or an agent set: reply language, payment method and delivery notes. The bot reads them into its prompt; checkout uses the payment method unless the customer asks for another one, and the delivery notes go to the driver.
+// @Tags Customer Preferences
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.CustomerPreference
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone}/preferences [get]
+func (h *CustomerPreferenceHandler) GetCustomerPreferences(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	return c.JSON(h.preferenceService.Preferences(clientID, c.Params("phone")))
+}
+
+// UpdateCustomerPreferences godoc
+// @Summary Edit a customer's preferences
+// @Description Fields left out keep their value; an empty string clears one. language is id, en, jv or su; payment_method is cod, qris, bank_transfer, ewallet or credit_card.
+// @Tags Customer Preferences
+// @Accept json
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Param preferences body models.UpdateCustomerPreferenceRequest true "Preferences"
+// @Success 200 {object} models.CustomerPreference
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone}/preferences [put]
+func (h *CustomerPreferenceHandler) UpdateCustomerPreferences(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateCustomerPreferenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	preference, err := h.preferenceService.Update(clientID, c.Params("phone"), &req, models.PreferenceSourceAgent)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(preference)
+}
+
+// DeleteCustomerPreferences godoc
+// @Summary Forget a customer's preferences
+// @Tags Customer Preferences
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /customers/{phone}/preferences [delete]
+func (h *CustomerPreferenceHandler) DeleteCustomerPreferences(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	if err := h.preferenceService.Delete(clientID, c.Params("phone")); err != nil {
+		log.Printf("❌ Failed to delete preferences of %s: %v", c.Params("phone"), err)
+		return c.Status(500).JSON(fiber.Map{"error": "failed to delete preferences"})
+	}
+	return c.JSON(fiber.Map{"status": "ok"})
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type CustomerSegmentHandler struct {
+	segmentService *services.CustomerSegmentService
+}
+
+func NewCustomerSegmentHandler(segmentService *services.CustomerSegmentService) *CustomerSegmentHandler {
+	return &CustomerSegmentHandler{segmentService: segmentService}
+}
+
+// ListSegments godoc
+// @Summary List customer segments
+// @Description Saved customer segments of a client, by name
+// @Tags Customer Segments
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {array} models.CustomerSegment
+// @Failure 400 {object} map[string]interface{}
+// @Router /customer-segments [get]
+func (h *CustomerSegmentHandler) ListSegments(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	segments, err := h.segmentService.List(clientID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(segments)
+}
+
+// CreateSegment godoc
+// @Summary Create a customer segment
+// @Description Save a filter over the client's customers, e.g. tags ["vip"] with ordered_within_days 30. Every rule set must match. Members are resolved whenever the segment is used: as a campaign audience (audience "segment"), in the customer list (segment_id) or in the customer_segments workflow condition field.
+// @Tags Customer Segments
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param segment body models.CustomerSegmentRequest true "Segment"
+// @Success 201 {object} models.CustomerSegment
+// @Failure 400 {object} map[string]interface{}
+// @Router /customer-segments [post]
+func (h *CustomerSegmentHandler) CreateSegment(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.CustomerSegmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	segment, err := h.segmentService.Create(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(201).JSON(segment)
+}
+
+// PreviewSegment godoc
+// @Summary Preview a customer segment
+// @Description Count the customers the rules match right now, without saving them
+// @Tags Customer Segments
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param rules body models.SegmentRules true "Segment rules"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /customer-segments/preview [post]
+func (h *CustomerSegmentHandler) PreviewSegment(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var rules models.SegmentRules
+	if err := c.BodyParser(&rules); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	count, err := h.segmentService.Preview(clientID, rules)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(fiber.Map{
+		"customers": count,
+	})
+}
+
+// GetSegment godoc
+// @Summary Get a customer segment
+// @Tags Customer Segments
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Segment ID"
+// @Success 200 {object} models.CustomerSegment
+// @Failure 404 {object} map[string]interface{}
+// @Router /customer-segments/{id} [get]
+func (h *CustomerSegmentHandler) GetSegment(c *fiber.Ctx) error {
+	clientID, id, err := segmentParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	segment, err := h.segmentService.Get(clientID, id)
+	if err != nil {
+		return segmentError(c, err)
+	}
+	return c.JSON(segment)
+}
+
+// UpdateSegment godoc
+// @Summary Update a customer segment
+// @Description Rename a segment or change its rules. Campaigns not yet sent to it use the new rules.
+// @Tags Customer Segments
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Segment ID"
+// @Param segment body models.CustomerSegmentRequest true "Segment"
+// @Success 200 {object} models.CustomerSegment
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /customer-segments/{id} [put]
+func (h *CustomerSegmentHandler) UpdateSegment(c *fiber.Ctx) error {
+	clientID, id, err := segmentParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var req models.CustomerSegmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	segment, err := h.segmentService.Update(clientID, id, &req)
+	if err != nil {
+		return segmentError(c, err)
+	}
+	return c.JSON(segment)
+}
+
+// DeleteSegment godoc
+// @Summary Delete a customer segment
+// @Description Delete a segment. Campaigns still to be sent to it fail when they come due.
+// @Tags Customer Segments
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param id path string true "Segment ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /customer-segments/{id} [delete]
+func (h *CustomerSegmentHandler) DeleteSegment(c *fiber.Ctx) error {
+	clientID, id, err := segmentParams(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.segmentService.Delete(clientID, id); err != nil {
+		return segmentError(c, err)
+	}
+	return c.JSON(fiber.Map{"message": "Segment deleted successfully"})
+}
+
+func segmentParams(c *fiber.Ctx) (uuid.UUID, uuid.UUID, error) {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("client_id is required")
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, uuid.Nil, errors.New("invalid segment id")
+	}
+	return clientID, id, nil
+}
+
+func segmentError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrSegmentNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type DeliveryHandler struct {
+	deliveryService *services.DeliveryService
+}
+
+func NewDeliveryHandler(deliveryService *services.DeliveryService) *DeliveryHandler {
+	return &DeliveryHandler{
+		deliveryService: deliveryService,
+	}
+}
+
+// RegisterDriver godoc
+// @Summary Register a delivery driver
+// @Description Register a driver who receives delivery jobs via WhatsApp
+// @Tags Delivery
+// @Accept json
+// @Produce json
+// @Param driver body models.CreateDriverRequest true "Driver details"
+// @Success 201 {object} models.Driver
+// @Failure 400 {object} map[string]interface{}
+// @Router /drivers [post]
+func (h *DeliveryHandler) RegisterDriver(c *fiber.Ctx) error {
+	var req models.CreateDriverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	if req.ClientID == "" || req.Name == "" || req.Phone == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id, name and phone are required"})
+	}
+
+	driver, err := h.deliveryService.RegisterDriver(&req)
+	if err != nil {
+		log.Printf("❌ Failed to register driver: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(driver)
+}
+
+// ListDrivers godoc
+// @Summary List delivery drivers
+// @Description List a client's delivery drivers
+// @Tags Delivery
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param active_only query bool false "Only active drivers"
+// @Success 200 {object} map[string]interface{}
+// @Router /drivers [get]
+func (h *DeliveryHandler) ListDrivers(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	drivers, err := h.deliveryService.ListDrivers(clientID, c.QueryBool("active_only", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"drivers": drivers,
+		"count":   len(drivers),
+	})
+}
+
+// UpdateDriver godoc
+// @Summary Update a delivery driver
+// @Description Update a driver's name, phone, branch or active status
+// @Tags Delivery
+// @Accept json
+// @Produce json
+// @Param id path string true "Driver ID"
+// @Param client_id query string true "Client ID"
+// @Param driver body models.UpdateDriverRequest true "Fields to update"
+// @Success 200 {object} models.Driver
+// @Failure 400 {object} map[string]interface{}
+// @Router /drivers/{id} [put]
+func (h *DeliveryHandler) UpdateDriver(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateDriverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	driver, err := h.deliveryService.UpdateDriver(c.Params("id"), clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(driver)
+}
+
+// AssignDriver godoc
+// @Summary Assign an order to a driver
+// @Description Assign a paid order to a driver; the driver receives the job (address and items) via WhatsApp
+// @Tags Delivery
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param assignment body models.AssignDriverRequest true "Driver and delivery address"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/{id}/assign-driver [post]
+func (h *DeliveryHandler) AssignDriver(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.AssignDriverRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	if req.DriverID == "" || req.DeliveryAddress == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "driver_id and delivery_address are required"})
+	}
+
+	shipment, err := h.deliveryService.AssignOrder(c.Params("id"), clientID, &req)
+	if err != nil {
+		log.Printf("❌ Failed to assign driver: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Driver assigned successfully",
+		"shipment": shipment,
+	})
+}
+
+// ListShipments godoc
+// @Summary List shipments
+// @Description List a client's shipments with optional status and driver filters
+// @Tags Delivery
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "assigned, picked_up, delivered, cancelled"
+// @Param driver_id query string false "Driver ID"
+// @Param limit query int false "Limit results" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Router /shipments [get]
+func (h *DeliveryHandler) ListShipments(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	shipments, err := h.deliveryService.ListShipments(clientID, c.Query("status"), c.Query("driver_id"), c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"shipments": shipments,
+		"count":     len(shipments),
+	})
+}
+
+// UpdateShipmentStatus godoc
+// @Summary Update shipment status
+// @Description Move a shipment to picked_up, delivered or cancelled (same transitions as the driver's WhatsApp keywords)
+// @Tags Delivery
+// @Accept json
+// @Produce json
+// @Param id path string true "Shipment ID"
+// @Param client_id query string true "Client ID"
+// @Param status body object{status=string} true "New status"
+// @Success 200 {object} models.Shipment
+// @Failure 400 {object} map[string]interface{}
+// @Router /shipments/{id}/status [put]
+func (h *DeliveryHandler) UpdateShipmentStatus(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Status == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "status is required"})
+	}
+
+	shipment, err := h.deliveryService.UpdateShipmentStatus(c.Params("id"), clientID, req.Status)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(shipment)
+}
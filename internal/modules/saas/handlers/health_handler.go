@@ -1,16 +1,40 @@
 package handlers
 
 import (
+	"context"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/cache"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/migrations"
 	"github.com/gofiber/fiber/v2"
 )
 
+// readinessTimeout bounds each dependency check of the readiness probe
+const readinessTimeout = 2 * time.Second
+
 type HealthHandler struct {
 	whatsappService *whatsapp.Service
+	db              *database.DB
+	vectorRetriever *kb.VectorRetriever // nil when no vector DB is configured
+	cache           *cache.Cache        // nil when Redis caching is off
+	autoMigrate     bool
+}
+
+func NewHealthHandler(whatsappService *whatsapp.Service, db *database.DB, vectorRetriever *kb.VectorRetriever, autoMigrate bool) *HealthHandler {
+	return &HealthHandler{
+		whatsappService: whatsappService,
+		db:              db,
+		vectorRetriever: vectorRetriever,
+		autoMigrate:     autoMigrate,
+	}
 }
 
-func NewHealthHandler(whatsappService *whatsapp.Service) *HealthHandler {
-	return &HealthHandler{whatsappService: whatsappService}
+// SetCache adds the Redis cache to the readiness probe
+func (h *HealthHandler) SetCache(c *cache.Cache) {
+	h.cache = c
 }
 
 // GetHealth godoc
@@ -27,3 +51,150 @@ func (h *HealthHandler) GetHealth(c *fiber.Ctx) error {
 		"provider": h.whatsappService.GetProviderName(),
 	})
 }
+
+// GetHealthz godoc
+// @Summary Readiness check
+// @Description Checks the database connection and reports the applied migration version per module. Returns 503 if the database is unreachable or a migration left the schema dirty.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /healthz [get]
+func (h *HealthHandler) GetHealthz(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := h.db.PingContext(ctx); err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status":   "unavailable",
+			"database": "unreachable",
+			"error":    err.Error(),
+		})
+	}
+
+	modules, err := migrations.Status(ctx, h.db)
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+			"status":   "unavailable",
+			"database": "ok",
+			"error":    err.Error(),
+		})
+	}
+
+	versions := fiber.Map{}
+	status, code := "ok", fiber.StatusOK
+	for _, m := range modules {
+		versions[m.Name] = fiber.Map{"version": m.Version, "dirty": m.Dirty}
+		if m.Dirty {
+			status, code = "unavailable", fiber.StatusServiceUnavailable
+		}
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"status":   status,
+		"service":  "saas-api",
+		"database": "ok",
+		"migration": fiber.Map{
+			"auto_migrate": h.autoMigrate,
+			"modules":      versions,
+		},
+	})
+}
+
+// GetLiveness godoc
+// @Summary Liveness probe
+// @Description Reports that the process is up and serving requests. Checks no dependencies, so a database or WhatsApp outage doesn't get the pod restarted.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /health/live [get]
+func (h *HealthHandler) GetLiveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// GetReadiness godoc
+// @Summary Readiness probe
+// @Description Pings Postgres, the vector DB (Qdrant), the Redis cache and the WhatsApp provider. Returns 503 only when Postgres is unreachable; a vector DB, cache or WhatsApp problem is reported as "degraded", as taking every replica out of the load balancer wouldn't fix it.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /health/ready [get]
+func (h *HealthHandler) GetReadiness(c *fiber.Ctx) error {
+	status, code := "ok", fiber.StatusOK
+	checks := fiber.Map{}
+
+	// Postgres
+	ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+	started := time.Now()
+	err := h.db.PingContext(ctx)
+	cancel()
+	if err != nil {
+		status, code = "unavailable", fiber.StatusServiceUnavailable
+		checks["database"] = fiber.Map{"status": "error", "error": err.Error()}
+	} else {
+		stats := h.db.Stats()
+		checks["database"] = fiber.Map{
+			"status":     "ok",
+			"latency_ms": time.Since(started).Milliseconds(),
+			"pool": fiber.Map{
+				"max_open":   stats.MaxOpenConnections,
+				"open":       stats.OpenConnections,
+				"in_use":     stats.InUse,
+				"idle":       stats.Idle,
+				"wait_count": stats.WaitCount,
+			},
+		}
+	}
+
+	// Vector DB
+	if h.vectorRetriever == nil {
+		checks["vector_db"] = fiber.Map{"status": "disabled"}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+		started := time.Now()
+		err := h.vectorRetriever.Ping(ctx)
+		cancel()
+		if err != nil {
+			if code == fiber.StatusOK {
+				status = "degraded"
+			}
+			checks["vector_db"] = fiber.Map{"status": "error", "error": err.Error()}
+		} else {
+			checks["vector_db"] = fiber.Map{"status": "ok", "latency_ms": time.Since(started).Milliseconds()}
+		}
+	}
+
+	// Redis cache; lookups fall back to Postgres while it's down
+	if !h.cache.Enabled() {
+		checks["cache"] = fiber.Map{"status": "disabled"}
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+		err := h.cache.Ping(ctx)
+		cancel()
+		if err != nil {
+			if code == fiber.StatusOK {
+				status = "degraded"
+			}
+			checks["cache"] = fiber.Map{"status": "error", "error": err.Error()}
+		} else {
+			checks["cache"] = fiber.Map{"status": "ok", "stats": h.cache.Stats()}
+		}
+	}
+
+	// WhatsApp provider
+	if h.whatsappService.IsConnected() {
+		checks["whatsapp"] = fiber.Map{"status": "ok", "provider": h.whatsappService.GetProviderName()}
+	} else {
+		if code == fiber.StatusOK {
+			status = "degraded"
+		}
+		checks["whatsapp"] = fiber.Map{"status": "disconnected", "provider": h.whatsappService.GetProviderName()}
+	}
+
+	return c.Status(code).JSON(fiber.Map{
+		"status":  status,
+		"service": "saas-api",
+		"checks":  checks,
+	})
+}
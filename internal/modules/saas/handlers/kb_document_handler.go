@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KBDocumentHandler struct {
+	documentService *services.KBDocumentService
+}
+
+func NewKBDocumentHandler(documentService *services.KBDocumentService) *KBDocumentHandler {
+	return &KBDocumentHandler{documentService: documentService}
+}
+
+// UploadDocument godoc
+// @Summary Upload a knowledge base document
+// @Description Ingests a PDF, DOCX or TXT file (max 20MB; files over 4MB need API_MAX_BODY_BYTES raised) so the bot can answer from long documents such as catalogs, policies or manuals. The text is extracted, split into overlapping chunks and embedded into the vector DB in the background; the document is "processing" until then, and "ready" or "failed" after. Scanned PDFs without a text layer are rejected.
+// @Tags KnowledgeBase
+// @Accept multipart/form-data
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param file formData file true "PDF, DOCX or TXT file"
+// @Success 202 {object} models.KBDocument
+// @Failure 400 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /knowledge-base/documents [post]
+func (h *KBDocumentHandler) UploadDocument(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "No file uploaded"})
+	}
+	if fileHeader.Size > services.MaxKBDocumentSize {
+		return c.Status(400).JSON(fiber.Map{"error": services.ErrKBDocumentTooLarge.Error()})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Failed to read uploaded file"})
+	}
+
+	document, err := h.documentService.Upload(clientID, fileHeader.Filename, data)
+	if err != nil {
+		return kbDocumentError(c, err)
+	}
+	return c.Status(202).JSON(document)
+}
+
+// ListDocuments godoc
+// @Summary List knowledge base documents
+// @Description The client's uploaded documents newest first, with their indexing status and chunk count.
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /knowledge-base/documents [get]
+func (h *KBDocumentHandler) ListDocuments(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	documents, err := h.documentService.List(clientID)
+	if err != nil {
+		log.Printf("❌ Failed to list documents for client %s: %v", clientID, err)
+		return c.Status(500).JSON(fiber.Map{"error": "failed to list documents"})
+	}
+
+	return c.JSON(fiber.Map{
+		"documents": documents,
+		"count":     len(documents),
+	})
+}
+
+// DeleteDocument godoc
+// @Summary Delete a knowledge base document
+// @Description Removes the document and its chunks from the vector DB, so the bot stops quoting it.
+// @Tags KnowledgeBase
+// @Produce json
+// @Param id path string true "Document ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /knowledge-base/documents/{id} [delete]
+func (h *KBDocumentHandler) DeleteDocument(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid document id"})
+	}
+
+	if err := h.documentService.Delete(c.Context(), clientID, id); err != nil {
+		return kbDocumentError(c, err)
+	}
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// kbDocumentError maps document errors to HTTP statuses
+func kbDocumentError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, kb.ErrUnsupportedDocument), errors.Is(err, kb.ErrNoDocumentText), errors.Is(err, services.ErrKBDocumentTooLarge):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrKBDocumentNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrKBDocumentProcessing):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrVectorSearchDisabled):
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	default:
+		log.Printf("❌ Knowledge base document error: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+}
@@ -2,10 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"log"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"github.com/lib/pq"
@@ -13,14 +16,18 @@ import (
 )
 
 type KBHandler struct {
-	kbRetriever *kb.Retriever
-	kbRepo      repositories.KBRepo
+	kbRetriever    *kb.Retriever
+	kbRepo         repositories.KBRepo
+	kbBulkService  *services.KBBulkService
+	kbDedupService *services.KBDedupService
 }
 
-func NewKBHandler(retriever *kb.Retriever, repo repositories.KBRepo) *KBHandler {
+func NewKBHandler(retriever *kb.Retriever, repo repositories.KBRepo, bulkService *services.KBBulkService, dedupService *services.KBDedupService) *KBHandler {
 	return &KBHandler{
-		kbRetriever: retriever,
-		kbRepo:      repo,
+		kbRetriever:    retriever,
+		kbRepo:         repo,
+		kbBulkService:  bulkService,
+		kbDedupService: dedupService,
 	}
 }
 
@@ -62,11 +69,14 @@ type KnowledgeBaseRequest struct {
 // AddKnowledgeItem godoc
 // @Summary Add new knowledge base item
 // @Description Adds knowledge base entry with flexible JSONB content. The 'content' field accepts any JSON structure. Examples: For FAQ use {"question":"...","answer":"..."}, for Product use {"name":"...","price":50000,"description":"...","stock":100}
+// @Description Title and text content are normalized (trimmed, spacing and punctuation tidied) and checked against existing entries of the same type by fuzzy text match and embedding similarity. A duplicate is flagged for review (on_duplicate=flag, default) or the entry is merged into the closest existing one (on_duplicate=merge).
 // @Tags KnowledgeBase
 // @Accept json
 // @Produce json
+// @Param on_duplicate query string false "flag (default) or merge"
 // @Param data body KnowledgeBaseRequest true "Knowledge base data - content field accepts any JSON structure"
-// @Success 201 {object} map[string]string
+// @Success 201 {object} map[string]interface{}
+// @Success 200 {object} map[string]interface{} "Merged into an existing entry"
 // @Failure 400 {object} map[string]string
 // @Router /knowledge-base [post]
 func (h *KBHandler) AddKnowledgeItem(c *fiber.Ctx) error {
@@ -129,16 +139,207 @@ func (h *KBHandler) AddKnowledgeItem(c *fiber.Ctx) error {
 		IsActive: true,
 	}
 
-	// Save to database
-	if err := h.kbRepo.Create(entry); err != nil {
+	onDuplicate := c.Query("on_duplicate", services.KBOnDuplicateFlag)
+	if onDuplicate != services.KBOnDuplicateFlag && onDuplicate != services.KBOnDuplicateMerge {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "on_duplicate must be flag or merge",
+		})
+	}
+
+	// Normalize, check for duplicates and save to database
+	result, err := h.kbDedupService.AddEntry(c.Context(), entry, onDuplicate)
+	if err != nil {
+		log.Printf("❌ Failed to add knowledge base entry: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "failed to create knowledge base entry",
 		})
 	}
 
+	if result.Merged {
+		return c.JSON(fiber.Map{
+			"status":     "ok",
+			"message":    "Merged into an existing knowledge base entry",
+			"id":         result.ID,
+			"merged":     true,
+			"duplicates": result.Duplicates,
+		})
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":     "ok",
+		"message":    "Knowledge base entry created successfully",
+		"id":         result.ID,
+		"merged":     false,
+		"duplicates": result.Duplicates,
+	})
+}
+
+// DeleteKnowledgeBase godoc
+// @Summary Bulk delete knowledge base entries
+// @Description Deletes every entry of a client, or only one type. Without confirm the call is a dry run that returns the number of entries and a confirmation token (valid 10 minutes); call again with confirm=<token> to delete. The token is rejected if entries were added or removed in between. Entries are removed from the vector index in the same step.
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param type query string false "Entry type (faq, product, ...); empty = all types"
+// @Param confirm query string false "Confirmation token from the dry run"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /knowledge-base [delete]
+func (h *KBHandler) DeleteKnowledgeBase(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+	entryType := c.Query("type")
+
+	token := c.Query("confirm")
+	if token == "" {
+		preview, err := h.kbBulkService.PreviewDelete(clientID, entryType)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.JSON(fiber.Map{
+			"dry_run": true,
+			"preview": preview,
+		})
+	}
+
+	deleted, err := h.kbBulkService.BulkDelete(c.Context(), clientID, entryType, token)
+	if errors.Is(err, services.ErrKBConfirmationStale) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to bulk delete knowledge base: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
 		"status":  "ok",
-		"message": "Knowledge base entry created successfully",
-		"id":      entry.ID.String(),
+		"deleted": deleted,
 	})
 }
+
+// ImportKnowledgeBase godoc
+// @Summary Re-import knowledge base entries of one type
+// @Description Replaces every entry of the given type with the items in the body, matched by title (case-insensitive). Items are normalized first. Runs as a dry run by default and returns the diff (added, changed, removed, and likely duplicates among the items); pass dry_run=false to apply it, which also flags the duplicates for review. Postgres and the vector index are updated together and rolled back together on failure.
+// @Tags KnowledgeBase
+// @Accept json
+// @Produce json
+// @Param dry_run query bool false "Only compute the diff (default true)"
+// @Param data body services.KBImportRequest true "Full set of entries for the type"
+// @Success 200 {object} services.KBImportDiff
+// @Failure 400 {object} map[string]string
+// @Router /knowledge-base/import [post]
+func (h *KBHandler) ImportKnowledgeBase(c *fiber.Ctx) error {
+	var req services.KBImportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	if req.ClientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	diff, err := h.kbBulkService.Import(c.Context(), &req, c.QueryBool("dry_run", true))
+	if err != nil {
+		log.Printf("❌ Failed to import knowledge base: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(diff)
+}
+
+// ListKBDuplicates godoc
+// @Summary List detected knowledge base duplicates
+// @Description Returns entries flagged as near-duplicates of another entry on write (single add or import), newest first. Open pairs whose entries were deleted since are left out.
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "open, merged or dismissed; empty = all"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /knowledge-base/duplicates [get]
+func (h *KBHandler) ListKBDuplicates(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid client_id format",
+		})
+	}
+
+	duplicates, err := h.kbDedupService.ListDuplicates(clientID, c.Query("status"))
+	if err != nil {
+		log.Printf("❌ Failed to list KB duplicates: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to list duplicates",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"duplicates": duplicates,
+		"count":      len(duplicates),
+	})
+}
+
+// MergeKBDuplicate godoc
+// @Summary Merge a detected knowledge base duplicate
+// @Description Deletes the later entry of a flagged pair (also from the vector index), keeping the earlier one with the tags of both
+// @Tags KnowledgeBase
+// @Produce json
+// @Param id path string true "Duplicate ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.KBDuplicate
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /knowledge-base/duplicates/{id}/merge [post]
+func (h *KBHandler) MergeKBDuplicate(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid client_id format",
+		})
+	}
+
+	duplicate, err := h.kbDedupService.MergeDuplicate(c.Context(), clientID, c.Params("id"))
+	return h.duplicateResponse(c, duplicate, err)
+}
+
+// DismissKBDuplicate godoc
+// @Summary Dismiss a detected knowledge base duplicate
+// @Description Marks a flagged pair as not being duplicates, both entries are kept
+// @Tags KnowledgeBase
+// @Produce json
+// @Param id path string true "Duplicate ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.KBDuplicate
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /knowledge-base/duplicates/{id}/dismiss [post]
+func (h *KBHandler) DismissKBDuplicate(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid client_id format",
+		})
+	}
+
+	duplicate, err := h.kbDedupService.DismissDuplicate(clientID, c.Params("id"))
+	return h.duplicateResponse(c, duplicate, err)
+}
+
+func (h *KBHandler) duplicateResponse(c *fiber.Ctx, duplicate *models.KBDuplicate, err error) error {
+	if errors.Is(err, services.ErrKBDuplicateNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(duplicate)
+}
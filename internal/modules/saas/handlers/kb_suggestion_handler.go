@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KBSuggestionHandler struct {
+	suggestionService *services.KBSuggestionService
+}
+
+func NewKBSuggestionHandler(suggestionService *services.KBSuggestionService) *KBSuggestionHandler {
+	return &KBSuggestionHandler{
+		suggestionService: suggestionService,
+	}
+}
+
+// ListSuggestions godoc
+// @Summary List KB suggestions
+// @Description FAQ entries drafted from unanswered and low-rated questions, most frequent first
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "pending, accepted, rejected" default(pending)
+// @Param limit query int false "Limit results" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /kb/suggestions [get]
+func (h *KBSuggestionHandler) ListSuggestions(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	suggestions, err := h.suggestionService.ListSuggestions(clientID, c.Query("status", models.KBSuggestionStatusPending), c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to fetch suggestions",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// GenerateSuggestions godoc
+// @Summary Generate KB suggestions now
+// @Description Run the weekly suggestion job for one client: cluster new gaps and draft FAQ entries
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /kb/suggestions/generate [post]
+func (h *KBSuggestionHandler) GenerateSuggestions(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	suggestions, err := h.suggestionService.GenerateSuggestions(c.Context(), clientID)
+	if err != nil {
+		log.Printf("❌ Failed to generate KB suggestions: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// AcceptSuggestion godoc
+// @Summary Accept a KB suggestion
+// @Description Write the suggestion (optionally edited) to the knowledge base as an FAQ and re-index it
+// @Tags KnowledgeBase
+// @Accept json
+// @Produce json
+// @Param id path string true "Suggestion ID"
+// @Param client_id query string true "Client ID"
+// @Param edits body models.AcceptKBSuggestionRequest false "Edited question/answer"
+// @Success 200 {object} models.KBSuggestion
+// @Failure 400 {object} map[string]string
+// @Router /kb/suggestions/{id}/accept [post]
+func (h *KBSuggestionHandler) AcceptSuggestion(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	var req models.AcceptKBSuggestionRequest
+	c.BodyParser(&req) // Optional edits
+
+	suggestion, err := h.suggestionService.AcceptSuggestion(c.Context(), clientID, c.Params("id"), &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(suggestion)
+}
+
+// RejectSuggestion godoc
+// @Summary Reject a KB suggestion
+// @Tags KnowledgeBase
+// @Produce json
+// @Param id path string true "Suggestion ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.KBSuggestion
+// @Failure 400 {object} map[string]string
+// @Router /kb/suggestions/{id}/reject [post]
+func (h *KBSuggestionHandler) RejectSuggestion(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	suggestion, err := h.suggestionService.RejectSuggestion(clientID, c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(suggestion)
+}
+
+// RateConversation godoc
+// @Summary Rate a bot answer
+// @Description Rate the bot's answer in a conversation (1-5); ratings of 2 or lower are queued for KB suggestions
+// @Tags KnowledgeBase
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param client_id query string true "Client ID"
+// @Param rating body object{rating=int} true "Rating 1-5"
+// @Success 200 {object} models.Conversation
+// @Failure 400 {object} map[string]string
+// @Router /conversations/{id}/rating [post]
+func (h *KBSuggestionHandler) RateConversation(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var req struct {
+		Rating int `json:"rating"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	conversation, err := h.suggestionService.RateAnswer(clientID, c.Params("id"), req.Rating)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(conversation)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type KBSyncHandler struct {
+	syncService *services.KBSyncService
+}
+
+func NewKBSyncHandler(syncService *services.KBSyncService) *KBSyncHandler {
+	return &KBSyncHandler{syncService: syncService}
+}
+
+// SyncKnowledgeBase godoc
+// @Summary Sync the knowledge base to the vector DB
+// @Description Every change to knowledge base entries is queued and applied to the vector DB by a background worker every minute; this applies the client's queued changes now. Entries are indexed under their own IDs, so syncing again never duplicates them. full=true first queues every entry, e.g. after the vector DB was restored. Failed changes are retried with backoff and counted as pending.
+// @Tags KnowledgeBase
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param full query bool false "Re-index every entry (default false)"
+// @Success 200 {object} models.KBSyncResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /knowledge-base/sync [post]
+func (h *KBSyncHandler) SyncKnowledgeBase(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	result, err := h.syncService.Sync(c.Context(), &clientID, c.QueryBool("full", false))
+	if errors.Is(err, services.ErrVectorSearchDisabled) {
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to sync knowledge base of client %s: %v", clientID, err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
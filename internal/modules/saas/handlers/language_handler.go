@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type LanguageHandler struct {
+	languageService *services.LanguageService
+}
+
+func NewLanguageHandler(languageService *services.LanguageService) *LanguageHandler {
+	return &LanguageHandler{
+		languageService: languageService,
+	}
+}
+
+// GetLanguageSettings godoc
+// @Summary Get reply language settings
+// @Description Get whether the bot answers in the language the customer writes in, and which languages it may switch to
+// @Tags Language
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.LanguageSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /language-settings [get]
+func (h *LanguageHandler) GetLanguageSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.languageService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateLanguageSettings godoc
+// @Summary Update reply language settings
+// @Description Configure reply language matching. When enabled, messages detected as one of allowed_languages (id, en, jv, su) are answered in that language; others are answered in default_language (the knowledge base language). post_process translates replies the LLM still wrote in the wrong language (one extra LLM call).
+// @Tags Language
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateLanguageSettingsRequest true "Language settings"
+// @Success 200 {object} models.LanguageSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /language-settings [put]
+func (h *LanguageHandler) UpdateLanguageSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateLanguageSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.languageService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// GetLanguageReport godoc
+// @Summary Conversations by customer language
+// @Description Count bot conversations and customers per detected language of the customer's message (empty language = could not be detected)
+// @Tags Analytics
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param period query string false "today, yesterday, this_week, last_week, this_month, last_month, this_year, last_30_days, last_90_days" default(last_30_days)
+// @Success 200 {object} models.LanguageReport
+// @Router /analytics/languages [get]
+func (h *LanguageHandler) GetLanguageReport(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	report, err := h.languageService.GetLanguageReport(clientID, c.Query("period"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(report)
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LatencyHandler struct {
+	latencyService *services.LatencyService
+}
+
+func NewLatencyHandler(latencyService *services.LatencyService) *LatencyHandler {
+	return &LatencyHandler{
+		latencyService: latencyService,
+	}
+}
+
+// GetLatencySettings godoc
+// @Summary Get reply latency budget
+// @Description Get when a slow AI reply triggers an interim message and when it is abandoned for a fallback (defaults apply until saved)
+// @Tags Latency
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.LatencySettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /latency-settings [get]
+func (h *LatencyHandler) GetLatencySettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.latencyService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateLatencySettings godoc
+// @Summary Update reply latency budget
+// @Description Configure the latency budget of AI replies. When the LLM takes longer than interim_after_seconds (0 = off) the customer gets interim_message; after hard_timeout_seconds (max 25) the reply is abandoned. Fallback "kb" answers with the best matching FAQ and hands over when none matches, "handover" always sends handover_message (and applies handover_tag to the chat) so an agent can take over. Empty messages use the defaults.
+// @Tags Latency
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateLatencySettingsRequest true "Latency settings"
+// @Success 200 {object} models.LatencySettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /latency-settings [put]
+func (h *LatencyHandler) UpdateLatencySettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateLatencySettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.latencyService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
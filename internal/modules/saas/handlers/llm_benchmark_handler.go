@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type LLMBenchmarkHandler struct {
+	benchmarkService *services.LLMBenchmarkService
+}
+
+func NewLLMBenchmarkHandler(benchmarkService *services.LLMBenchmarkService) *LLMBenchmarkHandler {
+	return &LLMBenchmarkHandler{benchmarkService: benchmarkService}
+}
+
+// LLMBenchmarkRequest selects the providers/models to benchmark
+type LLMBenchmarkRequest struct {
+	Targets []llm.BenchmarkTarget `json:"targets"` // Empty = every configured provider (see LLM_BENCHMARK_MODELS)
+}
+
+// RunBenchmark godoc
+// @Summary Benchmark LLM providers
+// @Description Runs the standard prompt suite (grounded Indonesian answers, not inventing facts, order and receipt JSON extraction, short replies) against every configured provider/model, or the targets in the body. Records latency, estimated cost and a quality score per model, stores them, and recommends the model with the best health score. Makes real API calls; one run at a time. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param data body LLMBenchmarkRequest false "Targets to benchmark"
+// @Success 200 {object} services.LLMBenchmarkRun
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/llm/benchmark [post]
+func (h *LLMBenchmarkHandler) RunBenchmark(c *fiber.Ctx) error {
+	var req LLMBenchmarkRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	run, err := h.benchmarkService.Run(c.Context(), req.Targets)
+	if errors.Is(err, services.ErrLLMBenchmarkRunning) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ LLM benchmark failed: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(run)
+}
+
+// GetBenchmarks godoc
+// @Summary LLM benchmark history
+// @Description Stored benchmark results newest first, to follow how providers' latency, cost and quality shift over time. Also lists the targets a run would benchmark. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param provider query string false "Provider (openai, gemini, groq, deepseek, claude)"
+// @Param model query string false "Model"
+// @Param limit query int false "Max results (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/llm/benchmarks [get]
+func (h *LLMBenchmarkHandler) GetBenchmarks(c *fiber.Ctx) error {
+	results, err := h.benchmarkService.History(c.Query("provider"), c.Query("model"), c.QueryInt("limit", 100))
+	if err != nil {
+		log.Printf("❌ Failed to list LLM benchmarks: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list benchmarks"})
+	}
+
+	return c.JSON(fiber.Map{
+		"results":            results,
+		"configured_targets": h.benchmarkService.Targets(),
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MessageFeatureHandler struct {
+	featureService *services.MessageFeatureService
+}
+
+func NewMessageFeatureHandler(featureService *services.MessageFeatureService) *MessageFeatureHandler {
+	return &MessageFeatureHandler{
+		featureService: featureService,
+	}
+}
+
+// GetMessageFeatureSettings godoc
+// @Summary Get message type toggles
+// @Description Get which inbound message types the bot handles: AI replies to customer text, receipt OCR of images, voice note transcription, nearest store for locations and messages from WhatsApp groups. Everything is on by default.
+// @Tags Message Features
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.MessageFeatureSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /message-features [get]
+func (h *MessageFeatureHandler) GetMessageFeatureSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.featureService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateMessageFeatureSettings godoc
+// @Summary Update message type toggles
+// @Description Turn handling of each inbound message type on or off. A message of a disabled type is logged and answered with its *_disabled_reply; an empty reply ignores it silently. Group messages are never answered when disabled. Turning text off only stops AI replies to customers; staff commands keep working.
+// @Tags Message Features
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateMessageFeatureSettingsRequest true "Message type toggles"
+// @Success 200 {object} models.MessageFeatureSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /message-features [put]
+func (h *MessageFeatureHandler) UpdateMessageFeatureSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateMessageFeatureSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.featureService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
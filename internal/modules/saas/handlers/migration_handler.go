@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/migrations"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MigrationHandler struct {
+	db *database.DB
+}
+
+func NewMigrationHandler(db *database.DB) *MigrationHandler {
+	return &MigrationHandler{db: db}
+}
+
+// GetMigrations godoc
+// @Summary Migration status per module
+// @Description Applied version, dirty state, latest embedded version, pending count and dependencies of every migration module, in the order "migrate all" applies them. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/migrations [get]
+func (h *MigrationHandler) GetMigrations(c *fiber.Ctx) error {
+	modules, err := migrations.Status(c.Context(), h.db)
+	if err != nil {
+		log.Printf("❌ Failed to read migration status: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"modules": modules,
+	})
+}
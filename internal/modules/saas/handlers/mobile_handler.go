@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type MobileHandler struct {
+	dashboardService *services.MobileDashboardService
+}
+
+func NewMobileHandler(dashboardService *services.MobileDashboardService) *MobileHandler {
+	return &MobileHandler{
+		dashboardService: dashboardService,
+	}
+}
+
+// GetDashboard godoc
+// @Summary Mobile mini-dashboard
+// @Description Compact dashboard for the admin mobile app: today's KPIs, orders needing action and unhealthy session alerts. Responses carry an ETag; send If-None-Match to get 304 Not Modified when nothing changed.
+// @Tags Mobile
+// @Produce json
+// @Param If-None-Match header string false "ETag of the previous response"
+// @Success 200 {object} models.MobileDashboard
+// @Success 304 "Not Modified"
+// @Failure 401 {object} map[string]interface{}
+// @Router /m/dashboard [get]
+func (h *MobileHandler) GetDashboard(c *fiber.Ctx) error {
+	clientID, ok := c.Locals("clientID").(string)
+	if !ok || clientID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	dashboard, err := h.dashboardService.GetDashboard(clientID)
+	if err != nil {
+		log.Printf("❌ Failed to build mobile dashboard: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "private, no-cache")
+	return c.JSON(dashboard)
+}
+
+// ConfirmPayment godoc
+// @Summary Confirm payment (mobile quick action)
+// @Description Confirm a manual payment for an order from the mobile dashboard
+// @Tags Mobile
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param payment body object{payment_method=string,reference=string} false "Payment details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /m/orders/{id}/confirm-payment [post]
+func (h *MobileHandler) ConfirmPayment(c *fiber.Ctx) error {
+	clientID, ok := c.Locals("clientID").(string)
+	if !ok || clientID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req struct {
+		PaymentMethod string `json:"payment_method"`
+		Reference     string `json:"reference"`
+	}
+	c.BodyParser(&req) // Optional
+
+	order, err := h.dashboardService.ConfirmPayment(clientID, c.Params("id"), req.PaymentMethod, req.Reference)
+	if err != nil {
+		return h.actionError(c, err)
+	}
+
+	return c.JSON(compactOrder(order))
+}
+
+// CancelOrder godoc
+// @Summary Cancel order (mobile quick action)
+// @Description Cancel an unpaid order from the mobile dashboard; the customer is notified via WhatsApp
+// @Tags Mobile
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param cancel body object{reason=string} false "Cancellation reason"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /m/orders/{id}/cancel [post]
+func (h *MobileHandler) CancelOrder(c *fiber.Ctx) error {
+	clientID, ok := c.Locals("clientID").(string)
+	if !ok || clientID == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{"error": "Unauthorized"})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	c.BodyParser(&req) // Optional, will use default if not provided
+
+	order, err := h.dashboardService.CancelOrder(clientID, c.Params("id"), req.Reason)
+	if err != nil {
+		return h.actionError(c, err)
+	}
+
+	return c.JSON(compactOrder(order))
+}
+
+// actionError maps a quick action failure to a response
+func (h *MobileHandler) actionError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+}
+
+// compactOrder trims an order to the mobile list row after a quick action
+func compactOrder(order *models.Order) fiber.Map {
+	return fiber.Map{
+		"id":                 order.ID,
+		"number":             order.OrderNumber,
+		"payment_status":     order.PaymentStatus,
+		"fulfillment_status": order.FulfillmentStatus,
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"log"
+	"time"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/ocr"
@@ -21,15 +22,17 @@ type OCRHandler struct {
 	llmService        *llm.Service
 	transactionRepo   repositories.TransactionRepo
 	workflowService   *services.WorkflowService
+	retentionService  *services.OCRRetentionService
 }
 
 // NewOCRHandler creates a new OCR handler
-func NewOCRHandler(ocrService *ocr.Service, llmService *llm.Service, transactionRepo repositories.TransactionRepo, workflowService *services.WorkflowService) *OCRHandler {
+func NewOCRHandler(ocrService *ocr.Service, llmService *llm.Service, transactionRepo repositories.TransactionRepo, workflowService *services.WorkflowService, retentionService *services.OCRRetentionService) *OCRHandler {
 	return &OCRHandler{
-		ocrService:      ocrService,
-		llmService:      llmService,
-		transactionRepo: transactionRepo,
-		workflowService: workflowService,
+		ocrService:       ocrService,
+		llmService:       llmService,
+		transactionRepo:  transactionRepo,
+		workflowService:  workflowService,
+		retentionService: retentionService,
 	}
 }
 
@@ -120,12 +123,12 @@ func (h *OCRHandler) ProcessReceipt(c *fiber.Ctx) error {
 		})
 	}
 
-	log.Printf("✅ OCR extracted text (confidence: %.2f%%): %s", ocrResult.Confidence*100, ocrResult.Text[:min(100, len(ocrResult.Text))])
+	log.Printf("✅ OCR extracted %d chars (confidence: %.2f%%)", len(ocrResult.Text), ocrResult.Confidence*100)
 
 	// Parse receipt data using LLM
 	log.Printf("🤖 Parsing receipt with LLM...")
 	llmParser := ocr.NewLLMParser(h.llmService)
-	receiptData, err := llmParser.ParseReceiptWithLLM(c.Context(), ocrResult.Text)
+	receiptData, err := llmParser.ParseReceiptWithLLM(llm.WithUsage(c.Context(), clientID, models.UsageFeatureReceipt), ocrResult.Text)
 	if err != nil {
 		log.Printf("❌ Failed to parse receipt: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -154,7 +157,7 @@ func (h *OCRHandler) ProcessReceipt(c *fiber.Ctx) error {
 		CreatedFrom:     "ocr",
 		SourceType:      "receipt",
 		OCRConfidence:   &ocrResult.Confidence,
-		OCRRawText:      ocrResult.Text,
+		OCRRawText:      h.retentionService.PrepareRawText(clientID, ocrResult.Text),
 	}
 
 	// Save to database
@@ -206,14 +209,6 @@ func (h *OCRHandler) ProcessReceipt(c *fiber.Ctx) error {
 	})
 }
 
-// min returns the minimum of two integers
-func min(a, b int) int {
-	if a < b {
-		return a
-	}
-	return b
-}
-
 // GetTransactions godoc
 // @Summary Get transactions for a client
 // @Description Retrieve transaction history for a specific client
@@ -252,3 +247,129 @@ func (h *OCRHandler) GetTransactions(c *fiber.Ctx) error {
 		"data":   transactions,
 	})
 }
+
+// GetOCRRetention godoc
+// @Summary Get raw OCR text retention settings
+// @Description How long raw receipt text is kept and whether sensitive data is masked at ingestion
+// @Tags Transactions
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.OCRRetentionSettings
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /transactions/ocr-retention [get]
+func (h *OCRHandler) GetOCRRetention(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	settings, err := h.retentionService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateOCRRetention godoc
+// @Summary Update raw OCR text retention settings
+// @Description Set retention_days (0 = don't store raw text, negative = keep forever) and anonymize (mask card numbers, emails, phones and names)
+// @Tags Transactions
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateOCRRetentionRequest true "Retention settings"
+// @Success 200 {object} models.OCRRetentionSettings
+// @Failure 400 {object} map[string]string
+// @Router /transactions/ocr-retention [put]
+func (h *OCRHandler) UpdateOCRRetention(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var req models.UpdateOCRRetentionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	settings, err := h.retentionService.UpdateSettings(clientID, &req)
+	if err != nil {
+		log.Printf("❌ Failed to update OCR retention: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(settings)
+}
+
+// PurgeRawText godoc
+// @Summary Delete raw OCR text
+// @Description Delete raw receipt text of all the client's transactions (optionally only those created before a date). Parsed totals and items are kept.
+// @Tags Transactions
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param before query string false "Only transactions created before this date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /transactions/raw-text [delete]
+func (h *OCRHandler) PurgeRawText(c *fiber.Ctx) error {
+	return h.purgeRawText(c, "")
+}
+
+// PurgeTransactionRawText godoc
+// @Summary Delete raw OCR text of a transaction
+// @Description Delete the raw receipt text of one transaction, keeping its parsed totals and items
+// @Tags Transactions
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /transactions/{id}/raw-text [delete]
+func (h *OCRHandler) PurgeTransactionRawText(c *fiber.Ctx) error {
+	return h.purgeRawText(c, c.Params("id"))
+}
+
+// purgeRawText deletes raw OCR text for the client in the query
+func (h *OCRHandler) purgeRawText(c *fiber.Ctx, transactionID string) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var before *time.Time
+	if value := c.Query("before"); value != "" {
+		date, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "before must be YYYY-MM-DD",
+			})
+		}
+		before = &date
+	}
+
+	purged, err := h.retentionService.PurgeRawText(clientID, transactionID, before)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"purged": purged,
+	})
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type OffboardingHandler struct {
+	offboardingService *services.OffboardingService
+}
+
+func NewOffboardingHandler(offboardingService *services.OffboardingService) *OffboardingHandler {
+	return &OffboardingHandler{offboardingService: offboardingService}
+}
+
+// DeactivateClient godoc
+// @Summary Deactivate a client
+// @Description Marks the client inactive and starts the offboarding cascade in the background: client_deactivated event, WhatsApp session stop, workflow pause, CMS user suspension and the data retention countdown. Progress is reported by the offboarding status endpoint. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param request body services.DeactivateClientRequest false "Reason and retention"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/clients/{id}/deactivate [post]
+func (h *OffboardingHandler) DeactivateClient(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	var req services.DeactivateClientRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	actor := req.RequestedBy
+	if actor == "" {
+		actor = "platform_admin"
+	}
+
+	run, err := h.offboardingService.Deactivate(clientID, req, actor)
+	if err != nil {
+		if errors.Is(err, services.ErrOffboardingInProgress) || errors.Is(err, services.ErrClientAlreadyInactive) {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error":       err.Error(),
+				"offboarding": run,
+			})
+		}
+		log.Printf("❌ Failed to deactivate client %s: %v", clientID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"offboarding": run,
+		"steps":       run.StepList(),
+	})
+}
+
+// GetOffboardingStatus godoc
+// @Summary Client offboarding status
+// @Description Latest offboarding run of a client with per-step progress and the data purge date. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/clients/{id}/offboarding [get]
+func (h *OffboardingHandler) GetOffboardingStatus(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	run, err := h.offboardingService.GetStatus(clientID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "client has not been offboarded"})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"offboarding": run,
+		"steps":       run.StepList(),
+	})
+}
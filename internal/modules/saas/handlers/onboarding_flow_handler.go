@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type OnboardingFlowHandler struct {
+	onboardingService *services.CustomerOnboardingService
+}
+
+func NewOnboardingFlowHandler(onboardingService *services.CustomerOnboardingService) *OnboardingFlowHandler {
+	return &OnboardingFlowHandler{
+		onboardingService: onboardingService,
+	}
+}
+
+// GetOnboardingFlow godoc
+// @Summary Get first-contact onboarding flow
+// @Description Get the greeting, quick menu, language selection and consent capture shown to first-time customers
+// @Tags Onboarding
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.OnboardingFlow
+// @Failure 400 {object} map[string]interface{}
+// @Router /onboarding-flow [get]
+func (h *OnboardingFlowHandler) GetOnboardingFlow(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	flow, err := h.onboardingService.GetFlow(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(flow)
+}
+
+// UpdateOnboardingFlow godoc
+// @Summary Update first-contact onboarding flow
+// @Description Configure the flow that runs once per customer on their first message. welcome_message supports {business_name}; languages (id, en) are asked only when more than one is given; quick_menu items are picked by replying their number.
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param flow body models.UpdateOnboardingFlowRequest true "Onboarding flow"
+// @Success 200 {object} models.OnboardingFlow
+// @Failure 400 {object} map[string]interface{}
+// @Router /onboarding-flow [put]
+func (h *OnboardingFlowHandler) UpdateOnboardingFlow(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateOnboardingFlowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	flow, err := h.onboardingService.UpdateFlow(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(flow)
+}
+
+// ResetCustomerOnboarding godoc
+// @Summary Reset a customer's onboarding
+// @Description Forget a customer's onboarding progress so the flow runs again on their next message
+// @Tags Onboarding
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /onboarding-flow/customers/{phone} [delete]
+func (h *OnboardingFlowHandler) ResetCustomerOnboarding(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	if err := h.onboardingService.ResetCustomer(clientID, c.Params("phone")); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{"message": "Customer onboarding reset"})
+}
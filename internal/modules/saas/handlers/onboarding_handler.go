@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OnboardingHandler handles tenant onboarding requests
+type OnboardingHandler struct {
+	onboardingService *services.OnboardingService
+}
+
+// NewOnboardingHandler creates a new onboarding handler
+func NewOnboardingHandler(onboardingService *services.OnboardingService) *OnboardingHandler {
+	return &OnboardingHandler{onboardingService: onboardingService}
+}
+
+// ProvisionWhatsApp godoc
+// @Summary Provision WhatsApp for a tenant
+// @Description Create the WAHA session, configure the HMAC-signed tenant webhook and run a self-test if the session is paired
+// @Tags Onboarding
+// @Accept json
+// @Produce json
+// @Param id path string true "Client ID"
+// @Param data body object{session_id=string} false "Optional session ID (defaults to the client's session)"
+// @Success 200 {object} services.OnboardingStatus
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /onboarding/{id}/whatsapp [post]
+func (h *OnboardingHandler) ProvisionWhatsApp(c *fiber.Ctx) error {
+	clientID := c.Params("id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "id is required",
+		})
+	}
+
+	var req struct {
+		SessionID string `json:"session_id"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request",
+			})
+		}
+	}
+
+	status, err := h.onboardingService.ProvisionWhatsApp(clientID, req.SessionID)
+	if err != nil {
+		log.Printf("❌ Failed to provision WhatsApp for client %s: %v", clientID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(status)
+}
+
+// RunSelfTest godoc
+// @Summary Run WhatsApp self-test
+// @Description Send a self-test message through the tenant session; it is verified when it arrives at the tenant webhook
+// @Tags Onboarding
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} services.OnboardingStatus
+// @Failure 400 {object} map[string]interface{}
+// @Router /onboarding/{id}/whatsapp/self-test [post]
+func (h *OnboardingHandler) RunSelfTest(c *fiber.Ctx) error {
+	clientID := c.Params("id")
+
+	status, err := h.onboardingService.RunSelfTest(clientID)
+	if err != nil {
+		log.Printf("❌ Self-test failed for client %s: %v", clientID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(status)
+}
+
+// GetStatus godoc
+// @Summary Get onboarding status
+// @Description Report WhatsApp readiness (session, QR pairing, webhook, self-test) for a tenant
+// @Tags Onboarding
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} services.OnboardingStatus
+// @Failure 404 {object} map[string]interface{}
+// @Router /onboarding/{id}/status [get]
+func (h *OnboardingHandler) GetStatus(c *fiber.Ctx) error {
+	status, err := h.onboardingService.GetStatus(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "client not found",
+		})
+	}
+
+	return c.JSON(status)
+}
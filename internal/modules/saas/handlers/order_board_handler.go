@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const (
+	// orderBoardPollInterval is how often a board stream checks for order changes
+	orderBoardPollInterval = 2 * time.Second
+	// orderBoardKeepAlive is how often an idle stream sends a comment so proxies keep it open
+	orderBoardKeepAlive = 20 * time.Second
+)
+
+type OrderBoardHandler struct {
+	boardService *services.OrderBoardService
+}
+
+func NewOrderBoardHandler(boardService *services.OrderBoardService) *OrderBoardHandler {
+	return &OrderBoardHandler{
+		boardService: boardService,
+	}
+}
+
+// GetBoard godoc
+// @Summary Order board
+// @Description Kanban view of the client's orders grouped by fulfillment stage (pending, processing, shipped, delivered, cancelled), oldest first. Delivered and cancelled columns only hold orders closed in the last 24 hours; each column loads at most 100 cards. Pass the returned cursor to /orders/board/stream to receive changes.
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param branch_id query string false "Only orders fulfilled by this branch"
+// @Success 200 {object} models.OrderBoard
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/board [get]
+func (h *OrderBoardHandler) GetBoard(c *fiber.Ctx) error {
+	clientID, branchID, err := boardScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	board, err := h.boardService.GetBoard(clientID, branchID)
+	if err != nil {
+		log.Printf("❌ Failed to load order board: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(board)
+}
+
+// StreamBoard godoc
+// @Summary Order board updates (SSE)
+// @Description Server-Sent Events stream of order cards changed after the cursor (new orders, stage moves, payments). Each "order" event carries a full card; apply it if its version is newer than the card shown. Changes appear within a few seconds. Without a cursor the stream starts with a "board" event holding the whole board.
+// @Tags Orders
+// @Produce text/event-stream
+// @Param client_id query string true "Client ID"
+// @Param branch_id query string false "Only orders fulfilled by this branch"
+// @Param cursor query string false "Cursor of the loaded board (RFC 3339)"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/board/stream [get]
+func (h *OrderBoardHandler) StreamBoard(c *fiber.Ctx) error {
+	clientID, branchID, err := boardScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	var board *models.OrderBoard
+	var cursor time.Time
+	if raw := c.Query("cursor"); raw != "" {
+		if cursor, err = time.Parse(time.RFC3339Nano, raw); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid cursor, use RFC 3339"})
+		}
+	} else {
+		if board, err = h.boardService.GetBoard(clientID, branchID); err != nil {
+			log.Printf("❌ Failed to load order board: %v", err)
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+		cursor = board.Cursor
+	}
+	feed := h.boardService.NewFeed(clientID, branchID, cursor)
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The writer runs after the handler returns, so it must not touch c
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		fmt.Fprint(w, "retry: 3000\n\n")
+		if board != nil {
+			writeBoardEvent(w, "board", board)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		lastWrite := time.Now()
+		for {
+			time.Sleep(orderBoardPollInterval)
+
+			cards, err := h.boardService.Poll(feed)
+			if err != nil {
+				log.Printf("⚠️ Order board stream for client %s: %v", clientID, err)
+				continue
+			}
+			for i := range cards {
+				writeBoardEvent(w, "order", &cards[i])
+			}
+			if len(cards) == 0 && time.Since(lastWrite) < orderBoardKeepAlive {
+				continue
+			}
+			if len(cards) == 0 {
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+
+			// A failed flush means the board was closed
+			if err := w.Flush(); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+	})
+
+	return nil
+}
+
+// MoveOrder godoc
+// @Summary Move an order on the board
+// @Description Drag an order card to another fulfillment stage. Send the card's version: if the order changed since (another device, payment, driver update), nothing is applied and 409 returns the current card. Cards move forward through pending → processing → packed → shipped → delivered (packing may be skipped) or back one stage; unpaid orders (except COD) can't leave pending, and cancelling uses /orders/{id}/cancel.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param move body models.OrderStageRequest true "Target stage and card version"
+// @Success 200 {object} models.OrderBoardCard
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /orders/{id}/stage [post]
+func (h *OrderBoardHandler) MoveOrder(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid order id"})
+	}
+
+	var req models.OrderStageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	if req.Stage == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "stage is required"})
+	}
+
+	card, err := h.boardService.MoveOrder(clientID.String(), orderID.String(), &req)
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrOrderVersionConflict), errors.Is(err, services.ErrInvalidStageMove), errors.Is(err, services.ErrOrderUnpaid):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error(), "order": card})
+	case err != nil:
+		log.Printf("❌ Failed to move order %s: %v", orderID, err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(card)
+}
+
+// boardScope reads the client and optional branch a board request is for
+func boardScope(c *fiber.Ctx) (string, string, error) {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return "", "", errors.New("client_id is required")
+	}
+	branchID := c.Query("branch_id")
+	if branchID != "" {
+		if _, err := uuid.Parse(branchID); err != nil {
+			return "", "", errors.New("invalid branch_id")
+		}
+	}
+	return clientID.String(), branchID, nil
+}
+
+// writeBoardEvent writes one SSE event with a JSON payload
+func writeBoardEvent(w *bufio.Writer, event string, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("⚠️ Failed to encode %s event: %v", event, err)
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/jobs"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type OutboundMessageHandler struct {
+	outboundService *services.OutboundMessageService
+}
+
+func NewOutboundMessageHandler(outboundService *services.OutboundMessageService) *OutboundMessageHandler {
+	return &OutboundMessageHandler{outboundService: outboundService}
+}
+
+// ListOutboundMessages godoc
+// @Summary List queued WhatsApp messages
+// @Description Outgoing WhatsApp messages of the outbound queue newest first, with attempts, last error and next retry. Messages still failing after the last retry have status dead_letter. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param client_id query string false "Client ID"
+// @Param status query string false "pending, processing, retrying, completed, failed or dead_letter"
+// @Param limit query int false "Max messages (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/outbound-messages [get]
+func (h *OutboundMessageHandler) ListOutboundMessages(c *fiber.Ctx) error {
+	var clientID *uuid.UUID
+	if v := c.Query("client_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client_id"})
+		}
+		clientID = &id
+	}
+
+	messages, err := h.outboundService.List(c.Context(), clientID, jobs.JobStatus(c.Query("status")), c.QueryInt("limit", 100))
+	if err != nil {
+		log.Printf("❌ Failed to list outbound messages: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list outbound messages"})
+	}
+
+	return c.JSON(fiber.Map{
+		"messages": messages,
+		"count":    len(messages),
+	})
+}
+
+// RetryOutboundMessage godoc
+// @Summary Retry a failed WhatsApp message
+// @Description Queues a failed or dead-letter message again with a fresh set of retries, e.g. after the WhatsApp provider is back. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Outbound message ID"
+// @Success 200 {object} services.OutboundMessage
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/outbound-messages/{id}/retry [post]
+func (h *OutboundMessageHandler) RetryOutboundMessage(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid outbound message id"})
+	}
+
+	message, err := h.outboundService.Retry(c.Context(), id)
+	if errors.Is(err, services.ErrOutboundMessageNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(message)
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PaymentEventHandler struct {
+	paymentEventService *services.PaymentEventService
+}
+
+func NewPaymentEventHandler(paymentEventService *services.PaymentEventService) *PaymentEventHandler {
+	return &PaymentEventHandler{paymentEventService: paymentEventService}
+}
+
+// ListPaymentEvents godoc
+// @Summary List payment webhook events
+// @Description Payment gateway webhooks newest first, with the raw payload, signature status and processing result. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param gateway query string false "Gateway (midtrans)"
+// @Param order_id query string false "Gateway order reference"
+// @Param result query string false "received, processed, failed, ignored or rejected"
+// @Param signature_status query string false "valid, invalid, missing or unchecked"
+// @Param from query string false "Received from (YYYY-MM-DD)"
+// @Param to query string false "Received until, inclusive (YYYY-MM-DD)"
+// @Param limit query int false "Max events (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/payment-events [get]
+func (h *PaymentEventHandler) ListPaymentEvents(c *fiber.Ctx) error {
+	filter := models.PaymentEventFilter{
+		Gateway:         c.Query("gateway"),
+		OrderID:         c.Query("order_id"),
+		Result:          c.Query("result"),
+		SignatureStatus: c.Query("signature_status"),
+		Limit:           c.QueryInt("limit", 100),
+	}
+	if c.Query("from") != "" || c.Query("to") != "" {
+		from, to, err := parseDateRange(c)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+		}
+		filter.From, filter.To = &from, &to
+	}
+
+	events, err := h.paymentEventService.List(filter)
+	if err != nil {
+		log.Printf("❌ Failed to list payment events: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list payment events"})
+	}
+
+	return c.JSON(fiber.Map{
+		"events": events,
+		"count":  len(events),
+	})
+}
+
+// ReplayPaymentEvent godoc
+// @Summary Replay a payment webhook event
+// @Description Processes a stored webhook payload again with the current code, e.g. after fixing a bug that made it fail, and records the new result on the event. Confirming an already paid order fails harmlessly. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Payment event ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/payment-events/{id}/replay [post]
+func (h *PaymentEventHandler) ReplayPaymentEvent(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid payment event id"})
+	}
+
+	event, reply, err := h.paymentEventService.Replay(id)
+	if errors.Is(err, services.ErrPaymentEventNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to replay payment event %s: %v", id, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"event": event,
+		"reply": reply,
+	})
+}
@@ -1,31 +1,39 @@
 package handlers
 
 import (
-	"fmt"
+	"errors"
 	"log"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type PaymentHandler struct {
-	orderService *services.OrderService
+	orderService        *services.OrderService
+	branchService       *services.BranchService
+	paymentEventService *services.PaymentEventService
 }
 
-func NewPaymentHandler(orderService *services.OrderService) *PaymentHandler {
+func NewPaymentHandler(orderService *services.OrderService, branchService *services.BranchService, paymentEventService *services.PaymentEventService) *PaymentHandler {
 	return &PaymentHandler{
-		orderService: orderService,
+		orderService:        orderService,
+		branchService:       branchService,
+		paymentEventService: paymentEventService,
 	}
 }
 
 // CreateOrder godoc
 // @Summary Create a new order
-// @Description Create a new order for a customer (admin only)
+// @Description Create a new order for a customer (admin only). Retries sending the same Idempotency-Key get the original order and payment result instead of a new order.
 // @Tags Orders
 // @Accept json
 // @Produce json
+// @Param Idempotency-Key header string false "Unique key of this order, overrides the body's IdempotencyKey"
 // @Param order body services.CreateOrderRequest true "Order details"
 // @Success 200 {object} map[string]interface{}
+// @Failure 422 {object} map[string]interface{} "Idempotency key already used for a different order"
 // @Router /orders [post]
 func (h *PaymentHandler) CreateOrder(c *fiber.Ctx) error {
 	var req services.CreateOrderRequest
@@ -46,9 +54,21 @@ func (h *PaymentHandler) CreateOrder(c *fiber.Ctx) error {
 	if req.TotalAmount <= 0 {
 		return c.Status(400).JSON(fiber.Map{"error": "total_amount must be greater than 0"})
 	}
+	if key := c.Get("Idempotency-Key"); key != "" {
+		req.IdempotencyKey = key
+	}
+	if len(req.IdempotencyKey) > services.MaxIdempotencyKeyLength {
+		return c.Status(400).JSON(fiber.Map{"error": "Idempotency-Key is too long"})
+	}
 
 	// Create order
 	order, paymentResult, err := h.orderService.CreateOrder(&req)
+	if errors.Is(err, services.ErrCODNotEligible) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, services.ErrIdempotencyKeyReused) {
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
 	if err != nil {
 		log.Printf("❌ Failed to create order: %v", err)
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
@@ -63,91 +83,26 @@ func (h *PaymentHandler) CreateOrder(c *fiber.Ctx) error {
 
 // MidtransWebhook godoc
 // @Summary Midtrans payment webhook
-// @Description Handle Midtrans payment notifications
+// @Description Handle Midtrans payment notifications. Notifications need a valid signature_key, and payments are confirmed through the Midtrans status API.
 // @Tags Webhooks
 // @Accept json
 // @Produce json
 // @Param notification body map[string]interface{} true "Midtrans notification"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{} "Invalid signature or payment not confirmed by Midtrans"
 // @Router /webhooks/midtrans [post]
 func (h *PaymentHandler) MidtransWebhook(c *fiber.Ctx) error {
-	var notification map[string]interface{}
-	if err := c.BodyParser(&notification); err != nil {
-		log.Printf("❌ Failed to parse Midtrans webhook: %v", err)
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
-	}
-
-	log.Printf("📥 Midtrans webhook received: %v", notification)
-
-	// Extract order ID and transaction status
-	orderID, ok := notification["order_id"].(string)
-	if !ok {
-		log.Printf("❌ Missing order_id in Midtrans webhook")
-		return c.Status(400).JSON(fiber.Map{"error": "missing order_id"})
+	// Every notification is stored with its signature status and result (see /admin/payment-events)
+	_, reply := h.paymentEventService.HandleMidtransWebhook(c.Body())
+	if reply.Status == "rejected" {
+		return c.Status(400).JSON(fiber.Map{"error": reply.Message})
 	}
 
-	transactionStatus, ok := notification["transaction_status"].(string)
-	if !ok {
-		log.Printf("❌ Missing transaction_status in Midtrans webhook")
-		return c.Status(400).JSON(fiber.Map{"error": "missing transaction_status"})
-	}
-
-	paymentType, _ := notification["payment_type"].(string)
-	transactionID, _ := notification["transaction_id"].(string)
-
-	log.Printf("📋 Order: %s, Status: %s, Type: %s, TxID: %s",
-		orderID, transactionStatus, paymentType, transactionID)
-
-	// Handle based on transaction status
-	switch transactionStatus {
-	case "capture", "settlement":
-		// Payment successful!
-		log.Printf("✅ Payment successful for order %s", orderID)
-
-		err := h.orderService.ConfirmPayment(orderID, paymentType, transactionID)
-		if err != nil {
-			log.Printf("❌ Failed to confirm payment for order %s: %v", orderID, err)
-			// Return 200 anyway to prevent Midtrans from retrying
-			return c.JSON(fiber.Map{
-				"status":  "received",
-				"message": "payment received but confirmation failed",
-			})
-		}
-
-		return c.JSON(fiber.Map{
-			"status":  "success",
-			"message": "payment confirmed",
-		})
-
-	case "pending":
-		log.Printf("⏳ Payment pending for order %s", orderID)
-		return c.JSON(fiber.Map{
-			"status":  "received",
-			"message": "payment pending",
-		})
-
-	case "deny", "cancel", "expire":
-		log.Printf("❌ Payment %s for order %s", transactionStatus, orderID)
-
-		// Cancel with automatic reason based on payment status
-		reason := fmt.Sprintf("Pembayaran %s", transactionStatus)
-		err := h.orderService.CancelOrder(orderID, reason)
-		if err != nil {
-			log.Printf("❌ Failed to cancel order %s: %v", orderID, err)
-		}
-
-		return c.JSON(fiber.Map{
-			"status":  "received",
-			"message": fmt.Sprintf("payment %s", transactionStatus),
-		})
-
-	default:
-		log.Printf("⚠️  Unknown transaction status: %s for order %s", transactionStatus, orderID)
-		return c.JSON(fiber.Map{
-			"status":  "received",
-			"message": "unknown status",
-		})
-	}
+	// Always 200 otherwise, failures are kept on the event for replay instead of Midtrans retrying
+	return c.JSON(fiber.Map{
+		"status":  reply.Status,
+		"message": reply.Message,
+	})
 }
 
 // ManualPaymentConfirm godoc
@@ -157,22 +112,30 @@ func (h *PaymentHandler) MidtransWebhook(c *fiber.Ctx) error {
 // @Accept json
 // @Produce json
 // @Param id path string true "Order ID"
-// @Param payment body object{payment_method=string,reference=string,notes=string} true "Payment confirmation details"
+// @Param payment body object{payment_method=string,reference=string,notes=string,amount=number} true "Payment confirmation details"
 // @Success 200 {object} map[string]interface{}
 // @Router /orders/{id}/confirm-payment [post]
 func (h *PaymentHandler) ManualPaymentConfirm(c *fiber.Ctx) error {
 	orderID := c.Params("id")
 
 	var req struct {
-		PaymentMethod string `json:"payment_method"` // bank_transfer, qris, cod, etc
-		Reference     string `json:"reference"`      // Transaction reference
-		Notes         string `json:"notes"`
+		PaymentMethod string   `json:"payment_method"` // bank_transfer, qris, cod, etc
+		Reference     string   `json:"reference"`      // Transaction reference
+		Notes         string   `json:"notes"`
+		Amount        *float64 `json:"amount"` // Amount shown on payment proof (optional)
 	}
 
 	if err := c.BodyParser(&req); err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
 	}
 
+	// Check payment proof amount against order total
+	if req.Amount != nil {
+		if err := h.orderService.VerifyPaymentProof(orderID, *req.Amount); err != nil {
+			return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+		}
+	}
+
 	// Admin confirms payment
 	err := h.orderService.ConfirmPayment(orderID, req.PaymentMethod, req.Reference)
 	if err != nil {
@@ -207,6 +170,73 @@ func (h *PaymentHandler) GetOrderStatus(c *fiber.Ctx) error {
 	})
 }
 
+// RefundOrder godoc
+// @Summary Refund an order (Admin)
+// @Description Refund a paid order through its payment gateway. Omit amount (or send 0) to refund everything not refunded yet; partial refunds can be repeated up to the order total. Midtrans refunds the payment, manual and COD orders are marked for the admin to return the money. The customer and tenant admin are notified.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param refund body services.RefundOrderRequest true "Refund details"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /orders/{id}/refund [post]
+func (h *PaymentHandler) RefundOrder(c *fiber.Ctx) error {
+	var req services.RefundOrderRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+	if req.RequestedBy == "" {
+		req.RequestedBy = "admin"
+	}
+
+	order, refund, err := h.orderService.RefundOrder(c.Params("id"), &req)
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, services.ErrRefundNotAllowed) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to refund order: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Order refunded successfully",
+		"order":   order,
+		"refund":  refund,
+	})
+}
+
+// ListOrderRefunds godoc
+// @Summary List order refunds
+// @Description Refunds of an order oldest first
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /orders/{id}/refunds [get]
+func (h *PaymentHandler) ListOrderRefunds(c *fiber.Ctx) error {
+	refunds, err := h.orderService.ListRefunds(c.Params("id"))
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"refunds": refunds,
+		"count":   len(refunds),
+	})
+}
+
 // CancelOrder godoc
 // @Summary Cancel an order
 // @Description Cancel a pending order
@@ -235,6 +265,56 @@ func (h *PaymentHandler) CancelOrder(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateFulfillment godoc
+// @Summary Update an order's fulfillment
+// @Description Mark a paid (or COD) order packed, shipped or delivered. Shipping needs the courier and its tracking (resi) number; sending shipped again corrects them. The customer gets a WhatsApp update at each step.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param fulfillment body models.FulfillmentUpdateRequest true "Fulfillment stage and shipping details"
+// @Success 200 {object} models.Order
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /orders/{id}/fulfillment [put]
+func (h *PaymentHandler) UpdateFulfillment(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	orderID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid order id"})
+	}
+
+	var req models.FulfillmentUpdateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	switch req.Status {
+	case models.FulfillmentStatusPacked, models.FulfillmentStatusShipped, models.FulfillmentStatusDelivered:
+	default:
+		return c.Status(400).JSON(fiber.Map{"error": "status must be packed, shipped or delivered"})
+	}
+
+	order, err := h.orderService.UpdateFulfillment(clientID.String(), orderID.String(), &req)
+	switch {
+	case errors.Is(err, services.ErrOrderNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrShippingDetailsRequired):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStageMove), errors.Is(err, services.ErrOrderUnpaid):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to update fulfillment of order %s: %v", orderID, err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(order)
+}
+
 // UpdateOrder godoc
 // @Summary Update an order (Admin)
 // @Description Update order details like items, total amount, or admin notes
@@ -271,6 +351,7 @@ func (h *PaymentHandler) UpdateOrder(c *fiber.Ctx) error {
 // @Tags Orders
 // @Produce json
 // @Param client_id query string true "Client ID"
+// @Param branch_id query string false "Only orders fulfilled by this branch"
 // @Param limit query int false "Limit results" default(50)
 // @Success 200 {object} map[string]interface{}
 // @Router /orders [get]
@@ -285,7 +366,13 @@ func (h *PaymentHandler) ListOrders(c *fiber.Ctx) error {
 		limit = 100
 	}
 
-	orders, err := h.orderService.ListOrders(clientID, limit)
+	var orders []models.Order
+	var err error
+	if branchID := c.Query("branch_id"); branchID != "" {
+		orders, err = h.orderService.ListBranchOrders(clientID, branchID, limit)
+	} else {
+		orders, err = h.orderService.ListOrders(clientID, limit)
+	}
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
@@ -296,6 +383,30 @@ func (h *PaymentHandler) ListOrders(c *fiber.Ctx) error {
 	})
 }
 
+// GetSalesAnalytics godoc
+// @Summary Order analytics
+// @Description Order count and revenue for a period with a per-branch breakdown (test orders excluded)
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param branch_id query string false "Only this branch"
+// @Param period query string false "today, yesterday, this_week, last_week, this_month, last_month, this_year, last_30_days, last_90_days" default(this_month)
+// @Success 200 {object} models.SalesAnalytics
+// @Router /orders/analytics [get]
+func (h *PaymentHandler) GetSalesAnalytics(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	result, err := h.branchService.GetSalesAnalytics(clientID, c.Query("branch_id"), c.Query("period"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
 // GetOrderByID godoc
 // @Summary Get order by ID
 // @Description Retrieve a specific order by its ID
@@ -350,3 +461,240 @@ func (h *PaymentHandler) ListCustomerOrders(c *fiber.Ctx) error {
 		"count":  len(orders),
 	})
 }
+
+// ReviewOrder godoc
+// @Summary Review a held order (Admin)
+// @Description Approve or reject an order flagged by risk scoring. Approving initiates payment, rejecting cancels the order.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param review body services.ReviewOrderRequest true "Review decision"
+// @Success 200 {object} map[string]interface{}
+// @Router /orders/{id}/review [post]
+func (h *PaymentHandler) ReviewOrder(c *fiber.Ctx) error {
+	orderID := c.Params("id")
+
+	var req services.ReviewOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	if req.Action != "approve" && req.Action != "reject" {
+		return c.Status(400).JSON(fiber.Map{"error": "action must be approve or reject"})
+	}
+
+	order, paymentResult, err := h.orderService.ReviewOrder(orderID, &req)
+	if err != nil {
+		log.Printf("❌ Failed to review order: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Order reviewed successfully",
+		"order":   order,
+		"payment": paymentResult,
+	})
+}
+
+// GetRiskRules godoc
+// @Summary Get order risk rules
+// @Description Get fraud and anomaly detection rules for a client
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.OrderRiskRule
+// @Router /orders/risk-rules [get]
+func (h *PaymentHandler) GetRiskRules(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	rules, err := h.orderService.GetRiskRules(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(rules)
+}
+
+// UpdateRiskRules godoc
+// @Summary Update order risk rules
+// @Description Configure fraud and anomaly detection rules for a client
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param rules body models.OrderRiskRule true "Risk rules"
+// @Success 200 {object} models.OrderRiskRule
+// @Router /orders/risk-rules [put]
+func (h *PaymentHandler) UpdateRiskRules(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var rule models.OrderRiskRule
+	if err := c.BodyParser(&rule); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	updated, err := h.orderService.UpdateRiskRules(clientID, &rule)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(updated)
+}
+
+// GetPaymentRouting godoc
+// @Summary Get payment gateway routing rules
+// @Description Get the rules that choose the payment gateway per order (by preferred payment method, amount and customer segment) and the gateways available to them
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Router /orders/payment-routing [get]
+func (h *PaymentHandler) GetPaymentRouting(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	policy, err := h.orderService.GetPaymentRouting(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"policy":             policy,
+		"available_gateways": h.orderService.AvailablePaymentGateways(),
+	})
+}
+
+// UpdatePaymentRouting godoc
+// @Summary Update payment gateway routing rules
+// @Description Configure which gateway processes an order. Rules are evaluated in order and the first match wins; unmatched orders use default_gateway (or the system default).
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param policy body models.UpdatePaymentRoutingRequest true "Routing policy"
+// @Success 200 {object} models.PaymentRoutingPolicy
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/payment-routing [put]
+func (h *PaymentHandler) UpdatePaymentRouting(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdatePaymentRoutingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	policy, err := h.orderService.UpdatePaymentRouting(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(policy)
+}
+
+// GetCODSettings godoc
+// @Summary Get cash on delivery settings
+// @Description Get whether COD is offered and the eligibility limits based on the customer's order history
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.CODSettings
+// @Router /orders/cod-settings [get]
+func (h *PaymentHandler) GetCODSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.orderService.GetCODSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateCODSettings godoc
+// @Summary Update cash on delivery settings
+// @Description Enable COD and limit it by order amount, completed paid orders, open COD orders and cancelled COD orders (0 = no limit)
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.CODSettings true "COD settings"
+// @Success 200 {object} models.CODSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/cod-settings [put]
+func (h *PaymentHandler) UpdateCODSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var settings models.CODSettings
+	if err := c.BodyParser(&settings); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	updated, err := h.orderService.UpdateCODSettings(clientID, &settings)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(updated)
+}
+
+// ConfirmCODCash godoc
+// @Summary Confirm COD cash received (Admin)
+// @Description Mark a cash on delivery order as paid once the cash has been collected
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param body body object false "collected_by: who received the cash"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/{id}/cod/confirm-cash [post]
+func (h *PaymentHandler) ConfirmCODCash(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req struct {
+		CollectedBy string `json:"collected_by"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+	if req.CollectedBy == "" {
+		req.CollectedBy = "admin"
+	}
+
+	order, err := h.orderService.ConfirmCODCash(c.Params("id"), clientID, req.CollectedBy)
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "COD payment confirmed",
+		"order":   order,
+	})
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PaymentReminderHandler struct {
+	reminderService *services.PaymentReminderService
+}
+
+func NewPaymentReminderHandler(reminderService *services.PaymentReminderService) *PaymentReminderHandler {
+	return &PaymentReminderHandler{
+		reminderService: reminderService,
+	}
+}
+
+// GetPaymentReminderSettings godoc
+// @Summary Get payment reminder ladder
+// @Description Reminders sent to customers of orders still waiting for payment, each a number of minutes before the payment link expires. Until saved, a disabled default ladder (23h, 6h, 1h) is returned.
+// @Tags Orders
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.PaymentReminderSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/payment-reminders [get]
+func (h *PaymentReminderHandler) GetPaymentReminderSettings(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	return c.JSON(h.reminderService.GetSettings(clientID))
+}
+
+// UpdatePaymentReminderSettings godoc
+// @Summary Update payment reminder ladder
+// @Description Save up to 5 reminder steps. Templates may use {name}, {order_number}, {total}, {payment_link} and {time_left}. Only the latest due step is sent, steps due within 15 minutes of the order are skipped, and the remaining steps are cancelled once the order is paid or cancelled. default_expiry_minutes is the payment window of orders whose gateway reports no expiry (default 1440).
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdatePaymentReminderSettingsRequest true "Reminder ladder"
+// @Success 200 {object} models.PaymentReminderSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /orders/payment-reminders [put]
+func (h *PaymentReminderHandler) UpdatePaymentReminderSettings(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdatePaymentReminderSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.reminderService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// GetOrderPaymentReminders godoc
+// @Summary Payment reminders of an order
+// @Description The reminder ladder applied to an order: sent (and whether payment followed), scheduled, skipped, or cancelled because the order was paid or cancelled first
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.PaymentReminderSchedule
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /orders/{id}/payment-reminders [get]
+func (h *PaymentReminderHandler) GetOrderPaymentReminders(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	schedule, err := h.reminderService.GetSchedule(clientID, c.Params("id"))
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(schedule)
+}
+
+// GetPaymentReminderStats godoc
+// @Summary Payment reminder effectiveness
+// @Description Reminders sent in the period per ladder step, and how many were followed by payment (credited to the last reminder before it), with the average minutes from reminder to payment
+// @Tags Analytics
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param period query string false "today, yesterday, this_week, last_week, this_month, last_month, this_year, last_30_days, last_90_days" default(last_30_days)
+// @Success 200 {object} models.PaymentReminderStats
+// @Failure 400 {object} map[string]interface{}
+// @Router /analytics/payment-reminders [get]
+func (h *PaymentReminderHandler) GetPaymentReminderStats(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	stats, err := h.reminderService.GetStats(clientID, c.Query("period"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(stats)
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"strconv"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
@@ -9,13 +11,21 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxProductImageSize is the largest product image accepted before resizing
+const maxProductImageSize = 10 * 1024 * 1024
+
+// maxProductImportSize is the largest CSV/XLSX file accepted by the bulk import
+const maxProductImportSize = 4 * 1024 * 1024
+
 type ProductHandler struct {
-	productService *services.ProductService
+	productService  *services.ProductService
+	waitlistService *services.WaitlistService
 }
 
-func NewProductHandler(productService *services.ProductService) *ProductHandler {
+func NewProductHandler(productService *services.ProductService, waitlistService *services.WaitlistService) *ProductHandler {
 	return &ProductHandler{
-		productService: productService,
+		productService:  productService,
+		waitlistService: waitlistService,
 	}
 }
 
@@ -386,3 +396,211 @@ func (h *ProductHandler) ToggleProductStatus(c *fiber.Ctx) error {
 
 	return c.JSON(product)
 }
+
+// GetWaitlist godoc
+// @Summary Get product waitlist
+// @Description List customers waiting for an out-of-stock product (restock alerts and pre-orders) with conversion stats (requires authentication)
+// @Tags Products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param status query string false "offered, waiting, notified, converted, cancelled"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/waitlist [get]
+func (h *ProductHandler) GetWaitlist(c *fiber.Ctx) error {
+	clientIDStr, ok := c.Locals("clientID").(string)
+	if !ok || clientIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	clientID, err := uuid.Parse(clientIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid client_id",
+		})
+	}
+
+	entries, stats, err := h.waitlistService.GetWaitlist(clientID, c.Params("id"), c.Query("status"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"entries": entries,
+		"count":   len(entries),
+		"stats":   stats,
+	})
+}
+
+// UploadProductImage godoc
+// @Summary Upload product image
+// @Description Upload a product image. The image is resized and stored with thumbnail and WhatsApp-optimized (<1MB) variants; the previous image is removed (requires authentication)
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Param file formData file true "Product image (jpeg, png, gif)"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/{id}/image [post]
+func (h *ProductHandler) UploadProductImage(c *fiber.Ctx) error {
+	clientIDStr, ok := c.Locals("clientID").(string)
+	if !ok || clientIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	clientID, err := uuid.Parse(clientIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid client_id",
+		})
+	}
+
+	productID := c.Params("id")
+	if productID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Product ID is required",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No file uploaded",
+		})
+	}
+
+	if fileHeader.Size > maxProductImageSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Image exceeds maximum size of 10MB",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	product, err := h.productService.UploadProductImage(productID, clientID, file)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(product)
+}
+
+// RemoveProductImage godoc
+// @Summary Remove product image
+// @Description Remove a product image and delete its hosted variants (requires authentication)
+// @Tags Products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.Product
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/image [delete]
+func (h *ProductHandler) RemoveProductImage(c *fiber.Ctx) error {
+	clientIDStr, ok := c.Locals("clientID").(string)
+	if !ok || clientIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	clientID, err := uuid.Parse(clientIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid client_id",
+		})
+	}
+
+	product, err := h.productService.RemoveProductImage(c.Params("id"), clientID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(product)
+}
+
+// ImportProducts godoc
+// @Summary Import products in bulk
+// @Description Create or update products from a CSV or XLSX file (first sheet), one product per row (requires authentication). Columns are matched by header: name, sku, description, category, price, stock, image_url, is_active, preorder (Indonesian headers like nama, harga, stok also work). Rows are matched to existing products by SKU, or by name without SKU; empty cells keep the current value, and new products need a name and price. Invalid rows are skipped and listed in errors. Use dry_run=true to preview.
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param file formData file true "Product list (.csv or .xlsx, max 4MB)"
+// @Param dry_run query bool false "Validate without saving"
+// @Success 200 {object} models.ProductImportResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /products/import [post]
+func (h *ProductHandler) ImportProducts(c *fiber.Ctx) error {
+	clientIDStr, ok := c.Locals("clientID").(string)
+	if !ok || clientIDStr == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Unauthorized",
+		})
+	}
+
+	clientID, err := uuid.Parse(clientIDStr)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid client_id",
+		})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "No file uploaded",
+		})
+	}
+
+	if fileHeader.Size > maxProductImportSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "File exceeds maximum size of 4MB",
+		})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Failed to read uploaded file",
+		})
+	}
+	defer file.Close()
+
+	result, err := h.productService.ImportProducts(clientID, file, fileHeader.Filename, c.QueryBool("dry_run", false))
+	if errors.Is(err, services.ErrProductImportFile) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to import products: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(result)
+}
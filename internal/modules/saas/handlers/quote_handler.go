@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type QuoteHandler struct {
+	quoteService *services.QuoteService
+}
+
+func NewQuoteHandler(quoteService *services.QuoteService) *QuoteHandler {
+	return &QuoteHandler{
+		quoteService: quoteService,
+	}
+}
+
+// CreateQuote godoc
+// @Summary Create a quote
+// @Description Build a quote from items or the customer's cart, optionally sending it via WhatsApp right away
+// @Tags Quotes
+// @Accept json
+// @Produce json
+// @Param quote body models.CreateQuoteRequest true "Quote details"
+// @Success 201 {object} models.Quote
+// @Failure 400 {object} map[string]interface{}
+// @Router /quotes [post]
+func (h *QuoteHandler) CreateQuote(c *fiber.Ctx) error {
+	var req models.CreateQuoteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	if req.ClientID == "" || req.CustomerPhone == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id and customer_phone are required"})
+	}
+
+	quote, err := h.quoteService.CreateQuote(&req)
+	if err != nil {
+		log.Printf("❌ Failed to create quote: %v", err)
+		if quote != nil {
+			// Saved as draft but the WhatsApp send failed
+			return c.Status(500).JSON(fiber.Map{"error": err.Error(), "quote": quote})
+		}
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(201).JSON(quote)
+}
+
+// ListQuotes godoc
+// @Summary List quotes
+// @Description List a client's quotes
+// @Tags Quotes
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param status query string false "draft, sent, accepted, rejected, expired"
+// @Param limit query int false "Limit results" default(50)
+// @Success 200 {object} map[string]interface{}
+// @Router /quotes [get]
+func (h *QuoteHandler) ListQuotes(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	quotes, err := h.quoteService.ListQuotes(clientID, c.Query("status"), c.QueryInt("limit", 50))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"quotes": quotes,
+		"count":  len(quotes),
+	})
+}
+
+// GetQuoteStats godoc
+// @Summary Quote win/loss stats
+// @Description Win and loss rates of quotes created in the last N days
+// @Tags Quotes
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param days query int false "Period in days" default(30)
+// @Success 200 {object} models.QuoteStats
+// @Router /quotes/stats [get]
+func (h *QuoteHandler) GetQuoteStats(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	stats, err := h.quoteService.GetStats(clientID, c.QueryInt("days", 30))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(stats)
+}
+
+// GetQuote godoc
+// @Summary Get quote by ID
+// @Tags Quotes
+// @Produce json
+// @Param id path string true "Quote ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.Quote
+// @Failure 404 {object} map[string]interface{}
+// @Router /quotes/{id} [get]
+func (h *QuoteHandler) GetQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuote(c.Params("id"), c.Query("client_id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(quote)
+}
+
+// SendQuote godoc
+// @Summary Send quote to customer
+// @Description Send (or re-send) the quote to the customer via WhatsApp
+// @Tags Quotes
+// @Produce json
+// @Param id path string true "Quote ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.Quote
+// @Failure 400 {object} map[string]interface{}
+// @Router /quotes/{id}/send [post]
+func (h *QuoteHandler) SendQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.SendQuote(c.Params("id"), c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(quote)
+}
+
+// DownloadQuotePDF godoc
+// @Summary Download quote PDF
+// @Tags Quotes
+// @Produce application/pdf
+// @Param id path string true "Quote ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]interface{}
+// @Router /quotes/{id}/pdf [get]
+func (h *QuoteHandler) DownloadQuotePDF(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuote(c.Params("id"), c.Query("client_id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return h.sendPDF(c, quote)
+}
+
+// AcceptQuote godoc
+// @Summary Accept quote (admin)
+// @Description Accept a quote on the customer's behalf, converting it into an order with payment instructions
+// @Tags Quotes
+// @Produce json
+// @Param id path string true "Quote ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /quotes/{id}/accept [post]
+func (h *QuoteHandler) AcceptQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuote(c.Params("id"), c.Query("client_id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return h.accept(c, quote)
+}
+
+// RejectQuote godoc
+// @Summary Reject quote (admin)
+// @Description Mark a quote as lost
+// @Tags Quotes
+// @Produce json
+// @Param id path string true "Quote ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.Quote
+// @Failure 400 {object} map[string]interface{}
+// @Router /quotes/{id}/reject [post]
+func (h *QuoteHandler) RejectQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuote(c.Params("id"), c.Query("client_id"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if err := h.quoteService.RejectQuote(quote); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(quote)
+}
+
+// ViewPublicQuote godoc
+// @Summary View quote (customer link)
+// @Description Quote details for the customer link sent via WhatsApp
+// @Tags Quotes
+// @Produce json
+// @Param token path string true "Quote access token"
+// @Success 200 {object} models.Quote
+// @Failure 404 {object} map[string]interface{}
+// @Router /q/{token} [get]
+func (h *QuoteHandler) ViewPublicQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuoteByToken(c.Params("token"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(quote)
+}
+
+// DownloadPublicQuotePDF godoc
+// @Summary Download quote PDF (customer link)
+// @Tags Quotes
+// @Produce application/pdf
+// @Param token path string true "Quote access token"
+// @Success 200 {file} binary
+// @Failure 404 {object} map[string]interface{}
+// @Router /q/{token}/pdf [get]
+func (h *QuoteHandler) DownloadPublicQuotePDF(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuoteByToken(c.Params("token"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return h.sendPDF(c, quote)
+}
+
+// AcceptPublicQuote godoc
+// @Summary Accept quote (customer link)
+// @Description Customer accepts the quote from the link; an order is created and payment instructions are sent via WhatsApp
+// @Tags Quotes
+// @Produce json
+// @Param token path string true "Quote access token"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /q/{token}/accept [post]
+func (h *QuoteHandler) AcceptPublicQuote(c *fiber.Ctx) error {
+	quote, err := h.quoteService.GetQuoteByToken(c.Params("token"))
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return h.accept(c, quote)
+}
+
+// accept converts the quote into an order and returns the payment details
+func (h *QuoteHandler) accept(c *fiber.Ctx, quote *models.Quote) error {
+	order, paymentResult, err := h.quoteService.AcceptQuote(quote)
+	if err != nil {
+		log.Printf("❌ Failed to accept quote %s: %v", quote.QuoteNumber, err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Quote accepted",
+		"quote":   quote,
+		"order":   order,
+		"payment": paymentResult,
+	})
+}
+
+// sendPDF renders the quote as a PDF attachment
+func (h *QuoteHandler) sendPDF(c *fiber.Ctx, quote *models.Quote) error {
+	pdf, err := h.quoteService.RenderPDF(quote)
+	if err != nil {
+		log.Printf("❌ Failed to render quote PDF: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": "failed to render quote"})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s.pdf", quote.QuoteNumber))
+	return c.Send(pdf)
+}
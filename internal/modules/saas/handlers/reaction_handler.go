@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReactionHandler struct {
+	reactionService *services.ReactionService
+}
+
+func NewReactionHandler(reactionService *services.ReactionService) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+	}
+}
+
+// GetReactionSettings godoc
+// @Summary Get customer reaction settings
+// @Description Get which emoji reactions map to which intent (confirm, cancel). Defaults to 👍 = confirm and ❌ = cancel.
+// @Tags Reactions
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.ReactionSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /reaction-settings [get]
+func (h *ReactionHandler) GetReactionSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.reactionService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateReactionSettings godoc
+// @Summary Update customer reaction settings
+// @Description Map emoji reactions to intents. confirm confirms the customer's unconfirmed COD order or accepts their pending quote; cancel cancels the unconfirmed COD order or rejects the pending quote. Every reaction also fires the reaction_received workflow event (with emoji, intent, action and the referenced message), even when enabled is false.
+// @Tags Reactions
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateReactionSettingsRequest true "Reaction settings"
+// @Success 200 {object} models.ReactionSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /reaction-settings [put]
+func (h *ReactionHandler) UpdateReactionSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateReactionSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.reactionService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
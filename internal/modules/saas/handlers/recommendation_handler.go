@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RecommendationHandler struct {
+	recommendationService *services.RecommendationService
+}
+
+func NewRecommendationHandler(recommendationService *services.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{
+		recommendationService: recommendationService,
+	}
+}
+
+// GetRecommendationSettings godoc
+// @Summary Get product recommendation settings
+// @Description Get whether complementary products are suggested in chat, at which points and how many. Off by default.
+// @Tags Recommendations
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.RecommendationSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /recommendation-settings [get]
+func (h *RecommendationHandler) GetRecommendationSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.recommendationService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateRecommendationSettings godoc
+// @Summary Update product recommendation settings
+// @Description Suggest products often ordered together with the customer's cart after a product is added (after_add_to_cart) and after checkout (after_checkout). Without co-purchase data the customer's own earlier orders are used. With use_llm the candidates are ranked by the LLM against the conversation. A customer gets at most one suggestion per cooldown_minutes and the same product at most once a day.
+// @Tags Recommendations
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateRecommendationSettingsRequest true "Recommendation settings"
+// @Success 200 {object} models.RecommendationSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /recommendation-settings [put]
+func (h *RecommendationHandler) UpdateRecommendationSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateRecommendationSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.recommendationService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// GetRecommendationStats godoc
+// @Summary Get product recommendation conversions
+// @Description Count suggested products, how many were added to the cart and how many were ordered within 7 days, per trigger point, per ranking (co_purchase, llm, history) and for the top products
+// @Tags Recommendations
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param from query string false "Start date (YYYY-MM-DD), default 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), default today"
+// @Success 200 {object} models.RecommendationStats
+// @Failure 400 {object} map[string]interface{}
+// @Router /recommendations/stats [get]
+func (h *RecommendationHandler) GetRecommendationStats(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	stats, err := h.recommendationService.Stats(clientID, from, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(stats)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReconciliationHandler struct {
+	reconciliationService *services.ReconciliationService
+}
+
+func NewReconciliationHandler(reconciliationService *services.ReconciliationService) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+	}
+}
+
+// GetReconciliation godoc
+// @Summary Payment reconciliation for a day
+// @Description Match the day's paid orders against payment gateway settlements and flag mismatches (paid order with no settlement, settlement with no order, unpaid order with a settlement, amount mismatch). The report is generated on first request and by the daily job; pass refresh=true to pull the gateway again.
+// @Tags Reconciliation
+// @Produce json
+// @Produce text/csv
+// @Param date path string true "Date (YYYY-MM-DD, client timezone)"
+// @Param client_id query string true "Client ID"
+// @Param refresh query bool false "Re-run the reconciliation"
+// @Param format query string false "json (default) or csv"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /reconciliation/{date} [get]
+func (h *ReconciliationHandler) GetReconciliation(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	date := c.Params("date")
+	report, items, err := h.reconciliationService.GetReport(clientID, date, c.QueryBool("refresh", false))
+	if err != nil {
+		log.Printf("❌ Failed to reconcile %s: %v", date, err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	if c.Query("format") == "csv" {
+		data, err := h.reconciliationService.ExportCSV(items)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		c.Attachment(fmt.Sprintf("reconciliation_%s.csv", date))
+		return c.Send(data)
+	}
+
+	return c.JSON(fiber.Map{
+		"report": report,
+		"items":  items,
+	})
+}
+
+// ImportSettlements godoc
+// @Summary Import gateway settlement report
+// @Description Upload the settlement/transaction CSV downloaded from the Midtrans dashboard. Needed to detect settlements with no matching order, since the gateway API can only be queried per order.
+// @Tags Reconciliation
+// @Accept multipart/form-data
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param file formData file true "Settlement report CSV"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /reconciliation/settlements [post]
+func (h *ReconciliationHandler) ImportSettlements(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "file is required"})
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "failed to read file"})
+	}
+	defer file.Close()
+
+	imported, err := h.reconciliationService.ImportSettlementReport(clientID, file)
+	if err != nil {
+		log.Printf("❌ Failed to import settlement report: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "Settlement report imported",
+		"imported": imported,
+	})
+}
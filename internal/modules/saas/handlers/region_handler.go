@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/region"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type RegionHandler struct {
+	regionMigrationService *services.RegionMigrationService
+}
+
+func NewRegionHandler(regionMigrationService *services.RegionMigrationService) *RegionHandler {
+	return &RegionHandler{regionMigrationService: regionMigrationService}
+}
+
+// MigrateClientRegionRequest is the region a client's data moves to
+type MigrateClientRegionRequest struct {
+	Region string `json:"region"` // Region code (see GET /admin/regions)
+}
+
+// GetRegions godoc
+// @Summary List regions
+// @Description Regions with their own database, vector DB and storage that tenants can be pinned to. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/regions [get]
+func (h *RegionHandler) GetRegions(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"regions": h.regionMigrationService.Regions(),
+	})
+}
+
+// MigrateClientRegion godoc
+// @Summary Move a client to another region
+// @Description Copies the client's rows to the target region's database, verifies the row counts, rebuilds its knowledge base vectors there and then pins the client to the region. Runs in the background; follow it with the region migrations endpoint. Source rows are kept and uploaded files are not moved. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param request body MigrateClientRegionRequest true "Target region"
+// @Success 202 {object} models.RegionMigration
+// @Failure 400 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/clients/{id}/region [post]
+func (h *RegionHandler) MigrateClientRegion(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	var req MigrateClientRegionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+	if req.Region == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "region is required"})
+	}
+
+	migration, err := h.regionMigrationService.Migrate(clientID, req.Region)
+	if errors.Is(err, services.ErrRegionMigrationRunning) || errors.Is(err, services.ErrSameRegion) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, region.ErrUnknownRegion) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to start region migration of client %s: %v", clientID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(migration)
+}
+
+// GetClientRegionMigrations godoc
+// @Summary Region migrations of a client
+// @Description Region moves of the client newest first, with the rows copied per table. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param limit query int false "Max migrations (default 20, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /admin/clients/{id}/region/migrations [get]
+func (h *RegionHandler) GetClientRegionMigrations(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	migrations, err := h.regionMigrationService.History(clientID, c.QueryInt("limit", 20))
+	if err != nil {
+		log.Printf("❌ Failed to list region migrations of client %s: %v", clientID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list region migrations"})
+	}
+
+	return c.JSON(fiber.Map{
+		"migrations": migrations,
+	})
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ReportHandler struct {
+	reportService *services.ReportService
+}
+
+func NewReportHandler(reportService *services.ReportService) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+	}
+}
+
+// GetSalesReport godoc
+// @Summary Sales report
+// @Description Orders, paid and cancelled orders, revenue, refunds, net revenue and average order value of orders created in the range, transactions (OCR and manual) dated in the range, and daily order totals. Test orders are excluded.
+// @Tags Reports
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Success 200 {object} models.SalesReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /reports/sales [get]
+func (h *ReportHandler) GetSalesReport(c *fiber.Ctx) error {
+	clientID, from, to, err := reportScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.reportService.GetSalesReport(clientID, from, to)
+	return h.respond(c, report, err)
+}
+
+// GetTopProducts godoc
+// @Summary Top products report
+// @Description Products of paid orders created in the range, by revenue or units sold (test orders excluded)
+// @Tags Reports
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Param sort query string false "revenue or units" default(revenue)
+// @Param limit query int false "Limit results (max 100)" default(10)
+// @Success 200 {object} models.TopProductsReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /reports/top-products [get]
+func (h *ReportHandler) GetTopProducts(c *fiber.Ctx) error {
+	clientID, from, to, err := reportScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.reportService.GetTopProducts(clientID, from, to, c.Query("sort"), c.QueryInt("limit", 10))
+	return h.respond(c, report, err)
+}
+
+// GetConversationReport godoc
+// @Summary Conversation report
+// @Description WhatsApp conversation volume in the range: customer messages, AI replies, agent replies, distinct customers and AI reply rate, with daily counts
+// @Tags Reports
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Success 200 {object} models.ConversationReport
+// @Failure 400 {object} map[string]interface{}
+// @Router /reports/conversations [get]
+func (h *ReportHandler) GetConversationReport(c *fiber.Ctx) error {
+	clientID, from, to, err := reportScope(c)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	report, err := h.reportService.GetConversationReport(clientID, from, to)
+	return h.respond(c, report, err)
+}
+
+func (h *ReportHandler) respond(c *fiber.Ctx, report interface{}, err error) error {
+	if errors.Is(err, services.ErrReportRangeTooLong) {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to build report: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.JSON(report)
+}
+
+// reportScope reads the client and date range a report is for
+func reportScope(c *fiber.Ctx) (uuid.UUID, time.Time, time.Time, error) {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return uuid.Nil, time.Time{}, time.Time{}, errors.New("client_id is required")
+	}
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return uuid.Nil, time.Time{}, time.Time{}, err
+	}
+	return clientID, from, to, nil
+}
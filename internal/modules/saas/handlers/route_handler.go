@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+type RouteHandler struct {
+	registry *RouteRegistry
+}
+
+func NewRouteHandler(registry *RouteRegistry) *RouteHandler {
+	return &RouteHandler{registry: registry}
+}
+
+// GetRoutes godoc
+// @Summary List routes and their access policies
+// @Description The authorization matrix: every mounted route (versioned and legacy paths) with the access policy it declares (public, authenticated or admin_key). Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/routes [get]
+func (h *RouteHandler) GetRoutes(c *fiber.Ctx) error {
+	routes := h.registry.Routes()
+	return c.JSON(fiber.Map{
+		"routes": routes,
+		"count":  len(routes),
+	})
+}
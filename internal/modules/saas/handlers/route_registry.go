@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RoutePolicy is the access rule a route declares. Its middleware enforces it and must reject a request
+// without credentials (401 or 403) before the route's handler runs.
+type RoutePolicy struct {
+	Name       string
+	Middleware []fiber.Handler
+}
+
+// PublicRoute is the policy of routes callable without credentials: health checks, webhooks (verified by
+// their signature), public links and workflow webhooks (authorized by their token) and sign-in
+var PublicRoute = &RoutePolicy{Name: "public"}
+
+// NewRoutePolicy creates a policy enforced by the given middleware
+func NewRoutePolicy(name string, middleware ...fiber.Handler) *RoutePolicy {
+	return &RoutePolicy{Name: name, Middleware: middleware}
+}
+
+// Protected reports whether the policy requires credentials
+func (p *RoutePolicy) Protected() bool {
+	return len(p.Middleware) > 0
+}
+
+// RouteInfo is one route of the authorization matrix
+type RouteInfo struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Policy string `json:"policy"`
+}
+
+type registeredRoute struct {
+	method string
+	path   string
+	policy *RoutePolicy
+	static bool // Serves files below path
+}
+
+// RouteRegistry records the access policy of every route as it is mounted, and verifies that the app has no
+// route mounted around it
+type RouteRegistry struct {
+	app    *fiber.App
+	stub   fiber.Handler // Replaces the routes' handlers, set by tests only
+	routes []registeredRoute
+}
+
+// NewRouteRegistry creates the route registry of an app
+func NewRouteRegistry(app *fiber.App) *RouteRegistry {
+	return &RouteRegistry{app: app}
+}
+
+// StubHandlers mounts the routes registered from now on with stub in place of their handler, keeping their
+// policy and route middleware. Tests use it to check the policies without running the handlers.
+func (g *RouteRegistry) StubHandlers(stub fiber.Handler) {
+	g.stub = stub
+}
+
+// Static serves files below prefix as a public route
+func (g *RouteRegistry) Static(prefix, root string) {
+	g.app.Static(prefix, root)
+	g.routes = append(g.routes, registeredRoute{method: fiber.MethodGet, path: prefix, policy: PublicRoute, static: true})
+}
+
+// Routes returns the authorization matrix: every route with the policy it declares, sorted by path
+func (g *RouteRegistry) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(g.routes))
+	for _, route := range g.routes {
+		routes = append(routes, RouteInfo{Method: route.method, Path: route.path, Policy: route.policy.Name})
+	}
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// Verify checks that every route of the app was mounted through the registry, so none escapes its policy
+func (g *RouteRegistry) Verify() error {
+	declared := make(map[string]bool, len(g.routes))
+	for _, route := range g.routes {
+		declared[routeKey(route.method, route.path)] = true
+	}
+
+	var problems []string
+	for _, route := range g.app.GetRoutes(true) {
+		method := route.Method
+		if method == fiber.MethodHead {
+			method = fiber.MethodGet // Fiber mounts HEAD along with every GET
+		}
+		if !declared[routeKey(method, route.Path)] {
+			problems = append(problems, fmt.Sprintf("%s %s is not mounted through the route registry", route.Method, route.Path))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%d routes without an access policy:\n  %s", len(problems), strings.Join(problems, "\n  "))
+	}
+	return nil
+}
+
+func (g *RouteRegistry) record(method, path string, policy *RoutePolicy) {
+	g.routes = append(g.routes, registeredRoute{method: method, path: path, policy: policy})
+}
+
+// routeKey compares paths the way Fiber matches them: case-insensitive and without a trailing slash
+func routeKey(method, path string) string {
+	path = strings.ToLower(path)
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return method + " " + path
+}
+
+// joinRoutePath joins a group prefix and a route path the way Fiber does
+func joinRoutePath(prefix, path string) string {
+	if path == "" {
+		return prefix
+	}
+	if path[0] != '/' {
+		path = "/" + path
+	}
+	return strings.TrimRight(prefix, "/") + path
+}
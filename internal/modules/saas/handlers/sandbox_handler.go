@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// sandboxModeMarker is included in every sandbox response so test data is never mistaken for live data
+const sandboxModeMarker = "TEST MODE"
+
+// SandboxHandler handles sandbox (test mode) requests
+type SandboxHandler struct {
+	sandboxService *services.SandboxService
+	webhookService *services.WebhookService
+	orderService   *services.OrderService
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler(sandboxService *services.SandboxService, webhookService *services.WebhookService, orderService *services.OrderService) *SandboxHandler {
+	return &SandboxHandler{
+		sandboxService: sandboxService,
+		webhookService: webhookService,
+		orderService:   orderService,
+	}
+}
+
+// SetMode godoc
+// @Summary Enable or disable sandbox mode
+// @Description Toggle test mode for a client. In sandbox mode WhatsApp messages are captured instead of sent and payments use a fake gateway
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param data body object{enabled=bool} true "Sandbox mode"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /sandbox/mode [put]
+func (h *SandboxHandler) SetMode(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+
+	client, err := h.sandboxService.SetSandboxMode(clientID, req.Enabled)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"client_id":    client.ID,
+		"sandbox_mode": client.SandboxMode,
+	})
+}
+
+// SendMessage godoc
+// @Summary Simulate an inbound WhatsApp message
+// @Description Run a simulated customer message through the bot and return the replies it produced
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param data body object{phone=string,message=string} true "Simulated message"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /sandbox/messages [post]
+func (h *SandboxHandler) SendMessage(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var req struct {
+		Phone   string `json:"phone"`
+		Message string `json:"message"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+	if req.Phone == "" || strings.TrimSpace(req.Message) == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "phone and message are required",
+		})
+	}
+
+	since := time.Now()
+	if err := h.webhookService.ProcessSandboxMessage(clientID, req.Phone, req.Message); err != nil {
+		log.Printf("❌ Sandbox message failed for client %s: %v", clientID, err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	messages, err := h.sandboxService.ListMessages(clientID, req.Phone, &since, 0)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"mode":     sandboxModeMarker,
+		"messages": messages,
+	})
+}
+
+// ListMessages godoc
+// @Summary List captured sandbox messages
+// @Description Get the simulated WhatsApp conversation captured while the client is in sandbox mode
+// @Tags Sandbox
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param phone query string false "Filter by customer phone"
+// @Param limit query int false "Maximum messages (default 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /sandbox/messages [get]
+func (h *SandboxHandler) ListMessages(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	messages, err := h.sandboxService.ListMessages(clientID, c.Query("phone"), nil, c.QueryInt("limit", 100))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"mode":     sandboxModeMarker,
+		"messages": messages,
+		"total":    len(messages),
+	})
+}
+
+// ClearMessages godoc
+// @Summary Clear captured sandbox messages
+// @Tags Sandbox
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /sandbox/messages [delete]
+func (h *SandboxHandler) ClearMessages(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	deleted, err := h.sandboxService.ClearMessages(clientID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"mode":    sandboxModeMarker,
+		"deleted": deleted,
+	})
+}
+
+// SettlePayment godoc
+// @Summary Settle a sandbox payment
+// @Description Mark a test order's fake payment as paid or failed
+// @Tags Sandbox
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param client_id query string true "Client ID"
+// @Param data body object{status=string} true "Settlement status (paid or failed)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sandbox/orders/{id}/settle [post]
+func (h *SandboxHandler) SettlePayment(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "client_id is required",
+		})
+	}
+
+	var req struct {
+		Status string `json:"status"`
+	}
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request",
+		})
+	}
+	if req.Status == "" {
+		req.Status = "paid"
+	}
+
+	order, err := h.orderService.SettleSandboxPayment(clientID, c.Params("id"), req.Status)
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"mode":  sandboxModeMarker,
+		"order": order,
+	})
+}
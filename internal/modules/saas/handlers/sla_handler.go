@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SLAHandler struct {
+	slaService *services.SLAService
+	clientRepo repositories.ClientRepo
+}
+
+func NewSLAHandler(slaService *services.SLAService, clientRepo repositories.ClientRepo) *SLAHandler {
+	return &SLAHandler{
+		slaService: slaService,
+		clientRepo: clientRepo,
+	}
+}
+
+// SLAThreadRequest identifies a customer's open thread and the agent acting on it
+type SLAThreadRequest struct {
+	CustomerPhone string `json:"customer_phone"`
+	Agent         string `json:"agent"` // Agent name or ID, as it should appear in the per-agent report
+}
+
+// GetSLASettings godoc
+// @Summary Get SLA targets
+// @Description First-response and resolution targets, and after how many idle minutes a thread counts as resolved
+// @Tags SLA
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.SLASettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /sla/settings [get]
+func (h *SLAHandler) GetSLASettings(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	return c.JSON(h.slaService.GetSettings(clientID))
+}
+
+// UpdateSLASettings godoc
+// @Summary Update SLA targets
+// @Description Set the first-response and resolution targets. Threads passing a target fire the sla_first_response_breached or sla_resolution_breached workflow event.
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateSLASettingsRequest true "SLA targets"
+// @Success 200 {object} models.SLASettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /sla/settings [put]
+func (h *SLAHandler) UpdateSLASettings(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateSLASettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.slaService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// RecordAgentResponse godoc
+// @Summary Record an agent response
+// @Description Record that an agent answered the customer outside the bot (e.g. from a helpdesk). Replies typed on the business phone are recorded automatically as agent "whatsapp_app".
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param request body SLAThreadRequest true "Customer and agent"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /sla/responses [post]
+func (h *SLAHandler) RecordAgentResponse(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req SLAThreadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	req.CustomerPhone = strings.TrimSpace(req.CustomerPhone)
+	req.Agent = strings.TrimSpace(req.Agent)
+	if req.CustomerPhone == "" || req.Agent == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "customer_phone and agent are required"})
+	}
+
+	client, err := h.clientRepo.GetByID(clientID)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "client not found"})
+	}
+
+	h.slaService.RecordResponse(client, req.CustomerPhone, req.Agent)
+	return c.JSON(fiber.Map{"status": "recorded"})
+}
+
+// ResolveThread godoc
+// @Summary Resolve a customer thread
+// @Description Close the customer's open thread (ticket closed), stopping its resolution clock. Threads without messages for idle_resolve_minutes are resolved automatically.
+// @Tags SLA
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param request body SLAThreadRequest true "Customer and agent"
+// @Success 200 {object} models.SLAThread
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /sla/resolve [post]
+func (h *SLAHandler) ResolveThread(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req SLAThreadRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	req.CustomerPhone = strings.TrimSpace(req.CustomerPhone)
+	if req.CustomerPhone == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "customer_phone is required"})
+	}
+
+	thread, err := h.slaService.Resolve(clientID, req.CustomerPhone, strings.TrimSpace(req.Agent))
+	if err != nil {
+		if errors.Is(err, services.ErrNoOpenThread) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(thread)
+}
+
+// GetSLAReport godoc
+// @Summary SLA report
+// @Description First-response and resolution time percentiles (p50/p90/p95, in seconds) and breach rates of threads opened in the period, overall and per agent ("bot" for bot answers)
+// @Tags Analytics
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param period query string false "today, yesterday, this_week, last_week, this_month, last_month, this_year, last_30_days, last_90_days" default(last_30_days)
+// @Success 200 {object} models.SLAReport
+// @Router /analytics/sla [get]
+func (h *SLAHandler) GetSLAReport(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	report, err := h.slaService.GetReport(clientID, c.Query("period"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(report)
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SplitPaymentHandler struct {
+	splitPaymentService *services.SplitPaymentService
+}
+
+func NewSplitPaymentHandler(splitPaymentService *services.SplitPaymentService) *SplitPaymentHandler {
+	return &SplitPaymentHandler{
+		splitPaymentService: splitPaymentService,
+	}
+}
+
+// SplitOrder godoc
+// @Summary Split an order between payers
+// @Description Turn a pending order into a group order: each payer gets a WhatsApp message with a payment link for their portion. Payers without an amount share what is left equally. The order is confirmed only when every portion is paid; if one payment is denied or the timeout passes first, the order is cancelled and paid portions are refunded.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param split body models.SplitOrderRequest true "Payers and timeout"
+// @Success 200 {object} models.OrderSplitSummary
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /orders/{id}/split [post]
+func (h *SplitPaymentHandler) SplitOrder(c *fiber.Ctx) error {
+	var req models.SplitOrderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	summary, err := h.splitPaymentService.Split(c.Params("id"), &req)
+	if errors.Is(err, services.ErrOrderNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, services.ErrSplitNotAllowed) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to split order: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(summary)
+}
+
+// GetSplitPayments godoc
+// @Summary Get the portions of a group order
+// @Description Payment status of every payer's portion, with the amount paid and still open
+// @Tags Orders
+// @Produce json
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.OrderSplitSummary
+// @Failure 404 {object} map[string]interface{}
+// @Router /orders/{id}/split [get]
+func (h *SplitPaymentHandler) GetSplitPayments(c *fiber.Ctx) error {
+	summary, err := h.splitPaymentService.Get(c.Params("id"))
+	if errors.Is(err, services.ErrOrderNotFound) || errors.Is(err, services.ErrSplitPaymentNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(summary)
+}
+
+// ConfirmSplitPayment godoc
+// @Summary Manually confirm a portion payment (Admin)
+// @Description Admin confirms a payer's portion of a manual or sandbox group order. Midtrans portions are confirmed by the payment webhook. The order is confirmed with the last portion.
+// @Tags Orders
+// @Accept json
+// @Produce json
+// @Param id path string true "Order ID"
+// @Param payment_id path string true "Split payment ID"
+// @Param payment body object{payment_method=string,reference=string} false "Payment details"
+// @Success 200 {object} models.OrderSplitSummary
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /orders/{id}/split/{payment_id}/confirm-payment [post]
+func (h *SplitPaymentHandler) ConfirmSplitPayment(c *fiber.Ctx) error {
+	paymentID, err := uuid.Parse(c.Params("payment_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid payment_id"})
+	}
+
+	var req struct {
+		PaymentMethod string `json:"payment_method"`
+		Reference     string `json:"reference"`
+	}
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	summary, err := h.splitPaymentService.ConfirmManualPayment(c.Params("id"), paymentID, req.PaymentMethod, req.Reference)
+	if errors.Is(err, services.ErrSplitPaymentNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if errors.Is(err, services.ErrSplitPaymentNotPending) || errors.Is(err, services.ErrSplitPaymentAutoConfirm) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to confirm split payment: %v", err)
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(summary)
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type StoreHandler struct {
+	storeService  *services.StoreService
+	branchService *services.BranchService
+}
+
+func NewStoreHandler(storeService *services.StoreService, branchService *services.BranchService) *StoreHandler {
+	return &StoreHandler{
+		storeService:  storeService,
+		branchService: branchService,
+	}
+}
+
+// CreateStore godoc
+// @Summary Create a store location
+// @Description Add a store/branch with coordinates for the store locator (requires authentication)
+// @Tags Stores
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param store body models.CreateStoreRequest true "Store data"
+// @Success 201 {object} models.Store
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /stores [post]
+func (h *StoreHandler) CreateStore(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req models.CreateStoreRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	store, err := h.storeService.CreateStore(clientID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(store)
+}
+
+// ListStores godoc
+// @Summary List store locations
+// @Description List the client's stores (requires authentication)
+// @Tags Stores
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param active_only query boolean false "Only active stores"
+// @Success 200 {object} map[string]interface{}
+// @Router /stores [get]
+func (h *StoreHandler) ListStores(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	stores, err := h.storeService.ListStores(clientID, c.Query("active_only") == "true")
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"stores": stores,
+		"total":  len(stores),
+	})
+}
+
+// FindNearest godoc
+// @Summary Find nearest stores
+// @Description List active stores ordered by distance from a point (requires authentication)
+// @Tags Stores
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param lat query number true "Latitude"
+// @Param lng query number true "Longitude"
+// @Param limit query int false "Maximum stores" default(3)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /stores/nearest [get]
+func (h *StoreHandler) FindNearest(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	latitude, latErr := strconv.ParseFloat(c.Query("lat"), 64)
+	longitude, lngErr := strconv.ParseFloat(c.Query("lng"), 64)
+	if latErr != nil || lngErr != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "lat and lng are required",
+		})
+	}
+
+	stores, err := h.storeService.FindNearest(clientID, latitude, longitude, c.QueryInt("limit", 3))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"stores": stores,
+		"total":  len(stores),
+	})
+}
+
+// GetStore godoc
+// @Summary Get store by ID
+// @Tags Stores
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Store ID"
+// @Success 200 {object} models.Store
+// @Failure 404 {object} map[string]interface{}
+// @Router /stores/{id} [get]
+func (h *StoreHandler) GetStore(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	store, err := h.storeService.GetStore(c.Params("id"), clientID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(store)
+}
+
+// UpdateStore godoc
+// @Summary Update a store location
+// @Tags Stores
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Store ID"
+// @Param store body models.UpdateStoreRequest true "Fields to update"
+// @Success 200 {object} models.Store
+// @Failure 400 {object} map[string]interface{}
+// @Router /stores/{id} [put]
+func (h *StoreHandler) UpdateStore(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req models.UpdateStoreRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	store, err := h.storeService.UpdateStore(c.Params("id"), clientID, &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(store)
+}
+
+// DeleteStore godoc
+// @Summary Delete a store location
+// @Tags Stores
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Store ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /stores/{id} [delete]
+func (h *StoreHandler) DeleteStore(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := h.storeService.DeleteStore(c.Params("id"), clientID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Store deleted successfully",
+	})
+}
+
+// ListBranchStock godoc
+// @Summary List branch stock
+// @Description Product stock at a branch (requires authentication)
+// @Tags Stores
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Store (branch) ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /stores/{id}/stock [get]
+func (h *StoreHandler) ListBranchStock(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	stock, err := h.branchService.ListBranchStock(clientID, c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"stock": stock,
+		"total": len(stock),
+	})
+}
+
+// SetBranchStock godoc
+// @Summary Set branch stock
+// @Description Set a product's stock at a branch (requires authentication)
+// @Tags Stores
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Store (branch) ID"
+// @Param stock body models.SetBranchStockRequest true "Product stock"
+// @Success 200 {object} models.BranchStock
+// @Failure 400 {object} map[string]interface{}
+// @Router /stores/{id}/stock [put]
+func (h *StoreHandler) SetBranchStock(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	var req models.SetBranchStockRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	stock, err := h.branchService.SetStock(clientID, c.Params("id"), &req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(stock)
+}
+
+// GetProductBranchStock godoc
+// @Summary Get product stock per branch
+// @Tags Products
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param id path string true "Product ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /products/{id}/branch-stock [get]
+func (h *StoreHandler) GetProductBranchStock(c *fiber.Ctx) error {
+	clientID, err := storeClientID(c)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	stock, err := h.branchService.ListProductStock(clientID, c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"stock": stock,
+		"total": len(stock),
+	})
+}
+
+// storeClientID reads the authenticated client ID from the auth context
+func storeClientID(c *fiber.Ctx) (uuid.UUID, error) {
+	clientIDStr, ok := c.Locals("clientID").(string)
+	if !ok || clientIDStr == "" {
+		return uuid.Nil, errors.New("Unauthorized")
+	}
+
+	clientID, err := uuid.Parse(clientIDStr)
+	if err != nil {
+		return uuid.Nil, errors.New("Invalid client_id")
+	}
+
+	return clientID, nil
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SubscriptionHandler struct {
+	subscriptionService *services.SubscriptionService
+	dunningService      *services.DunningService
+}
+
+func NewSubscriptionHandler(subscriptionService *services.SubscriptionService, dunningService *services.DunningService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+		dunningService:      dunningService,
+	}
+}
+
+// ListPlans godoc
+// @Summary List subscription plans
+// @Description Get the self-service plan catalog with monthly prices (IDR) and limits (0 = unlimited). With client_id, also returns the client's usage in the current billing period (calendar month), any plan change still open and the subscription's payment standing (active, past_due until the grace period ends, or downgraded for non-payment).
+// @Tags Subscription
+// @Produce json
+// @Param client_id query string false "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /plans [get]
+func (h *SubscriptionHandler) ListPlans(c *fiber.Ctx) error {
+	response := fiber.Map{"plans": h.subscriptionService.Plans()}
+	if c.Query("client_id") == "" {
+		return c.JSON(response)
+	}
+
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid client_id"})
+	}
+	usage, err := h.subscriptionService.Usage(clientID)
+	if errors.Is(err, services.ErrClientNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	subscription, err := h.dunningService.Status(clientID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	response["usage"] = usage
+	response["subscription"] = subscription
+	return c.JSON(response)
+}
+
+// ChangePlan godoc
+// @Summary Change subscription plan
+// @Description Move the client to another catalog plan. An upgrade is charged the price difference prorated over the rest of the billing period and applied once the returned payment link is paid; a downgrade is scheduled for the start of the next period. A new request cancels any change still open, and requesting the current plan just cancels it.
+// @Tags Subscription
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param change body models.ChangePlanRequest true "Target plan"
+// @Success 200 {object} services.PlanChangeResult
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /subscription/change [post]
+func (h *SubscriptionHandler) ChangePlan(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.ChangePlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+	if req.Plan == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "plan is required"})
+	}
+
+	result, err := h.subscriptionService.ChangePlan(clientID, req.Plan)
+	switch {
+	case errors.Is(err, services.ErrClientNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownPlan):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrSamePlan), errors.Is(err, services.ErrCustomPlan):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrBillingUnavailable):
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to change plan for client %s: %v", clientID, err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(result)
+}
+
+// ListPlanDefinitions godoc
+// @Summary List plan definitions (Admin)
+// @Description Get the catalog in effect and the plans defined by the operator, including withdrawn ones. Definitions add plans to the built-in catalog or replace the built-in plan with the same code. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/plans [get]
+func (h *SubscriptionHandler) ListPlanDefinitions(c *fiber.Ctx) error {
+	definitions, err := h.subscriptionService.PlanDefinitions()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"plans":       h.subscriptionService.Plans(),
+		"definitions": definitions,
+	})
+}
+
+// SavePlan godoc
+// @Summary Define or update a plan (Admin)
+// @Description Create a plan or replace its definition: name, monthly price (IDR), limits (0 = unlimited), overage rates and features. Set active to false to withdraw it from the catalog; clients already on it keep it as a custom plan. The free plan, which unpaid subscriptions are downgraded to, can't be withdrawn. A new price applies from the next billing statement. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param code path string true "Plan code"
+// @Param plan body models.PlanRequest true "Plan"
+// @Success 200 {object} models.PlanDefinition
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/plans/{code} [put]
+func (h *SubscriptionHandler) SavePlan(c *fiber.Ctx) error {
+	var req models.PlanRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	definition, err := h.subscriptionService.SavePlan(c.Params("code"), &req)
+	if errors.Is(err, services.ErrInvalidPlan) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to save plan %s: %v", c.Params("code"), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(definition)
+}
+
+// ListSubscriptions godoc
+// @Summary List tenant subscriptions by payment standing (Admin)
+// @Description Clients whose billing statement went unpaid 7 days after it was issued are past due, and are downgraded to the free plan when the 7-day grace period ends. Paying or voiding the statement makes the subscription active again. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param status query string false "active, past_due or downgraded"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/subscriptions [get]
+func (h *SubscriptionHandler) ListSubscriptions(c *fiber.Ctx) error {
+	status := c.Query("status")
+	switch status {
+	case "", models.SubscriptionActive, models.SubscriptionPastDue, models.SubscriptionDowngraded:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "status must be active, past_due or downgraded"})
+	}
+
+	subscriptions, err := h.dunningService.List(status)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"subscriptions": subscriptions,
+		"count":         len(subscriptions),
+	})
+}
@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OwnedBy checks that the row of table named by a route parameter belongs to the caller's client, for
+// routes addressing a resource by its own ID (/orders/:id, /workflows/:id). Rows of other clients are
+// answered 404, like missing ones, so IDs can't be probed. Super admins may access any row.
+func OwnedBy(owners repositories.OwnershipRepo, table, column, param string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if c.Locals("role") == auth.RoleSuperAdmin {
+			return c.Next()
+		}
+
+		value := c.Params(param)
+		if column == "id" {
+			if _, err := uuid.Parse(value); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid " + param})
+			}
+		}
+
+		clientID, _ := c.Locals("clientID").(string)
+		owner, err := owners.ClientOf(table, column, value)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("❌ Failed to check owner of %s %s: %v", table, value, err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to check access"})
+		}
+		if err != nil || !strings.EqualFold(owner.String(), clientID) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "not found"})
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrackingHandler struct {
+	trackingService *services.TrackingService
+}
+
+func NewTrackingHandler(trackingService *services.TrackingService) *TrackingHandler {
+	return &TrackingHandler{
+		trackingService: trackingService,
+	}
+}
+
+// TrackOrder godoc
+// @Summary Public order tracking page
+// @Description Order status, items, payment state and shipment tracking for the short link sent to the customer. Renders HTML for browsers, JSON when requested with Accept: application/json or ?format=json. No auth; rate-limited; links expire.
+// @Tags Tracking
+// @Produce html
+// @Produce json
+// @Param token path string true "Tracking token"
+// @Param format query string false "json"
+// @Success 200 {object} models.OrderTracking
+// @Failure 404 {object} map[string]interface{}
+// @Failure 410 {object} map[string]interface{}
+// @Failure 429 {object} map[string]interface{}
+// @Router /t/{token} [get]
+func (h *TrackingHandler) TrackOrder(c *fiber.Ctx) error {
+	wantsJSON := c.Query("format") == "json" || c.Accepts(fiber.MIMETextHTML, fiber.MIMEApplicationJSON) == fiber.MIMEApplicationJSON
+
+	tracking, err := h.trackingService.GetTracking(c.Params("token"))
+	if err != nil {
+		status := fiber.StatusNotFound
+		message := "Link pelacakan tidak ditemukan."
+		if errors.Is(err, services.ErrTrackingExpired) {
+			status = fiber.StatusGone
+			message = "Link pelacakan sudah kedaluwarsa."
+		}
+
+		if wantsJSON {
+			return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+		}
+		return h.render(c.Status(status), trackingPage{Error: message})
+	}
+
+	if wantsJSON {
+		return c.JSON(tracking)
+	}
+	return h.render(c, trackingPage{Tracking: tracking})
+}
+
+// render writes the tracking page as HTML
+func (h *TrackingHandler) render(c *fiber.Ctx, page trackingPage) error {
+	var buf bytes.Buffer
+	if err := trackingTemplate.Execute(&buf, page); err != nil {
+		log.Printf("❌ Failed to render tracking page: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to render page"})
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+	return c.Send(buf.Bytes())
+}
+
+type trackingPage struct {
+	Tracking *models.OrderTracking
+	Error    string
+}
+
+// trackingStatusLabels translates order and shipment statuses for customers
+var trackingStatusLabels = map[string]string{
+	"pending":     "Menunggu",
+	"pending_cod": "Bayar di tempat (COD)",
+	"paid":        "Lunas",
+	"failed":      "Gagal",
+	"cancelled":   "Dibatalkan",
+	"refunded":    "Dikembalikan",
+	"processing":  "Diproses",
+	"shipped":     "Dikirim",
+	"delivered":   "Diterima",
+	"assigned":    "Kurir ditugaskan",
+	"picked_up":   "Dalam perjalanan",
+}
+
+var trackingTemplate = template.Must(template.New("tracking").Funcs(template.FuncMap{
+	"label": func(status string) string {
+		if label, ok := trackingStatusLabels[status]; ok {
+			return label
+		}
+		return strings.ReplaceAll(status, "_", " ")
+	},
+	"rupiah": func(amount float64) string {
+		return "Rp " + formatRupiah(amount)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="id">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>{{if .Tracking}}Pesanan #{{.Tracking.OrderNumber}}{{else}}Lacak Pesanan{{end}}</title>
+<style>
+body{font-family:-apple-system,Segoe UI,Roboto,sans-serif;background:#f4f5f7;margin:0;padding:16px;color:#222}
+.card{background:#fff;border-radius:12px;max-width:480px;margin:0 auto 12px;padding:16px;box-shadow:0 1px 3px rgba(0,0,0,.08)}
+h1{font-size:18px;margin:0 0 4px}h2{font-size:15px;margin:0 0 8px}
+.muted{color:#777;font-size:13px}.badge{display:inline-block;padding:2px 8px;border-radius:10px;background:#e8f0fe;color:#1a56db;font-size:13px}
+table{width:100%;border-collapse:collapse;font-size:14px}td{padding:4px 0}td.r{text-align:right}
+.total td{border-top:1px solid #eee;font-weight:bold;padding-top:8px}
+a.btn{display:block;text-align:center;background:#25d366;color:#fff;padding:10px;border-radius:8px;text-decoration:none;margin-top:12px}
+</style>
+</head>
+<body>
+{{if .Error}}
+<div class="card"><h1>Lacak Pesanan</h1><p>{{.Error}}</p></div>
+{{else}}{{with .Tracking}}
+<div class="card">
+<div class="muted">{{.BusinessName}}</div>
+<h1>Pesanan #{{.OrderNumber}}</h1>
+<div class="muted">{{.CreatedAt.Format "02 Jan 2006 15:04"}}</div>
+</div>
+<div class="card">
+<h2>Status</h2>
+<p>Pembayaran: <span class="badge">{{label .PaymentStatus}}</span></p>
+<p>Pesanan: <span class="badge">{{label .FulfillmentStatus}}</span></p>
+{{if .PaymentLink}}<a class="btn" href="{{.PaymentLink}}">Bayar Sekarang</a>{{end}}
+</div>
+{{with .Shipment}}
+<div class="card">
+<h2>Pengiriman</h2>
+<p><span class="badge">{{label .Status}}</span>{{if .DriverName}} · Kurir: {{.DriverName}}{{end}}</p>
+<div class="muted">Kurir ditugaskan: {{.AssignedAt.Format "02 Jan 15:04"}}</div>
+{{if .PickedUpAt}}<div class="muted">Dijemput: {{.PickedUpAt.Format "02 Jan 15:04"}}</div>{{end}}
+{{if .DeliveredAt}}<div class="muted">Diterima: {{.DeliveredAt.Format "02 Jan 15:04"}}</div>{{end}}
+</div>
+{{end}}
+<div class="card">
+<h2>Rincian</h2>
+<table>
+{{range .Items}}<tr><td>{{.ProductName}} × {{.Quantity}}</td><td class="r">{{rupiah .Subtotal}}</td></tr>{{end}}
+<tr class="total"><td>Total</td><td class="r">{{rupiah .TotalAmount}}</td></tr>
+</table>
+</div>
+{{end}}{{end}}
+</body>
+</html>`))
+
+// formatRupiah formats an amount with thousand separators (1000000 -> 1.000.000)
+func formatRupiah(amount float64) string {
+	digits := fmt.Sprintf("%.0f", math.Abs(amount))
+
+	var sb strings.Builder
+	if amount < 0 {
+		sb.WriteString("-")
+	}
+	for i, ch := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteRune(ch)
+	}
+	return sb.String()
+}
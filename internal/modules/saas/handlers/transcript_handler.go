@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TranscriptHandler struct {
+	transcriptService *services.TranscriptService
+}
+
+func NewTranscriptHandler(transcriptService *services.TranscriptService) *TranscriptHandler {
+	return &TranscriptHandler{
+		transcriptService: transcriptService,
+	}
+}
+
+// ExportTranscript godoc
+// @Summary Export a chat transcript
+// @Description Queue a transcript of the chat with a customer (timestamps, customer/bot/agent attribution, media links) as PDF or HTML. The file is rendered in the background and stored; poll /transcript-exports/{id} for the download URL. With email, the link is also sent to the tenant admins (or email_to).
+// @Tags Conversations
+// @Accept json
+// @Produce json
+// @Param phone path string true "Customer phone number"
+// @Param client_id query string true "Client ID"
+// @Param export body models.TranscriptExportRequest false "Format, time window and email"
+// @Success 202 {object} models.TranscriptExport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /conversations/{phone}/export [post]
+func (h *TranscriptHandler) ExportTranscript(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.TranscriptExportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+		}
+	}
+
+	record, err := h.transcriptService.RequestExport(c.Context(), clientID, c.Params("phone"), &req)
+	switch {
+	case errors.Is(err, services.ErrClientNotFound), errors.Is(err, services.ErrConversationNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrTranscriptRequest):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrEmailUnavailable):
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to queue transcript export: %v", err)
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.Status(202).JSON(record)
+}
+
+// ListTranscriptExports godoc
+// @Summary List transcript exports of a chat
+// @Description The latest 20 transcript exports of the chat with a customer, newest first
+// @Tags Conversations
+// @Produce json
+// @Param phone path string true "Customer phone number"
+// @Param client_id query string true "Client ID"
+// @Success 200 {array} models.TranscriptExport
+// @Failure 400 {object} map[string]interface{}
+// @Router /conversations/{phone}/exports [get]
+func (h *TranscriptHandler) ListTranscriptExports(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	exports, err := h.transcriptService.ListExports(clientID, c.Params("phone"))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(exports)
+}
+
+// GetTranscriptExport godoc
+// @Summary Get a transcript export
+// @Description Status of a transcript export (pending, completed, failed) and, once completed, its file URL
+// @Tags Conversations
+// @Produce json
+// @Param id path string true "Export ID"
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.TranscriptExport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /transcript-exports/{id} [get]
+func (h *TranscriptHandler) GetTranscriptExport(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid export id"})
+	}
+
+	record, err := h.transcriptService.GetExport(clientID, id)
+	if err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(record)
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type UsageHandler struct {
+	usageService *services.UsageService
+}
+
+func NewUsageHandler(usageService *services.UsageService) *UsageHandler {
+	return &UsageHandler{usageService: usageService}
+}
+
+// GetUsage godoc
+// @Summary Get AI token usage
+// @Description Estimated LLM tokens (about 4 characters per token) used in the current billing period (calendar month), per feature and per day, against the plan's monthly quota and the client's AI credits. Plans without token overage pricing stop at the quota and then draw on credits; once both are used up, AI replies fall back to FAQ answers or a handover to agents.
+// @Tags Usage
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.UsageReport
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /usage [get]
+func (h *UsageHandler) GetUsage(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	report, err := h.usageService.Report(clientID)
+	if errors.Is(err, services.ErrClientNotFound) {
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(report)
+}
+
+// GrantAICredits godoc
+// @Summary Grant AI credits to a client (Admin)
+// @Description Adds prepaid AI tokens to the client's credit balance, or removes them with a negative amount (the balance never goes below 0). Credits are drawn once the plan's monthly token quota is used up. The change is written to the audit log. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param id path string true "Client ID"
+// @Param request body models.GrantAICreditsRequest true "Tokens and note"
+// @Success 200 {object} models.AICredit
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 500 {object} map[string]interface{}
+// @Router /admin/clients/{id}/ai-credits [post]
+func (h *UsageHandler) GrantAICredits(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid client id"})
+	}
+
+	var req models.GrantAICreditsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+	}
+
+	credit, err := h.usageService.GrantCredits(clientID, &req, "platform_admin")
+	switch {
+	case errors.Is(err, services.ErrInvalidCreditAmount):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrClientNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("❌ Failed to grant AI credits to client %s: %v", clientID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(credit)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/gofiber/fiber/v2"
+)
+
+type VectorIndexHandler struct {
+	vectorRetriever *kb.VectorRetriever // nil when the vector DB is disabled
+}
+
+func NewVectorIndexHandler(vectorRetriever *kb.VectorRetriever) *VectorIndexHandler {
+	return &VectorIndexHandler{vectorRetriever: vectorRetriever}
+}
+
+// GetIndexes godoc
+// @Summary Vector DB payload index status
+// @Description Payload indexes of the knowledge base collection and the filtered fields (client_id, doc_type) still missing one. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} kb.IndexStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /admin/vector/indexes [get]
+func (h *VectorIndexHandler) GetIndexes(c *fiber.Ctx) error {
+	if h.vectorRetriever == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "vector DB is disabled"})
+	}
+
+	status, err := h.vectorRetriever.GetIndexStatus(c.Context())
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(status)
+}
+
+// CreateIndexes godoc
+// @Summary Create missing vector DB payload indexes
+// @Description Create the payload indexes missing on the knowledge base collection, for collections created before they were added at startup. Existing indexes are left alone. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} kb.IndexStatus
+// @Failure 401 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /admin/vector/indexes [post]
+func (h *VectorIndexHandler) CreateIndexes(c *fiber.Ctx) error {
+	if h.vectorRetriever == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "vector DB is disabled"})
+	}
+
+	status, err := h.vectorRetriever.EnsureIndexes(c.Context())
+	if err != nil {
+		log.Printf("❌ Failed to create payload indexes: %v", err)
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(status)
+}
+
+// GetCacheStats godoc
+// @Summary Vector search cache hit rate
+// @Description Size, hits, misses and hit rate of the vector search result cache since startup, and how often a KB write invalidated a client's cached results. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 200 {object} kb.SearchCacheStats
+// @Failure 401 {object} map[string]interface{}
+// @Failure 503 {object} map[string]interface{}
+// @Router /admin/vector/cache [get]
+func (h *VectorIndexHandler) GetCacheStats(c *fiber.Ctx) error {
+	if h.vectorRetriever == nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{"error": "vector DB is disabled"})
+	}
+
+	return c.JSON(h.vectorRetriever.SearchCacheStats())
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WalletHandler struct {
+	walletService *services.WalletService
+}
+
+func NewWalletHandler(walletService *services.WalletService) *WalletHandler {
+	return &WalletHandler{
+		walletService: walletService,
+	}
+}
+
+// GetWalletSettings godoc
+// @Summary Get prepaid wallet settings
+// @Description Get whether customers can buy prepaid credit and whether orders are paid from it at checkout. Off by default.
+// @Tags Wallets
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} models.WalletSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /wallet-settings [get]
+func (h *WalletHandler) GetWalletSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	settings, err := h.walletService.GetSettings(clientID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// UpdateWalletSettings godoc
+// @Summary Update prepaid wallet settings
+// @Description Customers top up in chat ("isi saldo 50000") or through POST /wallets/top-up and check their balance with "saldo". With auto_deduct an order is paid from the balance at checkout when the balance covers the total; otherwise it is paid through the payment gateway as usual. Refunds of wallet-paid orders go back to the balance.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param settings body models.UpdateWalletSettingsRequest true "Wallet settings"
+// @Success 200 {object} models.WalletSettings
+// @Failure 400 {object} map[string]interface{}
+// @Router /wallet-settings [put]
+func (h *WalletHandler) UpdateWalletSettings(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.UpdateWalletSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	settings, err := h.walletService.UpdateSettings(clientID, &req)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(settings)
+}
+
+// ListWallets godoc
+// @Summary List customer wallets
+// @Description Customer wallets of a client, largest balance first
+// @Tags Wallets
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param limit query int false "Max wallets (default 100, max 500)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Router /wallets [get]
+func (h *WalletHandler) ListWallets(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	wallets, err := h.walletService.ListWallets(clientID, c.QueryInt("limit", 100))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(fiber.Map{
+		"wallets": wallets,
+		"count":   len(wallets),
+	})
+}
+
+// GetWallet godoc
+// @Summary Get a customer's wallet and ledger
+// @Description Balance and the latest transactions (top-ups, payments, refunds, adjustments), newest first
+// @Tags Wallets
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Param limit query int false "Max transactions (default 50, max 500)"
+// @Success 200 {object} models.WalletStatement
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /wallets/{phone} [get]
+func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	statement, err := h.walletService.GetStatement(clientID, c.Params("phone"), c.QueryInt("limit", 50))
+	if err != nil {
+		return walletError(c, err)
+	}
+
+	return c.JSON(statement)
+}
+
+// TopUpWallet godoc
+// @Summary Start a wallet top-up
+// @Description Create a payment link for credit on a customer's wallet and send it to the customer on WhatsApp. The balance grows once the payment is confirmed.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param request body models.WalletTopUpRequest true "Customer and amount"
+// @Success 201 {object} models.WalletTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Router /wallets/top-up [post]
+func (h *WalletHandler) TopUpWallet(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.WalletTopUpRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	transaction, err := h.walletService.TopUp(clientID, &req)
+	if err != nil {
+		return walletError(c, err)
+	}
+
+	return c.Status(201).JSON(transaction)
+}
+
+// ConfirmWalletTopUp godoc
+// @Summary Manually confirm a wallet top-up (Admin)
+// @Description Admin confirms a top-up paid by manual transfer or in the sandbox. Midtrans top-ups are confirmed by the payment webhook.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Param reference path string true "Top-up reference (TOP-...)"
+// @Param client_id query string true "Client ID"
+// @Param payment body object{payment_method=string,reference=string} false "Payment details"
+// @Success 200 {object} models.WalletTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /wallets/top-ups/{reference}/confirm [post]
+func (h *WalletHandler) ConfirmWalletTopUp(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req struct {
+		PaymentMethod string `json:"payment_method"`
+		Reference     string `json:"reference"`
+	}
+	_ = c.BodyParser(&req)
+
+	transaction, err := h.walletService.ConfirmManualTopUp(clientID, c.Params("reference"), req.PaymentMethod, req.Reference)
+	if err != nil {
+		return walletError(c, err)
+	}
+
+	return c.JSON(transaction)
+}
+
+// AdjustWallet godoc
+// @Summary Adjust a customer's balance (Admin)
+// @Description Add (positive amount) or remove (negative amount) credit by hand, e.g. to correct a mistake or give a goodwill credit. The customer is told on WhatsApp and the change is written to the audit log with the actor and reason.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Param phone path string true "Customer phone"
+// @Param client_id query string true "Client ID"
+// @Param request body models.WalletAdjustmentRequest true "Amount and reason"
+// @Success 200 {object} models.WalletTransaction
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /wallets/{phone}/adjustments [post]
+func (h *WalletHandler) AdjustWallet(c *fiber.Ctx) error {
+	clientID := c.Query("client_id")
+	if clientID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "client_id is required"})
+	}
+
+	var req models.WalletAdjustmentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	}
+
+	transaction, err := h.walletService.Adjust(clientID, c.Params("phone"), &req, requestActor(c, req.Actor))
+	if err != nil {
+		return walletError(c, err)
+	}
+
+	return c.JSON(transaction)
+}
+
+func walletError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrWalletNotFound), errors.Is(err, services.ErrWalletTopUpNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrInsufficientBalance), errors.Is(err, services.ErrWalletTopUpNotPending),
+		errors.Is(err, services.ErrWalletTopUpAutoConfirm):
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,418 @@
+package handlers
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/gofiber/fiber/v2"
+)
+
+// webhookMediaFolder is where inline webhook media is stored
+const webhookMediaFolder = "webhook-media"
+
+// errWebhookBodyTooLarge is returned when a webhook payload exceeds the configured limit
+var errWebhookBodyTooLarge = errors.New("webhook payload too large")
+
+// WebhookPayloadStats are payload size metrics for one webhook provider
+type WebhookPayloadStats struct {
+	Requests       int64 `json:"requests"`
+	Rejected       int64 `json:"rejected"`        // Over the size limit (413)
+	Gzipped        int64 `json:"gzipped"`         // Sent with Content-Encoding: gzip
+	WireBytes      int64 `json:"wire_bytes"`      // As received, before decompression
+	TotalBytes     int64 `json:"total_bytes"`     // After decompression
+	MaxBytes       int64 `json:"max_bytes"`       // Largest payload after decompression
+	MediaOffloaded int64 `json:"media_offloaded"` // Inline media streamed to storage
+	MediaBytes     int64 `json:"media_bytes"`     // Decoded size of offloaded media
+}
+
+// webhookBody is a webhook payload with inline media replaced by a stored file URL
+type webhookBody struct {
+	JSON     []byte
+	MediaURL string
+}
+
+// WebhookBodyReader reads webhook bodies within a size limit, inflating gzip and
+// streaming inline base64 media (media.data) to storage instead of buffering it
+type WebhookBodyReader struct {
+	maxBytes      int64
+	uploadService *upload.Service // Optional, inline media is dropped without it
+
+	mu    sync.Mutex
+	stats map[string]*WebhookPayloadStats
+}
+
+// NewWebhookBodyReader creates a webhook body reader
+func NewWebhookBodyReader(maxBytes int64, uploadService *upload.Service) *WebhookBodyReader {
+	return &WebhookBodyReader{
+		maxBytes:      maxBytes,
+		uploadService: uploadService,
+		stats:         make(map[string]*WebhookPayloadStats),
+	}
+}
+
+// Read reads the request body for a provider. Decompressed bytes are also written to tee (e.g. a signature MAC) if set.
+func (r *WebhookBodyReader) Read(c *fiber.Ctx, provider string, tee io.Writer) (*webhookBody, error) {
+	var src io.Reader
+	if c.Request().IsBodyStream() {
+		src = c.Context().RequestBodyStream()
+	} else {
+		src = bytes.NewReader(c.Request().Body())
+	}
+
+	wire := &countingReader{r: io.LimitReader(src, r.maxBytes+1)}
+	var body io.Reader = wire
+
+	gzipped := strings.EqualFold(strings.TrimSpace(c.Get(fiber.HeaderContentEncoding)), "gzip")
+	if gzipped {
+		gz, err := gzip.NewReader(wire)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	inflated := &countingReader{r: io.LimitReader(body, r.maxBytes+1)}
+	body = inflated
+	if tee != nil {
+		body = io.TeeReader(body, tee)
+	}
+
+	result, media, err := r.scan(bufio.NewReader(body))
+	if wire.n > r.maxBytes || inflated.n > r.maxBytes {
+		// The limit cuts the body short, so this takes precedence over scan errors
+		err = errWebhookBodyTooLarge
+	}
+	r.record(provider, gzipped, wire.n, inflated.n, media, err)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Stats returns payload size metrics per provider
+func (r *WebhookBodyReader) Stats() map[string]WebhookPayloadStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := make(map[string]WebhookPayloadStats, len(r.stats))
+	for provider, s := range r.stats {
+		stats[provider] = *s
+	}
+	return stats
+}
+
+// TooLarge writes the 413 response for an oversized webhook payload
+func (r *WebhookBodyReader) TooLarge(c *fiber.Ctx) error {
+	// The rest of the body is left unread, so the connection can't be reused
+	c.Context().SetConnectionClose()
+	return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+		"error":     "payload too large",
+		"max_bytes": r.maxBytes,
+	})
+}
+
+func (r *WebhookBodyReader) record(provider string, gzipped bool, wireBytes, bodyBytes, mediaBytes int64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	s, ok := r.stats[provider]
+	if !ok {
+		s = &WebhookPayloadStats{}
+		r.stats[provider] = s
+	}
+	s.Requests++
+	if errors.Is(err, errWebhookBodyTooLarge) {
+		s.Rejected++
+	}
+	if gzipped {
+		s.Gzipped++
+	}
+	s.WireBytes += wireBytes
+	s.TotalBytes += bodyBytes
+	if bodyBytes > s.MaxBytes {
+		s.MaxBytes = bodyBytes
+	}
+	if mediaBytes > 0 {
+		s.MediaOffloaded++
+		s.MediaBytes += mediaBytes
+	}
+}
+
+// scan copies the JSON body, streaming the string value of media.data to storage and leaving it empty in the copy
+func (r *WebhookBodyReader) scan(in *bufio.Reader) (*webhookBody, int64, error) {
+	type frame struct {
+		object    bool
+		name      string // Key this container is stored under in its parent
+		key       string // Last key read in this object
+		expectKey bool
+	}
+
+	var out bytes.Buffer
+	var stack []*frame
+	result := &webhookBody{}
+	var mediaBytes int64
+
+	for {
+		ch, err := in.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, mediaBytes, readError(err)
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch ch {
+		case '{', '[':
+			name := ""
+			if top != nil && top.object {
+				name = top.key
+			}
+			stack = append(stack, &frame{object: ch == '{', name: name, expectKey: ch == '{'})
+			out.WriteByte(ch)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			out.WriteByte(ch)
+		case ':':
+			if top != nil {
+				top.expectKey = false
+			}
+			out.WriteByte(ch)
+		case ',':
+			if top != nil && top.object {
+				top.expectKey = true
+			}
+			out.WriteByte(ch)
+		case '"':
+			if top != nil && top.object && top.expectKey {
+				key, err := readJSONString(in, &out)
+				if err != nil {
+					return nil, mediaBytes, err
+				}
+				top.key = key
+				continue
+			}
+			if top != nil && top.object && top.key == "data" && top.name == "media" {
+				url, size, err := r.offloadMedia(in)
+				if err != nil {
+					return nil, mediaBytes, err
+				}
+				mediaBytes += size
+				if url != "" {
+					result.MediaURL = url
+				}
+				out.WriteString(`""`)
+				continue
+			}
+			if _, err := readJSONString(in, &out); err != nil {
+				return nil, mediaBytes, err
+			}
+		default:
+			out.WriteByte(ch)
+		}
+	}
+
+	result.JSON = out.Bytes()
+	return result, mediaBytes, nil
+}
+
+// offloadMedia streams a base64 JSON string (opening quote already read) to storage.
+// Returns the stored file URL (empty if it could not be stored) and the decoded size.
+func (r *WebhookBodyReader) offloadMedia(in *bufio.Reader) (string, int64, error) {
+	pr, pw := io.Pipe()
+	type uploaded struct {
+		url  string
+		size int64
+	}
+	done := make(chan uploaded, 1)
+
+	go func() {
+		decoded := &countingReader{r: base64.NewDecoder(base64.StdEncoding, pr)}
+		url := r.storeMedia(decoded)
+		// Unblock the writer if the upload stopped early or the data isn't valid base64
+		io.Copy(io.Discard, decoded)
+		io.Copy(io.Discard, pr)
+		done <- uploaded{url: url, size: decoded.n}
+	}()
+
+	w := bufio.NewWriter(pw)
+	err := streamJSONString(in, w)
+	if err == nil {
+		err = w.Flush()
+	}
+	pw.CloseWithError(err)
+	result := <-done
+	if err != nil {
+		return "", result.size, err
+	}
+	return result.url, result.size, nil
+}
+
+// storeMedia uploads decoded media, naming it by its sniffed content type
+func (r *WebhookBodyReader) storeMedia(decoded io.Reader) string {
+	if r.uploadService == nil {
+		log.Printf("⚠️ Inline webhook media dropped: no upload storage configured")
+		return ""
+	}
+
+	buffered := bufio.NewReaderSize(decoded, 512)
+	head, _ := buffered.Peek(512)
+	if len(head) == 0 {
+		return ""
+	}
+
+	contentType := http.DetectContentType(head)
+	ext := ".bin"
+	if exts, _ := mime.ExtensionsByType(contentType); len(exts) > 0 {
+		ext = exts[0]
+	}
+
+	res, err := r.uploadService.Upload(buffered, "media"+ext, &upload.UploadOptions{
+		Folder:       webhookMediaFolder,
+		ResourceType: "auto",
+		AllowedTypes: []string{contentType},
+		MaxSize:      r.maxBytes,
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to store inline webhook media: %v", err)
+		return ""
+	}
+
+	log.Printf("📦 Inline webhook media stored (%s, %d bytes): %s", contentType, res.Size, res.URL)
+	if res.SecureURL != "" {
+		return res.SecureURL
+	}
+	return res.URL
+}
+
+// readJSONString copies a JSON string (opening quote already read) to out, quotes included, and returns its raw content
+func readJSONString(in *bufio.Reader, out *bytes.Buffer) (string, error) {
+	var raw strings.Builder
+	out.WriteByte('"')
+	for {
+		ch, err := in.ReadByte()
+		if err != nil {
+			return "", readError(err)
+		}
+		out.WriteByte(ch)
+		if ch == '"' {
+			return raw.String(), nil
+		}
+		raw.WriteByte(ch)
+		if ch == '\\' {
+			next, err := in.ReadByte()
+			if err != nil {
+				return "", readError(err)
+			}
+			out.WriteByte(next)
+			raw.WriteByte(next)
+		}
+	}
+}
+
+// streamJSONString writes the content of a JSON string (opening quote already read) to w, unescaping "\/"
+func streamJSONString(in *bufio.Reader, w io.ByteWriter) error {
+	for {
+		ch, err := in.ReadByte()
+		if err != nil {
+			return readError(err)
+		}
+		switch ch {
+		case '"':
+			return nil
+		case '\\':
+			next, err := in.ReadByte()
+			if err != nil {
+				return readError(err)
+			}
+			if next == 'u' {
+				// Not valid base64; skip the code point
+				if _, err := in.Discard(4); err != nil {
+					return readError(err)
+				}
+				continue
+			}
+			if next != '/' {
+				continue // \n, \r etc. are line breaks, ignored by the base64 decoder
+			}
+			ch = next
+		}
+		if err := w.WriteByte(ch); err != nil {
+			return err
+		}
+	}
+}
+
+// readError maps a read failure to a payload error
+func readError(err error) error {
+	if err == io.EOF {
+		return errors.New("unexpected end of payload")
+	}
+	return err
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// LimitBufferedBody enforces the body limit on routes that don't stream their body.
+// With StreamRequestBody, Fiber hands bodies over its BodyLimit to handlers as a stream
+// (and reads multipart forms of any declared length) instead of failing them.
+func LimitBufferedBody(maxBytes int, streamed func(c *fiber.Ctx) bool) fiber.Handler {
+	tooLarge := func(c *fiber.Ctx) error {
+		c.Context().SetConnectionClose()
+		return c.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error":     "request body too large",
+			"max_bytes": maxBytes,
+		})
+	}
+
+	return func(c *fiber.Ctx) error {
+		if streamed(c) {
+			return c.Next()
+		}
+
+		length := c.Request().Header.ContentLength()
+		if length > maxBytes {
+			return tooLarge(c)
+		}
+		if length == -1 {
+			// Chunked body: buffer it up to the limit so handlers can read it as usual
+			data, err := io.ReadAll(io.LimitReader(c.Context().RequestBodyStream(), int64(maxBytes)+1))
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "failed to read request body"})
+			}
+			if len(data) > maxBytes {
+				return tooLarge(c)
+			}
+			c.Request().SetBody(data)
+		}
+		return c.Next()
+	}
+}
@@ -2,23 +2,35 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"hash"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
 	"github.com/gofiber/fiber/v2"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // WebhookHandler handles HTTP webhook requests (thin layer)
 type WebhookHandler struct {
-	webhookService *services.WebhookService
+	webhookService    *services.WebhookService
+	onboardingService *services.OnboardingService
+	bodyReader        *WebhookBodyReader
+	signatures        *WebhookSignatures
 }
 
 // NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(webhookService *services.WebhookService) *WebhookHandler {
+func NewWebhookHandler(webhookService *services.WebhookService, onboardingService *services.OnboardingService, bodyReader *WebhookBodyReader, signatures *WebhookSignatures) *WebhookHandler {
 	return &WebhookHandler{
-		webhookService: webhookService,
+		webhookService:    webhookService,
+		onboardingService: onboardingService,
+		bodyReader:        bodyReader,
+		signatures:        signatures,
 	}
 }
 
@@ -31,6 +43,7 @@ type WAHAWebhookPayload struct {
 		Timestamp int64                  `json:"timestamp"`
 		From      string                 `json:"from"` // Format: 628xxx@c.us
 		FromMe    bool                   `json:"fromMe"`
+		Source    string                 `json:"source"` // Own messages: "app" (typed on the phone) or "api"
 		To        string                 `json:"to"`
 		Body      string                 `json:"body"`
 		HasMedia  bool                   `json:"hasMedia"`
@@ -38,36 +51,246 @@ type WAHAWebhookPayload struct {
 		MimeType  string                 `json:"mimeType"`  // image/jpeg, image/png, etc
 		Media     map[string]interface{} `json:"media"`     // WAHA media object (fallback)
 		Ack       int                    `json:"ack"`
+		Location  *WAHALocation          `json:"location"` // Set for location messages
+		Reaction  *WAHAReaction          `json:"reaction"` // Set for message.reaction events
+		ReplyTo   *WAHAReplyTo           `json:"replyTo"`  // Set when the customer quotes a message
+		Data      *WAHAMessageData       `json:"_data"`    // Engine raw data (WEBJS carries quoted message fields here)
 	} `json:"payload"`
 }
 
+// WAHAReplyTo is the message a customer quoted in their reply
+type WAHAReplyTo struct {
+	ID          string `json:"id"`
+	Participant string `json:"participant"`
+	Body        string `json:"body"`
+}
+
+// WAHAMessageData holds the raw engine fields used as a fallback for quoted replies
+type WAHAMessageData struct {
+	QuotedStanzaID    string `json:"quotedStanzaID"`
+	QuotedParticipant string `json:"quotedParticipant"`
+	QuotedMsg         *struct {
+		Body string `json:"body"`
+	} `json:"quotedMsg"`
+}
+
+// WAHAReaction represents an emoji reaction to a message (empty text = reaction removed)
+type WAHAReaction struct {
+	Text      string `json:"text"`
+	MessageID string `json:"messageId"`
+}
+
+// WAHALocation represents a shared location in a WAHA message (coordinates may be sent as strings)
+type WAHALocation struct {
+	Latitude    json.Number `json:"latitude"`
+	Longitude   json.Number `json:"longitude"`
+	Live        bool        `json:"live"`
+	Name        string      `json:"name"`
+	Address     string      `json:"address"`
+	Description string      `json:"description"`
+}
+
 // ReceiveWebhook godoc
 // @Summary WhatsApp webhook receiver
-// @Description Receive webhook events from WhatsApp Provider (WAHA/GreenAPI)
+// @Description Receive webhook events from WhatsApp Provider (WAHA/GreenAPI). Accepts gzip bodies (Content-Encoding: gzip); inline base64 media is streamed to upload storage. Requests must be signed by a provider with a configured secret: WAHA HMAC (X-Webhook-Hmac), Cloud API (X-Hub-Signature-256) or the GreenAPI webhook token (Authorization: Bearer); unsigned requests get 401.
 // @Tags Webhook
 // @Accept json
 // @Produce json
+// @Param X-Webhook-Hmac header string false "WAHA HMAC-SHA512 signature of the body"
+// @Param X-Hub-Signature-256 header string false "Cloud API signature of the body (sha256=...)"
+// @Param Authorization header string false "GreenAPI webhook token (Bearer ...)"
 // @Param payload body map[string]interface{} true "Webhook payload"
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
 // @Router /webhook [post]
 func (h *WebhookHandler) ReceiveWebhook(c *fiber.Ctx) error {
-	// Log raw body for debugging
-	rawBody := c.Body()
-	log.Printf("📥 Raw webhook payload: %s", string(rawBody))
+	defer observeWebhookRequest(c, "webhook")()
+
+	verifier, err := h.signatures.Select(c)
+	if err != nil {
+		log.Printf("⚠️ Unsigned webhook rejected from %s", c.IP())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "missing signature",
+		})
+	}
+
+	// Signatures covering the body are computed while it streams in
+	var mac hash.Hash
+	if verifier != nil {
+		mac = verifier.MAC()
+	}
+	body, err := h.bodyReader.Read(c, "waha", mac)
+	if err != nil {
+		return h.bodyError(c, err)
+	}
+
+	if verifier != nil && !verifier.Verify(c, mac) {
+		log.Printf("⚠️ Invalid %s webhook signature from %s", verifier.Name(), c.IP())
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid signature",
+		})
+	}
+
+	// Log raw body for debugging (inline media already stripped)
+	log.Printf("📥 Raw webhook payload: %s", string(body.JSON))
 
 	// Parse webhook payload
-	var payload WAHAWebhookPayload
-	if err := c.BodyParser(&payload); err != nil {
+	payload, err := parseWAHAPayload(body)
+	if err != nil {
+		log.Printf("❌ Failed to parse webhook: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payload",
+		})
+	}
+
+	return h.handleMessagePayload(c, payload)
+}
+
+// ReceiveTenantWebhook godoc
+// @Summary Per-tenant WhatsApp webhook receiver
+// @Description Receive WAHA webhook events on a tenant token route (configured during onboarding), verified with HMAC over the decompressed body
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Param token path string true "Tenant webhook token"
+// @Param X-Webhook-Hmac header string true "HMAC-SHA512 signature of the body"
+// @Param payload body map[string]interface{} true "Webhook payload"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Failure 413 {object} map[string]interface{}
+// @Router /webhook/{token} [post]
+func (h *WebhookHandler) ReceiveTenantWebhook(c *fiber.Ctx) error {
+	defer observeWebhookRequest(c, "tenant_webhook")()
+
+	prov, err := h.onboardingService.ResolveWebhookToken(c.Params("token"))
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "unknown webhook",
+		})
+	}
+
+	// The signature covers the original body, so it is computed while the body streams in
+	verifier := NewWAHAVerifier(prov.WebhookSecret)
+	mac := verifier.MAC()
+	body, err := h.bodyReader.Read(c, "waha", mac)
+	if err != nil {
+		return h.bodyError(c, err)
+	}
+
+	if prov.WebhookSecret == "" || !verifier.Verify(c, mac) {
+		log.Printf("⚠️ Invalid webhook signature for client %s", prov.ClientID)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "invalid signature",
+		})
+	}
+
+	payload, err := parseWAHAPayload(body)
+	if err != nil {
 		log.Printf("❌ Failed to parse webhook: %v", err)
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "invalid payload",
 		})
 	}
 
+	// message.any carries own messages: the onboarding self-test and agents replying from the phone
+	if payload.Event == "message.any" {
+		if payload.Payload.FromMe && h.onboardingService.HandleSelfTestMessage(prov, payload.Payload.Body) {
+			return c.JSON(fiber.Map{"status": "self_test_verified"})
+		}
+		if payload.Payload.FromMe && payload.Payload.Source == "app" && !strings.HasSuffix(payload.Payload.To, "@g.us") {
+			mediaURL := ""
+			if payload.Payload.HasMedia {
+				mediaURL = extractMediaURL(payload)
+			}
+			h.webhookService.Go(func() {
+				h.webhookService.ProcessOwnMessage(prov.ClientID, extractPhoneNumber(payload.Payload.To), payload.Payload.Source, payload.Payload.Body, mediaURL)
+			})
+			return c.JSON(fiber.Map{"status": "agent_reply_recorded"})
+		}
+		return c.JSON(fiber.Map{"status": "ignored"})
+	}
+
+	// The token identifies the tenant, so trust its session over the payload
+	payload.Session = prov.SessionID
+
+	return h.handleMessagePayload(c, payload)
+}
+
+// GetPayloadMetrics godoc
+// @Summary Webhook payload size metrics
+// @Description Request counts, payload sizes (wire and decompressed), gzip usage, rejected oversized payloads and inline media offloaded to storage, per provider since startup. Requires the metrics token, like /metrics.
+// @Tags Webhook
+// @Produce json
+// @Param Authorization header string true "Bearer METRICS_TOKEN"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /webhook/metrics [get]
+func (h *WebhookHandler) GetPayloadMetrics(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"providers": h.bodyReader.Stats(),
+	})
+}
+
+// observeWebhookRequest starts the "webhook.receive" span of a request, which the message processing spans
+// are children of, and returns the function that ends it and records the request duration
+func observeWebhookRequest(c *fiber.Ctx, route string) func() {
+	started := time.Now()
+	ctx, span := tracing.Start(c.UserContext(), "webhook.receive", attribute.String("webhook.route", route))
+	c.SetUserContext(ctx)
+	return func() {
+		status := c.Response().StatusCode()
+		span.SetAttributes(attribute.Int("http.response.status_code", status))
+		span.End()
+		metrics.ObserveWebhookRequest(route, status, started)
+	}
+}
+
+// bodyError responds to a webhook body that could not be read
+func (h *WebhookHandler) bodyError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, errWebhookBodyTooLarge) {
+		log.Printf("⚠️ Webhook payload rejected: %v", err)
+		return h.bodyReader.TooLarge(c)
+	}
+	log.Printf("❌ Failed to read webhook body: %v", err)
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "invalid payload",
+	})
+}
+
+// parseWAHAPayload decodes a WAHA payload, pointing media at the stored copy of inline data
+func parseWAHAPayload(body *webhookBody) (*WAHAWebhookPayload, error) {
+	var payload WAHAWebhookPayload
+	if err := json.Unmarshal(body.JSON, &payload); err != nil {
+		return nil, err
+	}
+	if body.MediaURL != "" && payload.Payload.MediaURL == "" {
+		payload.Payload.MediaURL = body.MediaURL
+	}
+	return &payload, nil
+}
+
+// handleMessagePayload routes a parsed WAHA message to text, voice note or image processing
+func (h *WebhookHandler) handleMessagePayload(c *fiber.Ctx, payload *WAHAWebhookPayload) error {
 	log.Printf("📨 Webhook received - Event: %s, From: %s, FromMe: %v, HasMedia: %v, MimeType: %s, MediaURL: %s, Body: %s",
 		payload.Event, payload.Payload.From, payload.Payload.FromMe, payload.Payload.HasMedia, payload.Payload.MimeType, payload.Payload.MediaURL, payload.Payload.Body)
 
+	// Reactions (e.g. 👍 to confirm an order) are separate events without a body
+	if payload.Event == "message.reaction" {
+		return h.handleReactionPayload(c, payload)
+	}
+
+	// Delivery acks of our own messages update campaign delivery tracking
+	if payload.Event == "message.ack" {
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessMessageAck(payload.Session, payload.Payload.ID, payload.Payload.Ack)
+		})
+		return c.JSON(fiber.Map{"status": "received"})
+	}
+
 	// Skip invalid messages
 	if payload.Event != "message" || payload.Payload.FromMe || payload.Payload.From == "" {
 		log.Printf("⏭️ Skipping event - Event: %s, FromMe: %v, From: %s",
@@ -75,6 +298,23 @@ func (h *WebhookHandler) ReceiveWebhook(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{"status": "ignored"})
 	}
 
+	// WAHA retries deliveries it considers failed; handle each message once
+	if h.webhookService.IsDuplicateMessage(payload.Session, payload.Payload.ID) {
+		log.Printf("⏭️ Skipping duplicate message %s", payload.Payload.ID)
+		return c.JSON(fiber.Map{"status": "duplicate"})
+	}
+
+	// Tenants can turn off answering in WhatsApp groups
+	if strings.HasSuffix(payload.Payload.From, "@g.us") &&
+		!h.webhookService.GroupMessagesEnabled(payload.Session, extractPhoneNumber(payload.Payload.From)) {
+		return c.JSON(fiber.Map{"status": "ignored", "reason": "group_disabled"})
+	}
+
+	// Location messages (store locator) carry coordinates instead of text
+	if payload.Payload.Location != nil {
+		return h.handleLocationPayload(c, payload)
+	}
+
 	// Check if this is an image message (receipt photo)
 	isImageMessage := payload.Payload.HasMedia
 
@@ -95,27 +335,132 @@ func (h *WebhookHandler) ReceiveWebhook(c *fiber.Ctx) error {
 		})
 	}
 
-	// Process message based on type
+	// Process message based on type, in the background (the request context is captured now, as the
+	// fiber context is reused once the webhook is answered)
+	ctx := c.UserContext()
 	if isImageMessage {
 		// Extract media URL from various possible fields
-		mediaURL := extractMediaURL(&payload)
+		mediaURL := extractMediaURL(payload)
 		if mediaURL == "" {
-			log.Printf("⚠️ Image message but no media URL found")
+			log.Printf("⚠️ Media message but no media URL found")
 			return c.JSON(fiber.Map{"status": "ignored", "reason": "no_media_url"})
 		}
 
+		// Voice notes are transcribed and answered like text
+		if mimeType := extractMimeType(payload); strings.HasPrefix(mimeType, "audio/") {
+			log.Printf("🎤 Voice note detected from %s - MediaURL: %s", phoneNumber, mediaURL)
+			h.webhookService.Go(func() {
+				h.webhookService.ProcessVoiceMessage(ctx, payload.Session, phoneNumber, mediaURL, mimeType, extractMessageRef(payload))
+			})
+			return c.JSON(fiber.Map{"status": "received"})
+		}
+
 		log.Printf("📸 Image message detected from %s - MediaURL: %s", phoneNumber, mediaURL)
 		// Process image message (OCR for receipt) - delegate to service
-		go h.webhookService.ProcessImageMessage(payload.Session, phoneNumber, mediaURL)
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessImageMessage(ctx, payload.Session, phoneNumber, mediaURL)
+		})
 	} else {
 		log.Printf("✅ Text message detected from %s: %s", phoneNumber, payload.Payload.Body)
 		// Process text message (AI chat) - delegate to service
-		go h.webhookService.ProcessTextMessage(payload.Session, phoneNumber, payload.Payload.Body)
+		h.webhookService.Go(func() {
+			h.webhookService.ProcessTextMessage(ctx, payload.Session, phoneNumber, payload.Payload.Body, extractMessageRef(payload))
+		})
+	}
+
+	return c.JSON(fiber.Map{"status": "received"})
+}
+
+// handleLocationPayload routes a WAHA location message to the store locator
+func (h *WebhookHandler) handleLocationPayload(c *fiber.Ctx, payload *WAHAWebhookPayload) error {
+	phoneNumber := extractPhoneNumber(payload.Payload.From)
+	if phoneNumber == "" {
+		log.Printf("⚠️ Invalid phone number format: %s", payload.Payload.From)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number",
+		})
+	}
+
+	latitude, latErr := payload.Payload.Location.Latitude.Float64()
+	longitude, lngErr := payload.Payload.Location.Longitude.Float64()
+	if latErr != nil || lngErr != nil {
+		log.Printf("⚠️ Location message without valid coordinates from %s", phoneNumber)
+		return c.JSON(fiber.Map{"status": "ignored", "reason": "invalid_location"})
 	}
 
+	log.Printf("📍 Location message detected from %s: %.6f,%.6f (live: %v)", phoneNumber, latitude, longitude, payload.Payload.Location.Live)
+	h.webhookService.Go(func() {
+		h.webhookService.ProcessLocationMessage(payload.Session, phoneNumber, latitude, longitude)
+	})
+
 	return c.JSON(fiber.Map{"status": "received"})
 }
 
+// handleReactionPayload routes a WAHA reaction event to intent handling and workflows
+func (h *WebhookHandler) handleReactionPayload(c *fiber.Ctx, payload *WAHAWebhookPayload) error {
+	reaction := payload.Payload.Reaction
+	if payload.Payload.FromMe || payload.Payload.From == "" || reaction == nil || reaction.Text == "" {
+		log.Printf("⏭️ Skipping reaction - FromMe: %v, From: %s", payload.Payload.FromMe, payload.Payload.From)
+		return c.JSON(fiber.Map{"status": "ignored"})
+	}
+
+	if h.webhookService.IsDuplicateMessage(payload.Session, payload.Payload.ID) {
+		log.Printf("⏭️ Skipping duplicate reaction %s", payload.Payload.ID)
+		return c.JSON(fiber.Map{"status": "duplicate"})
+	}
+
+	phoneNumber := extractPhoneNumber(payload.Payload.From)
+	if phoneNumber == "" {
+		log.Printf("⚠️ Invalid phone number format: %s", payload.Payload.From)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid phone number",
+		})
+	}
+
+	log.Printf("👍 Reaction detected from %s: %s on %s", phoneNumber, reaction.Text, reaction.MessageID)
+	h.webhookService.Go(func() {
+		h.webhookService.ProcessReaction(payload.Session, phoneNumber, reaction.Text, reaction.MessageID)
+	})
+
+	return c.JSON(fiber.Map{"status": "received"})
+}
+
+// extractMessageRef reads the message ID and the quoted message (replyTo, or the WEBJS _data fields)
+func extractMessageRef(payload *WAHAWebhookPayload) services.MessageRef {
+	ref := services.MessageRef{ID: payload.Payload.ID}
+
+	// The quoted message is ours when its sender isn't the customer
+	customer := extractPhoneNumber(payload.Payload.From)
+	fromBusiness := func(participant string) bool {
+		return participant != "" && extractPhoneNumber(participant) != customer
+	}
+
+	if replyTo := payload.Payload.ReplyTo; replyTo != nil && (replyTo.ID != "" || replyTo.Body != "") {
+		ref.ReplyToID = replyTo.ID
+		ref.ReplyToBody = replyTo.Body
+		ref.ReplyToFromMe = fromBusiness(replyTo.Participant)
+		return ref
+	}
+
+	if data := payload.Payload.Data; data != nil && data.QuotedStanzaID != "" {
+		ref.ReplyToID = data.QuotedStanzaID
+		if data.QuotedMsg != nil {
+			ref.ReplyToBody = data.QuotedMsg.Body
+		}
+		ref.ReplyToFromMe = fromBusiness(data.QuotedParticipant)
+	}
+	return ref
+}
+
+// extractMimeType returns the media type of a message, from the payload or the WAHA media object
+func extractMimeType(payload *WAHAWebhookPayload) string {
+	mimeType := payload.Payload.MimeType
+	if mimeType == "" && payload.Payload.Media != nil {
+		mimeType, _ = payload.Payload.Media["mimetype"].(string)
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
+
 // extractMediaURL tries to extract media URL from various possible fields
 func extractMediaURL(payload *WAHAWebhookPayload) string {
 	// Try direct mediaUrl field first
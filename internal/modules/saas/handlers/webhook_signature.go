@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errWebhookUnsigned is returned for a webhook request that carries no signature of a configured provider
+var errWebhookUnsigned = errors.New("webhook request is not signed")
+
+// WebhookVerifier is the signature check of one WhatsApp provider
+type WebhookVerifier interface {
+	// Name is the provider of the signature
+	Name() string
+	// Signed reports whether the request carries this provider's signature
+	Signed(c *fiber.Ctx) bool
+	// MAC returns the hash the body is fed into while it is read, nil when the signature does not cover the body
+	MAC() hash.Hash
+	// Verify checks the signature once the body has been read into mac
+	Verify(c *fiber.Ctx, mac hash.Hash) bool
+}
+
+// WebhookSecrets are the shared secrets of the providers that may call /webhook; empty ones are not accepted
+type WebhookSecrets struct {
+	WAHAHMACKey       string // WAHA webhook hmac.key, signs the body (X-Webhook-Hmac, sha512)
+	CloudAPIAppSecret string // Meta app secret, signs the body (X-Hub-Signature-256)
+	GreenAPIToken     string // GreenAPI webhookUrlToken, sent as a bearer token
+	AllowUnsigned     bool   // Accept requests without a signature (local development only)
+}
+
+// WebhookSignatures picks the verification strategy of a webhook request by the signature it carries
+type WebhookSignatures struct {
+	verifiers     []WebhookVerifier
+	allowUnsigned bool
+}
+
+// NewWebhookSignatures creates the strategies of the providers that have a secret configured
+func NewWebhookSignatures(secrets WebhookSecrets) *WebhookSignatures {
+	s := &WebhookSignatures{allowUnsigned: secrets.AllowUnsigned}
+	if secrets.WAHAHMACKey != "" {
+		s.verifiers = append(s.verifiers, NewWAHAVerifier(secrets.WAHAHMACKey))
+	}
+	if secrets.CloudAPIAppSecret != "" {
+		s.verifiers = append(s.verifiers, &cloudAPIVerifier{appSecret: []byte(secrets.CloudAPIAppSecret)})
+	}
+	if secrets.GreenAPIToken != "" {
+		s.verifiers = append(s.verifiers, &greenAPIVerifier{token: []byte(secrets.GreenAPIToken)})
+	}
+	return s
+}
+
+// Providers lists the providers whose signatures are accepted
+func (s *WebhookSignatures) Providers() []string {
+	names := make([]string, len(s.verifiers))
+	for i, v := range s.verifiers {
+		names[i] = v.Name()
+	}
+	return names
+}
+
+// Select returns the strategy matching the request's signature. It returns nil without error for
+// unsigned requests only when they are allowed.
+func (s *WebhookSignatures) Select(c *fiber.Ctx) (WebhookVerifier, error) {
+	for _, v := range s.verifiers {
+		if v.Signed(c) {
+			return v, nil
+		}
+	}
+	if s.allowUnsigned {
+		return nil, nil
+	}
+	return nil, errWebhookUnsigned
+}
+
+// wahaVerifier checks WAHA's HMAC-SHA512 of the body, hex encoded in X-Webhook-Hmac
+type wahaVerifier struct {
+	key []byte
+}
+
+// NewWAHAVerifier creates the WAHA strategy for a webhook hmac key
+func NewWAHAVerifier(key string) WebhookVerifier {
+	return &wahaVerifier{key: []byte(key)}
+}
+
+func (v *wahaVerifier) Name() string {
+	return "waha"
+}
+
+func (v *wahaVerifier) Signed(c *fiber.Ctx) bool {
+	return c.Get("X-Webhook-Hmac") != ""
+}
+
+func (v *wahaVerifier) MAC() hash.Hash {
+	return hmac.New(sha512.New, v.key)
+}
+
+func (v *wahaVerifier) Verify(c *fiber.Ctx, mac hash.Hash) bool {
+	if algorithm := c.Get("X-Webhook-Hmac-Algorithm"); algorithm != "" && !strings.EqualFold(algorithm, "sha512") {
+		return false
+	}
+	return verifyHexMAC(mac, c.Get("X-Webhook-Hmac"))
+}
+
+// cloudAPIVerifier checks Meta's HMAC-SHA256 of the body with the app secret, sent as X-Hub-Signature-256: sha256=<hex>
+type cloudAPIVerifier struct {
+	appSecret []byte
+}
+
+func (v *cloudAPIVerifier) Name() string {
+	return "cloudapi"
+}
+
+func (v *cloudAPIVerifier) Signed(c *fiber.Ctx) bool {
+	return c.Get("X-Hub-Signature-256") != ""
+}
+
+func (v *cloudAPIVerifier) MAC() hash.Hash {
+	return hmac.New(sha256.New, v.appSecret)
+}
+
+func (v *cloudAPIVerifier) Verify(c *fiber.Ctx, mac hash.Hash) bool {
+	signature, ok := strings.CutPrefix(c.Get("X-Hub-Signature-256"), "sha256=")
+	return ok && verifyHexMAC(mac, signature)
+}
+
+// greenAPIVerifier checks the webhookUrlToken GreenAPI sends as Authorization: Bearer <token>.
+// The token does not cover the body.
+type greenAPIVerifier struct {
+	token []byte
+}
+
+func (v *greenAPIVerifier) Name() string {
+	return "greenapi"
+}
+
+func (v *greenAPIVerifier) Signed(c *fiber.Ctx) bool {
+	return strings.HasPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+}
+
+func (v *greenAPIVerifier) MAC() hash.Hash {
+	return nil
+}
+
+func (v *greenAPIVerifier) Verify(c *fiber.Ctx, _ hash.Hash) bool {
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	return subtle.ConstantTimeCompare([]byte(token), v.token) == 1
+}
+
+// verifyHexMAC compares a hex encoded signature with the MAC of the body in constant time
+func verifyHexMAC(mac hash.Hash, signature string) bool {
+	if mac == nil || signature == "" {
+		return false
+	}
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
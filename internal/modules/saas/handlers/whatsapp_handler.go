@@ -11,12 +11,14 @@ import (
 type WhatsAppHandler struct {
 	whatsappService *whatsapp.Service
 	clientRepo      repositories.ClientRepo
+	webhookHMACKey  string // Signs the webhooks of sessions configured here, checked by /webhook
 }
 
-func NewWhatsAppHandler(whatsappService *whatsapp.Service, clientRepo repositories.ClientRepo) *WhatsAppHandler {
+func NewWhatsAppHandler(whatsappService *whatsapp.Service, clientRepo repositories.ClientRepo, webhookHMACKey string) *WhatsAppHandler {
 	return &WhatsAppHandler{
 		whatsappService: whatsappService,
 		clientRepo:      clientRepo,
+		webhookHMACKey:  webhookHMACKey,
 	}
 }
 
@@ -132,11 +134,21 @@ func (h *WhatsAppHandler) GetSessionStatus(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
+	response := fiber.Map{
 		"session_id": sessionID,
 		"connected":  connected,
 		"provider":   h.whatsappService.GetProviderName(),
-	})
+	}
+
+	// Vacation mode of the tenant on this session
+	if client, err := h.clientRepo.GetClientByWhatsAppSession(sessionID); err == nil {
+		response["bot_paused"] = client.BotPaused
+		if client.BotResumeAt != nil {
+			response["bot_resume_at"] = client.BotResumeAt
+		}
+	}
+
+	return c.JSON(response)
 }
 
 // StopSession godoc
@@ -223,7 +235,7 @@ func (h *WhatsAppHandler) RestartSession(c *fiber.Ctx) error {
 
 // ConfigureWebhook godoc
 // @Summary Configure webhook for WhatsApp session
-// @Description Configure webhook URL for receiving WhatsApp messages
+// @Description Configure webhook URL for receiving WhatsApp messages. When WAHA_WEBHOOK_HMAC_KEY is set, WAHA signs the webhooks with it as /webhook requires.
 // @Tags WhatsApp
 // @Accept json
 // @Produce json
@@ -255,7 +267,16 @@ func (h *WhatsAppHandler) ConfigureWebhook(c *fiber.Ctx) error {
 
 	log.Printf("🔧 Configuring webhook for session %s: %s", req.SessionID, req.WebhookURL)
 
-	if err := h.whatsappService.ConfigureWebhook(req.SessionID, req.WebhookURL); err != nil {
+	var err error
+	if h.webhookHMACKey != "" {
+		err = h.whatsappService.ConfigureWebhookWithOptions(req.SessionID, req.WebhookURL, whatsapp.WebhookOptions{
+			Events:  []string{"message", "message.reaction", "message.ack"}, // Same events as ConfigureWebhook
+			HMACKey: h.webhookHMACKey,
+		})
+	} else {
+		err = h.whatsappService.ConfigureWebhook(req.SessionID, req.WebhookURL)
+	}
+	if err != nil {
 		log.Printf("❌ Failed to configure webhook: %v", err)
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
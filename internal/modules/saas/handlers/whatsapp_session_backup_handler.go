@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type WhatsAppSessionBackupHandler struct {
+	backupService *services.WhatsAppSessionBackupService
+}
+
+func NewWhatsAppSessionBackupHandler(backupService *services.WhatsAppSessionBackupService) *WhatsAppSessionBackupHandler {
+	return &WhatsAppSessionBackupHandler{backupService: backupService}
+}
+
+// RestoreSessionsRequest selects the backup to restore
+type RestoreSessionsRequest struct {
+	BackupID string `json:"backup_id,omitempty"` // Defaults to the latest backup
+}
+
+// ListSessionBackups godoc
+// @Summary List WhatsApp session backups
+// @Description Snapshots of the WAHA sessions (names, webhook and other session configuration), taken every hour, newest first. file_url is the copy in object storage with the webhook HMAC keys redacted. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param limit query int false "Max backups (default and max 72)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /admin/whatsapp/session-backups [get]
+func (h *WhatsAppSessionBackupHandler) ListSessionBackups(c *fiber.Ctx) error {
+	backups, err := h.backupService.List(c.QueryInt("limit", 0))
+	if err != nil {
+		log.Printf("❌ Failed to list session backups: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "failed to list session backups"})
+	}
+
+	return c.JSON(fiber.Map{
+		"backups": backups,
+		"count":   len(backups),
+	})
+}
+
+// BackupSessions godoc
+// @Summary Back up WhatsApp sessions now
+// @Description Snapshots every WAHA session right away, e.g. before planned maintenance of the WAHA host. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 409 {object} map[string]interface{}
+// @Router /admin/whatsapp/session-backups [post]
+func (h *WhatsAppSessionBackupHandler) BackupSessions(c *fiber.Ctx) error {
+	backup, err := h.backupService.Backup()
+	if err != nil {
+		return sessionBackupError(c, err)
+	}
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{"backup": backup})
+}
+
+// RestoreSessions godoc
+// @Summary Restore WhatsApp sessions from a backup
+// @Description Recreates the sessions of a backup on the WAHA instance with their configuration, after the WAHA container was replaced. Sessions still working are left alone and sessions that were stopped are not started. When WAHA's session storage survived the replacement the sessions reconnect on their own; the ones reported with needs_qr lost their pairing and their tenant has to scan a new QR code. Requires the X-Admin-Key header.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Platform admin key"
+// @Param request body RestoreSessionsRequest false "Backup to restore"
+// @Success 200 {object} services.WhatsAppSessionRestore
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /admin/whatsapp/session-backups/restore [post]
+func (h *WhatsAppSessionBackupHandler) RestoreSessions(c *fiber.Ctx) error {
+	var req RestoreSessionsRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid request body"})
+		}
+	}
+
+	var backupID *uuid.UUID
+	if req.BackupID != "" {
+		id, err := uuid.Parse(req.BackupID)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid backup_id"})
+		}
+		backupID = &id
+	}
+
+	restore, err := h.backupService.Restore(backupID)
+	if err != nil {
+		return sessionBackupError(c, err)
+	}
+	return c.JSON(restore)
+}
+
+func sessionBackupError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrSessionBackupNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, services.ErrNoSessionsToBackUp):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, whatsapp.ErrSessionsUnsupported):
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
+	}
+	log.Printf("❌ WhatsApp session backup operation failed: %v", err)
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": err.Error()})
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WhatsAppSessionHandler struct {
+	sessionService *services.WhatsAppSessionService
+}
+
+func NewWhatsAppSessionHandler(sessionService *services.WhatsAppSessionService) *WhatsAppSessionHandler {
+	return &WhatsAppSessionHandler{
+		sessionService: sessionService,
+	}
+}
+
+// StartClientSession godoc
+// @Summary Start a client's WhatsApp session
+// @Description Create or start the client's own WAHA session (named after the client ID) and send the client's messages through it. Scan the QR from GET /clients/{id}/whatsapp/session/qr to pair the number.
+// @Tags WhatsApp
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} whatsapp.SessionState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session [post]
+func (h *WhatsAppSessionHandler) StartClientSession(c *fiber.Ctx) error {
+	state, err := h.sessionService.Start(c.Params("id"))
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(state)
+}
+
+// GetClientSession godoc
+// @Summary Get a client's WhatsApp session
+// @Description Status of the client's own session: STARTING, SCAN_QR_CODE, WORKING, FAILED or STOPPED, with the paired number once connected
+// @Tags WhatsApp
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} whatsapp.SessionState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session [get]
+func (h *WhatsAppSessionHandler) GetClientSession(c *fiber.Ctx) error {
+	state, err := h.sessionService.Status(c.Params("id"))
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(state)
+}
+
+// GetClientSessionQR godoc
+// @Summary Get a client's WhatsApp pairing QR code
+// @Tags WhatsApp
+// @Produce image/png
+// @Param id path string true "Client ID"
+// @Success 200 {file} image/png
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session/qr [get]
+func (h *WhatsAppSessionHandler) GetClientSessionQR(c *fiber.Ctx) error {
+	qr, err := h.sessionService.QR(c.Params("id"))
+	if err != nil {
+		return sessionError(c, err)
+	}
+
+	c.Set("Content-Type", "image/png")
+	c.Set("Content-Disposition", "inline; filename=whatsapp-qr.png")
+	return c.Send(qr)
+}
+
+// RestartClientSession godoc
+// @Summary Restart a client's WhatsApp session
+// @Tags WhatsApp
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} whatsapp.SessionState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session/restart [post]
+func (h *WhatsAppSessionHandler) RestartClientSession(c *fiber.Ctx) error {
+	state, err := h.sessionService.Restart(c.Params("id"))
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(state)
+}
+
+// StopClientSession godoc
+// @Summary Stop a client's WhatsApp session
+// @Description Stop the session but keep its pairing; the client's messages fail until it is started again
+// @Tags WhatsApp
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} whatsapp.SessionState
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session/stop [post]
+func (h *WhatsAppSessionHandler) StopClientSession(c *fiber.Ctx) error {
+	state, err := h.sessionService.Stop(c.Params("id"))
+	if err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(state)
+}
+
+// DeleteClientSession godoc
+// @Summary Delete a client's WhatsApp session
+// @Description Log the number out and remove the session; the client's messages go through the default session afterwards
+// @Tags WhatsApp
+// @Produce json
+// @Param id path string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /clients/{id}/whatsapp/session [delete]
+func (h *WhatsAppSessionHandler) DeleteClientSession(c *fiber.Ctx) error {
+	if err := h.sessionService.Delete(c.Params("id")); err != nil {
+		return sessionError(c, err)
+	}
+	return c.JSON(fiber.Map{"status": "ok", "message": "Session deleted"})
+}
+
+func sessionError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, services.ErrClientNotFound):
+		return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, whatsapp.ErrSessionsUnsupported):
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+	return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+}
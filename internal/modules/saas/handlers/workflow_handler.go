@@ -1,7 +1,12 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/services"
@@ -76,6 +81,18 @@ func (h *WorkflowHandler) CreateWorkflow(c *fiber.Ctx) error {
 		})
 	}
 
+	if err := workflow.ValidateActions(req.Actions); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if err := workflow.ValidateTriggerConfig(req.TriggerType, req.TriggerConfig); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
 	// Create workflow
 	createdWorkflow, err := h.workflowService.CreateWorkflow(clientID, req)
 	if err != nil {
@@ -92,6 +109,70 @@ func (h *WorkflowHandler) CreateWorkflow(c *fiber.Ctx) error {
 	})
 }
 
+// ListWorkflowTemplates godoc
+// @Summary List workflow templates
+// @Description Predefined workflows (abandoned cart reminder, payment follow-up, daily sales summary) with the variables they take
+// @Tags Workflows
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Router /workflow-templates [get]
+func (h *WorkflowHandler) ListWorkflowTemplates(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   h.workflowService.ListTemplates(),
+	})
+}
+
+// CreateWorkflowFromTemplate godoc
+// @Summary Create a workflow from a template
+// @Description Create a workflow for a client from a predefined template, filling in its variables (defaults are used for the rest)
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param templateID path string true "Template ID"
+// @Param client_id query string true "Client ID"
+// @Param request body workflow.CreateFromTemplateRequest false "Name override, variables and active flag"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workflows/from-template/{templateID} [post]
+func (h *WorkflowHandler) CreateWorkflowFromTemplate(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	var req workflow.CreateFromTemplateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	createdWorkflow, err := h.workflowService.CreateFromTemplate(clientID, c.Params("templateID"), req)
+	if errors.Is(err, workflow.ErrTemplateNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to create workflow from template: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Workflow created successfully",
+		"data":    createdWorkflow,
+	})
+}
+
 // ListWorkflows godoc
 // @Summary List workflows for a client
 // @Description Retrieve all workflows for a specific client
@@ -207,6 +288,22 @@ func (h *WorkflowHandler) UpdateWorkflow(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.TriggerType != nil && req.TriggerConfig != nil {
+		if err := workflow.ValidateTriggerConfig(*req.TriggerType, *req.TriggerConfig); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	if req.Actions != nil {
+		if err := workflow.ValidateActions(req.Actions); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+	}
+
 	updatedWorkflow, err := h.workflowService.UpdateWorkflow(workflowID, req)
 	if err != nil {
 		log.Printf("❌ Failed to update workflow: %v", err)
@@ -353,3 +450,424 @@ func (h *WorkflowHandler) GetWorkflowExecutions(c *fiber.Ctx) error {
 		"data":   executions,
 	})
 }
+
+// BulkUpdateWorkflows godoc
+// @Summary Enable or disable workflows in bulk
+// @Description Enable or disable workflows by IDs, or all workflows of a client
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param request body workflow.BulkWorkflowRequest true "Bulk action"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /workflows/bulk [post]
+func (h *WorkflowHandler) BulkUpdateWorkflows(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	var req workflow.BulkWorkflowRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if req.Action != "enable" && req.Action != "disable" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "action must be 'enable' or 'disable'",
+		})
+	}
+
+	if !req.All && len(req.WorkflowIDs) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "workflow_ids is required unless all is true",
+		})
+	}
+
+	result, err := h.workflowService.BulkSetActive(clientID, req, requestActor(c, req.Actor))
+	if err != nil {
+		log.Printf("❌ Failed to bulk update workflows: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   result,
+	})
+}
+
+// GetKillSwitch godoc
+// @Summary Get automation kill switch status
+// @Description Check whether all automation is paused for a client
+// @Tags Workflows
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workflows/kill-switch [get]
+func (h *WorkflowHandler) GetKillSwitch(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	status, err := h.workflowService.GetAutomationStatus(clientID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "client not found",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
+
+// SetKillSwitch godoc
+// @Summary Pause or resume all automation for a client
+// @Description Client-level kill switch; while paused, event and scheduled workflows are skipped
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param client_id query string true "Client ID"
+// @Param request body workflow.KillSwitchRequest true "Kill switch state"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /workflows/kill-switch [post]
+func (h *WorkflowHandler) SetKillSwitch(c *fiber.Ctx) error {
+	clientID, err := uuid.Parse(c.Query("client_id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "valid client_id is required",
+		})
+	}
+
+	var req workflow.KillSwitchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	status, err := h.workflowService.SetAutomationPaused(clientID, req.Paused, req.Reason, requestActor(c, req.Actor))
+	if err != nil {
+		log.Printf("❌ Failed to set kill switch: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to update automation status",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   status,
+	})
+}
+
+// requestActor identifies who made a request: the authenticated user, the given actor, or "api"
+func requestActor(c *fiber.Ctx, actor string) string {
+	if userID, ok := c.Locals("userID").(string); ok && userID != "" {
+		return userID
+	}
+	if actor != "" {
+		return actor
+	}
+	return "api"
+}
+
+// ExportWorkflowExecutions godoc
+// @Summary Export workflow execution history as CSV
+// @Description Download execution history for a workflow as a CSV file (opens in Excel)
+// @Tags Workflows
+// @Produce text/csv
+// @Param id path string true "Workflow ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Success 200 {file} file
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workflows/{id}/executions/export [get]
+func (h *WorkflowHandler) ExportWorkflowExecutions(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workflow id format",
+		})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	data, err := h.workflowService.ExportExecutionsCSV(workflowID, from, to)
+	if err != nil {
+		log.Printf("❌ Failed to export executions: %v", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "failed to export executions",
+		})
+	}
+
+	filename := fmt.Sprintf("workflow_%s_executions_%s_%s.csv", workflowID, from.Format("20060102"), to.AddDate(0, 0, -1).Format("20060102"))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Attachment(filename)
+	return c.Send(data)
+}
+
+// RetryWorkflowExecution godoc
+// @Summary Retry a failed workflow execution
+// @Description Run the workflow again in the background with the trigger data of a failed execution (or one with failed actions)
+// @Tags Workflows
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param execID path string true "Execution ID"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /workflows/{id}/executions/{execID}/retry [post]
+func (h *WorkflowHandler) RetryWorkflowExecution(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workflow id format",
+		})
+	}
+	executionID, err := uuid.Parse(c.Params("execID"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid execution id format",
+		})
+	}
+
+	execution, err := h.workflowService.RetryExecution(workflowID, executionID)
+	if errors.Is(err, services.ErrExecutionNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if errors.Is(err, services.ErrExecutionNotRetryable) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to retry workflow execution: %v", err)
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Workflow retry started",
+		"data":    execution,
+	})
+}
+
+// ReceiveWorkflowWebhook godoc
+// @Summary Trigger a workflow from an external system
+// @Description Inbound webhook of a webhook triggered workflow (Shopify, Google Forms, ...). The JSON or form body becomes the trigger data; the workflow's webhook_token is sent as the X-Webhook-Token header or the token query parameter.
+// @Tags Workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param token query string false "Webhook token (or X-Webhook-Token header)"
+// @Success 202 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /workflows/{id}/webhook [post]
+func (h *WorkflowHandler) ReceiveWorkflowWebhook(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workflow id format",
+		})
+	}
+
+	token := c.Get("X-Webhook-Token")
+	if token == "" {
+		token = c.Query("token")
+	}
+
+	payload, err := webhookPayload(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	execution, err := h.workflowService.HandleWebhook(workflowID, token, payload)
+	switch {
+	case errors.Is(err, services.ErrWorkflowWebhookNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidWebhookToken):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, services.ErrWorkflowNotAccepting):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case err != nil:
+		log.Printf("❌ Failed to run webhook workflow: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to execute workflow",
+		})
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":       "success",
+		"execution_id": execution.ID,
+	})
+}
+
+// webhookPayload reads an inbound webhook body as trigger data: a JSON object as is, other JSON under
+// "payload", and form posts as their fields
+func webhookPayload(c *fiber.Ctx) (map[string]interface{}, error) {
+	payload := make(map[string]interface{})
+	if len(c.Body()) == 0 {
+		return payload, nil
+	}
+
+	if strings.HasPrefix(string(c.Request().Header.ContentType()), fiber.MIMEApplicationForm) {
+		c.Request().PostArgs().VisitAll(func(key, value []byte) {
+			payload[string(key)] = string(value)
+		})
+		return payload, nil
+	}
+
+	var body interface{}
+	if err := json.Unmarshal(c.Body(), &body); err != nil {
+		return nil, errors.New("body must be JSON or a form")
+	}
+	if object, ok := body.(map[string]interface{}); ok {
+		return object, nil
+	}
+	payload["payload"] = body
+	return payload, nil
+}
+
+// RotateWorkflowWebhookToken godoc
+// @Summary Rotate a workflow's webhook token
+// @Description Replace the secret token of a webhook triggered workflow; calls with the old token are rejected
+// @Tags Workflows
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workflows/{id}/webhook/rotate [post]
+func (h *WorkflowHandler) RotateWorkflowWebhookToken(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workflow id format",
+		})
+	}
+
+	wf, err := h.workflowService.RotateWebhookToken(workflowID)
+	if errors.Is(err, services.ErrWorkflowWebhookNotFound) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	if err != nil {
+		log.Printf("❌ Failed to rotate webhook token: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "failed to rotate webhook token",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   wf,
+	})
+}
+
+// GetWorkflowStats godoc
+// @Summary Get workflow execution analytics
+// @Description Success rate, average duration, failures by action type and executions per day
+// @Tags Workflows
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param from query string false "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param to query string false "End date (YYYY-MM-DD, inclusive), defaults to today"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /workflows/{id}/stats [get]
+func (h *WorkflowHandler) GetWorkflowStats(c *fiber.Ctx) error {
+	workflowID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workflow id format",
+		})
+	}
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	stats, err := h.workflowService.GetExecutionStats(workflowID, from, to)
+	if err != nil {
+		log.Printf("❌ Failed to get workflow stats: %v", err)
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "failed to compute workflow stats",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"status": "success",
+		"data":   stats,
+	})
+}
+
+// parseDateRange reads the from/to (YYYY-MM-DD) query params; to is inclusive
+func parseDateRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1)
+	from := to.Add(-services.DefaultStatsWindow)
+
+	if v := c.Query("from"); v != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return from, to, fmt.Errorf("invalid from date, use YYYY-MM-DD")
+		}
+		from = parsed
+	}
+	if v := c.Query("to"); v != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", v, now.Location())
+		if err != nil {
+			return from, to, fmt.Errorf("invalid to date, use YYYY-MM-DD")
+		}
+		to = parsed.AddDate(0, 0, 1)
+	}
+
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from must be before to")
+	}
+
+	return from, to, nil
+}
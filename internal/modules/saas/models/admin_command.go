@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Admin WhatsApp commands that change data and need a YA reply first
+const (
+	AdminCommandCancelOrder    = "cancel_order"
+	AdminCommandConfirmPayment = "confirm_payment"
+	AdminCommandUpdateStock    = "update_stock"
+)
+
+// AdminPendingCommand is an admin command awaiting confirmation; an admin has at most one
+type AdminPendingCommand struct {
+	ID         uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID   uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	AdminPhone string         `gorm:"type:text;not null" json:"admin_phone"`
+	Role       string         `gorm:"type:text;not null" json:"role"`
+	Command    string         `gorm:"type:text;not null" json:"command"`
+	Message    string         `gorm:"type:text;not null" json:"message"` // Command as typed
+	Params     datatypes.JSON `gorm:"type:jsonb" json:"params"`          // AdminCommandParams
+	ExpiresAt  time.Time      `gorm:"not null" json:"expires_at"`
+	CreatedAt  time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (AdminPendingCommand) TableName() string {
+	return "saas_admin_pending_commands"
+}
+
+// BeforeCreate sets UUID before creating
+func (c *AdminPendingCommand) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// AdminCommandParams are the resolved arguments of a pending command
+type AdminCommandParams struct {
+	OrderID       string `json:"order_id,omitempty"`
+	OrderNumber   string `json:"order_number,omitempty"`
+	Reason        string `json:"reason,omitempty"`
+	PaymentMethod string `json:"payment_method,omitempty"`
+	Reference     string `json:"reference,omitempty"`
+	ProductID     string `json:"product_id,omitempty"`
+	ProductName   string `json:"product_name,omitempty"`
+	Quantity      int    `json:"quantity,omitempty"` // New stock level, or the change when Relative
+	Relative      bool   `json:"relative,omitempty"` // "+5" / "-3" instead of a stock level
+}
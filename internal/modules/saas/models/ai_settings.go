@@ -0,0 +1,23 @@
+package models
+
+// AISettings is how a client's bot answers: its model, persona and instructions, and how long and
+// creative its replies are. Empty and nil values use the platform default.
+type AISettings struct {
+	LLMProvider    string   `json:"llm_provider"`
+	LLMModel       string   `json:"llm_model"`
+	PromptTemplate string   `json:"prompt_template"` // Replaces the default persona and instructions; {{business_name}} and {{tone}} are filled in
+	Tone           string   `json:"tone"`
+	Temperature    *float32 `json:"temperature"`
+	MaxTokens      *int     `json:"max_tokens"`
+}
+
+// UpdateAISettingsRequest changes a client's AI settings; omitted fields keep their current value, and an
+// empty provider or template or a temperature or max tokens of 0 restores the platform default
+type UpdateAISettingsRequest struct {
+	LLMProvider    *string  `json:"llm_provider" example:"claude"`
+	LLMModel       *string  `json:"llm_model" example:"claude-sonnet-4-5"`
+	PromptTemplate *string  `json:"prompt_template" example:"Kamu adalah Sari, admin {{business_name}} yang sopan. Jawab singkat dengan tone {{tone}}."`
+	Tone           *string  `json:"tone" example:"formal"`
+	Temperature    *float32 `json:"temperature" example:"0.3"`
+	MaxTokens      *int     `json:"max_tokens" example:"300"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// APIKey grants server-to-server access to a client's data. Only a hash of the key is stored.
+type APIKey struct {
+	ID          uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	ExternalRef string     `gorm:"type:text;not null" json:"external_ref"` // Unique per client
+	Name        string     `gorm:"type:text;not null" json:"name"`
+	KeyPrefix   string     `gorm:"type:text;not null" json:"key_prefix"` // Shown to recognise the key
+	KeyHash     string     `gorm:"type:text;not null;uniqueIndex" json:"-"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt   *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (APIKey) TableName() string {
+	return "saas_api_keys"
+}
+
+// BeforeCreate sets UUID before creating
+func (k *APIKey) BeforeCreate(tx *gorm.DB) error {
+	if k.ID == uuid.Nil {
+		k.ID = uuid.New()
+	}
+	return nil
+}
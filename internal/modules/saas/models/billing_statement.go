@@ -0,0 +1,90 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Billing statement statuses
+const (
+	StatementUnpaid = "unpaid"
+	StatementPaid   = "paid"
+	StatementVoid   = "void"
+)
+
+// BillingStatement is a tenant's monthly invoice: the plan fee plus the usage above the plan limits
+type BillingStatement struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	Number        string         `gorm:"type:text;not null;uniqueIndex" json:"number"` // Also the gateway order ID of its payment
+	Plan          string         `gorm:"type:text;not null" json:"plan"`
+	PeriodStart   time.Time      `json:"period_start"`
+	PeriodEnd     time.Time      `json:"period_end"`
+	PlanFee       float64        `gorm:"type:numeric(15,2)" json:"plan_fee"`
+	OverageAmount float64        `gorm:"type:numeric(15,2)" json:"overage_amount"`
+	Total         float64        `gorm:"type:numeric(15,2)" json:"total"`
+	Lines         datatypes.JSON `gorm:"type:jsonb" json:"lines"` // []StatementLine
+	Status        string         `gorm:"type:text;not null;default:'unpaid'" json:"status"`
+	PDFURL        string         `gorm:"column:pdf_url;type:text" json:"pdf_url,omitempty"`
+	PaymentLink   string         `gorm:"type:text" json:"payment_link,omitempty"`
+	PaymentMethod string         `gorm:"type:text" json:"payment_method,omitempty"`
+	TransactionID string         `gorm:"type:text" json:"transaction_id,omitempty"`
+	EmailTo       pq.StringArray `gorm:"type:text[]" json:"email_to,omitempty"`
+	EmailedAt     *time.Time     `json:"emailed_at,omitempty"`
+	PaidAt        *time.Time     `json:"paid_at,omitempty"`
+	VoidReason    string         `gorm:"type:text" json:"void_reason,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (BillingStatement) TableName() string {
+	return "saas_billing_statements"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *BillingStatement) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// StatementLine is the usage of one metric on a statement
+type StatementLine struct {
+	Metric    string  `json:"metric"`
+	Used      int64   `json:"used"`
+	Included  int64   `json:"included"` // 0 = unlimited
+	Overage   int64   `json:"overage"`
+	UnitSize  int     `json:"unit_size"`  // Overage is billed per this many units
+	UnitPrice float64 `json:"unit_price"` // IDR per unit size
+	Amount    float64 `json:"amount"`
+}
+
+// StatementFilter narrows a statement listing
+type StatementFilter struct {
+	ClientID *uuid.UUID
+	Status   string
+	Limit    int
+}
+
+// GenerateStatementsRequest is the body for generating statements by hand
+type GenerateStatementsRequest struct {
+	Period   string `json:"period"`              // YYYY-MM, defaults to the previous month
+	ClientID string `json:"client_id,omitempty"` // Defaults to every active client
+}
+
+// MarkStatementPaidRequest is the body for recording a payment received outside the gateway
+type MarkStatementPaidRequest struct {
+	PaymentMethod string `json:"payment_method"`
+	Reference     string `json:"reference,omitempty"`
+}
+
+// VoidStatementRequest is the body for voiding a statement
+type VoidStatementRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BranchStock represents the stock of a product at a single branch (store)
+type BranchStock struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID  uuid.UUID `gorm:"type:uuid;not null;index" json:"client_id"`
+	BranchID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_branch_product" json:"branch_id"`
+	ProductID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_branch_product" json:"product_id"`
+	Stock     int       `gorm:"type:integer;not null;default:0" json:"stock"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (BranchStock) TableName() string {
+	return "saas_branch_stock"
+}
+
+// BeforeCreate sets UUID before creating
+func (b *BranchStock) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+// SetBranchStockRequest represents a request to set a product's stock at a branch
+type SetBranchStockRequest struct {
+	ProductID string `json:"product_id" validate:"required,uuid"`
+	Stock     int    `json:"stock" validate:"gte=0"`
+}
+
+// BranchSales represents order totals for a single branch
+type BranchSales struct {
+	BranchID    *uuid.UUID `json:"branch_id"`
+	BranchName  string     `json:"branch_name"`
+	TotalOrders int64      `json:"total_orders"`
+	PaidOrders  int64      `json:"paid_orders"`
+	Revenue     float64    `json:"revenue"`
+}
+
+// SalesAnalytics represents order totals for a period, optionally scoped to a branch
+type SalesAnalytics struct {
+	Period      string        `json:"period"`
+	Start       time.Time     `json:"start"`
+	End         time.Time     `json:"end"`
+	BranchID    *uuid.UUID    `json:"branch_id,omitempty"`
+	TotalOrders int64         `json:"total_orders"`
+	PaidOrders  int64         `json:"paid_orders"`
+	Revenue     float64       `json:"revenue"`
+	Branches    []BranchSales `json:"branches"`
+}
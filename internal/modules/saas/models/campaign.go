@@ -0,0 +1,125 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Campaign audiences
+const (
+	CampaignAudienceAll          = "all"           // Every customer who chatted or ordered
+	CampaignAudienceRecentBuyers = "recent_buyers" // Customers with an order in the last RecentDays days
+	CampaignAudienceTags         = "tags"          // Customers whose chat carries the AudienceTags
+	CampaignAudienceSegment      = "segment"       // Members of the customer segment SegmentID
+)
+
+// Campaign statuses
+const (
+	CampaignStatusDraft     = "draft"
+	CampaignStatusScheduled = "scheduled"
+	CampaignStatusSending   = "sending"
+	CampaignStatusCompleted = "completed"
+	CampaignStatusCancelled = "cancelled"
+	CampaignStatusFailed    = "failed"
+)
+
+// Campaign recipient statuses, in delivery order up to read
+const (
+	CampaignRecipientPending   = "pending"
+	CampaignRecipientSent      = "sent"
+	CampaignRecipientDelivered = "delivered"
+	CampaignRecipientRead      = "read"
+	CampaignRecipientFailed    = "failed"
+	CampaignRecipientSkipped   = "skipped" // Campaign cancelled before it reached the customer
+)
+
+// Campaign is a broadcast message a client sends to an audience of its customers
+type Campaign struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Name            string         `gorm:"type:text;not null" json:"name"`
+	Message         string         `gorm:"type:text;not null" json:"message"`
+	Audience        string         `gorm:"type:text;not null;default:'all'" json:"audience"`
+	AudienceTags    datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"audience_tags"` // []string
+	MatchAllTags    bool           `gorm:"not null;default:false" json:"match_all_tags"`
+	SegmentID       *uuid.UUID     `gorm:"type:uuid" json:"segment_id,omitempty"`
+	RecentDays      int            `gorm:"not null;default:30" json:"recent_days"`
+	ThrottleSeconds int            `gorm:"not null;default:3" json:"throttle_seconds"`
+	Status          string         `gorm:"type:text;not null;default:'draft'" json:"status"`
+	ScheduledAt     *time.Time     `json:"scheduled_at,omitempty"`
+	StartedAt       *time.Time     `json:"started_at,omitempty"`
+	CompletedAt     *time.Time     `json:"completed_at,omitempty"`
+	TotalRecipients int            `gorm:"not null;default:0" json:"total_recipients"`
+	SentCount       int            `gorm:"not null;default:0" json:"sent_count"`
+	FailedCount     int            `gorm:"not null;default:0" json:"failed_count"`
+	Error           string         `gorm:"type:text" json:"error,omitempty"`
+	CreatedBy       string         `gorm:"type:text" json:"created_by,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Delivery map[string]int64 `gorm:"-" json:"delivery,omitempty"` // Recipients per status
+}
+
+// TableName specifies the table name
+func (Campaign) TableName() string {
+	return "saas_campaigns"
+}
+
+// BeforeCreate sets UUID before creating
+func (c *Campaign) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CampaignRecipient is a customer a campaign is sent to, with what WhatsApp reported about the message
+type CampaignRecipient struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	CampaignID    uuid.UUID  `gorm:"type:uuid;not null" json:"campaign_id"`
+	ClientID      uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone string     `gorm:"type:text;not null" json:"customer_phone"`
+	Status        string     `gorm:"type:text;not null;default:'pending'" json:"status"`
+	MessageID     string     `gorm:"type:text" json:"message_id,omitempty"`
+	AckMessageID  string     `gorm:"type:text" json:"-"` // MessageID without its chat prefix, as acks report it
+	Error         string     `gorm:"type:text" json:"error,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (CampaignRecipient) TableName() string {
+	return "saas_campaign_recipients"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *CampaignRecipient) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// CampaignRequest is the body for creating or updating a campaign
+type CampaignRequest struct {
+	Name            string     `json:"name"`
+	Message         string     `json:"message"`
+	Audience        string     `json:"audience"`         // all (default), recent_buyers, tags or segment
+	AudienceTags    []string   `json:"audience_tags"`    // For the tags audience
+	MatchAllTags    bool       `json:"match_all_tags"`   // Require every tag instead of any
+	SegmentID       *uuid.UUID `json:"segment_id"`       // For the segment audience
+	RecentDays      int        `json:"recent_days"`      // For recent_buyers, default 30
+	ThrottleSeconds int        `json:"throttle_seconds"` // Pause between recipients, default 3
+	ScheduledAt     *time.Time `json:"scheduled_at"`     // Set to schedule the campaign, empty keeps it a draft
+	CreatedBy       string     `json:"created_by"`
+}
+
+// ScheduleCampaignRequest is the body for scheduling a draft campaign
+type ScheduleCampaignRequest struct {
+	ScheduledAt *time.Time `json:"scheduled_at"` // Empty = send now
+}
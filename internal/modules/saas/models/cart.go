@@ -51,6 +51,7 @@ type Cart struct {
 	Items         CartItems      `json:"items" gorm:"type:jsonb;not null"`
 	TotalAmount   float64        `json:"total_amount" gorm:"type:decimal(12,2);default:0"`
 	Status        string         `json:"status" gorm:"default:'active';check:status IN ('active', 'checked_out', 'expired', 'cancelled')"`
+	BranchID      *uuid.UUID     `json:"branch_id,omitempty" gorm:"type:uuid"` // Branch selected for checkout (nearest or customer choice)
 	CreatedAt     time.Time      `json:"created_at" gorm:"default:now()"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"default:now()"`
 	ExpiresAt     time.Time      `json:"expires_at"`
@@ -19,6 +19,37 @@ type Client struct {
 	Timezone           string    `gorm:"column:timezone;type:text;default:'Asia/Jakarta'" json:"timezone"`
 	WADeviceID         string    `gorm:"column:wa_device_id;type:text" json:"wa_device_id"`
 	WhatsAppSessionID  string    `gorm:"column:whatsapp_session_id;type:text" json:"whatsapp_session_id"` // WhatsApp session ID for multi-session providers (WAHA, etc)
+	ExternalRef        *string   `gorm:"column:external_ref;type:text" json:"external_ref,omitempty"`     // Reference ID from the provisioning tool (/v1/admin)
+	Region             string    `gorm:"column:region;type:text;default:''" json:"region"`                // Region holding the tenant's data, empty = default region (changed by /admin region migration)
+
+	// Sandbox mode (simulated WhatsApp and payments)
+	SandboxMode bool `gorm:"column:sandbox_mode;default:false" json:"sandbox_mode"`
+
+	// Automation kill switch (stops event and scheduled workflows)
+	AutomationPaused       bool       `gorm:"column:automation_paused;default:false" json:"automation_paused"`
+	AutomationPausedAt     *time.Time `gorm:"column:automation_paused_at" json:"automation_paused_at,omitempty"`
+	AutomationPausedBy     string     `gorm:"column:automation_paused_by;type:text" json:"automation_paused_by,omitempty"`
+	AutomationPausedReason string     `gorm:"column:automation_paused_reason;type:text" json:"automation_paused_reason,omitempty"`
+
+	// Vacation mode (bot paused from the admin's WhatsApp, optionally until a date)
+	BotPaused   bool       `gorm:"column:bot_paused;default:false" json:"bot_paused"`
+	BotPausedAt *time.Time `gorm:"column:bot_paused_at" json:"bot_paused_at,omitempty"`
+	BotPausedBy string     `gorm:"column:bot_paused_by;type:text" json:"bot_paused_by,omitempty"`
+	BotResumeAt *time.Time `gorm:"column:bot_resume_at" json:"bot_resume_at,omitempty"` // nil = until resumed manually
+
+	// OCR raw text retention (receipts may contain card digits or names)
+	OCRRawTextRetentionDays int  `gorm:"column:ocr_raw_text_retention_days;default:30" json:"ocr_raw_text_retention_days"` // 0 = don't store, negative = keep forever
+	OCRAnonymize            bool `gorm:"column:ocr_anonymize;default:true" json:"ocr_anonymize"`
+
+	// LLM used for the client's AI features; empty = platform default
+	LLMProvider string `gorm:"column:llm_provider;type:text;default:''" json:"llm_provider"` // openai, gemini, groq, deepseek, claude
+	LLMModel    string `gorm:"column:llm_model;type:text;default:''" json:"llm_model"`       // Empty = the provider's default model
+
+	// AI reply settings; empty/nil = platform default
+	AIPromptTemplate string   `gorm:"column:ai_prompt_template;type:text;default:''" json:"ai_prompt_template"` // Replaces the default persona and instructions
+	AITemperature    *float32 `gorm:"column:ai_temperature" json:"ai_temperature"`
+	AIMaxTokens      *int     `gorm:"column:ai_max_tokens" json:"ai_max_tokens"`
+
 	CreatedAt          time.Time `gorm:"column:created_at;autoCreateTime" json:"created_at"`
 	UpdatedAt          time.Time `gorm:"column:updated_at;autoUpdateTime" json:"updated_at"`
 }
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ClientOffboarding tracks the deactivation cascade of a client
+type ClientOffboarding struct {
+	ID       uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Status   string         `gorm:"type:text;not null;default:'pending'" json:"status"` // pending, running, completed, failed
+	Steps    datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"steps"`      // []OffboardingStep
+
+	Reason        string     `gorm:"type:text" json:"reason,omitempty"`
+	RequestedBy   string     `gorm:"type:text" json:"requested_by,omitempty"`
+	RetentionDays int        `gorm:"not null;default:30" json:"retention_days"`
+	PurgeAt       *time.Time `json:"purge_at,omitempty"` // Client data may be deleted after this
+
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	LastError   string     `gorm:"type:text" json:"last_error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ClientOffboarding) TableName() string {
+	return "saas_client_offboardings"
+}
+
+// BeforeCreate sets UUID before creating
+func (o *ClientOffboarding) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// OffboardingStep is the progress of one step of the cascade
+type OffboardingStep struct {
+	Name        string     `json:"name"`
+	Status      string     `json:"status"` // pending, completed, failed, skipped
+	Detail      string     `json:"detail,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// StepList decodes the recorded steps
+func (o *ClientOffboarding) StepList() []OffboardingStep {
+	var steps []OffboardingStep
+	if len(o.Steps) > 0 {
+		_ = json.Unmarshal(o.Steps, &steps)
+	}
+	return steps
+}
+
+// SetSteps encodes the steps
+func (o *ClientOffboarding) SetSteps(steps []OffboardingStep) {
+	data, _ := json.Marshal(steps)
+	o.Steps = datatypes.JSON(data)
+}
+
+// Offboarding status constants
+const (
+	OffboardingStatusPending   = "pending"
+	OffboardingStatusRunning   = "running"
+	OffboardingStatusCompleted = "completed"
+	OffboardingStatusFailed    = "failed"
+
+	OffboardingStepPending   = "pending"
+	OffboardingStepCompleted = "completed"
+	OffboardingStepFailed    = "failed"
+	OffboardingStepSkipped   = "skipped"
+
+	ClientStatusActive   = "active"
+	ClientStatusInactive = "inactive"
+)
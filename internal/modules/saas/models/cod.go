@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CODSettings holds a tenant's cash on delivery eligibility rules
+type CODSettings struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+
+	Enabled        bool    `gorm:"default:false" json:"enabled"`
+	MaxOrderAmount float64 `gorm:"type:decimal(12,2);default:0" json:"max_order_amount"` // 0 = no limit
+
+	// Customer history
+	MinPaidOrders   int `gorm:"default:0" json:"min_paid_orders"`   // Paid orders required before COD is offered
+	MaxOpenOrders   int `gorm:"default:1" json:"max_open_orders"`   // Unpaid COD orders a customer may have at once
+	MaxFailedOrders int `gorm:"default:1" json:"max_failed_orders"` // Cancelled COD orders before COD is blocked (0 = never block)
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CODSettings) TableName() string {
+	return "saas_cod_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *CODSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultCODSettings returns the settings used when a tenant has not configured COD (disabled)
+func DefaultCODSettings(clientID uuid.UUID) *CODSettings {
+	return &CODSettings{
+		ClientID:        clientID,
+		MaxOpenOrders:   1,
+		MaxFailedOrders: 1,
+	}
+}
+
+// CODHistory summarizes a customer's orders for COD eligibility
+type CODHistory struct {
+	PaidOrders   int64 `json:"paid_orders"`
+	OpenCOD      int64 `json:"open_cod"`      // COD orders not yet paid or cancelled
+	CancelledCOD int64 `json:"cancelled_cod"` // COD orders cancelled (e.g. refused at the door)
+}
@@ -7,6 +7,12 @@ import (
 	"gorm.io/gorm"
 )
 
+// Conversation message types
+const (
+	ConversationTypeIncoming = "incoming" // Customer message and the bot's reply
+	ConversationTypeAgent    = "agent"    // Reply typed on the business phone, kept in AIResponse
+)
+
 // Conversation represents a conversation between client and customer
 type Conversation struct {
 	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
@@ -15,7 +21,17 @@ type Conversation struct {
 	MessageType   string    `gorm:"type:text;default:'incoming'" json:"message_type"`
 	MessageText   string    `gorm:"type:text" json:"message_text"`
 	AIResponse    string    `gorm:"type:text" json:"ai_response"`
-	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	Language      string    `gorm:"type:text" json:"language,omitempty"` // Detected language of the customer's message
+	MediaURL      string    `gorm:"type:text" json:"media_url,omitempty"`
+
+	// Provider message IDs, for correlating acks, reactions and quoted replies
+	InboundMessageID  string `gorm:"type:text" json:"inbound_message_id,omitempty"`  // Customer's message
+	OutboundMessageID string `gorm:"type:text" json:"outbound_message_id,omitempty"` // Bot's reply
+	ReplyToMessageID  string `gorm:"type:text" json:"reply_to_message_id,omitempty"` // Message the customer quoted
+
+	Rating    *int       `json:"rating,omitempty"` // 1-5, rated from the dashboard
+	RatedAt   *time.Time `json:"rated_at,omitempty"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
 
 	// Relationship
 	Client Client `gorm:"foreignKey:ClientID;references:ID;constraint:OnDelete:CASCADE" json:"-"`
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Conversation replay statuses
+const (
+	ConversationReplayPending   = "pending"
+	ConversationReplayRunning   = "running"
+	ConversationReplayCompleted = "completed"
+	ConversationReplayFailed    = "failed"
+)
+
+// ConversationReplay is a QA run replaying a client's historical customer messages against the current
+// configuration, comparing the new answers with the ones that were sent
+type ConversationReplay struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID         uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Status           string         `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Provider         string         `gorm:"type:text;not null" json:"provider"`
+	Model            string         `gorm:"type:text;not null" json:"model"`
+	CustomerPhone    string         `gorm:"type:text" json:"customer_phone,omitempty"` // Empty = every customer
+	FromAt           time.Time      `gorm:"not null" json:"from_at"`
+	ToAt             time.Time      `gorm:"not null" json:"to_at"`
+	TotalTurns       int            `gorm:"not null;default:0" json:"total_turns"`
+	ReplayedTurns    int            `gorm:"not null;default:0" json:"replayed_turns"`
+	ChangedTurns     int            `gorm:"not null;default:0" json:"changed_turns"`
+	FailedTurns      int            `gorm:"not null;default:0" json:"failed_turns"`
+	AvgSimilarity    float64        `gorm:"not null;default:0" json:"avg_similarity"` // 0-1
+	AvgLatencyMs     int64          `gorm:"not null;default:0" json:"avg_latency_ms"`
+	P95LatencyMs     int64          `gorm:"column:p95_latency_ms;not null;default:0" json:"p95_latency_ms"`
+	EstimatedCostUSD float64        `gorm:"column:estimated_cost_usd;not null;default:0" json:"estimated_cost_usd"`
+	PriceKnown       bool           `gorm:"not null;default:false" json:"price_known"`
+	Turns            datatypes.JSON `gorm:"type:jsonb" json:"turns,omitempty"` // []ConversationReplayTurn
+	RequestedBy      string         `gorm:"type:text" json:"requested_by,omitempty"`
+	Error            string         `gorm:"type:text" json:"error,omitempty"`
+	StartedAt        *time.Time     `json:"started_at,omitempty"`
+	FinishedAt       *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ConversationReplayTurn is one historical customer message with the answer sent then and the answer given now
+type ConversationReplayTurn struct {
+	ConversationID   uuid.UUID `json:"conversation_id"`
+	CustomerPhone    string    `json:"customer_phone"`
+	Message          string    `json:"message"`
+	OriginalResponse string    `json:"original_response"`
+	NewResponse      string    `json:"new_response"`
+	Commands         []string  `json:"commands,omitempty"` // Cart commands the new answer asked for, not executed
+	Changed          bool      `json:"changed"`
+	Similarity       float64   `json:"similarity"` // 0-1, word overlap of the two answers
+	LatencyMs        int64     `json:"latency_ms"`
+	CostUSD          float64   `json:"cost_usd"`
+	Error            string    `json:"error,omitempty"`
+	OriginalAt       time.Time `json:"original_at"`
+}
+
+// TableName specifies the table name
+func (ConversationReplay) TableName() string {
+	return "saas_conversation_replays"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *ConversationReplay) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
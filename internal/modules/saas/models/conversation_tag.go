@@ -0,0 +1,100 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// ConversationTag is a label a client defines for customer chats (e.g. "komplain", "vip", "retur")
+type ConversationTag struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Name        string         `gorm:"type:text;not null" json:"name"` // Lowercase, no spaces, so it can be typed in chat commands
+	Color       string         `gorm:"type:text" json:"color,omitempty"`
+	Description string         `gorm:"type:text" json:"description,omitempty"`
+	Keywords    datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"keywords"` // []string, customer messages containing one get the tag automatically
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ConversationTag) TableName() string {
+	return "saas_conversation_tags"
+}
+
+// BeforeCreate sets UUID before creating
+func (t *ConversationTag) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// ConversationTagAssignment applies a tag to a customer's chat with the client
+type ConversationTagAssignment struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	TagID         uuid.UUID `gorm:"type:uuid;not null" json:"tag_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+	Source        string    `gorm:"type:text;not null;default:'manual'" json:"source"` // manual, command, auto
+	TaggedBy      string    `gorm:"type:text" json:"tagged_by,omitempty"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+
+	Tag *ConversationTag `gorm:"foreignKey:TagID" json:"tag,omitempty"`
+}
+
+// TableName specifies the table name
+func (ConversationTagAssignment) TableName() string {
+	return "saas_conversation_tag_assignments"
+}
+
+// BeforeCreate sets UUID before creating
+func (a *ConversationTagAssignment) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// Tag assignment sources
+const (
+	TagSourceManual  = "manual"
+	TagSourceCommand = "command"
+	TagSourceAuto    = "auto"
+)
+
+// ConversationTagRequest is the body for creating or updating a tag definition
+type ConversationTagRequest struct {
+	Name        string   `json:"name"`
+	Color       string   `json:"color"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+}
+
+// TagConversationRequest is the body for tagging a customer's chat
+type TagConversationRequest struct {
+	Tags     []string `json:"tags"`
+	TaggedBy string   `json:"tagged_by"`
+}
+
+// ConversationFilter selects chats for the conversation list
+type ConversationFilter struct {
+	Tags     []string // Tag names
+	MatchAll bool     // Chats must carry every tag instead of any
+	Phone    string   // Customer phone prefix
+	Limit    int
+	Offset   int
+}
+
+// ConversationSummary is a customer's chat with the client: the latest turn, turn count and tags
+type ConversationSummary struct {
+	CustomerPhone string    `json:"customer_phone"`
+	LastMessage   string    `json:"last_message"`
+	LastResponse  string    `json:"last_response"`
+	LastMessageAt time.Time `json:"last_message_at"`
+	Messages      int64     `json:"messages"`
+	Tags          []string  `json:"tags" gorm:"-"`
+}
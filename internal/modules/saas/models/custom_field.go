@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Entities that can carry custom fields
+const (
+	CustomFieldEntityCustomer = "customer"
+	CustomFieldEntityOrder    = "order"
+	CustomFieldEntityProduct  = "product"
+)
+
+// Custom field value types
+const (
+	CustomFieldTypeText    = "text"
+	CustomFieldTypeNumber  = "number"
+	CustomFieldTypeBoolean = "boolean"
+	CustomFieldTypeDate    = "date" // YYYY-MM-DD
+	CustomFieldTypeSelect  = "select"
+)
+
+// CustomFieldDefinition is an extra field a client adds to its customers, orders or products.
+// Values are stored in the entity's custom_fields JSONB column under Key
+type CustomFieldDefinition struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID  uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	Entity    string         `gorm:"type:text;not null" json:"entity"`
+	Key       string         `gorm:"type:text;not null" json:"key"`
+	Label     string         `gorm:"type:text;not null" json:"label"`
+	Type      string         `gorm:"type:text;not null" json:"type"`
+	Required  bool           `json:"required"`
+	Options   pq.StringArray `gorm:"type:text[]" json:"options,omitempty"`    // Select only
+	Pattern   string         `gorm:"type:text" json:"pattern,omitempty"`      // Text only, regular expression
+	MinValue  *float64       `gorm:"type:numeric(15,2)" json:"min,omitempty"` // Number: value, text: length
+	MaxValue  *float64       `gorm:"type:numeric(15,2)" json:"max,omitempty"` // Number: value, text: length
+	Position  int            `json:"position"`
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CustomFieldDefinition) TableName() string {
+	return "saas_custom_field_definitions"
+}
+
+// BeforeCreate sets UUID before creating
+func (d *CustomFieldDefinition) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// CustomFieldDefinitionRequest is the body for creating or updating a custom field
+type CustomFieldDefinitionRequest struct {
+	Entity   string   `json:"entity" example:"order"`
+	Key      string   `json:"key" example:"table_number"`
+	Label    string   `json:"label" example:"Nomor Meja"`
+	Type     string   `json:"type" example:"number"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+	Pattern  string   `json:"pattern,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Position int      `json:"position"`
+}
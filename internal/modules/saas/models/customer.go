@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Customer is a client's customer, identified by phone number. Customers are created from their first
+// inbound message (or custom field write) and carry the name and notes staff keep on them.
+type Customer struct {
+	ID                uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID          uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	CustomerPhone     string         `gorm:"type:text;not null" json:"customer_phone"`
+	Name              string         `gorm:"type:text;not null" json:"name"`
+	Notes             string         `gorm:"type:text;not null" json:"notes"`
+	MessageCount      int            `gorm:"not null;default:0" json:"message_count"` // Inbound messages received
+	LastInteractionAt *time.Time     `json:"last_interaction_at"`                     // Latest inbound message
+	CustomFields      datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"custom_fields"`
+	CreatedAt         time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt         time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (Customer) TableName() string {
+	return "saas_customers"
+}
+
+// BeforeCreate sets UUID before creating
+func (c *Customer) BeforeCreate(tx *gorm.DB) error {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return nil
+}
+
+// CustomerProfile is a customer with what the CRM aggregates about them
+type CustomerProfile struct {
+	Customer
+	OrderCount    int64      `json:"order_count"`    // Paid orders, including ones refunded since
+	LifetimeValue float64    `json:"lifetime_value"` // Paid order totals less refunds
+	LastOrderAt   *time.Time `json:"last_order_at"`  // Latest payment
+	Tags          []string   `json:"tags" gorm:"-"`  // Tags on the customer's chat
+}
+
+// Customer list orders
+const (
+	CustomerSortRecent        = "recent"         // Latest interaction first
+	CustomerSortLifetimeValue = "lifetime_value" // Highest lifetime value first
+	CustomerSortName          = "name"           // Alphabetical
+)
+
+// CustomerFilter selects customers for the CRM list
+type CustomerFilter struct {
+	Search  string        // Part of the name or phone
+	Tags    []string      // Tag names; customers whose chat carries any of them
+	Segment *SegmentRules // Members of a segment
+	Sort    string        // CustomerSort*
+	Limit   int
+	Offset  int
+}
+
+// UpdateCustomerRequest edits a customer's profile; fields left out keep their value
+type UpdateCustomerRequest struct {
+	Name  *string `json:"name,omitempty" example:"Budi Santoso"`
+	Notes *string `json:"notes,omitempty" example:"Pelanggan tetap, suka pesan untuk kantor"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Preference keys a customer can set in chat with [SET_PREFERENCE:key|value]
+const (
+	PreferenceLanguage      = "language"
+	PreferencePaymentMethod = "payment_method"
+	PreferenceDeliveryNotes = "delivery_notes"
+)
+
+// Who last changed a customer's preferences
+const (
+	PreferenceSourceCustomer = "customer"
+	PreferenceSourceAgent    = "agent"
+)
+
+// CustomerPreference is what a customer prefers, remembered across conversations: the bot replies in the
+// preferred language, and checkout uses the preferred payment method and delivery notes
+type CustomerPreference struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+	Language      string    `gorm:"type:varchar(5);not null" json:"language"`        // id, en, jv, su; empty = not stated
+	PaymentMethod string    `gorm:"type:varchar(20);not null" json:"payment_method"` // cod, qris, bank_transfer, ewallet, credit_card
+	DeliveryNotes string    `gorm:"type:text;not null" json:"delivery_notes"`
+	UpdatedBy     string    `gorm:"type:varchar(20);not null" json:"updated_by"` // customer or agent
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CustomerPreference) TableName() string {
+	return "saas_customer_preferences"
+}
+
+// IsEmpty reports whether no preference is set
+func (p *CustomerPreference) IsEmpty() bool {
+	return p.Language == "" && p.PaymentMethod == "" && p.DeliveryNotes == ""
+}
+
+// UpdateCustomerPreferenceRequest edits a customer's preferences; fields left out keep their value and an empty string clears one
+type UpdateCustomerPreferenceRequest struct {
+	Language      *string `json:"language,omitempty" example:"en"`
+	PaymentMethod *string `json:"payment_method,omitempty" example:"qris"`
+	DeliveryNotes *string `json:"delivery_notes,omitempty" example:"Titip di satpam"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// CustomerSegment is a saved filter over a client's customers (e.g. "VIP who ordered this month"), used
+// as a campaign audience and checked by workflow conditions. Members are resolved when used, so a
+// segment follows its customers' orders, tags and activity.
+type CustomerSegment struct {
+	ID          uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Name        string         `gorm:"type:text;not null" json:"name"`
+	Description string         `gorm:"type:text;not null" json:"description"`
+	Rules       datatypes.JSON `gorm:"type:jsonb;not null;default:'{}'" json:"rules"` // SegmentRules
+	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CustomerSegment) TableName() string {
+	return "saas_customer_segments"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *CustomerSegment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// SegmentRules select customers; every rule set must match, and no rules match every customer. Orders
+// count once paid, including ones refunded since.
+type SegmentRules struct {
+	Tags                 []string `json:"tags,omitempty"`                    // Chat carries any of these tags
+	MatchAllTags         bool     `json:"match_all_tags,omitempty"`          // Chat carries every one of Tags instead
+	ExcludeTags          []string `json:"exclude_tags,omitempty"`            // Chat carries none of these tags
+	OrderedWithinDays    int      `json:"ordered_within_days,omitempty"`     // Paid an order in the last N days
+	NotOrderedWithinDays int      `json:"not_ordered_within_days,omitempty"` // Paid no order in the last N days, e.g. churn risk
+	MinOrders            int      `json:"min_orders,omitempty"`              // Paid at least N orders
+	MinLifetimeValue     float64  `json:"min_lifetime_value,omitempty"`      // Paid at least this much, less refunds
+	ActiveWithinDays     int      `json:"active_within_days,omitempty"`      // Wrote in within the last N days
+}
+
+// CustomerSegmentRequest is the body for creating or updating a segment
+type CustomerSegmentRequest struct {
+	Name        string       `json:"name" example:"vip-aktif"`
+	Description string       `json:"description" example:"VIP yang belanja 30 hari terakhir"`
+	Rules       SegmentRules `json:"rules"`
+}
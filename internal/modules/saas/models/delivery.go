@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Driver represents a delivery driver of a client
+type Driver struct {
+	ID       uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID  `gorm:"type:uuid;not null;index" json:"client_id"`
+	BranchID *uuid.UUID `gorm:"type:uuid" json:"branch_id,omitempty"` // Home branch (store), optional
+
+	Name     string `gorm:"type:text;not null" json:"name"`
+	Phone    string `gorm:"type:text;not null" json:"phone"`
+	IsActive bool   `gorm:"type:boolean;default:true" json:"is_active"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (Driver) TableName() string {
+	return "saas_drivers"
+}
+
+// BeforeCreate sets UUID before creating
+func (d *Driver) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// Shipment represents the delivery of an order by a driver
+type Shipment struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	OrderID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"order_id"`
+	DriverID uuid.UUID `gorm:"type:uuid;not null" json:"driver_id"`
+
+	Status          string `gorm:"type:text;not null;default:'assigned'" json:"status"`
+	DeliveryAddress string `gorm:"type:text;not null" json:"delivery_address"`
+	Notes           string `gorm:"type:text" json:"notes,omitempty"`
+
+	AssignedAt  time.Time  `gorm:"autoCreateTime" json:"assigned_at"`
+	PickedUpAt  *time.Time `json:"picked_up_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Order  *Order  `gorm:"foreignKey:OrderID" json:"order,omitempty"`
+	Driver *Driver `gorm:"foreignKey:DriverID" json:"driver,omitempty"`
+}
+
+// TableName specifies the table name
+func (Shipment) TableName() string {
+	return "saas_shipments"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *Shipment) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// Shipment status constants
+const (
+	ShipmentStatusAssigned  = "assigned"
+	ShipmentStatusPickedUp  = "picked_up"
+	ShipmentStatusDelivered = "delivered"
+	ShipmentStatusCancelled = "cancelled"
+)
+
+// CreateDriverRequest represents driver registration request
+type CreateDriverRequest struct {
+	ClientID string `json:"client_id" validate:"required"`
+	Name     string `json:"name" validate:"required"`
+	Phone    string `json:"phone" validate:"required"`
+	BranchID string `json:"branch_id,omitempty"`
+}
+
+// UpdateDriverRequest represents driver update request
+type UpdateDriverRequest struct {
+	Name     *string `json:"name,omitempty"`
+	Phone    *string `json:"phone,omitempty"`
+	BranchID *string `json:"branch_id,omitempty"` // Empty string clears the branch
+	IsActive *bool   `json:"is_active,omitempty"`
+}
+
+// AssignDriverRequest represents a request to assign an order to a driver
+type AssignDriverRequest struct {
+	DriverID        string `json:"driver_id" validate:"required"`
+	DeliveryAddress string `json:"delivery_address" validate:"required"`
+	Notes           string `json:"notes,omitempty"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Knowledge base document statuses
+const (
+	KBDocumentProcessing = "processing"
+	KBDocumentReady      = "ready"
+	KBDocumentFailed     = "failed"
+)
+
+// KBDocument is a document uploaded to a client's knowledge base. Its text lives in the vector DB
+// as chunks, so the bot can answer from documents too long for the system prompt
+type KBDocument struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID   uuid.UUID `gorm:"type:uuid;not null;index" json:"client_id"`
+	Name       string    `gorm:"type:varchar(255);not null" json:"name"`
+	Format     string    `gorm:"type:varchar(10);not null" json:"format"` // pdf, docx, txt
+	SizeBytes  int64     `gorm:"not null" json:"size_bytes"`
+	Characters int       `gorm:"not null" json:"characters"`
+	ChunkCount int       `gorm:"not null" json:"chunk_count"`
+	Status     string    `gorm:"type:varchar(20);not null" json:"status"` // processing, ready, failed
+	Error      string    `gorm:"type:text;not null" json:"error,omitempty"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (KBDocument) TableName() string {
+	return "saas_kb_documents"
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// KB duplicate detection methods
+const (
+	KBDuplicateExact     = "exact"     // Same text once normalized
+	KBDuplicateFuzzy     = "fuzzy"     // Word overlap or edit distance
+	KBDuplicateEmbedding = "embedding" // Vector similarity
+)
+
+// KB duplicate statuses
+const (
+	KBDuplicateOpen      = "open"
+	KBDuplicateMerged    = "merged"    // The later entry was deleted
+	KBDuplicateDismissed = "dismissed" // Not a duplicate after all
+)
+
+// KBDuplicate flags a knowledge base entry as a near-duplicate of an earlier one
+type KBDuplicate struct {
+	ID               uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID         uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	Type             string     `gorm:"type:text;not null" json:"type"`
+	EntryID          uuid.UUID  `gorm:"type:uuid;not null" json:"entry_id"` // The entry written last
+	EntryTitle       string     `gorm:"type:text;not null" json:"entry_title"`
+	DuplicateOfID    uuid.UUID  `gorm:"type:uuid;not null" json:"duplicate_of_id"`
+	DuplicateOfTitle string     `gorm:"type:text;not null" json:"duplicate_of_title"`
+	Score            float64    `gorm:"not null" json:"score"`            // Similarity, 0-1
+	Method           string     `gorm:"type:text;not null" json:"method"` // exact, fuzzy, embedding
+	Status           string     `gorm:"type:text;not null" json:"status"` // open, merged, dismissed
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (KBDuplicate) TableName() string {
+	return "saas_kb_duplicates"
+}
+
+// BeforeCreate sets UUID before creating
+func (d *KBDuplicate) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return nil
+}
+
+// KBDuplicateMatch is an existing entry that a written entry duplicates
+type KBDuplicateMatch struct {
+	ID     string  `json:"id,omitempty"` // Empty for entries of a dry run import not created yet
+	Title  string  `json:"title"`
+	Score  float64 `json:"score"`
+	Method string  `json:"method"`
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// KBGap is a customer question the bot could not answer or whose answer was rated low
+type KBGap struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID       uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone  string     `gorm:"type:text;not null" json:"customer_phone"`
+	Question       string     `gorm:"type:text;not null" json:"question"`
+	AIResponse     string     `gorm:"type:text" json:"ai_response,omitempty"`
+	Reason         string     `gorm:"type:text;not null" json:"reason"` // unanswered, low_rating
+	ConversationID *uuid.UUID `gorm:"type:uuid" json:"conversation_id,omitempty"`
+	SuggestionID   *uuid.UUID `gorm:"type:uuid" json:"suggestion_id,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (KBGap) TableName() string {
+	return "saas_kb_gaps"
+}
+
+// BeforeCreate sets UUID before creating
+func (g *KBGap) BeforeCreate(tx *gorm.DB) error {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return nil
+}
+
+// KBSuggestion is an FAQ entry drafted from clustered KB gaps, waiting for admin approval
+type KBSuggestion struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	Question        string         `gorm:"type:text;not null" json:"question"`
+	Answer          string         `gorm:"type:text;not null" json:"answer"`
+	SampleQuestions datatypes.JSON `gorm:"type:jsonb;not null" json:"sample_questions"`
+	Occurrences     int            `gorm:"default:1" json:"occurrences"`
+	Status          string         `gorm:"type:text;not null;default:'pending'" json:"status"` // pending, accepted, rejected
+	KBEntryID       *uuid.UUID     `gorm:"type:uuid" json:"kb_entry_id,omitempty"`
+	ReviewedAt      *time.Time     `json:"reviewed_at,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (KBSuggestion) TableName() string {
+	return "saas_kb_suggestions"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *KBSuggestion) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// KB gap reasons and suggestion statuses
+const (
+	KBGapReasonUnanswered = "unanswered"
+	KBGapReasonLowRating  = "low_rating"
+
+	KBSuggestionStatusPending  = "pending"
+	KBSuggestionStatusAccepted = "accepted"
+	KBSuggestionStatusRejected = "rejected"
+)
+
+// AcceptKBSuggestionRequest optionally edits the suggestion before it is written to the KB
+type AcceptKBSuggestionRequest struct {
+	Question string   `json:"question"`
+	Answer   string   `json:"answer"`
+	Tags     []string `json:"tags"`
+}
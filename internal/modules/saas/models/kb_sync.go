@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Knowledge base sync operations
+const (
+	KBSyncUpsert = "upsert"
+	KBSyncDelete = "delete"
+)
+
+// KBSyncOutboxItem is a knowledge base change not yet applied to the vector DB.
+// Rows are written by a database trigger on saas_knowledge_base, one per entry and type.
+type KBSyncOutboxItem struct {
+	ID            int64     `gorm:"primaryKey" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	EntryID       uuid.UUID `gorm:"type:uuid;not null" json:"entry_id"`
+	EntryType     string    `gorm:"type:text;not null" json:"entry_type"`
+	Operation     string    `gorm:"type:varchar(10);not null" json:"operation"` // upsert, delete
+	Attempts      int       `gorm:"not null" json:"attempts"`
+	LastError     string    `gorm:"type:text;not null" json:"last_error,omitempty"`
+	Version       int64     `gorm:"not null" json:"-"`
+	EnqueuedAt    time.Time `json:"enqueued_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// TableName specifies the table name
+func (KBSyncOutboxItem) TableName() string {
+	return "saas_kb_sync_outbox"
+}
+
+// KBSyncResult summarizes a sync run
+type KBSyncResult struct {
+	Enqueued int   `json:"enqueued,omitempty"` // Entries queued by a full resync
+	Indexed  int   `json:"indexed"`            // Entries written to the vector DB
+	Removed  int   `json:"removed"`            // Deleted, retyped or deactivated entries removed from it
+	Failed   int   `json:"failed"`             // Changes kept for a retry
+	Pending  int64 `json:"pending"`            // Changes still queued after the run
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// LanguageSettings controls whether the bot answers customers in the language they write in
+type LanguageSettings struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID         uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled          bool           `gorm:"default:false" json:"enabled"`                   // Reply in the detected language
+	DefaultLanguage  string         `gorm:"type:text;default:'id'" json:"default_language"` // Language of the knowledge base
+	AllowedLanguages datatypes.JSON `gorm:"type:jsonb" json:"allowed_languages"`            // Detected languages the bot may switch to
+	PostProcess      bool           `gorm:"default:false" json:"post_process"`              // Translate replies the LLM wrote in the wrong language
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (LanguageSettings) TableName() string {
+	return "saas_language_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *LanguageSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// UpdateLanguageSettingsRequest is the body for saving language settings
+type UpdateLanguageSettingsRequest struct {
+	Enabled          bool     `json:"enabled"`
+	DefaultLanguage  string   `json:"default_language"`
+	AllowedLanguages []string `json:"allowed_languages"` // e.g. ["id", "en", "jv"]
+	PostProcess      bool     `json:"post_process"`
+}
+
+// LanguageCount is the number of conversations in a detected language
+type LanguageCount struct {
+	Language      string `json:"language"`
+	Conversations int64  `json:"conversations"`
+	Customers     int64  `json:"customers"`
+}
+
+// LanguageReport breaks a client's conversations down by detected language
+type LanguageReport struct {
+	Period    string          `json:"period"`
+	Start     time.Time       `json:"start"`
+	End       time.Time       `json:"end"`
+	Languages []LanguageCount `json:"languages"` // Language is empty when it could not be detected
+}
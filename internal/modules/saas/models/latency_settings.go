@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Fallbacks when the LLM misses the hard timeout
+const (
+	LatencyFallbackKB       = "kb"       // Best matching FAQ answer, handover when none matches
+	LatencyFallbackHandover = "handover" // Tell the customer an agent will reply
+)
+
+// LatencySettings is a client's reply latency budget: an interim message when the LLM is slow
+// and a degraded answer when it misses the hard timeout
+type LatencySettings struct {
+	ID                  uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID            uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled             bool      `json:"enabled"`               // No GORM default: false and 0 must be stored as given
+	InterimAfterSeconds int       `json:"interim_after_seconds"` // 0 = no interim message
+	InterimMessage      string    `gorm:"type:text" json:"interim_message"`
+	HardTimeoutSeconds  int       `json:"hard_timeout_seconds"`
+	Fallback            string    `gorm:"type:text" json:"fallback"` // kb or handover
+	HandoverMessage     string    `gorm:"type:text" json:"handover_message"`
+	HandoverTag         string    `gorm:"type:text" json:"handover_tag,omitempty"` // Conversation tag applied on handover
+	CreatedAt           time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt           time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (LatencySettings) TableName() string {
+	return "saas_latency_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *LatencySettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// UpdateLatencySettingsRequest is the body for saving latency settings
+type UpdateLatencySettingsRequest struct {
+	Enabled             bool   `json:"enabled"`
+	InterimAfterSeconds int    `json:"interim_after_seconds"`
+	InterimMessage      string `json:"interim_message"`
+	HardTimeoutSeconds  int    `json:"hard_timeout_seconds"`
+	Fallback            string `json:"fallback"`
+	HandoverMessage     string `json:"handover_message"`
+	HandoverTag         string `json:"handover_tag"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// LLMBenchmark is the result of one provider/model in a benchmark run
+type LLMBenchmark struct {
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	RunID            uuid.UUID      `gorm:"type:uuid;not null" json:"run_id"`
+	Provider         string         `gorm:"type:text;not null" json:"provider"`
+	Model            string         `gorm:"type:text;not null" json:"model"`
+	SuccessRate      float64        `gorm:"not null" json:"success_rate"`
+	AvgLatencyMs     int64          `gorm:"not null" json:"avg_latency_ms"`
+	P95LatencyMs     int64          `gorm:"column:p95_latency_ms;not null" json:"p95_latency_ms"`
+	Quality          float64        `gorm:"not null" json:"quality"`
+	EstimatedCostUSD float64        `gorm:"column:estimated_cost_usd;not null" json:"estimated_cost_usd"`
+	CostPer1KUSD     float64        `gorm:"column:cost_per_1k_usd;not null" json:"cost_per_1k_usd"`
+	PriceKnown       bool           `gorm:"not null" json:"price_known"`
+	HealthScore      float64        `gorm:"not null" json:"health_score"`
+	Recommended      bool           `gorm:"not null" json:"recommended"`
+	Cases            datatypes.JSON `gorm:"type:jsonb" json:"cases"` // []llm.BenchmarkCaseResult
+	CreatedAt        time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (LLMBenchmark) TableName() string {
+	return "saas_llm_benchmarks"
+}
+
+// BeforeCreate sets UUID before creating
+func (b *LLMBenchmark) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
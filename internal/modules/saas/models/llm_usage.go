@@ -0,0 +1,102 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// What a metered LLM call was made for
+const (
+	UsageFeatureChat           = "chat"            // AI replies to customers
+	UsageFeatureTranslation    = "translation"     // Replies translated to the customer's language
+	UsageFeatureReceipt        = "receipt"         // Receipts parsed after OCR
+	UsageFeatureRecommendation = "recommendation"  // Product recommendations ranked by the LLM
+	UsageFeatureProductMention = "product_mention" // Product mentions detected in customer messages
+	UsageFeatureKBSuggestion   = "kb_suggestion"   // FAQ drafts for unanswered questions
+	UsageFeatureWorkflow       = "workflow"        // call_llm workflow actions
+)
+
+// LLMUsage records the estimated tokens of one LLM call made for a client
+type LLMUsage struct {
+	ID               uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID         uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	Feature          string    `gorm:"type:text;not null" json:"feature"`
+	Provider         string    `gorm:"type:text;not null" json:"provider"`
+	Operation        string    `gorm:"type:text;not null" json:"operation"`
+	PromptTokens     int       `gorm:"not null;default:0" json:"prompt_tokens"`
+	CompletionTokens int       `gorm:"not null;default:0" json:"completion_tokens"`
+	TotalTokens      int       `gorm:"not null;default:0" json:"total_tokens"`
+	CreditTokens     int       `gorm:"not null;default:0" json:"credit_tokens"` // Drawn from AI credits
+	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (LLMUsage) TableName() string {
+	return "saas_llm_usage"
+}
+
+// BeforeCreate sets UUID before creating
+func (u *LLMUsage) BeforeCreate(tx *gorm.DB) error {
+	if u.ID == uuid.Nil {
+		u.ID = uuid.New()
+	}
+	return nil
+}
+
+// AICredit is a client's balance of prepaid AI tokens, drawn once the plan's monthly token quota is used up
+type AICredit struct {
+	ClientID  uuid.UUID `gorm:"type:uuid;primary_key" json:"client_id"`
+	Balance   int64     `gorm:"not null;default:0" json:"balance"` // Tokens
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (AICredit) TableName() string {
+	return "saas_ai_credits"
+}
+
+// LLMUsageTotals sums the metered LLM calls of a period
+type LLMUsageTotals struct {
+	Calls            int64 `json:"calls"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+	CreditTokens     int64 `json:"credit_tokens"`
+}
+
+// FeatureUsage is the LLM usage of one feature
+type FeatureUsage struct {
+	Feature string `json:"feature"`
+	Calls   int64  `json:"calls"`
+	Tokens  int64  `json:"tokens"`
+}
+
+// UsageDay is the LLM usage of one day
+type UsageDay struct {
+	Date   string `json:"date"` // YYYY-MM-DD
+	Calls  int64  `json:"calls"`
+	Tokens int64  `json:"tokens"`
+}
+
+// UsageReport is a client's AI token usage in the current billing period against its quota and credits
+type UsageReport struct {
+	Plan          string    `json:"plan"`
+	PeriodStart   time.Time `json:"period_start"`
+	PeriodEnd     time.Time `json:"period_end"`
+	TokenQuota    int64     `json:"token_quota"`    // Monthly tokens of the plan, 0 = unlimited
+	QuotaEnforced bool      `json:"quota_enforced"` // Calls stop at the quota (plus credits); otherwise usage over it is billed as overage
+	QuotaLeft     int64     `json:"quota_left"`
+	CreditBalance int64     `json:"credit_balance"`
+	Exhausted     bool      `json:"exhausted"` // AI features are paused until next period or a credit top-up
+	LLMUsageTotals
+	ByFeature []FeatureUsage `json:"by_feature"`
+	Daily     []UsageDay     `json:"daily"`
+}
+
+// GrantAICreditsRequest adds prepaid AI tokens to a client's credit balance (negative to correct it)
+type GrantAICreditsRequest struct {
+	Tokens int64  `json:"tokens"`
+	Note   string `json:"note"`
+}
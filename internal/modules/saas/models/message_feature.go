@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Inbound message types a client can turn off
+const (
+	MessageFeatureText     = "text"
+	MessageFeatureImage    = "image"
+	MessageFeatureVoice    = "voice"
+	MessageFeatureLocation = "location"
+	MessageFeatureGroup    = "group"
+)
+
+// MessageFeatureSettings controls which inbound message types the bot handles for a client.
+// A disabled type is answered with its disabled reply, or ignored when the reply is empty
+type MessageFeatureSettings struct {
+	ID                    uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID              uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	TextEnabled           bool      `gorm:"not null" json:"text_enabled"`
+	ImageEnabled          bool      `gorm:"not null" json:"image_enabled"`
+	VoiceEnabled          bool      `gorm:"not null" json:"voice_enabled"`
+	LocationEnabled       bool      `gorm:"not null" json:"location_enabled"`
+	GroupEnabled          bool      `gorm:"not null" json:"group_enabled"`
+	TextDisabledReply     string    `gorm:"type:text;not null" json:"text_disabled_reply"`
+	ImageDisabledReply    string    `gorm:"type:text;not null" json:"image_disabled_reply"`
+	VoiceDisabledReply    string    `gorm:"type:text;not null" json:"voice_disabled_reply"`
+	LocationDisabledReply string    `gorm:"type:text;not null" json:"location_disabled_reply"`
+	CreatedAt             time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (MessageFeatureSettings) TableName() string {
+	return "saas_message_feature_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *MessageFeatureSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultMessageFeatureSettings handles every message type, used until a client saves its own
+func DefaultMessageFeatureSettings(clientID uuid.UUID) *MessageFeatureSettings {
+	return &MessageFeatureSettings{
+		ClientID:              clientID,
+		TextEnabled:           true,
+		ImageEnabled:          true,
+		VoiceEnabled:          true,
+		LocationEnabled:       true,
+		GroupEnabled:          true,
+		TextDisabledReply:     "🙏 Maaf, balasan otomatis sedang tidak aktif. Tim kami akan membalas pesan Anda secepatnya.",
+		ImageDisabledReply:    "🙏 Maaf, kami tidak memproses gambar. Silakan kirim pesan dalam bentuk teks ya.",
+		VoiceDisabledReply:    "🙏 Maaf, kami tidak memproses pesan suara. Silakan kirim pesan dalam bentuk teks ya.",
+		LocationDisabledReply: "🙏 Maaf, kami tidak memproses lokasi. Silakan ketik alamat atau kota Anda ya.",
+	}
+}
+
+// Enabled reports whether a message type is handled
+func (s *MessageFeatureSettings) Enabled(feature string) bool {
+	switch feature {
+	case MessageFeatureText:
+		return s.TextEnabled
+	case MessageFeatureImage:
+		return s.ImageEnabled
+	case MessageFeatureVoice:
+		return s.VoiceEnabled
+	case MessageFeatureLocation:
+		return s.LocationEnabled
+	case MessageFeatureGroup:
+		return s.GroupEnabled
+	}
+	return true
+}
+
+// DisabledReply returns the reply to a message of a disabled type; group messages are never answered
+func (s *MessageFeatureSettings) DisabledReply(feature string) string {
+	switch feature {
+	case MessageFeatureText:
+		return s.TextDisabledReply
+	case MessageFeatureImage:
+		return s.ImageDisabledReply
+	case MessageFeatureVoice:
+		return s.VoiceDisabledReply
+	case MessageFeatureLocation:
+		return s.LocationDisabledReply
+	}
+	return ""
+}
+
+// UpdateMessageFeatureSettingsRequest is the body for saving message type toggles
+type UpdateMessageFeatureSettingsRequest struct {
+	TextEnabled           bool   `json:"text_enabled"`
+	ImageEnabled          bool   `json:"image_enabled"`
+	VoiceEnabled          bool   `json:"voice_enabled"`
+	LocationEnabled       bool   `json:"location_enabled"`
+	GroupEnabled          bool   `json:"group_enabled"`
+	TextDisabledReply     string `json:"text_disabled_reply"` // Empty = ignore silently
+	ImageDisabledReply    string `json:"image_disabled_reply"`
+	VoiceDisabledReply    string `json:"voice_disabled_reply"`
+	LocationDisabledReply string `json:"location_disabled_reply"`
+}
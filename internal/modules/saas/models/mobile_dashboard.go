@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Mobile dashboard actions on a pending order
+const (
+	MobileActionReview         = "review"
+	MobileActionConfirmPayment = "confirm_payment"
+	MobileActionShip           = "ship"
+)
+
+// MobileDashboard is the compact payload served at /m/dashboard
+type MobileDashboard struct {
+	KPIs    MobileKPIs    `json:"kpis"`
+	Pending []MobileOrder `json:"pending"`
+	Alerts  []MobileAlert `json:"alerts"`
+}
+
+// MobileKPIs holds today's numbers and the counts of orders waiting on the admin
+type MobileKPIs struct {
+	Orders         int64   `json:"orders"`          // Orders created today
+	Paid           int64   `json:"paid"`            // Orders paid today
+	Revenue        float64 `json:"revenue"`         // Paid today
+	PendingPayment int64   `json:"pending_payment"` // Awaiting payment
+	NeedsReview    int64   `json:"needs_review"`    // Flagged by risk checks
+	ToShip         int64   `json:"to_ship"`         // Paid, not yet shipped
+}
+
+// MobileOrder is a pending order trimmed to what a phone list row shows
+type MobileOrder struct {
+	ID        uuid.UUID `json:"id"`
+	Number    string    `json:"number"`
+	Customer  string    `json:"customer"`
+	Total     float64   `json:"total"`
+	Action    string    `json:"action"` // review, confirm_payment, ship
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MobileAlert is a problem the admin should look at, e.g. a disconnected WhatsApp session
+type MobileAlert struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
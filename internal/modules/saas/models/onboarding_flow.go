@@ -0,0 +1,87 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// OnboardingFlow configures what a tenant's first-time customers see before chatting with the bot
+type OnboardingFlow struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled         bool           `gorm:"default:false" json:"enabled"`
+	WelcomeMessage  string         `gorm:"type:text" json:"welcome_message"` // Supports {business_name}
+	QuickMenu       datatypes.JSON `gorm:"type:jsonb" json:"quick_menu"`     // []QuickMenuItem shown after onboarding
+	Languages       datatypes.JSON `gorm:"type:jsonb" json:"languages"`      // Language codes offered; asked only when more than one
+	ConsentRequired bool           `gorm:"default:false" json:"consent_required"`
+	ConsentText     string         `gorm:"type:text" json:"consent_text"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (OnboardingFlow) TableName() string {
+	return "saas_onboarding_flows"
+}
+
+// BeforeCreate sets UUID before creating
+func (f *OnboardingFlow) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
+// QuickMenuItem is a numbered shortcut; replying its number sends Message to the bot
+type QuickMenuItem struct {
+	Label   string `json:"label"`
+	Message string `json:"message"`
+}
+
+// UpdateOnboardingFlowRequest is the body for saving an onboarding flow
+type UpdateOnboardingFlowRequest struct {
+	Enabled         bool            `json:"enabled"`
+	WelcomeMessage  string          `json:"welcome_message"`
+	QuickMenu       []QuickMenuItem `json:"quick_menu"`
+	Languages       []string        `json:"languages"`
+	ConsentRequired bool            `json:"consent_required"`
+	ConsentText     string          `json:"consent_text"`
+}
+
+// CustomerOnboarding tracks a customer's progress through the onboarding flow so it only runs once
+type CustomerOnboarding struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID  `gorm:"type:uuid;not null;index" json:"client_id"`
+	CustomerPhone string     `gorm:"type:text;not null" json:"customer_phone"`
+	Step          string     `gorm:"type:text;not null" json:"step"`
+	Language      string     `gorm:"type:text" json:"language,omitempty"`
+	ConsentGiven  bool       `gorm:"default:false" json:"consent_given"`
+	ConsentAt     *time.Time `json:"consent_at,omitempty"`
+	MenuPending   bool       `gorm:"default:false" json:"menu_pending"` // Next reply may pick a quick menu number
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CustomerOnboarding) TableName() string {
+	return "saas_customer_onboarding"
+}
+
+// BeforeCreate sets UUID before creating
+func (o *CustomerOnboarding) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+// Customer onboarding steps
+const (
+	OnboardingStepLanguage  = "language"
+	OnboardingStepConsent   = "consent"
+	OnboardingStepCompleted = "completed"
+)
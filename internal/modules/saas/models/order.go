@@ -37,10 +37,59 @@ type Order struct {
 	PaymentGateway   string     `gorm:"type:text" json:"payment_gateway"`
 	PaymentLink      string     `gorm:"type:text" json:"payment_link"`
 	PaymentReference string     `gorm:"type:text" json:"payment_reference"`
+	PaymentRoute     string     `gorm:"type:text" json:"payment_route,omitempty"` // Routing rule that picked the gateway
 	PaidAt           *time.Time `json:"paid_at"`
+	PaymentExpiresAt *time.Time `json:"payment_expires_at,omitempty"` // Payment link expiry, when the gateway reports one
+
+	// Refunds (see OrderRefund)
+	RefundedAmount float64    `gorm:"type:decimal(12,2);default:0" json:"refunded_amount"`
+	RefundedAt     *time.Time `json:"refunded_at,omitempty"` // Last refund
+
+	// Group order paid in portions by several payers (see OrderSplitPayment)
+	SplitPayment   bool       `gorm:"default:false" json:"split_payment"`
+	SplitExpiresAt *time.Time `json:"split_expires_at,omitempty"` // Order is cancelled unless every portion is paid by then
+
+	// Cash on delivery
+	CODConfirmedAt *time.Time `json:"cod_confirmed_at,omitempty"`                 // Customer confirmed paying on delivery
+	CODCollectedBy string     `gorm:"type:text" json:"cod_collected_by,omitempty"` // Driver or admin who received the cash
 
 	// Fulfillment
 	FulfillmentStatus string `gorm:"type:text;default:'pending'" json:"fulfillment_status"`
+	DeliveryNotes     string `gorm:"type:text" json:"delivery_notes,omitempty"` // From the customer's preferences at checkout
+
+	// Courier delivery (PUT /orders/:id/fulfillment); in-house drivers are tracked by Shipment
+	ShippingCourier        string     `gorm:"type:text" json:"shipping_courier,omitempty"`         // e.g. JNE, J&T, SiCepat
+	ShippingTrackingNumber string     `gorm:"type:text" json:"shipping_tracking_number,omitempty"` // Courier's waybill (resi) number
+	PackedAt               *time.Time `json:"packed_at,omitempty"`
+	ShippedAt              *time.Time `json:"shipped_at,omitempty"`
+	DeliveredAt            *time.Time `json:"delivered_at,omitempty"`
+
+	// Risk
+	RiskScore    int            `gorm:"default:0" json:"risk_score"`
+	RiskFlags    datatypes.JSON `gorm:"type:jsonb" json:"risk_flags,omitempty"`
+	ReviewStatus string         `gorm:"type:text;default:'none'" json:"review_status"`
+	ReviewedAt   *time.Time     `json:"reviewed_at,omitempty"`
+
+	// Branch
+	BranchID *uuid.UUID `gorm:"type:uuid;index" json:"branch_id,omitempty"` // Store/branch fulfilling the order
+
+	// Public tracking link (/t/:token)
+	TrackingToken     *string    `gorm:"type:text;uniqueIndex" json:"-"`
+	TrackingExpiresAt *time.Time `json:"tracking_expires_at,omitempty"`
+
+	// Sandbox
+	IsTest bool `gorm:"default:false" json:"is_test"` // Created in sandbox mode (simulated payment)
+
+	// Optimistic lock, bumped by a database trigger on every update (order board moves check it)
+	Version int `gorm:"not null" json:"version"`
+
+	// Tenant-defined fields (see CustomFieldDefinition)
+	CustomFields datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"custom_fields,omitempty"`
+
+	// Idempotent creation: retries with the same Idempotency-Key get this order and its payment result back
+	IdempotencyKey         *string        `gorm:"type:text" json:"-"`
+	IdempotencyFingerprint string         `gorm:"type:text" json:"-"` // Hash of the creating request
+	IdempotencyResult      datatypes.JSON `gorm:"type:jsonb" json:"-"`
 
 	// Timestamps
 	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
@@ -60,19 +109,69 @@ func (o *Order) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// OrderTracking is the public view of an order shown on its tracking page
+type OrderTracking struct {
+	BusinessName      string      `json:"business_name"`
+	OrderNumber       string      `json:"order_number"`
+	CustomerName      string      `json:"customer_name,omitempty"`
+	Items             []OrderItem `json:"items"`
+	TotalAmount       float64     `json:"total_amount"`
+	PaymentStatus     string      `json:"payment_status"`
+	PaymentLink       string      `json:"payment_link,omitempty"` // Only while payment is pending
+	FulfillmentStatus string      `json:"fulfillment_status"`
+	CreatedAt         time.Time   `json:"created_at"`
+	PaidAt            *time.Time  `json:"paid_at,omitempty"`
+
+	// Courier and waybill number once shipped by courier
+	Courier        string `json:"courier,omitempty"`
+	TrackingNumber string `json:"tracking_number,omitempty"`
+
+	Shipment *ShipmentTracking `json:"shipment,omitempty"`
+}
+
+// ShipmentTracking is the public view of an order's delivery
+type ShipmentTracking struct {
+	Status      string     `json:"status"`
+	DriverName  string     `json:"driver_name,omitempty"`
+	AssignedAt  time.Time  `json:"assigned_at"`
+	PickedUpAt  *time.Time `json:"picked_up_at,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+}
+
 // Order status constants
 const (
 	// Payment Status
-	PaymentStatusPending   = "pending"
-	PaymentStatusPaid      = "paid"
-	PaymentStatusFailed    = "failed"
-	PaymentStatusCancelled = "cancelled"
-	PaymentStatusRefunded  = "refunded"
+	PaymentStatusPending           = "pending"
+	PaymentStatusPendingCOD        = "pending_cod" // COD confirmed by the customer, cash collected at delivery
+	PaymentStatusPaid              = "paid"
+	PaymentStatusFailed            = "failed"
+	PaymentStatusCancelled         = "cancelled"
+	PaymentStatusRefunded          = "refunded"
+	PaymentStatusPartiallyRefunded = "partially_refunded"
+
+	// Payment Method (others are recorded as reported by the gateway)
+	PaymentMethodCOD    = "cod"
+	PaymentMethodSplit  = "split"  // Paid in portions by a group of payers
+	PaymentMethodWallet = "wallet" // Paid from the customer's prepaid balance
 
 	// Fulfillment Status
 	FulfillmentStatusPending    = "pending"
 	FulfillmentStatusProcessing = "processing"
+	FulfillmentStatusPacked     = "packed"
 	FulfillmentStatusShipped    = "shipped"
 	FulfillmentStatusDelivered  = "delivered"
 	FulfillmentStatusCancelled  = "cancelled"
+
+	// Review Status
+	ReviewStatusNone        = "none"
+	ReviewStatusNeedsReview = "needs_review"
+	ReviewStatusApproved    = "approved"
+	ReviewStatusRejected    = "rejected"
 )
+
+// FulfillmentUpdateRequest moves an order through packing and courier delivery
+type FulfillmentUpdateRequest struct {
+	Status         string `json:"status" example:"shipped"`                   // packed, shipped or delivered
+	Courier        string `json:"courier,omitempty" example:"JNE"`            // Required to ship
+	TrackingNumber string `json:"tracking_number,omitempty" example:"JNE123"` // Required to ship
+}
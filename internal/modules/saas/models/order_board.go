@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrderBoardStages are the board columns, left to right, in fulfillment order
+var OrderBoardStages = []string{
+	FulfillmentStatusPending,
+	FulfillmentStatusProcessing,
+	FulfillmentStatusPacked,
+	FulfillmentStatusShipped,
+	FulfillmentStatusDelivered,
+	FulfillmentStatusCancelled,
+}
+
+// OrderBoard is the kanban view of a client's orders served at /orders/board
+type OrderBoard struct {
+	Columns []OrderBoardColumn `json:"columns"`
+	Cursor  time.Time          `json:"cursor"` // Latest order update included; the stream sends changes after it
+}
+
+// OrderBoardColumn holds the orders in one fulfillment stage, oldest first
+type OrderBoardColumn struct {
+	Stage  string           `json:"stage"`
+	Count  int              `json:"count"`
+	Orders []OrderBoardCard `json:"orders"`
+}
+
+// OrderBoardCard is an order trimmed to what a board card shows
+type OrderBoardCard struct {
+	ID            uuid.UUID  `json:"id"`
+	Number        string     `json:"number"`
+	Customer      string     `json:"customer"`
+	Items         []string   `json:"items"` // "2x Nasi Goreng"
+	Total         float64    `json:"total"`
+	PaymentStatus string     `json:"payment_status"`
+	PaymentMethod string     `json:"payment_method,omitempty"`
+	Courier       string     `json:"courier,omitempty"`
+	Tracking      string     `json:"tracking_number,omitempty"` // Courier's waybill number once shipped
+	Stage         string     `json:"stage"`
+	Version       int        `json:"version"` // Send back when moving the card
+	BranchID      *uuid.UUID `json:"branch_id,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// OrderStageRequest moves an order to another board column
+type OrderStageRequest struct {
+	Stage   string `json:"stage" example:"processing"`
+	Version int    `json:"version" example:"3"` // Version of the card the move is based on
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OrderRefund is a full or partial refund of a paid order
+type OrderRefund struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	OrderID     uuid.UUID `gorm:"type:uuid;not null" json:"order_id"`
+	Amount      float64   `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Reason      string    `gorm:"type:text" json:"reason,omitempty"`
+	Gateway     string    `gorm:"type:text;not null" json:"gateway"`
+	Reference   string    `gorm:"type:text" json:"reference,omitempty"` // Refund key or ID at the gateway
+	Status      string    `gorm:"type:text;not null" json:"status"`     // refunded, pending (admin returns the money manually)
+	RequestedBy string    `gorm:"type:text" json:"requested_by,omitempty"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (OrderRefund) TableName() string {
+	return "saas_order_refunds"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *OrderRefund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
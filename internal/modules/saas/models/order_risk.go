@@ -0,0 +1,77 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// OrderRiskRule holds per-tenant fraud and anomaly detection settings
+type OrderRiskRule struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+
+	Enabled         bool `gorm:"default:true" json:"enabled"`
+	ReviewThreshold int  `gorm:"default:50" json:"review_threshold"`
+
+	// Abnormal order value
+	MaxOrderAmount  float64 `gorm:"type:decimal(12,2);default:0" json:"max_order_amount"`
+	ValueMultiplier float64 `gorm:"type:decimal(6,2);default:5" json:"value_multiplier"`
+	MinOrderHistory int     `gorm:"default:3" json:"min_order_history"`
+
+	// Order velocity
+	VelocityMaxOrders     int `gorm:"default:3" json:"velocity_max_orders"`
+	VelocityWindowMinutes int `gorm:"default:10" json:"velocity_window_minutes"`
+
+	// Payment proof
+	ProofAmountTolerance float64 `gorm:"type:decimal(12,2);default:0" json:"proof_amount_tolerance"`
+
+	// Blocked numbers
+	BlockedPhones pq.StringArray `gorm:"type:text[]" json:"blocked_phones"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (OrderRiskRule) TableName() string {
+	return "saas_order_risk_rules"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *OrderRiskRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultOrderRiskRule returns the rules used when a tenant has not configured any
+func DefaultOrderRiskRule(clientID uuid.UUID) *OrderRiskRule {
+	return &OrderRiskRule{
+		ClientID:              clientID,
+		Enabled:               true,
+		ReviewThreshold:       50,
+		ValueMultiplier:       5,
+		MinOrderHistory:       3,
+		VelocityMaxOrders:     3,
+		VelocityWindowMinutes: 10,
+	}
+}
+
+// RiskFlag describes a single rule that matched during risk scoring
+type RiskFlag struct {
+	Code   string `json:"code"` // blocked_phone, abnormal_value, high_velocity, proof_mismatch
+	Score  int    `json:"score"`
+	Detail string `json:"detail"`
+}
+
+// Risk flag codes
+const (
+	RiskFlagBlockedPhone  = "blocked_phone"
+	RiskFlagAbnormalValue = "abnormal_value"
+	RiskFlagHighVelocity  = "high_velocity"
+	RiskFlagProofMismatch = "proof_mismatch"
+)
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Split payment portion statuses
+const (
+	SplitPaymentPending   = "pending"
+	SplitPaymentPaid      = "paid"
+	SplitPaymentCancelled = "cancelled" // Denied or cancelled at the gateway, or the group order was cancelled
+	SplitPaymentExpired   = "expired"   // Not paid before the group order timed out
+	SplitPaymentRefunded  = "refunded"  // Paid, then returned because the group order failed or was refunded
+)
+
+// OrderSplitPayment is one payer's portion of a group order, with its own payment link
+type OrderSplitPayment struct {
+	ID              uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	OrderID         uuid.UUID  `gorm:"type:uuid;not null" json:"order_id"`
+	Reference       string     `gorm:"type:text;not null;unique" json:"reference"` // Gateway order ID of the portion
+	PayerPhone      string     `gorm:"type:text;not null" json:"payer_phone"`
+	PayerName       string     `gorm:"type:text" json:"payer_name,omitempty"`
+	Amount          float64    `gorm:"type:decimal(12,2);not null" json:"amount"`
+	Status          string     `gorm:"type:text;not null;default:'pending'" json:"status"`
+	PaymentGateway  string     `gorm:"type:text;not null" json:"payment_gateway"`
+	PaymentLink     string     `gorm:"type:text" json:"payment_link,omitempty"`
+	PaymentMethod   string     `gorm:"type:text" json:"payment_method,omitempty"`
+	TransactionID   string     `gorm:"type:text" json:"transaction_id,omitempty"`
+	PaidAt          *time.Time `json:"paid_at,omitempty"`
+	RefundedAmount  float64    `gorm:"type:decimal(12,2);not null;default:0" json:"refunded_amount"`
+	RefundReference string     `gorm:"type:text" json:"refund_reference,omitempty"` // Latest refund
+	RefundedAt      *time.Time `json:"refunded_at,omitempty"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (OrderSplitPayment) TableName() string {
+	return "saas_order_split_payments"
+}
+
+// BeforeCreate sets UUID before creating
+func (p *OrderSplitPayment) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// SplitOrderRequest splits a pending order between payers
+type SplitOrderRequest struct {
+	Payers         []SplitPayer `json:"payers"`          // 2-10 payers, the ordering customer included if they pay a part
+	TimeoutMinutes int          `json:"timeout_minutes"` // 10-1440, default 60; the order is cancelled unless every portion is paid in time
+}
+
+// SplitPayer is one payer of a split order
+type SplitPayer struct {
+	Phone  string  `json:"phone"`
+	Name   string  `json:"name,omitempty"`
+	Amount float64 `json:"amount,omitempty"` // 0 = an equal share of what the payers with an amount leave
+}
+
+// OrderSplitSummary is a group order with the payment state of each portion
+type OrderSplitSummary struct {
+	Order           *Order              `json:"order"`
+	Payments        []OrderSplitPayment `json:"payments"`
+	PaidCount       int                 `json:"paid_count"`
+	PaidAmount      float64             `json:"paid_amount"`
+	RemainingAmount float64             `json:"remaining_amount"`
+}
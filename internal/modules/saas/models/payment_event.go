@@ -0,0 +1,67 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Signature status of a payment webhook
+const (
+	PaymentSignatureValid     = "valid"
+	PaymentSignatureInvalid   = "invalid"
+	PaymentSignatureMissing   = "missing"
+	PaymentSignatureUnchecked = "unchecked" // No server key configured
+)
+
+// Processing result of a payment webhook
+const (
+	PaymentEventReceived  = "received"  // Stored, processing did not finish
+	PaymentEventProcessed = "processed" // Order or plan change updated
+	PaymentEventFailed    = "failed"    // Updating the order or plan change returned an error
+	PaymentEventIgnored   = "ignored"   // Nothing to do (pending or unknown status)
+	PaymentEventRejected  = "rejected"  // Unparseable body, missing fields, bad signature or payment not confirmed by the gateway
+)
+
+// PaymentEvent is a payment gateway webhook as received and how it was handled
+type PaymentEvent struct {
+	ID                uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Gateway           string     `gorm:"type:text;not null" json:"gateway"`
+	OrderID           string     `gorm:"type:text" json:"order_id,omitempty"`
+	TransactionID     string     `gorm:"type:text" json:"transaction_id,omitempty"`
+	TransactionStatus string     `gorm:"type:text" json:"transaction_status,omitempty"`
+	PaymentType       string     `gorm:"type:text" json:"payment_type,omitempty"`
+	Payload           string     `gorm:"type:text;not null" json:"payload"`
+	SignatureStatus   string     `gorm:"type:text;not null" json:"signature_status"`
+	Result            string     `gorm:"type:text;not null" json:"result"`
+	Error             string     `gorm:"type:text" json:"error,omitempty"`
+	ReplayCount       int        `gorm:"not null;default:0" json:"replay_count"`
+	LastReplayedAt    *time.Time `json:"last_replayed_at,omitempty"`
+	ProcessedAt       *time.Time `json:"processed_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (PaymentEvent) TableName() string {
+	return "saas_payment_events"
+}
+
+// BeforeCreate sets UUID before creating
+func (e *PaymentEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// PaymentEventFilter narrows the payment event listing; empty fields match everything
+type PaymentEventFilter struct {
+	Gateway         string
+	OrderID         string
+	Result          string
+	SignatureStatus string
+	From            *time.Time
+	To              *time.Time
+	Limit           int
+}
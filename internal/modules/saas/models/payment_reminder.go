@@ -0,0 +1,119 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PaymentReminderSettings holds a client's reminder ladder for orders waiting for payment
+type PaymentReminderSettings struct {
+	ID                   uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID             uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled              bool           `gorm:"not null" json:"enabled"`
+	Steps                datatypes.JSON `gorm:"type:jsonb" json:"steps"`                // []PaymentReminderStep
+	DefaultExpiryMinutes int            `gorm:"not null" json:"default_expiry_minutes"` // Payment window of orders whose gateway reports no expiry
+	CreatedAt            time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt            time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (PaymentReminderSettings) TableName() string {
+	return "saas_payment_reminder_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *PaymentReminderSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// PaymentReminderStep is one rung of the ladder: a message sent a while before the payment expires.
+// Templates may use {name}, {order_number}, {total}, {payment_link} and {time_left}
+type PaymentReminderStep struct {
+	MinutesBefore int    `json:"minutes_before"`
+	Template      string `json:"template"`
+}
+
+// UpdatePaymentReminderSettingsRequest is the body for saving a reminder ladder
+type UpdatePaymentReminderSettingsRequest struct {
+	Enabled              bool                  `json:"enabled"`
+	Steps                []PaymentReminderStep `json:"steps"`
+	DefaultExpiryMinutes int                   `json:"default_expiry_minutes"`
+}
+
+// PaymentReminder is a reminder sent for an order
+type PaymentReminder struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	OrderID       uuid.UUID  `gorm:"type:uuid;not null" json:"order_id"`
+	MinutesBefore int        `gorm:"not null" json:"minutes_before"`
+	Status        string     `gorm:"type:text;not null" json:"status"` // sent, failed
+	Error         string     `gorm:"type:text" json:"error,omitempty"`
+	SentAt        time.Time  `gorm:"not null" json:"sent_at"`
+	ConvertedAt   *time.Time `json:"converted_at,omitempty"` // Order paid, with this as the last reminder before payment
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (PaymentReminder) TableName() string {
+	return "saas_payment_reminders"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *PaymentReminder) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// Payment reminder statuses. Sent and failed are stored; the others only appear in an order's schedule
+const (
+	PaymentReminderSent      = "sent"
+	PaymentReminderFailed    = "failed"
+	PaymentReminderScheduled = "scheduled"
+	PaymentReminderCancelled = "cancelled" // Order paid or cancelled before the reminder was due
+	PaymentReminderSkipped   = "skipped"   // Due too soon after the order was placed, or passed over by a later step
+)
+
+// PaymentReminderSchedule is the reminder ladder as applied to one order
+type PaymentReminderSchedule struct {
+	OrderID       uuid.UUID                     `json:"order_id"`
+	Enabled       bool                          `json:"enabled"`
+	PaymentStatus string                        `json:"payment_status"`
+	ExpiresAt     time.Time                     `json:"expires_at"`
+	Reminders     []PaymentReminderScheduleItem `json:"reminders"`
+}
+
+// PaymentReminderScheduleItem is one step of an order's reminder schedule
+type PaymentReminderScheduleItem struct {
+	MinutesBefore int        `json:"minutes_before"`
+	DueAt         time.Time  `json:"due_at"`
+	Status        string     `json:"status"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	Converted     bool       `json:"converted"`
+}
+
+// PaymentReminderStats measures how well reminders turn pending orders into payments
+type PaymentReminderStats struct {
+	Period          string                     `json:"period"`
+	OrdersReminded  int64                      `json:"orders_reminded"`
+	OrdersConverted int64                      `json:"orders_converted"` // Paid after a reminder
+	ConversionRate  float64                    `json:"conversion_rate"`  // Percentage
+	Steps           []PaymentReminderStepStats `json:"steps"`
+}
+
+// PaymentReminderStepStats is the effectiveness of one ladder step; a payment counts for the last reminder before it
+type PaymentReminderStepStats struct {
+	MinutesBefore   int     `json:"minutes_before"`
+	Sent            int64   `json:"sent"`
+	Failed          int64   `json:"failed"`
+	Converted       int64   `json:"converted"`
+	ConversionRate  float64 `json:"conversion_rate"`    // Percentage of sent reminders
+	AvgMinutesToPay float64 `json:"avg_minutes_to_pay"` // From the reminder to payment, for converted reminders
+}
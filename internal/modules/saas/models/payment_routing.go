@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PaymentRoutingPolicy holds a tenant's rules for choosing the payment gateway of an order
+type PaymentRoutingPolicy struct {
+	ID             uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID       uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled        bool           `gorm:"default:true" json:"enabled"`
+	DefaultGateway string         `gorm:"type:text" json:"default_gateway"` // Gateway key when no rule matches (empty = system default)
+	Rules          datatypes.JSON `gorm:"type:jsonb" json:"rules"`          // []PaymentRoutingRule, first match wins
+	CreatedAt      time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (PaymentRoutingPolicy) TableName() string {
+	return "saas_payment_routing_policies"
+}
+
+// BeforeCreate sets UUID before creating
+func (p *PaymentRoutingPolicy) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// PaymentRoutingRule sends matching orders to a gateway. Empty conditions match any order.
+type PaymentRoutingRule struct {
+	Name           string   `json:"name,omitempty"`
+	Gateway        string   `json:"gateway"`                   // Gateway key (manual, midtrans, ...)
+	PaymentMethods []string `json:"payment_methods,omitempty"` // Customer's preferred method (qris, ewallet, bank_transfer, credit_card, cod)
+	MinAmount      float64  `json:"min_amount,omitempty"`
+	MaxAmount      float64  `json:"max_amount,omitempty"` // 0 = no limit
+	Segments       []string `json:"segments,omitempty"`   // Customer segments (new, returning)
+}
+
+// UpdatePaymentRoutingRequest is the body for saving a routing policy
+type UpdatePaymentRoutingRequest struct {
+	Enabled        bool                 `json:"enabled"`
+	DefaultGateway string               `json:"default_gateway"`
+	Rules          []PaymentRoutingRule `json:"rules"`
+}
+
+// Customer segments used by routing rules
+const (
+	CustomerSegmentNew       = "new"       // No previous orders
+	CustomerSegmentReturning = "returning" // Has ordered before
+)
+
+// PaymentRouteDefault is recorded on orders when no routing rule matched
+const PaymentRouteDefault = "default"
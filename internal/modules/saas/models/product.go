@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
 )
 
@@ -22,12 +24,22 @@ type Product struct {
 	Price       float64 `gorm:"type:decimal(12,2);not null;default:0" json:"price"`
 	Stock       int     `gorm:"type:integer;not null;default:0" json:"stock"`
 
+	// Pre-order (out-of-stock product can still be ordered)
+	PreorderEnabled     bool       `gorm:"type:boolean;default:false" json:"preorder_enabled"`
+	PreorderAvailableAt *time.Time `json:"preorder_available_at,omitempty"` // Expected availability date
+
 	// Media
-	ImageURL    string `gorm:"type:text" json:"image_url,omitempty"`
+	ImageURL         string         `gorm:"type:text" json:"image_url,omitempty"`
+	ThumbnailURL     string         `gorm:"type:text" json:"thumbnail_url,omitempty"`
+	WhatsAppImageURL string         `gorm:"column:whatsapp_image_url;type:text" json:"whatsapp_image_url,omitempty"`
+	ImagePublicIDs   pq.StringArray `gorm:"type:text[]" json:"-"` // Hosted variants, deleted when image is replaced or product is deleted
 
 	// Status
 	IsActive    bool `gorm:"type:boolean;default:true" json:"is_active"`
 
+	// Tenant-defined fields (see CustomFieldDefinition)
+	CustomFields datatypes.JSON `gorm:"type:jsonb;default:'{}'" json:"custom_fields,omitempty"`
+
 	// Timestamps
 	CreatedAt   time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt   time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
@@ -52,6 +64,11 @@ func (p *Product) IsAvailable() bool {
 	return p.IsActive && p.Stock > 0
 }
 
+// CanPreorder checks if an out-of-stock product accepts pre-orders
+func (p *Product) CanPreorder() bool {
+	return p.IsActive && p.Stock <= 0 && p.PreorderEnabled
+}
+
 // DeductStock reduces the stock by the specified quantity
 func (p *Product) DeductStock(quantity int) bool {
 	if p.Stock >= quantity {
@@ -76,6 +93,9 @@ type CreateProductRequest struct {
 	Stock       int     `json:"stock" validate:"gte=0"`
 	ImageURL    string  `json:"image_url,omitempty" validate:"omitempty,url"`
 	IsActive    *bool   `json:"is_active,omitempty"` // Pointer to allow explicit false
+
+	PreorderEnabled     bool       `json:"preorder_enabled,omitempty"`
+	PreorderAvailableAt *time.Time `json:"preorder_available_at,omitempty"`
 }
 
 // UpdateProductRequest represents product update request
@@ -88,6 +108,9 @@ type UpdateProductRequest struct {
 	Stock       *int     `json:"stock,omitempty" validate:"omitempty,gte=0"`
 	ImageURL    *string  `json:"image_url,omitempty" validate:"omitempty,url"`
 	IsActive    *bool    `json:"is_active,omitempty"`
+
+	PreorderEnabled     *bool      `json:"preorder_enabled,omitempty"`
+	PreorderAvailableAt *time.Time `json:"preorder_available_at,omitempty"`
 }
 
 // ProductListResponse represents paginated product list response
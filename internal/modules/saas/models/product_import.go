@@ -0,0 +1,19 @@
+package models
+
+// ProductImportResult summarizes a bulk product import
+type ProductImportResult struct {
+	DryRun  bool                 `json:"dry_run"`
+	Total   int                  `json:"total"`   // Product rows in the file
+	Created int                  `json:"created"` // New products (or that would be created on a dry run)
+	Updated int                  `json:"updated"` // Existing products matched by SKU or name
+	Failed  int                  `json:"failed"`
+	Errors  []ProductImportError `json:"errors"`
+}
+
+// ProductImportError is a row of the import file that was skipped
+type ProductImportError struct {
+	Row   int    `json:"row"` // Row number in the file, the header being row 1
+	SKU   string `json:"sku,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Error string `json:"error"`
+}
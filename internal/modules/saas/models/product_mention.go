@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProductMention is a product a customer asked about in a conversation
+type ProductMention struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null" json:"product_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+	MatchType     string    `gorm:"type:text;not null" json:"match_type"` // exact, fuzzy, llm
+	MatchedText   string    `gorm:"type:text" json:"matched_text,omitempty"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (ProductMention) TableName() string {
+	return "saas_product_mentions"
+}
+
+// BeforeCreate sets UUID before creating
+func (m *ProductMention) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
+
+// Mention match types
+const (
+	MentionMatchExact = "exact" // Product name appears in the message
+	MentionMatchFuzzy = "fuzzy" // Product name words appear with typos or in another order
+	MentionMatchLLM   = "llm"   // Resolved by the LLM (synonyms, descriptions)
+)
+
+// ProductMentionCount is the number of mentions of a product in a period
+type ProductMentionCount struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Mentions  int64     `json:"mentions"`
+	Askers    int64     `json:"askers"` // Distinct customers
+}
+
+// ProductSales is the quantity of a product sold in paid orders in a period
+type ProductSales struct {
+	ProductID   string  `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	UnitsSold   int64   `json:"units_sold"`
+	Orders      int64   `json:"orders"`
+	Revenue     float64 `json:"revenue"`
+}
+
+// ProductDemand compares how often a product is asked about with how often it sells
+type ProductDemand struct {
+	ProductID      uuid.UUID `json:"product_id"`
+	Name           string    `json:"name"`
+	Mentions       int64     `json:"mentions"`
+	Askers         int64     `json:"askers"`
+	UnitsSold      int64     `json:"units_sold"`
+	Orders         int64     `json:"orders"`
+	ConversionRate float64   `json:"conversion_rate"` // Orders per asking customer, in percent
+}
+
+// ProductDemandReport lists the most asked but least sold products of a period
+type ProductDemandReport struct {
+	Period   string          `json:"period"`
+	Start    time.Time       `json:"start"`
+	End      time.Time       `json:"end"`
+	Products []ProductDemand `json:"products"`
+}
@@ -0,0 +1,140 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// QuoteItem represents a single line of a quote
+type QuoteItem struct {
+	ProductID       string  `json:"product_id,omitempty"`
+	ProductName     string  `json:"product_name"`
+	Quantity        int     `json:"quantity"`
+	UnitPrice       float64 `json:"unit_price"`
+	DiscountPercent float64 `json:"discount_percent,omitempty"`
+	Subtotal        float64 `json:"subtotal"` // After line discount
+}
+
+// QuoteItems is a custom type for JSONB array
+type QuoteItems []QuoteItem
+
+// Scan implements sql.Scanner interface
+func (q *QuoteItems) Scan(value interface{}) error {
+	if value == nil {
+		*q = []QuoteItem{}
+		return nil
+	}
+	bytes, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+	return json.Unmarshal(bytes, q)
+}
+
+// Value implements driver.Valuer interface
+func (q QuoteItems) Value() (driver.Value, error) {
+	if q == nil {
+		return json.Marshal([]QuoteItem{})
+	}
+	return json.Marshal(q)
+}
+
+// Quote represents a quotation sent to a customer before an order is created
+type Quote struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	QuoteNumber string    `gorm:"type:text;unique;not null" json:"quote_number"`
+
+	// Customer
+	CustomerPhone string `gorm:"type:text;not null" json:"customer_phone"`
+	CustomerName  string `gorm:"type:text" json:"customer_name"`
+
+	// Quote Details
+	Items          QuoteItems `gorm:"type:jsonb;not null" json:"items"`
+	Subtotal       float64    `gorm:"type:decimal(12,2);not null;default:0" json:"subtotal"`
+	DiscountAmount float64    `gorm:"type:decimal(12,2);not null;default:0" json:"discount_amount"`
+	TotalAmount    float64    `gorm:"type:decimal(12,2);not null;default:0" json:"total_amount"`
+	Notes          string     `gorm:"type:text" json:"notes,omitempty"`
+
+	// Lifecycle
+	Status      string     `gorm:"type:text;not null;default:'draft'" json:"status"`
+	ValidUntil  time.Time  `gorm:"not null" json:"valid_until"`
+	AccessToken string     `gorm:"type:text;unique;not null" json:"-"`
+	OrderID     *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"` // Order created on acceptance
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+	RespondedAt *time.Time `json:"responded_at,omitempty"`
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (Quote) TableName() string {
+	return "saas_quotes"
+}
+
+// BeforeCreate sets UUID before creating
+func (q *Quote) BeforeCreate(tx *gorm.DB) error {
+	if q.ID == uuid.Nil {
+		q.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsExpired checks if the quote is past its validity period
+func (q *Quote) IsExpired() bool {
+	return time.Now().After(q.ValidUntil)
+}
+
+// CalculateTotals recalculates line subtotals and quote totals
+func (q *Quote) CalculateTotals() {
+	subtotal := 0.0
+	for i, item := range q.Items {
+		lineTotal := item.UnitPrice * float64(item.Quantity)
+		q.Items[i].Subtotal = lineTotal - lineTotal*item.DiscountPercent/100
+		subtotal += q.Items[i].Subtotal
+	}
+	q.Subtotal = subtotal
+	q.TotalAmount = subtotal - q.DiscountAmount
+}
+
+// Quote status constants
+const (
+	QuoteStatusDraft    = "draft"
+	QuoteStatusSent     = "sent"
+	QuoteStatusAccepted = "accepted"
+	QuoteStatusRejected = "rejected"
+	QuoteStatusExpired  = "expired"
+)
+
+// CreateQuoteRequest represents quote creation request
+type CreateQuoteRequest struct {
+	ClientID       string      `json:"client_id" validate:"required"`
+	CustomerPhone  string      `json:"customer_phone" validate:"required"`
+	CustomerName   string      `json:"customer_name,omitempty"`
+	Items          []QuoteItem `json:"items,omitempty"`     // Ignored when from_cart is set
+	FromCart       bool        `json:"from_cart,omitempty"` // Build items from the customer's active cart
+	DiscountAmount float64     `json:"discount_amount,omitempty"`
+	ValidDays      int         `json:"valid_days,omitempty"` // Default 7
+	Notes          string      `json:"notes,omitempty"`
+	Send           bool        `json:"send,omitempty"` // Send to the customer right away
+}
+
+// QuoteStats summarizes quote outcomes for a period
+type QuoteStats struct {
+	Total         int64   `json:"total"`
+	Draft         int64   `json:"draft"`
+	Sent          int64   `json:"sent"` // Awaiting response
+	Accepted      int64   `json:"accepted"`
+	Rejected      int64   `json:"rejected"`
+	Expired       int64   `json:"expired"`
+	WinRate       float64 `json:"win_rate"`  // accepted / decided, in percent
+	LossRate      float64 `json:"loss_rate"` // (rejected + expired) / decided, in percent
+	WonAmount     float64 `json:"won_amount"`
+	PendingAmount float64 `json:"pending_amount"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Reaction intents a customer's emoji reaction can map to
+const (
+	ReactionIntentConfirm = "confirm" // Confirm the pending COD order or accept the pending quote
+	ReactionIntentCancel  = "cancel"  // Cancel the unconfirmed COD order or reject the pending quote
+)
+
+// ReactionSettings maps a tenant's customer reactions (emoji) to intents
+type ReactionSettings struct {
+	ID        uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID  uuid.UUID      `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled   bool           `gorm:"not null" json:"enabled"`   // When false reactions only trigger workflows
+	Intents   datatypes.JSON `gorm:"type:jsonb" json:"intents"` // map[emoji]intent
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ReactionSettings) TableName() string {
+	return "saas_reaction_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *ReactionSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultReactionIntents is used when a tenant has not configured reactions
+func DefaultReactionIntents() map[string]string {
+	return map[string]string{
+		"👍": ReactionIntentConfirm,
+		"❌": ReactionIntentCancel,
+	}
+}
+
+// UpdateReactionSettingsRequest is the body for saving reaction settings
+type UpdateReactionSettingsRequest struct {
+	Enabled bool              `json:"enabled"`
+	Intents map[string]string `json:"intents"` // e.g. {"👍": "confirm", "❌": "cancel"}
+}
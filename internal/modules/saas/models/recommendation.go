@@ -0,0 +1,110 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Points in the chat where products are suggested
+const (
+	RecommendationAfterAddToCart = "after_add_to_cart"
+	RecommendationAfterCheckout  = "after_checkout"
+)
+
+// How a suggested product was picked
+const (
+	RecommendationRankedByCoPurchase = "co_purchase" // Most often ordered together with the cart
+	RecommendationRankedByLLM        = "llm"         // Co-purchase candidates ranked by the LLM against the conversation
+	RecommendationRankedByHistory    = "history"     // The customer's own earlier orders, when there is no co-purchase data
+)
+
+// RecommendationSettings controls a tenant's chat product recommendations
+type RecommendationSettings struct {
+	ID              uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	Enabled         bool      `gorm:"not null" json:"enabled"`
+	AfterAddToCart  bool      `gorm:"not null" json:"after_add_to_cart"`
+	AfterCheckout   bool      `gorm:"not null" json:"after_checkout"`
+	MaxSuggestions  int       `gorm:"not null" json:"max_suggestions"`
+	UseLLM          bool      `gorm:"column:use_llm;not null" json:"use_llm"`
+	CooldownMinutes int       `gorm:"not null" json:"cooldown_minutes"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (RecommendationSettings) TableName() string {
+	return "saas_recommendation_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *RecommendationSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// UpdateRecommendationSettingsRequest is the body for saving recommendation settings
+type UpdateRecommendationSettingsRequest struct {
+	Enabled         bool `json:"enabled"`
+	AfterAddToCart  bool `json:"after_add_to_cart"`
+	AfterCheckout   bool `json:"after_checkout"`
+	MaxSuggestions  int  `json:"max_suggestions"`  // 1-3, default 2
+	UseLLM          bool `json:"use_llm"`          // Rank candidates with the LLM instead of co-purchase counts only
+	CooldownMinutes int  `json:"cooldown_minutes"` // 0-1440, quiet time between two suggestions to a customer
+}
+
+// ProductRecommendation is a product suggested to a customer, with whether they took it
+type ProductRecommendation struct {
+	ID              uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID        uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone   string         `gorm:"type:text;not null" json:"customer_phone"`
+	TriggerPoint    string         `gorm:"type:text;not null" json:"trigger_point"`
+	SourceProducts  datatypes.JSON `gorm:"type:jsonb;not null;default:'[]'" json:"source_products"` // []string
+	ProductName     string         `gorm:"type:text;not null" json:"product_name"`
+	Rank            int            `gorm:"not null;default:1" json:"rank"`
+	RankedBy        string         `gorm:"type:text;not null" json:"ranked_by"`
+	CoPurchaseCount int            `gorm:"not null;default:0" json:"co_purchase_count"`
+	AddedToCartAt   *time.Time     `json:"added_to_cart_at,omitempty"`
+	ConvertedAt     *time.Time     `json:"converted_at,omitempty"`
+	OrderID         *uuid.UUID     `gorm:"type:uuid" json:"order_id,omitempty"`
+	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (ProductRecommendation) TableName() string {
+	return "saas_product_recommendations"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *ProductRecommendation) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// RecommendationStats summarizes how a tenant's suggestions performed
+type RecommendationStats struct {
+	From           time.Time                      `json:"from"`
+	To             time.Time                      `json:"to"`
+	Suggested      int64                          `json:"suggested"`
+	AddedToCart    int64                          `json:"added_to_cart"`
+	Converted      int64                          `json:"converted"`
+	ConversionRate float64                        `json:"conversion_rate"` // Converted / suggested
+	ByTrigger      []RecommendationStatsBreakdown `json:"by_trigger"`
+	ByRankedBy     []RecommendationStatsBreakdown `json:"by_ranked_by"`
+	TopProducts    []RecommendationStatsBreakdown `json:"top_products"` // Most converted suggested products
+}
+
+// RecommendationStatsBreakdown counts suggestions for one trigger point, ranking or product
+type RecommendationStatsBreakdown struct {
+	Key         string `json:"key"`
+	Suggested   int64  `json:"suggested"`
+	AddedToCart int64  `json:"added_to_cart"`
+	Converted   int64  `json:"converted"`
+}
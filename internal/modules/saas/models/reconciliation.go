@@ -0,0 +1,101 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PaymentSettlement is a transaction as recorded by the payment gateway
+type PaymentSettlement struct {
+	ID            uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	Gateway       string     `gorm:"type:text;not null" json:"gateway"`
+	OrderNumber   string     `gorm:"type:text;not null" json:"order_number"`
+	TransactionID string     `gorm:"type:text;not null" json:"transaction_id"`
+	Status        string     `gorm:"type:text;not null" json:"status"` // Gateway status (settlement, capture, refund, ...)
+	PaymentType   string     `gorm:"type:text" json:"payment_type"`
+	GrossAmount   float64    `gorm:"type:decimal(12,2);not null" json:"gross_amount"`
+	SettledAt     *time.Time `json:"settled_at,omitempty"`
+	Source        string     `gorm:"type:text;not null" json:"source"` // gateway_api, report_import
+	CreatedAt     time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (PaymentSettlement) TableName() string {
+	return "saas_payment_settlements"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *PaymentSettlement) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsSettled reports whether the gateway considers the money received
+func (s *PaymentSettlement) IsSettled() bool {
+	return s.Status == "settlement" || s.Status == "capture"
+}
+
+// Settlement sources
+const (
+	SettlementSourceGatewayAPI   = "gateway_api"
+	SettlementSourceReportImport = "report_import"
+)
+
+// ReconciliationReport is the result of matching a day's paid orders against gateway settlements
+type ReconciliationReport struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	ReportDate    time.Time      `gorm:"type:date;not null" json:"report_date"`
+	Gateway       string         `gorm:"type:text;not null" json:"gateway"`
+	MatchedCount  int            `gorm:"default:0" json:"matched_count"`
+	MismatchCount int            `gorm:"default:0" json:"mismatch_count"`
+	PaidTotal     float64        `gorm:"type:decimal(14,2)" json:"paid_total"`    // Paid orders in the report
+	SettledTotal  float64        `gorm:"type:decimal(14,2)" json:"settled_total"` // Settled gateway transactions in the report
+	Items         datatypes.JSON `gorm:"type:jsonb" json:"items"`                 // []ReconciliationItem
+	GeneratedAt   time.Time      `gorm:"not null" json:"generated_at"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (ReconciliationReport) TableName() string {
+	return "saas_reconciliation_reports"
+}
+
+// BeforeCreate sets UUID before creating
+func (r *ReconciliationReport) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+// ReconciliationItem is one order and/or settlement line of a reconciliation report
+type ReconciliationItem struct {
+	OrderNumber      string     `json:"order_number"`
+	OrderID          string     `json:"order_id,omitempty"`
+	TransactionID    string     `json:"transaction_id,omitempty"`
+	Result           string     `json:"result"`
+	PaymentStatus    string     `json:"payment_status,omitempty"`    // Our order's payment status
+	SettlementStatus string     `json:"settlement_status,omitempty"` // Gateway transaction status
+	OrderAmount      float64    `json:"order_amount"`
+	SettledAmount    float64    `json:"settled_amount"`
+	PaidAt           *time.Time `json:"paid_at,omitempty"`
+	SettledAt        *time.Time `json:"settled_at,omitempty"`
+}
+
+// Reconciliation results
+const (
+	ReconciliationMatched                = "matched"
+	ReconciliationPaidWithoutSettlement  = "paid_without_settlement"  // Order marked paid, gateway has no settlement
+	ReconciliationSettlementWithoutOrder = "settlement_without_order" // Gateway settled money for an order we don't have
+	ReconciliationUnpaidWithSettlement   = "unpaid_with_settlement"   // Gateway settled but the order isn't marked paid
+	ReconciliationAmountMismatch         = "amount_mismatch"          // Settled amount differs from the order total
+)
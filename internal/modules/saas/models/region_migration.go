@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// Region migration statuses
+const (
+	RegionMigrationPending   = "pending"
+	RegionMigrationRunning   = "running"
+	RegionMigrationCompleted = "completed"
+	RegionMigrationFailed    = "failed"
+)
+
+// RegionMigration is a move of a tenant's data from one region to another
+type RegionMigration struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID     uuid.UUID      `gorm:"type:uuid;not null" json:"client_id"`
+	FromRegion   string         `gorm:"type:text;not null" json:"from_region"` // Empty = default region
+	ToRegion     string         `gorm:"type:text;not null" json:"to_region"`
+	Status       string         `gorm:"type:text;not null;default:'pending'" json:"status"`
+	Tables       datatypes.JSON `gorm:"type:jsonb" json:"tables"` // []RegionMigrationTable
+	VectorSynced bool           `gorm:"not null;default:false" json:"vector_synced"`
+	Error        string         `gorm:"type:text" json:"error,omitempty"`
+	StartedAt    *time.Time     `json:"started_at,omitempty"`
+	FinishedAt   *time.Time     `json:"finished_at,omitempty"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// RegionMigrationTable is how many rows of a table were found in the source region and copied to the target
+type RegionMigrationTable struct {
+	Table      string `json:"table"`
+	SourceRows int64  `json:"source_rows"`
+	CopiedRows int64  `json:"copied_rows"` // Rows the target did not have yet
+	TargetRows int64  `json:"target_rows"` // After copying, must equal source_rows
+}
+
+// TableName specifies the table name
+func (RegionMigration) TableName() string {
+	return "saas_region_migrations"
+}
+
+// BeforeCreate sets UUID before creating
+func (m *RegionMigration) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// SalesReport summarizes a client's orders and OCR transactions over a date range (test orders excluded)
+type SalesReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"` // Exclusive
+
+	Orders            int64   `json:"orders"`      // Created in the range
+	PaidOrders        int64   `json:"paid_orders"` // Of those, paid (including later refunded)
+	CancelledOrders   int64   `json:"cancelled_orders"`
+	Revenue           float64 `json:"revenue"`             // Total of paid orders
+	RefundedAmount    float64 `json:"refunded_amount"`     // Refunded from those orders
+	NetRevenue        float64 `json:"net_revenue"`         // Revenue minus refunds
+	AverageOrderValue float64 `json:"average_order_value"` // Revenue per paid order
+
+	// Receipts and invoices recorded as transactions, dated in the range
+	Transactions      int64   `json:"transactions"`
+	OCRTransactions   int64   `json:"ocr_transactions"` // Read from a receipt or invoice photo
+	TransactionAmount float64 `json:"transaction_amount"`
+
+	Daily []SalesReportDay `json:"daily"`
+}
+
+// SalesReportDay is one day of a sales report
+type SalesReportDay struct {
+	Date       string  `json:"date"` // YYYY-MM-DD
+	Orders     int64   `json:"orders"`
+	PaidOrders int64   `json:"paid_orders"`
+	Revenue    float64 `json:"revenue"`
+}
+
+// SalesSummary is the order totals of a sales report
+type SalesSummary struct {
+	Orders          int64
+	PaidOrders      int64
+	CancelledOrders int64
+	Revenue         float64
+	RefundedAmount  float64
+}
+
+// TransactionSummary is the transaction totals of a sales report
+type TransactionSummary struct {
+	Transactions      int64
+	OCRTransactions   int64
+	TransactionAmount float64
+}
+
+// TopProductsReport ranks the products sold in paid orders over a date range
+type TopProductsReport struct {
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	SortBy   string         `json:"sort_by"` // revenue or units
+	Products []ProductSales `json:"products"`
+}
+
+// ConversationReport summarizes a client's WhatsApp conversation volume over a date range
+type ConversationReport struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+
+	CustomerMessages int64   `json:"customer_messages"`
+	AIReplies        int64   `json:"ai_replies"`    // Customer messages the bot answered
+	AgentReplies     int64   `json:"agent_replies"` // Typed on the business phone
+	Customers        int64   `json:"customers"`     // Distinct customers who wrote
+	AIReplyRate      float64 `json:"ai_reply_rate"` // AI replies per customer message
+
+	Daily []ConversationReportDay `json:"daily"`
+}
+
+// ConversationReportDay is one day of a conversation report
+type ConversationReportDay struct {
+	Date             string `json:"date"` // YYYY-MM-DD
+	CustomerMessages int64  `json:"customer_messages"`
+	AIReplies        int64  `json:"ai_replies"`
+	AgentReplies     int64  `json:"agent_replies"`
+	Customers        int64  `json:"customers"`
+}
+
+// ConversationVolume is the totals of a conversation report
+type ConversationVolume struct {
+	CustomerMessages int64
+	AIReplies        int64
+	AgentReplies     int64
+	Customers        int64
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Sandbox message directions
+const (
+	SandboxDirectionInbound  = "inbound"
+	SandboxDirectionOutbound = "outbound"
+)
+
+// SandboxMessage is a WhatsApp message captured (not sent) for a tenant in sandbox mode
+type SandboxMessage struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID  uuid.UUID `gorm:"type:uuid;not null;index" json:"client_id"`
+	Direction string    `gorm:"type:text;not null" json:"direction"` // inbound, outbound
+	Phone     string    `gorm:"type:text;not null" json:"phone"`
+	Message   string    `gorm:"type:text;not null" json:"message"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (SandboxMessage) TableName() string {
+	return "saas_sandbox_messages"
+}
+
+// BeforeCreate sets UUID before creating
+func (m *SandboxMessage) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// SLASettings holds a client's response and resolution targets
+type SLASettings struct {
+	ID                         uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID                   uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+	FirstResponseTargetSeconds int       `gorm:"not null;default:300" json:"first_response_target_seconds"`
+	ResolutionTargetSeconds    int       `gorm:"not null;default:86400" json:"resolution_target_seconds"`
+	IdleResolveMinutes         int       `gorm:"not null;default:60" json:"idle_resolve_minutes"` // A thread without messages this long counts as resolved
+	CreatedAt                  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt                  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (SLASettings) TableName() string {
+	return "saas_sla_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *SLASettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// UpdateSLASettingsRequest is the body for saving SLA targets
+type UpdateSLASettingsRequest struct {
+	FirstResponseTargetSeconds int `json:"first_response_target_seconds"`
+	ResolutionTargetSeconds    int `json:"resolution_target_seconds"`
+	IdleResolveMinutes         int `json:"idle_resolve_minutes"`
+}
+
+// SLAThread is one support exchange with a customer, from their first message until it is closed or goes idle
+type SLAThread struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+	Status        string    `gorm:"type:text;not null;default:'open'" json:"status"` // open, resolved
+
+	OpenedAt              time.Time `gorm:"not null" json:"opened_at"`
+	LastCustomerMessageAt time.Time `gorm:"not null" json:"last_customer_message_at"`
+	LastActivityAt        time.Time `gorm:"not null" json:"last_activity_at"`
+
+	FirstResponseAt      *time.Time `json:"first_response_at,omitempty"`
+	FirstResponseBy      string     `gorm:"type:text" json:"first_response_by,omitempty"` // "bot" or the agent
+	FirstAgentResponseAt *time.Time `json:"first_agent_response_at,omitempty"`
+	FirstAgent           string     `gorm:"type:text" json:"first_agent,omitempty"`
+
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	ResolvedBy string     `gorm:"type:text" json:"resolved_by,omitempty"`
+	Resolution string     `gorm:"type:text" json:"resolution,omitempty"` // closed, idle
+
+	FirstResponseBreached bool `gorm:"default:false" json:"first_response_breached"`
+	ResolutionBreached    bool `gorm:"default:false" json:"resolution_breached"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (SLAThread) TableName() string {
+	return "saas_sla_threads"
+}
+
+// BeforeCreate sets UUID before creating
+func (t *SLAThread) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// SLA thread constants
+const (
+	SLAThreadOpen     = "open"
+	SLAThreadResolved = "resolved"
+
+	SLAResolutionClosed = "closed"
+	SLAResolutionIdle   = "idle"
+
+	SLAResponderBot = "bot"
+)
+
+// SLAMetric summarizes durations in seconds
+type SLAMetric struct {
+	Count         int64   `json:"count"`
+	P50           float64 `json:"p50"`
+	P90           float64 `json:"p90"`
+	P95           float64 `json:"p95"`
+	Avg           float64 `json:"avg"`
+	Breached      int64   `json:"breached"`
+	BreachRate    float64 `json:"breach_rate"` // In percent
+	TargetSeconds int     `json:"target_seconds"`
+}
+
+// SLADurationStats is a row of aggregated durations, per agent when grouped
+type SLADurationStats struct {
+	Agent    string
+	Count    int64
+	P50      float64
+	P90      float64
+	P95      float64
+	Avg      float64
+	Breached int64
+}
+
+// SLAAgentMetric is the SLA performance of one responder ("bot" or an agent)
+type SLAAgentMetric struct {
+	Agent         string    `json:"agent"`
+	FirstResponse SLAMetric `json:"first_response"`
+	Resolution    SLAMetric `json:"resolution"`
+}
+
+// SLAReport is a client's first-response and resolution times over a period
+type SLAReport struct {
+	Period        string           `json:"period"`
+	Start         time.Time        `json:"start"`
+	End           time.Time        `json:"end"`
+	OpenThreads   int64            `json:"open_threads"`
+	FirstResponse SLAMetric        `json:"first_response"`
+	Resolution    SLAMetric        `json:"resolution"`
+	Agents        []SLAAgentMetric `json:"agents"`
+}
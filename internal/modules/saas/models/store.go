@@ -0,0 +1,78 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Store represents a physical store/branch location of a client
+// Stores double as branches: each can have its own admin, stock and orders
+type Store struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null;index" json:"client_id"`
+
+	// Store Info
+	Name         string `gorm:"type:text;not null" json:"name"`
+	Address      string `gorm:"type:text;not null" json:"address"`
+	Phone        string `gorm:"type:text" json:"phone,omitempty"`
+	OpeningHours string `gorm:"type:text" json:"opening_hours,omitempty"`
+
+	// Branch admin (receives order notifications for this branch)
+	AdminName  string `gorm:"type:text" json:"admin_name,omitempty"`
+	AdminPhone string `gorm:"type:text" json:"admin_phone,omitempty"`
+
+	// Coordinates
+	Latitude  float64 `gorm:"type:double precision;not null" json:"latitude"`
+	Longitude float64 `gorm:"type:double precision;not null" json:"longitude"`
+
+	// Status
+	IsActive bool `gorm:"type:boolean;default:true" json:"is_active"`
+
+	// Timestamps
+	CreatedAt time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+
+	DistanceKm *float64 `gorm:"-" json:"distance_km,omitempty"` // Computed for nearest-store lookups
+}
+
+// TableName specifies the table name
+func (Store) TableName() string {
+	return "saas_stores"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *Store) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// CreateStoreRequest represents store creation request
+type CreateStoreRequest struct {
+	Name         string  `json:"name" validate:"required,min=1,max=200"`
+	Address      string  `json:"address" validate:"required"`
+	Phone        string  `json:"phone,omitempty"`
+	OpeningHours string  `json:"opening_hours,omitempty"`
+	AdminName    string  `json:"admin_name,omitempty"`
+	AdminPhone   string  `json:"admin_phone,omitempty"`
+	Latitude     float64 `json:"latitude" validate:"gte=-90,lte=90"`
+	Longitude    float64 `json:"longitude" validate:"gte=-180,lte=180"`
+	IsActive     *bool   `json:"is_active,omitempty"` // Pointer to allow explicit false
+}
+
+// UpdateStoreRequest represents store update request
+type UpdateStoreRequest struct {
+	Name         *string  `json:"name,omitempty"`
+	Address      *string  `json:"address,omitempty"`
+	Phone        *string  `json:"phone,omitempty"`
+	OpeningHours *string  `json:"opening_hours,omitempty"`
+	AdminName    *string  `json:"admin_name,omitempty"`
+	AdminPhone   *string  `json:"admin_phone,omitempty"`
+	Latitude     *float64 `json:"latitude,omitempty"`
+	Longitude    *float64 `json:"longitude,omitempty"`
+	IsActive     *bool    `json:"is_active,omitempty"`
+}
@@ -0,0 +1,224 @@
+package models
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Usage metrics limited by a plan
+const (
+	UsageMetricMessages      = "messages"       // Conversations in the current billing period
+	UsageMetricProducts      = "products"       // Products in the catalog
+	UsageMetricKnowledgeBase = "knowledge_base" // Knowledge base entries
+	UsageMetricKBDocuments   = "kb_documents"   // Uploaded knowledge base documents
+	UsageMetricTokens        = "tokens"         // Estimated LLM tokens in the current billing period
+	UsageMetricStorage       = "storage"        // Stored conversations and knowledge base, in MB
+)
+
+// Plan change statuses
+const (
+	PlanChangePendingPayment = "pending_payment" // Upgrade waiting for the prorated payment
+	PlanChangeScheduled      = "scheduled"       // Downgrade applied at the start of the next period
+	PlanChangeApplied        = "applied"
+	PlanChangeCancelled      = "cancelled"
+	PlanChangeFailed         = "failed"
+)
+
+// PlanLimits caps usage per billing period; 0 means unlimited
+type PlanLimits struct {
+	MessagesPerMonth int `json:"messages_per_month"`
+	Products         int `json:"products"`
+	KnowledgeBase    int `json:"knowledge_base"`
+	KBDocuments      int `json:"kb_documents"`
+	TokensPerMonth   int `json:"tokens_per_month"`
+	StorageMB        int `json:"storage_mb"`
+}
+
+// Limit returns the limit for a usage metric
+func (l PlanLimits) Limit(metric string) int {
+	switch metric {
+	case UsageMetricMessages:
+		return l.MessagesPerMonth
+	case UsageMetricProducts:
+		return l.Products
+	case UsageMetricKnowledgeBase:
+		return l.KnowledgeBase
+	case UsageMetricKBDocuments:
+		return l.KBDocuments
+	case UsageMetricTokens:
+		return l.TokensPerMonth
+	case UsageMetricStorage:
+		return l.StorageMB
+	}
+	return 0
+}
+
+// OverageRates prices usage above the plan limits on the monthly statement (IDR); 0 means not billed
+type OverageRates struct {
+	PerMessage        float64 `json:"per_message"`
+	PerThousandTokens float64 `json:"per_thousand_tokens"`
+	PerMB             float64 `json:"per_mb"`
+}
+
+// Rate returns the price of one unit of a metric and the unit size
+func (r OverageRates) Rate(metric string) (float64, int) {
+	switch metric {
+	case UsageMetricMessages:
+		return r.PerMessage, 1
+	case UsageMetricTokens:
+		return r.PerThousandTokens, 1000
+	case UsageMetricStorage:
+		return r.PerMB, 1
+	}
+	return 0, 1
+}
+
+// Plan is a subscription plan offered in the catalog
+type Plan struct {
+	Code         string       `json:"code"`
+	Name         string       `json:"name"`
+	MonthlyPrice float64      `json:"monthly_price"` // IDR
+	Limits       PlanLimits   `json:"limits"`
+	Overage      OverageRates `json:"overage"`
+	Features     []string     `json:"features"`
+}
+
+// DowngradePlan is the plan tenants are moved to when their subscription payment fails
+const DowngradePlan = "free"
+
+// PlanCatalog lists the built-in self-service plans from smallest to largest. Plans defined by the
+// operator (saas_plans) are merged into it with SetPlans
+var PlanCatalog = []Plan{
+	{
+		Code:         "free",
+		Name:         "Free",
+		MonthlyPrice: 0,
+		Limits:       PlanLimits{MessagesPerMonth: 300, Products: 20, KnowledgeBase: 20, KBDocuments: 2, TokensPerMonth: 150000, StorageMB: 50},
+		Features:     []string{"AI replies on WhatsApp", "Product catalog", "Manual payment confirmation"},
+	},
+	{
+		Code:         "starter",
+		Name:         "Starter",
+		MonthlyPrice: 99000,
+		Limits:       PlanLimits{MessagesPerMonth: 2000, Products: 100, KnowledgeBase: 100, KBDocuments: 10, TokensPerMonth: 1000000, StorageMB: 500},
+		Overage:      OverageRates{PerMessage: 50, PerThousandTokens: 100, PerMB: 200},
+		Features:     []string{"Everything in Free", "Payment links", "Order notifications"},
+	},
+	{
+		Code:         "pro",
+		Name:         "Pro",
+		MonthlyPrice: 299000,
+		Limits:       PlanLimits{MessagesPerMonth: 10000, Products: 1000, KnowledgeBase: 500, KBDocuments: 50, TokensPerMonth: 5000000, StorageMB: 2048},
+		Overage:      OverageRates{PerMessage: 30, PerThousandTokens: 80, PerMB: 100},
+		Features:     []string{"Everything in Starter", "Workflows", "Multi-branch stock", "Analytics"},
+	},
+	{
+		Code:         "business",
+		Name:         "Business",
+		MonthlyPrice: 799000,
+		Limits:       PlanLimits{},
+		Features:     []string{"Everything in Pro", "Unlimited usage", "Priority support"},
+	},
+}
+
+var (
+	plansMu sync.RWMutex
+	plans   = PlanCatalog
+)
+
+// Plans returns the current self-service plan catalog from smallest to largest. The slice is
+// replaced, never modified, by SetPlans
+func Plans() []Plan {
+	plansMu.RLock()
+	defer plansMu.RUnlock()
+	return plans
+}
+
+// SetPlans replaces the catalog; an empty list restores the built-in PlanCatalog
+func SetPlans(catalog []Plan) {
+	if len(catalog) == 0 {
+		catalog = PlanCatalog
+	}
+	plansMu.Lock()
+	plans = catalog
+	plansMu.Unlock()
+}
+
+// FindPlan returns the catalog plan with the given code and its position in the catalog
+func FindPlan(code string) (*Plan, int, bool) {
+	catalog := Plans()
+	for i := range catalog {
+		if catalog[i].Code == code {
+			return &catalog[i], i, true
+		}
+	}
+	return nil, -1, false
+}
+
+// PlanChange is a tenant's request to move to another plan
+type PlanChange struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID       uuid.UUID  `gorm:"type:uuid;not null;index" json:"client_id"`
+	FromPlan       string     `gorm:"type:text;not null" json:"from_plan"`
+	ToPlan         string     `gorm:"type:text;not null" json:"to_plan"`
+	Status         string     `gorm:"type:text;not null" json:"status"`
+	Reference      string     `gorm:"type:text;not null;uniqueIndex" json:"reference"` // Order ID sent to the payment gateway
+	ProratedAmount float64    `gorm:"type:numeric(15,2)" json:"prorated_amount"`
+	PeriodStart    time.Time  `json:"period_start"`
+	PeriodEnd      time.Time  `json:"period_end"`
+	EffectiveAt    *time.Time `json:"effective_at,omitempty"`
+	PaymentLink    string     `gorm:"type:text" json:"payment_link,omitempty"`
+	PaymentMethod  string     `gorm:"type:text" json:"payment_method,omitempty"`
+	TransactionID  string     `gorm:"type:text" json:"transaction_id,omitempty"`
+	AppliedAt      *time.Time `json:"applied_at,omitempty"`
+	CancelReason   string     `gorm:"type:text" json:"cancel_reason,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (PlanChange) TableName() string {
+	return "saas_plan_changes"
+}
+
+// BeforeCreate sets UUID before creating
+func (p *PlanChange) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
+
+// UsageAlert records an upgrade prompt sent for a usage threshold
+type UsageAlert struct {
+	ID          uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID    uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	PeriodStart time.Time `json:"period_start"`
+	Metric      string    `gorm:"type:text;not null" json:"metric"`
+	Threshold   int       `json:"threshold"` // Percent of the plan limit
+	Plan        string    `gorm:"type:text;not null" json:"plan"`
+	Used        int       `json:"used"`
+	UsageLimit  int       `json:"usage_limit"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (UsageAlert) TableName() string {
+	return "saas_usage_alerts"
+}
+
+// BeforeCreate sets UUID before creating
+func (a *UsageAlert) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
+
+// ChangePlanRequest is the body for a self-service plan change
+type ChangePlanRequest struct {
+	Plan string `json:"plan"` // Catalog plan code
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+// Tenant subscription statuses
+const (
+	SubscriptionActive     = "active"
+	SubscriptionPastDue    = "past_due"   // A billing statement is overdue; downgraded when the grace period ends
+	SubscriptionDowngraded = "downgraded" // Moved to DowngradePlan for non-payment
+)
+
+// PlanDefinition is a plan defined by the operator. It adds a plan to the built-in catalog or replaces the
+// built-in plan with the same code; an inactive definition withdraws the plan from the catalog
+type PlanDefinition struct {
+	Code         string         `gorm:"type:text;primary_key" json:"code"`
+	Name         string         `gorm:"type:text;not null" json:"name"`
+	MonthlyPrice float64        `gorm:"type:numeric(15,2);not null;default:0" json:"monthly_price"` // IDR
+	Limits       datatypes.JSON `gorm:"type:jsonb;not null" json:"limits"`                          // PlanLimits
+	Overage      datatypes.JSON `gorm:"type:jsonb;not null" json:"overage"`                         // OverageRates
+	Features     pq.StringArray `gorm:"type:text[]" json:"features"`
+	Active       bool           `gorm:"not null" json:"active"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt    time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (PlanDefinition) TableName() string {
+	return "saas_plans"
+}
+
+// Plan converts the definition to a catalog plan
+func (d *PlanDefinition) Plan() (Plan, error) {
+	plan := Plan{Code: d.Code, Name: d.Name, MonthlyPrice: d.MonthlyPrice, Features: d.Features}
+	if len(d.Limits) > 0 {
+		if err := json.Unmarshal(d.Limits, &plan.Limits); err != nil {
+			return plan, fmt.Errorf("invalid limits of plan %s: %w", d.Code, err)
+		}
+	}
+	if len(d.Overage) > 0 {
+		if err := json.Unmarshal(d.Overage, &plan.Overage); err != nil {
+			return plan, fmt.Errorf("invalid overage of plan %s: %w", d.Code, err)
+		}
+	}
+	if plan.Features == nil {
+		plan.Features = []string{}
+	}
+	return plan, nil
+}
+
+// TenantSubscription is the payment standing of a client's plan
+type TenantSubscription struct {
+	ClientID           uuid.UUID  `gorm:"type:uuid;primary_key" json:"client_id"`
+	Status             string     `gorm:"type:text;not null;default:'active'" json:"status"`
+	OverdueStatementID *uuid.UUID `gorm:"type:uuid" json:"overdue_statement_id,omitempty"`
+	PastDueSince       *time.Time `json:"past_due_since,omitempty"`
+	GraceEndsAt        *time.Time `json:"grace_ends_at,omitempty"`
+	DowngradedFrom     string     `gorm:"type:text" json:"downgraded_from,omitempty"`
+	DowngradedAt       *time.Time `json:"downgraded_at,omitempty"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (TenantSubscription) TableName() string {
+	return "saas_tenant_subscriptions"
+}
+
+// PlanRequest defines or updates an operator plan
+type PlanRequest struct {
+	Name         string       `json:"name"`
+	MonthlyPrice float64      `json:"monthly_price"`
+	Limits       PlanLimits   `json:"limits"`
+	Overage      OverageRates `json:"overage"`
+	Features     []string     `json:"features"`
+	Active       *bool        `json:"active"` // Defaults to true
+}
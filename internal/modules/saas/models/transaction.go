@@ -20,6 +20,7 @@ type Transaction struct {
 	SourceType      string         `gorm:"type:varchar(20);not null;default:'manual'" json:"source_type"`  // 'receipt', 'invoice', 'manual'
 	OCRConfidence   *float64       `gorm:"type:float" json:"ocr_confidence,omitempty"`                     // OCR confidence score (0-1)
 	OCRRawText      string         `gorm:"type:text" json:"ocr_raw_text,omitempty"`                        // Original OCR extracted text
+	OCRRawTextPurgedAt *time.Time  `json:"ocr_raw_text_purged_at,omitempty"`                                   // Set when raw text was deleted (retention or on demand)
 	CreatedAt       time.Time      `gorm:"autoCreateTime" json:"created_at"`
 	UpdatedAt       time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
 
@@ -39,3 +40,16 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	}
 	return nil
 }
+
+// OCRRetentionSettings is a client's raw OCR text retention policy
+type OCRRetentionSettings struct {
+	RetentionDays    int  `json:"retention_days"` // 0 = don't store, negative = keep forever
+	Anonymize        bool `json:"anonymize"`      // Mask card numbers, emails, phones and names at ingestion
+	MaxRawTextLength int  `json:"max_raw_text_length"`
+}
+
+// UpdateOCRRetentionRequest updates a client's raw OCR text retention policy
+type UpdateOCRRetentionRequest struct {
+	RetentionDays *int  `json:"retention_days"`
+	Anonymize     *bool `json:"anonymize"`
+}
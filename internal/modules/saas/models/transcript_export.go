@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// Transcript export statuses
+const (
+	TranscriptExportPending   = "pending"
+	TranscriptExportCompleted = "completed"
+	TranscriptExportFailed    = "failed"
+)
+
+// TranscriptExport is a customer's chat transcript rendered as PDF or HTML by a background job
+type TranscriptExport struct {
+	ID            uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID      `gorm:"type:uuid;not null;index" json:"client_id"`
+	CustomerPhone string         `gorm:"type:text;not null" json:"customer_phone"`
+	Format        string         `gorm:"type:text;not null" json:"format"` // pdf, html
+	Status        string         `gorm:"type:text;not null;default:'pending'" json:"status"`
+	RangeStart    *time.Time     `json:"range_start,omitempty"`
+	RangeEnd      *time.Time     `json:"range_end,omitempty"`
+	JobID         *uuid.UUID     `gorm:"type:uuid" json:"job_id,omitempty"`
+	FileURL       string         `gorm:"type:text" json:"file_url,omitempty"`
+	MessageCount  int            `json:"message_count"`
+	EmailTo       pq.StringArray `gorm:"type:text[]" json:"email_to,omitempty"`
+	EmailedAt     *time.Time     `json:"emailed_at,omitempty"`
+	Error         string         `gorm:"type:text" json:"error,omitempty"`
+	CompletedAt   *time.Time     `json:"completed_at,omitempty"`
+	CreatedAt     time.Time      `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time      `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (TranscriptExport) TableName() string {
+	return "saas_transcript_exports"
+}
+
+// BeforeCreate sets UUID before creating
+func (e *TranscriptExport) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return nil
+}
+
+// TranscriptExportRequest is the body for exporting a conversation
+type TranscriptExportRequest struct {
+	Format  string     `json:"format" example:"pdf"`                                // pdf (default) or html
+	Email   bool       `json:"email"`                                               // Email the download link to the tenant admins
+	EmailTo []string   `json:"email_to,omitempty"`                                  // Recipients instead of the tenant admins
+	Start   *time.Time `json:"start,omitempty" example:"2026-10-01T00:00:00+07:00"` // Only messages from this time
+	End     *time.Time `json:"end,omitempty" example:"2026-10-16T00:00:00+07:00"`   // Only messages before this time
+}
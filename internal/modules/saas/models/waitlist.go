@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WaitlistEntry represents a customer waiting for an out-of-stock product
+type WaitlistEntry struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	ProductID     uuid.UUID `gorm:"type:uuid;not null" json:"product_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+
+	Type     string `gorm:"type:text;not null;default:'restock'" json:"type"`   // restock, preorder
+	Status   string `gorm:"type:text;not null;default:'offered'" json:"status"` // offered, waiting, notified, converted, cancelled
+	Quantity int    `gorm:"default:1" json:"quantity"`
+
+	OrderID     *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty"`
+	ConvertedAt *time.Time `json:"converted_at,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+
+	Product *Product `gorm:"foreignKey:ProductID" json:"product,omitempty"`
+}
+
+// TableName specifies the table name
+func (WaitlistEntry) TableName() string {
+	return "saas_waitlist"
+}
+
+// BeforeCreate sets UUID before creating
+func (w *WaitlistEntry) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// Waitlist type and status constants
+const (
+	WaitlistTypeRestock  = "restock"
+	WaitlistTypePreorder = "preorder"
+
+	WaitlistStatusOffered   = "offered" // Restock alert offered, customer has not opted in yet
+	WaitlistStatusWaiting   = "waiting"
+	WaitlistStatusNotified  = "notified"
+	WaitlistStatusConverted = "converted"
+	WaitlistStatusCancelled = "cancelled"
+)
+
+// WaitlistStats summarizes a product's waitlist and its conversion
+type WaitlistStats struct {
+	Waiting        int64   `json:"waiting"`
+	Notified       int64   `json:"notified"`
+	Converted      int64   `json:"converted"`
+	Preorders      int64   `json:"preorders"`
+	ConversionRate float64 `json:"conversion_rate"` // converted / (notified + converted), in percent
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Wallet transaction types
+const (
+	WalletTxTopUp      = "top_up"     // Credit bought through the payment gateway
+	WalletTxPayment    = "payment"    // Order paid from the balance
+	WalletTxRefund     = "refund"     // Refund of an order paid from the balance
+	WalletTxAdjustment = "adjustment" // Manual correction by the tenant
+)
+
+// Wallet transaction statuses
+const (
+	WalletTxPending   = "pending" // Top-up waiting for its payment
+	WalletTxCompleted = "completed"
+	WalletTxCancelled = "cancelled" // Top-up payment denied, cancelled or expired
+)
+
+// WalletSettings holds a tenant's prepaid wallet settings
+type WalletSettings struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+
+	Enabled    bool    `gorm:"default:false" json:"enabled"`
+	AutoDeduct bool    `gorm:"default:true" json:"auto_deduct"`                    // Pay orders from the balance at checkout when it covers the total
+	MinTopUp   float64 `gorm:"type:decimal(12,2);default:10000" json:"min_top_up"` // Smallest top-up a customer can order
+	MaxTopUp   float64 `gorm:"type:decimal(12,2);default:0" json:"max_top_up"`     // 0 = no limit
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (WalletSettings) TableName() string {
+	return "saas_wallet_settings"
+}
+
+// BeforeCreate sets UUID before creating
+func (s *WalletSettings) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// DefaultWalletSettings returns the settings used when a tenant has not configured wallets (disabled)
+func DefaultWalletSettings(clientID uuid.UUID) *WalletSettings {
+	return &WalletSettings{
+		ClientID:   clientID,
+		AutoDeduct: true,
+		MinTopUp:   10000,
+	}
+}
+
+// UpdateWalletSettingsRequest updates a tenant's wallet settings
+type UpdateWalletSettingsRequest struct {
+	Enabled    bool    `json:"enabled"`
+	AutoDeduct bool    `json:"auto_deduct"`
+	MinTopUp   float64 `json:"min_top_up"` // Default 10000
+	MaxTopUp   float64 `json:"max_top_up"` // 0 = no limit
+}
+
+// CustomerWallet is a customer's prepaid balance at a tenant
+type CustomerWallet struct {
+	ID            uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID      uuid.UUID `gorm:"type:uuid;not null" json:"client_id"`
+	CustomerPhone string    `gorm:"type:text;not null" json:"customer_phone"`
+	CustomerName  string    `gorm:"type:text" json:"customer_name,omitempty"`
+	Balance       float64   `gorm:"type:decimal(12,2);not null;default:0" json:"balance"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (CustomerWallet) TableName() string {
+	return "saas_customer_wallets"
+}
+
+// BeforeCreate sets UUID before creating
+func (w *CustomerWallet) BeforeCreate(tx *gorm.DB) error {
+	if w.ID == uuid.Nil {
+		w.ID = uuid.New()
+	}
+	return nil
+}
+
+// WalletTransaction is one entry of a customer's wallet ledger
+type WalletTransaction struct {
+	ID             uuid.UUID  `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID       uuid.UUID  `gorm:"type:uuid;not null" json:"client_id"`
+	WalletID       uuid.UUID  `gorm:"type:uuid;not null" json:"wallet_id"`
+	CustomerPhone  string     `gorm:"type:text;not null" json:"customer_phone"`
+	Type           string     `gorm:"type:text;not null" json:"type"`
+	Status         string     `gorm:"type:text;not null;default:'completed'" json:"status"`
+	Amount         float64    `gorm:"type:decimal(12,2);not null" json:"amount"` // Credits positive, debits negative
+	BalanceAfter   *float64   `gorm:"type:decimal(12,2)" json:"balance_after,omitempty"`
+	OrderID        *uuid.UUID `gorm:"type:uuid" json:"order_id,omitempty"`
+	Reference      *string    `gorm:"type:text;unique" json:"reference,omitempty"` // Gateway order ID of a top-up
+	PaymentGateway string     `gorm:"type:text" json:"payment_gateway,omitempty"`
+	PaymentLink    string     `gorm:"type:text" json:"payment_link,omitempty"`
+	TransactionID  string     `gorm:"type:text" json:"transaction_id,omitempty"`
+	Note           string     `gorm:"type:text" json:"note,omitempty"`
+	Actor          string     `gorm:"type:text" json:"actor,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (WalletTransaction) TableName() string {
+	return "saas_wallet_transactions"
+}
+
+// BeforeCreate sets UUID before creating
+func (t *WalletTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+// WalletTopUpRequest starts a top-up of a customer's wallet
+type WalletTopUpRequest struct {
+	CustomerPhone string  `json:"customer_phone"`
+	CustomerName  string  `json:"customer_name,omitempty"`
+	Amount        float64 `json:"amount"`
+}
+
+// WalletAdjustmentRequest corrects a customer's balance by hand
+type WalletAdjustmentRequest struct {
+	Amount float64 `json:"amount"` // Positive adds credit, negative removes it
+	Reason string  `json:"reason"`
+	Actor  string  `json:"actor,omitempty"` // Who made the adjustment, defaults to the authenticated user
+}
+
+// WalletStatement is a wallet with its latest ledger entries
+type WalletStatement struct {
+	Wallet       *CustomerWallet     `json:"wallet"`
+	Transactions []WalletTransaction `json:"transactions"`
+}
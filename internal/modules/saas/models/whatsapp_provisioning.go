@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// WhatsAppProvisioning tracks automated WAHA setup for a tenant
+type WhatsAppProvisioning struct {
+	ID       uuid.UUID `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	ClientID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"client_id"`
+
+	// Session
+	SessionID        string     `gorm:"type:text;not null" json:"session_id"`
+	SessionCreatedAt *time.Time `json:"session_created_at,omitempty"`
+
+	// Webhook
+	WebhookToken        string     `gorm:"type:text;not null;uniqueIndex" json:"-"`
+	WebhookSecret       string     `gorm:"type:text;not null" json:"-"`
+	WebhookURL          string     `gorm:"type:text" json:"webhook_url"`
+	WebhookConfiguredAt *time.Time `json:"webhook_configured_at,omitempty"`
+
+	// Self-test
+	SelfTestNonce      string     `gorm:"type:text" json:"-"`
+	SelfTestSentAt     *time.Time `json:"self_test_sent_at,omitempty"`
+	SelfTestVerifiedAt *time.Time `json:"self_test_verified_at,omitempty"`
+
+	LastError string `gorm:"type:text" json:"last_error,omitempty"`
+
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// TableName specifies the table name
+func (WhatsAppProvisioning) TableName() string {
+	return "saas_whatsapp_provisioning"
+}
+
+// BeforeCreate sets UUID before creating
+func (p *WhatsAppProvisioning) BeforeCreate(tx *gorm.DB) error {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return nil
+}
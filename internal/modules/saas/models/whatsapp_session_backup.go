@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// WhatsAppSessionBackup is a snapshot of the WAHA sessions, kept to restore them after the WAHA container is replaced
+type WhatsAppSessionBackup struct {
+	ID           uuid.UUID      `gorm:"type:uuid;primary_key;default:gen_random_uuid()" json:"id"`
+	Provider     string         `gorm:"type:varchar(20);not null" json:"provider"`
+	SessionCount int            `gorm:"not null;default:0" json:"session_count"`
+	WorkingCount int            `gorm:"not null;default:0" json:"working_count"`
+	Snapshot     datatypes.JSON `gorm:"type:jsonb;not null" json:"-"` // whatsapp.SessionBackup, holds the webhook HMAC keys
+	FileURL      string         `gorm:"type:text;not null;default:''" json:"file_url,omitempty"`
+	FilePublicID string         `gorm:"type:text;not null;default:''" json:"-"`
+	StorageError string         `gorm:"type:text;not null;default:''" json:"storage_error,omitempty"`
+	CreatedAt    time.Time      `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// TableName specifies the table name
+func (WhatsAppSessionBackup) TableName() string {
+	return "saas_whatsapp_session_backups"
+}
+
+// BeforeCreate sets UUID before creating
+func (b *WhatsAppSessionBackup) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
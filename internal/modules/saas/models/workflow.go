@@ -13,13 +13,16 @@ type Workflow struct {
 	ClientID      uuid.UUID      `json:"client_id" gorm:"type:uuid;not null;index"`
 	Name          string         `json:"name" gorm:"type:varchar(255);not null"`
 	Description   string         `json:"description" gorm:"type:text"`
-	TriggerType   string         `json:"trigger_type" gorm:"type:varchar(50);not null;index"` // 'event', 'scheduled', 'manual'
+	TriggerType   string         `json:"trigger_type" gorm:"type:varchar(50);not null;index"` // 'event', 'scheduled', 'manual', 'message_received', 'webhook'
 	TriggerConfig datatypes.JSON `json:"trigger_config" gorm:"type:jsonb;not null;default:'{}'"`
 	Conditions    datatypes.JSON `json:"conditions" gorm:"type:jsonb;default:'[]'"`
 	Actions       datatypes.JSON `json:"actions" gorm:"type:jsonb;not null;default:'[]'"`
 	IsActive      bool           `json:"is_active" gorm:"default:true;index"`
+	WebhookToken  string         `json:"webhook_token,omitempty" gorm:"type:text"` // Secret of the inbound webhook of webhook triggered workflows
 	CreatedAt     time.Time      `json:"created_at" gorm:"autoCreateTime;index:,sort:desc"`
 	UpdatedAt     time.Time      `json:"updated_at" gorm:"autoUpdateTime"`
+
+	NextRunAt *time.Time `json:"next_run_at,omitempty" gorm:"-"` // Computed from the scheduler for scheduled workflows
 }
 
 // TableName specifies the table name for Workflow
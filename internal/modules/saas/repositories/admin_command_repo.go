@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AdminCommandRepo interface {
+	GetPending(clientID uuid.UUID, adminPhone string) (*models.AdminPendingCommand, error)
+	SavePending(command *models.AdminPendingCommand) error
+	DeletePending(id uuid.UUID) (bool, error)
+}
+
+type adminCommandRepo struct {
+	db *gorm.DB
+}
+
+func NewAdminCommandRepo(db *gorm.DB) AdminCommandRepo {
+	return &adminCommandRepo{db: db}
+}
+
+func (r *adminCommandRepo) GetPending(clientID uuid.UUID, adminPhone string) (*models.AdminPendingCommand, error) {
+	var command models.AdminPendingCommand
+	err := r.db.Where("client_id = ? AND admin_phone = ?", clientID, adminPhone).First(&command).Error
+	return &command, err
+}
+
+// SavePending stores a command awaiting confirmation, replacing the admin's previous one
+func (r *adminCommandRepo) SavePending(command *models.AdminPendingCommand) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "admin_phone"}},
+		DoUpdates: clause.AssignmentColumns([]string{"id", "role", "command", "message", "params", "expires_at", "created_at"}),
+	}).Create(command).Error
+}
+
+// DeletePending removes a pending command; false means it was already taken (e.g. a double YA)
+func (r *adminCommandRepo) DeletePending(id uuid.UUID) (bool, error) {
+	result := r.db.Where("id = ?", id).Delete(&models.AdminPendingCommand{})
+	return result.RowsAffected > 0, result.Error
+}
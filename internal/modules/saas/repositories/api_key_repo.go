@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepo interface {
+	Create(key *models.APIKey) error
+	Update(key *models.APIKey) error
+	GetByRef(clientID uuid.UUID, ref string) (*models.APIKey, error)
+	GetActiveByHash(keyHash string) (*models.APIKey, error)
+	ListByClient(clientID uuid.UUID) ([]models.APIKey, error)
+	RevokeAll(clientID uuid.UUID, now time.Time) (int64, error)
+	TouchLastUsed(id uuid.UUID, now time.Time) error
+}
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) APIKeyRepo {
+	return &apiKeyRepo{db: db}
+}
+
+func (r *apiKeyRepo) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepo) Update(key *models.APIKey) error {
+	return r.db.Save(key).Error
+}
+
+func (r *apiKeyRepo) GetByRef(clientID uuid.UUID, ref string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("client_id = ? AND external_ref = ?", clientID, ref).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetActiveByHash finds an unrevoked key by the hash of its value
+func (r *apiKeyRepo) GetActiveByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ? AND revoked_at IS NULL", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepo) ListByClient(clientID uuid.UUID) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Where("client_id = ?", clientID).Order("created_at ASC").Find(&keys).Error
+	return keys, err
+}
+
+// RevokeAll revokes every active key of a client
+func (r *apiKeyRepo) RevokeAll(clientID uuid.UUID, now time.Time) (int64, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("client_id = ? AND revoked_at IS NULL", clientID).
+		Update("revoked_at", now)
+	return result.RowsAffected, result.Error
+}
+
+func (r *apiKeyRepo) TouchLastUsed(id uuid.UUID, now time.Time) error {
+	return r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", now).Error
+}
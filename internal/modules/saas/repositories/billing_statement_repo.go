@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BillingStatementRepo interface {
+	Create(statement *models.BillingStatement) error
+	Update(statement *models.BillingStatement) error
+	GetByID(id uuid.UUID) (*models.BillingStatement, error)
+	GetByNumber(number string) (*models.BillingStatement, error)
+	GetByPeriod(clientID uuid.UUID, periodStart time.Time) (*models.BillingStatement, error)
+	List(filter models.StatementFilter) ([]models.BillingStatement, error)
+	MarkPaid(id uuid.UUID, paymentMethod, transactionID string, paidAt time.Time) (bool, error)
+	ListUnpaidIssuedBefore(before time.Time) ([]models.BillingStatement, error)
+}
+
+type billingStatementRepo struct {
+	db *gorm.DB
+}
+
+func NewBillingStatementRepo(db *gorm.DB) BillingStatementRepo {
+	return &billingStatementRepo{db: db}
+}
+
+func (r *billingStatementRepo) Create(statement *models.BillingStatement) error {
+	return r.db.Create(statement).Error
+}
+
+func (r *billingStatementRepo) Update(statement *models.BillingStatement) error {
+	return r.db.Save(statement).Error
+}
+
+func (r *billingStatementRepo) GetByID(id uuid.UUID) (*models.BillingStatement, error) {
+	var statement models.BillingStatement
+	if err := r.db.Where("id = ?", id).First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *billingStatementRepo) GetByNumber(number string) (*models.BillingStatement, error) {
+	var statement models.BillingStatement
+	if err := r.db.Where("number = ?", number).First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+func (r *billingStatementRepo) GetByPeriod(clientID uuid.UUID, periodStart time.Time) (*models.BillingStatement, error) {
+	var statement models.BillingStatement
+	if err := r.db.Where("client_id = ? AND period_start = ?", clientID, periodStart).First(&statement).Error; err != nil {
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// List returns statements newest period first
+func (r *billingStatementRepo) List(filter models.StatementFilter) ([]models.BillingStatement, error) {
+	query := r.db.Model(&models.BillingStatement{})
+	if filter.ClientID != nil {
+		query = query.Where("client_id = ?", *filter.ClientID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var statements []models.BillingStatement
+	err := query.Order("period_start DESC, created_at DESC").Limit(filter.Limit).Find(&statements).Error
+	return statements, err
+}
+
+// MarkPaid settles an unpaid statement, returning false when it was already paid or voided
+func (r *billingStatementRepo) MarkPaid(id uuid.UUID, paymentMethod, transactionID string, paidAt time.Time) (bool, error) {
+	result := r.db.Model(&models.BillingStatement{}).
+		Where("id = ? AND status = ?", id, models.StatementUnpaid).
+		Updates(map[string]interface{}{
+			"status":         models.StatementPaid,
+			"payment_method": paymentMethod,
+			"transaction_id": transactionID,
+			"paid_at":        paidAt,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListUnpaidIssuedBefore returns the unpaid statements with something to pay issued before a time, oldest first
+func (r *billingStatementRepo) ListUnpaidIssuedBefore(before time.Time) ([]models.BillingStatement, error) {
+	var statements []models.BillingStatement
+	err := r.db.Where("status = ? AND total > 0 AND created_at < ?", models.StatementUnpaid, before).
+		Order("created_at ASC").
+		Find(&statements).Error
+	return statements, err
+}
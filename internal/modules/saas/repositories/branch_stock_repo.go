@@ -0,0 +1,83 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BranchStockRepo interface {
+	Get(branchID, productID uuid.UUID) (*models.BranchStock, error)
+	ListByBranch(branchID uuid.UUID) ([]models.BranchStock, error)
+	ListByProduct(productID uuid.UUID) ([]models.BranchStock, error)
+	Upsert(stock *models.BranchStock) error
+	IsTracked(productID uuid.UUID) (bool, error)
+	Deduct(branchID, productID uuid.UUID, quantity int) (bool, error)
+	Restore(branchID, productID uuid.UUID, quantity int) error
+	Transaction(fn func(repo BranchStockRepo) error) error
+}
+
+type branchStockRepo struct {
+	db *gorm.DB
+}
+
+func NewBranchStockRepo(db *gorm.DB) BranchStockRepo {
+	return &branchStockRepo{db: db}
+}
+
+func (r *branchStockRepo) Get(branchID, productID uuid.UUID) (*models.BranchStock, error) {
+	var stock models.BranchStock
+	err := r.db.Where("branch_id = ? AND product_id = ?", branchID, productID).First(&stock).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stock, nil
+}
+
+func (r *branchStockRepo) ListByBranch(branchID uuid.UUID) ([]models.BranchStock, error) {
+	var stocks []models.BranchStock
+	err := r.db.Where("branch_id = ?", branchID).Order("updated_at DESC").Find(&stocks).Error
+	return stocks, err
+}
+
+func (r *branchStockRepo) ListByProduct(productID uuid.UUID) ([]models.BranchStock, error) {
+	var stocks []models.BranchStock
+	err := r.db.Where("product_id = ?", productID).Find(&stocks).Error
+	return stocks, err
+}
+
+// Upsert sets the stock of a product at a branch
+func (r *branchStockRepo) Upsert(stock *models.BranchStock) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "branch_id"}, {Name: "product_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"stock", "updated_at"}),
+	}).Create(stock).Error
+}
+
+// IsTracked reports whether a product has stock rows at any branch
+func (r *branchStockRepo) IsTracked(productID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.BranchStock{}).Where("product_id = ?", productID).Count(&count).Error
+	return count > 0, err
+}
+
+// Deduct atomically reduces branch stock, returning false if there isn't enough
+func (r *branchStockRepo) Deduct(branchID, productID uuid.UUID, quantity int) (bool, error) {
+	result := r.db.Model(&models.BranchStock{}).
+		Where("branch_id = ? AND product_id = ? AND stock >= ?", branchID, productID, quantity).
+		UpdateColumn("stock", gorm.Expr("stock - ?", quantity))
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *branchStockRepo) Restore(branchID, productID uuid.UUID, quantity int) error {
+	return r.db.Model(&models.BranchStock{}).
+		Where("branch_id = ? AND product_id = ?", branchID, productID).
+		UpdateColumn("stock", gorm.Expr("stock + ?", quantity)).Error
+}
+
+func (r *branchStockRepo) Transaction(fn func(repo BranchStockRepo) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&branchStockRepo{db: tx})
+	})
+}
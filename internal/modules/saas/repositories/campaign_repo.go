@@ -0,0 +1,251 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CampaignRepo interface {
+	Create(campaign *models.Campaign) error
+	Update(campaign *models.Campaign) error
+	GetByID(clientID, id uuid.UUID) (*models.Campaign, error)
+	GetStatus(id uuid.UUID) (string, error)
+	SaveProgress(campaign *models.Campaign) error
+	Finish(campaign *models.Campaign) error
+	List(clientID uuid.UUID, status string, limit int) ([]models.Campaign, error)
+	ClaimDue(now, staleBefore time.Time, limit int) ([]models.Campaign, error)
+	CancelActive(clientID uuid.UUID) (int64, error)
+
+	AudienceAll(clientID uuid.UUID) ([]string, error)
+	AudienceRecentBuyers(clientID uuid.UUID, since time.Time) ([]string, error)
+
+	AddRecipients(recipients []models.CampaignRecipient) error
+	ListRecipients(campaignID uuid.UUID, status string, limit int) ([]models.CampaignRecipient, error)
+	UpdateRecipient(recipient *models.CampaignRecipient) error
+	SkipPending(campaignID uuid.UUID) (int64, error)
+	CountRecipients(campaignID uuid.UUID) (map[string]int64, error)
+	ApplyAck(clientID *uuid.UUID, ackMessageID, status string, at time.Time) (int64, error)
+}
+
+type campaignRepo struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepo(db *gorm.DB) CampaignRepo {
+	return &campaignRepo{db: db}
+}
+
+func (r *campaignRepo) Create(campaign *models.Campaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *campaignRepo) Update(campaign *models.Campaign) error {
+	return r.db.Save(campaign).Error
+}
+
+func (r *campaignRepo) GetByID(clientID, id uuid.UUID) (*models.Campaign, error) {
+	var campaign models.Campaign
+	if err := r.db.Where("id = ? AND client_id = ?", id, clientID).First(&campaign).Error; err != nil {
+		return nil, err
+	}
+	return &campaign, nil
+}
+
+// GetStatus reads a campaign's status, for a sender checking whether it was cancelled
+func (r *campaignRepo) GetStatus(id uuid.UUID) (string, error) {
+	var status string
+	err := r.db.Model(&models.Campaign{}).Where("id = ?", id).Pluck("status", &status).Error
+	return status, err
+}
+
+// SaveProgress stores the counts of a campaign being sent, leaving its status alone so a cancellation isn't overwritten
+func (r *campaignRepo) SaveProgress(campaign *models.Campaign) error {
+	campaign.UpdatedAt = time.Now()
+	return r.db.Model(&models.Campaign{}).Where("id = ?", campaign.ID).Updates(map[string]interface{}{
+		"total_recipients": campaign.TotalRecipients,
+		"sent_count":       campaign.SentCount,
+		"failed_count":     campaign.FailedCount,
+		"updated_at":       campaign.UpdatedAt,
+	}).Error
+}
+
+// Finish records the final status of a campaign that is still sending
+func (r *campaignRepo) Finish(campaign *models.Campaign) error {
+	campaign.UpdatedAt = time.Now()
+	return r.db.Model(&models.Campaign{}).Where("id = ? AND status = ?", campaign.ID, models.CampaignStatusSending).Updates(map[string]interface{}{
+		"status":           campaign.Status,
+		"error":            campaign.Error,
+		"completed_at":     campaign.CompletedAt,
+		"total_recipients": campaign.TotalRecipients,
+		"sent_count":       campaign.SentCount,
+		"failed_count":     campaign.FailedCount,
+		"updated_at":       campaign.UpdatedAt,
+	}).Error
+}
+
+// List returns a client's campaigns newest first, optionally of one status
+func (r *campaignRepo) List(clientID uuid.UUID, status string, limit int) ([]models.Campaign, error) {
+	var campaigns []models.Campaign
+	query := r.db.Where("client_id = ?", clientID).Order("created_at DESC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&campaigns).Error
+	return campaigns, err
+}
+
+// ClaimDue moves scheduled campaigns that came due to sending, and takes over sending campaigns whose
+// sender stopped updating them before staleBefore (e.g. a restart). Each campaign is claimed by one worker.
+func (r *campaignRepo) ClaimDue(now, staleBefore time.Time, limit int) ([]models.Campaign, error) {
+	var candidates []models.Campaign
+	err := r.db.Where("(status = ? AND scheduled_at <= ?) OR (status = ? AND updated_at < ?)",
+		models.CampaignStatusScheduled, now, models.CampaignStatusSending, staleBefore).
+		Order("scheduled_at ASC").
+		Limit(limit).
+		Find(&candidates).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []models.Campaign
+	for _, campaign := range candidates {
+		result := r.db.Model(&models.Campaign{}).
+			Where("id = ? AND status = ? AND updated_at = ?", campaign.ID, campaign.Status, campaign.UpdatedAt).
+			Updates(map[string]interface{}{
+				"status":     models.CampaignStatusSending,
+				"started_at": gorm.Expr("COALESCE(started_at, ?)", now),
+				"updated_at": now,
+			})
+		if result.Error != nil {
+			return claimed, result.Error
+		}
+		if result.RowsAffected == 0 {
+			continue
+		}
+		campaign.Status = models.CampaignStatusSending
+		campaign.UpdatedAt = now
+		if campaign.StartedAt == nil {
+			campaign.StartedAt = &now
+		}
+		claimed = append(claimed, campaign)
+	}
+	return claimed, nil
+}
+
+// CancelActive cancels a client's scheduled and sending campaigns
+func (r *campaignRepo) CancelActive(clientID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Campaign{}).
+		Where("client_id = ? AND status IN ?", clientID, []string{models.CampaignStatusScheduled, models.CampaignStatusSending}).
+		Update("status", models.CampaignStatusCancelled)
+	return result.RowsAffected, result.Error
+}
+
+// AudienceAll returns every customer who chatted with the client or ordered (sandbox orders excluded)
+func (r *campaignRepo) AudienceAll(clientID uuid.UUID) ([]string, error) {
+	var phones []string
+	err := r.db.Raw(`
+		SELECT customer_phone FROM saas_conversations WHERE client_id = ? AND customer_phone <> ''
+		UNION
+		SELECT customer_phone FROM saas_orders WHERE client_id = ? AND customer_phone <> '' AND is_test = FALSE
+		ORDER BY customer_phone`, clientID, clientID).
+		Scan(&phones).Error
+	return phones, err
+}
+
+// AudienceRecentBuyers returns the customers who placed an order since the given time (sandbox orders excluded)
+func (r *campaignRepo) AudienceRecentBuyers(clientID uuid.UUID, since time.Time) ([]string, error) {
+	var phones []string
+	err := r.db.Model(&models.Order{}).
+		Distinct("customer_phone").
+		Where("client_id = ? AND created_at >= ? AND customer_phone <> '' AND is_test = FALSE", clientID, since).
+		Order("customer_phone").
+		Pluck("customer_phone", &phones).Error
+	return phones, err
+}
+
+// AddRecipients stores a campaign's recipients; customers already on it are kept as they are
+func (r *campaignRepo) AddRecipients(recipients []models.CampaignRecipient) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "campaign_id"}, {Name: "customer_phone"}},
+		DoNothing: true,
+	}).CreateInBatches(&recipients, 500).Error
+}
+
+// ListRecipients returns a campaign's recipients in the order they are sent, optionally of one status
+func (r *campaignRepo) ListRecipients(campaignID uuid.UUID, status string, limit int) ([]models.CampaignRecipient, error) {
+	var recipients []models.CampaignRecipient
+	query := r.db.Where("campaign_id = ?", campaignID).Order("created_at ASC, customer_phone ASC").Limit(limit)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&recipients).Error
+	return recipients, err
+}
+
+func (r *campaignRepo) UpdateRecipient(recipient *models.CampaignRecipient) error {
+	return r.db.Save(recipient).Error
+}
+
+// SkipPending marks the recipients a cancelled campaign did not reach
+func (r *campaignRepo) SkipPending(campaignID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.CampaignRecipient{}).
+		Where("campaign_id = ? AND status = ?", campaignID, models.CampaignRecipientPending).
+		Update("status", models.CampaignRecipientSkipped)
+	return result.RowsAffected, result.Error
+}
+
+// CountRecipients counts a campaign's recipients per status
+func (r *campaignRepo) CountRecipients(campaignID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&models.CampaignRecipient{}).
+		Select("status, COUNT(*) AS count").
+		Where("campaign_id = ?", campaignID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// ApplyAck records a delivery ack on the recipient whose message it is, among the client's recipients when
+// clientID is set. Statuses only move forward (sent -> delivered -> read).
+func (r *campaignRepo) ApplyAck(clientID *uuid.UUID, ackMessageID, status string, at time.Time) (int64, error) {
+	query := r.db.Model(&models.CampaignRecipient{}).Where("ack_message_id = ?", ackMessageID)
+	if clientID != nil {
+		query = query.Where("client_id = ?", *clientID)
+	}
+
+	var updates map[string]interface{}
+	switch status {
+	case models.CampaignRecipientDelivered:
+		query = query.Where("status = ?", models.CampaignRecipientSent)
+		updates = map[string]interface{}{"status": status, "delivered_at": at}
+	case models.CampaignRecipientRead:
+		query = query.Where("status IN ?", []string{models.CampaignRecipientSent, models.CampaignRecipientDelivered})
+		updates = map[string]interface{}{"status": status, "read_at": at, "delivered_at": gorm.Expr("COALESCE(delivered_at, ?)", at)}
+	case models.CampaignRecipientFailed:
+		query = query.Where("status = ?", models.CampaignRecipientSent)
+		updates = map[string]interface{}{"status": status, "error": "WhatsApp reported a delivery error"}
+	default:
+		return 0, nil
+	}
+
+	result := query.Updates(updates)
+	return result.RowsAffected, result.Error
+}
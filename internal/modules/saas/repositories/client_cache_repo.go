@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/cache"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// clientCachePrefix is followed by the client ID
+const clientCachePrefix = "client:"
+
+// cachedClientRepo serves GetByID from Redis; every other method goes to the database
+type cachedClientRepo struct {
+	ClientRepo
+	cache *cache.Cache
+}
+
+// NewCachedClientRepo caches GetByID of repo in Redis. Writes to clients seen through db drop the
+// written client's copy. A nil cache returns repo as is.
+func NewCachedClientRepo(repo ClientRepo, db *gorm.DB, c *cache.Cache) (ClientRepo, error) {
+	if !c.Enabled() {
+		return repo, nil
+	}
+
+	invalidate := func(ctx context.Context, clientIDs []string) {
+		if clientIDs == nil {
+			c.DeletePrefix(ctx, clientCachePrefix)
+			return
+		}
+		keys := make([]string, len(clientIDs))
+		for i, clientID := range clientIDs {
+			keys[i] = clientCachePrefix + clientID
+		}
+		c.Delete(ctx, keys...)
+	}
+	if err := c.OnWrite(db, "client", models.Client{}.TableName(), "id", invalidate); err != nil {
+		return nil, err
+	}
+
+	return &cachedClientRepo{ClientRepo: repo, cache: c}, nil
+}
+
+func (r *cachedClientRepo) GetByID(id string) (*models.Client, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+	cacheKey := clientCachePrefix + uid.String()
+	var client models.Client
+	if r.cache.Get(ctx, cacheKey, &client) {
+		return &client, nil
+	}
+
+	found, err := r.ClientRepo.GetByID(id)
+	if err != nil {
+		return found, err
+	}
+	r.cache.Set(ctx, cacheKey, found)
+	return found, nil
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ClientOffboardingRepo interface {
+	Create(offboarding *models.ClientOffboarding) error
+	Update(offboarding *models.ClientOffboarding) error
+	GetByID(id uuid.UUID) (*models.ClientOffboarding, error)
+	GetLatestByClient(clientID uuid.UUID) (*models.ClientOffboarding, error)
+	ListOpen(maxAttempts int) ([]models.ClientOffboarding, error)
+	SuspendCompanyUsers(clientID uuid.UUID) (int64, error)
+}
+
+type clientOffboardingRepo struct {
+	db *gorm.DB
+}
+
+func NewClientOffboardingRepo(db *gorm.DB) ClientOffboardingRepo {
+	return &clientOffboardingRepo{db: db}
+}
+
+func (r *clientOffboardingRepo) Create(offboarding *models.ClientOffboarding) error {
+	return r.db.Create(offboarding).Error
+}
+
+func (r *clientOffboardingRepo) Update(offboarding *models.ClientOffboarding) error {
+	return r.db.Save(offboarding).Error
+}
+
+func (r *clientOffboardingRepo) GetByID(id uuid.UUID) (*models.ClientOffboarding, error) {
+	var offboarding models.ClientOffboarding
+	if err := r.db.Where("id = ?", id).First(&offboarding).Error; err != nil {
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+// GetLatestByClient returns the most recent offboarding run of a client
+func (r *clientOffboardingRepo) GetLatestByClient(clientID uuid.UUID) (*models.ClientOffboarding, error) {
+	var offboarding models.ClientOffboarding
+	err := r.db.Where("client_id = ?", clientID).
+		Order("created_at DESC").
+		First(&offboarding).Error
+	if err != nil {
+		return nil, err
+	}
+	return &offboarding, nil
+}
+
+// ListOpen returns runs that still have steps to finish and haven't exhausted their retries
+func (r *clientOffboardingRepo) ListOpen(maxAttempts int) ([]models.ClientOffboarding, error) {
+	var offboardings []models.ClientOffboarding
+	err := r.db.Where("status IN ? AND attempts < ?",
+		[]string{models.OffboardingStatusPending, models.OffboardingStatusRunning, models.OffboardingStatusFailed}, maxAttempts).
+		Order("created_at ASC").
+		Find(&offboardings).Error
+	return offboardings, err
+}
+
+// SuspendCompanyUsers disables CMS logins of a client and revokes their refresh tokens
+func (r *clientOffboardingRepo) SuspendCompanyUsers(clientID uuid.UUID) (int64, error) {
+	result := r.db.Table("company_users").
+		Where("client_id = ? AND is_active = ?", clientID, true).
+		Updates(map[string]interface{}{
+			"is_active":                false,
+			"refresh_token":            "",
+			"refresh_token_expires_at": nil,
+		})
+	return result.RowsAffected, result.Error
+}
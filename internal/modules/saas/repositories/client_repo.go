@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -11,9 +13,14 @@ type ClientRepo interface {
 	GetByID(id string) (*models.Client, error)
 	GetByWhatsAppNumber(whatsappNumber string) (*models.Client, error)
 	GetClientByWhatsAppSession(sessionID string) (*models.Client, error)
+	GetByExternalRef(ref string) (*models.Client, error)
 	Create(client *models.Client) error
 	Update(client *models.Client) error
 	Delete(id string) error
+	UpdateBotPause(client *models.Client) error
+	UpdateSubscriptionStatus(id uuid.UUID, status string) error
+	UpdateSubscriptionPlan(id uuid.UUID, plan string) error
+	ListBotResumeDue(now time.Time) ([]models.Client, error)
 }
 
 type clientRepo struct {
@@ -56,6 +63,15 @@ func (r *clientRepo) GetClientByWhatsAppSession(sessionID string) (*models.Clien
 	return &client, err
 }
 
+// GetByExternalRef finds a client by its provisioning reference, whatever its subscription status
+func (r *clientRepo) GetByExternalRef(ref string) (*models.Client, error) {
+	var client models.Client
+	if err := r.db.Where("external_ref = ?", ref).First(&client).Error; err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
+
 func (r *clientRepo) Create(client *models.Client) error {
 	return r.db.Create(client).Error
 }
@@ -71,3 +87,31 @@ func (r *clientRepo) Delete(id string) error {
 	}
 	return r.db.Delete(&models.Client{}, "id = ?", uid).Error
 }
+
+// UpdateBotPause saves only the vacation mode columns
+func (r *clientRepo) UpdateBotPause(client *models.Client) error {
+	return r.db.Model(&models.Client{}).Where("id = ?", client.ID).Updates(map[string]interface{}{
+		"bot_paused":    client.BotPaused,
+		"bot_paused_at": client.BotPausedAt,
+		"bot_paused_by": client.BotPausedBy,
+		"bot_resume_at": client.BotResumeAt,
+	}).Error
+}
+
+// UpdateSubscriptionStatus sets the subscription status only; anything but "active" hides the client from lookups
+func (r *clientRepo) UpdateSubscriptionStatus(id uuid.UUID, status string) error {
+	return r.db.Model(&models.Client{}).Where("id = ?", id).Update("subscription_status", status).Error
+}
+
+// UpdateSubscriptionPlan sets the subscription plan only
+func (r *clientRepo) UpdateSubscriptionPlan(id uuid.UUID, plan string) error {
+	return r.db.Model(&models.Client{}).Where("id = ?", id).Update("subscription_plan", plan).Error
+}
+
+// ListBotResumeDue returns paused clients whose scheduled resume time has passed
+func (r *clientRepo) ListBotResumeDue(now time.Time) ([]models.Client, error) {
+	var clients []models.Client
+	err := r.db.Where("bot_paused = ? AND bot_resume_at IS NOT NULL AND bot_resume_at <= ?", true, now).
+		Find(&clients).Error
+	return clients, err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CODSettingsRepo interface {
+	GetByClientID(clientID string) (*models.CODSettings, error)
+	Upsert(settings *models.CODSettings) error
+}
+
+type codSettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewCODSettingsRepo(db *gorm.DB) CODSettingsRepo {
+	return &codSettingsRepo{db: db}
+}
+
+func (r *codSettingsRepo) GetByClientID(clientID string) (*models.CODSettings, error) {
+	var settings models.CODSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *codSettingsRepo) Upsert(settings *models.CODSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "max_order_amount", "min_paid_orders", "max_open_orders", "max_failed_orders", "updated_at",
+		}),
+	}).Create(settings).Error
+}
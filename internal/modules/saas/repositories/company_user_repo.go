@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// CompanyUserRepo manages CMS users of a client for provisioning
+type CompanyUserRepo interface {
+	GetByRef(clientID uuid.UUID, ref string) (*auth.CompanyUser, error)
+	EmailInUse(email string, exceptID uuid.UUID) (bool, error)
+	ListAdminEmails(clientID uuid.UUID) ([]string, error)
+	Create(user *auth.CompanyUser) error
+	Update(user *auth.CompanyUser) error
+}
+
+type companyUserRepo struct {
+	db *gorm.DB
+}
+
+func NewCompanyUserRepo(db *gorm.DB) CompanyUserRepo {
+	return &companyUserRepo{db: db}
+}
+
+func (r *companyUserRepo) GetByRef(clientID uuid.UUID, ref string) (*auth.CompanyUser, error) {
+	var user auth.CompanyUser
+	if err := r.db.Where("client_id = ? AND external_ref = ?", clientID, ref).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// EmailInUse reports whether another user already logs in with the email
+func (r *companyUserRepo) EmailInUse(email string, exceptID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&auth.CompanyUser{}).
+		Where("LOWER(email) = LOWER(?) AND id <> ?", email, exceptID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListAdminEmails returns the email addresses of the client's active tenant admins
+func (r *companyUserRepo) ListAdminEmails(clientID uuid.UUID) ([]string, error) {
+	var emails []string
+	err := r.db.Model(&auth.CompanyUser{}).
+		Where("client_id = ? AND role = ? AND is_active = ? AND email <> ''", clientID, "admin_tenant", true).
+		Order("created_at").
+		Pluck("email", &emails).Error
+	return emails, err
+}
+
+// Create inserts a user; google_id stays NULL so its unique index only applies to Google logins
+func (r *companyUserRepo) Create(user *auth.CompanyUser) error {
+	return r.db.Omit("GoogleID").Create(user).Error
+}
+
+func (r *companyUserRepo) Update(user *auth.CompanyUser) error {
+	return r.db.Omit("GoogleID").Save(user).Error
+}
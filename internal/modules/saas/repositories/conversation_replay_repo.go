@@ -0,0 +1,45 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ConversationReplayRepo interface {
+	Create(replay *models.ConversationReplay) error
+	Update(replay *models.ConversationReplay) error
+	GetByID(id uuid.UUID) (*models.ConversationReplay, error)
+	ListByClient(clientID uuid.UUID, limit int) ([]models.ConversationReplay, error)
+}
+
+type conversationReplayRepo struct {
+	db *gorm.DB
+}
+
+func NewConversationReplayRepo(db *gorm.DB) ConversationReplayRepo {
+	return &conversationReplayRepo{db: db}
+}
+
+func (r *conversationReplayRepo) Create(replay *models.ConversationReplay) error {
+	return r.db.Create(replay).Error
+}
+
+func (r *conversationReplayRepo) Update(replay *models.ConversationReplay) error {
+	return r.db.Save(replay).Error
+}
+
+func (r *conversationReplayRepo) GetByID(id uuid.UUID) (*models.ConversationReplay, error) {
+	var replay models.ConversationReplay
+	if err := r.db.Where("id = ?", id).First(&replay).Error; err != nil {
+		return nil, err
+	}
+	return &replay, nil
+}
+
+// ListByClient returns a client's replays newest first, without their per-turn answers
+func (r *conversationReplayRepo) ListByClient(clientID uuid.UUID, limit int) ([]models.ConversationReplay, error) {
+	var replays []models.ConversationReplay
+	err := r.db.Omit("turns").Where("client_id = ?", clientID).Order("created_at DESC").Limit(limit).Find(&replays).Error
+	return replays, err
+}
@@ -1,6 +1,9 @@
 package repositories
 
 import (
+	"strings"
+	"time"
+
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -8,7 +11,17 @@ import (
 
 type ConversationRepo interface {
 	LogConversation(clientID, customerPhone, message, response string) error
+	LogTurn(conversation *models.Conversation) error
 	GetByClientID(clientID string, limit int) ([]models.Conversation, error)
+	GetByID(id string) (*models.Conversation, error)
+	SetRating(id string, rating int) error
+	HasCustomerConversations(clientID, customerPhone string) (bool, error)
+	GetLatestForCustomer(clientID, customerPhone string) (*models.Conversation, error)
+	ListRecentForCustomer(clientID, customerPhone string, since, before time.Time, limit int) ([]models.Conversation, error)
+	GetByMessageID(clientID, messageID string) (*models.Conversation, error)
+	CountByLanguage(clientID string, start, end time.Time) ([]models.LanguageCount, error)
+	ListForCustomer(clientID uuid.UUID, customerPhone string, start, end *time.Time) ([]models.Conversation, error)
+	ListAnswered(clientID uuid.UUID, customerPhone string, start, end time.Time, limit int) ([]models.Conversation, error)
 }
 
 type conversationRepo struct {
@@ -27,25 +40,36 @@ func (r *conversationRepo) LogConversation(clientID, customerPhone, message, res
 	}
 
 	// Create conversation record
-	conversation := models.Conversation{
+	return r.LogTurn(&models.Conversation{
 		ClientID:      uid,
 		CustomerPhone: customerPhone,
-		MessageType:   "incoming",
 		MessageText:   message,
 		AIResponse:    response,
+	})
+}
+
+// LogTurn logs a conversation turn with its detected language and provider message IDs
+func (r *conversationRepo) LogTurn(conversation *models.Conversation) error {
+	if conversation.MessageType == "" {
+		conversation.MessageType = models.ConversationTypeIncoming
 	}
 
-	if err := r.db.Create(&conversation).Error; err != nil {
+	if err := r.db.Create(conversation).Error; err != nil {
 		return err
 	}
 
+	// Agent replies don't use bot credits
+	if conversation.MessageType == models.ConversationTypeAgent {
+		return nil
+	}
+
 	// Update credits (best effort) - using raw SQL for complex date logic
 	r.db.Exec(`
 		UPDATE saas_credits
 		SET credits_used = credits_used + 1
 		WHERE client_id = ?
 		AND CURRENT_DATE BETWEEN period_start AND period_end
-	`, uid)
+	`, conversation.ClientID)
 
 	return nil
 }
@@ -64,3 +88,114 @@ func (r *conversationRepo) GetByClientID(clientID string, limit int) ([]models.C
 
 	return conversations, err
 }
+
+func (r *conversationRepo) GetByID(id string) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.Where("id = ?", id).First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// SetRating stores the 1-5 rating of the bot's answer
+func (r *conversationRepo) SetRating(id string, rating int) error {
+	return r.db.Model(&models.Conversation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"rating":   rating,
+			"rated_at": time.Now(),
+		}).Error
+}
+
+// HasCustomerConversations reports whether the customer has chatted with the client before
+func (r *conversationRepo) HasCustomerConversations(clientID, customerPhone string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Conversation{}).
+		Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).
+		Limit(1).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// GetLatestForCustomer returns the customer's most recent exchange with the bot
+func (r *conversationRepo) GetLatestForCustomer(clientID, customerPhone string) (*models.Conversation, error) {
+	var conversation models.Conversation
+	err := r.db.Where("client_id = ? AND customer_phone = ? AND ai_response <> '' AND message_type <> ?", clientID, customerPhone, models.ConversationTypeAgent).
+		Order("created_at DESC").
+		First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// GetByMessageID finds the conversation turn a provider message ID belongs to (customer message or bot reply).
+// A bare stanza ID also matches the serialized WAHA form (fromMe_chatId_stanzaId).
+// ListRecentForCustomer returns the customer's latest exchanges since a time, newest first
+func (r *conversationRepo) ListRecentForCustomer(clientID, customerPhone string, since, before time.Time, limit int) ([]models.Conversation, error) {
+	var conversations []models.Conversation
+	err := r.db.Where("client_id = ? AND customer_phone = ? AND created_at >= ? AND created_at < ?", clientID, customerPhone, since, before).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&conversations).Error
+	return conversations, err
+}
+
+func (r *conversationRepo) GetByMessageID(clientID, messageID string) (*models.Conversation, error) {
+	query := r.db.Where("client_id = ?", clientID)
+	if strings.Contains(messageID, "_") {
+		query = query.Where("outbound_message_id = ? OR inbound_message_id = ?", messageID, messageID)
+	} else {
+		suffix := "%\\_" + messageID
+		query = query.Where("outbound_message_id = ? OR inbound_message_id = ? OR outbound_message_id LIKE ? OR inbound_message_id LIKE ?",
+			messageID, messageID, suffix, suffix)
+	}
+
+	var conversation models.Conversation
+	err := query.Order("created_at DESC").First(&conversation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &conversation, nil
+}
+
+// CountByLanguage counts conversations and customers per detected language in a date range
+func (r *conversationRepo) CountByLanguage(clientID string, start, end time.Time) ([]models.LanguageCount, error) {
+	var counts []models.LanguageCount
+	err := r.db.Model(&models.Conversation{}).
+		Select("COALESCE(language, '') AS language, COUNT(*) AS conversations, COUNT(DISTINCT customer_phone) AS customers").
+		Where("client_id = ? AND message_type <> ? AND created_at BETWEEN ? AND ?", clientID, models.ConversationTypeAgent, start, end).
+		Group("COALESCE(language, '')").
+		Order("conversations DESC").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ListForCustomer returns every turn of a customer's chat, oldest first, optionally limited to a time window
+func (r *conversationRepo) ListForCustomer(clientID uuid.UUID, customerPhone string, start, end *time.Time) ([]models.Conversation, error) {
+	query := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone)
+	if start != nil {
+		query = query.Where("created_at >= ?", *start)
+	}
+	if end != nil {
+		query = query.Where("created_at < ?", *end)
+	}
+
+	var conversations []models.Conversation
+	err := query.Order("created_at ASC").Find(&conversations).Error
+	return conversations, err
+}
+
+// ListAnswered returns customer messages the bot answered in a time window, oldest first, optionally of one customer
+func (r *conversationRepo) ListAnswered(clientID uuid.UUID, customerPhone string, start, end time.Time, limit int) ([]models.Conversation, error) {
+	query := r.db.Where("client_id = ? AND message_type = ? AND created_at >= ? AND created_at < ?", clientID, models.ConversationTypeIncoming, start, end).
+		Where("COALESCE(message_text, '') <> '' AND COALESCE(ai_response, '') <> ''")
+	if customerPhone != "" {
+		query = query.Where("customer_phone = ?", customerPhone)
+	}
+
+	var conversations []models.Conversation
+	err := query.Order("created_at ASC").Limit(limit).Find(&conversations).Error
+	return conversations, err
+}
@@ -0,0 +1,155 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ConversationTagRepo interface {
+	ListTags(clientID uuid.UUID) ([]models.ConversationTag, error)
+	GetTag(clientID, id uuid.UUID) (*models.ConversationTag, error)
+	GetTagsByNames(clientID uuid.UUID, names []string) ([]models.ConversationTag, error)
+	CreateTag(tag *models.ConversationTag) error
+	UpdateTag(tag *models.ConversationTag) error
+	DeleteTag(clientID, id uuid.UUID) (int64, error)
+	Assign(assignment *models.ConversationTagAssignment) (bool, error)
+	Unassign(clientID uuid.UUID, customerPhone string, tagID uuid.UUID) (int64, error)
+	ListCustomerTags(clientID uuid.UUID, customerPhone string) ([]string, error)
+	TagsForCustomers(clientID uuid.UUID, customerPhones []string) (map[string][]string, error)
+	ListCustomerPhonesByTags(clientID uuid.UUID, tags []string, matchAll bool) ([]string, error)
+	ListConversations(clientID uuid.UUID, filter models.ConversationFilter) ([]models.ConversationSummary, error)
+}
+
+type conversationTagRepo struct {
+	db *gorm.DB
+}
+
+func NewConversationTagRepo(db *gorm.DB) ConversationTagRepo {
+	return &conversationTagRepo{db: db}
+}
+
+func (r *conversationTagRepo) ListTags(clientID uuid.UUID) ([]models.ConversationTag, error) {
+	var tags []models.ConversationTag
+	err := r.db.Where("client_id = ?", clientID).Order("name ASC").Find(&tags).Error
+	return tags, err
+}
+
+func (r *conversationTagRepo) GetTag(clientID, id uuid.UUID) (*models.ConversationTag, error) {
+	var tag models.ConversationTag
+	if err := r.db.Where("client_id = ? AND id = ?", clientID, id).First(&tag).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *conversationTagRepo) GetTagsByNames(clientID uuid.UUID, names []string) ([]models.ConversationTag, error) {
+	var tags []models.ConversationTag
+	err := r.db.Where("client_id = ? AND name IN ?", clientID, names).Find(&tags).Error
+	return tags, err
+}
+
+func (r *conversationTagRepo) CreateTag(tag *models.ConversationTag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *conversationTagRepo) UpdateTag(tag *models.ConversationTag) error {
+	return r.db.Save(tag).Error
+}
+
+// DeleteTag removes a tag definition; its assignments go with it
+func (r *conversationTagRepo) DeleteTag(clientID, id uuid.UUID) (int64, error) {
+	result := r.db.Where("client_id = ? AND id = ?", clientID, id).Delete(&models.ConversationTag{})
+	return result.RowsAffected, result.Error
+}
+
+// Assign tags a customer's chat, reporting false when it already had the tag
+func (r *conversationTagRepo) Assign(assignment *models.ConversationTagAssignment) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tag_id"}, {Name: "customer_phone"}},
+		DoNothing: true,
+	}).Create(assignment)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *conversationTagRepo) Unassign(clientID uuid.UUID, customerPhone string, tagID uuid.UUID) (int64, error) {
+	result := r.db.Where("client_id = ? AND customer_phone = ? AND tag_id = ?", clientID, customerPhone, tagID).
+		Delete(&models.ConversationTagAssignment{})
+	return result.RowsAffected, result.Error
+}
+
+// ListCustomerTags returns the names of the tags on a customer's chat
+func (r *conversationTagRepo) ListCustomerTags(clientID uuid.UUID, customerPhone string) ([]string, error) {
+	tags, err := r.TagsForCustomers(clientID, []string{customerPhone})
+	if err != nil {
+		return nil, err
+	}
+	return tags[customerPhone], nil
+}
+
+// TagsForCustomers returns tag names per customer phone
+func (r *conversationTagRepo) TagsForCustomers(clientID uuid.UUID, customerPhones []string) (map[string][]string, error) {
+	var rows []struct {
+		CustomerPhone string
+		Name          string
+	}
+	err := r.db.Table("saas_conversation_tag_assignments AS a").
+		Select("a.customer_phone, t.name").
+		Joins("JOIN saas_conversation_tags t ON t.id = a.tag_id").
+		Where("a.client_id = ? AND a.customer_phone IN ?", clientID, customerPhones).
+		Order("t.name ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string][]string)
+	for _, row := range rows {
+		tags[row.CustomerPhone] = append(tags[row.CustomerPhone], row.Name)
+	}
+	return tags, nil
+}
+
+// taggedPhones selects customer phones carrying any (or all) of the tags
+func taggedPhones(db *gorm.DB, clientID uuid.UUID, tags []string, matchAll bool) *gorm.DB {
+	query := db.Table("saas_conversation_tag_assignments AS a").
+		Select("a.customer_phone").
+		Joins("JOIN saas_conversation_tags t ON t.id = a.tag_id").
+		Where("a.client_id = ? AND t.name IN ?", clientID, tags).
+		Group("a.customer_phone")
+	if matchAll {
+		query = query.Having("COUNT(DISTINCT t.name) = ?", len(tags))
+	}
+	return query
+}
+
+// ListCustomerPhonesByTags returns the customers carrying any (or all) of the tags, e.g. for a broadcast audience
+func (r *conversationTagRepo) ListCustomerPhonesByTags(clientID uuid.UUID, tags []string, matchAll bool) ([]string, error) {
+	var phones []string
+	err := taggedPhones(r.db, clientID, tags, matchAll).Scan(&phones).Error
+	return phones, err
+}
+
+// ListConversations returns one row per customer chat, most recently active first
+func (r *conversationTagRepo) ListConversations(clientID uuid.UUID, filter models.ConversationFilter) ([]models.ConversationSummary, error) {
+	chats := r.db.Model(&models.Conversation{}).
+		Select(`DISTINCT ON (customer_phone) customer_phone, message_text AS last_message, ai_response AS last_response,
+			created_at AS last_message_at, COUNT(*) OVER (PARTITION BY customer_phone) AS messages`).
+		Where("client_id = ?", clientID)
+	if len(filter.Tags) > 0 {
+		chats = chats.Where("customer_phone IN (?)", taggedPhones(r.db, clientID, filter.Tags, filter.MatchAll))
+	}
+	if filter.Phone != "" {
+		chats = chats.Where("customer_phone LIKE ?", filter.Phone+"%")
+	}
+	chats = chats.Order("customer_phone, created_at DESC")
+
+	var summaries []models.ConversationSummary
+	err := r.db.Table("(?) AS chats", chats).
+		Order("last_message_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Scan(&summaries).Error
+	return summaries, err
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomFieldRepo interface {
+	ListDefinitions(clientID uuid.UUID, entity string) ([]models.CustomFieldDefinition, error)
+	GetDefinition(clientID, id uuid.UUID) (*models.CustomFieldDefinition, error)
+	CreateDefinition(def *models.CustomFieldDefinition) error
+	UpdateDefinition(def *models.CustomFieldDefinition) error
+	DeleteDefinition(def *models.CustomFieldDefinition) error
+	GetValues(clientID uuid.UUID, entity, ref string) (datatypes.JSON, error)
+	SetValues(clientID uuid.UUID, entity, ref string, values datatypes.JSON) error
+	ListCustomers(clientID uuid.UUID) ([]models.Customer, error)
+	ListOrders(clientID uuid.UUID, start, end time.Time) ([]models.Order, error)
+	ListProducts(clientID uuid.UUID) ([]models.Product, error)
+}
+
+type customFieldRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomFieldRepo(db *gorm.DB) CustomFieldRepo {
+	return &customFieldRepo{db: db}
+}
+
+// customFieldTables maps an entity to the table holding its custom_fields column and the column identifying a row
+var customFieldTables = map[string]struct{ table, refColumn string }{
+	models.CustomFieldEntityCustomer: {"saas_customers", "customer_phone"},
+	models.CustomFieldEntityOrder:    {"saas_orders", "id"},
+	models.CustomFieldEntityProduct:  {"saas_products", "id"},
+}
+
+func (r *customFieldRepo) ListDefinitions(clientID uuid.UUID, entity string) ([]models.CustomFieldDefinition, error) {
+	var defs []models.CustomFieldDefinition
+	query := r.db.Where("client_id = ?", clientID)
+	if entity != "" {
+		query = query.Where("entity = ?", entity)
+	}
+	err := query.Order("entity, position, key").Find(&defs).Error
+	return defs, err
+}
+
+func (r *customFieldRepo) GetDefinition(clientID, id uuid.UUID) (*models.CustomFieldDefinition, error) {
+	var def models.CustomFieldDefinition
+	err := r.db.Where("client_id = ? AND id = ?", clientID, id).First(&def).Error
+	if err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+func (r *customFieldRepo) CreateDefinition(def *models.CustomFieldDefinition) error {
+	return r.db.Create(def).Error
+}
+
+func (r *customFieldRepo) UpdateDefinition(def *models.CustomFieldDefinition) error {
+	return r.db.Save(def).Error
+}
+
+// DeleteDefinition removes the definition and its stored values
+func (r *customFieldRepo) DeleteDefinition(def *models.CustomFieldDefinition) error {
+	target, ok := customFieldTables[def.Entity]
+	if !ok {
+		return fmt.Errorf("unknown custom field entity %q", def.Entity)
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table(target.table).
+			Where("client_id = ? AND custom_fields -> ? IS NOT NULL", def.ClientID, def.Key).
+			Update("custom_fields", gorm.Expr("custom_fields - ?", def.Key)).Error
+		if err != nil {
+			return err
+		}
+		return tx.Delete(def).Error
+	})
+}
+
+// GetValues returns the custom field values of one customer (by phone), order or product (by ID)
+func (r *customFieldRepo) GetValues(clientID uuid.UUID, entity, ref string) (datatypes.JSON, error) {
+	target, ok := customFieldTables[entity]
+	if !ok {
+		return nil, fmt.Errorf("unknown custom field entity %q", entity)
+	}
+	var row struct{ CustomFields datatypes.JSON }
+	result := r.db.Table(target.table).
+		Select("custom_fields").
+		Where("client_id = ? AND "+target.refColumn+" = ?", clientID, ref).
+		Limit(1).
+		Scan(&row)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return row.CustomFields, nil
+}
+
+// SetValues replaces the custom field values of a row; customers are created on first write
+func (r *customFieldRepo) SetValues(clientID uuid.UUID, entity, ref string, values datatypes.JSON) error {
+	if entity == models.CustomFieldEntityCustomer {
+		return r.db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+			DoUpdates: clause.AssignmentColumns([]string{"custom_fields", "updated_at"}),
+		}).Create(&models.Customer{ClientID: clientID, CustomerPhone: ref, CustomFields: values}).Error
+	}
+
+	target, ok := customFieldTables[entity]
+	if !ok {
+		return fmt.Errorf("unknown custom field entity %q", entity)
+	}
+	result := r.db.Table(target.table).
+		Where("client_id = ? AND "+target.refColumn+" = ?", clientID, ref).
+		Updates(map[string]interface{}{"custom_fields": values, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *customFieldRepo) ListCustomers(clientID uuid.UUID) ([]models.Customer, error) {
+	var customers []models.Customer
+	err := r.db.Where("client_id = ?", clientID).Order("customer_phone").Find(&customers).Error
+	return customers, err
+}
+
+func (r *customFieldRepo) ListOrders(clientID uuid.UUID, start, end time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("client_id = ? AND is_test = ? AND created_at >= ? AND created_at < ?", clientID, false, start, end).
+		Order("created_at").
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *customFieldRepo) ListProducts(clientID uuid.UUID) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Where("client_id = ?", clientID).Order("name").Find(&products).Error
+	return products, err
+}
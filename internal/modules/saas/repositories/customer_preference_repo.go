@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomerPreferenceRepo interface {
+	Get(clientID uuid.UUID, customerPhone string) (*models.CustomerPreference, error)
+	Upsert(preference *models.CustomerPreference) error
+	Delete(clientID uuid.UUID, customerPhone string) error
+}
+
+type customerPreferenceRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomerPreferenceRepo(db *gorm.DB) CustomerPreferenceRepo {
+	return &customerPreferenceRepo{db: db}
+}
+
+func (r *customerPreferenceRepo) Get(clientID uuid.UUID, customerPhone string) (*models.CustomerPreference, error) {
+	var preference models.CustomerPreference
+	if err := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).First(&preference).Error; err != nil {
+		return nil, err
+	}
+	return &preference, nil
+}
+
+func (r *customerPreferenceRepo) Upsert(preference *models.CustomerPreference) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+		DoUpdates: clause.AssignmentColumns([]string{"language", "payment_method", "delivery_notes", "updated_by", "updated_at"}),
+	}).Create(preference).Error
+}
+
+func (r *customerPreferenceRepo) Delete(clientID uuid.UUID, customerPhone string) error {
+	return r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).Delete(&models.CustomerPreference{}).Error
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomerRepo interface {
+	RecordInteraction(clientID uuid.UUID, customerPhone string, at time.Time) error
+	Get(clientID uuid.UUID, customerPhone string) (*models.CustomerProfile, error)
+	List(clientID uuid.UUID, filter models.CustomerFilter) ([]models.CustomerProfile, error)
+	SegmentPhones(clientID uuid.UUID, rules models.SegmentRules) ([]string, error)
+	CountSegment(clientID uuid.UUID, rules models.SegmentRules) (int64, error)
+	InSegment(clientID uuid.UUID, customerPhone string, rules models.SegmentRules) (bool, error)
+	Upsert(customer *models.Customer) error
+}
+
+type customerRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomerRepo(db *gorm.DB) CustomerRepo {
+	return &customerRepo{db: db}
+}
+
+// RecordInteraction counts an inbound message of the customer, creating the customer on their first one
+func (r *customerRepo) RecordInteraction(clientID uuid.UUID, customerPhone string, at time.Time) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"message_count":       gorm.Expr("saas_customers.message_count + 1"),
+			"last_interaction_at": at,
+		}),
+	}).Create(&models.Customer{ClientID: clientID, CustomerPhone: customerPhone, MessageCount: 1, LastInteractionAt: &at}).Error
+}
+
+func (r *customerRepo) Get(clientID uuid.UUID, customerPhone string) (*models.CustomerProfile, error) {
+	var profile models.CustomerProfile
+	result := r.profiles(clientID, customerPhone).Limit(1).Scan(&profile)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
+
+// List returns the client's customers matching the filter, most recently active first by default
+func (r *customerRepo) List(clientID uuid.UUID, filter models.CustomerFilter) ([]models.CustomerProfile, error) {
+	query := r.profiles(clientID, "")
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("c.customer_phone LIKE ? OR c.name ILIKE ? OR o.order_name ILIKE ?", pattern, pattern, pattern)
+	}
+	if len(filter.Tags) > 0 {
+		query = query.Where("c.customer_phone IN (?)", taggedPhones(r.db, clientID, filter.Tags, false))
+	}
+	if filter.Segment != nil {
+		query = r.inSegment(query, clientID, *filter.Segment)
+	}
+
+	switch filter.Sort {
+	case models.CustomerSortLifetimeValue:
+		query = query.Order("lifetime_value DESC, c.last_interaction_at DESC NULLS LAST")
+	case models.CustomerSortName:
+		query = query.Order("name ASC, c.customer_phone ASC")
+	default:
+		query = query.Order("c.last_interaction_at DESC NULLS LAST, c.created_at DESC")
+	}
+
+	var profiles []models.CustomerProfile
+	err := query.Limit(filter.Limit).Offset(filter.Offset).Scan(&profiles).Error
+	return profiles, err
+}
+
+// SegmentPhones returns the phones of the segment's members
+func (r *customerRepo) SegmentPhones(clientID uuid.UUID, rules models.SegmentRules) ([]string, error) {
+	var phones []string
+	members := r.inSegment(r.profiles(clientID, ""), clientID, rules)
+	err := r.db.Table("(?) AS members", members).Order("customer_phone").Pluck("customer_phone", &phones).Error
+	return phones, err
+}
+
+// CountSegment counts the segment's members
+func (r *customerRepo) CountSegment(clientID uuid.UUID, rules models.SegmentRules) (int64, error) {
+	var count int64
+	members := r.inSegment(r.profiles(clientID, ""), clientID, rules)
+	err := r.db.Table("(?) AS members", members).Count(&count).Error
+	return count, err
+}
+
+// InSegment reports whether the customer is a member of the segment
+func (r *customerRepo) InSegment(clientID uuid.UUID, customerPhone string, rules models.SegmentRules) (bool, error) {
+	var count int64
+	members := r.inSegment(r.profiles(clientID, customerPhone), clientID, rules)
+	err := r.db.Table("(?) AS members", members).Count(&count).Error
+	return count > 0, err
+}
+
+// inSegment narrows a profiles query down to the customers matching the rules
+func (r *customerRepo) inSegment(query *gorm.DB, clientID uuid.UUID, rules models.SegmentRules) *gorm.DB {
+	now := time.Now()
+	if len(rules.Tags) > 0 {
+		query = query.Where("c.customer_phone IN (?)", taggedPhones(r.db, clientID, rules.Tags, rules.MatchAllTags))
+	}
+	if len(rules.ExcludeTags) > 0 {
+		query = query.Where("c.customer_phone NOT IN (?)", taggedPhones(r.db, clientID, rules.ExcludeTags, false))
+	}
+	if rules.OrderedWithinDays > 0 {
+		query = query.Where("o.last_order_at >= ?", now.AddDate(0, 0, -rules.OrderedWithinDays))
+	}
+	if rules.NotOrderedWithinDays > 0 {
+		query = query.Where("(o.last_order_at IS NULL OR o.last_order_at < ?)", now.AddDate(0, 0, -rules.NotOrderedWithinDays))
+	}
+	if rules.MinOrders > 0 {
+		query = query.Where("COALESCE(o.order_count, 0) >= ?", rules.MinOrders)
+	}
+	if rules.MinLifetimeValue > 0 {
+		query = query.Where("COALESCE(o.lifetime_value, 0) >= ?", rules.MinLifetimeValue)
+	}
+	if rules.ActiveWithinDays > 0 {
+		query = query.Where("c.last_interaction_at >= ?", now.AddDate(0, 0, -rules.ActiveWithinDays))
+	}
+	return query
+}
+
+// Upsert saves the name and notes of a customer, creating the customer when they never wrote in
+func (r *customerRepo) Upsert(customer *models.Customer) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "notes", "updated_at"}),
+	}).Create(customer).Error
+}
+
+// profiles selects the client's customers (or the one with customerPhone) with their paid orders
+// aggregated. Customers without a name are named after their latest order.
+func (r *customerRepo) profiles(clientID uuid.UUID, customerPhone string) *gorm.DB {
+	orders := r.db.Model(&models.Order{}).
+		Select(`customer_phone,
+			COUNT(*) FILTER (WHERE `+paidOrderFilter+`) AS order_count,
+			COALESCE(SUM(total_amount - refunded_amount) FILTER (WHERE `+paidOrderFilter+`), 0) AS lifetime_value,
+			MAX(paid_at) AS last_order_at,
+			(ARRAY_AGG(customer_name ORDER BY created_at DESC) FILTER (WHERE customer_name <> ''))[1] AS order_name`).
+		Where("client_id = ? AND is_test = ?", clientID, false).
+		Group("customer_phone")
+
+	query := r.db.Table("saas_customers AS c").
+		Select(`c.id, c.client_id, c.customer_phone, COALESCE(NULLIF(c.name, ''), o.order_name, '') AS name, c.notes,
+			c.message_count, c.last_interaction_at, c.custom_fields, c.created_at, c.updated_at,
+			COALESCE(o.order_count, 0) AS order_count, COALESCE(o.lifetime_value, 0) AS lifetime_value, o.last_order_at`).
+		Where("c.client_id = ?", clientID)
+	if customerPhone != "" {
+		orders = orders.Where("customer_phone = ?", customerPhone)
+		query = query.Where("c.customer_phone = ?", customerPhone)
+	}
+	return query.Joins("LEFT JOIN (?) AS o ON o.customer_phone = c.customer_phone", orders)
+}
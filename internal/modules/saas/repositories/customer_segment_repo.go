@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerSegmentRepo interface {
+	List(clientID uuid.UUID) ([]models.CustomerSegment, error)
+	GetByID(clientID, id uuid.UUID) (*models.CustomerSegment, error)
+	GetByName(clientID uuid.UUID, name string) (*models.CustomerSegment, error)
+	Create(segment *models.CustomerSegment) error
+	Update(segment *models.CustomerSegment) error
+	Delete(clientID, id uuid.UUID) (int64, error)
+}
+
+type customerSegmentRepo struct {
+	db *gorm.DB
+}
+
+func NewCustomerSegmentRepo(db *gorm.DB) CustomerSegmentRepo {
+	return &customerSegmentRepo{db: db}
+}
+
+func (r *customerSegmentRepo) List(clientID uuid.UUID) ([]models.CustomerSegment, error) {
+	var segments []models.CustomerSegment
+	err := r.db.Where("client_id = ?", clientID).Order("name ASC").Find(&segments).Error
+	return segments, err
+}
+
+func (r *customerSegmentRepo) GetByID(clientID, id uuid.UUID) (*models.CustomerSegment, error) {
+	var segment models.CustomerSegment
+	if err := r.db.Where("client_id = ? AND id = ?", clientID, id).First(&segment).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *customerSegmentRepo) GetByName(clientID uuid.UUID, name string) (*models.CustomerSegment, error) {
+	var segment models.CustomerSegment
+	if err := r.db.Where("client_id = ? AND LOWER(name) = LOWER(?)", clientID, name).First(&segment).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *customerSegmentRepo) Create(segment *models.CustomerSegment) error {
+	return r.db.Create(segment).Error
+}
+
+func (r *customerSegmentRepo) Update(segment *models.CustomerSegment) error {
+	return r.db.Save(segment).Error
+}
+
+// Delete removes a segment; campaigns sent to it lose their audience
+func (r *customerSegmentRepo) Delete(clientID, id uuid.UUID) (int64, error) {
+	result := r.db.Where("client_id = ? AND id = ?", clientID, id).Delete(&models.CustomerSegment{})
+	return result.RowsAffected, result.Error
+}
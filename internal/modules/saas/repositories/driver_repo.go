@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type DriverRepo interface {
+	Create(driver *models.Driver) error
+	GetByID(id string) (*models.Driver, error)
+	GetByPhone(clientID, phone string) (*models.Driver, error)
+	ListByClientID(clientID string, activeOnly bool) ([]models.Driver, error)
+	Update(driver *models.Driver) error
+}
+
+type driverRepo struct {
+	db *gorm.DB
+}
+
+func NewDriverRepo(db *gorm.DB) DriverRepo {
+	return &driverRepo{db: db}
+}
+
+func (r *driverRepo) Create(driver *models.Driver) error {
+	return r.db.Create(driver).Error
+}
+
+func (r *driverRepo) GetByID(id string) (*models.Driver, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid driver ID: %w", err)
+	}
+
+	var driver models.Driver
+	err = r.db.First(&driver, "id = ?", uid).Error
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+func (r *driverRepo) GetByPhone(clientID, phone string) (*models.Driver, error) {
+	var driver models.Driver
+	err := r.db.Where("client_id = ? AND phone = ? AND is_active = ?", clientID, phone, true).First(&driver).Error
+	if err != nil {
+		return nil, err
+	}
+	return &driver, nil
+}
+
+func (r *driverRepo) ListByClientID(clientID string, activeOnly bool) ([]models.Driver, error) {
+	var drivers []models.Driver
+	query := r.db.Where("client_id = ?", clientID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("name ASC").Find(&drivers).Error
+	return drivers, err
+}
+
+func (r *driverRepo) Update(driver *models.Driver) error {
+	return r.db.Save(driver).Error
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KBDocumentRepo interface {
+	Create(document *models.KBDocument) error
+	Update(document *models.KBDocument) error
+	GetByID(clientID, id uuid.UUID) (*models.KBDocument, error)
+	ListByClient(clientID uuid.UUID) ([]models.KBDocument, error)
+	Delete(clientID, id uuid.UUID) error
+	HasReady(clientID uuid.UUID) (bool, error)
+}
+
+type kbDocumentRepo struct {
+	db *gorm.DB
+}
+
+func NewKBDocumentRepo(db *gorm.DB) KBDocumentRepo {
+	return &kbDocumentRepo{db: db}
+}
+
+func (r *kbDocumentRepo) Create(document *models.KBDocument) error {
+	return r.db.Create(document).Error
+}
+
+func (r *kbDocumentRepo) Update(document *models.KBDocument) error {
+	return r.db.Save(document).Error
+}
+
+func (r *kbDocumentRepo) GetByID(clientID, id uuid.UUID) (*models.KBDocument, error) {
+	var document models.KBDocument
+	if err := r.db.Where("id = ? AND client_id = ?", id, clientID).First(&document).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// ListByClient returns the client's documents newest first
+func (r *kbDocumentRepo) ListByClient(clientID uuid.UUID) ([]models.KBDocument, error) {
+	var documents []models.KBDocument
+	err := r.db.Where("client_id = ?", clientID).Order("created_at DESC").Find(&documents).Error
+	return documents, err
+}
+
+func (r *kbDocumentRepo) Delete(clientID, id uuid.UUID) error {
+	return r.db.Where("id = ? AND client_id = ?", id, clientID).Delete(&models.KBDocument{}).Error
+}
+
+// HasReady reports whether the client has any indexed document, so questions are only searched against clients that have one
+func (r *kbDocumentRepo) HasReady(clientID uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.KBDocument{}).
+		Where("client_id = ? AND status = ?", clientID, models.KBDocumentReady).
+		Limit(1).Count(&count).Error
+	return count > 0, err
+}
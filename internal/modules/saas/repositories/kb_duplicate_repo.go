@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type KBDuplicateRepo interface {
+	Create(duplicate *models.KBDuplicate) error
+	GetByID(clientID uuid.UUID, id string) (*models.KBDuplicate, error)
+	List(clientID uuid.UUID, status string) ([]models.KBDuplicate, error)
+	Update(duplicate *models.KBDuplicate) error
+}
+
+type kbDuplicateRepo struct {
+	db *gorm.DB
+}
+
+func NewKBDuplicateRepo(db *gorm.DB) KBDuplicateRepo {
+	return &kbDuplicateRepo{db: db}
+}
+
+// Create flags a pair once; a pair flagged before keeps its status
+func (r *kbDuplicateRepo) Create(duplicate *models.KBDuplicate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "entry_id"}, {Name: "duplicate_of_id"}},
+		DoNothing: true,
+	}).Create(duplicate).Error
+}
+
+func (r *kbDuplicateRepo) GetByID(clientID uuid.UUID, id string) (*models.KBDuplicate, error) {
+	var duplicate models.KBDuplicate
+	err := r.db.Where("client_id = ? AND id = ?", clientID, id).First(&duplicate).Error
+	return &duplicate, err
+}
+
+// List returns a client's flagged pairs, newest first. Open pairs whose entries were deleted since are left out
+func (r *kbDuplicateRepo) List(clientID uuid.UUID, status string) ([]models.KBDuplicate, error) {
+	var duplicates []models.KBDuplicate
+	query := r.db.Where("client_id = ?", clientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Where("status <> ? OR (EXISTS (SELECT 1 FROM saas_knowledge_base kb WHERE kb.id = entry_id) AND EXISTS (SELECT 1 FROM saas_knowledge_base kb WHERE kb.id = duplicate_of_id))",
+		models.KBDuplicateOpen).
+		Order("created_at DESC").
+		Find(&duplicates).Error
+	return duplicates, err
+}
+
+func (r *kbDuplicateRepo) Update(duplicate *models.KBDuplicate) error {
+	return r.db.Save(duplicate).Error
+}
@@ -4,12 +4,18 @@ import (
 	"encoding/json"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type KBRepo interface {
 	GetKnowledgeBase(clientID string) (*models.KnowledgeBase, error)
 	Create(entry *models.KnowledgeBaseEntry) error
+	ListEntries(clientID, entryType string) ([]models.KnowledgeBaseEntry, error)
+	GetEntry(clientID string, id uuid.UUID) (*models.KnowledgeBaseEntry, error)
+	Update(entry *models.KnowledgeBaseEntry) error
+	DeleteByIDs(clientID string, ids []uuid.UUID) (int64, error)
+	Transaction(fn func(repo KBRepo) error) error
 }
 
 type kbRepo struct {
@@ -85,3 +91,38 @@ func (r *kbRepo) Create(entry *models.KnowledgeBaseEntry) error {
 	// Use GORM to create the entry
 	return r.db.Create(entry).Error
 }
+
+// ListEntries returns a client's entries (active or not), optionally of one type
+func (r *kbRepo) ListEntries(clientID, entryType string) ([]models.KnowledgeBaseEntry, error) {
+	var entries []models.KnowledgeBaseEntry
+	query := r.db.Where("client_id = ?", clientID)
+	if entryType != "" {
+		query = query.Where("type = ?", entryType)
+	}
+	err := query.Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
+
+func (r *kbRepo) GetEntry(clientID string, id uuid.UUID) (*models.KnowledgeBaseEntry, error) {
+	var entry models.KnowledgeBaseEntry
+	err := r.db.Where("client_id = ? AND id = ?", clientID, id).First(&entry).Error
+	return &entry, err
+}
+
+func (r *kbRepo) Update(entry *models.KnowledgeBaseEntry) error {
+	return r.db.Save(entry).Error
+}
+
+func (r *kbRepo) DeleteByIDs(clientID string, ids []uuid.UUID) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	result := r.db.Where("client_id = ? AND id IN ?", clientID, ids).Delete(&models.KnowledgeBaseEntry{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *kbRepo) Transaction(fn func(repo KBRepo) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&kbRepo{db: tx})
+	})
+}
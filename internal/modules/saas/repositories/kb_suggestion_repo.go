@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KBSuggestionRepo interface {
+	CreateGap(gap *models.KBGap) error
+	ListUnprocessedGaps(clientID uuid.UUID, since time.Time) ([]models.KBGap, error)
+	ListClientsWithGaps(since time.Time) ([]uuid.UUID, error)
+	AssignGaps(gapIDs []uuid.UUID, suggestionID uuid.UUID) error
+	Create(suggestion *models.KBSuggestion) error
+	GetByID(id string) (*models.KBSuggestion, error)
+	List(clientID uuid.UUID, status string, limit int) ([]models.KBSuggestion, error)
+	Update(suggestion *models.KBSuggestion) error
+}
+
+type kbSuggestionRepo struct {
+	db *gorm.DB
+}
+
+func NewKBSuggestionRepo(db *gorm.DB) KBSuggestionRepo {
+	return &kbSuggestionRepo{db: db}
+}
+
+func (r *kbSuggestionRepo) CreateGap(gap *models.KBGap) error {
+	return r.db.Create(gap).Error
+}
+
+// ListUnprocessedGaps returns gaps not yet clustered into a suggestion
+func (r *kbSuggestionRepo) ListUnprocessedGaps(clientID uuid.UUID, since time.Time) ([]models.KBGap, error) {
+	var gaps []models.KBGap
+	err := r.db.Where("client_id = ? AND suggestion_id IS NULL AND created_at >= ?", clientID, since).
+		Order("created_at ASC").
+		Find(&gaps).Error
+	return gaps, err
+}
+
+// ListClientsWithGaps returns clients that have unprocessed gaps
+func (r *kbSuggestionRepo) ListClientsWithGaps(since time.Time) ([]uuid.UUID, error) {
+	var clientIDs []uuid.UUID
+	err := r.db.Model(&models.KBGap{}).
+		Distinct("client_id").
+		Where("suggestion_id IS NULL AND created_at >= ?", since).
+		Pluck("client_id", &clientIDs).Error
+	return clientIDs, err
+}
+
+// AssignGaps links clustered gaps to the suggestion drafted from them
+func (r *kbSuggestionRepo) AssignGaps(gapIDs []uuid.UUID, suggestionID uuid.UUID) error {
+	return r.db.Model(&models.KBGap{}).
+		Where("id IN ?", gapIDs).
+		Update("suggestion_id", suggestionID).Error
+}
+
+func (r *kbSuggestionRepo) Create(suggestion *models.KBSuggestion) error {
+	return r.db.Create(suggestion).Error
+}
+
+func (r *kbSuggestionRepo) GetByID(id string) (*models.KBSuggestion, error) {
+	var suggestion models.KBSuggestion
+	err := r.db.Where("id = ?", id).First(&suggestion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+func (r *kbSuggestionRepo) List(clientID uuid.UUID, status string, limit int) ([]models.KBSuggestion, error) {
+	var suggestions []models.KBSuggestion
+	query := r.db.Where("client_id = ?", clientID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	query = query.Order("occurrences DESC, created_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&suggestions).Error
+	return suggestions, err
+}
+
+func (r *kbSuggestionRepo) Update(suggestion *models.KBSuggestion) error {
+	return r.db.Save(suggestion).Error
+}
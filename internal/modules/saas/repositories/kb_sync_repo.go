@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type KBSyncRepo interface {
+	ListDue(clientID *uuid.UUID, limit int) ([]models.KBSyncOutboxItem, error)
+	GetEntries(ids []uuid.UUID) ([]models.KnowledgeBaseEntry, error)
+	Complete(item *models.KBSyncOutboxItem) error
+	Fail(item *models.KBSyncOutboxItem, errMsg string, retryIn time.Duration) error
+	EnqueueAll(clientID uuid.UUID) (int64, error)
+	CountPending(clientID *uuid.UUID) (int64, error)
+}
+
+type kbSyncRepo struct {
+	db *gorm.DB
+}
+
+func NewKBSyncRepo(db *gorm.DB) KBSyncRepo {
+	return &kbSyncRepo{db: db}
+}
+
+// ListDue returns queued changes due for an attempt, oldest first. Times are compared on the database clock,
+// the one the trigger stamps changes with.
+func (r *kbSyncRepo) ListDue(clientID *uuid.UUID, limit int) ([]models.KBSyncOutboxItem, error) {
+	query := r.db.Where("next_attempt_at <= NOW()")
+	if clientID != nil {
+		query = query.Where("client_id = ?", *clientID)
+	}
+
+	var items []models.KBSyncOutboxItem
+	err := query.Order("enqueued_at ASC, id ASC").Limit(limit).Find(&items).Error
+	return items, err
+}
+
+// GetEntries loads the current state of the given entries; deleted ones are missing from the result
+func (r *kbSyncRepo) GetEntries(ids []uuid.UUID) ([]models.KnowledgeBaseEntry, error) {
+	var entries []models.KnowledgeBaseEntry
+	if len(ids) == 0 {
+		return entries, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&entries).Error
+	return entries, err
+}
+
+// Complete removes a synced change, unless the entry changed again meanwhile (the trigger bumped its version)
+func (r *kbSyncRepo) Complete(item *models.KBSyncOutboxItem) error {
+	return r.db.Where("id = ? AND version = ?", item.ID, item.Version).Delete(&models.KBSyncOutboxItem{}).Error
+}
+
+// Fail records a failed attempt and when to retry, unless the entry changed again meanwhile
+func (r *kbSyncRepo) Fail(item *models.KBSyncOutboxItem, errMsg string, retryIn time.Duration) error {
+	return r.db.Model(&models.KBSyncOutboxItem{}).
+		Where("id = ? AND version = ?", item.ID, item.Version).
+		Updates(map[string]interface{}{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"last_error":      errMsg,
+			"next_attempt_at": gorm.Expr("NOW() + ? * INTERVAL '1 second'", retryIn.Seconds()),
+		}).Error
+}
+
+// EnqueueAll queues every entry of the client for re-indexing
+func (r *kbSyncRepo) EnqueueAll(clientID uuid.UUID) (int64, error) {
+	result := r.db.Exec(`
+		SELECT enqueue_saas_kb_sync(client_id, id, type, ?)
+		FROM saas_knowledge_base WHERE client_id = ?`, models.KBSyncUpsert, clientID)
+	return result.RowsAffected, result.Error
+}
+
+func (r *kbSyncRepo) CountPending(clientID *uuid.UUID) (int64, error) {
+	query := r.db.Model(&models.KBSyncOutboxItem{})
+	if clientID != nil {
+		query = query.Where("client_id = ?", *clientID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LanguageSettingsRepo interface {
+	GetByClientID(clientID string) (*models.LanguageSettings, error)
+	Upsert(settings *models.LanguageSettings) error
+}
+
+type languageSettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewLanguageSettingsRepo(db *gorm.DB) LanguageSettingsRepo {
+	return &languageSettingsRepo{db: db}
+}
+
+func (r *languageSettingsRepo) GetByClientID(clientID string) (*models.LanguageSettings, error) {
+	var settings models.LanguageSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *languageSettingsRepo) Upsert(settings *models.LanguageSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "default_language", "allowed_languages", "post_process", "updated_at",
+		}),
+	}).Create(settings).Error
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LatencySettingsRepo interface {
+	GetByClientID(clientID string) (*models.LatencySettings, error)
+	Upsert(settings *models.LatencySettings) error
+}
+
+type latencySettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewLatencySettingsRepo(db *gorm.DB) LatencySettingsRepo {
+	return &latencySettingsRepo{db: db}
+}
+
+func (r *latencySettingsRepo) GetByClientID(clientID string) (*models.LatencySettings, error) {
+	var settings models.LatencySettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *latencySettingsRepo) Upsert(settings *models.LatencySettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "interim_after_seconds", "interim_message", "hard_timeout_seconds",
+			"fallback", "handover_message", "handover_tag", "updated_at",
+		}),
+	}).Create(settings).Error
+}
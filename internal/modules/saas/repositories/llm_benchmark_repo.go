@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+)
+
+type LLMBenchmarkRepo interface {
+	CreateRun(results []models.LLMBenchmark) error
+	List(provider, model string, limit int) ([]models.LLMBenchmark, error)
+}
+
+type llmBenchmarkRepo struct {
+	db *gorm.DB
+}
+
+func NewLLMBenchmarkRepo(db *gorm.DB) LLMBenchmarkRepo {
+	return &llmBenchmarkRepo{db: db}
+}
+
+func (r *llmBenchmarkRepo) CreateRun(results []models.LLMBenchmark) error {
+	if len(results) == 0 {
+		return nil
+	}
+	return r.db.Create(&results).Error
+}
+
+// List returns benchmark results newest first, optionally of one provider and model
+func (r *llmBenchmarkRepo) List(provider, model string, limit int) ([]models.LLMBenchmark, error) {
+	var results []models.LLMBenchmark
+	query := r.db.Order("created_at DESC, health_score DESC").Limit(limit)
+	if provider != "" {
+		query = query.Where("provider = ?", provider)
+	}
+	if model != "" {
+		query = query.Where("model = ?", model)
+	}
+	err := query.Find(&results).Error
+	return results, err
+}
@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UsageRepo stores metered LLM usage and the AI token credits of each client
+type UsageRepo interface {
+	Create(usage *models.LLMUsage) error
+	Totals(clientID uuid.UUID, from, to time.Time) (*models.LLMUsageTotals, error)
+	ByFeature(clientID uuid.UUID, from, to time.Time) ([]models.FeatureUsage, error)
+	ByDay(clientID uuid.UUID, from, to time.Time) ([]models.UsageDay, error)
+
+	CreditBalance(clientID uuid.UUID) (int64, error)
+	AddCredits(clientID uuid.UUID, tokens int64) (int64, error)
+	DrawCredits(clientID uuid.UUID, tokens int64) (int64, error)
+}
+
+type usageRepo struct {
+	db *gorm.DB
+}
+
+func NewUsageRepo(db *gorm.DB) UsageRepo {
+	return &usageRepo{db: db}
+}
+
+func (r *usageRepo) Create(usage *models.LLMUsage) error {
+	return r.db.Create(usage).Error
+}
+
+// Totals sums the calls made in [from, to)
+func (r *usageRepo) Totals(clientID uuid.UUID, from, to time.Time) (*models.LLMUsageTotals, error) {
+	var totals models.LLMUsageTotals
+	err := r.db.Model(&models.LLMUsage{}).
+		Select(`COUNT(*) AS calls,
+			COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens,
+			COALESCE(SUM(completion_tokens), 0) AS completion_tokens,
+			COALESCE(SUM(total_tokens), 0) AS total_tokens,
+			COALESCE(SUM(credit_tokens), 0) AS credit_tokens`).
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Scan(&totals).Error
+	return &totals, err
+}
+
+// ByFeature sums the calls made in [from, to) per feature, most tokens first
+func (r *usageRepo) ByFeature(clientID uuid.UUID, from, to time.Time) ([]models.FeatureUsage, error) {
+	var features []models.FeatureUsage
+	err := r.db.Model(&models.LLMUsage{}).
+		Select("feature, COUNT(*) AS calls, COALESCE(SUM(total_tokens), 0) AS tokens").
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Group("feature").
+		Order("tokens DESC").
+		Scan(&features).Error
+	return features, err
+}
+
+// ByDay sums the calls made in [from, to) per day, days without calls left out
+func (r *usageRepo) ByDay(clientID uuid.UUID, from, to time.Time) ([]models.UsageDay, error) {
+	var days []models.UsageDay
+	err := r.db.Model(&models.LLMUsage{}).
+		Select("TO_CHAR(created_at, 'YYYY-MM-DD') AS date, COUNT(*) AS calls, COALESCE(SUM(total_tokens), 0) AS tokens").
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Group("date").
+		Order("date").
+		Scan(&days).Error
+	return days, err
+}
+
+// CreditBalance returns the client's AI credits, 0 when it never had any
+func (r *usageRepo) CreditBalance(clientID uuid.UUID) (int64, error) {
+	var credit models.AICredit
+	err := r.db.Where("client_id = ?", clientID).First(&credit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, nil
+	}
+	return credit.Balance, err
+}
+
+// AddCredits adds tokens to the client's balance (a negative amount removes them, down to 0) and returns the new balance
+func (r *usageRepo) AddCredits(clientID uuid.UUID, tokens int64) (int64, error) {
+	credit := models.AICredit{ClientID: clientID, Balance: max(tokens, 0)}
+	err := r.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "client_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"balance":    gorm.Expr("GREATEST(saas_ai_credits.balance + ?, 0)", tokens),
+				"updated_at": gorm.Expr("NOW()"),
+			}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "balance"}}},
+	).Create(&credit).Error
+	return credit.Balance, err
+}
+
+// DrawCredits takes up to tokens from the client's balance and returns how many it took
+func (r *usageRepo) DrawCredits(clientID uuid.UUID, tokens int64) (int64, error) {
+	var drawn int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var credit models.AICredit
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("client_id = ?", clientID).
+			First(&credit).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		drawn = min(tokens, credit.Balance)
+		if drawn <= 0 {
+			return nil
+		}
+		return tx.Model(&credit).Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance - ?", drawn),
+			"updated_at": time.Now(),
+		}).Error
+	})
+	return drawn, err
+}
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MessageFeatureRepo interface {
+	GetSettings(clientID string) (*models.MessageFeatureSettings, error)
+	UpsertSettings(settings *models.MessageFeatureSettings) error
+}
+
+type messageFeatureRepo struct {
+	db *gorm.DB
+}
+
+func NewMessageFeatureRepo(db *gorm.DB) MessageFeatureRepo {
+	return &messageFeatureRepo{db: db}
+}
+
+func (r *messageFeatureRepo) GetSettings(clientID string) (*models.MessageFeatureSettings, error) {
+	var settings models.MessageFeatureSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *messageFeatureRepo) UpsertSettings(settings *models.MessageFeatureSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"text_enabled", "image_enabled", "voice_enabled", "location_enabled", "group_enabled",
+			"text_disabled_reply", "image_disabled_reply", "voice_disabled_reply", "location_disabled_reply", "updated_at",
+		}),
+	}).Create(settings).Error
+}
@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OnboardingFlowRepo interface {
+	GetByClientID(clientID string) (*models.OnboardingFlow, error)
+	Upsert(flow *models.OnboardingFlow) error
+	GetCustomer(clientID, customerPhone string) (*models.CustomerOnboarding, error)
+	CreateCustomer(onboarding *models.CustomerOnboarding) error
+	UpdateCustomer(onboarding *models.CustomerOnboarding) error
+	DeleteCustomer(clientID, customerPhone string) error
+}
+
+type onboardingFlowRepo struct {
+	db *gorm.DB
+}
+
+func NewOnboardingFlowRepo(db *gorm.DB) OnboardingFlowRepo {
+	return &onboardingFlowRepo{db: db}
+}
+
+func (r *onboardingFlowRepo) GetByClientID(clientID string) (*models.OnboardingFlow, error) {
+	var flow models.OnboardingFlow
+	err := r.db.Where("client_id = ?", clientID).First(&flow).Error
+	return &flow, err
+}
+
+func (r *onboardingFlowRepo) Upsert(flow *models.OnboardingFlow) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "welcome_message", "quick_menu", "languages", "consent_required", "consent_text", "updated_at",
+		}),
+	}).Create(flow).Error
+}
+
+func (r *onboardingFlowRepo) GetCustomer(clientID, customerPhone string) (*models.CustomerOnboarding, error) {
+	var onboarding models.CustomerOnboarding
+	err := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).First(&onboarding).Error
+	return &onboarding, err
+}
+
+// CreateCustomer starts tracking a customer; a concurrent first message for the same customer is ignored
+func (r *onboardingFlowRepo) CreateCustomer(onboarding *models.CustomerOnboarding) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(onboarding).Error
+}
+
+func (r *onboardingFlowRepo) UpdateCustomer(onboarding *models.CustomerOnboarding) error {
+	return r.db.Save(onboarding).Error
+}
+
+// DeleteCustomer forgets a customer's progress so the flow runs again on their next message.
+// WhatsApp chat IDs are matched too, since webhook phones may carry the @c.us suffix.
+func (r *onboardingFlowRepo) DeleteCustomer(clientID, customerPhone string) error {
+	return r.db.Where("client_id = ? AND customer_phone IN ?", clientID, []string{customerPhone, customerPhone + "@c.us"}).
+		Delete(&models.CustomerOnboarding{}).Error
+}
@@ -1,20 +1,45 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/google/uuid"
+	"gorm.io/datatypes"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type OrderRepo interface {
 	Create(order *models.Order) error
 	GetByID(id string) (*models.Order, error)
 	GetByOrderNumber(orderNumber string) (*models.Order, error)
+	GetByTrackingToken(token string) (*models.Order, error)
+	GetByIdempotencyKey(clientID uuid.UUID, key string) (*models.Order, error)
+	SaveIdempotencyResult(orderID uuid.UUID, result datatypes.JSON) error
 	GetByClientID(clientID string, limit int) ([]models.Order, error)
+	GetByBranchID(clientID, branchID string, limit int) ([]models.Order, error)
 	GetByCustomerPhone(clientID, customerPhone string, limit int) ([]models.Order, error)
+	CountByCustomerSince(clientID, customerPhone string, since time.Time) (int64, error)
+	GetCustomerAverageAmount(clientID, customerPhone string) (float64, int64, error)
+	GetMobileKPIs(clientID string, today, actionSince time.Time) (*models.MobileKPIs, error)
+	ListNeedingAction(clientID string, since time.Time, limit int) ([]models.Order, error)
+	ListBoard(clientID, branchID string, closedSince time.Time, limit int) ([]models.Order, error)
+	ListUpdatedSince(clientID, branchID string, since time.Time) ([]models.Order, error)
+	LatestUpdate(clientID string) (time.Time, error)
+	UpdateStage(clientID, orderID, stage string, version int) (bool, error)
+	SumProductSales(clientID string, start, end time.Time) ([]models.ProductSales, error)
+	ListForReconciliation(clientID, gateway string, start, end time.Time) ([]models.Order, error)
+	GetByOrderNumbers(clientID string, orderNumbers []string) ([]models.Order, error)
+	GetCODHistory(clientID, customerPhone string) (*models.CODHistory, error)
+	GetLatestAwaitingCOD(clientID, customerPhone string, since time.Time) (*models.Order, error)
 	UpdatePaymentStatus(orderID, status string) error
 	UpdateFulfillmentStatus(orderID, status string) error
 	Update(order *models.Order) error
+	ReserveRefund(orderID uuid.UUID, amount float64) (bool, error)
+	ReleaseRefund(orderID uuid.UUID, amount float64) error
+	RecordRefund(order *models.Order, refund *models.OrderRefund, reserved float64) (bool, error)
+	ListRefunds(orderID uuid.UUID) ([]models.OrderRefund, error)
 	Delete(id string) error
 }
 
@@ -47,6 +72,23 @@ func (r *orderRepo) GetByOrderNumber(orderNumber string) (*models.Order, error)
 	return &order, err
 }
 
+func (r *orderRepo) GetByIdempotencyKey(clientID uuid.UUID, key string) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Where("client_id = ? AND idempotency_key = ?", clientID, key).First(&order).Error
+	return &order, err
+}
+
+// SaveIdempotencyResult stores the payment result returned with an order created under an idempotency key
+func (r *orderRepo) SaveIdempotencyResult(orderID uuid.UUID, result datatypes.JSON) error {
+	return r.db.Model(&models.Order{}).Where("id = ?", orderID).UpdateColumn("idempotency_result", result).Error
+}
+
+func (r *orderRepo) GetByTrackingToken(token string) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Where("tracking_token = ?", token).First(&order).Error
+	return &order, err
+}
+
 func (r *orderRepo) GetByClientID(clientID string, limit int) ([]models.Order, error) {
 	var orders []models.Order
 	query := r.db.Where("client_id = ?", clientID).
@@ -60,6 +102,19 @@ func (r *orderRepo) GetByClientID(clientID string, limit int) ([]models.Order, e
 	return orders, err
 }
 
+func (r *orderRepo) GetByBranchID(clientID, branchID string, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Where("client_id = ? AND branch_id = ?", clientID, branchID).
+		Order("created_at DESC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
 func (r *orderRepo) GetByCustomerPhone(clientID, customerPhone string, limit int) ([]models.Order, error) {
 	var orders []models.Order
 	query := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).
@@ -73,6 +128,191 @@ func (r *orderRepo) GetByCustomerPhone(clientID, customerPhone string, limit int
 	return orders, err
 }
 
+func (r *orderRepo) CountByCustomerSince(clientID, customerPhone string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Order{}).
+		Where("client_id = ? AND customer_phone = ? AND created_at >= ?", clientID, customerPhone, since).
+		Count(&count).Error
+	return count, err
+}
+
+// GetCustomerAverageAmount returns the average total and order count of a customer's non-cancelled orders
+func (r *orderRepo) GetCustomerAverageAmount(clientID, customerPhone string) (float64, int64, error) {
+	var result struct {
+		Average float64
+		Count   int64
+	}
+	err := r.db.Model(&models.Order{}).
+		Select("COALESCE(AVG(total_amount), 0) AS average, COUNT(*) AS count").
+		Where("client_id = ? AND customer_phone = ? AND payment_status <> ?", clientID, customerPhone, models.PaymentStatusCancelled).
+		Scan(&result).Error
+	return result.Average, result.Count, err
+}
+
+// GetMobileKPIs returns today's order numbers and the counts of live orders (created since actionSince) waiting on the admin
+func (r *orderRepo) GetMobileKPIs(clientID string, today, actionSince time.Time) (*models.MobileKPIs, error) {
+	var kpis models.MobileKPIs
+	err := r.db.Model(&models.Order{}).
+		Select(`COUNT(*) FILTER (WHERE created_at >= @today) AS orders,
+			COUNT(*) FILTER (WHERE payment_status = @paid AND paid_at >= @today) AS paid,
+			COALESCE(SUM(total_amount) FILTER (WHERE payment_status = @paid AND paid_at >= @today), 0) AS revenue,
+			COUNT(*) FILTER (WHERE payment_status = @pending AND created_at >= @since) AS pending_payment,
+			COUNT(*) FILTER (WHERE review_status = @review AND payment_status <> @cancelled AND created_at >= @since) AS needs_review,
+			COUNT(*) FILTER (WHERE payment_status = @paid AND fulfillment_status IN @to_ship) AS to_ship`,
+			map[string]interface{}{
+				"today":      today,
+				"since":      actionSince,
+				"paid":       models.PaymentStatusPaid,
+				"pending":    models.PaymentStatusPending,
+				"cancelled":  models.PaymentStatusCancelled,
+				"review":     models.ReviewStatusNeedsReview,
+				"to_ship":    []string{models.FulfillmentStatusProcessing, models.FulfillmentStatusPacked},
+			}).
+		Where("client_id = ? AND is_test = ?", clientID, false).
+		Scan(&kpis).Error
+	return &kpis, err
+}
+
+// ListNeedingAction lists orders awaiting review, payment confirmation or shipping, oldest first
+func (r *orderRepo) ListNeedingAction(clientID string, since time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Where("client_id = ? AND is_test = ?", clientID, false).
+		Where(
+			r.db.Where("created_at >= ? AND review_status = ? AND payment_status <> ?", since, models.ReviewStatusNeedsReview, models.PaymentStatusCancelled).
+				Or("created_at >= ? AND payment_status = ?", since, models.PaymentStatusPending).
+				Or("payment_status = ? AND fulfillment_status IN ?", models.PaymentStatusPaid, []string{models.FulfillmentStatusProcessing, models.FulfillmentStatusPacked}),
+		).
+		Order("created_at ASC")
+
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Find(&orders).Error
+	return orders, err
+}
+
+// ListBoard lists non-test orders for the order board: every open order plus orders delivered or
+// cancelled since closedSince, at most limit per stage, oldest first
+func (r *orderRepo) ListBoard(clientID, branchID string, closedSince time.Time, limit int) ([]models.Order, error) {
+	ranked := r.db.Model(&models.Order{}).
+		Select("*, ROW_NUMBER() OVER (PARTITION BY fulfillment_status ORDER BY created_at ASC) AS stage_rank").
+		Where("client_id = ? AND is_test = ?", clientID, false).
+		Where("(fulfillment_status NOT IN ? OR updated_at >= ?)",
+			[]string{models.FulfillmentStatusDelivered, models.FulfillmentStatusCancelled}, closedSince)
+	if branchID != "" {
+		ranked = ranked.Where("branch_id = ?", branchID)
+	}
+
+	var orders []models.Order
+	err := r.db.Table("(?) AS board", ranked).
+		Where("stage_rank <= ?", limit).
+		Order("created_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+// ListUpdatedSince lists non-test orders changed after since, oldest change first
+func (r *orderRepo) ListUpdatedSince(clientID, branchID string, since time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	query := r.db.Where("client_id = ? AND is_test = ? AND updated_at > ?", clientID, false, since)
+	if branchID != "" {
+		query = query.Where("branch_id = ?", branchID)
+	}
+	err := query.Order("updated_at ASC").Find(&orders).Error
+	return orders, err
+}
+
+// LatestUpdate returns when the client's most recently changed order was updated (zero without orders)
+func (r *orderRepo) LatestUpdate(clientID string) (time.Time, error) {
+	var latest *time.Time
+	err := r.db.Model(&models.Order{}).
+		Select("MAX(updated_at)").
+		Where("client_id = ?", clientID).
+		Scan(&latest).Error
+	if err != nil || latest == nil {
+		return time.Time{}, err
+	}
+	return *latest, nil
+}
+
+// UpdateStage moves an order to another fulfillment stage if it is still at version; false means the order
+// changed (or doesn't exist) since the caller read it
+func (r *orderRepo) UpdateStage(clientID, orderID, stage string, version int) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ? AND client_id = ? AND version = ?", orderID, clientID, version).
+		Update("fulfillment_status", stage)
+	return result.RowsAffected == 1, result.Error
+}
+
+// SumProductSales returns units sold per order item (product ID and name) in paid, non-test orders created in the period
+func (r *orderRepo) SumProductSales(clientID string, start, end time.Time) ([]models.ProductSales, error) {
+	var sales []models.ProductSales
+	err := r.db.Table("saas_orders AS o, jsonb_array_elements(o.items) AS item").
+		Select(`item->>'product_id' AS product_id,
+			item->>'product_name' AS product_name,
+			COALESCE(SUM((item->>'quantity')::int), 0) AS units_sold,
+			COUNT(DISTINCT o.id) AS orders,
+			COALESCE(SUM((item->>'subtotal')::numeric), 0) AS revenue`).
+		Where("o.client_id = ? AND o.is_test = ? AND o.payment_status = ? AND o.created_at BETWEEN ? AND ?",
+			clientID, false, models.PaymentStatusPaid, start, end).
+		Group("item->>'product_id', item->>'product_name'").
+		Scan(&sales).Error
+	return sales, err
+}
+
+// ListForReconciliation returns non-test gateway orders paid or created in the period
+func (r *orderRepo) ListForReconciliation(clientID, gateway string, start, end time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("client_id = ? AND payment_gateway = ? AND is_test = ?", clientID, gateway, false).
+		Where("(paid_at >= ? AND paid_at < ?) OR (created_at >= ? AND created_at < ?)", start, end, start, end).
+		Order("created_at ASC").
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *orderRepo) GetByOrderNumbers(clientID string, orderNumbers []string) ([]models.Order, error) {
+	var orders []models.Order
+	if len(orderNumbers) == 0 {
+		return orders, nil
+	}
+	err := r.db.Where("client_id = ? AND order_number IN ?", clientID, orderNumbers).
+		Find(&orders).Error
+	return orders, err
+}
+
+// GetCODHistory counts the customer's paid orders and open and cancelled COD orders (test orders excluded)
+func (r *orderRepo) GetCODHistory(clientID, customerPhone string) (*models.CODHistory, error) {
+	var history models.CODHistory
+	err := r.db.Model(&models.Order{}).
+		Select(`COUNT(*) FILTER (WHERE payment_status = @paid) AS paid_orders,
+			COUNT(*) FILTER (WHERE payment_method = @cod AND payment_status IN (@pending, @pendingCOD)) AS open_cod,
+			COUNT(*) FILTER (WHERE payment_method = @cod AND payment_status = @cancelled) AS cancelled_cod`,
+			map[string]interface{}{
+				"paid":       models.PaymentStatusPaid,
+				"cod":        models.PaymentMethodCOD,
+				"pending":    models.PaymentStatusPending,
+				"pendingCOD": models.PaymentStatusPendingCOD,
+				"cancelled":  models.PaymentStatusCancelled,
+			}).
+		Where("client_id = ? AND customer_phone = ? AND is_test = ?", clientID, customerPhone, false).
+		Scan(&history).Error
+	return &history, err
+}
+
+// GetLatestAwaitingCOD returns the customer's newest COD order created since the given time that the customer hasn't confirmed yet
+func (r *orderRepo) GetLatestAwaitingCOD(clientID, customerPhone string, since time.Time) (*models.Order, error) {
+	var order models.Order
+	err := r.db.Where("client_id = ? AND customer_phone = ? AND payment_method = ? AND payment_status = ? AND cod_confirmed_at IS NULL AND created_at >= ?",
+		clientID, customerPhone, models.PaymentMethodCOD, models.PaymentStatusPending, since).
+		Order("created_at DESC").
+		First(&order).Error
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
 func (r *orderRepo) UpdatePaymentStatus(orderID, status string) error {
 	return r.db.Model(&models.Order{}).
 		Where("id = ?", orderID).
@@ -89,6 +329,65 @@ func (r *orderRepo) Update(order *models.Order) error {
 	return r.db.Save(order).Error
 }
 
+// ReserveRefund adds amount to the refunded amount of a paid order before the gateway is asked to refund
+// it, so concurrent refunds can't exceed the total. It reports false when the order isn't paid or not
+// that much is left to refund.
+func (r *orderRepo) ReserveRefund(orderID uuid.UUID, amount float64) (bool, error) {
+	result := r.db.Model(&models.Order{}).
+		Where("id = ? AND payment_status IN ? AND refunded_amount + ? <= total_amount", orderID,
+			[]string{models.PaymentStatusPaid, models.PaymentStatusPartiallyRefunded}, amount).
+		Update("refunded_amount", gorm.Expr("refunded_amount + ?", amount))
+	return result.RowsAffected > 0, result.Error
+}
+
+// ReleaseRefund gives back an amount reserved for a refund the gateway didn't make
+func (r *orderRepo) ReleaseRefund(orderID uuid.UUID, amount float64) error {
+	return r.db.Model(&models.Order{}).
+		Where("id = ?", orderID).
+		Update("refunded_amount", gorm.Expr("GREATEST(refunded_amount - ?, 0)", amount)).Error
+}
+
+// RecordRefund saves a refund made against a reserved amount, settling the order's refunded amount on what
+// the gateway refunded and updating its payment status. A full refund cancels fulfillment that hasn't
+// shipped, reported by true. Only the refund columns are written; order is reloaded with the result.
+func (r *orderRepo) RecordRefund(order *models.Order, refund *models.OrderRefund, reserved float64) (bool, error) {
+	cancelled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(order, "id = ?", order.ID).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(refund).Error; err != nil {
+			return err
+		}
+
+		refunded := order.RefundedAmount + refund.Amount - reserved
+		updates := map[string]interface{}{
+			"refunded_amount": gorm.Expr("refunded_amount + ?", refund.Amount-reserved),
+			"refunded_at":     refund.CreatedAt,
+			"payment_status":  models.PaymentStatusPartiallyRefunded,
+		}
+		if refunded >= order.TotalAmount {
+			updates["payment_status"] = models.PaymentStatusRefunded
+			if order.FulfillmentStatus == models.FulfillmentStatusPending || order.FulfillmentStatus == models.FulfillmentStatusProcessing {
+				updates["fulfillment_status"] = models.FulfillmentStatusCancelled
+				cancelled = true
+			}
+		}
+		if err := tx.Model(order).Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.First(order, "id = ?", order.ID).Error
+	})
+	return cancelled, err
+}
+
+// ListRefunds returns an order's refunds oldest first
+func (r *orderRepo) ListRefunds(orderID uuid.UUID) ([]models.OrderRefund, error) {
+	var refunds []models.OrderRefund
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC").Find(&refunds).Error
+	return refunds, err
+}
+
 func (r *orderRepo) Delete(id string) error {
 	uid, err := uuid.Parse(id)
 	if err != nil {
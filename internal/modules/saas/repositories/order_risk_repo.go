@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderRiskRepo interface {
+	GetByClientID(clientID string) (*models.OrderRiskRule, error)
+	Upsert(rule *models.OrderRiskRule) error
+}
+
+type orderRiskRepo struct {
+	db *gorm.DB
+}
+
+func NewOrderRiskRepo(db *gorm.DB) OrderRiskRepo {
+	return &orderRiskRepo{db: db}
+}
+
+func (r *orderRiskRepo) GetByClientID(clientID string) (*models.OrderRiskRule, error) {
+	var rule models.OrderRiskRule
+	err := r.db.Where("client_id = ?", clientID).First(&rule).Error
+	return &rule, err
+}
+
+func (r *orderRiskRepo) Upsert(rule *models.OrderRiskRule) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "review_threshold", "max_order_amount", "value_multiplier",
+			"min_order_history", "velocity_max_orders", "velocity_window_minutes",
+			"proof_amount_tolerance", "blocked_phones", "updated_at",
+		}),
+	}).Create(rule).Error
+}
@@ -0,0 +1,29 @@
+package repositories
+
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// OwnershipRepo looks up the client a row belongs to, for tenant access checks
+type OwnershipRepo interface {
+	ClientOf(table, column, value string) (uuid.UUID, error)
+}
+
+type ownershipRepo struct {
+	db *gorm.DB
+}
+
+func NewOwnershipRepo(db *gorm.DB) OwnershipRepo {
+	return &ownershipRepo{db: db}
+}
+
+// ClientOf returns the client_id of the row of table whose column has value; table and column come
+// from code, never from the request. gorm.ErrRecordNotFound is returned when there is no such row.
+func (r *ownershipRepo) ClientOf(table, column, value string) (uuid.UUID, error) {
+	var row struct {
+		ClientID uuid.UUID
+	}
+	err := r.db.Table(table).Select("client_id").Where(column+" = ?", value).Take(&row).Error
+	return row.ClientID, err
+}
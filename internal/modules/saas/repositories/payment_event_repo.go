@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentEventRepo interface {
+	Create(event *models.PaymentEvent) error
+	GetByID(id uuid.UUID) (*models.PaymentEvent, error)
+	Update(event *models.PaymentEvent) error
+	List(filter models.PaymentEventFilter) ([]models.PaymentEvent, error)
+}
+
+type paymentEventRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentEventRepo(db *gorm.DB) PaymentEventRepo {
+	return &paymentEventRepo{db: db}
+}
+
+func (r *paymentEventRepo) Create(event *models.PaymentEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *paymentEventRepo) GetByID(id uuid.UUID) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	err := r.db.First(&event, "id = ?", id).Error
+	return &event, err
+}
+
+func (r *paymentEventRepo) Update(event *models.PaymentEvent) error {
+	return r.db.Save(event).Error
+}
+
+// List returns payment events newest first
+func (r *paymentEventRepo) List(filter models.PaymentEventFilter) ([]models.PaymentEvent, error) {
+	var events []models.PaymentEvent
+	query := r.db.Order("created_at DESC").Limit(filter.Limit)
+	if filter.Gateway != "" {
+		query = query.Where("gateway = ?", filter.Gateway)
+	}
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.Result != "" {
+		query = query.Where("result = ?", filter.Result)
+	}
+	if filter.SignatureStatus != "" {
+		query = query.Where("signature_status = ?", filter.SignatureStatus)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	err := query.Find(&events).Error
+	return events, err
+}
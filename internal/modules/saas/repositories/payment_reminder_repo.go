@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentReminderRepo interface {
+	GetSettings(clientID uuid.UUID) (*models.PaymentReminderSettings, error)
+	UpsertSettings(settings *models.PaymentReminderSettings) error
+	ListEnabledSettings() ([]models.PaymentReminderSettings, error)
+	ListRemindableOrders(clientID uuid.UUID, now time.Time, horizon, defaultExpiry time.Duration, limit int) ([]models.Order, error)
+	ListForOrders(orderIDs []uuid.UUID) ([]models.PaymentReminder, error)
+	Claim(reminder *models.PaymentReminder) (bool, error)
+	Update(reminder *models.PaymentReminder) error
+	MarkConversions() (int64, error)
+	StepStats(clientID uuid.UUID, start, end time.Time) ([]models.PaymentReminderStepStats, error)
+	OrderStats(clientID uuid.UUID, start, end time.Time) (reminded, converted int64, err error)
+}
+
+type paymentReminderRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentReminderRepo(db *gorm.DB) PaymentReminderRepo {
+	return &paymentReminderRepo{db: db}
+}
+
+func (r *paymentReminderRepo) GetSettings(clientID uuid.UUID) (*models.PaymentReminderSettings, error) {
+	var settings models.PaymentReminderSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *paymentReminderRepo) UpsertSettings(settings *models.PaymentReminderSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "steps", "default_expiry_minutes", "updated_at"}),
+	}).Create(settings).Error
+}
+
+func (r *paymentReminderRepo) ListEnabledSettings() ([]models.PaymentReminderSettings, error) {
+	var settings []models.PaymentReminderSettings
+	err := r.db.Where("enabled").Find(&settings).Error
+	return settings, err
+}
+
+// ListRemindableOrders returns the client's orders still waiting for an online payment whose link expires
+// within the horizon. Orders without a gateway expiry are taken to expire defaultExpiry after creation
+func (r *paymentReminderRepo) ListRemindableOrders(clientID uuid.UUID, now time.Time, horizon, defaultExpiry time.Duration, limit int) ([]models.Order, error) {
+	expiry := "COALESCE(payment_expires_at, created_at + make_interval(secs => ?))"
+	var orders []models.Order
+	err := r.db.Where("client_id = ? AND payment_status = ?", clientID, models.PaymentStatusPending).
+		Where("COALESCE(payment_method, '') <> ? AND COALESCE(payment_link, '') <> ''", models.PaymentMethodCOD).
+		Where("review_status <> ?", models.ReviewStatusNeedsReview).
+		Where(expiry+" > ?", defaultExpiry.Seconds(), now).
+		Where(expiry+" <= ?", defaultExpiry.Seconds(), now.Add(horizon)).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *paymentReminderRepo) ListForOrders(orderIDs []uuid.UUID) ([]models.PaymentReminder, error) {
+	var reminders []models.PaymentReminder
+	if len(orderIDs) == 0 {
+		return reminders, nil
+	}
+	err := r.db.Where("order_id IN ?", orderIDs).Order("sent_at ASC").Find(&reminders).Error
+	return reminders, err
+}
+
+// Claim records a reminder before it is sent; false means another worker already claimed the step
+func (r *paymentReminderRepo) Claim(reminder *models.PaymentReminder) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "order_id"}, {Name: "minutes_before"}},
+		DoNothing: true,
+	}).Create(reminder)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *paymentReminderRepo) Update(reminder *models.PaymentReminder) error {
+	return r.db.Save(reminder).Error
+}
+
+// MarkConversions credits each payment to the last reminder sent before it
+func (r *paymentReminderRepo) MarkConversions() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE saas_payment_reminders r SET converted_at = o.paid_at
+		FROM saas_orders o
+		WHERE r.order_id = o.id AND r.status = ? AND r.converted_at IS NULL
+			AND o.payment_status = ? AND o.paid_at >= r.sent_at
+			AND NOT EXISTS (
+				SELECT 1 FROM saas_payment_reminders later
+				WHERE later.order_id = r.order_id AND later.status = ?
+					AND later.sent_at > r.sent_at AND later.sent_at <= o.paid_at
+			)`,
+		models.PaymentReminderSent, models.PaymentStatusPaid, models.PaymentReminderSent)
+	return result.RowsAffected, result.Error
+}
+
+// StepStats aggregates the reminders sent in a range per ladder step
+func (r *paymentReminderRepo) StepStats(clientID uuid.UUID, start, end time.Time) ([]models.PaymentReminderStepStats, error) {
+	var stats []models.PaymentReminderStepStats
+	err := r.db.Model(&models.PaymentReminder{}).
+		Select(`minutes_before,
+			COUNT(*) FILTER (WHERE status = ?) AS sent,
+			COUNT(*) FILTER (WHERE status = ?) AS failed,
+			COUNT(converted_at) AS converted,
+			COALESCE(AVG(EXTRACT(EPOCH FROM (converted_at - sent_at)) / 60) FILTER (WHERE converted_at IS NOT NULL), 0) AS avg_minutes_to_pay`,
+			models.PaymentReminderSent, models.PaymentReminderFailed).
+		Where("client_id = ? AND sent_at BETWEEN ? AND ?", clientID, start, end).
+		Group("minutes_before").
+		Order("minutes_before DESC").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// OrderStats counts the orders reminded in a range and how many of them were paid after a reminder
+func (r *paymentReminderRepo) OrderStats(clientID uuid.UUID, start, end time.Time) (int64, int64, error) {
+	var row struct {
+		Reminded  int64
+		Converted int64
+	}
+	err := r.db.Model(&models.PaymentReminder{}).
+		Select("COUNT(DISTINCT order_id) FILTER (WHERE status = ?) AS reminded, COUNT(DISTINCT order_id) FILTER (WHERE converted_at IS NOT NULL) AS converted",
+			models.PaymentReminderSent).
+		Where("client_id = ? AND sent_at BETWEEN ? AND ?", clientID, start, end).
+		Scan(&row).Error
+	return row.Reminded, row.Converted, err
+}
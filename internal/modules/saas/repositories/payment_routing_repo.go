@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRoutingRepo interface {
+	GetByClientID(clientID string) (*models.PaymentRoutingPolicy, error)
+	Upsert(policy *models.PaymentRoutingPolicy) error
+}
+
+type paymentRoutingRepo struct {
+	db *gorm.DB
+}
+
+func NewPaymentRoutingRepo(db *gorm.DB) PaymentRoutingRepo {
+	return &paymentRoutingRepo{db: db}
+}
+
+func (r *paymentRoutingRepo) GetByClientID(clientID string) (*models.PaymentRoutingPolicy, error) {
+	var policy models.PaymentRoutingPolicy
+	err := r.db.Where("client_id = ?", clientID).First(&policy).Error
+	return &policy, err
+}
+
+func (r *paymentRoutingRepo) Upsert(policy *models.PaymentRoutingPolicy) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "default_gateway", "rules", "updated_at"}),
+	}).Create(policy).Error
+}
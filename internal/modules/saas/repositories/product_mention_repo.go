@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ProductMentionRepo interface {
+	CreateBatch(mentions []models.ProductMention) error
+	CountByProduct(clientID uuid.UUID, start, end time.Time) ([]models.ProductMentionCount, error)
+}
+
+type productMentionRepo struct {
+	db *gorm.DB
+}
+
+func NewProductMentionRepo(db *gorm.DB) ProductMentionRepo {
+	return &productMentionRepo{db: db}
+}
+
+func (r *productMentionRepo) CreateBatch(mentions []models.ProductMention) error {
+	if len(mentions) == 0 {
+		return nil
+	}
+	return r.db.Create(&mentions).Error
+}
+
+// CountByProduct returns mention and distinct customer counts per product, most mentioned first
+func (r *productMentionRepo) CountByProduct(clientID uuid.UUID, start, end time.Time) ([]models.ProductMentionCount, error) {
+	var counts []models.ProductMentionCount
+	err := r.db.Model(&models.ProductMention{}).
+		Select("product_id, COUNT(*) AS mentions, COUNT(DISTINCT customer_phone) AS askers").
+		Where("client_id = ? AND created_at BETWEEN ? AND ?", clientID, start, end).
+		Group("product_id").
+		Order("mentions DESC").
+		Scan(&counts).Error
+	return counts, err
+}
@@ -12,6 +12,7 @@ type ProductRepo interface {
 	Create(product *models.Product) error
 	GetByID(id string) (*models.Product, error)
 	GetBySKU(clientID uuid.UUID, sku string) (*models.Product, error)
+	GetByName(clientID uuid.UUID, name string) (*models.Product, error)
 	List(filter models.ProductFilter) ([]models.Product, int64, error)
 	Update(product *models.Product) error
 	Delete(id string) error           // Soft delete
@@ -55,6 +56,16 @@ func (r *productRepo) GetBySKU(clientID uuid.UUID, sku string) (*models.Product,
 	return &product, nil
 }
 
+// GetByName finds a product by name (case-insensitive), as the bot refers to products by name
+func (r *productRepo) GetByName(clientID uuid.UUID, name string) (*models.Product, error) {
+	var product models.Product
+	err := r.db.Where("client_id = ? AND LOWER(name) = LOWER(?)", clientID, name).First(&product).Error
+	if err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
 func (r *productRepo) List(filter models.ProductFilter) ([]models.Product, int64, error) {
 	var products []models.Product
 	var total int64
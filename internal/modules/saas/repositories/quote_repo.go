@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type QuoteRepo interface {
+	Create(quote *models.Quote) error
+	GetByID(id string) (*models.Quote, error)
+	GetByToken(token string) (*models.Quote, error)
+	GetLatestSent(clientID uuid.UUID, customerPhone string) (*models.Quote, error)
+	List(clientID, status string, limit int) ([]models.Quote, error)
+	Update(quote *models.Quote) error
+	ExpireOverdue(clientID uuid.UUID) (int64, error)
+	SummarizeByStatus(clientID uuid.UUID, since time.Time) (map[string]QuoteStatusSummary, error)
+}
+
+// QuoteStatusSummary is the count and value of quotes in a status
+type QuoteStatusSummary struct {
+	Count  int64
+	Amount float64
+}
+
+type quoteRepo struct {
+	db *gorm.DB
+}
+
+func NewQuoteRepo(db *gorm.DB) QuoteRepo {
+	return &quoteRepo{db: db}
+}
+
+func (r *quoteRepo) Create(quote *models.Quote) error {
+	return r.db.Create(quote).Error
+}
+
+func (r *quoteRepo) GetByID(id string) (*models.Quote, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote ID: %w", err)
+	}
+
+	var quote models.Quote
+	err = r.db.First(&quote, "id = ?", uid).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *quoteRepo) GetByToken(token string) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.Where("access_token = ?", token).First(&quote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+// GetLatestSent returns the customer's most recent quote awaiting a response
+func (r *quoteRepo) GetLatestSent(clientID uuid.UUID, customerPhone string) (*models.Quote, error) {
+	var quote models.Quote
+	err := r.db.Where("client_id = ? AND customer_phone = ? AND status = ?", clientID, customerPhone, models.QuoteStatusSent).
+		Order("sent_at DESC").
+		First(&quote).Error
+	if err != nil {
+		return nil, err
+	}
+	return &quote, nil
+}
+
+func (r *quoteRepo) List(clientID, status string, limit int) ([]models.Quote, error) {
+	var quotes []models.Quote
+	query := r.db.Where("client_id = ?", clientID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Order("created_at DESC").Find(&quotes).Error
+	return quotes, err
+}
+
+func (r *quoteRepo) Update(quote *models.Quote) error {
+	return r.db.Save(quote).Error
+}
+
+// ExpireOverdue marks sent quotes past their validity as expired
+func (r *quoteRepo) ExpireOverdue(clientID uuid.UUID) (int64, error) {
+	result := r.db.Model(&models.Quote{}).
+		Where("client_id = ? AND status = ? AND valid_until < ?", clientID, models.QuoteStatusSent, time.Now()).
+		Updates(map[string]interface{}{"status": models.QuoteStatusExpired})
+	return result.RowsAffected, result.Error
+}
+
+func (r *quoteRepo) SummarizeByStatus(clientID uuid.UUID, since time.Time) (map[string]QuoteStatusSummary, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+		Amount float64
+	}
+	err := r.db.Model(&models.Quote{}).
+		Select("status, COUNT(*) AS count, COALESCE(SUM(total_amount), 0) AS amount").
+		Where("client_id = ? AND created_at >= ?", clientID, since).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summary := make(map[string]QuoteStatusSummary, len(rows))
+	for _, row := range rows {
+		summary[row.Status] = QuoteStatusSummary{Count: row.Count, Amount: row.Amount}
+	}
+	return summary, nil
+}
@@ -0,0 +1,33 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReactionSettingsRepo interface {
+	GetByClientID(clientID string) (*models.ReactionSettings, error)
+	Upsert(settings *models.ReactionSettings) error
+}
+
+type reactionSettingsRepo struct {
+	db *gorm.DB
+}
+
+func NewReactionSettingsRepo(db *gorm.DB) ReactionSettingsRepo {
+	return &reactionSettingsRepo{db: db}
+}
+
+func (r *reactionSettingsRepo) GetByClientID(clientID string) (*models.ReactionSettings, error) {
+	var settings models.ReactionSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *reactionSettingsRepo) Upsert(settings *models.ReactionSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "intents", "updated_at"}),
+	}).Create(settings).Error
+}
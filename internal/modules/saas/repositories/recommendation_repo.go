@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CoPurchase is a product and how many orders had it
+type CoPurchase struct {
+	ProductName string
+	Orders      int64
+}
+
+type RecommendationRepo interface {
+	GetSettings(clientID string) (*models.RecommendationSettings, error)
+	UpsertSettings(settings *models.RecommendationSettings) error
+
+	CoPurchased(clientID uuid.UUID, basket []string, since time.Time, limit int) ([]CoPurchase, error)
+	CustomerProducts(clientID uuid.UUID, customerPhone string, since time.Time, limit int) ([]CoPurchase, error)
+
+	Create(recommendations []models.ProductRecommendation) error
+	LastSuggestedAt(clientID uuid.UUID, customerPhone string) (*time.Time, error)
+	SuggestedSince(clientID uuid.UUID, customerPhone string, since time.Time) ([]string, error)
+	MarkAddedToCart(clientID uuid.UUID, customerPhone, productName string, since, at time.Time) (int64, error)
+	MarkConverted(clientID uuid.UUID, customerPhone string, products []string, orderID uuid.UUID, since, at time.Time) (int64, error)
+	Stats(clientID uuid.UUID, from, to time.Time, groupBy string, limit int) ([]models.RecommendationStatsBreakdown, error)
+}
+
+type recommendationRepo struct {
+	db *gorm.DB
+}
+
+func NewRecommendationRepo(db *gorm.DB) RecommendationRepo {
+	return &recommendationRepo{db: db}
+}
+
+func (r *recommendationRepo) GetSettings(clientID string) (*models.RecommendationSettings, error) {
+	var settings models.RecommendationSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *recommendationRepo) UpsertSettings(settings *models.RecommendationSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"enabled", "after_add_to_cart", "after_checkout", "max_suggestions", "use_llm", "cooldown_minutes", "updated_at",
+		}),
+	}).Create(settings).Error
+}
+
+// CoPurchased counts the orders since the given time that had another product together with any of the
+// basket products (lowercase names), most often ordered first. Sandbox orders are left out.
+func (r *recommendationRepo) CoPurchased(clientID uuid.UUID, basket []string, since time.Time, limit int) ([]CoPurchase, error) {
+	var rows []CoPurchase
+	if len(basket) == 0 {
+		return rows, nil
+	}
+	err := r.db.Raw(`
+		SELECT other.item->>'product_name' AS product_name, COUNT(DISTINCT o.id) AS orders
+		FROM saas_orders o
+		CROSS JOIN LATERAL jsonb_array_elements(o.items) AS basket(item)
+		CROSS JOIN LATERAL jsonb_array_elements(o.items) AS other(item)
+		WHERE o.client_id = ? AND o.is_test = FALSE AND o.created_at >= ?
+			AND LOWER(basket.item->>'product_name') IN ?
+			AND LOWER(other.item->>'product_name') NOT IN ?
+		GROUP BY other.item->>'product_name'
+		ORDER BY orders DESC, product_name
+		LIMIT ?`, clientID, since, basket, basket, limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+// CustomerProducts returns the products a customer ordered since the given time, most often ordered first
+func (r *recommendationRepo) CustomerProducts(clientID uuid.UUID, customerPhone string, since time.Time, limit int) ([]CoPurchase, error) {
+	var rows []CoPurchase
+	err := r.db.Raw(`
+		SELECT item->>'product_name' AS product_name, COUNT(DISTINCT o.id) AS orders
+		FROM saas_orders o
+		CROSS JOIN LATERAL jsonb_array_elements(o.items) AS item
+		WHERE o.client_id = ? AND o.customer_phone = ? AND o.is_test = FALSE AND o.created_at >= ?
+		GROUP BY item->>'product_name'
+		ORDER BY orders DESC, product_name
+		LIMIT ?`, clientID, customerPhone, since, limit).
+		Scan(&rows).Error
+	return rows, err
+}
+
+func (r *recommendationRepo) Create(recommendations []models.ProductRecommendation) error {
+	if len(recommendations) == 0 {
+		return nil
+	}
+	return r.db.Create(&recommendations).Error
+}
+
+// LastSuggestedAt returns when a customer was last suggested a product, nil if never
+func (r *recommendationRepo) LastSuggestedAt(clientID uuid.UUID, customerPhone string) (*time.Time, error) {
+	var recommendation models.ProductRecommendation
+	err := r.db.Select("created_at").
+		Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).
+		Order("created_at DESC").
+		Limit(1).
+		Find(&recommendation).Error
+	if err != nil || recommendation.CreatedAt.IsZero() {
+		return nil, err
+	}
+	return &recommendation.CreatedAt, nil
+}
+
+// SuggestedSince returns the lowercase names of the products suggested to a customer since the given time
+func (r *recommendationRepo) SuggestedSince(clientID uuid.UUID, customerPhone string, since time.Time) ([]string, error) {
+	var names []string
+	err := r.db.Model(&models.ProductRecommendation{}).
+		Distinct("LOWER(product_name)").
+		Where("client_id = ? AND customer_phone = ? AND created_at >= ?", clientID, customerPhone, since).
+		Pluck("LOWER(product_name)", &names).Error
+	return names, err
+}
+
+// MarkAddedToCart records that a customer added a product suggested to them since the given time
+func (r *recommendationRepo) MarkAddedToCart(clientID uuid.UUID, customerPhone, productName string, since, at time.Time) (int64, error) {
+	result := r.db.Model(&models.ProductRecommendation{}).
+		Where("client_id = ? AND customer_phone = ? AND LOWER(product_name) = LOWER(?) AND created_at >= ? AND added_to_cart_at IS NULL",
+			clientID, customerPhone, productName, since).
+		Update("added_to_cart_at", at)
+	return result.RowsAffected, result.Error
+}
+
+// MarkConverted records the order in which a customer bought products (lowercase names) suggested to them since the given time
+func (r *recommendationRepo) MarkConverted(clientID uuid.UUID, customerPhone string, products []string, orderID uuid.UUID, since, at time.Time) (int64, error) {
+	if len(products) == 0 {
+		return 0, nil
+	}
+	result := r.db.Model(&models.ProductRecommendation{}).
+		Where("client_id = ? AND customer_phone = ? AND LOWER(product_name) IN ? AND created_at >= ? AND converted_at IS NULL",
+			clientID, customerPhone, products, since).
+		Updates(map[string]interface{}{
+			"converted_at":     at,
+			"order_id":         orderID,
+			"added_to_cart_at": gorm.Expr("COALESCE(added_to_cart_at, ?)", at),
+		})
+	return result.RowsAffected, result.Error
+}
+
+// Stats counts a client's suggestions between from and to per trigger_point, ranked_by or product_name
+func (r *recommendationRepo) Stats(clientID uuid.UUID, from, to time.Time, groupBy string, limit int) ([]models.RecommendationStatsBreakdown, error) {
+	switch groupBy {
+	case "trigger_point", "ranked_by", "product_name":
+	default:
+		return nil, fmt.Errorf("cannot group recommendations by %q", groupBy)
+	}
+
+	var rows []models.RecommendationStatsBreakdown
+	err := r.db.Model(&models.ProductRecommendation{}).
+		Select(groupBy+" AS key, COUNT(*) AS suggested, COUNT(added_to_cart_at) AS added_to_cart, COUNT(converted_at) AS converted").
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Group(groupBy).
+		Order("converted DESC, suggested DESC, key").
+		Limit(limit).
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,86 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReconciliationRepo interface {
+	UpsertSettlements(settlements []models.PaymentSettlement) error
+	ListSettlements(clientID uuid.UUID, gateway string, start, end time.Time) ([]models.PaymentSettlement, error)
+	ListSettlementsByOrderNumbers(clientID uuid.UUID, gateway string, orderNumbers []string) ([]models.PaymentSettlement, error)
+	ListClientsWithGatewayOrders(gateway string, start, end time.Time) ([]uuid.UUID, error)
+	SaveReport(report *models.ReconciliationReport) error
+	GetReport(clientID uuid.UUID, date time.Time) (*models.ReconciliationReport, error)
+}
+
+type reconciliationRepo struct {
+	db *gorm.DB
+}
+
+func NewReconciliationRepo(db *gorm.DB) ReconciliationRepo {
+	return &reconciliationRepo{db: db}
+}
+
+// UpsertSettlements stores gateway transactions, refreshing the status of ones already known
+func (r *reconciliationRepo) UpsertSettlements(settlements []models.PaymentSettlement) error {
+	if len(settlements) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "gateway"}, {Name: "transaction_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"order_number", "status", "payment_type", "gross_amount", "settled_at", "updated_at"}),
+	}).Create(&settlements).Error
+}
+
+// ListSettlements returns the transactions settled in the period
+func (r *reconciliationRepo) ListSettlements(clientID uuid.UUID, gateway string, start, end time.Time) ([]models.PaymentSettlement, error) {
+	var settlements []models.PaymentSettlement
+	err := r.db.Where("client_id = ? AND gateway = ? AND settled_at >= ? AND settled_at < ?", clientID, gateway, start, end).
+		Order("settled_at ASC").
+		Find(&settlements).Error
+	return settlements, err
+}
+
+func (r *reconciliationRepo) ListSettlementsByOrderNumbers(clientID uuid.UUID, gateway string, orderNumbers []string) ([]models.PaymentSettlement, error) {
+	var settlements []models.PaymentSettlement
+	if len(orderNumbers) == 0 {
+		return settlements, nil
+	}
+	err := r.db.Where("client_id = ? AND gateway = ? AND order_number IN ?", clientID, gateway, orderNumbers).
+		Find(&settlements).Error
+	return settlements, err
+}
+
+// ListClientsWithGatewayOrders returns clients that paid or created non-test orders through the gateway in the period
+func (r *reconciliationRepo) ListClientsWithGatewayOrders(gateway string, start, end time.Time) ([]uuid.UUID, error) {
+	var clientIDs []uuid.UUID
+	err := r.db.Model(&models.Order{}).
+		Distinct("client_id").
+		Where("payment_gateway = ? AND is_test = ?", gateway, false).
+		Where("(paid_at >= ? AND paid_at < ?) OR (created_at >= ? AND created_at < ?)", start, end, start, end).
+		Pluck("client_id", &clientIDs).Error
+	return clientIDs, err
+}
+
+// SaveReport creates or replaces the client's report for the day
+func (r *reconciliationRepo) SaveReport(report *models.ReconciliationReport) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "report_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"gateway", "matched_count", "mismatch_count", "paid_total", "settled_total", "items", "generated_at", "updated_at"}),
+	}).Create(report).Error
+}
+
+func (r *reconciliationRepo) GetReport(clientID uuid.UUID, date time.Time) (*models.ReconciliationReport, error) {
+	var report models.ReconciliationReport
+	err := r.db.Where("client_id = ? AND report_date = ?", clientID, date.Format("2006-01-02")).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
@@ -0,0 +1,42 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type RegionMigrationRepo interface {
+	Create(migration *models.RegionMigration) error
+	Update(migration *models.RegionMigration) error
+	ListByClient(clientID uuid.UUID, limit int) ([]models.RegionMigration, error)
+	SetClientRegion(clientID uuid.UUID, region string) error
+}
+
+type regionMigrationRepo struct {
+	db *gorm.DB
+}
+
+func NewRegionMigrationRepo(db *gorm.DB) RegionMigrationRepo {
+	return &regionMigrationRepo{db: db}
+}
+
+func (r *regionMigrationRepo) Create(migration *models.RegionMigration) error {
+	return r.db.Create(migration).Error
+}
+
+func (r *regionMigrationRepo) Update(migration *models.RegionMigration) error {
+	return r.db.Save(migration).Error
+}
+
+// ListByClient returns a client's region migrations newest first
+func (r *regionMigrationRepo) ListByClient(clientID uuid.UUID, limit int) ([]models.RegionMigration, error) {
+	var migrations []models.RegionMigration
+	err := r.db.Where("client_id = ?", clientID).Order("created_at DESC").Limit(limit).Find(&migrations).Error
+	return migrations, err
+}
+
+// SetClientRegion records in the directory which region holds the client's data
+func (r *regionMigrationRepo) SetClientRegion(clientID uuid.UUID, region string) error {
+	return r.db.Model(&models.Client{}).Where("id = ?", clientID).Update("region", region).Error
+}
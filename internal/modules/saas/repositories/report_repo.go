@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ReportRepo aggregates a client's orders, transactions and conversations over [from, to)
+type ReportRepo interface {
+	SalesSummary(clientID uuid.UUID, from, to time.Time) (*models.SalesSummary, error)
+	SalesByDay(clientID uuid.UUID, from, to time.Time) ([]models.SalesReportDay, error)
+	TransactionSummary(clientID uuid.UUID, from, to time.Time) (*models.TransactionSummary, error)
+	TopProducts(clientID uuid.UUID, from, to time.Time, sortBy string, limit int) ([]models.ProductSales, error)
+	ConversationVolume(clientID uuid.UUID, from, to time.Time) (*models.ConversationVolume, error)
+	ConversationsByDay(clientID uuid.UUID, from, to time.Time) ([]models.ConversationReportDay, error)
+}
+
+type reportRepo struct {
+	db *gorm.DB
+}
+
+func NewReportRepo(db *gorm.DB) ReportRepo {
+	return &reportRepo{db: db}
+}
+
+// paidOrderFilter matches orders that were paid, including ones refunded since
+const paidOrderFilter = "paid_at IS NOT NULL"
+
+// SalesSummary totals the non-test orders created in the range
+func (r *reportRepo) SalesSummary(clientID uuid.UUID, from, to time.Time) (*models.SalesSummary, error) {
+	var summary models.SalesSummary
+	err := r.db.Model(&models.Order{}).
+		Select(`COUNT(*) AS orders,
+			COUNT(*) FILTER (WHERE `+paidOrderFilter+`) AS paid_orders,
+			COUNT(*) FILTER (WHERE payment_status = ?) AS cancelled_orders,
+			COALESCE(SUM(total_amount) FILTER (WHERE `+paidOrderFilter+`), 0) AS revenue,
+			COALESCE(SUM(refunded_amount) FILTER (WHERE `+paidOrderFilter+`), 0) AS refunded_amount`,
+			models.PaymentStatusCancelled).
+		Where("client_id = ? AND is_test = ? AND created_at >= ? AND created_at < ?", clientID, false, from, to).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// SalesByDay totals the non-test orders per day they were created, days without orders left out
+func (r *reportRepo) SalesByDay(clientID uuid.UUID, from, to time.Time) ([]models.SalesReportDay, error) {
+	var days []models.SalesReportDay
+	err := r.db.Model(&models.Order{}).
+		Select(`TO_CHAR(created_at, 'YYYY-MM-DD') AS date,
+			COUNT(*) AS orders,
+			COUNT(*) FILTER (WHERE `+paidOrderFilter+`) AS paid_orders,
+			COALESCE(SUM(total_amount) FILTER (WHERE `+paidOrderFilter+`), 0) AS revenue`).
+		Where("client_id = ? AND is_test = ? AND created_at >= ? AND created_at < ?", clientID, false, from, to).
+		Group("date").
+		Order("date").
+		Scan(&days).Error
+	return days, err
+}
+
+// TransactionSummary totals the transactions dated in the range
+func (r *reportRepo) TransactionSummary(clientID uuid.UUID, from, to time.Time) (*models.TransactionSummary, error) {
+	var summary models.TransactionSummary
+	err := r.db.Model(&models.Transaction{}).
+		Select(`COUNT(*) AS transactions,
+			COUNT(*) FILTER (WHERE created_from = 'ocr') AS ocr_transactions,
+			COALESCE(SUM(total_amount), 0) AS transaction_amount`).
+		Where("client_id = ? AND transaction_date >= ? AND transaction_date < ?", clientID, from, to).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// TopProducts ranks the items of paid, non-test orders created in the range by revenue or units sold
+func (r *reportRepo) TopProducts(clientID uuid.UUID, from, to time.Time, sortBy string, limit int) ([]models.ProductSales, error) {
+	order := "revenue DESC, units_sold DESC"
+	if sortBy == "units" {
+		order = "units_sold DESC, revenue DESC"
+	}
+
+	var sales []models.ProductSales
+	err := r.db.Table("saas_orders AS o, jsonb_array_elements(o.items) AS item").
+		Select(`item->>'product_id' AS product_id,
+			item->>'product_name' AS product_name,
+			COALESCE(SUM((item->>'quantity')::int), 0) AS units_sold,
+			COUNT(DISTINCT o.id) AS orders,
+			COALESCE(SUM((item->>'subtotal')::numeric), 0) AS revenue`).
+		Where("o.client_id = ? AND o.is_test = ? AND o.paid_at IS NOT NULL AND o.created_at >= ? AND o.created_at < ?",
+			clientID, false, from, to).
+		Group("item->>'product_id', item->>'product_name'").
+		Order(order).
+		Limit(limit).
+		Scan(&sales).Error
+	return sales, err
+}
+
+// ConversationVolume counts the conversation messages of the range
+func (r *reportRepo) ConversationVolume(clientID uuid.UUID, from, to time.Time) (*models.ConversationVolume, error) {
+	var summary models.ConversationVolume
+	err := r.db.Model(&models.Conversation{}).
+		Select(`COUNT(*) FILTER (WHERE message_type = @incoming) AS customer_messages,
+			COUNT(*) FILTER (WHERE message_type = @incoming AND COALESCE(ai_response, '') <> '') AS ai_replies,
+			COUNT(*) FILTER (WHERE message_type = @agent) AS agent_replies,
+			COUNT(DISTINCT customer_phone) FILTER (WHERE message_type = @incoming) AS customers`,
+			map[string]interface{}{"incoming": models.ConversationTypeIncoming, "agent": models.ConversationTypeAgent}).
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Scan(&summary).Error
+	return &summary, err
+}
+
+// ConversationsByDay counts the conversation messages per day, days without messages left out
+func (r *reportRepo) ConversationsByDay(clientID uuid.UUID, from, to time.Time) ([]models.ConversationReportDay, error) {
+	var days []models.ConversationReportDay
+	err := r.db.Model(&models.Conversation{}).
+		Select(`TO_CHAR(created_at, 'YYYY-MM-DD') AS date,
+			COUNT(*) FILTER (WHERE message_type = @incoming) AS customer_messages,
+			COUNT(*) FILTER (WHERE message_type = @incoming AND COALESCE(ai_response, '') <> '') AS ai_replies,
+			COUNT(*) FILTER (WHERE message_type = @agent) AS agent_replies,
+			COUNT(DISTINCT customer_phone) FILTER (WHERE message_type = @incoming) AS customers`,
+			map[string]interface{}{"incoming": models.ConversationTypeIncoming, "agent": models.ConversationTypeAgent}).
+		Where("client_id = ? AND created_at >= ? AND created_at < ?", clientID, from, to).
+		Group("date").
+		Order("date").
+		Scan(&days).Error
+	return days, err
+}
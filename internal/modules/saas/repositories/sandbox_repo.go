@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+)
+
+type SandboxRepo interface {
+	CreateMessage(message *models.SandboxMessage) error
+	ListMessages(clientID, phone string, since *time.Time, limit int) ([]models.SandboxMessage, error)
+	DeleteMessages(clientID string) (int64, error)
+}
+
+type sandboxRepo struct {
+	db *gorm.DB
+}
+
+func NewSandboxRepo(db *gorm.DB) SandboxRepo {
+	return &sandboxRepo{db: db}
+}
+
+func (r *sandboxRepo) CreateMessage(message *models.SandboxMessage) error {
+	return r.db.Create(message).Error
+}
+
+func (r *sandboxRepo) ListMessages(clientID, phone string, since *time.Time, limit int) ([]models.SandboxMessage, error) {
+	var messages []models.SandboxMessage
+	query := r.db.Where("client_id = ?", clientID)
+	if phone != "" {
+		query = query.Where("phone = ?", phone)
+	}
+	if since != nil {
+		query = query.Where("created_at >= ?", *since)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Order("created_at DESC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+
+	// Return oldest first so the conversation reads top to bottom
+	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
+		messages[i], messages[j] = messages[j], messages[i]
+	}
+	return messages, nil
+}
+
+func (r *sandboxRepo) DeleteMessages(clientID string) (int64, error) {
+	result := r.db.Where("client_id = ?", clientID).Delete(&models.SandboxMessage{})
+	return result.RowsAffected, result.Error
+}
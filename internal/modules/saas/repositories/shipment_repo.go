@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ShipmentRepo interface {
+	Create(shipment *models.Shipment) error
+	GetByID(id string) (*models.Shipment, error)
+	GetByOrderID(orderID string) (*models.Shipment, error)
+	List(clientID, status, driverID string, limit int) ([]models.Shipment, error)
+	ListActiveByDriver(driverID uuid.UUID) ([]models.Shipment, error)
+	Update(shipment *models.Shipment) error
+}
+
+type shipmentRepo struct {
+	db *gorm.DB
+}
+
+func NewShipmentRepo(db *gorm.DB) ShipmentRepo {
+	return &shipmentRepo{db: db}
+}
+
+func (r *shipmentRepo) Create(shipment *models.Shipment) error {
+	return r.db.Create(shipment).Error
+}
+
+func (r *shipmentRepo) GetByID(id string) (*models.Shipment, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid shipment ID: %w", err)
+	}
+
+	var shipment models.Shipment
+	err = r.db.Preload("Order").Preload("Driver").First(&shipment, "id = ?", uid).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *shipmentRepo) GetByOrderID(orderID string) (*models.Shipment, error) {
+	var shipment models.Shipment
+	err := r.db.Preload("Order").Preload("Driver").Where("order_id = ?", orderID).First(&shipment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &shipment, nil
+}
+
+func (r *shipmentRepo) List(clientID, status, driverID string, limit int) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	query := r.db.Preload("Order").Preload("Driver").Where("client_id = ?", clientID)
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if driverID != "" {
+		query = query.Where("driver_id = ?", driverID)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	err := query.Order("assigned_at DESC").Find(&shipments).Error
+	return shipments, err
+}
+
+// ListActiveByDriver returns a driver's shipments that are not delivered or cancelled, oldest first
+func (r *shipmentRepo) ListActiveByDriver(driverID uuid.UUID) ([]models.Shipment, error) {
+	var shipments []models.Shipment
+	err := r.db.Preload("Order").
+		Where("driver_id = ? AND status IN ?", driverID, []string{models.ShipmentStatusAssigned, models.ShipmentStatusPickedUp}).
+		Order("assigned_at ASC").
+		Find(&shipments).Error
+	return shipments, err
+}
+
+func (r *shipmentRepo) Update(shipment *models.Shipment) error {
+	return r.db.Omit("Order", "Driver").Save(shipment).Error
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SLARepo interface {
+	GetSettings(clientID uuid.UUID) (*models.SLASettings, error)
+	UpsertSettings(settings *models.SLASettings) error
+	GetOpenThread(clientID uuid.UUID, customerPhone string) (*models.SLAThread, error)
+	OpenThread(thread *models.SLAThread) (*models.SLAThread, error)
+	UpdateThread(thread *models.SLAThread) error
+	ListOpenThreads(limit int) ([]models.SLAThread, error)
+	CountOpenThreads(clientID uuid.UUID) (int64, error)
+	FirstResponseStats(clientID uuid.UUID, start, end time.Time) (*models.SLADurationStats, error)
+	ResolutionStats(clientID uuid.UUID, start, end time.Time) (*models.SLADurationStats, error)
+	FirstResponseStatsByAgent(clientID uuid.UUID, start, end time.Time, targetSeconds int) ([]models.SLADurationStats, error)
+	ResolutionStatsByAgent(clientID uuid.UUID, start, end time.Time, targetSeconds int) ([]models.SLADurationStats, error)
+}
+
+type slaRepo struct {
+	db *gorm.DB
+}
+
+func NewSLARepo(db *gorm.DB) SLARepo {
+	return &slaRepo{db: db}
+}
+
+func (r *slaRepo) GetSettings(clientID uuid.UUID) (*models.SLASettings, error) {
+	var settings models.SLASettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *slaRepo) UpsertSettings(settings *models.SLASettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"first_response_target_seconds", "resolution_target_seconds", "idle_resolve_minutes", "updated_at",
+		}),
+	}).Create(settings).Error
+}
+
+func (r *slaRepo) GetOpenThread(clientID uuid.UUID, customerPhone string) (*models.SLAThread, error) {
+	var thread models.SLAThread
+	err := r.db.Where("client_id = ? AND customer_phone = ? AND status = ?", clientID, customerPhone, models.SLAThreadOpen).
+		First(&thread).Error
+	if err != nil {
+		return nil, err
+	}
+	return &thread, nil
+}
+
+// OpenThread creates an open thread, or returns the one a concurrent message already opened for the customer
+func (r *slaRepo) OpenThread(thread *models.SLAThread) (*models.SLAThread, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Eq{Column: "status", Value: models.SLAThreadOpen}}},
+		DoNothing:   true,
+	}).Create(thread)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return r.GetOpenThread(thread.ClientID, thread.CustomerPhone)
+	}
+	return thread, nil
+}
+
+func (r *slaRepo) UpdateThread(thread *models.SLAThread) error {
+	return r.db.Save(thread).Error
+}
+
+// ListOpenThreads returns the oldest open threads first
+func (r *slaRepo) ListOpenThreads(limit int) ([]models.SLAThread, error) {
+	var threads []models.SLAThread
+	err := r.db.Where("status = ?", models.SLAThreadOpen).
+		Order("opened_at ASC").
+		Limit(limit).
+		Find(&threads).Error
+	return threads, err
+}
+
+func (r *slaRepo) CountOpenThreads(clientID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.SLAThread{}).
+		Where("client_id = ? AND status = ?", clientID, models.SLAThreadOpen).
+		Count(&count).Error
+	return count, err
+}
+
+// durationAggregates selects count, percentiles and average of a duration expression in seconds
+func durationAggregates(expr string) string {
+	return fmt.Sprintf(`COUNT(*) AS count,
+		COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY %[1]s), 0) AS p50,
+		COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY %[1]s), 0) AS p90,
+		COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY %[1]s), 0) AS p95,
+		COALESCE(AVG(%[1]s), 0) AS avg`, expr)
+}
+
+// FirstResponseStats aggregates time to first response of threads opened in a range.
+// Threads still unanswered past the target count as breached without a duration.
+func (r *slaRepo) FirstResponseStats(clientID uuid.UUID, start, end time.Time) (*models.SLADurationStats, error) {
+	var stats models.SLADurationStats
+	err := r.db.Model(&models.SLAThread{}).
+		Select(durationAggregates("EXTRACT(EPOCH FROM (first_response_at - opened_at))")+
+			", COUNT(*) FILTER (WHERE first_response_breached) AS breached").
+		Where("client_id = ? AND opened_at BETWEEN ? AND ?", clientID, start, end).
+		Where("first_response_at IS NOT NULL OR first_response_breached").
+		Scan(&stats).Error
+	return &stats, err
+}
+
+// ResolutionStats aggregates time to resolution of threads opened in a range
+func (r *slaRepo) ResolutionStats(clientID uuid.UUID, start, end time.Time) (*models.SLADurationStats, error) {
+	var stats models.SLADurationStats
+	err := r.db.Model(&models.SLAThread{}).
+		Select(durationAggregates("EXTRACT(EPOCH FROM (resolved_at - opened_at))")+
+			", COUNT(*) FILTER (WHERE resolution_breached) AS breached").
+		Where("client_id = ? AND opened_at BETWEEN ? AND ?", clientID, start, end).
+		Where("resolved_at IS NOT NULL OR resolution_breached").
+		Scan(&stats).Error
+	return &stats, err
+}
+
+// FirstResponseStatsByAgent aggregates first-response times per responder: the bot where it answered first,
+// and each agent by their own first reply in the thread
+func (r *slaRepo) FirstResponseStatsByAgent(clientID uuid.UUID, start, end time.Time, targetSeconds int) ([]models.SLADurationStats, error) {
+	var stats []models.SLADurationStats
+	err := r.db.Raw(`
+		SELECT agent, `+durationAggregates("seconds")+`, COUNT(*) FILTER (WHERE seconds > ?) AS breached
+		FROM (
+			SELECT first_response_by AS agent, EXTRACT(EPOCH FROM (first_response_at - opened_at)) AS seconds
+			FROM saas_sla_threads
+			WHERE client_id = ? AND opened_at BETWEEN ? AND ? AND first_response_by = ?
+			UNION ALL
+			SELECT first_agent AS agent, EXTRACT(EPOCH FROM (first_agent_response_at - opened_at)) AS seconds
+			FROM saas_sla_threads
+			WHERE client_id = ? AND opened_at BETWEEN ? AND ? AND first_agent_response_at IS NOT NULL
+		) responses
+		GROUP BY agent
+		ORDER BY count DESC
+	`, targetSeconds, clientID, start, end, models.SLAResponderBot, clientID, start, end).Scan(&stats).Error
+	return stats, err
+}
+
+// ResolutionStatsByAgent aggregates resolution times per agent who closed the thread.
+// Idle threads are credited to the agent who answered them, or the bot.
+func (r *slaRepo) ResolutionStatsByAgent(clientID uuid.UUID, start, end time.Time, targetSeconds int) ([]models.SLADurationStats, error) {
+	var stats []models.SLADurationStats
+	err := r.db.Raw(`
+		SELECT agent, `+durationAggregates("seconds")+`, COUNT(*) FILTER (WHERE seconds > ?) AS breached
+		FROM (
+			SELECT COALESCE(NULLIF(resolved_by, ''), NULLIF(first_agent, ''), ?) AS agent,
+				EXTRACT(EPOCH FROM (resolved_at - opened_at)) AS seconds
+			FROM saas_sla_threads
+			WHERE client_id = ? AND opened_at BETWEEN ? AND ? AND resolved_at IS NOT NULL
+		) resolutions
+		GROUP BY agent
+		ORDER BY count DESC
+	`, targetSeconds, models.SLAResponderBot, clientID, start, end).Scan(&stats).Error
+	return stats, err
+}
@@ -0,0 +1,158 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SplitPaymentRepo interface {
+	CreateSplit(order *models.Order, payments []models.OrderSplitPayment) error
+	GetByID(id uuid.UUID) (*models.OrderSplitPayment, error)
+	GetByReference(reference string) (*models.OrderSplitPayment, error)
+	ListByOrder(orderID uuid.UUID) ([]models.OrderSplitPayment, error)
+	MarkPaid(orderID, id uuid.UUID, paymentMethod, transactionID string, at time.Time) (bool, bool, error)
+	CancelPending(orderID uuid.UUID, status string) ([]models.OrderSplitPayment, bool, error)
+	Transition(id uuid.UUID, from, to string) (bool, error)
+	RecordRefund(id uuid.UUID, amount float64, reference string, at time.Time) error
+	Update(payment *models.OrderSplitPayment) error
+	ListExpiredOrders(now time.Time, limit int) ([]models.Order, error)
+}
+
+type splitPaymentRepo struct {
+	db *gorm.DB
+}
+
+func NewSplitPaymentRepo(db *gorm.DB) SplitPaymentRepo {
+	return &splitPaymentRepo{db: db}
+}
+
+// CreateSplit saves the portions of an order together with the order's split fields
+func (r *splitPaymentRepo) CreateSplit(order *models.Order, payments []models.OrderSplitPayment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&payments).Error; err != nil {
+			return err
+		}
+		return tx.Save(order).Error
+	})
+}
+
+func (r *splitPaymentRepo) GetByID(id uuid.UUID) (*models.OrderSplitPayment, error) {
+	var payment models.OrderSplitPayment
+	err := r.db.First(&payment, "id = ?", id).Error
+	return &payment, err
+}
+
+func (r *splitPaymentRepo) GetByReference(reference string) (*models.OrderSplitPayment, error) {
+	var payment models.OrderSplitPayment
+	err := r.db.First(&payment, "reference = ?", reference).Error
+	return &payment, err
+}
+
+// ListByOrder returns the portions of an order in the order the payers were given
+func (r *splitPaymentRepo) ListByOrder(orderID uuid.UUID) ([]models.OrderSplitPayment, error) {
+	var payments []models.OrderSplitPayment
+	err := r.db.Where("order_id = ?", orderID).Order("created_at ASC, reference ASC").Find(&payments).Error
+	return payments, err
+}
+
+// MarkPaid marks a pending portion paid, reporting false when it was not pending anymore (a repeated
+// gateway notification, or a portion already cancelled), and whether the portion completed its pending
+// group order. The order row is locked meanwhile, so of portions paid at once on any instance only the
+// last one completes the group.
+func (r *splitPaymentRepo) MarkPaid(orderID, id uuid.UUID, paymentMethod, transactionID string, at time.Time) (bool, bool, error) {
+	marked, complete := false, false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.OrderSplitPayment{}).
+			Where("id = ? AND order_id = ? AND status = ?", id, orderID, models.SplitPaymentPending).
+			Updates(map[string]interface{}{
+				"status":         models.SplitPaymentPaid,
+				"payment_method": paymentMethod,
+				"transaction_id": transactionID,
+				"paid_at":        at,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		marked = true
+
+		var unpaid int64
+		err := tx.Model(&models.OrderSplitPayment{}).
+			Where("order_id = ? AND status <> ?", orderID, models.SplitPaymentPaid).
+			Count(&unpaid).Error
+		complete = unpaid == 0 && order.PaymentStatus == models.PaymentStatusPending
+		return err
+	})
+	return marked, complete && err == nil, err
+}
+
+// CancelPending gives the pending portions of a pending group order the status, returning every portion
+// of the order. It reports false and changes nothing when the order is not pending anymore or has no
+// pending portion (every portion is paid, so the order is being confirmed). The order row is locked
+// meanwhile, so a group can't be cancelled and completed at once.
+func (r *splitPaymentRepo) CancelPending(orderID uuid.UUID, status string) ([]models.OrderSplitPayment, bool, error) {
+	var payments []models.OrderSplitPayment
+	cancelled := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var order models.Order
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, "id = ?", orderID).Error; err != nil {
+			return err
+		}
+		if order.PaymentStatus != models.PaymentStatusPending {
+			return nil
+		}
+
+		result := tx.Model(&models.OrderSplitPayment{}).
+			Where("order_id = ? AND status = ?", orderID, models.SplitPaymentPending).
+			Update("status", status)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cancelled = true
+		return tx.Where("order_id = ?", orderID).Order("created_at ASC, reference ASC").Find(&payments).Error
+	})
+	return payments, cancelled && err == nil, err
+}
+
+// Transition changes the status of a portion that still has status from, reporting false otherwise
+func (r *splitPaymentRepo) Transition(id uuid.UUID, from, to string) (bool, error) {
+	result := r.db.Model(&models.OrderSplitPayment{}).
+		Where("id = ? AND status = ?", id, from).
+		Update("status", to)
+	return result.RowsAffected > 0, result.Error
+}
+
+// RecordRefund adds a refund to a paid portion, which becomes refunded once all of it is returned
+func (r *splitPaymentRepo) RecordRefund(id uuid.UUID, amount float64, reference string, at time.Time) error {
+	return r.db.Model(&models.OrderSplitPayment{}).
+		Where("id = ? AND status = ?", id, models.SplitPaymentPaid).
+		Updates(map[string]interface{}{
+			"refunded_amount":  gorm.Expr("refunded_amount + ?", amount),
+			"status":           gorm.Expr("CASE WHEN refunded_amount + ? >= amount THEN ? ELSE status END", amount, models.SplitPaymentRefunded),
+			"refund_reference": reference,
+			"refunded_at":      at,
+		}).Error
+}
+
+func (r *splitPaymentRepo) Update(payment *models.OrderSplitPayment) error {
+	return r.db.Save(payment).Error
+}
+
+// ListExpiredOrders returns unpaid split orders whose payment window has passed with portions still unpaid
+func (r *splitPaymentRepo) ListExpiredOrders(now time.Time, limit int) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Where("split_payment = TRUE AND payment_status = ? AND split_expires_at <= ?", models.PaymentStatusPending, now).
+		Where("EXISTS (SELECT 1 FROM saas_order_split_payments p WHERE p.order_id = saas_orders.id AND p.status = ?)", models.SplitPaymentPending).
+		Order("split_expires_at ASC").
+		Limit(limit).
+		Find(&orders).Error
+	return orders, err
+}
@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"fmt"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type StoreRepo interface {
+	Create(store *models.Store) error
+	GetByID(id string) (*models.Store, error)
+	ListByClientID(clientID uuid.UUID, activeOnly bool) ([]models.Store, error)
+	Update(store *models.Store) error
+	Delete(id string) error // Soft delete
+}
+
+type storeRepo struct {
+	db *gorm.DB
+}
+
+func NewStoreRepo(db *gorm.DB) StoreRepo {
+	return &storeRepo{db: db}
+}
+
+func (r *storeRepo) Create(store *models.Store) error {
+	return r.db.Create(store).Error
+}
+
+func (r *storeRepo) GetByID(id string) (*models.Store, error) {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid store ID: %w", err)
+	}
+
+	var store models.Store
+	err = r.db.First(&store, "id = ?", uid).Error
+	if err != nil {
+		return nil, err
+	}
+	return &store, nil
+}
+
+func (r *storeRepo) ListByClientID(clientID uuid.UUID, activeOnly bool) ([]models.Store, error) {
+	var stores []models.Store
+	query := r.db.Where("client_id = ?", clientID)
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+	err := query.Order("name ASC").Find(&stores).Error
+	return stores, err
+}
+
+func (r *storeRepo) Update(store *models.Store) error {
+	return r.db.Save(store).Error
+}
+
+func (r *storeRepo) Delete(id string) error {
+	uid, err := uuid.Parse(id)
+	if err != nil {
+		return fmt.Errorf("invalid store ID: %w", err)
+	}
+
+	return r.db.Delete(&models.Store{}, "id = ?", uid).Error
+}
@@ -0,0 +1,195 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SubscriptionRepo interface {
+	CreatePlanChange(change *models.PlanChange) error
+	UpdatePlanChange(change *models.PlanChange) error
+	GetPlanChangeByReference(reference string) (*models.PlanChange, error)
+	ListOpenPlanChanges(clientID uuid.UUID) ([]models.PlanChange, error)
+	ListPlanChanges(clientID uuid.UUID, limit int) ([]models.PlanChange, error)
+	ListScheduledDue(now time.Time) ([]models.PlanChange, error)
+	ListPendingPaymentBefore(before time.Time) ([]models.PlanChange, error)
+	ClaimUsageAlert(alert *models.UsageAlert) (bool, error)
+	CountMessagesSince(clientID uuid.UUID, since time.Time) (int64, error)
+	CountMessagesBetween(clientID uuid.UUID, start, end time.Time) (int64, error)
+	StorageBytes(clientID uuid.UUID) (int64, error)
+	CountProducts(clientID uuid.UUID) (int64, error)
+	CountKnowledgeBase(clientID uuid.UUID) (int64, error)
+	CountKBDocuments(clientID uuid.UUID) (int64, error)
+	ListPlanDefinitions() ([]models.PlanDefinition, error)
+	UpsertPlanDefinition(definition *models.PlanDefinition) error
+	GetTenantSubscription(clientID uuid.UUID) (*models.TenantSubscription, error)
+	UpsertTenantSubscription(subscription *models.TenantSubscription) error
+	ListTenantSubscriptions(status string) ([]models.TenantSubscription, error)
+}
+
+type subscriptionRepo struct {
+	db *gorm.DB
+}
+
+func NewSubscriptionRepo(db *gorm.DB) SubscriptionRepo {
+	return &subscriptionRepo{db: db}
+}
+
+func (r *subscriptionRepo) CreatePlanChange(change *models.PlanChange) error {
+	return r.db.Create(change).Error
+}
+
+func (r *subscriptionRepo) UpdatePlanChange(change *models.PlanChange) error {
+	return r.db.Save(change).Error
+}
+
+func (r *subscriptionRepo) GetPlanChangeByReference(reference string) (*models.PlanChange, error) {
+	var change models.PlanChange
+	err := r.db.Where("reference = ?", reference).First(&change).Error
+	if err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// ListOpenPlanChanges returns the client's changes still waiting for payment or their effective date
+func (r *subscriptionRepo) ListOpenPlanChanges(clientID uuid.UUID) ([]models.PlanChange, error) {
+	var changes []models.PlanChange
+	err := r.db.Where("client_id = ? AND status IN ?", clientID, []string{models.PlanChangePendingPayment, models.PlanChangeScheduled}).
+		Order("created_at DESC").
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *subscriptionRepo) ListPlanChanges(clientID uuid.UUID, limit int) ([]models.PlanChange, error) {
+	var changes []models.PlanChange
+	err := r.db.Where("client_id = ?", clientID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&changes).Error
+	return changes, err
+}
+
+// ListScheduledDue returns scheduled downgrades whose effective date has passed
+func (r *subscriptionRepo) ListScheduledDue(now time.Time) ([]models.PlanChange, error) {
+	var changes []models.PlanChange
+	err := r.db.Where("status = ? AND effective_at <= ?", models.PlanChangeScheduled, now).
+		Find(&changes).Error
+	return changes, err
+}
+
+// ListPendingPaymentBefore returns upgrades created before the given time that were never paid
+func (r *subscriptionRepo) ListPendingPaymentBefore(before time.Time) ([]models.PlanChange, error) {
+	var changes []models.PlanChange
+	err := r.db.Where("status = ? AND created_at < ?", models.PlanChangePendingPayment, before).
+		Find(&changes).Error
+	return changes, err
+}
+
+// ClaimUsageAlert records the alert, returning false when it was already sent this period
+func (r *subscriptionRepo) ClaimUsageAlert(alert *models.UsageAlert) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "period_start"}, {Name: "metric"}, {Name: "threshold"}},
+		DoNothing: true,
+	}).Create(alert)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *subscriptionRepo) CountMessagesSince(clientID uuid.UUID, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Conversation{}).
+		Where("client_id = ? AND message_type <> ? AND created_at >= ?", clientID, models.ConversationTypeAgent, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *subscriptionRepo) CountProducts(clientID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Product{}).Where("client_id = ?", clientID).Count(&count).Error
+	return count, err
+}
+
+func (r *subscriptionRepo) CountKnowledgeBase(clientID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.KnowledgeBaseEntry{}).Where("client_id = ?", clientID).Count(&count).Error
+	return count, err
+}
+
+func (r *subscriptionRepo) CountKBDocuments(clientID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.KBDocument{}).Where("client_id = ?", clientID).Count(&count).Error
+	return count, err
+}
+
+func (r *subscriptionRepo) CountMessagesBetween(clientID uuid.UUID, start, end time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.Conversation{}).
+		Where("client_id = ? AND message_type <> ? AND created_at >= ? AND created_at < ?", clientID, models.ConversationTypeAgent, start, end).
+		Count(&count).Error
+	return count, err
+}
+
+// StorageBytes returns the size of the client's stored conversations and knowledge base
+func (r *subscriptionRepo) StorageBytes(clientID uuid.UUID) (int64, error) {
+	var conversations, knowledge int64
+	err := r.db.Model(&models.Conversation{}).
+		Select("COALESCE(SUM(OCTET_LENGTH(COALESCE(message_text, '')) + OCTET_LENGTH(COALESCE(ai_response, ''))), 0)").
+		Where("client_id = ?", clientID).
+		Scan(&conversations).Error
+	if err != nil {
+		return 0, err
+	}
+	err = r.db.Model(&models.KnowledgeBaseEntry{}).
+		Select("COALESCE(SUM(OCTET_LENGTH(title) + OCTET_LENGTH(content::text)), 0)").
+		Where("client_id = ?", clientID).
+		Scan(&knowledge).Error
+	return conversations + knowledge, err
+}
+
+// ListPlanDefinitions returns the operator's plans, cheapest first
+func (r *subscriptionRepo) ListPlanDefinitions() ([]models.PlanDefinition, error) {
+	var definitions []models.PlanDefinition
+	err := r.db.Order("monthly_price ASC, code ASC").Find(&definitions).Error
+	return definitions, err
+}
+
+func (r *subscriptionRepo) UpsertPlanDefinition(definition *models.PlanDefinition) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "code"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"name", "monthly_price", "limits", "overage", "features", "active", "updated_at",
+		}),
+	}).Create(definition).Error
+}
+
+func (r *subscriptionRepo) GetTenantSubscription(clientID uuid.UUID) (*models.TenantSubscription, error) {
+	var subscription models.TenantSubscription
+	err := r.db.Where("client_id = ?", clientID).First(&subscription).Error
+	return &subscription, err
+}
+
+func (r *subscriptionRepo) UpsertTenantSubscription(subscription *models.TenantSubscription) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"status", "overdue_statement_id", "past_due_since", "grace_ends_at",
+			"downgraded_from", "downgraded_at", "updated_at",
+		}),
+	}).Create(subscription).Error
+}
+
+// ListTenantSubscriptions returns the subscriptions with a status, or all of them
+func (r *subscriptionRepo) ListTenantSubscriptions(status string) ([]models.TenantSubscription, error) {
+	query := r.db.Model(&models.TenantSubscription{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var subscriptions []models.TenantSubscription
+	err := query.Order("updated_at DESC").Find(&subscriptions).Error
+	return subscriptions, err
+}
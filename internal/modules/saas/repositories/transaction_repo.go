@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"gorm.io/gorm"
 )
@@ -10,6 +12,8 @@ type TransactionRepo interface {
 	Create(transaction *models.Transaction) error
 	GetByID(id string) (*models.Transaction, error)
 	GetByClientID(clientID string, limit int) ([]models.Transaction, error)
+	PurgeRawText(clientID, transactionID string, before *time.Time) (int64, error)
+	PurgeExpiredRawText() (int64, error)
 }
 
 type transactionRepo struct {
@@ -53,3 +57,36 @@ func (r *transactionRepo) GetByClientID(clientID string, limit int) ([]models.Tr
 
 	return transactions, nil
 }
+
+// PurgeRawText deletes raw OCR text of one transaction, or all of a client's transactions (optionally created before a date).
+// Parsed totals and items are kept.
+func (r *transactionRepo) PurgeRawText(clientID, transactionID string, before *time.Time) (int64, error) {
+	query := r.db.Model(&models.Transaction{}).
+		Where("client_id = ? AND ocr_raw_text IS NOT NULL AND ocr_raw_text <> ''", clientID)
+
+	if transactionID != "" {
+		query = query.Where("id = ?", transactionID)
+	}
+	if before != nil {
+		query = query.Where("created_at < ?", *before)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"ocr_raw_text":           "",
+		"ocr_raw_text_purged_at": time.Now(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// PurgeExpiredRawText deletes raw OCR text older than each client's retention period
+func (r *transactionRepo) PurgeExpiredRawText() (int64, error) {
+	result := r.db.Exec(`
+		UPDATE saas_transactions t
+		SET ocr_raw_text = '', ocr_raw_text_purged_at = NOW()
+		FROM clients c
+		WHERE t.client_id = c.id
+			AND c.ocr_raw_text_retention_days > 0
+			AND t.created_at < NOW() - c.ocr_raw_text_retention_days * INTERVAL '1 day'
+			AND t.ocr_raw_text IS NOT NULL AND t.ocr_raw_text <> ''`)
+	return result.RowsAffected, result.Error
+}
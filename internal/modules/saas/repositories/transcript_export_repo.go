@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type TranscriptExportRepo interface {
+	Create(export *models.TranscriptExport) error
+	Update(export *models.TranscriptExport) error
+	GetByID(id uuid.UUID) (*models.TranscriptExport, error)
+	ListByCustomer(clientID uuid.UUID, customerPhone string, limit int) ([]models.TranscriptExport, error)
+}
+
+type transcriptExportRepo struct {
+	db *gorm.DB
+}
+
+func NewTranscriptExportRepo(db *gorm.DB) TranscriptExportRepo {
+	return &transcriptExportRepo{db: db}
+}
+
+func (r *transcriptExportRepo) Create(export *models.TranscriptExport) error {
+	return r.db.Create(export).Error
+}
+
+func (r *transcriptExportRepo) Update(export *models.TranscriptExport) error {
+	return r.db.Save(export).Error
+}
+
+func (r *transcriptExportRepo) GetByID(id uuid.UUID) (*models.TranscriptExport, error) {
+	var export models.TranscriptExport
+	if err := r.db.Where("id = ?", id).First(&export).Error; err != nil {
+		return nil, err
+	}
+	return &export, nil
+}
+
+func (r *transcriptExportRepo) ListByCustomer(clientID uuid.UUID, customerPhone string, limit int) ([]models.TranscriptExport, error) {
+	var exports []models.TranscriptExport
+	err := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&exports).Error
+	return exports, err
+}
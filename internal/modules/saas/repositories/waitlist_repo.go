@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WaitlistRepo interface {
+	Create(entry *models.WaitlistEntry) error
+	GetOpen(productID uuid.UUID, customerPhone string) (*models.WaitlistEntry, error)
+	GetLatestOffered(clientID uuid.UUID, customerPhone string) (*models.WaitlistEntry, error)
+	ListByProduct(productID uuid.UUID, status string) ([]models.WaitlistEntry, error)
+	ListConvertible(clientID uuid.UUID, customerPhone string) ([]models.WaitlistEntry, error)
+	CountByStatus(productID uuid.UUID) (map[string]int64, error)
+	CountPreorders(productID uuid.UUID) (int64, error)
+	Update(entry *models.WaitlistEntry) error
+}
+
+type waitlistRepo struct {
+	db *gorm.DB
+}
+
+func NewWaitlistRepo(db *gorm.DB) WaitlistRepo {
+	return &waitlistRepo{db: db}
+}
+
+func (r *waitlistRepo) Create(entry *models.WaitlistEntry) error {
+	return r.db.Create(entry).Error
+}
+
+// GetOpen returns the customer's offered or waiting entry for a product
+func (r *waitlistRepo) GetOpen(productID uuid.UUID, customerPhone string) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.db.Where("product_id = ? AND customer_phone = ? AND status IN ?",
+		productID, customerPhone, []string{models.WaitlistStatusOffered, models.WaitlistStatusWaiting}).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetLatestOffered returns the most recent restock alert offered to the customer
+func (r *waitlistRepo) GetLatestOffered(clientID uuid.UUID, customerPhone string) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := r.db.Preload("Product").
+		Where("client_id = ? AND customer_phone = ? AND status = ?", clientID, customerPhone, models.WaitlistStatusOffered).
+		Order("created_at DESC").
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *waitlistRepo) ListByProduct(productID uuid.UUID, status string) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	query := r.db.Where("product_id = ?", productID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at ASC").Find(&entries).Error
+	return entries, err
+}
+
+// ListConvertible returns the customer's entries that an order can still convert
+func (r *waitlistRepo) ListConvertible(clientID uuid.UUID, customerPhone string) ([]models.WaitlistEntry, error) {
+	var entries []models.WaitlistEntry
+	err := r.db.Preload("Product").
+		Where("client_id = ? AND customer_phone = ? AND status IN ?", clientID, customerPhone,
+			[]string{models.WaitlistStatusWaiting, models.WaitlistStatusNotified}).
+		Find(&entries).Error
+	return entries, err
+}
+
+func (r *waitlistRepo) CountByStatus(productID uuid.UUID) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.db.Model(&models.WaitlistEntry{}).
+		Select("status, COUNT(*) AS count").
+		Where("product_id = ?", productID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *waitlistRepo) CountPreorders(productID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.WaitlistEntry{}).
+		Where("product_id = ? AND type = ?", productID, models.WaitlistTypePreorder).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *waitlistRepo) Update(entry *models.WaitlistEntry) error {
+	return r.db.Omit("Product").Save(entry).Error
+}
@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WalletRepo interface {
+	GetSettings(clientID string) (*models.WalletSettings, error)
+	UpsertSettings(settings *models.WalletSettings) error
+
+	GetWallet(clientID uuid.UUID, customerPhone string) (*models.CustomerWallet, error)
+	GetOrCreateWallet(clientID uuid.UUID, customerPhone, customerName string) (*models.CustomerWallet, error)
+	ListWallets(clientID uuid.UUID, limit int) ([]models.CustomerWallet, error)
+
+	CreateTransaction(transaction *models.WalletTransaction) error
+	GetTransactionByReference(reference string) (*models.WalletTransaction, error)
+	ListTransactions(walletID uuid.UUID, limit int) ([]models.WalletTransaction, error)
+	Apply(transaction *models.WalletTransaction, at time.Time) (bool, error)
+	CompleteTopUp(reference, transactionID string, at time.Time) (*models.WalletTransaction, bool, error)
+	CancelTopUp(reference string) (bool, error)
+}
+
+type walletRepo struct {
+	db *gorm.DB
+}
+
+func NewWalletRepo(db *gorm.DB) WalletRepo {
+	return &walletRepo{db: db}
+}
+
+func (r *walletRepo) GetSettings(clientID string) (*models.WalletSettings, error) {
+	var settings models.WalletSettings
+	err := r.db.Where("client_id = ?", clientID).First(&settings).Error
+	return &settings, err
+}
+
+func (r *walletRepo) UpsertSettings(settings *models.WalletSettings) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "auto_deduct", "min_top_up", "max_top_up", "updated_at"}),
+	}).Create(settings).Error
+}
+
+func (r *walletRepo) GetWallet(clientID uuid.UUID, customerPhone string) (*models.CustomerWallet, error) {
+	var wallet models.CustomerWallet
+	err := r.db.Where("client_id = ? AND customer_phone = ?", clientID, customerPhone).First(&wallet).Error
+	return &wallet, err
+}
+
+// GetOrCreateWallet returns the customer's wallet, opening an empty one on first use
+func (r *walletRepo) GetOrCreateWallet(clientID uuid.UUID, customerPhone, customerName string) (*models.CustomerWallet, error) {
+	wallet := &models.CustomerWallet{
+		ClientID:      clientID,
+		CustomerPhone: customerPhone,
+		CustomerName:  customerName,
+	}
+	err := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "client_id"}, {Name: "customer_phone"}},
+		DoNothing: true,
+	}).Create(wallet).Error
+	if err != nil {
+		return nil, err
+	}
+	return r.GetWallet(clientID, customerPhone)
+}
+
+// ListWallets returns a client's wallets, largest balance first
+func (r *walletRepo) ListWallets(clientID uuid.UUID, limit int) ([]models.CustomerWallet, error) {
+	var wallets []models.CustomerWallet
+	err := r.db.Where("client_id = ?", clientID).
+		Order("balance DESC, updated_at DESC").
+		Limit(limit).
+		Find(&wallets).Error
+	return wallets, err
+}
+
+func (r *walletRepo) CreateTransaction(transaction *models.WalletTransaction) error {
+	return r.db.Create(transaction).Error
+}
+
+func (r *walletRepo) GetTransactionByReference(reference string) (*models.WalletTransaction, error) {
+	var transaction models.WalletTransaction
+	err := r.db.First(&transaction, "reference = ?", reference).Error
+	return &transaction, err
+}
+
+// ListTransactions returns a wallet's ledger newest first
+func (r *walletRepo) ListTransactions(walletID uuid.UUID, limit int) ([]models.WalletTransaction, error) {
+	var transactions []models.WalletTransaction
+	err := r.db.Where("wallet_id = ?", walletID).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// Apply changes the wallet balance by the transaction amount and records the completed transaction, in one
+// database transaction. Reports false (nothing recorded) when a debit is larger than the balance.
+func (r *walletRepo) Apply(transaction *models.WalletTransaction, at time.Time) (bool, error) {
+	applied := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		balance, ok, err := changeBalance(tx, transaction.WalletID, transaction.Amount)
+		if err != nil || !ok {
+			return err
+		}
+
+		transaction.Status = models.WalletTxCompleted
+		transaction.BalanceAfter = &balance
+		transaction.CompletedAt = &at
+		if err := tx.Create(transaction).Error; err != nil {
+			return err
+		}
+		applied = true
+		return nil
+	})
+	return applied, err
+}
+
+// CompleteTopUp credits a pending top-up to its wallet, reporting false when it was not pending anymore
+// (a repeated gateway notification, or a top-up already cancelled)
+func (r *walletRepo) CompleteTopUp(reference, transactionID string, at time.Time) (*models.WalletTransaction, bool, error) {
+	var transaction models.WalletTransaction
+	completed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transaction, "reference = ?", reference).Error
+		if err != nil || transaction.Status != models.WalletTxPending {
+			return err
+		}
+
+		balance, _, err := changeBalance(tx, transaction.WalletID, transaction.Amount)
+		if err != nil {
+			return err
+		}
+
+		transaction.Status = models.WalletTxCompleted
+		transaction.TransactionID = transactionID
+		transaction.BalanceAfter = &balance
+		transaction.CompletedAt = &at
+		if err := tx.Save(&transaction).Error; err != nil {
+			return err
+		}
+		completed = true
+		return nil
+	})
+	return &transaction, completed, err
+}
+
+// CancelTopUp cancels a pending top-up, reporting false when it was not pending anymore
+func (r *walletRepo) CancelTopUp(reference string) (bool, error) {
+	result := r.db.Model(&models.WalletTransaction{}).
+		Where("reference = ? AND status = ?", reference, models.WalletTxPending).
+		Update("status", models.WalletTxCancelled)
+	return result.RowsAffected > 0, result.Error
+}
+
+// changeBalance adds amount to the wallet balance and returns the new balance; a debit that would make the
+// balance negative changes nothing and reports false
+func changeBalance(tx *gorm.DB, walletID uuid.UUID, amount float64) (float64, bool, error) {
+	result := tx.Model(&models.CustomerWallet{}).
+		Where("id = ? AND balance + ? >= 0", walletID, amount).
+		Updates(map[string]interface{}{
+			"balance":    gorm.Expr("balance + ?", amount),
+			"updated_at": time.Now(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0, false, result.Error
+	}
+
+	var wallet models.CustomerWallet
+	if err := tx.Select("balance").First(&wallet, "id = ?", walletID).Error; err != nil {
+		return 0, false, err
+	}
+	return wallet.Balance, true, nil
+}
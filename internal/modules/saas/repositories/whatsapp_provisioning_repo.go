@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"gorm.io/gorm"
+)
+
+type WhatsAppProvisioningRepo interface {
+	GetByClientID(clientID string) (*models.WhatsAppProvisioning, error)
+	GetByWebhookToken(token string) (*models.WhatsAppProvisioning, error)
+	Create(provisioning *models.WhatsAppProvisioning) error
+	Update(provisioning *models.WhatsAppProvisioning) error
+}
+
+type whatsAppProvisioningRepo struct {
+	db *gorm.DB
+}
+
+func NewWhatsAppProvisioningRepo(db *gorm.DB) WhatsAppProvisioningRepo {
+	return &whatsAppProvisioningRepo{db: db}
+}
+
+func (r *whatsAppProvisioningRepo) GetByClientID(clientID string) (*models.WhatsAppProvisioning, error) {
+	var provisioning models.WhatsAppProvisioning
+	err := r.db.Where("client_id = ?", clientID).First(&provisioning).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provisioning, nil
+}
+
+func (r *whatsAppProvisioningRepo) GetByWebhookToken(token string) (*models.WhatsAppProvisioning, error) {
+	var provisioning models.WhatsAppProvisioning
+	err := r.db.Where("webhook_token = ?", token).First(&provisioning).Error
+	if err != nil {
+		return nil, err
+	}
+	return &provisioning, nil
+}
+
+func (r *whatsAppProvisioningRepo) Create(provisioning *models.WhatsAppProvisioning) error {
+	return r.db.Create(provisioning).Error
+}
+
+func (r *whatsAppProvisioningRepo) Update(provisioning *models.WhatsAppProvisioning) error {
+	return r.db.Save(provisioning).Error
+}
@@ -0,0 +1,65 @@
+package repositories
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WhatsAppSessionBackupRepo interface {
+	Create(backup *models.WhatsAppSessionBackup) error
+	GetByID(id uuid.UUID) (*models.WhatsAppSessionBackup, error)
+	Latest() (*models.WhatsAppSessionBackup, error)
+	List(limit int) ([]models.WhatsAppSessionBackup, error)
+	Prune(keep int) ([]models.WhatsAppSessionBackup, error)
+}
+
+type whatsAppSessionBackupRepo struct {
+	db *gorm.DB
+}
+
+func NewWhatsAppSessionBackupRepo(db *gorm.DB) WhatsAppSessionBackupRepo {
+	return &whatsAppSessionBackupRepo{db: db}
+}
+
+func (r *whatsAppSessionBackupRepo) Create(backup *models.WhatsAppSessionBackup) error {
+	return r.db.Create(backup).Error
+}
+
+func (r *whatsAppSessionBackupRepo) GetByID(id uuid.UUID) (*models.WhatsAppSessionBackup, error) {
+	var backup models.WhatsAppSessionBackup
+	err := r.db.First(&backup, "id = ?", id).Error
+	return &backup, err
+}
+
+// Latest returns the most recent backup
+func (r *whatsAppSessionBackupRepo) Latest() (*models.WhatsAppSessionBackup, error) {
+	var backup models.WhatsAppSessionBackup
+	err := r.db.Order("created_at DESC").First(&backup).Error
+	return &backup, err
+}
+
+// List returns backups newest first, without their snapshot
+func (r *whatsAppSessionBackupRepo) List(limit int) ([]models.WhatsAppSessionBackup, error) {
+	var backups []models.WhatsAppSessionBackup
+	err := r.db.Omit("snapshot").Order("created_at DESC").Limit(limit).Find(&backups).Error
+	return backups, err
+}
+
+// Prune deletes all but the newest keep backups and returns the deleted ones
+func (r *whatsAppSessionBackupRepo) Prune(keep int) ([]models.WhatsAppSessionBackup, error) {
+	var old []models.WhatsAppSessionBackup
+	err := r.db.Select("id", "file_public_id").Order("created_at DESC").Offset(keep).Find(&old).Error
+	if err != nil || len(old) == 0 {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, len(old))
+	for i, backup := range old {
+		ids[i] = backup.ID
+	}
+	if err := r.db.Where("id IN ?", ids).Delete(&models.WhatsAppSessionBackup{}).Error; err != nil {
+		return nil, err
+	}
+	return old, nil
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
@@ -12,10 +14,14 @@ type WorkflowRepo interface {
 	FindByID(id uuid.UUID) (*models.Workflow, error)
 	FindByClientID(clientID uuid.UUID) ([]models.Workflow, error)
 	FindScheduledActive() ([]models.Workflow, error)
+	FindByClientIDAndIDs(clientID uuid.UUID, ids []uuid.UUID) ([]models.Workflow, error)
+	SetActive(clientID uuid.UUID, ids []uuid.UUID, active bool) (int64, error)
 	Update(workflow *models.Workflow) error
 	Delete(id uuid.UUID) error
 	CreateExecution(execution *models.WorkflowExecution) error
 	FindExecutionsByWorkflowID(workflowID uuid.UUID, limit int) ([]models.WorkflowExecution, error)
+	FindExecutionByID(id uuid.UUID) (*models.WorkflowExecution, error)
+	FindExecutionsInRange(workflowID uuid.UUID, from, to time.Time) ([]models.WorkflowExecution, error)
 	UpdateExecution(execution *models.WorkflowExecution) error
 }
 
@@ -53,6 +59,19 @@ func (r *workflowRepo) FindScheduledActive() ([]models.Workflow, error) {
 	return workflows, err
 }
 
+func (r *workflowRepo) FindByClientIDAndIDs(clientID uuid.UUID, ids []uuid.UUID) ([]models.Workflow, error) {
+	var workflows []models.Workflow
+	err := r.db.Where("client_id = ? AND id IN ?", clientID, ids).Find(&workflows).Error
+	return workflows, err
+}
+
+func (r *workflowRepo) SetActive(clientID uuid.UUID, ids []uuid.UUID, active bool) (int64, error) {
+	result := r.db.Model(&models.Workflow{}).
+		Where("client_id = ? AND id IN ?", clientID, ids).
+		Updates(map[string]interface{}{"is_active": active, "updated_at": time.Now()})
+	return result.RowsAffected, result.Error
+}
+
 func (r *workflowRepo) Update(workflow *models.Workflow) error {
 	return r.db.Save(workflow).Error
 }
@@ -75,6 +94,22 @@ func (r *workflowRepo) FindExecutionsByWorkflowID(workflowID uuid.UUID, limit in
 	return executions, err
 }
 
+func (r *workflowRepo) FindExecutionByID(id uuid.UUID) (*models.WorkflowExecution, error) {
+	var execution models.WorkflowExecution
+	if err := r.db.Where("id = ?", id).First(&execution).Error; err != nil {
+		return nil, err
+	}
+	return &execution, nil
+}
+
+func (r *workflowRepo) FindExecutionsInRange(workflowID uuid.UUID, from, to time.Time) ([]models.WorkflowExecution, error) {
+	var executions []models.WorkflowExecution
+	err := r.db.Where("workflow_id = ? AND started_at >= ? AND started_at < ?", workflowID, from, to).
+		Order("started_at DESC").
+		Find(&executions).Error
+	return executions, err
+}
+
 func (r *workflowRepo) UpdateExecution(execution *models.WorkflowExecution) error {
 	return r.db.Save(execution).Error
 }
@@ -0,0 +1,476 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/auth"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix starts every issued API key so leaked keys are easy to spot
+const apiKeyPrefix = "sk_"
+
+// externalRefPattern keeps reference IDs usable as path segments, e.g. "acme-prod" or "tf:tenant-42"
+var externalRefPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+// provisionableRoles are the CMS roles a provisioning tool may assign
+var provisionableRoles = []string{"admin_tenant", "staff_tenant"}
+
+var (
+	// ErrProvisionNotFound is returned when nothing carries the reference yet
+	ErrProvisionNotFound = errors.New("not found")
+	// ErrProvisionConflict is returned when the desired state clashes with another resource
+	ErrProvisionConflict = errors.New("conflict")
+	// ErrAPIKeyInvalid is returned for unknown or revoked API keys
+	ErrAPIKeyInvalid = errors.New("invalid or revoked API key")
+)
+
+// ProvisionClientRequest is the desired state of a client; omitted fields keep their current value
+type ProvisionClientRequest struct {
+	ClientID         *string `json:"client_id,omitempty"` // Adopt an existing client without a reference instead of creating one
+	BusinessName     *string `json:"business_name,omitempty" example:"Toko Maju"`
+	WhatsAppNumber   *string `json:"whatsapp_number,omitempty" example:"628123456789"`
+	Module           *string `json:"module,omitempty" example:"saas"`
+	SubscriptionPlan *string `json:"subscription_plan,omitempty" example:"pro"`
+	Tone             *string `json:"tone,omitempty" example:"friendly"`
+	Timezone         *string `json:"timezone,omitempty" example:"Asia/Jakarta"`
+	LLMProvider      *string `json:"llm_provider,omitempty" example:"claude"` // Empty = platform default
+	LLMModel         *string `json:"llm_model,omitempty" example:"claude-sonnet-4-5"`
+}
+
+// ProvisionUserRequest is the desired state of a CMS user; omitted fields keep their current value
+type ProvisionUserRequest struct {
+	Email       *string `json:"email,omitempty" example:"owner@tokomaju.id"`
+	Name        *string `json:"name,omitempty" example:"Budi"`
+	PhoneNumber *string `json:"phone_number,omitempty" example:"628123456789"`
+	Role        *string `json:"role,omitempty" example:"admin_tenant"`
+	Password    *string `json:"password,omitempty"` // Write-only; omit to leave it unchanged
+	IsActive    *bool   `json:"is_active,omitempty"`
+}
+
+// ProvisionAPIKeyRequest is the desired state of an API key
+type ProvisionAPIKeyRequest struct {
+	Name *string `json:"name,omitempty" example:"erp-sync"`
+}
+
+// ProvisionWhatsAppRequest is the desired WhatsApp session of a client
+type ProvisionWhatsAppRequest struct {
+	SessionID string `json:"session_id,omitempty" example:"client-acme"` // Defaults to the current or a generated session
+	Force     bool   `json:"force"`                                      // Re-run provisioning even when already in place
+}
+
+// AdminProvisioningService applies desired tenant state by external reference IDs, so repeated calls converge instead of duplicating
+type AdminProvisioningService struct {
+	clientRepo        repositories.ClientRepo
+	userRepo          repositories.CompanyUserRepo
+	apiKeyRepo        repositories.APIKeyRepo
+	onboardingService *OnboardingService
+}
+
+// NewAdminProvisioningService creates a new admin provisioning service
+func NewAdminProvisioningService(clientRepo repositories.ClientRepo, userRepo repositories.CompanyUserRepo, apiKeyRepo repositories.APIKeyRepo, onboardingService *OnboardingService) *AdminProvisioningService {
+	return &AdminProvisioningService{
+		clientRepo:        clientRepo,
+		userRepo:          userRepo,
+		apiKeyRepo:        apiKeyRepo,
+		onboardingService: onboardingService,
+	}
+}
+
+func validateExternalRef(ref string) error {
+	if !externalRefPattern.MatchString(ref) {
+		return fmt.Errorf("reference %q must be 1-128 letters, digits, '.', '_', ':' or '-'", ref)
+	}
+	return nil
+}
+
+// GetClient returns the client carrying the reference
+func (s *AdminProvisioningService) GetClient(ref string) (*models.Client, error) {
+	if err := validateExternalRef(ref); err != nil {
+		return nil, err
+	}
+	client, err := s.clientRepo.GetByExternalRef(ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("client %q %w", ref, ErrProvisionNotFound)
+		}
+		return nil, fmt.Errorf("failed to load client: %w", err)
+	}
+	return client, nil
+}
+
+// ApplyClient updates the client carrying the reference, or creates it when create is set.
+// It reports whether the client was created.
+func (s *AdminProvisioningService) ApplyClient(ref string, req *ProvisionClientRequest, create bool) (*models.Client, bool, error) {
+	client, err := s.GetClient(ref)
+	created := false
+	if err != nil {
+		if !create || !errors.Is(err, ErrProvisionNotFound) {
+			return nil, false, err
+		}
+		if client, err = s.adoptOrNewClient(ref, req); err != nil {
+			return nil, false, err
+		}
+		created = client.ID == uuid.Nil
+	}
+
+	if req.BusinessName != nil {
+		if strings.TrimSpace(*req.BusinessName) == "" {
+			return nil, false, errors.New("business_name must not be empty")
+		}
+		client.BusinessName = strings.TrimSpace(*req.BusinessName)
+	}
+	if req.WhatsAppNumber != nil {
+		client.WhatsAppNumber = strings.TrimSpace(*req.WhatsAppNumber)
+	}
+	if req.Module != nil {
+		client.Module = strings.TrimSpace(*req.Module)
+	}
+	if req.SubscriptionPlan != nil {
+		if strings.TrimSpace(*req.SubscriptionPlan) == "" {
+			return nil, false, errors.New("subscription_plan must not be empty")
+		}
+		client.SubscriptionPlan = strings.TrimSpace(*req.SubscriptionPlan)
+	}
+	if req.Tone != nil {
+		client.Tone = strings.TrimSpace(*req.Tone)
+	}
+	if req.Timezone != nil {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil {
+			return nil, false, fmt.Errorf("invalid timezone %q", *req.Timezone)
+		}
+		client.Timezone = *req.Timezone
+	}
+	if req.LLMProvider != nil {
+		provider := strings.ToLower(strings.TrimSpace(*req.LLMProvider))
+		if provider != "" {
+			if _, err := llm.ParseProviderType(provider); err != nil {
+				return nil, false, err
+			}
+		}
+		client.LLMProvider = provider
+	}
+	if req.LLMModel != nil {
+		client.LLMModel = strings.TrimSpace(*req.LLMModel)
+	}
+
+	if created {
+		err = s.clientRepo.Create(client)
+	} else {
+		err = s.clientRepo.Update(client)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save client: %w", err)
+	}
+
+	if created {
+		log.Printf("🏗️  Client provisioned: %s (ref: %s, ID: %s)", client.BusinessName, ref, client.ID)
+	}
+	return client, created, nil
+}
+
+// adoptOrNewClient attaches the reference to the client named in the request, or prepares a new client
+func (s *AdminProvisioningService) adoptOrNewClient(ref string, req *ProvisionClientRequest) (*models.Client, error) {
+	if req.ClientID == nil {
+		if req.BusinessName == nil {
+			return nil, errors.New("business_name is required to create a client")
+		}
+		return &models.Client{ExternalRef: &ref, Module: "saas", SubscriptionPlan: "free", SubscriptionStatus: models.ClientStatusActive, Tone: "neutral", Timezone: "Asia/Jakarta"}, nil
+	}
+
+	client, err := s.clientRepo.GetByID(*req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("client %s %w", *req.ClientID, ErrProvisionNotFound)
+	}
+	if client.ExternalRef != nil && *client.ExternalRef != ref {
+		return nil, fmt.Errorf("%w: client %s already has reference %q", ErrProvisionConflict, client.ID, *client.ExternalRef)
+	}
+	client.ExternalRef = &ref
+	return client, nil
+}
+
+// GetUser returns the CMS user carrying the reference within a client
+func (s *AdminProvisioningService) GetUser(clientRef, userRef string) (*auth.CompanyUser, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.getUser(client.ID, userRef)
+}
+
+func (s *AdminProvisioningService) getUser(clientID uuid.UUID, ref string) (*auth.CompanyUser, error) {
+	if err := validateExternalRef(ref); err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.GetByRef(clientID, ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("user %q %w", ref, ErrProvisionNotFound)
+		}
+		return nil, fmt.Errorf("failed to load user: %w", err)
+	}
+	return user, nil
+}
+
+// ApplyUser updates the CMS user carrying the reference, or creates it when create is set.
+// It reports whether the user was created.
+func (s *AdminProvisioningService) ApplyUser(clientRef, userRef string, req *ProvisionUserRequest, create bool) (*auth.CompanyUser, bool, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, false, err
+	}
+
+	user, err := s.getUser(client.ID, userRef)
+	created := false
+	if err != nil {
+		if !create || !errors.Is(err, ErrProvisionNotFound) {
+			return nil, false, err
+		}
+		if req.Email == nil || req.Role == nil {
+			return nil, false, errors.New("email and role are required to create a user")
+		}
+		user = &auth.CompanyUser{ClientID: client.ID, ExternalRef: &userRef, OAuthProvider: "email", IsActive: true}
+		created = true
+	}
+
+	if req.Email != nil {
+		email := strings.ToLower(strings.TrimSpace(*req.Email))
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, false, fmt.Errorf("invalid email %q", *req.Email)
+		}
+		if email != user.Email {
+			inUse, err := s.userRepo.EmailInUse(email, user.ID)
+			if err != nil {
+				return nil, false, fmt.Errorf("failed to check email: %w", err)
+			}
+			if inUse {
+				return nil, false, fmt.Errorf("%w: email %s is already registered", ErrProvisionConflict, email)
+			}
+			user.Email = email
+		}
+	}
+	if req.Name != nil {
+		user.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.PhoneNumber != nil {
+		user.PhoneNumber = strings.TrimSpace(*req.PhoneNumber)
+	}
+	if req.Role != nil {
+		if !slices.Contains(provisionableRoles, *req.Role) {
+			return nil, false, fmt.Errorf("role must be one of %s", strings.Join(provisionableRoles, ", "))
+		}
+		user.Role = *req.Role
+	}
+	if req.Password != nil {
+		if len(*req.Password) < 6 {
+			return nil, false, errors.New("password must be at least 6 characters")
+		}
+		hash, err := auth.HashPassword(*req.Password)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to hash password: %w", err)
+		}
+		user.PasswordHash = hash
+	}
+	if req.IsActive != nil {
+		user.IsActive = *req.IsActive
+		if !user.IsActive {
+			// Suspended users lose their sessions
+			user.RefreshToken = ""
+			user.RefreshTokenExpiresAt = nil
+		}
+	}
+
+	if created {
+		err = s.userRepo.Create(user)
+	} else {
+		err = s.userRepo.Update(user)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to save user: %w", err)
+	}
+
+	if created {
+		log.Printf("🏗️  User provisioned: %s (ref: %s) for client %s", user.Email, userRef, client.ID)
+	}
+	return user, created, nil
+}
+
+// GetAPIKey returns the API key carrying the reference within a client
+func (s *AdminProvisioningService) GetAPIKey(clientRef, keyRef string) (*models.APIKey, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.getAPIKey(client.ID, keyRef)
+}
+
+func (s *AdminProvisioningService) getAPIKey(clientID uuid.UUID, ref string) (*models.APIKey, error) {
+	if err := validateExternalRef(ref); err != nil {
+		return nil, err
+	}
+	key, err := s.apiKeyRepo.GetByRef(clientID, ref)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("API key %q %w", ref, ErrProvisionNotFound)
+		}
+		return nil, fmt.Errorf("failed to load API key: %w", err)
+	}
+	return key, nil
+}
+
+// ApplyAPIKey updates the API key carrying the reference, or issues it when create is set.
+// The key value is returned only when a key is issued (new, or replacing a revoked one) and can't be retrieved later.
+func (s *AdminProvisioningService) ApplyAPIKey(clientRef, keyRef string, req *ProvisionAPIKeyRequest, create bool) (*models.APIKey, string, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, err := s.getAPIKey(client.ID, keyRef)
+	isNew := false
+	if err != nil {
+		if !create || !errors.Is(err, ErrProvisionNotFound) {
+			return nil, "", err
+		}
+		key = &models.APIKey{ClientID: client.ID, ExternalRef: keyRef, Name: keyRef}
+		isNew = true
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, "", errors.New("name must not be empty")
+		}
+		key.Name = strings.TrimSpace(*req.Name)
+	}
+
+	// Desired state is "exists": a revoked key is reissued with a new value
+	secret := ""
+	if isNew || (create && key.RevokedAt != nil) {
+		if secret, err = issueAPIKey(key); err != nil {
+			return nil, "", err
+		}
+	}
+
+	if isNew {
+		err = s.apiKeyRepo.Create(key)
+	} else {
+		err = s.apiKeyRepo.Update(key)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to save API key: %w", err)
+	}
+
+	if secret != "" {
+		log.Printf("🔑 API key %s (ref: %s) issued for client %s", key.KeyPrefix, keyRef, client.ID)
+	}
+	return key, secret, nil
+}
+
+// RevokeAPIKey revokes the API key carrying the reference; revoking twice is a no-op
+func (s *AdminProvisioningService) RevokeAPIKey(clientRef, keyRef string) (*models.APIKey, error) {
+	key, err := s.GetAPIKey(clientRef, keyRef)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return key, nil
+	}
+
+	now := time.Now()
+	key.RevokedAt = &now
+	if err := s.apiKeyRepo.Update(key); err != nil {
+		return nil, fmt.Errorf("failed to revoke API key: %w", err)
+	}
+	log.Printf("🔑 API key %s (ref: %s) revoked for client %s", key.KeyPrefix, keyRef, key.ClientID)
+	return key, nil
+}
+
+// AuthenticateAPIKey resolves an API key value to its active key record
+func (s *AdminProvisioningService) AuthenticateAPIKey(value string) (*models.APIKey, error) {
+	if !strings.HasPrefix(value, apiKeyPrefix) {
+		return nil, ErrAPIKeyInvalid
+	}
+	key, err := s.apiKeyRepo.GetActiveByHash(hashAPIKey(value))
+	if err != nil {
+		return nil, ErrAPIKeyInvalid
+	}
+	if err := s.apiKeyRepo.TouchLastUsed(key.ID, time.Now()); err != nil {
+		log.Printf("⚠️ Failed to record API key use: %v", err)
+	}
+	return key, nil
+}
+
+// RevokeAPIKeysStep is the offboarding step revoking every API key of a deactivated client
+func (s *AdminProvisioningService) RevokeAPIKeysStep(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	revoked, err := s.apiKeyRepo.RevokeAll(client.ID, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("failed to revoke API keys: %w", err)
+	}
+	return fmt.Sprintf("%d API key(s) revoked", revoked), nil
+}
+
+// WhatsAppStatus reports the WhatsApp onboarding state of the client carrying the reference
+func (s *AdminProvisioningService) WhatsAppStatus(clientRef string) (*OnboardingStatus, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, err
+	}
+	return s.onboardingService.GetStatus(client.ID.String())
+}
+
+// ProvisionWhatsApp sets up the client's WhatsApp session and webhook unless they are already in place.
+// It reports whether provisioning ran.
+func (s *AdminProvisioningService) ProvisionWhatsApp(clientRef string, req *ProvisionWhatsAppRequest) (*OnboardingStatus, bool, error) {
+	client, err := s.GetClient(clientRef)
+	if err != nil {
+		return nil, false, err
+	}
+
+	status, err := s.onboardingService.GetStatus(client.ID.String())
+	if err != nil {
+		return nil, false, err
+	}
+	provisioned := status.SessionID != "" && status.NextStep != "provision_whatsapp"
+	if !req.Force && provisioned && (req.SessionID == "" || req.SessionID == status.SessionID) {
+		return status, false, nil
+	}
+
+	status, err = s.onboardingService.ProvisionWhatsApp(client.ID.String(), req.SessionID)
+	if err != nil {
+		return nil, false, err
+	}
+	return status, true, nil
+}
+
+// issueAPIKey generates a new key value for key, storing only its prefix and hash
+func issueAPIKey(key *models.APIKey) (string, error) {
+	random, err := randomHex(24)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate API key: %w", err)
+	}
+	secret := apiKeyPrefix + random
+	key.KeyPrefix = secret[:len(apiKeyPrefix)+6]
+	key.KeyHash = hashAPIKey(secret)
+	key.RevokedAt = nil
+	key.LastUsedAt = nil
+	return secret, nil
+}
+
+func hashAPIKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// Bounds of the per-client LLM settings
+const (
+	maxAITemperature = 1.0 // The highest temperature every provider accepts
+	maxAIMaxTokens   = 8192
+)
+
+// ErrInvalidAISettings is returned for AI settings outside the allowed bounds
+var ErrInvalidAISettings = errors.New("invalid AI settings")
+
+// AISettingsService manages the per-client model, prompt template, temperature and max tokens of the bot
+type AISettingsService struct {
+	clientRepo repositories.ClientRepo
+}
+
+func NewAISettingsService(clientRepo repositories.ClientRepo) *AISettingsService {
+	return &AISettingsService{clientRepo: clientRepo}
+}
+
+// GetSettings returns a client's AI settings
+func (s *AISettingsService) GetSettings(clientID string) (*models.AISettings, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	return aiSettings(client), nil
+}
+
+// UpdateSettings changes a client's AI settings
+func (s *AISettingsService) UpdateSettings(clientID string, req *models.UpdateAISettingsRequest) (*models.AISettings, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	if req.LLMProvider != nil {
+		provider := strings.ToLower(strings.TrimSpace(*req.LLMProvider))
+		if provider != "" {
+			if _, err := llm.ParseProviderType(provider); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidAISettings, err)
+			}
+		}
+		if provider != client.LLMProvider && req.LLMModel == nil {
+			client.LLMModel = "" // The old model belongs to the old provider
+		}
+		client.LLMProvider = provider
+	}
+	if req.LLMModel != nil {
+		client.LLMModel = strings.TrimSpace(*req.LLMModel)
+	}
+	if req.PromptTemplate != nil {
+		template := strings.TrimSpace(*req.PromptTemplate)
+		if len(template) > llm.MaxPromptTemplateLength {
+			return nil, fmt.Errorf("%w: prompt_template must be at most %d characters", ErrInvalidAISettings, llm.MaxPromptTemplateLength)
+		}
+		client.AIPromptTemplate = template
+	}
+	if req.Tone != nil {
+		client.Tone = strings.TrimSpace(*req.Tone)
+	}
+	if req.Temperature != nil {
+		temperature := *req.Temperature
+		if temperature < 0 || temperature > maxAITemperature {
+			return nil, fmt.Errorf("%w: temperature must be between 0 and %.1f", ErrInvalidAISettings, maxAITemperature)
+		}
+		client.AITemperature = nil
+		if temperature > 0 {
+			client.AITemperature = &temperature
+		}
+	}
+	if req.MaxTokens != nil {
+		maxTokens := *req.MaxTokens
+		if maxTokens < 0 || maxTokens > maxAIMaxTokens {
+			return nil, fmt.Errorf("%w: max_tokens must be between 0 and %d", ErrInvalidAISettings, maxAIMaxTokens)
+		}
+		client.AIMaxTokens = nil
+		if maxTokens > 0 {
+			client.AIMaxTokens = &maxTokens
+		}
+	}
+
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, fmt.Errorf("failed to update AI settings: %w", err)
+	}
+
+	log.Printf("🤖 AI settings of client %s updated (provider %q, model %q, custom prompt: %t)", clientID, client.LLMProvider, client.LLMModel, client.AIPromptTemplate != "")
+	return aiSettings(client), nil
+}
+
+// ClientModelLookup resolves the LLM settings of a client, for the LLM service
+func ClientModelLookup(clientRepo repositories.ClientRepo) llm.ModelLookup {
+	return func(clientID string) (llm.ModelSettings, error) {
+		client, err := clientRepo.GetByID(clientID)
+		if err != nil {
+			return llm.ModelSettings{}, err
+		}
+		settings := llm.ModelSettings{
+			Provider: llm.ProviderType(client.LLMProvider),
+			Model:    client.LLMModel,
+		}
+		if client.AITemperature != nil {
+			settings.Temperature = *client.AITemperature
+		}
+		if client.AIMaxTokens != nil {
+			settings.MaxTokens = *client.AIMaxTokens
+		}
+		return settings, nil
+	}
+}
+
+func aiSettings(client *models.Client) *models.AISettings {
+	return &models.AISettings{
+		LLMProvider:    client.LLMProvider,
+		LLMModel:       client.LLMModel,
+		PromptTemplate: client.AIPromptTemplate,
+		Tone:           client.Tone,
+		Temperature:    client.AITemperature,
+		MaxTokens:      client.AIMaxTokens,
+	}
+}
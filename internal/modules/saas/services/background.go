@@ -0,0 +1,61 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// backgroundTasks tracks the goroutines a service starts after answering a request, such as message
+// processing after the webhook returned, so shutdown can wait for them. The zero value is ready to use.
+type backgroundTasks struct {
+	mu       sync.Mutex
+	wg       sync.WaitGroup
+	draining bool
+}
+
+// Go runs fn in the background. Tasks started once draining has begun still run but are not waited for.
+func (t *backgroundTasks) Go(fn func()) {
+	t.mu.Lock()
+	if t.draining {
+		t.mu.Unlock()
+		go fn()
+		return
+	}
+	t.wg.Add(1)
+	t.mu.Unlock()
+
+	go func() {
+		defer t.wg.Done()
+		fn()
+	}()
+}
+
+// Drain waits for the running tasks to finish, or returns ctx.Err() when ctx ends first
+func (t *backgroundTasks) Drain(ctx context.Context) error {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Go processes a webhook notification in the background, after the webhook has been answered
+func (s *WebhookService) Go(fn func()) {
+	s.tasks.Go(fn)
+}
+
+// Drain waits for the messages being processed, until ctx ends
+func (s *WebhookService) Drain(ctx context.Context) error {
+	return s.tasks.Drain(ctx)
+}
@@ -0,0 +1,609 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/export"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// StatementReferencePrefix marks gateway order IDs that pay a tenant's billing statement
+const StatementReferencePrefix = "INV-"
+
+const (
+	statementFolder   = "billing-statements"
+	statementMaxBytes = 5 * 1024 * 1024
+	bytesPerMB        = 1024 * 1024
+)
+
+// statementMetrics are the usage metrics billed above the plan limits, in statement order
+var statementMetrics = []string{models.UsageMetricMessages, models.UsageMetricTokens, models.UsageMetricStorage}
+
+var (
+	ErrStatementNotFound      = errors.New("billing statement not found")
+	ErrStatementNotUnpaid     = errors.New("billing statement is not unpaid")
+	ErrInvalidStatementPeriod = errors.New("invalid period, use YYYY-MM of a month that has ended")
+)
+
+// StatementMailer sends a finished statement to the tenant admins
+type StatementMailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+// BillingStatementService invoices tenants monthly: the plan fee plus the messages, tokens and storage
+// used above the plan limits, rendered as PDF, emailed to the tenant admins and payable through the billing gateway
+type BillingStatementService struct {
+	statementRepo    repositories.BillingStatementRepo
+	subscriptionRepo repositories.SubscriptionRepo
+	clientRepo       repositories.ClientRepo
+	companyUserRepo  repositories.CompanyUserRepo
+	usageRepo        repositories.UsageRepo
+	exportService    *export.Service
+	uploadService    *upload.Service
+	billingGateway   payment.Gateway // nil when no automated gateway is configured
+	mailer           StatementMailer // nil when email is not configured
+	issuer           string
+}
+
+func NewBillingStatementService(
+	statementRepo repositories.BillingStatementRepo,
+	subscriptionRepo repositories.SubscriptionRepo,
+	clientRepo repositories.ClientRepo,
+	companyUserRepo repositories.CompanyUserRepo,
+	usageRepo repositories.UsageRepo,
+	exportService *export.Service,
+	uploadService *upload.Service,
+	billingGateway payment.Gateway,
+	mailer StatementMailer,
+	issuer string,
+) *BillingStatementService {
+	return &BillingStatementService{
+		statementRepo:    statementRepo,
+		subscriptionRepo: subscriptionRepo,
+		clientRepo:       clientRepo,
+		companyUserRepo:  companyUserRepo,
+		usageRepo:        usageRepo,
+		exportService:    exportService,
+		uploadService:    uploadService,
+		billingGateway:   billingGateway,
+		mailer:           mailer,
+		issuer:           issuer,
+	}
+}
+
+// List returns statements newest period first
+func (s *BillingStatementService) List(filter models.StatementFilter) ([]models.BillingStatement, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	return s.statementRepo.List(filter)
+}
+
+// Get returns a statement
+func (s *BillingStatementService) Get(id uuid.UUID) (*models.BillingStatement, error) {
+	statement, err := s.statementRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrStatementNotFound
+	}
+	return statement, err
+}
+
+// Generate builds the statements of a month (YYYY-MM, default the previous one) for one or every active client.
+// Unpaid and void statements of that month are rebuilt with fresh usage; paid statements are kept
+func (s *BillingStatementService) Generate(clientID *uuid.UUID, period string) ([]models.BillingStatement, error) {
+	var clients []models.Client
+	if clientID != nil {
+		client, err := s.clientRepo.GetByID(clientID.String())
+		if err != nil {
+			return nil, ErrClientNotFound
+		}
+		clients = []models.Client{*client}
+	} else {
+		active, err := s.clientRepo.GetActiveClients()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list clients: %w", err)
+		}
+		clients = active
+	}
+
+	now := time.Now()
+	statements := make([]models.BillingStatement, 0, len(clients))
+	for i := range clients {
+		client := &clients[i]
+		start, err := statementPeriodStart(period, client.Timezone, now)
+		if err != nil {
+			return nil, err
+		}
+		statement, err := s.generate(client, start, true)
+		if err != nil {
+			return nil, fmt.Errorf("client %s: %w", client.ID, err)
+		}
+		if statement != nil {
+			statements = append(statements, *statement)
+		}
+	}
+	return statements, nil
+}
+
+// MarkPaid records a payment received outside the gateway, e.g. a bank transfer
+func (s *BillingStatementService) MarkPaid(id uuid.UUID, req *models.MarkStatementPaidRequest) (*models.BillingStatement, error) {
+	statement, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if statement.Status != models.StatementUnpaid {
+		return nil, ErrStatementNotUnpaid
+	}
+
+	if statement.PaymentLink != "" && s.billingGateway != nil {
+		if err := s.billingGateway.Cancel(statement.Number); err != nil {
+			log.Printf("⚠️ Failed to cancel payment link of statement %s: %v", statement.Number, err)
+		}
+	}
+	method := strings.TrimSpace(req.PaymentMethod)
+	if method == "" {
+		method = "manual"
+	}
+	if err := s.settle(statement, method, strings.TrimSpace(req.Reference)); err != nil {
+		return nil, err
+	}
+	return s.Get(id)
+}
+
+// Void cancels an unpaid statement, e.g. one issued in error
+func (s *BillingStatementService) Void(id uuid.UUID, reason string) (*models.BillingStatement, error) {
+	statement, err := s.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if statement.Status != models.StatementUnpaid {
+		return nil, ErrStatementNotUnpaid
+	}
+
+	if statement.PaymentLink != "" && s.billingGateway != nil {
+		if err := s.billingGateway.Cancel(statement.Number); err != nil {
+			log.Printf("⚠️ Failed to cancel payment link of statement %s: %v", statement.Number, err)
+		}
+	}
+	statement.Status = models.StatementVoid
+	statement.VoidReason = strings.TrimSpace(reason)
+	statement.PaymentLink = ""
+	if err := s.statementRepo.Update(statement); err != nil {
+		return nil, fmt.Errorf("failed to void statement: %w", err)
+	}
+	log.Printf("🚫 Statement %s voided: %s", statement.Number, statement.VoidReason)
+	return statement, nil
+}
+
+// ConfirmPayment settles a statement paid through the gateway; repeated notifications are ignored
+func (s *BillingStatementService) ConfirmPayment(reference, paymentMethod, transactionID string) error {
+	statement, err := s.statementRepo.GetByNumber(reference)
+	if err != nil {
+		return fmt.Errorf("statement %s not found: %w", reference, err)
+	}
+	if statement.Status != models.StatementUnpaid {
+		log.Printf("ℹ️ Payment for statement %s ignored, status is %s", reference, statement.Status)
+		return nil
+	}
+	return s.settle(statement, paymentMethod, transactionID)
+}
+
+// CancelPayment drops a payment link that was denied or expired; the statement stays unpaid
+func (s *BillingStatementService) CancelPayment(reference, reason string) error {
+	statement, err := s.statementRepo.GetByNumber(reference)
+	if err != nil {
+		return fmt.Errorf("statement %s not found: %w", reference, err)
+	}
+	if statement.Status != models.StatementUnpaid || statement.PaymentLink == "" {
+		return nil
+	}
+
+	log.Printf("⚠️ Payment link of statement %s closed: %s", reference, reason)
+	statement.PaymentLink = ""
+	return s.statementRepo.Update(statement)
+}
+
+// RunStatementJob issues the previous month's statement of every active client once its period has ended
+// in the client's timezone. Statements already issued are left alone
+func (s *BillingStatementService) RunStatementJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.issueDueStatements(time.Now())
+		}
+	}
+}
+
+func (s *BillingStatementService) issueDueStatements(now time.Time) {
+	clients, err := s.clientRepo.GetActiveClients()
+	if err != nil {
+		log.Printf("⚠️ Failed to list clients for billing statements: %v", err)
+		return
+	}
+
+	issued := 0
+	for i := range clients {
+		client := &clients[i]
+		current, _ := billingPeriod(now, client.Timezone)
+		statement, err := s.generate(client, current.AddDate(0, -1, 0), false)
+		if err != nil {
+			log.Printf("⚠️ Failed to issue billing statement for client %s: %v", client.ID, err)
+			continue
+		}
+		if statement != nil {
+			issued++
+		}
+	}
+
+	if issued > 0 {
+		log.Printf("🧾 Issued %d billing statements", issued)
+	}
+}
+
+// generate builds, stores, renders and sends the client's statement for the period starting at start.
+// It returns nil when there is nothing to invoice: sandbox, custom-plan and newer clients, a zero total,
+// a paid statement, or (unless rebuild) any statement already issued for the period
+func (s *BillingStatementService) generate(client *models.Client, start time.Time, rebuild bool) (*models.BillingStatement, error) {
+	end := start.AddDate(0, 1, 0)
+	if client.SandboxMode || !client.CreatedAt.Before(end) {
+		return nil, nil
+	}
+
+	existing, err := s.statementRepo.GetByPeriod(client.ID, start)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to look up statement: %w", err)
+	}
+	if existing != nil && (!rebuild || existing.Status == models.StatementPaid) {
+		return nil, nil
+	}
+
+	changes, err := s.subscriptionRepo.ListPlanChanges(client.ID, 50)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan changes: %w", err)
+	}
+	// The fee is the plan held when the period started (upgrades were charged prorated when made);
+	// limits and overage rates are those of the plan held when it ended
+	feePlan, _, feeOK := models.FindPlan(planAt(client.SubscriptionPlan, changes, start))
+	plan, _, ok := models.FindPlan(planAt(client.SubscriptionPlan, changes, end))
+	if !ok || !feeOK {
+		return nil, nil // Custom plans are invoiced by hand
+	}
+
+	lines, err := s.overageLines(client.ID, plan, start, end)
+	if err != nil {
+		return nil, err
+	}
+	var overage float64
+	for _, line := range lines {
+		overage += line.Amount
+	}
+	total := feePlan.MonthlyPrice + overage
+	if total <= 0 {
+		return nil, nil
+	}
+
+	linesJSON, err := json.Marshal(lines)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode statement lines: %w", err)
+	}
+
+	statement := existing
+	if statement == nil {
+		statement = &models.BillingStatement{ClientID: client.ID, PeriodStart: start}
+	} else if statement.PaymentLink != "" && s.billingGateway != nil {
+		if err := s.billingGateway.Cancel(statement.Number); err != nil {
+			log.Printf("⚠️ Failed to cancel payment link of statement %s: %v", statement.Number, err)
+		}
+	}
+	now := time.Now()
+	// A rebuilt statement gets a new number, since the gateway does not accept an order ID twice
+	statement.Number = statementNumber(start)
+	statement.Plan = plan.Code
+	statement.PeriodEnd = end
+	statement.PlanFee = feePlan.MonthlyPrice
+	statement.OverageAmount = overage
+	statement.Total = total
+	statement.Lines = datatypes.JSON(linesJSON)
+	statement.Status = models.StatementUnpaid
+	statement.VoidReason = ""
+	statement.PDFURL = ""
+	statement.PaymentLink = ""
+	statement.EmailTo = nil
+	statement.EmailedAt = nil
+
+	if existing == nil {
+		err = s.statementRepo.Create(statement)
+	} else {
+		err = s.statementRepo.Update(statement)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save statement: %w", err)
+	}
+
+	// The statement is recorded; the payment link, PDF and email are best effort
+	s.createPaymentLink(client, statement, feePlan, now)
+	if err := s.render(client, statement, feePlan, lines); err != nil {
+		log.Printf("⚠️ Failed to render statement %s: %v", statement.Number, err)
+	}
+	s.email(client, statement)
+
+	if err := s.statementRepo.Update(statement); err != nil {
+		return nil, fmt.Errorf("failed to save statement: %w", err)
+	}
+	log.Printf("🧾 Statement %s for client %s: Rp %.0f (%s, overage Rp %.0f)", statement.Number, client.ID, statement.Total, plan.Code, overage)
+	return statement, nil
+}
+
+// overageLines measures each billed metric over the period and prices the usage above the plan limit
+func (s *BillingStatementService) overageLines(clientID uuid.UUID, plan *models.Plan, start, end time.Time) ([]models.StatementLine, error) {
+	lines := make([]models.StatementLine, 0, len(statementMetrics))
+	for _, metric := range statementMetrics {
+		used, err := s.measure(clientID, metric, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure %s: %w", metric, err)
+		}
+
+		price, unitSize := plan.Overage.Rate(metric)
+		line := models.StatementLine{
+			Metric:    metric,
+			Used:      used,
+			Included:  int64(plan.Limits.Limit(metric)),
+			UnitSize:  unitSize,
+			UnitPrice: price,
+		}
+		if line.Included > 0 && used > line.Included {
+			line.Overage = used - line.Included
+			units := (line.Overage + int64(unitSize) - 1) / int64(unitSize)
+			line.Amount = math.Round(float64(units) * price)
+		}
+		lines = append(lines, line)
+	}
+	return lines, nil
+}
+
+// measure returns a metric's usage: messages and metered tokens in the period, storage (MB) at generation time
+func (s *BillingStatementService) measure(clientID uuid.UUID, metric string, start, end time.Time) (int64, error) {
+	switch metric {
+	case models.UsageMetricMessages:
+		return s.subscriptionRepo.CountMessagesBetween(clientID, start, end)
+	case models.UsageMetricTokens:
+		totals, err := s.usageRepo.Totals(clientID, start, end)
+		if err != nil {
+			return 0, err
+		}
+		return totals.TotalTokens, nil
+	case models.UsageMetricStorage:
+		size, err := s.subscriptionRepo.StorageBytes(clientID)
+		return (size + bytesPerMB - 1) / bytesPerMB, err
+	}
+	return 0, fmt.Errorf("unknown metric %s", metric)
+}
+
+// createPaymentLink asks the billing gateway for a link paying the statement total
+func (s *BillingStatementService) createPaymentLink(client *models.Client, statement *models.BillingStatement, feePlan *models.Plan, now time.Time) {
+	if s.billingGateway == nil {
+		return
+	}
+
+	items := []payment.OrderItem{{
+		VariantID:   statement.ID,
+		ProductName: fmt.Sprintf("Plan %s", feePlan.Name),
+		VariantName: statement.PeriodStart.Format("January 2006"),
+		Quantity:    1,
+		UnitPrice:   statement.PlanFee,
+		Subtotal:    statement.PlanFee,
+	}}
+	if statement.OverageAmount > 0 {
+		items = append(items, payment.OrderItem{
+			VariantID:   statement.ID,
+			ProductName: "Usage overage",
+			VariantName: statement.PeriodStart.Format("January 2006"),
+			Quantity:    1,
+			UnitPrice:   statement.OverageAmount,
+			Subtotal:    statement.OverageAmount,
+		})
+	}
+
+	result, err := s.billingGateway.Process(&payment.Order{
+		ID:            statement.ID,
+		ClientID:      client.ID,
+		OrderNumber:   statement.Number,
+		CustomerPhone: client.WhatsAppNumber,
+		CustomerName:  client.BusinessName,
+		Items:         items,
+		TotalAmount:   statement.Total,
+		Currency:      "IDR",
+		Status:        payment.StatusPending,
+		CreatedAt:     now,
+	})
+	if err != nil {
+		log.Printf("⚠️ Failed to create payment link for statement %s: %v", statement.Number, err)
+		return
+	}
+	statement.PaymentLink = result.PaymentLink
+}
+
+// render builds the statement PDF and uploads it
+func (s *BillingStatementService) render(client *models.Client, statement *models.BillingStatement, feePlan *models.Plan, lines []models.StatementLine) error {
+	loc := clientLocation(client.Timezone)
+	doc := &export.Statement{
+		Number:      statement.Number,
+		Issuer:      s.issuer,
+		BillTo:      client.BusinessName,
+		Plan:        statement.Plan,
+		PeriodStart: statement.PeriodStart.In(loc),
+		PeriodEnd:   statement.PeriodEnd.In(loc),
+		IssuedAt:    time.Now().In(loc),
+		Currency:    "IDR",
+		Total:       statement.Total,
+		Status:      statement.Status,
+		PaymentLink: statement.PaymentLink,
+		Lines: []export.StatementLine{{
+			Description: fmt.Sprintf("Plan %s", feePlan.Name),
+			Detail:      "Monthly fee",
+			Amount:      statement.PlanFee,
+		}},
+	}
+	for _, line := range lines {
+		doc.Lines = append(doc.Lines, export.StatementLine{
+			Description: statementMetricLabel(line.Metric),
+			Detail:      statementLineDetail(line),
+			Amount:      line.Amount,
+		})
+	}
+
+	data, err := s.exportService.ExportStatement(doc)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("statement_%s.pdf", statement.Number)
+	res, err := s.uploadService.Upload(bytes.NewReader(data), filename, &upload.UploadOptions{
+		Folder:       statementFolder + "/" + client.ID.String(),
+		ResourceType: "raw",
+		AllowedTypes: []string{"application/pdf"},
+		MaxSize:      statementMaxBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store statement: %w", err)
+	}
+
+	statement.PDFURL = res.URL
+	if res.SecureURL != "" {
+		statement.PDFURL = res.SecureURL
+	}
+	return nil
+}
+
+// email sends the statement to the client's tenant admins
+func (s *BillingStatementService) email(client *models.Client, statement *models.BillingStatement) {
+	if s.mailer == nil {
+		log.Printf("⚠️ Statement %s not emailed, email is not configured", statement.Number)
+		return
+	}
+	recipients, err := s.companyUserRepo.ListAdminEmails(client.ID)
+	if err != nil {
+		log.Printf("⚠️ Failed to look up tenant admins for statement %s: %v", statement.Number, err)
+		return
+	}
+	if len(recipients) == 0 {
+		log.Printf("⚠️ Statement %s not emailed, client %s has no tenant admin with an email", statement.Number, client.ID)
+		return
+	}
+
+	month := statement.PeriodStart.In(clientLocation(client.Timezone)).Format("January 2006")
+	subject := fmt.Sprintf("Statement %s for %s", statement.Number, month)
+	body := fmt.Sprintf(
+		"<p>Hello %s,</p><p>Your statement for <b>%s</b> is ready: plan fee Rp %.0f, usage overage Rp %.0f, <b>total Rp %.0f</b>.</p>",
+		html.EscapeString(client.BusinessName), month, statement.PlanFee, statement.OverageAmount, statement.Total,
+	)
+	if statement.PDFURL != "" {
+		body += fmt.Sprintf("<p><a href=\"%s\">Download statement (PDF)</a></p>", html.EscapeString(statement.PDFURL))
+	}
+	if statement.PaymentLink != "" {
+		body += fmt.Sprintf("<p><a href=\"%s\">Pay now</a></p>", html.EscapeString(statement.PaymentLink))
+	}
+
+	for _, to := range recipients {
+		if err := s.mailer.SendEmail(to, subject, body); err != nil {
+			log.Printf("⚠️ Failed to email statement %s to %s: %v", statement.Number, to, err)
+			continue
+		}
+		statement.EmailTo = append(statement.EmailTo, to)
+	}
+	if len(statement.EmailTo) > 0 {
+		now := time.Now()
+		statement.EmailedAt = &now
+	}
+}
+
+func (s *BillingStatementService) settle(statement *models.BillingStatement, paymentMethod, transactionID string) error {
+	settled, err := s.statementRepo.MarkPaid(statement.ID, paymentMethod, transactionID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to mark statement paid: %w", err)
+	}
+	if !settled {
+		log.Printf("ℹ️ Statement %s was settled concurrently", statement.Number)
+		return nil
+	}
+	log.Printf("✅ Statement %s paid (%s)", statement.Number, paymentMethod)
+	return nil
+}
+
+// planAt returns the plan the client held at the given time, undoing the changes applied since
+func planAt(current string, changes []models.PlanChange, at time.Time) string {
+	plan := current
+	// changes are newest first, so the last match is the earliest change applied since then
+	for _, change := range changes {
+		if change.Status == models.PlanChangeApplied && change.AppliedAt != nil && !change.AppliedAt.Before(at) {
+			plan = change.FromPlan
+		}
+	}
+	return plan
+}
+
+// statementPeriodStart parses a YYYY-MM month in the client's timezone, defaulting to the previous month
+func statementPeriodStart(period, timezone string, now time.Time) (time.Time, error) {
+	current, _ := billingPeriod(now, timezone)
+	if period == "" {
+		return current.AddDate(0, -1, 0), nil
+	}
+
+	month, err := time.ParseInLocation("2006-01", period, current.Location())
+	if err != nil || !month.Before(current) {
+		return time.Time{}, ErrInvalidStatementPeriod
+	}
+	return month, nil
+}
+
+func statementNumber(start time.Time) string {
+	return fmt.Sprintf("%s%s-%s", StatementReferencePrefix, start.Format("200601"), strings.ToUpper(uuid.NewString()[:8]))
+}
+
+func statementMetricLabel(metric string) string {
+	switch metric {
+	case models.UsageMetricMessages:
+		return "Messages"
+	case models.UsageMetricTokens:
+		return "AI tokens (estimated)"
+	case models.UsageMetricStorage:
+		return "Storage (MB)"
+	}
+	return metric
+}
+
+// statementLineDetail describes the usage behind a line, e.g. "2350 used, 2000 included, 350 over x Rp 50"
+func statementLineDetail(line models.StatementLine) string {
+	if line.Included == 0 {
+		return fmt.Sprintf("%d used, unlimited", line.Used)
+	}
+	detail := fmt.Sprintf("%d used, %d included", line.Used, line.Included)
+	if line.Overage > 0 {
+		unit := ""
+		if line.UnitSize > 1 {
+			unit = fmt.Sprintf(" per %d", line.UnitSize)
+		}
+		detail += fmt.Sprintf(", %d over x Rp %.0f%s", line.Overage, line.UnitPrice, unit)
+	}
+	return detail
+}
@@ -0,0 +1,217 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// resumeDateLayouts are the accepted "bot off sampai ..." formats (day first)
+var resumeDateLayouts = []struct {
+	layout  string
+	hasYear bool
+}{
+	{"2/1/2006 15:04", true},
+	{"2/1/2006", true},
+	{"2/1 15:04", false},
+	{"2/1", false},
+}
+
+// BotPauseService handles vacation mode: the owner pauses the bot from WhatsApp and it resumes on schedule
+type BotPauseService struct {
+	clientRepo  repositories.ClientRepo
+	whatsappSvc WhatsAppService
+	sandboxSvc  *SandboxService
+
+	// Customers already sent the away message, per pause (client|phone -> paused_at)
+	mu       sync.Mutex
+	notified map[string]time.Time
+}
+
+// NewBotPauseService creates a new bot pause service
+func NewBotPauseService(clientRepo repositories.ClientRepo, whatsappSvc WhatsAppService, sandboxSvc *SandboxService) *BotPauseService {
+	return &BotPauseService{
+		clientRepo:  clientRepo,
+		whatsappSvc: whatsappSvc,
+		sandboxSvc:  sandboxSvc,
+		notified:    make(map[string]time.Time),
+	}
+}
+
+// Pause stops the bot for a client until resumeAt (nil = until resumed manually)
+func (s *BotPauseService) Pause(clientID, actor string, resumeAt *time.Time) (*models.Client, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	now := time.Now()
+	client.BotPaused = true
+	client.BotPausedAt = &now
+	client.BotPausedBy = actor
+	client.BotResumeAt = resumeAt
+	if err := s.clientRepo.UpdateBotPause(client); err != nil {
+		return nil, fmt.Errorf("failed to pause bot: %w", err)
+	}
+
+	log.Printf("⏸️  Bot paused for client %s by %s (resume: %v)", clientID, actor, resumeAt)
+	return client, nil
+}
+
+// Resume turns the bot back on for a client
+func (s *BotPauseService) Resume(clientID, actor string) (*models.Client, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	if err := s.resume(client); err != nil {
+		return nil, err
+	}
+
+	log.Printf("▶️  Bot resumed for client %s by %s", clientID, actor)
+	return client, nil
+}
+
+func (s *BotPauseService) resume(client *models.Client) error {
+	client.BotPaused = false
+	client.BotPausedAt = nil
+	client.BotPausedBy = ""
+	client.BotResumeAt = nil
+	if err := s.clientRepo.UpdateBotPause(client); err != nil {
+		return fmt.Errorf("failed to resume bot: %w", err)
+	}
+
+	s.mu.Lock()
+	prefix := client.ID.String() + "|"
+	for key := range s.notified {
+		if strings.HasPrefix(key, prefix) {
+			delete(s.notified, key)
+		}
+	}
+	s.mu.Unlock()
+	return nil
+}
+
+// IsPaused reports whether the bot is paused, resuming it if the scheduled time has passed
+func (s *BotPauseService) IsPaused(client *models.Client) bool {
+	if !client.BotPaused {
+		return false
+	}
+	if client.BotResumeAt != nil && !time.Now().Before(*client.BotResumeAt) {
+		if err := s.resume(client); err != nil {
+			log.Printf("⚠️ Failed to auto-resume bot for client %s: %v", client.ID, err)
+		}
+		return false
+	}
+	return true
+}
+
+// AwayReply returns the away message for a customer, once per customer per pause
+func (s *BotPauseService) AwayReply(client *models.Client, customerPhone string) (string, bool) {
+	key := client.ID.String() + "|" + customerPhone
+
+	var pausedAt time.Time
+	if client.BotPausedAt != nil {
+		pausedAt = *client.BotPausedAt
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if notifiedFor, ok := s.notified[key]; ok && notifiedFor.Equal(pausedAt) {
+		return "", false
+	}
+	s.notified[key] = pausedAt
+
+	message := fmt.Sprintf("🙏 Terima kasih telah menghubungi *%s*.\n\nSaat ini kami sedang libur", client.BusinessName)
+	if client.BotResumeAt != nil {
+		message += " dan akan kembali melayani mulai " + formatResumeTime(*client.BotResumeAt, client.Timezone)
+	}
+	message += ". Pesan Anda akan kami balas setelah kami kembali."
+	return message, true
+}
+
+// RunAutoResume periodically resumes bots whose scheduled resume time has passed and tells the admin
+func (s *BotPauseService) RunAutoResume(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.resumeDue()
+		}
+	}
+}
+
+func (s *BotPauseService) resumeDue() {
+	clients, err := s.clientRepo.ListBotResumeDue(time.Now())
+	if err != nil {
+		log.Printf("⚠️ Failed to list paused bots: %v", err)
+		return
+	}
+
+	for i := range clients {
+		client := &clients[i]
+		admin := client.BotPausedBy
+		if err := s.resume(client); err != nil {
+			log.Printf("⚠️ Failed to auto-resume bot for client %s: %v", client.ID, err)
+			continue
+		}
+
+		log.Printf("▶️  Bot auto-resumed for client %s", client.ID)
+		if admin != "" {
+			sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, client.ID).SendMessage(admin, "▶️ *Bot Aktif Kembali*\n\nMasa libur telah selesai, bot kembali membalas pelanggan secara otomatis.")
+		}
+	}
+}
+
+// parseResumeTime parses "25/04", "25/04/2026" or "25/04 08:00" in the client's timezone.
+// Without a time the bot resumes at the start of that day; without a year the next such date is used.
+func parseResumeTime(text, timezone string, now time.Time) (time.Time, error) {
+	text = strings.Join(strings.Fields(strings.ReplaceAll(text, "-", "/")), " ")
+	loc := clientLocation(timezone)
+
+	for _, format := range resumeDateLayouts {
+		layout := format.layout
+		value := text
+		if !format.hasYear {
+			// time.Parse defaults the year to 0; parse with the current year instead
+			layout = strings.Replace(layout, "2/1", "2/1/2006", 1)
+			parts := strings.SplitN(text, " ", 2)
+			parts[0] += fmt.Sprintf("/%d", now.In(loc).Year())
+			value = strings.Join(parts, " ")
+		}
+
+		t, err := time.ParseInLocation(layout, value, loc)
+		if err != nil {
+			continue
+		}
+		if !format.hasYear && !t.After(now) {
+			t = t.AddDate(1, 0, 0)
+		}
+		if !t.After(now) {
+			return time.Time{}, fmt.Errorf("resume time %s has already passed", text)
+		}
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid date %q", text)
+}
+
+// formatResumeTime formats a resume time for WhatsApp messages in the client's timezone
+func formatResumeTime(t time.Time, timezone string) string {
+	local := t.In(clientLocation(timezone))
+	if local.Hour() == 0 && local.Minute() == 0 {
+		return local.Format("02/01/2006")
+	}
+	return local.Format("02/01/2006 15:04")
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// ErrInsufficientBranchStock is returned when a branch can't fulfill the ordered quantities
+var ErrInsufficientBranchStock = errors.New("insufficient stock at branch")
+
+// BranchService manages branch-level stock, branch selection and branch analytics
+// Branches are the client's stores (see StoreService)
+type BranchService struct {
+	storeRepo       repositories.StoreRepo
+	branchStockRepo repositories.BranchStockRepo
+	productRepo     repositories.ProductRepo
+	cartService     *CartService
+	aggregator      *analytics.Aggregator
+}
+
+func NewBranchService(
+	storeRepo repositories.StoreRepo,
+	branchStockRepo repositories.BranchStockRepo,
+	productRepo repositories.ProductRepo,
+	cartService *CartService,
+	aggregator *analytics.Aggregator,
+) *BranchService {
+	return &BranchService{
+		storeRepo:       storeRepo,
+		branchStockRepo: branchStockRepo,
+		productRepo:     productRepo,
+		cartService:     cartService,
+		aggregator:      aggregator,
+	}
+}
+
+// GetBranch retrieves a branch by ID, scoped to the client
+func (s *BranchService) GetBranch(clientID uuid.UUID, branchID string) (*models.Store, error) {
+	branch, err := s.storeRepo.GetByID(branchID)
+	if err != nil || branch.ClientID != clientID {
+		return nil, errors.New("branch not found")
+	}
+	return branch, nil
+}
+
+// SetStock sets a product's stock at a branch
+func (s *BranchService) SetStock(clientID uuid.UUID, branchID string, req *models.SetBranchStockRequest) (*models.BranchStock, error) {
+	if req.Stock < 0 {
+		return nil, errors.New("stock cannot be negative")
+	}
+
+	branch, err := s.GetBranch(clientID, branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := s.productRepo.GetByID(req.ProductID)
+	if err != nil || product.ClientID != clientID {
+		return nil, errors.New("product not found")
+	}
+
+	stock := &models.BranchStock{
+		ClientID:  clientID,
+		BranchID:  branch.ID,
+		ProductID: product.ID,
+		Stock:     req.Stock,
+	}
+	if err := s.branchStockRepo.Upsert(stock); err != nil {
+		return nil, fmt.Errorf("failed to set branch stock: %w", err)
+	}
+
+	log.Printf("🏪 Stock for %s at branch %s set to %d", product.Name, branch.Name, req.Stock)
+	return s.branchStockRepo.Get(branch.ID, product.ID)
+}
+
+// ListBranchStock lists product stock at a branch
+func (s *BranchService) ListBranchStock(clientID uuid.UUID, branchID string) ([]models.BranchStock, error) {
+	branch, err := s.GetBranch(clientID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	return s.branchStockRepo.ListByBranch(branch.ID)
+}
+
+// ListProductStock lists a product's stock at every branch
+func (s *BranchService) ListProductStock(clientID uuid.UUID, productID string) ([]models.BranchStock, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil || product.ClientID != clientID {
+		return nil, errors.New("product not found")
+	}
+	return s.branchStockRepo.ListByProduct(product.ID)
+}
+
+// SelectCartBranch assigns a branch (customer choice) to the customer's active cart
+func (s *BranchService) SelectCartBranch(clientID, customerPhone, branchID string) (*models.Cart, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client ID: %w", err)
+	}
+
+	branch, err := s.GetBranch(uid, branchID)
+	if err != nil {
+		return nil, err
+	}
+	if !branch.IsActive {
+		return nil, errors.New("branch is not active")
+	}
+
+	return s.cartService.SetBranch(clientID, customerPhone, branch.ID)
+}
+
+// ResolveCheckoutBranch picks the branch that fulfills an order
+// An explicit branch must have enough stock; otherwise the first active branch with enough stock is used
+// Returns nil when the client has no branches
+func (s *BranchService) ResolveCheckoutBranch(clientID uuid.UUID, branchID string, items []models.OrderItem) (*models.Store, error) {
+	if branchID != "" {
+		branch, err := s.GetBranch(clientID, branchID)
+		if err != nil {
+			return nil, err
+		}
+		if ok, err := s.hasStock(branch.ID, items); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%w %s", ErrInsufficientBranchStock, branch.Name)
+		}
+		return branch, nil
+	}
+
+	branches, err := s.storeRepo.ListByClientID(clientID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list branches: %w", err)
+	}
+	if len(branches) == 0 {
+		return nil, nil
+	}
+
+	for i := range branches {
+		ok, err := s.hasStock(branches[i].ID, items)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return &branches[i], nil
+		}
+	}
+
+	return nil, ErrInsufficientBranchStock
+}
+
+// ReserveStock deducts ordered quantities from branch stock (products not tracked per branch are skipped)
+func (s *BranchService) ReserveStock(branchID uuid.UUID, items []models.OrderItem) error {
+	return s.branchStockRepo.Transaction(func(repo repositories.BranchStockRepo) error {
+		for _, item := range items {
+			productID, tracked, err := s.trackedProduct(repo, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if !tracked {
+				continue
+			}
+
+			ok, err := repo.Deduct(branchID, productID, item.Quantity)
+			if err != nil {
+				return fmt.Errorf("failed to deduct stock: %w", err)
+			}
+			if !ok {
+				return fmt.Errorf("%w for %s", ErrInsufficientBranchStock, item.ProductName)
+			}
+		}
+		return nil
+	})
+}
+
+// ReleaseStock returns ordered quantities to branch stock (e.g. when an order is cancelled)
+func (s *BranchService) ReleaseStock(branchID uuid.UUID, items []models.OrderItem) error {
+	return s.branchStockRepo.Transaction(func(repo repositories.BranchStockRepo) error {
+		for _, item := range items {
+			productID, tracked, err := s.trackedProduct(repo, item.ProductID)
+			if err != nil {
+				return err
+			}
+			if !tracked {
+				continue
+			}
+
+			if err := repo.Restore(branchID, productID, item.Quantity); err != nil {
+				return fmt.Errorf("failed to restore stock: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetSalesAnalytics returns order totals for a period with a per-branch breakdown
+// When branchID is set, totals are scoped to that branch
+func (s *BranchService) GetSalesAnalytics(clientID uuid.UUID, branchID, period string) (*models.SalesAnalytics, error) {
+	if period == "" {
+		period = "this_month"
+	}
+	dateRange := analytics.GetDateRange(period)
+
+	filters := map[string]interface{}{
+		"client_id": clientID,
+		"is_test":   false,
+	}
+
+	result := &models.SalesAnalytics{
+		Period:   period,
+		Start:    dateRange.Start,
+		End:      dateRange.End,
+		Branches: []models.BranchSales{},
+	}
+
+	if branchID != "" {
+		branch, err := s.GetBranch(clientID, branchID)
+		if err != nil {
+			return nil, err
+		}
+		filters["branch_id"] = branch.ID
+		result.BranchID = &branch.ID
+	}
+
+	rows, err := s.aggregator.Aggregate(analytics.AggregateQuery{
+		Table:   "saas_orders",
+		GroupBy: []string{"branch_id"},
+		Aggregates: map[string]string{
+			"total_orders": "COUNT(*)",
+			"paid_orders":  fmt.Sprintf("COUNT(*) FILTER (WHERE payment_status = '%s')", models.PaymentStatusPaid),
+			"revenue":      fmt.Sprintf("COALESCE(SUM(total_amount) FILTER (WHERE payment_status = '%s'), 0)", models.PaymentStatusPaid),
+		},
+		Filters:   filters,
+		DateRange: dateRange,
+		OrderBy:   []string{"revenue DESC"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	branchNames := make(map[string]string)
+	if branches, err := s.storeRepo.ListByClientID(clientID, false); err == nil {
+		for _, b := range branches {
+			branchNames[b.ID.String()] = b.Name
+		}
+	}
+
+	for _, row := range rows {
+		sales := models.BranchSales{
+			TotalOrders: toInt64(row["total_orders"]),
+			PaidOrders:  toInt64(row["paid_orders"]),
+			Revenue:     toFloat64(row["revenue"]),
+			BranchName:  "Tanpa cabang",
+		}
+		if id, err := uuid.Parse(fmt.Sprint(row["branch_id"])); err == nil {
+			sales.BranchID = &id
+			if name, ok := branchNames[id.String()]; ok {
+				sales.BranchName = name
+			}
+		}
+
+		result.TotalOrders += sales.TotalOrders
+		result.PaidOrders += sales.PaidOrders
+		result.Revenue += sales.Revenue
+		result.Branches = append(result.Branches, sales)
+	}
+
+	return result, nil
+}
+
+// hasStock checks whether a branch can fulfill all tracked items
+func (s *BranchService) hasStock(branchID uuid.UUID, items []models.OrderItem) (bool, error) {
+	for _, item := range items {
+		productID, tracked, err := s.trackedProduct(s.branchStockRepo, item.ProductID)
+		if err != nil {
+			return false, err
+		}
+		if !tracked {
+			continue
+		}
+
+		stock, err := s.branchStockRepo.Get(branchID, productID)
+		if err != nil || stock.Stock < item.Quantity {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// trackedProduct parses an order item's product ID and reports whether its stock is tracked per branch
+func (s *BranchService) trackedProduct(repo repositories.BranchStockRepo, rawID string) (uuid.UUID, bool, error) {
+	productID, err := uuid.Parse(rawID)
+	if err != nil || productID == uuid.Nil {
+		return uuid.Nil, false, nil
+	}
+
+	tracked, err := repo.IsTracked(productID)
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("failed to check branch stock: %w", err)
+	}
+	return productID, tracked, nil
+}
+
+// toInt64 converts an aggregate result value to int64
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int32:
+		return int64(v)
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
+
+// toFloat64 converts an aggregate result value (numeric columns may come back as strings) to float64
+func toFloat64(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int64:
+		return float64(v)
+	case string:
+		var f float64
+		fmt.Sscanf(strings.TrimSpace(v), "%f", &f)
+		return f
+	case []byte:
+		var f float64
+		fmt.Sscanf(strings.TrimSpace(string(v)), "%f", &f)
+		return f
+	}
+	return 0
+}
+
+// --- Order integration ---
+
+// assignBranch resolves the fulfilling branch for a new order
+func (s *OrderService) assignBranch(clientID, branchID string, items []models.OrderItem) (*models.Store, error) {
+	if s.branchSvc == nil {
+		return nil, nil
+	}
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client ID: %w", err)
+	}
+
+	return s.branchSvc.ResolveCheckoutBranch(uid, branchID, items)
+}
+
+// releaseBranchStock returns a cancelled order's items to its branch stock
+func (s *OrderService) releaseBranchStock(order *models.Order) {
+	if s.branchSvc == nil || order.BranchID == nil {
+		return
+	}
+
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		log.Printf("⚠️  Failed to parse items of order %s: %v", order.OrderNumber, err)
+		return
+	}
+
+	if err := s.branchSvc.ReleaseStock(*order.BranchID, items); err != nil {
+		log.Printf("⚠️  Failed to release branch stock for order %s: %v", order.OrderNumber, err)
+	}
+}
+
+// notifyBranchAdmin sends an order event to the admin of the order's branch
+func (s *OrderService) notifyBranchAdmin(order *models.Order, title, detail string) {
+	if s.branchSvc == nil || order.BranchID == nil {
+		return
+	}
+
+	branch, err := s.branchSvc.GetBranch(order.ClientID, order.BranchID.String())
+	if err != nil || branch.AdminPhone == "" {
+		return
+	}
+
+	message := fmt.Sprintf(
+		"🏪 *%s - %s*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Pelanggan: %s\n"+
+			"Total: *Rp %s*",
+		title,
+		branch.Name,
+		order.OrderNumber,
+		order.CustomerPhone,
+		formatPrice(order.TotalAmount),
+	)
+	if detail != "" {
+		message += "\n\n" + detail
+	}
+
+	if err := s.messenger(order.ClientID).SendMessage(branch.AdminPhone, message); err != nil {
+		log.Printf("⚠️  Failed to notify branch admin %s: %v", branch.Name, err)
+	}
+}
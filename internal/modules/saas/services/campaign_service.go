@@ -0,0 +1,520 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// campaignStaleAfter is how long a sending campaign may go without progress before another worker resumes it
+	campaignStaleAfter = 10 * time.Minute
+	// campaignBatch is how many recipients are loaded at a time while sending
+	campaignBatch = 50
+	// campaignClaimLimit is how many due campaigns one job tick starts
+	campaignClaimLimit = 10
+
+	defaultCampaignThrottle = 3
+	maxCampaignThrottle     = 60
+	defaultCampaignDays     = 30
+	maxCampaignDays         = 365
+	maxCampaignMessage      = 4096
+)
+
+// WhatsApp message acks reported by the provider
+const (
+	whatsappAckError  = -1
+	whatsappAckDevice = 2
+	whatsappAckRead   = 3
+	whatsappAckPlayed = 4
+)
+
+var (
+	ErrCampaignNotFound = errors.New("campaign not found")
+	// ErrCampaignNotEditable is returned when changing a campaign that already started
+	ErrCampaignNotEditable = errors.New("campaign can only be changed while it is a draft or scheduled")
+)
+
+// CampaignService sends a client's broadcast campaigns: it resolves the audience when the campaign comes due,
+// sends the message to one recipient at a time with a pause in between (so the number isn't flagged for
+// spam), and tracks each message up to read from WhatsApp's delivery acks
+type CampaignService struct {
+	repo        repositories.CampaignRepo
+	tagService  *ConversationTagService
+	whatsappSvc *whatsapp.Service
+	sandboxSvc  *SandboxService
+	sessions    *whatsapp.SessionManager
+	segmentSvc  *CustomerSegmentService
+
+	// Campaigns being sent by this instance
+	mu      sync.Mutex
+	sending map[uuid.UUID]bool
+}
+
+// NewCampaignService creates a new campaign service
+func NewCampaignService(repo repositories.CampaignRepo, tagService *ConversationTagService, whatsappSvc *whatsapp.Service, sandboxSvc *SandboxService) *CampaignService {
+	return &CampaignService{
+		repo:        repo,
+		tagService:  tagService,
+		whatsappSvc: whatsappSvc,
+		sandboxSvc:  sandboxSvc,
+		sending:     make(map[uuid.UUID]bool),
+	}
+}
+
+// SetSessionManager sends each client's campaigns through the client's own WhatsApp session
+func (s *CampaignService) SetSessionManager(sessions *whatsapp.SessionManager) {
+	s.sessions = sessions
+}
+
+// SetSegmentService enables the segment audience
+func (s *CampaignService) SetSegmentService(segmentSvc *CustomerSegmentService) {
+	s.segmentSvc = segmentSvc
+}
+
+// Create stores a campaign as a draft, or scheduled when the request has a send time
+func (s *CampaignService) Create(clientID uuid.UUID, req *models.CampaignRequest) (*models.Campaign, error) {
+	campaign := &models.Campaign{ClientID: clientID, CreatedBy: req.CreatedBy}
+	if err := s.applyRequest(campaign, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(campaign); err != nil {
+		return nil, fmt.Errorf("failed to create campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// Update changes a campaign that hasn't started sending
+func (s *CampaignService) Update(clientID, id uuid.UUID, req *models.CampaignRequest) (*models.Campaign, error) {
+	campaign, err := s.get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != models.CampaignStatusDraft && campaign.Status != models.CampaignStatusScheduled {
+		return nil, ErrCampaignNotEditable
+	}
+	if err := s.applyRequest(campaign, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(campaign); err != nil {
+		return nil, fmt.Errorf("failed to update campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// Schedule queues a draft campaign for the given time (now when nil)
+func (s *CampaignService) Schedule(clientID, id uuid.UUID, at *time.Time) (*models.Campaign, error) {
+	campaign, err := s.get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Status != models.CampaignStatusDraft && campaign.Status != models.CampaignStatusScheduled {
+		return nil, ErrCampaignNotEditable
+	}
+
+	scheduledAt := time.Now()
+	if at != nil {
+		scheduledAt = *at
+	}
+	campaign.ScheduledAt = &scheduledAt
+	campaign.Status = models.CampaignStatusScheduled
+	if err := s.repo.Update(campaign); err != nil {
+		return nil, fmt.Errorf("failed to schedule campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// Cancel stops a campaign; a campaign being sent stops after the current recipient
+func (s *CampaignService) Cancel(clientID, id uuid.UUID) (*models.Campaign, error) {
+	campaign, err := s.get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch campaign.Status {
+	case models.CampaignStatusDraft, models.CampaignStatusScheduled, models.CampaignStatusSending:
+	default:
+		return nil, fmt.Errorf("campaign is already %s", campaign.Status)
+	}
+
+	campaign.Status = models.CampaignStatusCancelled
+	if err := s.repo.Update(campaign); err != nil {
+		return nil, fmt.Errorf("failed to cancel campaign: %w", err)
+	}
+	if _, err := s.repo.SkipPending(campaign.ID); err != nil {
+		log.Printf("⚠️ Failed to skip recipients of cancelled campaign %s: %v", campaign.ID, err)
+	}
+	return campaign, nil
+}
+
+// Get returns a campaign with its recipients counted per delivery status
+func (s *CampaignService) Get(clientID, id uuid.UUID) (*models.Campaign, error) {
+	campaign, err := s.get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if campaign.Delivery, err = s.repo.CountRecipients(campaign.ID); err != nil {
+		return nil, fmt.Errorf("failed to count recipients: %w", err)
+	}
+	return campaign, nil
+}
+
+// List returns a client's campaigns newest first
+func (s *CampaignService) List(clientID uuid.UUID, status string, limit int) ([]models.Campaign, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	return s.repo.List(clientID, status, limit)
+}
+
+// ListRecipients returns a campaign's recipients, optionally of one delivery status
+func (s *CampaignService) ListRecipients(clientID, id uuid.UUID, status string, limit int) ([]models.CampaignRecipient, error) {
+	campaign, err := s.get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+	return s.repo.ListRecipients(campaign.ID, status, limit)
+}
+
+// PreviewAudience counts the customers a campaign request would currently reach
+func (s *CampaignService) PreviewAudience(clientID uuid.UUID, req *models.CampaignRequest) (int, error) {
+	campaign := &models.Campaign{ClientID: clientID}
+	req.Name, req.Message = "preview", "preview" // Only the audience is checked
+	if err := s.applyRequest(campaign, req); err != nil {
+		return 0, err
+	}
+	phones, err := s.audience(campaign)
+	if err != nil {
+		return 0, err
+	}
+	return len(phones), nil
+}
+
+// CancelClientCampaigns is an offboarding step: a deactivated client's campaigns must not go out
+func (s *CampaignService) CancelClientCampaigns(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	cancelled, err := s.repo.CancelActive(client.ID)
+	if err != nil {
+		return "", fmt.Errorf("failed to cancel campaigns: %w", err)
+	}
+	return fmt.Sprintf("%d campaign(s) cancelled", cancelled), nil
+}
+
+// campaignAckStatus is the recipient status a WhatsApp ack moves a campaign message to, "" when it is not tracked
+func campaignAckStatus(ack int) string {
+	switch ack {
+	case whatsappAckError:
+		return models.CampaignRecipientFailed
+	case whatsappAckDevice:
+		return models.CampaignRecipientDelivered
+	case whatsappAckRead, whatsappAckPlayed:
+		return models.CampaignRecipientRead
+	}
+	return ""
+}
+
+// ackMessageID strips the chat prefix WAHA adds to the IDs of sent messages ("true_628123@c.us_3EB0..."),
+// leaving the bare ID its acks may report
+func ackMessageID(messageID string) string {
+	return messageID[strings.LastIndex(messageID, "_")+1:]
+}
+
+// HandleAck records a WhatsApp delivery ack on the campaign message it belongs to, if any. clientID scopes
+// the lookup to the client whose session reported the ack; nil on single-number deployments.
+func (s *CampaignService) HandleAck(clientID *uuid.UUID, messageID string, ack int) {
+	status := campaignAckStatus(ack)
+	if status == "" || messageID == "" {
+		return
+	}
+
+	if _, err := s.repo.ApplyAck(clientID, ackMessageID(messageID), status, time.Now()); err != nil {
+		log.Printf("⚠️ Failed to record ack of message %s: %v", messageID, err)
+	}
+}
+
+// RunCampaignJob starts the campaigns that came due and resumes the ones left unfinished by a stopped instance
+func (s *CampaignService) RunCampaignJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.startDue(ctx, time.Now())
+		}
+	}
+}
+
+func (s *CampaignService) startDue(ctx context.Context, now time.Time) {
+	campaigns, err := s.repo.ClaimDue(now, now.Add(-campaignStaleAfter), campaignClaimLimit)
+	if err != nil {
+		log.Printf("⚠️ Failed to claim due campaigns: %v", err)
+	}
+	for i := range campaigns {
+		campaign := campaigns[i]
+		s.mu.Lock()
+		if s.sending[campaign.ID] {
+			s.mu.Unlock()
+			continue
+		}
+		s.sending[campaign.ID] = true
+		s.mu.Unlock()
+
+		go s.send(ctx, &campaign)
+	}
+}
+
+// send resolves the audience of a claimed campaign on first run, then messages its pending recipients
+func (s *CampaignService) send(ctx context.Context, campaign *models.Campaign) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.sending, campaign.ID)
+		s.mu.Unlock()
+	}()
+
+	if campaign.TotalRecipients == 0 {
+		phones, err := s.audience(campaign)
+		if err != nil {
+			s.finish(campaign, models.CampaignStatusFailed, fmt.Sprintf("failed to resolve audience: %v", err))
+			return
+		}
+		recipients := make([]models.CampaignRecipient, len(phones))
+		for i, phone := range phones {
+			recipients[i] = models.CampaignRecipient{
+				CampaignID:    campaign.ID,
+				ClientID:      campaign.ClientID,
+				CustomerPhone: phone,
+				Status:        models.CampaignRecipientPending,
+			}
+		}
+		if err := s.repo.AddRecipients(recipients); err != nil {
+			s.finish(campaign, models.CampaignStatusFailed, fmt.Sprintf("failed to store recipients: %v", err))
+			return
+		}
+		campaign.TotalRecipients = len(phones)
+		s.saveProgress(campaign)
+	}
+
+	log.Printf("📣 Sending campaign %q (%s) to %d customer(s)", campaign.Name, campaign.ID, campaign.TotalRecipients)
+
+	throttle := time.Duration(campaign.ThrottleSeconds) * time.Second
+	for {
+		recipients, err := s.repo.ListRecipients(campaign.ID, models.CampaignRecipientPending, campaignBatch)
+		if err != nil {
+			log.Printf("⚠️ Failed to load recipients of campaign %s: %v", campaign.ID, err)
+			return // Resumed by another tick once the campaign goes stale
+		}
+		if len(recipients) == 0 {
+			break
+		}
+
+		for i := range recipients {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(throttle):
+			}
+
+			// Cancelling from the dashboard or offboarding only changes the stored status
+			if status, err := s.repo.GetStatus(campaign.ID); err == nil && status == models.CampaignStatusCancelled {
+				if _, err := s.repo.SkipPending(campaign.ID); err != nil {
+					log.Printf("⚠️ Failed to skip recipients of cancelled campaign %s: %v", campaign.ID, err)
+				}
+				log.Printf("🛑 Campaign %s cancelled after %d message(s)", campaign.ID, campaign.SentCount)
+				return
+			}
+
+			s.sendTo(campaign, &recipients[i])
+			s.saveProgress(campaign) // Also a heartbeat that keeps other workers from resuming the campaign
+		}
+	}
+
+	s.finish(campaign, models.CampaignStatusCompleted, "")
+	log.Printf("✅ Campaign %s done: %d sent, %d failed", campaign.ID, campaign.SentCount, campaign.FailedCount)
+}
+
+// sendTo messages one recipient and records the provider message ID for delivery acks
+func (s *CampaignService) sendTo(campaign *models.Campaign, recipient *models.CampaignRecipient) {
+	messageID, err := s.deliver(campaign.ClientID.String(), recipient.CustomerPhone, campaign.Message)
+	now := time.Now()
+	if err != nil {
+		log.Printf("⚠️ Failed to send campaign %s to %s: %v", campaign.ID, recipient.CustomerPhone, err)
+		recipient.Status = models.CampaignRecipientFailed
+		recipient.Error = err.Error()
+		campaign.FailedCount++
+	} else {
+		recipient.Status = models.CampaignRecipientSent
+		recipient.MessageID = messageID
+		recipient.AckMessageID = ackMessageID(messageID)
+		recipient.SentAt = &now
+		campaign.SentCount++
+	}
+	if err := s.repo.UpdateRecipient(recipient); err != nil {
+		log.Printf("⚠️ Failed to update campaign recipient %s: %v", recipient.ID, err)
+	}
+}
+
+// deliver sends through WhatsApp, or captures the message in sandbox mode (no message ID, so no acks)
+func (s *CampaignService) deliver(clientID, to, message string) (string, error) {
+	if s.sandboxSvc != nil && s.sandboxSvc.IsSandbox(clientID) {
+		return "", s.sandboxSvc.SendMessage(clientID, to, message)
+	}
+	if s.sessions != nil {
+		return s.sessions.SendMessage(clientID, to, message)
+	}
+	return s.whatsappSvc.SendMessageWithID(to, message)
+}
+
+// audience resolves the customers a campaign goes to
+func (s *CampaignService) audience(campaign *models.Campaign) ([]string, error) {
+	switch campaign.Audience {
+	case models.CampaignAudienceRecentBuyers:
+		return s.repo.AudienceRecentBuyers(campaign.ClientID, time.Now().AddDate(0, 0, -campaign.RecentDays))
+	case models.CampaignAudienceTags:
+		var tags []string
+		if err := json.Unmarshal(campaign.AudienceTags, &tags); err != nil {
+			return nil, fmt.Errorf("invalid audience tags: %w", err)
+		}
+		return s.tagService.AudiencePhones(campaign.ClientID, tags, campaign.MatchAllTags)
+	case models.CampaignAudienceSegment:
+		if campaign.SegmentID == nil {
+			return nil, errors.New("the audience segment was deleted")
+		}
+		if s.segmentSvc == nil {
+			return nil, errors.New("customer segments are not available")
+		}
+		return s.segmentSvc.Phones(campaign.ClientID, *campaign.SegmentID)
+	default:
+		return s.repo.AudienceAll(campaign.ClientID)
+	}
+}
+
+// finish records the outcome of a campaign, unless it was cancelled meanwhile
+func (s *CampaignService) finish(campaign *models.Campaign, status, reason string) {
+	now := time.Now()
+	campaign.Status = status
+	campaign.Error = reason
+	campaign.CompletedAt = &now
+	if reason != "" {
+		log.Printf("❌ Campaign %s %s: %s", campaign.ID, status, reason)
+	}
+	if err := s.repo.Finish(campaign); err != nil {
+		log.Printf("⚠️ Failed to save campaign %s: %v", campaign.ID, err)
+	}
+}
+
+// saveProgress stores the recipient counts of a campaign being sent
+func (s *CampaignService) saveProgress(campaign *models.Campaign) {
+	if err := s.repo.SaveProgress(campaign); err != nil {
+		log.Printf("⚠️ Failed to save progress of campaign %s: %v", campaign.ID, err)
+	}
+}
+
+func (s *CampaignService) get(clientID, id uuid.UUID) (*models.Campaign, error) {
+	campaign, err := s.repo.GetByID(clientID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCampaignNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load campaign: %w", err)
+	}
+	return campaign, nil
+}
+
+// applyRequest validates a campaign request and copies it onto the campaign
+func (s *CampaignService) applyRequest(campaign *models.Campaign, req *models.CampaignRequest) error {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return errors.New("name is required")
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		return errors.New("message is required")
+	}
+	if len([]rune(message)) > maxCampaignMessage {
+		return fmt.Errorf("message is longer than %d characters", maxCampaignMessage)
+	}
+
+	audience := req.Audience
+	if audience == "" {
+		audience = models.CampaignAudienceAll
+	}
+	tags := []string{}
+	var segmentID *uuid.UUID
+	switch audience {
+	case models.CampaignAudienceAll:
+	case models.CampaignAudienceRecentBuyers:
+		if req.RecentDays < 0 || req.RecentDays > maxCampaignDays {
+			return fmt.Errorf("recent_days must be between 1 and %d", maxCampaignDays)
+		}
+	case models.CampaignAudienceTags:
+		for _, tag := range req.AudienceTags {
+			if name := NormalizeTagName(tag); name != "" {
+				tags = append(tags, name)
+			}
+		}
+		if len(tags) == 0 {
+			return errors.New("audience_tags is required for the tags audience")
+		}
+		if err := s.tagService.CheckDefined(campaign.ClientID, tags); err != nil {
+			return err
+		}
+	case models.CampaignAudienceSegment:
+		if req.SegmentID == nil {
+			return errors.New("segment_id is required for the segment audience")
+		}
+		if s.segmentSvc == nil {
+			return errors.New("customer segments are not available")
+		}
+		if _, err := s.segmentSvc.Get(campaign.ClientID, *req.SegmentID); err != nil {
+			return err
+		}
+		segmentID = req.SegmentID
+	default:
+		return fmt.Errorf("invalid audience %q (use all, recent_buyers, tags or segment)", audience)
+	}
+
+	throttle := req.ThrottleSeconds
+	if throttle == 0 {
+		throttle = defaultCampaignThrottle
+	}
+	if throttle < 1 || throttle > maxCampaignThrottle {
+		return fmt.Errorf("throttle_seconds must be between 1 and %d", maxCampaignThrottle)
+	}
+	days := req.RecentDays
+	if days == 0 {
+		days = defaultCampaignDays
+	}
+
+	tagsJSON, _ := json.Marshal(tags)
+	campaign.Name = name
+	campaign.Message = message
+	campaign.Audience = audience
+	campaign.AudienceTags = datatypes.JSON(tagsJSON)
+	campaign.MatchAllTags = req.MatchAllTags
+	campaign.SegmentID = segmentID
+	campaign.RecentDays = days
+	campaign.ThrottleSeconds = throttle
+	campaign.ScheduledAt = req.ScheduledAt
+	if req.ScheduledAt != nil {
+		campaign.Status = models.CampaignStatusScheduled
+	} else {
+		campaign.Status = models.CampaignStatusDraft
+	}
+	return nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
@@ -186,6 +187,22 @@ func (s *CartService) ClearCart(clientID, customerPhone string) error {
 	return nil
 }
 
+// SetBranch sets the branch the active cart will be checked out from
+func (s *CartService) SetBranch(clientID, customerPhone string, branchID uuid.UUID) (*models.Cart, error) {
+	cart, err := s.cartRepo.GetActiveCart(clientID, customerPhone)
+	if err != nil {
+		return nil, errors.New("cart not found")
+	}
+
+	cart.BranchID = &branchID
+	if err := s.cartRepo.Update(cart); err != nil {
+		return nil, fmt.Errorf("failed to update cart: %w", err)
+	}
+
+	log.Printf("🏪 Cart for %s assigned to branch %s", customerPhone, branchID)
+	return cart, nil
+}
+
 // CheckoutCart converts the cart to an order
 func (s *CartService) CheckoutCart(clientID, customerPhone string) (*models.Order, error) {
 	cart, err := s.cartRepo.GetActiveCart(clientID, customerPhone)
@@ -228,6 +245,7 @@ func (s *CartService) CheckoutCart(clientID, customerPhone string) (*models.Orde
 		TotalAmount:       cart.TotalAmount,
 		PaymentStatus:     "pending",
 		FulfillmentStatus: "pending",
+		BranchID:          cart.BranchID,
 	}
 
 	if err := s.orderRepo.Create(order); err != nil {
@@ -0,0 +1,860 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// ConfigBundleVersion is the bundle format produced by Export; Import rejects newer ones
+const ConfigBundleVersion = 1
+
+// Import conflict modes, deciding what happens to items that exist on both sides (matched by name or title)
+const (
+	ConfigImportSkip      = "skip"      // Keep the target's items and settings, only add what it lacks
+	ConfigImportOverwrite = "overwrite" // Bundle items and settings win, the target's other items stay
+	ConfigImportReplace   = "replace"   // The target ends up exactly like the bundle
+)
+
+// Bundle sections, importable separately
+const (
+	ConfigSectionProfile       = "profile"
+	ConfigSectionKnowledgeBase = "knowledge_base"
+	ConfigSectionWorkflows     = "workflows"
+	ConfigSectionTags          = "conversation_tags"
+	ConfigSectionSettings      = "settings"
+	ConfigSectionCustomFields  = "custom_fields"
+)
+
+var configSections = []string{ConfigSectionProfile, ConfigSectionKnowledgeBase, ConfigSectionWorkflows, ConfigSectionTags, ConfigSectionSettings, ConfigSectionCustomFields}
+
+// sensitiveHeaderHints mark call_api headers whose values are credentials and never leave the tenant
+var sensitiveHeaderHints = []string{"authorization", "token", "secret", "key", "signature", "cookie", "password"}
+
+var ErrClientNotFound = errors.New("client not found")
+
+// ConfigBundle is a portable copy of a tenant's bot setup. It carries no secrets or tenant-bound IDs.
+type ConfigBundle struct {
+	Version          int                                   `json:"version"`
+	ExportedAt       time.Time                             `json:"exported_at"`
+	SourceClientID   string                                `json:"source_client_id"`
+	SourceBusiness   string                                `json:"source_business_name"`
+	Profile          *ConfigProfile                        `json:"profile,omitempty"`
+	KnowledgeBase    map[string][]KBImportItem             `json:"knowledge_base,omitempty"` // Entries by type (faq, product, ...)
+	Workflows        []ConfigWorkflow                      `json:"workflows,omitempty"`
+	ConversationTags []models.ConversationTagRequest       `json:"conversation_tags,omitempty"`
+	Settings         *ConfigSettings                       `json:"settings,omitempty"`
+	CustomFields     []models.CustomFieldDefinitionRequest `json:"custom_fields,omitempty"`
+	Redacted         []string                              `json:"redacted,omitempty"` // Values removed on export that must be filled in after import
+}
+
+// ConfigProfile is the bot persona of a tenant
+type ConfigProfile struct {
+	Tone     string `json:"tone"`
+	Timezone string `json:"timezone"`
+}
+
+// ConfigWorkflow is a workflow without its tenant-bound IDs
+type ConfigWorkflow struct {
+	Name          string                 `json:"name"`
+	Description   string                 `json:"description"`
+	TriggerType   string                 `json:"trigger_type"`
+	TriggerConfig workflow.TriggerConfig `json:"trigger_config"`
+	Conditions    []workflow.Condition   `json:"conditions"`
+	Actions       []workflow.Action      `json:"actions"`
+	IsActive      bool                   `json:"is_active"`
+}
+
+// ConfigCODSettings are the cash-on-delivery rules of a tenant
+type ConfigCODSettings struct {
+	Enabled         bool    `json:"enabled"`
+	MaxOrderAmount  float64 `json:"max_order_amount"`
+	MinPaidOrders   int     `json:"min_paid_orders"`
+	MaxOpenOrders   int     `json:"max_open_orders"`
+	MaxFailedOrders int     `json:"max_failed_orders"`
+}
+
+// ConfigSettings holds the tenant's per-feature settings, each optional on import
+type ConfigSettings struct {
+	Language       *models.UpdateLanguageSettingsRequest `json:"language,omitempty"`
+	Reactions      *models.UpdateReactionSettingsRequest `json:"reactions,omitempty"`
+	OnboardingFlow *models.UpdateOnboardingFlowRequest   `json:"onboarding_flow,omitempty"`
+	COD            *ConfigCODSettings                    `json:"cod,omitempty"`
+	PaymentRouting *models.UpdatePaymentRoutingRequest   `json:"payment_routing,omitempty"`
+	SLA            *models.UpdateSLASettingsRequest      `json:"sla,omitempty"`
+	OCRRetention   *models.UpdateOCRRetentionRequest     `json:"ocr_retention,omitempty"`
+	Latency        *models.UpdateLatencySettingsRequest  `json:"latency,omitempty"`
+}
+
+// ConfigImportRequest applies a bundle to a tenant
+type ConfigImportRequest struct {
+	Bundle   ConfigBundle `json:"bundle"`
+	Mode     string       `json:"mode" example:"skip"`                                   // skip (default), overwrite or replace
+	Sections []string     `json:"sections,omitempty" example:"knowledge_base,workflows"` // Sections to import, all when empty
+	DryRun   bool         `json:"dry_run"`
+}
+
+// ConfigItemDiff lists what an import does to named items
+type ConfigItemDiff struct {
+	Added   []string `json:"added"`
+	Updated []string `json:"updated"`
+	Skipped []string `json:"skipped"` // Present on both sides and kept as they are
+	Removed []string `json:"removed"`
+}
+
+// ConfigImportResult reports what an import changed (or would change, on a dry run)
+type ConfigImportResult struct {
+	DryRun           bool            `json:"dry_run"`
+	Mode             string          `json:"mode"`
+	Profile          bool            `json:"profile"` // Tone and timezone applied
+	KnowledgeBase    []*KBImportDiff `json:"knowledge_base"`
+	Workflows        *ConfigItemDiff `json:"workflows,omitempty"`
+	ConversationTags *ConfigItemDiff `json:"conversation_tags,omitempty"`
+	CustomFields     *ConfigItemDiff `json:"custom_fields,omitempty"` // Matched by entity.key
+	Settings         []string        `json:"settings"`                // Settings applied
+	Redacted         []string        `json:"redacted,omitempty"`
+}
+
+// ConfigBundleService exports a tenant's bot setup and applies it to another tenant
+type ConfigBundleService struct {
+	clientRepo          repositories.ClientRepo
+	kbRepo              repositories.KBRepo
+	kbBulkService       *KBBulkService
+	workflowService     *WorkflowService
+	tagService          *ConversationTagService
+	languageService     *LanguageService
+	reactionService     *ReactionService
+	onboardingService   *CustomerOnboardingService
+	orderService        *OrderService
+	slaService          *SLAService
+	ocrRetentionService *OCRRetentionService
+	latencyService      *LatencyService
+	customFieldService  *CustomFieldService
+}
+
+// NewConfigBundleService creates a new config bundle service
+func NewConfigBundleService(
+	clientRepo repositories.ClientRepo,
+	kbRepo repositories.KBRepo,
+	kbBulkService *KBBulkService,
+	workflowService *WorkflowService,
+	tagService *ConversationTagService,
+	languageService *LanguageService,
+	reactionService *ReactionService,
+	onboardingService *CustomerOnboardingService,
+	orderService *OrderService,
+	slaService *SLAService,
+	ocrRetentionService *OCRRetentionService,
+	latencyService *LatencyService,
+	customFieldService *CustomFieldService,
+) *ConfigBundleService {
+	return &ConfigBundleService{
+		clientRepo:          clientRepo,
+		kbRepo:              kbRepo,
+		kbBulkService:       kbBulkService,
+		workflowService:     workflowService,
+		tagService:          tagService,
+		languageService:     languageService,
+		reactionService:     reactionService,
+		onboardingService:   onboardingService,
+		orderService:        orderService,
+		slaService:          slaService,
+		ocrRetentionService: ocrRetentionService,
+		latencyService:      latencyService,
+		customFieldService:  customFieldService,
+	}
+}
+
+// Export builds the config bundle of a client
+func (s *ConfigBundleService) Export(clientID string) (*ConfigBundle, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	bundle := &ConfigBundle{
+		Version:        ConfigBundleVersion,
+		ExportedAt:     time.Now(),
+		SourceClientID: client.ID.String(),
+		SourceBusiness: client.BusinessName,
+		Profile:        &ConfigProfile{Tone: client.Tone, Timezone: client.Timezone},
+		KnowledgeBase:  map[string][]KBImportItem{},
+	}
+
+	entries, err := s.kbRepo.ListEntries(clientID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge base: %w", err)
+	}
+	for _, entry := range entries {
+		bundle.KnowledgeBase[entry.Type] = append(bundle.KnowledgeBase[entry.Type], kbEntryItem(&entry))
+	}
+
+	workflows, err := s.workflowService.ListWorkflows(client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	for _, wf := range workflows {
+		item, err := exportWorkflow(&wf, &bundle.Redacted)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Workflows = append(bundle.Workflows, *item)
+	}
+
+	tags, err := s.tagService.ListTags(client.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range tags {
+		req := models.ConversationTagRequest{Name: tag.Name, Color: tag.Color, Description: tag.Description}
+		_ = json.Unmarshal(tag.Keywords, &req.Keywords)
+		bundle.ConversationTags = append(bundle.ConversationTags, req)
+	}
+
+	if bundle.Settings, err = s.exportSettings(client); err != nil {
+		return nil, err
+	}
+
+	fields, err := s.customFieldService.ListDefinitions(client.ID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	for _, field := range fields {
+		bundle.CustomFields = append(bundle.CustomFields, customFieldRequest(&field))
+	}
+
+	log.Printf("📤 Config exported for client %s: %d KB entries, %d workflows, %d tags",
+		clientID, len(entries), len(bundle.Workflows), len(bundle.ConversationTags))
+	return bundle, nil
+}
+
+// exportSettings collects the per-feature settings of a client
+func (s *ConfigBundleService) exportSettings(client *models.Client) (*ConfigSettings, error) {
+	clientID := client.ID.String()
+	settings := &ConfigSettings{}
+
+	language, err := s.languageService.GetSettings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Language = &models.UpdateLanguageSettingsRequest{
+		Enabled:         language.Enabled,
+		DefaultLanguage: language.DefaultLanguage,
+		PostProcess:     language.PostProcess,
+	}
+	_ = json.Unmarshal(language.AllowedLanguages, &settings.Language.AllowedLanguages)
+
+	reactions, err := s.reactionService.GetSettings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.Reactions = &models.UpdateReactionSettingsRequest{Enabled: reactions.Enabled}
+	_ = json.Unmarshal(reactions.Intents, &settings.Reactions.Intents)
+
+	flow, err := s.onboardingService.GetFlow(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.OnboardingFlow = &models.UpdateOnboardingFlowRequest{
+		Enabled:         flow.Enabled,
+		WelcomeMessage:  flow.WelcomeMessage,
+		ConsentRequired: flow.ConsentRequired,
+		ConsentText:     flow.ConsentText,
+	}
+	_ = json.Unmarshal(flow.QuickMenu, &settings.OnboardingFlow.QuickMenu)
+	_ = json.Unmarshal(flow.Languages, &settings.OnboardingFlow.Languages)
+
+	cod, err := s.orderService.GetCODSettings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.COD = &ConfigCODSettings{
+		Enabled:         cod.Enabled,
+		MaxOrderAmount:  cod.MaxOrderAmount,
+		MinPaidOrders:   cod.MinPaidOrders,
+		MaxOpenOrders:   cod.MaxOpenOrders,
+		MaxFailedOrders: cod.MaxFailedOrders,
+	}
+
+	routing, err := s.orderService.GetPaymentRouting(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.PaymentRouting = &models.UpdatePaymentRoutingRequest{
+		Enabled:        routing.Enabled,
+		DefaultGateway: routing.DefaultGateway,
+	}
+	_ = json.Unmarshal(routing.Rules, &settings.PaymentRouting.Rules)
+
+	sla := s.slaService.GetSettings(client.ID)
+	settings.SLA = &models.UpdateSLASettingsRequest{
+		FirstResponseTargetSeconds: sla.FirstResponseTargetSeconds,
+		ResolutionTargetSeconds:    sla.ResolutionTargetSeconds,
+		IdleResolveMinutes:         sla.IdleResolveMinutes,
+	}
+
+	ocr, err := s.ocrRetentionService.GetSettings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	settings.OCRRetention = &models.UpdateOCRRetentionRequest{RetentionDays: &ocr.RetentionDays, Anonymize: &ocr.Anonymize}
+
+	latency := s.latencyService.Settings(clientID)
+	settings.Latency = &models.UpdateLatencySettingsRequest{
+		Enabled:             latency.Enabled,
+		InterimAfterSeconds: latency.InterimAfterSeconds,
+		InterimMessage:      latency.InterimMessage,
+		HardTimeoutSeconds:  latency.HardTimeoutSeconds,
+		Fallback:            latency.Fallback,
+		HandoverMessage:     latency.HandoverMessage,
+		HandoverTag:         latency.HandoverTag,
+	}
+
+	return settings, nil
+}
+
+// kbEntryItem converts a knowledge base entry to its import form
+func kbEntryItem(entry *models.KnowledgeBaseEntry) KBImportItem {
+	item := KBImportItem{Title: entry.Title, Tags: []string(entry.Tags)}
+	_ = json.Unmarshal(entry.Content, &item.Content)
+	if !entry.IsActive {
+		inactive := false
+		item.Active = &inactive
+	}
+	return item
+}
+
+// exportWorkflow strips a workflow of the WhatsApp session and credentials it is bound to
+func exportWorkflow(wf *models.Workflow, redacted *[]string) (*ConfigWorkflow, error) {
+	item := &ConfigWorkflow{
+		Name:        wf.Name,
+		Description: wf.Description,
+		TriggerType: wf.TriggerType,
+		IsActive:    wf.IsActive,
+	}
+	if err := json.Unmarshal(wf.TriggerConfig, &item.TriggerConfig); err != nil {
+		return nil, fmt.Errorf("workflow %q: invalid trigger config: %w", wf.Name, err)
+	}
+	if len(wf.Conditions) > 0 {
+		if err := json.Unmarshal(wf.Conditions, &item.Conditions); err != nil {
+			return nil, fmt.Errorf("workflow %q: invalid conditions: %w", wf.Name, err)
+		}
+	}
+	if err := json.Unmarshal(wf.Actions, &item.Actions); err != nil {
+		return nil, fmt.Errorf("workflow %q: invalid actions: %w", wf.Name, err)
+	}
+
+	for i, action := range item.Actions {
+		// The target sends from its own session
+		delete(action.Config, "session_id")
+
+		headers, ok := action.Config["headers"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for name := range headers {
+			lower := strings.ToLower(name)
+			if slices.ContainsFunc(sensitiveHeaderHints, func(hint string) bool { return strings.Contains(lower, hint) }) {
+				headers[name] = ""
+				*redacted = append(*redacted, fmt.Sprintf("workflows[%s].actions[%d].headers.%s", wf.Name, i, name))
+			}
+		}
+	}
+	return item, nil
+}
+
+// Import applies a bundle to a client. Everything is validated before anything is written.
+func (s *ConfigBundleService) Import(ctx context.Context, clientID string, req *ConfigImportRequest) (*ConfigImportResult, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	bundle := &req.Bundle
+	if bundle.Version == 0 || bundle.Version > ConfigBundleVersion {
+		return nil, fmt.Errorf("unsupported bundle version %d", bundle.Version)
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = ConfigImportSkip
+	}
+	if mode != ConfigImportSkip && mode != ConfigImportOverwrite && mode != ConfigImportReplace {
+		return nil, fmt.Errorf("mode must be %s, %s or %s", ConfigImportSkip, ConfigImportOverwrite, ConfigImportReplace)
+	}
+	for _, section := range req.Sections {
+		if !slices.Contains(configSections, section) {
+			return nil, fmt.Errorf("unknown section %q (available: %s)", section, strings.Join(configSections, ", "))
+		}
+	}
+	include := func(section string) bool {
+		return len(req.Sections) == 0 || slices.Contains(req.Sections, section)
+	}
+
+	if err := validateBundle(client.ID, bundle); err != nil {
+		return nil, err
+	}
+
+	result := &ConfigImportResult{
+		DryRun:        req.DryRun,
+		Mode:          mode,
+		KnowledgeBase: []*KBImportDiff{},
+		Settings:      []string{},
+		Redacted:      bundle.Redacted,
+	}
+
+	// Settings and the persona belong to the target in skip mode
+	if include(ConfigSectionProfile) && bundle.Profile != nil && mode != ConfigImportSkip {
+		if !req.DryRun {
+			client.Tone = bundle.Profile.Tone
+			client.Timezone = bundle.Profile.Timezone
+			if err := s.clientRepo.Update(client); err != nil {
+				return nil, fmt.Errorf("failed to update profile: %w", err)
+			}
+		}
+		result.Profile = true
+	}
+
+	if include(ConfigSectionKnowledgeBase) {
+		if err := s.importKnowledgeBase(ctx, clientID, bundle, mode, req.DryRun, result); err != nil {
+			return nil, err
+		}
+	}
+	if include(ConfigSectionWorkflows) {
+		if result.Workflows, err = s.importWorkflows(client.ID, bundle, mode, req.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	if include(ConfigSectionTags) {
+		if result.ConversationTags, err = s.importTags(client.ID, bundle, mode, req.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	if include(ConfigSectionCustomFields) {
+		if result.CustomFields, err = s.importCustomFields(client.ID, bundle, mode, req.DryRun); err != nil {
+			return nil, err
+		}
+	}
+	if include(ConfigSectionSettings) && bundle.Settings != nil && mode != ConfigImportSkip {
+		if result.Settings, err = s.importSettings(client, bundle.Settings, req.DryRun); err != nil {
+			return nil, err
+		}
+	}
+
+	if !req.DryRun {
+		log.Printf("📥 Config bundle from client %s imported into client %s (mode: %s)", bundle.SourceClientID, clientID, mode)
+	}
+	return result, nil
+}
+
+// validateBundle checks KB items, workflows and tags up front so a bad bundle changes nothing
+func validateBundle(clientID uuid.UUID, bundle *ConfigBundle) error {
+	for entryType, items := range bundle.KnowledgeBase {
+		if _, err := buildImportEntries(clientID, &KBImportRequest{Type: entryType, Items: items}); err != nil {
+			return fmt.Errorf("knowledge_base %s: %w", entryType, err)
+		}
+	}
+
+	names := make(map[string]bool, len(bundle.Workflows))
+	for _, wf := range bundle.Workflows {
+		key := kbTitleKey(wf.Name)
+		if key == "" {
+			return errors.New("workflows: name is required")
+		}
+		if names[key] {
+			return fmt.Errorf("workflows: duplicate name %q", wf.Name)
+		}
+		names[key] = true
+		if len(wf.Actions) == 0 {
+			return fmt.Errorf("workflow %q: at least one action is required", wf.Name)
+		}
+		if err := workflow.ValidateActions(wf.Actions); err != nil {
+			return fmt.Errorf("workflow %q: invalid actions: %w", wf.Name, err)
+		}
+		if err := workflow.ValidateTriggerConfig(wf.TriggerType, wf.TriggerConfig); err != nil {
+			return fmt.Errorf("workflow %q: invalid trigger config: %w", wf.Name, err)
+		}
+	}
+
+	for i := range bundle.ConversationTags {
+		if err := applyTagRequest(&models.ConversationTag{}, &bundle.ConversationTags[i]); err != nil {
+			return fmt.Errorf("conversation tag %q: %w", bundle.ConversationTags[i].Name, err)
+		}
+	}
+
+	fields := make(map[string]bool, len(bundle.CustomFields))
+	for i := range bundle.CustomFields {
+		field := &models.CustomFieldDefinition{}
+		if err := applyCustomFieldRequest(field, &bundle.CustomFields[i]); err != nil {
+			return fmt.Errorf("custom field %s.%s: %w", bundle.CustomFields[i].Entity, bundle.CustomFields[i].Key, err)
+		}
+		name := field.Entity + "." + field.Key
+		if fields[name] {
+			return fmt.Errorf("custom fields: duplicate %s", name)
+		}
+		fields[name] = true
+	}
+	return nil
+}
+
+// importKnowledgeBase merges the bundle's entries per type into the client's and re-imports them,
+// so the KB re-import keeps the vector index in sync
+func (s *ConfigBundleService) importKnowledgeBase(ctx context.Context, clientID string, bundle *ConfigBundle, mode string, dryRun bool, result *ConfigImportResult) error {
+	existing, err := s.kbRepo.ListEntries(clientID, "")
+	if err != nil {
+		return fmt.Errorf("failed to list knowledge base: %w", err)
+	}
+	current := make(map[string][]KBImportItem)
+	for _, entry := range existing {
+		current[entry.Type] = append(current[entry.Type], kbEntryItem(&entry))
+	}
+
+	types := make([]string, 0, len(bundle.KnowledgeBase))
+	for entryType := range bundle.KnowledgeBase {
+		types = append(types, entryType)
+	}
+	if mode == ConfigImportReplace {
+		// Types missing from the bundle are emptied
+		for entryType := range current {
+			if _, ok := bundle.KnowledgeBase[entryType]; !ok {
+				types = append(types, entryType)
+			}
+		}
+	}
+	slices.Sort(types)
+
+	for _, entryType := range types {
+		items := bundle.KnowledgeBase[entryType]
+		if mode != ConfigImportReplace {
+			items = mergeKBItems(current[entryType], items, mode == ConfigImportOverwrite)
+		}
+		if items == nil {
+			items = []KBImportItem{}
+		}
+
+		diff, err := s.kbBulkService.Import(ctx, &KBImportRequest{ClientID: clientID, Type: entryType, Items: items}, dryRun)
+		if err != nil {
+			return fmt.Errorf("knowledge_base %s: %w", entryType, err)
+		}
+		result.KnowledgeBase = append(result.KnowledgeBase, diff)
+	}
+	return nil
+}
+
+// mergeKBItems combines the client's entries with the bundle's, matched by title.
+// With overwrite the bundle's version of a shared title wins, otherwise the client's is kept.
+func mergeKBItems(current, incoming []KBImportItem, overwrite bool) []KBImportItem {
+	merged := make([]KBImportItem, 0, len(current)+len(incoming))
+	index := make(map[string]int, len(current))
+	for _, item := range current {
+		key := kbTitleKey(item.Title)
+		if _, dup := index[key]; dup {
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	for _, item := range incoming {
+		key := kbTitleKey(item.Title)
+		if i, ok := index[key]; ok {
+			if overwrite {
+				merged[i] = item
+			}
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, item)
+	}
+	return merged
+}
+
+// importWorkflows creates, updates and (in replace mode) deletes workflows, matched by name
+func (s *ConfigBundleService) importWorkflows(clientID uuid.UUID, bundle *ConfigBundle, mode string, dryRun bool) (*ConfigItemDiff, error) {
+	existing, err := s.workflowService.ListWorkflows(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+	byName := make(map[string]models.Workflow, len(existing))
+	for _, wf := range existing {
+		if _, dup := byName[kbTitleKey(wf.Name)]; !dup {
+			byName[kbTitleKey(wf.Name)] = wf
+		}
+	}
+	matched := make(map[uuid.UUID]bool, len(bundle.Workflows))
+
+	diff := newConfigItemDiff()
+	for _, item := range bundle.Workflows {
+		isActive := item.IsActive
+		current, ok := byName[kbTitleKey(item.Name)]
+		if ok {
+			matched[current.ID] = true
+		}
+
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, item.Name)
+			if dryRun {
+				continue
+			}
+			if _, err := s.workflowService.CreateWorkflow(clientID, workflow.CreateWorkflowRequest{
+				Name:          item.Name,
+				Description:   item.Description,
+				TriggerType:   item.TriggerType,
+				TriggerConfig: item.TriggerConfig,
+				Conditions:    item.Conditions,
+				Actions:       item.Actions,
+				IsActive:      &isActive,
+			}); err != nil {
+				return nil, fmt.Errorf("workflow %q: %w", item.Name, err)
+			}
+		case mode == ConfigImportSkip:
+			diff.Skipped = append(diff.Skipped, item.Name)
+		default:
+			diff.Updated = append(diff.Updated, item.Name)
+			if dryRun {
+				continue
+			}
+			conditions := item.Conditions
+			if conditions == nil {
+				conditions = []workflow.Condition{}
+			}
+			if _, err := s.workflowService.UpdateWorkflow(current.ID, workflow.UpdateWorkflowRequest{
+				Name:          &item.Name,
+				Description:   &item.Description,
+				TriggerType:   &item.TriggerType,
+				TriggerConfig: &item.TriggerConfig,
+				Conditions:    conditions,
+				Actions:       item.Actions,
+				IsActive:      &isActive,
+			}); err != nil {
+				return nil, fmt.Errorf("workflow %q: %w", item.Name, err)
+			}
+		}
+	}
+
+	if mode == ConfigImportReplace {
+		for _, wf := range existing {
+			if matched[wf.ID] {
+				continue
+			}
+			diff.Removed = append(diff.Removed, wf.Name)
+			if dryRun {
+				continue
+			}
+			if err := s.workflowService.DeleteWorkflow(wf.ID); err != nil {
+				return nil, fmt.Errorf("workflow %q: %w", wf.Name, err)
+			}
+		}
+	}
+	return diff, nil
+}
+
+// importTags creates, updates and (in replace mode) deletes tag definitions, matched by name
+func (s *ConfigBundleService) importTags(clientID uuid.UUID, bundle *ConfigBundle, mode string, dryRun bool) (*ConfigItemDiff, error) {
+	existing, err := s.tagService.ListTags(clientID)
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]models.ConversationTag, len(existing))
+	for _, tag := range existing {
+		byName[tag.Name] = tag
+	}
+
+	diff := newConfigItemDiff()
+	for i := range bundle.ConversationTags {
+		req := &bundle.ConversationTags[i]
+		name := NormalizeTagName(req.Name)
+		current, ok := byName[name]
+		delete(byName, name)
+
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+			if !dryRun {
+				if _, err := s.tagService.CreateTag(clientID, req); err != nil {
+					return nil, fmt.Errorf("conversation tag %q: %w", name, err)
+				}
+			}
+		case mode == ConfigImportSkip:
+			diff.Skipped = append(diff.Skipped, name)
+		default:
+			diff.Updated = append(diff.Updated, name)
+			if !dryRun {
+				if _, err := s.tagService.UpdateTag(clientID, current.ID, req); err != nil {
+					return nil, fmt.Errorf("conversation tag %q: %w", name, err)
+				}
+			}
+		}
+	}
+
+	if mode == ConfigImportReplace {
+		for _, tag := range existing {
+			if _, ok := byName[tag.Name]; !ok {
+				continue
+			}
+			diff.Removed = append(diff.Removed, tag.Name)
+			if !dryRun {
+				if err := s.tagService.DeleteTag(clientID, tag.ID); err != nil {
+					return nil, fmt.Errorf("conversation tag %q: %w", tag.Name, err)
+				}
+			}
+		}
+	}
+	return diff, nil
+}
+
+// importCustomFields adds the bundle's custom field definitions, matched by entity and key
+func (s *ConfigBundleService) importCustomFields(clientID uuid.UUID, bundle *ConfigBundle, mode string, dryRun bool) (*ConfigItemDiff, error) {
+	existing, err := s.customFieldService.ListDefinitions(clientID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	byName := make(map[string]models.CustomFieldDefinition, len(existing))
+	for _, field := range existing {
+		byName[field.Entity+"."+field.Key] = field
+	}
+
+	diff := newConfigItemDiff()
+	for i := range bundle.CustomFields {
+		req := bundle.CustomFields[i]
+		req.Entity = strings.ToLower(strings.TrimSpace(req.Entity))
+		req.Key = strings.TrimSpace(req.Key)
+		name := req.Entity + "." + req.Key
+		current, ok := byName[name]
+		delete(byName, name)
+
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, name)
+			if !dryRun {
+				if _, err := s.customFieldService.CreateDefinition(clientID, &req); err != nil {
+					return nil, fmt.Errorf("custom field %s: %w", name, err)
+				}
+			}
+		case mode == ConfigImportSkip:
+			diff.Skipped = append(diff.Skipped, name)
+		default:
+			diff.Updated = append(diff.Updated, name)
+			if !dryRun {
+				if _, err := s.customFieldService.UpdateDefinition(clientID, current.ID, &req); err != nil {
+					return nil, fmt.Errorf("custom field %s: %w", name, err)
+				}
+			}
+		}
+	}
+
+	if mode == ConfigImportReplace {
+		for _, field := range existing {
+			name := field.Entity + "." + field.Key
+			if _, ok := byName[name]; !ok {
+				continue
+			}
+			diff.Removed = append(diff.Removed, name)
+			if !dryRun {
+				if err := s.customFieldService.DeleteDefinition(clientID, field.ID); err != nil {
+					return nil, fmt.Errorf("custom field %s: %w", name, err)
+				}
+			}
+		}
+	}
+	return diff, nil
+}
+
+// customFieldRequest converts a custom field definition to its import form
+func customFieldRequest(field *models.CustomFieldDefinition) models.CustomFieldDefinitionRequest {
+	return models.CustomFieldDefinitionRequest{
+		Entity:   field.Entity,
+		Key:      field.Key,
+		Label:    field.Label,
+		Type:     field.Type,
+		Required: field.Required,
+		Options:  []string(field.Options),
+		Pattern:  field.Pattern,
+		Min:      field.MinValue,
+		Max:      field.MaxValue,
+		Position: field.Position,
+	}
+}
+
+// importSettings saves each settings group present in the bundle through its own service, which validates it
+func (s *ConfigBundleService) importSettings(client *models.Client, settings *ConfigSettings, dryRun bool) ([]string, error) {
+	clientID := client.ID.String()
+	applied := []string{}
+	apply := func(name string, present bool, save func() error) error {
+		if !present {
+			return nil
+		}
+		if !dryRun {
+			if err := save(); err != nil {
+				return fmt.Errorf("settings %s: %w", name, err)
+			}
+		}
+		applied = append(applied, name)
+		return nil
+	}
+
+	steps := []struct {
+		name    string
+		present bool
+		save    func() error
+	}{
+		{"language", settings.Language != nil, func() error {
+			_, err := s.languageService.UpdateSettings(clientID, settings.Language)
+			return err
+		}},
+		{"reactions", settings.Reactions != nil, func() error {
+			_, err := s.reactionService.UpdateSettings(clientID, settings.Reactions)
+			return err
+		}},
+		{"onboarding_flow", settings.OnboardingFlow != nil, func() error {
+			_, err := s.onboardingService.UpdateFlow(clientID, settings.OnboardingFlow)
+			return err
+		}},
+		{"cod", settings.COD != nil, func() error {
+			_, err := s.orderService.UpdateCODSettings(clientID, &models.CODSettings{
+				Enabled:         settings.COD.Enabled,
+				MaxOrderAmount:  settings.COD.MaxOrderAmount,
+				MinPaidOrders:   settings.COD.MinPaidOrders,
+				MaxOpenOrders:   settings.COD.MaxOpenOrders,
+				MaxFailedOrders: settings.COD.MaxFailedOrders,
+			})
+			return err
+		}},
+		{"payment_routing", settings.PaymentRouting != nil, func() error {
+			_, err := s.orderService.UpdatePaymentRouting(clientID, settings.PaymentRouting)
+			return err
+		}},
+		{"sla", settings.SLA != nil, func() error {
+			_, err := s.slaService.UpdateSettings(client.ID, settings.SLA)
+			return err
+		}},
+		{"ocr_retention", settings.OCRRetention != nil, func() error {
+			_, err := s.ocrRetentionService.UpdateSettings(clientID, settings.OCRRetention)
+			return err
+		}},
+		{"latency", settings.Latency != nil, func() error {
+			_, err := s.latencyService.UpdateSettings(clientID, settings.Latency)
+			return err
+		}},
+	}
+	for _, step := range steps {
+		if err := apply(step.name, step.present, step.save); err != nil {
+			return applied, err
+		}
+	}
+	return applied, nil
+}
+
+func newConfigItemDiff() *ConfigItemDiff {
+	return &ConfigItemDiff{Added: []string{}, Updated: []string{}, Skipped: []string{}, Removed: []string{}}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// ConversationMemory loads a customer's earlier exchanges as LLM chat history, so the bot can
+// follow multi-turn conversations ("yang merah saja", "jadi totalnya berapa?")
+type ConversationMemory struct {
+	conversationRepo repositories.ConversationRepo
+	maxTurns         int           // Exchanges loaded, 0 disables the memory
+	maxTokens        int           // Estimated token budget of the history
+	maxAge           time.Duration // Older exchanges belong to another conversation
+}
+
+func NewConversationMemory(conversationRepo repositories.ConversationRepo, maxTurns, maxTokens int, maxAge time.Duration) *ConversationMemory {
+	return &ConversationMemory{
+		conversationRepo: conversationRepo,
+		maxTurns:         maxTurns,
+		maxTokens:        maxTokens,
+		maxAge:           maxAge,
+	}
+}
+
+// History returns the customer's latest exchanges, oldest first. The newest exchanges are kept
+// when the token budget runs out; an exchange is never cut in half
+func (m *ConversationMemory) History(clientID, customerPhone string) []llm.ChatMessage {
+	return m.HistoryBefore(clientID, customerPhone, time.Now())
+}
+
+// HistoryBefore returns the history the bot had when answering a message received at before
+func (m *ConversationMemory) HistoryBefore(clientID, customerPhone string, before time.Time) []llm.ChatMessage {
+	if m == nil || m.maxTurns <= 0 {
+		return nil
+	}
+
+	conversations, err := m.conversationRepo.ListRecentForCustomer(clientID, customerPhone, before.Add(-m.maxAge), before, m.maxTurns)
+	if err != nil {
+		log.Printf("⚠️ Failed to load conversation history for %s: %v", customerPhone, err)
+		return nil
+	}
+
+	// Conversations come newest first; collect exchanges while they fit the budget
+	var exchanges [][]llm.ChatMessage
+	budget := m.maxTokens
+	for _, conv := range conversations {
+		var exchange []llm.ChatMessage
+		if text := strings.TrimSpace(conv.MessageText); text != "" {
+			exchange = append(exchange, llm.ChatMessage{Role: llm.ChatRoleUser, Content: text})
+		}
+		if text := strings.TrimSpace(conv.AIResponse); text != "" {
+			exchange = append(exchange, llm.ChatMessage{Role: llm.ChatRoleAssistant, Content: text})
+		}
+		if len(exchange) == 0 {
+			continue
+		}
+
+		tokens := 0
+		for _, msg := range exchange {
+			tokens += estimateTokens(msg.Content)
+		}
+		if tokens > budget {
+			break
+		}
+		budget -= tokens
+		exchanges = append(exchanges, exchange)
+	}
+
+	var history []llm.ChatMessage
+	for i := len(exchanges) - 1; i >= 0; i-- {
+		history = append(history, exchanges[i]...)
+	}
+	return normalizeChatHistory(history)
+}
+
+// normalizeChatHistory makes the history alternate user/assistant, starting with the user and ending
+// with the assistant, as some providers require. Consecutive messages of one side (e.g. several
+// customer messages while the bot was paused) are merged
+func normalizeChatHistory(history []llm.ChatMessage) []llm.ChatMessage {
+	var merged []llm.ChatMessage
+	for _, msg := range history {
+		if len(merged) == 0 && msg.Role != llm.ChatRoleUser {
+			continue
+		}
+		if last := len(merged) - 1; last >= 0 && merged[last].Role == msg.Role {
+			merged[last].Content += "\n" + msg.Content
+			continue
+		}
+		merged = append(merged, msg)
+	}
+
+	// An unanswered message at the end would be followed by the new user message
+	if last := len(merged) - 1; last >= 0 && merged[last].Role == llm.ChatRoleUser {
+		merged = merged[:last]
+	}
+	return merged
+}
+
+// estimateTokens approximates the token count of a text (about 4 characters per token)
+func estimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// conversationReplayTimeout bounds a whole replay run; messages are replayed one by one
+	conversationReplayTimeout = 30 * time.Minute
+	// conversationReplayTurnTimeout bounds generating one answer
+	conversationReplayTurnTimeout = time.Minute
+	// conversationReplaySaveEvery is how many answers are replayed between progress saves
+	conversationReplaySaveEvery = 10
+)
+
+var (
+	// ErrConversationReplayRunning is returned when a client already has a replay in progress
+	ErrConversationReplayRunning = errors.New("a conversation replay is already running for this client")
+	// ErrConversationReplayNotFound is returned for an unknown replay
+	ErrConversationReplayNotFound = errors.New("conversation replay not found")
+	// ErrNothingToReplay is returned when the window has no answered customer messages
+	ErrNothingToReplay = errors.New("no answered customer messages in this window")
+)
+
+// ConversationReplayRequest selects which historical messages to replay and the LLM to answer them with
+type ConversationReplayRequest struct {
+	CustomerPhone string           `json:"customer_phone"` // Empty = every customer
+	Limit         int              `json:"limit"`          // Max messages, default 50, max 200
+	Provider      llm.ProviderType `json:"provider"`       // Empty = the production provider
+	Model         string           `json:"model"`          // Empty = the provider's default model
+	RequestedBy   string           `json:"requested_by"`
+}
+
+// ConversationReplayService replays a client's historical customer messages against the current knowledge
+// base, prompt and LLM, in a sandbox: answers are generated but never sent and cart commands are not run.
+// QA compares the new answers with the ones that were sent before releasing prompt or workflow changes.
+type ConversationReplayService struct {
+	repo             repositories.ConversationReplayRepo
+	conversationRepo repositories.ConversationRepo
+	clientRepo       repositories.ClientRepo
+	kbRetriever      *kb.Retriever
+	webhookService   *WebhookService
+	llmService       *llm.Service
+	baseConfig       *llm.ProviderConfig // Production provider, and API keys for other providers
+	mu               sync.Mutex
+	active           map[uuid.UUID]bool
+}
+
+func NewConversationReplayService(repo repositories.ConversationReplayRepo, conversationRepo repositories.ConversationRepo, clientRepo repositories.ClientRepo, kbRetriever *kb.Retriever, webhookService *WebhookService, llmService *llm.Service, baseConfig *llm.ProviderConfig) *ConversationReplayService {
+	return &ConversationReplayService{
+		repo:             repo,
+		conversationRepo: conversationRepo,
+		clientRepo:       clientRepo,
+		kbRetriever:      kbRetriever,
+		webhookService:   webhookService,
+		llmService:       llmService,
+		baseConfig:       baseConfig,
+		active:           make(map[uuid.UUID]bool),
+	}
+}
+
+// Start replays the answered messages of a client between from and to; the replay runs in the background
+func (s *ConversationReplayService) Start(clientID uuid.UUID, from, to time.Time, req *ConversationReplayRequest) (*models.ConversationReplay, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	limit := req.Limit
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	llmService, provider, model := s.llmService, s.baseConfig.Type, s.baseConfig.Model
+	if req.Provider != "" || req.Model != "" {
+		cfg := *s.baseConfig
+		if req.Provider != "" {
+			cfg.Type = req.Provider
+			cfg.Model = llm.DefaultModel(req.Provider)
+		}
+		if req.Model != "" {
+			cfg.Model = req.Model
+		}
+		if !llm.ProviderHasKey(&cfg, cfg.Type) {
+			return nil, fmt.Errorf("%s: provider is not configured", cfg.Type)
+		}
+		p, err := llm.NewProvider(&cfg)
+		if err != nil {
+			return nil, err
+		}
+		llmService, provider, model = llm.NewServiceWithProvider(p), cfg.Type, cfg.Model
+	}
+
+	turns, err := s.conversationRepo.ListAnswered(clientID, req.CustomerPhone, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversations: %w", err)
+	}
+	if len(turns) == 0 {
+		return nil, ErrNothingToReplay
+	}
+
+	s.mu.Lock()
+	if s.active[clientID] {
+		s.mu.Unlock()
+		return nil, ErrConversationReplayRunning
+	}
+	s.active[clientID] = true
+	s.mu.Unlock()
+
+	replay := &models.ConversationReplay{
+		ClientID:      clientID,
+		Status:        models.ConversationReplayPending,
+		Provider:      string(provider),
+		Model:         model,
+		CustomerPhone: req.CustomerPhone,
+		FromAt:        from,
+		ToAt:          to,
+		TotalTurns:    len(turns),
+		Turns:         datatypes.JSON("[]"),
+		RequestedBy:   req.RequestedBy,
+	}
+	if err := s.repo.Create(replay); err != nil {
+		s.release(clientID)
+		return nil, fmt.Errorf("failed to record conversation replay: %w", err)
+	}
+
+	go s.run(replay, client, turns, llmService)
+	return replay, nil
+}
+
+// Get returns a replay with the original and new answer of every message
+func (s *ConversationReplayService) Get(id uuid.UUID) (*models.ConversationReplay, error) {
+	replay, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrConversationReplayNotFound
+	}
+	return replay, err
+}
+
+// History returns a client's replays newest first, without their answers
+func (s *ConversationReplayService) History(clientID uuid.UUID, limit int) ([]models.ConversationReplay, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListByClient(clientID, limit)
+}
+
+func (s *ConversationReplayService) release(clientID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, clientID)
+}
+
+func (s *ConversationReplayService) run(replay *models.ConversationReplay, client *models.Client, conversations []models.Conversation, llmService *llm.Service) {
+	defer s.release(replay.ClientID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), conversationReplayTimeout)
+	defer cancel()
+
+	now := time.Now()
+	replay.Status = models.ConversationReplayRunning
+	replay.StartedAt = &now
+	s.save(replay)
+	log.Printf("🎬 Replaying %d messages of client %s with %s/%s", len(conversations), client.ID, replay.Provider, replay.Model)
+
+	// Answers use today's knowledge base, as a message received now would
+	knowledgeBase, err := s.kbRetriever.GetKnowledgeBase(client.ID.String())
+	if err != nil {
+		log.Printf("⚠️ Failed to get knowledge base for replay %s: %v", replay.ID, err)
+		knowledgeBase = &llm.KnowledgeBase{
+			BusinessName:   client.BusinessName,
+			Tone:           client.Tone,
+			PromptTemplate: client.AIPromptTemplate,
+		}
+	}
+
+	price, priceKnown := llm.BenchmarkPrices()[replay.Model]
+	replay.PriceKnown = priceKnown
+
+	turns := make([]models.ConversationReplayTurn, 0, len(conversations))
+	for i := range conversations {
+		if ctx.Err() != nil {
+			break
+		}
+		turns = append(turns, s.replayTurn(ctx, llmService, client, knowledgeBase, &conversations[i], price))
+		if len(turns)%conversationReplaySaveEvery == 0 {
+			summarizeConversationReplay(replay, turns)
+			s.save(replay)
+		}
+	}
+	summarizeConversationReplay(replay, turns)
+
+	finished := time.Now()
+	replay.FinishedAt = &finished
+	if ctx.Err() != nil {
+		replay.Status = models.ConversationReplayFailed
+		replay.Error = fmt.Sprintf("timed out after %d of %d messages", len(turns), len(conversations))
+	} else if replay.ReplayedTurns == 0 {
+		replay.Status = models.ConversationReplayFailed
+		replay.Error = "no message could be replayed"
+	} else {
+		replay.Status = models.ConversationReplayCompleted
+	}
+	s.save(replay)
+
+	log.Printf("🎬 Replay %s %s: %d/%d answers changed, avg similarity %.2f, avg latency %dms, ~$%.4f",
+		replay.ID, replay.Status, replay.ChangedTurns, replay.ReplayedTurns, replay.AvgSimilarity, replay.AvgLatencyMs, replay.EstimatedCostUSD)
+}
+
+// replayTurn answers one historical message again and compares the answer with the one sent
+func (s *ConversationReplayService) replayTurn(ctx context.Context, llmService *llm.Service, client *models.Client, knowledgeBase *llm.KnowledgeBase, conv *models.Conversation, price llm.ModelPrice) models.ConversationReplayTurn {
+	turn := models.ConversationReplayTurn{
+		ConversationID:   conv.ID,
+		CustomerPhone:    conv.CustomerPhone,
+		Message:          conv.MessageText,
+		OriginalResponse: conv.AIResponse,
+		OriginalAt:       conv.CreatedAt,
+	}
+
+	turnCtx, cancel := context.WithTimeout(ctx, conversationReplayTurnTimeout)
+	defer cancel()
+
+	start := time.Now()
+	draft, err := s.webhookService.DraftReply(turnCtx, llmService, client, knowledgeBase, conv)
+	turn.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		turn.Error = err.Error()
+		return turn
+	}
+
+	turn.NewResponse = draft.Response
+	turn.Commands = draft.Commands
+	turn.Similarity = answerSimilarity(conv.AIResponse, draft.Response)
+	turn.Changed = normalizeAnswer(conv.AIResponse) != normalizeAnswer(draft.Response)
+	turn.CostUSD = llm.EstimateCostUSD(price, draft.Prompt, draft.Response)
+	return turn
+}
+
+// summarizeConversationReplay sets the report totals from the answers replayed so far
+func summarizeConversationReplay(replay *models.ConversationReplay, turns []models.ConversationReplayTurn) {
+	replay.ReplayedTurns, replay.ChangedTurns, replay.FailedTurns = 0, 0, 0
+	replay.EstimatedCostUSD = 0
+
+	var latencies []int64
+	var latencyTotal int64
+	similarityTotal := 0.0
+	for _, turn := range turns {
+		if turn.Error != "" {
+			replay.FailedTurns++
+			continue
+		}
+		replay.ReplayedTurns++
+		if turn.Changed {
+			replay.ChangedTurns++
+		}
+		similarityTotal += turn.Similarity
+		latencies = append(latencies, turn.LatencyMs)
+		latencyTotal += turn.LatencyMs
+		replay.EstimatedCostUSD += turn.CostUSD
+	}
+
+	if replay.ReplayedTurns > 0 {
+		replay.AvgSimilarity = float64(int(similarityTotal/float64(replay.ReplayedTurns)*1000+0.5)) / 1000
+		replay.AvgLatencyMs = latencyTotal / int64(replay.ReplayedTurns)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		replay.P95LatencyMs = latencies[(len(latencies)*95+99)/100-1]
+	}
+
+	replay.Turns, _ = json.Marshal(turns)
+}
+
+func (s *ConversationReplayService) save(replay *models.ConversationReplay) {
+	if err := s.repo.Update(replay); err != nil {
+		log.Printf("⚠️ Failed to save conversation replay %s: %v", replay.ID, err)
+	}
+}
+
+// normalizeAnswer ignores case and whitespace differences between two answers
+func normalizeAnswer(answer string) string {
+	return strings.Join(strings.Fields(strings.ToLower(answer)), " ")
+}
+
+// answerSimilarity is the overlap of the words of two answers (Dice coefficient), 1 for the same words
+func answerSimilarity(a, b string) float64 {
+	wordsA, wordsB := answerWords(a), answerWords(b)
+	if len(wordsA) == 0 && len(wordsB) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for word := range wordsA {
+		if wordsB[word] {
+			shared++
+		}
+	}
+	similarity := 2 * float64(shared) / float64(len(wordsA)+len(wordsB))
+	return float64(int(similarity*1000+0.5)) / 1000
+}
+
+func answerWords(answer string) map[string]bool {
+	words := map[string]bool{}
+	for _, word := range strings.FieldsFunc(strings.ToLower(answer), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		words[word] = true
+	}
+	return words
+}
+
+// KeepChangedReplayTurns drops the messages whose answer did not change from a replay report
+func KeepChangedReplayTurns(replay *models.ConversationReplay) {
+	var turns []models.ConversationReplayTurn
+	if err := json.Unmarshal(replay.Turns, &turns); err != nil {
+		return
+	}
+	changed := make([]models.ConversationReplayTurn, 0, len(turns))
+	for _, turn := range turns {
+		if turn.Changed || turn.Error != "" {
+			changed = append(changed, turn)
+		}
+	}
+	replay.Turns, _ = json.Marshal(changed)
+}
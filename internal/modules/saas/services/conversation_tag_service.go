@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// EventConversationTagged is the workflow event fired when a tag is added to a customer's chat
+const EventConversationTagged = "conversation_tagged"
+
+// tagNamePattern keeps tag names typeable in a chat command ("TAG 628xxx komplain")
+var tagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+var ErrTagNotFound = errors.New("tag not found")
+
+// UnknownTagsError lists tag names that aren't defined for the client
+type UnknownTagsError struct {
+	Names []string
+}
+
+func (e *UnknownTagsError) Error() string {
+	return fmt.Sprintf("unknown tag(s): %s", strings.Join(e.Names, ", "))
+}
+
+// ConversationTagService manages tag definitions and tags on customer chats, applied by hand, by chat command or by keyword
+type ConversationTagService struct {
+	tagRepo         repositories.ConversationTagRepo
+	workflowService *WorkflowService
+}
+
+// NewConversationTagService creates a new conversation tag service
+func NewConversationTagService(tagRepo repositories.ConversationTagRepo, workflowService *WorkflowService) *ConversationTagService {
+	return &ConversationTagService{
+		tagRepo:         tagRepo,
+		workflowService: workflowService,
+	}
+}
+
+// NormalizeTagName lowercases a tag name as typed by staff ("VIP" -> "vip")
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// ListTags returns the client's tag definitions
+func (s *ConversationTagService) ListTags(clientID uuid.UUID) ([]models.ConversationTag, error) {
+	tags, err := s.tagRepo.ListTags(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tags: %w", err)
+	}
+	if tags == nil {
+		tags = []models.ConversationTag{}
+	}
+	return tags, nil
+}
+
+// CreateTag defines a new tag for the client
+func (s *ConversationTagService) CreateTag(clientID uuid.UUID, req *models.ConversationTagRequest) (*models.ConversationTag, error) {
+	tag := &models.ConversationTag{ClientID: clientID}
+	if err := applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.GetTagsByNames(clientID, []string{tag.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tag name: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("tag %q already exists", tag.Name)
+	}
+
+	if err := s.tagRepo.CreateTag(tag); err != nil {
+		return nil, fmt.Errorf("failed to create tag: %w", err)
+	}
+	return tag, nil
+}
+
+// UpdateTag renames or reconfigures a tag; chats keep it under the new name
+func (s *ConversationTagService) UpdateTag(clientID, id uuid.UUID, req *models.ConversationTagRequest) (*models.ConversationTag, error) {
+	tag, err := s.tagRepo.GetTag(clientID, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTagNotFound
+		}
+		return nil, fmt.Errorf("failed to load tag: %w", err)
+	}
+
+	if err := applyTagRequest(tag, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.tagRepo.GetTagsByNames(clientID, []string{tag.Name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check tag name: %w", err)
+	}
+	if len(existing) > 0 && existing[0].ID != tag.ID {
+		return nil, fmt.Errorf("tag %q already exists", tag.Name)
+	}
+
+	if err := s.tagRepo.UpdateTag(tag); err != nil {
+		return nil, fmt.Errorf("failed to update tag: %w", err)
+	}
+	return tag, nil
+}
+
+// DeleteTag removes a tag definition and takes it off every chat
+func (s *ConversationTagService) DeleteTag(clientID, id uuid.UUID) error {
+	deleted, err := s.tagRepo.DeleteTag(clientID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete tag: %w", err)
+	}
+	if deleted == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// CheckDefined rejects tag names the client hasn't defined, which would silently shrink an audience
+func (s *ConversationTagService) CheckDefined(clientID uuid.UUID, names []string) error {
+	defined, err := s.ListTags(clientID)
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(defined))
+	for _, tag := range defined {
+		known[tag.Name] = true
+	}
+	var unknown []string
+	for _, name := range names {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return &UnknownTagsError{Names: unknown}
+	}
+	return nil
+}
+
+// applyTagRequest validates a tag request onto a definition
+func applyTagRequest(tag *models.ConversationTag, req *models.ConversationTagRequest) error {
+	name := NormalizeTagName(req.Name)
+	if !tagNamePattern.MatchString(name) {
+		return fmt.Errorf("name must be 1-32 lowercase letters, digits, '-' or '_' without spaces")
+	}
+
+	keywords := []string{}
+	for _, keyword := range req.Keywords {
+		keyword = strings.ToLower(strings.TrimSpace(keyword))
+		if keyword != "" && !slices.Contains(keywords, keyword) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	keywordsJSON, err := json.Marshal(keywords)
+	if err != nil {
+		return err
+	}
+
+	tag.Name = name
+	tag.Color = strings.TrimSpace(req.Color)
+	tag.Description = strings.TrimSpace(req.Description)
+	tag.Keywords = datatypes.JSON(keywordsJSON)
+	return nil
+}
+
+// TagConversation adds tags to a customer's chat and returns the ones it didn't have yet.
+// Every name must be defined; nothing is tagged otherwise.
+func (s *ConversationTagService) TagConversation(clientID uuid.UUID, customerPhone string, names []string, source, actor string) ([]string, error) {
+	if customerPhone == "" {
+		return nil, fmt.Errorf("customer_phone is required")
+	}
+
+	wanted := make([]string, 0, len(names))
+	for _, name := range names {
+		name = NormalizeTagName(name)
+		if name != "" && !slices.Contains(wanted, name) {
+			wanted = append(wanted, name)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, fmt.Errorf("at least one tag is required")
+	}
+
+	tags, err := s.tagRepo.GetTagsByNames(clientID, wanted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tags: %w", err)
+	}
+	if len(tags) < len(wanted) {
+		unknown := &UnknownTagsError{}
+		for _, name := range wanted {
+			if !slices.ContainsFunc(tags, func(t models.ConversationTag) bool { return t.Name == name }) {
+				unknown.Names = append(unknown.Names, name)
+			}
+		}
+		return nil, unknown
+	}
+
+	return s.assign(clientID, customerPhone, tags, source, actor)
+}
+
+func (s *ConversationTagService) assign(clientID uuid.UUID, customerPhone string, tags []models.ConversationTag, source, actor string) ([]string, error) {
+	added := []string{}
+	for _, tag := range tags {
+		created, err := s.tagRepo.Assign(&models.ConversationTagAssignment{
+			ClientID:      clientID,
+			TagID:         tag.ID,
+			CustomerPhone: customerPhone,
+			Source:        source,
+			TaggedBy:      actor,
+		})
+		if err != nil {
+			return added, fmt.Errorf("failed to tag conversation: %w", err)
+		}
+		if created {
+			added = append(added, tag.Name)
+			s.fireTagged(clientID, customerPhone, tag.Name, source, actor)
+		}
+	}
+
+	if len(added) > 0 {
+		log.Printf("🏷️  Chat %s tagged %v (%s, by %s) for client %s", customerPhone, added, source, actor, clientID)
+	}
+	return added, nil
+}
+
+// UntagConversation removes a tag from a customer's chat
+func (s *ConversationTagService) UntagConversation(clientID uuid.UUID, customerPhone, name string) error {
+	tags, err := s.tagRepo.GetTagsByNames(clientID, []string{NormalizeTagName(name)})
+	if err != nil {
+		return fmt.Errorf("failed to load tag: %w", err)
+	}
+	if len(tags) == 0 {
+		return ErrTagNotFound
+	}
+
+	if _, err := s.tagRepo.Unassign(clientID, customerPhone, tags[0].ID); err != nil {
+		return fmt.Errorf("failed to untag conversation: %w", err)
+	}
+	return nil
+}
+
+// CustomerTags returns the tag names on a customer's chat
+func (s *ConversationTagService) CustomerTags(clientID uuid.UUID, customerPhone string) ([]string, error) {
+	tags, err := s.tagRepo.ListCustomerTags(clientID, customerPhone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation tags: %w", err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return tags, nil
+}
+
+// ListConversations returns the client's customer chats with their tags, optionally filtered by tag
+func (s *ConversationTagService) ListConversations(clientID uuid.UUID, filter models.ConversationFilter) ([]models.ConversationSummary, error) {
+	for i, name := range filter.Tags {
+		filter.Tags[i] = NormalizeTagName(name)
+	}
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+
+	summaries, err := s.tagRepo.ListConversations(clientID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	if len(summaries) == 0 {
+		return []models.ConversationSummary{}, nil
+	}
+
+	phones := make([]string, 0, len(summaries))
+	for _, summary := range summaries {
+		phones = append(phones, summary.CustomerPhone)
+	}
+	tags, err := s.tagRepo.TagsForCustomers(clientID, phones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation tags: %w", err)
+	}
+	for i := range summaries {
+		summaries[i].Tags = tags[summaries[i].CustomerPhone]
+		if summaries[i].Tags == nil {
+			summaries[i].Tags = []string{}
+		}
+	}
+	return summaries, nil
+}
+
+// AudiencePhones returns the customers whose chats carry any (or all) of the tags, for broadcasts to a tagged audience
+func (s *ConversationTagService) AudiencePhones(clientID uuid.UUID, tags []string, matchAll bool) ([]string, error) {
+	names := make([]string, 0, len(tags))
+	for _, name := range tags {
+		names = append(names, NormalizeTagName(name))
+	}
+	return s.tagRepo.ListCustomerPhonesByTags(clientID, names, matchAll)
+}
+
+// AutoTag applies the client's tags whose keywords appear in a customer message
+func (s *ConversationTagService) AutoTag(clientID uuid.UUID, customerPhone, message string) {
+	tags, err := s.tagRepo.ListTags(clientID)
+	if err != nil {
+		log.Printf("⚠️ Failed to load tags for auto-tagging: %v", err)
+		return
+	}
+
+	text := strings.ToLower(message)
+	var matched []models.ConversationTag
+	for _, tag := range tags {
+		var keywords []string
+		if len(tag.Keywords) > 0 {
+			if err := json.Unmarshal(tag.Keywords, &keywords); err != nil {
+				log.Printf("⚠️ Invalid keywords on tag %s: %v", tag.Name, err)
+				continue
+			}
+		}
+		if slices.ContainsFunc(keywords, func(k string) bool { return strings.Contains(text, k) }) {
+			matched = append(matched, tag)
+		}
+	}
+
+	if len(matched) > 0 {
+		if _, err := s.assign(clientID, customerPhone, matched, models.TagSourceAuto, "keyword"); err != nil {
+			log.Printf("⚠️ Failed to auto-tag chat %s: %v", customerPhone, err)
+		}
+	}
+}
+
+// fireTagged triggers workflows listening for conversation tags
+func (s *ConversationTagService) fireTagged(clientID uuid.UUID, customerPhone, tag, source, actor string) {
+	if s.workflowService == nil {
+		return
+	}
+
+	eventData := map[string]interface{}{
+		"client_id":      clientID.String(),
+		"customer_phone": customerPhone,
+		"tag":            tag,
+		"source":         source,
+		"tagged_by":      actor,
+	}
+	go func() {
+		if err := s.workflowService.HandleEvent(context.Background(), EventConversationTagged, eventData); err != nil {
+			log.Printf("⚠️ Failed to trigger workflows for %s: %v", EventConversationTagged, err)
+		}
+	}()
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// maxCustomFieldsPerEntity caps the definitions a client can add to one entity
+const maxCustomFieldsPerEntity = 30
+
+var (
+	customFieldEntities = []string{models.CustomFieldEntityCustomer, models.CustomFieldEntityOrder, models.CustomFieldEntityProduct}
+	customFieldTypes    = []string{models.CustomFieldTypeText, models.CustomFieldTypeNumber, models.CustomFieldTypeBoolean, models.CustomFieldTypeDate, models.CustomFieldTypeSelect}
+	customFieldKeyRe    = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+)
+
+var (
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+	ErrCustomFieldTarget   = errors.New("customer, order or product not found")
+)
+
+// CustomFieldService manages client-defined fields on customers, orders and products and validates their values
+type CustomFieldService struct {
+	customFieldRepo repositories.CustomFieldRepo
+}
+
+// NewCustomFieldService creates a new custom field service
+func NewCustomFieldService(customFieldRepo repositories.CustomFieldRepo) *CustomFieldService {
+	return &CustomFieldService{
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+// ListDefinitions returns the client's custom fields, of one entity or all when entity is empty
+func (s *CustomFieldService) ListDefinitions(clientID uuid.UUID, entity string) ([]models.CustomFieldDefinition, error) {
+	if entity != "" && !slices.Contains(customFieldEntities, entity) {
+		return nil, fmt.Errorf("entity must be one of: %s", strings.Join(customFieldEntities, ", "))
+	}
+	return s.customFieldRepo.ListDefinitions(clientID, entity)
+}
+
+// CreateDefinition adds a custom field to an entity
+func (s *CustomFieldService) CreateDefinition(clientID uuid.UUID, req *models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error) {
+	def := &models.CustomFieldDefinition{ClientID: clientID}
+	if err := applyCustomFieldRequest(def, req); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.customFieldRepo.ListDefinitions(clientID, def.Entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	if len(existing) >= maxCustomFieldsPerEntity {
+		return nil, fmt.Errorf("a %s can have at most %d custom fields", def.Entity, maxCustomFieldsPerEntity)
+	}
+	for _, other := range existing {
+		if other.Key == def.Key {
+			return nil, fmt.Errorf("%s already has a custom field with key %q", def.Entity, def.Key)
+		}
+	}
+
+	if err := s.customFieldRepo.CreateDefinition(def); err != nil {
+		return nil, fmt.Errorf("failed to create custom field: %w", err)
+	}
+	return def, nil
+}
+
+// UpdateDefinition changes a custom field; its entity and key are fixed once created since values are stored under them
+func (s *CustomFieldService) UpdateDefinition(clientID, id uuid.UUID, req *models.CustomFieldDefinitionRequest) (*models.CustomFieldDefinition, error) {
+	def, err := s.customFieldRepo.GetDefinition(clientID, id)
+	if err != nil {
+		return nil, ErrCustomFieldNotFound
+	}
+	if (req.Entity != "" && req.Entity != def.Entity) || (req.Key != "" && req.Key != def.Key) {
+		return nil, errors.New("entity and key cannot be changed, create a new field instead")
+	}
+	req.Entity, req.Key = def.Entity, def.Key
+
+	if err := applyCustomFieldRequest(def, req); err != nil {
+		return nil, err
+	}
+	if err := s.customFieldRepo.UpdateDefinition(def); err != nil {
+		return nil, fmt.Errorf("failed to update custom field: %w", err)
+	}
+	return def, nil
+}
+
+// DeleteDefinition removes a custom field along with the values stored under it
+func (s *CustomFieldService) DeleteDefinition(clientID, id uuid.UUID) error {
+	def, err := s.customFieldRepo.GetDefinition(clientID, id)
+	if err != nil {
+		return ErrCustomFieldNotFound
+	}
+	if err := s.customFieldRepo.DeleteDefinition(def); err != nil {
+		return fmt.Errorf("failed to delete custom field: %w", err)
+	}
+	log.Printf("🗑️ Custom field %s.%s deleted for client %s", def.Entity, def.Key, clientID)
+	return nil
+}
+
+// GetValues returns the custom field values of a customer (ref = phone), order or product (ref = ID)
+func (s *CustomFieldService) GetValues(clientID uuid.UUID, entity, ref string) (map[string]interface{}, error) {
+	ref, err := customFieldRef(entity, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := s.customFieldRepo.GetValues(clientID, entity, ref)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if entity == models.CustomFieldEntityCustomer {
+			return map[string]interface{}{}, nil // Customers exist before any value is stored
+		}
+		return nil, ErrCustomFieldTarget
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom fields: %w", err)
+	}
+	return decodeCustomFields(raw), nil
+}
+
+// SetValues merges values into the stored ones: a null value clears the field, keys left out keep their value.
+// Every key must be a defined field and required fields must have a value afterwards
+func (s *CustomFieldService) SetValues(clientID uuid.UUID, entity, ref string, values map[string]interface{}) (map[string]interface{}, error) {
+	current, err := s.GetValues(clientID, entity, ref)
+	if err != nil {
+		return nil, err
+	}
+	ref, _ = customFieldRef(entity, ref)
+
+	defs, err := s.customFieldRepo.ListDefinitions(clientID, entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+	byKey := make(map[string]*models.CustomFieldDefinition, len(defs))
+	for i := range defs {
+		byKey[defs[i].Key] = &defs[i]
+	}
+
+	for key, value := range values {
+		def, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%s has no custom field %q", entity, key)
+		}
+		if value == nil {
+			delete(current, key)
+			continue
+		}
+		normalized, err := ValidateCustomFieldValue(def, value)
+		if err != nil {
+			return nil, err
+		}
+		current[key] = normalized
+	}
+	for _, def := range defs {
+		if _, ok := current[def.Key]; def.Required && !ok {
+			return nil, fmt.Errorf("%s is required", def.Label)
+		}
+	}
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode custom fields: %w", err)
+	}
+	if err := s.customFieldRepo.SetValues(clientID, entity, ref, datatypes.JSON(raw)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomFieldTarget
+		}
+		return nil, fmt.Errorf("failed to save custom fields: %w", err)
+	}
+	return current, nil
+}
+
+// ValidateCustomFieldValue checks a value against its field definition and returns it in its stored form
+// (numbers as float64, booleans as bool, dates and text as string)
+func ValidateCustomFieldValue(def *models.CustomFieldDefinition, value interface{}) (interface{}, error) {
+	switch def.Type {
+	case models.CustomFieldTypeNumber:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s must be a number", def.Label)
+			}
+			n = parsed
+		default:
+			return nil, fmt.Errorf("%s must be a number", def.Label)
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("%s must be a number", def.Label)
+		}
+		if def.MinValue != nil && n < *def.MinValue {
+			return nil, fmt.Errorf("%s must be at least %v", def.Label, *def.MinValue)
+		}
+		if def.MaxValue != nil && n > *def.MaxValue {
+			return nil, fmt.Errorf("%s must be at most %v", def.Label, *def.MaxValue)
+		}
+		return n, nil
+
+	case models.CustomFieldTypeBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+				return b, nil
+			}
+		}
+		return nil, fmt.Errorf("%s must be true or false", def.Label)
+
+	case models.CustomFieldTypeDate:
+		v, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", def.Label)
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", def.Label)
+		}
+		return date.Format("2006-01-02"), nil
+
+	case models.CustomFieldTypeSelect:
+		v, ok := value.(string)
+		if !ok || !slices.Contains(def.Options, strings.TrimSpace(v)) {
+			return nil, fmt.Errorf("%s must be one of: %s", def.Label, strings.Join(def.Options, ", "))
+		}
+		return strings.TrimSpace(v), nil
+
+	default:
+		var v string
+		switch t := value.(type) {
+		case string:
+			v = strings.TrimSpace(t)
+		case float64, bool:
+			v = fmt.Sprint(t)
+		default:
+			return nil, fmt.Errorf("%s must be text", def.Label)
+		}
+		length := float64(len([]rune(v)))
+		if def.MinValue != nil && length < *def.MinValue {
+			return nil, fmt.Errorf("%s must be at least %v characters", def.Label, *def.MinValue)
+		}
+		if def.MaxValue != nil && length > *def.MaxValue {
+			return nil, fmt.Errorf("%s must be at most %v characters", def.Label, *def.MaxValue)
+		}
+		if def.Pattern != "" {
+			re, err := regexp.Compile(def.Pattern)
+			if err == nil && !re.MatchString(v) {
+				return nil, fmt.Errorf("%s has an invalid format", def.Label)
+			}
+		}
+		return v, nil
+	}
+}
+
+// ExportCSV renders the client's customers, orders (created in [start, end)) or products as CSV,
+// with one column per custom field after the entity's own columns
+func (s *CustomFieldService) ExportCSV(clientID uuid.UUID, entity string, start, end time.Time) ([]byte, error) {
+	if !slices.Contains(customFieldEntities, entity) {
+		return nil, fmt.Errorf("entity must be one of: %s", strings.Join(customFieldEntities, ", "))
+	}
+	defs, err := s.customFieldRepo.ListDefinitions(clientID, entity)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom fields: %w", err)
+	}
+
+	var header []string
+	var rows [][]string
+	var values []datatypes.JSON
+	switch entity {
+	case models.CustomFieldEntityCustomer:
+		header = []string{"customer_phone", "created_at"}
+		customers, err := s.customFieldRepo.ListCustomers(clientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list customers: %w", err)
+		}
+		for _, customer := range customers {
+			rows = append(rows, []string{customer.CustomerPhone, customer.CreatedAt.Format(time.RFC3339)})
+			values = append(values, customer.CustomFields)
+		}
+
+	case models.CustomFieldEntityOrder:
+		header = []string{"order_number", "customer_phone", "customer_name", "total_amount", "payment_status", "fulfillment_status", "created_at"}
+		orders, err := s.customFieldRepo.ListOrders(clientID, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list orders: %w", err)
+		}
+		for _, order := range orders {
+			rows = append(rows, []string{
+				order.OrderNumber,
+				order.CustomerPhone,
+				order.CustomerName,
+				fmt.Sprintf("%.2f", order.TotalAmount),
+				order.PaymentStatus,
+				order.FulfillmentStatus,
+				order.CreatedAt.Format(time.RFC3339),
+			})
+			values = append(values, order.CustomFields)
+		}
+
+	case models.CustomFieldEntityProduct:
+		header = []string{"id", "name", "sku", "category", "price", "stock", "is_active"}
+		products, err := s.customFieldRepo.ListProducts(clientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list products: %w", err)
+		}
+		for _, product := range products {
+			rows = append(rows, []string{
+				product.ID.String(),
+				product.Name,
+				product.SKU,
+				product.Category,
+				fmt.Sprintf("%.2f", product.Price),
+				strconv.Itoa(product.Stock),
+				strconv.FormatBool(product.IsActive),
+			})
+			values = append(values, product.CustomFields)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF") // UTF-8 BOM so Excel detects the encoding
+	writer := csv.NewWriter(&buf)
+
+	for _, def := range defs {
+		header = append(header, def.Key)
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+	for i, record := range rows {
+		custom := decodeCustomFields(values[i])
+		for _, def := range defs {
+			value, ok := custom[def.Key]
+			if !ok {
+				record = append(record, "")
+				continue
+			}
+			record = append(record, fmt.Sprint(value))
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// applyCustomFieldRequest validates a definition request and copies it onto def
+func applyCustomFieldRequest(def *models.CustomFieldDefinition, req *models.CustomFieldDefinitionRequest) error {
+	entity := strings.ToLower(strings.TrimSpace(req.Entity))
+	if !slices.Contains(customFieldEntities, entity) {
+		return fmt.Errorf("entity must be one of: %s", strings.Join(customFieldEntities, ", "))
+	}
+	key := strings.TrimSpace(req.Key)
+	if !customFieldKeyRe.MatchString(key) {
+		return errors.New("key must start with a lowercase letter and contain only lowercase letters, digits and underscores (max 40)")
+	}
+	label := strings.TrimSpace(req.Label)
+	if label == "" {
+		return errors.New("label is required")
+	}
+	fieldType := strings.ToLower(strings.TrimSpace(req.Type))
+	if !slices.Contains(customFieldTypes, fieldType) {
+		return fmt.Errorf("type must be one of: %s", strings.Join(customFieldTypes, ", "))
+	}
+
+	var options []string
+	for _, option := range req.Options {
+		if option = strings.TrimSpace(option); option != "" && !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	if fieldType == models.CustomFieldTypeSelect && len(options) == 0 {
+		return errors.New("a select field needs at least one option")
+	}
+	if fieldType != models.CustomFieldTypeSelect {
+		options = nil
+	}
+
+	pattern := strings.TrimSpace(req.Pattern)
+	if pattern != "" {
+		if fieldType != models.CustomFieldTypeText {
+			return errors.New("pattern only applies to text fields")
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+	}
+	if (req.Min != nil || req.Max != nil) && fieldType != models.CustomFieldTypeText && fieldType != models.CustomFieldTypeNumber {
+		return errors.New("min and max only apply to text and number fields")
+	}
+	if req.Min != nil && req.Max != nil && *req.Min > *req.Max {
+		return errors.New("min must not be greater than max")
+	}
+
+	def.Entity = entity
+	def.Key = key
+	def.Label = label
+	def.Type = fieldType
+	def.Required = req.Required
+	def.Options = options
+	def.Pattern = pattern
+	def.MinValue = req.Min
+	def.MaxValue = req.Max
+	def.Position = req.Position
+	return nil
+}
+
+// customFieldRef validates the identifier of the row holding the values
+func customFieldRef(entity, ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	switch entity {
+	case models.CustomFieldEntityCustomer:
+		if ref == "" {
+			return "", errors.New("customer phone is required")
+		}
+	case models.CustomFieldEntityOrder, models.CustomFieldEntityProduct:
+		if _, err := uuid.Parse(ref); err != nil {
+			return "", ErrCustomFieldTarget
+		}
+	default:
+		return "", fmt.Errorf("entity must be one of: %s", strings.Join(customFieldEntities, ", "))
+	}
+	return ref, nil
+}
+
+func decodeCustomFields(raw datatypes.JSON) map[string]interface{} {
+	values := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &values)
+	}
+	if values == nil {
+		values = map[string]interface{}{} // Stored as JSON null
+	}
+	return values
+}
@@ -0,0 +1,391 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// onboardingLanguages are the languages customers can pick during onboarding
+var onboardingLanguages = map[string]string{
+	"id": "Bahasa Indonesia",
+	"en": "English",
+}
+
+// onboardingLanguageAliases map free-text replies to language codes
+var onboardingLanguageAliases = map[string]string{
+	"indonesia": "id",
+	"indo":      "id",
+	"bahasa":    "id",
+	"english":   "en",
+	"inggris":   "en",
+}
+
+// consentKeywords are the replies accepted as consent
+var consentKeywords = []string{"setuju", "ya", "ok", "oke", "agree", "yes"}
+
+// onboardingTexts holds the fixed onboarding prompts per language
+var onboardingTexts = map[string]map[string]string{
+	"id": {
+		"welcome":  "Halo! 👋 Selamat datang di *{business_name}*.",
+		"consent":  "Dengan melanjutkan, Anda menyetujui data percakapan Anda kami simpan untuk melayani pertanyaan dan pesanan Anda.",
+		"agree":    "Balas *SETUJU* untuk melanjutkan.",
+		"pending":  "🙏 Kami memerlukan persetujuan Anda sebelum dapat membantu.",
+		"menu":     "Ada yang bisa kami bantu? Balas angka pilihan Anda:",
+		"ask":      "Ada yang bisa kami bantu? 😊",
+		"thanks":   "✅ Terima kasih!",
+		"language": "Silakan pilih bahasa / Please choose a language:",
+	},
+	"en": {
+		"welcome":  "Hi! 👋 Welcome to *{business_name}*.",
+		"consent":  "By continuing, you agree that we store this conversation to handle your questions and orders.",
+		"agree":    "Reply *AGREE* to continue.",
+		"pending":  "🙏 We need your consent before we can help you.",
+		"menu":     "How can we help? Reply with the number of your choice:",
+		"ask":      "How can we help? 😊",
+		"thanks":   "✅ Thank you!",
+		"language": "Silakan pilih bahasa / Please choose a language:",
+	},
+}
+
+// OnboardingResult tells the webhook what to do with a customer message
+type OnboardingResult struct {
+	Reply   string // Sent to the customer when not empty
+	Handled bool   // The flow consumed the message; the bot should not answer it
+	Message string // Message for the bot when not handled (a quick menu pick is replaced by its message)
+}
+
+// CustomerOnboardingService runs the first-contact flow (welcome, language, consent, quick menu) for new customers
+type CustomerOnboardingService struct {
+	flowRepo         repositories.OnboardingFlowRepo
+	conversationRepo repositories.ConversationRepo
+}
+
+// NewCustomerOnboardingService creates a new customer onboarding service
+func NewCustomerOnboardingService(flowRepo repositories.OnboardingFlowRepo, conversationRepo repositories.ConversationRepo) *CustomerOnboardingService {
+	return &CustomerOnboardingService{
+		flowRepo:         flowRepo,
+		conversationRepo: conversationRepo,
+	}
+}
+
+// Handle runs a customer message through the onboarding flow
+func (s *CustomerOnboardingService) Handle(client *models.Client, customerPhone, message string) OnboardingResult {
+	pass := OnboardingResult{Message: message}
+
+	flow, err := s.flowRepo.GetByClientID(client.ID.String())
+	if err != nil || !flow.Enabled {
+		return pass
+	}
+	languages := flowLanguages(flow)
+
+	state, err := s.flowRepo.GetCustomer(client.ID.String(), customerPhone)
+	if err != nil {
+		return s.start(client, flow, languages, customerPhone, message)
+	}
+
+	switch state.Step {
+	case models.OnboardingStepLanguage:
+		lang, ok := parseLanguageChoice(message, languages)
+		if !ok {
+			return OnboardingResult{Reply: languagePrompt(languages), Handled: true}
+		}
+		state.Language = lang
+		return s.advance(flow, languages, state, onboardingText(lang, "thanks"))
+
+	case models.OnboardingStepConsent:
+		if !isConsentReply(message) {
+			text := onboardingText(state.Language, "pending") + "\n\n" + consentPrompt(flow, state.Language)
+			return OnboardingResult{Reply: text, Handled: true}
+		}
+		now := time.Now()
+		state.ConsentGiven = true
+		state.ConsentAt = &now
+		return s.advance(flow, languages, state, onboardingText(state.Language, "thanks"))
+	}
+
+	// Completed: a number right after the quick menu picks a menu item
+	if state.MenuPending {
+		state.MenuPending = false
+		if err := s.flowRepo.UpdateCustomer(state); err != nil {
+			log.Printf("⚠️ Failed to update onboarding for %s: %v", customerPhone, err)
+		}
+		if item, ok := pickQuickMenuItem(flow, message); ok {
+			log.Printf("👋 Quick menu pick from %s: %s", customerPhone, item.Label)
+			return OnboardingResult{Message: item.Message}
+		}
+	}
+	return pass
+}
+
+// start handles the first message of a customer; customers who chatted before the flow existed skip it
+func (s *CustomerOnboardingService) start(client *models.Client, flow *models.OnboardingFlow, languages []string, customerPhone, message string) OnboardingResult {
+	pass := OnboardingResult{Message: message}
+
+	known, err := s.conversationRepo.HasCustomerConversations(client.ID.String(), customerPhone)
+	if err != nil {
+		log.Printf("⚠️ Failed to check conversation history for %s: %v", customerPhone, err)
+		return pass
+	}
+
+	state := &models.CustomerOnboarding{
+		ClientID:      client.ID,
+		CustomerPhone: customerPhone,
+	}
+	if len(languages) == 1 {
+		state.Language = languages[0]
+	}
+
+	if known {
+		now := time.Now()
+		state.Step = models.OnboardingStepCompleted
+		state.CompletedAt = &now
+		if err := s.flowRepo.CreateCustomer(state); err != nil {
+			log.Printf("⚠️ Failed to record onboarding for %s: %v", customerPhone, err)
+		}
+		return pass
+	}
+
+	log.Printf("👋 Starting onboarding for new customer %s (client %s)", customerPhone, client.ID)
+
+	welcome := flow.WelcomeMessage
+	if strings.TrimSpace(welcome) == "" {
+		welcome = onboardingText(state.Language, "welcome")
+	}
+	welcome = strings.ReplaceAll(welcome, "{business_name}", client.BusinessName)
+
+	result := s.advance(flow, languages, state, welcome)
+	if state.Step == models.OnboardingStepCompleted {
+		// Nothing to ask: greet and let the bot answer the first message too
+		result.Handled = false
+		result.Message = message
+	}
+	return result
+}
+
+// advance moves the customer to the next unanswered step, saves it and prefixes the step's prompt with intro
+func (s *CustomerOnboardingService) advance(flow *models.OnboardingFlow, languages []string, state *models.CustomerOnboarding, intro string) OnboardingResult {
+	var prompt string
+	switch {
+	case len(languages) > 1 && state.Language == "":
+		state.Step = models.OnboardingStepLanguage
+		prompt = languagePrompt(languages)
+	case flow.ConsentRequired && !state.ConsentGiven:
+		state.Step = models.OnboardingStepConsent
+		prompt = consentPrompt(flow, state.Language)
+	default:
+		now := time.Now()
+		state.Step = models.OnboardingStepCompleted
+		state.CompletedAt = &now
+		state.MenuPending = len(quickMenu(flow)) > 0
+		prompt = quickMenuText(flow, state.Language)
+	}
+
+	var err error
+	if state.ID == uuid.Nil {
+		err = s.flowRepo.CreateCustomer(state)
+	} else {
+		err = s.flowRepo.UpdateCustomer(state)
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to save onboarding for %s: %v", state.CustomerPhone, err)
+	}
+
+	return OnboardingResult{Reply: intro + "\n\n" + prompt, Handled: true}
+}
+
+// CustomerLanguage returns the language the customer picked during onboarding (empty if none)
+func (s *CustomerOnboardingService) CustomerLanguage(clientID, customerPhone string) string {
+	state, err := s.flowRepo.GetCustomer(clientID, customerPhone)
+	if err != nil {
+		return ""
+	}
+	return state.Language
+}
+
+// GetFlow returns the onboarding flow configured for a client (disabled and empty if none)
+func (s *CustomerOnboardingService) GetFlow(clientID string) (*models.OnboardingFlow, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	flow, err := s.flowRepo.GetByClientID(clientID)
+	if err != nil {
+		return &models.OnboardingFlow{
+			ClientID:  uid,
+			QuickMenu: datatypes.JSON("[]"),
+			Languages: datatypes.JSON("[]"),
+		}, nil
+	}
+	return flow, nil
+}
+
+// UpdateFlow validates and saves the onboarding flow for a client
+func (s *CustomerOnboardingService) UpdateFlow(clientID string, req *models.UpdateOnboardingFlowRequest) (*models.OnboardingFlow, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	languages := make([]string, 0, len(req.Languages))
+	for _, lang := range req.Languages {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if _, ok := onboardingLanguages[lang]; !ok {
+			return nil, fmt.Errorf("unsupported language %q (supported: id, en)", lang)
+		}
+		languages = append(languages, lang)
+	}
+
+	menu := make([]models.QuickMenuItem, 0, len(req.QuickMenu))
+	for i, item := range req.QuickMenu {
+		item.Label = strings.TrimSpace(item.Label)
+		item.Message = strings.TrimSpace(item.Message)
+		if item.Label == "" {
+			return nil, fmt.Errorf("quick_menu %d: label is required", i+1)
+		}
+		if item.Message == "" {
+			item.Message = item.Label
+		}
+		menu = append(menu, item)
+	}
+
+	menuJSON, err := json.Marshal(menu)
+	if err != nil {
+		return nil, err
+	}
+	languagesJSON, err := json.Marshal(languages)
+	if err != nil {
+		return nil, err
+	}
+
+	flow := &models.OnboardingFlow{
+		ClientID:        uid,
+		Enabled:         req.Enabled,
+		WelcomeMessage:  strings.TrimSpace(req.WelcomeMessage),
+		QuickMenu:       datatypes.JSON(menuJSON),
+		Languages:       datatypes.JSON(languagesJSON),
+		ConsentRequired: req.ConsentRequired,
+		ConsentText:     strings.TrimSpace(req.ConsentText),
+	}
+	if err := s.flowRepo.Upsert(flow); err != nil {
+		return nil, fmt.Errorf("failed to save onboarding flow: %w", err)
+	}
+
+	return s.GetFlow(clientID)
+}
+
+// ResetCustomer makes the flow run again on the customer's next message
+func (s *CustomerOnboardingService) ResetCustomer(clientID, customerPhone string) error {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.flowRepo.DeleteCustomer(clientID, normalizePhone(customerPhone))
+}
+
+// flowLanguages returns the configured language codes
+func flowLanguages(flow *models.OnboardingFlow) []string {
+	var languages []string
+	if len(flow.Languages) > 0 {
+		if err := json.Unmarshal(flow.Languages, &languages); err != nil {
+			log.Printf("⚠️ Invalid onboarding languages for client %s: %v", flow.ClientID, err)
+		}
+	}
+	return languages
+}
+
+// quickMenu returns the configured quick menu items
+func quickMenu(flow *models.OnboardingFlow) []models.QuickMenuItem {
+	var items []models.QuickMenuItem
+	if len(flow.QuickMenu) > 0 {
+		if err := json.Unmarshal(flow.QuickMenu, &items); err != nil {
+			log.Printf("⚠️ Invalid onboarding quick menu for client %s: %v", flow.ClientID, err)
+		}
+	}
+	return items
+}
+
+// onboardingText returns a fixed prompt in the customer's language (Indonesian by default)
+func onboardingText(lang, key string) string {
+	if texts, ok := onboardingTexts[lang]; ok {
+		return texts[key]
+	}
+	return onboardingTexts["id"][key]
+}
+
+func languagePrompt(languages []string) string {
+	var sb strings.Builder
+	sb.WriteString(onboardingText("id", "language"))
+	for i, lang := range languages {
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, onboardingLanguages[lang]))
+	}
+	return sb.String()
+}
+
+func consentPrompt(flow *models.OnboardingFlow, lang string) string {
+	text := flow.ConsentText
+	if strings.TrimSpace(text) == "" {
+		text = onboardingText(lang, "consent")
+	}
+	return text + "\n\n" + onboardingText(lang, "agree")
+}
+
+func quickMenuText(flow *models.OnboardingFlow, lang string) string {
+	items := quickMenu(flow)
+	if len(items) == 0 {
+		return onboardingText(lang, "ask")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(onboardingText(lang, "menu"))
+	for i, item := range items {
+		sb.WriteString(fmt.Sprintf("\n%d. %s", i+1, item.Label))
+	}
+	return sb.String()
+}
+
+// parseLanguageChoice accepts the option number, language code or language name
+func parseLanguageChoice(message string, languages []string) (string, bool) {
+	reply := strings.ToLower(strings.TrimSpace(message))
+	if n, err := strconv.Atoi(reply); err == nil && n >= 1 && n <= len(languages) {
+		return languages[n-1], true
+	}
+
+	if code, ok := onboardingLanguageAliases[reply]; ok {
+		reply = code
+	}
+	for _, lang := range languages {
+		if reply == lang || reply == strings.ToLower(onboardingLanguages[lang]) {
+			return lang, true
+		}
+	}
+	return "", false
+}
+
+func isConsentReply(message string) bool {
+	reply := strings.ToLower(strings.Trim(strings.TrimSpace(message), ".!"))
+	for _, keyword := range consentKeywords {
+		if reply == keyword {
+			return true
+		}
+	}
+	return false
+}
+
+// pickQuickMenuItem matches a reply with a quick menu number
+func pickQuickMenuItem(flow *models.OnboardingFlow, message string) (models.QuickMenuItem, bool) {
+	items := quickMenu(flow)
+	n, err := strconv.Atoi(strings.TrimSpace(message))
+	if err != nil || n < 1 || n > len(items) {
+		return models.QuickMenuItem{}, false
+	}
+	return items[n-1], true
+}
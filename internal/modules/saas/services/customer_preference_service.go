@@ -0,0 +1,170 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxDeliveryNotesLength keeps delivery notes short enough for a driver's job message
+const maxDeliveryNotesLength = 300
+
+// preferredPaymentMethods maps the words customers use for a payment method to the method recorded on orders
+var preferredPaymentMethods = map[string]string{
+	payment.MethodCOD:          payment.MethodCOD,
+	"bayar di tempat":          payment.MethodCOD,
+	"cash":                     payment.MethodCOD,
+	"tunai":                    payment.MethodCOD,
+	payment.MethodQRIS:         payment.MethodQRIS,
+	payment.MethodBankTransfer: payment.MethodBankTransfer,
+	"transfer":                 payment.MethodBankTransfer,
+	"transfer bank":            payment.MethodBankTransfer,
+	"va":                       payment.MethodBankTransfer,
+	"virtual account":          payment.MethodBankTransfer,
+	payment.MethodEWallet:      payment.MethodEWallet,
+	"e-wallet":                 payment.MethodEWallet,
+	"gopay":                    payment.MethodEWallet,
+	"ovo":                      payment.MethodEWallet,
+	"dana":                     payment.MethodEWallet,
+	"shopeepay":                payment.MethodEWallet,
+	payment.MethodCreditCard:   payment.MethodCreditCard,
+	"kartu kredit":             payment.MethodCreditCard,
+}
+
+// CustomerPreferenceService remembers per-customer preferences stated in chat (or set by an agent)
+// and applies them to replies and checkout
+type CustomerPreferenceService struct {
+	repo repositories.CustomerPreferenceRepo
+}
+
+// NewCustomerPreferenceService creates a new customer preference service
+func NewCustomerPreferenceService(repo repositories.CustomerPreferenceRepo) *CustomerPreferenceService {
+	return &CustomerPreferenceService{repo: repo}
+}
+
+// Preferences returns the customer's preferences, empty when none are stored
+func (s *CustomerPreferenceService) Preferences(clientID uuid.UUID, customerPhone string) *models.CustomerPreference {
+	preference, err := s.repo.Get(clientID, customerPhone)
+	if err == nil {
+		return preference
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("⚠️ Failed to load preferences of %s: %v", customerPhone, err)
+	}
+	return &models.CustomerPreference{ClientID: clientID, CustomerPhone: customerPhone}
+}
+
+// Update validates and saves preferences; fields left out of the request keep their value
+func (s *CustomerPreferenceService) Update(clientID uuid.UUID, customerPhone string, req *models.UpdateCustomerPreferenceRequest, source string) (*models.CustomerPreference, error) {
+	customerPhone = strings.TrimSpace(customerPhone)
+	if customerPhone == "" {
+		return nil, errors.New("customer phone is required")
+	}
+
+	preference := s.Preferences(clientID, customerPhone)
+	if req.Language != nil {
+		lang, err := normalizePreferredLanguage(*req.Language)
+		if err != nil {
+			return nil, err
+		}
+		preference.Language = lang
+	}
+	if req.PaymentMethod != nil {
+		method, err := normalizePreferredPaymentMethod(*req.PaymentMethod)
+		if err != nil {
+			return nil, err
+		}
+		preference.PaymentMethod = method
+	}
+	if req.DeliveryNotes != nil {
+		notes := strings.Join(strings.Fields(*req.DeliveryNotes), " ")
+		if len([]rune(notes)) > maxDeliveryNotesLength {
+			return nil, fmt.Errorf("delivery notes must be at most %d characters", maxDeliveryNotesLength)
+		}
+		preference.DeliveryNotes = notes
+	}
+	preference.UpdatedBy = source
+
+	if err := s.repo.Upsert(preference); err != nil {
+		return nil, fmt.Errorf("failed to save preferences: %w", err)
+	}
+	return s.Preferences(clientID, customerPhone), nil
+}
+
+// SetFromChat records a preference the customer stated in chat, parsed from a [SET_PREFERENCE:key|value] command
+func (s *CustomerPreferenceService) SetFromChat(clientID uuid.UUID, customerPhone, key, value string) error {
+	req := &models.UpdateCustomerPreferenceRequest{}
+	switch key {
+	case models.PreferenceLanguage:
+		req.Language = &value
+	case models.PreferencePaymentMethod:
+		req.PaymentMethod = &value
+	case models.PreferenceDeliveryNotes:
+		req.DeliveryNotes = &value
+	default:
+		return fmt.Errorf("unknown preference %q", key)
+	}
+	_, err := s.Update(clientID, customerPhone, req, models.PreferenceSourceCustomer)
+	return err
+}
+
+// Delete forgets all of the customer's preferences
+func (s *CustomerPreferenceService) Delete(clientID uuid.UUID, customerPhone string) error {
+	return s.repo.Delete(clientID, customerPhone)
+}
+
+// PromptContext tells the LLM the customer's stored preferences and how to record new ones
+func (s *CustomerPreferenceService) PromptContext(preference *models.CustomerPreference) string {
+	var sb strings.Builder
+	sb.WriteString("\n\n=== PREFERENSI CUSTOMER ===\n")
+	if preference.IsEmpty() {
+		sb.WriteString("Belum ada preferensi tersimpan.\n")
+	}
+	if preference.Language != "" {
+		sb.WriteString(fmt.Sprintf("- Bahasa: %s\n", llm.Languages[preference.Language]))
+	}
+	if preference.PaymentMethod != "" {
+		sb.WriteString(fmt.Sprintf("- Metode pembayaran: %s (otomatis dipakai saat checkout)\n", preference.PaymentMethod))
+	}
+	if preference.DeliveryNotes != "" {
+		sb.WriteString(fmt.Sprintf("- Catatan pengiriman: %s (otomatis diteruskan ke kurir)\n", preference.DeliveryNotes))
+	}
+	sb.WriteString("Jika customer menyebutkan preferensi baru (bahasa, metode pembayaran, atau catatan pengiriman seperti 'titip di satpam'), ")
+	sb.WriteString("konfirmasi dengan ramah lalu di AKHIR response (baris terpisah) tambahkan:\n")
+	sb.WriteString("   [SET_PREFERENCE:language|kode bahasa: id, en, jv atau su]\n")
+	sb.WriteString("   [SET_PREFERENCE:payment_method|cod, qris, bank_transfer, ewallet atau credit_card]\n")
+	sb.WriteString("   [SET_PREFERENCE:delivery_notes|isi catatan]\n")
+	sb.WriteString("   Contoh: [SET_PREFERENCE:delivery_notes|Titip di satpam]\n")
+	return sb.String()
+}
+
+func normalizePreferredLanguage(lang string) (string, error) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if lang == "" {
+		return "", nil
+	}
+	if _, ok := llm.Languages[lang]; !ok {
+		return "", fmt.Errorf("unsupported language %q", lang)
+	}
+	return lang, nil
+}
+
+func normalizePreferredPaymentMethod(method string) (string, error) {
+	method = strings.ToLower(strings.Join(strings.Fields(method), " "))
+	if method == "" {
+		return "", nil
+	}
+	normalized, ok := preferredPaymentMethods[method]
+	if !ok {
+		return "", fmt.Errorf("unsupported payment method %q", method)
+	}
+	return normalized, nil
+}
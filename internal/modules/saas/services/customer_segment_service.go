@@ -0,0 +1,233 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	maxSegmentNameLength = 64
+	maxSegmentDays       = 365
+)
+
+var ErrSegmentNotFound = errors.New("segment not found")
+
+// CustomerSegmentService keeps the client's saved customer segments and resolves their members for
+// campaigns and workflow conditions
+type CustomerSegmentService struct {
+	repo         repositories.CustomerSegmentRepo
+	customerRepo repositories.CustomerRepo
+	tagService   *ConversationTagService
+}
+
+// NewCustomerSegmentService creates a new customer segment service
+func NewCustomerSegmentService(repo repositories.CustomerSegmentRepo, customerRepo repositories.CustomerRepo, tagService *ConversationTagService) *CustomerSegmentService {
+	return &CustomerSegmentService{repo: repo, customerRepo: customerRepo, tagService: tagService}
+}
+
+// List returns the client's segments by name
+func (s *CustomerSegmentService) List(clientID uuid.UUID) ([]models.CustomerSegment, error) {
+	segments, err := s.repo.List(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	if segments == nil {
+		segments = []models.CustomerSegment{}
+	}
+	return segments, nil
+}
+
+// Get returns a segment, ErrSegmentNotFound when the client has no such segment
+func (s *CustomerSegmentService) Get(clientID, id uuid.UUID) (*models.CustomerSegment, error) {
+	segment, err := s.repo.GetByID(clientID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSegmentNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load segment: %w", err)
+	}
+	return segment, nil
+}
+
+// Create saves a new segment for the client
+func (s *CustomerSegmentService) Create(clientID uuid.UUID, req *models.CustomerSegmentRequest) (*models.CustomerSegment, error) {
+	segment := &models.CustomerSegment{ClientID: clientID}
+	if err := s.applyRequest(segment, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(segment); err != nil {
+		return nil, fmt.Errorf("failed to create segment: %w", err)
+	}
+	return segment, nil
+}
+
+// Update renames a segment or changes its rules; campaigns not yet sent to it pick up the new rules
+func (s *CustomerSegmentService) Update(clientID, id uuid.UUID, req *models.CustomerSegmentRequest) (*models.CustomerSegment, error) {
+	segment, err := s.Get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyRequest(segment, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(segment); err != nil {
+		return nil, fmt.Errorf("failed to update segment: %w", err)
+	}
+	return segment, nil
+}
+
+// Delete removes a segment; campaigns still to be sent to it fail when they come due
+func (s *CustomerSegmentService) Delete(clientID, id uuid.UUID) error {
+	deleted, err := s.repo.Delete(clientID, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete segment: %w", err)
+	}
+	if deleted == 0 {
+		return ErrSegmentNotFound
+	}
+	return nil
+}
+
+// Preview counts the customers currently matching the rules, without saving them
+func (s *CustomerSegmentService) Preview(clientID uuid.UUID, rules models.SegmentRules) (int64, error) {
+	if err := s.checkRules(clientID, &rules); err != nil {
+		return 0, err
+	}
+	count, err := s.customerRepo.CountSegment(clientID, rules)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count segment: %w", err)
+	}
+	return count, nil
+}
+
+// Rules returns the rules of a segment, for filtering customers by it
+func (s *CustomerSegmentService) Rules(segment *models.CustomerSegment) (models.SegmentRules, error) {
+	var rules models.SegmentRules
+	if err := json.Unmarshal(segment.Rules, &rules); err != nil {
+		return rules, fmt.Errorf("invalid rules of segment %s: %w", segment.ID, err)
+	}
+	return rules, nil
+}
+
+// Phones returns the phones of the segment's current members
+func (s *CustomerSegmentService) Phones(clientID, id uuid.UUID) ([]string, error) {
+	segment, err := s.Get(clientID, id)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.Rules(segment)
+	if err != nil {
+		return nil, err
+	}
+	phones, err := s.customerRepo.SegmentPhones(clientID, rules)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve segment %q: %w", segment.Name, err)
+	}
+	return phones, nil
+}
+
+// SegmentsOf returns the names of the client's segments the customer is currently a member of
+func (s *CustomerSegmentService) SegmentsOf(clientID uuid.UUID, customerPhone string) ([]string, error) {
+	segments, err := s.List(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	for i := range segments {
+		rules, err := s.Rules(&segments[i])
+		if err != nil {
+			return nil, err
+		}
+		member, err := s.customerRepo.InSegment(clientID, customerPhone, rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check segment %q: %w", segments[i].Name, err)
+		}
+		if member {
+			names = append(names, segments[i].Name)
+		}
+	}
+	return names, nil
+}
+
+// applyRequest validates a segment request and copies it onto the segment
+func (s *CustomerSegmentService) applyRequest(segment *models.CustomerSegment, req *models.CustomerSegmentRequest) error {
+	name := strings.Join(strings.Fields(req.Name), " ")
+	if name == "" {
+		return errors.New("name is required")
+	}
+	if len([]rune(name)) > maxSegmentNameLength {
+		return fmt.Errorf("name must be at most %d characters", maxSegmentNameLength)
+	}
+	existing, err := s.repo.GetByName(segment.ClientID, name)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check segment name: %w", err)
+	}
+	if existing != nil && existing.ID != segment.ID {
+		return fmt.Errorf("segment %q already exists", name)
+	}
+
+	rules := req.Rules
+	if err := s.checkRules(segment.ClientID, &rules); err != nil {
+		return err
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+
+	segment.Name = name
+	segment.Description = strings.TrimSpace(req.Description)
+	segment.Rules = datatypes.JSON(rulesJSON)
+	return nil
+}
+
+// checkRules validates segment rules, normalizing their tag names
+func (s *CustomerSegmentService) checkRules(clientID uuid.UUID, rules *models.SegmentRules) error {
+	windows := []struct {
+		field string
+		days  int
+	}{
+		{"ordered_within_days", rules.OrderedWithinDays},
+		{"not_ordered_within_days", rules.NotOrderedWithinDays},
+		{"active_within_days", rules.ActiveWithinDays},
+	}
+	for _, window := range windows {
+		if window.days < 0 || window.days > maxSegmentDays {
+			return fmt.Errorf("%s must be between 0 and %d (0 to ignore)", window.field, maxSegmentDays)
+		}
+	}
+	if rules.OrderedWithinDays > 0 && rules.NotOrderedWithinDays >= rules.OrderedWithinDays {
+		return errors.New("not_ordered_within_days must be less than ordered_within_days, or no customer matches")
+	}
+	if rules.MinOrders < 0 || rules.MinLifetimeValue < 0 {
+		return errors.New("min_orders and min_lifetime_value can't be negative")
+	}
+
+	rules.Tags = normalizeTagNames(rules.Tags)
+	rules.ExcludeTags = normalizeTagNames(rules.ExcludeTags)
+	if len(rules.Tags) == 0 {
+		rules.MatchAllTags = false
+	}
+	return s.tagService.CheckDefined(clientID, append(append([]string{}, rules.Tags...), rules.ExcludeTags...))
+}
+
+// normalizeTagNames normalizes tag names, dropping empty and repeated ones
+func normalizeTagNames(names []string) []string {
+	var normalized []string
+	for _, name := range names {
+		if name = NormalizeTagName(name); name != "" && !slices.Contains(normalized, name) {
+			normalized = append(normalized, name)
+		}
+	}
+	return normalized
+}
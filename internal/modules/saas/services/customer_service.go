@@ -0,0 +1,127 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	maxCustomerNameLength  = 100
+	maxCustomerNotesLength = 2000
+)
+
+// CustomerService keeps the client's customer records (CRM): created from inbound messages, named and
+// annotated by staff, with their orders and chat tags summed up
+type CustomerService struct {
+	repo    repositories.CustomerRepo
+	tagRepo repositories.ConversationTagRepo
+}
+
+// NewCustomerService creates a new customer service
+func NewCustomerService(repo repositories.CustomerRepo, tagRepo repositories.ConversationTagRepo) *CustomerService {
+	return &CustomerService{repo: repo, tagRepo: tagRepo}
+}
+
+// RecordInteraction notes an inbound message of the customer, creating their record on the first one
+func (s *CustomerService) RecordInteraction(clientID uuid.UUID, customerPhone string) error {
+	if err := s.repo.RecordInteraction(clientID, customerPhone, time.Now()); err != nil {
+		return fmt.Errorf("failed to record interaction of %s: %w", customerPhone, err)
+	}
+	return nil
+}
+
+// List returns the client's customers matching the filter, with their chat tags
+func (s *CustomerService) List(clientID uuid.UUID, filter models.CustomerFilter) ([]models.CustomerProfile, error) {
+	filter.Search = strings.TrimSpace(filter.Search)
+	for i, name := range filter.Tags {
+		filter.Tags[i] = NormalizeTagName(name)
+	}
+	if filter.Limit <= 0 || filter.Limit > 200 {
+		filter.Limit = 50
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	profiles, err := s.repo.List(clientID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list customers: %w", err)
+	}
+	if len(profiles) == 0 {
+		return []models.CustomerProfile{}, nil
+	}
+
+	phones := make([]string, 0, len(profiles))
+	for _, profile := range profiles {
+		phones = append(phones, profile.CustomerPhone)
+	}
+	tags, err := s.tagRepo.TagsForCustomers(clientID, phones)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation tags: %w", err)
+	}
+	for i := range profiles {
+		profiles[i].Tags = tags[profiles[i].CustomerPhone]
+		if profiles[i].Tags == nil {
+			profiles[i].Tags = []string{}
+		}
+	}
+	return profiles, nil
+}
+
+// Get returns a customer's profile with their chat tags, gorm.ErrRecordNotFound when there is no such customer
+func (s *CustomerService) Get(clientID uuid.UUID, customerPhone string) (*models.CustomerProfile, error) {
+	profile, err := s.repo.Get(clientID, customerPhone)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.ListCustomerTags(clientID, customerPhone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load conversation tags: %w", err)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	profile.Tags = tags
+	return profile, nil
+}
+
+// Update sets a customer's name and notes; fields left out of the request keep their value. Customers
+// who never wrote in are created, e.g. for a walk-in customer staff add by phone.
+func (s *CustomerService) Update(clientID uuid.UUID, customerPhone string, req *models.UpdateCustomerRequest) (*models.CustomerProfile, error) {
+	customerPhone = strings.TrimSpace(customerPhone)
+	if customerPhone == "" {
+		return nil, errors.New("customer phone is required")
+	}
+
+	customer := models.Customer{ClientID: clientID, CustomerPhone: customerPhone}
+	if existing, err := s.repo.Get(clientID, customerPhone); err == nil {
+		customer.Name = existing.Name
+		customer.Notes = existing.Notes
+	}
+	if req.Name != nil {
+		name := strings.Join(strings.Fields(*req.Name), " ")
+		if len([]rune(name)) > maxCustomerNameLength {
+			return nil, fmt.Errorf("name must be at most %d characters", maxCustomerNameLength)
+		}
+		customer.Name = name
+	}
+	if req.Notes != nil {
+		notes := strings.TrimSpace(*req.Notes)
+		if len([]rune(notes)) > maxCustomerNotesLength {
+			return nil, fmt.Errorf("notes must be at most %d characters", maxCustomerNotesLength)
+		}
+		customer.Notes = notes
+	}
+
+	if err := s.repo.Upsert(&customer); err != nil {
+		return nil, fmt.Errorf("failed to save customer: %w", err)
+	}
+	return s.Get(clientID, customerPhone)
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// Driver reply keywords
+const (
+	driverKeywordPickup        = "jemput"
+	driverKeywordDeliver       = "selesai"
+	driverKeywordCashCollected = "lunas" // COD: cash received and order delivered
+)
+
+// DeliveryService manages drivers and order shipments.
+// Drivers receive jobs over WhatsApp and update them by replying keywords.
+type DeliveryService struct {
+	driverRepo   repositories.DriverRepo
+	shipmentRepo repositories.ShipmentRepo
+	orderRepo    repositories.OrderRepo
+	orderSvc     *OrderService
+	branchSvc    *BranchService
+	whatsappSvc  WhatsAppService
+	sandboxSvc   *SandboxService
+}
+
+func NewDeliveryService(
+	driverRepo repositories.DriverRepo,
+	shipmentRepo repositories.ShipmentRepo,
+	orderRepo repositories.OrderRepo,
+	orderSvc *OrderService,
+	branchSvc *BranchService,
+	whatsappSvc WhatsAppService,
+	sandboxSvc *SandboxService,
+) *DeliveryService {
+	return &DeliveryService{
+		driverRepo:   driverRepo,
+		shipmentRepo: shipmentRepo,
+		orderRepo:    orderRepo,
+		orderSvc:     orderSvc,
+		branchSvc:    branchSvc,
+		whatsappSvc:  whatsappSvc,
+		sandboxSvc:   sandboxSvc,
+	}
+}
+
+// RegisterDriver registers a new driver for a client
+func (s *DeliveryService) RegisterDriver(req *models.CreateDriverRequest) (*models.Driver, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client ID: %w", err)
+	}
+
+	phone := normalizePhone(req.Phone)
+	if strings.TrimSpace(req.Name) == "" || phone == "" {
+		return nil, errors.New("name and phone are required")
+	}
+
+	driver := &models.Driver{
+		ClientID: clientID,
+		Name:     strings.TrimSpace(req.Name),
+		Phone:    phone,
+		IsActive: true,
+	}
+
+	if req.BranchID != "" {
+		branchID, err := s.resolveBranch(clientID, req.BranchID)
+		if err != nil {
+			return nil, err
+		}
+		driver.BranchID = branchID
+	}
+
+	if err := s.driverRepo.Create(driver); err != nil {
+		return nil, fmt.Errorf("failed to register driver: %w", err)
+	}
+
+	log.Printf("🛵 Driver registered: %s (%s)", driver.Name, driver.Phone)
+	return driver, nil
+}
+
+// ListDrivers lists a client's drivers
+func (s *DeliveryService) ListDrivers(clientID string, activeOnly bool) ([]models.Driver, error) {
+	return s.driverRepo.ListByClientID(clientID, activeOnly)
+}
+
+// UpdateDriver updates a driver's details
+func (s *DeliveryService) UpdateDriver(driverID, clientID string, req *models.UpdateDriverRequest) (*models.Driver, error) {
+	driver, err := s.getDriver(driverID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if strings.TrimSpace(*req.Name) == "" {
+			return nil, errors.New("name cannot be empty")
+		}
+		driver.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Phone != nil {
+		phone := normalizePhone(*req.Phone)
+		if phone == "" {
+			return nil, errors.New("phone cannot be empty")
+		}
+		driver.Phone = phone
+	}
+	if req.BranchID != nil {
+		if *req.BranchID == "" {
+			driver.BranchID = nil
+		} else {
+			branchID, err := s.resolveBranch(driver.ClientID, *req.BranchID)
+			if err != nil {
+				return nil, err
+			}
+			driver.BranchID = branchID
+		}
+	}
+	if req.IsActive != nil {
+		driver.IsActive = *req.IsActive
+	}
+
+	if err := s.driverRepo.Update(driver); err != nil {
+		return nil, fmt.Errorf("failed to update driver: %w", err)
+	}
+	return driver, nil
+}
+
+// AssignOrder assigns a paid (or customer-confirmed COD) order to a driver and sends the driver the job
+func (s *DeliveryService) AssignOrder(orderID, clientID string, req *models.AssignDriverRequest) (*models.Shipment, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, errors.New("order not found")
+	}
+
+	if order.PaymentStatus != models.PaymentStatusPaid && order.PaymentStatus != models.PaymentStatusPendingCOD {
+		return nil, fmt.Errorf("cannot assign driver to order with payment status %s", order.PaymentStatus)
+	}
+	if order.FulfillmentStatus == models.FulfillmentStatusDelivered || order.FulfillmentStatus == models.FulfillmentStatusCancelled {
+		return nil, fmt.Errorf("cannot assign driver to order with fulfillment status %s", order.FulfillmentStatus)
+	}
+
+	if strings.TrimSpace(req.DeliveryAddress) == "" {
+		return nil, errors.New("delivery address is required")
+	}
+
+	driver, err := s.getDriver(req.DriverID, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !driver.IsActive {
+		return nil, errors.New("driver is not active")
+	}
+
+	// Without notes from the agent, the driver gets the customer's own delivery notes
+	notes := req.Notes
+	if strings.TrimSpace(notes) == "" {
+		notes = order.DeliveryNotes
+	}
+
+	shipment, err := s.shipmentRepo.GetByOrderID(order.ID.String())
+	if err == nil {
+		// Reassigning is only allowed before pickup
+		if shipment.Status != models.ShipmentStatusAssigned {
+			return nil, fmt.Errorf("cannot reassign shipment with status %s", shipment.Status)
+		}
+		shipment.DriverID = driver.ID
+		shipment.DeliveryAddress = strings.TrimSpace(req.DeliveryAddress)
+		shipment.Notes = notes
+		shipment.AssignedAt = time.Now()
+		if err := s.shipmentRepo.Update(shipment); err != nil {
+			return nil, fmt.Errorf("failed to reassign shipment: %w", err)
+		}
+	} else {
+		shipment = &models.Shipment{
+			ClientID:        order.ClientID,
+			OrderID:         order.ID,
+			DriverID:        driver.ID,
+			Status:          models.ShipmentStatusAssigned,
+			DeliveryAddress: strings.TrimSpace(req.DeliveryAddress),
+			Notes:           notes,
+		}
+		if err := s.shipmentRepo.Create(shipment); err != nil {
+			return nil, fmt.Errorf("failed to create shipment: %w", err)
+		}
+	}
+
+	shipment.Order = order
+	shipment.Driver = driver
+
+	log.Printf("🛵 Order %s assigned to driver %s", order.OrderNumber, driver.Name)
+
+	s.sendDriverJob(shipment)
+
+	customerMessage := fmt.Sprintf(
+		"🛵 *Pesanan Segera Dikirim*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Kurir: %s\n\n"+
+			"Kami akan mengabari Anda saat pesanan dijemput kurir.",
+		order.OrderNumber,
+		driver.Name,
+	)
+	sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, order.ClientID).SendMessage(order.CustomerPhone, customerMessage)
+
+	return shipment, nil
+}
+
+// ListShipments lists a client's shipments with optional filtering
+func (s *DeliveryService) ListShipments(clientID, status, driverID string, limit int) ([]models.Shipment, error) {
+	return s.shipmentRepo.List(clientID, status, driverID, limit)
+}
+
+// UpdateShipmentStatus transitions a shipment (admin override of the driver keywords)
+func (s *DeliveryService) UpdateShipmentStatus(shipmentID, clientID, status string) (*models.Shipment, error) {
+	shipment, err := s.shipmentRepo.GetByID(shipmentID)
+	if err != nil || shipment.ClientID.String() != clientID {
+		return nil, errors.New("shipment not found")
+	}
+
+	if err := s.transition(shipment, status); err != nil {
+		return nil, err
+	}
+	return shipment, nil
+}
+
+// HandleDriverReply handles a keyword reply from a driver.
+// Returns true if the sender is a driver and the message was handled.
+func (s *DeliveryService) HandleDriverReply(clientID, phone, message string) bool {
+	driver, err := s.driverRepo.GetByPhone(clientID, normalizePhone(phone))
+	if err != nil {
+		return false
+	}
+
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(message)))
+	if len(fields) == 0 {
+		return false
+	}
+
+	switch fields[0] {
+	case driverKeywordPickup, driverKeywordDeliver, driverKeywordCashCollected:
+	default:
+		// Drivers may also be customers; only keywords are handled here
+		return false
+	}
+
+	reply := s.applyDriverKeyword(driver, fields[0], fields[1:])
+	sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, driver.ClientID).SendMessage(driver.Phone, reply)
+	return true
+}
+
+// applyDriverKeyword transitions the driver's matching shipment and returns the reply for the driver
+func (s *DeliveryService) applyDriverKeyword(driver *models.Driver, keyword string, args []string) string {
+	shipments, err := s.shipmentRepo.ListActiveByDriver(driver.ID)
+	if err != nil {
+		log.Printf("⚠️ Failed to list shipments for driver %s: %v", driver.Name, err)
+		return "❌ Gagal memproses status pengiriman. Silakan coba lagi."
+	}
+
+	// Only shipments that can move to the requested status are candidates
+	from, status := models.ShipmentStatusAssigned, models.ShipmentStatusPickedUp
+	if keyword != driverKeywordPickup {
+		from, status = models.ShipmentStatusPickedUp, models.ShipmentStatusDelivered
+	}
+
+	var candidates []models.Shipment
+	for _, shipment := range shipments {
+		if shipment.Status != from {
+			continue
+		}
+		if keyword == driverKeywordCashCollected && (shipment.Order == nil || !isUnpaidCOD(shipment.Order)) {
+			continue
+		}
+		if len(args) > 0 && shipment.Order != nil &&
+			!strings.EqualFold(strings.TrimPrefix(args[0], "#"), shipment.Order.OrderNumber) {
+			continue
+		}
+		candidates = append(candidates, shipment)
+	}
+
+	switch {
+	case len(candidates) == 0:
+		return "ℹ️ Tidak ada pengiriman yang bisa diperbarui."
+	case len(candidates) > 1:
+		var sb strings.Builder
+		sb.WriteString("📋 Anda memiliki beberapa pengiriman. Sertakan nomor pesanan, contoh:\n")
+		for _, shipment := range candidates {
+			if shipment.Order != nil {
+				sb.WriteString(fmt.Sprintf("\n• %s %s", keyword, shipment.Order.OrderNumber))
+			}
+		}
+		return sb.String()
+	}
+
+	shipment := candidates[0]
+
+	// COD orders are only delivered once the driver confirms the cash
+	if status == models.ShipmentStatusDelivered && shipment.Order != nil && isUnpaidCOD(shipment.Order) {
+		if keyword != driverKeywordCashCollected {
+			return fmt.Sprintf("💵 Pesanan *#%s* dibayar di tempat (COD). Balas *%s %s* setelah menerima uang tunai *Rp %s*.",
+				shipment.Order.OrderNumber, driverKeywordCashCollected, shipment.Order.OrderNumber, formatPrice(shipment.Order.TotalAmount))
+		}
+
+		order, err := s.orderSvc.ConfirmCODCash(shipment.Order.ID.String(), driver.ClientID.String(), "driver: "+driver.Name)
+		if err != nil {
+			log.Printf("⚠️ Failed to confirm COD cash for order %s: %v", shipment.Order.OrderNumber, err)
+			return "❌ Gagal mencatat pembayaran COD. Silakan coba lagi."
+		}
+		shipment.Order = order
+	}
+
+	if err := s.transition(&shipment, status); err != nil {
+		log.Printf("⚠️ Failed to update shipment %s: %v", shipment.ID, err)
+		return "❌ Gagal memproses status pengiriman. Silakan coba lagi."
+	}
+
+	if status == models.ShipmentStatusPickedUp {
+		if isUnpaidCOD(shipment.Order) {
+			return fmt.Sprintf("✅ Pesanan *#%s* dijemput. Tagih *Rp %s* tunai, lalu balas *%s* setelah uang diterima.",
+				shipment.Order.OrderNumber, formatPrice(shipment.Order.TotalAmount), driverKeywordCashCollected)
+		}
+		return fmt.Sprintf("✅ Pesanan *#%s* dijemput. Balas *%s* setelah pesanan diterima pelanggan.", shipment.Order.OrderNumber, driverKeywordDeliver)
+	}
+	return fmt.Sprintf("✅ Pesanan *#%s* selesai diantar. Terima kasih!", shipment.Order.OrderNumber)
+}
+
+// transition moves a shipment to a new status, syncs the order fulfillment status and notifies the customer
+func (s *DeliveryService) transition(shipment *models.Shipment, status string) error {
+	if !isValidShipmentTransition(shipment.Status, status) {
+		return fmt.Errorf("cannot change shipment status from %s to %s", shipment.Status, status)
+	}
+
+	order := shipment.Order
+	if order == nil {
+		var err error
+		order, err = s.orderRepo.GetByID(shipment.OrderID.String())
+		if err != nil {
+			return fmt.Errorf("order not found: %w", err)
+		}
+	}
+
+	now := time.Now()
+	shipment.Status = status
+	switch status {
+	case models.ShipmentStatusPickedUp:
+		shipment.PickedUpAt = &now
+		order.FulfillmentStatus = models.FulfillmentStatusShipped
+		order.ShippedAt = &now
+	case models.ShipmentStatusDelivered:
+		shipment.DeliveredAt = &now
+		order.FulfillmentStatus = models.FulfillmentStatusDelivered
+		order.DeliveredAt = &now
+	case models.ShipmentStatusCancelled:
+		// Order goes back to processing so it can be assigned again
+		order.FulfillmentStatus = models.FulfillmentStatusProcessing
+	}
+
+	if err := s.shipmentRepo.Update(shipment); err != nil {
+		return fmt.Errorf("failed to update shipment: %w", err)
+	}
+	if err := s.orderRepo.Update(order); err != nil {
+		return fmt.Errorf("failed to update order fulfillment: %w", err)
+	}
+	shipment.Order = order
+
+	log.Printf("🛵 Shipment for order %s: %s", order.OrderNumber, status)
+
+	s.notifyCustomer(order, status)
+	return nil
+}
+
+// isValidShipmentTransition checks the shipment lifecycle: assigned -> picked_up -> delivered
+func isValidShipmentTransition(from, to string) bool {
+	switch to {
+	case models.ShipmentStatusPickedUp:
+		return from == models.ShipmentStatusAssigned
+	case models.ShipmentStatusDelivered:
+		return from == models.ShipmentStatusPickedUp
+	case models.ShipmentStatusCancelled:
+		return from == models.ShipmentStatusAssigned || from == models.ShipmentStatusPickedUp
+	}
+	return false
+}
+
+// notifyCustomer tells the customer about a shipment status change
+func (s *DeliveryService) notifyCustomer(order *models.Order, status string) {
+	var message string
+	switch status {
+	case models.ShipmentStatusPickedUp:
+		message = fmt.Sprintf("🛵 *Pesanan Dalam Perjalanan*\n\nNo. Pesanan: *#%s*\n\nPesanan Anda sudah dijemput kurir dan sedang diantar.", order.OrderNumber)
+	case models.ShipmentStatusDelivered:
+		message = fmt.Sprintf("📦 *Pesanan Telah Diterima*\n\nNo. Pesanan: *#%s*\n\nTerima kasih telah berbelanja! 🙏", order.OrderNumber)
+	default:
+		return
+	}
+
+	if err := sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, order.ClientID).SendMessage(order.CustomerPhone, message); err != nil {
+		log.Printf("⚠️ Failed to notify customer %s: %v", order.CustomerPhone, err)
+	}
+}
+
+// sendDriverJob sends the job details (address and items) to the driver
+func (s *DeliveryService) sendDriverJob(shipment *models.Shipment) {
+	order := shipment.Order
+
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		log.Printf("⚠️ Failed to parse items of order %s: %v", order.OrderNumber, err)
+	}
+
+	var itemsText strings.Builder
+	for i, item := range items {
+		itemsText.WriteString(fmt.Sprintf("%d. %s x%d\n", i+1, item.ProductName, item.Quantity))
+	}
+
+	message := fmt.Sprintf(
+		"🛵 *Tugas Pengiriman Baru*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Pelanggan: %s %s\n"+
+			"Alamat: %s\n\n"+
+			"*Barang:*\n%s",
+		order.OrderNumber,
+		order.CustomerName,
+		order.CustomerPhone,
+		shipment.DeliveryAddress,
+		itemsText.String(),
+	)
+	if shipment.Notes != "" {
+		message += fmt.Sprintf("\nCatatan: %s\n", shipment.Notes)
+	}
+	doneKeyword := driverKeywordDeliver
+	if isUnpaidCOD(order) {
+		message += fmt.Sprintf("\n💵 *COD:* tagih *Rp %s* tunai ke pelanggan.\n", formatPrice(order.TotalAmount))
+		doneKeyword = driverKeywordCashCollected
+	}
+	message += fmt.Sprintf("\nBalas *%s %s* saat pesanan dijemput dan *%s %s* setelah diterima pelanggan.",
+		driverKeywordPickup, order.OrderNumber, doneKeyword, order.OrderNumber)
+
+	if err := sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, order.ClientID).SendMessage(shipment.Driver.Phone, message); err != nil {
+		log.Printf("⚠️ Failed to send job to driver %s: %v", shipment.Driver.Name, err)
+	}
+}
+
+// getDriver retrieves a driver by ID, scoped to the client
+func (s *DeliveryService) getDriver(driverID, clientID string) (*models.Driver, error) {
+	driver, err := s.driverRepo.GetByID(driverID)
+	if err != nil || driver.ClientID.String() != clientID {
+		return nil, errors.New("driver not found")
+	}
+	return driver, nil
+}
+
+// resolveBranch validates a branch ID against the client's branches
+func (s *DeliveryService) resolveBranch(clientID uuid.UUID, branchID string) (*uuid.UUID, error) {
+	if s.branchSvc == nil {
+		return nil, errors.New("branches are not configured")
+	}
+
+	branch, err := s.branchSvc.GetBranch(clientID, branchID)
+	if err != nil {
+		return nil, err
+	}
+	return &branch.ID, nil
+}
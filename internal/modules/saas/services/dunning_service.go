@@ -0,0 +1,283 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/notification"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// statementDueAfter is how long a billing statement may stay unpaid after it is issued
+const statementDueAfter = 7 * 24 * time.Hour
+
+// dunningGracePeriod is how long a past-due subscription keeps its plan before it is downgraded
+const dunningGracePeriod = 7 * 24 * time.Hour
+
+// DunningNotifier tells the tenant admin about an overdue statement and the downgrade that follows
+type DunningNotifier interface {
+	NotifyPaymentOverdue(tenantAdmin *notification.AdminContact, statementNumber string, total float64, paymentLink, currentPlan, downgradePlan string, graceEndsAt time.Time) error
+	NotifyPlanDowngraded(tenantAdmin *notification.AdminContact, statementNumber, fromPlan, toPlan string) error
+}
+
+// DunningService follows up unpaid billing statements: a statement still unpaid statementDueAfter after
+// it was issued (its payment link denied, expired or simply ignored) makes the subscription past due,
+// and the client is downgraded to models.DowngradePlan when the grace period ends. Paying or voiding
+// the statement makes the subscription active again; a downgraded client upgrades by itself.
+type DunningService struct {
+	subscriptionRepo    repositories.SubscriptionRepo
+	statementRepo       repositories.BillingStatementRepo
+	clientRepo          repositories.ClientRepo
+	subscriptionService *SubscriptionService
+	auditService        *audit.Service
+	notifier            DunningNotifier // nil when notifications are not configured
+}
+
+func NewDunningService(
+	subscriptionRepo repositories.SubscriptionRepo,
+	statementRepo repositories.BillingStatementRepo,
+	clientRepo repositories.ClientRepo,
+	subscriptionService *SubscriptionService,
+	auditService *audit.Service,
+	notifier DunningNotifier,
+) *DunningService {
+	return &DunningService{
+		subscriptionRepo:    subscriptionRepo,
+		statementRepo:       statementRepo,
+		clientRepo:          clientRepo,
+		subscriptionService: subscriptionService,
+		auditService:        auditService,
+		notifier:            notifier,
+	}
+}
+
+// Status returns the client's subscription standing; clients never past due are active
+func (s *DunningService) Status(clientID uuid.UUID) (*models.TenantSubscription, error) {
+	subscription, err := s.subscriptionRepo.GetTenantSubscription(clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.TenantSubscription{ClientID: clientID, Status: models.SubscriptionActive}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subscription: %w", err)
+	}
+	return subscription, nil
+}
+
+// List returns the subscriptions with a status (past_due, downgraded, active), or all that were ever past due
+func (s *DunningService) List(status string) ([]models.TenantSubscription, error) {
+	subscriptions, err := s.subscriptionRepo.ListTenantSubscriptions(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subscriptions: %w", err)
+	}
+	return subscriptions, nil
+}
+
+// RunDunningJob settles, downgrades and flags past-due subscriptions every interval
+func (s *DunningService) RunDunningJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			s.reactivatePaid()
+			s.downgradeExpired(now)
+			s.flagOverdue(now)
+		}
+	}
+}
+
+// reactivatePaid makes subscriptions active again once their overdue statement is paid or voided
+func (s *DunningService) reactivatePaid() {
+	for _, status := range []string{models.SubscriptionPastDue, models.SubscriptionDowngraded} {
+		subscriptions, err := s.subscriptionRepo.ListTenantSubscriptions(status)
+		if err != nil {
+			log.Printf("⚠️ Failed to list %s subscriptions: %v", status, err)
+			return
+		}
+		for i := range subscriptions {
+			subscription := &subscriptions[i]
+			if subscription.OverdueStatementID != nil {
+				statement, err := s.statementRepo.GetByID(*subscription.OverdueStatementID)
+				if err == nil && statement.Status == models.StatementUnpaid {
+					continue
+				}
+			}
+
+			subscription.Status = models.SubscriptionActive
+			subscription.OverdueStatementID = nil
+			subscription.PastDueSince = nil
+			subscription.GraceEndsAt = nil
+			if err := s.subscriptionRepo.UpsertTenantSubscription(subscription); err != nil {
+				log.Printf("⚠️ Failed to reactivate subscription of client %s: %v", subscription.ClientID, err)
+				continue
+			}
+			log.Printf("✅ Subscription of client %s is active again (was %s)", subscription.ClientID, status)
+		}
+	}
+}
+
+// downgradeExpired moves past-due clients whose grace period ended to the downgrade plan
+func (s *DunningService) downgradeExpired(now time.Time) {
+	subscriptions, err := s.subscriptionRepo.ListTenantSubscriptions(models.SubscriptionPastDue)
+	if err != nil {
+		log.Printf("⚠️ Failed to list past-due subscriptions: %v", err)
+		return
+	}
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		if subscription.GraceEndsAt == nil || subscription.GraceEndsAt.After(now) {
+			continue
+		}
+		if err := s.downgrade(subscription, now); err != nil {
+			log.Printf("⚠️ Failed to downgrade client %s: %v", subscription.ClientID, err)
+		}
+	}
+}
+
+func (s *DunningService) downgrade(subscription *models.TenantSubscription, now time.Time) error {
+	client, err := s.clientRepo.GetByID(subscription.ClientID.String())
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+
+	fromPlan := client.SubscriptionPlan
+	if fromPlan != models.DowngradePlan {
+		if _, err := s.subscriptionService.cancelOpenChanges(client.ID, "subscription downgraded for non-payment"); err != nil {
+			return err
+		}
+		if err := s.clientRepo.UpdateSubscriptionPlan(client.ID, models.DowngradePlan); err != nil {
+			return fmt.Errorf("failed to update client plan: %w", err)
+		}
+	}
+
+	subscription.Status = models.SubscriptionDowngraded
+	subscription.DowngradedFrom = fromPlan
+	subscription.DowngradedAt = &now
+	if err := s.subscriptionRepo.UpsertTenantSubscription(subscription); err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	log.Printf("⬇️ Client %s downgraded from %s to %s for non-payment", client.ID, fromPlan, models.DowngradePlan)
+
+	number := s.statementNumber(subscription.OverdueStatementID)
+	s.recordAudit(client.ID, fromPlan, number)
+	if s.notifier != nil {
+		if err := s.notifier.NotifyPlanDowngraded(tenantAdmin(client), number, fromPlan, models.DowngradePlan); err != nil {
+			log.Printf("⚠️ Failed to send downgrade notice to client %s: %v", client.ID, err)
+		}
+	}
+	return nil
+}
+
+// flagOverdue makes the subscription of every client with an overdue statement past due
+func (s *DunningService) flagOverdue(now time.Time) {
+	statements, err := s.statementRepo.ListUnpaidIssuedBefore(now.Add(-statementDueAfter))
+	if err != nil {
+		log.Printf("⚠️ Failed to list overdue statements: %v", err)
+		return
+	}
+
+	flagged := 0
+	for i := range statements {
+		statement := &statements[i]
+		subscription, err := s.Status(statement.ClientID)
+		if err != nil {
+			log.Printf("⚠️ Failed to get subscription of client %s: %v", statement.ClientID, err)
+			continue
+		}
+		if subscription.Status != models.SubscriptionActive {
+			continue // Already followed up for its oldest overdue statement
+		}
+		if err := s.flag(subscription, statement, now); err != nil {
+			log.Printf("⚠️ Failed to flag client %s past due: %v", statement.ClientID, err)
+			continue
+		}
+		flagged++
+	}
+
+	if flagged > 0 {
+		log.Printf("🧾 %d subscriptions past due", flagged)
+	}
+}
+
+func (s *DunningService) flag(subscription *models.TenantSubscription, statement *models.BillingStatement, now time.Time) error {
+	client, err := s.clientRepo.GetByID(statement.ClientID.String())
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+
+	graceEndsAt := now.Add(dunningGracePeriod)
+	subscription.Status = models.SubscriptionPastDue
+	subscription.OverdueStatementID = &statement.ID
+	subscription.PastDueSince = &now
+	subscription.GraceEndsAt = &graceEndsAt
+	if err := s.subscriptionRepo.UpsertTenantSubscription(subscription); err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	log.Printf("🧾 Client %s past due on %s (Rp %.0f), downgrade on %s", client.ID, statement.Number, statement.Total, graceEndsAt.Format(time.RFC3339))
+
+	if s.notifier != nil {
+		if err := s.notifier.NotifyPaymentOverdue(tenantAdmin(client), statement.Number, statement.Total, statement.PaymentLink, client.SubscriptionPlan, models.DowngradePlan, graceEndsAt); err != nil {
+			log.Printf("⚠️ Failed to send overdue notice to client %s: %v", client.ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *DunningService) statementNumber(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	statement, err := s.statementRepo.GetByID(*id)
+	if err != nil {
+		return id.String()
+	}
+	return statement.Number
+}
+
+// recordAudit writes an audit log entry for a downgrade, logging (not returning) failures
+func (s *DunningService) recordAudit(clientID uuid.UUID, fromPlan, statementNumber string) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := &audit.AuditLog{
+		ClientID:    clientID,
+		Action:      "update",
+		Entity:      "subscription",
+		EntityID:    clientID.String(),
+		Description: fmt.Sprintf("Downgraded from %s to %s for non-payment of %s", fromPlan, models.DowngradePlan, statementNumber),
+	}
+	if data, err := json.Marshal(map[string]interface{}{"plan": fromPlan}); err == nil {
+		entry.OldValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{"plan": models.DowngradePlan}); err == nil {
+		entry.NewValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{"actor": "system", "statement": statementNumber}); err == nil {
+		entry.Metadata = datatypes.JSON(data)
+	}
+
+	if err := s.auditService.Log(context.Background(), entry); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+func tenantAdmin(client *models.Client) *notification.AdminContact {
+	return &notification.AdminContact{
+		Phone: client.WhatsAppNumber,
+		Name:  client.BusinessName,
+	}
+}
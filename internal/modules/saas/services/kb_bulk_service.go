@@ -0,0 +1,457 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+// kbDeleteTokenTTL is how long a bulk delete confirmation token stays valid
+const kbDeleteTokenTTL = 10 * time.Minute
+
+var (
+	// ErrKBConfirmationInvalid is returned when a bulk delete token is missing, forged or expired
+	ErrKBConfirmationInvalid = errors.New("invalid or expired confirmation token")
+	// ErrKBConfirmationStale is returned when the knowledge base changed after the token was issued
+	ErrKBConfirmationStale = errors.New("knowledge base changed since the confirmation token was issued, request a new one")
+)
+
+// KBImportItem is one entry of a knowledge base re-import
+type KBImportItem struct {
+	Title   string                 `json:"title" example:"Cara Order"`
+	Content map[string]interface{} `json:"content" swaggertype:"object"`
+	Tags    []string               `json:"tags,omitempty" example:"order,howto"`
+	Active  *bool                  `json:"is_active,omitempty"` // Defaults to true
+}
+
+// KBImportRequest replaces every entry of one type with the given items
+type KBImportRequest struct {
+	ClientID string         `json:"client_id" example:"7a393015-15b8-4bcf-8ce6-840f753bfb1c"`
+	Type     string         `json:"type" example:"faq"`
+	Items    []KBImportItem `json:"items"`
+}
+
+// KBDiffItem identifies an entry in an import diff
+type KBDiffItem struct {
+	ID    string `json:"id,omitempty"` // Empty for entries not created yet
+	Title string `json:"title"`
+}
+
+// KBImportDuplicate is an added or changed entry that looks like a duplicate of another entry of the import
+type KBImportDuplicate struct {
+	Title       string  `json:"title"`
+	DuplicateOf string  `json:"duplicate_of"`
+	Score       float64 `json:"score"`
+	Method      string  `json:"method"` // exact, fuzzy or embedding
+}
+
+// KBImportDiff lists what a re-import adds, changes and removes
+type KBImportDiff struct {
+	DryRun     bool                `json:"dry_run"`
+	Type       string              `json:"type"`
+	Added      []KBDiffItem        `json:"added"`
+	Changed    []KBDiffItem        `json:"changed"`
+	Removed    []KBDiffItem        `json:"removed"`
+	Unchanged  int                 `json:"unchanged"`
+	Duplicates []KBImportDuplicate `json:"duplicates"` // Flagged for review once applied
+}
+
+// KBDeletePreview is returned by a bulk delete without a confirmation token
+type KBDeletePreview struct {
+	Type              string    `json:"type,omitempty"`
+	Count             int       `json:"count"`
+	ConfirmationToken string    `json:"confirmation_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+// KBBulkService deletes and re-imports knowledge base entries in bulk, keeping the vector index in sync
+type KBBulkService struct {
+	kbRepo          repositories.KBRepo
+	duplicateRepo   repositories.KBDuplicateRepo
+	vectorRetriever *kb.VectorRetriever // Optional, nil when no vector DB is configured
+	tokenSecret     []byte
+}
+
+func NewKBBulkService(kbRepo repositories.KBRepo, duplicateRepo repositories.KBDuplicateRepo, vectorRetriever *kb.VectorRetriever, tokenSecret string) *KBBulkService {
+	return &KBBulkService{
+		kbRepo:          kbRepo,
+		duplicateRepo:   duplicateRepo,
+		vectorRetriever: vectorRetriever,
+		tokenSecret:     []byte(tokenSecret),
+	}
+}
+
+// PreviewDelete counts the entries a bulk delete would remove and issues a confirmation token for them
+func (s *KBBulkService) PreviewDelete(clientID, entryType string) (*KBDeletePreview, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	entries, err := s.kbRepo.ListEntries(clientID, entryType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge base: %w", err)
+	}
+
+	expiresAt := time.Now().Add(kbDeleteTokenTTL).Truncate(time.Second)
+	return &KBDeletePreview{
+		Type:              entryType,
+		Count:             len(entries),
+		ConfirmationToken: s.deleteToken(clientID, entryType, len(entries), expiresAt),
+		ExpiresAt:         expiresAt,
+	}, nil
+}
+
+// BulkDelete removes all of a client's entries (or all of one type) once confirmed with a token from PreviewDelete
+func (s *KBBulkService) BulkDelete(ctx context.Context, clientID, entryType, token string) (int, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return 0, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	// Token format: <expires unix>.<entry count>.<signature>
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return 0, ErrKBConfirmationInvalid
+	}
+	expires, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return 0, ErrKBConfirmationInvalid
+	}
+	count, err := strconv.Atoi(parts[1])
+	if err != nil || !hmac.Equal([]byte(token), []byte(s.deleteToken(clientID, entryType, count, time.Unix(expires, 0)))) {
+		return 0, ErrKBConfirmationInvalid
+	}
+
+	var deleted int
+	err = s.kbRepo.Transaction(func(repo repositories.KBRepo) error {
+		entries, err := repo.ListEntries(clientID, entryType)
+		if err != nil {
+			return fmt.Errorf("failed to list knowledge base: %w", err)
+		}
+		if len(entries) != count {
+			return ErrKBConfirmationStale
+		}
+
+		ids := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+		if _, err := repo.DeleteByIDs(clientID, ids); err != nil {
+			return fmt.Errorf("failed to delete entries: %w", err)
+		}
+
+		if err := s.syncVectors(ctx, nil, entries, nil); err != nil {
+			return err
+		}
+		deleted = len(entries)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("🗑️  Bulk deleted %d KB entries for client %s (type: %q)", deleted, clientID, entryType)
+	return deleted, nil
+}
+
+// Import replaces every entry of one type with the given items, matched by title.
+// With dryRun only the diff is computed; otherwise Postgres and the vector index are updated together.
+// Items are normalized first, and added or changed items resembling another item are reported (and flagged once applied).
+func (s *KBBulkService) Import(ctx context.Context, req *KBImportRequest, dryRun bool) (*KBImportDiff, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	if req.Type == "" {
+		return nil, errors.New("type is required")
+	}
+
+	incoming, err := buildImportEntries(clientID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &KBImportDiff{
+		DryRun:     dryRun,
+		Type:       req.Type,
+		Added:      []KBDiffItem{},
+		Changed:    []KBDiffItem{},
+		Removed:    []KBDiffItem{},
+		Duplicates: []KBImportDuplicate{},
+	}
+	flagged := make(map[int][]kbDuplicateCandidate)
+
+	err = s.kbRepo.Transaction(func(repo repositories.KBRepo) error {
+		existing, err := repo.ListEntries(req.ClientID, req.Type)
+		if err != nil {
+			return fmt.Errorf("failed to list knowledge base: %w", err)
+		}
+
+		byTitle := make(map[string]*models.KnowledgeBaseEntry, len(existing))
+		var added, changed, removed []models.KnowledgeBaseEntry
+		var addedAt []int             // Index in incoming of each added entry
+		touched := make(map[int]bool) // Incoming entries that are added or changed
+		for i := range existing {
+			key := kbTitleKey(existing[i].Title)
+			if _, dup := byTitle[key]; dup {
+				// Duplicate titles can't be matched to an import item, keep only the first
+				removed = append(removed, existing[i])
+				continue
+			}
+			byTitle[key] = &existing[i]
+		}
+
+		for i := range incoming {
+			entry := &incoming[i]
+			key := kbTitleKey(entry.Title)
+			current, ok := byTitle[key]
+			if !ok {
+				added = append(added, *entry)
+				addedAt = append(addedAt, i)
+				touched[i] = true
+				continue
+			}
+			delete(byTitle, key)
+
+			entry.ID = current.ID
+			entry.CreatedAt = current.CreatedAt
+			if kbEntryEqual(current, entry) {
+				diff.Unchanged++
+				continue
+			}
+			changed = append(changed, *entry)
+			touched[i] = true
+		}
+		for i := range existing {
+			if current, ok := byTitle[kbTitleKey(existing[i].Title)]; ok && current.ID == existing[i].ID {
+				removed = append(removed, existing[i])
+			}
+		}
+
+		for _, entry := range changed {
+			diff.Changed = append(diff.Changed, KBDiffItem{ID: entry.ID.String(), Title: entry.Title})
+		}
+		for _, entry := range removed {
+			diff.Removed = append(diff.Removed, KBDiffItem{ID: entry.ID.String(), Title: entry.Title})
+		}
+		flagged = s.findImportDuplicates(ctx, incoming, touched)
+		for i, matches := range flagged {
+			for _, match := range matches {
+				diff.Duplicates = append(diff.Duplicates, KBImportDuplicate{
+					Title:       incoming[i].Title,
+					DuplicateOf: match.entry.Title,
+					Score:       match.score,
+					Method:      match.method,
+				})
+			}
+		}
+		if dryRun {
+			for _, entry := range added {
+				diff.Added = append(diff.Added, KBDiffItem{Title: entry.Title})
+			}
+			return nil
+		}
+
+		for i := range added {
+			if err := repo.Create(&added[i]); err != nil {
+				return fmt.Errorf("failed to create %q: %w", added[i].Title, err)
+			}
+			incoming[addedAt[i]].ID = added[i].ID
+			diff.Added = append(diff.Added, KBDiffItem{ID: added[i].ID.String(), Title: added[i].Title})
+		}
+		for i := range changed {
+			if err := repo.Update(&changed[i]); err != nil {
+				return fmt.Errorf("failed to update %q: %w", changed[i].Title, err)
+			}
+		}
+		removedIDs := make([]uuid.UUID, len(removed))
+		for i, entry := range removed {
+			removedIDs[i] = entry.ID
+		}
+		if _, err := repo.DeleteByIDs(req.ClientID, removedIDs); err != nil {
+			return fmt.Errorf("failed to delete removed entries: %w", err)
+		}
+
+		previous := make(map[uuid.UUID]models.KnowledgeBaseEntry, len(existing))
+		for _, entry := range existing {
+			previous[entry.ID] = entry
+		}
+		return s.syncVectors(ctx, append(added, changed...), removed, previous)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun {
+		log.Printf("📥 KB re-import for client %s (type: %s): %d added, %d changed, %d removed, %d unchanged",
+			req.ClientID, req.Type, len(diff.Added), len(diff.Changed), len(diff.Removed), diff.Unchanged)
+		for i, matches := range flagged {
+			flagKBDuplicates(s.duplicateRepo, &incoming[i], matches)
+		}
+	}
+	return diff, nil
+}
+
+// findImportDuplicates checks each added or changed entry against the other entries the import
+// keeps, reporting a pair once. Embedding similarity only finds entries already indexed and is
+// limited to the first kbDedupMaxEmbeddingChecks entries.
+func (s *KBBulkService) findImportDuplicates(ctx context.Context, incoming []models.KnowledgeBaseEntry, touched map[int]bool) map[int][]kbDuplicateCandidate {
+	result := make(map[int][]kbDuplicateCandidate)
+	checks := 0
+	for i := range incoming {
+		if !touched[i] {
+			continue
+		}
+		candidates := make([]models.KnowledgeBaseEntry, 0, len(incoming))
+		for j := range incoming {
+			// Later added or changed entries are checked against this one in their own turn
+			if j < i || (j > i && !touched[j]) {
+				candidates = append(candidates, incoming[j])
+			}
+		}
+
+		useEmbedding := checks < kbDedupMaxEmbeddingChecks
+		if useEmbedding {
+			checks++
+		}
+		if matches := findKBDuplicates(ctx, s.vectorRetriever, &incoming[i], candidates, useEmbedding); len(matches) > 0 {
+			result[i] = matches
+		}
+	}
+	return result
+}
+
+// syncVectors indexes upserted entries and drops removed ones from the vector index.
+// On failure the vectors already touched are restored from previous so the caller can roll back the DB transaction.
+func (s *KBBulkService) syncVectors(ctx context.Context, upserted, removed []models.KnowledgeBaseEntry, previous map[uuid.UUID]models.KnowledgeBaseEntry) error {
+	if s.vectorRetriever == nil {
+		return nil
+	}
+
+	var done []models.KnowledgeBaseEntry
+	var syncErr error
+	for _, entry := range upserted {
+		if err := s.indexEntry(ctx, &entry); err != nil {
+			syncErr = fmt.Errorf("failed to index %q in vector DB: %w", entry.Title, err)
+			break
+		}
+		done = append(done, entry)
+	}
+	if syncErr == nil {
+		for _, entry := range removed {
+			if err := s.vectorRetriever.DeleteDocument(ctx, entry.ClientID.String(), entry.Type, entry.ID.String()); err != nil {
+				syncErr = fmt.Errorf("failed to remove %q from vector DB: %w", entry.Title, err)
+				break
+			}
+			done = append(done, entry)
+		}
+	}
+	if syncErr == nil {
+		return nil
+	}
+
+	// Put back what the DB will still hold after rollback
+	for _, entry := range done {
+		var err error
+		if old, ok := previous[entry.ID]; ok {
+			err = s.indexEntry(ctx, &old)
+		} else if slices.ContainsFunc(removed, func(e models.KnowledgeBaseEntry) bool { return e.ID == entry.ID }) {
+			err = s.indexEntry(ctx, &entry)
+		} else {
+			err = s.vectorRetriever.DeleteDocument(ctx, entry.ClientID.String(), entry.Type, entry.ID.String())
+		}
+		if err != nil {
+			log.Printf("⚠️ Failed to restore vector for KB entry %s: %v", entry.ID, err)
+		}
+	}
+	return syncErr
+}
+
+// indexEntry writes an entry to the vector index, using the FAQ and product layouts where they apply
+func (s *KBBulkService) indexEntry(ctx context.Context, entry *models.KnowledgeBaseEntry) error {
+	return s.vectorRetriever.IndexEntry(ctx, entry)
+}
+
+// deleteToken signs the scope and size of a bulk delete so it can only be confirmed as previewed
+func (s *KBBulkService) deleteToken(clientID, entryType string, count int, expiresAt time.Time) string {
+	mac := hmac.New(sha256.New, s.tokenSecret)
+	fmt.Fprintf(mac, "kb-delete|%s|%s|%d|%d", clientID, entryType, count, expiresAt.Unix())
+	return fmt.Sprintf("%d.%d.%s", expiresAt.Unix(), count, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// buildImportEntries validates import items and converts them to entries
+func buildImportEntries(clientID uuid.UUID, req *KBImportRequest) ([]models.KnowledgeBaseEntry, error) {
+	entries := make([]models.KnowledgeBaseEntry, 0, len(req.Items))
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		title := NormalizeKBText(item.Title)
+		if title == "" {
+			return nil, fmt.Errorf("item %d: title is required", i+1)
+		}
+		if len(item.Content) == 0 {
+			return nil, fmt.Errorf("item %d: content is required", i+1)
+		}
+		key := kbTitleKey(title)
+		if seen[key] {
+			return nil, fmt.Errorf("item %d: duplicate title %q", i+1, title)
+		}
+		seen[key] = true
+
+		content, err := json.Marshal(item.Content)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: invalid content: %w", i+1, err)
+		}
+		tags := item.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		active := item.Active == nil || *item.Active
+		entry := models.KnowledgeBaseEntry{
+			ClientID: clientID,
+			Type:     req.Type,
+			Title:    title,
+			Content:  datatypes.JSON(content),
+			Tags:     pq.StringArray(tags),
+			IsActive: active,
+		}
+		NormalizeKBEntry(&entry)
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// kbTitleKey matches entries by title regardless of case, spacing and punctuation normalization
+func kbTitleKey(title string) string {
+	return strings.ToLower(strings.Join(strings.Fields(NormalizeKBText(title)), " "))
+}
+
+// kbEntryEqual reports whether an import item leaves an existing entry as it is
+func kbEntryEqual(current, incoming *models.KnowledgeBaseEntry) bool {
+	if current.IsActive != incoming.IsActive || current.Title != incoming.Title || !slices.Equal(current.Tags, incoming.Tags) {
+		return false
+	}
+
+	// Compare content semantically, Postgres jsonb doesn't keep key order or spacing
+	var a, b interface{}
+	if json.Unmarshal(current.Content, &a) != nil || json.Unmarshal(incoming.Content, &b) != nil {
+		return false
+	}
+	aJSON, _ := json.Marshal(a)
+	bJSON, _ := json.Marshal(b)
+	return string(aJSON) == string(bJSON)
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+const (
+	// kbFuzzyDuplicateScore is the text similarity (word overlap or edit distance) that makes two entries duplicates
+	kbFuzzyDuplicateScore = 0.85
+	// kbEmbeddingDuplicateScore is the vector similarity that makes two entries duplicates
+	kbEmbeddingDuplicateScore = 0.92
+	// kbDedupMaxEmbeddingChecks caps the vector searches (one embedding call each) of one import
+	kbDedupMaxEmbeddingChecks = 100
+)
+
+// KB duplicate handling when adding a single entry
+const (
+	KBOnDuplicateFlag  = "flag"  // Create the entry and flag it for review
+	KBOnDuplicateMerge = "merge" // Update the closest existing entry instead
+)
+
+// ErrKBDuplicateNotFound is returned when a flagged duplicate doesn't exist or belongs to another client
+var ErrKBDuplicateNotFound = errors.New("duplicate not found")
+
+var (
+	kbTypographyReplacer = strings.NewReplacer(
+		"\u201C", "\"", "\u201D", "\"", "\u2018", "'", "\u2019", "'",
+		"\u2026", "...", "\u00A0", " ", "\u200B", "",
+	)
+	kbSpaceBeforePunctuation = regexp.MustCompile(` +([?!.,:;])`)
+	kbRepeatedQuestionMarks  = regexp.MustCompile(`\?{2,}`)
+	kbRepeatedExclamations   = regexp.MustCompile(`!{2,}`)
+	kbBlankLines             = regexp.MustCompile(`\n{3,}`)
+)
+
+// KBAddResult is the outcome of adding a single knowledge base entry
+type KBAddResult struct {
+	ID         string                    `json:"id"`
+	Merged     bool                      `json:"merged"` // The entry updated an existing duplicate instead of being created
+	Duplicates []models.KBDuplicateMatch `json:"duplicates"`
+}
+
+// KBDedupService normalizes knowledge base entries on write and detects near-duplicates of
+// existing entries (fuzzy text match, plus embedding similarity when a vector DB is configured)
+type KBDedupService struct {
+	kbRepo          repositories.KBRepo
+	duplicateRepo   repositories.KBDuplicateRepo
+	bulkService     *KBBulkService      // Vector indexing of merged entries
+	vectorRetriever *kb.VectorRetriever // Optional, nil when no vector DB is configured
+}
+
+func NewKBDedupService(kbRepo repositories.KBRepo, duplicateRepo repositories.KBDuplicateRepo, bulkService *KBBulkService, vectorRetriever *kb.VectorRetriever) *KBDedupService {
+	return &KBDedupService{
+		kbRepo:          kbRepo,
+		duplicateRepo:   duplicateRepo,
+		bulkService:     bulkService,
+		vectorRetriever: vectorRetriever,
+	}
+}
+
+// AddEntry normalizes and stores a new entry. A near-duplicate of an existing entry is flagged,
+// or with KBOnDuplicateMerge merged into the closest one (content keys overwritten, tags combined)
+func (s *KBDedupService) AddEntry(ctx context.Context, entry *models.KnowledgeBaseEntry, onDuplicate string) (*KBAddResult, error) {
+	if onDuplicate == "" {
+		onDuplicate = KBOnDuplicateFlag
+	}
+	if onDuplicate != KBOnDuplicateFlag && onDuplicate != KBOnDuplicateMerge {
+		return nil, fmt.Errorf("on_duplicate must be %s or %s", KBOnDuplicateFlag, KBOnDuplicateMerge)
+	}
+
+	NormalizeKBEntry(entry)
+
+	existing, err := s.kbRepo.ListEntries(entry.ClientID.String(), entry.Type)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list knowledge base: %w", err)
+	}
+	matches := findKBDuplicates(ctx, s.vectorRetriever, entry, existing, true)
+	result := &KBAddResult{Duplicates: kbDuplicateMatches(matches)}
+
+	if onDuplicate == KBOnDuplicateMerge && len(matches) > 0 {
+		target := matches[0].entry
+		mergeKBEntry(target, entry)
+		if err := s.kbRepo.Update(target); err != nil {
+			return nil, fmt.Errorf("failed to merge into %q: %w", target.Title, err)
+		}
+		if s.vectorRetriever != nil {
+			if err := s.bulkService.indexEntry(ctx, target); err != nil {
+				log.Printf("⚠️ Failed to re-index KB entry %s: %v", target.ID, err)
+			}
+		}
+		log.Printf("🔀 Merged new KB entry %q into duplicate %q for client %s", entry.Title, target.Title, entry.ClientID)
+		result.ID = target.ID.String()
+		result.Merged = true
+		return result, nil
+	}
+
+	if err := s.kbRepo.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to create knowledge base entry: %w", err)
+	}
+	flagKBDuplicates(s.duplicateRepo, entry, matches)
+	result.ID = entry.ID.String()
+	return result, nil
+}
+
+// ListDuplicates returns the client's flagged duplicates, optionally of one status
+func (s *KBDedupService) ListDuplicates(clientID uuid.UUID, status string) ([]models.KBDuplicate, error) {
+	return s.duplicateRepo.List(clientID, status)
+}
+
+// MergeDuplicate resolves a flagged pair by deleting the later entry, adding its tags to the one kept
+func (s *KBDedupService) MergeDuplicate(ctx context.Context, clientID uuid.UUID, duplicateID string) (*models.KBDuplicate, error) {
+	duplicate, err := s.openDuplicate(clientID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.kbRepo.Transaction(func(repo repositories.KBRepo) error {
+		entry, err := repo.GetEntry(clientID.String(), duplicate.EntryID)
+		if err != nil {
+			return fmt.Errorf("entry %q no longer exists: %w", duplicate.EntryTitle, err)
+		}
+		kept, err := repo.GetEntry(clientID.String(), duplicate.DuplicateOfID)
+		if err != nil {
+			return fmt.Errorf("entry %q no longer exists: %w", duplicate.DuplicateOfTitle, err)
+		}
+
+		kept.Tags = mergeKBTags(kept.Tags, entry.Tags)
+		if err := repo.Update(kept); err != nil {
+			return fmt.Errorf("failed to update %q: %w", kept.Title, err)
+		}
+		if _, err := repo.DeleteByIDs(clientID.String(), []uuid.UUID{entry.ID}); err != nil {
+			return fmt.Errorf("failed to delete %q: %w", entry.Title, err)
+		}
+		if s.vectorRetriever != nil {
+			if err := s.vectorRetriever.DeleteDocument(ctx, clientID.String(), entry.Type, entry.ID.String()); err != nil {
+				return fmt.Errorf("failed to remove %q from vector DB: %w", entry.Title, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔀 Merged KB duplicate %q into %q for client %s", duplicate.EntryTitle, duplicate.DuplicateOfTitle, clientID)
+	return s.resolve(duplicate, models.KBDuplicateMerged)
+}
+
+// DismissDuplicate marks a flagged pair as not being duplicates
+func (s *KBDedupService) DismissDuplicate(clientID uuid.UUID, duplicateID string) (*models.KBDuplicate, error) {
+	duplicate, err := s.openDuplicate(clientID, duplicateID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(duplicate, models.KBDuplicateDismissed)
+}
+
+func (s *KBDedupService) openDuplicate(clientID uuid.UUID, duplicateID string) (*models.KBDuplicate, error) {
+	duplicate, err := s.duplicateRepo.GetByID(clientID, duplicateID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKBDuplicateNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if duplicate.Status != models.KBDuplicateOpen {
+		return nil, fmt.Errorf("duplicate already %s", duplicate.Status)
+	}
+	return duplicate, nil
+}
+
+func (s *KBDedupService) resolve(duplicate *models.KBDuplicate, status string) (*models.KBDuplicate, error) {
+	now := time.Now()
+	duplicate.Status = status
+	duplicate.ResolvedAt = &now
+	if err := s.duplicateRepo.Update(duplicate); err != nil {
+		return nil, err
+	}
+	return duplicate, nil
+}
+
+// flagKBDuplicates records an entry's duplicates for review, logging (not returning) failures
+func flagKBDuplicates(duplicateRepo repositories.KBDuplicateRepo, entry *models.KnowledgeBaseEntry, matches []kbDuplicateCandidate) {
+	for _, match := range matches {
+		duplicate := &models.KBDuplicate{
+			ClientID:         entry.ClientID,
+			Type:             entry.Type,
+			EntryID:          entry.ID,
+			EntryTitle:       entry.Title,
+			DuplicateOfID:    match.entry.ID,
+			DuplicateOfTitle: match.entry.Title,
+			Score:            match.score,
+			Method:           match.method,
+			Status:           models.KBDuplicateOpen,
+		}
+		if err := duplicateRepo.Create(duplicate); err != nil {
+			log.Printf("⚠️ Failed to flag KB duplicate %q of %q: %v", entry.Title, match.entry.Title, err)
+		}
+	}
+	if len(matches) > 0 {
+		log.Printf("👯 KB entry %q for client %s flagged as duplicate of %d entries", entry.Title, entry.ClientID, len(matches))
+	}
+}
+
+// kbDuplicateCandidate is an entry found to duplicate the entry being written
+type kbDuplicateCandidate struct {
+	entry  *models.KnowledgeBaseEntry
+	score  float64
+	method string
+}
+
+// findKBDuplicates compares an entry with candidates of the same type, closest first. Embedding
+// similarity is only used when asked for and a vector DB is configured; it finds candidates
+// already indexed
+func findKBDuplicates(ctx context.Context, vectorRetriever *kb.VectorRetriever, entry *models.KnowledgeBaseEntry, candidates []models.KnowledgeBaseEntry, useEmbedding bool) []kbDuplicateCandidate {
+	byID := make(map[string]*models.KnowledgeBaseEntry, len(candidates))
+	found := make(map[*models.KnowledgeBaseEntry]kbDuplicateCandidate)
+
+	text := kbDedupText(entry)
+	for i := range candidates {
+		candidate := &candidates[i]
+		if candidate.ID == entry.ID && entry.ID != uuid.Nil {
+			continue
+		}
+		byID[candidate.ID.String()] = candidate
+		if score, method := kbTextSimilarity(text, kbDedupText(candidate)); score >= kbFuzzyDuplicateScore {
+			found[candidate] = kbDuplicateCandidate{entry: candidate, score: score, method: method}
+		}
+	}
+
+	if useEmbedding && vectorRetriever != nil {
+		results, err := vectorRetriever.SearchByType(ctx, entry.ClientID.String(), kbEmbeddingText(entry), entry.Type, 3)
+		if err != nil {
+			log.Printf("⚠️ KB duplicate check skipped embeddings for %q: %v", entry.Title, err)
+		}
+		for _, result := range results {
+			candidate, ok := byID[result.DocID]
+			score := float64(result.Score)
+			if !ok || score < kbEmbeddingDuplicateScore {
+				continue
+			}
+			if current, seen := found[candidate]; !seen || current.score < score {
+				found[candidate] = kbDuplicateCandidate{entry: candidate, score: score, method: models.KBDuplicateEmbedding}
+			}
+		}
+	}
+
+	matches := make([]kbDuplicateCandidate, 0, len(found))
+	for _, match := range found {
+		matches = append(matches, match)
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].score > matches[j].score
+	})
+	return matches
+}
+
+func kbDuplicateMatches(matches []kbDuplicateCandidate) []models.KBDuplicateMatch {
+	result := make([]models.KBDuplicateMatch, 0, len(matches))
+	for _, match := range matches {
+		item := models.KBDuplicateMatch{Title: match.entry.Title, Score: match.score, Method: match.method}
+		if match.entry.ID != uuid.Nil {
+			item.ID = match.entry.ID.String()
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// kbDedupText is the text compared for duplicates: the question of an FAQ, the name of a product, otherwise the title
+func kbDedupText(entry *models.KnowledgeBaseEntry) string {
+	var content map[string]interface{}
+	_ = json.Unmarshal(entry.Content, &content)
+
+	field := ""
+	switch entry.Type {
+	case "faq":
+		field = "question"
+	case "product":
+		field = "name"
+	}
+	if text, ok := content[field].(string); ok && text != "" {
+		return text
+	}
+	return entry.Title
+}
+
+// kbEmbeddingText is the query text of the vector search, laid out like the indexed documents
+func kbEmbeddingText(entry *models.KnowledgeBaseEntry) string {
+	var content map[string]interface{}
+	_ = json.Unmarshal(entry.Content, &content)
+
+	switch entry.Type {
+	case "faq":
+		answer, _ := content["answer"].(string)
+		return fmt.Sprintf("Q: %s\nA: %s", kbDedupText(entry), answer)
+	case "product":
+		description, _ := content["description"].(string)
+		price, _ := content["price"].(float64)
+		return fmt.Sprintf("Product: %s\nDescription: %s\nPrice: %.2f", kbDedupText(entry), description, price)
+	default:
+		return entry.Title + "\n" + string(entry.Content)
+	}
+}
+
+// kbTextSimilarity scores two texts 0-1 ignoring case and punctuation: 1 when equal, otherwise
+// the higher of word overlap (reordered wording) and edit distance (typos)
+func kbTextSimilarity(a, b string) (float64, string) {
+	keyA, keyB := normalizeMentionText(a), normalizeMentionText(b)
+	if keyA == "" || keyB == "" {
+		return 0, ""
+	}
+	if keyA == keyB {
+		return 1, models.KBDuplicateExact
+	}
+
+	score := jaccard(kbQuestionWords(keyA), kbQuestionWords(keyB))
+	if ratio := editSimilarity(keyA, keyB); ratio > score {
+		score = ratio
+	}
+	return score, models.KBDuplicateFuzzy
+}
+
+// editSimilarity is 1 minus the Levenshtein distance relative to the longer text
+func editSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) < len(rb) {
+		ra, rb = rb, ra
+	}
+	if len(ra) == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return 1 - float64(prev[len(rb)])/float64(len(ra))
+}
+
+// NormalizeKBEntry tidies an entry before it is stored and embedded: the title and text fields of
+// the content are normalized (see NormalizeKBText), FAQ questions and answers start with a capital,
+// and tags are trimmed, lowercased and deduplicated
+func NormalizeKBEntry(entry *models.KnowledgeBaseEntry) {
+	entry.Title = NormalizeKBText(entry.Title)
+
+	var content map[string]interface{}
+	if err := json.Unmarshal(entry.Content, &content); err == nil && content != nil {
+		for key, value := range content {
+			text, ok := value.(string)
+			if !ok {
+				continue
+			}
+			text = NormalizeKBText(text)
+			if entry.Type == "faq" && (key == "question" || key == "answer") {
+				text = capitalizeFirst(text)
+			}
+			content[key] = text
+		}
+		if data, err := json.Marshal(content); err == nil {
+			entry.Content = datatypes.JSON(data)
+		}
+	}
+
+	tags := make(pq.StringArray, 0, len(entry.Tags))
+	for _, tag := range entry.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag != "" && !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	entry.Tags = tags
+}
+
+// NormalizeKBText trims text, collapses spaces (keeping line breaks), straightens typographic
+// quotes and removes spaces before and repeats of punctuation ("Bisa COD ??" -> "Bisa COD?")
+func NormalizeKBText(text string) string {
+	text = kbTypographyReplacer.Replace(strings.ReplaceAll(text, "\r\n", "\n"))
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	text = strings.TrimSpace(strings.Join(lines, "\n"))
+
+	text = kbBlankLines.ReplaceAllString(text, "\n\n")
+	text = kbSpaceBeforePunctuation.ReplaceAllString(text, "$1")
+	text = kbRepeatedQuestionMarks.ReplaceAllString(text, "?")
+	text = kbRepeatedExclamations.ReplaceAllString(text, "!")
+	return text
+}
+
+func capitalizeFirst(text string) string {
+	r, size := utf8.DecodeRuneInString(text)
+	if size == 0 || !unicode.IsLower(r) {
+		return text
+	}
+	return string(unicode.ToUpper(r)) + text[size:]
+}
+
+// mergeKBEntry folds a new entry into an existing duplicate: content keys of the new entry
+// overwrite, tags are combined, and the entry is made active
+func mergeKBEntry(target, incoming *models.KnowledgeBaseEntry) {
+	var content, update map[string]interface{}
+	_ = json.Unmarshal(target.Content, &content)
+	_ = json.Unmarshal(incoming.Content, &update)
+	if content == nil {
+		content = map[string]interface{}{}
+	}
+	for key, value := range update {
+		content[key] = value
+	}
+	if data, err := json.Marshal(content); err == nil {
+		target.Content = datatypes.JSON(data)
+	}
+	target.Tags = mergeKBTags(target.Tags, incoming.Tags)
+	target.IsActive = true
+}
+
+func mergeKBTags(a, b pq.StringArray) pq.StringArray {
+	tags := append(pq.StringArray{}, a...)
+	for _, tag := range b {
+		if !slices.Contains(tags, tag) {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxKBDocumentSize is the largest document accepted for ingestion
+const MaxKBDocumentSize = 20 * 1024 * 1024
+
+const (
+	kbDocumentIndexTimeout   = 10 * time.Minute
+	kbDocumentSearchTimeout  = 3 * time.Second
+	kbDocumentContextResults = 4
+	kbDocumentMinScore       = 0.5 // Passages scoring lower are not relevant enough to quote
+)
+
+var (
+	ErrKBDocumentNotFound   = errors.New("knowledge base document not found")
+	ErrKBDocumentProcessing = errors.New("document is still being indexed, try again when it is ready")
+	ErrKBDocumentTooLarge   = fmt.Errorf("document exceeds maximum size of %dMB", MaxKBDocumentSize/1024/1024)
+	ErrVectorSearchDisabled = errors.New("vector DB is disabled, documents cannot be indexed")
+)
+
+// KBDocumentService ingests uploaded documents into the vector knowledge base: the text is extracted,
+// split into overlapping chunks and embedded, and the chunks most relevant to a question are quoted to the LLM
+type KBDocumentService struct {
+	documentRepo    repositories.KBDocumentRepo
+	vectorRetriever *kb.VectorRetriever // nil when the vector DB is disabled
+}
+
+// NewKBDocumentService creates a new knowledge base document service
+func NewKBDocumentService(documentRepo repositories.KBDocumentRepo, vectorRetriever *kb.VectorRetriever) *KBDocumentService {
+	return &KBDocumentService{
+		documentRepo:    documentRepo,
+		vectorRetriever: vectorRetriever,
+	}
+}
+
+// Upload extracts and chunks a document, then indexes the chunks in the background.
+// The returned document is "processing" until the chunks are stored, then "ready" or "failed".
+func (s *KBDocumentService) Upload(clientID uuid.UUID, filename string, data []byte) (*models.KBDocument, error) {
+	if s.vectorRetriever == nil {
+		return nil, ErrVectorSearchDisabled
+	}
+	if len(data) > MaxKBDocumentSize {
+		return nil, ErrKBDocumentTooLarge
+	}
+
+	format, err := kb.DetectDocumentFormat(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := kb.ExtractText(format, data)
+	if err != nil {
+		return nil, err
+	}
+	chunks := kb.ChunkText(text, kb.DefaultChunkSize, kb.DefaultChunkOverlap)
+
+	name := strings.TrimSpace(filepath.Base(filename))
+	if len([]rune(name)) > 255 {
+		name = string([]rune(name)[:255])
+	}
+	document := &models.KBDocument{
+		ClientID:   clientID,
+		Name:       name,
+		Format:     format,
+		SizeBytes:  int64(len(data)),
+		Characters: len([]rune(text)),
+		ChunkCount: len(chunks),
+		Status:     models.KBDocumentProcessing,
+	}
+	if err := s.documentRepo.Create(document); err != nil {
+		return nil, fmt.Errorf("failed to save document: %w", err)
+	}
+
+	indexed := *document
+	go s.index(&indexed, chunks)
+
+	return document, nil
+}
+
+// index embeds and stores a document's chunks, removing the ones already stored when it fails
+func (s *KBDocumentService) index(document *models.KBDocument, chunks []kb.DocumentChunk) {
+	ctx, cancel := context.WithTimeout(context.Background(), kbDocumentIndexTimeout)
+	defer cancel()
+
+	clientID, documentID := document.ClientID.String(), document.ID.String()
+	err := s.vectorRetriever.AddDocumentChunks(ctx, clientID, documentID, document.Name, chunks)
+	if err != nil {
+		log.Printf("❌ Failed to index document %s (%s): %v", documentID, document.Name, err)
+		cleanupCtx, cleanupCancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cleanupCancel()
+		if delErr := s.vectorRetriever.DeleteDocumentChunks(cleanupCtx, clientID, documentID, len(chunks)); delErr != nil {
+			log.Printf("⚠️ Failed to remove chunks of document %s: %v", documentID, delErr)
+		}
+		document.Status = models.KBDocumentFailed
+		document.Error = err.Error()
+	} else {
+		log.Printf("✅ Indexed document %s (%s): %d chunks", documentID, document.Name, len(chunks))
+		document.Status = models.KBDocumentReady
+	}
+
+	if err := s.documentRepo.Update(document); err != nil {
+		log.Printf("⚠️ Failed to update document %s status: %v", documentID, err)
+	}
+}
+
+// List returns the client's documents newest first
+func (s *KBDocumentService) List(clientID uuid.UUID) ([]models.KBDocument, error) {
+	return s.documentRepo.ListByClient(clientID)
+}
+
+// Get returns one of the client's documents
+func (s *KBDocumentService) Get(clientID, id uuid.UUID) (*models.KBDocument, error) {
+	document, err := s.documentRepo.GetByID(clientID, id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrKBDocumentNotFound
+	}
+	return document, err
+}
+
+// Delete removes a document and its chunks from the vector DB
+func (s *KBDocumentService) Delete(ctx context.Context, clientID, id uuid.UUID) error {
+	document, err := s.Get(clientID, id)
+	if err != nil {
+		return err
+	}
+	if document.Status == models.KBDocumentProcessing {
+		return ErrKBDocumentProcessing
+	}
+
+	if document.Status == models.KBDocumentReady && s.vectorRetriever != nil {
+		if err := s.vectorRetriever.DeleteDocumentChunks(ctx, clientID.String(), id.String(), document.ChunkCount); err != nil {
+			return fmt.Errorf("failed to remove document chunks: %w", err)
+		}
+	}
+	return s.documentRepo.Delete(clientID, id)
+}
+
+// PromptContext returns the passages of the client's documents relevant to a customer message, as a system
+// prompt section. Empty when the client has no indexed document or nothing relevant is found.
+func (s *KBDocumentService) PromptContext(ctx context.Context, clientID uuid.UUID, message string) string {
+	if s.vectorRetriever == nil {
+		return ""
+	}
+	if ok, err := s.documentRepo.HasReady(clientID); err != nil || !ok {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kbDocumentSearchTimeout)
+	defer cancel()
+
+	passages, err := s.vectorRetriever.GetDocumentContext(ctx, clientID.String(), message, kbDocumentContextResults, kbDocumentMinScore)
+	if err != nil {
+		log.Printf("⚠️ Failed to search documents for client %s: %v", clientID, err)
+		return ""
+	}
+	if passages == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n=== KUTIPAN DOKUMEN ===\n")
+	sb.WriteString("Kutipan dari dokumen bisnis yang relevan dengan pertanyaan customer. Gunakan untuk menjawab bila sesuai:\n\n")
+	sb.WriteString(passages)
+	return sb.String()
+}
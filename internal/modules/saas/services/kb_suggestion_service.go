@@ -0,0 +1,436 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"gorm.io/datatypes"
+)
+
+const (
+	// kbSuggestionInterval is how often the suggestion job clusters new gaps
+	kbSuggestionInterval = 7 * 24 * time.Hour
+	// kbGapLookback ignores gaps older than this
+	kbGapLookback = 30 * 24 * time.Hour
+	// kbClusterMinSize is how many similar questions make a suggestion worthwhile
+	kbClusterMinSize = 2
+	// kbClusterSimilarity is the word overlap (Jaccard) for two questions to share a cluster
+	kbClusterSimilarity = 0.5
+	// kbSuggestionsPerRun caps LLM drafts per client per run
+	kbSuggestionsPerRun = 10
+	// kbLowRating is the highest rating that counts as a bad answer
+	kbLowRating = 2
+)
+
+// unansweredPhrases mark a bot reply that admits it does not know the answer
+var unansweredPhrases = []string{
+	"tidak memiliki informasi",
+	"tidak punya informasi",
+	"belum memiliki informasi",
+	"tidak ada informasi",
+	"informasi tersebut tidak tersedia",
+	"tidak dapat menemukan",
+	"saya tidak tahu",
+	"belum bisa menjawab",
+	"tidak bisa menjawab",
+	"i don't know",
+	"i don't have information",
+}
+
+// kbStopwords are ignored when comparing questions
+var kbStopwords = map[string]bool{
+	"apa": true, "apakah": true, "yang": true, "di": true, "ke": true, "dari": true, "dan": true,
+	"ini": true, "itu": true, "ada": true, "saya": true, "aku": true, "kak": true, "kakak": true,
+	"min": true, "admin": true, "gak": true, "ga": true, "nggak": true, "bisa": true, "nya": true,
+	"dong": true, "kah": true, "mau": true, "tanya": true, "boleh": true, "untuk": true, "dengan": true,
+	"berapa": true, "gimana": true, "bagaimana": true, "kalau": true, "kalo": true, "ya": true, "halo": true,
+}
+
+// KBSuggestionService turns unanswered and low-rated questions into FAQ suggestions for admin approval
+type KBSuggestionService struct {
+	suggestionRepo   repositories.KBSuggestionRepo
+	kbRepo           repositories.KBRepo
+	conversationRepo repositories.ConversationRepo
+	kbRetriever      *kb.Retriever
+	vectorRetriever  *kb.VectorRetriever // Optional, re-indexes accepted entries
+	llmService       *llm.Service
+}
+
+func NewKBSuggestionService(
+	suggestionRepo repositories.KBSuggestionRepo,
+	kbRepo repositories.KBRepo,
+	conversationRepo repositories.ConversationRepo,
+	kbRetriever *kb.Retriever,
+	vectorRetriever *kb.VectorRetriever,
+	llmService *llm.Service,
+) *KBSuggestionService {
+	return &KBSuggestionService{
+		suggestionRepo:   suggestionRepo,
+		kbRepo:           kbRepo,
+		conversationRepo: conversationRepo,
+		kbRetriever:      kbRetriever,
+		vectorRetriever:  vectorRetriever,
+		llmService:       llmService,
+	}
+}
+
+// IsUnansweredResponse reports whether the bot's reply admits it could not answer
+func IsUnansweredResponse(response string) bool {
+	lower := strings.ToLower(response)
+	for _, phrase := range unansweredPhrases {
+		if strings.Contains(lower, phrase) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordUnanswered logs a question the bot could not answer in the KB gap log
+func (s *KBSuggestionService) RecordUnanswered(clientID uuid.UUID, customerPhone, question, response string) {
+	gap := &models.KBGap{
+		ClientID:      clientID,
+		CustomerPhone: customerPhone,
+		Question:      question,
+		AIResponse:    response,
+		Reason:        models.KBGapReasonUnanswered,
+	}
+	if err := s.suggestionRepo.CreateGap(gap); err != nil {
+		log.Printf("⚠️ Failed to record KB gap: %v", err)
+		return
+	}
+	log.Printf("🕳️ KB gap recorded for client %s: %q", clientID, question)
+}
+
+// RateAnswer stores the rating of a bot answer; low ratings go to the KB gap log
+func (s *KBSuggestionService) RateAnswer(clientID, conversationID string, rating int) (*models.Conversation, error) {
+	if rating < 1 || rating > 5 {
+		return nil, errors.New("rating must be between 1 and 5")
+	}
+
+	conversation, err := s.conversationRepo.GetByID(conversationID)
+	if err != nil || conversation.ClientID.String() != clientID {
+		return nil, errors.New("conversation not found")
+	}
+
+	if err := s.conversationRepo.SetRating(conversationID, rating); err != nil {
+		return nil, fmt.Errorf("failed to save rating: %w", err)
+	}
+
+	if rating <= kbLowRating && conversation.MessageText != "" {
+		gap := &models.KBGap{
+			ClientID:       conversation.ClientID,
+			CustomerPhone:  conversation.CustomerPhone,
+			Question:       conversation.MessageText,
+			AIResponse:     conversation.AIResponse,
+			Reason:         models.KBGapReasonLowRating,
+			ConversationID: &conversation.ID,
+		}
+		if err := s.suggestionRepo.CreateGap(gap); err != nil {
+			log.Printf("⚠️ Failed to record low-rated answer as KB gap: %v", err)
+		}
+	}
+
+	now := time.Now()
+	conversation.Rating = &rating
+	conversation.RatedAt = &now
+	return conversation, nil
+}
+
+// RunWeeklyJob generates suggestions for every client with new gaps once per interval until ctx is done
+func (s *KBSuggestionService) RunWeeklyJob(ctx context.Context) {
+	ticker := time.NewTicker(kbSuggestionInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.generateForAllClients(ctx)
+		}
+	}
+}
+
+func (s *KBSuggestionService) generateForAllClients(ctx context.Context) {
+	clientIDs, err := s.suggestionRepo.ListClientsWithGaps(time.Now().Add(-kbGapLookback))
+	if err != nil {
+		log.Printf("⚠️ Failed to list clients with KB gaps: %v", err)
+		return
+	}
+
+	for _, clientID := range clientIDs {
+		if _, err := s.GenerateSuggestions(ctx, clientID); err != nil {
+			log.Printf("⚠️ KB suggestions for client %s: %v", clientID, err)
+		}
+	}
+}
+
+// GenerateSuggestions clusters the client's unprocessed gaps and drafts an FAQ entry for each cluster
+func (s *KBSuggestionService) GenerateSuggestions(ctx context.Context, clientID uuid.UUID) ([]models.KBSuggestion, error) {
+	gaps, err := s.suggestionRepo.ListUnprocessedGaps(clientID, time.Now().Add(-kbGapLookback))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KB gaps: %w", err)
+	}
+
+	clusters := clusterKBGaps(gaps)
+	businessContext := s.businessContext(clientID)
+
+	suggestions := []models.KBSuggestion{}
+	for _, cluster := range clusters {
+		if len(suggestions) >= kbSuggestionsPerRun {
+			break
+		}
+		if len(cluster) < kbClusterMinSize {
+			continue
+		}
+
+		questions := make([]string, 0, len(cluster))
+		gapIDs := make([]uuid.UUID, 0, len(cluster))
+		for _, gap := range cluster {
+			questions = append(questions, gap.Question)
+			gapIDs = append(gapIDs, gap.ID)
+		}
+
+		question, answer, err := s.draftFAQ(llm.WithUsage(ctx, clientID.String(), models.UsageFeatureKBSuggestion), businessContext, questions)
+		if err != nil {
+			// Gaps stay unprocessed and are retried next run
+			log.Printf("⚠️ Failed to draft FAQ for %q: %v", questions[0], err)
+			continue
+		}
+
+		samples, _ := json.Marshal(questions)
+		suggestion := models.KBSuggestion{
+			ClientID:        clientID,
+			Question:        question,
+			Answer:          answer,
+			SampleQuestions: datatypes.JSON(samples),
+			Occurrences:     len(cluster),
+			Status:          models.KBSuggestionStatusPending,
+		}
+		if err := s.suggestionRepo.Create(&suggestion); err != nil {
+			return suggestions, fmt.Errorf("failed to save suggestion: %w", err)
+		}
+		if err := s.suggestionRepo.AssignGaps(gapIDs, suggestion.ID); err != nil {
+			log.Printf("⚠️ Failed to link KB gaps to suggestion %s: %v", suggestion.ID, err)
+		}
+
+		suggestions = append(suggestions, suggestion)
+	}
+
+	if len(suggestions) > 0 {
+		log.Printf("💡 Drafted %d KB suggestion(s) for client %s", len(suggestions), clientID)
+	}
+	return suggestions, nil
+}
+
+// ListSuggestions lists a client's suggestions, most frequent first
+func (s *KBSuggestionService) ListSuggestions(clientID uuid.UUID, status string, limit int) ([]models.KBSuggestion, error) {
+	return s.suggestionRepo.List(clientID, status, limit)
+}
+
+// AcceptSuggestion writes the (optionally edited) suggestion to the KB as an FAQ and re-indexes it
+func (s *KBSuggestionService) AcceptSuggestion(ctx context.Context, clientID uuid.UUID, suggestionID string, req *models.AcceptKBSuggestionRequest) (*models.KBSuggestion, error) {
+	suggestion, err := s.pendingSuggestion(clientID, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Question != "" {
+		suggestion.Question = req.Question
+	}
+	if req.Answer != "" {
+		suggestion.Answer = req.Answer
+	}
+
+	tags := req.Tags
+	if len(tags) == 0 {
+		tags = []string{"suggested"}
+	}
+
+	// Store and embed the same normalized text as every other KB write
+	suggestion.Question = capitalizeFirst(NormalizeKBText(suggestion.Question))
+	suggestion.Answer = capitalizeFirst(NormalizeKBText(suggestion.Answer))
+
+	content, _ := json.Marshal(map[string]string{
+		"question": suggestion.Question,
+		"answer":   suggestion.Answer,
+	})
+	entry := &models.KnowledgeBaseEntry{
+		ClientID: clientID,
+		Type:     "faq",
+		Title:    suggestion.Question,
+		Content:  datatypes.JSON(content),
+		Tags:     pq.StringArray(tags),
+		IsActive: true,
+	}
+	NormalizeKBEntry(entry)
+	if err := s.kbRepo.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to create KB entry: %w", err)
+	}
+
+	if s.vectorRetriever != nil {
+		if err := s.vectorRetriever.AddFAQ(ctx, clientID.String(), entry.ID.String(), suggestion.Question, suggestion.Answer); err != nil {
+			log.Printf("⚠️ Failed to index FAQ %s in vector DB: %v", entry.ID, err)
+		}
+	}
+
+	now := time.Now()
+	suggestion.Status = models.KBSuggestionStatusAccepted
+	suggestion.KBEntryID = &entry.ID
+	suggestion.ReviewedAt = &now
+	if err := s.suggestionRepo.Update(suggestion); err != nil {
+		return nil, fmt.Errorf("failed to update suggestion: %w", err)
+	}
+
+	log.Printf("✅ KB suggestion accepted: %q", suggestion.Question)
+	return suggestion, nil
+}
+
+// RejectSuggestion dismisses a suggestion
+func (s *KBSuggestionService) RejectSuggestion(clientID uuid.UUID, suggestionID string) (*models.KBSuggestion, error) {
+	suggestion, err := s.pendingSuggestion(clientID, suggestionID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	suggestion.Status = models.KBSuggestionStatusRejected
+	suggestion.ReviewedAt = &now
+	if err := s.suggestionRepo.Update(suggestion); err != nil {
+		return nil, fmt.Errorf("failed to update suggestion: %w", err)
+	}
+	return suggestion, nil
+}
+
+func (s *KBSuggestionService) pendingSuggestion(clientID uuid.UUID, suggestionID string) (*models.KBSuggestion, error) {
+	suggestion, err := s.suggestionRepo.GetByID(suggestionID)
+	if err != nil || suggestion.ClientID != clientID {
+		return nil, errors.New("suggestion not found")
+	}
+	if suggestion.Status != models.KBSuggestionStatusPending {
+		return nil, fmt.Errorf("suggestion already %s", suggestion.Status)
+	}
+	return suggestion, nil
+}
+
+// businessContext summarizes the client's KB for the drafting prompt
+func (s *KBSuggestionService) businessContext(clientID uuid.UUID) string {
+	knowledgeBase, err := s.kbRetriever.GetKnowledgeBase(clientID.String())
+	if err != nil {
+		return ""
+	}
+	return llm.BuildSystemPrompt(knowledgeBase)
+}
+
+// draftFAQ asks the LLM for one FAQ entry answering a cluster of similar questions
+func (s *KBSuggestionService) draftFAQ(ctx context.Context, businessContext string, questions []string) (string, string, error) {
+	systemPrompt := `You write FAQ entries for an Indonesian business's WhatsApp assistant.
+Given similar customer questions the assistant could not answer well, write ONE FAQ entry in Indonesian.
+Use only facts from the business information. Where a fact is unknown, write a placeholder like [isi: jam buka] for the admin to fill in.
+Return ONLY JSON: {"question": "...", "answer": "..."}
+
+Business information:
+` + businessContext
+
+	userPrompt := "Customer questions:\n- " + strings.Join(questions, "\n- ")
+
+	response, err := s.llmService.GenerateResponse(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		return "", "", err
+	}
+
+	cleaned := strings.TrimSpace(response)
+	cleaned = strings.TrimPrefix(cleaned, "```json")
+	cleaned = strings.TrimPrefix(cleaned, "```")
+	cleaned = strings.TrimSuffix(cleaned, "```")
+
+	var faq struct {
+		Question string `json:"question"`
+		Answer   string `json:"answer"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(cleaned)), &faq); err != nil {
+		return "", "", fmt.Errorf("invalid LLM response: %w", err)
+	}
+	if faq.Question == "" || faq.Answer == "" {
+		return "", "", errors.New("LLM returned an empty FAQ")
+	}
+	return faq.Question, faq.Answer, nil
+}
+
+// clusterKBGaps groups gaps with overlapping question words, largest cluster first
+func clusterKBGaps(gaps []models.KBGap) [][]models.KBGap {
+	type cluster struct {
+		words map[string]bool
+		gaps  []models.KBGap
+	}
+
+	var clusters []*cluster
+	for _, gap := range gaps {
+		words := kbQuestionWords(gap.Question)
+		if len(words) == 0 {
+			continue
+		}
+
+		var best *cluster
+		bestScore := 0.0
+		for _, c := range clusters {
+			if score := jaccard(words, c.words); score >= kbClusterSimilarity && score > bestScore {
+				best, bestScore = c, score
+			}
+		}
+
+		if best == nil {
+			clusters = append(clusters, &cluster{words: words, gaps: []models.KBGap{gap}})
+			continue
+		}
+		best.gaps = append(best.gaps, gap)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].gaps) > len(clusters[j].gaps)
+	})
+
+	result := make([][]models.KBGap, 0, len(clusters))
+	for _, c := range clusters {
+		result = append(result, c.gaps)
+	}
+	return result
+}
+
+// kbQuestionWords returns the meaningful words of a question
+func kbQuestionWords(question string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range strings.Fields(normalizeMentionText(question)) {
+		if len(word) < 3 || kbStopwords[word] {
+			continue
+		}
+		words[word] = true
+	}
+	return words
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	intersection := 0
+	for word := range a {
+		if b[word] {
+			intersection++
+		}
+	}
+	union := len(a) + len(b) - intersection
+	if union == 0 {
+		return 0
+	}
+	return float64(intersection) / float64(union)
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	kbSyncBatchSize  = 100
+	kbSyncMaxBackoff = time.Hour
+)
+
+// KBSyncService keeps the vector DB consistent with the knowledge base in Postgres. Every insert, update and
+// delete of an entry is queued by a database trigger; the worker applies the queue under the entries' stable IDs,
+// so repeated syncs update vectors instead of duplicating them, and failed changes are retried with backoff.
+type KBSyncService struct {
+	syncRepo        repositories.KBSyncRepo
+	vectorRetriever *kb.VectorRetriever // nil when the vector DB is disabled
+}
+
+// NewKBSyncService creates a new knowledge base sync service
+func NewKBSyncService(syncRepo repositories.KBSyncRepo, vectorRetriever *kb.VectorRetriever) *KBSyncService {
+	return &KBSyncService{
+		syncRepo:        syncRepo,
+		vectorRetriever: vectorRetriever,
+	}
+}
+
+// RunSyncWorker applies queued knowledge base changes to the vector DB periodically
+func (s *KBSyncService) RunSyncWorker(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := s.Sync(ctx, nil, false)
+			if err != nil {
+				log.Printf("⚠️ KB sync failed: %v", err)
+			} else if result.Indexed+result.Removed+result.Failed > 0 {
+				log.Printf("🔄 KB sync: %d indexed, %d removed, %d failed, %d pending", result.Indexed, result.Removed, result.Failed, result.Pending)
+			}
+		}
+	}
+}
+
+// Sync applies the due queued changes of one client (or all clients when nil) until the queue is drained.
+// full first queues every entry of the client, e.g. to repair a vector index that drifted or was rebuilt.
+func (s *KBSyncService) Sync(ctx context.Context, clientID *uuid.UUID, full bool) (*models.KBSyncResult, error) {
+	if s.vectorRetriever == nil {
+		return nil, ErrVectorSearchDisabled
+	}
+
+	result := &models.KBSyncResult{}
+	if full && clientID != nil {
+		enqueued, err := s.syncRepo.EnqueueAll(*clientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to queue entries: %w", err)
+		}
+		result.Enqueued = int(enqueued)
+	}
+
+	// Failed changes are pushed into the future and synced ones dequeued, so each pass sees new changes.
+	// A change seen twice changed again during this run or could not be dequeued; it waits for the next run.
+	seen := make(map[int64]bool)
+	for ctx.Err() == nil {
+		items, err := s.syncRepo.ListDue(clientID, kbSyncBatchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list queued changes: %w", err)
+		}
+		items = slices.DeleteFunc(items, func(item models.KBSyncOutboxItem) bool { return seen[item.ID] })
+		if len(items) == 0 {
+			break
+		}
+		for _, item := range items {
+			seen[item.ID] = true
+		}
+		if err := s.syncBatch(ctx, items, result); err != nil {
+			return nil, err
+		}
+	}
+
+	pending, err := s.syncRepo.CountPending(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count queued changes: %w", err)
+	}
+	result.Pending = pending
+	return result, nil
+}
+
+// syncBatch applies one batch of queued changes against the entries' current state
+func (s *KBSyncService) syncBatch(ctx context.Context, items []models.KBSyncOutboxItem, result *models.KBSyncResult) error {
+	ids := make([]uuid.UUID, 0, len(items))
+	for _, item := range items {
+		if item.Operation == models.KBSyncUpsert {
+			ids = append(ids, item.EntryID)
+		}
+	}
+	entries, err := s.syncRepo.GetEntries(ids)
+	if err != nil {
+		return fmt.Errorf("failed to load entries: %w", err)
+	}
+	current := make(map[uuid.UUID]*models.KnowledgeBaseEntry, len(entries))
+	for i := range entries {
+		current[entries[i].ID] = &entries[i]
+	}
+
+	for i := range items {
+		item := &items[i]
+		entry := current[item.EntryID]
+
+		// The entry may have been deleted, retyped or deactivated since the change was queued
+		var err error
+		removed := true
+		if item.Operation == models.KBSyncUpsert && entry != nil && entry.Type == item.EntryType && entry.IsActive {
+			err = s.vectorRetriever.IndexEntry(ctx, entry)
+			removed = false
+		} else {
+			err = s.vectorRetriever.DeleteDocument(ctx, item.ClientID.String(), item.EntryType, item.EntryID.String())
+		}
+
+		if err != nil {
+			result.Failed++
+			if failErr := s.syncRepo.Fail(item, err.Error(), kbSyncBackoff(item.Attempts)); failErr != nil {
+				log.Printf("⚠️ Failed to record KB sync error for entry %s: %v", item.EntryID, failErr)
+			}
+			continue
+		}
+
+		if removed {
+			result.Removed++
+		} else {
+			result.Indexed++
+		}
+		if err := s.syncRepo.Complete(item); err != nil {
+			log.Printf("⚠️ Failed to dequeue KB sync of entry %s: %v", item.EntryID, err)
+		}
+	}
+	return nil
+}
+
+// kbSyncBackoff doubles the retry delay from a minute up to an hour
+func kbSyncBackoff(attempts int) time.Duration {
+	delay := time.Minute
+	for i := 0; i < attempts && delay < kbSyncMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > kbSyncMaxBackoff {
+		delay = kbSyncMaxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// defaultReplyLanguage is the language of the system prompt and, unless configured, the knowledge base
+const defaultReplyLanguage = "id"
+
+// LanguageService detects the language customers write in and keeps the bot's replies in it
+type LanguageService struct {
+	settingsRepo     repositories.LanguageSettingsRepo
+	conversationRepo repositories.ConversationRepo
+	llmService       *llm.Service
+}
+
+// NewLanguageService creates a new language service
+func NewLanguageService(settingsRepo repositories.LanguageSettingsRepo, conversationRepo repositories.ConversationRepo, llmService *llm.Service) *LanguageService {
+	return &LanguageService{
+		settingsRepo:     settingsRepo,
+		conversationRepo: conversationRepo,
+		llmService:       llmService,
+	}
+}
+
+// ReplyLanguage picks the language to answer a message in: the detected language when matching is enabled
+// and it is allowed, otherwise the language the customer chose (if allowed), otherwise the tenant default.
+// Also returns the detected language of the message (empty when unsure).
+func (s *LanguageService) ReplyLanguage(clientID, message, preferred string) (string, string) {
+	detected := llm.DetectLanguage(message)
+	settings := s.getSettings(clientID)
+	allowed := allowedLanguages(settings)
+
+	reply := settings.DefaultLanguage
+	if preferred != "" && (!settings.Enabled || slices.Contains(allowed, preferred)) {
+		reply = preferred
+	}
+	if settings.Enabled && detected != "" && slices.Contains(allowed, detected) {
+		reply = detected
+	}
+	return reply, detected
+}
+
+// MatchResponse translates the LLM's reply when it came back in another language than requested.
+// Only done when the tenant enabled post-processing; the original reply is kept on any failure.
+func (s *LanguageService) MatchResponse(ctx context.Context, clientID, response, lang string) string {
+	if lang == "" || s.llmService == nil {
+		return response
+	}
+
+	settings := s.getSettings(clientID)
+	if !settings.Enabled || !settings.PostProcess {
+		return response
+	}
+
+	got := llm.DetectLanguage(response)
+	if got == "" || got == lang {
+		return response
+	}
+
+	translated, err := s.llmService.GenerateResponse(llm.WithUsage(ctx, clientID, models.UsageFeatureTranslation), llm.BuildTranslationPrompt(lang), response)
+	if err != nil || strings.TrimSpace(translated) == "" {
+		log.Printf("⚠️ Failed to translate reply to %s: %v", lang, err)
+		return response
+	}
+
+	log.Printf("🌐 Reply translated from %s to %s for client %s", got, lang, clientID)
+	return strings.TrimSpace(translated)
+}
+
+// getSettings returns the tenant's language settings, falling back to defaults (matching disabled)
+func (s *LanguageService) getSettings(clientID string) *models.LanguageSettings {
+	if s.settingsRepo != nil {
+		settings, err := s.settingsRepo.GetByClientID(clientID)
+		if err == nil {
+			if settings.DefaultLanguage == "" {
+				settings.DefaultLanguage = defaultReplyLanguage
+			}
+			return settings
+		}
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	return &models.LanguageSettings{
+		ClientID:         uid,
+		DefaultLanguage:  defaultReplyLanguage,
+		AllowedLanguages: datatypes.JSON(`["id","en"]`),
+	}
+}
+
+// allowedLanguages returns the languages the bot may switch to
+func allowedLanguages(settings *models.LanguageSettings) []string {
+	var languages []string
+	if len(settings.AllowedLanguages) > 0 {
+		if err := json.Unmarshal(settings.AllowedLanguages, &languages); err != nil {
+			log.Printf("⚠️ Invalid allowed languages for client %s: %v", settings.ClientID, err)
+		}
+	}
+	return languages
+}
+
+// supportedLanguageCodes lists the language codes the detector knows, for error messages
+func supportedLanguageCodes() string {
+	codes := make([]string, 0, len(llm.Languages))
+	for code := range llm.Languages {
+		codes = append(codes, code)
+	}
+	slices.Sort(codes)
+	return strings.Join(codes, ", ")
+}
+
+// GetSettings returns the language settings configured for a client
+func (s *LanguageService) GetSettings(clientID string) (*models.LanguageSettings, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.getSettings(clientID), nil
+}
+
+// UpdateSettings validates and saves the language settings for a client
+func (s *LanguageService) UpdateSettings(clientID string, req *models.UpdateLanguageSettingsRequest) (*models.LanguageSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	defaultLang := strings.ToLower(strings.TrimSpace(req.DefaultLanguage))
+	if defaultLang == "" {
+		defaultLang = defaultReplyLanguage
+	}
+	if _, ok := llm.Languages[defaultLang]; !ok {
+		return nil, fmt.Errorf("unsupported default_language %q (supported: %s)", defaultLang, supportedLanguageCodes())
+	}
+
+	languages := []string{defaultLang}
+	for _, lang := range req.AllowedLanguages {
+		lang = strings.ToLower(strings.TrimSpace(lang))
+		if _, ok := llm.Languages[lang]; !ok {
+			return nil, fmt.Errorf("unsupported language %q (supported: %s)", lang, supportedLanguageCodes())
+		}
+		if !slices.Contains(languages, lang) {
+			languages = append(languages, lang)
+		}
+	}
+
+	languagesJSON, err := json.Marshal(languages)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &models.LanguageSettings{
+		ClientID:         uid,
+		Enabled:          req.Enabled,
+		DefaultLanguage:  defaultLang,
+		AllowedLanguages: datatypes.JSON(languagesJSON),
+		PostProcess:      req.PostProcess,
+	}
+	if err := s.settingsRepo.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save language settings: %w", err)
+	}
+
+	return s.getSettings(clientID), nil
+}
+
+// GetLanguageReport breaks a client's conversations down by the language customers wrote in
+func (s *LanguageService) GetLanguageReport(clientID uuid.UUID, period string) (*models.LanguageReport, error) {
+	if period == "" {
+		period = "last_30_days"
+	}
+	dateRange := analytics.GetDateRange(period)
+
+	counts, err := s.conversationRepo.CountByLanguage(clientID.String(), dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count conversations by language: %w", err)
+	}
+	if counts == nil {
+		counts = []models.LanguageCount{}
+	}
+
+	return &models.LanguageReport{
+		Period:    period,
+		Start:     dateRange.Start,
+		End:       dateRange.End,
+		Languages: counts,
+	}, nil
+}
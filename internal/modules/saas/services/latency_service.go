@@ -0,0 +1,144 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// Default latency budget, used until a client saves its own
+const (
+	defaultInterimAfterSeconds = 8
+	defaultHardTimeoutSeconds  = 20
+	defaultInterimMessage      = "Sebentar ya, saya cek dulu 🙏"
+	defaultHandoverMessage     = "Maaf, jawabannya agak lama. Tim kami akan segera membalas pesan Anda 🙏"
+)
+
+// maxHardTimeoutSeconds keeps the fallback within the 30 second budget of a message
+const maxHardTimeoutSeconds = 25
+
+// faqFallbackSimilarity is the minimum word overlap for a FAQ answer to stand in for the LLM
+const faqFallbackSimilarity = 0.3
+
+// LatencyService manages each client's reply latency budget
+type LatencyService struct {
+	settingsRepo repositories.LatencySettingsRepo
+}
+
+// NewLatencyService creates a new latency service
+func NewLatencyService(settingsRepo repositories.LatencySettingsRepo) *LatencyService {
+	return &LatencyService{
+		settingsRepo: settingsRepo,
+	}
+}
+
+// Settings returns the client's latency budget, falling back to the defaults
+func (s *LatencyService) Settings(clientID string) *models.LatencySettings {
+	if s.settingsRepo != nil {
+		if settings, err := s.settingsRepo.GetByClientID(clientID); err == nil {
+			applyLatencyDefaults(settings)
+			return settings
+		}
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	settings := &models.LatencySettings{
+		ClientID:            uid,
+		Enabled:             true,
+		InterimAfterSeconds: defaultInterimAfterSeconds,
+		HardTimeoutSeconds:  defaultHardTimeoutSeconds,
+	}
+	applyLatencyDefaults(settings)
+	return settings
+}
+
+// applyLatencyDefaults fills the messages and fallback left empty
+func applyLatencyDefaults(settings *models.LatencySettings) {
+	if settings.InterimMessage == "" {
+		settings.InterimMessage = defaultInterimMessage
+	}
+	if settings.HandoverMessage == "" {
+		settings.HandoverMessage = defaultHandoverMessage
+	}
+	if settings.Fallback == "" {
+		settings.Fallback = models.LatencyFallbackKB
+	}
+	if settings.HardTimeoutSeconds <= 0 {
+		settings.HardTimeoutSeconds = defaultHardTimeoutSeconds
+	}
+}
+
+// GetSettings returns the latency budget configured for a client
+func (s *LatencyService) GetSettings(clientID string) (*models.LatencySettings, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.Settings(clientID), nil
+}
+
+// UpdateSettings validates and saves the latency budget for a client
+func (s *LatencyService) UpdateSettings(clientID string, req *models.UpdateLatencySettingsRequest) (*models.LatencySettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if req.HardTimeoutSeconds < 1 || req.HardTimeoutSeconds > maxHardTimeoutSeconds {
+		return nil, fmt.Errorf("hard_timeout_seconds must be between 1 and %d", maxHardTimeoutSeconds)
+	}
+	if req.InterimAfterSeconds < 0 || (req.InterimAfterSeconds > 0 && req.InterimAfterSeconds >= req.HardTimeoutSeconds) {
+		return nil, fmt.Errorf("interim_after_seconds must be 0 (off) or less than hard_timeout_seconds")
+	}
+
+	fallback := strings.ToLower(strings.TrimSpace(req.Fallback))
+	if fallback == "" {
+		fallback = models.LatencyFallbackKB
+	}
+	if fallback != models.LatencyFallbackKB && fallback != models.LatencyFallbackHandover {
+		return nil, fmt.Errorf("fallback must be %q or %q", models.LatencyFallbackKB, models.LatencyFallbackHandover)
+	}
+
+	settings := &models.LatencySettings{
+		ClientID:            uid,
+		Enabled:             req.Enabled,
+		InterimAfterSeconds: req.InterimAfterSeconds,
+		InterimMessage:      strings.TrimSpace(req.InterimMessage),
+		HardTimeoutSeconds:  req.HardTimeoutSeconds,
+		Fallback:            fallback,
+		HandoverMessage:     strings.TrimSpace(req.HandoverMessage),
+		HandoverTag:         NormalizeTagName(req.HandoverTag),
+	}
+	if err := s.settingsRepo.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save latency settings: %w", err)
+	}
+
+	return s.Settings(clientID), nil
+}
+
+// FAQAnswer returns the answer of the FAQ whose question best matches the message,
+// to answer without the LLM
+func FAQAnswer(kb *llm.KnowledgeBase, message string) (string, bool) {
+	if kb == nil {
+		return "", false
+	}
+
+	words := kbQuestionWords(message)
+	if len(words) == 0 {
+		return "", false
+	}
+
+	best, bestScore := "", 0.0
+	for _, faq := range kb.FAQs {
+		if strings.TrimSpace(faq.Answer) == "" {
+			continue
+		}
+		if score := jaccard(words, kbQuestionWords(faq.Question)); score >= faqFallbackSimilarity && score > bestScore {
+			best, bestScore = faq.Answer, score
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// llmBenchmarkTimeout bounds a whole benchmark run; targets run in parallel, their cases one by one
+const llmBenchmarkTimeout = 5 * time.Minute
+
+// ErrLLMBenchmarkRunning is returned when a benchmark is started while another one runs
+var ErrLLMBenchmarkRunning = errors.New("a benchmark is already running")
+
+// LLMBenchmarkRun is the outcome of benchmarking several providers/models on the same suite
+type LLMBenchmarkRun struct {
+	RunID          uuid.UUID             `json:"run_id"`
+	StartedAt      time.Time             `json:"started_at"`
+	DurationMs     int64                 `json:"duration_ms"`
+	Results        []llm.BenchmarkResult `json:"results"`                  // Healthiest first
+	Recommendation *LLMRecommendation    `json:"recommendation,omitempty"` // Nil when no target answered reliably
+}
+
+// LLMRecommendation is the provider/model a benchmark run suggests
+type LLMRecommendation struct {
+	Target      llm.BenchmarkTarget `json:"target"`
+	HealthScore float64             `json:"health_score"`
+	Reason      string              `json:"reason"`
+}
+
+// LLMBenchmarkService runs the standard prompt suite against the configured LLM providers and keeps the results
+type LLMBenchmarkService struct {
+	repo       repositories.LLMBenchmarkRepo
+	baseConfig *llm.ProviderConfig // API keys, temperature and token limit of the production provider
+	running    sync.Mutex
+}
+
+func NewLLMBenchmarkService(repo repositories.LLMBenchmarkRepo, baseConfig *llm.ProviderConfig) *LLMBenchmarkService {
+	return &LLMBenchmarkService{
+		repo:       repo,
+		baseConfig: baseConfig,
+	}
+}
+
+// Targets lists the providers/models a benchmark without explicit targets runs against
+func (s *LLMBenchmarkService) Targets() []llm.BenchmarkTarget {
+	return llm.BenchmarkTargets(s.baseConfig)
+}
+
+// Run benchmarks the given targets, or every configured one, and stores the results.
+// Only one run at a time: each costs real API calls.
+func (s *LLMBenchmarkService) Run(ctx context.Context, targets []llm.BenchmarkTarget) (*LLMBenchmarkRun, error) {
+	if !s.running.TryLock() {
+		return nil, ErrLLMBenchmarkRunning
+	}
+	defer s.running.Unlock()
+
+	if len(targets) == 0 {
+		targets = s.Targets()
+	}
+	if len(targets) == 0 {
+		return nil, errors.New("no LLM provider is configured")
+	}
+
+	providers := make([]llm.LLMProvider, len(targets))
+	for i, target := range targets {
+		if target.Model == "" {
+			target.Model = llm.DefaultModel(target.Provider)
+			targets[i] = target
+		}
+		if !llm.ProviderHasKey(s.baseConfig, target.Provider) {
+			return nil, fmt.Errorf("%s: provider is not configured", target)
+		}
+		cfg := *s.baseConfig
+		cfg.Type = target.Provider
+		cfg.Model = target.Model
+		provider, err := llm.NewProvider(&cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", target, err)
+		}
+		providers[i] = provider
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, llmBenchmarkTimeout)
+	defer cancel()
+
+	run := &LLMBenchmarkRun{RunID: uuid.New(), StartedAt: time.Now()}
+	log.Printf("🏁 LLM benchmark %s started: %d targets", run.RunID, len(targets))
+
+	suite := llm.BenchmarkSuite()
+	prices := llm.BenchmarkPrices()
+	results := make([]llm.BenchmarkResult, len(targets))
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = llm.RunBenchmark(ctx, providers[i], targets[i], suite, prices)
+		}(i)
+	}
+	wg.Wait()
+
+	recommended := llm.ScoreBenchmarks(results)
+	if recommended >= 0 {
+		best := results[recommended]
+		run.Recommendation = &LLMRecommendation{
+			Target:      best.Target,
+			HealthScore: best.HealthScore,
+			Reason: fmt.Sprintf("Highest health score: quality %.0f%%, %.0f%% answered, avg latency %dms, ~$%.4f per 1000 replies",
+				best.Quality*100, best.SuccessRate*100, best.AvgLatencyMs, best.CostPer1KUSD),
+		}
+		if !best.PriceKnown {
+			run.Recommendation.Reason += " (price unknown, set LLM_BENCHMARK_PRICES)"
+		}
+	}
+
+	rows := make([]models.LLMBenchmark, len(results))
+	for i, result := range results {
+		cases, _ := json.Marshal(result.Cases)
+		rows[i] = models.LLMBenchmark{
+			RunID:            run.RunID,
+			Provider:         string(result.Target.Provider),
+			Model:            result.Target.Model,
+			SuccessRate:      result.SuccessRate,
+			AvgLatencyMs:     result.AvgLatencyMs,
+			P95LatencyMs:     result.P95LatencyMs,
+			Quality:          result.Quality,
+			EstimatedCostUSD: result.EstimatedCostUSD,
+			CostPer1KUSD:     result.CostPer1KUSD,
+			PriceKnown:       result.PriceKnown,
+			HealthScore:      result.HealthScore,
+			Recommended:      i == recommended,
+			Cases:            datatypes.JSON(cases),
+		}
+	}
+	if err := s.repo.CreateRun(rows); err != nil {
+		// The run is still worth returning, it cost API calls
+		log.Printf("⚠️ Failed to store LLM benchmark %s: %v", run.RunID, err)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].HealthScore > results[j].HealthScore
+	})
+	run.Results = results
+	run.DurationMs = time.Since(run.StartedAt).Milliseconds()
+
+	if run.Recommendation != nil {
+		log.Printf("🏁 LLM benchmark %s done in %dms, recommended: %s (health %.1f)",
+			run.RunID, run.DurationMs, run.Recommendation.Target, run.Recommendation.HealthScore)
+	} else {
+		log.Printf("🏁 LLM benchmark %s done in %dms, no target answered reliably", run.RunID, run.DurationMs)
+	}
+	return run, nil
+}
+
+// History returns stored benchmark results newest first, optionally of one provider and model
+func (s *LLMBenchmarkService) History(provider, model string, limit int) ([]models.LLMBenchmark, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.List(provider, model, limit)
+}
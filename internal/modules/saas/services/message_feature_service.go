@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// maxDisabledReplyLength keeps a disabled reply within one WhatsApp message
+const maxDisabledReplyLength = 1000
+
+// MessageFeatureService manages which inbound message types each client's bot handles
+type MessageFeatureService struct {
+	repo repositories.MessageFeatureRepo
+}
+
+// NewMessageFeatureService creates a new message feature service
+func NewMessageFeatureService(repo repositories.MessageFeatureRepo) *MessageFeatureService {
+	return &MessageFeatureService{repo: repo}
+}
+
+// Settings returns the client's toggles, falling back to handling everything
+func (s *MessageFeatureService) Settings(clientID uuid.UUID) *models.MessageFeatureSettings {
+	settings, err := s.repo.GetSettings(clientID.String())
+	if err == nil {
+		return settings
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("⚠️ Failed to load message feature settings for client %s: %v", clientID, err)
+	}
+	return models.DefaultMessageFeatureSettings(clientID)
+}
+
+// GetSettings returns the message type toggles configured for a client
+func (s *MessageFeatureService) GetSettings(clientID string) (*models.MessageFeatureSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.Settings(uid), nil
+}
+
+// UpdateSettings validates and saves the message type toggles for a client
+func (s *MessageFeatureService) UpdateSettings(clientID string, req *models.UpdateMessageFeatureSettingsRequest) (*models.MessageFeatureSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	settings := &models.MessageFeatureSettings{
+		ClientID:              uid,
+		TextEnabled:           req.TextEnabled,
+		ImageEnabled:          req.ImageEnabled,
+		VoiceEnabled:          req.VoiceEnabled,
+		LocationEnabled:       req.LocationEnabled,
+		GroupEnabled:          req.GroupEnabled,
+		TextDisabledReply:     strings.TrimSpace(req.TextDisabledReply),
+		ImageDisabledReply:    strings.TrimSpace(req.ImageDisabledReply),
+		VoiceDisabledReply:    strings.TrimSpace(req.VoiceDisabledReply),
+		LocationDisabledReply: strings.TrimSpace(req.LocationDisabledReply),
+	}
+	for _, reply := range []string{settings.TextDisabledReply, settings.ImageDisabledReply, settings.VoiceDisabledReply, settings.LocationDisabledReply} {
+		if len([]rune(reply)) > maxDisabledReplyLength {
+			return nil, fmt.Errorf("disabled replies must be at most %d characters", maxDisabledReplyLength)
+		}
+	}
+
+	if err := s.repo.UpsertSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to save message feature settings: %w", err)
+	}
+	return s.Settings(uid), nil
+}
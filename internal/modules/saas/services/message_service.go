@@ -22,7 +22,6 @@ type MessageService struct {
 	kbRetriever *kb.Retriever
 	clientRepo  repositories.ClientRepo
 	convRepo    repositories.ConversationRepo
-	creditsRepo *repositories.CreditsRepo
 }
 
 var lastMessageTime = make(map[string]time.Time)
@@ -51,7 +50,6 @@ func NewMessageService(
 	kbRetriever *kb.Retriever,
 	client repositories.ClientRepo,
 	conv repositories.ConversationRepo,
-	credits *repositories.CreditsRepo,
 ) *MessageService {
 	return &MessageService{
 		waService:   wa,
@@ -59,7 +57,6 @@ func NewMessageService(
 		kbRetriever: kbRetriever,
 		clientRepo:  client,
 		convRepo:    conv,
-		creditsRepo: credits,
 	}
 }
 
@@ -118,11 +115,8 @@ func (s *MessageService) HandleIncomingMessage(clientID string, evt *events.Mess
 		return
 	}
 
-	// Log conversation
+	// Log conversation (LLM usage is metered by UsageService through llm.Service)
 	go func() {
 		_ = s.convRepo.LogConversation(clientID, from, text, reply)
-		if s.creditsRepo != nil {
-			_ = s.creditsRepo.IncrementUsage(clientID)
-		}
 	}()
 }
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+const (
+	// mobileActionWindow limits pending orders to recent ones; older unpaid orders are stale, not actionable
+	mobileActionWindow = 7 * 24 * time.Hour
+	// mobilePendingLimit caps the pending orders list in the dashboard payload
+	mobilePendingLimit = 20
+)
+
+// ErrOrderNotFound is returned when an order does not exist or belongs to another client
+var ErrOrderNotFound = errors.New("order not found")
+
+// SessionStatusChecker reports whether a WhatsApp session is connected
+type SessionStatusChecker interface {
+	GetSessionStatus(sessionID string) (bool, error)
+}
+
+// MobileDashboardService builds the compact dashboard and quick actions for the admin mobile app
+type MobileDashboardService struct {
+	orderRepo     repositories.OrderRepo
+	clientRepo    repositories.ClientRepo
+	orderService  *OrderService
+	sessionStatus SessionStatusChecker
+}
+
+func NewMobileDashboardService(
+	orderRepo repositories.OrderRepo,
+	clientRepo repositories.ClientRepo,
+	orderService *OrderService,
+	sessionStatus SessionStatusChecker,
+) *MobileDashboardService {
+	return &MobileDashboardService{
+		orderRepo:     orderRepo,
+		clientRepo:    clientRepo,
+		orderService:  orderService,
+		sessionStatus: sessionStatus,
+	}
+}
+
+// GetDashboard returns today's KPIs, the orders waiting on the admin and any alerts
+func (s *MobileDashboardService) GetDashboard(clientID string) (*models.MobileDashboard, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	now := time.Now()
+	actionSince := now.Add(-mobileActionWindow)
+
+	kpis, err := s.orderRepo.GetMobileKPIs(clientID, startOfDay(now, client.Timezone), actionSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KPIs: %w", err)
+	}
+
+	orders, err := s.orderRepo.ListNeedingAction(clientID, actionSince, mobilePendingLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load pending orders: %w", err)
+	}
+
+	pending := make([]models.MobileOrder, 0, len(orders))
+	for _, order := range orders {
+		customer := order.CustomerName
+		if customer == "" {
+			customer = order.CustomerPhone
+		}
+		pending = append(pending, models.MobileOrder{
+			ID:        order.ID,
+			Number:    order.OrderNumber,
+			Customer:  customer,
+			Total:     order.TotalAmount,
+			Action:    mobileOrderAction(&order),
+			CreatedAt: order.CreatedAt,
+		})
+	}
+
+	return &models.MobileDashboard{
+		KPIs:    *kpis,
+		Pending: pending,
+		Alerts:  s.alerts(client),
+	}, nil
+}
+
+// ConfirmPayment confirms a manual payment for one of the client's orders
+func (s *MobileDashboardService) ConfirmPayment(clientID, orderID, paymentMethod, reference string) (*models.Order, error) {
+	if _, err := s.clientOrder(clientID, orderID); err != nil {
+		return nil, err
+	}
+
+	if paymentMethod == "" {
+		paymentMethod = "manual"
+	}
+	if err := s.orderService.ConfirmPayment(orderID, paymentMethod, reference); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.GetByID(orderID)
+}
+
+// CancelOrder cancels one of the client's unpaid orders
+func (s *MobileDashboardService) CancelOrder(clientID, orderID, reason string) (*models.Order, error) {
+	if _, err := s.clientOrder(clientID, orderID); err != nil {
+		return nil, err
+	}
+
+	if err := s.orderService.CancelOrder(orderID, reason); err != nil {
+		return nil, err
+	}
+
+	return s.orderRepo.GetByID(orderID)
+}
+
+// clientOrder loads an order and checks it belongs to the client
+func (s *MobileDashboardService) clientOrder(clientID, orderID string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, ErrOrderNotFound
+	}
+	return order, nil
+}
+
+// alerts collects problems the admin should act on
+func (s *MobileDashboardService) alerts(client *models.Client) []models.MobileAlert {
+	alerts := []models.MobileAlert{}
+
+	if client.SubscriptionStatus != "" && client.SubscriptionStatus != "active" {
+		alerts = append(alerts, models.MobileAlert{
+			Type:    "subscription",
+			Message: fmt.Sprintf("Langganan %s, bot mungkin tidak membalas pelanggan.", client.SubscriptionStatus),
+		})
+	}
+
+	// Single-session providers have no session ID; their connection is checked at the provider level
+	if client.WhatsAppSessionID != "" && s.sessionStatus != nil {
+		connected, err := s.sessionStatus.GetSessionStatus(client.WhatsAppSessionID)
+		if err != nil {
+			log.Printf("⚠️ Failed to check WhatsApp session %s: %v", client.WhatsAppSessionID, err)
+		}
+		if err != nil || !connected {
+			alerts = append(alerts, models.MobileAlert{
+				Type:    "session_unhealthy",
+				Message: "Sesi WhatsApp terputus. Scan ulang QR agar bot bisa membalas.",
+			})
+		}
+	}
+
+	return alerts
+}
+
+// mobileOrderAction is the next step the admin has to take on an order
+func mobileOrderAction(order *models.Order) string {
+	switch {
+	case order.ReviewStatus == models.ReviewStatusNeedsReview && order.PaymentStatus != models.PaymentStatusPaid:
+		return models.MobileActionReview
+	case order.PaymentStatus == models.PaymentStatusPending:
+		return models.MobileActionConfirmPayment
+	default:
+		return models.MobileActionShip
+	}
+}
+
+// startOfDay returns midnight of the current day in the client's timezone
+func startOfDay(now time.Time, timezone string) time.Time {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		loc = time.Local
+	}
+	now = now.In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/ocr"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// OCRRetentionService applies per-client retention and anonymization to raw OCR text
+type OCRRetentionService struct {
+	clientRepo      repositories.ClientRepo
+	transactionRepo repositories.TransactionRepo
+}
+
+func NewOCRRetentionService(clientRepo repositories.ClientRepo, transactionRepo repositories.TransactionRepo) *OCRRetentionService {
+	return &OCRRetentionService{
+		clientRepo:      clientRepo,
+		transactionRepo: transactionRepo,
+	}
+}
+
+// PrepareRawText returns the raw OCR text to store for a client's new transaction
+func (s *OCRRetentionService) PrepareRawText(clientID, text string) string {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		// Unknown settings: store the safe default
+		log.Printf("⚠️ Failed to load OCR retention settings for client %s: %v", clientID, err)
+		return ocr.TruncateRawText(ocr.Anonymize(text), ocr.MaxRawTextLength)
+	}
+	return OCRRawTextForStorage(client, text)
+}
+
+// GetSettings returns a client's raw OCR text retention policy
+func (s *OCRRetentionService) GetSettings(clientID string) (*models.OCRRetentionSettings, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+	return ocrRetentionSettings(client), nil
+}
+
+// UpdateSettings changes a client's raw OCR text retention policy
+func (s *OCRRetentionService) UpdateSettings(clientID string, req *models.UpdateOCRRetentionRequest) (*models.OCRRetentionSettings, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	if req.RetentionDays != nil {
+		client.OCRRawTextRetentionDays = *req.RetentionDays
+	}
+	if req.Anonymize != nil {
+		client.OCRAnonymize = *req.Anonymize
+	}
+
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, fmt.Errorf("failed to update OCR retention settings: %w", err)
+	}
+
+	log.Printf("🔒 OCR retention for client %s: %d days, anonymize=%t", clientID, client.OCRRawTextRetentionDays, client.OCRAnonymize)
+	return ocrRetentionSettings(client), nil
+}
+
+// PurgeRawText deletes raw OCR text on demand: one transaction, or all of a client's (optionally created before a date)
+func (s *OCRRetentionService) PurgeRawText(clientID, transactionID string, before *time.Time) (int64, error) {
+	if transactionID != "" {
+		transaction, err := s.transactionRepo.GetByID(transactionID)
+		if err != nil || transaction.ClientID.String() != clientID {
+			return 0, fmt.Errorf("transaction not found")
+		}
+	}
+
+	purged, err := s.transactionRepo.PurgeRawText(clientID, transactionID, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge raw OCR text: %w", err)
+	}
+
+	log.Printf("🧹 Purged raw OCR text of %d transaction(s) for client %s", purged, clientID)
+	return purged, nil
+}
+
+// PurgeExpired deletes raw OCR text past each client's retention period
+func (s *OCRRetentionService) PurgeExpired() (int64, error) {
+	purged, err := s.transactionRepo.PurgeExpiredRawText()
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired raw OCR text: %w", err)
+	}
+
+	if purged > 0 {
+		log.Printf("🧹 Retention purge removed raw OCR text of %d transaction(s)", purged)
+	}
+	return purged, nil
+}
+
+// RunPurgeJob purges expired raw OCR text now and then on every interval until ctx is done
+func (s *OCRRetentionService) RunPurgeJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.PurgeExpired(); err != nil {
+			log.Printf("⚠️ %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// OCRRawTextForStorage applies a client's retention settings to raw OCR text at ingestion
+func OCRRawTextForStorage(client *models.Client, text string) string {
+	if client.OCRRawTextRetentionDays == 0 {
+		return ""
+	}
+	if client.OCRAnonymize {
+		text = ocr.Anonymize(text)
+	}
+	return ocr.TruncateRawText(text, ocr.MaxRawTextLength)
+}
+
+func ocrRetentionSettings(client *models.Client) *models.OCRRetentionSettings {
+	return &models.OCRRetentionSettings{
+		RetentionDays:    client.OCRRawTextRetentionDays,
+		Anonymize:        client.OCRAnonymize,
+		MaxRawTextLength: ocr.MaxRawTextLength,
+	}
+}
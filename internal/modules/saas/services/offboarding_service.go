@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultRetentionDays is how long a deactivated client's data is kept before it may be purged
+	defaultRetentionDays = 30
+	// maxOffboardingAttempts stops the job from retrying a run forever
+	maxOffboardingAttempts = 10
+
+	// EventClientDeactivated is emitted to the client's event workflows when offboarding starts
+	EventClientDeactivated = "client_deactivated"
+)
+
+var (
+	ErrOffboardingInProgress = errors.New("client offboarding is already in progress")
+	ErrClientAlreadyInactive = errors.New("client is already deactivated")
+)
+
+// SessionStopper stops a client's WhatsApp session
+type SessionStopper interface {
+	StopSession(sessionID string) error
+	GetProviderName() string
+}
+
+// OffboardingStepFunc runs one step of the cascade and returns a short detail for the status.
+// Steps must be safe to run again: a failed run is retried from its first unfinished step.
+type OffboardingStepFunc func(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error)
+
+type offboardingStep struct {
+	name string
+	run  OffboardingStepFunc
+}
+
+// DeactivateClientRequest is the payload to deactivate a client
+type DeactivateClientRequest struct {
+	Reason        string `json:"reason"`
+	RequestedBy   string `json:"requested_by,omitempty"`   // Operator name for the audit trail
+	RetentionDays *int   `json:"retention_days,omitempty"` // nil = 30 days
+}
+
+// OffboardingService deactivates clients: it marks them inactive and then stops everything still running for them
+type OffboardingService struct {
+	clientRepo      repositories.ClientRepo
+	offboardingRepo repositories.ClientOffboardingRepo
+	sessions        SessionStopper
+	workflowService *WorkflowService
+
+	steps []offboardingStep
+
+	// Runs being processed by this instance
+	mu      sync.Mutex
+	running map[uuid.UUID]bool
+}
+
+// NewOffboardingService creates a new offboarding service with the built-in steps
+func NewOffboardingService(clientRepo repositories.ClientRepo, offboardingRepo repositories.ClientOffboardingRepo, sessions SessionStopper, workflowService *WorkflowService) *OffboardingService {
+	s := &OffboardingService{
+		clientRepo:      clientRepo,
+		offboardingRepo: offboardingRepo,
+		sessions:        sessions,
+		workflowService: workflowService,
+		running:         make(map[uuid.UUID]bool),
+	}
+
+	// Order matters: the event goes out before the kill switch, which would otherwise swallow it
+	s.RegisterStep("emit_event", s.emitDeactivatedEvent)
+	s.RegisterStep("stop_session", s.stopSession)
+	s.RegisterStep("pause_workflows", s.pauseWorkflows)
+	s.RegisterStep("suspend_credentials", s.suspendCredentials)
+	return s
+}
+
+// RegisterStep adds a step to the cascade (e.g. pausing campaigns or revoking API keys), run before the retention countdown.
+// Call it during startup only.
+func (s *OffboardingService) RegisterStep(name string, fn OffboardingStepFunc) {
+	s.steps = append(s.steps, offboardingStep{name: name, run: fn})
+}
+
+// Deactivate marks a client inactive and starts the offboarding cascade in the background
+func (s *OffboardingService) Deactivate(clientID uuid.UUID, req DeactivateClientRequest, actor string) (*models.ClientOffboarding, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	latest, err := s.offboardingRepo.GetLatestByClient(clientID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to load offboarding status: %w", err)
+	}
+	if latest != nil && latest.Status != models.OffboardingStatusCompleted {
+		return latest, ErrOffboardingInProgress
+	}
+	if client.SubscriptionStatus != models.ClientStatusActive && latest != nil {
+		return latest, ErrClientAlreadyInactive
+	}
+
+	retentionDays := defaultRetentionDays
+	if req.RetentionDays != nil {
+		if *req.RetentionDays < 0 {
+			return nil, fmt.Errorf("retention_days cannot be negative")
+		}
+		retentionDays = *req.RetentionDays
+	}
+
+	// Mark inactive right away so the client stops resolving for webhooks and logins even if the cascade lags
+	if err := s.clientRepo.UpdateSubscriptionStatus(clientID, models.ClientStatusInactive); err != nil {
+		return nil, fmt.Errorf("failed to deactivate client: %w", err)
+	}
+
+	run := &models.ClientOffboarding{
+		ClientID:      clientID,
+		Status:        models.OffboardingStatusPending,
+		Reason:        req.Reason,
+		RequestedBy:   actor,
+		RetentionDays: retentionDays,
+	}
+	steps := make([]models.OffboardingStep, 0, len(s.steps)+1)
+	for _, step := range s.steps {
+		steps = append(steps, models.OffboardingStep{Name: step.name, Status: models.OffboardingStepPending})
+	}
+	steps = append(steps, models.OffboardingStep{Name: "schedule_retention", Status: models.OffboardingStepPending})
+	run.SetSteps(steps)
+
+	if err := s.offboardingRepo.Create(run); err != nil {
+		return nil, fmt.Errorf("failed to create offboarding: %w", err)
+	}
+
+	log.Printf("📴 Client %s (%s) deactivated by %s, offboarding %s started", client.BusinessName, clientID, actor, run.ID)
+
+	go func(id uuid.UUID) {
+		if err := s.Process(context.Background(), id); err != nil {
+			log.Printf("⚠️ Offboarding %s failed, will retry: %v", id, err)
+		}
+	}(run.ID)
+
+	return run, nil
+}
+
+// GetStatus returns the latest offboarding run of a client
+func (s *OffboardingService) GetStatus(clientID uuid.UUID) (*models.ClientOffboarding, error) {
+	return s.offboardingRepo.GetLatestByClient(clientID)
+}
+
+// Process runs the unfinished steps of an offboarding run in order, stopping at the first failure
+func (s *OffboardingService) Process(ctx context.Context, id uuid.UUID) error {
+	s.mu.Lock()
+	if s.running[id] {
+		s.mu.Unlock()
+		return nil
+	}
+	s.running[id] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, id)
+		s.mu.Unlock()
+	}()
+
+	run, err := s.offboardingRepo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("offboarding not found: %w", err)
+	}
+	if run.Status == models.OffboardingStatusCompleted {
+		return nil
+	}
+
+	client, err := s.clientRepo.GetByID(run.ClientID.String())
+	if err != nil {
+		return s.fail(run, fmt.Errorf("client not found: %w", err))
+	}
+
+	now := time.Now()
+	run.Status = models.OffboardingStatusRunning
+	run.Attempts++
+	run.LastError = ""
+	if run.StartedAt == nil {
+		run.StartedAt = &now
+	}
+	if err := s.offboardingRepo.Update(run); err != nil {
+		return fmt.Errorf("failed to update offboarding: %w", err)
+	}
+
+	steps := run.StepList()
+	for i := range steps {
+		step := &steps[i]
+		if step.Status == models.OffboardingStepCompleted || step.Status == models.OffboardingStepSkipped {
+			continue
+		}
+
+		fn := s.stepFunc(step.Name)
+		if fn == nil {
+			step.Status = models.OffboardingStepSkipped
+			step.Detail = "step no longer registered"
+			continue
+		}
+
+		detail, err := fn(ctx, client, run)
+		if err != nil {
+			step.Status = models.OffboardingStepFailed
+			step.Error = err.Error()
+			run.SetSteps(steps)
+			return s.fail(run, fmt.Errorf("%s: %w", step.Name, err))
+		}
+
+		doneAt := time.Now()
+		step.Status = models.OffboardingStepCompleted
+		step.Detail = detail
+		step.Error = ""
+		step.CompletedAt = &doneAt
+		run.SetSteps(steps)
+		if err := s.offboardingRepo.Update(run); err != nil {
+			return fmt.Errorf("failed to record step %s: %w", step.Name, err)
+		}
+	}
+
+	completedAt := time.Now()
+	run.SetSteps(steps)
+	run.Status = models.OffboardingStatusCompleted
+	run.CompletedAt = &completedAt
+	if err := s.offboardingRepo.Update(run); err != nil {
+		return fmt.Errorf("failed to complete offboarding: %w", err)
+	}
+
+	log.Printf("✅ Offboarding %s completed for client %s (purge after %v)", run.ID, run.ClientID, run.PurgeAt)
+	return nil
+}
+
+// RunOffboardingJob retries unfinished offboarding runs, e.g. after a failed step or a restart mid-cascade
+func (s *OffboardingService) RunOffboardingJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			runs, err := s.offboardingRepo.ListOpen(maxOffboardingAttempts)
+			if err != nil {
+				log.Printf("⚠️ Failed to list open offboardings: %v", err)
+				continue
+			}
+			for _, run := range runs {
+				if err := s.Process(ctx, run.ID); err != nil {
+					log.Printf("⚠️ Offboarding %s attempt %d failed: %v", run.ID, run.Attempts+1, err)
+				}
+			}
+		}
+	}
+}
+
+func (s *OffboardingService) stepFunc(name string) OffboardingStepFunc {
+	if name == "schedule_retention" {
+		return s.scheduleRetention
+	}
+	for _, step := range s.steps {
+		if step.name == name {
+			return step.run
+		}
+	}
+	return nil
+}
+
+func (s *OffboardingService) fail(run *models.ClientOffboarding, err error) error {
+	run.Status = models.OffboardingStatusFailed
+	run.LastError = err.Error()
+	if updateErr := s.offboardingRepo.Update(run); updateErr != nil {
+		log.Printf("⚠️ Failed to record offboarding failure %s: %v", run.ID, updateErr)
+	}
+	return err
+}
+
+func (s *OffboardingService) emitDeactivatedEvent(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	if s.workflowService == nil {
+		return "", nil
+	}
+	err := s.workflowService.HandleEvent(ctx, EventClientDeactivated, map[string]interface{}{
+		"client_id":      client.ID.String(),
+		"business_name":  client.BusinessName,
+		"reason":         run.Reason,
+		"requested_by":   run.RequestedBy,
+		"retention_days": run.RetentionDays,
+		"offboarding_id": run.ID.String(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return EventClientDeactivated, nil
+}
+
+func (s *OffboardingService) stopSession(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	if client.SandboxMode || client.WhatsAppSessionID == "" {
+		return "no session", nil
+	}
+	// Only WAHA runs a session per client; other providers share one connection
+	if s.sessions == nil || s.sessions.GetProviderName() != "WAHA" {
+		return "provider has no per-client session", nil
+	}
+	if err := s.sessions.StopSession(client.WhatsAppSessionID); err != nil {
+		// Stopping a session that is already gone is fine
+		if strings.Contains(strings.ToLower(err.Error()), "not found") {
+			return "session already stopped", nil
+		}
+		return "", err
+	}
+	return "stopped " + client.WhatsAppSessionID, nil
+}
+
+func (s *OffboardingService) pauseWorkflows(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	if s.workflowService == nil {
+		return "", nil
+	}
+	// The kill switch stops event and scheduled workflows but keeps their configuration for a reactivation
+	if _, err := s.workflowService.SetAutomationPaused(client.ID, true, "client deactivated", run.RequestedBy); err != nil {
+		return "", err
+	}
+	return "automation paused", nil
+}
+
+func (s *OffboardingService) suspendCredentials(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	suspended, err := s.offboardingRepo.SuspendCompanyUsers(client.ID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d user(s) suspended", suspended), nil
+}
+
+func (s *OffboardingService) scheduleRetention(ctx context.Context, client *models.Client, run *models.ClientOffboarding) (string, error) {
+	purgeAt := time.Now().AddDate(0, 0, run.RetentionDays)
+	run.PurgeAt = &purgeAt
+	return "data kept until " + purgeAt.Format("2006-01-02"), nil
+}
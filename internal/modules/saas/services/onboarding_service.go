@@ -0,0 +1,332 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// WAHA session states
+const (
+	sessionStateWorking = "WORKING"
+	sessionStateScanQR  = "SCAN_QR_CODE"
+)
+
+// selfTestPrefix marks the message sent to verify the webhook end to end
+const selfTestPrefix = "🔧 Tes koneksi otomatis"
+
+// OnboardingService provisions tenant infrastructure (WhatsApp session, webhook) during onboarding
+type OnboardingService struct {
+	clientRepo       repositories.ClientRepo
+	provisioningRepo repositories.WhatsAppProvisioningRepo
+	waService        *whatsapp.Service
+	publicBaseURL    string
+}
+
+// OnboardingStep describes a single provisioning step
+type OnboardingStep struct {
+	Name        string     `json:"name"`
+	Done        bool       `json:"done"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Detail      string     `json:"detail,omitempty"`
+}
+
+// OnboardingStatus reports WhatsApp readiness for a tenant
+type OnboardingStatus struct {
+	ClientID     string           `json:"client_id"`
+	SessionID    string           `json:"session_id,omitempty"`
+	SessionState string           `json:"session_state,omitempty"`
+	WebhookURL   string           `json:"webhook_url,omitempty"`
+	Steps        []OnboardingStep `json:"steps"`
+	Ready        bool             `json:"ready"`
+	NextStep     string           `json:"next_step,omitempty"`
+	LastError    string           `json:"last_error,omitempty"`
+	BotPaused    bool             `json:"bot_paused"` // Vacation mode: bot not answering customers
+	BotResumeAt  *time.Time       `json:"bot_resume_at,omitempty"`
+}
+
+// NewOnboardingService creates a new onboarding service
+func NewOnboardingService(
+	clientRepo repositories.ClientRepo,
+	provisioningRepo repositories.WhatsAppProvisioningRepo,
+	waService *whatsapp.Service,
+	publicBaseURL string,
+) *OnboardingService {
+	return &OnboardingService{
+		clientRepo:       clientRepo,
+		provisioningRepo: provisioningRepo,
+		waService:        waService,
+		publicBaseURL:    strings.TrimRight(publicBaseURL, "/"),
+	}
+}
+
+// ProvisionWhatsApp creates the WAHA session, configures the signed webhook and runs the self-test when possible
+func (s *OnboardingService) ProvisionWhatsApp(clientID, sessionID string) (*OnboardingStatus, error) {
+	if s.publicBaseURL == "" {
+		return nil, fmt.Errorf("PUBLIC_BASE_URL is not configured")
+	}
+
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	if sessionID == "" {
+		sessionID = client.WhatsAppSessionID
+	}
+	if sessionID == "" {
+		sessionID = whatsapp.SessionName(client.ID.String())
+	}
+
+	prov, err := s.getOrCreateProvisioning(client.ID, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	prov.SessionID = sessionID
+	prov.LastError = ""
+
+	// 1. Create/start the session
+	if err := s.waService.StartSession(sessionID); err != nil {
+		return nil, s.recordError(prov, fmt.Errorf("failed to start session: %w", err))
+	}
+	now := time.Now()
+	prov.SessionCreatedAt = &now
+
+	if client.WhatsAppSessionID != sessionID {
+		client.WhatsAppSessionID = sessionID
+		if err := s.clientRepo.Update(client); err != nil {
+			return nil, s.recordError(prov, fmt.Errorf("failed to store session mapping: %w", err))
+		}
+	}
+
+	// 2. Configure the tenant webhook (message.any lets the self-test message come back, message.reaction carries customer reactions)
+	webhookURL := s.publicBaseURL + "/webhook/" + prov.WebhookToken
+	err = s.waService.ConfigureWebhookWithOptions(sessionID, webhookURL, whatsapp.WebhookOptions{
+		Events:  []string{"message", "message.any", "message.reaction", "message.ack"},
+		HMACKey: prov.WebhookSecret,
+	})
+	if err != nil {
+		return nil, s.recordError(prov, fmt.Errorf("failed to configure webhook: %w", err))
+	}
+	configuredAt := time.Now()
+	prov.WebhookURL = webhookURL
+	prov.WebhookConfiguredAt = &configuredAt
+
+	// Webhook changes require a new self-test
+	prov.SelfTestVerifiedAt = nil
+
+	if err := s.provisioningRepo.Update(prov); err != nil {
+		return nil, fmt.Errorf("failed to save provisioning: %w", err)
+	}
+
+	log.Printf("✅ WhatsApp provisioned for client %s: session=%s", clientID, sessionID)
+
+	// 3. Self-test right away if the session is already paired
+	if state, err := s.waService.GetSessionState(sessionID); err == nil && state == sessionStateWorking {
+		if err := s.sendSelfTest(prov); err != nil {
+			log.Printf("⚠️ Self-test failed for client %s: %v", clientID, err)
+		}
+	}
+
+	return s.GetStatus(clientID)
+}
+
+// RunSelfTest sends a self-test message through the tenant session; the webhook marks it verified on receipt
+func (s *OnboardingService) RunSelfTest(clientID string) (*OnboardingStatus, error) {
+	prov, err := s.provisioningRepo.GetByClientID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("whatsapp is not provisioned for this client")
+	}
+
+	state, err := s.waService.GetSessionState(prov.SessionID)
+	if err != nil {
+		return nil, s.recordError(prov, fmt.Errorf("failed to get session state: %w", err))
+	}
+	if state != sessionStateWorking {
+		return nil, fmt.Errorf("session is not connected yet (state: %s), scan the QR code first", state)
+	}
+
+	if err := s.sendSelfTest(prov); err != nil {
+		return nil, err
+	}
+
+	return s.GetStatus(clientID)
+}
+
+// GetStatus reports onboarding readiness for a tenant
+func (s *OnboardingService) GetStatus(clientID string) (*OnboardingStatus, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	status := &OnboardingStatus{
+		ClientID:    clientID,
+		BotPaused:   client.BotPaused,
+		BotResumeAt: client.BotResumeAt,
+	}
+
+	prov, err := s.provisioningRepo.GetByClientID(clientID)
+	if err != nil {
+		status.Steps = []OnboardingStep{
+			{Name: "session_created"},
+			{Name: "session_connected"},
+			{Name: "webhook_configured"},
+			{Name: "self_test_verified"},
+		}
+		status.NextStep = "provision_whatsapp"
+		return status, nil
+	}
+
+	status.SessionID = prov.SessionID
+	status.WebhookURL = prov.WebhookURL
+	status.LastError = prov.LastError
+
+	state, err := s.waService.GetSessionState(prov.SessionID)
+	if err != nil {
+		state = "UNKNOWN"
+	}
+	status.SessionState = state
+	connected := state == sessionStateWorking
+
+	selfTestDetail := ""
+	if prov.SelfTestVerifiedAt == nil && prov.SelfTestSentAt != nil {
+		selfTestDetail = "self-test sent at " + prov.SelfTestSentAt.Format(time.RFC3339) + ", waiting for webhook"
+	}
+
+	status.Steps = []OnboardingStep{
+		{Name: "session_created", Done: prov.SessionCreatedAt != nil, CompletedAt: prov.SessionCreatedAt},
+		{Name: "session_connected", Done: connected, Detail: state},
+		{Name: "webhook_configured", Done: prov.WebhookConfiguredAt != nil, CompletedAt: prov.WebhookConfiguredAt},
+		{Name: "self_test_verified", Done: prov.SelfTestVerifiedAt != nil, CompletedAt: prov.SelfTestVerifiedAt, Detail: selfTestDetail},
+	}
+
+	switch {
+	case prov.SessionCreatedAt == nil || prov.WebhookConfiguredAt == nil:
+		status.NextStep = "provision_whatsapp"
+	case state == sessionStateScanQR:
+		status.NextStep = "scan_qr_code"
+	case !connected:
+		status.NextStep = "wait_for_session"
+	case prov.SelfTestVerifiedAt == nil:
+		status.NextStep = "run_self_test"
+	default:
+		status.Ready = true
+	}
+
+	return status, nil
+}
+
+// ResolveWebhookToken finds the tenant provisioning behind a webhook token
+func (s *OnboardingService) ResolveWebhookToken(token string) (*models.WhatsAppProvisioning, error) {
+	if token == "" {
+		return nil, fmt.Errorf("webhook token is required")
+	}
+	return s.provisioningRepo.GetByWebhookToken(token)
+}
+
+// HandleSelfTestMessage marks the self-test verified when its message arrives through the webhook
+func (s *OnboardingService) HandleSelfTestMessage(prov *models.WhatsAppProvisioning, body string) bool {
+	if prov.SelfTestNonce == "" || !strings.Contains(body, prov.SelfTestNonce) {
+		return false
+	}
+
+	now := time.Now()
+	prov.SelfTestVerifiedAt = &now
+	prov.SelfTestNonce = ""
+	prov.LastError = ""
+
+	if err := s.provisioningRepo.Update(prov); err != nil {
+		log.Printf("⚠️ Failed to record self-test verification: %v", err)
+		return false
+	}
+
+	log.Printf("✅ WhatsApp self-test verified for client %s", prov.ClientID)
+	return true
+}
+
+// sendSelfTest sends a message with a fresh nonce from the session to its own number
+func (s *OnboardingService) sendSelfTest(prov *models.WhatsAppProvisioning) error {
+	phone, err := s.waService.GetSessionPhone(prov.SessionID)
+	if err != nil {
+		return s.recordError(prov, fmt.Errorf("failed to get session phone: %w", err))
+	}
+
+	nonce, err := randomHex(6)
+	if err != nil {
+		return fmt.Errorf("failed to generate self-test nonce: %w", err)
+	}
+
+	message := fmt.Sprintf("%s [%s]\n\nPesan ini dikirim otomatis untuk memastikan bot kamu sudah terhubung. Abaikan saja 🙏", selfTestPrefix, nonce)
+	if err := s.waService.SendMessageFromSession(prov.SessionID, phone, message); err != nil {
+		return s.recordError(prov, fmt.Errorf("failed to send self-test message: %w", err))
+	}
+
+	now := time.Now()
+	prov.SelfTestNonce = nonce
+	prov.SelfTestSentAt = &now
+	prov.SelfTestVerifiedAt = nil
+	prov.LastError = ""
+
+	if err := s.provisioningRepo.Update(prov); err != nil {
+		return fmt.Errorf("failed to save self-test state: %w", err)
+	}
+
+	log.Printf("📤 Self-test sent for client %s via session %s", prov.ClientID, prov.SessionID)
+	return nil
+}
+
+// getOrCreateProvisioning loads the provisioning record or creates one with a fresh token and secret
+func (s *OnboardingService) getOrCreateProvisioning(clientID uuid.UUID, sessionID string) (*models.WhatsAppProvisioning, error) {
+	prov, err := s.provisioningRepo.GetByClientID(clientID.String())
+	if err == nil {
+		return prov, nil
+	}
+
+	token, err := randomHex(24)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	prov = &models.WhatsAppProvisioning{
+		ClientID:      clientID,
+		SessionID:     sessionID,
+		WebhookToken:  token,
+		WebhookSecret: secret,
+	}
+	if err := s.provisioningRepo.Create(prov); err != nil {
+		return nil, fmt.Errorf("failed to create provisioning: %w", err)
+	}
+
+	return prov, nil
+}
+
+// recordError stores the last provisioning error and returns it
+func (s *OnboardingService) recordError(prov *models.WhatsAppProvisioning, err error) error {
+	prov.LastError = err.Error()
+	if saveErr := s.provisioningRepo.Update(prov); saveErr != nil {
+		log.Printf("⚠️ Failed to save provisioning error: %v", saveErr)
+	}
+	return err
+}
+
+// randomHex returns n random bytes encoded as hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// orderBoardClosedWindow keeps delivered and cancelled orders on the board for the rest of the shift
+	orderBoardClosedWindow = 24 * time.Hour
+	// orderBoardColumnLimit caps the cards loaded per column
+	orderBoardColumnLimit = 100
+	// orderBoardFeedOverlap re-reads recent changes on every poll so updates committed late aren't missed
+	orderBoardFeedOverlap = 5 * time.Second
+)
+
+var (
+	// ErrInvalidStageMove is returned when a board move skips the fulfillment lifecycle
+	ErrInvalidStageMove = errors.New("order can't be moved to this stage")
+	// ErrOrderUnpaid is returned when an unpaid, non-COD order is moved into fulfillment
+	ErrOrderUnpaid = errors.New("order is not paid yet")
+	// ErrOrderVersionConflict is returned when the order changed since the board card was loaded
+	ErrOrderVersionConflict = errors.New("order was changed by someone else")
+)
+
+// orderBoardMoves lists the stages a card can be dragged to from each stage. Cards may go back one
+// stage to undo a wrong drag; cancelling goes through the cancel endpoint so payment and stock are released
+var orderBoardMoves = map[string][]string{
+	models.FulfillmentStatusPending:    {models.FulfillmentStatusProcessing},
+	models.FulfillmentStatusProcessing: {models.FulfillmentStatusPending, models.FulfillmentStatusPacked, models.FulfillmentStatusShipped, models.FulfillmentStatusDelivered},
+	models.FulfillmentStatusPacked:     {models.FulfillmentStatusProcessing, models.FulfillmentStatusShipped, models.FulfillmentStatusDelivered},
+	models.FulfillmentStatusShipped:    {models.FulfillmentStatusProcessing, models.FulfillmentStatusPacked, models.FulfillmentStatusDelivered},
+	models.FulfillmentStatusDelivered:  {models.FulfillmentStatusShipped},
+}
+
+// OrderBoardService serves the kanban order board for fulfillment teams
+type OrderBoardService struct {
+	orderRepo repositories.OrderRepo
+}
+
+func NewOrderBoardService(orderRepo repositories.OrderRepo) *OrderBoardService {
+	return &OrderBoardService{
+		orderRepo: orderRepo,
+	}
+}
+
+// GetBoard groups the client's open and recently closed orders by fulfillment stage
+func (s *OrderBoardService) GetBoard(clientID, branchID string) (*models.OrderBoard, error) {
+	// Read the cursor first: a change racing the listing is sent again by the stream rather than lost
+	cursor, err := s.orderRepo.LatestUpdate(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read board cursor: %w", err)
+	}
+
+	orders, err := s.orderRepo.ListBoard(clientID, branchID, time.Now().Add(-orderBoardClosedWindow), orderBoardColumnLimit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load board orders: %w", err)
+	}
+
+	columns := make([]models.OrderBoardColumn, len(models.OrderBoardStages))
+	index := make(map[string]int, len(models.OrderBoardStages))
+	for i, stage := range models.OrderBoardStages {
+		columns[i] = models.OrderBoardColumn{Stage: stage, Orders: []models.OrderBoardCard{}}
+		index[stage] = i
+	}
+	for i := range orders {
+		card := orderBoardCard(&orders[i])
+		col, ok := index[card.Stage]
+		if !ok {
+			continue
+		}
+		columns[col].Orders = append(columns[col].Orders, card)
+		columns[col].Count++
+	}
+
+	return &models.OrderBoard{Columns: columns, Cursor: cursor}, nil
+}
+
+// MoveOrder drags an order to another stage. The move only applies if the order is still at the version
+// the caller saw; otherwise ErrOrderVersionConflict is returned with the current card
+func (s *OrderBoardService) MoveOrder(clientID, orderID string, req *models.OrderStageRequest) (*models.OrderBoardCard, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, ErrOrderNotFound
+	}
+
+	current := orderBoardCard(order)
+	if order.Version != req.Version {
+		return &current, ErrOrderVersionConflict
+	}
+	if order.FulfillmentStatus == req.Stage {
+		return &current, nil
+	}
+	if !canMoveOrder(order.FulfillmentStatus, req.Stage) {
+		return &current, fmt.Errorf("%w: %s to %s", ErrInvalidStageMove, order.FulfillmentStatus, req.Stage)
+	}
+	if order.FulfillmentStatus == models.FulfillmentStatusPending && order.PaymentStatus != models.PaymentStatusPaid && !isCOD(order) {
+		return &current, ErrOrderUnpaid
+	}
+
+	moved, err := s.orderRepo.UpdateStage(clientID, orderID, req.Stage, req.Version)
+	if err != nil {
+		return nil, fmt.Errorf("failed to move order: %w", err)
+	}
+
+	// Reload for the version bumped by the update (or the change that beat us to it)
+	if order, err = s.orderRepo.GetByID(orderID); err != nil {
+		return nil, fmt.Errorf("failed to reload order: %w", err)
+	}
+	current = orderBoardCard(order)
+	if !moved {
+		return &current, ErrOrderVersionConflict
+	}
+
+	log.Printf("📋 Order %s moved to %s on the board", order.OrderNumber, req.Stage)
+	return &current, nil
+}
+
+// OrderBoardFeed tracks what a board stream has already sent
+type OrderBoardFeed struct {
+	clientID string
+	branchID string
+	cursor   time.Time
+	sent     map[uuid.UUID]int // Version last sent per order changed within the overlap window
+}
+
+// NewFeed starts a change feed after cursor, normally the cursor of the board the client loaded
+func (s *OrderBoardService) NewFeed(clientID, branchID string, cursor time.Time) *OrderBoardFeed {
+	return &OrderBoardFeed{
+		clientID: clientID,
+		branchID: branchID,
+		cursor:   cursor,
+		sent:     make(map[uuid.UUID]int),
+	}
+}
+
+// Poll returns the cards of orders changed since the previous poll
+func (s *OrderBoardService) Poll(feed *OrderBoardFeed) ([]models.OrderBoardCard, error) {
+	since := feed.cursor.Add(-orderBoardFeedOverlap)
+	orders, err := s.orderRepo.ListUpdatedSince(feed.clientID, feed.branchID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to poll board changes: %w", err)
+	}
+
+	var cards []models.OrderBoardCard
+	for i := range orders {
+		order := &orders[i]
+		if order.UpdatedAt.After(feed.cursor) {
+			feed.cursor = order.UpdatedAt
+		}
+		if version, ok := feed.sent[order.ID]; ok && version >= order.Version {
+			continue
+		}
+		feed.sent[order.ID] = order.Version
+		cards = append(cards, orderBoardCard(order))
+	}
+
+	// Forget orders that dropped out of the overlap window
+	if len(feed.sent) > len(orders) {
+		recent := make(map[uuid.UUID]bool, len(orders))
+		for i := range orders {
+			recent[orders[i].ID] = true
+		}
+		for id := range feed.sent {
+			if !recent[id] {
+				delete(feed.sent, id)
+			}
+		}
+	}
+
+	return cards, nil
+}
+
+// canMoveOrder reports whether a card may be dragged from one stage to another
+func canMoveOrder(from, to string) bool {
+	for _, stage := range orderBoardMoves[from] {
+		if stage == to {
+			return true
+		}
+	}
+	return false
+}
+
+// orderBoardCard trims an order to a board card
+func orderBoardCard(order *models.Order) models.OrderBoardCard {
+	customer := order.CustomerName
+	if customer == "" {
+		customer = order.CustomerPhone
+	}
+
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		log.Printf("⚠️ Failed to parse items of order %s: %v", order.OrderNumber, err)
+	}
+	lines := make([]string, 0, len(items))
+	for _, item := range items {
+		lines = append(lines, fmt.Sprintf("%dx %s", item.Quantity, item.ProductName))
+	}
+
+	return models.OrderBoardCard{
+		ID:            order.ID,
+		Number:        order.OrderNumber,
+		Customer:      customer,
+		Items:         lines,
+		Total:         order.TotalAmount,
+		PaymentStatus: order.PaymentStatus,
+		PaymentMethod: order.PaymentMethod,
+		Courier:       order.ShippingCourier,
+		Tracking:      order.ShippingTrackingNumber,
+		Stage:         order.FulfillmentStatus,
+		Version:       order.Version,
+		BranchID:      order.BranchID,
+		CreatedAt:     order.CreatedAt,
+		UpdatedAt:     order.UpdatedAt,
+	}
+}
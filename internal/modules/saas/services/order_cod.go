@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// codConfirmWindow is how long after checkout a customer can confirm a COD order
+const codConfirmWindow = 24 * time.Hour
+
+// ErrCODNotEligible is returned when the customer can't pay cash on delivery
+var ErrCODNotEligible = errors.New("cod not eligible")
+
+// CODEligibilityError explains to the customer why COD isn't available
+type CODEligibilityError struct {
+	Reason string // Customer-facing reason
+}
+
+func (e *CODEligibilityError) Error() string {
+	return "cod not eligible: " + e.Reason
+}
+
+func (e *CODEligibilityError) Is(target error) bool {
+	return target == ErrCODNotEligible
+}
+
+// isCOD reports whether the order is paid cash on delivery
+func isCOD(order *models.Order) bool {
+	return order.PaymentMethod == models.PaymentMethodCOD
+}
+
+// isUnpaidCOD reports whether the order still waits for cash
+func isUnpaidCOD(order *models.Order) bool {
+	return isCOD(order) && (order.PaymentStatus == models.PaymentStatusPending || order.PaymentStatus == models.PaymentStatusPendingCOD)
+}
+
+// getCODSettings returns the tenant's COD settings, falling back to defaults (COD disabled)
+func (s *OrderService) getCODSettings(clientID string) *models.CODSettings {
+	if s.codRepo != nil {
+		settings, err := s.codRepo.GetByClientID(clientID)
+		if err == nil {
+			return settings
+		}
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	return models.DefaultCODSettings(uid)
+}
+
+// checkCODEligibility applies the tenant's COD rules to the customer's order history
+func (s *OrderService) checkCODEligibility(clientID, customerPhone string, amount float64) error {
+	settings := s.getCODSettings(clientID)
+	if !settings.Enabled {
+		return &CODEligibilityError{Reason: "Pembayaran di tempat (COD) belum tersedia di toko ini."}
+	}
+
+	if settings.MaxOrderAmount > 0 && amount > settings.MaxOrderAmount {
+		return &CODEligibilityError{Reason: fmt.Sprintf("COD hanya tersedia untuk pesanan hingga Rp %s.", formatPrice(settings.MaxOrderAmount))}
+	}
+
+	history, err := s.orderRepo.GetCODHistory(clientID, customerPhone)
+	if err != nil {
+		return fmt.Errorf("failed to check COD history: %w", err)
+	}
+
+	if settings.MaxFailedOrders > 0 && history.CancelledCOD >= int64(settings.MaxFailedOrders) {
+		return &CODEligibilityError{Reason: "COD tidak tersedia karena ada pesanan COD sebelumnya yang dibatalkan."}
+	}
+	if history.PaidOrders < int64(settings.MinPaidOrders) {
+		return &CODEligibilityError{Reason: fmt.Sprintf("COD tersedia setelah Anda menyelesaikan %d pesanan dengan pembayaran online.", settings.MinPaidOrders)}
+	}
+	if settings.MaxOpenOrders > 0 && history.OpenCOD >= int64(settings.MaxOpenOrders) {
+		return &CODEligibilityError{Reason: "Anda masih memiliki pesanan COD yang belum selesai."}
+	}
+
+	return nil
+}
+
+// HandleCODReply confirms the customer's latest COD order when they reply the confirmation keyword.
+// Returns the reply and true if the message was a COD confirmation.
+func (s *OrderService) HandleCODReply(clientID, customerPhone, message string) (string, bool) {
+	if !strings.EqualFold(strings.Join(strings.Fields(message), " "), payment.CODConfirmKeyword) {
+		return "", false
+	}
+
+	if reply, ok := s.ConfirmAwaitingCOD(clientID, customerPhone); ok {
+		return reply, true
+	}
+	return "ℹ️ Tidak ada pesanan COD yang menunggu konfirmasi.", true
+}
+
+// ConfirmAwaitingCOD confirms the customer's latest COD order still waiting for confirmation.
+// Returns false if there is none.
+func (s *OrderService) ConfirmAwaitingCOD(clientID, customerPhone string) (string, bool) {
+	order, err := s.orderRepo.GetLatestAwaitingCOD(clientID, customerPhone, time.Now().Add(-codConfirmWindow))
+	if err != nil {
+		return "", false
+	}
+
+	now := time.Now()
+	order.PaymentStatus = models.PaymentStatusPendingCOD
+	order.CODConfirmedAt = &now
+	order.FulfillmentStatus = models.FulfillmentStatusProcessing
+	if err := s.orderRepo.Update(order); err != nil {
+		log.Printf("⚠️  Failed to confirm COD order %s: %v", order.OrderNumber, err)
+		return "❌ Maaf, terjadi kesalahan. Silakan coba lagi.", true
+	}
+
+	log.Printf("💵 COD confirmed by customer for order %s", order.OrderNumber)
+
+	s.notifyBranchAdmin(order, "COD Dikonfirmasi", "Tagih uang tunai saat pesanan diantar.")
+
+	return fmt.Sprintf(
+		"✅ *Pesanan COD Dikonfirmasi*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Siapkan uang tunai *Rp %s* saat pesanan diantar.\n\n"+
+			"Pesanan Anda akan segera kami proses. Terima kasih! 🙏",
+		order.OrderNumber,
+		formatPrice(order.TotalAmount),
+	), true
+}
+
+// CancelAwaitingCOD cancels the customer's latest COD order still waiting for confirmation.
+// Returns false if there is none; the customer is notified by CancelOrder.
+func (s *OrderService) CancelAwaitingCOD(clientID, customerPhone string) bool {
+	order, err := s.orderRepo.GetLatestAwaitingCOD(clientID, customerPhone, time.Now().Add(-codConfirmWindow))
+	if err != nil {
+		return false
+	}
+
+	if err := s.CancelOrder(order.ID.String(), "Dibatalkan atas permintaan Anda"); err != nil {
+		log.Printf("⚠️  Failed to cancel COD order %s: %v", order.OrderNumber, err)
+		return false
+	}
+	return true
+}
+
+// ConfirmCODCash marks a COD order paid once the driver or admin has received the cash
+func (s *OrderService) ConfirmCODCash(orderID, clientID, collectedBy string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, ErrOrderNotFound
+	}
+
+	if !isCOD(order) {
+		return nil, errors.New("order is not a COD order")
+	}
+	if !isUnpaidCOD(order) {
+		return nil, fmt.Errorf("cannot collect cash for order with payment status %s", order.PaymentStatus)
+	}
+
+	now := time.Now()
+	order.PaymentStatus = models.PaymentStatusPaid
+	order.PaidAt = &now
+	order.PaymentReference = "COD"
+	order.CODCollectedBy = collectedBy
+	if order.FulfillmentStatus == models.FulfillmentStatusPending {
+		order.FulfillmentStatus = models.FulfillmentStatusProcessing
+	}
+
+	if err := s.orderRepo.Update(order); err != nil {
+		return nil, fmt.Errorf("failed to update order: %w", err)
+	}
+
+	log.Printf("💵 COD cash received for order %s by %s", order.OrderNumber, collectedBy)
+
+	message := fmt.Sprintf(
+		"✅ *Pembayaran COD Diterima*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Total: *Rp %s*\n\n"+
+			"Terima kasih telah berbelanja! 🙏",
+		order.OrderNumber,
+		formatPrice(order.TotalAmount),
+	)
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+
+	if s.notificationSvc != nil && !order.IsTest {
+		tenantAdmin := s.getTenantAdminContact(order.ClientID)
+		if tenantAdmin != nil {
+			if err := s.notificationSvc.NotifyPaymentConfirmed(tenantAdmin, order.OrderNumber, order.CustomerPhone, order.TotalAmount); err != nil {
+				log.Printf("⚠️  Failed to send payment confirmation notification to admin: %v", err)
+			}
+		}
+	}
+
+	return order, nil
+}
+
+// GetCODSettings returns the COD settings configured for a client
+func (s *OrderService) GetCODSettings(clientID string) (*models.CODSettings, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.getCODSettings(clientID), nil
+}
+
+// UpdateCODSettings saves the COD settings for a client
+func (s *OrderService) UpdateCODSettings(clientID string, settings *models.CODSettings) (*models.CODSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if settings.MaxOrderAmount < 0 || settings.MinPaidOrders < 0 || settings.MaxOpenOrders < 0 || settings.MaxFailedOrders < 0 {
+		return nil, errors.New("limits must not be negative")
+	}
+
+	settings.ID = uuid.Nil
+	settings.ClientID = uid
+	if err := s.codRepo.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save COD settings: %w", err)
+	}
+
+	return s.getCODSettings(clientID), nil
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// ErrShippingDetailsRequired is returned when an order is shipped without its courier and tracking number
+var ErrShippingDetailsRequired = errors.New("courier and tracking_number are required to ship an order")
+
+// fulfillmentTransitions lists the stages a paid order can be marked with from each stage. Packing is
+// optional, and orders picked up by the customer go straight to delivered.
+var fulfillmentTransitions = map[string][]string{
+	models.FulfillmentStatusProcessing: {models.FulfillmentStatusPacked, models.FulfillmentStatusShipped, models.FulfillmentStatusDelivered},
+	models.FulfillmentStatusPacked:     {models.FulfillmentStatusShipped, models.FulfillmentStatusDelivered},
+	models.FulfillmentStatusShipped:    {models.FulfillmentStatusDelivered},
+}
+
+// UpdateFulfillment marks an order packed, shipped (with its courier and tracking number) or delivered and
+// sends the customer a WhatsApp update. Sending shipped again corrects the courier or tracking number.
+func (s *OrderService) UpdateFulfillment(clientID, orderID string, req *models.FulfillmentUpdateRequest) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, ErrOrderNotFound
+	}
+
+	status := strings.ToLower(strings.TrimSpace(req.Status))
+	courier := strings.TrimSpace(req.Courier)
+	trackingNumber := strings.TrimSpace(req.TrackingNumber)
+
+	if status == order.FulfillmentStatus {
+		if status != models.FulfillmentStatusShipped || (courier == order.ShippingCourier && trackingNumber == order.ShippingTrackingNumber) {
+			return order, nil
+		}
+	} else {
+		if order.FulfillmentStatus == models.FulfillmentStatusPending && order.PaymentStatus != models.PaymentStatusPaid && !isCOD(order) {
+			return nil, ErrOrderUnpaid
+		}
+		if !canUpdateFulfillment(order.FulfillmentStatus, status) {
+			return nil, fmt.Errorf("%w: %s to %s", ErrInvalidStageMove, order.FulfillmentStatus, status)
+		}
+	}
+	if status == models.FulfillmentStatusShipped && (courier == "" || trackingNumber == "") {
+		return nil, ErrShippingDetailsRequired
+	}
+
+	now := time.Now()
+	switch status {
+	case models.FulfillmentStatusPacked:
+		order.PackedAt = &now
+	case models.FulfillmentStatusShipped:
+		order.ShippingCourier = courier
+		order.ShippingTrackingNumber = trackingNumber
+		if order.FulfillmentStatus != status {
+			order.ShippedAt = &now
+		}
+	case models.FulfillmentStatusDelivered:
+		order.DeliveredAt = &now
+	}
+	order.FulfillmentStatus = status
+
+	if err := s.orderRepo.Update(order); err != nil {
+		return nil, fmt.Errorf("failed to update fulfillment: %w", err)
+	}
+
+	log.Printf("📦 Order %s marked %s", order.OrderNumber, status)
+
+	s.sendFulfillmentUpdate(order)
+	return order, nil
+}
+
+// canUpdateFulfillment reports whether an order may be marked with a stage from its current stage
+func canUpdateFulfillment(from, to string) bool {
+	for _, stage := range fulfillmentTransitions[from] {
+		if stage == to {
+			return true
+		}
+	}
+	return false
+}
+
+// sendFulfillmentUpdate tells the customer their order reached a fulfillment stage
+func (s *OrderService) sendFulfillmentUpdate(order *models.Order) {
+	var message string
+	switch order.FulfillmentStatus {
+	case models.FulfillmentStatusPacked:
+		message = fmt.Sprintf("📦 *Pesanan Sedang Dikemas*\n\nNo. Pesanan: *#%s*\n\nPesanan Anda sedang dikemas dan akan segera dikirim.", order.OrderNumber)
+	case models.FulfillmentStatusShipped:
+		message = fmt.Sprintf(
+			"🚚 *Pesanan Dikirim*\n\n"+
+				"No. Pesanan: *#%s*\n"+
+				"Kurir: *%s*\n"+
+				"No. Resi: *%s*\n\n"+
+				"Gunakan nomor resi di atas untuk melacak paket Anda di situs kurir.",
+			order.OrderNumber,
+			order.ShippingCourier,
+			order.ShippingTrackingNumber,
+		)
+	case models.FulfillmentStatusDelivered:
+		message = fmt.Sprintf("✅ *Pesanan Telah Diterima*\n\nNo. Pesanan: *#%s*\n\nTerima kasih telah berbelanja! 🙏", order.OrderNumber)
+	default:
+		return
+	}
+	message += s.trackingLine(order)
+
+	if err := s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message); err != nil {
+		log.Printf("⚠️ Failed to send fulfillment update to %s: %v", order.CustomerPhone, err)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// MaxIdempotencyKeyLength bounds the Idempotency-Key a client can send
+const MaxIdempotencyKeyLength = 255
+
+// ErrIdempotencyKeyReused is returned when an idempotency key is sent again with a different order
+var ErrIdempotencyKeyReused = errors.New("idempotency key was already used for a different order")
+
+// findIdempotentOrder returns the order an earlier request with the same idempotency key created, with the
+// payment result it returned. It returns nil when the key is new.
+func (s *OrderService) findIdempotentOrder(req *CreateOrderRequest, fingerprint string) (*models.Order, *payment.ProcessResult, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	order, err := s.orderRepo.GetByIdempotencyKey(clientID, req.IdempotencyKey)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to look up idempotency key: %w", err)
+	}
+	if order.IdempotencyFingerprint != fingerprint {
+		return nil, nil, ErrIdempotencyKeyReused
+	}
+
+	// Held orders and orders whose first request is still running have no payment result (yet)
+	var result *payment.ProcessResult
+	if len(order.IdempotencyResult) > 0 && string(order.IdempotencyResult) != "null" {
+		result = &payment.ProcessResult{}
+		if err := json.Unmarshal(order.IdempotencyResult, result); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode stored payment result: %w", err)
+		}
+	}
+
+	log.Printf("🔁 Idempotent retry of order %s (Client: %s)", order.OrderNumber, req.ClientID)
+	return order, result, nil
+}
+
+// saveIdempotencyResult stores the payment result returned with an order created under an idempotency key
+func (s *OrderService) saveIdempotencyResult(order *models.Order, result *payment.ProcessResult) {
+	if order.IdempotencyKey == nil || result == nil {
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		log.Printf("⚠️  Failed to encode payment result of order %s: %v", order.OrderNumber, err)
+		return
+	}
+	order.IdempotencyResult = datatypes.JSON(data)
+	if err := s.orderRepo.SaveIdempotencyResult(order.ID, order.IdempotencyResult); err != nil {
+		log.Printf("⚠️  Failed to store payment result of order %s: %v", order.OrderNumber, err)
+	}
+}
+
+// orderRequestFingerprint hashes what an order request asks for, so a reused key with a different order is refused
+func orderRequestFingerprint(req *CreateOrderRequest) string {
+	data, _ := json.Marshal(struct {
+		CustomerPhone string
+		Items         []payment.OrderItem
+		TotalAmount   float64
+		BranchID      string
+		PaymentMethod string
+	}{req.CustomerPhone, req.Items, req.TotalAmount, req.BranchID, strings.ToLower(strings.TrimSpace(req.PaymentMethod))})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
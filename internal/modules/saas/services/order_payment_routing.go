@@ -0,0 +1,222 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// routingPaymentMethods are the payment methods routing rules can match on
+var routingPaymentMethods = []string{
+	payment.MethodBankTransfer,
+	payment.MethodQRIS,
+	payment.MethodEWallet,
+	payment.MethodCreditCard,
+}
+
+// routingSegments are the customer segments routing rules can match on
+var routingSegments = []string{
+	models.CustomerSegmentNew,
+	models.CustomerSegmentReturning,
+}
+
+// routePayment picks the gateway for a new order from the tenant's routing policy.
+// Returns the gateway and the route recorded on the order.
+func (s *OrderService) routePayment(req *CreateOrderRequest, isTest bool) (payment.Gateway, string) {
+	method := strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+
+	// COD orders never get a gateway link, so they skip routing entirely
+	if method == payment.MethodCOD {
+		if gateway, ok := s.paymentGateways.Get(payment.GatewayCOD); ok {
+			return gateway, ""
+		}
+	}
+
+	if isTest && s.sandboxGateway != nil {
+		return s.sandboxGateway, ""
+	}
+
+	policy := s.getRoutingPolicy(req.ClientID)
+	if policy == nil || !policy.Enabled {
+		return s.paymentGateways.Default(), models.PaymentRouteDefault
+	}
+
+	var rules []models.PaymentRoutingRule
+	if len(policy.Rules) > 0 {
+		if err := json.Unmarshal(policy.Rules, &rules); err != nil {
+			log.Printf("⚠️  Invalid payment routing rules for client %s: %v", req.ClientID, err)
+		}
+	}
+
+	// The customer's segment is only looked up when a rule needs it
+	segment := ""
+	customerSegment := func() string {
+		if segment == "" {
+			segment = s.customerSegment(req.ClientID, req.CustomerPhone)
+		}
+		return segment
+	}
+
+	for i, rule := range rules {
+		if !routingRuleMatches(rule, method, req.TotalAmount, customerSegment) {
+			continue
+		}
+
+		gateway, ok := s.paymentGateways.Get(rule.Gateway)
+		if !ok {
+			log.Printf("⚠️  Payment routing rule %d for client %s uses unavailable gateway %s", i+1, req.ClientID, rule.Gateway)
+			continue
+		}
+		return gateway, routeLabel(i, rule)
+	}
+
+	if gateway, ok := s.paymentGateways.Get(policy.DefaultGateway); ok {
+		return gateway, models.PaymentRouteDefault
+	}
+	return s.paymentGateways.Default(), models.PaymentRouteDefault
+}
+
+// routingRuleMatches checks a rule's conditions; empty conditions match any order
+func routingRuleMatches(rule models.PaymentRoutingRule, method string, amount float64, segment func() string) bool {
+	if len(rule.PaymentMethods) > 0 && !slices.Contains(rule.PaymentMethods, method) {
+		return false
+	}
+	if rule.MinAmount > 0 && amount < rule.MinAmount {
+		return false
+	}
+	if rule.MaxAmount > 0 && amount > rule.MaxAmount {
+		return false
+	}
+	if len(rule.Segments) > 0 && !slices.Contains(rule.Segments, segment()) {
+		return false
+	}
+	return true
+}
+
+// routeLabel names the matched rule for the order record
+func routeLabel(index int, rule models.PaymentRoutingRule) string {
+	if rule.Name != "" {
+		return fmt.Sprintf("rule %d: %s", index+1, rule.Name)
+	}
+	return fmt.Sprintf("rule %d", index+1)
+}
+
+// customerSegment classifies the customer by order history
+func (s *OrderService) customerSegment(clientID, customerPhone string) string {
+	_, count, err := s.orderRepo.GetCustomerAverageAmount(clientID, customerPhone)
+	if err == nil && count > 0 {
+		return models.CustomerSegmentReturning
+	}
+	return models.CustomerSegmentNew
+}
+
+// getRoutingPolicy returns the tenant's routing policy, or nil when none is configured
+func (s *OrderService) getRoutingPolicy(clientID string) *models.PaymentRoutingPolicy {
+	if s.routingRepo == nil {
+		return nil
+	}
+	policy, err := s.routingRepo.GetByClientID(clientID)
+	if err != nil {
+		return nil
+	}
+	return policy
+}
+
+// GetPaymentRouting returns the routing policy configured for a client (disabled and empty if none)
+func (s *OrderService) GetPaymentRouting(clientID string) (*models.PaymentRoutingPolicy, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if policy := s.getRoutingPolicy(clientID); policy != nil {
+		return policy, nil
+	}
+	return &models.PaymentRoutingPolicy{
+		ClientID: uid,
+		Rules:    datatypes.JSON("[]"),
+	}, nil
+}
+
+// UpdatePaymentRouting validates and saves the routing policy for a client
+func (s *OrderService) UpdatePaymentRouting(clientID string, req *models.UpdatePaymentRoutingRequest) (*models.PaymentRoutingPolicy, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if req.DefaultGateway != "" {
+		if _, ok := s.paymentGateways.Get(req.DefaultGateway); !ok {
+			return nil, fmt.Errorf("default_gateway %q is not available (available: %s)", req.DefaultGateway, strings.Join(s.paymentGateways.Keys(), ", "))
+		}
+	}
+
+	rules := make([]models.PaymentRoutingRule, len(req.Rules))
+	for i, rule := range req.Rules {
+		if err := s.validateRoutingRule(&rule); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		rules[i] = rule
+	}
+
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &models.PaymentRoutingPolicy{
+		ClientID:       uid,
+		Enabled:        req.Enabled,
+		DefaultGateway: req.DefaultGateway,
+		Rules:          datatypes.JSON(rulesJSON),
+	}
+	if err := s.routingRepo.Upsert(policy); err != nil {
+		return nil, fmt.Errorf("failed to save payment routing: %w", err)
+	}
+
+	return s.GetPaymentRouting(clientID)
+}
+
+// AvailablePaymentGateways lists the gateway keys routing rules can use
+func (s *OrderService) AvailablePaymentGateways() []string {
+	return s.paymentGateways.Keys()
+}
+
+// validateRoutingRule normalizes a rule and checks it references known gateways, methods and segments
+func (s *OrderService) validateRoutingRule(rule *models.PaymentRoutingRule) error {
+	if _, ok := s.paymentGateways.Get(rule.Gateway); !ok {
+		return fmt.Errorf("gateway %q is not available (available: %s)", rule.Gateway, strings.Join(s.paymentGateways.Keys(), ", "))
+	}
+
+	for i, method := range rule.PaymentMethods {
+		method = strings.ToLower(strings.TrimSpace(method))
+		if !slices.Contains(routingPaymentMethods, method) {
+			return fmt.Errorf("unknown payment method %q", method)
+		}
+		rule.PaymentMethods[i] = method
+	}
+
+	for i, segment := range rule.Segments {
+		segment = strings.ToLower(strings.TrimSpace(segment))
+		if !slices.Contains(routingSegments, segment) {
+			return fmt.Errorf("unknown segment %q", segment)
+		}
+		rule.Segments[i] = segment
+	}
+
+	if rule.MinAmount < 0 || rule.MaxAmount < 0 {
+		return fmt.Errorf("amounts must not be negative")
+	}
+	if rule.MaxAmount > 0 && rule.MinAmount > rule.MaxAmount {
+		return fmt.Errorf("min_amount must not exceed max_amount")
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// ErrRefundNotAllowed is returned when an order is not paid or the amount exceeds what is left to refund
+var ErrRefundNotAllowed = errors.New("refund not allowed")
+
+// RefundOrderRequest is a full or partial refund of a paid order
+type RefundOrderRequest struct {
+	Amount      float64 `json:"amount"` // 0 = everything not refunded yet
+	Reason      string  `json:"reason"`
+	RequestedBy string  `json:"requested_by"`
+}
+
+// RefundOrder refunds a paid order through its payment gateway (partial refunds can be repeated up to the total).
+// A full refund of an order that hasn't shipped cancels its fulfillment and returns the branch stock.
+func (s *OrderService) RefundOrder(orderID string, req *RefundOrderRequest) (*models.Order, *models.OrderRefund, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, nil, ErrOrderNotFound
+	}
+
+	if order.PaymentStatus != models.PaymentStatusPaid && order.PaymentStatus != models.PaymentStatusPartiallyRefunded {
+		return nil, nil, fmt.Errorf("%w: payment status is %s", ErrRefundNotAllowed, order.PaymentStatus)
+	}
+
+	remaining := roundAmount(order.TotalAmount - order.RefundedAmount)
+	amount := roundAmount(req.Amount)
+	if amount == 0 {
+		amount = remaining
+	}
+	if amount < 0 {
+		return nil, nil, fmt.Errorf("%w: amount must be positive", ErrRefundNotAllowed)
+	}
+	if amount > remaining {
+		return nil, nil, fmt.Errorf("%w: amount exceeds the refundable Rp %s", ErrRefundNotAllowed, formatPrice(remaining))
+	}
+
+	// Reserve the amount first so a concurrent refund can't take it too
+	reserved, err := s.orderRepo.ReserveRefund(order.ID, amount)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to reserve refund: %w", err)
+	}
+	if !reserved {
+		return nil, nil, fmt.Errorf("%w: the order was refunded or changed meanwhile, reload it and retry", ErrRefundNotAllowed)
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "Pengembalian dana pesanan"
+	}
+
+	var result *payment.RefundResult
+	var gatewayName string
+	if order.SplitPayment && s.splitRepo != nil {
+		// Group orders were paid through one gateway transaction per payer
+		gatewayName = s.gatewayFor(order).Name()
+		result, err = s.refundSplitPortions(order, amount, reason)
+	} else if isWalletPaid(order) && s.walletSvc != nil {
+		// Orders paid from the wallet are refunded to the wallet balance
+		gatewayName = walletGatewayName
+		result, err = s.walletSvc.RefundOrder(order, amount, reason)
+	} else {
+		gateway := s.gatewayFor(order)
+		gatewayName = gateway.Name()
+		result, err = gateway.Refund(order.OrderNumber, amount, reason)
+	}
+	if err != nil {
+		if releaseErr := s.orderRepo.ReleaseRefund(order.ID, amount); releaseErr != nil {
+			log.Printf("❌ Failed to release refund reservation of Rp %s on order %s: %v", formatPrice(amount), order.OrderNumber, releaseErr)
+		}
+		return nil, nil, fmt.Errorf("refund failed: %w", err)
+	}
+	refunded := amount
+	if result.Amount > 0 {
+		refunded = result.Amount
+	}
+
+	refund := &models.OrderRefund{
+		ClientID:    order.ClientID,
+		OrderID:     order.ID,
+		Amount:      refunded,
+		Reason:      reason,
+		Gateway:     gatewayName,
+		Reference:   result.Reference,
+		Status:      result.Status,
+		RequestedBy: req.RequestedBy,
+		CreatedAt:   time.Now(),
+	}
+	releaseStock, err := s.orderRepo.RecordRefund(order, refund, amount)
+	if err != nil {
+		// The gateway already refunded; the reference lets an admin reconcile the record
+		log.Printf("❌ Refund %s of order %s succeeded at the gateway but was not saved: %v", result.Reference, order.OrderNumber, err)
+		return nil, nil, fmt.Errorf("failed to save refund (gateway reference %s): %w", result.Reference, err)
+	}
+	amount = refunded
+
+	log.Printf("✅ Order %s refunded Rp %s (%s, total refunded Rp %s)", order.OrderNumber, formatPrice(amount), result.Status, formatPrice(order.RefundedAmount))
+
+	if releaseStock {
+		s.releaseBranchStock(order)
+	}
+
+	s.sendRefundNotice(order, refund)
+
+	// Notify tenant admin
+	if s.notificationSvc != nil && !order.IsTest {
+		tenantAdmin := s.getTenantAdminContact(order.ClientID)
+		if tenantAdmin != nil {
+			if err := s.notificationSvc.NotifyOrderRefunded(tenantAdmin, order.OrderNumber, order.CustomerPhone, amount, order.RefundedAmount, reason); err != nil {
+				log.Printf("⚠️  Failed to send refund notification to admin: %v", err)
+			}
+		}
+	}
+
+	s.notifyBranchAdmin(order, "Dana Dikembalikan", fmt.Sprintf("*Refund:* Rp %s\n*Alasan:* %s", formatPrice(amount), reason))
+
+	return order, refund, nil
+}
+
+// ListRefunds returns the refunds of an order oldest first
+func (s *OrderService) ListRefunds(orderID string) ([]models.OrderRefund, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	return s.orderRepo.ListRefunds(order.ID)
+}
+
+// sendRefundNotice tells the customer how much is refunded and when to expect it
+func (s *OrderService) sendRefundNotice(order *models.Order, refund *models.OrderRefund) {
+	eta := "Dana akan masuk ke metode pembayaran Anda dalam beberapa hari kerja."
+	if refund.Status == payment.RefundStatusPending {
+		eta = "Admin kami akan segera mentransfer dana tersebut kepada Anda."
+	}
+	if refund.Gateway == walletGatewayName {
+		eta = "Dana sudah dikembalikan ke saldo Anda."
+	}
+
+	message := fmt.Sprintf(
+		"↩️ *Pengembalian Dana*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Jumlah: *Rp %s*\n"+
+			"*Alasan:* %s\n\n"+
+			"%s Terima kasih atas pengertiannya! 🙏",
+		order.OrderNumber,
+		formatPrice(refund.Amount),
+		refund.Reason,
+		eta,
+	)
+
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+}
+
+// roundAmount rounds to two decimals so repeated partial refunds add up to the total exactly
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,313 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Risk score weights for each rule
+const (
+	riskScoreBlockedPhone  = 100
+	riskScoreAbnormalValue = 40
+	riskScoreHighVelocity  = 40
+	riskScoreProofMismatch = 60
+)
+
+// getRiskRules returns the tenant's risk rules, falling back to defaults
+func (s *OrderService) getRiskRules(clientID string) *models.OrderRiskRule {
+	if s.riskRepo != nil {
+		rule, err := s.riskRepo.GetByClientID(clientID)
+		if err == nil {
+			return rule
+		}
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	return models.DefaultOrderRiskRule(uid)
+}
+
+// scoreOrder evaluates the tenant's risk rules against a new order
+func (s *OrderService) scoreOrder(req *CreateOrderRequest) (int, []models.RiskFlag, bool) {
+	rules := s.getRiskRules(req.ClientID)
+	if !rules.Enabled {
+		return 0, nil, false
+	}
+
+	var flags []models.RiskFlag
+
+	// Blocked number list
+	for _, blocked := range rules.BlockedPhones {
+		if normalizePhone(blocked) == normalizePhone(req.CustomerPhone) {
+			flags = append(flags, models.RiskFlag{
+				Code:   models.RiskFlagBlockedPhone,
+				Score:  riskScoreBlockedPhone,
+				Detail: "customer phone is on the blocked list",
+			})
+			break
+		}
+	}
+
+	// Abnormal order value (absolute limit)
+	if rules.MaxOrderAmount > 0 && req.TotalAmount > rules.MaxOrderAmount {
+		flags = append(flags, models.RiskFlag{
+			Code:   models.RiskFlagAbnormalValue,
+			Score:  riskScoreAbnormalValue,
+			Detail: fmt.Sprintf("total Rp %.0f exceeds limit Rp %.0f", req.TotalAmount, rules.MaxOrderAmount),
+		})
+	} else if rules.ValueMultiplier > 0 {
+		// Abnormal order value compared to this customer's history
+		avg, count, err := s.orderRepo.GetCustomerAverageAmount(req.ClientID, req.CustomerPhone)
+		if err != nil {
+			log.Printf("⚠️  Failed to get order history for %s: %v", req.CustomerPhone, err)
+		} else if count >= int64(rules.MinOrderHistory) && avg > 0 && req.TotalAmount > avg*rules.ValueMultiplier {
+			flags = append(flags, models.RiskFlag{
+				Code:   models.RiskFlagAbnormalValue,
+				Score:  riskScoreAbnormalValue,
+				Detail: fmt.Sprintf("total Rp %.0f is over %.1fx the customer average Rp %.0f", req.TotalAmount, rules.ValueMultiplier, avg),
+			})
+		}
+	}
+
+	// Many orders from one phone in a short window
+	if rules.VelocityMaxOrders > 0 && rules.VelocityWindowMinutes > 0 {
+		since := time.Now().Add(-time.Duration(rules.VelocityWindowMinutes) * time.Minute)
+		recent, err := s.orderRepo.CountByCustomerSince(req.ClientID, req.CustomerPhone, since)
+		if err != nil {
+			log.Printf("⚠️  Failed to count recent orders for %s: %v", req.CustomerPhone, err)
+		} else if recent >= int64(rules.VelocityMaxOrders) {
+			flags = append(flags, models.RiskFlag{
+				Code:   models.RiskFlagHighVelocity,
+				Score:  riskScoreHighVelocity,
+				Detail: fmt.Sprintf("%d orders in the last %d minutes", recent+1, rules.VelocityWindowMinutes),
+			})
+		}
+	}
+
+	score := 0
+	for _, flag := range flags {
+		score += flag.Score
+	}
+
+	return score, flags, score >= rules.ReviewThreshold
+}
+
+// holdOrderForReview tells the customer the order is being verified and alerts the tenant admin
+func (s *OrderService) holdOrderForReview(order *models.Order, flags []models.RiskFlag) {
+	message := fmt.Sprintf(
+		"📝 *Pesanan Diterima*\n\n"+
+			"No. Pesanan: *#%s*\n"+
+			"Total: *Rp %s*\n\n"+
+			"Pesanan Anda sedang kami verifikasi. Instruksi pembayaran akan dikirim setelah verifikasi selesai. Terima kasih! 🙏",
+		order.OrderNumber,
+		formatPrice(order.TotalAmount),
+	)
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+
+	if s.notificationSvc != nil && !order.IsTest {
+		tenantAdmin := s.getTenantAdminContact(order.ClientID)
+		if tenantAdmin != nil {
+			if err := s.notificationSvc.NotifyOrderNeedsReview(tenantAdmin, order.OrderNumber, order.CustomerPhone, order.TotalAmount, order.RiskScore, formatRiskFlags(flags)); err != nil {
+				log.Printf("⚠️  Failed to send review notification to admin: %v", err)
+			}
+		}
+	}
+}
+
+// ReviewOrderRequest represents an admin decision on a held order
+type ReviewOrderRequest struct {
+	Action string `json:"action"` // approve, reject
+	Reason string `json:"reason,omitempty"`
+}
+
+// ReviewOrder lets an admin approve or reject an order held for review
+func (s *OrderService) ReviewOrder(orderID string, req *ReviewOrderRequest) (*models.Order, *payment.ProcessResult, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if order.ReviewStatus != models.ReviewStatusNeedsReview {
+		return nil, nil, fmt.Errorf("order is not awaiting review")
+	}
+
+	now := time.Now()
+	order.ReviewedAt = &now
+
+	switch req.Action {
+	case "approve":
+		order.ReviewStatus = models.ReviewStatusApproved
+		if err := s.orderRepo.Update(order); err != nil {
+			return nil, nil, err
+		}
+
+		log.Printf("✅ Order %s approved after review", order.OrderNumber)
+
+		items, err := paymentItemsFromOrder(order)
+		if err != nil {
+			return order, nil, err
+		}
+
+		result, err := s.initiatePayment(order, items)
+		if err != nil {
+			return order, nil, err
+		}
+		return order, result, nil
+
+	case "reject":
+		order.ReviewStatus = models.ReviewStatusRejected
+		if err := s.orderRepo.Update(order); err != nil {
+			return nil, nil, err
+		}
+
+		log.Printf("❌ Order %s rejected after review", order.OrderNumber)
+
+		if err := s.CancelOrder(order.ID.String(), req.Reason); err != nil {
+			return order, nil, err
+		}
+		return order, nil, nil
+
+	default:
+		return nil, nil, fmt.Errorf("invalid review action: %s", req.Action)
+	}
+}
+
+// VerifyPaymentProof compares a payment proof amount with the order total and holds the order on mismatch
+func (s *OrderService) VerifyPaymentProof(orderID string, proofAmount float64) error {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+
+	rules := s.getRiskRules(order.ClientID.String())
+	if !rules.Enabled {
+		return nil
+	}
+
+	diff := math.Abs(order.TotalAmount - proofAmount)
+	if diff <= rules.ProofAmountTolerance {
+		return nil
+	}
+
+	var flags []models.RiskFlag
+	if len(order.RiskFlags) > 0 {
+		if err := json.Unmarshal(order.RiskFlags, &flags); err != nil {
+			// Still hold the order: the mismatch flag replaces the unreadable ones
+			log.Printf("⚠️ Failed to parse risk flags of order %s: %v", order.OrderNumber, err)
+			flags = nil
+		}
+	}
+
+	flag := models.RiskFlag{
+		Code:   models.RiskFlagProofMismatch,
+		Score:  riskScoreProofMismatch,
+		Detail: fmt.Sprintf("payment proof Rp %.0f does not match total Rp %.0f", proofAmount, order.TotalAmount),
+	}
+	flags = append(flags, flag)
+
+	flagsJSON, err := json.Marshal(flags)
+	if err != nil {
+		return fmt.Errorf("failed to marshal risk flags: %w", err)
+	}
+
+	order.RiskFlags = datatypes.JSON(flagsJSON)
+	order.RiskScore += flag.Score
+	if order.RiskScore >= rules.ReviewThreshold {
+		order.ReviewStatus = models.ReviewStatusNeedsReview
+	}
+
+	if err := s.orderRepo.Update(order); err != nil {
+		return err
+	}
+
+	log.Printf("⚠️  Payment proof mismatch for order %s: proof %.2f, total %.2f", order.OrderNumber, proofAmount, order.TotalAmount)
+
+	if s.notificationSvc != nil && !order.IsTest {
+		tenantAdmin := s.getTenantAdminContact(order.ClientID)
+		if tenantAdmin != nil {
+			if err := s.notificationSvc.NotifyOrderNeedsReview(tenantAdmin, order.OrderNumber, order.CustomerPhone, order.TotalAmount, order.RiskScore, formatRiskFlags(flags)); err != nil {
+				log.Printf("⚠️  Failed to send review notification to admin: %v", err)
+			}
+		}
+	}
+
+	return fmt.Errorf("payment proof amount Rp %.0f does not match order total Rp %.0f", proofAmount, order.TotalAmount)
+}
+
+// GetRiskRules returns the risk rules configured for a client
+func (s *OrderService) GetRiskRules(clientID string) (*models.OrderRiskRule, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.getRiskRules(clientID), nil
+}
+
+// UpdateRiskRules saves the risk rules for a client
+func (s *OrderService) UpdateRiskRules(clientID string, rule *models.OrderRiskRule) (*models.OrderRiskRule, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if rule.ReviewThreshold <= 0 {
+		return nil, fmt.Errorf("review_threshold must be greater than 0")
+	}
+
+	rule.ID = uuid.Nil
+	rule.ClientID = uid
+	if err := s.riskRepo.Upsert(rule); err != nil {
+		return nil, fmt.Errorf("failed to save risk rules: %w", err)
+	}
+
+	return s.getRiskRules(clientID), nil
+}
+
+// paymentItemsFromOrder rebuilds gateway items from the stored order items
+func paymentItemsFromOrder(order *models.Order) ([]payment.OrderItem, error) {
+	var orderItems []models.OrderItem
+	if err := json.Unmarshal(order.Items, &orderItems); err != nil {
+		return nil, fmt.Errorf("failed to parse order items: %w", err)
+	}
+
+	items := make([]payment.OrderItem, len(orderItems))
+	for i, item := range orderItems {
+		productID, _ := uuid.Parse(item.ProductID)
+		items[i] = payment.OrderItem{
+			ProductID:   productID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Price,
+			Subtotal:    item.Subtotal,
+		}
+	}
+
+	return items, nil
+}
+
+// formatRiskFlags formats risk flags for admin notifications
+func formatRiskFlags(flags []models.RiskFlag) string {
+	lines := make([]string, len(flags))
+	for i, flag := range flags {
+		lines[i] = fmt.Sprintf("- %s (+%d): %s", flag.Code, flag.Score, flag.Detail)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// normalizePhone strips formatting so phone numbers can be compared
+func normalizePhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	phone = strings.TrimSuffix(phone, "@c.us")
+	phone = strings.NewReplacer("+", "", "-", "", " ", "").Replace(phone)
+	if strings.HasPrefix(phone, "0") {
+		phone = "62" + phone[1:]
+	}
+	return phone
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/notification"
@@ -17,40 +18,87 @@ import (
 type OrderService struct {
 	orderRepo       repositories.OrderRepo
 	clientRepo      repositories.ClientRepo
-	paymentGateway  payment.Gateway
+	paymentGateways *payment.Registry
+	sandboxGateway  payment.Gateway
 	whatsappSvc     WhatsAppService
 	notificationSvc NotificationService
+	riskRepo        repositories.OrderRiskRepo
+	routingRepo     repositories.PaymentRoutingRepo
+	codRepo         repositories.CODSettingsRepo
+	sandboxSvc      *SandboxService
+	branchSvc       *BranchService
+	waitlistSvc     *WaitlistService
+	walletSvc       *WalletService
+	splitRepo       repositories.SplitPaymentRepo
+	publicBaseURL   string
 }
 
 func NewOrderService(
 	orderRepo repositories.OrderRepo,
 	clientRepo repositories.ClientRepo,
-	paymentGateway payment.Gateway,
+	riskRepo repositories.OrderRiskRepo,
+	routingRepo repositories.PaymentRoutingRepo,
+	codRepo repositories.CODSettingsRepo,
+	paymentGateways *payment.Registry,
+	sandboxGateway payment.Gateway,
 	whatsappSvc WhatsAppService,
 	notificationSvc NotificationService,
+	sandboxSvc *SandboxService,
+	branchSvc *BranchService,
+	waitlistSvc *WaitlistService,
+	publicBaseURL string,
 ) *OrderService {
 	return &OrderService{
 		orderRepo:       orderRepo,
 		clientRepo:      clientRepo,
-		paymentGateway:  paymentGateway,
+		paymentGateways: paymentGateways,
+		sandboxGateway:  sandboxGateway,
 		whatsappSvc:     whatsappSvc,
 		notificationSvc: notificationSvc,
+		riskRepo:        riskRepo,
+		routingRepo:     routingRepo,
+		codRepo:         codRepo,
+		sandboxSvc:      sandboxSvc,
+		branchSvc:       branchSvc,
+		waitlistSvc:     waitlistSvc,
+		publicBaseURL:   strings.TrimRight(publicBaseURL, "/"),
 	}
 }
 
 // CreateOrderRequest represents the request to create an order
 type CreateOrderRequest struct {
-	ClientID      string
-	CustomerPhone string
-	CustomerName  string
-	Items         []payment.OrderItem
-	TotalAmount   float64
+	ClientID       string
+	CustomerPhone  string
+	CustomerName   string
+	Items          []payment.OrderItem
+	TotalAmount    float64
+	BranchID       string // Optional: fulfilling branch (defaults to the first branch with stock)
+	PaymentMethod  string // Optional: customer's preferred payment method, used by gateway routing rules ("cod" for cash on delivery)
+	DeliveryNotes  string // Optional: customer's delivery notes, passed on to the driver
+	IdempotencyKey string // Optional: retries with the same key return the order this key created
 }
 
 // CreateOrder creates a new order and initiates payment
 func (s *OrderService) CreateOrder(req *CreateOrderRequest) (*models.Order, *payment.ProcessResult, error) {
+	// A retried request gets the order its idempotency key created instead of a duplicate
+	req.IdempotencyKey = strings.TrimSpace(req.IdempotencyKey)
+	var fingerprint string
+	if req.IdempotencyKey != "" {
+		fingerprint = orderRequestFingerprint(req)
+		order, result, err := s.findIdempotentOrder(req, fingerprint)
+		if order != nil || err != nil {
+			return order, result, err
+		}
+	}
+
+	// Sandbox tenants get test orders with simulated payments
+	isTest := s.sandboxSvc != nil && s.sandboxSvc.IsSandbox(req.ClientID)
+
 	// Generate order number
 	orderNumber := s.generateOrderNumber()
+	if isTest {
+		orderNumber = "TEST-" + orderNumber
+	}
 
 	// Convert payment.OrderItem to models.OrderItem and marshal to JSON
 	orderItems := make([]models.OrderItem, len(req.Items))
@@ -69,6 +117,39 @@ func (s *OrderService) CreateOrder(req *CreateOrderRequest) (*models.Order, *pay
 		return nil, nil, fmt.Errorf("failed to marshal items: %w", err)
 	}
 
+	// Cash on delivery is limited by the customer's order history
+	req.PaymentMethod = strings.ToLower(strings.TrimSpace(req.PaymentMethod))
+	if req.PaymentMethod == models.PaymentMethodCOD {
+		if err := s.checkCODEligibility(req.ClientID, req.CustomerPhone, req.TotalAmount); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Pick the fulfilling branch for multi-branch tenants
+	branch, err := s.assignBranch(req.ClientID, req.BranchID, orderItems)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Score order for fraud/anomaly risk before it is persisted
+	riskScore, riskFlags, needsReview := s.scoreOrder(req)
+	reviewStatus := models.ReviewStatusNone
+	if needsReview {
+		reviewStatus = models.ReviewStatusNeedsReview
+	}
+
+	var riskFlagsJSON datatypes.JSON
+	if len(riskFlags) > 0 {
+		flagsBytes, err := json.Marshal(riskFlags)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal risk flags: %w", err)
+		}
+		riskFlagsJSON = datatypes.JSON(flagsBytes)
+	}
+
+	// Pick the payment gateway from the tenant's routing rules
+	gateway, route := s.routePayment(req, isTest)
+
 	// Create order
 	order := &models.Order{
 		ClientID:          uuid.MustParse(req.ClientID),
@@ -78,63 +159,125 @@ func (s *OrderService) CreateOrder(req *CreateOrderRequest) (*models.Order, *pay
 		Items:             datatypes.JSON(itemsJSON),
 		TotalAmount:       req.TotalAmount,
 		PaymentStatus:     models.PaymentStatusPending,
-		PaymentGateway:    s.paymentGateway.Name(),
+		PaymentGateway:    gateway.Name(),
+		PaymentRoute:      route,
 		FulfillmentStatus: models.FulfillmentStatusPending,
+		RiskScore:         riskScore,
+		RiskFlags:         riskFlagsJSON,
+		ReviewStatus:      reviewStatus,
+		DeliveryNotes:     req.DeliveryNotes,
+		IsTest:            isTest,
+	}
+	if req.PaymentMethod == models.PaymentMethodCOD {
+		order.PaymentMethod = models.PaymentMethodCOD
+	}
+	if req.IdempotencyKey != "" {
+		order.IdempotencyKey = &req.IdempotencyKey
+		order.IdempotencyFingerprint = fingerprint
+	}
+
+	// Public tracking link included in customer messages
+	s.assignTrackingToken(order)
+
+	// Reserve branch stock before the order is saved
+	if branch != nil {
+		order.BranchID = &branch.ID
+		if err := s.branchSvc.ReserveStock(branch.ID, orderItems); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Save to database
 	if err = s.orderRepo.Create(order); err != nil {
+		if branch != nil {
+			s.branchSvc.ReleaseStock(branch.ID, orderItems)
+		}
+		// A concurrent request with the same idempotency key saved its order first
+		if req.IdempotencyKey != "" {
+			if existing, result, findErr := s.findIdempotentOrder(req, fingerprint); existing != nil || findErr != nil {
+				return existing, result, findErr
+			}
+		}
 		return nil, nil, fmt.Errorf("failed to create order: %w", err)
 	}
 
-	log.Printf("✅ Order created: %s (Client: %s, Total: %.2f)", orderNumber, req.ClientID, req.TotalAmount)
+	log.Printf("✅ Order created: %s (Client: %s, Total: %.2f, Risk: %d)", orderNumber, req.ClientID, req.TotalAmount, riskScore)
 
-	// Process payment
+	// Attribute the order to restock alerts and pre-orders it fulfills
+	s.trackWaitlistConversion(order)
+
+	// High-risk orders are held until an admin reviews them
+	if needsReview {
+		log.Printf("⚠️  Order %s needs review (risk score %d)", orderNumber, riskScore)
+		s.holdOrderForReview(order, riskFlags)
+		return order, nil, nil
+	}
+
+	// Prepaid customers pay from their wallet balance when it covers the order
+	result := s.payFromWallet(order)
+	if result == nil {
+		result, err = s.initiatePayment(order, req.Items)
+		if err != nil {
+			return order, nil, err
+		}
+	}
+	s.saveIdempotencyResult(order, result)
+
+	// Notify tenant admin about new order
+	if s.notificationSvc != nil && !order.IsTest {
+		tenantAdmin := s.getTenantAdminContact(order.ClientID)
+		if tenantAdmin != nil {
+			itemsText := s.formatItemsForNotification(req.Items)
+			if err := s.notificationSvc.NotifyNewOrder(tenantAdmin, orderNumber, req.CustomerPhone, req.TotalAmount, itemsText); err != nil {
+				log.Printf("⚠️  Failed to send admin notification: %v", err)
+			}
+		}
+	}
+
+	// Notify the branch admin that fulfills the order
+	s.notifyBranchAdmin(order, "Pesanan Baru", s.formatItemsForNotification(req.Items))
+
+	return order, result, nil
+}
+
+// initiatePayment processes payment for an order and sends payment instructions to the customer
+func (s *OrderService) initiatePayment(order *models.Order, items []payment.OrderItem) (*payment.ProcessResult, error) {
 	paymentOrder := &payment.Order{
 		ID:            order.ID,
 		ClientID:      order.ClientID,
 		OrderNumber:   order.OrderNumber,
 		CustomerPhone: order.CustomerPhone,
 		CustomerName:  order.CustomerName,
-		Items:         req.Items,
+		Items:         items,
 		TotalAmount:   order.TotalAmount,
 		Currency:      "IDR",
 		Status:        order.PaymentStatus,
 		CreatedAt:     order.CreatedAt,
 	}
 
-	result, err := s.paymentGateway.Process(paymentOrder)
+	gateway := s.gatewayFor(order)
+	result, err := gateway.Process(paymentOrder)
 	if err != nil {
-		log.Printf("❌ Payment processing failed for order %s: %v", orderNumber, err)
-		return order, nil, fmt.Errorf("payment processing failed: %w", err)
+		log.Printf("❌ Payment processing failed for order %s: %v", order.OrderNumber, err)
+		return nil, fmt.Errorf("payment processing failed: %w", err)
 	}
 
 	// Update order with payment details
-	if result.PaymentLink != "" {
+	if result.PaymentLink != "" || result.ExpiresAt != nil {
 		order.PaymentLink = result.PaymentLink
+		order.PaymentExpiresAt = result.ExpiresAt
 		if err := s.orderRepo.Update(order); err != nil {
-			log.Printf("⚠️  Failed to update payment link for order %s: %v", orderNumber, err)
+			log.Printf("⚠️  Failed to update payment link for order %s: %v", order.OrderNumber, err)
 			// Continue anyway, payment link in response is still valid
 		}
 	}
 
-	log.Printf("✅ Payment initiated for order %s via %s", orderNumber, s.paymentGateway.Name())
+	log.Printf("✅ Payment initiated for order %s via %s", order.OrderNumber, gateway.Name())
 
 	// Send payment instructions to customer via WhatsApp
-	s.sendPaymentInstructions(req.CustomerPhone, order, result)
-
-	// Notify tenant admin about new order
-	if s.notificationSvc != nil {
-		tenantAdmin := s.getTenantAdminContact(order.ClientID)
-		if tenantAdmin != nil {
-			itemsText := s.formatItemsForNotification(req.Items)
-			if err := s.notificationSvc.NotifyNewOrder(tenantAdmin, orderNumber, req.CustomerPhone, req.TotalAmount, itemsText); err != nil {
-				log.Printf("⚠️  Failed to send admin notification: %v", err)
-			}
-		}
-	}
+	s.sendPaymentInstructions(order.CustomerPhone, order, result)
 
-	return order, result, nil
+	return result, nil
 }
 
 // ConfirmPayment confirms payment for an order (used by admin for manual mode)
@@ -167,7 +310,7 @@ func (s *OrderService) ConfirmPayment(orderID string, paymentMethod, reference s
 	s.sendPaymentConfirmation(order)
 
 	// Notify tenant admin
-	if s.notificationSvc != nil {
+	if s.notificationSvc != nil && !order.IsTest {
 		tenantAdmin := s.getTenantAdminContact(order.ClientID)
 		if tenantAdmin != nil {
 			if err := s.notificationSvc.NotifyPaymentConfirmed(tenantAdmin, order.OrderNumber, order.CustomerPhone, order.TotalAmount); err != nil {
@@ -176,6 +319,8 @@ func (s *OrderService) ConfirmPayment(orderID string, paymentMethod, reference s
 		}
 	}
 
+	s.notifyBranchAdmin(order, "Pembayaran Diterima", "Silakan siapkan pesanan ini.")
+
 	return nil
 }
 
@@ -191,7 +336,7 @@ func (s *OrderService) CancelOrder(orderID string, reason string) error {
 	}
 
 	// Cancel payment
-	err = s.paymentGateway.Cancel(order.OrderNumber)
+	err = s.gatewayFor(order).Cancel(order.OrderNumber)
 	if err != nil {
 		log.Printf("⚠️  Failed to cancel payment for order %s: %v", order.OrderNumber, err)
 		// Continue anyway to cancel order
@@ -208,6 +353,9 @@ func (s *OrderService) CancelOrder(orderID string, reason string) error {
 
 	log.Printf("✅ Order cancelled: %s (Reason: %s)", order.OrderNumber, reason)
 
+	// Return reserved stock to the branch
+	s.releaseBranchStock(order)
+
 	// Default reason if not provided
 	if reason == "" {
 		reason = "Maaf, pesanan tidak dapat diproses"
@@ -222,10 +370,10 @@ func (s *OrderService) CancelOrder(orderID string, reason string) error {
 		order.OrderNumber,
 		reason,
 	)
-	s.whatsappSvc.SendMessage(order.CustomerPhone, customerMessage)
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, customerMessage)
 
 	// Notify tenant admin
-	if s.notificationSvc != nil {
+	if s.notificationSvc != nil && !order.IsTest {
 		tenantAdmin := s.getTenantAdminContact(order.ClientID)
 		if tenantAdmin != nil {
 			if err := s.notificationSvc.NotifyOrderCancelled(tenantAdmin, order.OrderNumber, order.CustomerPhone, reason); err != nil {
@@ -234,6 +382,8 @@ func (s *OrderService) CancelOrder(orderID string, reason string) error {
 		}
 	}
 
+	s.notifyBranchAdmin(order, "Pesanan Dibatalkan", "*Alasan:* "+reason)
+
 	return nil
 }
 
@@ -245,7 +395,7 @@ func (s *OrderService) GetOrderStatus(orderNumber string) (*models.Order, *payme
 	}
 
 	// Get payment status from gateway
-	paymentStatus, err := s.paymentGateway.GetStatus(orderNumber)
+	paymentStatus, err := s.gatewayFor(order).GetStatus(orderNumber)
 	if err != nil {
 		log.Printf("⚠️  Failed to get payment status for %s: %v", orderNumber, err)
 		paymentStatus = &payment.PaymentStatus{
@@ -301,8 +451,9 @@ func (s *OrderService) sendPaymentInstructions(customerPhone string, order *mode
 		formatPrice(order.TotalAmount),
 		result.Instructions,
 	)
+	message += s.trackingLine(order)
 
-	s.whatsappSvc.SendMessage(customerPhone, message)
+	s.messenger(order.ClientID).SendMessage(customerPhone, message)
 }
 
 // sendPaymentConfirmation sends payment confirmation to customer
@@ -316,8 +467,9 @@ func (s *OrderService) sendPaymentConfirmation(order *models.Order) {
 		order.OrderNumber,
 		formatPrice(order.TotalAmount),
 	)
+	message += s.trackingLine(order)
 
-	s.whatsappSvc.SendMessage(order.CustomerPhone, message)
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
 }
 
 // Helper function to format price
@@ -396,6 +548,11 @@ func (s *OrderService) ListOrders(clientID string, limit int) ([]models.Order, e
 	return s.orderRepo.GetByClientID(clientID, limit)
 }
 
+// ListBranchOrders lists orders fulfilled by a branch
+func (s *OrderService) ListBranchOrders(clientID, branchID string, limit int) ([]models.Order, error) {
+	return s.orderRepo.GetByBranchID(clientID, branchID, limit)
+}
+
 // ListCustomerOrders lists orders for a specific customer
 func (s *OrderService) ListCustomerOrders(clientID, customerPhone string, limit int) ([]models.Order, error) {
 	return s.orderRepo.GetByCustomerPhone(clientID, customerPhone, limit)
@@ -423,7 +580,29 @@ func (s *OrderService) sendOrderUpdateNotification(order *models.Order) {
 		"",
 	)
 
-	s.whatsappSvc.SendMessage(order.CustomerPhone, message)
+	s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+}
+
+// gatewayFor returns the sandbox gateway for test orders, otherwise the gateway recorded on the order
+// (COD orders always use the COD gateway since no payment is simulated)
+func (s *OrderService) gatewayFor(order *models.Order) payment.Gateway {
+	if isCOD(order) {
+		if gateway, ok := s.paymentGateways.Get(payment.GatewayCOD); ok {
+			return gateway
+		}
+	}
+	if order.IsTest && s.sandboxGateway != nil {
+		return s.sandboxGateway
+	}
+	if gateway, ok := s.paymentGateways.ByName(order.PaymentGateway); ok {
+		return gateway
+	}
+	return s.paymentGateways.Default()
+}
+
+// messenger returns the WhatsApp sender for a client (captured instead of sent in sandbox mode)
+func (s *OrderService) messenger(clientID uuid.UUID) WhatsAppService {
+	return sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, clientID)
 }
 
 // WhatsAppService interface for dependency injection
@@ -452,4 +631,6 @@ type NotificationService interface {
 	NotifyNewOrder(tenantAdmin *notification.AdminContact, orderNumber, customerPhone string, totalAmount float64, items string) error
 	NotifyPaymentConfirmed(tenantAdmin *notification.AdminContact, orderNumber, customerPhone string, totalAmount float64) error
 	NotifyOrderCancelled(tenantAdmin *notification.AdminContact, orderNumber, customerPhone string, reason string) error
+	NotifyOrderRefunded(tenantAdmin *notification.AdminContact, orderNumber, customerPhone string, amount, totalRefunded float64, reason string) error
+	NotifyOrderNeedsReview(tenantAdmin *notification.AdminContact, orderNumber, customerPhone string, totalAmount float64, riskScore int, reasons string) error
 }
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// SetSplitPaymentRepo refunds group orders through the payments of their portions
+func (s *OrderService) SetSplitPaymentRepo(splitRepo repositories.SplitPaymentRepo) {
+	s.splitRepo = splitRepo
+}
+
+// refundSplitPortions refunds amount of a paid group order, each payer getting back their part of it in
+// proportion to what is left of their portion. The full-amount transaction was cancelled when the order
+// was split, so every portion is refunded by its own reference. When a portion fails after others were
+// refunded, the result holds what was refunded so far.
+func (s *OrderService) refundSplitPortions(order *models.Order, amount float64, reason string) (*payment.RefundResult, error) {
+	portions, err := s.splitRepo.ListByOrder(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list split payments: %w", err)
+	}
+
+	var paid []*models.OrderSplitPayment
+	var refundable float64
+	for i := range portions {
+		if portions[i].Status == models.SplitPaymentPaid {
+			paid = append(paid, &portions[i])
+			refundable += portions[i].Amount - portions[i].RefundedAmount
+		}
+	}
+	if len(paid) == 0 || refundable <= 0 {
+		return nil, errors.New("no paid portion is left to refund")
+	}
+
+	gateway := s.gatewayFor(order)
+	result := &payment.RefundResult{Status: payment.RefundStatusRefunded}
+	var references []string
+	left := amount
+	for i, portion := range paid {
+		share := roundAmount(amount * (portion.Amount - portion.RefundedAmount) / refundable)
+		if i == len(paid)-1 || share > left {
+			share = left // The last payer takes the rounding difference
+		}
+		if share <= 0 {
+			continue
+		}
+
+		refund, err := gateway.Refund(portion.Reference, share, reason)
+		if err != nil {
+			if result.Amount == 0 {
+				return nil, fmt.Errorf("portion %s: %w", portion.Reference, err)
+			}
+			log.Printf("❌ Refund of Rp %s to split portion %s of order %s failed after others were refunded: %v", formatPrice(share), portion.Reference, order.OrderNumber, err)
+			break
+		}
+		if refund.Amount > 0 {
+			share = refund.Amount
+		}
+		if err := s.splitRepo.RecordRefund(portion.ID, share, refund.Reference, time.Now()); err != nil {
+			log.Printf("❌ Refund %s of split portion %s succeeded at the gateway but was not saved: %v", refund.Reference, portion.Reference, err)
+		}
+
+		left = roundAmount(left - share)
+		result.Amount = roundAmount(result.Amount + share)
+		references = append(references, refund.Reference)
+		if refund.Status == payment.RefundStatusPending {
+			result.Status = payment.RefundStatusPending
+		}
+	}
+
+	result.Reference = strings.Join(references, ",")
+	return result, nil
+}
@@ -0,0 +1,44 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// SetWalletService pays orders from the customer's prepaid balance at checkout when it covers the total
+func (s *OrderService) SetWalletService(walletSvc *WalletService) {
+	s.walletSvc = walletSvc
+}
+
+// isWalletPaid reports whether the order was paid from the customer's wallet
+func isWalletPaid(order *models.Order) bool {
+	return order.PaymentMethod == models.PaymentMethodWallet
+}
+
+// payFromWallet pays a new order from the customer's wallet and confirms it. Returns nil when the order
+// has to be paid through its gateway (no wallet, wallet disabled or balance too low).
+func (s *OrderService) payFromWallet(order *models.Order) *payment.ProcessResult {
+	if s.walletSvc == nil || isCOD(order) {
+		return nil
+	}
+
+	reference, ok := s.walletSvc.PayOrder(order)
+	if !ok {
+		return nil
+	}
+
+	if err := s.ConfirmPayment(order.ID.String(), models.PaymentMethodWallet, reference); err != nil {
+		// The balance was already debited; the ledger reference lets an admin reconcile the order
+		log.Printf("❌ Order %s was paid from the wallet (%s) but not confirmed: %v", order.OrderNumber, reference, err)
+	}
+	if paid, err := s.orderRepo.GetByID(order.ID.String()); err == nil {
+		*order = *paid
+	}
+
+	return &payment.ProcessResult{
+		Success: true,
+		Message: "Paid from wallet balance",
+	}
+}
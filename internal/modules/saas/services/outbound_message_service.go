@@ -0,0 +1,292 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/jobs"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/google/uuid"
+)
+
+const (
+	// OutboundMessageJobType is the job type of queued WhatsApp messages
+	OutboundMessageJobType = "whatsapp_send"
+	// OutboundQueue is the jobs queue outbound WhatsApp messages are sent from
+	OutboundQueue = "whatsapp_outbound"
+	// outboundMaxAttempts is how often a message is tried before it becomes a dead letter; with the queue's
+	// exponential backoff (2s, 4s, 8s, ...) the last attempt is about 8 minutes after the first
+	outboundMaxAttempts = 8
+)
+
+// ErrOutboundMessageNotFound is returned for a job that is not a queued WhatsApp message
+var ErrOutboundMessageNotFound = errors.New("outbound message not found")
+
+// OutboundSender delivers a client's WhatsApp messages through the client's session. SendReply quotes
+// quotedMessageID when set and returns the provider message ID.
+type OutboundSender interface {
+	SendReply(clientID, phoneNumber, message, quotedMessageID string) (string, error)
+	SendLocation(clientID, phoneNumber string, location whatsapp.Location) error
+	SendImage(clientID, phoneNumber string, image whatsapp.Media) (string, error)
+	SendButtons(clientID, phoneNumber string, message whatsapp.ButtonMessage) (string, error)
+	SendList(clientID, phoneNumber string, message whatsapp.ListMessage) (string, error)
+}
+
+// Kinds of queued WhatsApp messages other than text
+const (
+	outboundKindLocation = "location"
+	outboundKindImage    = "image"
+	outboundKindButtons  = "buttons"
+	outboundKindList     = "list"
+)
+
+// outboundMessagePayload is the job payload of a queued WhatsApp message. Message is the text of text
+// messages, and describes the other kinds for the outbound message list. Buttons and lists are tried once
+// as such: their retries send Message instead, which every provider delivers.
+type outboundMessagePayload struct {
+	Kind     string                  `json:"kind,omitempty"` // "" for text
+	To       string                  `json:"to"`
+	Message  string                  `json:"message"`
+	QuotedID string                  `json:"quoted_id,omitempty"`
+	Location *whatsapp.Location      `json:"location,omitempty"`
+	Media    *whatsapp.Media         `json:"media,omitempty"`
+	Buttons  *whatsapp.ButtonMessage `json:"buttons,omitempty"`
+	List     *whatsapp.ListMessage   `json:"list,omitempty"`
+}
+
+// interactive reports whether the message is a button message or list menu
+func (p *outboundMessagePayload) interactive() bool {
+	return p.Kind == outboundKindButtons || p.Kind == outboundKindList
+}
+
+// OutboundMessage is a queued WhatsApp message with its delivery state
+type OutboundMessage struct {
+	ID            uuid.UUID      `json:"id"`
+	ClientID      uuid.UUID      `json:"client_id"`
+	Kind          string         `json:"kind,omitempty"` // "" for text, location, image, buttons, list
+	To            string         `json:"to"`
+	Message       string         `json:"message"`
+	Status        jobs.JobStatus `json:"status"` // pending, processing, retrying, completed, failed, dead_letter
+	Attempts      int            `json:"attempts"`
+	MaxAttempts   int            `json:"max_attempts"`
+	Error         string         `json:"error,omitempty"`
+	NextAttemptAt *time.Time     `json:"next_attempt_at,omitempty"`
+	FailedAt      *time.Time     `json:"failed_at,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+// OutboundMessageService sends WhatsApp messages through the jobs queue, so a message that fails while
+// WAHA or GreenAPI is unreachable is retried with exponential backoff instead of being dropped. Messages
+// that still fail after the last attempt are kept as dead letters for the platform to inspect and retry.
+type OutboundMessageService struct {
+	jobService *jobs.Service
+	sender     OutboundSender
+}
+
+// NewOutboundMessageService creates a new outbound message service
+func NewOutboundMessageService(jobService *jobs.Service, sender OutboundSender) *OutboundMessageService {
+	return &OutboundMessageService{
+		jobService: jobService,
+		sender:     sender,
+	}
+}
+
+// Enqueue queues a message of a client for delivery. When the queue can't be written the message is sent right away.
+func (s *OutboundMessageService) Enqueue(clientID, to, message, quotedID string) error {
+	return s.enqueue(clientID, outboundMessagePayload{To: to, Message: message, QuotedID: quotedID})
+}
+
+// EnqueueLocation queues a location pin of a client for delivery
+func (s *OutboundMessageService) EnqueueLocation(clientID, to string, location whatsapp.Location) error {
+	return s.enqueue(clientID, outboundMessagePayload{
+		Kind:     outboundKindLocation,
+		To:       to,
+		Message:  locationText(location),
+		Location: &location,
+	})
+}
+
+// EnqueueImage queues an image of a client for delivery
+func (s *OutboundMessageService) EnqueueImage(clientID, to string, image whatsapp.Media) error {
+	return s.enqueue(clientID, outboundMessagePayload{
+		Kind:    outboundKindImage,
+		To:      to,
+		Message: imageText(image),
+		Media:   &image,
+	})
+}
+
+// EnqueueButtons queues a message with reply buttons of a client for delivery
+func (s *OutboundMessageService) EnqueueButtons(clientID, to string, message whatsapp.ButtonMessage) error {
+	return s.enqueue(clientID, outboundMessagePayload{
+		Kind:    outboundKindButtons,
+		To:      to,
+		Message: message.Text(),
+		Buttons: &message,
+	})
+}
+
+// EnqueueList queues a list menu of a client for delivery
+func (s *OutboundMessageService) EnqueueList(clientID, to string, message whatsapp.ListMessage) error {
+	return s.enqueue(clientID, outboundMessagePayload{
+		Kind:    outboundKindList,
+		To:      to,
+		Message: message.Text(),
+		List:    &message,
+	})
+}
+
+func (s *OutboundMessageService) enqueue(clientID string, payload outboundMessagePayload) error {
+	uid, err := uuid.Parse(clientID)
+	if err == nil {
+		_, err = s.jobService.Enqueue(context.Background(), uid, OutboundMessageJobType, payload, jobs.EnqueueOptions{
+			Queue:      OutboundQueue,
+			Priority:   jobs.PriorityHigh,
+			MaxRetries: outboundMaxAttempts,
+		})
+		if err == nil {
+			return nil
+		}
+	}
+
+	log.Printf("⚠️ Failed to queue WhatsApp message to %s, sending directly: %v", payload.To, err)
+	_, err = s.send(clientID, &payload)
+	if err != nil && payload.interactive() {
+		log.Printf("⚠️ Failed to send %s to %s, sending text instead: %v", payload.Kind, payload.To, err)
+		_, err = s.sender.SendReply(clientID, payload.To, payload.Message, "")
+	}
+	return err
+}
+
+// send delivers a message of any kind and returns the provider message ID, when the provider gives one
+func (s *OutboundMessageService) send(clientID string, payload *outboundMessagePayload) (string, error) {
+	switch payload.Kind {
+	case "":
+		return s.sender.SendReply(clientID, payload.To, payload.Message, payload.QuotedID)
+	case outboundKindLocation:
+		if payload.Location == nil {
+			return "", jobs.Permanent(fmt.Errorf("location message has no location"))
+		}
+		return "", s.sender.SendLocation(clientID, payload.To, *payload.Location)
+	case outboundKindImage:
+		if payload.Media == nil {
+			return "", jobs.Permanent(fmt.Errorf("image message has no image"))
+		}
+		return s.sender.SendImage(clientID, payload.To, *payload.Media)
+	case outboundKindButtons:
+		if payload.Buttons == nil {
+			return "", jobs.Permanent(fmt.Errorf("button message has no buttons"))
+		}
+		return s.sender.SendButtons(clientID, payload.To, *payload.Buttons)
+	case outboundKindList:
+		if payload.List == nil {
+			return "", jobs.Permanent(fmt.Errorf("list message has no list"))
+		}
+		return s.sender.SendList(clientID, payload.To, *payload.List)
+	}
+	return "", jobs.Permanent(fmt.Errorf("unknown outbound message kind %q", payload.Kind))
+}
+
+// GetType implements jobs.JobHandler
+func (s *OutboundMessageService) GetType() string {
+	return OutboundMessageJobType
+}
+
+// Handle implements jobs.JobHandler: sends the message; an error makes the queue retry it later
+func (s *OutboundMessageService) Handle(ctx context.Context, job *jobs.Job) error {
+	var payload outboundMessagePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return jobs.Permanent(fmt.Errorf("invalid outbound message payload: %w", err))
+	}
+	if payload.To == "" || payload.Message == "" {
+		return jobs.Permanent(fmt.Errorf("outbound message has no recipient or text"))
+	}
+
+	if payload.interactive() && job.Attempts > 1 {
+		// The buttons or list failed (or the provider rejected them), the retries send their text
+		payload = outboundMessagePayload{To: payload.To, Message: payload.Message}
+	}
+
+	messageID, err := s.send(job.ClientID.String(), &payload)
+	if err != nil {
+		if job.Attempts >= job.MaxRetries {
+			log.Printf("☠️ WhatsApp message %s to %s dead-lettered after %d attempts: %v", job.ID, payload.To, job.Attempts, err)
+		}
+		return fmt.Errorf("send to %s: %w", payload.To, err)
+	}
+
+	if job.Attempts > 1 {
+		log.Printf("✅ WhatsApp message %s to %s delivered on attempt %d (id: %s)", job.ID, payload.To, job.Attempts, messageID)
+	}
+	return nil
+}
+
+// List returns queued messages newest first, optionally of one client and status (e.g. dead_letter)
+func (s *OutboundMessageService) List(ctx context.Context, clientID *uuid.UUID, status jobs.JobStatus, limit int) ([]OutboundMessage, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	queued, err := s.jobService.ListJobs(ctx, jobs.JobFilter{
+		ClientID: clientID,
+		Queue:    OutboundQueue,
+		Type:     OutboundMessageJobType,
+		Status:   status,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]OutboundMessage, len(queued))
+	for i := range queued {
+		messages[i] = toOutboundMessage(&queued[i])
+	}
+	return messages, nil
+}
+
+// Retry sends a failed or dead-letter message again, from its first attempt
+func (s *OutboundMessageService) Retry(ctx context.Context, id uuid.UUID) (*OutboundMessage, error) {
+	job, err := s.jobService.GetJob(ctx, id)
+	if err != nil || job.Queue != OutboundQueue {
+		return nil, ErrOutboundMessageNotFound
+	}
+
+	if err := s.jobService.Requeue(ctx, id); err != nil {
+		return nil, err
+	}
+
+	job, err = s.jobService.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	message := toOutboundMessage(job)
+	return &message, nil
+}
+
+func toOutboundMessage(job *jobs.Job) OutboundMessage {
+	var payload outboundMessagePayload
+	_ = json.Unmarshal(job.Payload, &payload)
+
+	message := OutboundMessage{
+		ID:          job.ID,
+		ClientID:    job.ClientID,
+		Kind:        payload.Kind,
+		To:          payload.To,
+		Message:     payload.Message,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxRetries,
+		Error:       job.Error,
+		FailedAt:    job.FailedAt,
+		CreatedAt:   job.CreatedAt,
+	}
+	if job.Status == jobs.StatusRetrying {
+		message.NextAttemptAt = job.ScheduledAt
+	}
+	return message
+}
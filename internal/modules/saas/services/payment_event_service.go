@@ -0,0 +1,441 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrPaymentEventNotFound is returned when replaying an unknown payment event
+var ErrPaymentEventNotFound = errors.New("payment event not found")
+
+// PaymentWebhookReply is what the webhook answers the gateway
+type PaymentWebhookReply struct {
+	Status  string `json:"status"` // success, received or rejected
+	Message string `json:"message"`
+}
+
+// PaymentEventService records every payment gateway webhook and processes it, so a webhook that
+// was mishandled can be replayed once the bug is fixed
+type PaymentEventService struct {
+	repo                repositories.PaymentEventRepo
+	orderService        *OrderService
+	subscriptionService *SubscriptionService
+	splitPaymentService *SplitPaymentService
+	walletService       *WalletService
+	statementService    *BillingStatementService        // nil until SetStatementService is called
+	midtrans            *payment.MidtransPaymentGateway // nil when no server key is configured
+}
+
+func NewPaymentEventService(repo repositories.PaymentEventRepo, orderService *OrderService, subscriptionService *SubscriptionService, splitPaymentService *SplitPaymentService, walletService *WalletService, midtrans *payment.MidtransPaymentGateway) *PaymentEventService {
+	return &PaymentEventService{
+		repo:                repo,
+		orderService:        orderService,
+		subscriptionService: subscriptionService,
+		splitPaymentService: splitPaymentService,
+		walletService:       walletService,
+		midtrans:            midtrans,
+	}
+}
+
+// SetStatementService routes payments of tenant billing statements (INV-...) to the statement service
+func (s *PaymentEventService) SetStatementService(statementService *BillingStatementService) {
+	s.statementService = statementService
+}
+
+// HandleMidtransWebhook stores a Midtrans notification before processing it, then records the result.
+// Failing to store the event never blocks the payment update.
+func (s *PaymentEventService) HandleMidtransWebhook(payload []byte) (*models.PaymentEvent, *PaymentWebhookReply) {
+	event := &models.PaymentEvent{
+		Gateway:         payment.GatewayMidtrans,
+		Payload:         string(payload),
+		SignatureStatus: models.PaymentSignatureUnchecked,
+		Result:          models.PaymentEventReceived,
+	}
+	stored := true
+	if err := s.repo.Create(event); err != nil {
+		log.Printf("⚠️  Failed to store Midtrans webhook: %v", err)
+		stored = false
+	}
+
+	reply := s.processMidtrans(event)
+	metrics.PaymentWebhook(event.Gateway, event.Result)
+
+	if stored {
+		if err := s.repo.Update(event); err != nil {
+			log.Printf("⚠️  Failed to record result of payment event %s: %v", event.ID, err)
+		}
+	}
+	return event, reply
+}
+
+// List returns stored payment events newest first
+func (s *PaymentEventService) List(filter models.PaymentEventFilter) ([]models.PaymentEvent, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+	return s.repo.List(filter)
+}
+
+// Replay processes a stored webhook again with the current code and records the new result
+func (s *PaymentEventService) Replay(id uuid.UUID) (*models.PaymentEvent, *PaymentWebhookReply, error) {
+	event, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrPaymentEventNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load payment event: %w", err)
+	}
+
+	var reply *PaymentWebhookReply
+	switch event.Gateway {
+	case payment.GatewayMidtrans:
+		reply = s.processMidtrans(event)
+	default:
+		return nil, nil, fmt.Errorf("replay is not supported for gateway %s", event.Gateway)
+	}
+
+	now := time.Now()
+	event.ReplayCount++
+	event.LastReplayedAt = &now
+	if err := s.repo.Update(event); err != nil {
+		return nil, nil, fmt.Errorf("failed to record replay: %w", err)
+	}
+
+	log.Printf("🔁 Replayed payment event %s (%s %s): %s", event.ID, event.OrderID, event.TransactionStatus, event.Result)
+	return event, reply, nil
+}
+
+// processMidtrans applies a Midtrans notification to its order or plan change and sets the event's
+// fields, signature status and result. Notifications without a valid signature are rejected, and a payment
+// is only confirmed once the Midtrans status API reports it settled for the expected amount. Errors from the
+// order or plan change are recorded on the event instead of returned, since Midtrans retries anything but a 200.
+func (s *PaymentEventService) processMidtrans(event *models.PaymentEvent) *PaymentWebhookReply {
+	now := time.Now()
+	event.ProcessedAt = &now
+	event.Error = ""
+
+	var notification map[string]interface{}
+	if err := json.Unmarshal([]byte(event.Payload), &notification); err != nil {
+		log.Printf("❌ Failed to parse Midtrans webhook: %v", err)
+		return rejectPaymentEvent(event, "invalid request")
+	}
+
+	log.Printf("📥 Midtrans webhook received: %v", notification)
+
+	event.SignatureStatus = midtransSignatureStatus(notification, s.midtrans)
+
+	// Extract order ID and transaction status
+	orderID, ok := notification["order_id"].(string)
+	if !ok {
+		log.Printf("❌ Missing order_id in Midtrans webhook")
+		return rejectPaymentEvent(event, "missing order_id")
+	}
+	event.OrderID = orderID
+
+	transactionStatus, ok := notification["transaction_status"].(string)
+	if !ok {
+		log.Printf("❌ Missing transaction_status in Midtrans webhook")
+		return rejectPaymentEvent(event, "missing transaction_status")
+	}
+	event.TransactionStatus = transactionStatus
+
+	paymentType, _ := notification["payment_type"].(string)
+	transactionID, _ := notification["transaction_id"].(string)
+	event.PaymentType = paymentType
+	event.TransactionID = transactionID
+
+	log.Printf("📋 Order: %s, Status: %s, Type: %s, TxID: %s, Signature: %s",
+		orderID, transactionStatus, paymentType, transactionID, event.SignatureStatus)
+
+	switch event.SignatureStatus {
+	case models.PaymentSignatureValid:
+	case models.PaymentSignatureUnchecked:
+		log.Printf("❌ Rejected Midtrans webhook for %s: Midtrans is not configured", orderID)
+		return rejectPaymentEvent(event, "midtrans is not configured")
+	default:
+		log.Printf("❌ Rejected Midtrans webhook for %s: %s signature", orderID, event.SignatureStatus)
+		return rejectPaymentEvent(event, fmt.Sprintf("%s signature", event.SignatureStatus))
+	}
+
+	// A notification alone never confirms a payment: Midtrans' own record of the transaction must agree
+	var order *models.Order
+	if transactionStatus == "capture" || transactionStatus == "settlement" {
+		var reply *PaymentWebhookReply
+		order, reply = s.findMidtransOrder(event)
+		if reply != nil {
+			return reply
+		}
+		settlement, reply := s.verifyMidtransPayment(event, notification, order)
+		if reply != nil {
+			return reply
+		}
+		paymentType, transactionID = settlement.PaymentType, settlement.TransactionID
+	}
+
+	// Plan change payments (SUB-...) belong to the tenant's subscription, not to a customer order
+	if strings.HasPrefix(orderID, PlanChangeReferencePrefix) {
+		return s.processPlanChange(event, paymentType, transactionID)
+	}
+
+	// Split payments (SPL-...) pay one payer's portion of a group order
+	if strings.HasPrefix(orderID, SplitPaymentReferencePrefix) {
+		return s.processSplitPayment(event, paymentType, transactionID)
+	}
+
+	// Wallet top-ups (TOP-...) add credit to a customer's prepaid balance
+	if strings.HasPrefix(orderID, WalletTopUpReferencePrefix) {
+		return s.processWalletTopUp(event, paymentType, transactionID)
+	}
+
+	// Statement payments (INV-...) settle a tenant's monthly billing statement
+	if strings.HasPrefix(orderID, StatementReferencePrefix) && s.statementService != nil {
+		return s.processStatementPayment(event, paymentType, transactionID)
+	}
+
+	// Handle based on transaction status
+	switch transactionStatus {
+	case "capture", "settlement":
+		// Payment successful!
+		if order == nil {
+			// A statement payment while statements are not wired up
+			return rejectPaymentEvent(event, "unknown order")
+		}
+		log.Printf("✅ Payment successful for order %s", orderID)
+
+		if err := s.orderService.ConfirmPayment(order.ID.String(), paymentType, transactionID); err != nil {
+			log.Printf("❌ Failed to confirm payment for order %s: %v", orderID, err)
+			event.Result = models.PaymentEventFailed
+			event.Error = err.Error()
+			return &PaymentWebhookReply{Status: "received", Message: "payment received but confirmation failed"}
+		}
+
+		event.Result = models.PaymentEventProcessed
+		return &PaymentWebhookReply{Status: "success", Message: "payment confirmed"}
+
+	case "pending":
+		log.Printf("⏳ Payment pending for order %s", orderID)
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: "payment pending"}
+
+	case "deny", "cancel", "expire":
+		log.Printf("❌ Payment %s for order %s", transactionStatus, orderID)
+
+		// Cancel with automatic reason based on payment status
+		reason := fmt.Sprintf("Pembayaran %s", transactionStatus)
+		if err := s.orderService.CancelOrder(orderID, reason); err != nil {
+			log.Printf("❌ Failed to cancel order %s: %v", orderID, err)
+			event.Result = models.PaymentEventFailed
+			event.Error = err.Error()
+		} else {
+			event.Result = models.PaymentEventProcessed
+		}
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("payment %s", transactionStatus)}
+
+	case "refund", "partial_refund":
+		// Refunds are started by RefundOrder, which already recorded them on the order
+		log.Printf("↩️  Refund notification for order %s", orderID)
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("payment %s", transactionStatus)}
+
+	default:
+		log.Printf("⚠️  Unknown transaction status: %s for order %s", transactionStatus, orderID)
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: "unknown status"}
+	}
+}
+
+// processPlanChange applies or cancels a plan change according to its payment status
+func (s *PaymentEventService) processPlanChange(event *models.PaymentEvent, paymentType, transactionID string) *PaymentWebhookReply {
+	reference, transactionStatus := event.OrderID, event.TransactionStatus
+
+	var err error
+	switch transactionStatus {
+	case "capture", "settlement":
+		err = s.subscriptionService.ConfirmPayment(reference, paymentType, transactionID)
+	case "deny", "cancel", "expire":
+		err = s.subscriptionService.CancelPayment(reference, fmt.Sprintf("Pembayaran %s", transactionStatus))
+	default:
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("plan change payment %s", transactionStatus)}
+	}
+
+	if err != nil {
+		log.Printf("❌ Failed to update plan change %s: %v", reference, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = err.Error()
+	} else {
+		event.Result = models.PaymentEventProcessed
+	}
+	return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("plan change payment %s", transactionStatus)}
+}
+
+// processSplitPayment records a paid portion of a group order, or fails the group order when a portion's payment fails
+func (s *PaymentEventService) processSplitPayment(event *models.PaymentEvent, paymentType, transactionID string) *PaymentWebhookReply {
+	reference, transactionStatus := event.OrderID, event.TransactionStatus
+
+	var err error
+	switch transactionStatus {
+	case "capture", "settlement":
+		err = s.splitPaymentService.ConfirmPayment(reference, paymentType, transactionID)
+	case "deny", "cancel", "expire":
+		err = s.splitPaymentService.CancelPayment(reference, fmt.Sprintf("Pembayaran %s", transactionStatus))
+	default:
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("split payment %s", transactionStatus)}
+	}
+
+	if err != nil {
+		log.Printf("❌ Failed to update split payment %s: %v", reference, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = err.Error()
+	} else {
+		event.Result = models.PaymentEventProcessed
+	}
+	return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("split payment %s", transactionStatus)}
+}
+
+// processWalletTopUp credits a paid top-up to its wallet, or cancels it when its payment failed
+func (s *PaymentEventService) processWalletTopUp(event *models.PaymentEvent, paymentType, transactionID string) *PaymentWebhookReply {
+	reference, transactionStatus := event.OrderID, event.TransactionStatus
+
+	var err error
+	switch transactionStatus {
+	case "capture", "settlement":
+		err = s.walletService.ConfirmTopUp(reference, paymentType, transactionID)
+	case "deny", "cancel", "expire":
+		err = s.walletService.CancelTopUp(reference, fmt.Sprintf("Pembayaran %s", transactionStatus))
+	default:
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("wallet top-up %s", transactionStatus)}
+	}
+
+	if err != nil {
+		log.Printf("❌ Failed to update wallet top-up %s: %v", reference, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = err.Error()
+	} else {
+		event.Result = models.PaymentEventProcessed
+	}
+	return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("wallet top-up %s", transactionStatus)}
+}
+
+// processStatementPayment settles a billing statement, or drops its payment link when the payment fails
+func (s *PaymentEventService) processStatementPayment(event *models.PaymentEvent, paymentType, transactionID string) *PaymentWebhookReply {
+	reference, transactionStatus := event.OrderID, event.TransactionStatus
+
+	var err error
+	switch transactionStatus {
+	case "capture", "settlement":
+		err = s.statementService.ConfirmPayment(reference, paymentType, transactionID)
+	case "deny", "cancel", "expire":
+		err = s.statementService.CancelPayment(reference, fmt.Sprintf("Pembayaran %s", transactionStatus))
+	default:
+		event.Result = models.PaymentEventIgnored
+		return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("statement payment %s", transactionStatus)}
+	}
+
+	if err != nil {
+		log.Printf("❌ Failed to update statement %s: %v", reference, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = err.Error()
+	} else {
+		event.Result = models.PaymentEventProcessed
+	}
+	return &PaymentWebhookReply{Status: "received", Message: fmt.Sprintf("statement payment %s", transactionStatus)}
+}
+
+func rejectPaymentEvent(event *models.PaymentEvent, reason string) *PaymentWebhookReply {
+	event.Result = models.PaymentEventRejected
+	event.Error = reason
+	return &PaymentWebhookReply{Status: "rejected", Message: reason}
+}
+
+// isMidtransReference reports whether a Midtrans order_id is one of our prefixed references rather than an order number
+func isMidtransReference(orderID string) bool {
+	for _, prefix := range []string{PlanChangeReferencePrefix, SplitPaymentReferencePrefix, WalletTopUpReferencePrefix, StatementReferencePrefix} {
+		if strings.HasPrefix(orderID, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// findMidtransOrder loads the customer order a payment notification is for, nil for prefixed references
+func (s *PaymentEventService) findMidtransOrder(event *models.PaymentEvent) (*models.Order, *PaymentWebhookReply) {
+	if isMidtransReference(event.OrderID) {
+		return nil, nil
+	}
+	order, err := s.orderService.GetOrderByOrderNumber(event.OrderID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		log.Printf("❌ Midtrans payment for unknown order %s", event.OrderID)
+		return nil, rejectPaymentEvent(event, "unknown order")
+	}
+	if err != nil {
+		log.Printf("❌ Failed to load order %s: %v", event.OrderID, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = err.Error()
+		return nil, &PaymentWebhookReply{Status: "received", Message: "payment received but confirmation failed"}
+	}
+	return order, nil
+}
+
+// verifyMidtransPayment checks with the Midtrans status API that a notified payment settled for the order's total,
+// or for the signed gross_amount of prefixed references whose service checks the amount itself
+func (s *PaymentEventService) verifyMidtransPayment(event *models.PaymentEvent, notification map[string]interface{}, order *models.Order) (*payment.Settlement, *PaymentWebhookReply) {
+	var amount float64
+	if order != nil {
+		amount = order.TotalAmount
+	} else {
+		grossAmount, _ := notification["gross_amount"].(string)
+		parsed, err := strconv.ParseFloat(grossAmount, 64)
+		if err != nil {
+			return nil, rejectPaymentEvent(event, "invalid gross_amount")
+		}
+		amount = parsed
+	}
+
+	settlement, err := s.midtrans.VerifyTransaction(event.OrderID, amount)
+	if errors.Is(err, payment.ErrPaymentNotSettled) || errors.Is(err, payment.ErrPaymentAmountMismatch) {
+		log.Printf("❌ Rejected Midtrans payment for %s: %v", event.OrderID, err)
+		return nil, rejectPaymentEvent(event, err.Error())
+	}
+	if err != nil {
+		// Recorded as failed so an admin can replay the event once Midtrans answers again
+		log.Printf("❌ Failed to verify Midtrans payment for %s: %v", event.OrderID, err)
+		event.Result = models.PaymentEventFailed
+		event.Error = fmt.Sprintf("failed to verify payment: %v", err)
+		return nil, &PaymentWebhookReply{Status: "received", Message: "payment received but verification failed"}
+	}
+	return settlement, nil
+}
+
+// midtransSignatureStatus checks the notification's signature_key with the Midtrans gateway
+func midtransSignatureStatus(notification map[string]interface{}, midtrans *payment.MidtransPaymentGateway) string {
+	if midtrans == nil {
+		return models.PaymentSignatureUnchecked
+	}
+	signature, _ := notification["signature_key"].(string)
+	if signature == "" {
+		return models.PaymentSignatureMissing
+	}
+
+	orderID, _ := notification["order_id"].(string)
+	statusCode, _ := notification["status_code"].(string)
+	grossAmount, _ := notification["gross_amount"].(string)
+	if !midtrans.VerifySignature(orderID, statusCode, grossAmount, signature) {
+		return models.PaymentSignatureInvalid
+	}
+	return models.PaymentSignatureValid
+}
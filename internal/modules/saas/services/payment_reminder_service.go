@@ -0,0 +1,431 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPaymentExpiry = 24 * time.Hour
+
+	// paymentReminderMinGap skips steps that would fall right after the customer got the payment instructions
+	paymentReminderMinGap = 15 * time.Minute
+	// paymentReminderBatch is how many pending orders per client the job checks per tick
+	paymentReminderBatch = 200
+
+	maxPaymentReminderSteps = 5
+	maxReminderMinutes      = 7 * 24 * 60
+)
+
+// defaultPaymentReminderSteps is the ladder offered until the client saves their own (23h, 6h and 1h before expiry)
+var defaultPaymentReminderSteps = []models.PaymentReminderStep{
+	{
+		MinutesBefore: 23 * 60,
+		Template: "Halo {name} 👋\n\n" +
+			"Pesanan *#{order_number}* sebesar *Rp {total}* masih menunggu pembayaran.\n\n" +
+			"Selesaikan pembayaran di sini:\n{payment_link}",
+	},
+	{
+		MinutesBefore: 6 * 60,
+		Template: "⏰ *Pengingat Pembayaran*\n\n" +
+			"Link pembayaran pesanan *#{order_number}* (Rp {total}) berakhir dalam *{time_left}*.\n\n" +
+			"Bayar sekarang:\n{payment_link}",
+	},
+	{
+		MinutesBefore: 60,
+		Template: "⚠️ *Batas waktu hampir habis!*\n\n" +
+			"Pesanan *#{order_number}* belum dibayar dan link pembayaran berakhir dalam *{time_left}*.\n\n" +
+			"{payment_link}",
+	},
+}
+
+// PaymentReminderService nudges customers to pay pending orders along the client's reminder ladder
+// and measures which reminders lead to payment
+type PaymentReminderService struct {
+	reminderRepo repositories.PaymentReminderRepo
+	orderRepo    repositories.OrderRepo
+	whatsappSvc  WhatsAppService
+	sandboxSvc   *SandboxService
+}
+
+// NewPaymentReminderService creates a new payment reminder service
+func NewPaymentReminderService(reminderRepo repositories.PaymentReminderRepo, orderRepo repositories.OrderRepo, whatsappSvc WhatsAppService, sandboxSvc *SandboxService) *PaymentReminderService {
+	return &PaymentReminderService{
+		reminderRepo: reminderRepo,
+		orderRepo:    orderRepo,
+		whatsappSvc:  whatsappSvc,
+		sandboxSvc:   sandboxSvc,
+	}
+}
+
+// GetSettings returns the client's reminder ladder, falling back to the default (disabled) ladder
+func (s *PaymentReminderService) GetSettings(clientID uuid.UUID) *models.PaymentReminderSettings {
+	settings, err := s.reminderRepo.GetSettings(clientID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("⚠️ Failed to load payment reminder settings for client %s: %v", clientID, err)
+		}
+		settings = &models.PaymentReminderSettings{ClientID: clientID}
+	}
+	if len(settings.Steps) == 0 {
+		steps, _ := json.Marshal(defaultPaymentReminderSteps)
+		settings.Steps = steps
+	}
+	if settings.DefaultExpiryMinutes <= 0 {
+		settings.DefaultExpiryMinutes = int(defaultPaymentExpiry.Minutes())
+	}
+	return settings
+}
+
+// UpdateSettings validates and saves the client's reminder ladder
+func (s *PaymentReminderService) UpdateSettings(clientID uuid.UUID, req *models.UpdatePaymentReminderSettingsRequest) (*models.PaymentReminderSettings, error) {
+	if req.DefaultExpiryMinutes == 0 {
+		req.DefaultExpiryMinutes = int(defaultPaymentExpiry.Minutes())
+	}
+	if req.DefaultExpiryMinutes < 0 || req.DefaultExpiryMinutes > maxReminderMinutes {
+		return nil, fmt.Errorf("default_expiry_minutes must be between 1 and %d", maxReminderMinutes)
+	}
+	if len(req.Steps) > maxPaymentReminderSteps {
+		return nil, fmt.Errorf("at most %d reminder steps are allowed", maxPaymentReminderSteps)
+	}
+	if req.Enabled && len(req.Steps) == 0 {
+		return nil, errors.New("at least one reminder step is required")
+	}
+
+	seen := make(map[int]bool)
+	for i := range req.Steps {
+		step := &req.Steps[i]
+		step.Template = strings.TrimSpace(step.Template)
+		if step.MinutesBefore <= 0 || step.MinutesBefore > maxReminderMinutes {
+			return nil, fmt.Errorf("step %d: minutes_before must be between 1 and %d", i+1, maxReminderMinutes)
+		}
+		if seen[step.MinutesBefore] {
+			return nil, fmt.Errorf("step %d: another step is already sent %d minutes before expiry", i+1, step.MinutesBefore)
+		}
+		seen[step.MinutesBefore] = true
+		if step.Template == "" {
+			return nil, fmt.Errorf("step %d: template is required", i+1)
+		}
+	}
+	sort.Slice(req.Steps, func(i, j int) bool { return req.Steps[i].MinutesBefore > req.Steps[j].MinutesBefore })
+
+	steps, err := json.Marshal(req.Steps)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode reminder steps: %w", err)
+	}
+	settings := &models.PaymentReminderSettings{
+		ClientID:             clientID,
+		Enabled:              req.Enabled,
+		Steps:                steps,
+		DefaultExpiryMinutes: req.DefaultExpiryMinutes,
+	}
+	if err := s.reminderRepo.UpsertSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to save payment reminder settings: %w", err)
+	}
+	return s.GetSettings(clientID), nil
+}
+
+// GetSchedule shows how the client's ladder applies to an order: what was sent, what is still scheduled
+// and what was cancelled because the order was paid (or cancelled) first
+func (s *PaymentReminderService) GetSchedule(clientID uuid.UUID, orderID string) (*models.PaymentReminderSchedule, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID != clientID {
+		return nil, ErrOrderNotFound
+	}
+
+	settings := s.GetSettings(clientID)
+	reminders, err := s.reminderRepo.ListForOrders([]uuid.UUID{order.ID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load reminders: %w", err)
+	}
+	recorded := make(map[int]*models.PaymentReminder, len(reminders))
+	lastRecorded := 0
+	for i := range reminders {
+		recorded[reminders[i].MinutesBefore] = &reminders[i]
+		if lastRecorded == 0 || reminders[i].MinutesBefore < lastRecorded {
+			lastRecorded = reminders[i].MinutesBefore
+		}
+	}
+
+	now := time.Now()
+	steps := reminderSteps(settings)
+	expiresAt := paymentExpiry(order, settings)
+	current := dueReminderStep(steps, order.CreatedAt, expiresAt, now)
+	schedule := &models.PaymentReminderSchedule{
+		OrderID:       order.ID,
+		Enabled:       settings.Enabled,
+		PaymentStatus: order.PaymentStatus,
+		ExpiresAt:     expiresAt,
+		Reminders:     []models.PaymentReminderScheduleItem{},
+	}
+	for _, step := range steps {
+		item := models.PaymentReminderScheduleItem{
+			MinutesBefore: step.MinutesBefore,
+			DueAt:         expiresAt.Add(-time.Duration(step.MinutesBefore) * time.Minute),
+		}
+		switch reminder := recorded[step.MinutesBefore]; {
+		case reminder != nil:
+			item.Status = reminder.Status
+			item.SentAt = &reminder.SentAt
+			item.Converted = reminder.ConvertedAt != nil
+		case order.PaymentStatus != models.PaymentStatusPending:
+			item.Status = models.PaymentReminderCancelled
+		case item.DueAt.Before(order.CreatedAt.Add(paymentReminderMinGap)),
+			lastRecorded != 0 && lastRecorded < step.MinutesBefore,
+			!item.DueAt.After(now) && (current == nil || current.MinutesBefore != step.MinutesBefore):
+			item.Status = models.PaymentReminderSkipped
+		default:
+			item.Status = models.PaymentReminderScheduled
+		}
+		schedule.Reminders = append(schedule.Reminders, item)
+	}
+
+	return schedule, nil
+}
+
+// GetStats reports, per ladder step, how many reminders were sent in a period and how many were followed by payment
+func (s *PaymentReminderService) GetStats(clientID uuid.UUID, period string) (*models.PaymentReminderStats, error) {
+	if period == "" {
+		period = "last_30_days"
+	}
+	dateRange := analytics.GetDateRange(period)
+
+	steps, err := s.reminderRepo.StepStats(clientID, dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute reminder stats: %w", err)
+	}
+	reminded, converted, err := s.reminderRepo.OrderStats(clientID, dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute reminded orders: %w", err)
+	}
+
+	for i := range steps {
+		if steps[i].Sent > 0 {
+			steps[i].ConversionRate = float64(steps[i].Converted) / float64(steps[i].Sent) * 100
+		}
+	}
+	stats := &models.PaymentReminderStats{
+		Period:          period,
+		OrdersReminded:  reminded,
+		OrdersConverted: converted,
+		Steps:           steps,
+	}
+	if stats.Steps == nil {
+		stats.Steps = []models.PaymentReminderStepStats{}
+	}
+	if reminded > 0 {
+		stats.ConversionRate = float64(converted) / float64(reminded) * 100
+	}
+	return stats, nil
+}
+
+// RunReminderJob sends the reminders that came due and credits payments to the reminders before them
+func (s *PaymentReminderService) RunReminderJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.processReminders(time.Now())
+		}
+	}
+}
+
+func (s *PaymentReminderService) processReminders(now time.Time) {
+	if converted, err := s.reminderRepo.MarkConversions(); err != nil {
+		log.Printf("⚠️ Failed to record payment reminder conversions: %v", err)
+	} else if converted > 0 {
+		log.Printf("💸 %d order(s) paid after a payment reminder", converted)
+	}
+
+	clients, err := s.reminderRepo.ListEnabledSettings()
+	if err != nil {
+		log.Printf("⚠️ Failed to list payment reminder settings: %v", err)
+		return
+	}
+
+	sent := 0
+	for i := range clients {
+		sent += s.remindClient(&clients[i], now)
+	}
+	if sent > 0 {
+		log.Printf("🔔 Payment reminder job: %d reminder(s) sent", sent)
+	}
+}
+
+// remindClient sends the due reminders of one client's pending orders. Only the latest due step is sent,
+// so an order isn't flooded with every missed step after downtime; paid or cancelled orders drop out of
+// the query, which cancels the rest of their ladder
+func (s *PaymentReminderService) remindClient(settings *models.PaymentReminderSettings, now time.Time) int {
+	steps := reminderSteps(settings)
+	if len(steps) == 0 {
+		return 0
+	}
+	defaultExpiry := time.Duration(settings.DefaultExpiryMinutes) * time.Minute
+	if defaultExpiry <= 0 {
+		defaultExpiry = defaultPaymentExpiry
+	}
+	horizon := time.Duration(steps[0].MinutesBefore) * time.Minute
+
+	orders, err := s.reminderRepo.ListRemindableOrders(settings.ClientID, now, horizon, defaultExpiry, paymentReminderBatch)
+	if err != nil {
+		log.Printf("⚠️ Failed to list pending orders of client %s: %v", settings.ClientID, err)
+		return 0
+	}
+	if len(orders) == 0 {
+		return 0
+	}
+
+	ids := make([]uuid.UUID, len(orders))
+	for i := range orders {
+		ids[i] = orders[i].ID
+	}
+	reminders, err := s.reminderRepo.ListForOrders(ids)
+	if err != nil {
+		log.Printf("⚠️ Failed to load payment reminders of client %s: %v", settings.ClientID, err)
+		return 0
+	}
+	lastStep := make(map[uuid.UUID]int)
+	for _, reminder := range reminders {
+		if last, ok := lastStep[reminder.OrderID]; !ok || reminder.MinutesBefore < last {
+			lastStep[reminder.OrderID] = reminder.MinutesBefore
+		}
+	}
+
+	sent := 0
+	for i := range orders {
+		order := &orders[i]
+		expiresAt := paymentExpiry(order, settings)
+		step := dueReminderStep(steps, order.CreatedAt, expiresAt, now)
+		if step == nil {
+			continue
+		}
+		if last, ok := lastStep[order.ID]; ok && last <= step.MinutesBefore {
+			continue
+		}
+		if s.sendReminder(order, step, expiresAt, now) {
+			sent++
+		}
+	}
+	return sent
+}
+
+// sendReminder claims the step for the order and messages the customer
+func (s *PaymentReminderService) sendReminder(order *models.Order, step *models.PaymentReminderStep, expiresAt, now time.Time) bool {
+	reminder := &models.PaymentReminder{
+		ClientID:      order.ClientID,
+		OrderID:       order.ID,
+		MinutesBefore: step.MinutesBefore,
+		Status:        models.PaymentReminderSent,
+		SentAt:        now,
+	}
+	claimed, err := s.reminderRepo.Claim(reminder)
+	if err != nil {
+		log.Printf("⚠️ Failed to record payment reminder for order %s: %v", order.OrderNumber, err)
+		return false
+	}
+	if !claimed {
+		return false
+	}
+
+	message := renderPaymentReminder(step.Template, order, expiresAt.Sub(now))
+	if err := sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, order.ClientID).SendMessage(order.CustomerPhone, message); err != nil {
+		log.Printf("⚠️ Failed to send payment reminder for order %s: %v", order.OrderNumber, err)
+		reminder.Status = models.PaymentReminderFailed
+		reminder.Error = err.Error()
+		if err := s.reminderRepo.Update(reminder); err != nil {
+			log.Printf("⚠️ Failed to update payment reminder %s: %v", reminder.ID, err)
+		}
+		return false
+	}
+	return true
+}
+
+// reminderSteps decodes the client's ladder, earliest reminder (most minutes before expiry) first
+func reminderSteps(settings *models.PaymentReminderSettings) []models.PaymentReminderStep {
+	var steps []models.PaymentReminderStep
+	if err := json.Unmarshal(settings.Steps, &steps); err != nil {
+		log.Printf("⚠️ Invalid payment reminder steps for client %s: %v", settings.ClientID, err)
+		return nil
+	}
+	sort.Slice(steps, func(i, j int) bool { return steps[i].MinutesBefore > steps[j].MinutesBefore })
+	return steps
+}
+
+// paymentExpiry is when the order's payment link expires, or the client's default window for gateways without expiry
+func paymentExpiry(order *models.Order, settings *models.PaymentReminderSettings) time.Time {
+	if order.PaymentExpiresAt != nil {
+		return *order.PaymentExpiresAt
+	}
+	window := time.Duration(settings.DefaultExpiryMinutes) * time.Minute
+	if window <= 0 {
+		window = defaultPaymentExpiry
+	}
+	return order.CreatedAt.Add(window)
+}
+
+// dueReminderStep returns the latest step that is due, unless it falls too close to when the order was placed
+func dueReminderStep(steps []models.PaymentReminderStep, createdAt, expiresAt, now time.Time) *models.PaymentReminderStep {
+	if !now.Before(expiresAt) {
+		return nil
+	}
+	var due *models.PaymentReminderStep
+	var dueAt time.Time
+	for i := range steps {
+		at := expiresAt.Add(-time.Duration(steps[i].MinutesBefore) * time.Minute)
+		if at.After(now) {
+			break
+		}
+		due, dueAt = &steps[i], at
+	}
+	if due == nil || dueAt.Before(createdAt.Add(paymentReminderMinGap)) {
+		return nil
+	}
+	return due
+}
+
+// renderPaymentReminder fills a reminder template for an order
+func renderPaymentReminder(template string, order *models.Order, timeLeft time.Duration) string {
+	name := order.CustomerName
+	if name == "" {
+		name = "Kak"
+	}
+	return strings.NewReplacer(
+		"{name}", name,
+		"{order_number}", order.OrderNumber,
+		"{total}", formatPrice(order.TotalAmount),
+		"{payment_link}", order.PaymentLink,
+		"{time_left}", formatTimeLeft(timeLeft),
+	).Replace(template)
+}
+
+// formatTimeLeft renders a remaining time in Indonesian, e.g. "6 jam" or "45 menit"
+func formatTimeLeft(d time.Duration) string {
+	minutes := int(d.Round(time.Minute).Minutes())
+	if minutes < 1 {
+		minutes = 1
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%d menit", minutes)
+	}
+	hours, rest := minutes/60, minutes%60
+	if rest == 0 || hours >= 6 {
+		return fmt.Sprintf("%d jam", (minutes+30)/60)
+	}
+	return fmt.Sprintf("%d jam %d menit", hours, rest)
+}
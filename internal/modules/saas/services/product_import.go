@@ -0,0 +1,377 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// maxProductImportRows caps the product rows of one import file
+const maxProductImportRows = 5000
+
+// ErrProductImportFile is returned when an import file can't be read as a whole (format, header, size)
+var ErrProductImportFile = errors.New("invalid product import file")
+
+// productImportColumns maps product fields to the header names accepted in import files
+var productImportColumns = map[string][]string{
+	"name":             {"name", "product name", "product_name", "nama", "nama produk"},
+	"sku":              {"sku", "kode", "kode produk"},
+	"description":      {"description", "deskripsi"},
+	"category":         {"category", "kategori"},
+	"price":            {"price", "harga"},
+	"stock":            {"stock", "qty", "quantity", "stok"},
+	"image_url":        {"image url", "image_url", "image", "gambar"},
+	"is_active":        {"is active", "is_active", "active", "aktif"},
+	"preorder_enabled": {"preorder", "pre-order", "preorder_enabled"},
+}
+
+// productImportRow is one product row of an import file, holding only the columns present in the file
+type productImportRow struct {
+	line   int
+	fields map[string]string
+}
+
+func (r productImportRow) value(field string) (string, bool) {
+	v, ok := r.fields[field]
+	return v, ok
+}
+
+// ImportProducts creates or updates products in bulk from a CSV or XLSX file. Rows are matched to
+// existing products by SKU, or by name when the row has no SKU; empty cells keep the current value.
+// Invalid rows are skipped and reported, the others are applied. A dry run only validates and classifies
+func (s *ProductService) ImportProducts(clientID uuid.UUID, r io.Reader, filename string, dryRun bool) (*models.ProductImportResult, error) {
+	rows, err := parseProductImport(r, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.ProductImportResult{
+		DryRun: dryRun,
+		Total:  len(rows),
+		Errors: []models.ProductImportError{},
+	}
+	seen := make(map[string]int)
+
+	for _, row := range rows {
+		sku, _ := row.value("sku")
+		name, _ := row.value("name")
+		fail := func(err error) {
+			result.Failed++
+			result.Errors = append(result.Errors, models.ProductImportError{Row: row.line, SKU: sku, Name: name, Error: err.Error()})
+		}
+
+		if sku == "" && name == "" {
+			fail(errors.New("name or sku is required"))
+			continue
+		}
+
+		key := "name:" + strings.ToLower(name)
+		if sku != "" {
+			key = "sku:" + sku
+		}
+		if first, dup := seen[key]; dup {
+			fail(fmt.Errorf("duplicate of row %d", first))
+			continue
+		}
+		seen[key] = row.line
+
+		existing, err := s.findImportMatch(clientID, sku, name)
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		if existing == nil {
+			req, err := productImportCreateRequest(row)
+			if err != nil {
+				fail(err)
+				continue
+			}
+			if !dryRun {
+				if _, err := s.CreateProduct(clientID, req); err != nil {
+					fail(err)
+					continue
+				}
+			}
+			result.Created++
+			continue
+		}
+
+		req, err := productImportUpdateRequest(row)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		if !dryRun {
+			if _, err := s.UpdateProduct(existing.ID.String(), clientID, req); err != nil {
+				fail(err)
+				continue
+			}
+		}
+		result.Updated++
+	}
+
+	if !dryRun {
+		log.Printf("📦 Imported products for client %s: %d created, %d updated, %d failed",
+			clientID, result.Created, result.Updated, result.Failed)
+	}
+
+	return result, nil
+}
+
+// findImportMatch returns the product an import row refers to, or nil when it is a new product
+func (s *ProductService) findImportMatch(clientID uuid.UUID, sku, name string) (*models.Product, error) {
+	var product *models.Product
+	var err error
+	if sku != "" {
+		product, err = s.productRepo.GetBySKU(clientID, sku)
+	} else {
+		product, err = s.productRepo.GetByName(clientID, name)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up product: %w", err)
+	}
+	return product, nil
+}
+
+// productImportCreateRequest builds the request for a row that adds a new product
+func productImportCreateRequest(row productImportRow) (*models.CreateProductRequest, error) {
+	name, _ := row.value("name")
+	if name == "" {
+		return nil, errors.New("name is required for new products")
+	}
+	rawPrice, _ := row.value("price")
+	if rawPrice == "" {
+		return nil, errors.New("price is required for new products")
+	}
+
+	update, err := productImportUpdateRequest(row)
+	if err != nil {
+		return nil, err
+	}
+
+	req := &models.CreateProductRequest{
+		Name:     name,
+		Price:    *update.Price,
+		IsActive: update.IsActive,
+	}
+	if update.SKU != nil {
+		req.SKU = *update.SKU
+	}
+	if update.Description != nil {
+		req.Description = *update.Description
+	}
+	if update.Category != nil {
+		req.Category = *update.Category
+	}
+	if update.Stock != nil {
+		req.Stock = *update.Stock
+	}
+	if update.ImageURL != nil {
+		req.ImageURL = *update.ImageURL
+	}
+	if update.PreorderEnabled != nil {
+		req.PreorderEnabled = *update.PreorderEnabled
+	}
+	return req, nil
+}
+
+// productImportUpdateRequest builds the request for a row that updates an existing product. Only
+// non-empty cells are applied, so a file with just sku and stock columns works as a stock update
+func productImportUpdateRequest(row productImportRow) (*models.UpdateProductRequest, error) {
+	req := &models.UpdateProductRequest{}
+	text := func(field string) *string {
+		if v, ok := row.value(field); ok && v != "" {
+			return &v
+		}
+		return nil
+	}
+
+	req.Name = text("name")
+	req.SKU = text("sku")
+	req.Description = text("description")
+	req.Category = text("category")
+	req.ImageURL = text("image_url")
+
+	if raw := text("price"); raw != nil {
+		price, err := parseImportNumber(*raw)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("invalid price %q", *raw)
+		}
+		req.Price = &price
+	}
+	if raw := text("stock"); raw != nil {
+		stock, err := parseImportNumber(*raw)
+		if err != nil || stock < 0 || stock != float64(int(stock)) {
+			return nil, fmt.Errorf("invalid stock %q", *raw)
+		}
+		n := int(stock)
+		req.Stock = &n
+	}
+	if raw := text("is_active"); raw != nil {
+		active, ok := parseImportBool(*raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid is_active %q", *raw)
+		}
+		req.IsActive = &active
+	}
+	if raw := text("preorder_enabled"); raw != nil {
+		preorder, ok := parseImportBool(*raw)
+		if !ok {
+			return nil, fmt.Errorf("invalid preorder %q", *raw)
+		}
+		req.PreorderEnabled = &preorder
+	}
+
+	return req, nil
+}
+
+// parseProductImport reads the product rows of a CSV or XLSX file, chosen by the file extension
+func parseProductImport(r io.Reader, filename string) ([]productImportRow, error) {
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		all, err := reader.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProductImportFile, err)
+		}
+		records = all
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProductImportFile, err)
+		}
+		defer f.Close()
+		// Products are read from the first sheet
+		all, err := f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProductImportFile, err)
+		}
+		records = all
+	default:
+		return nil, fmt.Errorf("%w: use a .csv or .xlsx file", ErrProductImportFile)
+	}
+
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: file is empty", ErrProductImportFile)
+	}
+
+	index := make(map[string]int)
+	for i, name := range records[0] {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\uFEFF")))
+		for field, aliases := range productImportColumns {
+			if _, found := index[field]; found {
+				continue
+			}
+			for _, alias := range aliases {
+				if name == alias {
+					index[field] = i
+					break
+				}
+			}
+		}
+	}
+	_, hasName := index["name"]
+	_, hasSKU := index["sku"]
+	if !hasName && !hasSKU {
+		return nil, fmt.Errorf("%w: a name or sku column is required", ErrProductImportFile)
+	}
+
+	var rows []productImportRow
+	for i, record := range records[1:] {
+		row := productImportRow{line: i + 2, fields: make(map[string]string, len(index))}
+		empty := true
+		for field, col := range index {
+			var v string
+			if col < len(record) {
+				v = strings.TrimSpace(record[col])
+			}
+			row.fields[field] = v
+			if v != "" {
+				empty = false
+			}
+		}
+		if empty {
+			continue
+		}
+		if len(rows) == maxProductImportRows {
+			return nil, fmt.Errorf("%w: more than %d products, split the file", ErrProductImportFile, maxProductImportRows)
+		}
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("%w: no products found", ErrProductImportFile)
+	}
+	return rows, nil
+}
+
+// parseImportNumber parses prices and quantities like "15000", "Rp 15.000", "15,000" or "15.000,50"
+func parseImportNumber(raw string) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if strings.HasPrefix(raw, "Rp") {
+		raw = strings.TrimPrefix(strings.TrimPrefix(raw, "Rp"), ".")
+	}
+	raw = strings.ReplaceAll(raw, " ", "")
+
+	switch {
+	case strings.Contains(raw, ".") && strings.Contains(raw, ","):
+		// The separator that comes last is the decimal one
+		if strings.LastIndex(raw, ",") > strings.LastIndex(raw, ".") {
+			raw = strings.ReplaceAll(raw, ".", "")
+			raw = strings.Replace(raw, ",", ".", 1)
+		} else {
+			raw = strings.ReplaceAll(raw, ",", "")
+		}
+	case strings.Contains(raw, ","):
+		raw = normalizeImportSeparator(raw, ",")
+	case strings.Contains(raw, "."):
+		raw = normalizeImportSeparator(raw, ".")
+	}
+
+	return strconv.ParseFloat(raw, 64)
+}
+
+// normalizeImportSeparator treats sep as a thousands separator when every group after it has three
+// digits ("15.000", "1,250,000") and as the decimal point otherwise ("15.5", "12,75")
+func normalizeImportSeparator(raw, sep string) string {
+	groups := strings.Split(raw, sep)
+	thousands := true
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			thousands = false
+			break
+		}
+	}
+	if thousands {
+		return strings.Join(groups, "")
+	}
+	return strings.Replace(raw, sep, ".", 1)
+}
+
+// parseImportBool accepts the usual spreadsheet spellings of yes/no, in English and Indonesian
+func parseImportBool(raw string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "1", "true", "yes", "y", "ya", "aktif", "active":
+		return true, true
+	case "0", "false", "no", "n", "tidak", "nonaktif", "inactive":
+		return false, true
+	}
+	return false, false
+}
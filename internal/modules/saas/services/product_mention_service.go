@@ -0,0 +1,395 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// mentionCatalogTTL is how long a client's catalog is cached for mention matching
+	mentionCatalogTTL = 5 * time.Minute
+	// mentionCatalogLimit caps the products loaded per client
+	mentionCatalogLimit = 1000
+	// mentionLLMCatalogLimit is the largest catalog sent to the LLM for matching
+	mentionLLMCatalogLimit = 200
+)
+
+// mentionInquiryWords mark a message as a product question worth an LLM lookup when the catalog match finds nothing
+var mentionInquiryWords = map[string]bool{
+	"ada": true, "harga": true, "berapa": true, "jual": true, "stok": true, "ready": true,
+	"pesan": true, "beli": true, "order": true, "mau": true, "cari": true, "punya": true, "tersedia": true,
+}
+
+// ProductMentionService detects products customers ask about and reports which ones don't sell
+type ProductMentionService struct {
+	mentionRepo repositories.ProductMentionRepo
+	productRepo repositories.ProductRepo
+	orderRepo   repositories.OrderRepo
+	llmService  *llm.Service
+
+	mu       sync.Mutex
+	catalogs map[uuid.UUID]mentionCatalog
+}
+
+type mentionCatalog struct {
+	products []models.Product
+	loadedAt time.Time
+}
+
+func NewProductMentionService(
+	mentionRepo repositories.ProductMentionRepo,
+	productRepo repositories.ProductRepo,
+	orderRepo repositories.OrderRepo,
+	llmService *llm.Service,
+) *ProductMentionService {
+	return &ProductMentionService{
+		mentionRepo: mentionRepo,
+		productRepo: productRepo,
+		orderRepo:   orderRepo,
+		llmService:  llmService,
+		catalogs:    make(map[uuid.UUID]mentionCatalog),
+	}
+}
+
+// Record detects product mentions in a customer message and stores them as mention events
+func (s *ProductMentionService) Record(clientID uuid.UUID, customerPhone, message string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	mentions := s.Detect(ctx, clientID, message)
+	if len(mentions) == 0 {
+		return
+	}
+
+	for i := range mentions {
+		mentions[i].ClientID = clientID
+		mentions[i].CustomerPhone = customerPhone
+	}
+
+	if err := s.mentionRepo.CreateBatch(mentions); err != nil {
+		log.Printf("⚠️ Failed to record product mentions: %v", err)
+		return
+	}
+	log.Printf("🏷️ Recorded %d product mention(s) from %s", len(mentions), customerPhone)
+}
+
+// Detect matches a message against the client's active catalog: exact name, then fuzzy words, then the LLM
+func (s *ProductMentionService) Detect(ctx context.Context, clientID uuid.UUID, message string) []models.ProductMention {
+	products := s.catalog(clientID)
+	if len(products) == 0 {
+		return nil
+	}
+
+	text := normalizeMentionText(message)
+	if text == "" {
+		return nil
+	}
+	words := strings.Fields(text)
+
+	var mentions []models.ProductMention
+	for _, product := range products {
+		name := normalizeMentionText(product.Name)
+		if name == "" {
+			continue
+		}
+
+		if strings.Contains(" "+text+" ", " "+name+" ") {
+			mentions = append(mentions, models.ProductMention{ProductID: product.ID, MatchType: models.MentionMatchExact, MatchedText: name})
+			continue
+		}
+
+		if matched, ok := fuzzyNameMatch(strings.Fields(name), words); ok {
+			mentions = append(mentions, models.ProductMention{ProductID: product.ID, MatchType: models.MentionMatchFuzzy, MatchedText: matched})
+		}
+	}
+
+	if len(mentions) == 0 && s.llmService != nil && len(products) <= mentionLLMCatalogLimit && isProductInquiry(words) {
+		mentions = s.detectWithLLM(llm.WithUsage(ctx, clientID.String(), models.UsageFeatureProductMention), products, message)
+	}
+
+	return mentions
+}
+
+// NamedProducts returns the catalog products a message names exactly. Unlike Detect it never calls the
+// LLM, so it can run before every reply.
+func (s *ProductMentionService) NamedProducts(clientID uuid.UUID, message string) []models.Product {
+	text := normalizeMentionText(message)
+	if text == "" {
+		return nil
+	}
+
+	var named []models.Product
+	for _, product := range s.catalog(clientID) {
+		name := normalizeMentionText(product.Name)
+		if name != "" && strings.Contains(" "+text+" ", " "+name+" ") {
+			named = append(named, product)
+		}
+	}
+	return named
+}
+
+// GetDemandReport lists products by how much they are asked about compared with how much they sell
+func (s *ProductMentionService) GetDemandReport(clientID uuid.UUID, period string, minMentions, limit int) (*models.ProductDemandReport, error) {
+	if period == "" {
+		period = "last_30_days"
+	}
+	dateRange := analytics.GetDateRange(period)
+
+	counts, err := s.mentionRepo.CountByProduct(clientID, dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count product mentions: %w", err)
+	}
+
+	sales, err := s.orderRepo.SumProductSales(clientID.String(), dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum product sales: %w", err)
+	}
+
+	products, _, err := s.productRepo.List(models.ProductFilter{ClientID: clientID, PageSize: mentionCatalogLimit})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products: %w", err)
+	}
+	names := make(map[uuid.UUID]string, len(products))
+	for _, product := range products {
+		names[product.ID] = product.Name
+	}
+
+	// Order items carry the product ID when added from the catalog, otherwise only the name
+	salesByID := make(map[string]models.ProductSales)
+	salesByName := make(map[string]models.ProductSales)
+	for _, sale := range sales {
+		if sale.ProductID != "" {
+			salesByID[sale.ProductID] = mergeProductSales(salesByID[sale.ProductID], sale)
+		} else {
+			key := strings.ToLower(sale.ProductName)
+			salesByName[key] = mergeProductSales(salesByName[key], sale)
+		}
+	}
+
+	report := &models.ProductDemandReport{
+		Period:   period,
+		Start:    dateRange.Start,
+		End:      dateRange.End,
+		Products: []models.ProductDemand{},
+	}
+
+	for _, count := range counts {
+		if count.Mentions < int64(minMentions) {
+			continue
+		}
+
+		name, ok := names[count.ProductID]
+		if !ok {
+			continue // Deleted product
+		}
+
+		sold := mergeProductSales(salesByID[count.ProductID.String()], salesByName[strings.ToLower(name)])
+		demand := models.ProductDemand{
+			ProductID: count.ProductID,
+			Name:      name,
+			Mentions:  count.Mentions,
+			Askers:    count.Askers,
+			UnitsSold: sold.UnitsSold,
+			Orders:    sold.Orders,
+		}
+		if demand.Askers > 0 {
+			demand.ConversionRate = float64(demand.Orders) / float64(demand.Askers) * 100
+		}
+		report.Products = append(report.Products, demand)
+	}
+
+	// Most asked but least sold first
+	sort.SliceStable(report.Products, func(i, j int) bool {
+		a, b := report.Products[i], report.Products[j]
+		if a.ConversionRate != b.ConversionRate {
+			return a.ConversionRate < b.ConversionRate
+		}
+		return a.Mentions > b.Mentions
+	})
+
+	if limit > 0 && len(report.Products) > limit {
+		report.Products = report.Products[:limit]
+	}
+
+	return report, nil
+}
+
+// catalog returns the client's active products, cached for a few minutes
+func (s *ProductMentionService) catalog(clientID uuid.UUID) []models.Product {
+	s.mu.Lock()
+	cached, ok := s.catalogs[clientID]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < mentionCatalogTTL {
+		return cached.products
+	}
+
+	active := true
+	products, _, err := s.productRepo.List(models.ProductFilter{ClientID: clientID, IsActive: &active, PageSize: mentionCatalogLimit})
+	if err != nil {
+		log.Printf("⚠️ Failed to load catalog for mention detection: %v", err)
+		return cached.products
+	}
+
+	s.mu.Lock()
+	s.catalogs[clientID] = mentionCatalog{products: products, loadedAt: time.Now()}
+	s.mu.Unlock()
+	return products
+}
+
+// detectWithLLM asks the LLM which catalog products the message refers to
+func (s *ProductMentionService) detectWithLLM(ctx context.Context, products []models.Product, message string) []models.ProductMention {
+	var catalog strings.Builder
+	byName := make(map[string]models.Product, len(products))
+	for _, product := range products {
+		catalog.WriteString("- " + product.Name + "\n")
+		byName[strings.ToLower(product.Name)] = product
+	}
+
+	systemPrompt := `You match a customer's WhatsApp message to a product catalog.
+Return ONLY a JSON array of the catalog product names the customer is asking about, written exactly as in the catalog.
+Return [] if the message does not refer to any catalog product.`
+	userPrompt := fmt.Sprintf("Catalog:\n%s\nMessage: %s", catalog.String(), message)
+
+	response, err := s.llmService.GenerateResponse(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		log.Printf("⚠️ LLM mention matching failed: %v", err)
+		return nil
+	}
+
+	cleaned := strings.TrimSpace(response)
+	cleaned = strings.TrimPrefix(cleaned, "```json")
+	cleaned = strings.TrimPrefix(cleaned, "```")
+	cleaned = strings.TrimSuffix(cleaned, "```")
+
+	var names []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(cleaned)), &names); err != nil {
+		log.Printf("⚠️ Failed to parse LLM mention response: %v", err)
+		return nil
+	}
+
+	var mentions []models.ProductMention
+	seen := make(map[uuid.UUID]bool)
+	for _, name := range names {
+		product, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+		if !ok || seen[product.ID] {
+			continue
+		}
+		seen[product.ID] = true
+		mentions = append(mentions, models.ProductMention{ProductID: product.ID, MatchType: models.MentionMatchLLM, MatchedText: product.Name})
+	}
+	return mentions
+}
+
+// fuzzyNameMatch reports whether most words of a product name appear in the message, allowing small typos.
+// Returns the matched message words.
+func fuzzyNameMatch(nameWords, messageWords []string) (string, bool) {
+	var significant []string
+	for _, word := range nameWords {
+		if len([]rune(word)) >= 3 {
+			significant = append(significant, word)
+		}
+	}
+	if len(significant) == 0 {
+		return "", false
+	}
+
+	var matched []string
+	for _, word := range significant {
+		for _, candidate := range messageWords {
+			if similarWord(word, candidate) {
+				matched = append(matched, candidate)
+				break
+			}
+		}
+	}
+
+	// A one-word name must be long enough that a typo match is meaningful
+	if len(significant) == 1 {
+		if len(matched) == 1 && len([]rune(significant[0])) >= 5 {
+			return matched[0], true
+		}
+		return "", false
+	}
+
+	if float64(len(matched))/float64(len(significant)) >= 0.75 {
+		return strings.Join(matched, " "), true
+	}
+	return "", false
+}
+
+// similarWord compares words allowing one typo from 5 letters and two from 8
+func similarWord(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	length := len([]rune(a))
+	switch {
+	case length >= 8:
+		return levenshtein(a, b) <= 2
+	case length >= 5:
+		return levenshtein(a, b) <= 1
+	default:
+		return false
+	}
+}
+
+// levenshtein returns the edit distance between two words
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// normalizeMentionText lowercases text and turns punctuation into single spaces
+func normalizeMentionText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// isProductInquiry reports whether a message looks like a question about products
+func isProductInquiry(words []string) bool {
+	for _, word := range words {
+		if mentionInquiryWords[word] {
+			return true
+		}
+	}
+	return false
+}
+
+func mergeProductSales(a, b models.ProductSales) models.ProductSales {
+	a.UnitsSold += b.UnitsSold
+	a.Orders += b.Orders
+	a.Revenue += b.Revenue
+	return a
+}
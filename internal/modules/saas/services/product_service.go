@@ -3,7 +3,10 @@ package services
 import (
 	"errors"
 	"fmt"
+	"io"
+	"log"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
 	"github.com/google/uuid"
@@ -11,12 +14,16 @@ import (
 )
 
 type ProductService struct {
-	productRepo repositories.ProductRepo
+	productRepo     repositories.ProductRepo
+	uploadService   *upload.Service
+	waitlistService *WaitlistService
 }
 
-func NewProductService(productRepo repositories.ProductRepo) *ProductService {
+func NewProductService(productRepo repositories.ProductRepo, uploadService *upload.Service, waitlistService *WaitlistService) *ProductService {
 	return &ProductService{
-		productRepo: productRepo,
+		productRepo:     productRepo,
+		uploadService:   uploadService,
+		waitlistService: waitlistService,
 	}
 }
 
@@ -55,6 +62,9 @@ func (s *ProductService) CreateProduct(clientID uuid.UUID, req *models.CreatePro
 		Stock:       req.Stock,
 		ImageURL:    req.ImageURL,
 		IsActive:    true,
+
+		PreorderEnabled:     req.PreorderEnabled,
+		PreorderAvailableAt: req.PreorderAvailableAt,
 	}
 
 	// Override IsActive if explicitly set
@@ -155,6 +165,7 @@ func (s *ProductService) UpdateProduct(productID string, clientID uuid.UUID, req
 		product.Price = *req.Price
 	}
 
+	wasAvailable := product.IsAvailable()
 	if req.Stock != nil {
 		if *req.Stock < 0 {
 			return nil, errors.New("stock cannot be negative")
@@ -162,8 +173,22 @@ func (s *ProductService) UpdateProduct(productID string, clientID uuid.UUID, req
 		product.Stock = *req.Stock
 	}
 
-	if req.ImageURL != nil {
+	if req.PreorderEnabled != nil {
+		product.PreorderEnabled = *req.PreorderEnabled
+	}
+
+	if req.PreorderAvailableAt != nil {
+		product.PreorderAvailableAt = req.PreorderAvailableAt
+	}
+
+	var orphanedMedia []string
+	if req.ImageURL != nil && *req.ImageURL != product.ImageURL {
+		// Switching to an external link orphans any hosted variants
+		orphanedMedia = product.ImagePublicIDs
 		product.ImageURL = *req.ImageURL
+		product.ThumbnailURL = ""
+		product.WhatsAppImageURL = ""
+		product.ImagePublicIDs = nil
 	}
 
 	if req.IsActive != nil {
@@ -176,18 +201,111 @@ func (s *ProductService) UpdateProduct(productID string, clientID uuid.UUID, req
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
+	s.cleanupMedia(orphanedMedia)
+
+	if !wasAvailable {
+		s.notifyRestock(product)
+	}
+
 	return product, nil
 }
 
 // DeleteProduct soft deletes a product
 func (s *ProductService) DeleteProduct(productID string, clientID uuid.UUID) error {
 	// Verify product belongs to client
-	_, err := s.GetProduct(productID, clientID)
+	product, err := s.GetProduct(productID, clientID)
 	if err != nil {
 		return err
 	}
 
-	return s.productRepo.Delete(productID)
+	if err := s.productRepo.Delete(productID); err != nil {
+		return err
+	}
+
+	// Remove hosted images so they don't linger in storage
+	s.cleanupMedia(product.ImagePublicIDs)
+
+	return nil
+}
+
+// UploadProductImage stores a product image with thumbnail and WhatsApp-optimized variants
+func (s *ProductService) UploadProductImage(productID string, clientID uuid.UUID, file io.Reader) (*models.Product, error) {
+	if s.uploadService == nil {
+		return nil, errors.New("upload service not configured")
+	}
+
+	product, err := s.GetProduct(productID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unique base ID per upload so CDN caches never serve a stale image
+	baseID := fmt.Sprintf("%s_%s", product.ID.String(), uuid.New().String()[:8])
+
+	variants, err := s.uploadService.UploadImageVariants(file, "products/"+clientID.String(), baseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload product image: %w", err)
+	}
+
+	oldMedia := product.ImagePublicIDs
+
+	product.ImageURL = variantURL(variants.Original)
+	product.ThumbnailURL = variantURL(variants.Thumbnail)
+	product.WhatsAppImageURL = variantURL(variants.WhatsApp)
+	product.ImagePublicIDs = variants.PublicIDs()
+
+	if err := s.productRepo.Update(product); err != nil {
+		s.cleanupMedia(variants.PublicIDs())
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	s.cleanupMedia(oldMedia)
+
+	return product, nil
+}
+
+// RemoveProductImage clears a product's image and deletes its hosted variants
+func (s *ProductService) RemoveProductImage(productID string, clientID uuid.UUID) (*models.Product, error) {
+	product, err := s.GetProduct(productID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	oldMedia := product.ImagePublicIDs
+
+	product.ImageURL = ""
+	product.ThumbnailURL = ""
+	product.WhatsAppImageURL = ""
+	product.ImagePublicIDs = nil
+
+	if err := s.productRepo.Update(product); err != nil {
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+
+	s.cleanupMedia(oldMedia)
+
+	return product, nil
+}
+
+// cleanupMedia deletes hosted media that is no longer referenced by a product
+func (s *ProductService) cleanupMedia(publicIDs []string) {
+	if s.uploadService == nil || len(publicIDs) == 0 {
+		return
+	}
+
+	deleted := s.uploadService.DeleteMany(publicIDs)
+	log.Printf("🧹 Cleaned up %d/%d orphaned product images", deleted, len(publicIDs))
+}
+
+// variantURL prefers the HTTPS URL of an uploaded variant
+func variantURL(result *upload.UploadResult) string {
+	if result == nil {
+		return ""
+	}
+	if result.SecureURL != "" {
+		return result.SecureURL
+	}
+	return result.URL
 }
 
 // UpdateStock updates product stock (can be positive or negative)
@@ -208,26 +326,46 @@ func (s *ProductService) UpdateStock(productID string, clientID uuid.UUID, quant
 		return nil, fmt.Errorf("failed to update stock: %w", err)
 	}
 
+	wasAvailable := product.IsAvailable()
+
 	// Get updated product
 	product, err = s.productRepo.GetByID(productID)
 	if err != nil {
 		return nil, err
 	}
 
+	if !wasAvailable {
+		s.notifyRestock(product)
+	}
+
 	return product, nil
 }
 
 // BulkUpdateStock updates stock for multiple products
 func (s *ProductService) BulkUpdateStock(clientID uuid.UUID, updates map[string]int) error {
 	// Validate all products belong to client first
+	var outOfStock []string
 	for productID := range updates {
-		_, err := s.GetProduct(productID, clientID)
+		product, err := s.GetProduct(productID, clientID)
 		if err != nil {
 			return fmt.Errorf("product %s: %w", productID, err)
 		}
+		if !product.IsAvailable() {
+			outOfStock = append(outOfStock, productID)
+		}
 	}
 
-	return s.productRepo.BulkUpdateStock(updates)
+	if err := s.productRepo.BulkUpdateStock(updates); err != nil {
+		return err
+	}
+
+	// Alert waitlists of products that are back in stock
+	for _, productID := range outOfStock {
+		if product, err := s.productRepo.GetByID(productID); err == nil {
+			s.notifyRestock(product)
+		}
+	}
+	return nil
 }
 
 // GetProductBySKU retrieves a product by SKU
@@ -247,6 +385,23 @@ func (s *ProductService) GetProductBySKU(clientID uuid.UUID, sku string) (*model
 	return product, nil
 }
 
+// GetProductByName retrieves a product by name (case-insensitive)
+func (s *ProductService) GetProductByName(clientID uuid.UUID, name string) (*models.Product, error) {
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	product, err := s.productRepo.GetByName(clientID, name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("product not found")
+		}
+		return nil, err
+	}
+
+	return product, nil
+}
+
 // ToggleProductStatus toggles product active status
 func (s *ProductService) ToggleProductStatus(productID string, clientID uuid.UUID) (*models.Product, error) {
 	product, err := s.GetProduct(productID, clientID)
@@ -263,3 +418,11 @@ func (s *ProductService) ToggleProductStatus(productID string, clientID uuid.UUI
 
 	return product, nil
 }
+
+// notifyRestock alerts the product's waitlist in the background once it is back in stock
+func (s *ProductService) notifyRestock(product *models.Product) {
+	if s.waitlistService == nil || !product.IsAvailable() {
+		return
+	}
+	go s.waitlistService.NotifyRestock(product)
+}
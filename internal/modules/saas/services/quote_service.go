@@ -0,0 +1,509 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/export"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const defaultQuoteValidDays = 7
+
+// Customer reply keywords for a quote
+const (
+	quoteKeywordAccept = "terima"
+	quoteKeywordReject = "tolak"
+)
+
+var errQuoteExpired = errors.New("quote has expired")
+
+// QuoteService handles quotations that convert into orders on acceptance
+type QuoteService struct {
+	quoteRepo     repositories.QuoteRepo
+	cartService   *CartService
+	orderService  *OrderService
+	exportService *export.Service
+	whatsappSvc   WhatsAppService
+	sandboxSvc    *SandboxService
+	publicBaseURL string
+}
+
+func NewQuoteService(
+	quoteRepo repositories.QuoteRepo,
+	cartService *CartService,
+	orderService *OrderService,
+	exportService *export.Service,
+	whatsappSvc WhatsAppService,
+	sandboxSvc *SandboxService,
+	publicBaseURL string,
+) *QuoteService {
+	return &QuoteService{
+		quoteRepo:     quoteRepo,
+		cartService:   cartService,
+		orderService:  orderService,
+		exportService: exportService,
+		whatsappSvc:   whatsappSvc,
+		sandboxSvc:    sandboxSvc,
+		publicBaseURL: strings.TrimRight(publicBaseURL, "/"),
+	}
+}
+
+// CreateQuote builds a quote from the given items or the customer's cart
+func (s *QuoteService) CreateQuote(req *models.CreateQuoteRequest) (*models.Quote, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client ID: %w", err)
+	}
+
+	items := req.Items
+	if req.FromCart {
+		cart, err := s.cartService.ViewCart(req.ClientID, req.CustomerPhone)
+		if err != nil || cart.IsEmpty() {
+			return nil, errors.New("customer cart is empty")
+		}
+		items = make([]models.QuoteItem, len(cart.Items))
+		for i, item := range cart.Items {
+			items[i] = models.QuoteItem{
+				ProductID:   item.ProductID,
+				ProductName: item.ProductName,
+				Quantity:    item.Quantity,
+				UnitPrice:   item.Price,
+			}
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, errors.New("quote must have at least one item")
+	}
+	for _, item := range items {
+		if item.ProductName == "" || item.Quantity <= 0 || item.UnitPrice < 0 {
+			return nil, errors.New("each item needs a product name, positive quantity and non-negative price")
+		}
+		if item.DiscountPercent < 0 || item.DiscountPercent > 100 {
+			return nil, errors.New("discount_percent must be between 0 and 100")
+		}
+	}
+
+	validDays := req.ValidDays
+	if validDays <= 0 {
+		validDays = defaultQuoteValidDays
+	}
+
+	token, err := randomHex(16)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate quote token: %w", err)
+	}
+
+	quote := &models.Quote{
+		ClientID:       clientID,
+		QuoteNumber:    s.generateQuoteNumber(),
+		CustomerPhone:  req.CustomerPhone,
+		CustomerName:   req.CustomerName,
+		Items:          items,
+		DiscountAmount: req.DiscountAmount,
+		Notes:          req.Notes,
+		Status:         models.QuoteStatusDraft,
+		ValidUntil:     time.Now().AddDate(0, 0, validDays),
+		AccessToken:    token,
+	}
+	quote.CalculateTotals()
+
+	if quote.DiscountAmount < 0 || quote.TotalAmount < 0 {
+		return nil, errors.New("discount_amount cannot exceed the quote subtotal")
+	}
+
+	if err := s.quoteRepo.Create(quote); err != nil {
+		return nil, fmt.Errorf("failed to create quote: %w", err)
+	}
+
+	log.Printf("📝 Quote created: %s (Client: %s, Total: %.2f)", quote.QuoteNumber, req.ClientID, quote.TotalAmount)
+
+	if req.Send {
+		if err := s.sendQuote(quote); err != nil {
+			return quote, err
+		}
+	}
+
+	return quote, nil
+}
+
+// GetQuote retrieves a quote by ID, scoped to the client
+func (s *QuoteService) GetQuote(quoteID, clientID string) (*models.Quote, error) {
+	quote, err := s.quoteRepo.GetByID(quoteID)
+	if err != nil || quote.ClientID.String() != clientID {
+		return nil, errors.New("quote not found")
+	}
+	s.expireIfOverdue(quote)
+	return quote, nil
+}
+
+// GetQuoteByToken retrieves a quote from its customer link token
+func (s *QuoteService) GetQuoteByToken(token string) (*models.Quote, error) {
+	quote, err := s.quoteRepo.GetByToken(token)
+	if err != nil {
+		return nil, errors.New("quote not found")
+	}
+	s.expireIfOverdue(quote)
+	return quote, nil
+}
+
+// ListQuotes lists a client's quotes with optional status filter
+func (s *QuoteService) ListQuotes(clientID, status string, limit int) ([]models.Quote, error) {
+	if uid, err := uuid.Parse(clientID); err == nil {
+		if _, err := s.quoteRepo.ExpireOverdue(uid); err != nil {
+			log.Printf("⚠️ Failed to expire overdue quotes: %v", err)
+		}
+	}
+	return s.quoteRepo.List(clientID, status, limit)
+}
+
+// SendQuote sends a draft (or re-sends a pending) quote to the customer via WhatsApp
+func (s *QuoteService) SendQuote(quoteID, clientID string) (*models.Quote, error) {
+	quote, err := s.GetQuote(quoteID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quote.Status != models.QuoteStatusDraft && quote.Status != models.QuoteStatusSent {
+		return nil, fmt.Errorf("cannot send quote with status %s", quote.Status)
+	}
+
+	if err := s.sendQuote(quote); err != nil {
+		return nil, err
+	}
+	return quote, nil
+}
+
+// AcceptQuote converts a quote into an order and sends payment instructions
+func (s *QuoteService) AcceptQuote(quote *models.Quote) (*models.Order, *payment.ProcessResult, error) {
+	if err := s.ensureOpen(quote); err != nil {
+		return nil, nil, err
+	}
+
+	orderReq := &CreateOrderRequest{
+		ClientID:      quote.ClientID.String(),
+		CustomerPhone: quote.CustomerPhone,
+		CustomerName:  quote.CustomerName,
+		Items:         quoteOrderItems(quote),
+		TotalAmount:   quote.TotalAmount,
+	}
+	if orderReq.CustomerName == "" {
+		orderReq.CustomerName = quote.CustomerPhone
+	}
+
+	order, result, err := s.orderService.CreateOrder(orderReq)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create order from quote: %w", err)
+	}
+
+	now := time.Now()
+	quote.Status = models.QuoteStatusAccepted
+	quote.OrderID = &order.ID
+	quote.RespondedAt = &now
+	if err := s.quoteRepo.Update(quote); err != nil {
+		log.Printf("⚠️ Failed to mark quote %s accepted: %v", quote.QuoteNumber, err)
+	}
+
+	log.Printf("✅ Quote %s accepted, order %s created", quote.QuoteNumber, order.OrderNumber)
+	return order, result, nil
+}
+
+// RejectQuote marks a quote as rejected (lost)
+func (s *QuoteService) RejectQuote(quote *models.Quote) error {
+	if err := s.ensureOpen(quote); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	quote.Status = models.QuoteStatusRejected
+	quote.RespondedAt = &now
+	if err := s.quoteRepo.Update(quote); err != nil {
+		return fmt.Errorf("failed to reject quote: %w", err)
+	}
+
+	log.Printf("❌ Quote %s rejected", quote.QuoteNumber)
+	return nil
+}
+
+// HandleCustomerReply accepts or rejects the customer's pending quote from a keyword reply.
+// Returns the reply and true if the message was handled.
+func (s *QuoteService) HandleCustomerReply(clientID, customerPhone, message string) (string, bool) {
+	fields := strings.Fields(strings.ToLower(strings.TrimSpace(message)))
+	if len(fields) == 0 || len(fields) > 2 || (fields[0] != quoteKeywordAccept && fields[0] != quoteKeywordReject) {
+		return "", false
+	}
+	// "terima kasih" and the like are not quote replies
+	if len(fields) == 2 && !strings.HasPrefix(fields[1], "quo-") {
+		return "", false
+	}
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return "", false
+	}
+
+	quote, err := s.quoteRepo.GetLatestSent(uid, customerPhone)
+	if err != nil {
+		return "", false
+	}
+
+	// An explicit quote number must match the pending quote
+	if len(fields) == 2 && !strings.EqualFold(fields[1], quote.QuoteNumber) {
+		return fmt.Sprintf("ℹ️ Penawaran %s tidak ditemukan. Penawaran aktif Anda: *%s*", strings.ToUpper(fields[1]), quote.QuoteNumber), true
+	}
+
+	return s.respondToQuote(quote, fields[0] == quoteKeywordAccept), true
+}
+
+// RespondToLatest accepts or rejects the customer's latest pending quote.
+// Returns false if the customer has no pending quote.
+func (s *QuoteService) RespondToLatest(clientID, customerPhone string, accept bool) (string, bool) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return "", false
+	}
+
+	quote, err := s.quoteRepo.GetLatestSent(uid, customerPhone)
+	if err != nil {
+		return "", false
+	}
+	return s.respondToQuote(quote, accept), true
+}
+
+// respondToQuote accepts or rejects a quote on the customer's behalf and returns the reply
+func (s *QuoteService) respondToQuote(quote *models.Quote, accept bool) string {
+	if !accept {
+		if err := s.RejectQuote(quote); err != nil {
+			return "ℹ️ " + quoteErrorMessage(err)
+		}
+		return fmt.Sprintf("🙏 Penawaran *%s* telah ditolak. Terima kasih atas pertimbangannya!", quote.QuoteNumber)
+	}
+
+	order, _, err := s.AcceptQuote(quote)
+	if err != nil {
+		log.Printf("⚠️ Failed to accept quote %s: %v", quote.QuoteNumber, err)
+		return "ℹ️ " + quoteErrorMessage(err)
+	}
+
+	// Payment instructions are sent by the order service
+	return fmt.Sprintf("✅ Penawaran *%s* diterima! Pesanan *#%s* telah dibuat.", quote.QuoteNumber, order.OrderNumber)
+}
+
+// RenderPDF renders the quote as a PDF document
+func (s *QuoteService) RenderPDF(quote *models.Quote) ([]byte, error) {
+	rows := make([][]interface{}, 0, len(quote.Items)+3)
+	for i, item := range quote.Items {
+		discount := "-"
+		if item.DiscountPercent > 0 {
+			discount = fmt.Sprintf("%.0f%%", item.DiscountPercent)
+		}
+		rows = append(rows, []interface{}{
+			i + 1,
+			item.ProductName,
+			item.Quantity,
+			"Rp " + formatCurrency(item.UnitPrice),
+			discount,
+			"Rp " + formatCurrency(item.Subtotal),
+		})
+	}
+
+	rows = append(rows, []interface{}{"", "Subtotal", "", "", "", "Rp " + formatCurrency(quote.Subtotal)})
+	if quote.DiscountAmount > 0 {
+		rows = append(rows, []interface{}{"", "Diskon", "", "", "", "- Rp " + formatCurrency(quote.DiscountAmount)})
+	}
+	rows = append(rows, []interface{}{"", "Total", "", "", "", "Rp " + formatCurrency(quote.TotalAmount)})
+
+	description := fmt.Sprintf("Kepada: %s (%s)\nBerlaku hingga: %s",
+		quote.CustomerName, quote.CustomerPhone, quote.ValidUntil.Format("02 Jan 2006"))
+	if quote.Notes != "" {
+		description += "\nCatatan: " + quote.Notes
+	}
+
+	data := &export.ExportData{
+		Title:       "Penawaran " + quote.QuoteNumber,
+		Description: description,
+		CreatedAt:   quote.CreatedAt,
+		Headers:     []string{"No", "Produk", "Qty", "Harga", "Diskon", "Subtotal"},
+		Rows:        rows,
+		Style:       export.DefaultStyle(),
+	}
+	return s.exportService.ExportToPDF(data)
+}
+
+// GetStats returns quote win/loss rates for the last N days
+func (s *QuoteService) GetStats(clientID string, days int) (*models.QuoteStats, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client ID: %w", err)
+	}
+	if days <= 0 {
+		days = 30
+	}
+
+	if _, err := s.quoteRepo.ExpireOverdue(uid); err != nil {
+		log.Printf("⚠️ Failed to expire overdue quotes: %v", err)
+	}
+
+	summary, err := s.quoteRepo.SummarizeByStatus(uid, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.QuoteStats{
+		Draft:         summary[models.QuoteStatusDraft].Count,
+		Sent:          summary[models.QuoteStatusSent].Count,
+		Accepted:      summary[models.QuoteStatusAccepted].Count,
+		Rejected:      summary[models.QuoteStatusRejected].Count,
+		Expired:       summary[models.QuoteStatusExpired].Count,
+		WonAmount:     summary[models.QuoteStatusAccepted].Amount,
+		PendingAmount: summary[models.QuoteStatusSent].Amount,
+	}
+	stats.Total = stats.Draft + stats.Sent + stats.Accepted + stats.Rejected + stats.Expired
+
+	if decided := stats.Accepted + stats.Rejected + stats.Expired; decided > 0 {
+		stats.WinRate = float64(stats.Accepted) / float64(decided) * 100
+		stats.LossRate = float64(stats.Rejected+stats.Expired) / float64(decided) * 100
+	}
+
+	return stats, nil
+}
+
+// RequestQuoteFromCart creates and sends a quote for the customer's cart (chat flow)
+func (s *QuoteService) RequestQuoteFromCart(clientID, customerPhone string) (*models.Quote, error) {
+	return s.CreateQuote(&models.CreateQuoteRequest{
+		ClientID:      clientID,
+		CustomerPhone: customerPhone,
+		FromCart:      true,
+		Send:          true,
+	})
+}
+
+// sendQuote sends the quote message to the customer and marks it sent
+func (s *QuoteService) sendQuote(quote *models.Quote) error {
+	if err := sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, quote.ClientID).SendMessage(quote.CustomerPhone, s.formatQuoteMessage(quote)); err != nil {
+		return fmt.Errorf("failed to send quote: %w", err)
+	}
+
+	now := time.Now()
+	quote.Status = models.QuoteStatusSent
+	quote.SentAt = &now
+	if err := s.quoteRepo.Update(quote); err != nil {
+		return fmt.Errorf("failed to update quote: %w", err)
+	}
+
+	log.Printf("📤 Quote %s sent to %s", quote.QuoteNumber, quote.CustomerPhone)
+	return nil
+}
+
+// formatQuoteMessage renders the quote as a WhatsApp message
+func (s *QuoteService) formatQuoteMessage(quote *models.Quote) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📝 *Penawaran Harga #%s*\n\n", quote.QuoteNumber))
+
+	for i, item := range quote.Items {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, item.ProductName))
+		sb.WriteString(fmt.Sprintf("   %dx @ Rp %s", item.Quantity, formatCurrency(item.UnitPrice)))
+		if item.DiscountPercent > 0 {
+			sb.WriteString(fmt.Sprintf(" (diskon %.0f%%)", item.DiscountPercent))
+		}
+		sb.WriteString(fmt.Sprintf(" = Rp %s\n", formatCurrency(item.Subtotal)))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nSubtotal: Rp %s\n", formatCurrency(quote.Subtotal)))
+	if quote.DiscountAmount > 0 {
+		sb.WriteString(fmt.Sprintf("Diskon: - Rp %s\n", formatCurrency(quote.DiscountAmount)))
+	}
+	sb.WriteString(fmt.Sprintf("💰 *Total: Rp %s*\n\n", formatCurrency(quote.TotalAmount)))
+
+	if quote.Notes != "" {
+		sb.WriteString(fmt.Sprintf("Catatan: %s\n\n", quote.Notes))
+	}
+
+	sb.WriteString(fmt.Sprintf("⏰ Berlaku hingga: %s\n\n", quote.ValidUntil.Format("02 Jan 2006")))
+	sb.WriteString(fmt.Sprintf("Balas *%s* untuk menyetujui atau *%s* untuk menolak penawaran ini.",
+		strings.ToUpper(quoteKeywordAccept), strings.ToUpper(quoteKeywordReject)))
+
+	if s.publicBaseURL != "" {
+		sb.WriteString(fmt.Sprintf("\n\nLihat penawaran: %s/q/%s", s.publicBaseURL, quote.AccessToken))
+	}
+
+	return sb.String()
+}
+
+// ensureOpen checks that a quote can still be accepted or rejected
+func (s *QuoteService) ensureOpen(quote *models.Quote) error {
+	s.expireIfOverdue(quote)
+
+	switch quote.Status {
+	case models.QuoteStatusSent, models.QuoteStatusDraft:
+		return nil
+	case models.QuoteStatusExpired:
+		return errQuoteExpired
+	default:
+		return fmt.Errorf("quote already %s", quote.Status)
+	}
+}
+
+// expireIfOverdue marks a pending quote past its validity as expired
+func (s *QuoteService) expireIfOverdue(quote *models.Quote) {
+	if quote.Status != models.QuoteStatusSent || !quote.IsExpired() {
+		return
+	}
+
+	quote.Status = models.QuoteStatusExpired
+	if err := s.quoteRepo.Update(quote); err != nil {
+		log.Printf("⚠️ Failed to expire quote %s: %v", quote.QuoteNumber, err)
+	}
+}
+
+// generateQuoteNumber generates a unique quote number
+func (s *QuoteService) generateQuoteNumber() string {
+	now := time.Now()
+	return fmt.Sprintf("QUO-%s-%d",
+		now.Format("20060102"),
+		now.UnixNano()/int64(time.Millisecond)%100000,
+	)
+}
+
+// quoteErrorMessage translates a quote error for the customer
+func quoteErrorMessage(err error) string {
+	if errors.Is(err, errQuoteExpired) {
+		return "Maaf, masa berlaku penawaran ini sudah habis. Silakan hubungi kami untuk penawaran baru."
+	}
+	return "Maaf, penawaran ini tidak dapat diproses. Silakan hubungi kami."
+}
+
+// quoteOrderItems converts quote lines to order items; the quote-level discount becomes a negative line
+func quoteOrderItems(quote *models.Quote) []payment.OrderItem {
+	items := make([]payment.OrderItem, 0, len(quote.Items)+1)
+	for _, item := range quote.Items {
+		productUUID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			productUUID = uuid.Nil
+		}
+		items = append(items, payment.OrderItem{
+			ProductID:   productUUID,
+			ProductName: item.ProductName,
+			Quantity:    item.Quantity,
+			UnitPrice:   item.Subtotal / float64(item.Quantity), // Line discount folded into the unit price
+			Subtotal:    item.Subtotal,
+		})
+	}
+
+	if quote.DiscountAmount > 0 {
+		items = append(items, payment.OrderItem{
+			ProductName: "Diskon Penawaran " + quote.QuoteNumber,
+			Quantity:    1,
+			UnitPrice:   -quote.DiscountAmount,
+			Subtotal:    -quote.DiscountAmount,
+		})
+	}
+	return items
+}
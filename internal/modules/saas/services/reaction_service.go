@@ -0,0 +1,250 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// ReactionEventName is the workflow event fired for every customer reaction
+const ReactionEventName = "reaction_received"
+
+// reactionIntents are the intents a reaction can be mapped to
+var reactionIntents = []string{
+	models.ReactionIntentConfirm,
+	models.ReactionIntentCancel,
+}
+
+// Actions taken for a reaction, reported to workflows
+const (
+	reactionActionCODConfirmed  = "cod_confirmed"
+	reactionActionCODCancelled  = "cod_cancelled"
+	reactionActionQuoteAccepted = "quote_accepted"
+	reactionActionQuoteRejected = "quote_rejected"
+)
+
+// ReactionService turns customer emoji reactions into intents and workflow events
+type ReactionService struct {
+	settingsRepo     repositories.ReactionSettingsRepo
+	conversationRepo repositories.ConversationRepo
+	orderService     *OrderService
+	quoteService     *QuoteService
+	workflowService  *WorkflowService
+}
+
+// NewReactionService creates a new reaction service
+func NewReactionService(
+	settingsRepo repositories.ReactionSettingsRepo,
+	conversationRepo repositories.ConversationRepo,
+	orderService *OrderService,
+	quoteService *QuoteService,
+	workflowService *WorkflowService,
+) *ReactionService {
+	return &ReactionService{
+		settingsRepo:     settingsRepo,
+		conversationRepo: conversationRepo,
+		orderService:     orderService,
+		quoteService:     quoteService,
+		workflowService:  workflowService,
+	}
+}
+
+// ReactionResult is the outcome of handling a reaction
+type ReactionResult struct {
+	Intent string // Mapped intent, empty if the emoji is not configured
+	Action string // What was done, empty if nothing was pending
+	Reply  string // Message for the customer, if any
+}
+
+// Handle applies the intent mapped to a customer's reaction and fires the reaction workflow event.
+// act is false while the bot is paused, so only workflows see the reaction.
+func (s *ReactionService) Handle(client *models.Client, customerPhone, emoji, messageID string, act bool) ReactionResult {
+	clientID := client.ID.String()
+	settings := s.getSettings(clientID)
+
+	var result ReactionResult
+	result.Intent = reactionIntent(settings, emoji)
+	if act && settings.Enabled && result.Intent != "" {
+		result.Action, result.Reply = s.applyIntent(clientID, customerPhone, result.Intent)
+	}
+
+	log.Printf("👍 Reaction %s from %s on %s (intent: %q, action: %q)", emoji, customerPhone, messageID, result.Intent, result.Action)
+
+	s.fireEvent(client, customerPhone, emoji, messageID, result)
+	return result
+}
+
+// applyIntent acts on what the customer has pending: an unconfirmed COD order first, then a sent quote
+func (s *ReactionService) applyIntent(clientID, customerPhone, intent string) (string, string) {
+	switch intent {
+	case models.ReactionIntentConfirm:
+		if s.orderService != nil {
+			if reply, ok := s.orderService.ConfirmAwaitingCOD(clientID, customerPhone); ok {
+				return reactionActionCODConfirmed, reply
+			}
+		}
+		if s.quoteService != nil {
+			if reply, ok := s.quoteService.RespondToLatest(clientID, customerPhone, true); ok {
+				return reactionActionQuoteAccepted, reply
+			}
+		}
+	case models.ReactionIntentCancel:
+		if s.orderService != nil && s.orderService.CancelAwaitingCOD(clientID, customerPhone) {
+			return reactionActionCODCancelled, ""
+		}
+		if s.quoteService != nil {
+			if reply, ok := s.quoteService.RespondToLatest(clientID, customerPhone, false); ok {
+				return reactionActionQuoteRejected, reply
+			}
+		}
+	}
+	return "", ""
+}
+
+// fireEvent triggers workflows listening for reactions, with the referenced message as context.
+// The message is looked up by its provider ID; when it wasn't recorded, the bot's latest reply is used.
+func (s *ReactionService) fireEvent(client *models.Client, customerPhone, emoji, messageID string, result ReactionResult) {
+	if s.workflowService == nil {
+		return
+	}
+
+	eventData := map[string]interface{}{
+		"client_id":      client.ID.String(),
+		"customer_phone": customerPhone,
+		"emoji":          emoji,
+		"intent":         result.Intent,
+		"action":         result.Action,
+		"message_id":     messageID,
+	}
+	if s.conversationRepo != nil {
+		if text, at, ok := s.referencedMessage(client.ID.String(), customerPhone, messageID); ok {
+			eventData["referenced_message"] = text
+			eventData["referenced_message_at"] = at
+		}
+	}
+
+	go func() {
+		if err := s.workflowService.HandleEvent(context.Background(), ReactionEventName, eventData); err != nil {
+			log.Printf("⚠️ Failed to trigger workflows for %s: %v", ReactionEventName, err)
+		}
+	}()
+}
+
+// referencedMessage returns the text and time of the message a reaction refers to
+func (s *ReactionService) referencedMessage(clientID, customerPhone, messageID string) (string, time.Time, bool) {
+	if messageID != "" {
+		if conversation, err := s.conversationRepo.GetByMessageID(clientID, messageID); err == nil {
+			if sameMessageID(conversation.InboundMessageID, messageID) {
+				return conversation.MessageText, conversation.CreatedAt, true
+			}
+			return conversation.AIResponse, conversation.CreatedAt, true
+		}
+	}
+
+	conversation, err := s.conversationRepo.GetLatestForCustomer(clientID, customerPhone)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return conversation.AIResponse, conversation.CreatedAt, true
+}
+
+// getSettings returns the tenant's reaction settings, falling back to the default mapping
+func (s *ReactionService) getSettings(clientID string) *models.ReactionSettings {
+	if s.settingsRepo != nil {
+		settings, err := s.settingsRepo.GetByClientID(clientID)
+		if err == nil {
+			return settings
+		}
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	intents, _ := json.Marshal(models.DefaultReactionIntents())
+	return &models.ReactionSettings{
+		ClientID: uid,
+		Enabled:  true,
+		Intents:  datatypes.JSON(intents),
+	}
+}
+
+// reactionIntent looks up the intent for an emoji, ignoring skin tones and variation selectors
+func reactionIntent(settings *models.ReactionSettings, emoji string) string {
+	var intents map[string]string
+	if len(settings.Intents) > 0 {
+		if err := json.Unmarshal(settings.Intents, &intents); err != nil {
+			log.Printf("⚠️ Invalid reaction intents for client %s: %v", settings.ClientID, err)
+			return ""
+		}
+	}
+
+	emoji = normalizeEmoji(emoji)
+	for key, intent := range intents {
+		if normalizeEmoji(key) == emoji {
+			return intent
+		}
+	}
+	return ""
+}
+
+// normalizeEmoji strips skin tone modifiers and variation selectors so 👍🏽 matches 👍
+func normalizeEmoji(emoji string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\uFE0F' || (r >= 0x1F3FB && r <= 0x1F3FF) {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(emoji))
+}
+
+// GetSettings returns the reaction settings configured for a client
+func (s *ReactionService) GetSettings(clientID string) (*models.ReactionSettings, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.getSettings(clientID), nil
+}
+
+// UpdateSettings validates and saves the reaction settings for a client
+func (s *ReactionService) UpdateSettings(clientID string, req *models.UpdateReactionSettingsRequest) (*models.ReactionSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	intents := make(map[string]string, len(req.Intents))
+	for emoji, intent := range req.Intents {
+		emoji = normalizeEmoji(emoji)
+		intent = strings.ToLower(strings.TrimSpace(intent))
+		if emoji == "" {
+			return nil, fmt.Errorf("emoji must not be empty")
+		}
+		if !slices.Contains(reactionIntents, intent) {
+			return nil, fmt.Errorf("unknown intent %q for %s (available: %s)", intent, emoji, strings.Join(reactionIntents, ", "))
+		}
+		intents[emoji] = intent
+	}
+
+	intentsJSON, err := json.Marshal(intents)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &models.ReactionSettings{
+		ClientID: uid,
+		Enabled:  req.Enabled,
+		Intents:  datatypes.JSON(intentsJSON),
+	}
+	if err := s.settingsRepo.Upsert(settings); err != nil {
+		return nil, fmt.Errorf("failed to save reaction settings: %w", err)
+	}
+
+	return s.getSettings(clientID), nil
+}
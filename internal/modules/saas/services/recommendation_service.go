@@ -0,0 +1,361 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// recommendationOrderWindow is how far back orders count for co-purchase statistics and customer history
+	recommendationOrderWindow = 180 * 24 * time.Hour
+	// recommendationConversionWindow is how long after a suggestion adding or ordering the product counts for it
+	recommendationConversionWindow = 7 * 24 * time.Hour
+	// recommendationRepeatWindow keeps a product from being suggested to the same customer again too soon
+	recommendationRepeatWindow = 24 * time.Hour
+	// recommendationCandidates is how many co-purchase candidates are ranked
+	recommendationCandidates = 10
+	// recommendationLLMTimeout bounds the LLM ranking; the co-purchase order is used when it runs out
+	recommendationLLMTimeout = 8 * time.Second
+)
+
+// RecommendationService suggests complementary products in chat ("yang beli kopi biasanya juga ambil
+// croissant"): candidates come from what other customers ordered together with the cart, or the customer's
+// own earlier orders, and are optionally ranked by the LLM against the conversation. Suggestions are
+// recorded so adding them to the cart and ordering them count as conversions.
+type RecommendationService struct {
+	repo            repositories.RecommendationRepo
+	llmService      *llm.Service
+	waitlistService *WaitlistService
+}
+
+// NewRecommendationService creates a new recommendation service
+func NewRecommendationService(repo repositories.RecommendationRepo, llmService *llm.Service, waitlistService *WaitlistService) *RecommendationService {
+	return &RecommendationService{
+		repo:            repo,
+		llmService:      llmService,
+		waitlistService: waitlistService,
+	}
+}
+
+// recommendationCandidate is a catalog product that could be suggested
+type recommendationCandidate struct {
+	product llm.Product
+	orders  int64
+}
+
+// Suggest picks complementary products for a customer's cart or order and returns the message offering
+// them, or "" when recommendations are off for this point, the customer was just offered something or
+// nothing fits. basket holds the products in the cart or order, the one the suggestion is about first.
+// Only products of the catalog (with a price) are suggested.
+func (s *RecommendationService) Suggest(ctx context.Context, clientID, customerPhone, trigger string, basket []string, message string, catalog []llm.Product) string {
+	uid, err := uuid.Parse(clientID)
+	if err != nil || len(basket) == 0 || len(catalog) == 0 {
+		return ""
+	}
+
+	settings := s.getSettings(clientID)
+	if !settings.Enabled ||
+		(trigger == models.RecommendationAfterAddToCart && !settings.AfterAddToCart) ||
+		(trigger == models.RecommendationAfterCheckout && !settings.AfterCheckout) {
+		return ""
+	}
+
+	now := time.Now()
+	if settings.CooldownMinutes > 0 {
+		last, err := s.repo.LastSuggestedAt(uid, customerPhone)
+		if err != nil {
+			log.Printf("⚠️ Failed to check last recommendation for %s: %v", customerPhone, err)
+			return ""
+		}
+		if last != nil && now.Sub(*last) < time.Duration(settings.CooldownMinutes)*time.Minute {
+			return ""
+		}
+	}
+
+	candidates, rankedBy := s.candidates(uid, clientID, customerPhone, basket, catalog, now)
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	if settings.UseLLM && len(candidates) > 1 && s.llmService != nil {
+		if ranked, ok := s.rankWithLLM(llm.WithUsage(ctx, clientID, models.UsageFeatureRecommendation), candidates, basket, message); ok {
+			candidates, rankedBy = ranked, models.RecommendationRankedByLLM
+		}
+	}
+	if len(candidates) > settings.MaxSuggestions {
+		candidates = candidates[:settings.MaxSuggestions]
+	}
+
+	sourceJSON, _ := json.Marshal(basket)
+	recommendations := make([]models.ProductRecommendation, len(candidates))
+	for i, candidate := range candidates {
+		recommendations[i] = models.ProductRecommendation{
+			ClientID:        uid,
+			CustomerPhone:   customerPhone,
+			TriggerPoint:    trigger,
+			SourceProducts:  sourceJSON,
+			ProductName:     candidate.product.Name,
+			Rank:            i + 1,
+			RankedBy:        rankedBy,
+			CoPurchaseCount: int(candidate.orders),
+		}
+	}
+	if err := s.repo.Create(recommendations); err != nil {
+		log.Printf("⚠️ Failed to record recommendations for %s: %v", customerPhone, err)
+		return ""
+	}
+
+	log.Printf("💡 Recommending %d products to %s %s (%s)", len(candidates), customerPhone, trigger, rankedBy)
+	return recommendationMessage(trigger, basket[0], candidates)
+}
+
+// candidates returns the catalog products most often ordered together with the basket, or when there are
+// none the customer's own favourites, leaving out the basket, recent suggestions and unavailable products
+func (s *RecommendationService) candidates(uid uuid.UUID, clientID, customerPhone string, basket []string, catalog []llm.Product, now time.Time) ([]recommendationCandidate, string) {
+	byName := make(map[string]llm.Product, len(catalog))
+	for _, product := range catalog {
+		if product.Price > 0 {
+			byName[strings.ToLower(product.Name)] = product
+		}
+	}
+
+	exclude := make([]string, 0, len(basket))
+	for _, name := range basket {
+		exclude = append(exclude, strings.ToLower(name))
+	}
+	if suggested, err := s.repo.SuggestedSince(uid, customerPhone, now.Add(-recommendationRepeatWindow)); err == nil {
+		exclude = append(exclude, suggested...)
+	}
+
+	pick := func(rows []repositories.CoPurchase) []recommendationCandidate {
+		var picked []recommendationCandidate
+		for _, row := range rows {
+			name := strings.ToLower(strings.TrimSpace(row.ProductName))
+			product, ok := byName[name]
+			if !ok || slices.Contains(exclude, name) {
+				continue
+			}
+			if s.waitlistService != nil {
+				if stocked, found := s.waitlistService.FindProduct(clientID, product.Name); found && !stocked.IsAvailable() {
+					continue
+				}
+			}
+			exclude = append(exclude, name)
+			picked = append(picked, recommendationCandidate{product: product, orders: row.Orders})
+		}
+		return picked
+	}
+
+	since := now.Add(-recommendationOrderWindow)
+	coPurchased, err := s.repo.CoPurchased(uid, exclude[:len(basket)], since, recommendationCandidates*2)
+	if err != nil {
+		log.Printf("⚠️ Failed to load co-purchases for client %s: %v", clientID, err)
+	}
+	if picked := pick(coPurchased); len(picked) > 0 {
+		if len(picked) > recommendationCandidates {
+			picked = picked[:recommendationCandidates]
+		}
+		return picked, models.RecommendationRankedByCoPurchase
+	}
+
+	history, err := s.repo.CustomerProducts(uid, customerPhone, since, recommendationCandidates)
+	if err != nil {
+		log.Printf("⚠️ Failed to load order history of %s: %v", customerPhone, err)
+	}
+	return pick(history), models.RecommendationRankedByHistory
+}
+
+// rankWithLLM asks the LLM to order the candidates by how well they fit the basket and the conversation
+func (s *RecommendationService) rankWithLLM(ctx context.Context, candidates []recommendationCandidate, basket []string, message string) ([]recommendationCandidate, bool) {
+	ctx, cancel := context.WithTimeout(ctx, recommendationLLMTimeout)
+	defer cancel()
+
+	var list strings.Builder
+	byName := make(map[string]recommendationCandidate, len(candidates))
+	for _, candidate := range candidates {
+		list.WriteString(fmt.Sprintf("- %s (ordered together %d times)\n", candidate.product.Name, candidate.orders))
+		byName[strings.ToLower(candidate.product.Name)] = candidate
+	}
+
+	systemPrompt := `You pick add-on products for a shop customer chatting on WhatsApp.
+Order the candidate products from the best to the worst complement for what the customer is buying and saying.
+Leave out candidates that clearly do not fit. Return ONLY a JSON array of candidate names, written exactly as listed.`
+	userPrompt := fmt.Sprintf("Customer is buying: %s\nCustomer's last message: %s\n\nCandidates:\n%s",
+		strings.Join(basket, ", "), message, list.String())
+
+	response, err := s.llmService.GenerateResponse(ctx, systemPrompt, userPrompt)
+	if err != nil {
+		log.Printf("⚠️ LLM recommendation ranking failed: %v", err)
+		return nil, false
+	}
+
+	cleaned := strings.TrimSpace(response)
+	cleaned = strings.TrimPrefix(cleaned, "```json")
+	cleaned = strings.TrimPrefix(cleaned, "```")
+	cleaned = strings.TrimSuffix(cleaned, "```")
+
+	var names []string
+	if err := json.Unmarshal([]byte(strings.TrimSpace(cleaned)), &names); err != nil {
+		log.Printf("⚠️ Failed to parse LLM recommendation ranking: %v", err)
+		return nil, false
+	}
+
+	var ranked []recommendationCandidate
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if candidate, ok := byName[key]; ok {
+			ranked = append(ranked, candidate)
+			delete(byName, key)
+		}
+	}
+	return ranked, len(ranked) > 0
+}
+
+// recommendationMessage offers the suggested products to the customer
+func recommendationMessage(trigger, focus string, candidates []recommendationCandidate) string {
+	var msg strings.Builder
+	if trigger == models.RecommendationAfterCheckout {
+		msg.WriteString("💡 *Mungkin Anda juga suka:*\n")
+	} else {
+		msg.WriteString(fmt.Sprintf("💡 *Yang beli %s biasanya juga ambil:*\n", focus))
+	}
+	for _, candidate := range candidates {
+		msg.WriteString(fmt.Sprintf("• %s - Rp %s\n", candidate.product.Name, formatCurrency(candidate.product.Price)))
+	}
+	if trigger == models.RecommendationAfterCheckout {
+		msg.WriteString("\nBisa dipesan kapan saja, cukup balas nama produknya 😊")
+	} else {
+		msg.WriteString("\nMau sekalian? Balas nama produknya ya 😊")
+	}
+	return msg.String()
+}
+
+// TrackAddToCart counts a product added to the cart as a conversion of the suggestions that offered it
+func (s *RecommendationService) TrackAddToCart(clientID, customerPhone, productName string) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if _, err := s.repo.MarkAddedToCart(uid, customerPhone, productName, now.Add(-recommendationConversionWindow), now); err != nil {
+		log.Printf("⚠️ Failed to track recommendation add-to-cart for %s: %v", customerPhone, err)
+	}
+}
+
+// TrackOrder marks the suggestions of products in a customer's order as converted
+func (s *RecommendationService) TrackOrder(order *models.Order) {
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil || len(items) == 0 {
+		return
+	}
+	products := make([]string, 0, len(items))
+	for _, item := range items {
+		products = append(products, strings.ToLower(item.ProductName))
+	}
+
+	now := time.Now()
+	converted, err := s.repo.MarkConverted(order.ClientID, order.CustomerPhone, products, order.ID, now.Add(-recommendationConversionWindow), now)
+	if err != nil {
+		log.Printf("⚠️ Failed to track recommendation conversions for order %s: %v", order.OrderNumber, err)
+		return
+	}
+	if converted > 0 {
+		log.Printf("💡 Order %s converted %d recommendations", order.OrderNumber, converted)
+	}
+}
+
+// Stats summarizes a client's suggestions between from and to
+func (s *RecommendationService) Stats(clientID uuid.UUID, from, to time.Time) (*models.RecommendationStats, error) {
+	stats := &models.RecommendationStats{From: from, To: to}
+
+	var err error
+	if stats.ByTrigger, err = s.repo.Stats(clientID, from, to, "trigger_point", 10); err != nil {
+		return nil, fmt.Errorf("failed to count recommendations: %w", err)
+	}
+	if stats.ByRankedBy, err = s.repo.Stats(clientID, from, to, "ranked_by", 10); err != nil {
+		return nil, fmt.Errorf("failed to count recommendations: %w", err)
+	}
+	if stats.TopProducts, err = s.repo.Stats(clientID, from, to, "product_name", 10); err != nil {
+		return nil, fmt.Errorf("failed to count recommendations: %w", err)
+	}
+
+	for _, row := range stats.ByTrigger {
+		stats.Suggested += row.Suggested
+		stats.AddedToCart += row.AddedToCart
+		stats.Converted += row.Converted
+	}
+	if stats.Suggested > 0 {
+		stats.ConversionRate = float64(int(float64(stats.Converted)/float64(stats.Suggested)*1000+0.5)) / 1000
+	}
+	return stats, nil
+}
+
+// getSettings returns the tenant's recommendation settings; recommendations are off until a tenant enables them
+func (s *RecommendationService) getSettings(clientID string) *models.RecommendationSettings {
+	settings, err := s.repo.GetSettings(clientID)
+	if err == nil {
+		return settings
+	}
+
+	uid, _ := uuid.Parse(clientID)
+	return &models.RecommendationSettings{
+		ClientID:        uid,
+		Enabled:         false,
+		AfterAddToCart:  true,
+		AfterCheckout:   true,
+		MaxSuggestions:  2,
+		UseLLM:          true,
+		CooldownMinutes: 30,
+	}
+}
+
+// GetSettings returns the recommendation settings configured for a client
+func (s *RecommendationService) GetSettings(clientID string) (*models.RecommendationSettings, error) {
+	if _, err := uuid.Parse(clientID); err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	return s.getSettings(clientID), nil
+}
+
+// UpdateSettings validates and saves the recommendation settings for a client
+func (s *RecommendationService) UpdateSettings(clientID string, req *models.UpdateRecommendationSettingsRequest) (*models.RecommendationSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if req.MaxSuggestions == 0 {
+		req.MaxSuggestions = 2
+	}
+	if req.MaxSuggestions < 1 || req.MaxSuggestions > 3 {
+		return nil, fmt.Errorf("max_suggestions must be between 1 and 3")
+	}
+	if req.CooldownMinutes < 0 || req.CooldownMinutes > 1440 {
+		return nil, fmt.Errorf("cooldown_minutes must be between 0 and 1440")
+	}
+
+	settings := &models.RecommendationSettings{
+		ClientID:        uid,
+		Enabled:         req.Enabled,
+		AfterAddToCart:  req.AfterAddToCart,
+		AfterCheckout:   req.AfterCheckout,
+		MaxSuggestions:  req.MaxSuggestions,
+		UseLLM:          req.UseLLM,
+		CooldownMinutes: req.CooldownMinutes,
+	}
+	if err := s.repo.UpsertSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to save recommendation settings: %w", err)
+	}
+
+	return s.getSettings(clientID), nil
+}
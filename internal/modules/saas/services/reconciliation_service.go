@@ -0,0 +1,419 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// reconciliationAmountTolerance absorbs rounding between our totals and gateway amounts
+const reconciliationAmountTolerance = 0.5
+
+// ReconciliationService matches paid orders against payment gateway settlements
+type ReconciliationService struct {
+	reconRepo  repositories.ReconciliationRepo
+	orderRepo  repositories.OrderRepo
+	clientRepo repositories.ClientRepo
+	gateway    payment.Gateway
+}
+
+func NewReconciliationService(
+	reconRepo repositories.ReconciliationRepo,
+	orderRepo repositories.OrderRepo,
+	clientRepo repositories.ClientRepo,
+	gateway payment.Gateway,
+) *ReconciliationService {
+	return &ReconciliationService{
+		reconRepo:  reconRepo,
+		orderRepo:  orderRepo,
+		clientRepo: clientRepo,
+		gateway:    gateway,
+	}
+}
+
+// GetReport returns the client's reconciliation for a day (YYYY-MM-DD in the client's timezone),
+// generating it when it doesn't exist yet or refresh is set
+func (s *ReconciliationService) GetReport(clientID, date string, refresh bool) (*models.ReconciliationReport, []models.ReconciliationItem, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, clientLocation(client.Timezone))
+	if err != nil {
+		return nil, nil, errors.New("date must be in YYYY-MM-DD format")
+	}
+	if day.After(time.Now()) {
+		return nil, nil, errors.New("date is in the future")
+	}
+
+	var report *models.ReconciliationReport
+	if !refresh {
+		report, err = s.reconRepo.GetReport(client.ID, day)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, err
+		}
+	}
+	if report == nil {
+		if report, err = s.Reconcile(client, day); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var items []models.ReconciliationItem
+	if len(report.Items) > 0 {
+		if err := json.Unmarshal(report.Items, &items); err != nil {
+			return nil, nil, fmt.Errorf("failed to decode report items: %w", err)
+		}
+	}
+
+	return report, items, nil
+}
+
+// Reconcile pulls gateway settlements for the day's orders, matches them and stores the report
+func (s *ReconciliationService) Reconcile(client *models.Client, day time.Time) (*models.ReconciliationReport, error) {
+	gateway := s.gateway.Name()
+	start := day
+	end := day.AddDate(0, 0, 1)
+
+	orders, err := s.orderRepo.ListForReconciliation(client.ID.String(), gateway, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	orderNumbers := make([]string, 0, len(orders))
+	for _, order := range orders {
+		orderNumbers = append(orderNumbers, order.OrderNumber)
+	}
+
+	if err := s.pullSettlements(client.ID, orderNumbers); err != nil {
+		return nil, err
+	}
+
+	// Settlements can land on a different day than the payment, so match both ways
+	settledInPeriod, err := s.reconRepo.ListSettlements(client.ID, gateway, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settlements: %w", err)
+	}
+	forOrders, err := s.reconRepo.ListSettlementsByOrderNumbers(client.ID, gateway, orderNumbers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load settlements: %w", err)
+	}
+
+	settlements := make(map[string]*models.PaymentSettlement)
+	for _, list := range [][]models.PaymentSettlement{settledInPeriod, forOrders} {
+		for i := range list {
+			settlement := &list[i]
+			// Prefer the settled record when an order has several attempts
+			if existing, ok := settlements[settlement.OrderNumber]; ok && existing.IsSettled() {
+				continue
+			}
+			settlements[settlement.OrderNumber] = settlement
+		}
+	}
+
+	ordersByNumber := make(map[string]*models.Order, len(orders))
+	for i := range orders {
+		ordersByNumber[orders[i].OrderNumber] = &orders[i]
+	}
+
+	// Settlements in the period for orders outside it
+	var missing []string
+	for orderNumber := range settlements {
+		if _, ok := ordersByNumber[orderNumber]; !ok {
+			missing = append(missing, orderNumber)
+		}
+	}
+	if len(missing) > 0 {
+		others, err := s.orderRepo.GetByOrderNumbers(client.ID.String(), missing)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load orders: %w", err)
+		}
+		for i := range others {
+			ordersByNumber[others[i].OrderNumber] = &others[i]
+		}
+	}
+
+	report := &models.ReconciliationReport{
+		ClientID: client.ID,
+		// Stored as a plain date so the database timezone can't shift the day
+		ReportDate:  time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC),
+		Gateway:     gateway,
+		GeneratedAt: time.Now(),
+	}
+
+	items := matchSettlements(ordersByNumber, settlements)
+	for _, item := range items {
+		if item.Result == models.ReconciliationMatched {
+			report.MatchedCount++
+		} else {
+			report.MismatchCount++
+		}
+		if item.PaymentStatus == models.PaymentStatusPaid {
+			report.PaidTotal += item.OrderAmount
+		}
+		report.SettledTotal += item.SettledAmount
+	}
+
+	itemsJSON, err := json.Marshal(items)
+	if err != nil {
+		return nil, err
+	}
+	report.Items = itemsJSON
+
+	if err := s.reconRepo.SaveReport(report); err != nil {
+		return nil, fmt.Errorf("failed to save report: %w", err)
+	}
+
+	if report.MismatchCount > 0 {
+		log.Printf("⚠️ Reconciliation %s for client %s: %d matched, %d mismatches",
+			day.Format("2006-01-02"), client.ID, report.MatchedCount, report.MismatchCount)
+	}
+
+	return report, nil
+}
+
+// pullSettlements refreshes stored settlements from the gateway, when it can report them
+func (s *ReconciliationService) pullSettlements(clientID uuid.UUID, orderNumbers []string) error {
+	source, ok := s.gateway.(payment.SettlementSource)
+	if !ok || len(orderNumbers) == 0 {
+		return nil
+	}
+
+	fetched, err := source.FetchSettlements(orderNumbers)
+	if err != nil {
+		return fmt.Errorf("failed to fetch gateway settlements: %w", err)
+	}
+
+	return s.reconRepo.UpsertSettlements(s.toSettlements(clientID, fetched, models.SettlementSourceGatewayAPI))
+}
+
+// ImportSettlementReport stores the transactions of a settlement report CSV downloaded from the gateway dashboard
+func (s *ReconciliationService) ImportSettlementReport(clientID string, r io.Reader) (int, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return 0, errors.New("invalid client_id")
+	}
+
+	parsed, err := payment.ParseSettlementReport(r)
+	if err != nil {
+		return 0, err
+	}
+
+	settlements := s.toSettlements(uid, parsed, models.SettlementSourceReportImport)
+	if err := s.reconRepo.UpsertSettlements(settlements); err != nil {
+		return 0, fmt.Errorf("failed to store settlements: %w", err)
+	}
+
+	log.Printf("📥 Imported %d gateway settlements for client %s", len(settlements), clientID)
+	return len(settlements), nil
+}
+
+func (s *ReconciliationService) toSettlements(clientID uuid.UUID, settlements []payment.Settlement, source string) []models.PaymentSettlement {
+	records := make([]models.PaymentSettlement, 0, len(settlements))
+	seen := make(map[string]int, len(settlements))
+	for _, settlement := range settlements {
+		transactionID := settlement.TransactionID
+		if transactionID == "" {
+			// Reports without transaction IDs are keyed by order number
+			transactionID = settlement.OrderID
+		}
+
+		record := models.PaymentSettlement{
+			ClientID:      clientID,
+			Gateway:       s.gateway.Name(),
+			OrderNumber:   settlement.OrderID,
+			TransactionID: transactionID,
+			Status:        settlement.Status,
+			PaymentType:   settlement.PaymentType,
+			GrossAmount:   settlement.GrossAmount,
+			SettledAt:     settlement.SettledAt,
+			Source:        source,
+		}
+
+		// A transaction can appear more than once in a report; the last row wins
+		if i, ok := seen[transactionID]; ok {
+			records[i] = record
+			continue
+		}
+		seen[transactionID] = len(records)
+		records = append(records, record)
+	}
+	return records
+}
+
+// ExportCSV renders the report items as CSV
+func (s *ReconciliationService) ExportCSV(items []models.ReconciliationItem) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF") // UTF-8 BOM so Excel detects the encoding
+	writer := csv.NewWriter(&buf)
+
+	header := []string{
+		"order_number", "transaction_id", "result", "payment_status", "settlement_status",
+		"order_amount", "settled_amount", "difference", "paid_at", "settled_at",
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	formatTime := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format(time.RFC3339)
+	}
+
+	for _, item := range items {
+		record := []string{
+			item.OrderNumber,
+			item.TransactionID,
+			item.Result,
+			item.PaymentStatus,
+			item.SettlementStatus,
+			fmt.Sprintf("%.2f", item.OrderAmount),
+			fmt.Sprintf("%.2f", item.SettledAmount),
+			fmt.Sprintf("%.2f", item.SettledAmount-item.OrderAmount),
+			formatTime(item.PaidAt),
+			formatTime(item.SettledAt),
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// RunDailyJob reconciles the previous day for every client with gateway orders once per interval until ctx is done
+func (s *ReconciliationService) RunDailyJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reconcileYesterday()
+		}
+	}
+}
+
+func (s *ReconciliationService) reconcileYesterday() {
+	now := time.Now()
+	clientIDs, err := s.reconRepo.ListClientsWithGatewayOrders(s.gateway.Name(), now.Add(-48*time.Hour), now)
+	if err != nil {
+		log.Printf("⚠️ Failed to list clients for reconciliation: %v", err)
+		return
+	}
+
+	for _, clientID := range clientIDs {
+		client, err := s.clientRepo.GetByID(clientID.String())
+		if err != nil {
+			continue
+		}
+
+		yesterday := startOfDay(now, client.Timezone).AddDate(0, 0, -1)
+		if _, err := s.Reconcile(client, yesterday); err != nil {
+			log.Printf("⚠️ Reconciliation for client %s: %v", clientID, err)
+		}
+	}
+}
+
+// matchSettlements pairs orders with gateway settlements by order number and flags mismatches
+func matchSettlements(orders map[string]*models.Order, settlements map[string]*models.PaymentSettlement) []models.ReconciliationItem {
+	var items []models.ReconciliationItem
+
+	for orderNumber, order := range orders {
+		settlement, hasSettlement := settlements[orderNumber]
+		settled := hasSettlement && settlement.IsSettled()
+		paid := order.PaymentStatus == models.PaymentStatusPaid
+
+		item := models.ReconciliationItem{
+			OrderNumber:   orderNumber,
+			OrderID:       order.ID.String(),
+			PaymentStatus: order.PaymentStatus,
+			OrderAmount:   order.TotalAmount,
+			PaidAt:        order.PaidAt,
+		}
+		if hasSettlement {
+			item.TransactionID = settlement.TransactionID
+			item.SettlementStatus = settlement.Status
+			item.SettledAt = settlement.SettledAt
+			if settled {
+				item.SettledAmount = settlement.GrossAmount
+			}
+		}
+
+		switch {
+		case paid && settled:
+			item.Result = models.ReconciliationMatched
+			if math.Abs(settlement.GrossAmount-order.TotalAmount) > reconciliationAmountTolerance {
+				item.Result = models.ReconciliationAmountMismatch
+			}
+		case paid:
+			item.Result = models.ReconciliationPaidWithoutSettlement
+		case settled:
+			item.Result = models.ReconciliationUnpaidWithSettlement
+		default:
+			// Unpaid and unsettled orders have nothing to reconcile
+			continue
+		}
+
+		items = append(items, item)
+	}
+
+	for orderNumber, settlement := range settlements {
+		if _, ok := orders[orderNumber]; ok || !settlement.IsSettled() {
+			continue
+		}
+		items = append(items, models.ReconciliationItem{
+			OrderNumber:      orderNumber,
+			TransactionID:    settlement.TransactionID,
+			Result:           models.ReconciliationSettlementWithoutOrder,
+			SettlementStatus: settlement.Status,
+			SettledAmount:    settlement.GrossAmount,
+			SettledAt:        settlement.SettledAt,
+		})
+	}
+
+	// Mismatches first, then by order number
+	sort.Slice(items, func(i, j int) bool {
+		iMatched := items[i].Result == models.ReconciliationMatched
+		jMatched := items[j].Result == models.ReconciliationMatched
+		if iMatched != jMatched {
+			return !iMatched
+		}
+		return items[i].OrderNumber < items[j].OrderNumber
+	})
+
+	return items
+}
+
+// clientLocation returns the client's timezone, falling back to the server's
+func clientLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" {
+		return time.Local
+	}
+	return loc
+}
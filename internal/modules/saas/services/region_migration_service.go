@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/region"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// regionMigrationTimeout bounds copying one tenant to another region
+const regionMigrationTimeout = time.Hour
+
+// regionMigrationSkipTables have a client_id column but describe the platform, not the tenant
+var regionMigrationSkipTables = map[string]bool{
+	"saas_region_migrations": true,
+	"saas_kb_sync_outbox":    true, // Refilled by the knowledge base trigger as entries are copied
+}
+
+var (
+	// ErrRegionMigrationRunning is returned when a client is already being moved
+	ErrRegionMigrationRunning = errors.New("a region migration is already running for this client")
+	// ErrSameRegion is returned when a client is moved to the region it is in
+	ErrSameRegion = errors.New("client is already in this region")
+)
+
+// RegionMigrationService moves a tenant's data between regions: it copies the tenant's rows from the source
+// region's database to the target's, verifies the row counts, rebuilds the tenant's vectors in the target
+// region, then repoints clients.region in the directory. Source rows are kept for rollback, and uploaded
+// files stay where they were stored (their URLs keep working).
+type RegionMigrationService struct {
+	repo       repositories.RegionMigrationRepo
+	clientRepo repositories.ClientRepo
+	registry   *region.Registry
+	mu         sync.Mutex
+	active     map[uuid.UUID]bool
+}
+
+func NewRegionMigrationService(repo repositories.RegionMigrationRepo, clientRepo repositories.ClientRepo, registry *region.Registry) *RegionMigrationService {
+	return &RegionMigrationService{
+		repo:       repo,
+		clientRepo: clientRepo,
+		registry:   registry,
+		active:     make(map[uuid.UUID]bool),
+	}
+}
+
+// Regions describes the configured regions
+func (s *RegionMigrationService) Regions() []region.Info {
+	return s.registry.List()
+}
+
+// History returns a client's region migrations newest first
+func (s *RegionMigrationService) History(clientID uuid.UUID, limit int) ([]models.RegionMigration, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+	return s.repo.ListByClient(clientID, limit)
+}
+
+// Migrate starts moving a client to another region; the copy runs in the background
+func (s *RegionMigrationService) Migrate(clientID uuid.UUID, toRegion string) (*models.RegionMigration, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	// The default region is stored as empty so a client never needs updating when DEFAULT_REGION is renamed
+	if toRegion == s.registry.Default().Code {
+		toRegion = ""
+	}
+	if toRegion == client.Region {
+		return nil, ErrSameRegion
+	}
+	source, err := s.registry.Get(client.Region)
+	if err != nil {
+		return nil, fmt.Errorf("source region: %w", err)
+	}
+	target, err := s.registry.Get(toRegion)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.active[clientID] {
+		s.mu.Unlock()
+		return nil, ErrRegionMigrationRunning
+	}
+	s.active[clientID] = true
+	s.mu.Unlock()
+
+	migration := &models.RegionMigration{
+		ClientID:   clientID,
+		FromRegion: client.Region,
+		ToRegion:   toRegion,
+		Status:     models.RegionMigrationPending,
+		Tables:     datatypes.JSON("[]"),
+	}
+	if err := s.repo.Create(migration); err != nil {
+		s.release(clientID)
+		return nil, fmt.Errorf("failed to record region migration: %w", err)
+	}
+
+	go s.run(migration, source, target)
+	return migration, nil
+}
+
+func (s *RegionMigrationService) release(clientID uuid.UUID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.active, clientID)
+}
+
+func (s *RegionMigrationService) run(migration *models.RegionMigration, source, target *region.Region) {
+	defer s.release(migration.ClientID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), regionMigrationTimeout)
+	defer cancel()
+
+	now := time.Now()
+	migration.Status = models.RegionMigrationRunning
+	migration.StartedAt = &now
+	s.save(migration)
+	log.Printf("🌏 Moving client %s from region %q to %q", migration.ClientID, source.Code, target.Code)
+
+	err := s.copyTenant(ctx, migration, source, target)
+	if err == nil {
+		err = s.switchRegion(ctx, migration, target)
+	}
+
+	finished := time.Now()
+	migration.FinishedAt = &finished
+	if err != nil {
+		log.Printf("❌ Region migration %s failed: %v", migration.ID, err)
+		migration.Status = models.RegionMigrationFailed
+		migration.Error = err.Error()
+	} else {
+		log.Printf("✅ Client %s now in region %q", migration.ClientID, target.Code)
+		migration.Status = models.RegionMigrationCompleted
+	}
+	s.save(migration)
+}
+
+// copyTenant copies the client row, then every tenant table parents first, checking the target has every source row
+func (s *RegionMigrationService) copyTenant(ctx context.Context, migration *models.RegionMigration, source, target *region.Region) error {
+	clientID := migration.ClientID.String()
+
+	var results []models.RegionMigrationTable
+	copyTable := func(table, column string) error {
+		result := models.RegionMigrationTable{Table: table}
+		var err error
+		if result.SourceRows, err = source.DB.CountRows(ctx, table, column, clientID); err != nil {
+			return fmt.Errorf("failed to count %s: %w", table, err)
+		}
+		if result.CopiedRows, err = database.CopyRows(ctx, source.DB, target.DB, table, column, clientID); err != nil {
+			return err
+		}
+		if result.TargetRows, err = target.DB.CountRows(ctx, table, column, clientID); err != nil {
+			return fmt.Errorf("failed to count %s in target region: %w", table, err)
+		}
+
+		results = append(results, result)
+		migration.Tables, _ = json.Marshal(results)
+		s.save(migration)
+
+		if result.TargetRows < result.SourceRows {
+			return fmt.Errorf("%s: %d of %d rows in target region", table, result.TargetRows, result.SourceRows)
+		}
+		return nil
+	}
+
+	if err := copyTable("clients", "id"); err != nil {
+		return err
+	}
+
+	tables, err := source.DB.TenantTables(ctx)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		if regionMigrationSkipTables[table] {
+			continue
+		}
+		if err := copyTable(table, "client_id"); err != nil {
+			return err
+		}
+	}
+
+	// Embeddings are rebuilt from the copied knowledge base rather than copied between vector databases
+	if target.Vector != nil {
+		if err := target.Vector.SyncFromDatabase(ctx, kb.NewRetriever(target.DB.GORM), clientID); err != nil {
+			return fmt.Errorf("failed to index knowledge base in target region: %w", err)
+		}
+		migration.VectorSynced = true
+	} else {
+		log.Printf("⚠️  Region %q has no vector DB, semantic search stays disabled for client %s", target.Code, clientID)
+	}
+	return nil
+}
+
+// switchRegion records the new region in the directory and in the target's copy of the client row
+func (s *RegionMigrationService) switchRegion(ctx context.Context, migration *models.RegionMigration, target *region.Region) error {
+	if err := s.repo.SetClientRegion(migration.ClientID, migration.ToRegion); err != nil {
+		return fmt.Errorf("failed to update client region: %w", err)
+	}
+	if target != s.registry.Default() {
+		if _, err := target.DB.ExecContext(ctx, "UPDATE clients SET region = $1 WHERE id = $2", migration.ToRegion, migration.ClientID); err != nil {
+			log.Printf("⚠️  Failed to update client region in region %q: %v", target.Code, err)
+		}
+	}
+	s.registry.SetClientRegion(migration.ClientID.String(), migration.ToRegion)
+	return nil
+}
+
+func (s *RegionMigrationService) save(migration *models.RegionMigration) {
+	if err := s.repo.Update(migration); err != nil {
+		log.Printf("⚠️  Failed to save region migration %s: %v", migration.ID, err)
+	}
+}
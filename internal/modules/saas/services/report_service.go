@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxReportRange bounds the date range of a report
+	maxReportRange = 366 * 24 * time.Hour
+	// maxTopProducts caps the products of a top products report
+	maxTopProducts = 100
+)
+
+// ErrReportRangeTooLong is returned for reports over more than a year
+var ErrReportRangeTooLong = errors.New("report range can't exceed one year")
+
+// ReportService computes the sales, product and conversation reports of tenant dashboards in SQL
+type ReportService struct {
+	reportRepo repositories.ReportRepo
+}
+
+func NewReportService(reportRepo repositories.ReportRepo) *ReportService {
+	return &ReportService{
+		reportRepo: reportRepo,
+	}
+}
+
+// GetSalesReport returns order count, revenue, refunds, average order value and OCR transactions of [from, to), with daily totals
+func (s *ReportService) GetSalesReport(clientID uuid.UUID, from, to time.Time) (*models.SalesReport, error) {
+	if to.Sub(from) > maxReportRange {
+		return nil, ErrReportRangeTooLong
+	}
+
+	sales, err := s.reportRepo.SalesSummary(clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum orders: %w", err)
+	}
+	transactions, err := s.reportRepo.TransactionSummary(clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum transactions: %w", err)
+	}
+	daily, err := s.reportRepo.SalesByDay(clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum orders per day: %w", err)
+	}
+
+	report := &models.SalesReport{
+		From:              from,
+		To:                to,
+		Orders:            sales.Orders,
+		PaidOrders:        sales.PaidOrders,
+		CancelledOrders:   sales.CancelledOrders,
+		Revenue:           sales.Revenue,
+		RefundedAmount:    sales.RefundedAmount,
+		NetRevenue:        sales.Revenue - sales.RefundedAmount,
+		Transactions:      transactions.Transactions,
+		OCRTransactions:   transactions.OCRTransactions,
+		TransactionAmount: transactions.TransactionAmount,
+		Daily:             daily,
+	}
+	if sales.PaidOrders > 0 {
+		report.AverageOrderValue = sales.Revenue / float64(sales.PaidOrders)
+	}
+	if report.Daily == nil {
+		report.Daily = []models.SalesReportDay{}
+	}
+	return report, nil
+}
+
+// GetTopProducts ranks the products of paid orders created in [from, to) by revenue or units sold
+func (s *ReportService) GetTopProducts(clientID uuid.UUID, from, to time.Time, sortBy string, limit int) (*models.TopProductsReport, error) {
+	if to.Sub(from) > maxReportRange {
+		return nil, ErrReportRangeTooLong
+	}
+	if sortBy != "units" {
+		sortBy = "revenue"
+	}
+	if limit <= 0 || limit > maxTopProducts {
+		limit = 10
+	}
+
+	products, err := s.reportRepo.TopProducts(clientID, from, to, sortBy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rank products: %w", err)
+	}
+	if products == nil {
+		products = []models.ProductSales{}
+	}
+
+	return &models.TopProductsReport{
+		From:     from,
+		To:       to,
+		SortBy:   sortBy,
+		Products: products,
+	}, nil
+}
+
+// GetConversationReport returns the customer messages, AI and agent replies and distinct customers of [from, to), with daily counts
+func (s *ReportService) GetConversationReport(clientID uuid.UUID, from, to time.Time) (*models.ConversationReport, error) {
+	if to.Sub(from) > maxReportRange {
+		return nil, ErrReportRangeTooLong
+	}
+
+	summary, err := s.reportRepo.ConversationVolume(clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count conversations: %w", err)
+	}
+	daily, err := s.reportRepo.ConversationsByDay(clientID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count conversations per day: %w", err)
+	}
+
+	report := &models.ConversationReport{
+		From:             from,
+		To:               to,
+		CustomerMessages: summary.CustomerMessages,
+		AIReplies:        summary.AIReplies,
+		AgentReplies:     summary.AgentReplies,
+		Customers:        summary.Customers,
+		Daily:            daily,
+	}
+	if summary.CustomerMessages > 0 {
+		report.AIReplyRate = float64(summary.AIReplies) / float64(summary.CustomerMessages)
+	}
+	if report.Daily == nil {
+		report.Daily = []models.ConversationReportDay{}
+	}
+	return report, nil
+}
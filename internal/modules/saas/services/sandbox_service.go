@@ -0,0 +1,203 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// SandboxService routes WhatsApp traffic for tenants in sandbox mode to a simulated inbox
+type SandboxService struct {
+	clientRepo  repositories.ClientRepo
+	sandboxRepo repositories.SandboxRepo
+	whatsappSvc WhatsAppService
+	outbound    *OutboundMessageService
+	sessions    *whatsapp.SessionManager
+}
+
+// NewSandboxService creates a new sandbox service
+func NewSandboxService(clientRepo repositories.ClientRepo, sandboxRepo repositories.SandboxRepo, whatsappSvc WhatsAppService) *SandboxService {
+	return &SandboxService{
+		clientRepo:  clientRepo,
+		sandboxRepo: sandboxRepo,
+		whatsappSvc: whatsappSvc,
+	}
+}
+
+// SetOutboundQueue sends live messages through the outbound queue, so failed sends are retried
+func (s *SandboxService) SetOutboundQueue(outbound *OutboundMessageService) {
+	s.outbound = outbound
+}
+
+// SetSessionManager sends live messages through each client's own WhatsApp session
+func (s *SandboxService) SetSessionManager(sessions *whatsapp.SessionManager) {
+	s.sessions = sessions
+}
+
+// IsSandbox reports whether a client is in sandbox mode
+func (s *SandboxService) IsSandbox(clientID string) bool {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return false
+	}
+	return client.SandboxMode
+}
+
+// SetSandboxMode turns sandbox mode on or off for a client
+func (s *SandboxService) SetSandboxMode(clientID string, enabled bool) (*models.Client, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	client.SandboxMode = enabled
+	if err := s.clientRepo.Update(client); err != nil {
+		return nil, fmt.Errorf("failed to update sandbox mode: %w", err)
+	}
+
+	if enabled {
+		log.Printf("🧪 Sandbox mode enabled for client %s", clientID)
+	} else {
+		log.Printf("✅ Sandbox mode disabled for client %s", clientID)
+	}
+
+	return client, nil
+}
+
+// SendMessage queues a WhatsApp message for delivery, or captures it with a TEST MODE marker in sandbox mode
+func (s *SandboxService) SendMessage(clientID, to, message string) error {
+	if !s.IsSandbox(clientID) {
+		if s.outbound != nil {
+			return s.outbound.Enqueue(clientID, to, message, "")
+		}
+		if s.sessions != nil {
+			_, err := s.sessions.SendMessage(clientID, to, message)
+			return err
+		}
+		return s.whatsappSvc.SendMessage(to, message)
+	}
+
+	return s.record(clientID, models.SandboxDirectionOutbound, to, markTestMode(message))
+}
+
+// RecordInbound stores a simulated incoming customer message
+func (s *SandboxService) RecordInbound(clientID, from, message string) error {
+	return s.record(clientID, models.SandboxDirectionInbound, from, message)
+}
+
+// ListMessages returns the latest simulated messages for a client (oldest first)
+func (s *SandboxService) ListMessages(clientID, phone string, since *time.Time, limit int) ([]models.SandboxMessage, error) {
+	return s.sandboxRepo.ListMessages(clientID, phone, since, limit)
+}
+
+// ClearMessages deletes all simulated messages for a client
+func (s *SandboxService) ClearMessages(clientID string) (int64, error) {
+	return s.sandboxRepo.DeleteMessages(clientID)
+}
+
+// Messenger returns a WhatsAppService bound to a client that respects sandbox mode
+func (s *SandboxService) Messenger(clientID string) WhatsAppService {
+	return &clientMessenger{sandbox: s, clientID: clientID}
+}
+
+// sandboxAwareMessenger returns the WhatsApp sender for a client: the sandbox's when sandbox mode is wired
+// in (messages are captured instead of sent while the client is in sandbox mode), whatsappSvc otherwise
+func sandboxAwareMessenger(sandboxSvc *SandboxService, whatsappSvc WhatsAppService, clientID uuid.UUID) WhatsAppService {
+	if sandboxSvc != nil {
+		return sandboxSvc.Messenger(clientID.String())
+	}
+	return whatsappSvc
+}
+
+// record stores a simulated message
+func (s *SandboxService) record(clientID, direction, phone, message string) error {
+	clientUUID, err := uuid.Parse(clientID)
+	if err != nil {
+		return fmt.Errorf("invalid client id: %w", err)
+	}
+
+	err = s.sandboxRepo.CreateMessage(&models.SandboxMessage{
+		ClientID:  clientUUID,
+		Direction: direction,
+		Phone:     phone,
+		Message:   message,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store sandbox message: %w", err)
+	}
+
+	log.Printf("🧪 [TEST MODE] %s message captured for client %s (%s)", direction, clientID, phone)
+	return nil
+}
+
+// clientMessenger adapts SandboxService to the WhatsAppService interface for one client
+type clientMessenger struct {
+	sandbox  *SandboxService
+	clientID string
+}
+
+// SendMessage sends or captures a message for the bound client
+func (m *clientMessenger) SendMessage(to, message string) error {
+	return m.sandbox.SendMessage(m.clientID, to, message)
+}
+
+// markTestMode prefixes a message with the TEST MODE marker (once)
+func markTestMode(message string) string {
+	if strings.HasPrefix(message, payment.TestModeMarker) {
+		return message
+	}
+	return payment.TestModeMarker + "\n" + message
+}
+
+// SettleSandboxPayment settles a simulated payment for a test order of the client (paid or failed)
+func (s *OrderService) SettleSandboxPayment(clientID, orderID, status string) (*models.Order, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil || order.ClientID.String() != clientID {
+		return nil, ErrOrderNotFound
+	}
+
+	if !order.IsTest {
+		return nil, fmt.Errorf("only test orders can be settled through the sandbox")
+	}
+
+	if order.PaymentStatus != models.PaymentStatusPending {
+		return nil, fmt.Errorf("order payment is already %s", order.PaymentStatus)
+	}
+
+	switch status {
+	case payment.StatusPaid:
+		reference := "SANDBOX-" + strings.ToUpper(uuid.New().String()[:8])
+		if err := s.ConfirmPayment(orderID, "sandbox", reference); err != nil {
+			return nil, err
+		}
+
+	case payment.StatusFailed:
+		order.PaymentStatus = payment.StatusFailed
+		if err := s.orderRepo.Update(order); err != nil {
+			return nil, err
+		}
+
+		message := fmt.Sprintf(
+			"❌ *Pembayaran Gagal*\n\n"+
+				"No. Pesanan: *#%s*\n"+
+				"Total: *Rp %s*\n\n"+
+				"Silakan coba lagi atau hubungi kami.",
+			order.OrderNumber,
+			formatPrice(order.TotalAmount),
+		)
+		s.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+
+	default:
+		return nil, fmt.Errorf("status must be '%s' or '%s'", payment.StatusPaid, payment.StatusFailed)
+	}
+
+	log.Printf("🧪 [TEST MODE] Sandbox payment %s for order %s", status, order.OrderNumber)
+	return s.orderRepo.GetByID(orderID)
+}
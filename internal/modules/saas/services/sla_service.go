@@ -0,0 +1,383 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/analytics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Workflow events fired when a thread misses an SLA target
+const (
+	EventSLAFirstResponseBreached = "sla_first_response_breached"
+	EventSLAResolutionBreached    = "sla_resolution_breached"
+)
+
+const (
+	defaultFirstResponseTarget = 5 * time.Minute
+	defaultResolutionTarget    = 24 * time.Hour
+	defaultIdleResolve         = time.Hour
+
+	// slaJobBatch is how many open threads the job checks per tick
+	slaJobBatch = 500
+)
+
+var ErrNoOpenThread = errors.New("customer has no open thread")
+
+// SLAService tracks first-response and resolution times of customer threads and reports them against the client's targets
+type SLAService struct {
+	slaRepo         repositories.SLARepo
+	workflowService *WorkflowService
+}
+
+// NewSLAService creates a new SLA service
+func NewSLAService(slaRepo repositories.SLARepo, workflowService *WorkflowService) *SLAService {
+	return &SLAService{
+		slaRepo:         slaRepo,
+		workflowService: workflowService,
+	}
+}
+
+// RecordCustomerMessage opens a thread for the customer, or extends their open one
+func (s *SLAService) RecordCustomerMessage(client *models.Client, customerPhone string) {
+	if client.SandboxMode {
+		return
+	}
+
+	now := time.Now()
+	thread, err := s.slaRepo.GetOpenThread(client.ID, customerPhone)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("⚠️ Failed to load SLA thread for %s: %v", customerPhone, err)
+			return
+		}
+		_, err = s.slaRepo.OpenThread(&models.SLAThread{
+			ClientID:              client.ID,
+			CustomerPhone:         customerPhone,
+			Status:                models.SLAThreadOpen,
+			OpenedAt:              now,
+			LastCustomerMessageAt: now,
+			LastActivityAt:        now,
+		})
+		if err != nil {
+			log.Printf("⚠️ Failed to open SLA thread for %s: %v", customerPhone, err)
+		}
+		return
+	}
+
+	thread.LastCustomerMessageAt = now
+	thread.LastActivityAt = now
+	if err := s.slaRepo.UpdateThread(thread); err != nil {
+		log.Printf("⚠️ Failed to update SLA thread %s: %v", thread.ID, err)
+	}
+}
+
+// RecordResponse records an answer to the customer's open thread; agent is empty for the bot
+func (s *SLAService) RecordResponse(client *models.Client, customerPhone, agent string) {
+	if client.SandboxMode {
+		return
+	}
+
+	thread, err := s.slaRepo.GetOpenThread(client.ID, customerPhone)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Printf("⚠️ Failed to load SLA thread for %s: %v", customerPhone, err)
+		}
+		return // Messages the business starts aren't responses
+	}
+
+	now := time.Now()
+	responder := agent
+	if responder == "" {
+		responder = models.SLAResponderBot
+	}
+	if thread.FirstResponseAt == nil {
+		thread.FirstResponseAt = &now
+		thread.FirstResponseBy = responder
+	}
+	if agent != "" && thread.FirstAgentResponseAt == nil {
+		thread.FirstAgentResponseAt = &now
+		thread.FirstAgent = agent
+	}
+	thread.LastActivityAt = now
+
+	settings := s.settings(client.ID)
+	breached := !thread.FirstResponseBreached && thread.FirstResponseAt.Sub(thread.OpenedAt) > time.Duration(settings.FirstResponseTargetSeconds)*time.Second
+	if breached {
+		thread.FirstResponseBreached = true
+	}
+
+	if err := s.slaRepo.UpdateThread(thread); err != nil {
+		log.Printf("⚠️ Failed to update SLA thread %s: %v", thread.ID, err)
+		return
+	}
+	if breached {
+		s.fireBreach(EventSLAFirstResponseBreached, thread, settings.FirstResponseTargetSeconds, now)
+	}
+}
+
+// Resolve closes the customer's open thread on behalf of an agent
+func (s *SLAService) Resolve(clientID uuid.UUID, customerPhone, agent string) (*models.SLAThread, error) {
+	thread, err := s.slaRepo.GetOpenThread(clientID, customerPhone)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoOpenThread
+		}
+		return nil, fmt.Errorf("failed to load thread: %w", err)
+	}
+
+	if err := s.resolve(thread, time.Now(), agent, models.SLAResolutionClosed); err != nil {
+		return nil, err
+	}
+	return thread, nil
+}
+
+func (s *SLAService) resolve(thread *models.SLAThread, at time.Time, agent, resolution string) error {
+	settings := s.settings(thread.ClientID)
+
+	thread.Status = models.SLAThreadResolved
+	thread.ResolvedAt = &at
+	thread.ResolvedBy = agent
+	thread.Resolution = resolution
+	breached := !thread.ResolutionBreached && at.Sub(thread.OpenedAt) > time.Duration(settings.ResolutionTargetSeconds)*time.Second
+	if breached {
+		thread.ResolutionBreached = true
+	}
+
+	if err := s.slaRepo.UpdateThread(thread); err != nil {
+		return fmt.Errorf("failed to resolve thread: %w", err)
+	}
+	if breached {
+		s.fireBreach(EventSLAResolutionBreached, thread, settings.ResolutionTargetSeconds, at)
+	}
+	return nil
+}
+
+// RunSLAJob resolves idle threads and flags threads that passed a target while still waiting, so breach workflows fire on time
+func (s *SLAService) RunSLAJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.checkOpenThreads(time.Now())
+		}
+	}
+}
+
+func (s *SLAService) checkOpenThreads(now time.Time) {
+	threads, err := s.slaRepo.ListOpenThreads(slaJobBatch)
+	if err != nil {
+		log.Printf("⚠️ Failed to list open SLA threads: %v", err)
+		return
+	}
+
+	settingsByClient := make(map[uuid.UUID]*models.SLASettings)
+	resolved, breached := 0, 0
+	for i := range threads {
+		thread := &threads[i]
+		settings, ok := settingsByClient[thread.ClientID]
+		if !ok {
+			settings = s.settings(thread.ClientID)
+			settingsByClient[thread.ClientID] = settings
+		}
+
+		// Idle threads are resolved as of their last activity, not when the job noticed
+		if now.Sub(thread.LastActivityAt) > time.Duration(settings.IdleResolveMinutes)*time.Minute {
+			if err := s.resolve(thread, thread.LastActivityAt, "", models.SLAResolutionIdle); err != nil {
+				log.Printf("⚠️ Failed to resolve idle SLA thread %s: %v", thread.ID, err)
+			} else {
+				resolved++
+			}
+			continue
+		}
+
+		var events []string
+		elapsed := now.Sub(thread.OpenedAt)
+		if thread.FirstResponseAt == nil && !thread.FirstResponseBreached && elapsed > time.Duration(settings.FirstResponseTargetSeconds)*time.Second {
+			thread.FirstResponseBreached = true
+			events = append(events, EventSLAFirstResponseBreached)
+		}
+		if !thread.ResolutionBreached && elapsed > time.Duration(settings.ResolutionTargetSeconds)*time.Second {
+			thread.ResolutionBreached = true
+			events = append(events, EventSLAResolutionBreached)
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		if err := s.slaRepo.UpdateThread(thread); err != nil {
+			log.Printf("⚠️ Failed to flag SLA breach on thread %s: %v", thread.ID, err)
+			continue
+		}
+		for _, event := range events {
+			target := settings.FirstResponseTargetSeconds
+			if event == EventSLAResolutionBreached {
+				target = settings.ResolutionTargetSeconds
+			}
+			s.fireBreach(event, thread, target, now)
+			breached++
+		}
+	}
+
+	if resolved > 0 || breached > 0 {
+		log.Printf("⏱️  SLA job: %d idle thread(s) resolved, %d breach(es) flagged", resolved, breached)
+	}
+}
+
+// fireBreach triggers the client's workflows listening for an SLA breach
+func (s *SLAService) fireBreach(event string, thread *models.SLAThread, targetSeconds int, at time.Time) {
+	log.Printf("🚨 %s: client %s, customer %s, thread %s", event, thread.ClientID, thread.CustomerPhone, thread.ID)
+	if s.workflowService == nil {
+		return
+	}
+
+	eventData := map[string]interface{}{
+		"client_id":       thread.ClientID.String(),
+		"customer_phone":  thread.CustomerPhone,
+		"thread_id":       thread.ID.String(),
+		"opened_at":       thread.OpenedAt,
+		"target_seconds":  targetSeconds,
+		"elapsed_seconds": int(at.Sub(thread.OpenedAt).Seconds()),
+		"first_agent":     thread.FirstAgent,
+	}
+	go func() {
+		if err := s.workflowService.HandleEvent(context.Background(), event, eventData); err != nil {
+			log.Printf("⚠️ Failed to trigger workflows for %s: %v", event, err)
+		}
+	}()
+}
+
+// settings returns the client's SLA targets, falling back to defaults
+func (s *SLAService) settings(clientID uuid.UUID) *models.SLASettings {
+	settings, err := s.slaRepo.GetSettings(clientID)
+	if err != nil {
+		settings = &models.SLASettings{ClientID: clientID}
+	}
+	if settings.FirstResponseTargetSeconds <= 0 {
+		settings.FirstResponseTargetSeconds = int(defaultFirstResponseTarget.Seconds())
+	}
+	if settings.ResolutionTargetSeconds <= 0 {
+		settings.ResolutionTargetSeconds = int(defaultResolutionTarget.Seconds())
+	}
+	if settings.IdleResolveMinutes <= 0 {
+		settings.IdleResolveMinutes = int(defaultIdleResolve.Minutes())
+	}
+	return settings
+}
+
+// GetSettings returns the SLA targets configured for a client
+func (s *SLAService) GetSettings(clientID uuid.UUID) *models.SLASettings {
+	return s.settings(clientID)
+}
+
+// UpdateSettings validates and saves the SLA targets for a client
+func (s *SLAService) UpdateSettings(clientID uuid.UUID, req *models.UpdateSLASettingsRequest) (*models.SLASettings, error) {
+	if req.FirstResponseTargetSeconds <= 0 || req.ResolutionTargetSeconds <= 0 || req.IdleResolveMinutes <= 0 {
+		return nil, fmt.Errorf("first_response_target_seconds, resolution_target_seconds and idle_resolve_minutes must be positive")
+	}
+	if req.ResolutionTargetSeconds < req.FirstResponseTargetSeconds {
+		return nil, fmt.Errorf("resolution_target_seconds cannot be shorter than first_response_target_seconds")
+	}
+
+	settings := &models.SLASettings{
+		ClientID:                   clientID,
+		FirstResponseTargetSeconds: req.FirstResponseTargetSeconds,
+		ResolutionTargetSeconds:    req.ResolutionTargetSeconds,
+		IdleResolveMinutes:         req.IdleResolveMinutes,
+	}
+	if err := s.slaRepo.UpsertSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to save SLA settings: %w", err)
+	}
+	return s.settings(clientID), nil
+}
+
+// GetReport computes first-response and resolution percentiles for threads opened in a period, overall and per agent
+func (s *SLAService) GetReport(clientID uuid.UUID, period string) (*models.SLAReport, error) {
+	if period == "" {
+		period = "last_30_days"
+	}
+	dateRange := analytics.GetDateRange(period)
+	settings := s.settings(clientID)
+
+	firstResponse, err := s.slaRepo.FirstResponseStats(clientID, dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute first response times: %w", err)
+	}
+	resolution, err := s.slaRepo.ResolutionStats(clientID, dateRange.Start, dateRange.End)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute resolution times: %w", err)
+	}
+	responseByAgent, err := s.slaRepo.FirstResponseStatsByAgent(clientID, dateRange.Start, dateRange.End, settings.FirstResponseTargetSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute first response times per agent: %w", err)
+	}
+	resolutionByAgent, err := s.slaRepo.ResolutionStatsByAgent(clientID, dateRange.Start, dateRange.End, settings.ResolutionTargetSeconds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute resolution times per agent: %w", err)
+	}
+	open, err := s.slaRepo.CountOpenThreads(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count open threads: %w", err)
+	}
+
+	// Agents appear in the order of their first-response volume, then agents that only resolved
+	agents := []models.SLAAgentMetric{}
+	index := make(map[string]int)
+	for _, stats := range responseByAgent {
+		index[stats.Agent] = len(agents)
+		agents = append(agents, models.SLAAgentMetric{
+			Agent:         stats.Agent,
+			FirstResponse: slaMetric(&stats, settings.FirstResponseTargetSeconds),
+			Resolution:    models.SLAMetric{TargetSeconds: settings.ResolutionTargetSeconds},
+		})
+	}
+	for _, stats := range resolutionByAgent {
+		i, ok := index[stats.Agent]
+		if !ok {
+			i = len(agents)
+			agents = append(agents, models.SLAAgentMetric{
+				Agent:         stats.Agent,
+				FirstResponse: models.SLAMetric{TargetSeconds: settings.FirstResponseTargetSeconds},
+			})
+		}
+		agents[i].Resolution = slaMetric(&stats, settings.ResolutionTargetSeconds)
+	}
+
+	return &models.SLAReport{
+		Period:        period,
+		Start:         dateRange.Start,
+		End:           dateRange.End,
+		OpenThreads:   open,
+		FirstResponse: slaMetric(firstResponse, settings.FirstResponseTargetSeconds),
+		Resolution:    slaMetric(resolution, settings.ResolutionTargetSeconds),
+		Agents:        agents,
+	}, nil
+}
+
+// slaMetric converts aggregated durations into a report metric with its breach rate
+func slaMetric(stats *models.SLADurationStats, targetSeconds int) models.SLAMetric {
+	metric := models.SLAMetric{
+		Count:         stats.Count,
+		P50:           stats.P50,
+		P90:           stats.P90,
+		P95:           stats.P95,
+		Avg:           stats.Avg,
+		Breached:      stats.Breached,
+		TargetSeconds: targetSeconds,
+	}
+	if stats.Count > 0 {
+		metric.BreachRate = float64(stats.Breached) / float64(stats.Count) * 100
+	}
+	return metric
+}
@@ -0,0 +1,592 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// SplitPaymentReferencePrefix marks gateway order IDs that pay a portion of a group order
+const SplitPaymentReferencePrefix = "SPL-"
+
+const (
+	defaultSplitTimeout = 60 * time.Minute
+	minSplitTimeout     = 10
+	maxSplitTimeout     = 24 * 60
+	maxSplitPayers      = 10
+
+	// splitExpiryBatch is how many timed out group orders the job cancels per tick
+	splitExpiryBatch = 100
+)
+
+var (
+	ErrSplitNotAllowed         = errors.New("order cannot be split")
+	ErrSplitPaymentNotFound    = errors.New("split payment not found")
+	ErrSplitPaymentNotPending  = errors.New("split payment is not pending")
+	ErrSplitPaymentAutoConfirm = errors.New("split payment is confirmed by its payment gateway")
+)
+
+// SplitPaymentService lets a group split the bill of one order: every payer gets a payment link for their
+// portion, the order is confirmed only once every portion is paid, and a group order that is not fully paid
+// in time is cancelled with the paid portions refunded
+type SplitPaymentService struct {
+	repo         repositories.SplitPaymentRepo
+	orderRepo    repositories.OrderRepo
+	orderService *OrderService
+}
+
+// NewSplitPaymentService creates a new split payment service
+func NewSplitPaymentService(repo repositories.SplitPaymentRepo, orderRepo repositories.OrderRepo, orderService *OrderService) *SplitPaymentService {
+	return &SplitPaymentService{
+		repo:         repo,
+		orderRepo:    orderRepo,
+		orderService: orderService,
+	}
+}
+
+// Split replaces the payment of a pending order with one payment link per payer and sends each payer their link
+func (s *SplitPaymentService) Split(orderID string, req *models.SplitOrderRequest) (*models.OrderSplitSummary, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+
+	switch {
+	case order.SplitPayment:
+		return nil, fmt.Errorf("%w: order is already split", ErrSplitNotAllowed)
+	case order.PaymentStatus != models.PaymentStatusPending:
+		return nil, fmt.Errorf("%w: payment status is %s", ErrSplitNotAllowed, order.PaymentStatus)
+	case isCOD(order):
+		return nil, fmt.Errorf("%w: cash on delivery orders are paid at once", ErrSplitNotAllowed)
+	case order.ReviewStatus == models.ReviewStatusNeedsReview:
+		return nil, fmt.Errorf("%w: order is waiting for review", ErrSplitNotAllowed)
+	}
+
+	timeout := defaultSplitTimeout
+	if req.TimeoutMinutes != 0 {
+		if req.TimeoutMinutes < minSplitTimeout || req.TimeoutMinutes > maxSplitTimeout {
+			return nil, fmt.Errorf("%w: timeout_minutes must be between %d and %d", ErrSplitNotAllowed, minSplitTimeout, maxSplitTimeout)
+		}
+		timeout = time.Duration(req.TimeoutMinutes) * time.Minute
+	}
+
+	amounts, err := splitAmounts(order.TotalAmount, req.Payers)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(timeout)
+	gateway := s.orderService.gatewayFor(order)
+	payments := make([]models.OrderSplitPayment, len(req.Payers))
+	instructions := make([]string, len(req.Payers))
+	for i, payer := range req.Payers {
+		portion := models.OrderSplitPayment{
+			ID:             uuid.New(),
+			ClientID:       order.ClientID,
+			OrderID:        order.ID,
+			Reference:      splitPaymentReference(order, i),
+			PayerPhone:     normalizePhone(payer.Phone),
+			PayerName:      strings.TrimSpace(payer.Name),
+			Amount:         amounts[i],
+			Status:         models.SplitPaymentPending,
+			PaymentGateway: gateway.Name(),
+		}
+
+		result, err := gateway.Process(&payment.Order{
+			ID:            portion.ID,
+			ClientID:      order.ClientID,
+			OrderNumber:   portion.Reference,
+			CustomerPhone: portion.PayerPhone,
+			CustomerName:  portion.PayerName,
+			Items: []payment.OrderItem{{
+				VariantID:   portion.ID,
+				ProductName: fmt.Sprintf("Patungan pesanan #%s", order.OrderNumber),
+				VariantName: fmt.Sprintf("Bagian %d dari %d", i+1, len(req.Payers)),
+				Quantity:    1,
+				UnitPrice:   portion.Amount,
+				Subtotal:    portion.Amount,
+			}},
+			TotalAmount: portion.Amount,
+			Currency:    "IDR",
+			Status:      payment.StatusPending,
+			CreatedAt:   now,
+		})
+		if err != nil {
+			s.cancelLinks(gateway, payments[:i])
+			return nil, fmt.Errorf("failed to create payment link for %s: %w", portion.PayerPhone, err)
+		}
+
+		portion.PaymentLink = result.PaymentLink
+		payments[i] = portion
+		instructions[i] = result.Instructions
+	}
+
+	previousLink := order.PaymentLink
+	order.SplitPayment = true
+	order.SplitExpiresAt = &expiresAt
+	order.PaymentMethod = models.PaymentMethodSplit
+	order.PaymentLink = "" // Keeps the group order out of the single-payer payment reminders
+	order.PaymentExpiresAt = &expiresAt
+	if err := s.repo.CreateSplit(order, payments); err != nil {
+		s.cancelLinks(gateway, payments)
+		return nil, fmt.Errorf("failed to save split payment: %w", err)
+	}
+
+	// The full-amount link must not be paid on top of the portions. Manual and sandbox gateways have no link
+	// and cancel the order record itself, so they are left alone.
+	if previousLink != "" && !order.IsTest {
+		if err := gateway.Cancel(order.OrderNumber); err != nil {
+			log.Printf("⚠️  Failed to cancel full payment link of split order %s: %v", order.OrderNumber, err)
+		}
+	}
+
+	log.Printf("👥 Order %s split between %d payers (Rp %s, until %s)", order.OrderNumber, len(payments), formatPrice(order.TotalAmount), expiresAt.Format(time.RFC3339))
+
+	for i := range payments {
+		s.sendPortionInstructions(order, &payments[i], len(payments), instructions[i])
+	}
+	s.sendSplitOverview(order, payments)
+
+	return s.summary(order, payments), nil
+}
+
+// Get returns a group order with the payment state of each portion
+func (s *SplitPaymentService) Get(orderID string) (*models.OrderSplitSummary, error) {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return nil, ErrOrderNotFound
+	}
+	if !order.SplitPayment {
+		return nil, ErrSplitPaymentNotFound
+	}
+
+	payments, err := s.repo.ListByOrder(order.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list split payments: %w", err)
+	}
+	return s.summary(order, payments), nil
+}
+
+// ConfirmPayment records a paid portion and confirms the order once every portion is paid; repeated
+// gateway notifications are ignored. A portion paid after its group order failed is refunded.
+func (s *SplitPaymentService) ConfirmPayment(reference, paymentMethod, transactionID string) error {
+	portion, err := s.repo.GetByReference(reference)
+	if err != nil {
+		return fmt.Errorf("split payment %s not found: %w", reference, err)
+	}
+	return s.confirm(portion, paymentMethod, transactionID)
+}
+
+// ConfirmManualPayment lets an admin confirm a portion paid outside an automated gateway (manual or sandbox orders)
+func (s *SplitPaymentService) ConfirmManualPayment(orderID string, paymentID uuid.UUID, paymentMethod, reference string) (*models.OrderSplitSummary, error) {
+	portion, err := s.repo.GetByID(paymentID)
+	if err != nil || portion.OrderID.String() != orderID {
+		return nil, ErrSplitPaymentNotFound
+	}
+	if portion.Status != models.SplitPaymentPending {
+		return nil, fmt.Errorf("%w: status is %s", ErrSplitPaymentNotPending, portion.Status)
+	}
+	if portion.PaymentGateway == s.midtransName() {
+		return nil, ErrSplitPaymentAutoConfirm
+	}
+
+	if paymentMethod == "" {
+		paymentMethod = payment.MethodManual
+	}
+	if err := s.confirm(portion, paymentMethod, reference); err != nil {
+		return nil, err
+	}
+	return s.Get(orderID)
+}
+
+// CancelPayment fails the group order of a portion whose payment was denied, cancelled or expired
+func (s *SplitPaymentService) CancelPayment(reference, reason string) error {
+	portion, err := s.repo.GetByReference(reference)
+	if err != nil {
+		return fmt.Errorf("split payment %s not found: %w", reference, err)
+	}
+	if portion.Status != models.SplitPaymentPending {
+		return nil
+	}
+
+	order, err := s.orderRepo.GetByID(portion.OrderID.String())
+	if err != nil {
+		return fmt.Errorf("order of split payment %s not found: %w", reference, err)
+	}
+
+	payer := portion.PayerName
+	if payer == "" {
+		payer = portion.PayerPhone
+	}
+	return s.cancelGroup(order, models.SplitPaymentCancelled, fmt.Sprintf("%s dari %s", reason, payer))
+}
+
+// RunSplitPaymentJob cancels group orders that were not fully paid before their timeout
+func (s *SplitPaymentService) RunSplitPaymentJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.expireUnpaidOrders(time.Now())
+		}
+	}
+}
+
+func (s *SplitPaymentService) expireUnpaidOrders(now time.Time) {
+	orders, err := s.repo.ListExpiredOrders(now, splitExpiryBatch)
+	if err != nil {
+		log.Printf("⚠️  Failed to list expired split orders: %v", err)
+		return
+	}
+
+	for i := range orders {
+		if err := s.cancelGroup(&orders[i], models.SplitPaymentExpired, "Batas waktu pembayaran patungan habis"); err != nil {
+			log.Printf("⚠️  Failed to cancel expired split order %s: %v", orders[i].OrderNumber, err)
+		}
+	}
+}
+
+func (s *SplitPaymentService) confirm(portion *models.OrderSplitPayment, paymentMethod, transactionID string) error {
+	now := time.Now()
+	marked, complete, err := s.repo.MarkPaid(portion.OrderID, portion.ID, paymentMethod, transactionID, now)
+	if err != nil {
+		return fmt.Errorf("failed to mark split payment paid: %w", err)
+	}
+	if !marked {
+		log.Printf("ℹ️ Payment for split portion %s ignored, status is %s", portion.Reference, portion.Status)
+		return nil
+	}
+	portion.Status = models.SplitPaymentPaid
+	portion.PaymentMethod = paymentMethod
+	portion.TransactionID = transactionID
+	portion.PaidAt = &now
+
+	order, err := s.orderRepo.GetByID(portion.OrderID.String())
+	if err != nil {
+		return fmt.Errorf("order of split payment %s not found: %w", portion.Reference, err)
+	}
+
+	// The group order already failed (timeout or another payer's payment was denied)
+	if order.PaymentStatus != models.PaymentStatusPending {
+		log.Printf("↩️  Split portion %s paid after order %s was %s, refunding", portion.Reference, order.OrderNumber, order.PaymentStatus)
+		s.refundPortion(order, portion, "Pesanan patungan sudah dibatalkan")
+		return nil
+	}
+
+	payments, err := s.repo.ListByOrder(order.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list split payments: %w", err)
+	}
+	summary := s.summary(order, payments)
+
+	log.Printf("✅ Split portion %s of order %s paid (%d/%d)", portion.Reference, order.OrderNumber, summary.PaidCount, len(payments))
+
+	if !complete {
+		s.sendPortionReceived(order, portion, summary)
+		return nil
+	}
+
+	references := make([]string, len(payments))
+	for i := range payments {
+		references[i] = payments[i].Reference
+	}
+	if err := s.orderService.ConfirmPayment(order.ID.String(), models.PaymentMethodSplit, strings.Join(references, ",")); err != nil {
+		return fmt.Errorf("failed to confirm split order %s: %w", order.OrderNumber, err)
+	}
+
+	log.Printf("🎉 Split order %s fully paid by %d payers", order.OrderNumber, len(payments))
+	s.sendGroupPaid(order, payments)
+	return nil
+}
+
+// cancelGroup cancels the unpaid portions with the given status, refunds the paid ones and cancels the order.
+// A group order that is not pending anymore, or whose portions are all paid, is left alone.
+func (s *SplitPaymentService) cancelGroup(order *models.Order, unpaidStatus, reason string) error {
+	payments, cancelled, err := s.repo.CancelPending(order.ID, unpaidStatus)
+	if err != nil {
+		return fmt.Errorf("failed to cancel split payments: %w", err)
+	}
+	if !cancelled {
+		return nil
+	}
+
+	gateway := s.orderService.gatewayFor(order)
+	for i := range payments {
+		portion := &payments[i]
+		switch portion.Status {
+		case unpaidStatus:
+			if portion.PaymentLink != "" && !order.IsTest {
+				if err := gateway.Cancel(portion.Reference); err != nil {
+					log.Printf("⚠️  Failed to cancel payment link of split portion %s: %v", portion.Reference, err)
+				}
+			}
+			s.sendGroupCancelled(order, portion, reason, false)
+
+		case models.SplitPaymentPaid:
+			s.refundPortion(order, portion, reason)
+		}
+	}
+
+	log.Printf("❌ Split order %s cancelled: %s", order.OrderNumber, reason)
+
+	// Tells the ordering customer and the tenant admin, and returns the reserved stock
+	return s.orderService.CancelOrder(order.ID.String(), reason)
+}
+
+// refundPortion returns a paid portion of a failed group order to its payer. The portion is claimed first,
+// so the cancellation and a payment confirmed meanwhile don't both refund it.
+func (s *SplitPaymentService) refundPortion(order *models.Order, portion *models.OrderSplitPayment, reason string) {
+	claimed, err := s.repo.Transition(portion.ID, models.SplitPaymentPaid, models.SplitPaymentRefunded)
+	if err != nil {
+		log.Printf("❌ Failed to claim refund of split portion %s of order %s: %v", portion.Reference, order.OrderNumber, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	result, err := s.orderService.gatewayFor(order).Refund(portion.Reference, portion.Amount, reason)
+	if err != nil {
+		// Back to paid so an admin sees the portion still has to be returned
+		log.Printf("❌ Failed to refund split portion %s of order %s: %v", portion.Reference, order.OrderNumber, err)
+		if _, err := s.repo.Transition(portion.ID, models.SplitPaymentRefunded, models.SplitPaymentPaid); err != nil {
+			log.Printf("⚠️  Failed to reset split portion %s to paid: %v", portion.Reference, err)
+		}
+		return
+	}
+
+	now := time.Now()
+	portion.Status = models.SplitPaymentRefunded
+	portion.RefundedAmount = portion.Amount
+	portion.RefundReference = result.Reference
+	portion.RefundedAt = &now
+	if err := s.repo.Update(portion); err != nil {
+		log.Printf("❌ Refund %s of split portion %s succeeded at the gateway but was not saved: %v", result.Reference, portion.Reference, err)
+	}
+
+	s.sendGroupCancelled(order, portion, reason, true)
+}
+
+func (s *SplitPaymentService) cancelLinks(gateway payment.Gateway, payments []models.OrderSplitPayment) {
+	for i := range payments {
+		if payments[i].PaymentLink == "" {
+			continue
+		}
+		if err := gateway.Cancel(payments[i].Reference); err != nil {
+			log.Printf("⚠️  Failed to cancel payment link of split portion %s: %v", payments[i].Reference, err)
+		}
+	}
+}
+
+// midtransName is the name Midtrans portions are recorded with, which confirm through the webhook only
+func (s *SplitPaymentService) midtransName() string {
+	if gateway, ok := s.orderService.paymentGateways.Get(payment.GatewayMidtrans); ok {
+		return gateway.Name()
+	}
+	return ""
+}
+
+func (s *SplitPaymentService) summary(order *models.Order, payments []models.OrderSplitPayment) *models.OrderSplitSummary {
+	summary := &models.OrderSplitSummary{Order: order, Payments: payments}
+	for _, p := range payments {
+		if p.Status == models.SplitPaymentPaid {
+			summary.PaidCount++
+			summary.PaidAmount += p.Amount
+		}
+	}
+	summary.PaidAmount = roundAmount(summary.PaidAmount)
+	summary.RemainingAmount = roundAmount(order.TotalAmount - summary.PaidAmount)
+	if summary.RemainingAmount < 0 || order.PaymentStatus != models.PaymentStatusPending {
+		summary.RemainingAmount = 0
+	}
+	return summary
+}
+
+// sendPortionInstructions sends a payer their portion and its payment link
+func (s *SplitPaymentService) sendPortionInstructions(order *models.Order, portion *models.OrderSplitPayment, payers int, instructions string) {
+	message := fmt.Sprintf(
+		"👥 *Pesanan Patungan*\n\n"+
+			"Anda diajak patungan untuk pesanan *#%s* (total Rp %s, %d orang).\n\n"+
+			"Bagian Anda: *Rp %s*\n"+
+			"Batas bayar: *%s*\n\n"+
+			"%s\n\n"+
+			"Pesanan diproses setelah semua bagian dibayar. Jika belum lengkap sampai batas waktu, pesanan dibatalkan dan dana dikembalikan.",
+		order.OrderNumber,
+		formatPrice(order.TotalAmount),
+		payers,
+		formatPrice(portion.Amount),
+		order.SplitExpiresAt.Format("02 Jan 15:04"),
+		instructions,
+	)
+	s.orderService.messenger(order.ClientID).SendMessage(portion.PayerPhone, message)
+}
+
+// sendSplitOverview tells the ordering customer who pays what
+func (s *SplitPaymentService) sendSplitOverview(order *models.Order, payments []models.OrderSplitPayment) {
+	message := fmt.Sprintf(
+		"👥 *Pembayaran Dibagi*\n\n"+
+			"Pesanan *#%s* (Rp %s) dibayar patungan:\n%s\n\n"+
+			"Setiap orang sudah menerima link pembayarannya. Kami kabari setiap ada pembayaran masuk.",
+		order.OrderNumber,
+		formatPrice(order.TotalAmount),
+		splitPayerLines(payments),
+	)
+	message += s.orderService.trackingLine(order)
+	s.orderService.messenger(order.ClientID).SendMessage(order.CustomerPhone, message)
+}
+
+// sendPortionReceived thanks the payer and tells the ordering customer how much is still open
+func (s *SplitPaymentService) sendPortionReceived(order *models.Order, portion *models.OrderSplitPayment, summary *models.OrderSplitSummary) {
+	messenger := s.orderService.messenger(order.ClientID)
+	remaining := len(summary.Payments) - summary.PaidCount
+
+	messenger.SendMessage(portion.PayerPhone, fmt.Sprintf(
+		"✅ *Bagian Anda Diterima*\n\n"+
+			"Pembayaran Rp %s untuk pesanan *#%s* sudah kami terima.\n"+
+			"Menunggu %d orang lagi (sisa Rp %s). Pesanan diproses setelah semua bagian lunas. 🙏",
+		formatPrice(portion.Amount),
+		order.OrderNumber,
+		remaining,
+		formatPrice(summary.RemainingAmount),
+	))
+
+	if normalizePhone(order.CustomerPhone) == portion.PayerPhone {
+		return
+	}
+	messenger.SendMessage(order.CustomerPhone, fmt.Sprintf(
+		"💰 *Update Patungan #%s*\n\n"+
+			"%s\n\n"+
+			"Terkumpul Rp %s dari Rp %s.",
+		order.OrderNumber,
+		splitPayerLines(summary.Payments),
+		formatPrice(summary.PaidAmount),
+		formatPrice(order.TotalAmount),
+	))
+}
+
+// sendGroupPaid tells every payer but the ordering customer (who gets the payment confirmation) that the order is on its way
+func (s *SplitPaymentService) sendGroupPaid(order *models.Order, payments []models.OrderSplitPayment) {
+	messenger := s.orderService.messenger(order.ClientID)
+	customer := normalizePhone(order.CustomerPhone)
+	for _, p := range payments {
+		if p.PayerPhone == customer {
+			continue
+		}
+		messenger.SendMessage(p.PayerPhone, fmt.Sprintf(
+			"🎉 *Patungan Lunas!*\n\n"+
+				"Semua bagian pesanan *#%s* sudah dibayar. Pesanan sedang diproses. Terima kasih! 🙏",
+			order.OrderNumber,
+		))
+	}
+}
+
+// sendGroupCancelled tells a payer the group order failed and whether their money is returned
+func (s *SplitPaymentService) sendGroupCancelled(order *models.Order, portion *models.OrderSplitPayment, reason string, refunded bool) {
+	if normalizePhone(order.CustomerPhone) == portion.PayerPhone && !refunded {
+		return // The ordering customer gets the cancellation notice of the order itself
+	}
+
+	message := fmt.Sprintf(
+		"😔 *Patungan Dibatalkan*\n\n"+
+			"Pesanan *#%s* dibatalkan karena belum semua bagian dibayar.\n"+
+			"*Alasan:* %s",
+		order.OrderNumber,
+		reason,
+	)
+	if refunded {
+		message += fmt.Sprintf("\n\nBagian Anda sebesar *Rp %s* akan dikembalikan ke metode pembayaran Anda.", formatPrice(portion.Amount))
+		if portion.PaymentGateway != s.midtransName() {
+			message += " Admin kami akan segera mentransfer dana tersebut."
+		}
+	} else {
+		message += "\n\nLink pembayaran Anda tidak berlaku lagi."
+	}
+	s.orderService.messenger(order.ClientID).SendMessage(portion.PayerPhone, message)
+}
+
+// splitAmounts gives each payer their amount; payers without one share what is left equally,
+// rounded to the rupiah with the rounding difference on the first of them
+func splitAmounts(total float64, payers []models.SplitPayer) ([]float64, error) {
+	if len(payers) < 2 || len(payers) > maxSplitPayers {
+		return nil, fmt.Errorf("%w: between 2 and %d payers are needed", ErrSplitNotAllowed, maxSplitPayers)
+	}
+
+	seen := make(map[string]bool, len(payers))
+	amounts := make([]float64, len(payers))
+	var fixed float64
+	var open []int
+	for i, payer := range payers {
+		phone := normalizePhone(payer.Phone)
+		if phone == "" {
+			return nil, fmt.Errorf("%w: payer %d has no phone", ErrSplitNotAllowed, i+1)
+		}
+		if seen[phone] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrSplitNotAllowed, phone)
+		}
+		seen[phone] = true
+
+		switch {
+		case payer.Amount < 0:
+			return nil, fmt.Errorf("%w: amount of %s must be positive", ErrSplitNotAllowed, phone)
+		case payer.Amount > 0:
+			amounts[i] = roundAmount(payer.Amount)
+			fixed += amounts[i]
+		default:
+			open = append(open, i)
+		}
+	}
+
+	left := roundAmount(total - fixed)
+	if len(open) == 0 {
+		if math.Abs(left) >= 0.01 {
+			return nil, fmt.Errorf("%w: amounts add up to Rp %s instead of Rp %s", ErrSplitNotAllowed, formatPrice(fixed), formatPrice(total))
+		}
+		return amounts, nil
+	}
+	if left < float64(len(open)) {
+		return nil, fmt.Errorf("%w: amounts leave nothing for the payers without one", ErrSplitNotAllowed)
+	}
+
+	share := math.Floor(left / float64(len(open)))
+	for _, i := range open {
+		amounts[i] = share
+	}
+	amounts[open[0]] = roundAmount(left - share*float64(len(open)-1))
+	return amounts, nil
+}
+
+// splitPayerLines lists the payers with their portion and whether it is paid
+func splitPayerLines(payments []models.OrderSplitPayment) string {
+	lines := make([]string, len(payments))
+	for i, p := range payments {
+		name := p.PayerName
+		if name == "" {
+			name = p.PayerPhone
+		}
+		mark := "⏳"
+		if p.Status == models.SplitPaymentPaid {
+			mark = "✅"
+		}
+		lines[i] = fmt.Sprintf("%s %s - Rp %s", mark, name, formatPrice(p.Amount))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// splitPaymentReference is the gateway order ID of a portion, unique per order and payer
+func splitPaymentReference(order *models.Order, index int) string {
+	return fmt.Sprintf("%s%s-%d-%s", SplitPaymentReferencePrefix, order.OrderNumber, index+1, strings.ToUpper(uuid.NewString()[:4]))
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// earthRadiusKm is the mean Earth radius used for haversine distances
+const earthRadiusKm = 6371.0
+
+// storeLocatorKeywords are phrases that mean the customer is asking where the store is
+var storeLocatorKeywords = []string{
+	"toko di mana", "toko dimana", "tokonya di mana", "tokonya dimana",
+	"lokasi toko", "alamat toko", "alamatnya", "lokasinya", "cabang terdekat",
+	"toko terdekat", "where is the store", "store location",
+}
+
+type StoreService struct {
+	storeRepo repositories.StoreRepo
+}
+
+func NewStoreService(storeRepo repositories.StoreRepo) *StoreService {
+	return &StoreService{storeRepo: storeRepo}
+}
+
+// CreateStore creates a new store location
+func (s *StoreService) CreateStore(clientID uuid.UUID, req *models.CreateStoreRequest) (*models.Store, error) {
+	if req.Name == "" {
+		return nil, errors.New("store name is required")
+	}
+	if req.Address == "" {
+		return nil, errors.New("store address is required")
+	}
+	if err := validateCoordinates(req.Latitude, req.Longitude); err != nil {
+		return nil, err
+	}
+
+	store := &models.Store{
+		ClientID:     clientID,
+		Name:         req.Name,
+		Address:      req.Address,
+		Phone:        req.Phone,
+		OpeningHours: req.OpeningHours,
+		AdminName:    req.AdminName,
+		AdminPhone:   req.AdminPhone,
+		Latitude:     req.Latitude,
+		Longitude:    req.Longitude,
+		IsActive:     true,
+	}
+	if req.IsActive != nil {
+		store.IsActive = *req.IsActive
+	}
+
+	if err := s.storeRepo.Create(store); err != nil {
+		return nil, fmt.Errorf("failed to create store: %w", err)
+	}
+
+	return store, nil
+}
+
+// GetStore retrieves a store by ID, scoped to the client
+func (s *StoreService) GetStore(storeID string, clientID uuid.UUID) (*models.Store, error) {
+	store, err := s.storeRepo.GetByID(storeID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("store not found")
+		}
+		return nil, err
+	}
+
+	if store.ClientID != clientID {
+		return nil, errors.New("store not found")
+	}
+
+	return store, nil
+}
+
+// ListStores lists the stores of a client
+func (s *StoreService) ListStores(clientID uuid.UUID, activeOnly bool) ([]models.Store, error) {
+	return s.storeRepo.ListByClientID(clientID, activeOnly)
+}
+
+// UpdateStore updates an existing store
+func (s *StoreService) UpdateStore(storeID string, clientID uuid.UUID, req *models.UpdateStoreRequest) (*models.Store, error) {
+	store, err := s.GetStore(storeID, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, errors.New("store name cannot be empty")
+		}
+		store.Name = *req.Name
+	}
+	if req.Address != nil {
+		if *req.Address == "" {
+			return nil, errors.New("store address cannot be empty")
+		}
+		store.Address = *req.Address
+	}
+	if req.Phone != nil {
+		store.Phone = *req.Phone
+	}
+	if req.OpeningHours != nil {
+		store.OpeningHours = *req.OpeningHours
+	}
+	if req.AdminName != nil {
+		store.AdminName = *req.AdminName
+	}
+	if req.AdminPhone != nil {
+		store.AdminPhone = *req.AdminPhone
+	}
+	if req.Latitude != nil {
+		store.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		store.Longitude = *req.Longitude
+	}
+	if err := validateCoordinates(store.Latitude, store.Longitude); err != nil {
+		return nil, err
+	}
+	if req.IsActive != nil {
+		store.IsActive = *req.IsActive
+	}
+
+	if err := s.storeRepo.Update(store); err != nil {
+		return nil, fmt.Errorf("failed to update store: %w", err)
+	}
+
+	return store, nil
+}
+
+// DeleteStore soft deletes a store
+func (s *StoreService) DeleteStore(storeID string, clientID uuid.UUID) error {
+	if _, err := s.GetStore(storeID, clientID); err != nil {
+		return err
+	}
+
+	if err := s.storeRepo.Delete(storeID); err != nil {
+		return fmt.Errorf("failed to delete store: %w", err)
+	}
+
+	return nil
+}
+
+// FindNearest returns the client's active stores ordered by distance from the given point
+func (s *StoreService) FindNearest(clientID uuid.UUID, latitude, longitude float64, limit int) ([]models.Store, error) {
+	if err := validateCoordinates(latitude, longitude); err != nil {
+		return nil, err
+	}
+
+	stores, err := s.storeRepo.ListByClientID(clientID, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list stores: %w", err)
+	}
+
+	for i := range stores {
+		distance := haversineKm(latitude, longitude, stores[i].Latitude, stores[i].Longitude)
+		stores[i].DistanceKm = &distance
+	}
+
+	sort.Slice(stores, func(i, j int) bool {
+		return *stores[i].DistanceKm < *stores[j].DistanceKm
+	})
+
+	if limit > 0 && len(stores) > limit {
+		stores = stores[:limit]
+	}
+
+	return stores, nil
+}
+
+// IsStoreLocatorQuery checks whether a text message asks for the store location
+func IsStoreLocatorQuery(message string) bool {
+	lower := strings.ToLower(message)
+	for _, keyword := range storeLocatorKeywords {
+		if strings.Contains(lower, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatNearestStoreReply builds the WhatsApp reply for the nearest store
+func FormatNearestStoreReply(store *models.Store) string {
+	var sb strings.Builder
+	sb.WriteString("📍 *Toko terdekat dari lokasi Anda:*\n\n")
+	writeStoreDetails(&sb, store)
+	sb.WriteString("\nLokasi toko kami kirimkan di bawah ini 👇")
+	return sb.String()
+}
+
+// FormatStoreList builds the WhatsApp reply listing all stores
+func FormatStoreList(stores []models.Store) string {
+	var sb strings.Builder
+	if len(stores) == 1 {
+		sb.WriteString("📍 *Lokasi toko kami:*\n\n")
+	} else {
+		sb.WriteString("📍 *Daftar toko kami:*\n\n")
+	}
+
+	for i := range stores {
+		if len(stores) > 1 {
+			sb.WriteString(fmt.Sprintf("%d. ", i+1))
+		}
+		writeStoreDetails(&sb, &stores[i])
+		sb.WriteString("\n")
+	}
+
+	if len(stores) > 1 {
+		sb.WriteString("💡 Kirim *lokasi* Anda (Share Location) untuk mengetahui toko terdekat.")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// writeStoreDetails writes the name, address and optional details of a store
+func writeStoreDetails(sb *strings.Builder, store *models.Store) {
+	sb.WriteString(fmt.Sprintf("*%s*\n", store.Name))
+	sb.WriteString(fmt.Sprintf("🏠 %s\n", store.Address))
+	if store.DistanceKm != nil {
+		sb.WriteString(fmt.Sprintf("📏 ± %.1f km\n", *store.DistanceKm))
+	}
+	if store.OpeningHours != "" {
+		sb.WriteString(fmt.Sprintf("🕐 %s\n", store.OpeningHours))
+	}
+	if store.Phone != "" {
+		sb.WriteString(fmt.Sprintf("📞 %s\n", store.Phone))
+	}
+}
+
+// validateCoordinates checks latitude and longitude ranges
+func validateCoordinates(latitude, longitude float64) error {
+	if latitude < -90 || latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if longitude < -180 || longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	return nil
+}
+
+// haversineKm returns the great-circle distance between two points in kilometers
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return earthRadiusKm * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,621 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/notification"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// PlanChangeReferencePrefix marks gateway order IDs that pay for a plan change instead of a customer order
+const PlanChangeReferencePrefix = "SUB-"
+
+// planChangePaymentTTL is how long an upgrade may wait for its payment before it is cancelled
+const planChangePaymentTTL = 24 * time.Hour
+
+// usageThresholds are the shares of a plan limit (percent) that trigger an upgrade prompt
+var usageThresholds = []int{80, 100}
+
+var (
+	ErrUnknownPlan        = errors.New("unknown plan")
+	ErrSamePlan           = errors.New("client is already on this plan")
+	ErrCustomPlan         = errors.New("current plan is not in the self-service catalog, contact support to change it")
+	ErrBillingUnavailable = errors.New("billing payment gateway is not configured")
+	ErrInvalidPlan        = errors.New("invalid plan")
+)
+
+// planCodePattern is the form of plan codes: lowercase letters, digits, - and _
+var planCodePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// UsageNotifier sends upgrade prompts to the tenant admin
+type UsageNotifier interface {
+	NotifyUsageThreshold(tenantAdmin *notification.AdminContact, metric string, used, limit, percent int, currentPlan, upgradePlan string, upgradePrice float64) error
+}
+
+// MetricUsage is the usage of one plan-limited metric; Limit 0 means unlimited
+type MetricUsage struct {
+	Metric  string `json:"metric"`
+	Used    int    `json:"used"`
+	Limit   int    `json:"limit"`
+	Percent int    `json:"percent"`
+}
+
+// SubscriptionUsage is a client's usage against its plan in the current billing period
+type SubscriptionUsage struct {
+	Plan        string              `json:"plan"`
+	PeriodStart time.Time           `json:"period_start"`
+	PeriodEnd   time.Time           `json:"period_end"`
+	Metrics     []MetricUsage       `json:"metrics"`
+	OpenChanges []models.PlanChange `json:"open_changes"`
+}
+
+// PlanChangeResult is the outcome of a plan change request
+type PlanChangeResult struct {
+	Change  *models.PlanChange     `json:"change,omitempty"`
+	Payment *payment.ProcessResult `json:"payment,omitempty"`
+	Message string                 `json:"message"`
+}
+
+// SubscriptionService handles the plan catalog, usage-based upgrade prompts and self-service plan changes.
+// Billing periods are calendar months in the client's timezone
+type SubscriptionService struct {
+	subscriptionRepo repositories.SubscriptionRepo
+	clientRepo       repositories.ClientRepo
+	usageRepo        repositories.UsageRepo
+	billingGateway   payment.Gateway // nil when no automated gateway is configured
+	notifier         UsageNotifier   // nil when notifications are not configured
+}
+
+// NewSubscriptionService creates a new subscription service
+func NewSubscriptionService(subscriptionRepo repositories.SubscriptionRepo, clientRepo repositories.ClientRepo, usageRepo repositories.UsageRepo, billingGateway payment.Gateway, notifier UsageNotifier) *SubscriptionService {
+	return &SubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		clientRepo:       clientRepo,
+		usageRepo:        usageRepo,
+		billingGateway:   billingGateway,
+		notifier:         notifier,
+	}
+}
+
+// Plans returns the self-service plan catalog
+func (s *SubscriptionService) Plans() []models.Plan {
+	return models.Plans()
+}
+
+// LoadPlans merges the operator's plan definitions into the catalog
+func (s *SubscriptionService) LoadPlans() error {
+	definitions, err := s.subscriptionRepo.ListPlanDefinitions()
+	if err != nil {
+		return fmt.Errorf("failed to list plan definitions: %w", err)
+	}
+	catalog, err := mergePlans(models.PlanCatalog, definitions)
+	if err != nil {
+		return err
+	}
+	models.SetPlans(catalog)
+	if len(definitions) > 0 {
+		log.Printf("📦 Plan catalog loaded: %d plans (%d defined by the operator)", len(catalog), len(definitions))
+	}
+	return nil
+}
+
+// PlanDefinitions returns the plans defined by the operator, including withdrawn ones
+func (s *SubscriptionService) PlanDefinitions() ([]models.PlanDefinition, error) {
+	definitions, err := s.subscriptionRepo.ListPlanDefinitions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan definitions: %w", err)
+	}
+	return definitions, nil
+}
+
+// SavePlan defines a plan or updates the operator's definition of it, then reloads the catalog.
+// Clients stay on their plan code; a changed price applies from their next statement
+func (s *SubscriptionService) SavePlan(code string, req *models.PlanRequest) (*models.PlanDefinition, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if err := validatePlan(code, req); err != nil {
+		return nil, err
+	}
+
+	definition := &models.PlanDefinition{
+		Code:         code,
+		Name:         strings.TrimSpace(req.Name),
+		MonthlyPrice: req.MonthlyPrice,
+		Features:     req.Features,
+		Active:       req.Active == nil || *req.Active,
+	}
+	limits, err := json.Marshal(req.Limits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode limits: %w", err)
+	}
+	overage, err := json.Marshal(req.Overage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode overage: %w", err)
+	}
+	definition.Limits = datatypes.JSON(limits)
+	definition.Overage = datatypes.JSON(overage)
+
+	if err := s.subscriptionRepo.UpsertPlanDefinition(definition); err != nil {
+		return nil, fmt.Errorf("failed to save plan: %w", err)
+	}
+	if err := s.LoadPlans(); err != nil {
+		return nil, err
+	}
+	log.Printf("📦 Plan %s saved (Rp %.0f/month, active %t)", definition.Code, definition.MonthlyPrice, definition.Active)
+	return definition, nil
+}
+
+// Usage returns the client's usage in the current billing period
+func (s *SubscriptionService) Usage(clientID uuid.UUID) (*SubscriptionUsage, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	usage, err := s.usage(client, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	usage.OpenChanges, err = s.subscriptionRepo.ListOpenPlanChanges(client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list plan changes: %w", err)
+	}
+	return usage, nil
+}
+
+func (s *SubscriptionService) usage(client *models.Client, now time.Time) (*SubscriptionUsage, error) {
+	start, end := billingPeriod(now, client.Timezone)
+	usage := &SubscriptionUsage{Plan: client.SubscriptionPlan, PeriodStart: start, PeriodEnd: end}
+
+	var limits models.PlanLimits
+	if plan, _, ok := models.FindPlan(client.SubscriptionPlan); ok {
+		limits = plan.Limits
+	}
+
+	counts := []struct {
+		metric string
+		count  func() (int64, error)
+	}{
+		{models.UsageMetricMessages, func() (int64, error) { return s.subscriptionRepo.CountMessagesSince(client.ID, start) }},
+		{models.UsageMetricProducts, func() (int64, error) { return s.subscriptionRepo.CountProducts(client.ID) }},
+		{models.UsageMetricKnowledgeBase, func() (int64, error) { return s.subscriptionRepo.CountKnowledgeBase(client.ID) }},
+		{models.UsageMetricKBDocuments, func() (int64, error) { return s.subscriptionRepo.CountKBDocuments(client.ID) }},
+		{models.UsageMetricTokens, func() (int64, error) {
+			totals, err := s.usageRepo.Totals(client.ID, start, end)
+			return totals.TotalTokens, err
+		}},
+	}
+	for _, c := range counts {
+		used, err := c.count()
+		if err != nil {
+			return nil, fmt.Errorf("failed to count %s: %w", c.metric, err)
+		}
+		metric := MetricUsage{Metric: c.metric, Used: int(used), Limit: limits.Limit(c.metric)}
+		if metric.Limit > 0 {
+			metric.Percent = metric.Used * 100 / metric.Limit
+		}
+		usage.Metrics = append(usage.Metrics, metric)
+	}
+	return usage, nil
+}
+
+// ChangePlan moves the client to another catalog plan. Upgrades are charged the prorated price
+// difference for the rest of the period and applied once paid; downgrades take effect next period.
+// A new request supersedes any change still open
+func (s *SubscriptionService) ChangePlan(clientID uuid.UUID, planCode string) (*PlanChangeResult, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	target, _, ok := models.FindPlan(strings.ToLower(strings.TrimSpace(planCode)))
+	if !ok {
+		return nil, ErrUnknownPlan
+	}
+	current, _, ok := models.FindPlan(client.SubscriptionPlan)
+	if !ok {
+		return nil, ErrCustomPlan
+	}
+
+	cancelled, err := s.cancelOpenChanges(client.ID, "superseded by a new plan change")
+	if err != nil {
+		return nil, err
+	}
+	if target.Code == current.Code {
+		if cancelled > 0 {
+			return &PlanChangeResult{Message: fmt.Sprintf("Pending plan change cancelled, staying on %s", current.Name)}, nil
+		}
+		return nil, ErrSamePlan
+	}
+
+	now := time.Now()
+	start, end := billingPeriod(now, client.Timezone)
+	change := &models.PlanChange{
+		ClientID:    client.ID,
+		FromPlan:    current.Code,
+		ToPlan:      target.Code,
+		Reference:   planChangeReference(now),
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	if target.MonthlyPrice <= current.MonthlyPrice {
+		change.Status = models.PlanChangeScheduled
+		change.EffectiveAt = &end
+		if err := s.subscriptionRepo.CreatePlanChange(change); err != nil {
+			return nil, fmt.Errorf("failed to save plan change: %w", err)
+		}
+		log.Printf("📅 Plan change %s scheduled for client %s: %s → %s on %s", change.Reference, client.ID, current.Code, target.Code, end.Format("2006-01-02"))
+		return &PlanChangeResult{
+			Change:  change,
+			Message: fmt.Sprintf("Plan changes to %s on %s", target.Name, end.Format("2006-01-02")),
+		}, nil
+	}
+
+	change.ProratedAmount = proratedAmount(current.MonthlyPrice, target.MonthlyPrice, start, end, now)
+	if change.ProratedAmount < 1 {
+		// Nothing left to charge this period (e.g. requested in its last seconds)
+		change.Status = models.PlanChangePendingPayment
+		if err := s.subscriptionRepo.CreatePlanChange(change); err != nil {
+			return nil, fmt.Errorf("failed to save plan change: %w", err)
+		}
+		if err := s.applyChange(change, now); err != nil {
+			return nil, err
+		}
+		return &PlanChangeResult{Change: change, Message: fmt.Sprintf("Plan changed to %s", target.Name)}, nil
+	}
+
+	if s.billingGateway == nil {
+		return nil, ErrBillingUnavailable
+	}
+	change.Status = models.PlanChangePendingPayment
+	if err := s.subscriptionRepo.CreatePlanChange(change); err != nil {
+		return nil, fmt.Errorf("failed to save plan change: %w", err)
+	}
+
+	result, err := s.billingGateway.Process(&payment.Order{
+		ID:            change.ID,
+		ClientID:      client.ID,
+		OrderNumber:   change.Reference,
+		CustomerPhone: client.WhatsAppNumber,
+		CustomerName:  client.BusinessName,
+		Items: []payment.OrderItem{{
+			VariantID:   change.ID,
+			ProductName: fmt.Sprintf("Plan %s", target.Name),
+			VariantName: fmt.Sprintf("Prorated until %s", end.Format("2006-01-02")),
+			Quantity:    1,
+			UnitPrice:   change.ProratedAmount,
+			Subtotal:    change.ProratedAmount,
+		}},
+		TotalAmount: change.ProratedAmount,
+		Currency:    "IDR",
+		Status:      payment.StatusPending,
+		CreatedAt:   now,
+	})
+	if err != nil {
+		change.Status = models.PlanChangeFailed
+		change.CancelReason = err.Error()
+		if updateErr := s.subscriptionRepo.UpdatePlanChange(change); updateErr != nil {
+			log.Printf("⚠️ Failed to mark plan change %s as failed: %v", change.Reference, updateErr)
+		}
+		return nil, fmt.Errorf("failed to create payment link: %w", err)
+	}
+
+	change.PaymentLink = result.PaymentLink
+	if err := s.subscriptionRepo.UpdatePlanChange(change); err != nil {
+		return nil, fmt.Errorf("failed to save payment link: %w", err)
+	}
+
+	log.Printf("💳 Plan change %s for client %s: %s → %s, prorated Rp %.0f", change.Reference, client.ID, current.Code, target.Code, change.ProratedAmount)
+	return &PlanChangeResult{
+		Change:  change,
+		Payment: result,
+		Message: fmt.Sprintf("Pay Rp %.0f to upgrade to %s now", change.ProratedAmount, target.Name),
+	}, nil
+}
+
+// ConfirmPayment applies a paid upgrade; repeated gateway notifications are ignored
+func (s *SubscriptionService) ConfirmPayment(reference, paymentMethod, transactionID string) error {
+	change, err := s.subscriptionRepo.GetPlanChangeByReference(reference)
+	if err != nil {
+		return fmt.Errorf("plan change %s not found: %w", reference, err)
+	}
+	if change.Status != models.PlanChangePendingPayment {
+		log.Printf("ℹ️ Payment for plan change %s ignored, status is %s", reference, change.Status)
+		return nil
+	}
+
+	change.PaymentMethod = paymentMethod
+	change.TransactionID = transactionID
+	return s.applyChange(change, time.Now())
+}
+
+// CancelPayment cancels an upgrade whose payment was denied, cancelled or expired
+func (s *SubscriptionService) CancelPayment(reference, reason string) error {
+	change, err := s.subscriptionRepo.GetPlanChangeByReference(reference)
+	if err != nil {
+		return fmt.Errorf("plan change %s not found: %w", reference, err)
+	}
+	if change.Status != models.PlanChangePendingPayment {
+		return nil
+	}
+
+	change.Status = models.PlanChangeCancelled
+	change.CancelReason = reason
+	return s.subscriptionRepo.UpdatePlanChange(change)
+}
+
+// RunSubscriptionJob applies scheduled downgrades, cancels unpaid upgrades and sends usage upgrade prompts
+func (s *SubscriptionService) RunSubscriptionJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			now := time.Now()
+			s.applyScheduledChanges(now)
+			s.expireUnpaidChanges(now)
+			s.checkUsageThresholds(now)
+		}
+	}
+}
+
+func (s *SubscriptionService) applyScheduledChanges(now time.Time) {
+	changes, err := s.subscriptionRepo.ListScheduledDue(now)
+	if err != nil {
+		log.Printf("⚠️ Failed to list scheduled plan changes: %v", err)
+		return
+	}
+	for i := range changes {
+		if err := s.applyChange(&changes[i], now); err != nil {
+			log.Printf("⚠️ Failed to apply plan change %s: %v", changes[i].Reference, err)
+		}
+	}
+}
+
+func (s *SubscriptionService) expireUnpaidChanges(now time.Time) {
+	changes, err := s.subscriptionRepo.ListPendingPaymentBefore(now.Add(-planChangePaymentTTL))
+	if err != nil {
+		log.Printf("⚠️ Failed to list unpaid plan changes: %v", err)
+		return
+	}
+	for i := range changes {
+		changes[i].Status = models.PlanChangeCancelled
+		changes[i].CancelReason = "payment not received"
+		if err := s.subscriptionRepo.UpdatePlanChange(&changes[i]); err != nil {
+			log.Printf("⚠️ Failed to cancel unpaid plan change %s: %v", changes[i].Reference, err)
+		}
+	}
+}
+
+// checkUsageThresholds prompts each client once per period and threshold; a client that jumps
+// straight past several thresholds only hears about the highest
+func (s *SubscriptionService) checkUsageThresholds(now time.Time) {
+	clients, err := s.clientRepo.GetActiveClients()
+	if err != nil {
+		log.Printf("⚠️ Failed to list clients for usage check: %v", err)
+		return
+	}
+
+	sent := 0
+	for i := range clients {
+		client := &clients[i]
+		if client.SandboxMode {
+			continue
+		}
+		if _, _, ok := models.FindPlan(client.SubscriptionPlan); !ok {
+			continue // Custom plans are managed by hand
+		}
+
+		usage, err := s.usage(client, now)
+		if err != nil {
+			log.Printf("⚠️ Failed to compute usage for client %s: %v", client.ID, err)
+			continue
+		}
+		for _, metric := range usage.Metrics {
+			threshold := crossedThreshold(metric)
+			if threshold == 0 {
+				continue
+			}
+			claimed, err := s.subscriptionRepo.ClaimUsageAlert(&models.UsageAlert{
+				ClientID:    client.ID,
+				PeriodStart: usage.PeriodStart,
+				Metric:      metric.Metric,
+				Threshold:   threshold,
+				Plan:        client.SubscriptionPlan,
+				Used:        metric.Used,
+				UsageLimit:  metric.Limit,
+			})
+			if err != nil {
+				log.Printf("⚠️ Failed to record usage alert for client %s: %v", client.ID, err)
+				continue
+			}
+			if claimed && s.notifyUsage(client, metric) {
+				sent++
+			}
+		}
+	}
+
+	if sent > 0 {
+		log.Printf("📈 Sent %d usage upgrade prompts", sent)
+	}
+}
+
+func (s *SubscriptionService) notifyUsage(client *models.Client, metric MetricUsage) bool {
+	if s.notifier == nil {
+		log.Printf("⚠️ Client %s is at %d%% of its %s limit, notifications not configured", client.ID, metric.Percent, metric.Metric)
+		return false
+	}
+
+	admin := &notification.AdminContact{
+		Phone: client.WhatsAppNumber,
+		Name:  client.BusinessName,
+	}
+	var upgradeName string
+	var upgradePrice float64
+	if upgrade := upgradeFor(client.SubscriptionPlan, metric); upgrade != nil {
+		upgradeName, upgradePrice = upgrade.Name, upgrade.MonthlyPrice
+	}
+	if err := s.notifier.NotifyUsageThreshold(admin, metric.Metric, metric.Used, metric.Limit, metric.Percent, client.SubscriptionPlan, upgradeName, upgradePrice); err != nil {
+		log.Printf("⚠️ Failed to send usage prompt to client %s: %v", client.ID, err)
+		return false
+	}
+	return true
+}
+
+func (s *SubscriptionService) applyChange(change *models.PlanChange, now time.Time) error {
+	if err := s.clientRepo.UpdateSubscriptionPlan(change.ClientID, change.ToPlan); err != nil {
+		return fmt.Errorf("failed to update client plan: %w", err)
+	}
+
+	change.Status = models.PlanChangeApplied
+	change.AppliedAt = &now
+	if err := s.subscriptionRepo.UpdatePlanChange(change); err != nil {
+		return fmt.Errorf("failed to update plan change: %w", err)
+	}
+	log.Printf("✅ Client %s moved from %s to %s (%s)", change.ClientID, change.FromPlan, change.ToPlan, change.Reference)
+	return nil
+}
+
+func (s *SubscriptionService) cancelOpenChanges(clientID uuid.UUID, reason string) (int, error) {
+	changes, err := s.subscriptionRepo.ListOpenPlanChanges(clientID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("failed to list plan changes: %w", err)
+	}
+	for i := range changes {
+		change := &changes[i]
+		if change.Status == models.PlanChangePendingPayment && change.PaymentLink != "" && s.billingGateway != nil {
+			if err := s.billingGateway.Cancel(change.Reference); err != nil {
+				log.Printf("⚠️ Failed to cancel payment for plan change %s: %v", change.Reference, err)
+			}
+		}
+		change.Status = models.PlanChangeCancelled
+		change.CancelReason = reason
+		if err := s.subscriptionRepo.UpdatePlanChange(change); err != nil {
+			return i, fmt.Errorf("failed to cancel plan change %s: %w", change.Reference, err)
+		}
+	}
+	return len(changes), nil
+}
+
+// validatePlan checks an operator plan; the downgrade plan can't be withdrawn
+func validatePlan(code string, req *models.PlanRequest) error {
+	if !planCodePattern.MatchString(code) {
+		return fmt.Errorf("%w: code must be lowercase letters, digits, - or _", ErrInvalidPlan)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidPlan)
+	}
+	if req.MonthlyPrice < 0 {
+		return fmt.Errorf("%w: monthly_price must not be negative", ErrInvalidPlan)
+	}
+	limits := req.Limits
+	if limits.MessagesPerMonth < 0 || limits.Products < 0 || limits.KnowledgeBase < 0 || limits.KBDocuments < 0 ||
+		limits.TokensPerMonth < 0 || limits.StorageMB < 0 {
+		return fmt.Errorf("%w: limits must not be negative (0 = unlimited)", ErrInvalidPlan)
+	}
+	if req.Overage.PerMessage < 0 || req.Overage.PerThousandTokens < 0 || req.Overage.PerMB < 0 {
+		return fmt.Errorf("%w: overage rates must not be negative", ErrInvalidPlan)
+	}
+	if code == models.DowngradePlan && req.Active != nil && !*req.Active {
+		return fmt.Errorf("%w: %s is the plan unpaid subscriptions are downgraded to and can't be withdrawn", ErrInvalidPlan, code)
+	}
+	return nil
+}
+
+// mergePlans applies the operator's definitions to the built-in catalog: a definition adds a plan or
+// replaces the one with its code, a withdrawn definition removes it. Plans are ordered by price
+func mergePlans(builtIn []models.Plan, definitions []models.PlanDefinition) ([]models.Plan, error) {
+	byCode := make(map[string]models.Plan, len(builtIn)+len(definitions))
+	for _, plan := range builtIn {
+		byCode[plan.Code] = plan
+	}
+	for i := range definitions {
+		if !definitions[i].Active {
+			delete(byCode, definitions[i].Code)
+			continue
+		}
+		plan, err := definitions[i].Plan()
+		if err != nil {
+			return nil, err
+		}
+		byCode[plan.Code] = plan
+	}
+
+	catalog := make([]models.Plan, 0, len(byCode))
+	for _, plan := range byCode {
+		catalog = append(catalog, plan)
+	}
+	sort.Slice(catalog, func(i, j int) bool {
+		if catalog[i].MonthlyPrice != catalog[j].MonthlyPrice {
+			return catalog[i].MonthlyPrice < catalog[j].MonthlyPrice
+		}
+		return catalog[i].Code < catalog[j].Code
+	})
+	return catalog, nil
+}
+
+// billingPeriod returns the calendar month containing now, in the client's timezone
+func billingPeriod(now time.Time, timezone string) (time.Time, time.Time) {
+	now = now.In(clientLocation(timezone))
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return start, start.AddDate(0, 1, 0)
+}
+
+// proratedAmount charges the monthly price difference for the part of the period left, rounded up to the rupiah
+func proratedAmount(fromPrice, toPrice float64, start, end, now time.Time) float64 {
+	remaining := end.Sub(now)
+	if remaining <= 0 {
+		return 0
+	}
+	return math.Ceil((toPrice - fromPrice) * remaining.Seconds() / end.Sub(start).Seconds())
+}
+
+// crossedThreshold returns the highest usage threshold the metric has reached, or 0
+func crossedThreshold(metric MetricUsage) int {
+	if metric.Limit <= 0 {
+		return 0
+	}
+	crossed := 0
+	for _, threshold := range usageThresholds {
+		if metric.Used*100 >= threshold*metric.Limit {
+			crossed = threshold
+		}
+	}
+	return crossed
+}
+
+// upgradeFor returns the smallest larger plan whose limit covers the current usage
+func upgradeFor(planCode string, metric MetricUsage) *models.Plan {
+	_, index, ok := models.FindPlan(planCode)
+	if !ok {
+		return nil
+	}
+	catalog := models.Plans()
+	for i := index + 1; i < len(catalog); i++ {
+		limit := catalog[i].Limits.Limit(metric.Metric)
+		if limit == 0 || limit > metric.Used {
+			return &catalog[i]
+		}
+	}
+	return nil
+}
+
+func planChangeReference(now time.Time) string {
+	return fmt.Sprintf("%s%s-%s", PlanChangeReferencePrefix, now.Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
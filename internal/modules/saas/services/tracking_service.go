@@ -0,0 +1,113 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// trackingLinkTTL is how long a public tracking link stays valid after the order is created
+const trackingLinkTTL = 30 * 24 * time.Hour
+
+var (
+	ErrTrackingNotFound = errors.New("tracking link not found")
+	ErrTrackingExpired  = errors.New("tracking link has expired")
+)
+
+// TrackingService serves the public order tracking page
+type TrackingService struct {
+	orderRepo    repositories.OrderRepo
+	shipmentRepo repositories.ShipmentRepo
+	clientRepo   repositories.ClientRepo
+}
+
+func NewTrackingService(orderRepo repositories.OrderRepo, shipmentRepo repositories.ShipmentRepo, clientRepo repositories.ClientRepo) *TrackingService {
+	return &TrackingService{
+		orderRepo:    orderRepo,
+		shipmentRepo: shipmentRepo,
+		clientRepo:   clientRepo,
+	}
+}
+
+// GetTracking returns the public view of the order behind a tracking token
+func (s *TrackingService) GetTracking(token string) (*models.OrderTracking, error) {
+	if token == "" {
+		return nil, ErrTrackingNotFound
+	}
+
+	order, err := s.orderRepo.GetByTrackingToken(token)
+	if err != nil {
+		return nil, ErrTrackingNotFound
+	}
+
+	if order.TrackingExpiresAt != nil && time.Now().After(*order.TrackingExpiresAt) {
+		return nil, ErrTrackingExpired
+	}
+
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		log.Printf("⚠️ Failed to parse items of order %s: %v", order.OrderNumber, err)
+	}
+
+	tracking := &models.OrderTracking{
+		OrderNumber:       order.OrderNumber,
+		CustomerName:      order.CustomerName,
+		Items:             items,
+		TotalAmount:       order.TotalAmount,
+		PaymentStatus:     order.PaymentStatus,
+		FulfillmentStatus: order.FulfillmentStatus,
+		CreatedAt:         order.CreatedAt,
+		PaidAt:            order.PaidAt,
+		Courier:           order.ShippingCourier,
+		TrackingNumber:    order.ShippingTrackingNumber,
+	}
+	if order.PaymentStatus == models.PaymentStatusPending {
+		tracking.PaymentLink = order.PaymentLink
+	}
+
+	if client, err := s.clientRepo.GetByID(order.ClientID.String()); err == nil {
+		tracking.BusinessName = client.BusinessName
+	}
+
+	if shipment, err := s.shipmentRepo.GetByOrderID(order.ID.String()); err == nil {
+		tracking.Shipment = &models.ShipmentTracking{
+			Status:      shipment.Status,
+			AssignedAt:  shipment.AssignedAt,
+			PickedUpAt:  shipment.PickedUpAt,
+			DeliveredAt: shipment.DeliveredAt,
+		}
+		if shipment.Driver != nil {
+			tracking.Shipment.DriverName = shipment.Driver.Name
+		}
+	}
+
+	return tracking, nil
+}
+
+// --- Order integration ---
+
+// assignTrackingToken gives a new order its public tracking token
+func (s *OrderService) assignTrackingToken(order *models.Order) {
+	token, err := randomHex(6)
+	if err != nil {
+		log.Printf("⚠️  Failed to generate tracking token for order %s: %v", order.OrderNumber, err)
+		return
+	}
+
+	expiresAt := time.Now().Add(trackingLinkTTL)
+	order.TrackingToken = &token
+	order.TrackingExpiresAt = &expiresAt
+}
+
+// trackingLine returns the tracking link line appended to customer messages, if available
+func (s *OrderService) trackingLine(order *models.Order) string {
+	if s.publicBaseURL == "" || order.TrackingToken == nil {
+		return ""
+	}
+	return fmt.Sprintf("\n\n📦 Lacak pesanan: %s/t/%s", s.publicBaseURL, *order.TrackingToken)
+}
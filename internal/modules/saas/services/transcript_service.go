@@ -0,0 +1,371 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/export"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/jobs"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	// TranscriptJobType is the job type of transcript exports on the exports queue
+	TranscriptJobType = "conversation_transcript"
+	// TranscriptQueue is the jobs queue transcript exports run on
+	TranscriptQueue = "exports"
+	// transcriptFolder is the storage folder of rendered transcripts
+	transcriptFolder = "transcripts"
+	// transcriptMaxBytes caps a rendered transcript in storage
+	transcriptMaxBytes = 50 * 1024 * 1024
+)
+
+var (
+	// ErrConversationNotFound is returned when the client has no chat with the customer
+	ErrConversationNotFound = errors.New("conversation not found")
+	// ErrTranscriptExportNotFound is returned when an export does not exist or belongs to another client
+	ErrTranscriptExportNotFound = errors.New("transcript export not found")
+	// ErrTranscriptRequest is returned for an invalid export request
+	ErrTranscriptRequest = errors.New("invalid transcript export request")
+	// ErrEmailUnavailable is returned when a transcript should be emailed but no email provider is configured
+	ErrEmailUnavailable = errors.New("email is not configured")
+)
+
+// TranscriptMailer sends the download link of a finished transcript
+type TranscriptMailer interface {
+	SendEmail(to, subject, body string) error
+}
+
+// transcriptJobPayload is the job payload of a transcript export
+type transcriptJobPayload struct {
+	ExportID uuid.UUID `json:"export_id"`
+}
+
+// TranscriptService exports customer chats as PDF or HTML transcripts through the jobs queue
+type TranscriptService struct {
+	exportRepo       repositories.TranscriptExportRepo
+	conversationRepo repositories.ConversationRepo
+	clientRepo       repositories.ClientRepo
+	companyUserRepo  repositories.CompanyUserRepo
+	jobService       *jobs.Service
+	exportService    *export.Service
+	uploadService    *upload.Service
+	mailer           TranscriptMailer
+}
+
+func NewTranscriptService(
+	exportRepo repositories.TranscriptExportRepo,
+	conversationRepo repositories.ConversationRepo,
+	clientRepo repositories.ClientRepo,
+	companyUserRepo repositories.CompanyUserRepo,
+	jobService *jobs.Service,
+	exportService *export.Service,
+	uploadService *upload.Service,
+	mailer TranscriptMailer,
+) *TranscriptService {
+	return &TranscriptService{
+		exportRepo:       exportRepo,
+		conversationRepo: conversationRepo,
+		clientRepo:       clientRepo,
+		companyUserRepo:  companyUserRepo,
+		jobService:       jobService,
+		exportService:    exportService,
+		uploadService:    uploadService,
+		mailer:           mailer,
+	}
+}
+
+// RequestExport records an export of the customer's chat and queues it for rendering
+func (s *TranscriptService) RequestExport(ctx context.Context, clientID uuid.UUID, customerPhone string, req *models.TranscriptExportRequest) (*models.TranscriptExport, error) {
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = string(export.FormatPDF)
+	}
+	if format != string(export.FormatPDF) && format != string(export.FormatHTML) {
+		return nil, fmt.Errorf("%w: format must be pdf or html", ErrTranscriptRequest)
+	}
+	if req.Start != nil && req.End != nil && !req.End.After(*req.Start) {
+		return nil, fmt.Errorf("%w: end must be after start", ErrTranscriptRequest)
+	}
+
+	if _, err := s.clientRepo.GetByID(clientID.String()); err != nil {
+		return nil, ErrClientNotFound
+	}
+	known, err := s.conversationRepo.HasCustomerConversations(clientID.String(), customerPhone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up conversation: %w", err)
+	}
+	if !known {
+		return nil, ErrConversationNotFound
+	}
+
+	var recipients []string
+	if req.Email || len(req.EmailTo) > 0 {
+		if s.mailer == nil {
+			return nil, ErrEmailUnavailable
+		}
+		if recipients, err = s.recipients(clientID, req.EmailTo); err != nil {
+			return nil, err
+		}
+	}
+
+	record := &models.TranscriptExport{
+		ClientID:      clientID,
+		CustomerPhone: customerPhone,
+		Format:        format,
+		Status:        models.TranscriptExportPending,
+		RangeStart:    req.Start,
+		RangeEnd:      req.End,
+		EmailTo:       recipients,
+	}
+	if err := s.exportRepo.Create(record); err != nil {
+		return nil, fmt.Errorf("failed to save transcript export: %w", err)
+	}
+
+	job, err := s.jobService.Enqueue(ctx, clientID, TranscriptJobType, transcriptJobPayload{ExportID: record.ID}, jobs.EnqueueOptions{
+		Queue:      TranscriptQueue,
+		Priority:   jobs.PriorityNormal,
+		MaxRetries: 1,
+	})
+	if err != nil {
+		record.Status = models.TranscriptExportFailed
+		record.Error = err.Error()
+		_ = s.exportRepo.Update(record)
+		return nil, fmt.Errorf("failed to queue transcript export: %w", err)
+	}
+
+	record.JobID = &job.ID
+	if err := s.exportRepo.Update(record); err != nil {
+		log.Printf("⚠️ Failed to save job of transcript export %s: %v", record.ID, err)
+	}
+
+	log.Printf("📝 Transcript export %s queued for %s (%s)", record.ID, customerPhone, format)
+	return record, nil
+}
+
+// GetExport returns a transcript export of the client
+func (s *TranscriptService) GetExport(clientID, id uuid.UUID) (*models.TranscriptExport, error) {
+	record, err := s.exportRepo.GetByID(id)
+	if err != nil || record.ClientID != clientID {
+		return nil, ErrTranscriptExportNotFound
+	}
+	return record, nil
+}
+
+// ListExports returns the customer's latest transcript exports
+func (s *TranscriptService) ListExports(clientID uuid.UUID, customerPhone string) ([]models.TranscriptExport, error) {
+	exports, err := s.exportRepo.ListByCustomer(clientID, customerPhone, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list transcript exports: %w", err)
+	}
+	if exports == nil {
+		exports = []models.TranscriptExport{}
+	}
+	return exports, nil
+}
+
+// GetType implements jobs.JobHandler
+func (s *TranscriptService) GetType() string {
+	return TranscriptJobType
+}
+
+// Handle implements jobs.JobHandler: renders the transcript, stores it and emails the link when requested
+func (s *TranscriptService) Handle(ctx context.Context, job *jobs.Job) error {
+	var payload transcriptJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return fmt.Errorf("invalid transcript job payload: %w", err)
+	}
+
+	record, err := s.exportRepo.GetByID(payload.ExportID)
+	if err != nil {
+		return fmt.Errorf("transcript export %s: %w", payload.ExportID, err)
+	}
+	if record.Status == models.TranscriptExportCompleted {
+		return nil
+	}
+
+	if err := s.render(record); err != nil {
+		record.Status = models.TranscriptExportFailed
+		record.Error = err.Error()
+		if saveErr := s.exportRepo.Update(record); saveErr != nil {
+			log.Printf("⚠️ Failed to save transcript export %s: %v", record.ID, saveErr)
+		}
+		return err
+	}
+
+	now := time.Now()
+	record.Status = models.TranscriptExportCompleted
+	record.Error = ""
+	record.CompletedAt = &now
+	if err := s.exportRepo.Update(record); err != nil {
+		return fmt.Errorf("failed to save transcript export: %w", err)
+	}
+	log.Printf("✅ Transcript export %s stored (%d messages): %s", record.ID, record.MessageCount, record.FileURL)
+
+	// The file is already stored, so a failed email doesn't fail the job
+	if len(record.EmailTo) > 0 {
+		s.email(record)
+	}
+	return nil
+}
+
+// render builds the transcript, renders it and uploads the file
+func (s *TranscriptService) render(record *models.TranscriptExport) error {
+	client, err := s.clientRepo.GetByID(record.ClientID.String())
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+	turns, err := s.conversationRepo.ListForCustomer(record.ClientID, record.CustomerPhone, record.RangeStart, record.RangeEnd)
+	if err != nil {
+		return fmt.Errorf("failed to load conversation: %w", err)
+	}
+
+	loc := clientLocation(client.Timezone)
+	transcript := &export.Transcript{
+		Title:       fmt.Sprintf("Chat transcript: %s", record.CustomerPhone),
+		Subtitle:    client.BusinessName,
+		GeneratedAt: time.Now().In(loc),
+		Timezone:    loc.String(),
+		Entries:     transcriptEntries(turns, client.BusinessName, loc),
+	}
+	if record.RangeStart != nil || record.RangeEnd != nil {
+		transcript.Subtitle += " | " + transcriptRange(record.RangeStart, record.RangeEnd, loc)
+	}
+
+	format := export.ExportFormat(record.Format)
+	data, contentType, err := s.exportService.ExportTranscript(transcript, format)
+	if err != nil {
+		return err
+	}
+
+	filename := fmt.Sprintf("transcript_%s_%s.%s", strings.TrimPrefix(record.CustomerPhone, "+"), record.ID.String()[:8], record.Format)
+	res, err := s.uploadService.Upload(bytes.NewReader(data), filename, &upload.UploadOptions{
+		Folder:       transcriptFolder + "/" + record.ClientID.String(),
+		ResourceType: "raw",
+		AllowedTypes: []string{contentType},
+		MaxSize:      transcriptMaxBytes,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to store transcript: %w", err)
+	}
+
+	record.FileURL = res.URL
+	if res.SecureURL != "" {
+		record.FileURL = res.SecureURL
+	}
+	record.MessageCount = len(transcript.Entries)
+	return nil
+}
+
+// email sends the download link to the export's recipients
+func (s *TranscriptService) email(record *models.TranscriptExport) {
+	subject := fmt.Sprintf("Chat transcript %s is ready", record.CustomerPhone)
+	body := fmt.Sprintf(
+		"<p>The chat transcript with <b>%s</b> (%d messages, %s) is ready.</p>"+
+			"<p><a href=\"%s\">Download transcript</a></p>",
+		html.EscapeString(record.CustomerPhone), record.MessageCount, strings.ToUpper(record.Format), html.EscapeString(record.FileURL),
+	)
+
+	sent := false
+	for _, to := range record.EmailTo {
+		if err := s.mailer.SendEmail(to, subject, body); err != nil {
+			log.Printf("⚠️ Failed to email transcript %s to %s: %v", record.ID, to, err)
+			continue
+		}
+		sent = true
+	}
+	if !sent {
+		return
+	}
+
+	now := time.Now()
+	record.EmailedAt = &now
+	if err := s.exportRepo.Update(record); err != nil {
+		log.Printf("⚠️ Failed to save transcript export %s: %v", record.ID, err)
+	}
+}
+
+// recipients validates explicit addresses or falls back to the client's tenant admins
+func (s *TranscriptService) recipients(clientID uuid.UUID, emailTo []string) ([]string, error) {
+	if len(emailTo) == 0 {
+		admins, err := s.companyUserRepo.ListAdminEmails(clientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up tenant admins: %w", err)
+		}
+		if len(admins) == 0 {
+			return nil, fmt.Errorf("%w: the client has no tenant admin with an email, set email_to", ErrTranscriptRequest)
+		}
+		return admins, nil
+	}
+
+	recipients := make([]string, 0, len(emailTo))
+	for _, address := range emailTo {
+		parsed, err := mail.ParseAddress(strings.TrimSpace(address))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email %q", ErrTranscriptRequest, address)
+		}
+		recipients = append(recipients, parsed.Address)
+	}
+	return recipients, nil
+}
+
+// transcriptEntries splits conversation turns into customer, bot and agent messages
+func transcriptEntries(turns []models.Conversation, businessName string, loc *time.Location) []export.TranscriptEntry {
+	entries := make([]export.TranscriptEntry, 0, len(turns)*2)
+	for _, turn := range turns {
+		at := turn.CreatedAt.In(loc)
+		if turn.MessageType == models.ConversationTypeAgent {
+			entries = append(entries, export.TranscriptEntry{
+				Time:     at,
+				Role:     export.TranscriptRoleAgent,
+				Author:   businessName,
+				Text:     turn.AIResponse,
+				MediaURL: turn.MediaURL,
+			})
+			continue
+		}
+
+		if turn.MessageText != "" || turn.MediaURL != "" {
+			entries = append(entries, export.TranscriptEntry{
+				Time:     at,
+				Role:     export.TranscriptRoleCustomer,
+				Author:   turn.CustomerPhone,
+				Text:     turn.MessageText,
+				MediaURL: turn.MediaURL,
+			})
+		}
+		if turn.AIResponse != "" {
+			entries = append(entries, export.TranscriptEntry{
+				Time:   at,
+				Role:   export.TranscriptRoleBot,
+				Author: "Bot",
+				Text:   turn.AIResponse,
+			})
+		}
+	}
+	return entries
+}
+
+// transcriptRange describes the export window for the transcript header
+func transcriptRange(start, end *time.Time, loc *time.Location) string {
+	const layout = "2006-01-02 15:04"
+	switch {
+	case start != nil && end != nil:
+		return start.In(loc).Format(layout) + " - " + end.In(loc).Format(layout)
+	case start != nil:
+		return "from " + start.In(loc).Format(layout)
+	default:
+		return "until " + end.In(loc).Format(layout)
+	}
+}
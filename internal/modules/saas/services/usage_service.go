@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// usageCheckTTL is how long a client's quota check is reused before its usage is summed again
+const usageCheckTTL = time.Minute
+
+// ErrInvalidCreditAmount is returned for a credit grant of 0 tokens
+var ErrInvalidCreditAmount = errors.New("tokens must not be 0")
+
+type usageCheck struct {
+	err       error
+	expiresAt time.Time
+}
+
+// UsageService meters the estimated tokens of every LLM call made for a client and enforces the plan's
+// monthly token quota. Plans that bill token overage are never blocked; plans without overage pricing stop
+// at the quota, then draw on the client's AI credits, and refuse LLM calls once both are used up.
+type UsageService struct {
+	usageRepo    repositories.UsageRepo
+	clientRepo   repositories.ClientRepo
+	auditService *audit.Service
+
+	mu     sync.Mutex
+	checks map[string]usageCheck
+}
+
+func NewUsageService(usageRepo repositories.UsageRepo, clientRepo repositories.ClientRepo, auditService *audit.Service) *UsageService {
+	return &UsageService{
+		usageRepo:    usageRepo,
+		clientRepo:   clientRepo,
+		auditService: auditService,
+		checks:       make(map[string]usageCheck),
+	}
+}
+
+// Allow refuses LLM calls with llm.ErrUsageExhausted once the client's enforced quota and credits are used up.
+// Failing to look up the usage never blocks a call.
+func (s *UsageService) Allow(ctx context.Context, clientID string) error {
+	s.mu.Lock()
+	check, ok := s.checks[clientID]
+	s.mu.Unlock()
+	if ok && time.Now().Before(check.expiresAt) {
+		return check.err
+	}
+
+	err := s.check(clientID)
+	s.mu.Lock()
+	s.checks[clientID] = usageCheck{err: err, expiresAt: time.Now().Add(usageCheckTTL)}
+	s.mu.Unlock()
+	return err
+}
+
+func (s *UsageService) check(clientID string) error {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil
+	}
+	quota, enforced := tokenQuota(client)
+	if !enforced {
+		return nil
+	}
+
+	start, end := billingPeriod(time.Now(), client.Timezone)
+	totals, err := s.usageRepo.Totals(client.ID, start, end)
+	if err != nil {
+		log.Printf("⚠️ Failed to sum token usage of client %s: %v", clientID, err)
+		return nil
+	}
+	if totals.TotalTokens < quota {
+		return nil
+	}
+	balance, err := s.usageRepo.CreditBalance(client.ID)
+	if err != nil {
+		log.Printf("⚠️ Failed to read AI credits of client %s: %v", clientID, err)
+		return nil
+	}
+	if balance > 0 {
+		return nil
+	}
+	return llm.ErrUsageExhausted
+}
+
+// Record stores the tokens of a call; the part over an enforced quota is drawn from the client's credits
+func (s *UsageService) Record(ctx context.Context, usage llm.Usage) {
+	client, err := s.clientRepo.GetByID(usage.ClientID)
+	if err != nil {
+		log.Printf("⚠️ Not metering LLM call of unknown client %s", usage.ClientID)
+		return
+	}
+
+	record := &models.LLMUsage{
+		ClientID:         client.ID,
+		Feature:          usage.Feature,
+		Provider:         usage.Provider,
+		Operation:        usage.Operation,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.PromptTokens + usage.CompletionTokens,
+	}
+
+	if quota, enforced := tokenQuota(client); enforced {
+		start, end := billingPeriod(time.Now(), client.Timezone)
+		totals, err := s.usageRepo.Totals(client.ID, start, end)
+		if err != nil {
+			log.Printf("⚠️ Failed to sum token usage of client %s: %v", client.ID, err)
+		} else if over := totals.TotalTokens + int64(record.TotalTokens) - quota; over > 0 {
+			drawn, err := s.usageRepo.DrawCredits(client.ID, min(over, int64(record.TotalTokens)))
+			if err != nil {
+				log.Printf("⚠️ Failed to draw AI credits of client %s: %v", client.ID, err)
+			}
+			record.CreditTokens = int(drawn)
+			s.forget(usage.ClientID) // The client may have just run out
+		}
+	}
+
+	if err := s.usageRepo.Create(record); err != nil {
+		log.Printf("⚠️ Failed to record LLM usage of client %s: %v", client.ID, err)
+	}
+}
+
+// Report returns the client's token usage in the current billing period, per feature and per day
+func (s *UsageService) Report(clientID uuid.UUID) (*models.UsageReport, error) {
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	start, end := billingPeriod(time.Now(), client.Timezone)
+	totals, err := s.usageRepo.Totals(client.ID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum token usage: %w", err)
+	}
+	byFeature, err := s.usageRepo.ByFeature(client.ID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum token usage per feature: %w", err)
+	}
+	daily, err := s.usageRepo.ByDay(client.ID, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum token usage per day: %w", err)
+	}
+	balance, err := s.usageRepo.CreditBalance(client.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AI credits: %w", err)
+	}
+
+	quota, enforced := tokenQuota(client)
+	report := &models.UsageReport{
+		Plan:           client.SubscriptionPlan,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		TokenQuota:     quota,
+		QuotaEnforced:  enforced,
+		CreditBalance:  balance,
+		LLMUsageTotals: *totals,
+		ByFeature:      byFeature,
+		Daily:          daily,
+	}
+	if quota > 0 {
+		report.QuotaLeft = max(quota-totals.TotalTokens, 0)
+	}
+	report.Exhausted = enforced && report.QuotaLeft == 0 && balance == 0
+	if report.ByFeature == nil {
+		report.ByFeature = []models.FeatureUsage{}
+	}
+	if report.Daily == nil {
+		report.Daily = []models.UsageDay{}
+	}
+	return report, nil
+}
+
+// GrantCredits adds AI tokens to a client's credits (or removes them with a negative amount) and writes an audit log entry
+func (s *UsageService) GrantCredits(clientID uuid.UUID, req *models.GrantAICreditsRequest, actor string) (*models.AICredit, error) {
+	if req.Tokens == 0 {
+		return nil, ErrInvalidCreditAmount
+	}
+	if _, err := s.clientRepo.GetByID(clientID.String()); err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	before, err := s.usageRepo.CreditBalance(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read AI credits: %w", err)
+	}
+	balance, err := s.usageRepo.AddCredits(clientID, req.Tokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add AI credits: %w", err)
+	}
+	s.forget(clientID.String())
+
+	log.Printf("🎟️ AI credits of client %s changed by %d tokens (balance %d) by %s", clientID, req.Tokens, balance, actor)
+	s.recordAudit(clientID, actor, req, before, balance)
+	return &models.AICredit{ClientID: clientID, Balance: balance, UpdatedAt: time.Now()}, nil
+}
+
+// forget drops the cached quota check of a client
+func (s *UsageService) forget(clientID string) {
+	s.mu.Lock()
+	delete(s.checks, clientID)
+	s.mu.Unlock()
+}
+
+// recordAudit writes an audit log entry for a credit grant, logging (not returning) failures
+func (s *UsageService) recordAudit(clientID uuid.UUID, actor string, req *models.GrantAICreditsRequest, before, after int64) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := &audit.AuditLog{
+		ClientID:    clientID,
+		Action:      "update",
+		Entity:      "ai_credits",
+		EntityID:    clientID.String(),
+		Description: fmt.Sprintf("AI credits changed by %d tokens: %s", req.Tokens, req.Note),
+	}
+	if data, err := json.Marshal(map[string]interface{}{"balance": before}); err == nil {
+		entry.OldValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{"balance": after}); err == nil {
+		entry.NewValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{"actor": actor, "tokens": req.Tokens, "note": req.Note}); err == nil {
+		entry.Metadata = datatypes.JSON(data)
+	}
+
+	if err := s.auditService.Log(context.Background(), entry); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+// tokenQuota returns the monthly tokens of the client's plan and whether calls stop there. Custom plans
+// outside the catalog, unlimited plans and plans billing token overage are not enforced.
+func tokenQuota(client *models.Client) (int64, bool) {
+	plan, _, ok := models.FindPlan(client.SubscriptionPlan)
+	if !ok {
+		return 0, false
+	}
+	quota := int64(plan.Limits.TokensPerMonth)
+	rate, _ := plan.Overage.Rate(models.UsageMetricTokens)
+	return quota, quota > 0 && rate == 0
+}
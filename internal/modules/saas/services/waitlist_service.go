@@ -0,0 +1,283 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+)
+
+// waitlistOptInKeyword is the reply a customer sends to opt in to a restock alert
+const waitlistOptInKeyword = "ingatkan"
+
+// WaitlistService handles restock alerts and pre-orders for out-of-stock products
+type WaitlistService struct {
+	waitlistRepo repositories.WaitlistRepo
+	productRepo  repositories.ProductRepo
+	whatsappSvc  WhatsAppService
+	sandboxSvc   *SandboxService
+}
+
+func NewWaitlistService(
+	waitlistRepo repositories.WaitlistRepo,
+	productRepo repositories.ProductRepo,
+	whatsappSvc WhatsAppService,
+	sandboxSvc *SandboxService,
+) *WaitlistService {
+	return &WaitlistService{
+		waitlistRepo: waitlistRepo,
+		productRepo:  productRepo,
+		whatsappSvc:  whatsappSvc,
+		sandboxSvc:   sandboxSvc,
+	}
+}
+
+// FindProduct looks up a catalog product by the name the bot uses
+func (s *WaitlistService) FindProduct(clientID, productName string) (*models.Product, bool) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, false
+	}
+
+	product, err := s.productRepo.GetByName(uid, productName)
+	if err != nil {
+		return nil, false
+	}
+	return product, true
+}
+
+// OfferRestockAlert records a pending restock alert and returns the reply asking the customer to opt in
+func (s *WaitlistService) OfferRestockAlert(product *models.Product, customerPhone string, quantity int) string {
+	entry, err := s.waitlistRepo.GetOpen(product.ID, customerPhone)
+	if err == nil && entry.Status == models.WaitlistStatusWaiting {
+		return fmt.Sprintf("🙏 Maaf, *%s* sedang habis.\n\nAnda sudah terdaftar dan akan kami kabari saat stok tersedia kembali.", product.Name)
+	}
+
+	if err != nil {
+		entry = &models.WaitlistEntry{
+			ClientID:      product.ClientID,
+			ProductID:     product.ID,
+			CustomerPhone: customerPhone,
+			Type:          models.WaitlistTypeRestock,
+			Status:        models.WaitlistStatusOffered,
+			Quantity:      quantity,
+		}
+		if err := s.waitlistRepo.Create(entry); err != nil {
+			log.Printf("⚠️ Failed to record restock offer for %s: %v", product.Name, err)
+		}
+	}
+
+	return fmt.Sprintf(
+		"🙏 Maaf, *%s* sedang habis.\n\n"+
+			"Balas *%s* jika ingin kami kabari saat stok tersedia kembali.",
+		product.Name,
+		strings.ToUpper(waitlistOptInKeyword),
+	)
+}
+
+// HandleOptIn confirms the customer's latest restock offer when they reply the opt-in keyword.
+// Returns the reply and true if the message was an opt-in.
+func (s *WaitlistService) HandleOptIn(clientID, customerPhone, message string) (string, bool) {
+	if !strings.EqualFold(strings.TrimSpace(message), waitlistOptInKeyword) {
+		return "", false
+	}
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return "", false
+	}
+
+	entry, err := s.waitlistRepo.GetLatestOffered(uid, customerPhone)
+	if err != nil {
+		return "", false
+	}
+
+	entry.Status = models.WaitlistStatusWaiting
+	if err := s.waitlistRepo.Update(entry); err != nil {
+		log.Printf("⚠️ Failed to confirm waitlist entry %s: %v", entry.ID, err)
+		return "❌ Maaf, terjadi kesalahan. Silakan coba lagi.", true
+	}
+
+	productName := "produk ini"
+	if entry.Product != nil {
+		productName = entry.Product.Name
+	}
+
+	log.Printf("🔔 %s joined the waitlist for %s", customerPhone, productName)
+	return fmt.Sprintf("🔔 Siap! Kami akan mengabari Anda saat *%s* tersedia kembali.", productName), true
+}
+
+// RecordPreorder tracks a pre-order added to the cart so its conversion can be measured
+func (s *WaitlistService) RecordPreorder(product *models.Product, customerPhone string, quantity int) {
+	entry, err := s.waitlistRepo.GetOpen(product.ID, customerPhone)
+	if err == nil {
+		entry.Type = models.WaitlistTypePreorder
+		entry.Status = models.WaitlistStatusWaiting
+		entry.Quantity = quantity
+		if err := s.waitlistRepo.Update(entry); err != nil {
+			log.Printf("⚠️ Failed to update pre-order entry %s: %v", entry.ID, err)
+		}
+		return
+	}
+
+	entry = &models.WaitlistEntry{
+		ClientID:      product.ClientID,
+		ProductID:     product.ID,
+		CustomerPhone: customerPhone,
+		Type:          models.WaitlistTypePreorder,
+		Status:        models.WaitlistStatusWaiting,
+		Quantity:      quantity,
+	}
+	if err := s.waitlistRepo.Create(entry); err != nil {
+		log.Printf("⚠️ Failed to record pre-order for %s: %v", product.Name, err)
+	}
+}
+
+// NotifyRestock sends restock alerts to everyone waiting for a product that is back in stock
+func (s *WaitlistService) NotifyRestock(product *models.Product) {
+	if !product.IsAvailable() {
+		return
+	}
+
+	entries, err := s.waitlistRepo.ListByProduct(product.ID, models.WaitlistStatusWaiting)
+	if err != nil {
+		log.Printf("⚠️ Failed to load waitlist for %s: %v", product.Name, err)
+		return
+	}
+
+	message := fmt.Sprintf(
+		"🎉 *%s* sudah tersedia kembali!\n\n"+
+			"💰 Harga: Rp %s\n\n"+
+			"Balas pesan ini untuk langsung memesan.",
+		product.Name,
+		formatCurrency(product.Price),
+	)
+
+	notified := 0
+	for i := range entries {
+		entry := &entries[i]
+		// Pre-orders are already in the customer's cart or order
+		if entry.Type != models.WaitlistTypeRestock {
+			continue
+		}
+
+		if err := sandboxAwareMessenger(s.sandboxSvc, s.whatsappSvc, product.ClientID).SendMessage(entry.CustomerPhone, message); err != nil {
+			log.Printf("⚠️ Failed to send restock alert to %s: %v", entry.CustomerPhone, err)
+			continue
+		}
+
+		now := time.Now()
+		entry.Status = models.WaitlistStatusNotified
+		entry.NotifiedAt = &now
+		if err := s.waitlistRepo.Update(entry); err != nil {
+			log.Printf("⚠️ Failed to update waitlist entry %s: %v", entry.ID, err)
+		}
+		notified++
+	}
+
+	if notified > 0 {
+		log.Printf("🔔 Restock alert for %s sent to %d customers", product.Name, notified)
+	}
+}
+
+// TrackConversion marks the customer's waitlist entries for products in the order as converted
+func (s *WaitlistService) TrackConversion(order *models.Order) {
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		return
+	}
+
+	entries, err := s.waitlistRepo.ListConvertible(order.ClientID, order.CustomerPhone)
+	if err != nil || len(entries) == 0 {
+		return
+	}
+
+	for i := range entries {
+		entry := &entries[i]
+		if !orderContainsProduct(items, entry) {
+			continue
+		}
+
+		now := time.Now()
+		entry.Status = models.WaitlistStatusConverted
+		entry.OrderID = &order.ID
+		entry.ConvertedAt = &now
+		if err := s.waitlistRepo.Update(entry); err != nil {
+			log.Printf("⚠️ Failed to mark waitlist entry %s converted: %v", entry.ID, err)
+			continue
+		}
+		log.Printf("📈 Waitlist %s converted into order %s", entry.Type, order.OrderNumber)
+	}
+}
+
+// GetWaitlist lists a product's waitlist entries with conversion stats
+func (s *WaitlistService) GetWaitlist(clientID uuid.UUID, productID, status string) ([]models.WaitlistEntry, *models.WaitlistStats, error) {
+	product, err := s.productRepo.GetByID(productID)
+	if err != nil || product.ClientID != clientID {
+		return nil, nil, errors.New("product not found")
+	}
+
+	entries, err := s.waitlistRepo.ListByProduct(product.ID, status)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	counts, err := s.waitlistRepo.CountByStatus(product.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	preorders, err := s.waitlistRepo.CountPreorders(product.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stats := &models.WaitlistStats{
+		Waiting:   counts[models.WaitlistStatusWaiting],
+		Notified:  counts[models.WaitlistStatusNotified],
+		Converted: counts[models.WaitlistStatusConverted],
+		Preorders: preorders,
+	}
+	if reached := stats.Notified + stats.Converted; reached > 0 {
+		stats.ConversionRate = float64(stats.Converted) / float64(reached) * 100
+	}
+
+	return entries, stats, nil
+}
+
+// PreorderNote describes a pre-order for the cart item and the customer reply
+func PreorderNote(product *models.Product) string {
+	if product.PreorderAvailableAt != nil {
+		return fmt.Sprintf("Pre-order, estimasi tersedia %s", product.PreorderAvailableAt.Format("02 Jan 2006"))
+	}
+	return "Pre-order"
+}
+
+// orderContainsProduct matches an order item to a waitlist entry by product ID or name
+func orderContainsProduct(items []models.OrderItem, entry *models.WaitlistEntry) bool {
+	for _, item := range items {
+		if item.ProductID == entry.ProductID.String() {
+			return true
+		}
+		if entry.Product != nil && strings.EqualFold(item.ProductName, entry.Product.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// --- Order integration ---
+
+// trackWaitlistConversion attributes a new order to the customer's waitlist entries
+func (s *OrderService) trackWaitlistConversion(order *models.Order) {
+	if s.waitlistSvc == nil {
+		return
+	}
+	s.waitlistSvc.TrackConversion(order)
+}
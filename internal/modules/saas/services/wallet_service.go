@@ -0,0 +1,628 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// WalletTopUpReferencePrefix marks gateway order IDs that top up a customer's wallet
+const WalletTopUpReferencePrefix = "TOP-"
+
+const (
+	// walletGatewayName is recorded as the gateway of wallet refunds
+	walletGatewayName = "Wallet"
+	// walletChatHistory is how many ledger entries a balance check in chat shows
+	walletChatHistory = 3
+)
+
+var (
+	ErrWalletDisabled         = errors.New("wallet is not enabled for this client")
+	ErrWalletNotFound         = errors.New("wallet not found")
+	ErrInsufficientBalance    = errors.New("insufficient wallet balance")
+	ErrInvalidWalletAmount    = errors.New("invalid wallet amount")
+	ErrWalletTopUpNotFound    = errors.New("wallet top-up not found")
+	ErrWalletTopUpNotPending  = errors.New("wallet top-up is not pending")
+	ErrWalletTopUpAutoConfirm = errors.New("wallet top-up is confirmed by its payment gateway")
+)
+
+// Chat commands: "isi saldo 50000" / "topup 50rb" start a top-up, "saldo" checks the balance
+var (
+	walletTopUpPattern    = regexp.MustCompile(`^(?:isi\s*saldo|top\s*up)\s+(?:rp\.?\s*)?([\d.,]+)\s*(rb|ribu|k|jt|juta)?$`)
+	walletBalanceKeywords = map[string]bool{"saldo": true, "cek saldo": true, "saldo saya": true, "info saldo": true}
+)
+
+// WalletService keeps prepaid credit per customer: customers top up through the payment gateway, orders are
+// paid from the balance at checkout when it covers the total, refunds of those orders go back to the
+// balance, and the tenant can correct a balance by hand (recorded in the audit log)
+type WalletService struct {
+	repo         repositories.WalletRepo
+	orderService *OrderService
+	auditService *audit.Service
+}
+
+// NewWalletService creates a new wallet service
+func NewWalletService(repo repositories.WalletRepo, orderService *OrderService, auditService *audit.Service) *WalletService {
+	return &WalletService{
+		repo:         repo,
+		orderService: orderService,
+		auditService: auditService,
+	}
+}
+
+// GetSettings returns the tenant's wallet settings, falling back to defaults (wallet disabled)
+func (s *WalletService) GetSettings(clientID string) (*models.WalletSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	settings, err := s.repo.GetSettings(clientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.DefaultWalletSettings(uid), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet settings: %w", err)
+	}
+	return settings, nil
+}
+
+// UpdateSettings validates and stores the tenant's wallet settings
+func (s *WalletService) UpdateSettings(clientID string, req *models.UpdateWalletSettingsRequest) (*models.WalletSettings, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	if req.MinTopUp == 0 {
+		req.MinTopUp = models.DefaultWalletSettings(uid).MinTopUp
+	}
+	switch {
+	case req.MinTopUp < 0:
+		return nil, fmt.Errorf("min_top_up must not be negative")
+	case req.MaxTopUp < 0:
+		return nil, fmt.Errorf("max_top_up must not be negative")
+	case req.MaxTopUp > 0 && req.MaxTopUp < req.MinTopUp:
+		return nil, fmt.Errorf("max_top_up must be at least min_top_up")
+	}
+
+	settings := &models.WalletSettings{
+		ClientID:   uid,
+		Enabled:    req.Enabled,
+		AutoDeduct: req.AutoDeduct,
+		MinTopUp:   roundAmount(req.MinTopUp),
+		MaxTopUp:   roundAmount(req.MaxTopUp),
+	}
+	if err := s.repo.UpsertSettings(settings); err != nil {
+		return nil, fmt.Errorf("failed to save wallet settings: %w", err)
+	}
+	return s.GetSettings(clientID)
+}
+
+// ListWallets returns a client's customer wallets, largest balance first
+func (s *WalletService) ListWallets(clientID string, limit int) ([]models.CustomerWallet, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return s.repo.ListWallets(uid, limit)
+}
+
+// GetStatement returns a customer's wallet with its latest ledger entries
+func (s *WalletService) GetStatement(clientID, customerPhone string, limit int) (*models.WalletStatement, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+
+	wallet, err := s.repo.GetWallet(uid, normalizePhone(customerPhone))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	transactions, err := s.repo.ListTransactions(wallet.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet transactions: %w", err)
+	}
+	return &models.WalletStatement{Wallet: wallet, Transactions: transactions}, nil
+}
+
+// TopUp creates a payment link for credit on the customer's wallet and sends it to the customer.
+// The balance grows once the gateway (or an admin, for manual payments) confirms the payment.
+func (s *WalletService) TopUp(clientID string, req *models.WalletTopUpRequest) (*models.WalletTransaction, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	settings, err := s.GetSettings(clientID)
+	if err != nil {
+		return nil, err
+	}
+	if !settings.Enabled {
+		return nil, ErrWalletDisabled
+	}
+
+	phone := normalizePhone(req.CustomerPhone)
+	if phone == "" {
+		return nil, fmt.Errorf("%w: customer_phone is required", ErrInvalidWalletAmount)
+	}
+	amount := roundAmount(req.Amount)
+	if amount < settings.MinTopUp {
+		return nil, fmt.Errorf("%w: the minimum top-up is Rp %s", ErrInvalidWalletAmount, formatPrice(settings.MinTopUp))
+	}
+	if settings.MaxTopUp > 0 && amount > settings.MaxTopUp {
+		return nil, fmt.Errorf("%w: the maximum top-up is Rp %s", ErrInvalidWalletAmount, formatPrice(settings.MaxTopUp))
+	}
+
+	gateway, err := s.topUpGateway(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.repo.GetOrCreateWallet(uid, phone, strings.TrimSpace(req.CustomerName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	reference := walletTopUpReference()
+	transaction := &models.WalletTransaction{
+		ID:             uuid.New(),
+		ClientID:       uid,
+		WalletID:       wallet.ID,
+		CustomerPhone:  phone,
+		Type:           models.WalletTxTopUp,
+		Status:         models.WalletTxPending,
+		Amount:         amount,
+		Reference:      &reference,
+		PaymentGateway: gateway.Name(),
+		Note:           "Isi saldo",
+	}
+
+	result, err := gateway.Process(&payment.Order{
+		ID:            transaction.ID,
+		ClientID:      uid,
+		OrderNumber:   reference,
+		CustomerPhone: phone,
+		CustomerName:  wallet.CustomerName,
+		Items: []payment.OrderItem{{
+			VariantID:   transaction.ID,
+			ProductName: "Isi Saldo",
+			Quantity:    1,
+			UnitPrice:   amount,
+			Subtotal:    amount,
+		}},
+		TotalAmount: amount,
+		Currency:    "IDR",
+		Status:      payment.StatusPending,
+		CreatedAt:   time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create top-up payment: %w", err)
+	}
+	transaction.PaymentLink = result.PaymentLink
+
+	if err := s.repo.CreateTransaction(transaction); err != nil {
+		_ = gateway.Cancel(reference)
+		return nil, fmt.Errorf("failed to save top-up: %w", err)
+	}
+
+	log.Printf("💳 Wallet top-up %s started: %s Rp %s via %s", reference, phone, formatPrice(amount), gateway.Name())
+
+	s.orderService.messenger(uid).SendMessage(phone, fmt.Sprintf(
+		"💳 *Isi Saldo*\n\n"+
+			"Nominal: *Rp %s*\n"+
+			"Saldo sekarang: Rp %s\n\n"+
+			"%s\n\n"+
+			"Saldo bertambah otomatis setelah pembayaran diterima.",
+		formatPrice(amount),
+		formatPrice(wallet.Balance),
+		result.Instructions,
+	))
+	return transaction, nil
+}
+
+// ConfirmTopUp credits a paid top-up to its wallet (gateway webhook). Repeated notifications are ignored.
+func (s *WalletService) ConfirmTopUp(reference, paymentMethod, transactionID string) error {
+	transaction, completed, err := s.repo.CompleteTopUp(reference, transactionID, time.Now())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWalletTopUpNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to credit top-up: %w", err)
+	}
+	if !completed {
+		log.Printf("ℹ️ Wallet top-up %s is already %s", reference, transaction.Status)
+		return nil
+	}
+
+	log.Printf("✅ Wallet top-up %s credited: %s Rp %s (%s)", reference, transaction.CustomerPhone, formatPrice(transaction.Amount), paymentMethod)
+
+	s.orderService.messenger(transaction.ClientID).SendMessage(transaction.CustomerPhone, fmt.Sprintf(
+		"✅ *Saldo Bertambah*\n\n"+
+			"Isi saldo *Rp %s* sudah kami terima.\n"+
+			"Saldo sekarang: *Rp %s*\n\n"+
+			"Saldo otomatis dipakai untuk pesanan berikutnya. Terima kasih! 🙏",
+		formatPrice(transaction.Amount),
+		formatPrice(*transaction.BalanceAfter),
+	))
+	return nil
+}
+
+// ConfirmManualTopUp credits a top-up paid outside an automated gateway (e.g. bank transfer checked by an admin)
+func (s *WalletService) ConfirmManualTopUp(clientID, reference, paymentMethod, transactionID string) (*models.WalletTransaction, error) {
+	transaction, err := s.repo.GetTransactionByReference(reference)
+	if err != nil || transaction.ClientID.String() != clientID {
+		return nil, ErrWalletTopUpNotFound
+	}
+	if transaction.Status != models.WalletTxPending {
+		return nil, fmt.Errorf("%w: status is %s", ErrWalletTopUpNotPending, transaction.Status)
+	}
+	if gateway, ok := s.orderService.paymentGateways.Get(payment.GatewayMidtrans); ok && transaction.PaymentGateway == gateway.Name() {
+		return nil, ErrWalletTopUpAutoConfirm
+	}
+
+	if paymentMethod == "" {
+		paymentMethod = payment.MethodManual
+	}
+	if err := s.ConfirmTopUp(reference, paymentMethod, transactionID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTransactionByReference(reference)
+}
+
+// CancelTopUp cancels a top-up whose payment was denied, cancelled or expired
+func (s *WalletService) CancelTopUp(reference, reason string) error {
+	transaction, err := s.repo.GetTransactionByReference(reference)
+	if err != nil {
+		return ErrWalletTopUpNotFound
+	}
+
+	cancelled, err := s.repo.CancelTopUp(reference)
+	if err != nil {
+		return fmt.Errorf("failed to cancel top-up: %w", err)
+	}
+	if !cancelled {
+		log.Printf("ℹ️ Wallet top-up %s is already %s", reference, transaction.Status)
+		return nil
+	}
+
+	log.Printf("❌ Wallet top-up %s cancelled: %s", reference, reason)
+
+	s.orderService.messenger(transaction.ClientID).SendMessage(transaction.CustomerPhone, fmt.Sprintf(
+		"😔 *Isi Saldo Dibatalkan*\n\n"+
+			"Isi saldo Rp %s tidak berhasil.\n"+
+			"*Alasan:* %s\n\n"+
+			"Ketik *isi saldo <nominal>* untuk mencoba lagi.",
+		formatPrice(transaction.Amount),
+		reason,
+	))
+	return nil
+}
+
+// PayOrder pays a new order from the customer's balance when the tenant deducts automatically and the balance
+// covers the total. Returns the ledger reference, or false when the order has to be paid the normal way (the
+// customer is told when their balance falls short).
+func (s *WalletService) PayOrder(order *models.Order) (string, bool) {
+	settings, err := s.GetSettings(order.ClientID.String())
+	if err != nil || !settings.Enabled || !settings.AutoDeduct {
+		return "", false
+	}
+
+	wallet, err := s.repo.GetWallet(order.ClientID, normalizePhone(order.CustomerPhone))
+	if err != nil || wallet.Balance <= 0 {
+		return "", false // No credit bought yet
+	}
+
+	orderID := order.ID
+	transaction := &models.WalletTransaction{
+		ClientID:      order.ClientID,
+		WalletID:      wallet.ID,
+		CustomerPhone: wallet.CustomerPhone,
+		Type:          models.WalletTxPayment,
+		Amount:        -roundAmount(order.TotalAmount),
+		OrderID:       &orderID,
+		Note:          fmt.Sprintf("Pembayaran pesanan #%s", order.OrderNumber),
+	}
+	applied, err := s.repo.Apply(transaction, time.Now())
+	if err != nil {
+		log.Printf("⚠️ Failed to pay order %s from wallet: %v", order.OrderNumber, err)
+		return "", false
+	}
+	if !applied {
+		log.Printf("💳 Wallet balance Rp %s too low for order %s (Rp %s)", formatPrice(wallet.Balance), order.OrderNumber, formatPrice(order.TotalAmount))
+		s.orderService.messenger(order.ClientID).SendMessage(order.CustomerPhone, fmt.Sprintf(
+			"💳 Saldo Anda *Rp %s* belum cukup untuk pesanan *#%s* (Rp %s), jadi pesanan dibayar dengan cara biasa.\n\n"+
+				"Ketik *isi saldo <nominal>* untuk menambah saldo.",
+			formatPrice(wallet.Balance),
+			order.OrderNumber,
+			formatPrice(order.TotalAmount),
+		))
+		return "", false
+	}
+
+	log.Printf("💳 Order %s paid from wallet (Rp %s left)", order.OrderNumber, formatPrice(*transaction.BalanceAfter))
+	return transaction.ID.String(), true
+}
+
+// RefundOrder returns amount of an order paid from the wallet to the customer's balance
+func (s *WalletService) RefundOrder(order *models.Order, amount float64, reason string) (*payment.RefundResult, error) {
+	wallet, err := s.repo.GetOrCreateWallet(order.ClientID, normalizePhone(order.CustomerPhone), order.CustomerName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open wallet: %w", err)
+	}
+
+	orderID := order.ID
+	transaction := &models.WalletTransaction{
+		ClientID:      order.ClientID,
+		WalletID:      wallet.ID,
+		CustomerPhone: wallet.CustomerPhone,
+		Type:          models.WalletTxRefund,
+		Amount:        roundAmount(amount),
+		OrderID:       &orderID,
+		Note:          reason,
+	}
+	if _, err := s.repo.Apply(transaction, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to credit refund to wallet: %w", err)
+	}
+
+	return &payment.RefundResult{
+		Reference: transaction.ID.String(),
+		Amount:    transaction.Amount,
+		Status:    payment.RefundStatusRefunded,
+		Message:   fmt.Sprintf("credited to wallet, balance Rp %s", formatPrice(*transaction.BalanceAfter)),
+	}, nil
+}
+
+// Adjust corrects a customer's balance by hand and records who did it in the audit log
+func (s *WalletService) Adjust(clientID, customerPhone string, req *models.WalletAdjustmentRequest, actor string) (*models.WalletTransaction, error) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id: %w", err)
+	}
+
+	amount := roundAmount(req.Amount)
+	if amount == 0 {
+		return nil, fmt.Errorf("%w: amount must not be zero", ErrInvalidWalletAmount)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidWalletAmount)
+	}
+
+	phone := normalizePhone(customerPhone)
+	var wallet *models.CustomerWallet
+	if amount > 0 {
+		wallet, err = s.repo.GetOrCreateWallet(uid, phone, "")
+	} else {
+		wallet, err = s.repo.GetWallet(uid, phone)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrWalletNotFound
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	transaction := &models.WalletTransaction{
+		ClientID:      uid,
+		WalletID:      wallet.ID,
+		CustomerPhone: wallet.CustomerPhone,
+		Type:          models.WalletTxAdjustment,
+		Amount:        amount,
+		Note:          reason,
+		Actor:         actor,
+	}
+	applied, err := s.repo.Apply(transaction, time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("failed to adjust wallet: %w", err)
+	}
+	if !applied {
+		return nil, fmt.Errorf("%w: balance is Rp %s", ErrInsufficientBalance, formatPrice(wallet.Balance))
+	}
+
+	log.Printf("🛠️ Wallet of %s adjusted by %s: %+.2f (%s)", wallet.CustomerPhone, actor, amount, reason)
+
+	s.recordAudit(uid, actor, wallet, transaction)
+
+	verb := "ditambahkan ke"
+	if amount < 0 {
+		verb = "dikurangi dari"
+	}
+	s.orderService.messenger(uid).SendMessage(wallet.CustomerPhone, fmt.Sprintf(
+		"💳 *Penyesuaian Saldo*\n\n"+
+			"Rp %s %s saldo Anda.\n"+
+			"*Keterangan:* %s\n"+
+			"Saldo sekarang: *Rp %s*",
+		formatPrice(abs(amount)),
+		verb,
+		reason,
+		formatPrice(*transaction.BalanceAfter),
+	))
+	return transaction, nil
+}
+
+// HandleCustomerMessage answers balance checks ("saldo") and starts top-ups ("isi saldo 50000") in chat.
+// Returns the reply and true if the message was a wallet command of a client with wallets enabled.
+func (s *WalletService) HandleCustomerMessage(clientID, customerPhone, message string) (string, bool) {
+	text := strings.ToLower(strings.Join(strings.Fields(message), " "))
+	match := walletTopUpPattern.FindStringSubmatch(text)
+	if match == nil && !walletBalanceKeywords[text] {
+		return "", false
+	}
+
+	settings, err := s.GetSettings(clientID)
+	if err != nil || !settings.Enabled {
+		return "", false
+	}
+
+	if match != nil {
+		amount, ok := parseWalletAmount(match[1], match[2])
+		if !ok {
+			return "❌ Nominal tidak valid. Contoh: *isi saldo 50000*", true
+		}
+		_, err := s.TopUp(clientID, &models.WalletTopUpRequest{CustomerPhone: customerPhone, Amount: amount})
+		switch {
+		case errors.Is(err, ErrInvalidWalletAmount):
+			limits := fmt.Sprintf("minimal Rp %s", formatPrice(settings.MinTopUp))
+			if settings.MaxTopUp > 0 {
+				limits += fmt.Sprintf(", maksimal Rp %s", formatPrice(settings.MaxTopUp))
+			}
+			return fmt.Sprintf("❌ Nominal isi saldo %s.", limits), true
+		case err != nil:
+			log.Printf("⚠️ Failed to start wallet top-up for %s: %v", customerPhone, err)
+			return "❌ Maaf, isi saldo sedang tidak bisa diproses. Silakan coba lagi nanti.", true
+		}
+		return "", true // The payment instructions were sent by TopUp
+	}
+
+	statement, err := s.GetStatement(clientID, customerPhone, walletChatHistory)
+	if errors.Is(err, ErrWalletNotFound) {
+		return fmt.Sprintf(
+			"💳 *Saldo Anda: Rp 0*\n\n"+
+				"Ketik *isi saldo <nominal>* untuk mengisi saldo (minimal Rp %s). Saldo otomatis dipakai saat checkout.",
+			formatPrice(settings.MinTopUp),
+		), true
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to get wallet of %s: %v", customerPhone, err)
+		return "❌ Maaf, terjadi kesalahan. Silakan coba lagi.", true
+	}
+
+	reply := fmt.Sprintf("💳 *Saldo Anda: Rp %s*", formatPrice(statement.Wallet.Balance))
+	var lines []string
+	for _, t := range statement.Transactions {
+		if t.Status != models.WalletTxCompleted {
+			continue
+		}
+		sign := "+"
+		if t.Amount < 0 {
+			sign = "-"
+		}
+		lines = append(lines, fmt.Sprintf("• %s %s Rp %s — %s", t.CreatedAt.Format("02 Jan"), sign, formatPrice(abs(t.Amount)), walletTxLabel(t)))
+	}
+	if len(lines) > 0 {
+		reply += "\n\n*Transaksi terakhir:*\n" + strings.Join(lines, "\n")
+	}
+	reply += "\n\nKetik *isi saldo <nominal>* untuk menambah saldo."
+	return reply, true
+}
+
+// topUpGateway is the gateway top-ups are paid through: the sandbox gateway for sandbox tenants,
+// otherwise the default gateway (cash on delivery can't pay for credit)
+func (s *WalletService) topUpGateway(clientID string) (payment.Gateway, error) {
+	if s.orderService.sandboxSvc != nil && s.orderService.sandboxSvc.IsSandbox(clientID) && s.orderService.sandboxGateway != nil {
+		return s.orderService.sandboxGateway, nil
+	}
+	if s.orderService.paymentGateways.DefaultKey() == payment.GatewayCOD {
+		return nil, fmt.Errorf("top-ups need an online or manual payment gateway")
+	}
+	return s.orderService.paymentGateways.Default(), nil
+}
+
+// recordAudit writes an audit log entry for a manual adjustment, logging (not returning) failures
+func (s *WalletService) recordAudit(clientID uuid.UUID, actor string, wallet *models.CustomerWallet, transaction *models.WalletTransaction) {
+	if s.auditService == nil {
+		return
+	}
+
+	entry := &audit.AuditLog{
+		ClientID:    clientID,
+		Action:      "update",
+		Entity:      "wallet",
+		EntityID:    wallet.ID.String(),
+		Description: fmt.Sprintf("Wallet of %s adjusted by Rp %s: %s", wallet.CustomerPhone, strconv.FormatFloat(transaction.Amount, 'f', 2, 64), transaction.Note),
+	}
+	if userID, err := uuid.Parse(actor); err == nil {
+		entry.UserID = userID
+	}
+	if data, err := json.Marshal(map[string]interface{}{"balance": wallet.Balance}); err == nil {
+		entry.OldValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{"balance": *transaction.BalanceAfter}); err == nil {
+		entry.NewValue = datatypes.JSON(data)
+	}
+	if data, err := json.Marshal(map[string]interface{}{
+		"actor":          actor,
+		"customer_phone": wallet.CustomerPhone,
+		"transaction_id": transaction.ID,
+		"amount":         transaction.Amount,
+		"reason":         transaction.Note,
+	}); err == nil {
+		entry.Metadata = datatypes.JSON(data)
+	}
+
+	if err := s.auditService.Log(context.Background(), entry); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+// walletTxLabel describes a ledger entry to the customer
+func walletTxLabel(t models.WalletTransaction) string {
+	switch t.Type {
+	case models.WalletTxTopUp:
+		return "Isi saldo"
+	case models.WalletTxRefund:
+		return "Pengembalian dana"
+	case models.WalletTxAdjustment:
+		return "Penyesuaian"
+	}
+	return t.Note
+}
+
+// parseWalletAmount reads a rupiah amount typed in chat, e.g. "50000", "50.000", "50rb" or "1,5jt"
+func parseWalletAmount(number, unit string) (float64, bool) {
+	multiplier := 1.0
+	switch unit {
+	case "rb", "ribu", "k":
+		multiplier = 1000
+	case "jt", "juta":
+		multiplier = 1000000
+	}
+
+	if multiplier == 1 {
+		// Without a unit, dots and commas group thousands
+		number = strings.NewReplacer(".", "", ",", "").Replace(number)
+	} else {
+		number = strings.ReplaceAll(strings.ReplaceAll(number, ".", ""), ",", ".")
+	}
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil || amount <= 0 {
+		return 0, false
+	}
+	return roundAmount(amount * multiplier), true
+}
+
+func walletTopUpReference() string {
+	return fmt.Sprintf("%s%s-%s", WalletTopUpReferencePrefix, time.Now().Format("20060102"), strings.ToUpper(uuid.NewString()[:8]))
+}
+
+func abs(amount float64) float64 {
+	if amount < 0 {
+		return -amount
+	}
+	return amount
+}
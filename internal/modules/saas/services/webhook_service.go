@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,16 +11,22 @@ import (
 	"strings"
 	"time"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/kb"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/ocr"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/stt"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/config"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 	"gorm.io/datatypes"
 )
 
@@ -35,7 +42,38 @@ type WebhookService struct {
 	tenantResolver   *tenant.Resolver
 	cartService      *CartService
 	orderService     *OrderService
+	sandboxService   *SandboxService
+	storeService     *StoreService
+	deliveryService  *DeliveryService
+	waitlistService  *WaitlistService
+	quoteService     *QuoteService
+	mentionService   *ProductMentionService
+	kbSuggestionSvc  *KBSuggestionService
+	onboardingSvc    *CustomerOnboardingService
+	botPauseSvc      *BotPauseService
+	reactionSvc      *ReactionService
+	languageSvc      *LanguageService
+	slaSvc           *SLAService
+	tagSvc           *ConversationTagService
+	latencySvc       *LatencyService
+	memory           *ConversationMemory
+	catalogImages    *catalogImageLog
+	campaignSvc      *CampaignService
+	recommendSvc     *RecommendationService
+	sttService       *stt.Service
+	outbound         *OutboundMessageService
+	sessions         *whatsapp.SessionManager
+	walletSvc        *WalletService
+	featureSvc       *MessageFeatureService
+	documentSvc      *KBDocumentService
+	preferenceSvc    *CustomerPreferenceService
+	customerSvc      *CustomerService
+	workflowSvc      *WorkflowService
+	productService   *ProductService
+	adminCommandRepo repositories.AdminCommandRepo
+	auditService     *audit.Service
 	config           *config.Config
+	tasks            backgroundTasks // Message processing started by the webhook handlers
 }
 
 // NewWebhookService creates a new webhook service
@@ -50,6 +88,24 @@ func NewWebhookService(
 	tenantResolver *tenant.Resolver,
 	cartService *CartService,
 	orderService *OrderService,
+	sandboxService *SandboxService,
+	storeService *StoreService,
+	deliveryService *DeliveryService,
+	waitlistService *WaitlistService,
+	quoteService *QuoteService,
+	mentionService *ProductMentionService,
+	kbSuggestionSvc *KBSuggestionService,
+	onboardingSvc *CustomerOnboardingService,
+	botPauseSvc *BotPauseService,
+	reactionSvc *ReactionService,
+	languageSvc *LanguageService,
+	slaSvc *SLAService,
+	tagSvc *ConversationTagService,
+	latencySvc *LatencyService,
+	memory *ConversationMemory,
+	productService *ProductService,
+	adminCommandRepo repositories.AdminCommandRepo,
+	auditService *audit.Service,
 	cfg *config.Config,
 ) *WebhookService {
 	return &WebhookService{
@@ -63,22 +119,58 @@ func NewWebhookService(
 		tenantResolver:   tenantResolver,
 		cartService:      cartService,
 		orderService:     orderService,
+		sandboxService:   sandboxService,
+		storeService:     storeService,
+		deliveryService:  deliveryService,
+		waitlistService:  waitlistService,
+		quoteService:     quoteService,
+		mentionService:   mentionService,
+		kbSuggestionSvc:  kbSuggestionSvc,
+		onboardingSvc:    onboardingSvc,
+		botPauseSvc:      botPauseSvc,
+		reactionSvc:      reactionSvc,
+		languageSvc:      languageSvc,
+		slaSvc:           slaSvc,
+		tagSvc:           tagSvc,
+		latencySvc:       latencySvc,
+		memory:           memory,
+		catalogImages:    newCatalogImageLog(),
+		productService:   productService,
+		adminCommandRepo: adminCommandRepo,
+		auditService:     auditService,
 		config:           cfg,
 	}
 }
 
+// IsDuplicateMessage reports whether a webhook message was already received, e.g. a retried delivery or
+// one handled by another replica. IDs are scoped to the session, as two sessions can see the same message
+func (s *WebhookService) IsDuplicateMessage(sessionID, messageID string) bool {
+	if messageID == "" {
+		return false
+	}
+	return s.whatsappService.IsDuplicate(sessionID + ":" + messageID)
+}
+
 // ProcessTextMessage handles incoming text messages with AI chat
-func (s *WebhookService) ProcessTextMessage(sessionID, customerPhone, message string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+func (s *WebhookService) ProcessTextMessage(ctx context.Context, sessionID, customerPhone, message string, ref MessageRef) {
+	defer metrics.ObserveWebhookProcessing("text", time.Now())
+
+	ctx, span := tracing.Start(ctx, "message.process", attribute.String("message.type", "text"))
+	defer span.End()
+
+	// Processing outlives the webhook request it was received with
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
 	defer cancel()
 
 	log.Printf("🔄 Processing message from %s (session: %s): %s", customerPhone, sessionID, message)
 
 	// 1. Resolve tenant context (determine role, module, client)
-	tenantCtx, err := s.tenantResolver.ResolveFromPhone(customerPhone)
+	tenantCtx, err := s.resolveTenant(ctx, sessionID, customerPhone)
 	if err != nil {
 		log.Printf("❌ Failed to resolve tenant for %s: %v", customerPhone, err)
-		s.whatsappService.SendMessage(customerPhone, "Maaf, sistem sedang bermasalah. Silakan hubungi administrator.")
+		if !errors.Is(err, tenant.ErrSessionNotMapped) { // Dropped: no tenant may answer on that session
+			s.whatsappService.SendMessage(customerPhone, "Maaf, sistem sedang bermasalah. Silakan hubungi administrator.")
+		}
 		return
 	}
 
@@ -93,43 +185,279 @@ func (s *WebhookService) ProcessTextMessage(sessionID, customerPhone, message st
 
 	log.Printf("📋 Using client: %s (%s) [Role: %s]", client.BusinessName, client.ID.String(), tenantCtx.Role)
 
-	// Check if message is admin command (for admin_tenant or super_admin)
-	if tenantCtx.Role == "admin_tenant" || tenantCtx.Role == "super_admin" {
-		if handled := s.handleAdminCommand(ctx, client.ID.String(), customerPhone, message); handled {
+	s.recordCustomer(client, tenantCtx.Role, customerPhone)
+
+	// Staff commands keep working when AI replies to customers are turned off
+	if tenantCtx.Role == "customer" && !s.messageTypeAllowed(client, models.MessageFeatureText, customerPhone, message) {
+		return
+	}
+
+	s.respondToText(ctx, client, tenantCtx.Role, customerPhone, message, ref)
+}
+
+// resolveTenant resolves the client a message was received for from the receiving session, and the
+// sender's role in it, in a "tenant.resolve" span
+func (s *WebhookService) resolveTenant(ctx context.Context, sessionID, customerPhone string) (*tenant.TenantContext, error) {
+	_, span := tracing.Start(ctx, "tenant.resolve", attribute.String("whatsapp.session", sessionID))
+	tenantCtx, err := s.tenantResolver.ResolveFromSession(sessionID, customerPhone)
+	if err == nil {
+		span.SetAttributes(attribute.String("client.id", tenantCtx.ClientID), attribute.String("tenant.role", tenantCtx.Role))
+	}
+	tracing.End(span, err)
+	return tenantCtx, err
+}
+
+// ProcessSandboxMessage runs a simulated customer message through the bot for a client in sandbox mode
+func (s *WebhookService) ProcessSandboxMessage(clientID, customerPhone, message string) error {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return fmt.Errorf("client not found: %w", err)
+	}
+
+	if !client.SandboxMode {
+		return fmt.Errorf("client is not in sandbox mode")
+	}
+
+	if err := s.sandboxService.RecordInbound(clientID, customerPhone, message); err != nil {
+		return err
+	}
+	if !s.messageTypeAllowed(client, models.MessageFeatureText, customerPhone, message) {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	log.Printf("🧪 [TEST MODE] Processing simulated message from %s for client %s", customerPhone, clientID)
+	s.respondToText(ctx, client, "customer", customerPhone, message, MessageRef{})
+	return nil
+}
+
+// respondToText generates and sends the AI reply for a resolved client
+func (s *WebhookService) respondToText(ctx context.Context, client *models.Client, role, customerPhone, message string, ref MessageRef) {
+	// Check if message is an admin command (admins and staff, limited by role)
+	if role != "customer" {
+		if handled := s.handleAdminCommand(ctx, client.ID.String(), role, customerPhone, message); handled {
 			return // Command handled, don't process as regular message
 		}
 	}
 
-	// 2. Start typing indicator
-	if err := s.whatsappService.StartTyping(customerPhone); err != nil {
-		log.Printf("⚠️ Failed to start typing indicator: %v", err)
-	} else {
-		log.Printf("⌨️ Typing indicator started for %s", customerPhone)
+	// Delivery drivers update their shipments with keyword replies ("jemput", "selesai")
+	if s.deliveryService != nil && s.deliveryService.HandleDriverReply(client.ID.String(), customerPhone, message) {
+		return
+	}
+
+	// Customer messages open or extend an SLA thread (also while the bot is paused, when agents must answer)
+	if s.slaSvc != nil && role == "customer" {
+		s.slaSvc.RecordCustomerMessage(client, customerPhone)
 	}
 
-	// Ensure typing stops when function exits
-	defer func() {
-		if err := s.whatsappService.StopTyping(customerPhone); err != nil {
-			log.Printf("⚠️ Failed to stop typing indicator: %v", err)
+	// Tag the chat by the client's tag keywords (e.g. "rusak" -> komplain)
+	if s.tagSvc != nil && role == "customer" && !client.SandboxMode {
+		go s.tagSvc.AutoTag(client.ID, customerPhone, message)
+	}
+
+	// Vacation mode: no automated answers, customers get an away message once
+	if s.botPauseSvc != nil && s.botPauseSvc.IsPaused(client) {
+		reply, ok := s.botPauseSvc.AwayReply(client, customerPhone)
+		if ok {
+			s.sendMessage(client.ID.String(), customerPhone, reply)
+		}
+		log.Printf("⏸️  Bot paused for client %s, not answering %s", client.ID, customerPhone)
+		if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, reply); err != nil {
+			log.Printf("⚠️ Failed to log conversation: %v", err)
 		}
-	}()
+		return
+	}
+
+	// Every path below answers the customer, which counts as the bot's response for SLA tracking
+	answered := true
+	if s.slaSvc != nil && role == "customer" {
+		defer func() {
+			if answered {
+				s.slaSvc.RecordResponse(client, customerPhone, "")
+			}
+		}()
+	}
+
+	// First-time customers go through the tenant's onboarding flow (welcome, language, consent, quick menu)
+	if s.onboardingSvc != nil && role == "customer" {
+		result := s.onboardingSvc.Handle(client, customerPhone, message)
+		if result.Reply != "" {
+			s.sendMessage(client.ID.String(), customerPhone, result.Reply)
+		}
+		if result.Handled {
+			if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, result.Reply); err != nil {
+				log.Printf("⚠️ Failed to log conversation: %v", err)
+			}
+			return
+		}
+		message = result.Message
+	}
+
+	// Customer opts in to a restock alert offered for an out-of-stock product
+	if s.waitlistService != nil {
+		if reply, ok := s.waitlistService.HandleOptIn(client.ID.String(), customerPhone, message); ok {
+			s.sendMessage(client.ID.String(), customerPhone, reply)
+			if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, reply); err != nil {
+				log.Printf("⚠️ Failed to log conversation: %v", err)
+			}
+			return
+		}
+	}
+
+	// Customer accepts or rejects a pending quote ("terima" / "tolak")
+	if s.quoteService != nil {
+		if reply, ok := s.quoteService.HandleCustomerReply(client.ID.String(), customerPhone, message); ok {
+			s.sendMessage(client.ID.String(), customerPhone, reply)
+			if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, reply); err != nil {
+				log.Printf("⚠️ Failed to log conversation: %v", err)
+			}
+			return
+		}
+	}
+
+	// Customer confirms a cash on delivery order ("YA COD")
+	if s.orderService != nil {
+		if reply, ok := s.orderService.HandleCODReply(client.ID.String(), customerPhone, message); ok {
+			s.sendMessage(client.ID.String(), customerPhone, reply)
+			if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, reply); err != nil {
+				log.Printf("⚠️ Failed to log conversation: %v", err)
+			}
+			return
+		}
+	}
+
+	// Customer checks their prepaid balance ("saldo") or tops it up ("isi saldo 50000")
+	if role == "customer" && s.handleWalletMessage(client.ID.String(), customerPhone, message) {
+		return
+	}
+
+	// Customer taps a cart button ("Lihat Keranjang", "Checkout", "Bicara dengan Admin")
+	if role == "customer" && s.handleQuickReply(client, customerPhone, message) {
+		return
+	}
+
+	// Keyword workflows built by the tenant, which can replace the AI reply
+	if role == "customer" && s.runMessageWorkflows(ctx, client, workflow.MessageStageBeforeLLM, customerPhone, message, "") {
+		return
+	}
+
+	// Track which products customers ask about (sandbox chats are excluded from analytics)
+	if s.mentionService != nil && !client.SandboxMode {
+		go s.mentionService.Record(client.ID, customerPhone, message)
+	}
+
+	// Answer "toko di mana?" directly from the store list
+	if IsStoreLocatorQuery(message) {
+		if reply, ok := s.replyStoreList(client, customerPhone); ok {
+			if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, reply); err != nil {
+				log.Printf("⚠️ Failed to log conversation: %v", err)
+			}
+			return
+		}
+	}
+
+	// 2. Start typing indicator (not shown for simulated sandbox chats)
+	if !client.SandboxMode {
+		if err := s.whatsappService.StartTyping(customerPhone); err != nil {
+			log.Printf("⚠️ Failed to start typing indicator: %v", err)
+		} else {
+			log.Printf("⌨️ Typing indicator started for %s", customerPhone)
+		}
+
+		// Ensure typing stops when function exits
+		defer func() {
+			if err := s.whatsappService.StopTyping(customerPhone); err != nil {
+				log.Printf("⚠️ Failed to stop typing indicator: %v", err)
+			}
+		}()
+	}
 
 	// 3. Retrieve knowledge base for this client
+	kbCtx, kbSpan := tracing.Start(ctx, "kb.retrieve")
 	knowledgeBase, err := s.kbRetriever.GetKnowledgeBase(client.ID.String())
 	if err != nil {
+		tracing.Fail(kbSpan, err)
 		log.Printf("⚠️ Failed to get knowledge base: %v", err)
 		knowledgeBase = &llm.KnowledgeBase{
-			BusinessName: client.BusinessName,
-			Tone:         client.Tone,
+			BusinessName:   client.BusinessName,
+			Tone:           client.Tone,
+			PromptTemplate: client.AIPromptTemplate,
 		}
 	}
 
+	// Orders go through function calling when the client's model supports it, instead of cart commands
+	var tools *toolTurn
+	if s.llmService.SupportsTools(llm.WithUsage(ctx, client.ID.String(), models.UsageFeatureChat)) {
+		tools = s.newToolTurn(client.ID.String(), customerPhone, knowledgeBase.Products)
+		knowledgeBase.ToolCalling = true
+	}
+
 	// 4. Build system prompt with knowledge base
 	systemPrompt := llm.BuildSystemPrompt(knowledgeBase)
 
-	// 5. Call LLM to generate response
-	log.Printf("🤖 Calling LLM: %s", s.llmService.GetProviderName())
-	aiResponse, err := s.llmService.GenerateResponse(ctx, systemPrompt, message)
+	// Passages of uploaded documents relevant to the question
+	if s.documentSvc != nil {
+		systemPrompt += s.documentSvc.PromptContext(kbCtx, client.ID, message)
+	}
+	kbSpan.End()
+
+	// What this customer told us they prefer, and how to record new preferences
+	var preference *models.CustomerPreference
+	if s.preferenceSvc != nil {
+		preference = s.preferenceSvc.Preferences(client.ID, customerPhone)
+		systemPrompt += s.preferenceSvc.PromptContext(preference)
+	}
+
+	// Answer in the customer's language: detected from the message, or picked during onboarding
+	replyLang, detectedLang := "", llm.DetectLanguage(message)
+	if s.onboardingSvc != nil {
+		replyLang = s.onboardingSvc.CustomerLanguage(client.ID.String(), customerPhone)
+	}
+	if preference != nil && preference.Language != "" {
+		replyLang = preference.Language // A language the customer asked for overrides the onboarding choice
+	}
+	if s.languageSvc != nil {
+		replyLang, detectedLang = s.languageSvc.ReplyLanguage(client.ID.String(), message, replyLang)
+	}
+	systemPrompt += llm.LanguageInstruction(replyLang)
+
+	// A quoted reply is answered in the context of the message it quotes
+	systemPrompt += quotedContextPrompt(s.lookupQuoted(client.ID.String(), ref), client.Timezone)
+
+	// Earlier exchanges with this customer, so follow-up questions are understood
+	history := s.memory.History(client.ID.String(), customerPhone)
+
+	// 5. Call LLM to generate response, within the client's latency budget
+	log.Printf("🤖 Calling LLM: %s (%d history messages)", s.llmService.GetProviderName(), len(history))
+	aiResponse, outcome, err := s.generateWithinBudget(ctx, client, customerPhone, systemPrompt, history, message, knowledgeBase, tools)
+	if outcome != budgetLLM {
+		// Degraded reply: sent as is, without cart commands or translation (both need the LLM)
+		if outcome == budgetHandover {
+			answered = false // An agent still owes the first response
+		}
+		outboundID, err := s.sendReply(ctx, client.ID.String(), customerPhone, aiResponse, ref.threadID())
+		if err != nil {
+			log.Printf("❌ Failed to send WhatsApp message: %v", err)
+			answered = false
+			return
+		}
+		turn := &models.Conversation{
+			ClientID:          client.ID,
+			CustomerPhone:     customerPhone,
+			MessageText:       message,
+			AIResponse:        aiResponse,
+			Language:          detectedLang,
+			InboundMessageID:  ref.ID,
+			OutboundMessageID: outboundID,
+			ReplyToMessageID:  ref.ReplyToID,
+		}
+		if err := s.conversationRepo.LogTurn(turn); err != nil {
+			log.Printf("⚠️ Failed to log conversation: %v", err)
+		}
+		return
+	}
 	if err != nil {
 		log.Printf("❌ LLM error (%s): %v", s.llmService.GetProviderName(), err)
 		aiResponse = "Maaf, saya sedang mengalami gangguan. Silakan coba lagi nanti."
@@ -139,22 +467,56 @@ func (s *WebhookService) ProcessTextMessage(sessionID, customerPhone, message st
 
 	// 6. Parse cart commands from AI response
 	cleanResponse, commands := s.parseCartCommands(aiResponse)
+	if tools != nil {
+		commands = withoutToolCommands(commands)
+	}
+	if s.languageSvc != nil && err == nil { // the fallback error message is not translated
+		cleanResponse = s.languageSvc.MatchResponse(ctx, client.ID.String(), cleanResponse, replyLang)
+	}
 
-	// 7. Send clean response back via WhatsApp (without commands)
-	if err := s.whatsappService.SendMessage(customerPhone, cleanResponse); err != nil {
+	// 7. Send clean response back via WhatsApp (without commands), threaded under the customer's message when they quoted one
+	outboundID, err := s.sendReply(ctx, client.ID.String(), customerPhone, cleanResponse, ref.threadID())
+	if err != nil {
 		log.Printf("❌ Failed to send WhatsApp message: %v", err)
+		answered = false
 		return
 	}
 
 	log.Printf("✅ Message sent to %s", customerPhone)
 
+	// Photos of the products the customer asked about, captioned with price and stock
+	s.sendProductImages(client, customerPhone, message)
+
+	// Keyword workflows that follow up on the AI reply
+	if role == "customer" {
+		s.runMessageWorkflows(ctx, client, workflow.MessageStageAfterLLM, customerPhone, message, cleanResponse)
+	}
+
 	// 8. Execute cart commands if any
 	if len(commands) > 0 {
-		s.executeCartCommands(ctx, client.ID.String(), customerPhone, commands, knowledgeBase.Products)
+		s.executeCartCommands(ctx, client.ID.String(), customerPhone, message, commands, knowledgeBase.Products)
+	}
+	if tools != nil {
+		s.recommendAfterTools(ctx, tools, message)
+	}
+
+	// Questions the bot could not answer feed the KB suggestion pipeline
+	if s.kbSuggestionSvc != nil && !client.SandboxMode && IsUnansweredResponse(cleanResponse) {
+		s.kbSuggestionSvc.RecordUnanswered(client.ID, customerPhone, message, cleanResponse)
 	}
 
 	// 9. Log conversation to database
-	if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, cleanResponse); err != nil {
+	turn := &models.Conversation{
+		ClientID:          client.ID,
+		CustomerPhone:     customerPhone,
+		MessageText:       message,
+		AIResponse:        cleanResponse,
+		Language:          detectedLang,
+		InboundMessageID:  ref.ID,
+		OutboundMessageID: outboundID,
+		ReplyToMessageID:  ref.ReplyToID,
+	}
+	if err := s.conversationRepo.LogTurn(turn); err != nil {
 		log.Printf("⚠️ Failed to log conversation: %v", err)
 	}
 
@@ -162,17 +524,24 @@ func (s *WebhookService) ProcessTextMessage(sessionID, customerPhone, message st
 }
 
 // ProcessImageMessage handles incoming image messages for OCR processing
-func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL string) {
-	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+func (s *WebhookService) ProcessImageMessage(ctx context.Context, sessionID, customerPhone, mediaURL string) {
+	defer metrics.ObserveWebhookProcessing("image", time.Now())
+
+	ctx, span := tracing.Start(ctx, "message.process", attribute.String("message.type", "image"))
+	defer span.End()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 60*time.Second)
 	defer cancel()
 
 	log.Printf("📸 Processing image from %s (session: %s): %s", customerPhone, sessionID, mediaURL)
 
 	// 1. Resolve tenant context
-	tenantCtx, err := s.tenantResolver.ResolveFromPhone(customerPhone)
+	tenantCtx, err := s.resolveTenant(ctx, sessionID, customerPhone)
 	if err != nil {
 		log.Printf("❌ Failed to resolve tenant for %s: %v", customerPhone, err)
-		s.whatsappService.SendMessage(customerPhone, "Maaf, sistem sedang bermasalah. Silakan hubungi administrator.")
+		if !errors.Is(err, tenant.ErrSessionNotMapped) { // Dropped: no tenant may answer on that session
+			s.whatsappService.SendMessage(customerPhone, "Maaf, sistem sedang bermasalah. Silakan hubungi administrator.")
+		}
 		return
 	}
 
@@ -187,23 +556,31 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 
 	log.Printf("📋 Using client: %s (%s) [Role: %s]", client.BusinessName, client.ID.String(), tenantCtx.Role)
 
-	// 2. Start typing indicator
-	if err := s.whatsappService.StartTyping(customerPhone); err != nil {
-		log.Printf("⚠️ Failed to start typing indicator: %v", err)
+	s.recordCustomer(client, tenantCtx.Role, customerPhone)
+
+	if !s.messageTypeAllowed(client, models.MessageFeatureImage, customerPhone, "[Gambar] "+mediaURL) {
+		return
 	}
 
-	defer func() {
-		if err := s.whatsappService.StopTyping(customerPhone); err != nil {
-			log.Printf("⚠️ Failed to stop typing indicator: %v", err)
+	// 2. Start typing indicator (not shown for simulated sandbox chats)
+	if !client.SandboxMode {
+		if err := s.whatsappService.StartTyping(customerPhone); err != nil {
+			log.Printf("⚠️ Failed to start typing indicator: %v", err)
 		}
-	}()
+
+		defer func() {
+			if err := s.whatsappService.StopTyping(customerPhone); err != nil {
+				log.Printf("⚠️ Failed to stop typing indicator: %v", err)
+			}
+		}()
+	}
 
 	// 3. Download image from WhatsApp media URL
 	log.Printf("⬇️ Downloading image from: %s", mediaURL)
-	imageData, err := s.downloadImage(mediaURL)
+	imageData, err := s.downloadMedia(mediaURL)
 	if err != nil {
 		log.Printf("❌ Failed to download image: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "❌ Maaf, gagal mengunduh gambar. Pastikan gambar terkirim dengan baik.")
+		s.sendMessage(client.ID.String(), customerPhone, "❌ Maaf, gagal mengunduh gambar. Pastikan gambar terkirim dengan baik.")
 		return
 	}
 
@@ -214,18 +591,18 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 	ocrResult, err := s.ocrService.ExtractText(ctx, imageData)
 	if err != nil {
 		log.Printf("❌ OCR extraction failed: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "❌ Maaf, gagal membaca teks dari gambar. Pastikan foto struk jelas dan tidak buram.")
+		s.sendMessage(client.ID.String(), customerPhone, "❌ Maaf, gagal membaca teks dari gambar. Pastikan foto struk jelas dan tidak buram.")
 		return
 	}
 
-	log.Printf("✅ OCR extracted text (confidence: %.2f%%): %s", ocrResult.Confidence*100, ocrResult.Text)
+	log.Printf("✅ OCR extracted %d chars (confidence: %.2f%%)", len(ocrResult.Text), ocrResult.Confidence*100)
 
 	// 5. Parse receipt data using LLM (much more accurate than regex)
 	llmParser := ocr.NewLLMParser(s.llmService)
-	receiptData, err := llmParser.ParseReceiptWithLLM(ctx, ocrResult.Text)
+	receiptData, err := llmParser.ParseReceiptWithLLM(llm.WithUsage(ctx, client.ID.String(), models.UsageFeatureReceipt), ocrResult.Text)
 	if err != nil {
 		log.Printf("❌ Failed to parse receipt: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "❌ Maaf, gagal memproses data struk. Silakan coba lagi dengan foto yang lebih jelas.")
+		s.sendMessage(client.ID.String(), customerPhone, "❌ Maaf, gagal memproses data struk. Silakan coba lagi dengan foto yang lebih jelas.")
 		return
 	}
 
@@ -236,7 +613,7 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 	itemsJSON, err := json.Marshal(receiptData.Items)
 	if err != nil {
 		log.Printf("❌ Failed to marshal items: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "❌ Maaf, terjadi kesalahan saat menyimpan data.")
+		s.sendMessage(client.ID.String(), customerPhone, "❌ Maaf, terjadi kesalahan saat menyimpan data.")
 		return
 	}
 
@@ -250,12 +627,12 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 		CreatedFrom:     "ocr",
 		SourceType:      "receipt",
 		OCRConfidence:   &ocrResult.Confidence,
-		OCRRawText:      ocrResult.Text,
+		OCRRawText:      OCRRawTextForStorage(client, ocrResult.Text),
 	}
 
 	if err := s.transactionRepo.Create(transaction); err != nil {
 		log.Printf("❌ Failed to save transaction: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "❌ Maaf, gagal menyimpan transaksi ke database.")
+		s.sendMessage(client.ID.String(), customerPhone, "❌ Maaf, gagal menyimpan transaksi ke database.")
 		return
 	}
 
@@ -263,7 +640,7 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 
 	// 8. Send success response to user
 	responseMessage := s.buildReceiptResponseMessage(transaction, receiptData)
-	if err := s.whatsappService.SendMessage(customerPhone, responseMessage); err != nil {
+	if err := s.sendMessage(client.ID.String(), customerPhone, responseMessage); err != nil {
 		log.Printf("❌ Failed to send response: %v", err)
 		return
 	}
@@ -271,8 +648,8 @@ func (s *WebhookService) ProcessImageMessage(sessionID, customerPhone, mediaURL
 	log.Printf("✅ Response sent to %s", customerPhone)
 }
 
-// downloadImage downloads image from WhatsApp media URL
-func (s *WebhookService) downloadImage(mediaURL string) ([]byte, error) {
+// downloadMedia downloads an image or voice note from a WhatsApp media URL
+func (s *WebhookService) downloadMedia(mediaURL string) ([]byte, error) {
 	// Create HTTP request
 	req, err := http.NewRequest("GET", mediaURL, nil)
 	if err != nil {
@@ -360,9 +737,11 @@ func formatCurrency(amount float64) string {
 
 // CartCommand represents a cart operation command
 type CartCommand struct {
-	Action      string // ADD_TO_CART, VIEW_CART, CHECKOUT
+	Action      string // ADD_TO_CART, VIEW_CART, CHECKOUT, CHECKOUT_COD, REQUEST_QUOTE, SET_PREFERENCE
 	ProductName string
 	Quantity    int
+	Key         string // SET_PREFERENCE: preference key
+	Value       string // SET_PREFERENCE: preference value
 }
 
 // parseCartCommands extracts cart commands from AI response
@@ -399,6 +778,23 @@ func (s *WebhookService) parseCartCommands(aiResponse string) (string, []CartCom
 		} else if trimmed == "[CHECKOUT]" {
 			commands = append(commands, CartCommand{Action: "CHECKOUT"})
 			log.Printf("🛒 Parsed CHECKOUT command")
+		} else if trimmed == "[CHECKOUT_COD]" {
+			commands = append(commands, CartCommand{Action: "CHECKOUT_COD"})
+			log.Printf("🛒 Parsed CHECKOUT_COD command")
+		} else if trimmed == "[REQUEST_QUOTE]" {
+			commands = append(commands, CartCommand{Action: "REQUEST_QUOTE"})
+			log.Printf("📝 Parsed REQUEST_QUOTE command")
+		} else if strings.HasPrefix(trimmed, "[SET_PREFERENCE:") && strings.HasSuffix(trimmed, "]") {
+			// Extract: [SET_PREFERENCE:key|value]
+			content := strings.TrimSuffix(strings.TrimPrefix(trimmed, "[SET_PREFERENCE:"), "]")
+			if key, value, ok := strings.Cut(content, "|"); ok {
+				commands = append(commands, CartCommand{
+					Action: "SET_PREFERENCE",
+					Key:    strings.ToLower(strings.TrimSpace(key)),
+					Value:  strings.TrimSpace(value),
+				})
+				log.Printf("📌 Parsed SET_PREFERENCE command: %s", key)
+			}
 		} else {
 			// Not a command, keep in clean response
 			cleanLines = append(cleanLines, line)
@@ -412,23 +808,43 @@ func (s *WebhookService) parseCartCommands(aiResponse string) (string, []CartCom
 }
 
 // executeCartCommands processes cart commands
-func (s *WebhookService) executeCartCommands(ctx context.Context, clientID, customerPhone string, commands []CartCommand, products []llm.Product) {
+func (s *WebhookService) executeCartCommands(ctx context.Context, clientID, customerPhone, message string, commands []CartCommand, products []llm.Product) {
+	var lastAdded string
+	var order *models.Order
 	for _, cmd := range commands {
 		switch cmd.Action {
 		case "ADD_TO_CART":
-			s.handleAddToCart(clientID, customerPhone, cmd.ProductName, cmd.Quantity, products)
+			if s.handleAddToCart(clientID, customerPhone, cmd.ProductName, cmd.Quantity, products) {
+				lastAdded = cmd.ProductName
+			}
 
 		case "VIEW_CART":
 			s.handleViewCart(clientID, customerPhone)
 
 		case "CHECKOUT":
-			s.handleCheckout(clientID, customerPhone)
+			order = s.handleCheckout(clientID, customerPhone, "")
+
+		case "CHECKOUT_COD":
+			order = s.handleCheckout(clientID, customerPhone, payment.MethodCOD)
+
+		case "REQUEST_QUOTE":
+			s.handleRequestQuote(clientID, customerPhone)
+
+		case "SET_PREFERENCE":
+			s.handleSetPreference(clientID, customerPhone, cmd.Key, cmd.Value)
 		}
 	}
+
+	// Complementary products are suggested once the cart commands are done
+	if order != nil {
+		s.recommendAfterCheckout(ctx, order, message, products)
+	} else if lastAdded != "" {
+		s.recommendAfterAddToCart(ctx, clientID, customerPhone, lastAdded, message, products)
+	}
 }
 
-// handleAddToCart adds item to cart
-func (s *WebhookService) handleAddToCart(clientID, customerPhone, productName string, quantity int, products []llm.Product) {
+// handleAddToCart adds item to cart, reporting whether it was added
+func (s *WebhookService) handleAddToCart(clientID, customerPhone, productName string, quantity int, products []llm.Product) bool {
 	// Find product price from knowledge base
 	var productPrice float64
 	for _, p := range products {
@@ -440,8 +856,8 @@ func (s *WebhookService) handleAddToCart(clientID, customerPhone, productName st
 
 	if productPrice == 0 {
 		log.Printf("⚠️  Product not found in knowledge base: %s", productName)
-		s.whatsappService.SendMessage(customerPhone, fmt.Sprintf("Maaf, produk '%s' tidak ditemukan dalam katalog.", productName))
-		return
+		s.sendMessage(clientID, customerPhone, fmt.Sprintf("Maaf, produk '%s' tidak ditemukan dalam katalog.", productName))
+		return false
 	}
 
 	// Add to cart
@@ -454,25 +870,50 @@ func (s *WebhookService) handleAddToCart(clientID, customerPhone, productName st
 		Price:         productPrice,
 	}
 
+	// Out-of-stock catalog products are pre-ordered or offered a restock alert
+	var preorderNote string
+	if s.waitlistService != nil {
+		if product, ok := s.waitlistService.FindProduct(clientID, productName); ok && !product.IsAvailable() {
+			if !product.CanPreorder() {
+				s.sendMessage(clientID, customerPhone, s.waitlistService.OfferRestockAlert(product, customerPhone, quantity))
+				return false
+			}
+			preorderNote = PreorderNote(product)
+			req.ProductID = product.ID.String()
+			req.Notes = preorderNote
+			s.waitlistService.RecordPreorder(product, customerPhone, quantity)
+		}
+	}
+
 	cart, err := s.cartService.AddToCart(req)
 	if err != nil {
 		log.Printf("❌ Failed to add to cart: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "Maaf, terjadi kesalahan saat menambahkan ke keranjang.")
-		return
+		s.sendMessage(clientID, customerPhone, "Maaf, terjadi kesalahan saat menambahkan ke keranjang.")
+		return false
 	}
 
 	log.Printf("✅ Added %s x%d to cart for %s", productName, quantity, customerPhone)
 
-	// Send confirmation
+	if s.recommendSvc != nil {
+		s.recommendSvc.TrackAddToCart(clientID, customerPhone, productName)
+	}
+
+	// Send confirmation with the next steps as buttons
 	message := fmt.Sprintf(
 		"✅ *Berhasil ditambahkan!*\n\n"+
 			"🛒 Total item di keranjang: %d\n"+
-			"💰 Total belanja: Rp %s\n\n"+
-			"Ketik 'checkout' untuk lanjut pembayaran atau 'lihat keranjang' untuk cek pesanan.",
+			"💰 Total belanja: Rp %s",
 		len(cart.Items),
 		formatCurrency(cart.TotalAmount),
 	)
-	s.whatsappService.SendMessage(customerPhone, message)
+	if preorderNote != "" {
+		message = fmt.Sprintf("📦 *%s* (%s)\n\n", productName, preorderNote) + message
+	}
+	s.sendButtons(clientID, customerPhone, whatsapp.ButtonMessage{
+		Body:    message,
+		Buttons: []whatsapp.Button{viewCartButton, checkoutButton, talkToHumanButton},
+	})
+	return true
 }
 
 // handleViewCart shows cart contents
@@ -480,12 +921,12 @@ func (s *WebhookService) handleViewCart(clientID, customerPhone string) {
 	cart, err := s.cartService.ViewCart(clientID, customerPhone)
 	if err != nil {
 		log.Printf("⚠️  No cart found: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "Keranjang Anda masih kosong. Yuk pesan sesuatu! 😊")
+		s.sendMessage(clientID, customerPhone, "Keranjang Anda masih kosong. Yuk pesan sesuatu! 😊")
 		return
 	}
 
 	if cart.IsEmpty() {
-		s.whatsappService.SendMessage(customerPhone, "Keranjang Anda masih kosong. Yuk pesan sesuatu! 😊")
+		s.sendMessage(clientID, customerPhone, "Keranjang Anda masih kosong. Yuk pesan sesuatu! 😊")
 		return
 	}
 
@@ -502,32 +943,54 @@ func (s *WebhookService) handleViewCart(clientID, customerPhone string) {
 		))
 	}
 
-	msg.WriteString(fmt.Sprintf("💰 *Total: Rp %s*\n\n", formatCurrency(cart.TotalAmount)))
-	msg.WriteString("Ketik 'checkout' untuk lanjut pembayaran.")
+	msg.WriteString(fmt.Sprintf("💰 *Total: Rp %s*", formatCurrency(cart.TotalAmount)))
 
-	s.whatsappService.SendMessage(customerPhone, msg.String())
+	s.sendButtons(clientID, customerPhone, whatsapp.ButtonMessage{
+		Body:    msg.String(),
+		Buttons: []whatsapp.Button{checkoutButton, talkToHumanButton},
+	})
 }
 
-// handleCheckout processes checkout
-func (s *WebhookService) handleCheckout(clientID, customerPhone string) {
+// handleRequestQuote sends the customer a quote for their cart instead of checking out
+func (s *WebhookService) handleRequestQuote(clientID, customerPhone string) {
+	if s.quoteService == nil {
+		s.handleCheckout(clientID, customerPhone, "")
+		return
+	}
+
+	quote, err := s.quoteService.RequestQuoteFromCart(clientID, customerPhone)
+	if err != nil {
+		log.Printf("⚠️  Failed to create quote for %s: %v", customerPhone, err)
+		s.sendMessage(clientID, customerPhone, "Keranjang Anda masih kosong. Tambahkan produk terlebih dahulu untuk meminta penawaran.")
+		return
+	}
+
+	log.Printf("✅ Quote created from cart: %s", quote.QuoteNumber)
+}
+
+// handleCheckout processes checkout (paymentMethod is "cod" for cash on delivery, empty otherwise)
+func (s *WebhookService) handleCheckout(clientID, customerPhone, paymentMethod string) *models.Order {
 	// Get cart
 	cart, err := s.cartService.ViewCart(clientID, customerPhone)
 	if err != nil {
 		log.Printf("⚠️  No cart found: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "Keranjang Anda masih kosong. Silakan pesan terlebih dahulu.")
-		return
+		s.sendMessage(clientID, customerPhone, "Keranjang Anda masih kosong. Silakan pesan terlebih dahulu.")
+		return nil
 	}
 
 	if cart.IsEmpty() {
-		s.whatsappService.SendMessage(customerPhone, "Keranjang Anda masih kosong. Silakan pesan terlebih dahulu.")
-		return
+		s.sendMessage(clientID, customerPhone, "Keranjang Anda masih kosong. Silakan pesan terlebih dahulu.")
+		return nil
 	}
 
 	// Convert cart items to payment.OrderItem format
 	orderItems := make([]payment.OrderItem, len(cart.Items))
 	for i, item := range cart.Items {
-		// Generate UUID from product ID string
-		productUUID := uuid.MustParse("00000000-0000-0000-0000-000000000000") // Placeholder
+		// Product IDs from the catalog are UUIDs; anything else gets a placeholder
+		productUUID, err := uuid.Parse(item.ProductID)
+		if err != nil {
+			productUUID = uuid.Nil
+		}
 		variantUUID := uuid.MustParse("00000000-0000-0000-0000-000000000000") // Placeholder
 
 		orderItems[i] = payment.OrderItem{
@@ -548,13 +1011,48 @@ func (s *WebhookService) handleCheckout(clientID, customerPhone string) {
 		CustomerName:  customerPhone, // Use phone as name for now
 		Items:         orderItems,
 		TotalAmount:   cart.TotalAmount,
+		PaymentMethod: paymentMethod,
+	}
+	if cart.BranchID != nil {
+		orderReq.BranchID = cart.BranchID.String()
+	}
+
+	// The customer's stored preferences fill in what the checkout command left open
+	preferredMethod := false
+	if s.preferenceSvc != nil {
+		preference := s.preferenceSvc.Preferences(cart.ClientID, customerPhone)
+		if orderReq.PaymentMethod == "" && preference.PaymentMethod != "" {
+			orderReq.PaymentMethod = preference.PaymentMethod
+			preferredMethod = true
+		}
+		orderReq.DeliveryNotes = preference.DeliveryNotes
 	}
 
 	order, paymentResult, err := s.orderService.CreateOrder(orderReq)
+	var codErr *CODEligibilityError
+	if preferredMethod && errors.As(err, &codErr) {
+		// A stored COD preference the customer no longer qualifies for falls back to online payment
+		log.Printf("⚠️  Preferred COD refused for %s, checking out online: %v", customerPhone, err)
+		orderReq.PaymentMethod = ""
+		order, paymentResult, err = s.orderService.CreateOrder(orderReq)
+	}
+	if errors.Is(err, ErrInsufficientBranchStock) {
+		log.Printf("⚠️  Checkout blocked for %s: %v", customerPhone, err)
+		s.sendMessage(clientID, customerPhone, "🙏 Maaf, stok untuk pesanan Anda tidak mencukupi di cabang kami. Silakan ubah jumlah pesanan atau pilih cabang lain.")
+		return nil
+	}
+	if errors.As(err, &codErr) {
+		log.Printf("⚠️  COD checkout refused for %s: %v", customerPhone, err)
+		s.sendButtons(clientID, customerPhone, whatsapp.ButtonMessage{
+			Body:    "🙏 Maaf, " + codErr.Reason + "\n\nPilih *Checkout* untuk lanjut dengan pembayaran online.",
+			Buttons: []whatsapp.Button{checkoutButton, talkToHumanButton},
+		})
+		return nil
+	}
 	if err != nil {
 		log.Printf("❌ Failed to create order: %v", err)
-		s.whatsappService.SendMessage(customerPhone, "Maaf, terjadi kesalahan saat memproses pesanan. Silakan coba lagi.")
-		return
+		s.sendMessage(clientID, customerPhone, "Maaf, terjadi kesalahan saat memproses pesanan. Silakan coba lagi.")
+		return nil
 	}
 
 	log.Printf("✅ Order created from cart: %s", order.OrderNumber)
@@ -567,4 +1065,21 @@ func (s *WebhookService) handleCheckout(clientID, customerPhone string) {
 
 	// Note: Notifications to tenant admin and super admin are automatically sent by OrderService.CreateOrder
 	_ = paymentResult // Payment result already handled in OrderService
+
+	if s.recommendSvc != nil && !order.IsTest {
+		s.recommendSvc.TrackOrder(order)
+	}
+	return order
+}
+
+// sendMessage sends a WhatsApp message for a client, captured instead of sent in sandbox mode
+func (s *WebhookService) sendMessage(clientID, to, message string) error {
+	if s.sandboxService != nil {
+		return s.sandboxService.SendMessage(clientID, to, message)
+	}
+	if s.sessions != nil {
+		_, err := s.sessions.SendMessage(clientID, to, message)
+		return err
+	}
+	return s.whatsappService.SendMessage(to, message)
 }
@@ -2,162 +2,635 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
-// handleAdminCommand processes admin commands from WhatsApp
+// adminCommandTTL is how long a command waits for the admin's YA
+const adminCommandTTL = 5 * time.Minute
+
+var orderNumberPattern = regexp.MustCompile(`^(TEST-)?ORD-\d{8}-\d+$`)
+
+// adminCommandRoles lists the roles allowed to run each admin command. Business owners (admin),
+// tenant admins and super admins may run everything; staff may only update stock and tag chats
+var adminCommandRoles = map[string][]string{
+	models.AdminCommandCancelOrder:    {"admin", "admin_tenant", "super_admin"},
+	models.AdminCommandConfirmPayment: {"admin", "admin_tenant", "super_admin"},
+	models.AdminCommandUpdateStock:    {"admin", "admin_tenant", "super_admin", "staff_tenant"},
+	"bot":                             {"admin", "admin_tenant", "super_admin"},
+	"tag":                             {"admin", "admin_tenant", "super_admin", "staff_tenant"},
+}
+
+func canRunAdminCommand(role, command string) bool {
+	for _, allowed := range adminCommandRoles[command] {
+		if role == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// adminCommandName maps the first word of a message (English or Indonesian) to an admin command
+func adminCommandName(message string) string {
+	fields := strings.Fields(strings.ToUpper(message))
+	if len(fields) == 0 {
+		return ""
+	}
+	switch fields[0] {
+	case "CANCEL", "BATALKAN":
+		return models.AdminCommandCancelOrder
+	case "CONFIRM", "KONFIRMASI":
+		return models.AdminCommandConfirmPayment
+	case "STOK", "STOCK":
+		return models.AdminCommandUpdateStock
+	case "BOT":
+		return "bot"
+	}
+	if isTagCommand(message) {
+		return "tag"
+	}
+	return ""
+}
+
+// handleAdminCommand processes admin and staff commands from WhatsApp.
 // Returns true if command was handled, false if message should be processed normally
-func (s *WebhookService) handleAdminCommand(ctx context.Context, clientID, adminPhone, message string) bool {
+func (s *WebhookService) handleAdminCommand(ctx context.Context, clientID, role, adminPhone, message string) bool {
 	message = strings.TrimSpace(message)
-	messageUpper := strings.ToUpper(message)
 
-	// Check for CANCEL command
-	// Format: CANCEL ORD-20251130-5863 Stok habis
-	if strings.HasPrefix(messageUpper, "CANCEL ") {
-		s.handleCancelCommand(adminPhone, message)
-		return true
+	// YA / TIDAK answers a command waiting for confirmation
+	switch strings.ToUpper(message) {
+	case "YA", "Y", "YES":
+		return s.resolvePendingCommand(clientID, adminPhone, true)
+	case "TIDAK", "NO", "BATAL":
+		return s.resolvePendingCommand(clientID, adminPhone, false)
 	}
 
-	// Check for CONFIRM command
-	// Format: CONFIRM ORD-20251130-5863 transfer TRF123456
-	if strings.HasPrefix(messageUpper, "CONFIRM ") {
-		s.handleConfirmCommand(adminPhone, message)
+	command := adminCommandName(message)
+	if command == "" || (command == "bot" && s.botPauseSvc == nil) || (command == "tag" && s.tagSvc == nil) {
+		// Not an admin command
+		return false
+	}
+
+	if !canRunAdminCommand(role, command) {
+		log.Printf("🚫 %s (%s) is not allowed to run %s", adminPhone, role, command)
+		s.recordAdminAudit(clientID, adminPhone, role, command, "command", "", nil, nil,
+			"Admin command denied", map[string]interface{}{"status": "denied", "message": message})
+		s.sendMessage(clientID, adminPhone, "🚫 Anda tidak memiliki akses untuk perintah ini.")
 		return true
 	}
 
-	// Not an admin command
-	return false
+	switch command {
+	case models.AdminCommandCancelOrder:
+		// Format: BATALKAN ORD-20251130-5863 Stok habis
+		s.handleCancelCommand(clientID, role, adminPhone, message)
+	case models.AdminCommandConfirmPayment:
+		// Format: KONFIRMASI ORD-20251130-5863 [transfer TRF123456]
+		s.handleConfirmCommand(clientID, role, adminPhone, message)
+	case models.AdminCommandUpdateStock:
+		// Format: STOK Kopi Susu 20, STOK KOPI-01 +5
+		s.handleStockCommand(clientID, role, adminPhone, message)
+	case "bot":
+		// Format: BOT OFF [sampai 25/04], BOT ON, BOT STATUS
+		s.handleBotCommand(clientID, adminPhone, message)
+	case "tag":
+		// Format: TAG 08123456789 komplain, UNTAG 08123456789 komplain, TAGS [nomor]
+		s.handleTagCommand(clientID, adminPhone, message)
+	}
+	return true
 }
 
-// handleCancelCommand processes order cancellation
-// Format: CANCEL ORD-20251130-5863 Stok habis
-func (s *WebhookService) handleCancelCommand(adminPhone, message string) {
-	// Parse: CANCEL <order-number> <reason>
+// handleCancelCommand asks the admin to confirm an order cancellation
+// Format: BATALKAN ORD-20251130-5863 Stok habis (or CANCEL ...)
+func (s *WebhookService) handleCancelCommand(clientID, role, adminPhone, message string) {
 	parts := strings.SplitN(message, " ", 3)
 
 	if len(parts) < 2 {
-		s.whatsappService.SendMessage(adminPhone,
+		s.sendMessage(clientID, adminPhone,
 			"❌ Format salah!\n\n"+
-			"Gunakan:\n"+
-			"CANCEL <order-number> <alasan>\n\n"+
-			"Contoh:\n"+
-			"CANCEL ORD-20251130-5863 Stok habis")
+				"Gunakan:\n"+
+				"BATALKAN <order-number> <alasan>\n\n"+
+				"Contoh:\n"+
+				"BATALKAN ORD-20251130-5863 Stok habis")
 		return
 	}
 
-	orderNumber := strings.TrimSpace(parts[1])
 	reason := "Dibatalkan oleh admin"
-	if len(parts) == 3 {
+	if len(parts) == 3 && strings.TrimSpace(parts[2]) != "" {
 		reason = strings.TrimSpace(parts[2])
 	}
 
-	// Validate order number format
-	orderPattern := regexp.MustCompile(`^ORD-\d{8}-\d+$`)
-	if !orderPattern.MatchString(orderNumber) {
-		s.whatsappService.SendMessage(adminPhone,
-			"❌ Nomor order tidak valid!\n\n"+
-			"Format yang benar: ORD-YYYYMMDD-XXXXX\n"+
-			"Contoh: ORD-20251130-5863")
+	order, ok := s.findAdminOrder(clientID, adminPhone, parts[1])
+	if !ok {
 		return
 	}
+	if order.PaymentStatus == models.PaymentStatusPaid {
+		s.sendMessage(clientID, adminPhone, "❌ Order "+order.OrderNumber+" sudah dibayar dan tidak bisa dibatalkan.")
+		return
+	}
+	if order.PaymentStatus == models.PaymentStatusCancelled {
+		s.sendMessage(clientID, adminPhone, "ℹ️ Order "+order.OrderNumber+" sudah dibatalkan.")
+		return
+	}
+
+	s.requestConfirmation(clientID, role, adminPhone, message, models.AdminCommandCancelOrder,
+		models.AdminCommandParams{OrderID: order.ID.String(), OrderNumber: order.OrderNumber, Reason: reason},
+		"🗑️ *Batalkan Order?*\n\n"+
+			"📦 Order: "+order.OrderNumber+"\n"+
+			"👤 Customer: "+order.CustomerName+"\n"+
+			"💰 Total: Rp "+formatPrice(order.TotalAmount)+"\n"+
+			"📝 Alasan: "+reason)
+}
 
-	log.Printf("🔧 Admin %s cancelling order %s: %s", adminPhone, orderNumber, reason)
+// handleConfirmCommand asks the admin to confirm a manual payment confirmation. Without a method
+// the order's own method is kept (or "manual"), and the reference defaults to the admin's number
+// Format: KONFIRMASI ORD-20251130-5863 [metode] [referensi] (or CONFIRM ...)
+func (s *WebhookService) handleConfirmCommand(clientID, role, adminPhone, message string) {
+	parts := strings.SplitN(message, " ", 4)
 
-	// Get order by order number
-	order, err := s.orderService.GetOrderByOrderNumber(orderNumber)
+	if len(parts) < 2 {
+		s.sendMessage(clientID, adminPhone,
+			"❌ Format salah!\n\n"+
+				"Gunakan:\n"+
+				"KONFIRMASI <order-number> [metode] [referensi]\n\n"+
+				"Contoh:\n"+
+				"KONFIRMASI ORD-20251130-5863\n"+
+				"KONFIRMASI ORD-20251130-5863 transfer TRF123456\n"+
+				"KONFIRMASI ORD-20251130-5863 cash NOTA-001")
+		return
+	}
+
+	order, ok := s.findAdminOrder(clientID, adminPhone, parts[1])
+	if !ok {
+		return
+	}
+	if order.PaymentStatus == models.PaymentStatusPaid {
+		s.sendMessage(clientID, adminPhone, "ℹ️ Order "+order.OrderNumber+" sudah dibayar.")
+		return
+	}
+	if order.PaymentStatus == models.PaymentStatusCancelled {
+		s.sendMessage(clientID, adminPhone, "❌ Order "+order.OrderNumber+" sudah dibatalkan.")
+		return
+	}
+
+	paymentMethod := order.PaymentMethod
+	if len(parts) >= 3 {
+		paymentMethod = strings.TrimSpace(parts[2])
+	}
+	if paymentMethod == "" {
+		paymentMethod = "manual"
+	}
+	reference := "WA-" + adminPhone
+	if len(parts) == 4 && strings.TrimSpace(parts[3]) != "" {
+		reference = strings.TrimSpace(parts[3])
+	}
+
+	s.requestConfirmation(clientID, role, adminPhone, message, models.AdminCommandConfirmPayment,
+		models.AdminCommandParams{OrderID: order.ID.String(), OrderNumber: order.OrderNumber, PaymentMethod: paymentMethod, Reference: reference},
+		"💳 *Konfirmasi Pembayaran?*\n\n"+
+			"📦 Order: "+order.OrderNumber+"\n"+
+			"👤 Customer: "+order.CustomerName+"\n"+
+			"💰 Total: Rp "+formatPrice(order.TotalAmount)+"\n"+
+			"💳 Metode: "+paymentMethod+"\n"+
+			"🔖 Referensi: "+reference)
+}
+
+// handleStockCommand asks to confirm a stock change. The product is matched by SKU, then by name;
+// the last word is the new stock, or a change when it starts with + or -
+// Format: STOK Kopi Susu 20, STOK KOPI-01 +5
+func (s *WebhookService) handleStockCommand(clientID, role, adminPhone, message string) {
+	usage := "Gunakan:\n" +
+		"STOK <produk/SKU> <jumlah> - set stok\n" +
+		"STOK <produk/SKU> +<jumlah> - tambah stok\n" +
+		"STOK <produk/SKU> -<jumlah> - kurangi stok\n\n" +
+		"Contoh:\n" +
+		"STOK Kopi Susu 20\n" +
+		"STOK KOPI-01 +5"
+
+	fields := strings.Fields(message)
+	if len(fields) < 3 || s.productService == nil {
+		s.sendMessage(clientID, adminPhone, "❌ Format salah!\n\n"+usage)
+		return
+	}
+
+	rawQty := fields[len(fields)-1]
+	relative := strings.HasPrefix(rawQty, "+") || strings.HasPrefix(rawQty, "-")
+	quantity, err := strconv.Atoi(rawQty)
+	if err != nil || (!relative && quantity < 0) || (relative && quantity == 0) {
+		s.sendMessage(clientID, adminPhone, "❌ Jumlah stok tidak valid: "+rawQty+"\n\n"+usage)
+		return
+	}
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		log.Printf("❌ Invalid client ID: %s - %v", clientID, err)
+		return
+	}
+
+	name := strings.Join(fields[1:len(fields)-1], " ")
+	product, err := s.productService.GetProductBySKU(uid, name)
 	if err != nil {
-		log.Printf("❌ Order not found: %s - %v", orderNumber, err)
-		s.whatsappService.SendMessage(adminPhone,
+		product, err = s.productService.GetProductByName(uid, name)
+	}
+	if err != nil {
+		s.sendMessage(clientID, adminPhone,
+			"❌ Produk tidak ditemukan!\n\n"+
+				"Produk: "+name+"\n"+
+				"Gunakan nama produk atau SKU yang terdaftar.")
+		return
+	}
+
+	newStock := quantity
+	if relative {
+		newStock = product.Stock + quantity
+	}
+	if newStock < 0 {
+		s.sendMessage(clientID, adminPhone, fmt.Sprintf("❌ Stok %s hanya %d, tidak bisa dikurangi %d.", product.Name, product.Stock, -quantity))
+		return
+	}
+
+	s.requestConfirmation(clientID, role, adminPhone, message, models.AdminCommandUpdateStock,
+		models.AdminCommandParams{ProductID: product.ID.String(), ProductName: product.Name, Quantity: quantity, Relative: relative},
+		fmt.Sprintf("📦 *Ubah Stok?*\n\n"+
+			"🏷️ Produk: %s\n"+
+			"📊 Stok: %d → %d", product.Name, product.Stock, newStock))
+}
+
+// findAdminOrder looks up an order of the admin's own client, replying when it can't be used
+func (s *WebhookService) findAdminOrder(clientID, adminPhone, rawOrderNumber string) (*models.Order, bool) {
+	orderNumber := strings.ToUpper(strings.TrimSpace(rawOrderNumber))
+	if !orderNumberPattern.MatchString(orderNumber) {
+		s.sendMessage(clientID, adminPhone,
+			"❌ Nomor order tidak valid!\n\n"+
+				"Format yang benar: ORD-YYYYMMDD-XXXXX\n"+
+				"Contoh: ORD-20251130-5863")
+		return nil, false
+	}
+
+	order, err := s.orderService.GetOrderByOrderNumber(orderNumber)
+	// Orders of other clients are reported as not found
+	if err != nil || order.ClientID.String() != clientID {
+		log.Printf("❌ Order not found for client %s: %s - %v", clientID, orderNumber, err)
+		s.sendMessage(clientID, adminPhone,
 			"❌ Order tidak ditemukan!\n\n"+
-			"Nomor order: "+orderNumber+"\n"+
-			"Pastikan nomor order benar.")
+				"Nomor order: "+orderNumber+"\n"+
+				"Pastikan nomor order benar.")
+		return nil, false
+	}
+	return order, true
+}
+
+// requestConfirmation stores a command until the admin replies YA, replacing any earlier one
+func (s *WebhookService) requestConfirmation(clientID, role, adminPhone, message, command string, params models.AdminCommandParams, summary string) {
+	uid, err := uuid.Parse(clientID)
+	if err != nil || s.adminCommandRepo == nil {
+		log.Printf("❌ Cannot store admin command for client %s: %v", clientID, err)
+		return
+	}
+
+	data, _ := json.Marshal(params)
+	pending := &models.AdminPendingCommand{
+		ClientID:   uid,
+		AdminPhone: adminPhone,
+		Role:       role,
+		Command:    command,
+		Message:    message,
+		Params:     datatypes.JSON(data),
+		ExpiresAt:  time.Now().Add(adminCommandTTL),
+		CreatedAt:  time.Now(),
+	}
+	if err := s.adminCommandRepo.SavePending(pending); err != nil {
+		log.Printf("❌ Failed to store admin command: %v", err)
+		s.sendMessage(clientID, adminPhone, "❌ Sistem sedang bermasalah, silakan coba lagi.")
 		return
 	}
 
-	// Cancel the order
-	err = s.orderService.CancelOrder(order.ID.String(), reason)
+	log.Printf("🔧 %s (%s) requested %s, waiting for confirmation", adminPhone, role, command)
+	s.sendMessage(clientID, adminPhone, summary+"\n\n"+
+		fmt.Sprintf("Balas *YA* untuk melanjutkan atau *TIDAK* untuk membatalkan (berlaku %d menit).", int(adminCommandTTL.Minutes())))
+}
+
+// resolvePendingCommand runs or drops the admin's pending command. Returns false when there is
+// none, so a plain "ya" is handled as a normal message
+func (s *WebhookService) resolvePendingCommand(clientID, adminPhone string, confirmed bool) bool {
+	uid, err := uuid.Parse(clientID)
+	if err != nil || s.adminCommandRepo == nil {
+		return false
+	}
+
+	pending, err := s.adminCommandRepo.GetPending(uid, adminPhone)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false
+	}
 	if err != nil {
+		log.Printf("❌ Failed to load pending admin command: %v", err)
+		return false
+	}
+
+	// Taken by another delivery of the same reply
+	if taken, err := s.adminCommandRepo.DeletePending(pending.ID); err != nil || !taken {
+		return true
+	}
+
+	var params models.AdminCommandParams
+	_ = json.Unmarshal(pending.Params, &params)
+	metadata := map[string]interface{}{"message": pending.Message}
+
+	if time.Now().After(pending.ExpiresAt) {
+		metadata["status"] = "expired"
+		s.recordAdminAudit(clientID, adminPhone, pending.Role, pending.Command, "command", "", nil, nil,
+			"Admin command expired before confirmation", metadata)
+		s.sendMessage(clientID, adminPhone, "⌛ Perintah sudah kedaluwarsa. Silakan kirim ulang perintahnya.")
+		return true
+	}
+
+	if !confirmed {
+		metadata["status"] = "declined"
+		s.recordAdminAudit(clientID, adminPhone, pending.Role, pending.Command, "command", "", nil, nil,
+			"Admin command declined", metadata)
+		s.sendMessage(clientID, adminPhone, "👌 Perintah dibatalkan, tidak ada perubahan.")
+		return true
+	}
+
+	// Permissions are checked again in case the admin's role changed while the command waited
+	if !canRunAdminCommand(pending.Role, pending.Command) {
+		return true
+	}
+
+	switch pending.Command {
+	case models.AdminCommandCancelOrder:
+		s.runCancelOrder(clientID, adminPhone, pending.Role, params, metadata)
+	case models.AdminCommandConfirmPayment:
+		s.runConfirmPayment(clientID, adminPhone, pending.Role, params, metadata)
+	case models.AdminCommandUpdateStock:
+		s.runUpdateStock(clientID, adminPhone, pending.Role, params, metadata)
+	}
+	return true
+}
+
+func (s *WebhookService) runCancelOrder(clientID, adminPhone, role string, params models.AdminCommandParams, metadata map[string]interface{}) {
+	log.Printf("🔧 Admin %s cancelling order %s: %s", adminPhone, params.OrderNumber, params.Reason)
+
+	before := s.orderAuditState(params.OrderNumber)
+	if err := s.orderService.CancelOrder(params.OrderID, params.Reason); err != nil {
 		log.Printf("❌ Failed to cancel order: %v", err)
-		s.whatsappService.SendMessage(adminPhone,
+		metadata["status"] = "failed"
+		metadata["error"] = err.Error()
+		s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandCancelOrder, "order", params.OrderID, nil, nil,
+			"Failed to cancel order "+params.OrderNumber+" from WhatsApp", metadata)
+		s.sendMessage(clientID, adminPhone,
 			"❌ Gagal membatalkan order!\n\n"+
-			"Error: "+err.Error())
+				"Error: "+err.Error())
 		return
 	}
 
-	// Success response to admin
-	s.whatsappService.SendMessage(adminPhone,
+	metadata["status"] = "executed"
+	metadata["reason"] = params.Reason
+	s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandCancelOrder, "order", params.OrderID,
+		before, s.orderAuditState(params.OrderNumber), "Order "+params.OrderNumber+" cancelled from WhatsApp", metadata)
+
+	s.sendMessage(clientID, adminPhone,
 		"✅ *Order Dibatalkan*\n\n"+
-		"📦 Order: "+orderNumber+"\n"+
-		"📝 Alasan: "+reason+"\n\n"+
-		"Customer telah menerima notifikasi pembatalan.")
+			"📦 Order: "+params.OrderNumber+"\n"+
+			"📝 Alasan: "+params.Reason+"\n\n"+
+			"Customer telah menerima notifikasi pembatalan.")
 
-	log.Printf("✅ Admin %s successfully cancelled order %s", adminPhone, orderNumber)
+	log.Printf("✅ Admin %s successfully cancelled order %s", adminPhone, params.OrderNumber)
 }
 
-// handleConfirmCommand processes payment confirmation
-// Format: CONFIRM ORD-20251130-5863 transfer TRF123456
-func (s *WebhookService) handleConfirmCommand(adminPhone, message string) {
-	// Parse: CONFIRM <order-number> <payment-method> <reference>
-	parts := strings.SplitN(message, " ", 4)
+func (s *WebhookService) runConfirmPayment(clientID, adminPhone, role string, params models.AdminCommandParams, metadata map[string]interface{}) {
+	log.Printf("🔧 Admin %s confirming payment for order %s: %s %s", adminPhone, params.OrderNumber, params.PaymentMethod, params.Reference)
 
-	if len(parts) < 4 {
-		s.whatsappService.SendMessage(adminPhone,
-			"❌ Format salah!\n\n"+
-			"Gunakan:\n"+
-			"CONFIRM <order-number> <metode> <referensi>\n\n"+
-			"Contoh:\n"+
-			"CONFIRM ORD-20251130-5863 transfer TRF123456\n"+
-			"CONFIRM ORD-20251130-5863 cash NOTA-001\n"+
-			"CONFIRM ORD-20251130-5863 gopay GP-987654")
+	before := s.orderAuditState(params.OrderNumber)
+	if err := s.orderService.ConfirmPayment(params.OrderID, params.PaymentMethod, params.Reference); err != nil {
+		log.Printf("❌ Failed to confirm payment: %v", err)
+		metadata["status"] = "failed"
+		metadata["error"] = err.Error()
+		s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandConfirmPayment, "order", params.OrderID, nil, nil,
+			"Failed to confirm payment of order "+params.OrderNumber+" from WhatsApp", metadata)
+		s.sendMessage(clientID, adminPhone,
+			"❌ Gagal konfirmasi pembayaran!\n\n"+
+				"Error: "+err.Error())
 		return
 	}
 
-	orderNumber := strings.TrimSpace(parts[1])
-	paymentMethod := strings.TrimSpace(parts[2])
-	reference := strings.TrimSpace(parts[3])
+	metadata["status"] = "executed"
+	s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandConfirmPayment, "order", params.OrderID,
+		before, s.orderAuditState(params.OrderNumber), "Payment of order "+params.OrderNumber+" confirmed from WhatsApp", metadata)
 
-	// Validate order number format
-	orderPattern := regexp.MustCompile(`^ORD-\d{8}-\d+$`)
-	if !orderPattern.MatchString(orderNumber) {
-		s.whatsappService.SendMessage(adminPhone,
-			"❌ Nomor order tidak valid!\n\n"+
-			"Format yang benar: ORD-YYYYMMDD-XXXXX\n"+
-			"Contoh: ORD-20251130-5863")
+	s.sendMessage(clientID, adminPhone,
+		"✅ *Pembayaran Dikonfirmasi*\n\n"+
+			"📦 Order: "+params.OrderNumber+"\n"+
+			"💳 Metode: "+params.PaymentMethod+"\n"+
+			"🔖 Referensi: "+params.Reference+"\n\n"+
+			"Customer telah menerima notifikasi pembayaran diterima.")
+
+	log.Printf("✅ Admin %s successfully confirmed payment for order %s", adminPhone, params.OrderNumber)
+}
+
+func (s *WebhookService) runUpdateStock(clientID, adminPhone, role string, params models.AdminCommandParams, metadata map[string]interface{}) {
+	fail := func(err error) {
+		log.Printf("❌ Failed to update stock: %v", err)
+		metadata["status"] = "failed"
+		metadata["error"] = err.Error()
+		s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandUpdateStock, "product", params.ProductID, nil, nil,
+			"Failed to update stock of "+params.ProductName+" from WhatsApp", metadata)
+		s.sendMessage(clientID, adminPhone, "❌ Gagal mengubah stok!\n\nError: "+err.Error())
+	}
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil || s.productService == nil {
+		fail(fmt.Errorf("product service unavailable"))
 		return
 	}
 
-	log.Printf("🔧 Admin %s confirming payment for order %s: %s %s", adminPhone, orderNumber, paymentMethod, reference)
+	// The change is applied to the current stock, which may have moved since the command was sent
+	product, err := s.productService.GetProduct(params.ProductID, uid)
+	if err != nil {
+		fail(err)
+		return
+	}
+	change := params.Quantity
+	if !params.Relative {
+		change = params.Quantity - product.Stock
+	}
 
-	// Get order by order number
+	updated := product
+	if change != 0 {
+		updated, err = s.productService.UpdateStock(params.ProductID, uid, change)
+		if err != nil {
+			fail(err)
+			return
+		}
+	}
+
+	metadata["status"] = "executed"
+	s.recordAdminAudit(clientID, adminPhone, role, models.AdminCommandUpdateStock, "product", params.ProductID,
+		map[string]interface{}{"stock": product.Stock}, map[string]interface{}{"stock": updated.Stock},
+		fmt.Sprintf("Stock of %s set to %d from WhatsApp", updated.Name, updated.Stock), metadata)
+
+	s.sendMessage(clientID, adminPhone, fmt.Sprintf(
+		"✅ *Stok Diperbarui*\n\n"+
+			"🏷️ Produk: %s\n"+
+			"📊 Stok: %d → %d", updated.Name, product.Stock, updated.Stock))
+
+	log.Printf("✅ %s updated stock of %s: %d -> %d", adminPhone, updated.Name, product.Stock, updated.Stock)
+}
+
+// orderAuditState is the part of an order recorded in the audit log of WhatsApp commands
+func (s *WebhookService) orderAuditState(orderNumber string) interface{} {
 	order, err := s.orderService.GetOrderByOrderNumber(orderNumber)
 	if err != nil {
-		log.Printf("❌ Order not found: %s - %v", orderNumber, err)
-		s.whatsappService.SendMessage(adminPhone,
-			"❌ Order tidak ditemukan!\n\n"+
-			"Nomor order: "+orderNumber+"\n"+
-			"Pastikan nomor order benar.")
+		return nil
+	}
+	return map[string]interface{}{
+		"payment_status":     order.PaymentStatus,
+		"fulfillment_status": order.FulfillmentStatus,
+		"payment_method":     order.PaymentMethod,
+		"payment_reference":  order.PaymentReference,
+	}
+}
+
+// recordAdminAudit writes an audit log entry for a WhatsApp command, logging (not returning) failures
+func (s *WebhookService) recordAdminAudit(clientID, adminPhone, role, action, entity, entityID string, oldValue, newValue interface{}, description string, metadata map[string]interface{}) {
+	if s.auditService == nil {
 		return
 	}
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return
+	}
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["actor"] = adminPhone
+	metadata["role"] = role
+	metadata["channel"] = "whatsapp"
 
-	// Confirm payment
-	err = s.orderService.ConfirmPayment(order.ID.String(), paymentMethod, reference)
+	entry := &audit.AuditLog{
+		ClientID:    uid,
+		Action:      action,
+		Entity:      entity,
+		EntityID:    entityID,
+		Description: description,
+	}
+	if oldValue != nil {
+		if data, err := json.Marshal(oldValue); err == nil {
+			entry.OldValue = datatypes.JSON(data)
+		}
+	}
+	if newValue != nil {
+		if data, err := json.Marshal(newValue); err == nil {
+			entry.NewValue = datatypes.JSON(data)
+		}
+	}
+	if data, err := json.Marshal(metadata); err == nil {
+		entry.Metadata = datatypes.JSON(data)
+	}
+
+	if err := s.auditService.Log(context.Background(), entry); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+// handleBotCommand pauses or resumes the bot (vacation mode)
+// Format: BOT OFF sampai 25/04 | BOT ON | BOT STATUS
+func (s *WebhookService) handleBotCommand(clientID, adminPhone, message string) {
+	usage := "Gunakan:\n" +
+		"BOT OFF - nonaktifkan bot\n" +
+		"BOT OFF sampai <tanggal> - nonaktifkan sampai tanggal tertentu\n" +
+		"BOT ON - aktifkan kembali\n" +
+		"BOT STATUS - cek status bot\n\n" +
+		"Contoh:\n" +
+		"BOT OFF sampai 25/04\n" +
+		"BOT OFF sampai 25/04/2026 08:00"
+
+	client, err := s.clientRepo.GetByID(clientID)
 	if err != nil {
-		log.Printf("❌ Failed to confirm payment: %v", err)
-		s.whatsappService.SendMessage(adminPhone,
-			"❌ Gagal konfirmasi pembayaran!\n\n"+
-			"Error: "+err.Error())
+		log.Printf("❌ Client not found: %s - %v", clientID, err)
 		return
 	}
 
-	// Success response to admin
-	s.whatsappService.SendMessage(adminPhone,
-		"✅ *Pembayaran Dikonfirmasi*\n\n"+
-		"📦 Order: "+orderNumber+"\n"+
-		"💳 Metode: "+paymentMethod+"\n"+
-		"🔖 Referensi: "+reference+"\n\n"+
-		"Customer telah menerima notifikasi pembayaran diterima.")
+	fields := strings.Fields(strings.ToLower(message))
+	if len(fields) < 2 {
+		s.sendMessage(clientID, adminPhone, "❌ Format salah!\n\n"+usage)
+		return
+	}
 
-	log.Printf("✅ Admin %s successfully confirmed payment for order %s", adminPhone, orderNumber)
+	switch fields[1] {
+	case "off":
+		var resumeAt *time.Time
+		args := fields[2:]
+		if len(args) > 0 && (args[0] == "sampai" || args[0] == "until") {
+			args = args[1:]
+		}
+		if len(args) > 0 {
+			t, err := parseResumeTime(strings.Join(args, " "), client.Timezone, time.Now())
+			if err != nil {
+				s.sendMessage(clientID, adminPhone,
+					"❌ Tanggal tidak valid!\n\n"+
+						"Gunakan format DD/MM, DD/MM/YYYY atau DD/MM/YYYY HH:MM yang belum lewat.\n"+
+						"Contoh: BOT OFF sampai 25/04")
+				return
+			}
+			resumeAt = &t
+		}
+
+		log.Printf("🔧 Admin %s pausing bot for client %s", adminPhone, clientID)
+
+		if _, err := s.botPauseSvc.Pause(clientID, adminPhone, resumeAt); err != nil {
+			log.Printf("❌ Failed to pause bot: %v", err)
+			s.sendMessage(clientID, adminPhone, "❌ Gagal menonaktifkan bot!\n\nError: "+err.Error())
+			return
+		}
+
+		until := "sampai diaktifkan kembali"
+		if resumeAt != nil {
+			until = "sampai " + formatResumeTime(*resumeAt, client.Timezone) + " (aktif kembali otomatis)"
+		}
+		s.sendMessage(clientID, adminPhone,
+			"⏸️ *Bot Dinonaktifkan*\n\n"+
+				"Bot tidak membalas pelanggan "+until+".\n"+
+				"Pelanggan akan menerima pesan bahwa toko sedang libur.\n\n"+
+				"Ketik *BOT ON* untuk mengaktifkan kembali.")
+
+	case "on":
+		log.Printf("🔧 Admin %s resuming bot for client %s", adminPhone, clientID)
+
+		if _, err := s.botPauseSvc.Resume(clientID, adminPhone); err != nil {
+			log.Printf("❌ Failed to resume bot: %v", err)
+			s.sendMessage(clientID, adminPhone, "❌ Gagal mengaktifkan bot!\n\nError: "+err.Error())
+			return
+		}
+
+		s.sendMessage(clientID, adminPhone,
+			"▶️ *Bot Aktif Kembali*\n\n"+
+				"Bot kembali membalas pelanggan secara otomatis.")
+
+	case "status":
+		status := "▶️ Bot *aktif* dan membalas pelanggan."
+		if s.botPauseSvc.IsPaused(client) {
+			status = "⏸️ Bot *nonaktif* (mode libur)"
+			if client.BotResumeAt != nil {
+				status += " sampai " + formatResumeTime(*client.BotResumeAt, client.Timezone)
+			}
+			status += ".\n\nKetik *BOT ON* untuk mengaktifkan kembali."
+		}
+		s.sendMessage(clientID, adminPhone, status)
+
+	default:
+		s.sendMessage(clientID, adminPhone, "❌ Perintah tidak dikenal!\n\n"+usage)
+	}
 }
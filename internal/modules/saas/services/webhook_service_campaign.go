@@ -0,0 +1,29 @@
+package services
+
+import (
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/google/uuid"
+)
+
+// SetCampaignService enables delivery tracking of campaign messages from WhatsApp acks
+func (s *WebhookService) SetCampaignService(campaignSvc *CampaignService) {
+	s.campaignSvc = campaignSvc
+}
+
+// ProcessMessageAck handles a delivery ack (sent, delivered, read) of a message the business sent from a
+// session. Acks are matched against the campaigns of the client mapped to the session only.
+func (s *WebhookService) ProcessMessageAck(sessionID, messageID string, ack int) {
+	if s.campaignSvc == nil || campaignAckStatus(ack) == "" {
+		return
+	}
+
+	var clientID *uuid.UUID
+	client, err := s.clientRepo.GetClientByWhatsAppSession(sessionID)
+	switch {
+	case err == nil:
+		clientID = &client.ID
+	case !tenant.SingleNumberSession(sessionID):
+		return // Unmapped session, e.g. of a deactivated client
+	}
+	s.campaignSvc.HandleAck(clientID, messageID, ack)
+}
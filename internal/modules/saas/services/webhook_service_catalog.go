@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+const (
+	// maxCatalogImages caps the product photos sent for one message
+	maxCatalogImages = 3
+	// catalogImageCooldown is how long before a customer is sent the same product photo again
+	catalogImageCooldown = 6 * time.Hour
+)
+
+// catalogPhotoWords ask to see a product; with a product name they get its photo like a product question does
+var catalogPhotoWords = map[string]bool{
+	"foto": true, "gambar": true, "photo": true, "pic": true, "liat": true, "lihat": true, "penampakan": true, "contoh": true,
+}
+
+// catalogImageLog remembers which product photos each customer was sent recently
+type catalogImageLog struct {
+	mu   sync.Mutex
+	sent map[string]time.Time
+}
+
+func newCatalogImageLog() *catalogImageLog {
+	return &catalogImageLog{sent: make(map[string]time.Time)}
+}
+
+// claim reports whether the photo may be sent now, and if so records it as sent
+func (l *catalogImageLog) claim(clientID, customerPhone, productID string) bool {
+	key := clientID + "|" + customerPhone + "|" + productID
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if sentAt, ok := l.sent[key]; ok && now.Sub(sentAt) < catalogImageCooldown {
+		return false
+	}
+	for k, sentAt := range l.sent {
+		if now.Sub(sentAt) >= catalogImageCooldown {
+			delete(l.sent, k)
+		}
+	}
+	l.sent[key] = now
+	return true
+}
+
+// sendProductImages sends the photo of each catalog product the customer asks about, captioned with its
+// price and stock. Products without an image, and photos the customer got recently, are skipped.
+func (s *WebhookService) sendProductImages(client *models.Client, customerPhone, message string) {
+	if s.mentionService == nil {
+		return
+	}
+	words := strings.Fields(normalizeMentionText(message))
+	if !isProductInquiry(words) && !hasCatalogPhotoWord(words) {
+		return
+	}
+
+	products := s.mentionService.NamedProducts(client.ID, message)
+	sent := 0
+	for i := range products {
+		product := &products[i]
+		imageURL := productImageURL(product)
+		if imageURL == "" || !s.catalogImages.claim(client.ID.String(), customerPhone, product.ID.String()) {
+			continue
+		}
+
+		image := whatsapp.Media{URL: imageURL, Caption: productCaption(product)}
+		if err := s.sendImage(client.ID.String(), customerPhone, image); err != nil {
+			log.Printf("⚠️ Failed to send photo of %s to %s: %v", product.Name, customerPhone, err)
+			continue
+		}
+		log.Printf("🖼️ Sent photo of %s to %s", product.Name, customerPhone)
+
+		if sent++; sent == maxCatalogImages {
+			return
+		}
+	}
+}
+
+// sendImage queues an image of a client for delivery, captured as text in sandbox mode
+func (s *WebhookService) sendImage(clientID, to string, image whatsapp.Media) error {
+	if s.sandboxService != nil && s.sandboxService.IsSandbox(clientID) {
+		return s.sandboxService.SendMessage(clientID, to, imageText(image))
+	}
+	if s.outbound != nil {
+		return s.outbound.EnqueueImage(clientID, to, image)
+	}
+	if s.sessions != nil {
+		_, err := s.sessions.SendImage(clientID, to, image)
+		return err
+	}
+	_, err := s.whatsappService.SendImage(to, image)
+	return err
+}
+
+// imageText describes an image as text: its URL and caption
+func imageText(image whatsapp.Media) string {
+	return fmt.Sprintf("🖼️ %s\n%s", image.URL, image.Caption)
+}
+
+// productImageURL prefers the image variant sized for WhatsApp
+func productImageURL(product *models.Product) string {
+	if product.WhatsAppImageURL != "" {
+		return product.WhatsAppImageURL
+	}
+	return product.ImageURL
+}
+
+// productCaption describes a product's price and stock under its photo
+func productCaption(product *models.Product) string {
+	caption := fmt.Sprintf("*%s*\n💰 Rp %s\n", product.Name, formatCurrency(product.Price))
+	switch {
+	case product.IsAvailable():
+		caption += fmt.Sprintf("📦 Stok: %d", product.Stock)
+	case product.CanPreorder():
+		caption += "📦 " + PreorderNote(product)
+	default:
+		caption += "❌ Stok habis"
+	}
+	return caption
+}
+
+func hasCatalogPhotoWord(words []string) bool {
+	for _, word := range words {
+		if catalogPhotoWords[word] {
+			return true
+		}
+	}
+	return false
+}
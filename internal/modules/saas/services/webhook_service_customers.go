@@ -0,0 +1,23 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// SetCustomerService keeps a customer record (CRM) for everyone who writes in
+func (s *WebhookService) SetCustomerService(customerSvc *CustomerService) {
+	s.customerSvc = customerSvc
+}
+
+// recordCustomer notes an inbound message on the sender's customer record; staff messages are not
+// customer interactions
+func (s *WebhookService) recordCustomer(client *models.Client, role, customerPhone string) {
+	if s.customerSvc == nil || role != "customer" {
+		return
+	}
+	if err := s.customerSvc.RecordInteraction(client.ID, customerPhone); err != nil {
+		log.Printf("⚠️ %v", err)
+	}
+}
@@ -0,0 +1,6 @@
+package services
+
+// SetKBDocumentService lets the bot quote uploaded knowledge base documents in its answers
+func (s *WebhookService) SetKBDocumentService(documentSvc *KBDocumentService) {
+	s.documentSvc = documentSvc
+}
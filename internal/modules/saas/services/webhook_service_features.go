@@ -0,0 +1,54 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// SetMessageFeatureService enables per-client toggles for the inbound message types
+func (s *WebhookService) SetMessageFeatureService(featureSvc *MessageFeatureService) {
+	s.featureSvc = featureSvc
+}
+
+// messageTypeAllowed checks the client's toggle for an inbound message type. A disabled type is answered
+// with the client's disabled reply (if any) and logged with the given inbound text, and false is returned
+func (s *WebhookService) messageTypeAllowed(client *models.Client, feature, customerPhone, inbound string) bool {
+	if s.featureSvc == nil {
+		return true
+	}
+	settings := s.featureSvc.Settings(client.ID)
+	if settings.Enabled(feature) {
+		return true
+	}
+
+	log.Printf("🚫 %s messages are disabled for client %s, not handling message from %s", feature, client.ID, customerPhone)
+	reply := settings.DisabledReply(feature)
+	if reply != "" {
+		s.sendMessage(client.ID.String(), customerPhone, reply)
+	}
+	if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, inbound, reply); err != nil {
+		log.Printf("⚠️ Failed to log conversation: %v", err)
+	}
+	return false
+}
+
+// GroupMessagesEnabled reports whether the tenant handling a group chat answers group messages
+func (s *WebhookService) GroupMessagesEnabled(sessionID, groupID string) bool {
+	if s.featureSvc == nil {
+		return true
+	}
+	tenantCtx, err := s.tenantResolver.ResolveFromSession(sessionID, groupID)
+	if err != nil {
+		return true // Left to the regular routing, which reports the failure
+	}
+	client, err := s.clientRepo.GetByID(tenantCtx.ClientID)
+	if err != nil {
+		return true
+	}
+	if s.featureSvc.Settings(client.ID).Enabled(models.MessageFeatureGroup) {
+		return true
+	}
+	log.Printf("🚫 Group messages are disabled for client %s, ignoring group %s (session: %s)", client.ID, groupID, sessionID)
+	return false
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// Quick reply button IDs. Cloud API sends the ID back when a button is tapped; WAHA and the plain text
+// fallback send the title, so both are matched.
+const (
+	quickReplyViewCart    = "view_cart"
+	quickReplyCheckout    = "checkout"
+	quickReplyTalkToHuman = "talk_to_human"
+)
+
+// talkToHumanMessage confirms a customer's request for an agent
+const talkToHumanMessage = "Baik, pesan Anda sudah kami teruskan ke admin. Tim kami akan segera membalas 🙏"
+
+var (
+	viewCartButton    = whatsapp.Button{ID: quickReplyViewCart, Title: "Lihat Keranjang"}
+	checkoutButton    = whatsapp.Button{ID: quickReplyCheckout, Title: "Checkout"}
+	talkToHumanButton = whatsapp.Button{ID: quickReplyTalkToHuman, Title: "Bicara dengan Admin"}
+)
+
+// quickReplyButtons are the buttons handled by handleQuickReply
+var quickReplyButtons = []whatsapp.Button{viewCartButton, checkoutButton, talkToHumanButton}
+
+// handleQuickReply answers a tapped cart button (or its title typed as text). Returns false for other messages.
+func (s *WebhookService) handleQuickReply(client *models.Client, customerPhone, message string) bool {
+	if s.cartService == nil {
+		return false
+	}
+
+	reply := strings.TrimSpace(message)
+	var action string
+	for _, button := range quickReplyButtons {
+		if strings.EqualFold(reply, button.ID) || strings.EqualFold(reply, button.Title) {
+			action = button.ID
+			break
+		}
+	}
+
+	clientID := client.ID.String()
+	var logged string
+	switch action {
+	case quickReplyViewCart:
+		s.handleViewCart(clientID, customerPhone)
+
+	case quickReplyCheckout:
+		if s.orderService == nil {
+			return false
+		}
+		if order := s.handleCheckout(clientID, customerPhone, ""); order != nil {
+			logged = fmt.Sprintf("Order %s created", order.OrderNumber)
+		}
+
+	case quickReplyTalkToHuman:
+		s.handoverToHuman(client, customerPhone)
+		logged = talkToHumanMessage
+
+	default:
+		return false
+	}
+
+	log.Printf("🔘 Quick reply %s from %s", action, customerPhone)
+	if err := s.conversationRepo.LogConversation(clientID, customerPhone, message, logged); err != nil {
+		log.Printf("⚠️ Failed to log conversation: %v", err)
+	}
+	return true
+}
+
+// handoverToHuman tags the chat with the client's handover tag so agents pick it up, and tells the customer
+func (s *WebhookService) handoverToHuman(client *models.Client, customerPhone string) {
+	if s.latencySvc != nil && s.tagSvc != nil && !client.SandboxMode {
+		if tag := s.latencySvc.Settings(client.ID.String()).HandoverTag; tag != "" {
+			if _, err := s.tagSvc.TagConversation(client.ID, customerPhone, []string{tag}, models.TagSourceAuto, "quick_reply"); err != nil {
+				log.Printf("⚠️ Failed to tag handover for %s: %v", customerPhone, err)
+			}
+		}
+	}
+	s.sendMessage(client.ID.String(), customerPhone, talkToHumanMessage)
+}
+
+// sendButtons queues a message with reply buttons of a client for delivery. Sandbox mode captures the text
+// fallback, and a failed send falls back to plain text so the customer still gets the message.
+func (s *WebhookService) sendButtons(clientID, to string, message whatsapp.ButtonMessage) error {
+	if s.sandboxService != nil && s.sandboxService.IsSandbox(clientID) {
+		return s.sandboxService.SendMessage(clientID, to, message.Text())
+	}
+	if s.outbound != nil {
+		return s.outbound.EnqueueButtons(clientID, to, message)
+	}
+
+	var err error
+	if s.sessions != nil {
+		_, err = s.sessions.SendButtons(clientID, to, message)
+	} else {
+		_, err = s.whatsappService.SendButtons(to, message)
+	}
+	if err != nil {
+		log.Printf("⚠️ Failed to send buttons to %s, sending text instead: %v", to, err)
+		return s.sendMessage(clientID, to, message.Text())
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// How a reply within the latency budget was produced
+const (
+	budgetLLM      = "llm"      // The LLM answered in time
+	budgetKB       = "kb"       // Hard timeout, answered with a matching FAQ
+	budgetHandover = "handover" // Hard timeout, the customer was told an agent will reply
+)
+
+// generateWithinBudget calls the LLM within the client's latency budget. A slow reply gets an interim
+// message first; when the hard timeout passes the LLM call is abandoned for a FAQ answer or a handover.
+// A client whose AI usage is exhausted gets the same fallback without calling the LLM.
+// The error is the LLM's, only set for budgetLLM.
+func (s *WebhookService) generateWithinBudget(ctx context.Context, client *models.Client, customerPhone, systemPrompt string, history []llm.ChatMessage, message string, knowledgeBase *llm.KnowledgeBase, tools *toolTurn) (string, string, error) {
+	ctx = llm.WithUsage(ctx, client.ID.String(), models.UsageFeatureChat)
+	response, outcome, err := s.generateTimed(ctx, client, customerPhone, systemPrompt, history, message, knowledgeBase, tools)
+	if errors.Is(err, llm.ErrUsageExhausted) {
+		return s.usageFallback(client, customerPhone, message, knowledgeBase)
+	}
+	return response, outcome, err
+}
+
+// generateTimed calls the LLM, falling back once the client's hard timeout passes
+func (s *WebhookService) generateTimed(ctx context.Context, client *models.Client, customerPhone, systemPrompt string, history []llm.ChatMessage, message string, knowledgeBase *llm.KnowledgeBase, tools *toolTurn) (string, string, error) {
+	if s.latencySvc == nil {
+		response, err := s.generate(ctx, systemPrompt, history, message, tools)
+		return response, budgetLLM, err
+	}
+
+	clientID := client.ID.String()
+	settings := s.latencySvc.Settings(clientID)
+	if !settings.Enabled {
+		response, err := s.generate(ctx, systemPrompt, history, message, tools)
+		return response, budgetLLM, err
+	}
+
+	llmCtx, cancel := context.WithTimeout(ctx, time.Duration(settings.HardTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	type result struct {
+		response string
+		err      error
+	}
+	done := make(chan result, 1)
+	start := time.Now()
+	go func() {
+		response, err := s.generate(llmCtx, systemPrompt, history, message, tools)
+		done <- result{response, err}
+	}()
+
+	var interim <-chan time.Time
+	if settings.InterimAfterSeconds > 0 {
+		timer := time.NewTimer(time.Duration(settings.InterimAfterSeconds) * time.Second)
+		defer timer.Stop()
+		interim = timer.C
+	}
+
+	for {
+		select {
+		case r := <-done:
+			// Providers report the deadline as their own error
+			if r.err != nil && errors.Is(llmCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+				return s.latencyFallback(client, customerPhone, message, knowledgeBase, settings, start)
+			}
+			return r.response, budgetLLM, r.err
+
+		case <-interim:
+			interim = nil
+			log.Printf("⏳ LLM slow for %s (client %s), sending interim message", customerPhone, clientID)
+			if err := s.sendMessage(clientID, customerPhone, settings.InterimMessage); err != nil {
+				log.Printf("⚠️ Failed to send interim message: %v", err)
+			}
+
+		case <-llmCtx.Done():
+			if ctx.Err() != nil {
+				return "", budgetLLM, ctx.Err()
+			}
+			return s.latencyFallback(client, customerPhone, message, knowledgeBase, settings, start)
+		}
+	}
+}
+
+// latencyFallback picks the degraded reply once the LLM missed the hard timeout
+func (s *WebhookService) latencyFallback(client *models.Client, customerPhone, message string, knowledgeBase *llm.KnowledgeBase, settings *models.LatencySettings, start time.Time) (string, string, error) {
+	if settings.Fallback == models.LatencyFallbackKB {
+		if answer, ok := FAQAnswer(knowledgeBase, message); ok {
+			log.Printf("⌛ LLM timed out after %s for %s (client %s), answered from FAQ", time.Since(start).Round(time.Millisecond), customerPhone, client.ID)
+			return answer, budgetKB, nil
+		}
+	}
+
+	log.Printf("⌛ LLM timed out after %s for %s (client %s), handing over to agents", time.Since(start).Round(time.Millisecond), customerPhone, client.ID)
+	if settings.HandoverTag != "" && s.tagSvc != nil && !client.SandboxMode {
+		if _, err := s.tagSvc.TagConversation(client.ID, customerPhone, []string{settings.HandoverTag}, models.TagSourceAuto, "latency_budget"); err != nil {
+			log.Printf("⚠️ Failed to tag handover for %s: %v", customerPhone, err)
+		}
+	}
+	return settings.HandoverMessage, budgetHandover, nil
+}
+
+// usageExhaustedMessage is sent when the client's AI quota and credits are used up and no FAQ matches
+const usageExhaustedMessage = "Terima kasih, pesan Anda sudah kami terima. Tim kami akan segera membalas."
+
+// usageFallback replies without the LLM once the client's AI token quota and credits are exhausted:
+// with a matching FAQ if there is one, otherwise by handing over to agents
+func (s *WebhookService) usageFallback(client *models.Client, customerPhone, message string, knowledgeBase *llm.KnowledgeBase) (string, string, error) {
+	if answer, ok := FAQAnswer(knowledgeBase, message); ok {
+		log.Printf("🪫 AI usage exhausted for client %s, answered %s from FAQ", client.ID, customerPhone)
+		return answer, budgetKB, nil
+	}
+	log.Printf("🪫 AI usage exhausted for client %s, handing %s over to agents", client.ID, customerPhone)
+	return usageExhaustedMessage, budgetHandover, nil
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// ProcessLocationMessage handles an inbound location pin and replies with the nearest store
+func (s *WebhookService) ProcessLocationMessage(sessionID, customerPhone string, latitude, longitude float64) {
+	log.Printf("📍 Processing location from %s (session: %s): %.6f,%.6f", customerPhone, sessionID, latitude, longitude)
+
+	tenantCtx, err := s.tenantResolver.ResolveFromSession(sessionID, customerPhone)
+	if err != nil {
+		log.Printf("❌ Failed to resolve tenant for %s: %v", customerPhone, err)
+		return
+	}
+
+	client, err := s.clientRepo.GetByID(tenantCtx.ClientID)
+	if err != nil {
+		log.Printf("❌ No client found for ID '%s': %v", tenantCtx.ClientID, err)
+		return
+	}
+
+	s.recordCustomer(client, tenantCtx.Role, customerPhone)
+
+	inbound := fmt.Sprintf("[Lokasi] %.6f,%.6f", latitude, longitude)
+	if !s.messageTypeAllowed(client, models.MessageFeatureLocation, customerPhone, inbound) {
+		return
+	}
+
+	if s.storeService == nil {
+		return
+	}
+
+	clientID := client.ID.String()
+
+	stores, err := s.storeService.FindNearest(client.ID, latitude, longitude, 1)
+	if err != nil {
+		log.Printf("❌ Failed to find nearest store: %v", err)
+		s.sendMessage(clientID, customerPhone, "❌ Maaf, lokasi tidak dapat diproses. Silakan coba lagi.")
+		return
+	}
+
+	if len(stores) == 0 {
+		reply := "🙏 Maaf, saat ini belum ada informasi lokasi toko yang tersedia."
+		s.sendMessage(clientID, customerPhone, reply)
+		s.logLocationConversation(clientID, customerPhone, inbound, reply)
+		return
+	}
+
+	nearest := &stores[0]
+	reply := FormatNearestStoreReply(nearest)
+	if err := s.sendMessage(clientID, customerPhone, reply); err != nil {
+		log.Printf("❌ Failed to send nearest store reply: %v", err)
+		return
+	}
+
+	if err := s.sendLocation(clientID, customerPhone, storeLocation(nearest)); err != nil {
+		log.Printf("⚠️ Failed to send store location pin: %v", err)
+	}
+
+	// An open cart is fulfilled from the nearest branch
+	if _, err := s.cartService.SetBranch(clientID, customerPhone, nearest.ID); err == nil {
+		s.sendMessage(clientID, customerPhone, fmt.Sprintf("🛒 Pesanan Anda akan diproses dari cabang *%s*.", nearest.Name))
+	}
+
+	log.Printf("✅ Nearest store for %s: %s (%.1f km)", customerPhone, nearest.Name, *nearest.DistanceKm)
+	s.logLocationConversation(clientID, customerPhone, inbound, reply)
+}
+
+// replyStoreList answers a store locator question with the client's stores
+// Returns false when the client has no stores configured, so the AI answers instead
+func (s *WebhookService) replyStoreList(client *models.Client, customerPhone string) (string, bool) {
+	if s.storeService == nil {
+		return "", false
+	}
+
+	stores, err := s.storeService.ListStores(client.ID, true)
+	if err != nil || len(stores) == 0 {
+		return "", false
+	}
+
+	clientID := client.ID.String()
+	reply := FormatStoreList(stores)
+	if err := s.sendMessage(clientID, customerPhone, reply); err != nil {
+		log.Printf("❌ Failed to send store list: %v", err)
+		return "", false
+	}
+
+	// A single store gets its location pin right away
+	if len(stores) == 1 {
+		if err := s.sendLocation(clientID, customerPhone, storeLocation(&stores[0])); err != nil {
+			log.Printf("⚠️ Failed to send store location pin: %v", err)
+		}
+	}
+
+	return reply, true
+}
+
+// sendLocation queues a location pin of a client for delivery, captured as text in sandbox mode
+func (s *WebhookService) sendLocation(clientID, to string, location whatsapp.Location) error {
+	if s.sandboxService != nil && s.sandboxService.IsSandbox(clientID) {
+		return s.sandboxService.SendMessage(clientID, to, locationText(location))
+	}
+	if s.outbound != nil {
+		return s.outbound.EnqueueLocation(clientID, to, location)
+	}
+	if s.sessions != nil {
+		return s.sessions.SendLocation(clientID, to, location)
+	}
+	return s.whatsappService.SendLocation(to, location)
+}
+
+// logLocationConversation logs a location exchange to the conversation history
+func (s *WebhookService) logLocationConversation(clientID, customerPhone, message, reply string) {
+	if err := s.conversationRepo.LogConversation(clientID, customerPhone, message, reply); err != nil {
+		log.Printf("⚠️ Failed to log conversation: %v", err)
+	}
+}
+
+// locationText describes a location pin as text, with a Google Maps link
+func locationText(location whatsapp.Location) string {
+	return fmt.Sprintf("📍 %s\n%s\nhttps://maps.google.com/?q=%.6f,%.6f",
+		location.Name, location.Address, location.Latitude, location.Longitude)
+}
+
+// storeLocation converts a store into a WhatsApp location pin
+func storeLocation(store *models.Store) whatsapp.Location {
+	return whatsapp.Location{
+		Latitude:  store.Latitude,
+		Longitude: store.Longitude,
+		Name:      store.Name,
+		Address:   store.Address,
+	}
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// SetCustomerPreferenceService lets the bot remember what customers prefer and apply it to replies and checkout
+func (s *WebhookService) SetCustomerPreferenceService(preferenceSvc *CustomerPreferenceService) {
+	s.preferenceSvc = preferenceSvc
+}
+
+// handleSetPreference stores a preference the customer stated; the LLM already confirmed it in its reply
+func (s *WebhookService) handleSetPreference(clientID, customerPhone, key, value string) {
+	if s.preferenceSvc == nil {
+		return
+	}
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		return
+	}
+	if err := s.preferenceSvc.SetFromChat(uid, customerPhone, key, value); err != nil {
+		log.Printf("⚠️ Failed to save %s preference of %s: %v", key, customerPhone, err)
+		return
+	}
+	log.Printf("📌 Saved %s preference of %s", key, customerPhone)
+}
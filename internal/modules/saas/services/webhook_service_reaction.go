@@ -0,0 +1,40 @@
+package services
+
+import "log"
+
+// ProcessReaction handles a customer's emoji reaction to a message
+func (s *WebhookService) ProcessReaction(sessionID, customerPhone, emoji, messageID string) {
+	log.Printf("👍 Processing reaction %s from %s (session: %s) on %s", emoji, customerPhone, sessionID, messageID)
+
+	if s.reactionSvc == nil {
+		return
+	}
+
+	tenantCtx, err := s.tenantResolver.ResolveFromSession(sessionID, customerPhone)
+	if err != nil {
+		log.Printf("❌ Failed to resolve tenant for %s: %v", customerPhone, err)
+		return
+	}
+
+	client, err := s.clientRepo.GetByID(tenantCtx.ClientID)
+	if err != nil {
+		log.Printf("❌ No client found for ID '%s': %v", tenantCtx.ClientID, err)
+		return
+	}
+
+	// Reactions are only intents for customers; while the bot is paused they only reach workflows
+	act := tenantCtx.Role == "customer" && (s.botPauseSvc == nil || !s.botPauseSvc.IsPaused(client))
+
+	result := s.reactionSvc.Handle(client, customerPhone, emoji, messageID, act)
+	if result.Action == "" {
+		return
+	}
+
+	clientID := client.ID.String()
+	if result.Reply != "" {
+		s.sendMessage(clientID, customerPhone, result.Reply)
+	}
+	if err := s.conversationRepo.LogConversation(clientID, customerPhone, "[Reaksi] "+emoji, result.Reply); err != nil {
+		log.Printf("⚠️ Failed to log conversation: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// SetRecommendationService enables complementary product suggestions after add-to-cart and checkout
+func (s *WebhookService) SetRecommendationService(recommendSvc *RecommendationService) {
+	s.recommendSvc = recommendSvc
+}
+
+// recommendAfterAddToCart suggests products that go with the cart, about the product just added
+func (s *WebhookService) recommendAfterAddToCart(ctx context.Context, clientID, customerPhone, added, message string, products []llm.Product) {
+	if s.recommendSvc == nil {
+		return
+	}
+
+	cart, err := s.cartService.ViewCart(clientID, customerPhone)
+	if err != nil {
+		return
+	}
+	basket := []string{added}
+	for _, item := range cart.Items {
+		if item.ProductName != added {
+			basket = append(basket, item.ProductName)
+		}
+	}
+
+	if reply := s.recommendSvc.Suggest(ctx, clientID, customerPhone, models.RecommendationAfterAddToCart, basket, message, products); reply != "" {
+		s.sendMessage(clientID, customerPhone, reply)
+	}
+}
+
+// recommendAfterCheckout suggests products that go with an order just placed
+func (s *WebhookService) recommendAfterCheckout(ctx context.Context, order *models.Order, message string, products []llm.Product) {
+	if s.recommendSvc == nil {
+		return
+	}
+
+	var items []models.OrderItem
+	if err := json.Unmarshal(order.Items, &items); err != nil {
+		return
+	}
+	basket := make([]string, 0, len(items))
+	for _, item := range items {
+		basket = append(basket, item.ProductName)
+	}
+
+	clientID := order.ClientID.String()
+	if reply := s.recommendSvc.Suggest(ctx, clientID, order.CustomerPhone, models.RecommendationAfterCheckout, basket, message, products); reply != "" {
+		s.sendMessage(clientID, order.CustomerPhone, reply)
+	}
+}
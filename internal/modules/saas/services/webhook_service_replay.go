@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// ReplyDraft is the answer the bot would give to a message, with what was sent to the LLM
+type ReplyDraft struct {
+	Response string
+	Commands []string // Cart commands of the answer, not executed
+	Prompt   string   // System prompt, history and message, for cost estimates
+}
+
+// DraftReply answers a logged customer message the way respondToText would today, with the given LLM,
+// without sending anything, running cart commands or touching customer state. The history is the chat as
+// it was when the message came in. Flows answered without the LLM (onboarding, COD and quote replies,
+// store list) are not reproduced.
+func (s *WebhookService) DraftReply(ctx context.Context, llmService *llm.Service, client *models.Client, knowledgeBase *llm.KnowledgeBase, conv *models.Conversation) (*ReplyDraft, error) {
+	clientID := client.ID.String()
+	message := conv.MessageText
+
+	systemPrompt := llm.BuildSystemPrompt(knowledgeBase)
+
+	replyLang := ""
+	if s.onboardingSvc != nil {
+		replyLang = s.onboardingSvc.CustomerLanguage(clientID, conv.CustomerPhone)
+	}
+	if s.languageSvc != nil {
+		replyLang, _ = s.languageSvc.ReplyLanguage(clientID, message, replyLang)
+	}
+	systemPrompt += llm.LanguageInstruction(replyLang)
+	systemPrompt += quotedContextPrompt(s.lookupQuoted(clientID, MessageRef{ReplyToID: conv.ReplyToMessageID}), client.Timezone)
+
+	history := s.memory.HistoryBefore(clientID, conv.CustomerPhone, conv.CreatedAt)
+
+	var prompt strings.Builder
+	prompt.WriteString(systemPrompt)
+	for _, msg := range history {
+		prompt.WriteString(msg.Content)
+	}
+	prompt.WriteString(message)
+
+	aiResponse, err := llmService.GenerateResponseWithHistory(ctx, systemPrompt, history, message)
+	if err != nil {
+		return nil, err
+	}
+
+	cleanResponse, commands := s.parseCartCommands(aiResponse)
+	if s.languageSvc != nil {
+		cleanResponse = s.languageSvc.MatchResponse(ctx, clientID, cleanResponse, replyLang)
+	}
+
+	draft := &ReplyDraft{Response: cleanResponse, Prompt: prompt.String()}
+	for _, cmd := range commands {
+		if cmd.Action == "ADD_TO_CART" {
+			draft.Commands = append(draft.Commands, fmt.Sprintf("ADD_TO_CART:%s|%d", cmd.ProductName, cmd.Quantity))
+		} else {
+			draft.Commands = append(draft.Commands, cmd.Action)
+		}
+	}
+	return draft, nil
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// agentFromPhone is the agent recorded for replies typed on the business phone, which WhatsApp doesn't attribute to a person
+const agentFromPhone = "whatsapp_app"
+
+// ProcessOwnMessage records a reply someone typed on the business phone: it is logged as an agent turn of the
+// conversation and counted as an agent response for SLA tracking.
+// Messages sent through the API (the bot, notifications) are not agent responses.
+func (s *WebhookService) ProcessOwnMessage(clientID uuid.UUID, customerPhone, source, text, mediaURL string) {
+	if source != "app" || customerPhone == "" {
+		return
+	}
+
+	client, err := s.clientRepo.GetByID(clientID.String())
+	if err != nil {
+		log.Printf("❌ No client found for ID '%s': %v", clientID, err)
+		return
+	}
+
+	if text != "" || mediaURL != "" {
+		turn := &models.Conversation{
+			ClientID:      client.ID,
+			CustomerPhone: customerPhone,
+			MessageType:   models.ConversationTypeAgent,
+			AIResponse:    text,
+			MediaURL:      mediaURL,
+		}
+		if err := s.conversationRepo.LogTurn(turn); err != nil {
+			log.Printf("⚠️ Failed to log agent reply: %v", err)
+		}
+	}
+
+	if s.slaSvc != nil {
+		s.slaSvc.RecordResponse(client, customerPhone, agentFromPhone)
+	}
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// isTagCommand reports whether a message is a TAG, UNTAG or TAGS chat command
+func isTagCommand(message string) bool {
+	fields := strings.Fields(strings.ToUpper(message))
+	if len(fields) == 0 {
+		return false
+	}
+	switch fields[0] {
+	case "TAG", "UNTAG", "TAGS":
+		return true
+	}
+	return false
+}
+
+// handleTagCommand lets admins and staff tag customer chats from WhatsApp
+// Format: TAG 08123456789 komplain vip, UNTAG 08123456789 vip, TAGS 08123456789, TAGS
+func (s *WebhookService) handleTagCommand(clientID, staffPhone, message string) {
+	usage := "Gunakan:\n" +
+		"TAG <nomor> <tag> [tag...] - tambah tag ke chat pelanggan\n" +
+		"UNTAG <nomor> <tag> - hapus tag\n" +
+		"TAGS <nomor> - lihat tag chat pelanggan\n" +
+		"TAGS - lihat daftar tag\n\n" +
+		"Contoh:\n" +
+		"TAG 08123456789 komplain"
+
+	uid, err := uuid.Parse(clientID)
+	if err != nil {
+		log.Printf("❌ Invalid client ID: %s - %v", clientID, err)
+		return
+	}
+
+	fields := strings.Fields(message)
+	command := strings.ToUpper(fields[0])
+
+	if command == "TAGS" && len(fields) == 1 {
+		tags, err := s.tagSvc.ListTags(uid)
+		if err != nil {
+			log.Printf("❌ Failed to list tags: %v", err)
+			s.sendMessage(clientID, staffPhone, "❌ Gagal memuat daftar tag!")
+			return
+		}
+		if len(tags) == 0 {
+			s.sendMessage(clientID, staffPhone, "🏷️ Belum ada tag. Buat tag terlebih dahulu dari dashboard.")
+			return
+		}
+		names := make([]string, 0, len(tags))
+		for _, tag := range tags {
+			names = append(names, "- "+tag.Name)
+		}
+		s.sendMessage(clientID, staffPhone, "🏷️ *Daftar Tag*\n\n"+strings.Join(names, "\n"))
+		return
+	}
+
+	if len(fields) < 2 || (command != "TAGS" && len(fields) < 3) {
+		s.sendMessage(clientID, staffPhone, "❌ Format salah!\n\n"+usage)
+		return
+	}
+
+	customerPhone := normalizePhone(fields[1])
+
+	switch command {
+	case "TAG":
+		added, err := s.tagSvc.TagConversation(uid, customerPhone, fields[2:], models.TagSourceCommand, staffPhone)
+		if err != nil {
+			var unknown *UnknownTagsError
+			if errors.As(err, &unknown) {
+				s.sendMessage(clientID, staffPhone, fmt.Sprintf("❌ Tag tidak dikenal: %s\n\nKetik *TAGS* untuk melihat daftar tag.", strings.Join(unknown.Names, ", ")))
+				return
+			}
+			log.Printf("❌ Failed to tag chat %s: %v", customerPhone, err)
+			s.sendMessage(clientID, staffPhone, "❌ Gagal menambah tag!\n\nError: "+err.Error())
+			return
+		}
+		if len(added) == 0 {
+			s.sendMessage(clientID, staffPhone, "ℹ️ Chat "+customerPhone+" sudah memiliki tag tersebut.")
+			return
+		}
+		s.sendMessage(clientID, staffPhone, "✅ Tag ditambahkan ke chat "+customerPhone+": "+strings.Join(added, ", "))
+
+	case "UNTAG":
+		if err := s.tagSvc.UntagConversation(uid, customerPhone, fields[2]); err != nil {
+			if errors.Is(err, ErrTagNotFound) {
+				s.sendMessage(clientID, staffPhone, "❌ Tag tidak dikenal: "+fields[2]+"\n\nKetik *TAGS* untuk melihat daftar tag.")
+				return
+			}
+			log.Printf("❌ Failed to untag chat %s: %v", customerPhone, err)
+			s.sendMessage(clientID, staffPhone, "❌ Gagal menghapus tag!\n\nError: "+err.Error())
+			return
+		}
+		s.sendMessage(clientID, staffPhone, "✅ Tag "+NormalizeTagName(fields[2])+" dihapus dari chat "+customerPhone)
+
+	case "TAGS":
+		tags, err := s.tagSvc.CustomerTags(uid, customerPhone)
+		if err != nil {
+			log.Printf("❌ Failed to load tags of %s: %v", customerPhone, err)
+			s.sendMessage(clientID, staffPhone, "❌ Gagal memuat tag!")
+			return
+		}
+		if len(tags) == 0 {
+			s.sendMessage(clientID, staffPhone, "🏷️ Chat "+customerPhone+" belum memiliki tag.")
+			return
+		}
+		s.sendMessage(clientID, staffPhone, "🏷️ Tag chat "+customerPhone+": "+strings.Join(tags, ", "))
+	}
+}
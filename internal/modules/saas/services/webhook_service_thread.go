@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// quotedContextLimit caps how much of a quoted message is passed to the LLM
+const quotedContextLimit = 500
+
+// MessageRef identifies an inbound provider message and the message it quotes (all optional)
+type MessageRef struct {
+	ID            string // Provider message ID of the customer's message
+	ReplyToID     string // Provider message ID the customer quoted
+	ReplyToBody   string // Quoted text, when the provider includes it
+	ReplyToFromMe bool   // Whether the quoted message was sent by the business number
+}
+
+// threadID is the message the bot's reply should quote: the customer's message, when they replied in a thread
+func (r MessageRef) threadID() string {
+	if r.ReplyToID == "" {
+		return ""
+	}
+	return r.ID
+}
+
+// quotedMessage is an earlier message the customer replied to
+type quotedMessage struct {
+	FromBot  bool
+	Text     string
+	Question string // For a quoted bot reply: the customer message it answered
+	SentAt   *time.Time
+}
+
+// lookupQuoted resolves the message the customer quoted, from the stored message IDs or the quoted text in the payload
+func (s *WebhookService) lookupQuoted(clientID string, ref MessageRef) *quotedMessage {
+	if ref.ReplyToID == "" && ref.ReplyToBody == "" {
+		return nil
+	}
+
+	if ref.ReplyToID != "" {
+		if conversation, err := s.conversationRepo.GetByMessageID(clientID, ref.ReplyToID); err == nil {
+			if sameMessageID(conversation.OutboundMessageID, ref.ReplyToID) {
+				return &quotedMessage{FromBot: true, Text: conversation.AIResponse, Question: conversation.MessageText, SentAt: &conversation.CreatedAt}
+			}
+			return &quotedMessage{Text: conversation.MessageText, SentAt: &conversation.CreatedAt}
+		}
+	}
+
+	if strings.TrimSpace(ref.ReplyToBody) == "" {
+		return nil
+	}
+	return &quotedMessage{FromBot: ref.ReplyToFromMe, Text: ref.ReplyToBody}
+}
+
+// sameMessageID reports whether a stored provider message ID is id, either exactly or as its bare stanza ID
+func sameMessageID(stored, id string) bool {
+	return stored != "" && (stored == id || strings.HasSuffix(stored, "_"+id))
+}
+
+// quotedContextPrompt tells the LLM which earlier message the customer is answering
+func quotedContextPrompt(quoted *quotedMessage, timezone string) string {
+	if quoted == nil || strings.TrimSpace(quoted.Text) == "" {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n\n=== PESAN YANG DIBALAS CUSTOMER ===\n")
+
+	sender := "customer sendiri"
+	if quoted.FromBot {
+		sender = "Anda"
+	}
+	if quoted.SentAt != nil {
+		sb.WriteString(fmt.Sprintf("Customer membalas (quote) pesan dari %s yang dikirim %s:\n", sender, quoted.SentAt.In(clientLocation(timezone)).Format("02/01 15:04")))
+	} else {
+		sb.WriteString(fmt.Sprintf("Customer membalas (quote) pesan dari %s:\n", sender))
+	}
+	sb.WriteString(fmt.Sprintf("\"%s\"\n", truncateQuote(quoted.Text)))
+
+	if quoted.Question != "" {
+		sb.WriteString(fmt.Sprintf("Pesan tersebut menjawab pertanyaan customer: \"%s\"\n", truncateQuote(quoted.Question)))
+	}
+	sb.WriteString("Jawab pesan customer dengan mengacu pada pesan yang dikutip tersebut.")
+	return sb.String()
+}
+
+// truncateQuote caps how much of a quoted message is put in the prompt
+func truncateQuote(text string) string {
+	text = strings.TrimSpace(text)
+	if runes := []rune(text); len(runes) > quotedContextLimit {
+		return string(runes[:quotedContextLimit]) + "..."
+	}
+	return text
+}
+
+// sendReply sends a WhatsApp message quoting quotedID (if set) and returns the provider message ID.
+// Sandbox messages and replies queued for retry have no provider ID.
+func (s *WebhookService) sendReply(ctx context.Context, clientID, to, message, quotedID string) (string, error) {
+	_, span := tracing.Start(ctx, "whatsapp.send", attribute.String("whatsapp.provider", s.whatsappService.GetProviderName()))
+	defer span.End()
+
+	if s.sandboxService != nil && s.sandboxService.IsSandbox(clientID) {
+		return "", s.sandboxService.SendMessage(clientID, to, message)
+	}
+
+	var messageID string
+	var err error
+	if s.sessions != nil {
+		messageID, err = s.sessions.SendReply(clientID, to, message, quotedID)
+	} else {
+		messageID, err = s.whatsappService.SendReply(to, message, quotedID)
+	}
+	if err != nil {
+		tracing.Fail(span, err)
+		// Replies are sent right away for their message ID; a failed one is retried from the outbound queue
+		if s.outbound == nil {
+			return "", err
+		}
+		log.Printf("⚠️ Reply to %s failed, queued for retry: %v", to, err)
+		return "", s.outbound.Enqueue(clientID, to, message, quotedID)
+	}
+	if messageID == "" {
+		log.Printf("ℹ️ %s returned no message ID for reply to %s", s.whatsappService.GetProviderName(), to)
+	}
+	return messageID, nil
+}
+
+// SetOutboundQueue retries replies that fail to send from the outbound queue
+func (s *WebhookService) SetOutboundQueue(outbound *OutboundMessageService) {
+	s.outbound = outbound
+}
+
+// SetSessionManager sends each client's replies through the client's own WhatsApp session
+func (s *WebhookService) SetSessionManager(sessions *whatsapp.SessionManager) {
+	s.sessions = sessions
+}
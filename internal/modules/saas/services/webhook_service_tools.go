@@ -0,0 +1,341 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/agent"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// maxToolProducts is how many products a lookup_product call returns
+const maxToolProducts = 5
+
+// maxToolOrders is how many recent orders a check_order_status call returns
+const maxToolOrders = 3
+
+// toolTurn is one AI reply that can call the commerce tools for a customer
+type toolTurn struct {
+	clientID      string
+	customerPhone string
+	products      []llm.Product
+	box           *agent.Toolbox
+
+	lastAdded string        // Product most recently added with add_to_cart
+	order     *models.Order // Order placed with create_order
+}
+
+// toolHandledCommands are the cart commands replaced by tools; the model is told not to write them
+var toolHandledCommands = map[string]bool{
+	"ADD_TO_CART":  true,
+	"CHECKOUT":     true,
+	"CHECKOUT_COD": true,
+}
+
+// newToolTurn offers the model the commerce tools for a customer's message: product lookup, order status,
+// add to cart and checkout. Cart and checkout reuse the cart command handlers, so the customer gets the
+// same confirmations and payment instructions either way.
+func (s *WebhookService) newToolTurn(clientID, customerPhone string, products []llm.Product) *toolTurn {
+	turn := &toolTurn{
+		clientID:      clientID,
+		customerPhone: customerPhone,
+		products:      products,
+		box:           agent.NewToolbox(),
+	}
+
+	turn.box.Register(llm.Tool{
+		Name:        "lookup_product",
+		Description: "Find products in the store catalog by name, SKU or description. Returns price, stock and availability.",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query": map[string]interface{}{"type": "string", "description": "Product name or keywords"},
+			},
+			"required": []string{"query"},
+		},
+	}, func(ctx context.Context, args json.RawMessage) (string, error) {
+		return s.toolLookupProduct(turn, args)
+	})
+
+	if s.orderService != nil {
+		turn.box.Register(llm.Tool{
+			Name:        "check_order_status",
+			Description: "Get the payment and delivery status of the customer's order, or of their latest orders when no order number is given.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"order_number": map[string]interface{}{"type": "string", "description": "Order number, if the customer gave one"},
+				},
+			},
+		}, func(ctx context.Context, args json.RawMessage) (string, error) {
+			return s.toolCheckOrderStatus(turn, args)
+		})
+	}
+
+	if s.cartService != nil {
+		turn.box.Register(llm.Tool{
+			Name:        "add_to_cart",
+			Description: "Add a product to the customer's cart. The customer is sent the new cart total.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"product_name": map[string]interface{}{"type": "string", "description": "Product name exactly as in the catalog"},
+					"quantity":     map[string]interface{}{"type": "integer", "description": "Quantity, at least 1"},
+				},
+				"required": []string{"product_name", "quantity"},
+			},
+		}, func(ctx context.Context, args json.RawMessage) (string, error) {
+			return s.toolAddToCart(turn, args)
+		})
+	}
+
+	if s.cartService != nil && s.orderService != nil {
+		turn.box.Register(llm.Tool{
+			Name:        "create_order",
+			Description: "Check out the customer's cart into an order. The customer is sent the payment instructions.",
+			Parameters: map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"payment_method": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{"online", "cod"},
+						"description": "cod for cash on delivery, online otherwise",
+					},
+				},
+			},
+		}, func(ctx context.Context, args json.RawMessage) (string, error) {
+			return s.toolCreateOrder(turn, args)
+		})
+	}
+
+	return turn
+}
+
+// toolLookupProduct searches the product catalog, then the knowledge base products
+func (s *WebhookService) toolLookupProduct(turn *toolTurn, args json.RawMessage) (string, error) {
+	var req struct {
+		Query string `json:"query"`
+	}
+	if err := agent.DecodeArgs(args, &req); err != nil {
+		return "", err
+	}
+	query := strings.TrimSpace(req.Query)
+	if query == "" {
+		return "", fmt.Errorf("query is required")
+	}
+
+	type productResult struct {
+		Name        string  `json:"name"`
+		Price       float64 `json:"price"`
+		Stock       *int    `json:"stock,omitempty"`
+		Available   bool    `json:"available"`
+		Preorder    bool    `json:"preorder,omitempty"`
+		Category    string  `json:"category,omitempty"`
+		Description string  `json:"description,omitempty"`
+	}
+	results := []productResult{}
+
+	if clientUUID, err := uuid.Parse(turn.clientID); err == nil && s.productService != nil {
+		active := true
+		list, err := s.productService.ListProducts(models.ProductFilter{
+			ClientID:   clientUUID,
+			IsActive:   &active,
+			SearchTerm: query,
+			Page:       1,
+			PageSize:   maxToolProducts,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to search products: %w", err)
+		}
+		for i := range list.Products {
+			product := &list.Products[i]
+			stock := product.Stock
+			results = append(results, productResult{
+				Name:        product.Name,
+				Price:       product.Price,
+				Stock:       &stock,
+				Available:   product.IsAvailable(),
+				Preorder:    !product.IsAvailable() && product.CanPreorder(),
+				Category:    product.Category,
+				Description: product.Description,
+			})
+		}
+	}
+
+	// Clients without a catalog list their products in the knowledge base
+	if len(results) == 0 {
+		lowered := strings.ToLower(query)
+		for _, product := range turn.products {
+			if strings.Contains(strings.ToLower(product.Name), lowered) {
+				results = append(results, productResult{Name: product.Name, Price: product.Price, Available: true})
+				if len(results) == maxToolProducts {
+					break
+				}
+			}
+		}
+	}
+
+	return agent.Result(map[string]interface{}{"products": results})
+}
+
+// toolCheckOrderStatus returns the status of one of the customer's orders, or of their latest orders
+func (s *WebhookService) toolCheckOrderStatus(turn *toolTurn, args json.RawMessage) (string, error) {
+	var req struct {
+		OrderNumber string `json:"order_number"`
+	}
+	if err := agent.DecodeArgs(args, &req); err != nil {
+		return "", err
+	}
+
+	var orders []models.Order
+	if number := strings.TrimSpace(req.OrderNumber); number != "" {
+		order, err := s.orderService.GetOrderByOrderNumber(number)
+		// Only the customer's own orders are disclosed
+		if err != nil || order.ClientID.String() != turn.clientID || order.CustomerPhone != turn.customerPhone {
+			return agent.Result(map[string]interface{}{"orders": []interface{}{}, "note": "no order with this number for this customer"})
+		}
+		orders = []models.Order{*order}
+	} else {
+		found, err := s.orderService.ListCustomerOrders(turn.clientID, turn.customerPhone, maxToolOrders)
+		if err != nil {
+			return "", fmt.Errorf("failed to list orders: %w", err)
+		}
+		orders = found
+	}
+
+	type orderResult struct {
+		OrderNumber       string  `json:"order_number"`
+		CreatedAt         string  `json:"created_at"`
+		Total             float64 `json:"total"`
+		PaymentMethod     string  `json:"payment_method,omitempty"`
+		PaymentStatus     string  `json:"payment_status"`
+		FulfillmentStatus string  `json:"fulfillment_status"`
+		Courier           string  `json:"courier,omitempty"`
+		TrackingNumber    string  `json:"tracking_number,omitempty"`
+	}
+	results := make([]orderResult, 0, len(orders))
+	for _, order := range orders {
+		results = append(results, orderResult{
+			OrderNumber:       order.OrderNumber,
+			CreatedAt:         order.CreatedAt.Format(time.RFC3339),
+			Total:             order.TotalAmount,
+			PaymentMethod:     order.PaymentMethod,
+			PaymentStatus:     order.PaymentStatus,
+			FulfillmentStatus: order.FulfillmentStatus,
+			Courier:           order.ShippingCourier,
+			TrackingNumber:    order.ShippingTrackingNumber,
+		})
+	}
+	return agent.Result(map[string]interface{}{"orders": results})
+}
+
+// toolAddToCart adds a product to the cart like the ADD_TO_CART command
+func (s *WebhookService) toolAddToCart(turn *toolTurn, args json.RawMessage) (string, error) {
+	var req struct {
+		ProductName string `json:"product_name"`
+		Quantity    int    `json:"quantity"`
+	}
+	if err := agent.DecodeArgs(args, &req); err != nil {
+		return "", err
+	}
+	name := strings.TrimSpace(req.ProductName)
+	if name == "" {
+		return "", fmt.Errorf("product_name is required")
+	}
+	if req.Quantity < 1 {
+		req.Quantity = 1
+	}
+
+	if !s.handleAddToCart(turn.clientID, turn.customerPhone, name, req.Quantity, turn.products) {
+		return agent.Result(map[string]interface{}{
+			"added": false,
+			"note":  "not added; the customer was already told why (unknown product, out of stock or an error)",
+		})
+	}
+	turn.lastAdded = name
+
+	result := map[string]interface{}{"added": true, "product": name, "quantity": req.Quantity}
+	if cart, err := s.cartService.ViewCart(turn.clientID, turn.customerPhone); err == nil {
+		result["cart_items"] = len(cart.Items)
+		result["cart_total"] = cart.TotalAmount
+	}
+	return agent.Result(result)
+}
+
+// toolCreateOrder checks the cart out like the CHECKOUT and CHECKOUT_COD commands
+func (s *WebhookService) toolCreateOrder(turn *toolTurn, args json.RawMessage) (string, error) {
+	var req struct {
+		PaymentMethod string `json:"payment_method"`
+	}
+	if err := agent.DecodeArgs(args, &req); err != nil {
+		return "", err
+	}
+	method := ""
+	if strings.EqualFold(req.PaymentMethod, payment.MethodCOD) {
+		method = payment.MethodCOD
+	}
+
+	order := s.handleCheckout(turn.clientID, turn.customerPhone, method)
+	if order == nil {
+		return agent.Result(map[string]interface{}{
+			"created": false,
+			"note":    "no order was created; the customer was already told why (empty cart, stock, COD not allowed or an error)",
+		})
+	}
+	turn.order = order
+
+	return agent.Result(map[string]interface{}{
+		"created":        true,
+		"order_number":   order.OrderNumber,
+		"total":          order.TotalAmount,
+		"payment_method": order.PaymentMethod,
+		"note":           "the customer was sent the payment instructions",
+	})
+}
+
+// generate calls the LLM, with the commerce tools when the turn offers them
+func (s *WebhookService) generate(ctx context.Context, systemPrompt string, history []llm.ChatMessage, message string, tools *toolTurn) (string, error) {
+	if tools == nil {
+		return s.llmService.GenerateResponseWithHistory(ctx, systemPrompt, history, message)
+	}
+
+	response, steps, err := s.llmService.GenerateWithTools(ctx, systemPrompt, history, message, tools.box.Tools(), tools.box.Execute)
+	if len(steps) > 0 {
+		calls := 0
+		for _, step := range steps {
+			calls += len(step.Calls)
+		}
+		log.Printf("🧰 %d tool calls in %d rounds for %s", calls, len(steps), tools.customerPhone)
+	}
+	return response, err
+}
+
+// withoutToolCommands drops the cart commands the tools replaced, so a model that writes them anyway
+// doesn't add to the cart or check out twice
+func withoutToolCommands(commands []CartCommand) []CartCommand {
+	kept := commands[:0]
+	for _, cmd := range commands {
+		if toolHandledCommands[cmd.Action] {
+			log.Printf("⚠️ Ignoring %s command, handled by tools", cmd.Action)
+			continue
+		}
+		kept = append(kept, cmd)
+	}
+	return kept
+}
+
+// recommendAfterTools suggests complementary products once the reply is sent, as after the cart commands
+func (s *WebhookService) recommendAfterTools(ctx context.Context, tools *toolTurn, message string) {
+	if tools.order != nil {
+		s.recommendAfterCheckout(ctx, tools.order, message, tools.products)
+	} else if tools.lastAdded != "" {
+		s.recommendAfterAddToCart(ctx, tools.clientID, tools.customerPhone, tools.lastAdded, message, tools.products)
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/stt"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tenant"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/tracing"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// SetSTTService enables transcription of customer voice notes
+func (s *WebhookService) SetSTTService(sttService *stt.Service) {
+	s.sttService = sttService
+}
+
+// ProcessVoiceMessage transcribes a voice note and answers it like a text message
+func (s *WebhookService) ProcessVoiceMessage(ctx context.Context, sessionID, customerPhone, mediaURL, mimeType string, ref MessageRef) {
+	defer metrics.ObserveWebhookProcessing("voice", time.Now())
+
+	ctx, span := tracing.Start(context.WithoutCancel(ctx), "message.process", attribute.String("message.type", "voice"))
+	defer span.End()
+
+	log.Printf("🎤 Processing voice note from %s (session: %s): %s", customerPhone, sessionID, mediaURL)
+
+	// 1. Resolve tenant context
+	tenantCtx, err := s.resolveTenant(ctx, sessionID, customerPhone)
+	if err != nil {
+		log.Printf("❌ Failed to resolve tenant for %s: %v", customerPhone, err)
+		if !errors.Is(err, tenant.ErrSessionNotMapped) { // Dropped: no tenant may answer on that session
+			s.whatsappService.SendMessage(customerPhone, "Maaf, sistem sedang bermasalah. Silakan hubungi administrator.")
+		}
+		return
+	}
+
+	// 2. Get client details
+	client, err := s.clientRepo.GetByID(tenantCtx.ClientID)
+	if err != nil {
+		log.Printf("❌ No client found for ID '%s': %v", tenantCtx.ClientID, err)
+		return
+	}
+
+	s.recordCustomer(client, tenantCtx.Role, customerPhone)
+
+	if !s.messageTypeAllowed(client, models.MessageFeatureVoice, customerPhone, "[Pesan suara] "+mediaURL) {
+		return
+	}
+
+	clientID := client.ID.String()
+	if s.sttService == nil {
+		s.sendMessage(clientID, customerPhone, "🙏 Maaf, kami belum bisa memproses pesan suara. Silakan kirim pesan dalam bentuk teks ya.")
+		return
+	}
+
+	// 3. Download and transcribe the voice note
+	audio, err := s.downloadMedia(mediaURL)
+	if err != nil {
+		log.Printf("❌ Failed to download voice note: %v", err)
+		s.sendMessage(clientID, customerPhone, "❌ Maaf, gagal mengunduh pesan suara. Silakan coba kirim ulang atau ketik pesan Anda.")
+		return
+	}
+
+	sttCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	transcript, err := s.sttService.Transcribe(sttCtx, audio, mimeType)
+	cancel()
+	if err != nil {
+		log.Printf("❌ Voice transcription failed (%s): %v", s.sttService.GetProviderName(), err)
+		s.sendMessage(clientID, customerPhone, "🙏 Maaf, pesan suara Anda belum bisa kami proses. Silakan ketik pesan Anda ya.")
+		return
+	}
+	if transcript.Text == "" {
+		s.sendMessage(clientID, customerPhone, "🙏 Maaf, pesan suara Anda kurang jelas. Boleh diulang atau diketik saja?")
+		return
+	}
+
+	log.Printf("🎤 Transcribed %d bytes of audio for %s (%s): %s", len(audio), customerPhone, s.sttService.GetProviderName(), transcript.Text)
+
+	// 4. Answer the transcript through the normal chat pipeline
+	ctx, cancel = context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	s.respondToText(ctx, client, tenantCtx.Role, customerPhone, transcript.Text, ref)
+}
@@ -0,0 +1,27 @@
+package services
+
+import "log"
+
+// SetWalletService lets customers check their prepaid balance and top up in chat
+func (s *WebhookService) SetWalletService(walletSvc *WalletService) {
+	s.walletSvc = walletSvc
+}
+
+// handleWalletMessage answers "saldo" and "isi saldo <nominal>". Returns false for other messages.
+func (s *WebhookService) handleWalletMessage(clientID, customerPhone, message string) bool {
+	if s.walletSvc == nil {
+		return false
+	}
+
+	reply, ok := s.walletSvc.HandleCustomerMessage(clientID, customerPhone, message)
+	if !ok {
+		return false
+	}
+	if reply != "" { // Top-ups send their payment instructions themselves
+		s.sendMessage(clientID, customerPhone, reply)
+	}
+	if err := s.conversationRepo.LogConversation(clientID, customerPhone, message, reply); err != nil {
+		log.Printf("⚠️ Failed to log conversation: %v", err)
+	}
+	return true
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+)
+
+// SetWorkflowService lets tenants answer customer messages with keyword triggered workflows
+func (s *WebhookService) SetWorkflowService(workflowSvc *WorkflowService) {
+	s.workflowSvc = workflowSvc
+}
+
+// runMessageWorkflows runs the client's message_received workflows matching a customer message at the
+// given stage. Returns true when a matching workflow answers instead of the AI. Sandbox chats are skipped,
+// as workflow actions send real messages.
+func (s *WebhookService) runMessageWorkflows(ctx context.Context, client *models.Client, stage, customerPhone, message, aiResponse string) bool {
+	if s.workflowSvc == nil || client.SandboxMode {
+		return false
+	}
+
+	data := map[string]interface{}{
+		"client_id":      client.ID.String(),
+		"session_id":     workflowSessionID(client),
+		"from":           customerPhone,
+		"customer_phone": customerPhone,
+		"message":        message,
+		"stage":          stage,
+	}
+	if stage == workflow.MessageStageAfterLLM {
+		data["ai_response"] = aiResponse
+	}
+
+	skipAI := s.workflowSvc.HandleMessage(ctx, client.ID, stage, message, data)
+	if skipAI {
+		log.Printf("⚙️ Workflow answers %s, skipping AI reply", customerPhone)
+		if err := s.conversationRepo.LogConversation(client.ID.String(), customerPhone, message, ""); err != nil {
+			log.Printf("⚠️ Failed to log conversation: %v", err)
+		}
+	}
+	return skipAI
+}
+
+// workflowSessionID is the WhatsApp session workflow actions of the client send messages from
+func workflowSessionID(client *models.Client) string {
+	if client.WhatsAppSessionID == "" {
+		return "default"
+	}
+	return client.WhatsAppSessionID
+}
@@ -0,0 +1,245 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/upload"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	sessionBackupFolder   = "whatsapp-session-backups"
+	sessionBackupKeep     = 72 // Three days of hourly backups
+	sessionBackupMaxBytes = 5 * 1024 * 1024
+)
+
+var (
+	// ErrSessionBackupNotFound is returned when restoring a backup that does not exist, or when none was taken yet
+	ErrSessionBackupNotFound = errors.New("WhatsApp session backup not found")
+	// ErrNoSessionsToBackUp is returned when WAHA has no sessions. An empty instance is what a replaced
+	// container looks like, so it never overwrites the backups that have sessions.
+	ErrNoSessionsToBackUp = errors.New("WAHA has no sessions to back up")
+)
+
+// WhatsAppSessionRestore is the outcome of restoring the sessions of a backup
+type WhatsAppSessionRestore struct {
+	BackupID       uuid.UUID                       `json:"backup_id"`
+	BackupTakenAt  time.Time                       `json:"backup_taken_at"`
+	Restored       int                             `json:"restored"`
+	AlreadyWorking int                             `json:"already_working"`
+	NeedsQR        int                             `json:"needs_qr"` // Restored sessions whose tenant has to scan a new QR code
+	Failed         int                             `json:"failed"`
+	Sessions       []whatsapp.SessionRestoreResult `json:"sessions"`
+}
+
+// WhatsAppSessionBackupService snapshots the WAHA sessions periodically and restores them after the WAHA
+// container is replaced, so tenants only re-pair when WAHA's own session storage was lost too. Snapshots are
+// kept in the database for restores, with a copy in object storage (webhook HMAC keys redacted) for the runbook.
+type WhatsAppSessionBackupService struct {
+	backupRepo    repositories.WhatsAppSessionBackupRepo
+	sessions      *whatsapp.SessionManager
+	uploadService *upload.Service
+}
+
+// NewWhatsAppSessionBackupService creates a new WhatsApp session backup service
+func NewWhatsAppSessionBackupService(backupRepo repositories.WhatsAppSessionBackupRepo, sessions *whatsapp.SessionManager, uploadService *upload.Service) *WhatsAppSessionBackupService {
+	return &WhatsAppSessionBackupService{
+		backupRepo:    backupRepo,
+		sessions:      sessions,
+		uploadService: uploadService,
+	}
+}
+
+// RunBackupJob backs the sessions up periodically
+func (s *WhatsAppSessionBackupService) RunBackupJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Backup(); err != nil && !errors.Is(err, ErrNoSessionsToBackUp) {
+				log.Printf("⚠️ WhatsApp session backup failed: %v", err)
+			}
+		}
+	}
+}
+
+// Backup snapshots every session, stores the snapshot and drops the oldest backups
+func (s *WhatsAppSessionBackupService) Backup() (*models.WhatsAppSessionBackup, error) {
+	snapshot, err := s.sessions.Export()
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshot.Sessions) == 0 {
+		return nil, ErrNoSessionsToBackUp
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	backup := &models.WhatsAppSessionBackup{
+		Provider:     snapshot.Provider,
+		SessionCount: len(snapshot.Sessions),
+		Snapshot:     data,
+	}
+	for _, session := range snapshot.Sessions {
+		if session.Status == "WORKING" {
+			backup.WorkingCount++
+		}
+	}
+	if err := s.storeCopy(snapshot, backup); err != nil {
+		log.Printf("⚠️ Failed to store session backup in object storage: %v", err)
+		backup.StorageError = err.Error()
+	}
+
+	if err := s.backupRepo.Create(backup); err != nil {
+		return nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+	log.Printf("💾 Backed up %d WhatsApp sessions (%d working)", backup.SessionCount, backup.WorkingCount)
+
+	s.prune()
+	return backup, nil
+}
+
+// storeCopy uploads the snapshot without its webhook HMAC keys to object storage
+func (s *WhatsAppSessionBackupService) storeCopy(snapshot *whatsapp.SessionBackup, backup *models.WhatsAppSessionBackup) error {
+	if s.uploadService == nil {
+		return fmt.Errorf("object storage is not configured")
+	}
+
+	data, err := json.MarshalIndent(redactSessionBackup(snapshot), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+
+	filename := fmt.Sprintf("sessions_%s.json", snapshot.CreatedAt.UTC().Format("20060102T150405Z"))
+	res, err := s.uploadService.Upload(bytes.NewReader(data), filename, &upload.UploadOptions{
+		Folder:       sessionBackupFolder,
+		ResourceType: "raw",
+		AllowedTypes: []string{"application/json"},
+		MaxSize:      sessionBackupMaxBytes,
+	})
+	if err != nil {
+		return err
+	}
+
+	backup.FileURL = res.URL
+	if res.SecureURL != "" {
+		backup.FileURL = res.SecureURL
+	}
+	backup.FilePublicID = res.PublicID
+	return nil
+}
+
+// prune deletes the backups beyond the ones kept, with their object storage copies
+func (s *WhatsAppSessionBackupService) prune() {
+	old, err := s.backupRepo.Prune(sessionBackupKeep)
+	if err != nil {
+		log.Printf("⚠️ Failed to prune session backups: %v", err)
+		return
+	}
+	for _, backup := range old {
+		if backup.FilePublicID == "" || s.uploadService == nil {
+			continue
+		}
+		if err := s.uploadService.Delete(backup.FilePublicID); err != nil {
+			log.Printf("⚠️ Failed to delete session backup file %s: %v", backup.FilePublicID, err)
+		}
+	}
+}
+
+// List returns the most recent backups newest first
+func (s *WhatsAppSessionBackupService) List(limit int) ([]models.WhatsAppSessionBackup, error) {
+	if limit <= 0 || limit > sessionBackupKeep {
+		limit = sessionBackupKeep
+	}
+	return s.backupRepo.List(limit)
+}
+
+// Restore recreates the sessions of a backup on the WAHA instance, the latest backup when backupID is nil
+func (s *WhatsAppSessionBackupService) Restore(backupID *uuid.UUID) (*WhatsAppSessionRestore, error) {
+	var backup *models.WhatsAppSessionBackup
+	var err error
+	if backupID != nil {
+		backup, err = s.backupRepo.GetByID(*backupID)
+	} else {
+		backup, err = s.backupRepo.Latest()
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionBackupNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load backup: %w", err)
+	}
+
+	var snapshot whatsapp.SessionBackup
+	if err := json.Unmarshal(backup.Snapshot, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to decode backup: %w", err)
+	}
+
+	results, err := s.sessions.Restore(&snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	restore := &WhatsAppSessionRestore{
+		BackupID:      backup.ID,
+		BackupTakenAt: backup.CreatedAt,
+		Sessions:      results,
+	}
+	for _, result := range results {
+		switch result.Outcome {
+		case whatsapp.RestoreOutcomeRestored:
+			restore.Restored++
+			if result.NeedsQR {
+				restore.NeedsQR++
+			}
+		case whatsapp.RestoreOutcomeAlreadyWorking:
+			restore.AlreadyWorking++
+		case whatsapp.RestoreOutcomeFailed:
+			restore.Failed++
+		}
+	}
+	log.Printf("♻️ Restored WhatsApp sessions from backup %s: %d restored (%d need a QR scan), %d already working, %d failed",
+		backup.ID, restore.Restored, restore.NeedsQR, restore.AlreadyWorking, restore.Failed)
+	return restore, nil
+}
+
+// redactSessionBackup returns a copy of the snapshot with the webhook HMAC keys removed from the session configs
+func redactSessionBackup(snapshot *whatsapp.SessionBackup) *whatsapp.SessionBackup {
+	redacted := *snapshot
+	redacted.Sessions = make([]whatsapp.SessionSnapshot, len(snapshot.Sessions))
+	for i, session := range snapshot.Sessions {
+		redacted.Sessions[i] = session
+
+		var config map[string]interface{}
+		if len(session.Config) == 0 || json.Unmarshal(session.Config, &config) != nil {
+			continue
+		}
+		webhooks, _ := config["webhooks"].([]interface{})
+		for _, webhook := range webhooks {
+			if w, ok := webhook.(map[string]interface{}); ok && w["hmac"] != nil {
+				w["hmac"] = map[string]string{"key": "REDACTED"}
+			}
+		}
+		if data, err := json.Marshal(config); err == nil {
+			redacted.Sessions[i].Config = data
+		}
+	}
+	return &redacted
+}
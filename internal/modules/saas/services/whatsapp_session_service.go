@@ -0,0 +1,123 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/repositories"
+)
+
+// WhatsAppSessionService manages each client's own WhatsApp session and keeps the client's
+// session mapping (whatsapp_session_id) in step, so outbound messages use the right number
+type WhatsAppSessionService struct {
+	clientRepo repositories.ClientRepo
+	sessions   *whatsapp.SessionManager
+}
+
+// NewWhatsAppSessionService creates a new WhatsApp session service
+func NewWhatsAppSessionService(clientRepo repositories.ClientRepo, sessions *whatsapp.SessionManager) *WhatsAppSessionService {
+	return &WhatsAppSessionService{
+		clientRepo: clientRepo,
+		sessions:   sessions,
+	}
+}
+
+// ClientSessionLookup resolves the session of a client from its stored mapping, for the session manager
+func ClientSessionLookup(clientRepo repositories.ClientRepo) whatsapp.SessionLookup {
+	return func(clientID string) (string, error) {
+		client, err := clientRepo.GetByID(clientID)
+		if err != nil {
+			return "", err
+		}
+		return client.WhatsAppSessionID, nil
+	}
+}
+
+// Start creates or starts the client's session (named after the client ID) and maps the client to it
+func (s *WhatsAppSessionService) Start(clientID string) (*whatsapp.SessionState, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+
+	sessionID, err := s.sessions.Start(client.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	if client.WhatsAppSessionID != sessionID {
+		client.WhatsAppSessionID = sessionID
+		if err := s.clientRepo.Update(client); err != nil {
+			return nil, fmt.Errorf("failed to store session mapping: %w", err)
+		}
+		log.Printf("✅ Session mapping stored: client=%s -> session=%s", client.ID, sessionID)
+	}
+
+	return s.sessions.State(client.ID.String())
+}
+
+// Status returns the state of the client's session
+func (s *WhatsAppSessionService) Status(clientID string) (*whatsapp.SessionState, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	return s.sessions.State(client.ID.String())
+}
+
+// QR returns the pairing QR code of the client's session
+func (s *WhatsAppSessionService) QR(clientID string) ([]byte, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	return s.sessions.QR(client.ID.String())
+}
+
+// Restart stops and starts the client's session
+func (s *WhatsAppSessionService) Restart(clientID string) (*whatsapp.SessionState, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	if err := s.sessions.Restart(client.ID.String()); err != nil {
+		return nil, err
+	}
+	return s.sessions.State(client.ID.String())
+}
+
+// Stop stops the client's session, keeping its pairing and mapping
+func (s *WhatsAppSessionService) Stop(clientID string) (*whatsapp.SessionState, error) {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, ErrClientNotFound
+	}
+	if err := s.sessions.Stop(client.ID.String()); err != nil {
+		return nil, err
+	}
+	return s.sessions.State(client.ID.String())
+}
+
+// Delete logs the client's session out, removes it and unmaps the client, whose messages then go
+// through the default session
+func (s *WhatsAppSessionService) Delete(clientID string) error {
+	client, err := s.clientRepo.GetByID(clientID)
+	if err != nil {
+		return ErrClientNotFound
+	}
+	if err := s.sessions.Delete(client.ID.String()); err != nil {
+		return err
+	}
+
+	if client.WhatsAppSessionID != "" {
+		client.WhatsAppSessionID = ""
+		if err := s.clientRepo.Update(client); err != nil {
+			return fmt.Errorf("failed to clear session mapping: %w", err)
+		}
+	}
+	s.sessions.Forget(client.ID.String())
+
+	log.Printf("🗑️ WhatsApp session of client %s removed", client.ID)
+	return nil
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/notification"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// workflowAlertCooldown is how long after an alert a failing workflow alerts the tenant admin again
+const workflowAlertCooldown = time.Hour
+
+// WorkflowNotifier tells the tenant admin a workflow run failed
+type WorkflowNotifier interface {
+	NotifyWorkflowFailed(tenantAdmin *notification.AdminContact, workflowName, executionID string, actionsFailed int, errorMessage string) error
+}
+
+// SetNotifier alerts the tenant admin when workflow runs fail
+func (s *WorkflowService) SetNotifier(notifier WorkflowNotifier) {
+	s.notifier = notifier
+}
+
+// workflowAlertLog remembers when each workflow last alerted, so a workflow failing on every event
+// doesn't flood the admin
+type workflowAlertLog struct {
+	mu   sync.Mutex
+	sent map[uuid.UUID]time.Time
+}
+
+func newWorkflowAlertLog() *workflowAlertLog {
+	return &workflowAlertLog{sent: make(map[uuid.UUID]time.Time)}
+}
+
+// claim reports whether the workflow may alert now, and if so records the alert
+func (l *workflowAlertLog) claim(workflowID uuid.UUID) bool {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if sentAt, ok := l.sent[workflowID]; ok && now.Sub(sentAt) < workflowAlertCooldown {
+		return false
+	}
+	l.sent[workflowID] = now
+	return true
+}
+
+// alertFailure notifies the tenant admin of a failed workflow run, at most once per cooldown per workflow
+func (s *WorkflowService) alertFailure(wf *models.Workflow, execution *models.WorkflowExecution, actionsFailed int, errorMessage string) {
+	if s.notifier == nil || !s.alerts.claim(wf.ID) {
+		return
+	}
+
+	var client models.Client
+	if err := s.db.Select("id", "business_name", "whatsapp_number").Where("id = ?", wf.ClientID).First(&client).Error; err != nil {
+		log.Printf("⚠️ Failed to load client for workflow alert: %v", err)
+		return
+	}
+	if client.WhatsAppNumber == "" {
+		return
+	}
+
+	s.tasks.Go(func() {
+		if err := s.notifier.NotifyWorkflowFailed(tenantAdmin(&client), wf.Name, execution.ID.String(), actionsFailed, errorMessage); err != nil {
+			log.Printf("⚠️ Failed to send workflow failure alert for %s: %v", wf.Name, err)
+		}
+	})
+}
+
+// firstActionError returns the error of the first failed step in an execution log
+func firstActionError(executionLog []workflow.ExecutionLogEntry) string {
+	for _, entry := range executionLog {
+		if entry.Status == "failed" && entry.Error != "" {
+			return entry.Error
+		}
+	}
+	return ""
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// DefaultStatsWindow is the period covered by stats and exports when no range is given
+const DefaultStatsWindow = 30 * 24 * time.Hour
+
+// WorkflowStats holds aggregate metrics for a workflow's executions
+type WorkflowStats struct {
+	WorkflowID       uuid.UUID         `json:"workflow_id"`
+	From             time.Time         `json:"from"`
+	To               time.Time         `json:"to"`
+	TotalExecutions  int               `json:"total_executions"`
+	Completed        int               `json:"completed"`
+	Failed           int               `json:"failed"`
+	Running          int               `json:"running"`
+	SuccessRate      float64           `json:"success_rate"` // Percentage of finished executions that completed
+	AvgDurationMs    float64           `json:"avg_duration_ms"`
+	ActionsCompleted int               `json:"actions_completed"`
+	ActionsFailed    int               `json:"actions_failed"`
+	FailuresByAction map[string]int    `json:"failures_by_action"`
+	ExecutionsPerDay []DailyExecutions `json:"executions_per_day"`
+}
+
+// DailyExecutions holds execution counts for a single day
+type DailyExecutions struct {
+	Date      string `json:"date"` // YYYY-MM-DD
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Failed    int    `json:"failed"`
+}
+
+// GetExecutionStats computes aggregate metrics from the execution records in a time range
+func (s *WorkflowService) GetExecutionStats(workflowID uuid.UUID, from, to time.Time) (*WorkflowStats, error) {
+	if _, err := s.workflowRepo.FindByID(workflowID); err != nil {
+		return nil, fmt.Errorf("workflow not found: %w", err)
+	}
+
+	executions, err := s.workflowRepo.FindExecutionsInRange(workflowID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	stats := &WorkflowStats{
+		WorkflowID:       workflowID,
+		From:             from,
+		To:               to,
+		TotalExecutions:  len(executions),
+		FailuresByAction: make(map[string]int),
+		ExecutionsPerDay: []DailyExecutions{},
+	}
+
+	perDay := make(map[string]*DailyExecutions)
+	var totalDuration int64
+	var durationCount int
+
+	for _, execution := range executions {
+		day := execution.StartedAt.Format("2006-01-02")
+		daily, ok := perDay[day]
+		if !ok {
+			daily = &DailyExecutions{Date: day}
+			perDay[day] = daily
+		}
+		daily.Total++
+
+		switch execution.Status {
+		case "completed":
+			stats.Completed++
+			daily.Completed++
+		case "failed":
+			stats.Failed++
+			daily.Failed++
+		default:
+			stats.Running++
+		}
+
+		if execution.CompletedAt != nil {
+			totalDuration += int64(execution.DurationMs)
+			durationCount++
+		}
+
+		stats.ActionsCompleted += execution.ActionsCompleted
+		stats.ActionsFailed += execution.ActionsFailed
+
+		for _, entry := range parseExecutionLog(execution) {
+			if entry.Step == "action_execute" && entry.Status == "failed" {
+				stats.FailuresByAction[entry.ActionType]++
+			}
+		}
+	}
+
+	if finished := stats.Completed + stats.Failed; finished > 0 {
+		stats.SuccessRate = float64(stats.Completed) / float64(finished) * 100
+	}
+	if durationCount > 0 {
+		stats.AvgDurationMs = float64(totalDuration) / float64(durationCount)
+	}
+
+	for _, daily := range perDay {
+		stats.ExecutionsPerDay = append(stats.ExecutionsPerDay, *daily)
+	}
+	sort.Slice(stats.ExecutionsPerDay, func(i, j int) bool {
+		return stats.ExecutionsPerDay[i].Date < stats.ExecutionsPerDay[j].Date
+	})
+
+	return stats, nil
+}
+
+// ExportExecutionsCSV renders the execution history in a time range as CSV
+func (s *WorkflowService) ExportExecutionsCSV(workflowID uuid.UUID, from, to time.Time) ([]byte, error) {
+	if _, err := s.workflowRepo.FindByID(workflowID); err != nil {
+		return nil, fmt.Errorf("workflow not found: %w", err)
+	}
+
+	executions, err := s.workflowRepo.FindExecutionsInRange(workflowID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load executions: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("\uFEFF") // UTF-8 BOM so Excel detects the encoding
+	writer := csv.NewWriter(&buf)
+
+	header := []string{
+		"execution_id", "status", "started_at", "completed_at", "duration_ms",
+		"actions_completed", "actions_failed", "failed_actions", "triggered_by", "error_message",
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write csv header: %w", err)
+	}
+
+	for _, execution := range executions {
+		completedAt := ""
+		if execution.CompletedAt != nil {
+			completedAt = execution.CompletedAt.Format(time.RFC3339)
+		}
+
+		var failedActions []string
+		for _, entry := range parseExecutionLog(execution) {
+			if entry.Step == "action_execute" && entry.Status == "failed" {
+				failedActions = append(failedActions, entry.ActionType)
+			}
+		}
+
+		var triggerData map[string]interface{}
+		_ = json.Unmarshal(execution.TriggerData, &triggerData)
+		triggeredBy, _ := triggerData["triggered_by"].(string)
+
+		record := []string{
+			execution.ID.String(),
+			execution.Status,
+			execution.StartedAt.Format(time.RFC3339),
+			completedAt,
+			strconv.Itoa(execution.DurationMs),
+			strconv.Itoa(execution.ActionsCompleted),
+			strconv.Itoa(execution.ActionsFailed),
+			strings.Join(failedActions, ";"),
+			triggeredBy,
+			execution.ErrorMessage,
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write csv row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// parseExecutionLog decodes the execution log, ignoring malformed entries
+func parseExecutionLog(execution models.WorkflowExecution) []workflow.ExecutionLogEntry {
+	var entries []workflow.ExecutionLogEntry
+	if len(execution.ExecutionLog) == 0 {
+		return entries
+	}
+	_ = json.Unmarshal(execution.ExecutionLog, &entries)
+	return entries
+}
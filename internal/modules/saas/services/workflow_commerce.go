@@ -0,0 +1,270 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/payment"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// workflowCommerce runs the order and cart actions of workflows through the cart and order services
+type workflowCommerce struct {
+	cartService    *CartService
+	orderService   *OrderService
+	productService *ProductService
+	reportService  *ReportService
+}
+
+// SetCommerceServices enables the add_to_cart, create_order, update_order_status and sales_summary workflow actions
+func (s *WorkflowService) SetCommerceServices(cartService *CartService, orderService *OrderService, productService *ProductService, reportService *ReportService) {
+	s.actionExecutor.SetCommerce(&workflowCommerce{
+		cartService:    cartService,
+		orderService:   orderService,
+		productService: productService,
+		reportService:  reportService,
+	})
+}
+
+// AddToCart adds a catalog product (or a priced item outside the catalog) to the customer's cart
+func (c *workflowCommerce) AddToCart(ctx context.Context, req workflow.CartItemInput) (map[string]interface{}, error) {
+	item, err := c.resolveItem(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cart, err := c.cartService.AddToCart(&AddToCartRequest{
+		ClientID:      req.ClientID,
+		CustomerPhone: req.CustomerPhone,
+		ProductID:     item.ProductID,
+		ProductName:   item.ProductName,
+		Quantity:      item.Quantity,
+		Price:         item.Price,
+		Notes:         req.Notes,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"id":           cart.ID.String(),
+		"product_name": item.ProductName,
+		"items":        len(cart.Items),
+		"total":        cart.TotalAmount,
+	}, nil
+}
+
+// CreateOrder creates an order from the listed items, or checks out the customer's cart when none are
+// listed. The customer gets the payment instructions as with any other order.
+func (c *workflowCommerce) CreateOrder(ctx context.Context, req workflow.OrderInput) (map[string]interface{}, error) {
+	orderReq := &CreateOrderRequest{
+		ClientID:       req.ClientID,
+		CustomerPhone:  req.CustomerPhone,
+		CustomerName:   req.CustomerName,
+		PaymentMethod:  req.PaymentMethod,
+		DeliveryNotes:  req.DeliveryNotes,
+		IdempotencyKey: req.IdempotencyKey,
+	}
+	if orderReq.CustomerName == "" {
+		orderReq.CustomerName = req.CustomerPhone
+	}
+
+	fromCart := len(req.Items) == 0
+	if fromCart {
+		cart, err := c.cartService.ViewCart(req.ClientID, req.CustomerPhone)
+		if err != nil || cart.IsEmpty() {
+			return nil, fmt.Errorf("customer has no items in their cart")
+		}
+		for _, item := range cart.Items {
+			orderReq.Items = append(orderReq.Items, paymentOrderItem(item.ProductID, item.ProductName, item.Quantity, item.Price))
+		}
+		orderReq.TotalAmount = cart.TotalAmount
+		if cart.BranchID != nil {
+			orderReq.BranchID = cart.BranchID.String()
+		}
+	} else {
+		for _, input := range req.Items {
+			input.ClientID = req.ClientID
+			item, err := c.resolveItem(input)
+			if err != nil {
+				return nil, err
+			}
+			orderItem := paymentOrderItem(item.ProductID, item.ProductName, item.Quantity, item.Price)
+			orderReq.Items = append(orderReq.Items, orderItem)
+			orderReq.TotalAmount += orderItem.Subtotal
+		}
+	}
+
+	order, _, err := c.orderService.CreateOrder(orderReq)
+	if order == nil {
+		return nil, err
+	}
+	if err != nil {
+		// The order is saved; only starting the payment failed, which the customer can retry
+		log.Printf("⚠️ Workflow order %s created without payment: %v", order.OrderNumber, err)
+	}
+	if fromCart {
+		c.cartService.ClearCart(req.ClientID, req.CustomerPhone)
+	}
+
+	return orderResult(order), nil
+}
+
+// UpdateOrderStatus marks one of the client's orders packed, shipped or delivered, or cancels it
+func (c *workflowCommerce) UpdateOrderStatus(ctx context.Context, req workflow.OrderStatusInput) (map[string]interface{}, error) {
+	var order *models.Order
+	var err error
+	if _, parseErr := uuid.Parse(req.OrderRef); parseErr == nil {
+		order, err = c.orderService.GetOrderByID(req.OrderRef)
+	} else {
+		order, err = c.orderService.GetOrderByOrderNumber(strings.TrimPrefix(req.OrderRef, "#"))
+	}
+	if err != nil || order.ClientID.String() != req.ClientID {
+		return nil, ErrOrderNotFound
+	}
+
+	status := strings.ToLower(req.Status)
+	if status == models.FulfillmentStatusCancelled {
+		reason := req.Reason
+		if reason == "" {
+			reason = "Cancelled by workflow"
+		}
+		if err := c.orderService.CancelOrder(order.ID.String(), reason); err != nil {
+			return nil, err
+		}
+		order, err = c.orderService.GetOrderByID(order.ID.String())
+		if err != nil {
+			return nil, err
+		}
+		return orderResult(order), nil
+	}
+
+	order, err = c.orderService.UpdateFulfillment(req.ClientID, order.ID.String(), &models.FulfillmentUpdateRequest{
+		Status:         status,
+		Courier:        req.Courier,
+		TrackingNumber: req.TrackingNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orderResult(order), nil
+}
+
+// SalesSummary reports the client's orders and revenue of a period, in the client's timezone
+func (c *workflowCommerce) SalesSummary(ctx context.Context, clientID, period string) (map[string]interface{}, error) {
+	if c.reportService == nil {
+		return nil, fmt.Errorf("sales reports are not available")
+	}
+	client, err := c.orderService.clientRepo.GetByID(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	to := startOfDay(time.Now(), client.Timezone).AddDate(0, 0, 1)
+	from := to.AddDate(0, 0, -1)
+	switch period {
+	case workflow.SalesPeriodYesterday:
+		from, to = from.AddDate(0, 0, -1), from
+	case workflow.SalesPeriodLast7Days:
+		from = to.AddDate(0, 0, -7)
+	}
+
+	report, err := c.reportService.GetSalesReport(client.ID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	date := from.Format("02/01/2006")
+	if period == workflow.SalesPeriodLast7Days {
+		date += " - " + to.AddDate(0, 0, -1).Format("02/01/2006")
+	}
+	return map[string]interface{}{
+		"period":           period,
+		"date":             date,
+		"orders":           report.Orders,
+		"paid_orders":      report.PaidOrders,
+		"cancelled_orders": report.CancelledOrders,
+		"revenue":          report.Revenue,
+		"net_revenue":      report.NetRevenue,
+		"revenue_text":     "Rp " + formatCurrency(report.NetRevenue),
+	}, nil
+}
+
+// workflowItem is a product resolved against the client's catalog
+type workflowItem struct {
+	ProductID   string
+	ProductName string
+	Quantity    int
+	Price       float64
+}
+
+// resolveItem looks the product up in the catalog, by ID or name. Products outside the catalog can be
+// ordered by name when the workflow gives their price.
+func (c *workflowCommerce) resolveItem(req workflow.CartItemInput) (*workflowItem, error) {
+	clientUUID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid client_id")
+	}
+
+	var product *models.Product
+	if c.productService != nil {
+		if req.ProductID != "" {
+			product, err = c.productService.GetProduct(req.ProductID, clientUUID)
+		} else {
+			product, err = c.productService.GetProductByName(clientUUID, req.ProductName)
+		}
+	}
+	if product == nil {
+		if req.ProductID != "" || req.Price == nil {
+			return nil, fmt.Errorf("product %s%s not found", req.ProductID, req.ProductName)
+		}
+		return &workflowItem{ProductName: req.ProductName, Quantity: req.Quantity, Price: *req.Price}, nil
+	}
+
+	if !product.IsAvailable() && !product.CanPreorder() {
+		return nil, fmt.Errorf("product %s is out of stock", product.Name)
+	}
+	item := &workflowItem{
+		ProductID:   product.ID.String(),
+		ProductName: product.Name,
+		Quantity:    req.Quantity,
+		Price:       product.Price,
+	}
+	if req.Price != nil {
+		item.Price = *req.Price
+	}
+	return item, nil
+}
+
+// paymentOrderItem converts an item to the order service's format; IDs outside the catalog become nil
+func paymentOrderItem(productID, name string, quantity int, price float64) payment.OrderItem {
+	productUUID, err := uuid.Parse(productID)
+	if err != nil {
+		productUUID = uuid.Nil
+	}
+	return payment.OrderItem{
+		ProductID:   productUUID,
+		VariantID:   uuid.Nil,
+		ProductName: name,
+		Quantity:    quantity,
+		UnitPrice:   price,
+		Subtotal:    price * float64(quantity),
+	}
+}
+
+// orderResult is what later workflow actions see of an order, as {order.<field>}
+func orderResult(order *models.Order) map[string]interface{} {
+	return map[string]interface{}{
+		"id":                 order.ID.String(),
+		"order_number":       order.OrderNumber,
+		"total":              order.TotalAmount,
+		"payment_status":     order.PaymentStatus,
+		"fulfillment_status": order.FulfillmentStatus,
+		"customer_phone":     order.CustomerPhone,
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// Events raised for workflows by the commerce event job
+const (
+	EventCartAbandoned       = "cart_abandoned"        // Cart left unchanged for an hour without checkout
+	EventOrderPaymentPending = "order_payment_pending" // Online order still unpaid an hour after it was placed
+)
+
+const (
+	cartAbandonedAfter  = time.Hour
+	paymentPendingAfter = time.Hour
+	// commerceEventWindow stops the job from raising events for carts and orders older than a day,
+	// such as the backlog of a workflow created today
+	commerceEventWindow = 24 * time.Hour
+	// commerceEventBatch is how many carts or orders per event the job raises events for per tick
+	commerceEventBatch = 200
+)
+
+// RunCommerceEventJob raises the cart_abandoned and order_payment_pending events of clients with an
+// active workflow for them
+func (s *WorkflowService) RunCommerceEventJob(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.raiseAbandonedCarts(ctx, time.Now())
+			s.raisePendingPayments(ctx, time.Now())
+		}
+	}
+}
+
+// raiseAbandonedCarts raises cart_abandoned once per abandonment: a cart changed after its event can
+// raise it again
+func (s *WorkflowService) raiseAbandonedCarts(ctx context.Context, now time.Time) {
+	clients, err := s.eventWorkflowClients(EventCartAbandoned)
+	if err != nil || len(clients) == 0 {
+		return
+	}
+
+	var carts []models.Cart
+	err = s.db.Where("client_id IN ? AND status = ? AND jsonb_array_length(items) > 0", clientIDs(clients), "active").
+		Where("updated_at <= ? AND updated_at > ? AND expires_at > ?", now.Add(-cartAbandonedAfter), now.Add(-commerceEventWindow), now).
+		Where("abandoned_event_at IS NULL OR abandoned_event_at < updated_at").
+		Order("updated_at ASC").
+		Limit(commerceEventBatch).
+		Find(&carts).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to list abandoned carts: %v", err)
+		return
+	}
+
+	raised := 0
+	for _, cart := range carts {
+		claim := s.db.Table("saas_carts").
+			Where("id = ? AND (abandoned_event_at IS NULL OR abandoned_event_at < updated_at)", cart.ID).
+			Update("abandoned_event_at", now)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		lines := make([]string, 0, len(cart.Items))
+		for _, item := range cart.Items {
+			lines = append(lines, fmt.Sprintf("- %dx %s (Rp %s)", item.Quantity, item.ProductName, formatCurrency(item.Subtotal)))
+		}
+		data := commerceEventData(clients[cart.ClientID], cart.CustomerPhone)
+		data["cart_id"] = cart.ID.String()
+		data["cart_items"] = len(cart.Items)
+		data["cart_total"] = cart.TotalAmount
+		data["cart_total_text"] = "Rp " + formatCurrency(cart.TotalAmount)
+		data["items_text"] = strings.Join(lines, "\n")
+
+		if err := s.HandleEvent(ctx, EventCartAbandoned, data); err != nil {
+			log.Printf("⚠️ Failed to raise %s for cart %s: %v", EventCartAbandoned, cart.ID, err)
+			continue
+		}
+		raised++
+	}
+	if raised > 0 {
+		log.Printf("🛒 Commerce event job: %d abandoned cart(s)", raised)
+	}
+}
+
+// raisePendingPayments raises order_payment_pending once per unpaid online order
+func (s *WorkflowService) raisePendingPayments(ctx context.Context, now time.Time) {
+	clients, err := s.eventWorkflowClients(EventOrderPaymentPending)
+	if err != nil || len(clients) == 0 {
+		return
+	}
+
+	var orders []models.Order
+	err = s.db.Where("client_id IN ? AND payment_status = ? AND payment_method <> ? AND is_test = ?",
+		clientIDs(clients), models.PaymentStatusPending, models.PaymentMethodCOD, false).
+		Where("created_at <= ? AND created_at > ?", now.Add(-paymentPendingAfter), now.Add(-commerceEventWindow)).
+		Where("payment_pending_event_at IS NULL").
+		Order("created_at ASC").
+		Limit(commerceEventBatch).
+		Find(&orders).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to list pending payments: %v", err)
+		return
+	}
+
+	raised := 0
+	for _, order := range orders {
+		claim := s.db.Table("saas_orders").
+			Where("id = ? AND payment_pending_event_at IS NULL", order.ID).
+			Update("payment_pending_event_at", now)
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue
+		}
+
+		data := commerceEventData(clients[order.ClientID], order.CustomerPhone)
+		data["order_id"] = order.ID.String()
+		data["order_number"] = order.OrderNumber
+		data["customer_name"] = order.CustomerName
+		data["total_amount"] = order.TotalAmount
+		data["total_text"] = "Rp " + formatCurrency(order.TotalAmount)
+		data["payment_link"] = order.PaymentLink
+
+		if err := s.HandleEvent(ctx, EventOrderPaymentPending, data); err != nil {
+			log.Printf("⚠️ Failed to raise %s for order %s: %v", EventOrderPaymentPending, order.OrderNumber, err)
+			continue
+		}
+		raised++
+	}
+	if raised > 0 {
+		log.Printf("💳 Commerce event job: %d pending payment(s)", raised)
+	}
+}
+
+// eventWorkflowClients returns the clients with an active workflow for the event, leaving out clients
+// with automation paused and sandbox clients, whose customers are test chats
+func (s *WorkflowService) eventWorkflowClients(eventName string) (map[uuid.UUID]*models.Client, error) {
+	var clients []models.Client
+	err := s.db.Select("id", "whatsapp_session_id").
+		Where("automation_paused = ? AND sandbox_mode = ?", false, false).
+		Where("id IN (?)", s.db.Model(&models.Workflow{}).Select("client_id").
+			Where("trigger_type = ? AND is_active = ? AND trigger_config->>'event_name' = ?", "event", true, eventName)).
+		Find(&clients).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to list clients with %s workflows: %v", eventName, err)
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]*models.Client, len(clients))
+	for i := range clients {
+		byID[clients[i].ID] = &clients[i]
+	}
+	return byID, nil
+}
+
+// clientIDs returns the keys of a client map
+func clientIDs(clients map[uuid.UUID]*models.Client) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(clients))
+	for id := range clients {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// commerceEventData is the trigger data shared by commerce events; actions answer the customer by default
+func commerceEventData(client *models.Client, customerPhone string) map[string]interface{} {
+	return map[string]interface{}{
+		"client_id":      client.ID.String(),
+		"session_id":     workflowSessionID(client),
+		"customer_phone": customerPhone,
+		"from":           customerPhone,
+	}
+}
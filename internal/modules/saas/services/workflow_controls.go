@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// BulkWorkflowResult summarizes a bulk enable/disable operation
+type BulkWorkflowResult struct {
+	Action   string   `json:"action"`
+	Updated  int64    `json:"updated"`
+	NotFound []string `json:"not_found,omitempty"`
+}
+
+// AutomationStatus represents the kill switch state for a client
+type AutomationStatus struct {
+	ClientID uuid.UUID  `json:"client_id"`
+	Paused   bool       `json:"paused"`
+	PausedAt *time.Time `json:"paused_at,omitempty"`
+	PausedBy string     `json:"paused_by,omitempty"`
+	Reason   string     `json:"reason,omitempty"`
+}
+
+// BulkSetActive enables or disables several (or all) workflows of a client
+func (s *WorkflowService) BulkSetActive(clientID uuid.UUID, req workflow.BulkWorkflowRequest, actor string) (*BulkWorkflowResult, error) {
+	var active bool
+	switch req.Action {
+	case "enable":
+		active = true
+	case "disable":
+		active = false
+	default:
+		return nil, fmt.Errorf("action must be 'enable' or 'disable'")
+	}
+
+	result := &BulkWorkflowResult{Action: req.Action}
+
+	var workflows []models.Workflow
+	var err error
+	if req.All {
+		workflows, err = s.workflowRepo.FindByClientID(clientID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflows: %w", err)
+		}
+	} else {
+		if len(req.WorkflowIDs) == 0 {
+			return nil, fmt.Errorf("workflow_ids is required unless all is true")
+		}
+
+		ids := make([]uuid.UUID, 0, len(req.WorkflowIDs))
+		for _, idStr := range req.WorkflowIDs {
+			id, err := uuid.Parse(idStr)
+			if err != nil {
+				return nil, fmt.Errorf("invalid workflow id: %s", idStr)
+			}
+			ids = append(ids, id)
+		}
+
+		workflows, err = s.workflowRepo.FindByClientIDAndIDs(clientID, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workflows: %w", err)
+		}
+
+		found := make(map[uuid.UUID]bool, len(workflows))
+		for _, wf := range workflows {
+			found[wf.ID] = true
+		}
+		for _, id := range ids {
+			if !found[id] {
+				result.NotFound = append(result.NotFound, id.String())
+			}
+		}
+	}
+
+	if len(workflows) == 0 {
+		return result, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(workflows))
+	for _, wf := range workflows {
+		ids = append(ids, wf.ID)
+	}
+
+	updated, err := s.workflowRepo.SetActive(clientID, ids, active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update workflows: %w", err)
+	}
+	result.Updated = updated
+
+	// Keep the scheduler in sync
+	for i := range workflows {
+		wf := &workflows[i]
+		if wf.TriggerType != "scheduled" {
+			continue
+		}
+
+		if active && !wf.IsActive {
+			wf.IsActive = true
+			if err := s.addWorkflowToScheduler(wf); err != nil {
+				log.Printf("⚠️ Failed to schedule workflow %s: %v", wf.Name, err)
+			}
+		} else if !active && wf.IsActive {
+			s.scheduler.RemoveWorkflow(wf.ID.String())
+		}
+	}
+
+	idStrings := make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStrings = append(idStrings, id.String())
+	}
+
+	s.recordAudit(clientID, actor, "bulk_"+req.Action, "workflow", "", nil, map[string]interface{}{
+		"workflow_ids": idStrings,
+		"all":          req.All,
+		"is_active":    active,
+	}, fmt.Sprintf("Bulk %s of %d workflow(s)", req.Action, updated), nil)
+
+	log.Printf("✅ Bulk %s: %d workflow(s) for client %s by %s", req.Action, updated, clientID, actor)
+	return result, nil
+}
+
+// SetAutomationPaused flips the client-level automation kill switch
+func (s *WorkflowService) SetAutomationPaused(clientID uuid.UUID, paused bool, reason, actor string) (*AutomationStatus, error) {
+	var client models.Client
+	if err := s.db.Where("id = ?", clientID).First(&client).Error; err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+
+	previous := clientAutomationStatus(&client)
+
+	client.AutomationPaused = paused
+	client.AutomationPausedAt = nil
+	client.AutomationPausedBy = ""
+	client.AutomationPausedReason = ""
+	if paused {
+		now := time.Now()
+		client.AutomationPausedAt = &now
+		client.AutomationPausedBy = actor
+		client.AutomationPausedReason = reason
+	}
+
+	err := s.db.Model(&models.Client{}).Where("id = ?", clientID).Updates(map[string]interface{}{
+		"automation_paused":        client.AutomationPaused,
+		"automation_paused_at":     client.AutomationPausedAt,
+		"automation_paused_by":     client.AutomationPausedBy,
+		"automation_paused_reason": client.AutomationPausedReason,
+	}).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to update automation status: %w", err)
+	}
+
+	current := clientAutomationStatus(&client)
+
+	action := "automation_resumed"
+	description := "Automation resumed"
+	if paused {
+		action = "automation_paused"
+		description = "Automation paused"
+	}
+	s.recordAudit(clientID, actor, action, "client", clientID.String(), previous, current, description, map[string]interface{}{
+		"reason": reason,
+	})
+
+	if paused {
+		log.Printf("⏸️  Automation paused for client %s by %s (reason: %s)", clientID, actor, reason)
+	} else {
+		log.Printf("▶️  Automation resumed for client %s by %s", clientID, actor)
+	}
+
+	return current, nil
+}
+
+// GetAutomationStatus returns the kill switch state for a client
+func (s *WorkflowService) GetAutomationStatus(clientID uuid.UUID) (*AutomationStatus, error) {
+	var client models.Client
+	if err := s.db.Where("id = ?", clientID).First(&client).Error; err != nil {
+		return nil, fmt.Errorf("client not found: %w", err)
+	}
+	return clientAutomationStatus(&client), nil
+}
+
+// isAutomationPaused checks whether the kill switch is on for a client
+func (s *WorkflowService) isAutomationPaused(clientID uuid.UUID) bool {
+	var paused bool
+	err := s.db.Model(&models.Client{}).
+		Select("automation_paused").
+		Where("id = ?", clientID).
+		Scan(&paused).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to check automation status for client %s: %v", clientID, err)
+		return false
+	}
+	return paused
+}
+
+// recordAudit writes an audit log entry, logging (not returning) failures
+func (s *WorkflowService) recordAudit(clientID uuid.UUID, actor, action, entity, entityID string, oldValue, newValue interface{}, description string, metadata map[string]interface{}) {
+	if s.auditService == nil {
+		return
+	}
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["actor"] = actor
+
+	entry := &audit.AuditLog{
+		ClientID:    clientID,
+		Action:      action,
+		Entity:      entity,
+		EntityID:    entityID,
+		Description: description,
+	}
+
+	// Actor is a user ID when the request was authenticated
+	if userID, err := uuid.Parse(actor); err == nil {
+		entry.UserID = userID
+	}
+
+	if oldValue != nil {
+		if data, err := json.Marshal(oldValue); err == nil {
+			entry.OldValue = datatypes.JSON(data)
+		}
+	}
+	if newValue != nil {
+		if data, err := json.Marshal(newValue); err == nil {
+			entry.NewValue = datatypes.JSON(data)
+		}
+	}
+	if data, err := json.Marshal(metadata); err == nil {
+		entry.Metadata = datatypes.JSON(data)
+	}
+
+	if err := s.auditService.Log(context.Background(), entry); err != nil {
+		log.Printf("⚠️ Failed to write audit log: %v", err)
+	}
+}
+
+// clientAutomationStatus builds the kill switch state from a client record
+func clientAutomationStatus(client *models.Client) *AutomationStatus {
+	return &AutomationStatus{
+		ClientID: client.ID,
+		Paused:   client.AutomationPaused,
+		PausedAt: client.AutomationPausedAt,
+		PausedBy: client.AutomationPausedBy,
+		Reason:   client.AutomationPausedReason,
+	}
+}
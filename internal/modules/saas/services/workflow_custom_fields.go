@@ -0,0 +1,80 @@
+package services
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// Custom field values are added to the trigger data as "<entity>.<key>", e.g. a condition
+// {"field": "order.table_number", "operator": "equals", "value": 12} or the template "Meja {order.table_number}"
+const (
+	customerFieldPrefix = "customer."
+	orderFieldPrefix    = "order."
+	productFieldPrefix  = "product."
+)
+
+// withCustomFields adds the custom field values of the customer, order and product the event refers to
+func (s *WorkflowService) withCustomFields(data map[string]interface{}) map[string]interface{} {
+	clientID, _ := data["client_id"].(string)
+	if _, err := uuid.Parse(clientID); err != nil {
+		return data
+	}
+
+	type source struct {
+		prefix, table, column string
+		ref                   string
+	}
+	var sources []source
+	if phone, _ := data["customer_phone"].(string); phone != "" {
+		sources = append(sources, source{customerFieldPrefix, "saas_customers", "customer_phone", phone})
+	}
+	if orderID, _ := data["order_id"].(string); orderID != "" {
+		if _, err := uuid.Parse(orderID); err == nil {
+			sources = append(sources, source{orderFieldPrefix, "saas_orders", "id", orderID})
+		}
+	} else if orderNumber, _ := data["order_number"].(string); orderNumber != "" {
+		sources = append(sources, source{orderFieldPrefix, "saas_orders", "order_number", orderNumber})
+	}
+	if productID, _ := data["product_id"].(string); productID != "" {
+		if _, err := uuid.Parse(productID); err == nil {
+			sources = append(sources, source{productFieldPrefix, "saas_products", "id", productID})
+		}
+	}
+	if len(sources) == 0 {
+		return data
+	}
+
+	enriched := make(map[string]interface{}, len(data))
+	for k, v := range data {
+		enriched[k] = v
+	}
+	for _, src := range sources {
+		var rows []struct{ CustomFields datatypes.JSON }
+		err := s.db.Table(src.table).
+			Select("custom_fields").
+			Where("client_id = ? AND "+src.column+" = ?", clientID, src.ref).
+			Limit(1).
+			Scan(&rows).Error
+		if err != nil {
+			log.Printf("⚠️ Failed to load %scustom fields for workflow: %v", src.prefix, err)
+			continue
+		}
+		if len(rows) == 0 || len(rows[0].CustomFields) == 0 {
+			continue
+		}
+
+		var values map[string]interface{}
+		if err := json.Unmarshal(rows[0].CustomFields, &values); err != nil {
+			continue
+		}
+		for key, value := range values {
+			if _, exists := enriched[src.prefix+key]; !exists {
+				enriched[src.prefix+key] = value
+			}
+		}
+	}
+	return enriched
+}
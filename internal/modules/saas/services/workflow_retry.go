@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrExecutionNotFound is returned when a workflow has no execution with the given ID
+	ErrExecutionNotFound = errors.New("execution not found")
+	// ErrExecutionNotRetryable is returned when retrying an execution that is running or had no failures
+	ErrExecutionNotRetryable = errors.New("only failed executions can be retried")
+)
+
+// RetryExecution runs a workflow again with the trigger data of one of its failed executions (or one
+// with failed actions). The whole workflow runs again, in the background; the new execution is returned
+// with the failed one as retry_of in its trigger data.
+func (s *WorkflowService) RetryExecution(workflowID, executionID uuid.UUID) (*models.WorkflowExecution, error) {
+	previous, err := s.workflowRepo.FindExecutionByID(executionID)
+	if err != nil || previous.WorkflowID != workflowID {
+		return nil, ErrExecutionNotFound
+	}
+	if previous.Status != "failed" && !(previous.Status == "completed" && previous.ActionsFailed > 0) {
+		return nil, ErrExecutionNotRetryable
+	}
+
+	wf, err := s.workflowRepo.FindByID(workflowID)
+	if err != nil {
+		return nil, fmt.Errorf("workflow not found: %w", err)
+	}
+	if !wf.IsActive {
+		return nil, fmt.Errorf("workflow is not active")
+	}
+
+	triggerData := map[string]interface{}{}
+	if len(previous.TriggerData) > 0 {
+		if err := json.Unmarshal(previous.TriggerData, &triggerData); err != nil {
+			return nil, fmt.Errorf("failed to parse trigger data: %w", err)
+		}
+	}
+	triggerData["retry_of"] = previous.ID.String()
+	triggerData["triggered_by"] = "retry"
+
+	execution, err := s.startExecution(wf, triggerData)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🔁 Retrying execution %s of workflow %s as %s", previous.ID, wf.Name, execution.ID)
+	s.tasks.Go(func() {
+		if err := s.runExecution(context.Background(), wf, execution, triggerData); err != nil {
+			log.Printf("⚠️ Workflow retry failed for %s: %v", wf.Name, err)
+		}
+	})
+	return execution, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/google/uuid"
+)
+
+// CustomerSegmentsField is the condition field holding the names of the segments the customer is in, e.g.
+// {"field": "customer_segments", "operator": "contains", "value": "vip-aktif"}
+const CustomerSegmentsField = "customer_segments"
+
+// SetCustomerSegmentService enables the customer_segments condition field
+func (s *WorkflowService) SetCustomerSegmentService(segmentSvc *CustomerSegmentService) {
+	s.segmentSvc = segmentSvc
+}
+
+// withCustomerSegments adds the customer's segments to the trigger data when a condition checks them
+func (s *WorkflowService) withCustomerSegments(conditions []workflow.Condition, data map[string]interface{}) map[string]interface{} {
+	if _, ok := data[CustomerSegmentsField]; ok || s.segmentSvc == nil {
+		return data
+	}
+
+	needed := false
+	for _, condition := range conditions {
+		if condition.Field == CustomerSegmentsField {
+			needed = true
+			break
+		}
+	}
+	clientID, _ := data["client_id"].(string)
+	customerPhone, _ := data["customer_phone"].(string)
+	if !needed || clientID == "" || customerPhone == "" {
+		return data
+	}
+
+	segments := []string{}
+	if id, err := uuid.Parse(clientID); err == nil {
+		if segments, err = s.segmentSvc.SegmentsOf(id, customerPhone); err != nil {
+			log.Printf("⚠️ Failed to load segments of %s for workflow conditions: %v", customerPhone, err)
+			segments = []string{}
+		}
+	}
+
+	enriched := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		enriched[k] = v
+	}
+	enriched[CustomerSegmentsField] = segments
+	return enriched
+}
@@ -7,7 +7,9 @@ import (
 	"log"
 	"time"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/audit"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/llm"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/metrics"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/whatsapp"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
 	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
@@ -24,6 +26,11 @@ type WorkflowService struct {
 	conditionEvaluator *workflow.ConditionEvaluator
 	actionExecutor     *workflow.ActionExecutor
 	scheduler          *workflow.Scheduler
+	auditService       *audit.Service
+	notifier           WorkflowNotifier // nil when failure alerts are not configured
+	segmentSvc         *CustomerSegmentService
+	alerts             *workflowAlertLog
+	tasks              backgroundTasks // Executions started by events, messages, webhooks and retries
 }
 
 // NewWorkflowService creates a new workflow service
@@ -32,6 +39,7 @@ func NewWorkflowService(
 	db *gorm.DB,
 	waService *whatsapp.Service,
 	llmService *llm.Service,
+	auditService *audit.Service,
 ) *WorkflowService {
 	return &WorkflowService{
 		workflowRepo:       workflowRepo,
@@ -39,6 +47,8 @@ func NewWorkflowService(
 		conditionEvaluator: workflow.NewConditionEvaluator(),
 		actionExecutor:     workflow.NewActionExecutor(db, waService, llmService),
 		scheduler:          workflow.NewScheduler(),
+		auditService:       auditService,
+		alerts:             newWorkflowAlertLog(),
 	}
 }
 
@@ -58,15 +68,33 @@ func (s *WorkflowService) Initialize() error {
 	return nil
 }
 
-// Shutdown stops the workflow service
-func (s *WorkflowService) Shutdown() {
+// Shutdown stops scheduling workflows and waits for the executions in progress, until ctx ends
+func (s *WorkflowService) Shutdown(ctx context.Context) error {
 	log.Println("🛑 Shutting down Workflow Service...")
-	s.scheduler.Stop()
+	scheduled := s.scheduler.Stop()
+
+	if err := s.tasks.Drain(ctx); err != nil {
+		return fmt.Errorf("executions still running: %w", err)
+	}
+	select {
+	case <-scheduled.Done():
+	case <-ctx.Done():
+		return fmt.Errorf("scheduled executions still running: %w", ctx.Err())
+	}
+
 	log.Println("✅ Workflow Service stopped")
+	return nil
 }
 
 // CreateWorkflow creates a new workflow
 func (s *WorkflowService) CreateWorkflow(clientID uuid.UUID, req workflow.CreateWorkflowRequest) (*models.Workflow, error) {
+	if err := workflow.ValidateTriggerConfig(req.TriggerType, req.TriggerConfig); err != nil {
+		return nil, fmt.Errorf("invalid trigger config: %w", err)
+	}
+	if err := workflow.ValidateActions(req.Actions); err != nil {
+		return nil, fmt.Errorf("invalid actions: %w", err)
+	}
+
 	// Marshal trigger config
 	triggerConfigJSON, err := json.Marshal(req.TriggerConfig)
 	if err != nil {
@@ -102,6 +130,9 @@ func (s *WorkflowService) CreateWorkflow(clientID uuid.UUID, req workflow.Create
 		Actions:       datatypes.JSON(actionsJSON),
 		IsActive:      isActive,
 	}
+	if err := ensureWebhookToken(wf); err != nil {
+		return nil, err
+	}
 
 	if err := s.workflowRepo.Create(wf); err != nil {
 		return nil, fmt.Errorf("failed to create workflow: %w", err)
@@ -114,18 +145,43 @@ func (s *WorkflowService) CreateWorkflow(clientID uuid.UUID, req workflow.Create
 		}
 	}
 
+	s.populateNextRun(wf)
+
 	log.Printf("✅ Workflow created: %s (ID: %s)", wf.Name, wf.ID)
 	return wf, nil
 }
 
 // ListWorkflows lists all workflows for a client
 func (s *WorkflowService) ListWorkflows(clientID uuid.UUID) ([]models.Workflow, error) {
-	return s.workflowRepo.FindByClientID(clientID)
+	workflows, err := s.workflowRepo.FindByClientID(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range workflows {
+		s.populateNextRun(&workflows[i])
+	}
+
+	return workflows, nil
 }
 
 // GetWorkflow retrieves a workflow by ID
 func (s *WorkflowService) GetWorkflow(workflowID uuid.UUID) (*models.Workflow, error) {
-	return s.workflowRepo.FindByID(workflowID)
+	wf, err := s.workflowRepo.FindByID(workflowID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.populateNextRun(wf)
+	return wf, nil
+}
+
+// populateNextRun fills in the next scheduled run time
+func (s *WorkflowService) populateNextRun(wf *models.Workflow) {
+	if wf.TriggerType != "scheduled" || !wf.IsActive {
+		return
+	}
+	wf.NextRunAt = s.scheduler.NextRun(wf.ID.String())
 }
 
 // UpdateWorkflow updates an existing workflow
@@ -136,6 +192,33 @@ func (s *WorkflowService) UpdateWorkflow(workflowID uuid.UUID, req workflow.Upda
 		return nil, fmt.Errorf("workflow not found: %w", err)
 	}
 
+	wasScheduled := wf.TriggerType == "scheduled" && wf.IsActive
+
+	// Validate trigger changes against the resulting trigger type
+	if req.TriggerType != nil || req.TriggerConfig != nil {
+		triggerType := wf.TriggerType
+		if req.TriggerType != nil {
+			triggerType = *req.TriggerType
+		}
+
+		var triggerConfig workflow.TriggerConfig
+		if req.TriggerConfig != nil {
+			triggerConfig = *req.TriggerConfig
+		} else if err := json.Unmarshal(wf.TriggerConfig, &triggerConfig); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal trigger config: %w", err)
+		}
+
+		if err := workflow.ValidateTriggerConfig(triggerType, triggerConfig); err != nil {
+			return nil, fmt.Errorf("invalid trigger config: %w", err)
+		}
+	}
+
+	if req.Actions != nil {
+		if err := workflow.ValidateActions(req.Actions); err != nil {
+			return nil, fmt.Errorf("invalid actions: %w", err)
+		}
+	}
+
 	// Update fields if provided
 	if req.Name != nil {
 		wf.Name = *req.Name
@@ -168,21 +251,10 @@ func (s *WorkflowService) UpdateWorkflow(workflowID uuid.UUID, req workflow.Upda
 		wf.Actions = datatypes.JSON(actionsJSON)
 	}
 	if req.IsActive != nil {
-		wasActive := wf.IsActive
 		wf.IsActive = *req.IsActive
-
-		// Handle scheduler updates
-		if wf.TriggerType == "scheduled" {
-			if !wasActive && wf.IsActive {
-				// Workflow was inactive, now active - add to scheduler
-				if err := s.addWorkflowToScheduler(wf); err != nil {
-					log.Printf("⚠️ Failed to schedule workflow: %v", err)
-				}
-			} else if wasActive && !wf.IsActive {
-				// Workflow was active, now inactive - remove from scheduler
-				s.scheduler.RemoveWorkflow(wf.ID.String())
-			}
-		}
+	}
+	if err := ensureWebhookToken(wf); err != nil {
+		return nil, err
 	}
 
 	// Save updates
@@ -190,6 +262,20 @@ func (s *WorkflowService) UpdateWorkflow(workflowID uuid.UUID, req workflow.Upda
 		return nil, fmt.Errorf("failed to update workflow: %w", err)
 	}
 
+	// Handle scheduler updates (activation, deactivation or schedule change)
+	isScheduled := wf.TriggerType == "scheduled" && wf.IsActive
+	if isScheduled {
+		if !wasScheduled || req.TriggerConfig != nil {
+			if err := s.addWorkflowToScheduler(wf); err != nil {
+				log.Printf("⚠️ Failed to schedule workflow: %v", err)
+			}
+		}
+	} else if wasScheduled {
+		s.scheduler.RemoveWorkflow(wf.ID.String())
+	}
+
+	s.populateNextRun(wf)
+
 	log.Printf("✅ Workflow updated: %s (ID: %s)", wf.Name, wf.ID)
 	return wf, nil
 }
@@ -237,9 +323,17 @@ func (s *WorkflowService) ExecuteWorkflow(ctx context.Context, workflowID uuid.U
 func (s *WorkflowService) HandleEvent(ctx context.Context, eventName string, eventData map[string]interface{}) error {
 	log.Printf("📬 Event received: %s", eventName)
 
-	// Find all active workflows with this event trigger
+	// Find all active workflows with this event trigger (skipping clients with automation paused)
+	query := s.db.Where("trigger_type = ? AND is_active = ?", "event", true).
+		Where("client_id NOT IN (?)", s.db.Model(&models.Client{}).Select("id").Where("automation_paused = ?", true))
+
+	// Events raised for a client only trigger that client's workflows
+	if clientID, ok := eventData["client_id"].(string); ok && clientID != "" {
+		query = query.Where("client_id = ?", clientID)
+	}
+
 	var workflows []models.Workflow
-	err := s.db.Where("trigger_type = ? AND is_active = ?", "event", true).Find(&workflows).Error
+	err := query.Find(&workflows).Error
 	if err != nil {
 		return fmt.Errorf("failed to query workflows: %w", err)
 	}
@@ -260,17 +354,58 @@ func (s *WorkflowService) HandleEvent(ctx context.Context, eventName string, eve
 			log.Printf("   ✅ Workflow '%s' matches event '%s', executing...", wf.Name, eventName)
 
 			// Execute workflow in background
-			go func(workflow models.Workflow) {
-				if err := s.executeWorkflowInternal(ctx, &workflow, eventData); err != nil {
-					log.Printf("⚠️ Workflow execution failed for %s: %v", workflow.Name, err)
+			s.tasks.Go(func() {
+				if err := s.executeWorkflowInternal(ctx, &wf, eventData); err != nil {
+					log.Printf("⚠️ Workflow execution failed for %s: %v", wf.Name, err)
 				}
-			}(wf)
+			})
 		}
 	}
 
 	return nil
 }
 
+// HandleMessage runs a client's message_received workflows whose keywords or pattern match a customer
+// message at the given stage. Returns true when a matching before_llm workflow replaces the AI reply.
+func (s *WorkflowService) HandleMessage(ctx context.Context, clientID uuid.UUID, stage, message string, messageData map[string]interface{}) bool {
+	if s.isAutomationPaused(clientID) {
+		return false
+	}
+
+	var workflows []models.Workflow
+	if err := s.db.Where("client_id = ? AND trigger_type = ? AND is_active = ?", clientID, "message_received", true).
+		Find(&workflows).Error; err != nil {
+		log.Printf("⚠️ Failed to query message workflows for client %s: %v", clientID, err)
+		return false
+	}
+
+	skipAI := false
+	for _, wf := range workflows {
+		var triggerConfig workflow.TriggerConfig
+		if err := json.Unmarshal(wf.TriggerConfig, &triggerConfig); err != nil {
+			log.Printf("⚠️ Failed to unmarshal trigger config for workflow %s: %v", wf.ID, err)
+			continue
+		}
+		if triggerConfig.MessageStage() != stage || !triggerConfig.MatchesMessage(message) {
+			continue
+		}
+
+		log.Printf("   ✅ Workflow '%s' matches message (%s), executing...", wf.Name, stage)
+		if triggerConfig.SkipAIReply && stage == workflow.MessageStageBeforeLLM {
+			skipAI = true
+		}
+
+		// Execute workflow in background, outliving the message it was triggered by
+		s.tasks.Go(func() {
+			if err := s.executeWorkflowInternal(context.WithoutCancel(ctx), &wf, messageData); err != nil {
+				log.Printf("⚠️ Workflow execution failed for %s: %v", wf.Name, err)
+			}
+		})
+	}
+
+	return skipAI
+}
+
 // GetExecutions retrieves execution history for a workflow
 func (s *WorkflowService) GetExecutions(workflowID uuid.UUID, limit int) ([]models.WorkflowExecution, error) {
 	return s.workflowRepo.FindExecutionsByWorkflowID(workflowID, limit)
@@ -278,13 +413,19 @@ func (s *WorkflowService) GetExecutions(workflowID uuid.UUID, limit int) ([]mode
 
 // executeWorkflowInternal executes a workflow with the given trigger data
 func (s *WorkflowService) executeWorkflowInternal(ctx context.Context, wf *models.Workflow, triggerData map[string]interface{}) error {
-	startTime := time.Now()
+	execution, err := s.startExecution(wf, triggerData)
+	if err != nil {
+		return err
+	}
+	return s.runExecution(ctx, wf, execution, triggerData)
+}
 
-	// Create execution record
+// startExecution creates the running execution record of a workflow run
+func (s *WorkflowService) startExecution(wf *models.Workflow, triggerData map[string]interface{}) (*models.WorkflowExecution, error) {
 	execution := &models.WorkflowExecution{
 		WorkflowID: wf.ID,
 		Status:     "running",
-		StartedAt:  startTime,
+		StartedAt:  time.Now(),
 	}
 
 	// Marshal trigger data
@@ -292,8 +433,14 @@ func (s *WorkflowService) executeWorkflowInternal(ctx context.Context, wf *model
 	execution.TriggerData = datatypes.JSON(triggerDataJSON)
 
 	if err := s.workflowRepo.CreateExecution(execution); err != nil {
-		return fmt.Errorf("failed to create execution record: %w", err)
+		return nil, fmt.Errorf("failed to create execution record: %w", err)
 	}
+	return execution, nil
+}
+
+// runExecution evaluates the conditions and runs the actions of a started execution
+func (s *WorkflowService) runExecution(ctx context.Context, wf *models.Workflow, execution *models.WorkflowExecution, triggerData map[string]interface{}) error {
+	startTime := execution.StartedAt
 
 	log.Printf("🚀 Executing workflow: %s (ID: %s)", wf.Name, wf.ID)
 
@@ -304,14 +451,18 @@ func (s *WorkflowService) executeWorkflowInternal(ctx context.Context, wf *model
 	var conditions []workflow.Condition
 	if len(wf.Conditions) > 0 {
 		if err := json.Unmarshal(wf.Conditions, &conditions); err != nil {
-			return s.failExecution(execution, fmt.Errorf("failed to parse conditions: %w", err), executionLog)
+			return s.failExecution(wf, execution, fmt.Errorf("failed to parse conditions: %w", err), executionLog)
 		}
 	}
 
-	// Evaluate conditions
+	// Evaluate conditions (tag and segment conditions look up the customer's chat tags and segments,
+	// custom field values are available to conditions and message templates as "<entity>.<key>")
+	triggerData = s.withCustomerTags(conditions, triggerData)
+	triggerData = s.withCustomerSegments(conditions, triggerData)
+	triggerData = s.withCustomFields(triggerData)
 	conditionsPassed, err := s.conditionEvaluator.Evaluate(conditions, triggerData)
 	if err != nil {
-		return s.failExecution(execution, fmt.Errorf("condition evaluation error: %w", err), executionLog)
+		return s.failExecution(wf, execution, fmt.Errorf("condition evaluation error: %w", err), executionLog)
 	}
 
 	executionLog = append(executionLog, workflow.ExecutionLogEntry{
@@ -331,47 +482,32 @@ func (s *WorkflowService) executeWorkflowInternal(ctx context.Context, wf *model
 		logJSON, _ := json.Marshal(executionLog)
 		execution.ExecutionLog = datatypes.JSON(logJSON)
 		s.workflowRepo.UpdateExecution(execution)
+		metrics.WorkflowExecution("skipped")
 		return nil
 	}
 
 	// Parse actions
 	var actions []workflow.Action
 	if err := json.Unmarshal(wf.Actions, &actions); err != nil {
-		return s.failExecution(execution, fmt.Errorf("failed to parse actions: %w", err), executionLog)
+		return s.failExecution(wf, execution, fmt.Errorf("failed to parse actions: %w", err), executionLog)
 	}
 
-	// Execute actions sequentially
-	actionsCompleted := 0
-	actionsFailed := 0
+	// Execute actions in order, following the branches whose conditions pass
+	// call_llm actions are metered against the workflow's client, and order actions limited to its orders
+	actionCtx := llm.WithUsage(ctx, wf.ClientID.String(), models.UsageFeatureWorkflow)
+	actionCtx = workflow.WithClientID(actionCtx, wf.ClientID.String())
 
-	for i, action := range actions {
-		log.Printf("   🔧 Executing action %d/%d: %s", i+1, len(actions), action.Type)
-
-		err := s.actionExecutor.Execute(ctx, action, triggerData)
-		if err != nil {
-			log.Printf("   ❌ Action failed: %v", err)
-			actionsFailed++
-			executionLog = append(executionLog, workflow.ExecutionLogEntry{
-				Timestamp:  time.Now(),
-				Step:       "action_execute",
-				ActionType: action.Type,
-				Status:     "failed",
-				Message:    fmt.Sprintf("Action %d failed", i+1),
-				Error:      err.Error(),
-			})
-		} else {
-			log.Printf("   ✅ Action completed successfully")
-			actionsCompleted++
-			executionLog = append(executionLog, workflow.ExecutionLogEntry{
-				Timestamp:  time.Now(),
-				Step:       "action_execute",
-				ActionType: action.Type,
-				Status:     "success",
-				Message:    fmt.Sprintf("Action %d completed", i+1),
-			})
-		}
+	// Actions record their outputs in the data, so each run gets its own copy of the trigger data, which
+	// is shared by every workflow an event triggers
+	data := make(map[string]interface{}, len(triggerData)+1)
+	for key, value := range triggerData {
+		data[key] = value
 	}
 
+	run := &actionRun{log: executionLog}
+	s.runActions(actionCtx, actions, data, "", run)
+	actionsCompleted, actionsFailed, executionLog := run.completed, run.failed, run.log
+
 	// Update execution record
 	execution.Status = "completed"
 	execution.ActionsCompleted = actionsCompleted
@@ -387,12 +523,120 @@ func (s *WorkflowService) executeWorkflowInternal(ctx context.Context, wf *model
 		log.Printf("⚠️ Failed to update execution record: %v", err)
 	}
 
-	log.Printf("✅ Workflow execution completed: %d/%d actions succeeded", actionsCompleted, len(actions))
+	metrics.WorkflowExecution("completed")
+	if actionsFailed > 0 {
+		s.alertFailure(wf, execution, actionsFailed, firstActionError(executionLog))
+	}
+	log.Printf("✅ Workflow execution completed: %d/%d actions succeeded", actionsCompleted, actionsCompleted+actionsFailed)
 	return nil
 }
 
+// actionRun tallies the actions of one workflow execution
+type actionRun struct {
+	completed int
+	failed    int
+	log       []workflow.ExecutionLogEntry
+}
+
+// runActions executes actions in order. Named action outputs are recorded for later actions, and "if"
+// actions run their then or else branch. A failed action is logged and the next one still runs.
+func (s *WorkflowService) runActions(ctx context.Context, actions []workflow.Action, data map[string]interface{}, prefix string, run *actionRun) {
+	for i, action := range actions {
+		path := fmt.Sprintf("%s%d", prefix, i+1)
+
+		if action.Type == workflow.ActionTypeIf {
+			passed, err := s.conditionEvaluator.Evaluate(action.If, data)
+			entry := workflow.ExecutionLogEntry{
+				Timestamp:  time.Now(),
+				Step:       "branch",
+				ActionType: action.Type,
+				ActionID:   action.ID,
+				Path:       path,
+				Status:     "success",
+			}
+			if err != nil {
+				log.Printf("   ❌ Branch %s failed: %v", path, err)
+				run.failed++
+				entry.Status = "failed"
+				entry.Message = fmt.Sprintf("Branch %s failed", path)
+				entry.Error = err.Error()
+				run.log = append(run.log, entry)
+				continue
+			}
+
+			branch, name := action.Then, "then"
+			if !passed {
+				branch, name = action.Else, "else"
+			}
+			log.Printf("   🔀 Branch %s: running %s (%d actions)", path, name, len(branch))
+			entry.Message = fmt.Sprintf("Conditions %s, running %s", map[bool]string{true: "passed", false: "failed"}[passed], name)
+			run.log = append(run.log, entry)
+			workflow.RecordOutput(data, action.ID, map[string]interface{}{"passed": passed})
+
+			s.runActions(ctx, branch, data, path+"."+name+".", run)
+			continue
+		}
+
+		log.Printf("   🔧 Executing action %s: %s", path, action.Type)
+		output, attempts, err := s.executeWithRetry(ctx, action, data)
+		if err != nil {
+			log.Printf("   ❌ Action failed: %v", err)
+			run.failed++
+			run.log = append(run.log, workflow.ExecutionLogEntry{
+				Timestamp:  time.Now(),
+				Step:       "action_execute",
+				ActionType: action.Type,
+				ActionID:   action.ID,
+				Path:       path,
+				Status:     "failed",
+				Message:    fmt.Sprintf("Action %s failed", path),
+				Error:      err.Error(),
+				Attempts:   attempts,
+			})
+			continue
+		}
+
+		log.Printf("   ✅ Action completed successfully")
+		run.completed++
+		workflow.RecordOutput(data, action.ID, output)
+		run.log = append(run.log, workflow.ExecutionLogEntry{
+			Timestamp:  time.Now(),
+			Step:       "action_execute",
+			ActionType: action.Type,
+			ActionID:   action.ID,
+			Path:       path,
+			Status:     "success",
+			Message:    fmt.Sprintf("Action %s completed", path),
+			Data:       output,
+			Attempts:   attempts,
+		})
+	}
+}
+
+// executeWithRetry executes an action, retrying it by its retry policy. Returns the attempts made.
+func (s *WorkflowService) executeWithRetry(ctx context.Context, action workflow.Action, data map[string]interface{}) (map[string]interface{}, int, error) {
+	attempts := action.Retry.Attempts()
+	for attempt := 1; ; attempt++ {
+		output, err := s.actionExecutor.Execute(ctx, action, data)
+		if err == nil || attempt == attempts {
+			if attempts == 1 {
+				attempt = 0 // Not reported for actions without a retry policy
+			}
+			return output, attempt, err
+		}
+
+		delay := action.Retry.Delay(attempt + 1)
+		log.Printf("   🔁 Action failed (attempt %d/%d), retrying in %s: %v", attempt, attempts, delay, err)
+		select {
+		case <-ctx.Done():
+			return nil, attempt, fmt.Errorf("%w (retry cancelled: %v)", err, ctx.Err())
+		case <-time.After(delay):
+		}
+	}
+}
+
 // failExecution marks execution as failed
-func (s *WorkflowService) failExecution(execution *models.WorkflowExecution, err error, executionLog []workflow.ExecutionLogEntry) error {
+func (s *WorkflowService) failExecution(wf *models.Workflow, execution *models.WorkflowExecution, err error, executionLog []workflow.ExecutionLogEntry) error {
 	execution.Status = "failed"
 	execution.ErrorMessage = err.Error()
 	completedAt := time.Now()
@@ -403,6 +647,8 @@ func (s *WorkflowService) failExecution(execution *models.WorkflowExecution, err
 	execution.ExecutionLog = datatypes.JSON(logJSON)
 
 	s.workflowRepo.UpdateExecution(execution)
+	metrics.WorkflowExecution("failed")
+	s.alertFailure(wf, execution, 0, err.Error())
 	return err
 }
 
@@ -432,8 +678,10 @@ func (s *WorkflowService) addWorkflowToScheduler(wf *models.Workflow) error {
 		return fmt.Errorf("failed to unmarshal trigger config: %w", err)
 	}
 
-	if triggerConfig.Schedule == "" {
-		return fmt.Errorf("schedule is empty")
+	// One-shot triggers whose time has passed are not rescheduled
+	if triggerConfig.RunAt != nil && !triggerConfig.RunAt.After(time.Now()) {
+		log.Printf("⏭️  Skipping one-shot workflow %s: run_at %s already passed", wf.Name, triggerConfig.RunAt.Format(time.RFC3339))
+		return nil
 	}
 
 	// Create job function
@@ -443,7 +691,7 @@ func (s *WorkflowService) addWorkflowToScheduler(wf *models.Workflow) error {
 		ctx := context.Background()
 		triggerData := map[string]interface{}{
 			"triggered_by": "schedule",
-			"schedule":     triggerConfig.Schedule,
+			"schedule":     triggerConfig.Describe(),
 			"timestamp":    time.Now(),
 		}
 
@@ -454,11 +702,24 @@ func (s *WorkflowService) addWorkflowToScheduler(wf *models.Workflow) error {
 			return
 		}
 
+		if s.isAutomationPaused(freshWf.ClientID) {
+			log.Printf("⏸️  Automation paused for client %s, skipping scheduled workflow: %s", freshWf.ClientID, freshWf.Name)
+			return
+		}
+
+		// Scheduled runs have no customer; actions message the business at {admin_phone}
+		var client models.Client
+		if err := s.db.Select("id", "whatsapp_number", "whatsapp_session_id").Where("id = ?", freshWf.ClientID).First(&client).Error; err == nil {
+			triggerData["client_id"] = client.ID.String()
+			triggerData["session_id"] = workflowSessionID(&client)
+			triggerData["admin_phone"] = client.WhatsAppNumber
+		}
+
 		if err := s.executeWorkflowInternal(ctx, freshWf, triggerData); err != nil {
 			log.Printf("❌ Scheduled workflow execution failed: %v", err)
 		}
 	}
 
 	// Add to scheduler
-	return s.scheduler.AddWorkflow(wf.ID.String(), triggerConfig.Schedule, job)
+	return s.scheduler.AddWorkflowTrigger(wf.ID.String(), triggerConfig, job)
 }
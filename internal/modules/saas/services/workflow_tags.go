@@ -0,0 +1,48 @@
+package services
+
+import (
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+)
+
+// CustomerTagsField is the condition field holding the tags on the customer's chat, e.g.
+// {"field": "customer_tags", "operator": "contains", "value": "vip"}
+const CustomerTagsField = "customer_tags"
+
+// withCustomerTags adds the customer's chat tags to the trigger data when a condition checks them
+func (s *WorkflowService) withCustomerTags(conditions []workflow.Condition, data map[string]interface{}) map[string]interface{} {
+	if _, ok := data[CustomerTagsField]; ok {
+		return data
+	}
+
+	needed := false
+	for _, condition := range conditions {
+		if condition.Field == CustomerTagsField {
+			needed = true
+			break
+		}
+	}
+	clientID, _ := data["client_id"].(string)
+	customerPhone, _ := data["customer_phone"].(string)
+	if !needed || clientID == "" || customerPhone == "" {
+		return data
+	}
+
+	tags := []string{}
+	err := s.db.Table("saas_conversation_tag_assignments AS a").
+		Select("t.name").
+		Joins("JOIN saas_conversation_tags t ON t.id = a.tag_id").
+		Where("a.client_id = ? AND a.customer_phone = ?", clientID, customerPhone).
+		Scan(&tags).Error
+	if err != nil {
+		log.Printf("⚠️ Failed to load tags of %s for workflow conditions: %v", customerPhone, err)
+	}
+
+	enriched := make(map[string]interface{}, len(data)+1)
+	for k, v := range data {
+		enriched[k] = v
+	}
+	enriched[CustomerTagsField] = tags
+	return enriched
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/core/workflow"
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// ListTemplates returns the predefined workflows clients can start from
+func (s *WorkflowService) ListTemplates() []workflow.WorkflowTemplate {
+	return workflow.ListTemplates()
+}
+
+// CreateFromTemplate creates a workflow for the client from a template, filling in the given variables
+// (defaults for the rest). Returns workflow.ErrTemplateNotFound for an unknown template.
+func (s *WorkflowService) CreateFromTemplate(clientID uuid.UUID, templateID string, req workflow.CreateFromTemplateRequest) (*models.Workflow, error) {
+	tmpl, err := workflow.GetTemplate(templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	createReq, err := tmpl.Instantiate(req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	if name := strings.TrimSpace(req.Name); name != "" {
+		createReq.Name = name
+	}
+	if req.IsActive != nil {
+		createReq.IsActive = req.IsActive
+	}
+
+	wf, err := s.CreateWorkflow(clientID, *createReq)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", templateID, err)
+	}
+	return wf, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/modules/saas/models"
+	"github.com/google/uuid"
+)
+
+// webhookTriggerType is the trigger type of workflows run by an inbound webhook
+const webhookTriggerType = "webhook"
+
+var (
+	// ErrWorkflowWebhookNotFound is returned when a workflow does not exist or is not webhook triggered
+	ErrWorkflowWebhookNotFound = errors.New("workflow webhook not found")
+	// ErrInvalidWebhookToken is returned when an inbound webhook call has a missing or wrong token
+	ErrInvalidWebhookToken = errors.New("invalid webhook token")
+	// ErrWorkflowNotAccepting is returned when a webhook calls a workflow that is inactive or paused
+	ErrWorkflowNotAccepting = errors.New("workflow is not active")
+)
+
+// ensureWebhookToken gives a webhook triggered workflow its secret token; other workflows keep theirs,
+// so switching back to a webhook trigger doesn't break the caller's URL
+func ensureWebhookToken(wf *models.Workflow) error {
+	if wf.TriggerType != webhookTriggerType || wf.WebhookToken != "" {
+		return nil
+	}
+	token, err := randomHex(24)
+	if err != nil {
+		return fmt.Errorf("failed to generate webhook token: %w", err)
+	}
+	wf.WebhookToken = token
+	return nil
+}
+
+// HandleWebhook runs a webhook triggered workflow in the background with the request body as trigger
+// data, once the token matches the workflow's. The started execution is returned.
+func (s *WorkflowService) HandleWebhook(workflowID uuid.UUID, token string, payload map[string]interface{}) (*models.WorkflowExecution, error) {
+	wf, err := s.workflowRepo.FindByID(workflowID)
+	if err != nil || wf.TriggerType != webhookTriggerType {
+		return nil, ErrWorkflowWebhookNotFound
+	}
+	if token == "" || wf.WebhookToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(wf.WebhookToken)) != 1 {
+		return nil, ErrInvalidWebhookToken
+	}
+	if !wf.IsActive || s.isAutomationPaused(wf.ClientID) {
+		return nil, ErrWorkflowNotAccepting
+	}
+
+	triggerData := make(map[string]interface{}, len(payload)+3)
+	for key, value := range payload {
+		triggerData[key] = value
+	}
+	// The caller can't pick the client or WhatsApp session actions run with
+	triggerData["triggered_by"] = webhookTriggerType
+	triggerData["client_id"] = wf.ClientID.String()
+	delete(triggerData, "session_id")
+	var client models.Client
+	if err := s.db.Select("id", "whatsapp_session_id").Where("id = ?", wf.ClientID).First(&client).Error; err == nil {
+		triggerData["session_id"] = workflowSessionID(&client)
+	}
+
+	execution, err := s.startExecution(wf, triggerData)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("🪝 Webhook triggered workflow %s as %s", wf.Name, execution.ID)
+	s.tasks.Go(func() {
+		if err := s.runExecution(context.Background(), wf, execution, triggerData); err != nil {
+			log.Printf("⚠️ Webhook workflow execution failed for %s: %v", wf.Name, err)
+		}
+	})
+	return execution, nil
+}
+
+// RotateWebhookToken replaces the secret token of a webhook triggered workflow; the old URL stops working
+func (s *WorkflowService) RotateWebhookToken(workflowID uuid.UUID) (*models.Workflow, error) {
+	wf, err := s.workflowRepo.FindByID(workflowID)
+	if err != nil || wf.TriggerType != webhookTriggerType {
+		return nil, ErrWorkflowWebhookNotFound
+	}
+
+	wf.WebhookToken = ""
+	if err := ensureWebhookToken(wf); err != nil {
+		return nil, err
+	}
+	if err := s.workflowRepo.Update(wf); err != nil {
+		return nil, fmt.Errorf("failed to update workflow: %w", err)
+	}
+
+	log.Printf("🔑 Webhook token rotated for workflow %s", wf.Name)
+	s.populateNextRun(wf)
+	return wf, nil
+}
@@ -0,0 +1,127 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// columnCondition matches a condition on a single column such as "client_id = ?" or "clients.id = ?"
+var columnCondition = regexp.MustCompile(`^\s*(?:"?\w+"?\.)?"?(\w+)"?\s*=\s*\?`)
+
+// OnWrite calls invalidate after rows of table are created, updated or deleted through db, with the
+// values of column in the written rows. values is nil when the statement can't be narrowed down to
+// known rows, such as an update by another column; invalidate should then drop everything it caches
+// for the table. Writes inside a transaction invalidate before the commit, so a read racing the
+// commit can cache the old rows until the TTL expires. Raw SQL writes are not seen.
+func (c *Cache) OnWrite(db *gorm.DB, name, table, column string, invalidate func(ctx context.Context, values []string)) error {
+	if c == nil {
+		return nil
+	}
+
+	hook := func(tx *gorm.DB) {
+		if tx.Error != nil || tx.RowsAffected == 0 || tx.Statement.Table != table {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(tx.Statement.Context), 2*time.Second)
+		defer cancel()
+		invalidate(ctx, writtenValues(tx.Statement, column))
+	}
+
+	callbackName := "cache:" + name
+	if err := db.Callback().Create().After("gorm:create").Register(callbackName+":create", hook); err != nil {
+		return fmt.Errorf("failed to register %s create hook: %w", name, err)
+	}
+	if err := db.Callback().Update().After("gorm:update").Register(callbackName+":update", hook); err != nil {
+		return fmt.Errorf("failed to register %s update hook: %w", name, err)
+	}
+	if err := db.Callback().Delete().After("gorm:delete").Register(callbackName+":delete", hook); err != nil {
+		return fmt.Errorf("failed to register %s delete hook: %w", name, err)
+	}
+	return nil
+}
+
+// writtenValues returns the values of column in the rows a statement wrote: from the rows passed in
+// (Save, Create, Delete(&row)) or else from a "column = ?" condition (Where("id = ?", id).Update(...))
+func writtenValues(stmt *gorm.Statement, column string) []string {
+	if stmt.Schema != nil {
+		if field := stmt.Schema.LookUpField(column); field != nil {
+			if values := rowValues(stmt, stmt.ReflectValue, field); len(values) > 0 {
+				return values
+			}
+			// Model(&row).Update(...) writes the model's row
+			if stmt.Model != nil {
+				if values := rowValues(stmt, reflect.ValueOf(stmt.Model), field); len(values) > 0 {
+					return values
+				}
+			}
+		}
+	}
+
+	where, ok := stmt.Clauses["WHERE"].Expression.(clause.Where)
+	if !ok {
+		return nil
+	}
+	for _, expr := range where.Exprs {
+		switch e := expr.(type) {
+		case clause.Eq:
+			if conditionColumn(e.Column) == column && e.Value != nil {
+				return []string{fmt.Sprint(e.Value)}
+			}
+		case clause.IN:
+			if conditionColumn(e.Column) == column && len(e.Values) > 0 {
+				values := make([]string, len(e.Values))
+				for i, value := range e.Values {
+					values[i] = fmt.Sprint(value)
+				}
+				return values
+			}
+		case clause.Expr:
+			if m := columnCondition.FindStringSubmatch(e.SQL); m != nil && m[1] == column && len(e.Vars) > 0 {
+				return []string{fmt.Sprint(e.Vars[0])}
+			}
+		}
+	}
+	return nil
+}
+
+// rowValues returns the field of every row in rows, or nil when a row doesn't have it set
+func rowValues(stmt *gorm.Statement, rows reflect.Value, field *schema.Field) []string {
+	rv := reflect.Indirect(rows)
+	switch rv.Kind() {
+	case reflect.Struct:
+		value, zero := field.ValueOf(stmt.Context, rv)
+		if zero {
+			return nil
+		}
+		return []string{fmt.Sprint(value)}
+	case reflect.Slice, reflect.Array:
+		values := make([]string, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			value, zero := field.ValueOf(stmt.Context, reflect.Indirect(rv.Index(i)))
+			if zero {
+				return nil
+			}
+			values = append(values, fmt.Sprint(value))
+		}
+		return values
+	}
+	return nil
+}
+
+// conditionColumn returns the column name of a clause.Eq column
+func conditionColumn(column interface{}) string {
+	switch c := column.(type) {
+	case clause.Column:
+		return c.Name
+	case string:
+		return c
+	}
+	return ""
+}
@@ -4,12 +4,19 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
 	DatabaseURL         string
+	DBMaxOpenConns      int           // DB_MAX_OPEN_CONNS (default: 25)
+	DBMaxIdleConns      int           // DB_MAX_IDLE_CONNS (default: 5)
+	DBConnMaxLifetime   time.Duration // DB_CONN_MAX_LIFETIME (default: 1h)
+	DBConnMaxIdleTime   time.Duration // DB_CONN_MAX_IDLE_TIME (default: 10m)
 	WhatsAppStoreURL    string
 	OpenAIKey           string
 	Port                string
@@ -38,6 +45,46 @@ type Config struct {
 	AdminPhone string
 	AdminEmail string
 
+	// Public URL of this API (used to build provider webhook URLs)
+	PublicBaseURL string
+
+	// Apply embedded SQL migrations on startup (AUTO_MIGRATE=true)
+	AutoMigrate bool
+
+	// Platform operator key for /admin endpoints (X-Admin-Key header)
+	AdminAPIKey string
+
+	// Bearer token Prometheus scrapes /metrics with (metrics are disabled when empty)
+	MetricsToken string
+
+	// How long a stopping server waits for in-flight requests, message processing and jobs (SHUTDOWN_TIMEOUT, default: 30s)
+	ShutdownTimeout time.Duration
+
+	// API versioning: unprefixed legacy paths alias /v1 until the sunset date
+	LegacyRoutesEnabled bool      // API_LEGACY_ROUTES=false drops the aliases (default: true)
+	LegacyRoutesSunset  time.Time // API_LEGACY_SUNSET as YYYY-MM-DD (default: 2027-04-30)
+
+	// Webhook Configuration
+	WebhookMaxBodyBytes int64 // Max webhook payload size after decompression (default: 24MB)
+	APIMaxBodyBytes     int   // Max request body for other routes (default: 4MB)
+
+	// Webhook signatures: /webhook only accepts requests signed by a provider with a secret set
+	WAHAWebhookHMACKey   string // WAHA_WEBHOOK_HMAC_KEY, also set on sessions configured through the API
+	CloudAPIAppSecret    string // CLOUDAPI_APP_SECRET (X-Hub-Signature-256)
+	CloudAPIVerifyToken  string // CLOUDAPI_VERIFY_TOKEN, answered to Meta's hub.verify_token on GET /webhooks/whatsapp-cloud
+	GreenAPIWebhookToken string // GREEN_API_WEBHOOK_TOKEN (webhookUrlToken)
+	WebhookAllowUnsigned bool   // WEBHOOK_ALLOW_UNSIGNED=true accepts unsigned requests (local development only)
+
+	// Conversation memory: earlier exchanges sent to the LLM as chat history
+	LLMHistoryTurns  int           // LLM_HISTORY_TURNS, 0 disables (default: 10)
+	LLMHistoryTokens int           // LLM_HISTORY_TOKENS, estimated token budget of the history (default: 1500)
+	LLMHistoryMaxAge time.Duration // LLM_HISTORY_MAX_AGE, older exchanges are left out (default: 24h)
+
+	// Speech-to-text for customer voice notes
+	STTProvider     string // STT_PROVIDER: "whisper" (default, uses OPENAI_API_KEY), "google" or "none"
+	GoogleSTTAPIKey string // GOOGLE_STT_API_KEY (default: GOOGLE_VISION_API_KEY)
+	STTLanguage     string // STT_LANGUAGE, BCP-47 code of the voice notes (default: "id-ID")
+
 	// Authentication Configuration
 	JWTSecret        string
 	GoogleClientID   string
@@ -65,6 +112,31 @@ type Config struct {
 	// Embedding Configuration
 	EmbeddingProvider string // "openai" or "gemini" (future)
 	EmbeddingModel    string // OpenAI: "text-embedding-3-small" or "text-embedding-3-large"
+
+	// Vector search result cache
+	VectorCacheEnabled    bool          // VECTOR_CACHE_ENABLED=false disables it (default: true)
+	VectorCacheTTL        time.Duration // VECTOR_CACHE_TTL (default: 1m)
+	VectorCacheMaxEntries int           // VECTOR_CACHE_MAX_ENTRIES (default: 5000)
+
+	// Redis cache of clients, knowledge bases and tenant resolution; empty RedisURL disables it
+	RedisURL string        // REDIS_URL, e.g. redis://:password@localhost:6379/0
+	CacheTTL time.Duration // CACHE_TTL (default: 5m)
+
+	// Multi-region: the settings above are the default region, REGIONS lists the others
+	DefaultRegion string         // DEFAULT_REGION, code of the main deployment (default: "default")
+	Regions       []RegionConfig // REGIONS=sg,eu with REGION_<CODE>_* settings
+}
+
+// RegionConfig is the connection set of a region tenants can be pinned to
+type RegionConfig struct {
+	Code           string
+	DatabaseURL    string // REGION_<CODE>_DATABASE_URL (required)
+	QdrantCloudURL string // REGION_<CODE>_QDRANT_CLOUD_URL, or
+	QdrantAPIKey   string // REGION_<CODE>_QDRANT_CLOUD_API_KEY
+	QdrantHost     string // REGION_<CODE>_QDRANT_HOST for self-hosted (port: REGION_<CODE>_QDRANT_PORT, default 6334)
+	QdrantPort     int
+	S3Region       string // REGION_<CODE>_S3_REGION and
+	S3BucketName   string // REGION_<CODE>_S3_BUCKET_NAME, uploads bucket (S3 credentials are shared; default: the default region's storage)
 }
 
 func LoadConfig() *Config {
@@ -102,6 +174,26 @@ func LoadConfig() *Config {
 		AdminPhone: os.Getenv("ADMIN_PHONE"),
 		AdminEmail: os.Getenv("ADMIN_EMAIL"),
 
+		// Public URL
+		PublicBaseURL: os.Getenv("PUBLIC_BASE_URL"),
+
+		// Migrations
+		AutoMigrate: os.Getenv("AUTO_MIGRATE") == "true",
+		AdminAPIKey: os.Getenv("ADMIN_API_KEY"),
+
+		// Metrics
+		MetricsToken: os.Getenv("METRICS_TOKEN"),
+
+		// API versioning
+		LegacyRoutesEnabled: os.Getenv("API_LEGACY_ROUTES") != "false",
+
+		// Webhook signatures
+		WAHAWebhookHMACKey:   os.Getenv("WAHA_WEBHOOK_HMAC_KEY"),
+		CloudAPIAppSecret:    os.Getenv("CLOUDAPI_APP_SECRET"),
+		CloudAPIVerifyToken:  os.Getenv("CLOUDAPI_VERIFY_TOKEN"),
+		GreenAPIWebhookToken: os.Getenv("GREEN_API_WEBHOOK_TOKEN"),
+		WebhookAllowUnsigned: os.Getenv("WEBHOOK_ALLOW_UNSIGNED") == "true",
+
 		// Authentication
 		JWTSecret:          os.Getenv("JWT_SECRET"),
 		GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
@@ -128,6 +220,14 @@ func LoadConfig() *Config {
 		// Embedding
 		EmbeddingProvider: os.Getenv("EMBEDDING_PROVIDER"),
 		EmbeddingModel:    os.Getenv("EMBEDDING_MODEL"),
+
+		// Vector search cache
+		VectorCacheEnabled: os.Getenv("VECTOR_CACHE_ENABLED") != "false",
+
+		// Speech-to-text
+		STTProvider:     os.Getenv("STT_PROVIDER"),
+		GoogleSTTAPIKey: os.Getenv("GOOGLE_STT_API_KEY"),
+		STTLanguage:     os.Getenv("STT_LANGUAGE"),
 	}
 
 	// Parse Qdrant port (default: 6334)
@@ -137,6 +237,130 @@ func LoadConfig() *Config {
 		}
 	}
 
+	// Parse body size limits
+	if sizeStr := os.Getenv("WEBHOOK_MAX_BODY_BYTES"); sizeStr != "" {
+		if size, err := strconv.ParseInt(sizeStr, 10, 64); err == nil {
+			cfg.WebhookMaxBodyBytes = size
+		}
+	}
+	if sizeStr := os.Getenv("API_MAX_BODY_BYTES"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil {
+			cfg.APIMaxBodyBytes = size
+		}
+	}
+
+	// Parse conversation memory limits
+	cfg.LLMHistoryTurns = 10
+	if turnsStr := os.Getenv("LLM_HISTORY_TURNS"); turnsStr != "" {
+		if turns, err := strconv.Atoi(turnsStr); err == nil && turns >= 0 {
+			cfg.LLMHistoryTurns = turns
+		}
+	}
+	if tokensStr := os.Getenv("LLM_HISTORY_TOKENS"); tokensStr != "" {
+		if tokens, err := strconv.Atoi(tokensStr); err == nil {
+			cfg.LLMHistoryTokens = tokens
+		}
+	}
+	if ageStr := os.Getenv("LLM_HISTORY_MAX_AGE"); ageStr != "" {
+		if age, err := time.ParseDuration(ageStr); err == nil {
+			cfg.LLMHistoryMaxAge = age
+		} else {
+			log.Printf("⚠️ Invalid LLM_HISTORY_MAX_AGE %q, using default", ageStr)
+		}
+	}
+
+	// Parse database pool settings (defaults are applied by database.NewDB)
+	if n, err := strconv.Atoi(os.Getenv("DB_MAX_OPEN_CONNS")); err == nil {
+		cfg.DBMaxOpenConns = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNS")); err == nil {
+		cfg.DBMaxIdleConns = n
+	}
+	if lifetimeStr := os.Getenv("DB_CONN_MAX_LIFETIME"); lifetimeStr != "" {
+		if lifetime, err := time.ParseDuration(lifetimeStr); err == nil {
+			cfg.DBConnMaxLifetime = lifetime
+		} else {
+			log.Printf("⚠️ Invalid DB_CONN_MAX_LIFETIME %q, using default", lifetimeStr)
+		}
+	}
+	if idleStr := os.Getenv("DB_CONN_MAX_IDLE_TIME"); idleStr != "" {
+		if idle, err := time.ParseDuration(idleStr); err == nil {
+			cfg.DBConnMaxIdleTime = idle
+		} else {
+			log.Printf("⚠️ Invalid DB_CONN_MAX_IDLE_TIME %q, using default", idleStr)
+		}
+	}
+	if timeoutStr := os.Getenv("SHUTDOWN_TIMEOUT"); timeoutStr != "" {
+		if timeout, err := time.ParseDuration(timeoutStr); err == nil {
+			cfg.ShutdownTimeout = timeout
+		} else {
+			log.Printf("⚠️ Invalid SHUTDOWN_TIMEOUT %q, using default", timeoutStr)
+		}
+	}
+
+	// Parse vector search cache limits
+	if ttlStr := os.Getenv("VECTOR_CACHE_TTL"); ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err == nil {
+			cfg.VectorCacheTTL = ttl
+		} else {
+			log.Printf("⚠️ Invalid VECTOR_CACHE_TTL %q, using default", ttlStr)
+		}
+	}
+	if sizeStr := os.Getenv("VECTOR_CACHE_MAX_ENTRIES"); sizeStr != "" {
+		if size, err := strconv.Atoi(sizeStr); err == nil {
+			cfg.VectorCacheMaxEntries = size
+		}
+	}
+
+	// Parse Redis cache settings
+	cfg.RedisURL = os.Getenv("REDIS_URL")
+	if ttlStr := os.Getenv("CACHE_TTL"); ttlStr != "" {
+		if ttl, err := time.ParseDuration(ttlStr); err == nil {
+			cfg.CacheTTL = ttl
+		} else {
+			log.Printf("⚠️ Invalid CACHE_TTL %q, using default", ttlStr)
+		}
+	}
+
+	// Parse regions
+	cfg.DefaultRegion = os.Getenv("DEFAULT_REGION")
+	for _, code := range strings.Split(os.Getenv("REGIONS"), ",") {
+		code = strings.ToLower(strings.TrimSpace(code))
+		if code == "" {
+			continue
+		}
+		prefix := "REGION_" + strings.ToUpper(strings.ReplaceAll(code, "-", "_")) + "_"
+		region := RegionConfig{
+			Code:           code,
+			DatabaseURL:    os.Getenv(prefix + "DATABASE_URL"),
+			QdrantCloudURL: os.Getenv(prefix + "QDRANT_CLOUD_URL"),
+			QdrantAPIKey:   os.Getenv(prefix + "QDRANT_CLOUD_API_KEY"),
+			QdrantHost:     os.Getenv(prefix + "QDRANT_HOST"),
+			QdrantPort:     6334,
+			S3Region:       os.Getenv(prefix + "S3_REGION"),
+			S3BucketName:   os.Getenv(prefix + "S3_BUCKET_NAME"),
+		}
+		if portStr := os.Getenv(prefix + "QDRANT_PORT"); portStr != "" {
+			if port, err := strconv.Atoi(portStr); err == nil {
+				region.QdrantPort = port
+			}
+		}
+		if region.DatabaseURL == "" {
+			log.Printf("⚠️ Region %q has no %sDATABASE_URL, skipping", code, prefix)
+			continue
+		}
+		cfg.Regions = append(cfg.Regions, region)
+	}
+
+	// Parse legacy route sunset date
+	if dateStr := os.Getenv("API_LEGACY_SUNSET"); dateStr != "" {
+		if date, err := time.Parse("2006-01-02", dateStr); err == nil {
+			cfg.LegacyRoutesSunset = date
+		} else {
+			log.Printf("⚠️ Invalid API_LEGACY_SUNSET %q, using default", dateStr)
+		}
+	}
+
 	// Default values
 	if cfg.Port == "" {
 		cfg.Port = "8080"
@@ -157,6 +381,15 @@ func LoadConfig() *Config {
 	if cfg.TesseractLanguage == "" {
 		cfg.TesseractLanguage = "eng" // Default to English
 	}
+	if cfg.STTProvider == "" {
+		cfg.STTProvider = "whisper" // Default to OpenAI Whisper
+	}
+	if cfg.GoogleSTTAPIKey == "" {
+		cfg.GoogleSTTAPIKey = cfg.GoogleVisionAPIKey // Same Google Cloud project key
+	}
+	if cfg.STTLanguage == "" {
+		cfg.STTLanguage = "id-ID" // Customers mostly speak Indonesian
+	}
 	if cfg.PaymentMode == "" {
 		cfg.PaymentMode = "manual" // Default to manual for MVP
 	}
@@ -194,9 +427,49 @@ func LoadConfig() *Config {
 	if cfg.EmbeddingProvider == "" {
 		cfg.EmbeddingProvider = "openai" // Default to OpenAI
 	}
+	if cfg.WebhookMaxBodyBytes <= 0 {
+		cfg.WebhookMaxBodyBytes = 24 * 1024 * 1024 // Fits WhatsApp's 16MB media limit as base64
+	}
+	if cfg.APIMaxBodyBytes <= 0 {
+		cfg.APIMaxBodyBytes = 4 * 1024 * 1024 // Fiber's default
+	}
+	if cfg.LLMHistoryTokens <= 0 {
+		cfg.LLMHistoryTokens = 1500
+	}
+	if cfg.LLMHistoryMaxAge <= 0 {
+		cfg.LLMHistoryMaxAge = 24 * time.Hour
+	}
+	if cfg.LegacyRoutesSunset.IsZero() {
+		cfg.LegacyRoutesSunset = time.Date(2027, time.April, 30, 0, 0, 0, 0, time.UTC) // Six months after /v1 shipped
+	}
 	if cfg.EmbeddingModel == "" {
 		cfg.EmbeddingModel = "text-embedding-3-small" // Default model (1536 dims, cheap)
 	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if cfg.VectorCacheTTL <= 0 {
+		cfg.VectorCacheTTL = time.Minute
+	}
+	if cfg.VectorCacheMaxEntries <= 0 {
+		cfg.VectorCacheMaxEntries = 5000
+	}
+	if cfg.CacheTTL <= 0 {
+		cfg.CacheTTL = 5 * time.Minute
+	}
+	if cfg.DefaultRegion == "" {
+		cfg.DefaultRegion = "default"
+	}
 
 	return cfg
 }
+
+// DatabasePool returns the connection pool settings of the database
+func (c *Config) DatabasePool() database.PoolConfig {
+	return database.PoolConfig{
+		MaxOpenConns:    c.DBMaxOpenConns,
+		MaxIdleConns:    c.DBMaxIdleConns,
+		ConnMaxLifetime: c.DBConnMaxLifetime,
+		ConnMaxIdleTime: c.DBConnMaxIdleTime,
+	}
+}
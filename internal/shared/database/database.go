@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"log"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -15,8 +16,36 @@ type DB struct {
 	GORM    *gorm.DB // New GORM instance
 }
 
+// PoolConfig sizes the connection pool; zero fields use the defaults
+type PoolConfig struct {
+	MaxOpenConns    int           // Default: 25
+	MaxIdleConns    int           // Default: 5
+	ConnMaxLifetime time.Duration // Connections are recycled after this long (default: 1h)
+	ConnMaxIdleTime time.Duration // Idle connections are closed after this long (default: 10m)
+}
+
+// withDefaults fills the unset fields in
+func (p PoolConfig) withDefaults() PoolConfig {
+	if p.MaxOpenConns <= 0 {
+		p.MaxOpenConns = 25
+	}
+	if p.MaxIdleConns <= 0 {
+		p.MaxIdleConns = 5
+	}
+	if p.MaxIdleConns > p.MaxOpenConns {
+		p.MaxIdleConns = p.MaxOpenConns
+	}
+	if p.ConnMaxLifetime <= 0 {
+		p.ConnMaxLifetime = time.Hour
+	}
+	if p.ConnMaxIdleTime <= 0 {
+		p.ConnMaxIdleTime = 10 * time.Minute
+	}
+	return p
+}
+
 // NewDB creates a new database connection using GORM
-func NewDB(connStr string) *DB {
+func NewDB(connStr string, pool PoolConfig) *DB {
 	if connStr == "" {
 		log.Fatal("❌ DATABASE_URL is empty")
 	}
@@ -36,16 +65,18 @@ func NewDB(connStr string) *DB {
 	}
 
 	// Connection pool settings
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(60 * 60) // 1 hour in seconds
+	pool = pool.withDefaults()
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	// Ping to verify connection
 	if err := sqlDB.Ping(); err != nil {
 		log.Fatalf("❌ Failed to ping database: %v", err)
 	}
 
-	log.Println("✅ Database connected (GORM)!")
+	log.Printf("✅ Database connected (GORM)! Pool: %d open, %d idle max", pool.MaxOpenConns, pool.MaxIdleConns)
 	return &DB{
 		DB:   sqlDB,
 		GORM: gormDB,
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"net/url"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/lib/pq"
+)
+
+// migrationLockKey is the Postgres advisory lock held while migrating, so replicas starting together run migrations one at a time
+const migrationLockKey int64 = 7_331_000_001
+
+// MigrationSource is a module's migration directory and the table its applied version is kept in
+type MigrationSource struct {
+	Module string
+	Table  string
+}
+
+// MigrationStatus is the schema version recorded by golang-migrate
+type MigrationStatus struct {
+	Version uint `json:"version"`
+	Dirty   bool `json:"dirty"`
+}
+
+// RunMigrations applies pending migrations of each source in order from an embedded filesystem (e.g. migrations.FS).
+// Other replicas block on the advisory lock and find nothing left to apply once they get it.
+func (db *DB) RunMigrations(ctx context.Context, connStr string, fsys fs.FS, sources ...MigrationSource) error {
+	conn, err := db.DB.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection for migration lock: %w", err)
+	}
+	defer conn.Close()
+
+	log.Printf("🔒 Waiting for migration lock...")
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey); err != nil {
+			log.Printf("⚠️ Failed to release migration lock: %v", err)
+		}
+	}()
+
+	for _, source := range sources {
+		status, err := migrateUp(connStr, fsys, source)
+		if err != nil {
+			return fmt.Errorf("%s: %w", source.Module, err)
+		}
+		log.Printf("✅ %s migrations applied, version %d (dirty: %t)", source.Module, status.Version, status.Dirty)
+	}
+	return nil
+}
+
+func migrateUp(connStr string, fsys fs.FS, source MigrationSource) (*MigrationStatus, error) {
+	files, err := iofs.New(fsys, source.Module)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migrations: %w", err)
+	}
+
+	dsn, err := MigrationURL(connStr, source.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", files, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
+	}
+	defer m.Close()
+
+	log.Printf("⬆️  Running %s migrations...", source.Module)
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return nil, fmt.Errorf("migration up failed: %w", err)
+	}
+
+	version, dirty, err := m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return &MigrationStatus{Version: version, Dirty: dirty}, nil
+}
+
+// MigrationURL points a database URL at a module's version table
+func MigrationURL(connStr, table string) (string, error) {
+	u, err := url.Parse(connStr)
+	if err != nil {
+		return "", fmt.Errorf("invalid database URL: %w", err)
+	}
+	query := u.Query()
+	query.Set("x-migrations-table", table)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// MigrationVersion reads the version applied from a version table, nil if the module has never been migrated
+func (db *DB) MigrationVersion(ctx context.Context, table string) (*MigrationStatus, error) {
+	var exists bool
+	if err := db.DB.QueryRowContext(ctx, "SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var status MigrationStatus
+	rows, err := db.DB.QueryContext(ctx, "SELECT version, dirty FROM "+pq.QuoteIdentifier(table)+" LIMIT 1")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+	if err := rows.Scan(&status.Version, &status.Dirty); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// tenantCopyBatchSize is the number of rows inserted per statement when copying a tenant between databases
+const tenantCopyBatchSize = 500
+
+// TenantTables lists the tables holding rows of a tenant (those with a client_id column), referenced tables first
+// so the rows can be inserted into another database without breaking foreign keys
+func (db *DB) TenantTables(ctx context.Context) ([]string, error) {
+	rows, err := db.DB.QueryContext(ctx, `
+		SELECT c.table_name
+		FROM information_schema.columns c
+		JOIN information_schema.tables t ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+		WHERE c.table_schema = current_schema() AND c.column_name = 'client_id' AND t.table_type = 'BASE TABLE'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tenant tables: %w", err)
+	}
+	tables := map[string]bool{}
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables[table] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = db.DB.QueryContext(ctx, `
+		SELECT DISTINCT tc.table_name, ccu.table_name
+		FROM information_schema.table_constraints tc
+		JOIN information_schema.constraint_column_usage ccu
+			ON ccu.constraint_schema = tc.constraint_schema AND ccu.constraint_name = tc.constraint_name
+		WHERE tc.table_schema = current_schema() AND tc.constraint_type = 'FOREIGN KEY'
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+	defer rows.Close()
+	dependsOn := map[string][]string{}
+	for rows.Next() {
+		var table, referenced string
+		if err := rows.Scan(&table, &referenced); err != nil {
+			return nil, err
+		}
+		if tables[table] && tables[referenced] && table != referenced {
+			dependsOn[table] = append(dependsOn[table], referenced)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(tables))
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+
+	// Depth-first topological sort; a foreign key cycle keeps the alphabetical order of its tables
+	ordered := make([]string, 0, len(names))
+	state := map[string]int{}
+	var visit func(table string)
+	visit = func(table string) {
+		if state[table] != 0 {
+			return
+		}
+		state[table] = 1
+		for _, referenced := range dependsOn[table] {
+			visit(referenced)
+		}
+		state[table] = 2
+		ordered = append(ordered, table)
+	}
+	for _, table := range names {
+		visit(table)
+	}
+	return ordered, nil
+}
+
+// CopyRows copies the rows of table whose column equals value into the same table of dst, skipping rows dst
+// already has, and returns how many were inserted. Both databases must be on the same schema version.
+func CopyRows(ctx context.Context, src, dst *DB, table, column string, value interface{}) (int64, error) {
+	quotedTable := pq.QuoteIdentifier(table)
+	rows, err := src.DB.QueryContext(ctx,
+		fmt.Sprintf("SELECT row_to_json(t)::text FROM %s t WHERE %s = $1", quotedTable, pq.QuoteIdentifier(column)), value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	insert := fmt.Sprintf("INSERT INTO %s SELECT * FROM json_populate_recordset(NULL::%s, $1::json) ON CONFLICT DO NOTHING", quotedTable, quotedTable)
+	var copied int64
+	batch := make([]string, 0, tenantCopyBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := dst.DB.ExecContext(ctx, insert, "["+strings.Join(batch, ",")+"]")
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", table, err)
+		}
+		inserted, _ := result.RowsAffected()
+		copied += inserted
+		batch = batch[:0]
+		return nil
+	}
+
+	for rows.Next() {
+		var row string
+		if err := rows.Scan(&row); err != nil {
+			return copied, err
+		}
+		batch = append(batch, row)
+		if len(batch) == tenantCopyBatchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return copied, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	if err := flush(); err != nil {
+		return copied, err
+	}
+	return copied, nil
+}
+
+// CountRows counts the rows of table whose column equals value
+func (db *DB) CountRows(ctx context.Context, table, column string, value interface{}) (int64, error) {
+	var count int64
+	err := db.DB.QueryRowContext(ctx,
+		fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = $1", pq.QuoteIdentifier(table), pq.QuoteIdentifier(column)), value).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_company_users_client_external_ref;
+DROP INDEX IF EXISTS idx_clients_external_ref;
+
+ALTER TABLE company_users DROP COLUMN IF EXISTS external_ref;
+ALTER TABLE clients DROP COLUMN IF EXISTS external_ref;
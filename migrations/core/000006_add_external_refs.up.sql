@@ -0,0 +1,9 @@
+-- External reference IDs supplied by provisioning tools (Terraform etc.) so repeated calls update instead of duplicating
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS external_ref TEXT;
+ALTER TABLE company_users ADD COLUMN IF NOT EXISTS external_ref TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_clients_external_ref ON clients(external_ref) WHERE external_ref IS NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_company_users_client_external_ref ON company_users(client_id, external_ref) WHERE external_ref IS NOT NULL;
+
+COMMENT ON COLUMN clients.external_ref IS 'Reference ID from the provisioning tool, unique across clients';
+COMMENT ON COLUMN company_users.external_ref IS 'Reference ID from the provisioning tool, unique per client';
@@ -0,0 +1,11 @@
+-- Incoming WhatsApp message IDs already handled, shared by every replica so a message
+-- delivered twice (webhook retries, polling overlap) is processed once
+CREATE TABLE IF NOT EXISTS whatsapp_message_dedup (
+    message_id TEXT PRIMARY KEY,
+    seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Expired IDs are deleted by seen_at
+CREATE INDEX idx_whatsapp_message_dedup_seen_at ON whatsapp_message_dedup(seen_at);
+
+COMMENT ON TABLE whatsapp_message_dedup IS 'Seen incoming WhatsApp message IDs for cross-replica deduplication';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_clients_region;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS region;
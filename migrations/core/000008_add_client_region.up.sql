@@ -0,0 +1,6 @@
+-- Region the tenant's data is pinned to; empty = the default region (this database)
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS region TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_clients_region ON clients(region) WHERE region <> '';
+
+COMMENT ON COLUMN clients.region IS 'Region code whose database, vector DB and storage hold the tenant''s data; empty = default region';
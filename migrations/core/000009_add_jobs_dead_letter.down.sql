@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_jobs_dead_letter;
+
+DROP INDEX IF EXISTS idx_jobs_dequeue;
+CREATE INDEX idx_jobs_dequeue ON jobs(queue, status, priority DESC, created_at)
+    WHERE status = 'pending';
+
+UPDATE jobs SET status = 'failed' WHERE status = 'dead_letter';
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'retrying', 'cancelled'));
@@ -0,0 +1,11 @@
+-- Jobs whose retries ran out move to dead_letter instead of failed, so they can be inspected and requeued
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_status_check;
+ALTER TABLE jobs ADD CONSTRAINT jobs_status_check
+    CHECK (status IN ('pending', 'processing', 'completed', 'failed', 'retrying', 'cancelled', 'dead_letter'));
+
+-- Retrying jobs are dequeued again once their backoff has passed
+DROP INDEX IF EXISTS idx_jobs_dequeue;
+CREATE INDEX idx_jobs_dequeue ON jobs(queue, status, priority DESC, created_at)
+    WHERE status IN ('pending', 'retrying');
+
+CREATE INDEX IF NOT EXISTS idx_jobs_dead_letter ON jobs(queue, failed_at DESC) WHERE status = 'dead_letter';
@@ -0,0 +1,9 @@
+// Package migrations embeds the SQL migration files so services can apply them on startup
+package migrations
+
+import "embed"
+
+// FS holds the migrations of every module, one directory per module (e.g. "saas")
+//
+//go:embed core/*.sql saas/*.sql
+var FS embed.FS
@@ -0,0 +1,168 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/MuhamadAgungGumelar/micro-system-ai-agent-be/internal/shared/database"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Module is a directory of migrations with its own version table
+type Module struct {
+	Name      string   `json:"name"`
+	DependsOn []string `json:"depends_on"` // Modules whose migrations must be applied first
+	Table     string   `json:"table"`      // golang-migrate version table
+}
+
+// Modules lists every migration module and its dependencies.
+// saas keeps the default version table so existing deployments stay at their current version.
+var Modules = []Module{
+	{Name: "saas", DependsOn: []string{}, Table: "schema_migrations"},
+	// core 000001 copies saas_clients into clients and repoints the saas_* foreign keys
+	{Name: "core", DependsOn: []string{"saas"}, Table: "schema_migrations_core"},
+}
+
+// Find returns a module by name
+func Find(name string) (Module, bool) {
+	for _, m := range Modules {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// Resolve returns the named modules (every module if none are named) with their dependencies, dependencies first
+func Resolve(names ...string) ([]Module, error) {
+	if len(names) == 0 {
+		for _, m := range Modules {
+			names = append(names, m.Name)
+		}
+	}
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int)
+	var ordered []Module
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("migration dependency cycle: %v", append(path, name))
+		}
+
+		m, ok := Find(name)
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("module %s depends on unknown module %s", path[len(path)-1], name)
+			}
+			return fmt.Errorf("unknown migration module %s", name)
+		}
+
+		state[name] = visiting
+		for _, dep := range m.DependsOn {
+			if err := visit(dep, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, m)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Up applies the embedded migrations of the named modules (every module if none are named) and their dependencies, in order
+func Up(ctx context.Context, db *database.DB, connStr string, names ...string) error {
+	modules, err := Resolve(names...)
+	if err != nil {
+		return err
+	}
+
+	sources := make([]database.MigrationSource, len(modules))
+	for i, m := range modules {
+		sources[i] = database.MigrationSource{Module: m.Name, Table: m.Table}
+	}
+	return db.RunMigrations(ctx, connStr, FS, sources...)
+}
+
+// Versions lists the migration versions embedded for a module, oldest first
+func Versions(name string) ([]uint, error) {
+	source, err := iofs.New(FS, name)
+	if err != nil {
+		return nil, err
+	}
+	defer source.Close()
+
+	version, err := source.First()
+	if err != nil {
+		return nil, err
+	}
+
+	versions := []uint{version}
+	for {
+		version, err = source.Next(version)
+		if errors.Is(err, fs.ErrNotExist) || errors.Is(err, os.ErrNotExist) {
+			return versions, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+}
+
+// ModuleStatus is a module's applied version against the migrations embedded in this build
+type ModuleStatus struct {
+	Module
+	Version *uint `json:"version"` // Nil if the module has never been migrated
+	Dirty   bool  `json:"dirty"`
+	Latest  uint  `json:"latest"`
+	Pending int   `json:"pending"`
+}
+
+// Status reports every module in dependency order
+func Status(ctx context.Context, db *database.DB) ([]ModuleStatus, error) {
+	modules, err := Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]ModuleStatus, len(modules))
+	for i, m := range modules {
+		applied, err := db.MigrationVersion(ctx, m.Table)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Name, err)
+		}
+		versions, err := Versions(m.Name)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", m.Name, err)
+		}
+
+		status := ModuleStatus{Module: m, Latest: versions[len(versions)-1], Pending: len(versions)}
+		if applied != nil {
+			status.Version = &applied.Version
+			status.Dirty = applied.Dirty
+			next, _ := slices.BinarySearch(versions, applied.Version+1)
+			status.Pending = len(versions) - next
+		}
+		statuses[i] = status
+	}
+	return statuses, nil
+}
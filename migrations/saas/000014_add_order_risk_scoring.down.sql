@@ -0,0 +1,9 @@
+-- Drop risk rules and risk columns
+DROP TABLE IF EXISTS saas_order_risk_rules CASCADE;
+
+DROP INDEX IF EXISTS idx_orders_review_status;
+ALTER TABLE saas_orders DROP CONSTRAINT IF EXISTS valid_review_status;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS review_status;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS risk_flags;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS risk_score;
@@ -0,0 +1,44 @@
+-- Add risk scoring columns to orders
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS risk_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS risk_flags JSONB;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS review_status TEXT NOT NULL DEFAULT 'none';
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMP;
+
+ALTER TABLE saas_orders ADD CONSTRAINT valid_review_status
+    CHECK (review_status IN ('none', 'needs_review', 'approved', 'rejected'));
+
+CREATE INDEX IF NOT EXISTS idx_orders_review_status ON saas_orders(review_status) WHERE review_status = 'needs_review';
+
+-- Per-tenant risk rules
+CREATE TABLE IF NOT EXISTS saas_order_risk_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    review_threshold INTEGER NOT NULL DEFAULT 50, -- Score at or above this puts the order on hold
+
+    -- Abnormal order value
+    max_order_amount DECIMAL(12,2) NOT NULL DEFAULT 0, -- 0 = no absolute limit
+    value_multiplier DECIMAL(6,2) NOT NULL DEFAULT 5,  -- Flag if total > multiplier x customer average
+    min_order_history INTEGER NOT NULL DEFAULT 3,       -- Orders needed before average is trusted
+
+    -- Order velocity
+    velocity_max_orders INTEGER NOT NULL DEFAULT 3,
+    velocity_window_minutes INTEGER NOT NULL DEFAULT 10,
+
+    -- Payment proof
+    proof_amount_tolerance DECIMAL(12,2) NOT NULL DEFAULT 0,
+
+    -- Blocked numbers
+    blocked_phones TEXT[],
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_order_risk_rules_updated_at
+    BEFORE UPDATE ON saas_order_risk_rules
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_order_risk_rules IS 'Per-tenant fraud and anomaly rules applied on order creation';
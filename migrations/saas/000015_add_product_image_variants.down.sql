@@ -0,0 +1,4 @@
+-- Remove hosted image variant columns
+ALTER TABLE saas_products DROP COLUMN IF EXISTS image_public_ids;
+ALTER TABLE saas_products DROP COLUMN IF EXISTS whatsapp_image_url;
+ALTER TABLE saas_products DROP COLUMN IF EXISTS thumbnail_url;
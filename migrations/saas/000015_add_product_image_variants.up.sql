@@ -0,0 +1,4 @@
+-- Add hosted image variants to products
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS thumbnail_url TEXT;
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS whatsapp_image_url TEXT; -- Re-encoded to stay under WhatsApp's 1MB limit
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS image_public_ids TEXT[];  -- Storage IDs of every variant, used for cleanup
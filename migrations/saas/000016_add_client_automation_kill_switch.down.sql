@@ -0,0 +1,4 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS automation_paused_reason;
+ALTER TABLE clients DROP COLUMN IF EXISTS automation_paused_by;
+ALTER TABLE clients DROP COLUMN IF EXISTS automation_paused_at;
+ALTER TABLE clients DROP COLUMN IF EXISTS automation_paused;
@@ -0,0 +1,5 @@
+-- Client-level automation kill switch (stops event and scheduled workflows)
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS automation_paused BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS automation_paused_at TIMESTAMP;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS automation_paused_by TEXT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS automation_paused_reason TEXT;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_whatsapp_provisioning_updated_at ON saas_whatsapp_provisioning;
+DROP TABLE IF EXISTS saas_whatsapp_provisioning;
@@ -0,0 +1,32 @@
+-- Tracks automated WhatsApp (WAHA) provisioning during tenant onboarding
+CREATE TABLE IF NOT EXISTS saas_whatsapp_provisioning (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+
+    -- Session
+    session_id TEXT NOT NULL,
+    session_created_at TIMESTAMP,
+
+    -- Webhook (per-tenant token route, signed with HMAC)
+    webhook_token TEXT NOT NULL UNIQUE,
+    webhook_secret TEXT NOT NULL,
+    webhook_url TEXT,
+    webhook_configured_at TIMESTAMP,
+
+    -- End-to-end self-test
+    self_test_nonce TEXT,
+    self_test_sent_at TIMESTAMP,
+    self_test_verified_at TIMESTAMP,
+
+    last_error TEXT,
+
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_whatsapp_provisioning_updated_at
+    BEFORE UPDATE ON saas_whatsapp_provisioning
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_whatsapp_provisioning IS 'WAHA session, webhook and self-test state per tenant';
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS saas_sandbox_messages;
+DROP INDEX IF EXISTS idx_saas_orders_is_test;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS is_test;
+ALTER TABLE clients DROP COLUMN IF EXISTS sandbox_mode;
@@ -0,0 +1,19 @@
+-- Per-tenant sandbox (test) mode with simulated WhatsApp and payments
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS sandbox_mode BOOLEAN NOT NULL DEFAULT FALSE;
+
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
+CREATE INDEX IF NOT EXISTS idx_saas_orders_is_test ON saas_orders(is_test) WHERE is_test = TRUE;
+
+-- Messages captured instead of being sent while a tenant is in sandbox mode
+CREATE TABLE IF NOT EXISTS saas_sandbox_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    direction TEXT NOT NULL CHECK (direction IN ('inbound', 'outbound')),
+    phone TEXT NOT NULL,
+    message TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_sandbox_messages_client_created ON saas_sandbox_messages(client_id, created_at DESC);
+
+COMMENT ON TABLE saas_sandbox_messages IS 'Simulated WhatsApp traffic for tenants in sandbox mode';
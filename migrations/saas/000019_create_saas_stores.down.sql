@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_saas_stores_updated_at ON saas_stores;
+DROP TABLE IF EXISTS saas_stores;
@@ -0,0 +1,34 @@
+-- Store/branch locations per tenant (used by the store locator)
+CREATE TABLE IF NOT EXISTS saas_stores (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+
+    -- Store Info
+    name TEXT NOT NULL,
+    address TEXT NOT NULL,
+    phone TEXT,
+    opening_hours TEXT, -- Free text, e.g. "Senin-Sabtu 08.00-21.00"
+
+    -- Coordinates
+    latitude DOUBLE PRECISION NOT NULL,
+    longitude DOUBLE PRECISION NOT NULL,
+
+    -- Status
+    is_active BOOLEAN DEFAULT true,
+
+    -- Timestamps
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX idx_saas_stores_client_id ON saas_stores(client_id);
+CREATE INDEX idx_saas_stores_is_active ON saas_stores(is_active);
+CREATE INDEX idx_saas_stores_deleted_at ON saas_stores(deleted_at);
+
+CREATE TRIGGER update_saas_stores_updated_at
+    BEFORE UPDATE ON saas_stores
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_stores IS 'Store/branch locations per tenant with coordinates for nearest-store lookup';
@@ -0,0 +1,10 @@
+ALTER TABLE saas_carts DROP COLUMN IF EXISTS branch_id;
+
+DROP INDEX IF EXISTS idx_saas_orders_branch_id;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS branch_id;
+
+DROP TRIGGER IF EXISTS update_saas_branch_stock_updated_at ON saas_branch_stock;
+DROP TABLE IF EXISTS saas_branch_stock;
+
+ALTER TABLE saas_stores DROP COLUMN IF EXISTS admin_phone;
+ALTER TABLE saas_stores DROP COLUMN IF EXISTS admin_name;
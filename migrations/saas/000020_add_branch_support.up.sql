@@ -0,0 +1,31 @@
+-- Multi-branch support: stores act as branches with their own admin, stock and orders
+ALTER TABLE saas_stores ADD COLUMN IF NOT EXISTS admin_name TEXT;
+ALTER TABLE saas_stores ADD COLUMN IF NOT EXISTS admin_phone TEXT; -- Receives order notifications for this branch
+
+-- Stock per product per branch (products without rows here are not tracked per branch)
+CREATE TABLE IF NOT EXISTS saas_branch_stock (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    branch_id UUID NOT NULL REFERENCES saas_stores(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES saas_products(id) ON DELETE CASCADE,
+    stock INTEGER NOT NULL DEFAULT 0 CHECK (stock >= 0),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (branch_id, product_id)
+);
+
+CREATE INDEX idx_saas_branch_stock_client_id ON saas_branch_stock(client_id);
+CREATE INDEX idx_saas_branch_stock_product_id ON saas_branch_stock(product_id);
+
+CREATE TRIGGER update_saas_branch_stock_updated_at
+    BEFORE UPDATE ON saas_branch_stock
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Branch that fulfills an order / is selected for a cart
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS branch_id UUID REFERENCES saas_stores(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_saas_orders_branch_id ON saas_orders(branch_id);
+
+ALTER TABLE saas_carts ADD COLUMN IF NOT EXISTS branch_id UUID REFERENCES saas_stores(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE saas_branch_stock IS 'Product stock per branch (saas_stores) for multi-branch tenants';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_saas_shipments_updated_at ON saas_shipments;
+DROP TABLE IF EXISTS saas_shipments;
+
+DROP TRIGGER IF EXISTS update_saas_drivers_updated_at ON saas_drivers;
+DROP TABLE IF EXISTS saas_drivers;
@@ -0,0 +1,47 @@
+-- Delivery drivers per tenant (optionally attached to a branch)
+CREATE TABLE IF NOT EXISTS saas_drivers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    branch_id UUID REFERENCES saas_stores(id) ON DELETE SET NULL,
+    name TEXT NOT NULL,
+    phone TEXT NOT NULL,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, phone)
+);
+
+CREATE INDEX idx_saas_drivers_client_id ON saas_drivers(client_id);
+CREATE INDEX idx_saas_drivers_phone ON saas_drivers(phone);
+
+CREATE TRIGGER update_saas_drivers_updated_at
+    BEFORE UPDATE ON saas_drivers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Shipment of an order by a driver
+CREATE TABLE IF NOT EXISTS saas_shipments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES saas_orders(id) ON DELETE CASCADE,
+    driver_id UUID NOT NULL REFERENCES saas_drivers(id),
+    status TEXT NOT NULL DEFAULT 'assigned' CHECK (status IN ('assigned', 'picked_up', 'delivered', 'cancelled')),
+    delivery_address TEXT NOT NULL,
+    notes TEXT,
+    assigned_at TIMESTAMP DEFAULT NOW(),
+    picked_up_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_shipments_client_status ON saas_shipments(client_id, status);
+CREATE INDEX idx_saas_shipments_driver_status ON saas_shipments(driver_id, status);
+
+CREATE TRIGGER update_saas_shipments_updated_at
+    BEFORE UPDATE ON saas_shipments
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_drivers IS 'Delivery drivers who receive jobs and update status via WhatsApp';
+COMMENT ON TABLE saas_shipments IS 'Order deliveries assigned to drivers';
@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_saas_waitlist_updated_at ON saas_waitlist;
+DROP TABLE IF EXISTS saas_waitlist;
+
+ALTER TABLE saas_products DROP COLUMN IF EXISTS preorder_available_at;
+ALTER TABLE saas_products DROP COLUMN IF EXISTS preorder_enabled;
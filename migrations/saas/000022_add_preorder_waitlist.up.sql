@@ -0,0 +1,33 @@
+-- Pre-order mode per product (out-of-stock products can still be ordered)
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS preorder_enabled BOOLEAN DEFAULT false;
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS preorder_available_at TIMESTAMP; -- Expected availability date shown to customers
+
+-- Customers waiting for an out-of-stock product (restock alert) or who pre-ordered it
+CREATE TABLE IF NOT EXISTS saas_waitlist (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES saas_products(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    type TEXT NOT NULL DEFAULT 'restock' CHECK (type IN ('restock', 'preorder')),
+    status TEXT NOT NULL DEFAULT 'offered' CHECK (status IN ('offered', 'waiting', 'notified', 'converted', 'cancelled')),
+    quantity INTEGER NOT NULL DEFAULT 1,
+    order_id UUID REFERENCES saas_orders(id) ON DELETE SET NULL, -- Order the entry converted into
+    notified_at TIMESTAMP,
+    converted_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_waitlist_product_status ON saas_waitlist(product_id, status);
+CREATE INDEX idx_saas_waitlist_customer ON saas_waitlist(client_id, customer_phone);
+
+-- One open entry per customer per product
+CREATE UNIQUE INDEX idx_saas_waitlist_open_entry ON saas_waitlist(product_id, customer_phone)
+    WHERE status IN ('offered', 'waiting');
+
+CREATE TRIGGER update_saas_waitlist_updated_at
+    BEFORE UPDATE ON saas_waitlist
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_waitlist IS 'Restock alerts and pre-orders for out-of-stock products, with conversion tracking';
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS update_saas_quotes_updated_at ON saas_quotes;
+DROP TABLE IF EXISTS saas_quotes;
@@ -0,0 +1,31 @@
+-- Quotations (B2B): sent to the customer before an order is created
+CREATE TABLE IF NOT EXISTS saas_quotes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    quote_number TEXT NOT NULL UNIQUE,
+    customer_phone TEXT NOT NULL,
+    customer_name TEXT,
+    items JSONB NOT NULL DEFAULT '[]'::jsonb,
+    subtotal DECIMAL(12,2) NOT NULL DEFAULT 0,
+    discount_amount DECIMAL(12,2) NOT NULL DEFAULT 0, -- Quote-level discount on top of line discounts
+    total_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    notes TEXT,
+    status TEXT NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'sent', 'accepted', 'rejected', 'expired')),
+    valid_until TIMESTAMP NOT NULL,
+    access_token TEXT NOT NULL UNIQUE, -- Used in the customer accept link
+    order_id UUID REFERENCES saas_orders(id) ON DELETE SET NULL,
+    sent_at TIMESTAMP,
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_quotes_client_status ON saas_quotes(client_id, status);
+CREATE INDEX idx_saas_quotes_customer ON saas_quotes(client_id, customer_phone);
+
+CREATE TRIGGER update_saas_quotes_updated_at
+    BEFORE UPDATE ON saas_quotes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_quotes IS 'Quotations that convert into orders when the customer accepts';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_saas_orders_tracking_token;
+
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS tracking_expires_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS tracking_token;
@@ -0,0 +1,5 @@
+-- Public order tracking links (GET /t/:token)
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS tracking_token TEXT;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS tracking_expires_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_orders_tracking_token ON saas_orders(tracking_token) WHERE tracking_token IS NOT NULL;
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_saas_transactions_raw_text;
+
+ALTER TABLE saas_transactions DROP COLUMN IF EXISTS ocr_raw_text_purged_at;
+
+ALTER TABLE clients DROP COLUMN IF EXISTS ocr_anonymize;
+ALTER TABLE clients DROP COLUMN IF EXISTS ocr_raw_text_retention_days;
@@ -0,0 +1,8 @@
+-- Per-client retention and anonymization of raw OCR text (receipts may contain card digits or names)
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS ocr_raw_text_retention_days INTEGER NOT NULL DEFAULT 30;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS ocr_anonymize BOOLEAN NOT NULL DEFAULT TRUE;
+
+ALTER TABLE saas_transactions ADD COLUMN IF NOT EXISTS ocr_raw_text_purged_at TIMESTAMP;
+
+-- Purge job scans transactions that still hold raw text
+CREATE INDEX IF NOT EXISTS idx_saas_transactions_raw_text ON saas_transactions(client_id, created_at) WHERE ocr_raw_text IS NOT NULL AND ocr_raw_text <> '';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_product_mentions;
@@ -0,0 +1,15 @@
+-- Products customers ask about in WhatsApp conversations (for "most asked but least sold" analytics)
+CREATE TABLE IF NOT EXISTS saas_product_mentions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    product_id UUID NOT NULL REFERENCES saas_products(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    match_type TEXT NOT NULL CHECK (match_type IN ('exact', 'fuzzy', 'llm')),
+    matched_text TEXT, -- Part of the message that matched the product
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_product_mentions_client_created ON saas_product_mentions(client_id, created_at);
+CREATE INDEX idx_saas_product_mentions_product ON saas_product_mentions(product_id);
+
+COMMENT ON TABLE saas_product_mentions IS 'Product mention events detected in customer messages';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS saas_kb_gaps;
+DROP TABLE IF EXISTS saas_kb_suggestions;
+
+ALTER TABLE saas_conversations DROP COLUMN IF EXISTS rated_at;
+ALTER TABLE saas_conversations DROP COLUMN IF EXISTS rating;
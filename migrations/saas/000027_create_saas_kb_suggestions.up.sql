@@ -0,0 +1,43 @@
+-- Customer rating of bot answers (1-5), set from the dashboard
+ALTER TABLE saas_conversations ADD COLUMN IF NOT EXISTS rating SMALLINT CHECK (rating BETWEEN 1 AND 5);
+ALTER TABLE saas_conversations ADD COLUMN IF NOT EXISTS rated_at TIMESTAMP;
+
+-- FAQ entries drafted by the LLM from clustered KB gaps, waiting for tenant admin approval
+CREATE TABLE IF NOT EXISTS saas_kb_suggestions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    question TEXT NOT NULL,
+    answer TEXT NOT NULL,
+    sample_questions JSONB NOT NULL DEFAULT '[]', -- Customer questions in the cluster
+    occurrences INTEGER NOT NULL DEFAULT 1,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    kb_entry_id UUID REFERENCES saas_knowledge_base(id) ON DELETE SET NULL, -- Entry written on accept
+    reviewed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_kb_suggestions_client_status ON saas_kb_suggestions(client_id, status);
+
+CREATE TRIGGER update_saas_kb_suggestions_updated_at
+    BEFORE UPDATE ON saas_kb_suggestions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Questions the bot could not answer or whose answer was rated low
+CREATE TABLE IF NOT EXISTS saas_kb_gaps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    question TEXT NOT NULL,
+    ai_response TEXT,
+    reason TEXT NOT NULL CHECK (reason IN ('unanswered', 'low_rating')),
+    conversation_id UUID REFERENCES saas_conversations(id) ON DELETE SET NULL,
+    suggestion_id UUID REFERENCES saas_kb_suggestions(id) ON DELETE SET NULL, -- Set once clustered into a suggestion
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_saas_kb_gaps_unprocessed ON saas_kb_gaps(client_id, created_at) WHERE suggestion_id IS NULL;
+
+COMMENT ON TABLE saas_kb_gaps IS 'KB gap log: unanswered or low-rated customer questions';
+COMMENT ON TABLE saas_kb_suggestions IS 'Suggested FAQ entries queued for tenant admin approval';
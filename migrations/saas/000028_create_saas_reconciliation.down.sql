@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_reconciliation_reports;
+DROP TABLE IF EXISTS saas_payment_settlements;
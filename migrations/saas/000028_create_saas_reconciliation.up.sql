@@ -0,0 +1,49 @@
+-- Transactions as recorded by the payment gateway (pulled from the status API or imported from settlement reports)
+CREATE TABLE IF NOT EXISTS saas_payment_settlements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    gateway TEXT NOT NULL,
+    order_number TEXT NOT NULL, -- Order ID as sent to the gateway
+    transaction_id TEXT NOT NULL,
+    status TEXT NOT NULL,
+    payment_type TEXT,
+    gross_amount DECIMAL(12,2) NOT NULL DEFAULT 0,
+    settled_at TIMESTAMP,
+    source TEXT NOT NULL CHECK (source IN ('gateway_api', 'report_import')),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, gateway, transaction_id)
+);
+
+CREATE INDEX idx_saas_payment_settlements_client_settled ON saas_payment_settlements(client_id, settled_at);
+CREATE INDEX idx_saas_payment_settlements_order_number ON saas_payment_settlements(client_id, order_number);
+
+CREATE TRIGGER update_saas_payment_settlements_updated_at
+    BEFORE UPDATE ON saas_payment_settlements
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Daily reconciliation of paid orders against gateway settlements
+CREATE TABLE IF NOT EXISTS saas_reconciliation_reports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    report_date DATE NOT NULL,
+    gateway TEXT NOT NULL,
+    matched_count INT NOT NULL DEFAULT 0,
+    mismatch_count INT NOT NULL DEFAULT 0,
+    paid_total DECIMAL(14,2) NOT NULL DEFAULT 0,
+    settled_total DECIMAL(14,2) NOT NULL DEFAULT 0,
+    items JSONB NOT NULL DEFAULT '[]',
+    generated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, report_date)
+);
+
+CREATE TRIGGER update_saas_reconciliation_reports_updated_at
+    BEFORE UPDATE ON saas_reconciliation_reports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_payment_settlements IS 'Gateway transaction records used for payment reconciliation';
+COMMENT ON TABLE saas_reconciliation_reports IS 'Daily paid order vs gateway settlement reconciliation results';
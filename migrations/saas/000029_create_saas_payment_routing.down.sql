@@ -0,0 +1,2 @@
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS payment_route;
+DROP TABLE IF EXISTS saas_payment_routing_policies;
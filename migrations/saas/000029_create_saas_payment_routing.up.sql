@@ -0,0 +1,21 @@
+-- Per-tenant rules choosing the payment gateway for each order
+CREATE TABLE IF NOT EXISTS saas_payment_routing_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT true,
+    default_gateway TEXT, -- Gateway key when no rule matches (empty = system default)
+    rules JSONB NOT NULL DEFAULT '[]', -- Ordered rules, first match wins
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_payment_routing_policies_updated_at
+    BEFORE UPDATE ON saas_payment_routing_policies
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Which routing rule picked the order's gateway
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS payment_route TEXT;
+
+COMMENT ON TABLE saas_payment_routing_policies IS 'Payment gateway routing rules per client';
+COMMENT ON COLUMN saas_orders.payment_route IS 'Routing rule that selected payment_gateway (default when none matched)';
@@ -0,0 +1,10 @@
+UPDATE saas_orders SET payment_status = 'pending' WHERE payment_status = 'pending_cod';
+ALTER TABLE saas_orders DROP CONSTRAINT IF EXISTS valid_payment_status;
+ALTER TABLE saas_orders ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'paid', 'failed', 'cancelled', 'refunded'));
+
+DROP INDEX IF EXISTS idx_saas_orders_customer_method;
+ALTER TABLE saas_orders
+    DROP COLUMN IF EXISTS cod_collected_by,
+    DROP COLUMN IF EXISTS cod_confirmed_at;
+DROP TABLE IF EXISTS saas_cod_settings;
@@ -0,0 +1,32 @@
+-- Cash on delivery eligibility rules per client
+CREATE TABLE IF NOT EXISTS saas_cod_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT false,
+    max_order_amount DECIMAL(12,2) DEFAULT 0, -- 0 = no limit
+    min_paid_orders INT DEFAULT 0,
+    max_open_orders INT DEFAULT 1,
+    max_failed_orders INT DEFAULT 1, -- 0 = never block
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_cod_settings_updated_at
+    BEFORE UPDATE ON saas_cod_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+ALTER TABLE saas_orders
+    ADD COLUMN IF NOT EXISTS cod_confirmed_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS cod_collected_by TEXT;
+
+-- Confirmed COD orders wait for cash at delivery
+ALTER TABLE saas_orders DROP CONSTRAINT IF EXISTS valid_payment_status;
+ALTER TABLE saas_orders ADD CONSTRAINT valid_payment_status
+    CHECK (payment_status IN ('pending', 'pending_cod', 'paid', 'failed', 'cancelled', 'refunded'));
+
+CREATE INDEX IF NOT EXISTS idx_saas_orders_customer_method ON saas_orders(client_id, customer_phone, payment_method);
+
+COMMENT ON TABLE saas_cod_settings IS 'Cash on delivery eligibility rules per client';
+COMMENT ON COLUMN saas_orders.cod_confirmed_at IS 'When the customer confirmed paying cash on delivery';
+COMMENT ON COLUMN saas_orders.cod_collected_by IS 'Driver or admin who confirmed the cash was received';
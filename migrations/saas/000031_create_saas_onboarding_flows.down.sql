@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS update_saas_customer_onboarding_updated_at ON saas_customer_onboarding;
+DROP TABLE IF EXISTS saas_customer_onboarding;
+
+DROP TRIGGER IF EXISTS update_saas_onboarding_flows_updated_at ON saas_onboarding_flows;
+DROP TABLE IF EXISTS saas_onboarding_flows;
@@ -0,0 +1,44 @@
+-- First-contact flow per client (welcome, quick menu, language, consent)
+CREATE TABLE IF NOT EXISTS saas_onboarding_flows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT false,
+    welcome_message TEXT,
+    quick_menu JSONB NOT NULL DEFAULT '[]',
+    languages JSONB NOT NULL DEFAULT '[]',
+    consent_required BOOLEAN DEFAULT false,
+    consent_text TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_onboarding_flows_updated_at
+    BEFORE UPDATE ON saas_onboarding_flows
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Each customer's progress through the flow, so it only triggers once
+CREATE TABLE IF NOT EXISTS saas_customer_onboarding (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    step TEXT NOT NULL,
+    language TEXT,
+    consent_given BOOLEAN DEFAULT false,
+    consent_at TIMESTAMP,
+    menu_pending BOOLEAN DEFAULT false,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+
+    CONSTRAINT unique_customer_onboarding UNIQUE (client_id, customer_phone),
+    CONSTRAINT valid_onboarding_step CHECK (step IN ('language', 'consent', 'completed'))
+);
+
+CREATE TRIGGER update_saas_customer_onboarding_updated_at
+    BEFORE UPDATE ON saas_customer_onboarding
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_onboarding_flows IS 'Greeting and onboarding flow for first-time customers';
+COMMENT ON TABLE saas_customer_onboarding IS 'Per-customer onboarding progress, language and consent';
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_clients_bot_resume_at;
+ALTER TABLE clients DROP COLUMN IF EXISTS bot_resume_at;
+ALTER TABLE clients DROP COLUMN IF EXISTS bot_paused_by;
+ALTER TABLE clients DROP COLUMN IF EXISTS bot_paused_at;
+ALTER TABLE clients DROP COLUMN IF EXISTS bot_paused;
@@ -0,0 +1,10 @@
+-- Out-of-office / vacation mode: the bot stops answering customers until resumed
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS bot_paused BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS bot_paused_at TIMESTAMP;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS bot_paused_by TEXT;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS bot_resume_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_clients_bot_resume_at ON clients(bot_resume_at) WHERE bot_paused = TRUE;
+
+COMMENT ON COLUMN clients.bot_paused IS 'Bot paused by the owner (vacation mode); customers get an away message';
+COMMENT ON COLUMN clients.bot_resume_at IS 'When the bot resumes automatically (NULL = until resumed manually)';
@@ -0,0 +1,16 @@
+-- Maps customer emoji reactions to intents (confirm, cancel) per client
+CREATE TABLE IF NOT EXISTS saas_reaction_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    intents JSONB, -- {"👍": "confirm", "❌": "cancel"}
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_reaction_settings_updated_at
+    BEFORE UPDATE ON saas_reaction_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_reaction_settings IS 'Customer reaction (emoji) to intent mapping per client';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS saas_language_settings;
+DROP INDEX IF EXISTS idx_saas_conversations_client_language;
+ALTER TABLE saas_conversations DROP COLUMN IF EXISTS language;
@@ -0,0 +1,23 @@
+-- Detected language of each customer message, for analytics
+ALTER TABLE saas_conversations ADD COLUMN IF NOT EXISTS language TEXT;
+CREATE INDEX IF NOT EXISTS idx_saas_conversations_client_language ON saas_conversations(client_id, language);
+
+-- Reply-language matching per client
+CREATE TABLE IF NOT EXISTS saas_language_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT false,
+    default_language TEXT DEFAULT 'id',
+    allowed_languages JSONB, -- ["id", "en", "jv"]
+    post_process BOOLEAN DEFAULT false,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_language_settings_updated_at
+    BEFORE UPDATE ON saas_language_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON COLUMN saas_conversations.language IS 'Detected language of the customer message (empty when unsure)';
+COMMENT ON TABLE saas_language_settings IS 'Reply-language detection and matching per client';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_saas_conversations_outbound_message;
+DROP INDEX IF EXISTS idx_saas_conversations_inbound_message;
+ALTER TABLE saas_conversations
+    DROP COLUMN IF EXISTS reply_to_message_id,
+    DROP COLUMN IF EXISTS outbound_message_id,
+    DROP COLUMN IF EXISTS inbound_message_id;
@@ -0,0 +1,12 @@
+-- Provider message IDs per conversation turn, for correlating acks, reactions and quoted replies
+ALTER TABLE saas_conversations
+    ADD COLUMN IF NOT EXISTS inbound_message_id TEXT,
+    ADD COLUMN IF NOT EXISTS outbound_message_id TEXT,
+    ADD COLUMN IF NOT EXISTS reply_to_message_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_saas_conversations_inbound_message ON saas_conversations(client_id, inbound_message_id) WHERE inbound_message_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_saas_conversations_outbound_message ON saas_conversations(client_id, outbound_message_id) WHERE outbound_message_id IS NOT NULL;
+
+COMMENT ON COLUMN saas_conversations.inbound_message_id IS 'Provider message ID of the customer message';
+COMMENT ON COLUMN saas_conversations.outbound_message_id IS 'Provider message ID of the bot reply';
+COMMENT ON COLUMN saas_conversations.reply_to_message_id IS 'Provider message ID the customer quoted';
@@ -0,0 +1,25 @@
+-- Client offboarding runs: deactivation cascade tracked step by step, with a data retention countdown
+CREATE TABLE IF NOT EXISTS saas_client_offboardings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending',
+    steps JSONB NOT NULL DEFAULT '[]',
+    reason TEXT,
+    requested_by TEXT,
+    retention_days INT NOT NULL DEFAULT 30,
+    purge_at TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_client_offboardings_client ON saas_client_offboardings(client_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_client_offboardings_open ON saas_client_offboardings(status) WHERE status IN ('pending', 'running', 'failed');
+CREATE INDEX IF NOT EXISTS idx_saas_client_offboardings_purge ON saas_client_offboardings(purge_at) WHERE purge_at IS NOT NULL;
+
+COMMENT ON TABLE saas_client_offboardings IS 'Deactivation cascade per client: session stop, workflow pause, credential suspension, retention countdown';
+COMMENT ON COLUMN saas_client_offboardings.steps IS 'Per-step progress: [{name, status, error, completed_at}]';
+COMMENT ON COLUMN saas_client_offboardings.purge_at IS 'When the client data becomes eligible for deletion';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_sla_threads;
+DROP TABLE IF EXISTS saas_sla_settings;
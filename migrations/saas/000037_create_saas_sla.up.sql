@@ -0,0 +1,39 @@
+-- SLA tracking: per-client targets and support threads (customer message -> first response -> resolution)
+CREATE TABLE IF NOT EXISTS saas_sla_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    first_response_target_seconds INT NOT NULL DEFAULT 300,
+    resolution_target_seconds INT NOT NULL DEFAULT 86400,
+    idle_resolve_minutes INT NOT NULL DEFAULT 60,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS saas_sla_threads (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'open',
+    opened_at TIMESTAMP NOT NULL,
+    last_customer_message_at TIMESTAMP NOT NULL,
+    last_activity_at TIMESTAMP NOT NULL,
+    first_response_at TIMESTAMP,
+    first_response_by TEXT,
+    first_agent_response_at TIMESTAMP,
+    first_agent TEXT,
+    resolved_at TIMESTAMP,
+    resolved_by TEXT,
+    resolution TEXT,
+    first_response_breached BOOLEAN NOT NULL DEFAULT false,
+    resolution_breached BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_sla_threads_open ON saas_sla_threads(client_id, customer_phone) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS idx_saas_sla_threads_client_opened ON saas_sla_threads(client_id, opened_at);
+CREATE INDEX IF NOT EXISTS idx_saas_sla_threads_status ON saas_sla_threads(status, opened_at);
+
+COMMENT ON TABLE saas_sla_threads IS 'Support threads: opened by a customer message, resolved when closed by an agent or idle';
+COMMENT ON COLUMN saas_sla_threads.first_response_by IS 'bot, or the agent who answered first';
+COMMENT ON COLUMN saas_sla_threads.resolution IS 'closed (by an agent) or idle';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_conversation_tag_assignments;
+DROP TABLE IF EXISTS saas_conversation_tags;
@@ -0,0 +1,28 @@
+-- Conversation tags: per-client tag definitions and the customer chats they are applied to
+CREATE TABLE IF NOT EXISTS saas_conversation_tags (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    color TEXT,
+    description TEXT,
+    keywords JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS saas_conversation_tag_assignments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES saas_conversation_tags(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    source TEXT NOT NULL DEFAULT 'manual',
+    tagged_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (tag_id, customer_phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_conversation_tag_assignments_customer ON saas_conversation_tag_assignments(client_id, customer_phone);
+
+COMMENT ON COLUMN saas_conversation_tags.keywords IS 'Customer message keywords that apply the tag automatically';
+COMMENT ON COLUMN saas_conversation_tag_assignments.source IS 'manual (API), command (WhatsApp chat command), auto (keyword match)';
@@ -0,0 +1,17 @@
+-- API keys for server-to-server access to a client's data, issued by provisioning tools
+CREATE TABLE IF NOT EXISTS saas_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    external_ref TEXT NOT NULL,
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, external_ref)
+);
+
+COMMENT ON TABLE saas_api_keys IS 'Client API keys; only a SHA-256 hash of the key is stored';
+COMMENT ON COLUMN saas_api_keys.key_prefix IS 'First characters of the key, to recognise it without revealing it';
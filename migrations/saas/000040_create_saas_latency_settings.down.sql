@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_latency_settings;
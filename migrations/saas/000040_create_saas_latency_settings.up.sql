@@ -0,0 +1,21 @@
+-- Reply latency budget per client: interim message, hard timeout and the fallback when the LLM is slow
+CREATE TABLE IF NOT EXISTS saas_latency_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN DEFAULT true,
+    interim_after_seconds INT DEFAULT 8, -- 0 = no interim message
+    interim_message TEXT,
+    hard_timeout_seconds INT DEFAULT 20,
+    fallback TEXT DEFAULT 'kb', -- kb (FAQ answer, else handover) or handover
+    handover_message TEXT,
+    handover_tag TEXT, -- Conversation tag applied on handover
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_latency_settings_updated_at
+    BEFORE UPDATE ON saas_latency_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_latency_settings IS 'Reply latency budget per client (interim message, hard timeout, degraded fallback)';
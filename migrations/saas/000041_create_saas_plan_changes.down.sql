@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_usage_alerts;
+DROP TABLE IF EXISTS saas_plan_changes;
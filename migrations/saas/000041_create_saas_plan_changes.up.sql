@@ -0,0 +1,46 @@
+-- Self-service plan changes: prorated upgrades paid through the billing gateway, downgrades scheduled for the next period
+CREATE TABLE IF NOT EXISTS saas_plan_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    from_plan TEXT NOT NULL,
+    to_plan TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending_payment', -- pending_payment, scheduled, applied, cancelled, failed
+    reference TEXT NOT NULL UNIQUE, -- Order ID sent to the payment gateway (SUB-...)
+    prorated_amount NUMERIC(15,2) DEFAULT 0, -- Charged now for the rest of the current period
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    effective_at TIMESTAMP, -- When a scheduled downgrade takes effect
+    payment_link TEXT,
+    payment_method TEXT,
+    transaction_id TEXT,
+    applied_at TIMESTAMP,
+    cancel_reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_plan_changes_client ON saas_plan_changes(client_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_plan_changes_status ON saas_plan_changes(status);
+
+CREATE TRIGGER update_saas_plan_changes_updated_at
+    BEFORE UPDATE ON saas_plan_changes
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_plan_changes IS 'Self-service subscription plan changes with prorated payment';
+
+-- Upgrade prompts already sent, so each threshold is announced once per billing period
+CREATE TABLE IF NOT EXISTS saas_usage_alerts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    period_start TIMESTAMP NOT NULL,
+    metric TEXT NOT NULL, -- messages, products, knowledge_base
+    threshold INT NOT NULL, -- Percent of the plan limit
+    plan TEXT NOT NULL,
+    used INT NOT NULL,
+    usage_limit INT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, period_start, metric, threshold)
+);
+
+COMMENT ON TABLE saas_usage_alerts IS 'Usage threshold upgrade prompts sent to tenant admins';
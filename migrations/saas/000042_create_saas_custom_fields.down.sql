@@ -0,0 +1,4 @@
+ALTER TABLE saas_products DROP COLUMN IF EXISTS custom_fields;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS custom_fields;
+DROP TABLE IF EXISTS saas_customers;
+DROP TABLE IF EXISTS saas_custom_field_definitions;
@@ -0,0 +1,46 @@
+-- Tenant-defined extra fields (NPWP, table number, ...) on customers, orders and products
+CREATE TABLE IF NOT EXISTS saas_custom_field_definitions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    entity TEXT NOT NULL, -- customer, order, product
+    key TEXT NOT NULL, -- Storage and template key, e.g. table_number
+    label TEXT NOT NULL,
+    type TEXT NOT NULL, -- text, number, boolean, date, select
+    required BOOLEAN DEFAULT false,
+    options TEXT[], -- Allowed values of a select field
+    pattern TEXT, -- Regular expression a text value must match
+    min_value NUMERIC(15,2), -- Number: smallest value, text: shortest length
+    max_value NUMERIC(15,2), -- Number: largest value, text: longest length
+    position INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, entity, key)
+);
+
+CREATE TRIGGER update_saas_custom_field_definitions_updated_at
+    BEFORE UPDATE ON saas_custom_field_definitions
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_custom_field_definitions IS 'Custom field definitions per client and entity';
+
+-- Customers are identified by phone number; this holds their custom field values
+CREATE TABLE IF NOT EXISTS saas_customers (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    custom_fields JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, customer_phone)
+);
+
+CREATE TRIGGER update_saas_customers_updated_at
+    BEFORE UPDATE ON saas_customers
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_customers IS 'Customers of a client, keyed by phone number';
+
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS custom_fields JSONB DEFAULT '{}';
+ALTER TABLE saas_products ADD COLUMN IF NOT EXISTS custom_fields JSONB DEFAULT '{}';
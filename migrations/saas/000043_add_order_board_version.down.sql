@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_saas_orders_board;
+DROP TRIGGER IF EXISTS bump_saas_orders_version ON saas_orders;
+DROP FUNCTION IF EXISTS bump_order_version();
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS version;
@@ -0,0 +1,20 @@
+-- Optimistic lock for order board stage moves; every update of an order bumps it
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 0;
+
+CREATE OR REPLACE FUNCTION bump_order_version()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.version = OLD.version + 1;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER bump_saas_orders_version
+    BEFORE UPDATE ON saas_orders
+    FOR EACH ROW
+    EXECUTE FUNCTION bump_order_version();
+
+-- Board columns and the change feed read orders by stage and last update
+CREATE INDEX IF NOT EXISTS idx_saas_orders_board ON saas_orders(client_id, fulfillment_status, updated_at);
+
+COMMENT ON COLUMN saas_orders.version IS 'Incremented on every update; order board moves must send the version they saw';
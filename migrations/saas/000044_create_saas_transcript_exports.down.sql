@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_transcript_exports;
+ALTER TABLE saas_conversations DROP COLUMN IF EXISTS media_url;
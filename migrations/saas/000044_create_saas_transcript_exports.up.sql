@@ -0,0 +1,31 @@
+-- Media sent in a conversation turn (agent replies from the business phone)
+ALTER TABLE saas_conversations ADD COLUMN IF NOT EXISTS media_url TEXT;
+
+-- Chat transcript exports, rendered in the background and kept in object storage
+CREATE TABLE IF NOT EXISTS saas_transcript_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    format TEXT NOT NULL, -- pdf, html
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, completed, failed
+    range_start TIMESTAMP, -- Optional transcript window
+    range_end TIMESTAMP,
+    job_id UUID,
+    file_url TEXT,
+    message_count INT DEFAULT 0,
+    email_to TEXT[], -- Recipients of the download link, empty when not emailed
+    emailed_at TIMESTAMP,
+    error TEXT,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_transcript_exports_client ON saas_transcript_exports(client_id, customer_phone, created_at DESC);
+
+CREATE TRIGGER update_saas_transcript_exports_updated_at
+    BEFORE UPDATE ON saas_transcript_exports
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_transcript_exports IS 'Per-conversation transcript exports (PDF/HTML) for dispute evidence';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_saas_orders_pending_payment;
+DROP TABLE IF EXISTS saas_payment_reminders;
+DROP TABLE IF EXISTS saas_payment_reminder_settings;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS payment_expires_at;
@@ -0,0 +1,37 @@
+-- When the order's payment link expires (as reported by the gateway)
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS payment_expires_at TIMESTAMP;
+
+-- Per-client payment reminder ladder for pending orders
+CREATE TABLE IF NOT EXISTS saas_payment_reminder_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    steps JSONB NOT NULL DEFAULT '[]', -- [{minutes_before, template}]
+    default_expiry_minutes INT NOT NULL DEFAULT 1440, -- Payment window of orders whose gateway reports no expiry
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_payment_reminder_settings_updated_at
+    BEFORE UPDATE ON saas_payment_reminder_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Reminders sent for an order, one per ladder step
+CREATE TABLE IF NOT EXISTS saas_payment_reminders (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES saas_orders(id) ON DELETE CASCADE,
+    minutes_before INT NOT NULL, -- Ladder step, in minutes before the payment expires
+    status TEXT NOT NULL, -- sent, failed
+    error TEXT,
+    sent_at TIMESTAMP NOT NULL,
+    converted_at TIMESTAMP, -- Order paid, with this as the last reminder before payment
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_payment_reminders_step ON saas_payment_reminders(order_id, minutes_before);
+CREATE INDEX IF NOT EXISTS idx_saas_payment_reminders_client_sent ON saas_payment_reminders(client_id, sent_at);
+CREATE INDEX IF NOT EXISTS idx_saas_orders_pending_payment ON saas_orders(client_id, created_at) WHERE payment_status = 'pending';
+
+COMMENT ON TABLE saas_payment_reminders IS 'Payment reminders sent for pending orders and whether the order was paid after them';
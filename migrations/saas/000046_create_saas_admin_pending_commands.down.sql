@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_admin_pending_commands;
@@ -0,0 +1,16 @@
+-- Admin WhatsApp commands waiting for the admin to reply YA (one per admin number)
+CREATE TABLE IF NOT EXISTS saas_admin_pending_commands (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    admin_phone TEXT NOT NULL,
+    role TEXT NOT NULL,
+    command TEXT NOT NULL, -- cancel_order, confirm_payment, update_stock
+    message TEXT NOT NULL, -- Command as typed by the admin
+    params JSONB NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_admin_pending_commands_admin ON saas_admin_pending_commands(client_id, admin_phone);
+
+COMMENT ON TABLE saas_admin_pending_commands IS 'Admin WhatsApp commands awaiting confirmation';
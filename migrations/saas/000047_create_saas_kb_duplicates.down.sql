@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_kb_duplicates;
@@ -0,0 +1,20 @@
+-- Near-duplicate knowledge base entries detected when entries are written
+CREATE TABLE IF NOT EXISTS saas_kb_duplicates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    entry_id UUID NOT NULL, -- The entry written last
+    entry_title TEXT NOT NULL,
+    duplicate_of_id UUID NOT NULL, -- The entry it duplicates
+    duplicate_of_title TEXT NOT NULL,
+    score DOUBLE PRECISION NOT NULL, -- Similarity, 0-1
+    method TEXT NOT NULL, -- exact, fuzzy, embedding
+    status TEXT NOT NULL DEFAULT 'open', -- open, merged, dismissed
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_kb_duplicates_pair ON saas_kb_duplicates(entry_id, duplicate_of_id);
+CREATE INDEX IF NOT EXISTS idx_saas_kb_duplicates_client_status ON saas_kb_duplicates(client_id, status, created_at DESC);
+
+COMMENT ON TABLE saas_kb_duplicates IS 'Knowledge base entries flagged as near-duplicates of another entry';
@@ -0,0 +1,23 @@
+-- LLM provider benchmark results, one row per provider/model per run
+CREATE TABLE IF NOT EXISTS saas_llm_benchmarks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    run_id UUID NOT NULL, -- Rows benchmarked together share a run
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    success_rate DOUBLE PRECISION NOT NULL, -- 0-1
+    avg_latency_ms BIGINT NOT NULL,
+    p95_latency_ms BIGINT NOT NULL,
+    quality DOUBLE PRECISION NOT NULL, -- 0-1
+    estimated_cost_usd DOUBLE PRECISION NOT NULL,
+    cost_per_1k_usd DOUBLE PRECISION NOT NULL,
+    price_known BOOLEAN NOT NULL DEFAULT FALSE,
+    health_score DOUBLE PRECISION NOT NULL, -- 0-100, relative to the run
+    recommended BOOLEAN NOT NULL DEFAULT FALSE,
+    cases JSONB NOT NULL DEFAULT '[]', -- Latency, quality and error of each prompt
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_llm_benchmarks_run ON saas_llm_benchmarks(run_id);
+CREATE INDEX IF NOT EXISTS idx_saas_llm_benchmarks_model ON saas_llm_benchmarks(provider, model, created_at DESC);
+
+COMMENT ON TABLE saas_llm_benchmarks IS 'Latency, cost and quality of LLM providers/models on the standard prompt suite';
@@ -0,0 +1,23 @@
+-- Every payment gateway webhook as received, with its signature check and processing result
+CREATE TABLE IF NOT EXISTS saas_payment_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    gateway TEXT NOT NULL, -- midtrans
+    order_id TEXT, -- Gateway order reference (order number or SUB-... plan change reference)
+    transaction_id TEXT,
+    transaction_status TEXT, -- capture, settlement, pending, deny, cancel, expire, ...
+    payment_type TEXT,
+    payload TEXT NOT NULL, -- Raw request body, replayed as-is
+    signature_status TEXT NOT NULL, -- valid, invalid, missing, unchecked
+    result TEXT NOT NULL, -- processed, failed, ignored, rejected
+    error TEXT,
+    replay_count INT NOT NULL DEFAULT 0,
+    last_replayed_at TIMESTAMP,
+    processed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_payment_events_created ON saas_payment_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_payment_events_order ON saas_payment_events(order_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_payment_events_result ON saas_payment_events(result, created_at DESC);
+
+COMMENT ON TABLE saas_payment_events IS 'Payment gateway webhooks with signature status and processing result, replayable by platform admins';
@@ -0,0 +1,21 @@
+-- Moves of a tenant's data between regions, started by platform admins
+CREATE TABLE IF NOT EXISTS saas_region_migrations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    from_region TEXT NOT NULL, -- Empty = default region
+    to_region TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    tables JSONB NOT NULL DEFAULT '[]', -- Rows in the source and copied to the target, per table
+    vector_synced BOOLEAN NOT NULL DEFAULT FALSE,
+    error TEXT,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_region_migrations_client ON saas_region_migrations(client_id, created_at DESC);
+
+-- One migration in flight per tenant
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_region_migrations_active ON saas_region_migrations(client_id) WHERE status IN ('pending', 'running');
+
+COMMENT ON TABLE saas_region_migrations IS 'Tenant moves between regions with per-table copy counts';
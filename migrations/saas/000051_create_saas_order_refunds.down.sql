@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS saas_order_refunds;
+
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS refunded_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS refunded_amount;
@@ -0,0 +1,21 @@
+-- Refunds of paid orders, full or partial, through the order's payment gateway
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS refunded_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS saas_order_refunds (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES saas_orders(id) ON DELETE CASCADE,
+    amount DECIMAL(12,2) NOT NULL,
+    reason TEXT,
+    gateway TEXT NOT NULL, -- Gateway name recorded on the order
+    reference TEXT, -- Refund key or ID at the gateway
+    status TEXT NOT NULL, -- refunded, pending (admin returns the money manually)
+    requested_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_order_refunds_order ON saas_order_refunds(order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saas_order_refunds_client ON saas_order_refunds(client_id, created_at DESC);
+
+COMMENT ON TABLE saas_order_refunds IS 'Full and partial refunds of paid orders';
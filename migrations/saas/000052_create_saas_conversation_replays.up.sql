@@ -0,0 +1,30 @@
+-- QA replays of historical conversations against the current prompt, knowledge base and LLM (nothing is sent)
+CREATE TABLE IF NOT EXISTS saas_conversation_replays (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, running, completed, failed
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    customer_phone TEXT, -- Empty = every customer of the client
+    from_at TIMESTAMP NOT NULL,
+    to_at TIMESTAMP NOT NULL,
+    total_turns INT NOT NULL DEFAULT 0,
+    replayed_turns INT NOT NULL DEFAULT 0,
+    changed_turns INT NOT NULL DEFAULT 0,
+    failed_turns INT NOT NULL DEFAULT 0,
+    avg_similarity DOUBLE PRECISION NOT NULL DEFAULT 0, -- 0-1, new vs original answers
+    avg_latency_ms BIGINT NOT NULL DEFAULT 0,
+    p95_latency_ms BIGINT NOT NULL DEFAULT 0,
+    estimated_cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    price_known BOOLEAN NOT NULL DEFAULT FALSE,
+    turns JSONB NOT NULL DEFAULT '[]', -- Original and new answer of each replayed message
+    requested_by TEXT,
+    error TEXT,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_conversation_replays_client ON saas_conversation_replays(client_id, created_at DESC);
+
+COMMENT ON TABLE saas_conversation_replays IS 'Historical conversations replayed in a sandbox with the new vs original answers, latency and cost';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_campaign_recipients;
+DROP TABLE IF EXISTS saas_campaigns;
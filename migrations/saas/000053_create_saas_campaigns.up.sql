@@ -0,0 +1,48 @@
+-- Broadcast campaigns: one message sent to an audience of a tenant's customers, now or at a scheduled time
+CREATE TABLE IF NOT EXISTS saas_campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    message TEXT NOT NULL,
+    audience TEXT NOT NULL DEFAULT 'all', -- all, recent_buyers, tags
+    audience_tags JSONB NOT NULL DEFAULT '[]', -- Tag names, for the tags audience
+    match_all_tags BOOLEAN NOT NULL DEFAULT FALSE, -- Customers need every tag instead of any
+    recent_days INT NOT NULL DEFAULT 30, -- Order window of the recent_buyers audience
+    throttle_seconds INT NOT NULL DEFAULT 3, -- Pause between two recipients
+    status TEXT NOT NULL DEFAULT 'draft', -- draft, scheduled, sending, completed, cancelled, failed
+    scheduled_at TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    total_recipients INT NOT NULL DEFAULT 0,
+    sent_count INT NOT NULL DEFAULT 0,
+    failed_count INT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_campaigns_client ON saas_campaigns(client_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_campaigns_due ON saas_campaigns(scheduled_at) WHERE status IN ('scheduled', 'sending');
+
+-- Customers a campaign is sent to, with the delivery status reported by WhatsApp
+CREATE TABLE IF NOT EXISTS saas_campaign_recipients (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    campaign_id UUID NOT NULL REFERENCES saas_campaigns(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, sent, delivered, read, failed, skipped
+    message_id TEXT, -- Provider message ID, matched against delivery acks
+    error TEXT,
+    sent_at TIMESTAMP,
+    delivered_at TIMESTAMP,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (campaign_id, customer_phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_campaign_recipients_status ON saas_campaign_recipients(campaign_id, status);
+CREATE INDEX IF NOT EXISTS idx_saas_campaign_recipients_message ON saas_campaign_recipients(message_id) WHERE message_id IS NOT NULL;
+
+COMMENT ON TABLE saas_campaigns IS 'Scheduled WhatsApp broadcasts to filtered customer audiences';
+COMMENT ON TABLE saas_campaign_recipients IS 'Per-customer send and delivery status of a campaign';
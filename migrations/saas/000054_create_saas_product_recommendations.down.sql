@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_product_recommendations;
+DROP TABLE IF EXISTS saas_recommendation_settings;
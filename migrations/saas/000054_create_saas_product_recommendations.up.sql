@@ -0,0 +1,41 @@
+-- Per-client settings of the chat product recommendations
+CREATE TABLE IF NOT EXISTS saas_recommendation_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    after_add_to_cart BOOLEAN NOT NULL DEFAULT TRUE, -- Suggest once a product is added to the cart
+    after_checkout BOOLEAN NOT NULL DEFAULT TRUE, -- Suggest once an order is placed
+    max_suggestions INT NOT NULL DEFAULT 2,
+    use_llm BOOLEAN NOT NULL DEFAULT TRUE, -- Let the LLM rank the co-purchase candidates against the conversation
+    cooldown_minutes INT NOT NULL DEFAULT 30, -- Quiet time between two suggestions to the same customer
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TRIGGER update_saas_recommendation_settings_updated_at
+    BEFORE UPDATE ON saas_recommendation_settings
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+-- Products suggested to customers in chat, one row per suggested product, with what the customer did with it
+CREATE TABLE IF NOT EXISTS saas_product_recommendations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    trigger_point TEXT NOT NULL, -- after_add_to_cart, after_checkout
+    source_products JSONB NOT NULL DEFAULT '[]', -- Cart or order the suggestion was made for
+    product_name TEXT NOT NULL,
+    rank INT NOT NULL DEFAULT 1,
+    ranked_by TEXT NOT NULL, -- co_purchase, llm, history
+    co_purchase_count INT NOT NULL DEFAULT 0, -- Orders that had the product together with the source products
+    added_to_cart_at TIMESTAMP,
+    converted_at TIMESTAMP,
+    order_id UUID,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_product_recommendations_customer ON saas_product_recommendations(client_id, customer_phone, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_product_recommendations_created ON saas_product_recommendations(client_id, created_at);
+
+COMMENT ON TABLE saas_recommendation_settings IS 'Chat product recommendation settings per client';
+COMMENT ON TABLE saas_product_recommendations IS 'Products suggested to customers in chat, with add-to-cart and order conversions';
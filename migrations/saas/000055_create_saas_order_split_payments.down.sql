@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_saas_orders_split_due;
+DROP TABLE IF EXISTS saas_order_split_payments;
+
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS split_expires_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS split_payment;
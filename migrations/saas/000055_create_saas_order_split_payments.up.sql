@@ -0,0 +1,29 @@
+-- Group orders: one order paid in portions by several payers, confirmed once every portion is paid
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS split_payment BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS split_expires_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS saas_order_split_payments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    order_id UUID NOT NULL REFERENCES saas_orders(id) ON DELETE CASCADE,
+    reference TEXT NOT NULL UNIQUE, -- Gateway order ID of the portion (SPL-...)
+    payer_phone TEXT NOT NULL,
+    payer_name TEXT,
+    amount DECIMAL(12,2) NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending', -- pending, paid, cancelled, expired, refunded
+    payment_gateway TEXT NOT NULL,
+    payment_link TEXT,
+    payment_method TEXT,
+    transaction_id TEXT,
+    paid_at TIMESTAMP,
+    refund_reference TEXT, -- Set when a paid portion is returned because the group order failed
+    refunded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (order_id, payer_phone)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_order_split_payments_order ON saas_order_split_payments(order_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saas_orders_split_due ON saas_orders(split_expires_at) WHERE split_payment = TRUE AND payment_status = 'pending';
+
+COMMENT ON TABLE saas_order_split_payments IS 'Per-payer portions of split group orders';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS saas_wallet_transactions;
+DROP TABLE IF EXISTS saas_customer_wallets;
+DROP TABLE IF EXISTS saas_wallet_settings;
@@ -0,0 +1,49 @@
+-- Prepaid customer wallets: topped up through the payment gateway, spent at checkout
+CREATE TABLE IF NOT EXISTS saas_wallet_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    auto_deduct BOOLEAN NOT NULL DEFAULT TRUE, -- Pay orders from the balance at checkout when it covers the total
+    min_top_up DECIMAL(12,2) NOT NULL DEFAULT 10000,
+    max_top_up DECIMAL(12,2) NOT NULL DEFAULT 0, -- 0 = no limit
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS saas_customer_wallets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    customer_name TEXT,
+    balance DECIMAL(12,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, customer_phone)
+);
+
+CREATE TABLE IF NOT EXISTS saas_wallet_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    wallet_id UUID NOT NULL REFERENCES saas_customer_wallets(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    type TEXT NOT NULL, -- top_up, payment, refund, adjustment
+    status TEXT NOT NULL DEFAULT 'completed', -- pending (top-up waiting for payment), completed, cancelled
+    amount DECIMAL(12,2) NOT NULL, -- Signed: credits positive, debits negative
+    balance_after DECIMAL(12,2), -- Set once the transaction is applied
+    order_id UUID REFERENCES saas_orders(id) ON DELETE SET NULL,
+    reference TEXT UNIQUE, -- Gateway order ID of a top-up (TOP-...)
+    payment_gateway TEXT,
+    payment_link TEXT,
+    transaction_id TEXT,
+    note TEXT,
+    actor TEXT, -- Who made an adjustment
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_wallet_transactions_wallet ON saas_wallet_transactions(wallet_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_wallet_transactions_order ON saas_wallet_transactions(order_id) WHERE order_id IS NOT NULL;
+
+COMMENT ON TABLE saas_wallet_settings IS 'Per-tenant prepaid wallet settings';
+COMMENT ON TABLE saas_customer_wallets IS 'Prepaid credit balance of each customer';
+COMMENT ON TABLE saas_wallet_transactions IS 'Ledger of wallet top-ups, payments, refunds and adjustments';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_billing_statements;
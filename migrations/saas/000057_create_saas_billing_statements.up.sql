@@ -0,0 +1,35 @@
+-- Monthly billing statements: the plan fee plus usage overages, invoiced to each tenant
+CREATE TABLE IF NOT EXISTS saas_billing_statements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    number TEXT NOT NULL UNIQUE, -- Statement number, also the gateway order ID of its payment (INV-...)
+    plan TEXT NOT NULL, -- Plan at the end of the period
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    plan_fee NUMERIC(15,2) NOT NULL DEFAULT 0,
+    overage_amount NUMERIC(15,2) NOT NULL DEFAULT 0,
+    total NUMERIC(15,2) NOT NULL DEFAULT 0,
+    lines JSONB NOT NULL DEFAULT '[]', -- Usage per metric: used, included, overage, unit price, amount
+    status TEXT NOT NULL DEFAULT 'unpaid', -- unpaid, paid, void
+    pdf_url TEXT,
+    payment_link TEXT,
+    payment_method TEXT,
+    transaction_id TEXT,
+    email_to TEXT[],
+    emailed_at TIMESTAMP,
+    paid_at TIMESTAMP,
+    void_reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (client_id, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_billing_statements_client ON saas_billing_statements(client_id, period_start DESC);
+CREATE INDEX IF NOT EXISTS idx_saas_billing_statements_status ON saas_billing_statements(status);
+
+CREATE TRIGGER update_saas_billing_statements_updated_at
+    BEFORE UPDATE ON saas_billing_statements
+    FOR EACH ROW
+    EXECUTE FUNCTION update_updated_at_column();
+
+COMMENT ON TABLE saas_billing_statements IS 'Monthly tenant billing statements with plan fee, usage overages and payment status';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_message_feature_settings;
@@ -0,0 +1,18 @@
+-- Per-client toggles for handling each inbound message type, with the reply sent when a type is turned off
+CREATE TABLE IF NOT EXISTS saas_message_feature_settings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL UNIQUE REFERENCES clients(id) ON DELETE CASCADE,
+    text_enabled BOOLEAN NOT NULL DEFAULT TRUE, -- AI replies to customer text messages
+    image_enabled BOOLEAN NOT NULL DEFAULT TRUE, -- Receipt OCR of images
+    voice_enabled BOOLEAN NOT NULL DEFAULT TRUE, -- Voice note transcription
+    location_enabled BOOLEAN NOT NULL DEFAULT TRUE, -- Nearest store for shared locations
+    group_enabled BOOLEAN NOT NULL DEFAULT TRUE, -- Messages from WhatsApp groups
+    text_disabled_reply TEXT NOT NULL DEFAULT '', -- Empty = ignore silently
+    image_disabled_reply TEXT NOT NULL DEFAULT '',
+    voice_disabled_reply TEXT NOT NULL DEFAULT '',
+    location_disabled_reply TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE saas_message_feature_settings IS 'Per-client toggles for text, image, voice, location and group message handling';
@@ -0,0 +1,18 @@
+-- Documents (PDF, DOCX, TXT) uploaded to a client's knowledge base; their text is chunked and stored in the vector DB
+CREATE TABLE IF NOT EXISTS saas_kb_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL, -- Uploaded file name
+    format VARCHAR(10) NOT NULL, -- pdf, docx, txt
+    size_bytes BIGINT NOT NULL DEFAULT 0,
+    characters INT NOT NULL DEFAULT 0, -- Extracted text length
+    chunk_count INT NOT NULL DEFAULT 0,
+    status VARCHAR(20) NOT NULL DEFAULT 'processing', -- processing, ready, failed
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_kb_documents_client ON saas_kb_documents(client_id, created_at DESC);
+
+COMMENT ON TABLE saas_kb_documents IS 'Knowledge base documents ingested into the vector DB as overlapping text chunks';
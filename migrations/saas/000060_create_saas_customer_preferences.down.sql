@@ -0,0 +1,2 @@
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS delivery_notes;
+DROP TABLE IF EXISTS saas_customer_preferences;
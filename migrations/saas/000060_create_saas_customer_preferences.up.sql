@@ -0,0 +1,18 @@
+-- Preferences a customer stated in chat or an agent set: reply language, payment method and delivery notes
+CREATE TABLE IF NOT EXISTS saas_customer_preferences (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    customer_phone TEXT NOT NULL,
+    language VARCHAR(5) NOT NULL DEFAULT '', -- id, en, jv, su; empty = not stated
+    payment_method VARCHAR(20) NOT NULL DEFAULT '', -- cod, qris, bank_transfer, ewallet, credit_card
+    delivery_notes TEXT NOT NULL DEFAULT '', -- e.g. "titip di satpam"
+    updated_by VARCHAR(20) NOT NULL DEFAULT 'customer', -- customer (from chat) or agent (via the API)
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, customer_phone)
+);
+
+COMMENT ON TABLE saas_customer_preferences IS 'Per-customer preferences read into the bot prompt and applied at checkout';
+
+-- Delivery notes copied from the customer's preferences at checkout, passed on to the driver
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS delivery_notes TEXT;
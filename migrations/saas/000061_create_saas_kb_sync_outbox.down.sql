@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS trigger_track_saas_kb_changes ON saas_knowledge_base;
+DROP FUNCTION IF EXISTS track_saas_kb_changes();
+DROP FUNCTION IF EXISTS enqueue_saas_kb_sync(UUID, UUID, TEXT, TEXT);
+DROP TABLE IF EXISTS saas_kb_sync_outbox;
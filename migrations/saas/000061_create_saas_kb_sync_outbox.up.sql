@@ -0,0 +1,60 @@
+-- Knowledge base changes waiting to be applied to the vector DB, written by a trigger on every insert, update and delete
+-- so no write path can bypass the sync. One row per entry and type: repeated changes coalesce into the latest one.
+CREATE TABLE IF NOT EXISTS saas_kb_sync_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    client_id UUID NOT NULL,
+    entry_id UUID NOT NULL,
+    entry_type TEXT NOT NULL, -- Type the change applies to; the vector ID includes it, so a retyped entry also queues a delete of the old type
+    operation VARCHAR(10) NOT NULL, -- upsert, delete
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    version BIGINT NOT NULL DEFAULT 1, -- Bumped on every change; the worker only removes a row that did not change while it was synced
+    enqueued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (entry_id, entry_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_kb_sync_outbox_due ON saas_kb_sync_outbox(next_attempt_at, enqueued_at);
+CREATE INDEX IF NOT EXISTS idx_saas_kb_sync_outbox_client ON saas_kb_sync_outbox(client_id);
+
+COMMENT ON TABLE saas_kb_sync_outbox IS 'Pending knowledge base changes for the vector DB sync worker';
+
+CREATE OR REPLACE FUNCTION enqueue_saas_kb_sync(p_client_id UUID, p_entry_id UUID, p_entry_type TEXT, p_operation TEXT)
+RETURNS VOID AS $$
+BEGIN
+    INSERT INTO saas_kb_sync_outbox (client_id, entry_id, entry_type, operation)
+    VALUES (p_client_id, p_entry_id, p_entry_type, p_operation)
+    ON CONFLICT (entry_id, entry_type) DO UPDATE SET
+        operation = EXCLUDED.operation,
+        attempts = 0,
+        last_error = '',
+        version = saas_kb_sync_outbox.version + 1,
+        enqueued_at = NOW(),
+        next_attempt_at = NOW();
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION track_saas_kb_changes()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM enqueue_saas_kb_sync(OLD.client_id, OLD.id, OLD.type, 'delete');
+        RETURN OLD;
+    END IF;
+    IF TG_OP = 'UPDATE' AND OLD.type IS DISTINCT FROM NEW.type THEN
+        PERFORM enqueue_saas_kb_sync(OLD.client_id, OLD.id, OLD.type, 'delete');
+    END IF;
+    PERFORM enqueue_saas_kb_sync(NEW.client_id, NEW.id, NEW.type, 'upsert');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_track_saas_kb_changes
+    AFTER INSERT OR UPDATE OR DELETE ON saas_knowledge_base
+    FOR EACH ROW
+    EXECUTE FUNCTION track_saas_kb_changes();
+
+-- Index every existing entry once under its stable ID
+INSERT INTO saas_kb_sync_outbox (client_id, entry_id, entry_type, operation)
+SELECT client_id, id, type, 'upsert' FROM saas_knowledge_base
+ON CONFLICT (entry_id, entry_type) DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS saas_whatsapp_session_backups;
@@ -0,0 +1,17 @@
+-- Snapshots of the WAHA sessions (names and configuration) taken periodically, used to rehydrate the sessions
+-- after the WAHA container is replaced instead of every tenant pairing again from scratch
+CREATE TABLE IF NOT EXISTS saas_whatsapp_session_backups (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    provider VARCHAR(20) NOT NULL,
+    session_count INT NOT NULL DEFAULT 0,
+    working_count INT NOT NULL DEFAULT 0, -- Sessions logged in when the snapshot was taken
+    snapshot JSONB NOT NULL, -- whatsapp.SessionBackup
+    file_url TEXT NOT NULL DEFAULT '', -- Copy in object storage, webhook HMAC keys redacted
+    file_public_id TEXT NOT NULL DEFAULT '',
+    storage_error TEXT NOT NULL DEFAULT '', -- Why the object storage copy failed
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_whatsapp_session_backups_created ON saas_whatsapp_session_backups(created_at DESC);
+
+COMMENT ON TABLE saas_whatsapp_session_backups IS 'WAHA session snapshots for restoring sessions after infrastructure replacement';
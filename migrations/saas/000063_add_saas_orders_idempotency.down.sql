@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_saas_orders_idempotency_key;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS idempotency_result;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS idempotency_fingerprint;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS idempotency_key;
@@ -0,0 +1,10 @@
+-- Idempotency-Key of the API request that created the order: a retried request returns this order
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS idempotency_key TEXT;
+-- Hash of the request, to refuse a key sent again with a different order
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS idempotency_fingerprint TEXT;
+-- Payment result returned with the order, returned again to retries
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS idempotency_result JSONB;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_saas_orders_idempotency_key
+    ON saas_orders (client_id, idempotency_key)
+    WHERE idempotency_key IS NOT NULL;
@@ -0,0 +1,5 @@
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS delivered_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS shipped_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS packed_at;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS shipping_tracking_number;
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS shipping_courier;
@@ -0,0 +1,10 @@
+-- Courier delivery of an order (PUT /orders/:id/fulfillment): courier name and waybill (resi) number
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS shipping_courier TEXT;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS shipping_tracking_number TEXT;
+
+-- When the order reached each fulfillment stage
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS packed_at TIMESTAMP;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS shipped_at TIMESTAMP;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP;
+
+COMMENT ON COLUMN saas_orders.fulfillment_status IS 'pending, processing, packed, shipped, delivered or cancelled';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_saas_transactions_client_date;
+DROP INDEX IF EXISTS idx_saas_conversations_client_created;
+DROP INDEX IF EXISTS idx_saas_orders_client_created;
@@ -0,0 +1,4 @@
+-- Reports (/reports/*) aggregate one client's rows over a date range
+CREATE INDEX IF NOT EXISTS idx_saas_orders_client_created ON saas_orders(client_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saas_conversations_client_created ON saas_conversations(client_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_saas_transactions_client_date ON saas_transactions(client_id, transaction_date);
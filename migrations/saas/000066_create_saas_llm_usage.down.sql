@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_ai_credits;
+DROP TABLE IF EXISTS saas_llm_usage;
@@ -0,0 +1,25 @@
+-- Estimated tokens of every LLM call made for a client (GET /usage, monthly token quota, billing statements)
+CREATE TABLE IF NOT EXISTS saas_llm_usage (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    feature TEXT NOT NULL, -- chat, translation, receipt, recommendation, product_mention, kb_suggestion, workflow
+    provider TEXT NOT NULL,
+    operation TEXT NOT NULL,
+    prompt_tokens INT NOT NULL DEFAULT 0,
+    completion_tokens INT NOT NULL DEFAULT 0,
+    total_tokens INT NOT NULL DEFAULT 0,
+    credit_tokens INT NOT NULL DEFAULT 0, -- Part of total_tokens drawn from AI credits (over the plan quota)
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_llm_usage_client_created ON saas_llm_usage(client_id, created_at);
+
+-- Prepaid AI token credits, drawn once a plan's monthly token quota is used up
+CREATE TABLE IF NOT EXISTS saas_ai_credits (
+    client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    balance BIGINT NOT NULL DEFAULT 0 CHECK (balance >= 0), -- Tokens
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE saas_llm_usage IS 'Metered LLM token usage per call';
+COMMENT ON TABLE saas_ai_credits IS 'AI token credit balance of each client';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS saas_tenant_subscriptions;
+DROP TABLE IF EXISTS saas_plans;
@@ -0,0 +1,32 @@
+-- Plans defined by the operator, added to the built-in catalog or replacing the plan with the same code;
+-- inactive rows withdraw the plan from the catalog
+CREATE TABLE IF NOT EXISTS saas_plans (
+    code TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    monthly_price NUMERIC(15,2) NOT NULL DEFAULT 0, -- IDR
+    limits JSONB NOT NULL DEFAULT '{}', -- messages_per_month, products, knowledge_base, kb_documents, tokens_per_month, storage_mb (0 = unlimited)
+    overage JSONB NOT NULL DEFAULT '{}', -- per_message, per_thousand_tokens, per_mb
+    features TEXT[],
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Payment standing of each client's plan: overdue statements make it past due, and the client is
+-- downgraded to the free plan when the grace period ends
+CREATE TABLE IF NOT EXISTS saas_tenant_subscriptions (
+    client_id UUID PRIMARY KEY REFERENCES clients(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'active', -- active, past_due, downgraded
+    overdue_statement_id UUID REFERENCES saas_billing_statements(id) ON DELETE SET NULL,
+    past_due_since TIMESTAMP,
+    grace_ends_at TIMESTAMP,
+    downgraded_from TEXT,
+    downgraded_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_saas_tenant_subscriptions_status ON saas_tenant_subscriptions(status);
+
+COMMENT ON TABLE saas_plans IS 'Operator-defined subscription plans';
+COMMENT ON TABLE saas_tenant_subscriptions IS 'Subscription payment standing of each client';
@@ -0,0 +1,2 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS llm_model;
+ALTER TABLE clients DROP COLUMN IF EXISTS llm_provider;
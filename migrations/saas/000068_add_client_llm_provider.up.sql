@@ -0,0 +1,6 @@
+-- Per-client LLM provider and model, so tenants can run on different models
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS llm_provider TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS llm_model TEXT NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN clients.llm_provider IS 'LLM provider of the client (openai, gemini, groq, deepseek, claude); empty = platform default';
+COMMENT ON COLUMN clients.llm_model IS 'Model of llm_provider; empty = the provider default';
@@ -0,0 +1,3 @@
+ALTER TABLE clients DROP COLUMN IF EXISTS ai_max_tokens;
+ALTER TABLE clients DROP COLUMN IF EXISTS ai_temperature;
+ALTER TABLE clients DROP COLUMN IF EXISTS ai_prompt_template;
@@ -0,0 +1,8 @@
+-- Per-client AI reply settings: the bot's persona and instructions, temperature and max tokens
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS ai_prompt_template TEXT NOT NULL DEFAULT '';
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS ai_temperature REAL;
+ALTER TABLE clients ADD COLUMN IF NOT EXISTS ai_max_tokens INTEGER;
+
+COMMENT ON COLUMN clients.ai_prompt_template IS 'Persona and instructions replacing the default ones of the system prompt; {{business_name}} and {{tone}} are filled in. Empty = default';
+COMMENT ON COLUMN clients.ai_temperature IS 'LLM temperature of the client; NULL = platform default';
+COMMENT ON COLUMN clients.ai_max_tokens IS 'Max tokens of an LLM response of the client; NULL = platform default';
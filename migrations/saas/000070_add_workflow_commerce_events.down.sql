@@ -0,0 +1,2 @@
+ALTER TABLE saas_orders DROP COLUMN IF EXISTS payment_pending_event_at;
+ALTER TABLE saas_carts DROP COLUMN IF EXISTS abandoned_event_at;
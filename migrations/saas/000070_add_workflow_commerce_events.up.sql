@@ -0,0 +1,6 @@
+-- When the cart_abandoned and order_payment_pending workflow events were last raised, so each is raised once
+ALTER TABLE saas_carts ADD COLUMN IF NOT EXISTS abandoned_event_at TIMESTAMPTZ;
+ALTER TABLE saas_orders ADD COLUMN IF NOT EXISTS payment_pending_event_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN saas_carts.abandoned_event_at IS 'When the cart_abandoned workflow event was last raised; raised again once the cart changes';
+COMMENT ON COLUMN saas_orders.payment_pending_event_at IS 'When the order_payment_pending workflow event was raised';
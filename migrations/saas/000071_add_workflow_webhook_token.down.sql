@@ -0,0 +1 @@
+ALTER TABLE saas_workflows DROP COLUMN IF EXISTS webhook_token;
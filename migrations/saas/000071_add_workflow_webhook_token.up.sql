@@ -0,0 +1,4 @@
+-- Secret token of the inbound webhook (POST /workflows/:id/webhook) of webhook triggered workflows
+ALTER TABLE saas_workflows ADD COLUMN IF NOT EXISTS webhook_token TEXT;
+
+COMMENT ON COLUMN saas_workflows.webhook_token IS 'Secret the caller of the workflow''s inbound webhook sends as X-Webhook-Token or ?token=; set for webhook triggered workflows';
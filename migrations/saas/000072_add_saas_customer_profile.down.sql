@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_saas_customers_last_interaction;
+ALTER TABLE saas_customers DROP COLUMN IF EXISTS last_interaction_at;
+ALTER TABLE saas_customers DROP COLUMN IF EXISTS message_count;
+ALTER TABLE saas_customers DROP COLUMN IF EXISTS notes;
+ALTER TABLE saas_customers DROP COLUMN IF EXISTS name;
@@ -0,0 +1,38 @@
+-- Customer profile (CRM): the name and notes staff keep on a customer, and when they last wrote in.
+-- Customers are created from their first inbound message; order count and lifetime value are
+-- aggregated from their paid orders when read, and tags are the tags on their chat.
+ALTER TABLE saas_customers ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+ALTER TABLE saas_customers ADD COLUMN IF NOT EXISTS notes TEXT NOT NULL DEFAULT '';
+ALTER TABLE saas_customers ADD COLUMN IF NOT EXISTS message_count INT NOT NULL DEFAULT 0;
+ALTER TABLE saas_customers ADD COLUMN IF NOT EXISTS last_interaction_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_saas_customers_last_interaction ON saas_customers(client_id, last_interaction_at DESC NULLS LAST);
+
+-- Customers who wrote in or ordered before the profile existed
+INSERT INTO saas_customers (client_id, customer_phone, message_count, last_interaction_at, created_at)
+SELECT client_id, customer_phone, COUNT(*), MAX(created_at), MIN(created_at)
+FROM saas_conversations
+WHERE message_type = 'incoming'
+GROUP BY client_id, customer_phone
+ON CONFLICT (client_id, customer_phone) DO UPDATE
+SET message_count = EXCLUDED.message_count, last_interaction_at = EXCLUDED.last_interaction_at;
+
+INSERT INTO saas_customers (client_id, customer_phone, created_at)
+SELECT client_id, customer_phone, MIN(created_at)
+FROM saas_orders
+WHERE is_test = FALSE
+GROUP BY client_id, customer_phone
+ON CONFLICT (client_id, customer_phone) DO NOTHING;
+
+UPDATE saas_customers c
+SET name = o.customer_name
+FROM (
+    SELECT DISTINCT ON (client_id, customer_phone) client_id, customer_phone, customer_name
+    FROM saas_orders
+    WHERE customer_name <> ''
+    ORDER BY client_id, customer_phone, created_at DESC
+) o
+WHERE o.client_id = c.client_id AND o.customer_phone = c.customer_phone AND c.name = '';
+
+COMMENT ON COLUMN saas_customers.name IS 'Set by staff, or taken from the customer''s latest order';
+COMMENT ON COLUMN saas_customers.message_count IS 'Inbound messages received from the customer';
@@ -0,0 +1,2 @@
+ALTER TABLE saas_campaigns DROP COLUMN IF EXISTS segment_id;
+DROP TABLE IF EXISTS saas_customer_segments;
@@ -0,0 +1,18 @@
+-- Customer segments: saved rules over customer records (chat tags, paid orders, lifetime value, activity),
+-- usable as a campaign audience and in workflow conditions
+CREATE TABLE IF NOT EXISTS saas_customer_segments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    client_id UUID NOT NULL REFERENCES clients(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    rules JSONB NOT NULL DEFAULT '{}', -- models.SegmentRules, every rule set must match
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (client_id, name)
+);
+
+-- Campaigns sent to a segment; a deleted segment leaves the campaign without an audience, so it fails
+ALTER TABLE saas_campaigns ADD COLUMN IF NOT EXISTS segment_id UUID REFERENCES saas_customer_segments(id) ON DELETE SET NULL;
+
+COMMENT ON TABLE saas_customer_segments IS 'Saved customer filters for campaign audiences and workflow conditions';
+COMMENT ON COLUMN saas_campaigns.segment_id IS 'Segment of the segment audience, resolved when sending starts';
@@ -0,0 +1 @@
+ALTER TABLE saas_order_split_payments DROP COLUMN IF EXISTS refunded_amount;
@@ -0,0 +1,6 @@
+-- Refunds of a paid group order are returned to its payers in proportion to their portions
+ALTER TABLE saas_order_split_payments ADD COLUMN IF NOT EXISTS refunded_amount DECIMAL(12,2) NOT NULL DEFAULT 0;
+
+UPDATE saas_order_split_payments SET refunded_amount = amount WHERE status = 'refunded';
+
+COMMENT ON COLUMN saas_order_split_payments.refunded_amount IS 'Returned to the payer so far; the portion is refunded once it reaches amount';
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_saas_campaign_recipients_ack;
+CREATE INDEX IF NOT EXISTS idx_saas_campaign_recipients_message ON saas_campaign_recipients(message_id) WHERE message_id IS NOT NULL;
+
+ALTER TABLE saas_campaign_recipients DROP COLUMN IF EXISTS ack_message_id;
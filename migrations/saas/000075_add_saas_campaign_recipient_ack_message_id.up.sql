@@ -0,0 +1,10 @@
+-- Acks report the bare provider message ID, while some providers return it with a chat prefix on send
+ALTER TABLE saas_campaign_recipients ADD COLUMN IF NOT EXISTS ack_message_id TEXT;
+
+UPDATE saas_campaign_recipients SET ack_message_id = regexp_replace(message_id, '^.*_', '')
+WHERE message_id IS NOT NULL AND message_id <> '';
+
+DROP INDEX IF EXISTS idx_saas_campaign_recipients_message;
+CREATE INDEX IF NOT EXISTS idx_saas_campaign_recipients_ack ON saas_campaign_recipients(ack_message_id) WHERE ack_message_id IS NOT NULL;
+
+COMMENT ON COLUMN saas_campaign_recipients.ack_message_id IS 'Provider message ID without its chat prefix, matched against delivery acks';